@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+const (
+	maxAuditedBodySize = 64 << 10
+	redactedValue      = "[REDACTED]"
+)
+
+// sensitiveFields are redacted from audited request and response bodies
+var sensitiveFields = map[string]bool{
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"token":            true,
+	"access_token":     true,
+	"refresh_token":    true,
+	"secret":           true,
+	"client_secret":    true,
+	"api_key":          true,
+	"authorization":    true,
+}
+
+// sensitiveHeaders are redacted from audited request headers
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// admin wraps a privileged endpoint with authentication, admin checks and request auditing
+func (a *application) admin(handler http.HandlerFunc) http.Handler {
+	return a.requireAuth(a.auditAdmin(a.requireAdmin(handler)))
+}
+
+// requireAdmin rejects callers that are not configured as administrators,
+// including admins holding an impersonation token
+func (a *application) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := claimsFromContext(r.Context()); claims != nil && !claims.IsImpersonationToken() && a.isAdmin(claims.UserID) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusForbidden, map[string]apiError{
+			"error": {Code: "FORBIDDEN", Message: "Administrator access required"},
+		})
+	})
+}
+
+// isAdmin reports whether the user is configured as an administrator
+func (a *application) isAdmin(userID string) bool {
+	for _, id := range a.config.AdminUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// auditAdmin records the redacted request and response of privileged calls in the audit store
+func (a *application) auditAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var requestBody []byte
+		if r.Body != nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(r.Body, maxAuditedBodySize+1))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(requestBody), r.Body))
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		entry := audit.AuditEntry{
+			Timestamp:     start,
+			Action:        r.Pattern,
+			Resource:      "admin_api",
+			ResourceID:    r.URL.Path,
+			Success:       recorder.status < http.StatusBadRequest,
+			IPAddress:     clientIP(r),
+			UserAgent:     r.UserAgent(),
+			SessionID:     user.SessionIDFromContext(r.Context()),
+			CorrelationID: correlation.CorrelationID(r.Context()),
+			CausationID:   correlation.CausationID(r.Context()),
+			Details: map[string]interface{}{
+				"method":        r.Method,
+				"path":          r.URL.Path,
+				"query":         r.URL.RawQuery,
+				"status":        recorder.status,
+				"duration_ms":   time.Since(start).Milliseconds(),
+				"headers":       redactHeaders(r.Header),
+				"request_body":  redactBody(requestBody),
+				"response_body": redactBody(recorder.body.Bytes()),
+			},
+		}
+		if claims := claimsFromContext(r.Context()); claims != nil {
+			entry.UserID = claims.UserID
+		}
+		if !entry.Success {
+			entry.Error = http.StatusText(recorder.status)
+		}
+
+		if err := a.audit.Log(r.Context(), entry); err != nil {
+			correlation.Logf(r.Context(), "Failed to audit admin request %s: %v", r.URL.Path, err)
+		}
+	})
+}
+
+// responseRecorder captures the status and a bounded copy of the response body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if remaining := maxAuditedBodySize + 1 - r.body.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		r.body.Write(p[:remaining])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// redactBody returns the JSON body with sensitive fields masked. Bodies that
+// are not JSON or exceed the audit limit are only described by their size.
+func redactBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	if len(body) > maxAuditedBodySize {
+		return map[string]interface{}{"truncated": true, "size": len(body)}
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return map[string]interface{}{"non_json": true, "size": len(body)}
+	}
+	return redactValue(decoded)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func redactHeaders(headers http.Header) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name := range headers {
+		if sensitiveHeaders[name] {
+			redacted[name] = redactedValue
+			continue
+		}
+		redacted[name] = headers.Get(name)
+	}
+	return redacted
+}
+
+func (a *application) handleAdminGetUser(w http.ResponseWriter, r *http.Request) {
+	found, err := a.users.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.writeUser(w, r, http.StatusOK, a.subject(claimsFromContext(r.Context())), found)
+}
+
+// adminUserPage is a page of users rendered for an administrator
+type adminUserPage struct {
+	Users      []*userview.View `json:"users"`
+	Total      int64            `json:"total"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// handleAdminListUsers lists and searches users. Pages are selected with
+// ?offset= or with the next_cursor of the previous page in ?cursor=. While
+// user data is encrypted, searching or sorting on emails and names is
+// rejected before reaching the user service.
+func (a *application) handleAdminListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := user.ListFilters{
+		EmailPrefix: query.Get("email"),
+		Name:        query.Get("name"),
+		SortBy:      query.Get("sort_by"),
+		SortOrder:   query.Get("sort_order"),
+		Cursor:      query.Get("cursor"),
+	}
+	if a.piiEncrypted {
+		if err := filters.EncryptedFieldsError(); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	var err error
+	if filters.Limit, err = queryInt(query, "limit"); err != nil {
+		badRequest(w, "limit must be an integer")
+		return
+	}
+	if filters.Offset, err = queryInt(query, "offset"); err != nil {
+		badRequest(w, "offset must be an integer")
+		return
+	}
+	if filters.CreatedAfter, err = queryTime(query, "created_after"); err != nil {
+		badRequest(w, "created_after must be an RFC 3339 timestamp")
+		return
+	}
+	if filters.CreatedBefore, err = queryTime(query, "created_before"); err != nil {
+		badRequest(w, "created_before must be an RFC 3339 timestamp")
+		return
+	}
+
+	page, err := a.users.List(r.Context(), filters)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	viewer := a.subject(claimsFromContext(r.Context()))
+	result := adminUserPage{
+		Users:      make([]*userview.View, 0, len(page.Users)),
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	}
+	for _, u := range page.Users {
+		view, err := a.views.Render(r.Context(), viewer, u)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		result.Users = append(result.Users, view)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *application) handleAdminUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var data user.UpdateProfileData
+	if !decodeJSON(w, r, &data) {
+		return
+	}
+
+	updated, err := a.users.UpdateProfile(r.Context(), r.PathValue("id"), data)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.writeUser(w, r, http.StatusOK, a.subject(claimsFromContext(r.Context())), updated)
+}
+
+func (a *application) handleAdminRevokeTokens(w http.ResponseWriter, r *http.Request) {
+	if err := a.token.RevokeAllTokensForUser(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unlockAccountRequest names the account whose lock an admin lifts
+type unlockAccountRequest struct {
+	Email string `json:"email"`
+}
+
+// handleAdminUnlockAccount lifts the lock an account got after failed logins
+// before its cool-down ends
+func (a *application) handleAdminUnlockAccount(w http.ResponseWriter, r *http.Request) {
+	if a.unlocker == nil {
+		writeJSON(w, http.StatusNotFound, map[string]apiError{
+			"error": {Code: "NOT_FOUND", Message: "Account lockout is disabled"},
+		})
+		return
+	}
+
+	var req unlockAccountRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Email == "" {
+		badRequest(w, "email is required")
+		return
+	}
+
+	if err := a.unlocker.Unlock(r.Context(), req.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// impersonateRequest lists the authorization actions an admin asks to be
+// granted while acting as a user
+type impersonateRequest struct {
+	Scopes []string `json:"scopes"`
+}
+
+// handleAdminImpersonate issues the caller a short-lived token to act as the
+// user with the requested scopes. Admins cannot be impersonated, so an
+// impersonation never gains privileges the caller's own token lacks.
+func (a *application) handleAdminImpersonate(w http.ResponseWriter, r *http.Request) {
+	var req impersonateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Scopes) == 0 {
+		badRequest(w, "scopes is required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !authorization.IsImpersonationAction(scope) {
+			badRequest(w, "scope "+scope+" cannot be granted while impersonating")
+			return
+		}
+	}
+
+	userID := r.PathValue("id")
+	if a.isAdmin(userID) {
+		writeJSON(w, http.StatusForbidden, map[string]apiError{
+			"error": {Code: "FORBIDDEN", Message: "Administrators cannot be impersonated"},
+		})
+		return
+	}
+	if _, err := a.users.GetByID(r.Context(), userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	issued, err := a.token.GenerateImpersonationToken(r.Context(), claimsFromContext(r.Context()).UserID, userID, req.Scopes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+// queryInt parses an optional integer query parameter; absent parameters are zero
+func queryInt(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// queryTime parses an optional RFC 3339 query parameter; absent parameters are nil
+func queryTime(query url.Values, name string) (*time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	lockoutMemory "github.com/gentra/decorator-arch-go/internal/lockout/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userLockout "github.com/gentra/decorator-arch-go/internal/user/lockout"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	userViewStandard "github.com/gentra/decorator-arch-go/internal/userview/standard"
+)
+
+func newAdminTestApp(t *testing.T) (*application, *auditMock.MockAuditService, *userMock.MockUserService) {
+	t.Helper()
+	auditSvc := &auditMock.MockAuditService{}
+	users := &userMock.MockUserService{}
+	tokens := testkit.NewTokenService(t)
+	app := &application{
+		config:  config{AdminUserIDs: []string{"admin-1"}},
+		audit:   auditSvc,
+		token:   tokens,
+		apiKeys: authUsecase.NewAPIKeyAuthStrategy(users, tokens),
+		users:   users,
+		views:   userViewStandard.NewService(userViewStandard.Config{}),
+	}
+	return app, auditSvc, users
+}
+
+func authorizedRequest(t *testing.T, app *application, userID, method, path, body string) *http.Request {
+	t.Helper()
+	accessToken, _, err := app.token.GenerateAuthToken(t.Context(), userID, userID+"@example.com")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set(correlationIDHeader, "corr-123")
+	return req
+}
+
+func TestAuditAdmin_GivenAdminRequest_WhenHandled_ThenRecordsRedactedEntry(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	users.On("UpdateProfile", mock.Anything, "user-9", mock.Anything).
+		Return(&user.User{Email: "user-9@example.com", FirstName: "Jane"}, nil)
+
+	var logged audit.AuditEntry
+	auditSvc.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { logged = args.Get(1).(audit.AuditEntry) }).
+		Return(nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPut, "/api/admin/users/user-9/profile",
+		`{"first_name":"Jane","password":"hunter2"}`)
+	rec := httptest.NewRecorder()
+
+	app.routes().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "corr-123", rec.Header().Get(correlationIDHeader))
+	assert.Equal(t, "corr-123", logged.CorrelationID)
+	assert.Equal(t, "admin-1", logged.UserID)
+	assert.Equal(t, "PUT /api/admin/users/{id}/profile", logged.Action)
+	assert.True(t, logged.Success)
+
+	details := logged.Details.(map[string]interface{})
+	requestBody := details["request_body"].(map[string]interface{})
+	assert.Equal(t, redactedValue, requestBody["password"])
+	assert.Equal(t, "Jane", requestBody["first_name"])
+	assert.Equal(t, redactedValue, details["headers"].(map[string]string)["Authorization"])
+	assert.NotNil(t, details["response_body"])
+}
+
+func TestAuditAdmin_GivenNonAdmin_WhenCalling_ThenForbiddenAndAudited(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+
+	var logged audit.AuditEntry
+	auditSvc.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { logged = args.Get(1).(audit.AuditEntry) }).
+		Return(nil)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodGet, "/api/admin/users/user-9", "")
+	rec := httptest.NewRecorder()
+
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.False(t, logged.Success)
+	assert.Equal(t, "user-1", logged.UserID)
+	users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestAdminListUsers_GivenQueryFilters_WhenAdminCalls_ThenReturnsRenderedPage(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filters := user.ListFilters{EmailPrefix: "jane", SortBy: user.SortByEmail, SortOrder: user.SortAsc, Limit: 1, CreatedAfter: &after}
+	users.On("List", mock.Anything, filters).Return(&user.Page{
+		Users:      []*user.User{testkit.NewUserBuilder().WithEmail("jane@example.com").Build()},
+		Total:      2,
+		Limit:      1,
+		NextCursor: "next",
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodGet,
+		"/api/admin/users?email=jane&sort_by=email&sort_order=asc&limit=1&created_after=2024-01-01T00:00:00Z", ""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page struct {
+		Users []struct {
+			Email string `json:"email"`
+		} `json:"users"`
+		Total      int64  `json:"total"`
+		NextCursor string `json:"next_cursor"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "jane@example.com", page.Users[0].Email)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, "next", page.NextCursor)
+	users.AssertExpectations(t)
+}
+
+func TestAdminListUsers_GivenMalformedTimestamp_WhenAdminCalls_ThenReturnsBadRequest(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/users?created_before=yesterday", ""))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestAdminListUsers_GivenEncryptedUserData_WhenSearchingEncryptedFields_ThenRejectsUpFront(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Given an email prefix, When an admin lists users, Then is rejected", query: "email=jane"},
+		{name: "Given a name, When an admin lists users, Then is rejected", query: "name=Jane"},
+		{name: "Given a sort by email, When an admin lists users, Then is rejected", query: "sort_by=email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, auditSvc, users := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+			app.piiEncrypted = true
+
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/users?"+tt.query, ""))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), user.ErrInvalidListFilter.Code)
+			users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected interface{}
+	}{
+		{
+			name:     "Given nested secrets, When redacting, Then every sensitive field is masked",
+			body:     `{"user":{"email":"a@b.c","Password":"x"},"tokens":[{"refresh_token":"y"}]}`,
+			expected: map[string]interface{}{"user": map[string]interface{}{"email": "a@b.c", "Password": redactedValue}, "tokens": []interface{}{map[string]interface{}{"refresh_token": redactedValue}}},
+		},
+		{
+			name:     "Given non-JSON body, When redacting, Then only size is recorded",
+			body:     "plain text",
+			expected: map[string]interface{}{"non_json": true, "size": 10},
+		},
+		{
+			name:     "Given empty body, When redacting, Then nothing is recorded",
+			body:     "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactBody([]byte(tt.body)))
+		})
+	}
+}
+
+func TestAdminUnlockAccount_GivenLockedAccount_WhenUnlocking_ThenLiftsLock(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("Login", mock.Anything, "jane@example.com", mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	app.unlocker = userLockout.NewService(users, lockoutMemory.NewService(), nil, userLockout.Config{MaxAttempts: 1})
+	_, err := app.unlocker.Login(t.Context(), "jane@example.com", "wrong")
+	require.ErrorIs(t, err, user.ErrAccountLocked)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/users/unlock", `{"email":"jane@example.com"}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	_, _ = app.unlocker.Login(t.Context(), "jane@example.com", "wrong")
+	users.AssertNumberOfCalls(t, "Login", 2)
+}
+
+func TestAdminUnlockAccount_GivenLockoutDisabled_WhenUnlocking_ThenReturnsNotFound(t *testing.T) {
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/users/unlock", `{"email":"jane@example.com"}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminImpersonate_GivenUser_WhenImpersonating_ThenIssuesScopedToken(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("GetByID", mock.Anything, "user-9").Return(&user.User{Email: "user-9@example.com"}, nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/users/user-9/impersonate",
+		`{"scopes":["user:update_profile"]}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var issued token.ImpersonationToken
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &issued))
+	assert.Equal(t, "admin-1", issued.ActorID)
+	assert.Equal(t, "user-9", issued.UserID)
+	assert.Equal(t, []string{"user:update_profile"}, issued.Scopes)
+
+	// The token acts as the user, marked as impersonated
+	var impersonation user.Impersonation
+	users.On("GetByID", mock.Anything, "user-9").Unset()
+	users.On("GetByID", mock.Anything, "user-9").
+		Run(func(args mock.Arguments) {
+			impersonation, _ = user.ImpersonationFromContext(args.Get(0).(context.Context))
+		}).
+		Return(&user.User{Email: "user-9@example.com"}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/api/users/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, user.Impersonation{ActorID: "admin-1", OnBehalfOfID: "user-9", Scopes: []string{"user:update_profile"}}, impersonation)
+}
+
+func TestAdminImpersonate_GivenImpersonationToken_WhenCallingAdminEndpoint_ThenReturnsForbidden(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	issued, err := app.token.GenerateImpersonationToken(t.Context(), "admin-1", "admin-1", []string{"user:update_profile"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestAdminImpersonate_GivenInvalidRequest_WhenImpersonating_ThenRefuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+	}{
+		{
+			name:     "Given no scopes, When impersonating, Then returns bad request",
+			path:     "/api/admin/users/user-9/impersonate",
+			body:     `{}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "Given a scope not grantable while impersonating, When impersonating, Then returns bad request",
+			path:     "/api/admin/users/user-9/impersonate",
+			body:     `{"scopes":["user:change_password"]}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "Given an admin target, When impersonating, Then returns forbidden",
+			path:     "/api/admin/users/admin-1/impersonate",
+			body:     `{"scopes":["user:update_profile"]}`,
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, auditSvc, users := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+			req := authorizedRequest(t, app, "admin-1", http.MethodPost, tt.path, tt.body)
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
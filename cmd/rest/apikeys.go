@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// apiKeyScopes is the scope an API key needs for each route, keyed by the
+// pattern the route is registered with. Routes missing here refuse API keys,
+// so new endpoints stay closed to them until a scope is assigned.
+var apiKeyScopes = map[string]string{
+	"GET /api/auth/me":                  "users:read",
+	"POST /api/auth/introspect":         "tokens:introspect",
+	"GET /api/users/profile":            "users:read",
+	"PUT /api/users/profile":            "users:write",
+	"GET /api/users/avatar":             "users:read",
+	"GET /api/users/preferences":        "users:read",
+	"PUT /api/users/preferences":        "users:write",
+	"GET /api/users/preferences/schema": "users:read",
+	"GET /api/users/feature-flags":      "users:read",
+	"GET /api/notifications":            "notifications:read",
+	"PUT /api/notifications/{id}/read":  "notifications:write",
+
+	"GET /api/users/me/notifications":              "notifications:read",
+	"GET /api/users/me/notifications/unread-count": "notifications:read",
+	"POST /api/users/me/notifications/{id}/read":   "notifications:write",
+	"POST /api/users/me/notifications/read-all":    "notifications:write",
+
+	"GET /api/users/me/notification-digest":         "notifications:read",
+	"PUT /api/users/me/notification-digest":         "notifications:write",
+	"GET /api/users/me/notification-digest/pending": "notifications:read",
+	"GET /api/users/me/notification-history":        "notifications:read",
+}
+
+// authorizeAPIKey checks that the API key grants the scope of the matched
+// route and that a service account holding it is still enabled
+func (a *application) authorizeAPIKey(r *http.Request, bearer string) error {
+	claims, err := a.apiKeys.ValidateToken(r.Context(), bearer)
+	if err != nil {
+		return err
+	}
+	if err := a.activePrincipal(r.Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	scope, ok := apiKeyScopes[r.Pattern]
+	if !ok || !claims.HasScope(scope) {
+		return auth.ErrInsufficientScope
+	}
+	return nil
+}
+
+// grantableScope reports whether users may issue API keys with the scope
+func grantableScope(scope string) bool {
+	for _, granted := range apiKeyScopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyOwner returns the user ID of the path when the caller manages their
+// own keys; API keys and impersonation tokens cannot manage keys
+func apiKeyOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	claims := claimsFromContext(r.Context())
+	userID := r.PathValue("id")
+	if claims.UserID != userID || claims.TokenType == "api" || claims.IsImpersonationToken() {
+		writeError(w, user.ErrForbidden)
+		return "", false
+	}
+	return userID, true
+}
+
+// createAPIKeyRequest is the body of an API key creation
+type createAPIKeyRequest struct {
+	Scopes []string `json:"scopes"`
+}
+
+// handleCreateAPIKey issues a long-lived API key with the requested scopes.
+// The key is only returned in this response.
+func (a *application) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := apiKeyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req createAPIKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Scopes) == 0 {
+		badRequest(w, "scopes is required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !grantableScope(scope) {
+			badRequest(w, "scope "+scope+" cannot be granted to API keys")
+			return
+		}
+	}
+
+	issued, err := a.token.GenerateAPIToken(r.Context(), userID, req.Scopes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+// revokeAPIKeyRequest is the body of an API key revocation
+type revokeAPIKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// handleRevokeAPIKey revokes one of the user's API keys. The key travels in
+// the body rather than the path so it stays out of access logs.
+func (a *application) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := apiKeyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req revokeAPIKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Key == "" {
+		badRequest(w, "key is required")
+		return
+	}
+
+	claims, err := a.apiKeys.ValidateToken(r.Context(), req.Key)
+	if err != nil {
+		badRequest(w, "key is not a valid API key")
+		return
+	}
+	if claims.UserID != userID {
+		writeError(w, user.ErrForbidden)
+		return
+	}
+
+	if err := a.apiKeys.RevokeToken(r.Context(), req.Key); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// apiKeyRequest returns a request authenticated with the API key
+func apiKeyRequest(method, path, apiKey string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req
+}
+
+func TestAPIKeys_GivenKeyScopes_WhenCallingRoutes_ThenEnforcesScopePerRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		method   string
+		path     string
+		expected int
+	}{
+		{
+			name:     "Given a key with the route's scope, When calling it, Then succeeds",
+			scopes:   []string{"users:read"},
+			method:   http.MethodGet,
+			path:     "/api/users/profile",
+			expected: http.StatusOK,
+		},
+		{
+			name:     "Given a key granting every action on the resource, When calling a route, Then succeeds",
+			scopes:   []string{"users:*"},
+			method:   http.MethodGet,
+			path:     "/api/auth/me",
+			expected: http.StatusOK,
+		},
+		{
+			name:     "Given a key without the route's scope, When calling it, Then is forbidden",
+			scopes:   []string{"notifications:read"},
+			method:   http.MethodGet,
+			path:     "/api/users/profile",
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "Given a key granting everything, When calling a route without a scope, Then is forbidden",
+			scopes:   []string{"*"},
+			method:   http.MethodGet,
+			path:     "/api/users/profile/export",
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, users := newAdminTestApp(t)
+			users.On("GetByID", mock.Anything, "user-1").Return(testkit.NewUserBuilder().Build(), nil)
+			apiKey, err := app.token.GenerateAPIToken(t.Context(), "user-1", tt.scopes)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, apiKeyRequest(tt.method, tt.path, apiKey.Token))
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusForbidden {
+				assert.Contains(t, rec.Body.String(), "INSUFFICIENT_SCOPE")
+			}
+		})
+	}
+}
+
+func TestCreateAPIKey_GivenOwnUser_WhenCreating_ThenReturnsScopedKey(t *testing.T) {
+	app, _, _ := newAdminTestApp(t)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/user-1/api-keys",
+		`{"scopes":["users:read","notifications:read"]}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var issued token.APIToken
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &issued))
+	claims, err := app.token.ValidateAPIToken(t.Context(), issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, []string{"users:read", "notifications:read"}, claims.Scopes)
+}
+
+func TestCreateAPIKey_GivenInvalidRequest_WhenCreating_ThenRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+	}{
+		{
+			name:     "Given another user's ID, When creating, Then is forbidden",
+			path:     "/api/users/user-2/api-keys",
+			body:     `{"scopes":["users:read"]}`,
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "Given no scopes, When creating, Then is a bad request",
+			path:     "/api/users/user-1/api-keys",
+			body:     `{"scopes":[]}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "Given a scope no route uses, When creating, Then is a bad request",
+			path:     "/api/users/user-1/api-keys",
+			body:     `{"scopes":["*"]}`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, _ := newAdminTestApp(t)
+
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, tt.path, tt.body))
+
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestCreateAPIKey_GivenAPIKey_WhenCreating_ThenIsForbidden(t *testing.T) {
+	app, _, _ := newAdminTestApp(t)
+	apiKey, err := app.token.GenerateAPIToken(t.Context(), "user-1", []string{"users:write"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodPost, "/api/users/user-1/api-keys", apiKey.Token))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRevokeAPIKey_GivenOwnKey_WhenRevoking_ThenKeyStopsWorking(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("GetByID", mock.Anything, "user-1").Return(testkit.NewUserBuilder().Build(), nil)
+	apiKey, err := app.token.GenerateAPIToken(t.Context(), "user-1", []string{"users:read"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/user-1/api-keys/revoke",
+		`{"key":"`+apiKey.Token+`"}`))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/api/users/profile", apiKey.Token))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRevokeAPIKey_GivenAnotherUsersKey_WhenRevoking_ThenIsForbidden(t *testing.T) {
+	app, _, _ := newAdminTestApp(t)
+	apiKey, err := app.token.GenerateAPIToken(t.Context(), "user-2", []string{"users:read"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/user-1/api-keys/revoke",
+		`{"key":"`+apiKey.Token+`"}`))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	_, err = app.token.ValidateAPIToken(t.Context(), apiKey.Token)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,983 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditFactory "github.com/gentra/decorator-arch-go/internal/audit/factory"
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authFactory "github.com/gentra/decorator-arch-go/internal/auth/factory"
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	captchaFactory "github.com/gentra/decorator-arch-go/internal/captcha/factory"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/device"
+	deviceMemory "github.com/gentra/decorator-arch-go/internal/device/memory"
+	deviceRedis "github.com/gentra/decorator-arch-go/internal/device/redis"
+	"github.com/gentra/decorator-arch-go/internal/encryption"
+	encryptionFactory "github.com/gentra/decorator-arch-go/internal/encryption/factory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/dispatcher"
+	eventHandlerFactory "github.com/gentra/decorator-arch-go/internal/eventhandler/factory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/welcomeemail"
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsAMQP "github.com/gentra/decorator-arch-go/internal/events/amqp"
+	eventsFactory "github.com/gentra/decorator-arch-go/internal/events/factory"
+	eventsPubSub "github.com/gentra/decorator-arch-go/internal/events/pubsub"
+	eventsSNS "github.com/gentra/decorator-arch-go/internal/events/sns"
+	eventSchemaFactory "github.com/gentra/decorator-arch-go/internal/eventschema/factory"
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	hashArgon2id "github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+	hashFactory "github.com/gentra/decorator-arch-go/internal/hash/factory"
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+	idempotencyMemory "github.com/gentra/decorator-arch-go/internal/idempotency/memory"
+	idempotencyPostgres "github.com/gentra/decorator-arch-go/internal/idempotency/postgres"
+	idempotencyRedis "github.com/gentra/decorator-arch-go/internal/idempotency/redis"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/lockout"
+	lockoutMemory "github.com/gentra/decorator-arch-go/internal/lockout/memory"
+	lockoutRedis "github.com/gentra/decorator-arch-go/internal/lockout/redis"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationFactory "github.com/gentra/decorator-arch-go/internal/notification/factory"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	notificationHistoryMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+	notificationHistoryPostgres "github.com/gentra/decorator-arch-go/internal/notificationhistory/postgres"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	oauthServerFactory "github.com/gentra/decorator-arch-go/internal/oauthserver/factory"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	profilingFactory "github.com/gentra/decorator-arch-go/internal/profiling/factory"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	ratelimitFactory "github.com/gentra/decorator-arch-go/internal/ratelimit/factory"
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
+	revocationRedis "github.com/gentra/decorator-arch-go/internal/revocation/redis"
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	sanitizeStandard "github.com/gentra/decorator-arch-go/internal/sanitize/standard"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	serviceAccountFactory "github.com/gentra/decorator-arch-go/internal/serviceaccount/factory"
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	signingKeyFile "github.com/gentra/decorator-arch-go/internal/signingkey/file"
+	signingKeyMemory "github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+	signingKeyPostgres "github.com/gentra/decorator-arch-go/internal/signingkey/postgres"
+	signingKeyRedis "github.com/gentra/decorator-arch-go/internal/signingkey/redis"
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	storageFactory "github.com/gentra/decorator-arch-go/internal/storage/factory"
+	storageLocal "github.com/gentra/decorator-arch-go/internal/storage/local"
+	storageS3 "github.com/gentra/decorator-arch-go/internal/storage/s3"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
+	throttleRedis "github.com/gentra/decorator-arch-go/internal/throttle/redis"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	tokenFactory "github.com/gentra/decorator-arch-go/internal/token/factory"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
+	tokenStorePostgres "github.com/gentra/decorator-arch-go/internal/tokenstore/postgres"
+	tokenStoreRedis "github.com/gentra/decorator-arch-go/internal/tokenstore/redis"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userFactory "github.com/gentra/decorator-arch-go/internal/user/factory"
+	userLockout "github.com/gentra/decorator-arch-go/internal/user/lockout"
+	userSQLite "github.com/gentra/decorator-arch-go/internal/user/sqlite"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+	userViewStandard "github.com/gentra/decorator-arch-go/internal/userview/standard"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	validationFactory "github.com/gentra/decorator-arch-go/internal/validation/factory"
+	"github.com/gentra/decorator-arch-go/internal/validation/jsonschema"
+	"github.com/gentra/decorator-arch-go/internal/validation/liverules"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/deliverability"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/pwned"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	webhookDelivery "github.com/gentra/decorator-arch-go/internal/webhook/delivery"
+)
+
+// application holds every domain service the REST server depends on
+type application struct {
+	config config
+
+	db    *gorm.DB
+	pool  *pgxpool.Pool // only opened for the "postgres" user storage or idempotency store
+	redis *redis.Client
+
+	audit        audit.Service
+	encryption   encryption.Service
+	hasher       hash.Service
+	rateLimit    ratelimit.Service
+	validation   validation.Service
+	notification notification.Service
+	history      notificationhistory.Service
+	templates    notificationtemplate.Service
+	schedule     notificationschedule.Service
+	digests      notificationdigest.Service
+	inbox        inbox.Service
+	token        token.Service
+	tokenConfig  tokenFactory.Config // Settings a.token was built from
+	revocations  revocation.Service
+	events       events.Service
+	users        user.Service
+	piiEncrypted bool // Emails and names are stored encrypted and cannot be searched
+	views        userview.Service
+	profiling    profiling.Service
+	auth         auth.Service
+	apiKeys      auth.Service
+	magicLinks   *authUsecase.MagicLinkSender // nil when magic links are disabled
+	storage      storage.Service
+	idempotency  idempotency.Service
+	lockout      lockout.Service
+	devices      device.Service
+
+	// validationRules keeps the rules administrators manage, applied by
+	// liveRules; both nil when the validation rule API is disabled
+	validationRules rulestore.Service
+	liveRules       *liverules.Service
+
+	// schemas check request bodies against their endpoint's JSON Schema;
+	// nil when payload schemas are disabled
+	schemas *jsonschema.Contracts
+
+	// captcha checks registrations and suspicious logins against failed
+	// logins counted in captchaFailures; nil when captchas are disabled
+	captcha         captcha.Service
+	captchaFailures throttle.Service
+
+	// unlocker lifts account locks; nil when lockout is disabled
+	unlocker userLockout.Service
+
+	serviceAccounts serviceaccount.Service
+	oauthServer     oauthserver.Service
+	outbox          outbox.Service
+
+	realtime *realtimeHub
+
+	// schedulerOwner identifies the leases this instance takes on the
+	// scheduled notifications it delivers
+	schedulerOwner string
+
+	// eventOutbox keeps the events publisher records until the relay sends
+	// them to the event bus; nil when the outbox is disabled and publisher
+	// is the bus itself
+	eventOutbox eventoutbox.Service
+	publisher   events.Service
+
+	// deadLetters keeps the events handlers gave up on; nil when the
+	// dead-letter store is disabled
+	deadLetters deadletter.Service
+
+	// eventHandlers dispatch the events of the bus to the built-in handlers
+	eventHandlers []*dispatcher.Service
+
+	// replays feed stored events back into the built-in handlers
+	replays eventreplay.Service
+
+	// webhooks keeps the external endpoints events are delivered to; nil
+	// when webhooks are disabled
+	webhooks webhook.Service
+
+	// Deprecated API surface and the per-client usage counter
+	deprecations    deprecations
+	deprecatedUsage *prometheus.CounterVec
+}
+
+// component is a single buildable dependency of the application
+type component struct {
+	name  string
+	build func() error
+}
+
+// newApplication builds every component in order and fails on the first error
+func newApplication(cfg config) (*application, error) {
+	app := &application{config: cfg}
+	for _, c := range app.components() {
+		if err := c.build(); err != nil {
+			app.Close()
+			return nil, fmt.Errorf("failed to build %s: %w", c.name, err)
+		}
+	}
+	return app, nil
+}
+
+// components lists every dependency in build order
+func (a *application) components() []component {
+	return []component{
+		{name: "database", build: a.openDatabase},
+		{name: "redis", build: a.connectRedis},
+		{name: "audit", build: a.buildAudit},
+		{name: "encryption", build: a.buildEncryption},
+		{name: "hash", build: a.buildHash},
+		{name: "ratelimit", build: a.buildRateLimit},
+		{name: "validation", build: a.buildValidation},
+		{name: "validationrules", build: a.buildValidationRules},
+		{name: "schemas", build: a.buildSchemas},
+		{name: "notification", build: a.buildNotification},
+		{name: "revocation", build: a.buildRevocations},
+		{name: "token", build: a.buildToken},
+		{name: "serviceaccount", build: a.buildServiceAccounts},
+		{name: "oauthserver", build: a.buildOAuthServer},
+		{name: "deadletters", build: a.buildDeadLetters},
+		{name: "events", build: a.buildEvents},
+		{name: "eventoutbox", build: a.buildEventOutbox},
+		{name: "webhooks", build: a.buildWebhooks},
+		{name: "eventhandlers", build: a.buildEventHandlers},
+		{name: "eventreplays", build: a.buildEventReplays},
+		{name: "realtime", build: a.buildRealtime},
+		{name: "storage", build: a.buildStorage},
+		{name: "idempotency", build: a.buildIdempotency},
+		{name: "lockout", build: a.buildLockout},
+		{name: "device", build: a.buildDevices},
+		{name: "captcha", build: a.buildCaptcha},
+		{name: "user", build: a.buildUser},
+		{name: "userview", build: a.buildUserViews},
+		{name: "profiling", build: a.buildProfiling},
+		{name: "auth", build: a.buildAuth},
+		{name: "deprecations", build: a.buildDeprecations},
+	}
+}
+
+// eventDrainTimeout bounds how long Close waits for queued events
+const eventDrainTimeout = 10 * time.Second
+
+// Close delivers the events still queued and releases database and cache
+// connections
+func (a *application) Close() {
+	if bus, ok := a.events.(interface{ Close(context.Context) error }); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), eventDrainTimeout)
+		if err := bus.Close(ctx); err != nil {
+			log.Printf("Failed to deliver queued events: %v", err)
+		}
+		cancel()
+	}
+	for _, handler := range a.eventHandlers {
+		ctx, cancel := context.WithTimeout(context.Background(), eventDrainTimeout)
+		if err := handler.Close(ctx); err != nil {
+			log.Printf("Failed to handle queued events: %v", err)
+		}
+		cancel()
+	}
+	if a.redis != nil {
+		_ = a.redis.Close()
+	}
+	if a.pool != nil {
+		a.pool.Close()
+	}
+	if a.db != nil {
+		if sqlDB, err := a.db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+}
+
+func (a *application) openDatabase() error {
+	if a.config.UserStorage == "sqlite" {
+		db, err := userSQLite.Open(a.config.SQLitePath)
+		if err != nil {
+			return err
+		}
+		a.db = db
+		return nil
+	}
+
+	if a.config.DatabaseURL == "" {
+		return fmt.Errorf("DATABASE_URL is required")
+	}
+
+	db, err := gorm.Open(postgres.Open(a.config.DatabaseURL), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	a.db = db
+
+	if a.config.UserStorage == "postgres" || a.config.IdempotencyStore == "postgres" ||
+		a.config.JWTKeyStore == "postgres" || (a.config.TokenProvider == "opaque" && a.config.TokenStore == "postgres") ||
+		(a.config.TokenProvider != "opaque" && a.config.TokenRegistry == "postgres") ||
+		a.config.ValidationRuleStore == "postgres" || a.config.EventOutbox == "postgres" ||
+		a.config.DeadLetterStore == "postgres" || a.config.WebhookStore == "postgres" ||
+		a.config.NotificationHistoryStore == "postgres" || a.config.NotificationTemplateStore == "postgres" ||
+		a.config.NotificationScheduleStore == "postgres" || a.config.InboxStore == "postgres" ||
+		a.config.NotificationDigestStore == "postgres" || a.config.ServiceAccountStore == "postgres" ||
+		a.config.OAuthStore == "postgres" || a.config.ProfilingStore == "postgres" {
+		pool, err := pgxpool.New(context.Background(), a.config.DatabaseURL)
+		if err != nil {
+			return err
+		}
+		a.pool = pool
+	}
+	return nil
+}
+
+func (a *application) connectRedis() error {
+	if a.config.RedisURL == "" {
+		return nil
+	}
+
+	opts, err := redis.ParseURL(a.config.RedisURL)
+	if err != nil {
+		return fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	a.redis = redis.NewClient(opts)
+	return nil
+}
+
+func (a *application) buildAudit() (err error) {
+	a.audit, err = auditFactory.NewFactory(auditFactory.DefaultConfig()).Build()
+	return err
+}
+
+func (a *application) buildEncryption() (err error) {
+	// User PII is encrypted with a key ring so keys can be rotated without
+	// losing access to existing rows; ENCRYPTION_KEY seeds the ring
+	builder := encryptionFactory.NewConfigBuilder().WithAlgorithm("keyring")
+	if a.config.EncryptionKey != "" {
+		key, decodeErr := base64.StdEncoding.DecodeString(a.config.EncryptionKey)
+		if decodeErr != nil {
+			return fmt.Errorf("ENCRYPTION_KEY must be base64 encoded: %w", decodeErr)
+		}
+		builder = builder.WithDefaultKey(key).WithAutoGenerateKeys(false)
+	}
+
+	a.encryption, err = encryptionFactory.NewFactory(builder.Build()).Build()
+	return err
+}
+
+func (a *application) buildHash() (err error) {
+	a.hasher, err = hashFactory.NewFactory(hashFactory.Config{
+		Algorithm:  a.config.PasswordHashAlgorithm,
+		BcryptCost: a.config.BcryptCost,
+		Argon2id: hashArgon2id.Params{
+			Memory:      uint32(a.config.Argon2Memory),
+			Iterations:  uint32(a.config.Argon2Iterations),
+			Parallelism: uint8(a.config.Argon2Parallelism),
+		},
+	}).Build()
+	return err
+}
+
+func (a *application) buildRateLimit() (err error) {
+	config := ratelimitFactory.NewConfigBuilder().EnableTokenBucket().Build()
+	a.rateLimit, err = ratelimitFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildValidation() (err error) {
+	builder := validationFactory.NewConfigBuilder().
+		EnableTagEngine().
+		WithI18n(true, a.config.DefaultLanguage).
+		WithTranslations(a.config.TranslationDir).
+		WithCustomRuleDir(a.config.ValidationRuleDir)
+	if a.config.ValidationRuleStore != "" {
+		// The rules seed the rule store, which applies them from then on
+		builder.WithCustomRuleDir("")
+	}
+	if a.config.EmailDeliverabilityCheck {
+		builder.WithFieldRule("email", deliverability.RuleName, deliverability.NewService(deliverability.Config{Remote: a.config.RemoteValidation}))
+	}
+	if a.config.BreachedPasswordCheck {
+		builder.WithFieldRule("password", pwned.RuleName, pwned.NewService(pwned.Config{Remote: a.config.RemoteValidation}))
+	}
+	config := builder.Build()
+	a.validation, err = validationFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildNotification() (err error) {
+	switch a.config.NotificationHistoryStore {
+	case "", "memory":
+		a.history = notificationHistoryMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for NOTIFICATION_HISTORY_STORE=postgres")
+		}
+		a.history = notificationHistoryPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown NOTIFICATION_HISTORY_STORE %q", a.config.NotificationHistoryStore)
+	}
+
+	if err := a.buildNotificationTemplates(); err != nil {
+		return err
+	}
+	if err := a.buildNotificationSchedule(); err != nil {
+		return err
+	}
+	if err := a.buildInbox(); err != nil {
+		return err
+	}
+	if err := a.buildNotificationDigests(); err != nil {
+		return err
+	}
+
+	a.outbox = outboxMemory.NewService(outboxMemory.DefaultCapacity)
+	config := notificationFactory.NewConfigBuilder().
+		WithDryRun(a.outbox, a.config.NotificationDryRun).
+		WithTemplateService(a.templates).
+		WithHistory(a.history).
+		WithSMSProvider(a.config.SMSProvider).
+		WithTwilioConfig(a.config.TwilioAccountSID, a.config.TwilioAuthToken, a.config.TwilioFromNumber).
+		WithTwilioStatusCallback(a.config.TwilioStatusCallbackURL).
+		WithPreferences(a.notificationPreferences).
+		WithInbox(a.inbox).
+		WithDigests(a.digests).
+		WithSchedule(a.schedule).
+		Build()
+	a.notification, err = notificationFactory.NewFactory(config).Build()
+	return err
+}
+
+// notificationPreferences looks up the preferences the notification
+// dispatcher respects. The user service is built after the notification
+// service it sends through, so it is only resolved when a notification is
+// sent.
+func (a *application) notificationPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	if a.users == nil {
+		return nil, nil
+	}
+	return a.users.GetPreferences(ctx, userID)
+}
+
+// buildRevocations opens the store revoked tokens and users are recorded in,
+// shared by the token service and the auth token manager
+func (a *application) buildRevocations() error {
+	switch a.config.RevocationStore {
+	case "", "memory":
+		a.revocations = revocationMemory.NewService()
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("REDIS_URL is required for the redis revocation store")
+		}
+		a.revocations = revocationRedis.NewService(a.redis)
+	default:
+		return fmt.Errorf("unknown REVOCATION_STORE %q", a.config.RevocationStore)
+	}
+	return nil
+}
+
+func (a *application) buildToken() (err error) {
+	builder := tokenFactory.NewConfigBuilder().
+		WithRevocationStore(a.revocations).
+		WithAuditService(a.audit)
+	if a.config.Production {
+		builder = builder.EnableMetrics()
+	}
+	if a.config.JWTSecret != "" {
+		builder = builder.WithSecretString(a.config.JWTSecret)
+	}
+
+	switch a.config.JWTAlgorithm {
+	case "", token.AlgorithmHS256:
+	case token.AlgorithmRS256:
+		builder = builder.WithRSAKeys(a.config.JWTPrivateKeyPath, a.config.JWTPublicKeyPath)
+	case token.AlgorithmES256:
+		builder = builder.WithECDSAKeys(a.config.JWTPrivateKeyPath, a.config.JWTPublicKeyPath)
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q", a.config.JWTAlgorithm)
+	}
+
+	if a.config.JWTRetiredKeyPath != "" {
+		rotatedAt, err := time.Parse(time.RFC3339, a.config.JWTKeyRotatedAt)
+		if err != nil {
+			return fmt.Errorf("JWT_KEY_ROTATED_AT must be an RFC 3339 time when JWT_RETIRED_KEY_PATH is set: %w", err)
+		}
+		builder = builder.WithRetiredKey(a.config.JWTRetiredKeyPath, rotatedAt)
+	}
+
+	if a.config.JWTKeyStore != "" {
+		keyStore, err := a.buildKeyStore()
+		if err != nil {
+			return err
+		}
+		builder = builder.
+			WithKeyStore(a.config.JWTAlgorithm, keyStore).
+			WithKeyRotation(true, a.config.JWTRotationInterval)
+	}
+
+	if builder, err = a.configureEncryption(builder); err != nil {
+		return err
+	}
+
+	builder = builder.
+		WithMaxActiveTokens(a.config.MaxActiveTokens).
+		WithClockSkew(a.config.TokenClockSkew)
+	switch a.config.TokenProvider {
+	case "", "jwt":
+		if a.config.TokenRegistry != "none" {
+			registry, err := a.buildTokenStore(a.config.TokenRegistry, "TOKEN_REGISTRY")
+			if err != nil {
+				return err
+			}
+			builder = builder.WithTokenRegistry(registry)
+		}
+	case "opaque":
+		tokenStore, err := a.buildTokenStore(a.config.TokenStore, "TOKEN_STORE")
+		if err != nil {
+			return err
+		}
+		builder = builder.WithTokenStore(tokenStore)
+	default:
+		return fmt.Errorf("unknown TOKEN_PROVIDER %q", a.config.TokenProvider)
+	}
+
+	a.tokenConfig = builder.Build()
+	a.token, err = tokenFactory.NewFactory(a.tokenConfig).Build()
+	return err
+}
+
+// configureEncryption encrypts JWTs with the configured key, if any
+func (a *application) configureEncryption(builder *tokenFactory.ConfigBuilder) (*tokenFactory.ConfigBuilder, error) {
+	switch {
+	case a.config.JWTEncryptionKey != "":
+		key, err := base64.StdEncoding.DecodeString(a.config.JWTEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_ENCRYPTION_KEY must be base64 encoded: %w", err)
+		}
+		builder = builder.WithDirectEncryption(key)
+	case a.config.JWTEncryptionKeyPath != "":
+		builder = builder.WithRSAEncryption(a.config.JWTEncryptionKeyPath)
+	case a.config.JWTEncryptionMode != "" && a.config.JWTEncryptionMode != "encrypt":
+		return nil, fmt.Errorf("JWT_ENCRYPTION_MODE requires JWT_ENCRYPTION_KEY or JWT_ENCRYPTION_KEY_PATH")
+	default:
+		return builder, nil
+	}
+
+	switch a.config.JWTEncryptionMode {
+	case "", "encrypt":
+	case "decrypt_only":
+		builder = builder.WithEncryptionMigration(true, false)
+	case "required":
+		builder = builder.WithEncryptionMigration(false, true)
+	default:
+		return nil, fmt.Errorf("unknown JWT_ENCRYPTION_MODE %q", a.config.JWTEncryptionMode)
+	}
+	return builder, nil
+}
+
+// buildTokenStore opens the kind of store opaque token claims or the JWT
+// registry are kept in, as selected by the setting
+func (a *application) buildTokenStore(kind, setting string) (tokenstore.Service, error) {
+	switch kind {
+	case "", "memory":
+		return tokenStoreMemory.NewService(), nil
+	case "redis":
+		if a.redis == nil {
+			return nil, fmt.Errorf("REDIS_URL is required for %s=redis", setting)
+		}
+		return tokenStoreRedis.NewService(a.redis), nil
+	case "postgres":
+		if a.pool == nil {
+			return nil, fmt.Errorf("DATABASE_URL is required for %s=postgres", setting)
+		}
+		return tokenStorePostgres.NewService(a.pool), nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q", setting, kind)
+	}
+}
+
+// buildKeyStore opens the store rotated JWT signing keys are kept in
+func (a *application) buildKeyStore() (signingkey.Service, error) {
+	switch a.config.JWTKeyStore {
+	case "memory":
+		return signingKeyMemory.NewService(), nil
+	case "file":
+		return signingKeyFile.NewService(a.config.JWTKeyDir)
+	case "redis":
+		if a.redis == nil {
+			return nil, fmt.Errorf("REDIS_URL is required for the redis JWT key store")
+		}
+		return signingKeyRedis.NewService(a.redis), nil
+	case "postgres":
+		if a.pool == nil {
+			return nil, fmt.Errorf("DATABASE_URL is required for the postgres JWT key store")
+		}
+		return signingKeyPostgres.NewService(a.pool), nil
+	default:
+		return nil, fmt.Errorf("unknown JWT_KEY_STORE %q", a.config.JWTKeyStore)
+	}
+}
+
+func (a *application) buildServiceAccounts() (err error) {
+	config := serviceAccountFactory.DefaultConfig(a.token, a.audit)
+	switch a.config.ServiceAccountStore {
+	case "", "memory":
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for SERVICE_ACCOUNT_STORE=postgres")
+		}
+		config.Provider = "postgres"
+		config.Pool = a.pool
+	default:
+		return fmt.Errorf("unknown SERVICE_ACCOUNT_STORE %q", a.config.ServiceAccountStore)
+	}
+	a.serviceAccounts, err = serviceAccountFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildOAuthServer() (err error) {
+	config := oauthServerFactory.DefaultConfig(a.token, a.audit)
+	switch a.config.OAuthStore {
+	case "", "memory":
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for OAUTH_STORE=postgres")
+		}
+		config.Provider = "postgres"
+		config.Pool = a.pool
+	default:
+		return fmt.Errorf("unknown OAUTH_STORE %q", a.config.OAuthStore)
+	}
+	a.oauthServer, err = oauthServerFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildEvents() (err error) {
+	builder := eventsFactory.NewConfigBuilder().WithSerialization(a.config.EventsSerialization)
+	if a.config.EventsCompression != "" {
+		builder = builder.WithCompression(a.config.EventsCompression, a.config.EventsCompressionThreshold)
+	}
+	switch a.config.EventsProvider {
+	case "sns":
+		snsConfig := eventsSNS.DefaultConfig()
+		snsConfig.Endpoint = a.config.AWSEndpoint
+		snsConfig.TopicARN = a.config.SNSTopicARN
+		snsConfig.QueueURL = a.config.SQSQueueURL
+		snsConfig.DeadLetterQueueARN = a.config.SQSDeadLetter
+		builder = builder.WithSNSConfig(snsConfig)
+	case "pubsub":
+		builder = builder.WithPubSubConfig(eventsPubSub.Config{
+			ProjectID:         a.config.PubSubProject,
+			TopicID:           a.config.PubSubTopic,
+			SubscriptionID:    a.config.PubSubSubscription,
+			EnableOrdering:    true,
+			DeadLetterTopicID: a.config.PubSubDeadLetter,
+			EmulatorHost:      a.config.PubSubEmulatorHost,
+			CreateIfMissing:   a.config.PubSubEmulatorHost != "",
+		})
+	case "amqp", "rabbitmq":
+		amqpConfig := eventsAMQP.DefaultConfig()
+		amqpConfig.URL = a.config.AMQPURL
+		amqpConfig.Queue = a.config.AMQPQueue
+		amqpConfig.DeadLetter = a.config.AMQPDeadLetter
+		builder = builder.WithAMQPConfig(amqpConfig)
+	}
+	if a.deadLetters != nil {
+		builder = builder.WithDeadLetters(a.deadLetters)
+	}
+	if a.config.EventSchemas {
+		schemas, err := eventSchemaFactory.NewFactory(eventSchemaFactory.DefaultConfig()).Build()
+		if err != nil {
+			return err
+		}
+		builder = builder.WithSchemas(schemas)
+	}
+
+	a.events, err = eventsFactory.NewFactory(builder.Build()).Build()
+	return err
+}
+
+// buildEventHandlers subscribes the configured built-in handlers to the bus,
+// and the webhook delivery handler when webhooks are enabled
+func (a *application) buildEventHandlers() (err error) {
+	handlerIDs := a.config.EventHandlers
+	if a.webhooks != nil && !a.handlesEvents(webhookDelivery.HandlerID) {
+		handlerIDs = append(append([]string(nil), handlerIDs...), webhookDelivery.HandlerID)
+	}
+	registrations, err := eventHandlerFactory.BuiltinHandlers(handlerIDs, eventHandlerFactory.BuiltinDependencies{
+		NotificationService: a.notification,
+		AuditService:        a.audit,
+		WebhookService:      a.webhooks,
+	})
+	if err != nil {
+		return err
+	}
+	a.eventHandlers, err = eventHandlerFactory.Subscribe(context.Background(), a.events, registrations, a.deadLetters)
+	return err
+}
+
+// handlesEvents reports whether the built-in event handler is enabled
+func (a *application) handlesEvents(handlerID string) bool {
+	for _, id := range a.config.EventHandlers {
+		if id == handlerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *application) buildRealtime() error {
+	if a.events == nil {
+		return fmt.Errorf("events service is required")
+	}
+
+	a.realtime = newRealtimeHub()
+	return a.events.Subscribe(context.Background(), a.realtime.GetHandledEventTypes(), a.realtime)
+}
+
+func (a *application) buildStorage() (err error) {
+	config := storageFactory.Config{
+		Provider: a.config.StorageProvider,
+		Local:    storageLocal.Config{Root: a.config.StorageDir, BaseURL: mediaPath},
+		S3: storageS3.Config{
+			Endpoint:      a.config.AWSEndpoint,
+			UsePathStyle:  a.config.AWSEndpoint != "",
+			Bucket:        a.config.S3Bucket,
+			Prefix:        a.config.S3Prefix,
+			PublicBaseURL: a.config.S3PublicURL,
+		},
+	}
+
+	a.storage, err = storageFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildIdempotency() error {
+	switch a.config.IdempotencyStore {
+	case "", "memory":
+		a.idempotency = idempotencyMemory.NewService()
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("REDIS_URL is required for the redis idempotency store")
+		}
+		a.idempotency = idempotencyRedis.NewService(a.redis)
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for the postgres idempotency store")
+		}
+		a.idempotency = idempotencyPostgres.NewService(a.pool)
+	case "none":
+	default:
+		return fmt.Errorf("unknown IDEMPOTENCY_STORE %q", a.config.IdempotencyStore)
+	}
+	return nil
+}
+
+func (a *application) buildLockout() error {
+	switch a.config.LockoutStore {
+	case "", "memory":
+		a.lockout = lockoutMemory.NewService()
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("REDIS_URL is required for the redis lockout store")
+		}
+		a.lockout = lockoutRedis.NewService(a.redis)
+	case "none":
+	default:
+		return fmt.Errorf("unknown LOCKOUT_STORE %q", a.config.LockoutStore)
+	}
+	return nil
+}
+
+func (a *application) buildDevices() error {
+	switch a.config.DeviceStore {
+	case "", "memory":
+		a.devices = deviceMemory.NewService()
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("REDIS_URL is required for the redis device store")
+		}
+		a.devices = deviceRedis.NewService(a.redis)
+	case "none":
+	default:
+		return fmt.Errorf("unknown DEVICE_STORE %q", a.config.DeviceStore)
+	}
+	return nil
+}
+
+func (a *application) buildCaptcha() (err error) {
+	if a.config.CaptchaProvider == "none" {
+		return nil
+	}
+
+	a.captcha, err = captchaFactory.NewFactory(captchaFactory.Config{
+		Provider: a.config.CaptchaProvider,
+		SiteVerify: siteverify.Config{
+			Secret:   a.config.CaptchaSecret,
+			Hostname: a.config.CaptchaHostname,
+			MinScore: a.config.CaptchaMinScore,
+		},
+	}).Build()
+	if err != nil {
+		return err
+	}
+
+	switch a.config.CaptchaStore {
+	case "", "memory":
+		a.captchaFailures = throttleMemory.NewService()
+	case "redis":
+		if a.redis == nil {
+			return fmt.Errorf("REDIS_URL is required for the redis captcha store")
+		}
+		a.captchaFailures = throttleRedis.NewServiceWithPrefix(a.redis, "captcha:")
+	default:
+		return fmt.Errorf("unknown CAPTCHA_STORE %q", a.config.CaptchaStore)
+	}
+	return nil
+}
+
+func (a *application) buildUser() (err error) {
+	newConfig := userFactory.NewDefaultConfig
+	if a.config.Production {
+		newConfig = userFactory.NewProductionConfig
+	}
+
+	cfg := newConfig(
+		a.db,
+		a.redis,
+		a.audit,
+		a.encryption,
+		a.rateLimit,
+		a.validation,
+		a.notification,
+		a.token,
+		a.publisher,
+	)
+	cfg.StorageProvider = a.config.UserStorage
+	cfg.NotificationHistory = a.history
+	if a.config.InputSanitization {
+		cfg.Sanitizer = sanitizeStandard.NewService(sanitizeStandard.UserFields())
+	}
+	cfg.Pool = a.pool
+	cfg.Features.EnableTransactions = a.transactionalOutbox()
+	cfg.CacheTTL = a.config.CacheTTL
+	cfg.UserCacheTTL = a.config.UserCacheTTL
+	cfg.PreferencesCacheTTL = a.config.PrefsCacheTTL
+	cfg.FeatureFlagsCacheTTL = a.config.FlagsCacheTTL
+	cfg.ListCacheTTL = a.config.ListCacheTTL
+	cfg.NotFoundCacheTTL = a.config.NotFoundCacheTTL
+	cfg.DisableCacheWriteThrough = !a.config.CacheWriteThrough
+	cfg.DisableCacheCoalescing = !a.config.CacheCoalescing
+	cfg.AvatarStorage = a.storage
+	cfg.PasswordHasher = a.hasher
+	cfg.AvatarURLExpiry = a.config.AvatarURLExpiry
+	cfg.CacheProvider = a.config.CacheProvider
+	if cfg.CacheProvider == "" && a.redis == nil {
+		cfg.CacheProvider = "none"
+	}
+	cfg.MemoryCacheSize = a.config.MemoryCacheSize
+	cfg.L1CacheTTL = a.config.L1CacheTTL
+	cfg.Features.EnableCache = cfg.CacheProvider != "none"
+	cfg.IdempotencyService = a.idempotency
+	cfg.Idempotency.TTL = a.config.IdempotencyTTL
+	cfg.Features.EnableIdempotency = a.idempotency != nil
+	cfg.LockoutService = a.lockout
+	cfg.Lockout = userLockout.Config{
+		MaxAttempts:      a.config.LockoutMaxAttempts,
+		MaxAttemptsPerIP: a.config.LockoutMaxAttemptsPerIP,
+		Window:           a.config.LockoutWindow,
+		CoolDown:         a.config.LockoutCoolDown,
+	}
+	cfg.Features.EnableLockout = a.lockout != nil
+	cfg.DeviceService = a.devices
+	cfg.Features.EnableDeviceAlerts = a.devices != nil
+	cfg.CaptchaService = a.captcha
+	cfg.CaptchaFailures = a.captchaFailures
+	cfg.Captcha.LoginFailures = a.config.CaptchaLoginFailures
+	cfg.Features.EnableCaptcha = a.captcha != nil
+	cfg.StepUp.MaxAge = a.config.StepUpMaxAge
+	cfg.Features.EnableStepUp = a.config.StepUpMaxAge > 0
+	cfg.EmailVerificationGracePeriod = a.config.EmailVerificationGracePeriod
+	cfg.DisableWelcomeEmail = a.handlesEvents(welcomeemail.HandlerID)
+
+	a.piiEncrypted = cfg.Features.EnableEncryption
+
+	factory := userFactory.NewUserServiceFactory(cfg)
+	a.users, err = factory.Build()
+	a.unlocker = factory.Lockout()
+	return err
+}
+
+func (a *application) buildUserViews() error {
+	// Users carry no organization yet, so organization admins see public views
+	a.views = userViewStandard.NewService(userViewStandard.Config{})
+	return nil
+}
+
+func (a *application) buildProfiling() (err error) {
+	config := profilingFactory.DefaultConfig(a.validation)
+	switch a.config.ProfilingStore {
+	case "", "memory":
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for PROFILING_STORE=postgres")
+		}
+		config.Provider = "postgres"
+		config.Pool = a.pool
+	default:
+		return fmt.Errorf("unknown PROFILING_STORE %q", a.config.ProfilingStore)
+	}
+	a.profiling, err = profilingFactory.NewFactory(config).Build()
+	return err
+}
+
+func (a *application) buildAuth() (err error) {
+	secret := []byte(a.config.JWTSecret)
+	if len(secret) == 0 {
+		return fmt.Errorf("JWT_SECRET is required")
+	}
+
+	config := authFactory.NewDefaultConfig(secret, a.users)
+	config.TokenService = a.token
+	config.RevocationStore = a.revocations
+	config.Features.EnableAPIKeyAuth = true
+	config.AuditService = a.audit
+	config.EventsService = a.publisher
+	config.Features.EnableAudit = true
+	config.Features.EnableMetrics = a.config.Production
+	if a.config.SAML.IsConfigured() {
+		config.SAML = a.config.SAML
+		config.Features.EnableSAML = true
+	}
+	if a.config.MagicLinkTTL > 0 {
+		config.MagicLink = authUsecase.MagicLinkConfig{TTL: a.config.MagicLinkTTL}
+		config.Features.EnableMagicLink = true
+		a.magicLinks, err = authUsecase.NewMagicLinkSenderWithLimits(a.users, a.token, a.notification, a.rateLimit, config.MagicLink)
+		if err != nil {
+			return err
+		}
+	}
+	if a.auth, err = authFactory.NewAuthServiceFactory(config).Build(); err != nil {
+		return err
+	}
+
+	// Requests are authorized with API keys without a user lookup, as service
+	// accounts authenticate with API tokens too
+	a.apiKeys = authUsecase.NewAPIKeyAuthStrategy(a.users, a.token)
+	return nil
+}
+
+func (a *application) buildDeprecations() (err error) {
+	a.deprecations = deprecatedAPI
+	a.deprecatedUsage, err = newDeprecatedUsage(prometheus.DefaultRegisterer)
+	return err
+}
+
+// pingDatabase checks database connectivity
+func (a *application) pingDatabase(ctx context.Context) error {
+	if a.db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// pingRedis checks cache connectivity
+func (a *application) pingRedis(ctx context.Context) error {
+	if a.redis == nil {
+		return fmt.Errorf("redis is not configured")
+	}
+	return a.redis.Ping(ctx).Err()
+}
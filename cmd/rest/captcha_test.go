@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	captchaMock "github.com/gentra/decorator-arch-go/internal/captcha/mock"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
+	userCaptcha "github.com/gentra/decorator-arch-go/internal/user/captcha"
+)
+
+func TestRegister_GivenCaptchaHeader_WhenRegistering_ThenVerifiesCaptcha(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        string
+		verifyErr    error
+		expected     int
+		expectedCode string
+	}{
+		{name: "Given a solved captcha, When registering, Then creates the user", token: "solved", expected: http.StatusCreated},
+		{name: "Given no captcha, When registering, Then asks for one", expected: http.StatusBadRequest, expectedCode: captcha.ErrCaptchaRequired.Code},
+		{name: "Given a rejected captcha, When registering, Then refuses it", token: "forged", verifyErr: captcha.ErrCaptchaInvalid, expected: http.StatusBadRequest, expectedCode: captcha.ErrCaptchaInvalid.Code},
+		{name: "Given the provider is down, When registering, Then is unavailable", token: "solved", verifyErr: captcha.ErrCaptchaUnavailable, expected: http.StatusServiceUnavailable, expectedCode: captcha.ErrCaptchaUnavailable.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _, users := newAdminTestApp(t)
+			users.On("Register", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+			verifier := &captchaMock.MockCaptchaService{}
+			verifier.On("Verify", mock.Anything, tt.token, mock.Anything).Return(tt.verifyErr)
+			app.users = userCaptcha.NewService(users, verifier, throttleMemory.NewService(), userCaptcha.DefaultConfig())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"jane@example.com","password":"Password123!","first_name":"Jane","last_name":"Doe"}`))
+			if tt.token != "" {
+				req.Header.Set(captchaTokenHeader, tt.token)
+			}
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expectedCode != "" {
+				assert.Contains(t, rec.Body.String(), tt.expectedCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// preferenceCleanupSubject is the identity scheduled cleanup runs are authorized and audited as
+var preferenceCleanupSubject = authorization.Subject{
+	ID:    "system:preference-cleanup",
+	Roles: []string{authorization.RoleAdmin},
+}
+
+// runPreferenceCleanup removes stale notification types from stored
+// preferences every interval until the context is cancelled
+func (a *application) runPreferenceCleanup(ctx context.Context, interval time.Duration, strip bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.cleanupPreferences(ctx, strip)
+		}
+	}
+}
+
+// cleanupPreferences runs a single scheduled cleanup and logs its report
+func (a *application) cleanupPreferences(ctx context.Context, strip bool) {
+	ctx = authorization.WithSubject(ctx, preferenceCleanupSubject)
+	ctx = audit.WithAuditContext(ctx, preferenceCleanupSubject.ID, "", "", "")
+
+	report, err := a.users.CleanupPreferences(ctx, user.PreferenceCleanupOptions{Strip: strip})
+	if err != nil {
+		log.Printf("Preference cleanup failed: %v", err)
+		return
+	}
+	log.Printf("Preference cleanup scanned %d preferences, %d held unknown notification types %v (stripped: %t)",
+		report.Scanned, len(report.AffectedUsers), report.UnknownKeys, report.Stripped)
+}
+
+// runRevocationCleanup drops expired revocations from the revocation store
+// every interval until the context is cancelled
+func (a *application) runRevocationCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.revocations.Cleanup(ctx); err != nil {
+				log.Printf("Revocation cleanup failed: %v", err)
+			}
+		}
+	}
+}
+
+// handleCleanupPreferences reports stale notification types and, with ?strip=true, removes them
+func (a *application) handleCleanupPreferences(w http.ResponseWriter, r *http.Request) {
+	var opts user.PreferenceCleanupOptions
+	if raw := r.URL.Query().Get("strip"); raw != "" {
+		strip, err := strconv.ParseBool(raw)
+		if err != nil {
+			badRequest(w, "strip must be a boolean")
+			return
+		}
+		opts.Strip = strip
+	}
+
+	report, err := a.users.CleanupPreferences(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
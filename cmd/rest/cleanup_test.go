@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func TestCleanupPreferences_GivenStripQuery_WhenAdminCalls_ThenStripsAndReturnsReport(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("CleanupPreferences", mock.Anything, user.PreferenceCleanupOptions{Strip: true}).Return(&user.PreferenceCleanupReport{
+		Scanned:       3,
+		UnknownKeys:   map[string]int{"legacy_digest": 1},
+		AffectedUsers: []string{"user-1"},
+		Stripped:      true,
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/preferences/cleanup?strip=true", ""))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"scanned":3,"unknown_keys":{"legacy_digest":1},"affected_users":["user-1"],"stripped":true}`, rec.Body.String())
+	users.AssertExpectations(t)
+}
+
+func TestCleanupPreferences_GivenInvalidStripQuery_WhenAdminCalls_ThenReturnsBadRequest(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/preferences/cleanup?strip=maybe", ""))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "CleanupPreferences", mock.Anything, mock.Anything)
+}
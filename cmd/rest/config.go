@@ -0,0 +1,573 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+// config contains the runtime configuration of the REST server, read from the environment
+type config struct {
+	Addr          string
+	DatabaseURL   string
+	RedisURL      string
+	JWTSecret     string
+	EncryptionKey string
+	CacheTTL      time.Duration
+	UserCacheTTL  time.Duration
+	PrefsCacheTTL time.Duration
+	FlagsCacheTTL time.Duration
+	ListCacheTTL  time.Duration
+	Production    bool
+
+	// JWTAlgorithm selects how tokens are signed: "HS256" (default) with
+	// JWTSecret, or "RS256" or "ES256" with the PEM key pair at
+	// JWTPrivateKeyPath and JWTPublicKeyPath. After a key rollover the
+	// previous public key at JWTRetiredKeyPath, replaced at JWTKeyRotatedAt,
+	// stays valid and published at /.well-known/jwks.json until the tokens
+	// it signed expire.
+	JWTAlgorithm      string
+	JWTPrivateKeyPath string
+	JWTPublicKeyPath  string
+	JWTRetiredKeyPath string
+	JWTKeyRotatedAt   string
+
+	// JWTKeyStore turns on automatic rotation of RS256 or ES256 keys, which
+	// replaces the key files: a key is generated every JWTRotationInterval
+	// and kept in "memory", "file" (under JWTKeyDir), "postgres" or "redis",
+	// the latter two shared by every instance. Empty keeps the key files.
+	JWTKeyStore         string
+	JWTKeyDir           string
+	JWTRotationInterval time.Duration
+
+	// JWTEncryptionKey, a base64 256-bit key, or JWTEncryptionKeyPath, a
+	// PEM RSA key pair, encrypt JWTs so clients cannot read their claims.
+	// JWTEncryptionMode is "encrypt" (default), "decrypt_only" while every
+	// instance is given the key, or "required" once the signed tokens issued
+	// before encryption have expired.
+	JWTEncryptionKey     string
+	JWTEncryptionKeyPath string
+	JWTEncryptionMode    string
+
+	// TokenProvider selects the tokens issued: "jwt" (default) or "opaque"
+	// random handles whose claims are kept in TokenStore: "memory"
+	// (default), "redis" or "postgres". Opaque tokens are checked against
+	// the store on every request and revoked everywhere at once.
+	TokenProvider string
+	TokenStore    string
+
+	// TokenRegistry records the JWTs issued so users can list their active
+	// tokens, of which they keep at most MaxActiveTokens (10 by default):
+	// "memory" (default), "redis", "postgres" or "none"
+	TokenRegistry   string
+	MaxActiveTokens int
+
+	// TokenClockSkew is how far the clocks of the instances issuing and
+	// validating tokens may disagree (30s by default)
+	TokenClockSkew time.Duration
+
+	// RevocationStore records revoked JWTs and users: "memory" (default)
+	// or "redis", which every instance shares and which survives restarts.
+	// RevocationCleanupInterval schedules dropping expired revocations from
+	// the store; zero disables it.
+	RevocationStore           string
+	RevocationCleanupInterval time.Duration
+
+	// UserStorage selects how users are stored: "gorm" (default),
+	// "postgres" for the pgx repository on its own connection pool, or
+	// "sqlite" for a single-binary demo without Postgres kept at SQLitePath
+	UserStorage string
+	SQLitePath  string
+
+	// NotFoundCacheTTL is how long missing user IDs are remembered; zero
+	// disables negative caching. CacheWriteThrough caches users returned
+	// by writes instead of invalidating them, and CacheCoalescing shares
+	// one load between concurrent misses for the same user.
+	NotFoundCacheTTL  time.Duration
+	CacheWriteThrough bool
+	CacheCoalescing   bool
+
+	// CacheProvider selects the user cache: "redis", "memory", "tiered" or
+	// "none". When empty Redis is used if REDIS_URL is set and caching is off
+	// otherwise. L1CacheTTL bounds the in-process tier of "tiered".
+	CacheProvider   string
+	MemoryCacheSize int
+	L1CacheTTL      time.Duration
+
+	// IdempotencyStore keeps the results replayed for requests carrying an
+	// Idempotency-Key header: "memory" (default), "redis", "postgres" or
+	// "none". Results are replayed for IdempotencyTTL, zero uses 24 hours.
+	IdempotencyStore string
+	IdempotencyTTL   time.Duration
+
+	// LockoutStore counts failed logins: "memory" (default), "redis" or
+	// "none". An email is locked after LockoutMaxAttempts failures and an IP
+	// after LockoutMaxAttemptsPerIP within LockoutWindow, for LockoutCoolDown;
+	// zero values use the lockout defaults.
+	LockoutStore            string
+	LockoutMaxAttempts      int
+	LockoutMaxAttemptsPerIP int
+	LockoutWindow           time.Duration
+	LockoutCoolDown         time.Duration
+
+	// DeviceStore remembers the devices users signed in from, so they are
+	// emailed about sign-ins from unrecognized ones: "memory" (default),
+	// "redis" or "none", which disables the alerts
+	DeviceStore string
+
+	// CaptchaProvider checks captchas on registration and on logins after
+	// CaptchaLoginFailures failed ones per email or IP (3 by default):
+	// "recaptcha", "hcaptcha", "turnstile", "noop" or "none" (default), which
+	// disables them. Clients send the solved token in X-Captcha-Token.
+	// CaptchaMinScore applies to reCAPTCHA v3, CaptchaHostname, when set,
+	// must match the site the captcha was solved on, and CaptchaStore counts
+	// the failed logins: "memory" (default) or "redis".
+	CaptchaProvider      string
+	CaptchaSecret        string
+	CaptchaHostname      string
+	CaptchaMinScore      float64
+	CaptchaLoginFailures int
+	CaptchaStore         string
+
+	// StepUpMaxAge is how recently users must have signed in to change their
+	// password or email or erase their account; older sessions get a 401
+	// asking them to sign in again. Zero disables the check.
+	StepUpMaxAge time.Duration
+
+	// MagicLinkTTL is how long the one-time sign-in tokens emailed by
+	// /api/auth/magic-link stay valid. Zero disables magic links.
+	MagicLinkTTL time.Duration
+
+	// EmailVerificationGracePeriod is how long after registering users can
+	// sign in without verifying their email; later logins get a 403 and a
+	// fresh verification email. Zero never blocks unverified users.
+	EmailVerificationGracePeriod time.Duration
+
+	// DefaultLanguage renders validation messages when none of the languages
+	// in a request's Accept-Language header has them; TranslationDir adds
+	// JSON translation files, one per language, to the built-in ones
+	DefaultLanguage string
+	TranslationDir  string
+
+	// ValidationRuleDir holds YAML or JSON rule sets declaring validation
+	// rules, such as a password policy or name lengths, applied to the
+	// fields they name on top of the built-in checks
+	ValidationRuleDir string
+
+	// ValidationRuleStore keeps the validation rules administrators manage
+	// at runtime through /api/admin/validation-rules: "memory" or
+	// "postgres"; empty disables the API. Rules in ValidationRuleDir seed
+	// the store instead of being applied directly, and every instance
+	// reloads the stored rules each ValidationRuleReloadInterval so changes
+	// made on another instance take effect.
+	ValidationRuleStore          string
+	ValidationRuleReloadInterval time.Duration
+
+	// InputSanitization cleans user input before it is validated: names
+	// and emails are trimmed and stripped of control characters, emails
+	// brought to Unicode NFC and time zones spelled canonically. The fields
+	// changed are recorded in the audit trail.
+	InputSanitization bool
+
+	// PayloadSchemas checks request bodies against the JSON Schema of their
+	// endpoint before they reach the handlers; failures name the offending
+	// fields with JSON pointers. SchemaDir adds or replaces schemas with the
+	// .json files it holds, named after the endpoint's schema.
+	PayloadSchemas bool
+	SchemaDir      string
+
+	// EmailDeliverabilityCheck rejects email addresses whose domain does not
+	// accept mail, checked in DNS; BreachedPasswordCheck rejects passwords
+	// found in data breaches, checked with the Pwned Passwords API. Their
+	// calls are bounded by RemoteValidation's timeout, cached and skipped
+	// while failing; values they cannot check are accepted unless its
+	// fallback is "reject". Offline never makes the calls.
+	EmailDeliverabilityCheck bool
+	BreachedPasswordCheck    bool
+	RemoteValidation         remote.Config
+
+	// AdminUserIDs may call /api/admin/* endpoints
+	AdminUserIDs []string
+
+	// PasswordHashAlgorithm hashes new passwords: "bcrypt" (default) or
+	// "argon2id". Hashes of the other algorithm or older parameters keep
+	// working and are replaced on the user's next login. Zero costs use the
+	// algorithm defaults; Argon2Memory is in KiB.
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2Memory          int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+
+	// SAML enables single sign-on with a SAML 2.0 identity provider when its
+	// SSO URL is set; responses are posted to /api/auth/saml/acs
+	SAML saml.Config
+
+	// PrefsCleanupInterval schedules the stale preference cleanup; zero
+	// disables it. Without PrefsCleanupStrip runs only report unknown keys.
+	PrefsCleanupInterval time.Duration
+	PrefsCleanupStrip    bool
+
+	// NotificationDryRun captures every notification in the outbox instead of
+	// delivering it, so staging environments never message real users
+	NotificationDryRun bool
+
+	// NotificationDryRunHeader lets any caller, not only administrators,
+	// capture a request's notifications with X-Notification-Dry-Run; for
+	// development and test environments, it is ignored in production
+	NotificationDryRunHeader bool
+
+	// NotificationHistoryStore records the notifications sent, "memory"
+	// (default) or "postgres", with the delivery status providers report
+	NotificationHistoryStore string
+
+	// NotificationTemplateStore keeps the template versions administrators
+	// save over the shipped ones, "memory" (default) or "postgres";
+	// NotificationTemplateDir holds YAML template files replacing or adding
+	// to the shipped ones
+	NotificationTemplateStore string
+	NotificationTemplateDir   string
+
+	// InboxStore keeps the users' in-app notifications, "memory" (default)
+	// or "postgres"
+	InboxStore string
+
+	// NotificationScheduleStore queues the notifications scheduled for
+	// later, "memory" (default) or "postgres". Every
+	// NotificationSchedulerInterval the worker leases up to
+	// NotificationSchedulerBatch due ones for NotificationSchedulerLease and
+	// sends them; a zero interval disables the worker.
+	NotificationScheduleStore     string
+	NotificationSchedulerInterval time.Duration
+	NotificationSchedulerBatch    int
+	NotificationSchedulerLease    time.Duration
+
+	// NotificationDigestStore holds the notifications users collect in
+	// digests, "memory" (default) or "postgres". Every
+	// NotificationDigestInterval up to NotificationDigestBatch due digests
+	// are sent; a zero interval disables sending them.
+	NotificationDigestStore    string
+	NotificationDigestInterval time.Duration
+	NotificationDigestBatch    int
+
+	// SMSProvider sends SMS: mock (default) or twilio, with the Twilio
+	// account below. Twilio posts delivery reports to
+	// TwilioStatusCallbackURL, the public URL of /api/notifications/sms/status
+	SMSProvider             string
+	TwilioAccountSID        string
+	TwilioAuthToken         string
+	TwilioFromNumber        string
+	TwilioStatusCallbackURL string
+
+	// EventsProvider selects the event bus: memory (default), sns, pubsub
+	// or amqp
+	EventsProvider string
+	SNSTopicARN    string
+	SQSQueueURL    string
+	SQSDeadLetter  string // ARN of the dead-letter queue for failed deliveries
+	AWSEndpoint    string // LocalStack or other emulator endpoint
+
+	// EventsSerialization is the wire format of the sns, pubsub and amqp
+	// providers: json (default), cloudevents or protobuf. EventsCompression,
+	// gzip or zstd, compresses their bodies from EventsCompressionThreshold
+	// bytes; empty sends them uncompressed
+	EventsSerialization        string
+	EventsCompression          string
+	EventsCompressionThreshold int
+
+	PubSubProject      string
+	PubSubTopic        string
+	PubSubSubscription string
+	PubSubDeadLetter   string // Topic ID for failed deliveries
+	PubSubEmulatorHost string
+
+	// AMQPURL is the RabbitMQ or other AMQP 0-9-1 broker; AMQPQueue prefixes
+	// the queues consumed per domain, and AMQPDeadLetter routes the events
+	// handlers keep failing to a dead-letter queue beside each of them
+	AMQPURL        string
+	AMQPQueue      string
+	AMQPDeadLetter bool
+
+	// EventOutbox records the events of the user and auth services in an
+	// outbox, "memory" or "postgres", instead of publishing them directly;
+	// empty disables it. Every EventRelayInterval up to EventRelayBatch of
+	// them are relayed to the event bus, and relayed ones are dropped after
+	// EventOutboxRetention. With "postgres" and the postgres user storage,
+	// user changes and their events commit in one transaction.
+	EventOutbox          string
+	EventRelayInterval   time.Duration
+	EventRelayBatch      int
+	EventOutboxRetention time.Duration
+
+	// DeadLetterStore keeps the events the memory and AMQP providers'
+	// handlers give up on, "memory" or "postgres", for administrators to
+	// re-drive or discard under /api/admin/dead-letters; empty leaves them
+	// to the provider, which drops them or uses the broker's dead-letter queue
+	DeadLetterStore string
+
+	// EventHandlers lists the built-in event handlers consuming the events
+	// pipeline: "welcome-email" sends the welcome email on registration in
+	// place of the user service, and "audit-projection" records logins and
+	// password changes in the audit log
+	EventHandlers []string
+
+	// EventSchemas checks published events against the payload contracts of
+	// their type and version, and upcasts events of older versions read
+	// back or delivered; on unless set to false
+	EventSchemas bool
+
+	// WebhookStore keeps the external endpoints events are forwarded to,
+	// "memory" or "postgres", managed under /api/admin/webhooks; setting it
+	// subscribes the "webhooks" handler to the bus. Empty disables webhooks.
+	WebhookStore string
+
+	// ServiceAccountStore keeps the organizations' service accounts,
+	// "memory" (default) or "postgres"
+	ServiceAccountStore string
+
+	// OAuthStore keeps the registered OAuth clients and the authorization
+	// codes issued to them, "memory" (default) or "postgres". In memory a
+	// code can only be exchanged at the instance that issued it.
+	OAuthStore string
+
+	// ProfilingStore keeps the users' answers to the progressive profiling
+	// prompts, "memory" (default) or "postgres"
+	ProfilingStore string
+
+	// StorageProvider selects where avatar images are kept: local (default),
+	// served by this server under /media, or s3
+	StorageProvider string
+	StorageDir      string
+	S3Bucket        string
+	S3Prefix        string
+	S3PublicURL     string        // Public bucket or CDN URL; links are presigned without it
+	AvatarURLExpiry time.Duration // Lifetime of presigned avatar links
+}
+
+// loadConfig reads the server configuration from environment variables
+func loadConfig() config {
+	return config{
+		Addr:          envOr("HTTP_ADDR", ":8080"),
+		DatabaseURL:   os.Getenv("DATABASE_URL"),
+		RedisURL:      os.Getenv("REDIS_URL"),
+		JWTSecret:     os.Getenv("JWT_SECRET"),
+		EncryptionKey: os.Getenv("ENCRYPTION_KEY"),
+		CacheTTL:      envDuration("CACHE_TTL", 5*time.Minute),
+		UserCacheTTL:  envDuration("USER_CACHE_TTL", 0),
+		PrefsCacheTTL: envDuration("PREFERENCES_CACHE_TTL", 0),
+		FlagsCacheTTL: envDuration("FEATURE_FLAGS_CACHE_TTL", 0),
+		ListCacheTTL:  envDuration("LIST_CACHE_TTL", 0),
+		Production:    os.Getenv("APP_ENV") == "production",
+		AdminUserIDs:  envList("ADMIN_USER_IDS"),
+		UserStorage:   os.Getenv("USER_STORAGE"),
+		SQLitePath:    envOr("SQLITE_PATH", "decorator-arch.db"),
+
+		JWTAlgorithm:      envOr("JWT_ALGORITHM", token.AlgorithmHS256),
+		JWTPrivateKeyPath: os.Getenv("JWT_PRIVATE_KEY_PATH"),
+		JWTPublicKeyPath:  os.Getenv("JWT_PUBLIC_KEY_PATH"),
+		JWTRetiredKeyPath: os.Getenv("JWT_RETIRED_KEY_PATH"),
+		JWTKeyRotatedAt:   os.Getenv("JWT_KEY_ROTATED_AT"),
+
+		JWTKeyStore:         os.Getenv("JWT_KEY_STORE"),
+		JWTKeyDir:           envOr("JWT_KEY_DIR", "keys"),
+		JWTRotationInterval: envDuration("JWT_ROTATION_INTERVAL", 30*24*time.Hour),
+
+		JWTEncryptionKey:     os.Getenv("JWT_ENCRYPTION_KEY"),
+		JWTEncryptionKeyPath: os.Getenv("JWT_ENCRYPTION_KEY_PATH"),
+		JWTEncryptionMode:    envOr("JWT_ENCRYPTION_MODE", "encrypt"),
+
+		TokenProvider: envOr("TOKEN_PROVIDER", "jwt"),
+		TokenStore:    envOr("TOKEN_STORE", "memory"),
+
+		TokenRegistry:   envOr("TOKEN_REGISTRY", "memory"),
+		MaxActiveTokens: envInt("MAX_ACTIVE_TOKENS", 10),
+		TokenClockSkew:  envDuration("TOKEN_CLOCK_SKEW", token.DefaultClockSkew),
+
+		RevocationStore:           envOr("REVOCATION_STORE", "memory"),
+		RevocationCleanupInterval: envDuration("REVOCATION_CLEANUP_INTERVAL", 10*time.Minute),
+
+		NotFoundCacheTTL:  envDuration("NOT_FOUND_CACHE_TTL", 30*time.Second),
+		CacheWriteThrough: os.Getenv("CACHE_WRITE_THROUGH") != "false",
+		CacheCoalescing:   os.Getenv("CACHE_COALESCING") != "false",
+		CacheProvider:     os.Getenv("CACHE_PROVIDER"),
+		MemoryCacheSize:   envInt("MEMORY_CACHE_SIZE", 0),
+		L1CacheTTL:        envDuration("L1_CACHE_TTL", 0),
+
+		IdempotencyStore: envOr("IDEMPOTENCY_STORE", "memory"),
+		IdempotencyTTL:   envDuration("IDEMPOTENCY_TTL", 0),
+
+		LockoutStore:            envOr("LOCKOUT_STORE", "memory"),
+		LockoutMaxAttempts:      envInt("LOCKOUT_MAX_ATTEMPTS", 0),
+		LockoutMaxAttemptsPerIP: envInt("LOCKOUT_MAX_ATTEMPTS_PER_IP", 0),
+		LockoutWindow:           envDuration("LOCKOUT_WINDOW", 0),
+		LockoutCoolDown:         envDuration("LOCKOUT_COOLDOWN", 0),
+
+		DeviceStore: envOr("DEVICE_STORE", "memory"),
+
+		CaptchaProvider:      envOr("CAPTCHA_PROVIDER", "none"),
+		CaptchaSecret:        os.Getenv("CAPTCHA_SECRET"),
+		CaptchaHostname:      os.Getenv("CAPTCHA_HOSTNAME"),
+		CaptchaMinScore:      envFloat("CAPTCHA_MIN_SCORE", 0),
+		CaptchaLoginFailures: envInt("CAPTCHA_LOGIN_FAILURES", 0),
+		CaptchaStore:         envOr("CAPTCHA_STORE", "memory"),
+
+		StepUpMaxAge: envDuration("STEP_UP_MAX_AGE", 15*time.Minute),
+
+		MagicLinkTTL: envDuration("MAGIC_LINK_TTL", 15*time.Minute),
+
+		EmailVerificationGracePeriod: envDuration("EMAIL_VERIFICATION_GRACE_PERIOD", 0),
+
+		DefaultLanguage: envOr("DEFAULT_LANGUAGE", translator.DefaultLanguage),
+		TranslationDir:  os.Getenv("TRANSLATION_DIR"),
+
+		ValidationRuleDir:            os.Getenv("VALIDATION_RULE_DIR"),
+		ValidationRuleStore:          os.Getenv("VALIDATION_RULE_STORE"),
+		ValidationRuleReloadInterval: envDuration("VALIDATION_RULE_RELOAD_INTERVAL", 30*time.Second),
+
+		InputSanitization: os.Getenv("INPUT_SANITIZATION") != "false",
+
+		PayloadSchemas: os.Getenv("PAYLOAD_SCHEMAS") != "false",
+		SchemaDir:      os.Getenv("SCHEMA_DIR"),
+
+		EmailDeliverabilityCheck: os.Getenv("EMAIL_DELIVERABILITY_CHECK") == "true",
+		BreachedPasswordCheck:    os.Getenv("BREACHED_PASSWORD_CHECK") == "true",
+		RemoteValidation: remote.Config{
+			Timeout:  envDuration("REMOTE_VALIDATION_TIMEOUT", 2*time.Second),
+			Fallback: remote.Fallback(envOr("REMOTE_VALIDATION_FALLBACK", string(remote.FallbackAccept))),
+			Offline:  os.Getenv("REMOTE_VALIDATION_OFFLINE") == "true",
+		},
+
+		PasswordHashAlgorithm: envOr("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+		BcryptCost:            envInt("BCRYPT_COST", 0),
+		Argon2Memory:          envInt("ARGON2_MEMORY_KIB", 0),
+		Argon2Iterations:      envInt("ARGON2_ITERATIONS", 0),
+		Argon2Parallelism:     envInt("ARGON2_PARALLELISM", 0),
+
+		SAML: saml.Config{
+			EntityID:          os.Getenv("SAML_ENTITY_ID"),
+			ACSURL:            os.Getenv("SAML_ACS_URL"),
+			IdPEntityID:       os.Getenv("SAML_IDP_ENTITY_ID"),
+			IdPSSOURL:         os.Getenv("SAML_IDP_SSO_URL"),
+			IdPCertificate:    os.Getenv("SAML_IDP_CERTIFICATE"),
+			AllowIdPInitiated: os.Getenv("SAML_ALLOW_IDP_INITIATED") == "true",
+		},
+
+		PrefsCleanupInterval: envDuration("PREFERENCE_CLEANUP_INTERVAL", 0),
+		PrefsCleanupStrip:    os.Getenv("PREFERENCE_CLEANUP_STRIP") == "true",
+
+		NotificationDryRun:        os.Getenv("NOTIFICATION_DRY_RUN") == "true",
+		NotificationDryRunHeader:  os.Getenv("NOTIFICATION_DRY_RUN_HEADER") == "true",
+		NotificationHistoryStore:  envOr("NOTIFICATION_HISTORY_STORE", "memory"),
+		NotificationTemplateStore: envOr("NOTIFICATION_TEMPLATE_STORE", "memory"),
+		NotificationTemplateDir:   os.Getenv("NOTIFICATION_TEMPLATE_DIR"),
+		SMSProvider:               envOr("SMS_PROVIDER", "mock"),
+		TwilioAccountSID:          os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:           os.Getenv("TWILIO_AUTH_TOKEN"),
+		TwilioFromNumber:          os.Getenv("TWILIO_FROM_NUMBER"),
+		TwilioStatusCallbackURL:   os.Getenv("TWILIO_STATUS_CALLBACK_URL"),
+
+		InboxStore: envOr("INBOX_STORE", "memory"),
+
+		NotificationScheduleStore:     envOr("NOTIFICATION_SCHEDULE_STORE", "memory"),
+		NotificationSchedulerInterval: envDuration("NOTIFICATION_SCHEDULER_INTERVAL", 30*time.Second),
+		NotificationSchedulerBatch:    envInt("NOTIFICATION_SCHEDULER_BATCH", notificationschedule.DefaultDeliveryLimit),
+		NotificationSchedulerLease:    envDuration("NOTIFICATION_SCHEDULER_LEASE", notificationschedule.DefaultLease),
+
+		NotificationDigestStore:    envOr("NOTIFICATION_DIGEST_STORE", "memory"),
+		NotificationDigestInterval: envDuration("NOTIFICATION_DIGEST_INTERVAL", time.Minute),
+		NotificationDigestBatch:    envInt("NOTIFICATION_DIGEST_BATCH", notificationdigest.DefaultDeliveryLimit),
+
+		EventsProvider:             envOr("EVENTS_PROVIDER", "memory"),
+		EventsSerialization:        envOr("EVENTS_SERIALIZATION", "json"),
+		EventsCompression:          os.Getenv("EVENTS_COMPRESSION"),
+		EventsCompressionThreshold: envInt("EVENTS_COMPRESSION_THRESHOLD", 1024),
+		SNSTopicARN:                os.Getenv("SNS_TOPIC_ARN"),
+		SQSQueueURL:                os.Getenv("SQS_QUEUE_URL"),
+		SQSDeadLetter:              os.Getenv("SQS_DEAD_LETTER_QUEUE_ARN"),
+		AWSEndpoint:                os.Getenv("AWS_ENDPOINT_URL"),
+
+		PubSubProject:      os.Getenv("PUBSUB_PROJECT_ID"),
+		PubSubTopic:        os.Getenv("PUBSUB_TOPIC"),
+		PubSubSubscription: os.Getenv("PUBSUB_SUBSCRIPTION"),
+		PubSubDeadLetter:   os.Getenv("PUBSUB_DEAD_LETTER_TOPIC"),
+		PubSubEmulatorHost: os.Getenv("PUBSUB_EMULATOR_HOST"),
+
+		AMQPURL:        os.Getenv("AMQP_URL"),
+		AMQPQueue:      envOr("AMQP_QUEUE", "decorator-arch"),
+		AMQPDeadLetter: os.Getenv("AMQP_DEAD_LETTER") != "false",
+
+		EventOutbox:          os.Getenv("EVENT_OUTBOX"),
+		EventRelayInterval:   envDuration("EVENT_RELAY_INTERVAL", time.Second),
+		EventRelayBatch:      envInt("EVENT_RELAY_BATCH", 100),
+		EventOutboxRetention: envDuration("EVENT_OUTBOX_RETENTION", 24*time.Hour),
+		DeadLetterStore:      os.Getenv("DEAD_LETTER_STORE"),
+		EventHandlers:        envList("EVENT_HANDLERS"),
+		EventSchemas:         os.Getenv("EVENT_SCHEMAS") != "false",
+		WebhookStore:         os.Getenv("WEBHOOK_STORE"),
+
+		ServiceAccountStore: envOr("SERVICE_ACCOUNT_STORE", "memory"),
+		OAuthStore:          envOr("OAUTH_STORE", "memory"),
+		ProfilingStore:      envOr("PROFILING_STORE", "memory"),
+
+		StorageProvider: envOr("STORAGE_PROVIDER", "local"),
+		StorageDir:      envOr("STORAGE_DIR", "data/media"),
+		S3Bucket:        os.Getenv("S3_BUCKET"),
+		S3Prefix:        os.Getenv("S3_PREFIX"),
+		S3PublicURL:     os.Getenv("S3_PUBLIC_URL"),
+		AvatarURLExpiry: envDuration("AVATAR_URL_EXPIRY", 0),
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
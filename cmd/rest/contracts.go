@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
+	"github.com/gentra/decorator-arch-go/internal/validation/jsonschema"
+)
+
+// schemaFiles are the JSON Schemas of the request bodies, one per endpoint
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// maxPayloadSize bounds the request bodies checked against schemas, as
+// decodeJSON does
+const maxPayloadSize = 1 << 20
+
+// buildSchemas compiles the endpoints' payload schemas, the embedded ones
+// first so SchemaDir can replace them. Messages render like validation
+// messages, in the requester's language.
+func (a *application) buildSchemas() error {
+	if !a.config.PayloadSchemas {
+		return nil
+	}
+
+	config := catalog.Config{DefaultLanguage: a.config.DefaultLanguage}
+	if a.config.TranslationDir != "" {
+		config.Loaders = append(config.Loaders, catalog.DirLoader(a.config.TranslationDir))
+	}
+	translations, err := catalog.NewService(config)
+	if err != nil {
+		return err
+	}
+
+	contracts := jsonschema.NewContracts(translations)
+	if err := contracts.LoadFS(schemaFiles, "schemas"); err != nil {
+		return err
+	}
+	if a.config.SchemaDir != "" {
+		if err := contracts.LoadFS(os.DirFS(a.config.SchemaDir), "."); err != nil {
+			return err
+		}
+	}
+	a.schemas = contracts
+	return nil
+}
+
+// withSchema checks request bodies against the named schema before handing
+// them to next. Bodies breaking the contract are answered with the
+// validation errors, which name fields with JSON pointers such as "/email".
+// Without schemas requests go straight to next.
+func (a *application) withSchema(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.schemas == nil || !a.schemas.Has(name) {
+			next(w, r)
+			return
+		}
+
+		payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+		if err != nil {
+			badRequest(w, "request body must be valid JSON")
+			return
+		}
+		if err := a.schemas.Validate(r.Context(), name, payload); err != nil {
+			if errors.Is(err, jsonschema.ErrInvalidJSON) {
+				badRequest(w, "request body must be valid JSON")
+				return
+			}
+			writeError(w, err)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(payload))
+		next(w, r)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestWithSchema_GivenRegistrationPayloads_WhenRegistering_ThenEnforcesContract(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expected      int
+		expectedField string
+	}{
+		{
+			name:     "Given a payload matching the schema, When registering, Then reaches the handler",
+			body:     `{"email":"jane.doe@example.com","password":"Str0ng!Pass","first_name":"Jane","last_name":"Doe"}`,
+			expected: http.StatusCreated,
+		},
+		{
+			name:          "Given a missing field, When registering, Then returns the field's JSON pointer",
+			body:          `{"email":"jane.doe@example.com","password":"Str0ng!Pass","first_name":"Jane"}`,
+			expected:      http.StatusBadRequest,
+			expectedField: "/last_name",
+		},
+		{
+			name:          "Given a field of the wrong type, When registering, Then returns the field's JSON pointer",
+			body:          `{"email":"jane.doe@example.com","password":12345678,"first_name":"Jane","last_name":"Doe"}`,
+			expected:      http.StatusBadRequest,
+			expectedField: "/password",
+		},
+		{
+			name:     "Given malformed JSON, When registering, Then returns bad request",
+			body:     `{"email":`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _, users := newAdminTestApp(t)
+			app.config.PayloadSchemas = true
+			require.NoError(t, app.buildSchemas())
+			users.On("Register", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(tt.body)))
+
+			// Assert
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected != http.StatusCreated {
+				users.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+			}
+			if tt.expectedField != "" {
+				var body map[string]apiError
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				assert.Equal(t, "VALIDATION_FAILED", body["error"].Code)
+				assert.Equal(t, tt.expectedField, body["error"].Field)
+			}
+		})
+	}
+}
+
+func TestBuildSchemas_GivenSchemaDir_WhenBuilding_ThenReplacesEmbeddedSchema(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "login.json"), []byte(`{"type":"object","required":["otp"]}`), 0o600))
+	app, _, users := newAdminTestApp(t)
+	app.config.PayloadSchemas, app.config.SchemaDir = true, dir
+	require.NoError(t, app.buildSchemas())
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"jane@example.com","password":"secret"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"/otp"`)
+	users.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything)
+}
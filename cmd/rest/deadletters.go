@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	deadLetterMemory "github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	deadLetterMetrics "github.com/gentra/decorator-arch-go/internal/deadletter/metrics"
+	deadLetterPostgres "github.com/gentra/decorator-arch-go/internal/deadletter/postgres"
+)
+
+const defaultDeadLetterLimit = 100
+
+// buildDeadLetters opens the dead-letter store the event bus hands the
+// events its handlers give up on, reporting its depth as a metric
+func (a *application) buildDeadLetters() (err error) {
+	var store deadletter.Service
+	switch a.config.DeadLetterStore {
+	case "":
+		return nil
+	case "memory":
+		store = deadLetterMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for DEAD_LETTER_STORE=postgres")
+		}
+		store = deadLetterPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown DEAD_LETTER_STORE %q", a.config.DeadLetterStore)
+	}
+
+	a.deadLetters, err = deadLetterMetrics.NewService(store, prometheus.DefaultRegisterer)
+	return err
+}
+
+// handleListDeadLetters lists dead-lettered events, oldest first
+func (a *application) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := deadletter.Filter{
+		EventType: query.Get("event_type"),
+		Provider:  query.Get("provider"),
+		Limit:     defaultDeadLetterLimit,
+	}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			badRequest(w, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	letters, err := a.deadLetters.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, letters)
+}
+
+func (a *application) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, err := a.deadLetters.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, letter)
+}
+
+// handleRedriveDeadLetter publishes the event to the event bus again, which
+// delivers it to every subscriber, and removes it from the queue. Should
+// the removal fail the event stays queued, and handlers tolerate the
+// duplicate a second re-drive delivers.
+func (a *application) handleRedriveDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, err := a.deadLetters.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.events.Publish(r.Context(), letter.Event); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.deadLetters.Remove(r.Context(), letter.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, letter)
+}
+
+// handleDiscardDeadLetter drops a dead-lettered event without delivering it
+func (a *application) handleDiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if err := a.deadLetters.Remove(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
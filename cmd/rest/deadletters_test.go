@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	deadLetterMemory "github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestDeadLetters_GivenDeadLetteredEvent_WhenAdminRedrivesIt_ThenRepublishesAndRemovesIt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	app.events = bus
+	app.deadLetters = deadLetterMemory.NewService()
+	event := testkit.NewEventBuilder().Build()
+	letter, err := app.deadLetters.Add(ctx, deadletter.Letter{Event: *event, Provider: "memory", Error: "handler unavailable", Attempts: 4})
+	require.NoError(t, err)
+
+	// Act
+	listRec := httptest.NewRecorder()
+	app.routes().ServeHTTP(listRec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/dead-letters?provider=memory", ""))
+	redriveRec := httptest.NewRecorder()
+	app.routes().ServeHTTP(redriveRec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/dead-letters/"+letter.ID+"/redrive", ""))
+
+	// Assert
+	require.Equal(t, http.StatusOK, listRec.Code)
+	var listed []deadletter.Letter
+	require.NoError(t, json.NewDecoder(listRec.Body).Decode(&listed))
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, letter.ID, listed[0].ID)
+	}
+	require.Equal(t, http.StatusOK, redriveRec.Code)
+	published, err := bus.GetEventsByAggregate(ctx, event.AggregateID, 0)
+	require.NoError(t, err)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, event.ID, published[0].ID)
+	}
+	depth, err := app.deadLetters.Depth(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, depth)
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(closeCtx))
+}
+
+func TestDeadLetters_GivenUnknownID_WhenAdminDiscardsIt_ThenReturnsNotFound(t *testing.T) {
+	// Arrange
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.deadLetters = deadLetterMemory.NewService()
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodDelete, "/api/admin/dead-letters/missing", ""))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "DEAD_LETTER_NOT_FOUND")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// deprecation marks API surface scheduled for removal
+type deprecation struct {
+	Since  time.Time // When it was deprecated; sent as the Deprecation header
+	Sunset time.Time // When it will be removed; zero until a date is set
+	Link   string    // Documentation of the replacement, sent as a Link header
+}
+
+// deprecations is the route metadata for deprecated API surface. Routes are
+// keyed by the pattern they are registered with on the mux, scopes by name.
+type deprecations struct {
+	Routes map[string]deprecation
+	Scopes map[string]deprecation
+}
+
+// Kinds of deprecated surface, used as a metric label
+const (
+	deprecatedRoute = "route"
+	deprecatedScope = "scope"
+)
+
+// otherClient labels calls to deprecated surface not made with an API token.
+// Clients are only told apart by credentials the server issued, so the
+// metric's cardinality is bounded by the API keys and service accounts.
+const otherClient = "other"
+
+// newDeprecatedUsage creates the counter of calls to deprecated surface per client
+func newDeprecatedUsage(registerer prometheus.Registerer) (*prometheus.CounterVec, error) {
+	usage := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "http",
+		Name:      "deprecated_requests_total",
+		Help:      "Requests using deprecated routes or token scopes by client.",
+	}, []string{"kind", "name", "client"})
+
+	if err := registerer.Register(usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// withDeprecations emits Deprecation and Sunset headers on deprecated routes
+// and counts their use. The route is resolved up front from the mux so the
+// headers are in place before the handler writes the response.
+func (a *application) withDeprecations(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(a.deprecations.Routes) > 0 {
+			if _, pattern := mux.Handler(r); pattern != "" {
+				if dep, ok := a.deprecations.Routes[pattern]; ok {
+					a.markDeprecated(w, deprecatedRoute, pattern, a.deprecationClient(r), dep)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// markDeprecatedScopes flags requests authenticated with a deprecated API token scope
+func (a *application) markDeprecatedScopes(w http.ResponseWriter, r *http.Request, bearer string) {
+	if len(a.deprecations.Scopes) == 0 {
+		return
+	}
+
+	claims, err := a.token.ValidateAPIToken(r.Context(), bearer)
+	if err != nil {
+		return
+	}
+	for _, scope := range claims.Scopes {
+		if dep, ok := a.deprecations.Scopes[scope]; ok {
+			a.markDeprecated(w, deprecatedScope, scope, apiClient(&claims.TokenClaims), dep)
+		}
+	}
+}
+
+// markDeprecated sets the deprecation headers and records the use. When a
+// request hits several deprecated items the earliest dates are announced.
+func (a *application) markDeprecated(w http.ResponseWriter, kind, name, client string, dep deprecation) {
+	header := w.Header()
+
+	// Deprecation is a structured field date (RFC 9745); Sunset an HTTP date (RFC 8594)
+	current, err := strconv.ParseInt(strings.TrimPrefix(header.Get("Deprecation"), "@"), 10, 64)
+	if err != nil || dep.Since.Unix() < current {
+		header.Set("Deprecation", "@"+strconv.FormatInt(dep.Since.Unix(), 10))
+	}
+	if !dep.Sunset.IsZero() {
+		current, err := http.ParseTime(header.Get("Sunset"))
+		if err != nil || dep.Sunset.Before(current) {
+			header.Set("Sunset", dep.Sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+	if dep.Link != "" {
+		header.Add("Link", "<"+dep.Link+`>; rel="deprecation"`)
+	}
+
+	if a.deprecatedUsage != nil {
+		a.deprecatedUsage.WithLabelValues(kind, name, client).Inc()
+	}
+}
+
+// deprecationClient identifies the caller of a deprecated route by its API
+// token. Routes are matched before authentication, so the token is checked
+// here; anything else is counted as otherClient.
+func (a *application) deprecationClient(r *http.Request) string {
+	bearer := bearerToken(r)
+	if bearer == "" {
+		return otherClient
+	}
+	claims, err := a.token.ValidateAPIToken(r.Context(), bearer)
+	if err != nil {
+		return otherClient
+	}
+	return apiClient(&claims.TokenClaims)
+}
+
+// apiClient labels an API token by the service account holding it, or by
+// the key's ID for API keys of users
+func apiClient(claims *token.TokenClaims) string {
+	if strings.HasPrefix(claims.UserID, serviceaccount.PrincipalPrefix) {
+		return claims.UserID
+	}
+	if claims.JTI == "" {
+		return otherClient
+	}
+	return "api_key:" + claims.JTI
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func newDeprecationTestApp(t *testing.T, deps deprecations) *application {
+	t.Helper()
+	app, _, users := newAdminTestApp(t)
+	users.On("GetByID", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+
+	usage, err := newDeprecatedUsage(prometheus.NewRegistry())
+	require.NoError(t, err)
+	app.deprecations = deps
+	app.deprecatedUsage = usage
+	return app
+}
+
+func TestDeprecations_GivenDeprecatedRoute_WhenCalledWithASession_ThenSetsHeadersAndCountsOtherClient(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	app := newDeprecationTestApp(t, deprecations{Routes: map[string]deprecation{
+		"GET /api/users/profile": {Since: since, Sunset: sunset, Link: "https://docs.example.com/migrate"},
+	}})
+
+	req := authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/profile", "")
+	req.Header.Set("User-Agent", "billing-sync/2.1 (+https://example.com)")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "@1735689600", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jul 2025 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `<https://docs.example.com/migrate>; rel="deprecation"`, rec.Header().Get("Link"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(app.deprecatedUsage.WithLabelValues(deprecatedRoute, "GET /api/users/profile", otherClient)))
+	assert.Equal(t, 1, testutil.CollectAndCount(app.deprecatedUsage))
+}
+
+func TestDeprecations_GivenDeprecatedRoute_WhenCalledWithAPITokens_ThenCountsEachKeyOrServiceAccount(t *testing.T) {
+	app := newDeprecationTestApp(t, deprecations{Routes: map[string]deprecation{
+		"GET /api/users/profile": {Since: time.Now()},
+	}})
+	apiKey, err := app.token.GenerateAPIToken(t.Context(), "user-1", []string{"users:read"})
+	require.NoError(t, err)
+	principal := serviceaccount.PrincipalPrefix + "sa-1"
+	serviceToken, err := app.token.GenerateAPIToken(t.Context(), principal, []string{"users:read"})
+	require.NoError(t, err)
+
+	for _, bearer := range []string{apiKey.Token, serviceToken.Token} {
+		req := apiKeyRequest(http.MethodGet, "/api/users/profile", bearer)
+		req.Header.Set("User-Agent", "billing-sync/2.1")
+		rec := httptest.NewRecorder()
+		app.routes().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(app.deprecatedUsage.WithLabelValues(deprecatedRoute, "GET /api/users/profile", "api_key:"+apiKey.ID)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(app.deprecatedUsage.WithLabelValues(deprecatedRoute, "GET /api/users/profile", principal)))
+}
+
+func TestDeprecations_GivenCurrentRoute_WhenCalled_ThenOmitsHeaders(t *testing.T) {
+	app := newDeprecationTestApp(t, deprecations{Routes: map[string]deprecation{
+		"GET /api/auth/me": {Since: time.Now()},
+	}})
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/profile", ""))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"))
+}
+
+func TestDeprecations_GivenTokenWithDeprecatedScope_WhenCalled_ThenSetsHeadersAndCountsScope(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	app := newDeprecationTestApp(t, deprecations{Scopes: map[string]deprecation{
+		"users:legacy": {Since: since},
+	}})
+	apiToken, err := app.token.GenerateAPIToken(t.Context(), "user-1", []string{"users:read", "users:legacy"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+apiToken.Token)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "@1740787200", rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(app.deprecatedUsage.WithLabelValues(deprecatedScope, "users:legacy", "api_key:"+apiToken.ID)))
+}
+
+func TestMarkDeprecated_GivenSeveralDeprecations_WhenMarking_ThenAnnouncesEarliestDates(t *testing.T) {
+	app := &application{}
+	early := deprecation{Since: time.Unix(100, 0), Sunset: time.Unix(1000, 0)}
+	late := deprecation{Since: time.Unix(200, 0), Sunset: time.Unix(2000, 0)}
+
+	rec := httptest.NewRecorder()
+	app.markDeprecated(rec, deprecatedRoute, "late", otherClient, late)
+	app.markDeprecated(rec, deprecatedScope, "early", otherClient, early)
+
+	assert.Equal(t, "@100", rec.Header().Get("Deprecation"))
+	assert.Equal(t, time.Unix(1000, 0).UTC().Format(http.TimeFormat), rec.Header().Get("Sunset"))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestEventHandlers_GivenAuditProjection_WhenLoginIsPublished_ThenRecordsItOnClose(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	app := &application{
+		config:       config{EventHandlers: []string{"welcome-email", "audit-projection"}},
+		events:       eventsMemory.NewService(events.DefaultEventConfig()),
+		audit:        auditMemory.NewService(),
+		notification: notificationMock.NewService(),
+	}
+	require.NoError(t, app.buildEventHandlers())
+	event := testkit.NewEventBuilder().WithType(events.EventTypeUserLoggedIn).Build()
+
+	// Act
+	require.NoError(t, app.events.Publish(ctx, *event))
+	app.Close()
+
+	// Assert
+	assert.True(t, app.handlesEvents("welcome-email"))
+	assert.Len(t, app.eventHandlers, 2)
+	entries, err := app.audit.GetAuditLogs(ctx, audit.AuditFilters{Action: events.EventTypeUserLoggedIn})
+	require.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, event.ID, entries[0].ID)
+	}
+}
+
+func TestBuildEventHandlers_GivenUnknownHandler_WhenBuilding_ThenFails(t *testing.T) {
+	// Arrange
+	app := &application{
+		config: config{EventHandlers: []string{"billing"}},
+		events: eventsMemory.NewService(events.DefaultEventConfig()),
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = app.events.(*eventsMemory.Service).Close(closeCtx)
+	}()
+
+	// Act
+	err := app.buildEventHandlers()
+
+	// Assert
+	assert.ErrorIs(t, err, eventhandler.ErrHandlerNotFound)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	eventOutboxMemory "github.com/gentra/decorator-arch-go/internal/eventoutbox/memory"
+	eventOutboxPostgres "github.com/gentra/decorator-arch-go/internal/eventoutbox/postgres"
+	eventsOutbox "github.com/gentra/decorator-arch-go/internal/events/outbox"
+	eventsPropagation "github.com/gentra/decorator-arch-go/internal/events/propagation"
+)
+
+// buildEventOutbox opens the event outbox and routes the events the user
+// and auth services publish through it; the event bus itself only receives
+// them from the relay
+func (a *application) buildEventOutbox() error {
+	switch a.config.EventOutbox {
+	case "":
+		a.publisher = a.events
+		return nil
+	case "memory":
+		a.eventOutbox = eventOutboxMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for EVENT_OUTBOX=postgres")
+		}
+		a.eventOutbox = eventOutboxPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown EVENT_OUTBOX %q", a.config.EventOutbox)
+	}
+
+	// Events are correlated as they are recorded, since the relay publishes
+	// them outside the request
+	a.publisher = eventsPropagation.NewService(eventsOutbox.NewService(a.events, a.eventOutbox))
+	return nil
+}
+
+// transactionalOutbox reports whether user changes and their events commit
+// in one transaction, which takes both in the same Postgres database
+func (a *application) transactionalOutbox() bool {
+	return a.config.EventOutbox == "postgres" && a.config.UserStorage == "postgres"
+}
+
+// runEventRelay publishes the events waiting in the outbox every interval
+// until the context is cancelled
+func (a *application) runEventRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.relayEvents(ctx)
+		}
+	}
+}
+
+// relayEvents runs a single relay, then drops entries published longer ago
+// than the retention
+func (a *application) relayEvents(ctx context.Context) {
+	report, err := a.eventOutbox.Relay(ctx, a.config.EventRelayBatch, a.events.Publish)
+	if err != nil {
+		log.Printf("Event relay failed: %v", err)
+		return
+	}
+	if report.Failed > 0 {
+		log.Printf("Event relay published %d events, %d failed and %d wait behind them",
+			report.Published, report.Failed, report.Deferred)
+	}
+
+	if a.config.EventOutboxRetention > 0 {
+		if _, err := a.eventOutbox.Prune(ctx, time.Now().Add(-a.config.EventOutboxRetention)); err != nil {
+			log.Printf("Event outbox pruning failed: %v", err)
+		}
+	}
+}
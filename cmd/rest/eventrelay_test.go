@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestEventRelay_GivenMemoryOutbox_WhenRelaying_ThenPublishesRecordedEventsToTheBus(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	app := &application{config: config{EventOutbox: "memory", EventRelayBatch: 10}, events: bus}
+	require.NoError(t, app.buildEventOutbox())
+	event := testkit.NewEventBuilder().Build()
+	require.NoError(t, app.publisher.Publish(ctx, *event))
+
+	// Act
+	app.relayEvents(ctx)
+
+	// Assert
+	published, err := bus.GetEventsByAggregate(ctx, event.AggregateID, 0)
+	require.NoError(t, err)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, event.ID, published[0].ID)
+	}
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(closeCtx))
+}
+
+func TestBuildEventOutbox_GivenUnknownOutbox_WhenBuilding_ThenFails(t *testing.T) {
+	// Arrange
+	app := &application{config: config{EventOutbox: "kafka"}}
+
+	// Act
+	err := app.buildEventOutbox()
+
+	// Assert
+	assert.ErrorContains(t, err, `unknown EVENT_OUTBOX "kafka"`)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+
+	eventHandlerFactory "github.com/gentra/decorator-arch-go/internal/eventhandler/factory"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	eventReplayMemory "github.com/gentra/decorator-arch-go/internal/eventreplay/memory"
+)
+
+// buildEventReplays lets administrators replay the stored events into the
+// built-in handlers whose services are configured, subscribed or not, to
+// rebuild a read model or backfill a handler enabled later
+func (a *application) buildEventReplays() error {
+	if a.events == nil {
+		return nil
+	}
+	handlers := eventHandlerFactory.ReplayableHandlers(eventHandlerFactory.BuiltinDependencies{
+		NotificationService: a.notification,
+		AuditService:        a.audit,
+		WebhookService:      a.webhooks,
+	})
+	a.replays = eventReplayMemory.NewService(a.events, handlers)
+	return nil
+}
+
+// handleListEventReplays lists the replays of this instance, newest first
+func (a *application) handleListEventReplays(w http.ResponseWriter, r *http.Request) {
+	jobs, err := a.replays.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleStartEventReplay starts a replay, answering before it finishes
+func (a *application) handleStartEventReplay(w http.ResponseWriter, r *http.Request) {
+	var req eventreplay.Request
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	job, err := a.replays.Start(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleGetEventReplay returns a replay with its progress
+func (a *application) handleGetEventReplay(w http.ResponseWriter, r *http.Request) {
+	job, err := a.replays.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleCancelEventReplay stops a running replay
+func (a *application) handleCancelEventReplay(w http.ResponseWriter, r *http.Request) {
+	job, err := a.replays.Cancel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
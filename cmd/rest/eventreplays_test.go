@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestEventReplays_GivenStoredLogin_WhenAdminReplaysIntoAuditProjection_ThenRecordsIt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_ = bus.Close(closeCtx)
+	}()
+	projected := auditMemory.NewService()
+	app.events = bus
+	app.audit = projected
+	require.NoError(t, app.buildEventReplays())
+	app.audit = auditSvc
+	event := testkit.NewEventBuilder().WithType(events.EventTypeUserLoggedIn).Build()
+	require.NoError(t, bus.Publish(ctx, *event))
+
+	// Act
+	startRec := httptest.NewRecorder()
+	app.routes().ServeHTTP(startRec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/event-replays",
+		`{"filters":{"event_types":["`+events.EventTypeUserLoggedIn+`"]},"handlers":["audit-projection"],"rate":100}`))
+
+	// Assert
+	require.Equal(t, http.StatusAccepted, startRec.Code)
+	var started eventreplay.Job
+	require.NoError(t, json.NewDecoder(startRec.Body).Decode(&started))
+	var job eventreplay.Job
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/event-replays/"+started.ID, ""))
+		return rec.Code == http.StatusOK && json.NewDecoder(rec.Body).Decode(&job) == nil && job.IsFinished()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, eventreplay.StatusCompleted, job.Status)
+	assert.Equal(t, 1, job.Handled)
+	entries, err := projected.GetAuditLogs(ctx, audit.AuditFilters{Action: events.EventTypeUserLoggedIn})
+	require.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, event.ID, entries[0].ID)
+	}
+}
+
+func TestEventReplays_GivenBadRequest_WhenAdminCallsTheAPI_ThenReturnsTheError(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Given an unknown handler, When starting a replay, Then returns bad request",
+			method:         http.MethodPost,
+			path:           "/api/admin/event-replays",
+			body:           `{"handlers":["billing"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   eventreplay.ErrUnknownHandler.Code,
+		},
+		{
+			name:           "Given no handlers, When starting a replay, Then returns bad request",
+			method:         http.MethodPost,
+			path:           "/api/admin/event-replays",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   eventreplay.ErrInvalidRequest.Code,
+		},
+		{
+			name:           "Given an unknown replay, When cancelling it, Then returns not found",
+			method:         http.MethodPost,
+			path:           "/api/admin/event-replays/missing/cancel",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   eventreplay.ErrJobNotFound.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, auditSvc, _ := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+			bus := eventsMemory.NewService(events.DefaultEventConfig())
+			defer func() {
+				closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = bus.Close(closeCtx)
+			}()
+			app.events = bus
+			require.NoError(t, app.buildEventReplays())
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", tt.method, tt.path, tt.body))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedCode)
+		})
+	}
+}
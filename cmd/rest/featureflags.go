@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// setFeatureFlagRequest is the body of an admin feature flag change
+type setFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// requireFeature serves the endpoint only to callers with the feature flag
+// switched on. Everyone else gets 404, so beta endpoints stay invisible until
+// a user is let in. It must run after requireAuth.
+func (a *application) requireFeature(flag string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enabled, err := user.IsFeatureEnabled(r.Context(), a.users, claimsFromContext(r.Context()).UserID, flag)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if !enabled {
+			writeJSON(w, http.StatusNotFound, map[string]apiError{
+				"error": {Code: "NOT_FOUND", Message: "Not found"},
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetFeatureFlags returns the caller's feature flags
+func (a *application) handleGetFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := a.users.GetFeatureFlags(r.Context(), claimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+func (a *application) handleAdminGetFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := a.users.GetFeatureFlags(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+// handleAdminSetFeatureFlag switches one feature flag of a user
+func (a *application) handleAdminSetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var req setFeatureFlagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		badRequest(w, "enabled is required")
+		return
+	}
+
+	if err := a.users.SetFeatureFlag(r.Context(), r.PathValue("id"), r.PathValue("flag"), *req.Enabled); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func TestGetFeatureFlags_GivenAuthenticatedCaller_WhenRequested_ThenReturnsCallerFlags(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("GetFeatureFlags", mock.Anything, "user-1").
+		Return(&user.FeatureFlags{UserID: uuid.New(), Flags: map[string]bool{"beta_dashboard": true}}, nil)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/feature-flags", "")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"flags":{"beta_dashboard":true}`)
+}
+
+func TestAdminSetFeatureFlag_GivenAdmin_WhenSwitchingFlag_ThenStoresIt(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("SetFeatureFlag", mock.Anything, "user-9", "beta_dashboard", true).Return(nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPut, "/api/admin/users/user-9/feature-flags/beta_dashboard", `{"enabled":true}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestAdminSetFeatureFlag_GivenNoEnabledField_WhenSwitchingFlag_ThenReturnsBadRequest(t *testing.T) {
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPut, "/api/admin/users/user-9/feature-flags/beta_dashboard", `{}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "SetFeatureFlag", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequireFeature(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	t.Run("Given the flag is on, When requested, Then serves the endpoint", func(t *testing.T) {
+		app, _, users := newAdminTestApp(t)
+		users.On("GetFeatureFlags", mock.Anything, "user-1").
+			Return(&user.FeatureFlags{Flags: map[string]bool{"beta_dashboard": true}}, nil)
+
+		req := authorizedRequest(t, app, "user-1", http.MethodGet, "/beta", "")
+		rec := httptest.NewRecorder()
+		app.requireAuth(app.requireFeature("beta_dashboard", ok)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Given the flag is off, When requested, Then returns not found", func(t *testing.T) {
+		app, _, users := newAdminTestApp(t)
+		users.On("GetFeatureFlags", mock.Anything, "user-1").
+			Return(&user.FeatureFlags{Flags: map[string]bool{}}, nil)
+
+		req := authorizedRequest(t, app, "user-1", http.MethodGet, "/beta", "")
+		rec := httptest.NewRecorder()
+		app.requireAuth(app.requireFeature("beta_dashboard", ok)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+const defaultNotificationLimit = 50
+
+func (a *application) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleJWKS publishes the public keys tokens are verified with, so other
+// services can validate them without sharing a secret
+func (a *application) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	keys, err := a.token.PublicKeys(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, keys)
+}
+
+func (a *application) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var data user.RegisterData
+	if !decodeJSON(w, r, &data) {
+		return
+	}
+
+	created, err := a.users.Register(r.Context(), data)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.writeUser(w, r, http.StatusCreated, selfSubject(created), created)
+}
+
+func (a *application) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var credentials struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if !decodeJSON(w, r, &credentials) {
+		return
+	}
+
+	result, err := a.users.Login(r.Context(), credentials.Email, credentials.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	view, err := a.views.Render(r.Context(), selfSubject(result.User), result.User)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, userview.AuthResultView{
+		User:         view,
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresAt:    result.ExpiresAt,
+	})
+}
+
+// verifyEmailRequest is the body of an email verification
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// handleVerifyEmail marks the email of the user the token was sent to as
+// verified. The token is the proof, so the caller need not be signed in.
+func (a *application) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	verified, err := a.users.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.writeUser(w, r, http.StatusOK, selfSubject(verified), verified)
+}
+
+// passwordResetRequest is the body of a password reset request
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// confirmPasswordResetRequest is the body of a password reset confirmation
+type confirmPasswordResetRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// handleRequestPasswordReset emails a reset token to the user with the
+// given email. Unknown and deactivated accounts get the same answer, so the
+// endpoint does not reveal which emails have an account.
+func (a *application) handleRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req passwordResetRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	_, err := a.users.RequestPasswordReset(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) && !errors.Is(err, user.ErrAccountDeactivated) {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleConfirmPasswordReset replaces the password of the user the token was
+// sent to and signs them out everywhere. The token is the proof, so the
+// caller need not be signed in.
+func (a *application) handleConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req confirmPasswordResetRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := a.users.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *application) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	pair, err := a.token.RefreshToken(r.Context(), body.RefreshToken)
+	if err != nil {
+		var reuse token.RefreshTokenReuseError
+		if errors.As(err, &reuse) {
+			a.publishTokenReuse(r.Context(), reuse)
+		}
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, userview.AuthResultView{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt,
+	})
+}
+
+// publishTokenReuse announces that an exchanged refresh token was presented
+// again and its family revoked, as a possible token theft; failures are only
+// logged
+func (a *application) publishTokenReuse(ctx context.Context, reuse token.RefreshTokenReuseError) {
+	if a.publisher == nil {
+		return
+	}
+
+	now := time.Now()
+	event := events.Event{
+		ID:            uuid.NewString(),
+		Type:          events.EventTypeTokenReuseDetected,
+		AggregateID:   reuse.UserID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":     reuse.UserID,
+			"family_id":   reuse.FamilyID,
+			"detected_at": now,
+		},
+		Metadata: events.EventMetadata{
+			UserID:    reuse.UserID,
+			Source:    "auth",
+			IPAddress: user.ClientIPFromContext(ctx),
+		},
+		Timestamp: now,
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+	if err := a.publisher.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish TokenReuseDetected event: %v", err)
+	}
+}
+
+func (a *application) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := a.token.RevokeToken(r.Context(), bearerToken(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleLogoutAll revokes every token issued to the caller, API keys
+// included, signing them out on every device. Impersonation tokens cannot
+// sign the user out.
+func (a *application) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	if claims.IsImpersonationToken() {
+		writeError(w, user.ErrForbidden)
+		return
+	}
+
+	if err := a.auth.RevokeAllForUser(r.Context(), claims.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// introspectRequest names the token to describe
+type introspectRequest struct {
+	Token string `json:"token"`
+}
+
+// handleIntrospect describes a token the way an RFC 7662 introspection
+// endpoint does; tokens that are invalid, expired or revoked are only
+// reported as inactive
+func (a *application) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	var req introspectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Token == "" {
+		badRequest(w, "token is required")
+		return
+	}
+
+	introspection, err := a.auth.Introspect(r.Context(), req.Token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if introspection.Active && a.activePrincipal(r.Context(), introspection.Subject) != nil {
+		introspection = auth.NewTokenIntrospection(nil)
+	}
+	writeJSON(w, http.StatusOK, introspection)
+}
+
+func (a *application) handleMe(w http.ResponseWriter, r *http.Request) {
+	a.handleGetProfile(w, r)
+}
+
+func (a *application) handleGetProfile(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	found, err := a.users.GetByID(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", versionETag(found.Version))
+	a.writeUser(w, r, http.StatusOK, a.subject(claims), found)
+}
+
+// handleUpdateProfile updates the caller's profile. With If-Match the update
+// only applies to the version named by the entity tag.
+func (a *application) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	version, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+
+	var data user.UpdateProfileData
+	if !decodeJSON(w, r, &data) {
+		return
+	}
+	if conditional {
+		data.Version = version
+	}
+
+	updated, err := a.users.UpdateProfile(r.Context(), claims.UserID, data)
+	if err != nil {
+		writeUpdateError(w, err, conditional)
+		return
+	}
+	w.Header().Set("ETag", versionETag(updated.Version))
+	a.writeUser(w, r, http.StatusOK, a.subject(claims), updated)
+}
+
+// changePasswordRequest is the body of a password change
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// handleChangePassword changes the caller's password
+func (a *application) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	var req changePasswordRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := a.users.ChangePassword(r.Context(), claims.UserID, req.CurrentPassword, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// emailChangeRequest is the body of an email change request
+type emailChangeRequest struct {
+	Email string `json:"email"`
+}
+
+// confirmEmailChangeRequest is the body of an email change confirmation
+type confirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+// handleRequestEmailChange sends a confirmation token to the caller's new email
+func (a *application) handleRequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	var req emailChangeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := a.users.RequestEmailChange(r.Context(), claims.UserID, req.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleConfirmEmailChange applies the caller's pending email change
+func (a *application) handleConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	var req confirmEmailChangeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := a.users.ConfirmEmailChange(r.Context(), claims.UserID, req.Token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.writeUser(w, r, http.StatusOK, a.subject(claims), updated)
+}
+
+// handleUploadAvatar replaces the caller's avatar with the image in the request
+// body; the Content-Type header gives the image type
+func (a *application) handleUploadAvatar(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, user.ErrUnsupportedAvatar)
+		return
+	}
+
+	if err := a.users.UploadAvatar(r.Context(), claims.UserID, r.Body, contentType); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// avatarResponse is the link to a user's avatar
+type avatarResponse struct {
+	URL string `json:"url"`
+}
+
+// handleGetAvatarURL returns a link to the caller's avatar
+func (a *application) handleGetAvatarURL(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	url, err := a.users.GetAvatarURL(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, avatarResponse{URL: url})
+}
+
+// mediaPath is where locally stored objects are served
+const mediaPath = "/media"
+
+// handleMedia serves an object from local storage. Avatar keys change on
+// every upload, so objects can be cached indefinitely.
+func (a *application) handleMedia(w http.ResponseWriter, r *http.Request) {
+	body, object, err := a.storage.Get(r.Context(), r.PathValue("key"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("Failed to serve media %s: %v", object.Key, err)
+	}
+}
+
+func (a *application) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	prefs, err := a.users.GetPreferences(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", versionETag(prefs.Version))
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// handleUpdatePreferences replaces the caller's preferences. With If-Match
+// the update only applies to the version named by the entity tag.
+func (a *application) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	version, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+
+	var prefs user.UserPreferences
+	if !decodeJSON(w, r, &prefs) {
+		return
+	}
+	if conditional {
+		prefs.Version = version
+	}
+
+	if err := a.users.UpdatePreferences(r.Context(), claims.UserID, prefs); err != nil {
+		writeUpdateError(w, err, conditional)
+		return
+	}
+	if updated, ok := prefs.Updated(); ok {
+		w.Header().Set("ETag", versionETag(updated.Version))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetPreferenceSchema lists the notification types preferences may
+// hold, so clients can render the switches without hard-coding them
+func (a *application) handleGetPreferenceSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"notification_types": user.DefaultPreferenceSchema().Definitions(),
+	})
+}
+
+// handleExportUserData returns everything stored about the caller, as JSON or,
+// with ?format=zip, as a ZIP archive with one file per section
+func (a *application) handleExportUserData(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		badRequest(w, "format must be json or zip")
+		return
+	}
+
+	export, err := a.users.ExportUserData(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format != "zip" {
+		writeJSON(w, http.StatusOK, export)
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := export.WriteZip(&archive); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="user-data-export.zip"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(archive.Bytes()); err != nil {
+		log.Printf("Failed to write data export: %v", err)
+	}
+}
+
+// handleEraseUser erases the caller's personal data and revokes their tokens
+func (a *application) handleEraseUser(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	if err := a.users.EraseUser(r.Context(), claims.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.token.RevokeAllTokensForUser(r.Context(), claims.UserID); err != nil {
+		log.Printf("Failed to revoke tokens of erased user %s: %v", claims.UserID, err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *application) handleListNotifications(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	limit := defaultNotificationLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			badRequest(w, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	history, err := a.notification.GetNotificationHistory(r.Context(), claims.UserID, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (a *application) handleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	if err := a.notification.MarkAsRead(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeUser renders the user for the viewer, so each handler exposes exactly
+// the fields the viewer's relationship allows
+func (a *application) writeUser(w http.ResponseWriter, r *http.Request, status int, viewer authorization.Subject, target *user.User) {
+	view, err := a.views.Render(r.Context(), viewer, target)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, view)
+}
+
+// selfSubject is the viewer for responses returned to the user who just
+// registered or logged in, before a token is presented
+func selfSubject(u *user.User) authorization.Subject {
+	if u == nil {
+		return authorization.Subject{}
+	}
+	return authorization.Subject{ID: u.ID.String(), Roles: []string{authorization.RoleUser}}
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	storageLocal "github.com/gentra/decorator-arch-go/internal/storage/local"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	tokenJWT "github.com/gentra/decorator-arch-go/internal/token/jwt"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+func TestRegister_GivenIdempotencyKey_WhenRegistering_ThenPassesKeyToUserService(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("Register", mock.MatchedBy(func(ctx context.Context) bool {
+		return user.IdempotencyKeyFromContext(ctx) == "signup-1"
+	}), mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"jane.doe@example.com"}`))
+	req.Header.Set("Idempotency-Key", "signup-1")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestRegister_GivenOverlongIdempotencyKey_WhenRegistering_ThenReturnsBadRequest(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"jane.doe@example.com"}`))
+	req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+func TestRegister_GivenIdempotencyKeyReused_WhenRegistering_ThenReturnsUnprocessableEntity(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("Register", mock.Anything, mock.Anything).Return(nil, user.ErrIdempotencyKeyReuse)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"other@example.com"}`))
+	req.Header.Set("Idempotency-Key", "signup-1")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"IDEMPOTENCY_KEY_REUSED"`)
+}
+
+func TestExportUserData_GivenZipFormat_WhenExporting_ThenReturnsArchiveWithOneFilePerSection(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("ExportUserData", mock.Anything, "user-1").Return(&user.DataExport{
+		UserID:      "user-1",
+		ExportedAt:  testkit.Epoch,
+		Profile:     testkit.NewUserBuilder().Build(),
+		Preferences: testkit.NewPreferencesBuilder().Build(),
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/profile/export?format=zip", ""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"export.json", "profile.json", "preferences.json", "audit_entries.json", "notifications.json"}, names)
+}
+
+func TestExportUserData_GivenUnknownFormat_WhenExporting_ThenReturnsBadRequest(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/profile/export?format=csv", ""))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "ExportUserData", mock.Anything, mock.Anything)
+}
+
+func TestEraseUser_GivenAuthenticatedUser_WhenErasing_ThenErasesCallerData(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("EraseUser", mock.Anything, "user-1").Return(nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/profile/erase", ""))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestChangePassword_GivenPasswords_WhenChanging_ThenChangesCallerPassword(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("ChangePassword", mock.Anything, "user-1", "old-password", "N3w-Password!").Return(nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPut, "/api/users/password",
+		`{"current_password":"old-password","new_password":"N3w-Password!"}`))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestChangePassword_GivenRecentPassword_WhenChanging_ThenReturnsBadRequest(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("ChangePassword", mock.Anything, "user-1", "old-password", "old-password").Return(user.ErrPasswordReused)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPut, "/api/users/password",
+		`{"current_password":"old-password","new_password":"old-password"}`))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), user.ErrPasswordReused.Code)
+}
+
+func TestRequestEmailChange_GivenNewEmail_WhenRequesting_ThenAccepted(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("RequestEmailChange", mock.Anything, "user-1", "jane.new@example.com").Return(nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/email", `{"email":"jane.new@example.com"}`))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestConfirmEmailChange_GivenToken_WhenConfirming_ThenReturnsUpdatedUser(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	userID := testkit.DefaultUserID.String()
+	updated := testkit.NewUserBuilder().WithEmail("jane.new@example.com").Build()
+	users.On("ConfirmEmailChange", mock.Anything, userID, "confirm-token").Return(updated, nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, userID, http.MethodPost, "/api/users/email/confirm", `{"token":"confirm-token"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"email":"jane.new@example.com"`)
+}
+
+func TestGetProfile_GivenVersionedUser_WhenRequesting_ThenReturnsVersionETag(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	userID := testkit.DefaultUserID.String()
+	found := testkit.NewUserBuilder().WithVersion(3).Build()
+	users.On("GetByID", mock.Anything, userID).Return(found, nil)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, userID, http.MethodGet, "/api/users/profile", ""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+}
+
+func TestUpdateProfile_GivenStaleIfMatch_WhenUpdating_ThenReturnsPreconditionFailed(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	userID := testkit.DefaultUserID.String()
+	users.On("UpdateProfile", mock.Anything, userID, mock.MatchedBy(func(data user.UpdateProfileData) bool {
+		return data.Version == 3
+	})).Return(nil, user.ErrConflict)
+
+	req := authorizedRequest(t, app, userID, http.MethodPut, "/api/users/profile", `{"first_name":"Janet"}`)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"CONFLICT"`)
+	users.AssertExpectations(t)
+}
+
+func TestUpdateProfile_GivenMalformedIfMatch_WhenUpdating_ThenReturnsBadRequest(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	userID := testkit.DefaultUserID.String()
+
+	req := authorizedRequest(t, app, userID, http.MethodPut, "/api/users/profile", `{"first_name":"Janet"}`)
+	req.Header.Set("If-Match", "3")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	users.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdatePreferences_GivenIfMatch_WhenUpdating_ThenReturnsNextVersionETag(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	userID := testkit.DefaultUserID.String()
+	users.On("UpdatePreferences", mock.Anything, userID, mock.MatchedBy(func(prefs user.UserPreferences) bool {
+		return prefs.Version == 4 && prefs.Theme == "dark"
+	})).Return(nil)
+
+	req := authorizedRequest(t, app, userID, http.MethodPut, "/api/users/preferences", `{"theme":"dark"}`)
+	req.Header.Set("If-Match", `"4"`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+	users.AssertExpectations(t)
+}
+
+func TestGetPreferenceSchema_GivenAuthenticatedCaller_WhenRequested_ThenListsNotificationTypes(t *testing.T) {
+	app, _, _ := newAdminTestApp(t)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/preferences/schema", "")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"type":"task_assigned","domain":"tasks","default":true`)
+}
+
+func TestUploadAvatar_GivenImage_WhenUploading_ThenStoresCallerAvatar(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("UploadAvatar", mock.Anything, "user-1", mock.Anything, "image/png").Return(nil)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodPut, "/api/users/avatar", "\x89PNG")
+	req.Header.Set("Content-Type", "image/png; charset=binary")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	users.AssertExpectations(t)
+}
+
+func TestUploadAvatar_GivenOversizedImage_WhenUploading_ThenReturnsEntityTooLarge(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("UploadAvatar", mock.Anything, "user-1", mock.Anything, "image/png").
+		Return(fmt.Errorf("failed to write object: %w", user.ErrAvatarTooLarge))
+
+	req := authorizedRequest(t, app, "user-1", http.MethodPut, "/api/users/avatar", "\x89PNG")
+	req.Header.Set("Content-Type", "image/png")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), user.ErrAvatarTooLarge.Code)
+}
+
+func TestGetAvatarURL_GivenNoAvatar_WhenRequesting_ThenReturnsNotFound(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("GetAvatarURL", mock.Anything, "user-1").Return("", user.ErrAvatarNotFound)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/avatar", ""))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMedia_GivenLocalStorage_WhenRequestingStoredAvatar_ThenServesImage(t *testing.T) {
+	app, _, _ := newAdminTestApp(t)
+	avatars, err := storageLocal.NewService(storageLocal.Config{Root: t.TempDir(), BaseURL: mediaPath})
+	require.NoError(t, err)
+	_, err = avatars.Put(t.Context(), "avatars/user-1/a.png", strings.NewReader("png-bytes"), "image/png")
+	require.NoError(t, err)
+	app.storage = avatars
+	app.config.StorageProvider = "local"
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/media/avatars/user-1/a.png", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "png-bytes", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/media/avatars/user-1/missing.png", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestLogin_GivenLockedAccount_WhenLoggingIn_ThenReturnsLocked(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("Login", mock.Anything, "jane.doe@example.com", "Password123!").Return(nil, user.ErrAccountLocked)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"jane.doe@example.com","password":"Password123!"}`))
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusLocked, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"ACCOUNT_LOCKED"`)
+}
+
+func TestLogin_GivenUnverifiedEmailAfterGracePeriod_WhenLoggingIn_ThenReturnsForbidden(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("Login", mock.Anything, "jane.doe@example.com", "Password123!").Return(nil, user.ErrEmailNotVerified)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"jane.doe@example.com","password":"Password123!"}`))
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"EMAIL_NOT_VERIFIED"`)
+}
+
+func TestVerifyEmail_GivenToken_WhenVerifyingWithoutSigningIn_ThenReturnsVerifiedUser(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	verified := testkit.NewUserBuilder().Build()
+	verifiedAt := testkit.Epoch
+	verified.EmailVerifiedAt = &verifiedAt
+	users.On("VerifyEmail", mock.Anything, "verify-token").Return(verified, nil)
+	users.On("VerifyEmail", mock.Anything, "expired-token").Return(nil, user.ErrInvalidVerifyToken)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+		body     string
+	}{
+		{name: "Given a valid token, When verifying, Then returns the verified user", token: "verify-token", expected: http.StatusOK, body: `"email_verified_at"`},
+		{name: "Given an expired token, When verifying, Then returns bad request", token: "expired-token", expected: http.StatusBadRequest, body: `"INVALID_VERIFICATION_TOKEN"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/verify-email", strings.NewReader(`{"token":"`+tt.token+`"}`))
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.body)
+		})
+	}
+}
+
+func TestRequestPasswordReset_GivenAnyEmail_WhenRequesting_ThenAnswersAlikeUnlessRateLimited(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("RequestPasswordReset", mock.Anything, "jane@example.com").Return(testkit.NewUserBuilder().Build(), nil)
+	users.On("RequestPasswordReset", mock.Anything, "nobody@example.com").Return(nil, user.ErrUserNotFound)
+	users.On("RequestPasswordReset", mock.Anything, "gone@example.com").Return(nil, user.ErrAccountDeactivated)
+	users.On("RequestPasswordReset", mock.Anything, "busy@example.com").Return(nil, user.ErrRateLimited)
+
+	tests := []struct {
+		name     string
+		email    string
+		expected int
+	}{
+		{name: "Given a user's email, When requesting, Then returns accepted", email: "jane@example.com", expected: http.StatusAccepted},
+		{name: "Given an unknown email, When requesting, Then returns accepted", email: "nobody@example.com", expected: http.StatusAccepted},
+		{name: "Given a deactivated user's email, When requesting, Then returns accepted", email: "gone@example.com", expected: http.StatusAccepted},
+		{name: "Given too many requests, When requesting, Then returns too many requests", email: "busy@example.com", expected: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/password-reset/request", strings.NewReader(`{"email":"`+tt.email+`"}`))
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestConfirmPasswordReset_GivenToken_WhenResettingWithoutSigningIn_ThenReplacesPassword(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("ResetPassword", mock.Anything, "reset-token", "NewPassword456!").Return(nil)
+	users.On("ResetPassword", mock.Anything, "used-token", "NewPassword456!").Return(user.ErrInvalidResetToken)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+		body     string
+	}{
+		{name: "Given a valid token, When resetting, Then returns no content", token: "reset-token", expected: http.StatusNoContent},
+		{name: "Given a used token, When resetting, Then returns bad request", token: "used-token", expected: http.StatusBadRequest, body: `"INVALID_PASSWORD_RESET_TOKEN"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/password-reset/confirm", strings.NewReader(`{"token":"`+tt.token+`","new_password":"NewPassword456!"}`))
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.body)
+		})
+	}
+}
+
+// newAuthTestApp returns a test application whose auth service introspects
+// and revokes the tokens of its token service
+func newAuthTestApp(t *testing.T) *application {
+	t.Helper()
+	app, _, _ := newAdminTestApp(t)
+	tokenManager := authUsecase.NewJWTTokenManager(testkit.TestSecret, time.Hour, time.Hour)
+	app.auth = authUsecase.NewAuthOrchestratorWithTokenService(tokenManager, app.token)
+	return app
+}
+
+func TestLogoutAll_GivenSignedInUser_WhenLoggingOutEverywhere_ThenRevokesAllTheirTokens(t *testing.T) {
+	app := newAuthTestApp(t)
+	otherSession, _, err := app.token.GenerateAuthToken(t.Context(), "user-1", "user-1@example.com")
+	require.NoError(t, err)
+	otherUser, _, err := app.token.GenerateAuthToken(t.Context(), "user-2", "user-2@example.com")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/auth/logout-all", ""))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = app.token.ValidateToken(t.Context(), otherSession)
+	assert.Error(t, err)
+	_, err = app.token.ValidateToken(t.Context(), otherUser)
+	assert.NoError(t, err)
+}
+
+func TestIntrospect_GivenTokens_WhenIntrospecting_ThenDescribesThemLikeRFC7662(t *testing.T) {
+	app := newAuthTestApp(t)
+	active, _, err := app.token.GenerateAuthToken(t.Context(), "user-2", "user-2@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected auth.TokenIntrospection
+	}{
+		{
+			name:     "Given an active token, When introspecting, Then should describe it",
+			token:    active,
+			expected: auth.TokenIntrospection{Active: true, Username: "user-2@example.com", Subject: "user-2", TokenType: "auth", Strategy: "jwt"},
+		},
+		{
+			name:     "Given an invalid token, When introspecting, Then should only report it inactive",
+			token:    "not-a-token",
+			expected: auth.TokenIntrospection{Active: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"token":%q}`, tt.token)
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/auth/introspect", body))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			var introspection auth.TokenIntrospection
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &introspection))
+			introspection.ExpiresAt, introspection.IssuedAt = 0, 0
+			assert.Equal(t, tt.expected, introspection)
+		})
+	}
+}
+
+func TestIntrospect_GivenNoToken_WhenIntrospecting_ThenReturnsBadRequest(t *testing.T) {
+	app := newAuthTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/auth/introspect", `{}`))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestJWKS_GivenES256TokenService_WhenFetched_ThenPublishesKeyThatVerifiesIssuedTokens(t *testing.T) {
+	// Arrange
+	app, _, _ := newAdminTestApp(t)
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	config := token.DefaultTokenConfig()
+	config.Algorithm = token.AlgorithmES256
+	config.SigningKey = signingKey
+	app.token, err = tokenJWT.NewService(config)
+	require.NoError(t, err)
+	accessToken, _, err := app.token.GenerateAuthToken(t.Context(), "user-1", "user-1@example.com")
+	require.NoError(t, err)
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "max-age")
+	var keys token.JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &keys))
+
+	parsed, err := jwtlib.Parse(accessToken, func(issued *jwtlib.Token) (interface{}, error) {
+		jwk, ok := keys.Key(issued.Header["kid"].(string))
+		if !ok {
+			return nil, fmt.Errorf("unknown kid")
+		}
+		x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+		y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}, jwtlib.WithValidMethods([]string{token.AlgorithmES256}))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+}
+
+func TestRegister_GivenAcceptLanguage_WhenValidationFails_ThenPassesLanguagesAndListsFieldMessages(t *testing.T) {
+	app, _, users := newAdminTestApp(t)
+	users.On("Register", mock.MatchedBy(func(ctx context.Context) bool {
+		return slices.Equal(translator.LanguagesFromContext(ctx), []string{"es-mx", "es", "en"})
+	}), mock.Anything).Return(nil, validation.ValidationErrors{Errors: []validation.ValidationError{
+		{Field: "email", Message: "debe ser una dirección de correo electrónico válida", Rule: "email"},
+		{Field: "last_name", Message: "el campo es obligatorio", Rule: "required"},
+	}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"jane@"}`))
+	req.Header.Set("Accept-Language", "en;q=0.5, es-MX, es;q=0.8")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var body map[string]apiError
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, apiError{
+		Code:    "VALIDATION_FAILED",
+		Message: "email: debe ser una dirección de correo electrónico válida; last_name: el campo es obligatorio",
+		Field:   "email",
+	}, body["error"])
+}
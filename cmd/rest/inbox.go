@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	inboxMemory "github.com/gentra/decorator-arch-go/internal/inbox/memory"
+	inboxPostgres "github.com/gentra/decorator-arch-go/internal/inbox/postgres"
+	inboxPublisher "github.com/gentra/decorator-arch-go/internal/inbox/publisher"
+)
+
+// buildInbox opens the users' in-app inboxes, publishing their changes for
+// the realtime channel
+func (a *application) buildInbox() error {
+	var store inbox.Service
+	switch a.config.InboxStore {
+	case "", "memory":
+		store = inboxMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for INBOX_STORE=postgres")
+		}
+		store = inboxPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown INBOX_STORE %q", a.config.InboxStore)
+	}
+
+	a.inbox = inboxPublisher.NewService(store, a.publishInboxEvent)
+	return nil
+}
+
+// publishInboxEvent publishes inbox changes. The events service is built
+// after the notification service delivering to the inbox, so it is only
+// resolved when an event is published.
+func (a *application) publishInboxEvent(ctx context.Context, event events.Event) error {
+	if a.publisher == nil {
+		return nil
+	}
+	return a.publisher.Publish(ctx, event)
+}
+
+// handleListInbox returns a page of the user's in-app notifications with
+// their unread count; ?unread=true lists only unread ones
+func (a *application) handleListInbox(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := inbox.Query{Cursor: query.Get("cursor"), UnreadOnly: query.Get("unread") == "true"}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > inbox.MaxLimit {
+			badRequest(w, fmt.Sprintf("limit must be an integer between 1 and %d", inbox.MaxLimit))
+			return
+		}
+		q.Limit = parsed
+	}
+
+	page, err := a.inbox.List(r.Context(), claimsFromContext(r.Context()).UserID, q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (a *application) handleInboxUnreadCount(w http.ResponseWriter, r *http.Request) {
+	count, err := a.inbox.UnreadCount(r.Context(), claimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+func (a *application) handleMarkInboxRead(w http.ResponseWriter, r *http.Request) {
+	read, err := a.inbox.MarkRead(r.Context(), claimsFromContext(r.Context()).UserID, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, read)
+}
+
+func (a *application) handleMarkAllInboxRead(w http.ResponseWriter, r *http.Request) {
+	count, err := a.inbox.MarkAllRead(r.Context(), claimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"marked_read": count, "unread_count": 0})
+}
+
+// handleAdminSendInAppNotification delivers an in-app notification to a user
+func (a *application) handleAdminSendInAppNotification(w http.ResponseWriter, r *http.Request) {
+	var n inbox.Notification
+	if !decodeJSON(w, r, &n) {
+		return
+	}
+	n.ID, n.UserID, n.CreatedAt, n.ReadAt = "", r.PathValue("id"), time.Time{}, nil
+
+	delivered, err := a.inbox.Deliver(r.Context(), n)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, delivered)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+)
+
+func newInboxTestApp(t *testing.T) *application {
+	t.Helper()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	require.NoError(t, app.buildInbox())
+	return app
+}
+
+// serveUser serves a request made by the user
+func serveUser(t *testing.T, app *application, userID, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, userID, method, path, ""))
+	return rec
+}
+
+func TestInbox_GivenDeliveredNotifications_WhenTheUserReadsThem_ThenUpdatesTheUnreadCount(t *testing.T) {
+	// Arrange
+	app := newInboxTestApp(t)
+	for _, title := range []string{"Welcome", "Sale", "Reminder"} {
+		rec := serveAdmin(t, app, http.MethodPost, "/api/admin/users/user-1/notifications", `{"title":"`+title+`","body":"Hello"}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	// Act
+	rec := serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notifications?limit=2")
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page inbox.Page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Notifications, 2)
+	assert.Equal(t, "Reminder", page.Notifications[0].Title)
+	assert.Equal(t, 3, page.UnreadCount)
+	require.NotEmpty(t, page.NextCursor)
+
+	rec = serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notifications?limit=2&cursor="+page.NextCursor)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var next inbox.Page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &next))
+	require.Len(t, next.Notifications, 1)
+	assert.Equal(t, "Welcome", next.Notifications[0].Title)
+	assert.Empty(t, next.NextCursor)
+
+	// Act & Assert: another user cannot read the notification
+	rec = serveUser(t, app, "user-2", http.MethodPost, "/api/users/me/notifications/"+page.Notifications[0].ID+"/read")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Act & Assert: reading one leaves two unread
+	rec = serveUser(t, app, "user-1", http.MethodPost, "/api/users/me/notifications/"+page.Notifications[0].ID+"/read")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notifications/unread-count")
+	assert.JSONEq(t, `{"unread_count":2}`, rec.Body.String())
+
+	// Act & Assert: reading all leaves none unread
+	rec = serveUser(t, app, "user-1", http.MethodPost, "/api/users/me/notifications/read-all")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"marked_read":2,"unread_count":0}`, rec.Body.String())
+	rec = serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notifications?unread=true")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Empty(t, page.Notifications)
+}
+
+func TestInbox_GivenInvalidRequest_WhenListing_ThenRejectsIt(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Given a limit above the maximum, When listing, Then rejects it", path: "/api/users/me/notifications?limit=1000"},
+		{name: "Given a malformed cursor, When listing, Then rejects it", path: "/api/users/me/notifications?cursor=bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := newInboxTestApp(t)
+
+			// Act
+			rec := serveUser(t, app, "user-1", http.MethodGet, tt.path)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+// magicLinkRequest is the body of a magic link request
+type magicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// verifyMagicLinkRequest is the body of a magic link sign-in
+type verifyMagicLinkRequest struct {
+	Token string `json:"token"`
+}
+
+// handleRequestMagicLink emails a one-time sign-in token to the user with
+// the given email. Unknown and deactivated accounts get the same answer, so
+// the endpoint does not reveal which emails have an account.
+func (a *application) handleRequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req magicLinkRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	err := a.magicLinks.Send(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) && !errors.Is(err, user.ErrAccountDeactivated) {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleVerifyMagicLink signs in the user the token was sent to. Each token
+// works once, even when the link is opened on several instances at once.
+func (a *application) handleVerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req verifyMagicLinkRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	result, err := a.auth.Authenticate(r.Context(), authUsecase.MagicLinkStrategy, auth.MagicLinkCredentials{Token: req.Token})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Sessions are issued by the token service, so they refresh and revoke
+	// like those of password logins
+	a.writeSession(w, r, result.User.ID, token.AMROTP)
+}
+
+// writeSession issues an access and refresh token pair for a user who just
+// signed in with method and writes them with the user's view
+func (a *application) writeSession(w http.ResponseWriter, r *http.Request, userID, method string) {
+	signedIn, err := a.users.GetByID(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx := token.WithAuthentication(r.Context(), time.Now(), method)
+	accessToken, expiresAt, err := a.token.GenerateAuthToken(ctx, signedIn.ID.String(), signedIn.Email)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	refreshToken, err := a.token.GenerateRefreshToken(ctx, signedIn.ID.String())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	view, err := a.views.Render(r.Context(), selfSubject(signedIn), signedIn)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, userview.AuthResultView{
+		User:         view,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	})
+}
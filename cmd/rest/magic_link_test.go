@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/notification/dryrun"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	ratelimitMemory "github.com/gentra/decorator-arch-go/internal/ratelimit/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+// newMagicLinkTestApp returns an app emailing magic links into its outbox,
+// whose users include the testkit user
+func newMagicLinkTestApp(t *testing.T) *application {
+	t.Helper()
+	app, _, users := newAdminTestApp(t)
+	app.outbox = outboxMemory.NewService(10)
+	app.notification = dryrun.NewService(notificationMock.NewService(), app.outbox, dryrun.Config{Global: true})
+	app.magicLinks = authUsecase.NewMagicLinkSender(users, app.token, app.notification, authUsecase.MagicLinkConfig{})
+	tokenManager := authUsecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	app.auth = authUsecase.NewMagicLinkAuthStrategy(users, app.token, tokenManager)
+
+	jane := testkit.NewUserBuilder().Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{Users: []*user.User{jane}}, nil)
+	users.On("GetByID", mock.Anything, jane.ID.String()).Return(jane, nil)
+	return app
+}
+
+func TestMagicLink_GivenEmailedLink_WhenVerified_ThenReturnsSessionOnce(t *testing.T) {
+	// Arrange
+	app := newMagicLinkTestApp(t)
+	request := httptest.NewRecorder()
+	app.routes().ServeHTTP(request, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link", strings.NewReader(`{"email":"`+testkit.DefaultEmail+`"}`)))
+	require.Equal(t, http.StatusAccepted, request.Code)
+
+	messages, err := app.outbox.List(t.Context(), outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	magicLinkToken := messages[0].Body[strings.LastIndex(messages[0].Body, " ")+1:]
+	body := `{"token":"` + magicLinkToken + `"}`
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link/verify", strings.NewReader(body)))
+	replay := httptest.NewRecorder()
+	app.routes().ServeHTTP(replay, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link/verify", strings.NewReader(body)))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result userview.AuthResultView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.NotEmpty(t, result.RefreshToken)
+	claims, err := app.token.ValidateToken(t.Context(), result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{token.AMROTP}, claims.AMR)
+	assert.Equal(t, http.StatusUnauthorized, replay.Code)
+}
+
+func TestMagicLink_GivenUnknownEmail_WhenRequesting_ThenAcceptsWithoutSending(t *testing.T) {
+	app := newMagicLinkTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link", strings.NewReader(`{"email":"nobody@example.com"}`)))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	messages, err := app.outbox.List(t.Context(), outbox.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestMagicLink_GivenRepeatedRequests_WhenOverTheLimit_ThenIsRateLimitedWithoutSending(t *testing.T) {
+	// Arrange
+	app := newMagicLinkTestApp(t)
+	sender, err := authUsecase.NewMagicLinkSenderWithLimits(app.users, app.token, app.notification, ratelimitMemory.NewService(nil), authUsecase.MagicLinkConfig{
+		PerEmail: ratelimit.RateLimitConfig{Limit: 1, Window: time.Hour},
+	})
+	require.NoError(t, err)
+	app.magicLinks = sender
+	body := `{"email":"` + testkit.DefaultEmail + `"}`
+
+	// Act
+	first := httptest.NewRecorder()
+	app.routes().ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link", strings.NewReader(body)))
+	second := httptest.NewRecorder()
+	app.routes().ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/api/auth/magic-link", strings.NewReader(body)))
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	messages, err := app.outbox.List(t.Context(), outbox.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
@@ -0,0 +1,105 @@
+// Command rest runs the REST API server.
+//
+// With --selftest it builds every factory, pings dependencies, issues and
+// validates a test token and performs a dry-run validation, prints a JSON
+// report and exits non-zero if any check failed. This makes it usable as an
+// init-container gate before rollout.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func main() {
+	cfg := loadConfig()
+
+	flag.StringVar(&cfg.Addr, "addr", cfg.Addr, "HTTP listen address")
+	selfTest := flag.Bool("selftest", false, "run startup self-test and exit")
+	flag.Parse()
+
+	if *selfTest {
+		report := runSelfTest(context.Background(), cfg)
+		if err := report.Write(os.Stdout); err != nil {
+			log.Printf("Failed to write self-test report: %v", err)
+		}
+		if !report.Passed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := serve(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve builds the application and runs the HTTP server until interrupted
+func serve(cfg config) error {
+	app, err := newApplication(cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	// Honour W3C trace context from callers; spans are exported once a
+	// tracer provider is installed with otel.SetTracerProvider
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           app.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.PrefsCleanupInterval > 0 {
+		go app.runPreferenceCleanup(ctx, cfg.PrefsCleanupInterval, cfg.PrefsCleanupStrip)
+	}
+	if cfg.RevocationCleanupInterval > 0 {
+		go app.runRevocationCleanup(ctx, cfg.RevocationCleanupInterval)
+	}
+	if app.liveRules != nil && cfg.ValidationRuleReloadInterval > 0 {
+		go app.runValidationRuleReload(ctx, cfg.ValidationRuleReloadInterval)
+	}
+	if app.eventOutbox != nil && cfg.EventRelayInterval > 0 {
+		go app.runEventRelay(ctx, cfg.EventRelayInterval)
+	}
+	if app.schedule != nil && cfg.NotificationSchedulerInterval > 0 {
+		go app.runNotificationScheduler(ctx, cfg.NotificationSchedulerInterval)
+	}
+	if app.digests != nil && cfg.NotificationDigestInterval > 0 {
+		go app.runNotificationDigests(ctx, cfg.NotificationDigestInterval)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", cfg.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+const correlationIDHeader = "X-Correlation-ID"
+
+// causationIDHeader names the request or event that caused the request
+const causationIDHeader = "X-Causation-ID"
+
+// notificationDryRunHeader lets administrators, or any caller where
+// NotificationDryRunHeader is enabled, capture the request's notifications in
+// the outbox instead of delivering them
+const notificationDryRunHeader = "X-Notification-Dry-Run"
+
+// idempotencyKeyHeader lets a client retry a mutating request without
+// repeating it; the original result is returned instead
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// deviceFingerprintHeader carries a fingerprint the client computed for its
+// device, recognizing it more reliably than the user agent
+const deviceFingerprintHeader = "X-Device-Fingerprint"
+
+// captchaTokenHeader carries the token the client got from solving a captcha
+const captchaTokenHeader = "X-Captcha-Token"
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+// withCorrelationID tags every request with a correlation ID, reusing the
+// caller's ID when present, and echoes it in the response headers. The
+// request is the cause of what it does: it gets its own causation ID, the
+// caller's when it sends one, which events and audit entries of the
+// request carry with the correlation ID.
+func withCorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(correlationIDHeader)
+		if correlationID == "" {
+			correlationID = r.Header.Get("X-Request-ID")
+		}
+		if correlationID == "" {
+			correlationID = correlation.NewID()
+		}
+		causationID := r.Header.Get(causationIDHeader)
+		if causationID == "" {
+			causationID = correlation.NewID()
+		}
+
+		w.Header().Set(correlationIDHeader, correlationID)
+		w.Header().Set(causationIDHeader, causationID)
+		ctx := correlation.WithIDs(r.Context(), correlationID, causationID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withClientIP stores the caller's IP address for per-IP protections
+func withClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := user.WithClientIP(r.Context(), clientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withDevice stores the caller's user agent and device fingerprint, so logins
+// from unrecognized devices can be told apart, and records the device on the
+// tokens issued to it
+func withDevice(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := user.WithUserAgent(r.Context(), r.UserAgent())
+		ctx = user.WithDeviceFingerprint(ctx, r.Header.Get(deviceFingerprintHeader))
+		ctx = token.WithDevice(ctx, r.UserAgent(), clientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withLanguage stores the languages of the caller's Accept-Language header,
+// in which validation messages are rendered
+func withLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if languages := translator.ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(languages) > 0 {
+			r = r.WithContext(translator.WithLanguages(r.Context(), languages))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withCaptchaToken stores the caller's captcha token for the user service
+func withCaptchaToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get(captchaTokenHeader); token != "" {
+			r = r.WithContext(user.WithCaptchaToken(r.Context(), token))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withIdempotencyKey stores the caller's idempotency key for the user service
+func withIdempotencyKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if len(key) > maxIdempotencyKeyLength {
+			badRequest(w, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		if key != "" {
+			r = r.WithContext(user.WithIdempotencyKey(r.Context(), key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withNotificationDryRun marks requests that opt into notification dry-run
+// mode when any caller may, outside production. Otherwise only administrators
+// may, which requireAuth checks once the caller is known, so that nobody can
+// keep the security emails of a login or a password reset from their user.
+func (a *application) withNotificationDryRun(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.config.NotificationDryRunHeader && !a.config.Production && dryRunRequested(r) {
+			r = r.WithContext(notification.WithDryRun(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// dryRunRequested reports whether the request asks for notification dry-run mode
+func dryRunRequested(r *http.Request) bool {
+	return r.Header.Get(notificationDryRunHeader) == "true"
+}
+
+// clientIP returns the remote address of the request without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
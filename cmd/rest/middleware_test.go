@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+)
+
+func TestWithCorrelationID_GivenRequestHeaders_WhenServing_ThenPropagatesAndEchoesIDs(t *testing.T) {
+	tests := []struct {
+		name                string
+		headers             map[string]string
+		expectedCorrelation string
+		expectedCausation   string
+	}{
+		{
+			name:                "Given correlation and causation headers, When serving, Then uses the caller's IDs",
+			headers:             map[string]string{correlationIDHeader: "request-1", causationIDHeader: "cause-1"},
+			expectedCorrelation: "request-1",
+			expectedCausation:   "cause-1",
+		},
+		{
+			name:                "Given only a request ID, When serving, Then correlates with it",
+			headers:             map[string]string{"X-Request-ID": "request-2"},
+			expectedCorrelation: "request-2",
+		},
+		{
+			name: "Given no headers, When serving, Then generates both IDs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var correlationID, causationID string
+			handler := withCorrelationID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				correlationID = correlation.CorrelationID(r.Context())
+				causationID = correlation.CausationID(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.NotEmpty(t, correlationID)
+			assert.NotEmpty(t, causationID)
+			if tt.expectedCorrelation != "" {
+				assert.Equal(t, tt.expectedCorrelation, correlationID)
+			}
+			if tt.expectedCausation != "" {
+				assert.Equal(t, tt.expectedCausation, causationID)
+			}
+			assert.Equal(t, correlationID, rec.Header().Get(correlationIDHeader))
+			assert.Equal(t, causationID, rec.Header().Get(causationIDHeader))
+		})
+	}
+}
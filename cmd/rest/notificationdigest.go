@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	notificationDigestMemory "github.com/gentra/decorator-arch-go/internal/notificationdigest/memory"
+	notificationDigestPostgres "github.com/gentra/decorator-arch-go/internal/notificationdigest/postgres"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// buildNotificationDigests opens the store notifications are held in for
+// the users' digests
+func (a *application) buildNotificationDigests() error {
+	switch a.config.NotificationDigestStore {
+	case "", "memory":
+		a.digests = notificationDigestMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for NOTIFICATION_DIGEST_STORE=postgres")
+		}
+		a.digests = notificationDigestPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown NOTIFICATION_DIGEST_STORE %q", a.config.NotificationDigestStore)
+	}
+	return nil
+}
+
+// runNotificationDigests sends the due digests every interval until the
+// context is cancelled
+func (a *application) runNotificationDigests(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.deliverNotificationDigests(ctx)
+		}
+	}
+}
+
+// deliverNotificationDigests runs a single delivery of the due digests
+func (a *application) deliverNotificationDigests(ctx context.Context) {
+	report, err := a.digests.Deliver(ctx, notificationdigest.DeliveryOptions{
+		Limit: a.config.NotificationDigestBatch,
+	}, notificationdigest.SendWith(a.notification, a.templates, a.digestEmail))
+	if err != nil {
+		log.Printf("Notification digest delivery failed: %v", err)
+		return
+	}
+	if report.Retried > 0 || report.Dropped > 0 {
+		log.Printf("Notification digest delivery sent %d digests, %d will be retried and %d were dropped",
+			report.Sent, report.Retried, report.Dropped)
+	}
+}
+
+// digestEmail returns the address of the user a digest is for
+func (a *application) digestEmail(ctx context.Context, userID string) (string, error) {
+	u, err := a.users.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return u.Email, nil
+}
+
+func (a *application) handleGetDigestSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := a.digests.GetSettings(r.Context(), claimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// handleUpdateDigestSettings sets how often the user gets a digest and the
+// notification types it collects, which must be in the preference schema
+func (a *application) handleUpdateDigestSettings(w http.ResponseWriter, r *http.Request) {
+	var settings notificationdigest.Settings
+	if !decodeJSON(w, r, &settings) {
+		return
+	}
+	settings.UserID = claimsFromContext(r.Context()).UserID
+	schema := user.DefaultPreferenceSchema()
+	for _, notificationType := range settings.Types {
+		if !schema.IsKnown(user.NotificationType(notificationType)) {
+			writeError(w, notificationdigest.DigestError{
+				Code:    notificationdigest.ErrInvalidSettings.Code,
+				Message: fmt.Sprintf("unknown notification type %q", notificationType),
+				Field:   "types",
+			})
+			return
+		}
+	}
+
+	updated, err := a.digests.UpdateSettings(r.Context(), settings)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// handleGetPendingDigest returns the notifications held for the user's next
+// digest, if any
+func (a *application) handleGetPendingDigest(w http.ResponseWriter, r *http.Request) {
+	userID := claimsFromContext(r.Context()).UserID
+	pending, err := a.digests.Pending(r.Context(), userID)
+	if errors.Is(err, notificationdigest.ErrNotFound) {
+		pending, err = &notificationdigest.Digest{UserID: userID, Items: []notificationdigest.Item{}}, nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// digestEmailRecorder records the digest emails sent
+type digestEmailRecorder struct {
+	notification.Service
+	sent []notification.EmailNotification
+}
+
+func (d *digestEmailRecorder) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	d.sent = append(d.sent, emails...)
+	return nil
+}
+
+func newDigestTestApp(t *testing.T) (*application, *digestEmailRecorder) {
+	t.Helper()
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("GetByID", mock.Anything, "user-1").Return(&user.User{Email: "jane@example.com"}, nil)
+	require.NoError(t, app.buildNotificationDigests())
+	app.templates = embedded.MustNewService(embedded.Config{})
+	sender := &digestEmailRecorder{Service: notificationMock.NewService()}
+	app.notification = sender
+	return app, sender
+}
+
+// serveDigest serves a digest request made by user-1
+func serveDigest(t *testing.T, app *application, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", method, path, body))
+	return rec
+}
+
+func TestNotificationDigest_GivenOptedInUser_WhenTheDigestIsDue_ThenSendsOneSummaryEmail(t *testing.T) {
+	// Arrange
+	app, sender := newDigestTestApp(t)
+	rec := serveDigest(t, app, http.MethodPut, "/api/users/me/notification-digest",
+		`{"frequency":"daily","deliver_at":"09:00","types":["marketing","system_updates"]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	for _, title := range []string{"Spring sale", "New dashboard"} {
+		_, err := app.digests.Hold(t.Context(), notificationdigest.Item{UserID: "user-1", Type: "marketing", Title: title},
+			time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+	}
+
+	rec = serveDigest(t, app, http.MethodGet, "/api/users/me/notification-digest/pending", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var pending notificationdigest.Digest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pending))
+	assert.Len(t, pending.Items, 2)
+
+	// Act
+	app.deliverNotificationDigests(t.Context())
+
+	// Assert
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "jane@example.com", sender.sent[0].To)
+	assert.Equal(t, "Your notification digest: 2 new", sender.sent[0].Subject)
+	assert.True(t, strings.Contains(sender.sent[0].Body, "- Spring sale\n- New dashboard"), sender.sent[0].Body)
+
+	rec = serveDigest(t, app, http.MethodGet, "/api/users/me/notification-digest", "")
+	var settings notificationdigest.Settings
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &settings))
+	assert.Equal(t, notificationdigest.FrequencyDaily, settings.Frequency)
+	assert.Equal(t, []string{"marketing", "system_updates"}, settings.Types)
+	rec = serveDigest(t, app, http.MethodGet, "/api/users/me/notification-digest/pending", "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pending))
+	assert.Empty(t, pending.Items)
+}
+
+func TestNotificationDigest_GivenInvalidSettings_WhenUpdating_ThenRejectsThem(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{name: "Given an unknown frequency, When updating, Then rejects it", body: `{"frequency":"weekly","types":["marketing"]}`, expectedField: "frequency"},
+		{name: "Given a type outside the preference schema, When updating, Then rejects it", body: `{"frequency":"hourly","types":["gossip"]}`, expectedField: "types"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _ := newDigestTestApp(t)
+
+			// Act
+			rec := serveDigest(t, app, http.MethodPut, "/api/users/me/notification-digest", tt.body)
+
+			// Assert
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			var body map[string]apiError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, notificationdigest.ErrInvalidSettings.Code, body["error"].Code)
+			assert.Equal(t, tt.expectedField, body["error"].Field)
+		})
+	}
+}
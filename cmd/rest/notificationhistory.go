@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+)
+
+// historyFilter reads the type, status, from and to query parameters,
+// answering 400 and reporting false when one is malformed
+func historyFilter(w http.ResponseWriter, query url.Values) (notificationhistory.Filter, bool) {
+	filter := notificationhistory.Filter{
+		Type:   notification.NotificationType(query.Get("type")),
+		Status: notification.NotificationStatus(query.Get("status")),
+	}
+	var err error
+	if filter.From, err = queryTime(query, "from"); err != nil {
+		badRequest(w, "from must be an RFC 3339 time")
+		return filter, false
+	}
+	if filter.To, err = queryTime(query, "to"); err != nil {
+		badRequest(w, "to must be an RFC 3339 time")
+		return filter, false
+	}
+	return filter, true
+}
+
+// historyQuery reads a page of the notification history selected by the
+// query parameters, for userID when set
+func (a *application) historyQuery(w http.ResponseWriter, r *http.Request, userID string) {
+	query := r.URL.Query()
+	filter, ok := historyFilter(w, query)
+	if !ok {
+		return
+	}
+	filter.UserID = userID
+	limit, err := queryInt(query, "limit")
+	if err != nil || limit < 0 || limit > notificationhistory.MaxLimit {
+		badRequest(w, fmt.Sprintf("limit must be an integer between 1 and %d", notificationhistory.MaxLimit))
+		return
+	}
+	filter.Limit = limit
+
+	page, err := a.history.Query(r.Context(), notificationhistory.Query{Filter: filter, Cursor: query.Get("cursor")})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleListMyNotificationHistory returns a page of the notifications sent
+// to the user, newest first, with their delivery status
+func (a *application) handleListMyNotificationHistory(w http.ResponseWriter, r *http.Request) {
+	a.historyQuery(w, r, claimsFromContext(r.Context()).UserID)
+}
+
+// handleListNotificationHistory returns a page of the notifications sent to
+// any user, or to ?user_id= only
+func (a *application) handleListNotificationHistory(w http.ResponseWriter, r *http.Request) {
+	a.historyQuery(w, r, r.URL.Query().Get("user_id"))
+}
+
+// handleNotificationStats returns the delivery and failure rates per
+// channel of the notifications sent in the ?from= and ?to= range
+func (a *application) handleNotificationStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, ok := historyFilter(w, query)
+	if !ok {
+		return
+	}
+	filter.UserID = query.Get("user_id")
+
+	stats, err := a.history.Stats(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	notificationHistoryMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+)
+
+func newHistoryTestApp(t *testing.T) *application {
+	t.Helper()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.history = notificationHistoryMemory.NewService()
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, entry := range []notification.NotificationHistory{
+		{UserID: "user-1", Type: notification.NotificationTypeEmail, Body: "Welcome", Status: notification.NotificationStatusDelivered},
+		{UserID: "user-2", Type: notification.NotificationTypeSMS, Body: "Code", Status: notification.NotificationStatusFailed},
+		{UserID: "user-1", Type: notification.NotificationTypeSMS, Body: "Reminder", Status: notification.NotificationStatusDelivered},
+		{UserID: "user-1", Type: notification.NotificationTypeEmail, Body: "Sale", Status: notification.NotificationStatusSent},
+	} {
+		entry.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		_, err := app.history.Record(t.Context(), entry)
+		require.NoError(t, err)
+	}
+	return app
+}
+
+func TestNotificationHistory_GivenRecordedNotifications_WhenTheUserPages_ThenReturnsOnlyTheirs(t *testing.T) {
+	// Arrange
+	app := newHistoryTestApp(t)
+
+	// Act
+	rec := serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notification-history?limit=2")
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page notificationhistory.Page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Notifications, 2)
+	assert.Equal(t, "Sale", page.Notifications[0].Body)
+	assert.Equal(t, "Reminder", page.Notifications[1].Body)
+	require.NotEmpty(t, page.NextCursor)
+
+	rec = serveUser(t, app, "user-1", http.MethodGet, "/api/users/me/notification-history?limit=2&cursor="+page.NextCursor)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var next notificationhistory.Page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &next))
+	require.Len(t, next.Notifications, 1)
+	assert.Equal(t, "Welcome", next.Notifications[0].Body)
+	assert.Empty(t, next.NextCursor)
+}
+
+func TestNotificationHistory_GivenAdminFilters_WhenListing_ThenReturnsTheMatchingNotifications(t *testing.T) {
+	// Arrange
+	app := newHistoryTestApp(t)
+
+	// Act
+	rec := serveAdmin(t, app, http.MethodGet, "/api/admin/notification-history?type=sms&from=2026-03-01T10:00:00Z&to=2026-03-01T12:00:00Z", "")
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page notificationhistory.Page
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Notifications, 2)
+	assert.Equal(t, "user-1", page.Notifications[0].UserID)
+	assert.Equal(t, "user-2", page.Notifications[1].UserID)
+
+	// Act & Assert: ?user_id= narrows the list to one user
+	rec = serveAdmin(t, app, http.MethodGet, "/api/admin/notification-history?type=sms&user_id=user-2", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Notifications, 1)
+	assert.Equal(t, "Code", page.Notifications[0].Body)
+
+	// Act & Assert: users cannot list other users' history
+	rec = serveUser(t, app, "user-1", http.MethodGet, "/api/admin/notification-history")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestNotificationHistory_GivenRecordedNotifications_WhenAggregating_ThenReturnsRatesPerChannel(t *testing.T) {
+	// Arrange
+	app := newHistoryTestApp(t)
+
+	// Act
+	rec := serveAdmin(t, app, http.MethodGet, "/api/admin/notification-history/stats", "")
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var stats notificationhistory.Stats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	require.Len(t, stats.Channels, 2)
+	assert.Equal(t, notification.NotificationTypeEmail, stats.Channels[0].Channel)
+	assert.Equal(t, 0.5, stats.Channels[0].DeliveryRate)
+	assert.Equal(t, notification.NotificationTypeSMS, stats.Channels[1].Channel)
+	assert.Equal(t, 0.5, stats.Channels[1].FailureRate)
+	assert.Equal(t, 4, stats.Overall.Total)
+	assert.Equal(t, 0.25, stats.Overall.FailureRate)
+}
+
+func TestNotificationHistory_GivenInvalidRequest_WhenQuerying_ThenRejectsIt(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Given a malformed from, When listing, Then rejects it", path: "/api/admin/notification-history?from=yesterday"},
+		{name: "Given a reversed range, When aggregating, Then rejects it", path: "/api/admin/notification-history/stats?from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z"},
+		{name: "Given a limit above the maximum, When listing, Then rejects it", path: "/api/admin/notification-history?limit=500"},
+		{name: "Given a malformed cursor, When listing, Then rejects it", path: "/api/admin/notification-history?cursor=bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := newHistoryTestApp(t)
+
+			// Act
+			rec := serveAdmin(t, app, http.MethodGet, tt.path, "")
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	notificationScheduleHistory "github.com/gentra/decorator-arch-go/internal/notificationschedule/history"
+	notificationScheduleMemory "github.com/gentra/decorator-arch-go/internal/notificationschedule/memory"
+	notificationSchedulePostgres "github.com/gentra/decorator-arch-go/internal/notificationschedule/postgres"
+)
+
+// defaultScheduledNotificationLimit caps the scheduled notifications listed
+// when the request sets no limit
+const defaultScheduledNotificationLimit = 100
+
+// scheduleRequest is the body scheduling a notification: the payload of its
+// channel and either an absolute send_at or a local_time ("HH:MM") sent at
+// its next occurrence in timezone, which defaults to the user's
+type scheduleRequest struct {
+	Channel   notification.NotificationType   `json:"channel"`
+	UserID    string                          `json:"user_id"`
+	Email     *notification.EmailNotification `json:"email"`
+	Push      *notification.PushNotification  `json:"push"`
+	SMS       *notification.SMSNotification   `json:"sms"`
+	SendAt    *time.Time                      `json:"send_at"`
+	LocalTime string                          `json:"local_time"`
+	Timezone  string                          `json:"timezone"`
+}
+
+// buildNotificationSchedule opens the schedule notifications are queued in,
+// recording them in the notification history
+func (a *application) buildNotificationSchedule() error {
+	var schedule notificationschedule.Service
+	switch a.config.NotificationScheduleStore {
+	case "", "memory":
+		schedule = notificationScheduleMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for NOTIFICATION_SCHEDULE_STORE=postgres")
+		}
+		schedule = notificationSchedulePostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown NOTIFICATION_SCHEDULE_STORE %q", a.config.NotificationScheduleStore)
+	}
+
+	a.schedule = notificationScheduleHistory.NewService(schedule, a.history)
+	a.schedulerOwner = uuid.NewString()
+	return nil
+}
+
+// runNotificationScheduler sends the due scheduled notifications every
+// interval until the context is cancelled
+func (a *application) runNotificationScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.deliverScheduledNotifications(ctx)
+		}
+	}
+}
+
+// deliverScheduledNotifications runs a single delivery of the due
+// notifications leased to this instance
+func (a *application) deliverScheduledNotifications(ctx context.Context) {
+	report, err := a.schedule.Deliver(ctx, notificationschedule.DeliveryOptions{
+		Owner: a.schedulerOwner,
+		Limit: a.config.NotificationSchedulerBatch,
+		Lease: a.config.NotificationSchedulerLease,
+	}, notificationschedule.SendWith(a.notification))
+	if err != nil {
+		log.Printf("Scheduled notification delivery failed: %v", err)
+		return
+	}
+	if report.Retried > 0 || report.Failed > 0 {
+		log.Printf("Scheduled notification delivery sent %d notifications, %d will be retried and %d failed",
+			report.Sent, report.Retried, report.Failed)
+	}
+}
+
+func (a *application) handleListScheduledNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := notificationschedule.Filter{
+		UserID: query.Get("user_id"),
+		Status: notificationschedule.Status(query.Get("status")),
+		Limit:  defaultScheduledNotificationLimit,
+	}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			badRequest(w, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	scheduled, err := a.schedule.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduled)
+}
+
+// handleScheduleNotification queues a notification for later delivery
+func (a *application) handleScheduleNotification(w http.ResponseWriter, r *http.Request) {
+	var req scheduleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	scheduled := notificationschedule.Scheduled{
+		UserID:  req.UserID,
+		Channel: req.Channel,
+		Email:   req.Email,
+		Push:    req.Push,
+		SMS:     req.SMS,
+	}
+	switch {
+	case req.LocalTime != "":
+		timezone, err := a.scheduleTimezone(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		scheduled.SendAt, err = notificationschedule.NextLocalTime(time.Now(), timezone, req.LocalTime)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		scheduled.Timezone = timezone
+	case req.SendAt != nil:
+		scheduled.SendAt = *req.SendAt
+	}
+
+	created, err := a.schedule.Schedule(r.Context(), scheduled)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// scheduleTimezone returns the timezone of a local send time: the one
+// requested, or else the user's preferred one, or else UTC
+func (a *application) scheduleTimezone(ctx context.Context, req scheduleRequest) (string, error) {
+	if req.Timezone != "" || req.UserID == "" || a.users == nil {
+		return req.Timezone, nil
+	}
+	prefs, err := a.users.GetPreferences(ctx, req.UserID)
+	if err != nil {
+		return "", err
+	}
+	return prefs.Timezone, nil
+}
+
+func (a *application) handleGetScheduledNotification(w http.ResponseWriter, r *http.Request) {
+	scheduled, err := a.schedule.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduled)
+}
+
+// handleCancelScheduledNotification cancels a notification not sent yet
+func (a *application) handleCancelScheduledNotification(w http.ResponseWriter, r *http.Request) {
+	cancelled, err := a.schedule.Cancel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cancelled)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	notificationHistoryMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// pushRecorder records the push notifications the scheduler sends
+type pushRecorder struct {
+	notification.Service
+	sent []string
+}
+
+func (p *pushRecorder) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	p.sent = append(p.sent, userID+": "+push.Title)
+	return nil
+}
+
+func newScheduleTestApp(t *testing.T) (*application, *pushRecorder) {
+	t.Helper()
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("GetPreferences", mock.Anything, "user-7").Return(&user.UserPreferences{Timezone: "Asia/Tokyo"}, nil)
+	app.history = notificationHistoryMemory.NewService()
+	require.NoError(t, app.buildNotificationSchedule())
+	sender := &pushRecorder{Service: notificationMock.NewService()}
+	app.notification = sender
+	return app, sender
+}
+
+func TestScheduledNotifications_GivenDueNotification_WhenTheWorkerRuns_ThenSendsItAndRecordsHistory(t *testing.T) {
+	// Arrange
+	app, sender := newScheduleTestApp(t)
+	sendAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	rec := serveAdmin(t, app, http.MethodPost, "/api/admin/scheduled-notifications",
+		`{"channel":"push","user_id":"user-7","push":{"user_id":"user-7","title":"Trial ending","body":"3 days left"},"send_at":"`+sendAt+`"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created notificationschedule.Scheduled
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	// Act
+	app.deliverScheduledNotifications(t.Context())
+
+	// Assert
+	assert.Equal(t, []string{"user-7: Trial ending"}, sender.sent)
+	rec = serveAdmin(t, app, http.MethodGet, "/api/admin/scheduled-notifications/"+created.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var delivered notificationschedule.Scheduled
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &delivered))
+	assert.Equal(t, notificationschedule.StatusSent, delivered.Status)
+	entry, err := app.history.Get(t.Context(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, notification.NotificationStatusSent, entry.Status)
+	assert.Equal(t, "Trial ending", entry.Title)
+
+	rec = serveAdmin(t, app, http.MethodPost, "/api/admin/scheduled-notifications/"+created.ID+"/cancel", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestScheduledNotifications_GivenLocalTime_WhenScheduling_ThenResolvesItInTheTimezone(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		expectedTimezone string
+	}{
+		{
+			name:             "Given a timezone, When scheduling, Then sends at the local time there",
+			body:             `{"channel":"push","user_id":"user-7","push":{"user_id":"user-7","title":"Digest","body":"News"},"local_time":"09:00","timezone":"America/New_York"}`,
+			expectedTimezone: "America/New_York",
+		},
+		{
+			name:             "Given no timezone, When scheduling, Then uses the user's preferred one",
+			body:             `{"channel":"push","user_id":"user-7","push":{"user_id":"user-7","title":"Digest","body":"News"},"local_time":"09:00"}`,
+			expectedTimezone: "Asia/Tokyo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _ := newScheduleTestApp(t)
+
+			// Act
+			rec := serveAdmin(t, app, http.MethodPost, "/api/admin/scheduled-notifications", tt.body)
+
+			// Assert
+			require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+			var created notificationschedule.Scheduled
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+			assert.Equal(t, tt.expectedTimezone, created.Timezone)
+			location, err := time.LoadLocation(tt.expectedTimezone)
+			require.NoError(t, err)
+			local := created.SendAt.In(location)
+			assert.Equal(t, 9, local.Hour())
+			assert.Equal(t, 0, local.Minute())
+			assert.True(t, created.SendAt.After(time.Now()))
+		})
+	}
+}
+
+func TestScheduledNotifications_GivenPendingNotification_WhenCancelled_ThenTheWorkerSkipsIt(t *testing.T) {
+	// Arrange
+	app, sender := newScheduleTestApp(t)
+	rec := serveAdmin(t, app, http.MethodPost, "/api/admin/scheduled-notifications",
+		`{"channel":"push","user_id":"user-7","push":{"user_id":"user-7","title":"Sale","body":"20% off"},"local_time":"09:00"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created notificationschedule.Scheduled
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	// Act
+	rec = serveAdmin(t, app, http.MethodPost, "/api/admin/scheduled-notifications/"+created.ID+"/cancel", "")
+	app.deliverScheduledNotifications(t.Context())
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, sender.sent)
+	entry, err := app.history.Get(t.Context(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, notification.NotificationStatusCancelled, entry.Status)
+	rec = serveAdmin(t, app, http.MethodGet, "/api/admin/scheduled-notifications?status=cancelled", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed []notificationschedule.Scheduled
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, created.ID, listed[0].ID)
+}
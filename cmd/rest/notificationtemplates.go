@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	notificationTemplateMemory "github.com/gentra/decorator-arch-go/internal/notificationtemplate/memory"
+	notificationTemplatePostgres "github.com/gentra/decorator-arch-go/internal/notificationtemplate/postgres"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// templateRequest is the body saving a template variant; a channel or
+// variables left out are kept from the current variant, or else from the
+// variant of the default language
+type templateRequest struct {
+	Channel   notification.NotificationType   `json:"channel"`
+	Subject   string                          `json:"subject"`
+	Body      string                          `json:"body"`
+	HTML      string                          `json:"html"`
+	Variables []notificationtemplate.Variable `json:"variables"`
+}
+
+// previewRequest is the body previewing an unsaved template
+type previewRequest struct {
+	Template  notificationtemplate.Template `json:"template"`
+	Variables map[string]interface{}        `json:"variables"`
+}
+
+// renderRequest is the body rendering a template; without a language it
+// renders in the languages of the request's Accept-Language
+type renderRequest struct {
+	Language  string                 `json:"language"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// buildNotificationTemplates loads the shipped templates and puts the store
+// of the versions administrators save in front of them
+func (a *application) buildNotificationTemplates() error {
+	shipped, err := embedded.NewService(embedded.Config{
+		DefaultLanguage: a.config.DefaultLanguage,
+		Dir:             a.config.NotificationTemplateDir,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load notification templates: %w", err)
+	}
+
+	switch a.config.NotificationTemplateStore {
+	case "", "memory":
+		a.templates = notificationTemplateMemory.NewService(shipped, a.config.DefaultLanguage)
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for NOTIFICATION_TEMPLATE_STORE=postgres")
+		}
+		a.templates = notificationTemplatePostgres.NewService(shipped, a.pool, a.config.DefaultLanguage)
+	default:
+		return fmt.Errorf("unknown NOTIFICATION_TEMPLATE_STORE %q", a.config.NotificationTemplateStore)
+	}
+	return nil
+}
+
+func (a *application) handleListNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := a.templates.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, templates)
+}
+
+func (a *application) handleGetNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := a.templates.Get(r.Context(), r.PathValue("name"), r.PathValue("language"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+func (a *application) handleListNotificationTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := a.templates.Versions(r.Context(), r.PathValue("name"), r.PathValue("language"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+// handleSaveNotificationTemplate stores a new version of a template variant,
+// which notifications render from then on
+func (a *application) handleSaveNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var req templateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name, language := r.PathValue("name"), r.PathValue("language")
+	if req.Channel == "" || req.Variables == nil {
+		current, err := a.templates.Get(r.Context(), name, language)
+		if errors.Is(err, notificationtemplate.ErrTemplateNotFound) {
+			current, err = a.templates.Get(r.Context(), name, a.config.DefaultLanguage)
+		}
+		if err != nil && !errors.Is(err, notificationtemplate.ErrTemplateNotFound) {
+			writeError(w, err)
+			return
+		}
+		if current != nil && req.Channel == "" {
+			req.Channel = current.Channel
+		}
+		if current != nil && req.Variables == nil {
+			req.Variables = current.Variables
+		}
+	}
+
+	saved, err := a.templates.Save(r.Context(), notificationtemplate.Template{
+		Name:      name,
+		Language:  language,
+		Channel:   req.Channel,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		HTML:      req.HTML,
+		Variables: req.Variables,
+		UpdatedBy: claimsFromContext(r.Context()).UserID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// handleRevertNotificationTemplate drops the saved versions of a template
+// variant, going back to the shipped one
+func (a *application) handleRevertNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := a.templates.Revert(r.Context(), r.PathValue("name"), r.PathValue("language")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePreviewNotificationTemplate renders a template without saving it
+func (a *application) handlePreviewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	message, err := a.templates.Preview(r.Context(), req.Template, req.Variables)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, message)
+}
+
+// handleRenderNotificationTemplate renders the current version of a
+// template as notifications would
+func (a *application) handleRenderNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var req renderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	if req.Language != "" {
+		ctx = translator.WithLanguages(ctx, []string{req.Language})
+	}
+	message, err := a.templates.Render(ctx, r.PathValue("name"), req.Variables)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, message)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+)
+
+func TestNotificationTemplates_GivenAdminEdits_WhenRendering_ThenRendersTheCurrentVersion(t *testing.T) {
+	// Arrange
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	require.NoError(t, app.buildNotificationTemplates())
+	render := func() notificationtemplate.Message {
+		rec := serveAdmin(t, app, http.MethodPost, "/api/admin/notification-templates/welcome_email/render",
+			`{"language":"en","variables":{"name":"Jane"}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var message notificationtemplate.Message
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &message))
+		return message
+	}
+
+	// Act & Assert: the shipped version renders first
+	assert.Equal(t, "Hi Jane, welcome to our platform.", render().Body)
+
+	// Act & Assert: a saved version keeps the variables and renders from then on
+	rec := serveAdmin(t, app, http.MethodPut, "/api/admin/notification-templates/welcome_email/en",
+		`{"subject":"Welcome aboard","body":"Hello {{.name}}!"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var saved notificationtemplate.Template
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &saved))
+	assert.Equal(t, 1, saved.Version)
+	assert.Equal(t, "admin-1", saved.UpdatedBy)
+	assert.Equal(t, "Hello Jane!", render().Body)
+
+	// Act & Assert: a version using an undeclared variable is refused
+	rec = serveAdmin(t, app, http.MethodPut, "/api/admin/notification-templates/welcome_email/en",
+		`{"subject":"Welcome","body":"Hello {{.nickname}}!"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Act & Assert: reverting goes back to the shipped version
+	rec = serveAdmin(t, app, http.MethodGet, "/api/admin/notification-templates/welcome_email/en/versions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var versions []notificationtemplate.Template
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &versions))
+	assert.Len(t, versions, 2)
+	rec = serveAdmin(t, app, http.MethodDelete, "/api/admin/notification-templates/welcome_email/en", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "Hi Jane, welcome to our platform.", render().Body)
+}
+
+func TestNotificationTemplates_GivenUnsavedTemplate_WhenPreviewing_ThenRendersOrReportsIt(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Given a valid template, When previewing, Then renders it",
+			body:         `{"template":{"name":"promo","language":"en","channel":"push","subject":"Sale","body":"{{.percent}}% off","variables":[{"name":"percent","required":true}]},"variables":{"percent":20}}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "20% off",
+		},
+		{
+			name:         "Given a required variable left out, When previewing, Then reports it",
+			body:         `{"template":{"name":"promo","language":"en","channel":"push","subject":"Sale","body":"{{.percent}}% off","variables":[{"name":"percent","required":true}]}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: notificationtemplate.ErrMissingVariable.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, auditSvc, _ := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+			require.NoError(t, app.buildNotificationTemplates())
+
+			// Act
+			rec := serveAdmin(t, app, http.MethodPost, "/api/admin/notification-templates/preview", tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func (a *application) handleRegisterOAuthClient(w http.ResponseWriter, r *http.Request) {
+	var req oauthserver.ClientRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.CreatedBy = claimsFromContext(r.Context()).UserID
+
+	credentials, err := a.oauthServer.RegisterClient(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, credentials)
+}
+
+func (a *application) handleListOAuthClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := a.oauthServer.ListClients(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, clients)
+}
+
+func (a *application) handleGetOAuthClient(w http.ResponseWriter, r *http.Request) {
+	client, err := a.oauthServer.GetClient(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, client)
+}
+
+func (a *application) handleDeleteOAuthClient(w http.ResponseWriter, r *http.Request) {
+	if err := a.oauthServer.DeleteClient(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleOAuthAuthorize is the authorization endpoint of RFC 6749 section 3.1.
+// The signed-in user's token authorizes the client; the user agent is sent
+// back to the client's redirect URI with a code, or with an error once the
+// redirect URI is known to be the client's.
+func (a *application) handleOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	if claims.IsImpersonationToken() {
+		writeError(w, user.ErrForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, oauthserver.ErrInvalidRequest)
+		return
+	}
+
+	req := oauthserver.AuthorizeRequest{
+		ResponseType:        r.Form.Get("response_type"),
+		ClientID:            r.Form.Get("client_id"),
+		RedirectURI:         r.Form.Get("redirect_uri"),
+		Scopes:              oauthserver.ParseScope(r.Form.Get("scope")),
+		State:               r.Form.Get("state"),
+		CodeChallenge:       r.Form.Get("code_challenge"),
+		CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+		UserID:              claims.UserID,
+	}
+
+	code, err := a.oauthServer.Authorize(r.Context(), req)
+	if err != nil {
+		var oauthErr oauthserver.OAuthError
+		if !errors.As(err, &oauthErr) || errors.Is(err, oauthserver.ErrInvalidClient) || errors.Is(err, oauthserver.ErrInvalidRedirectURI) {
+			// Never redirect to a URI that is not the client's
+			writeError(w, err)
+			return
+		}
+
+		params := url.Values{"error": {oauthErr.Code}, "error_description": {oauthErr.Message}}
+		if req.State != "" {
+			params.Set("state", req.State)
+		}
+		http.Redirect(w, r, oauthserver.AppendQuery(req.RedirectURI, params), http.StatusFound)
+		return
+	}
+
+	http.Redirect(w, r, code.RedirectURL(), http.StatusFound)
+}
+
+// handleOAuthToken is the token endpoint of RFC 6749 section 3.2. Clients
+// authenticate with HTTP Basic or client_id and client_secret form fields,
+// and errors use the RFC 6749 section 5.2 body.
+func (a *application) handleOAuthToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, oauthserver.ErrInvalidRequest)
+		return
+	}
+
+	clientID, clientSecret, basic := r.BasicAuth()
+	if !basic {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	response, err := a.oauthServer.Token(r.Context(), oauthserver.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       oauthserver.ParseScope(r.PostForm.Get("scope")),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	})
+	if err != nil {
+		if basic && errors.Is(err, oauthserver.ErrInvalidClient) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeOAuthError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeOAuthError writes an OAuth error in the RFC 6749 body clients expect;
+// other errors use the usual envelope
+func writeOAuthError(w http.ResponseWriter, err error) {
+	var oauthErr oauthserver.OAuthError
+	if !errors.As(err, &oauthErr) {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, oauthErrorStatus(oauthErr.Code), oauthErr)
+}
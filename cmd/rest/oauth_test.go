@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	oauthServerMemory "github.com/gentra/decorator-arch-go/internal/oauthserver/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+const (
+	oauthRedirectURI = "https://app.example.com/callback"
+	oauthVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk-long-enough"
+)
+
+// newOAuthTestApp returns an application with an OAuth server and a public
+// client registered for the authorization code grant
+func newOAuthTestApp(t *testing.T) (*application, *oauthserver.Client) {
+	t.Helper()
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("GetByID", mock.Anything, "user-1").Return(testkit.NewUserBuilder().Build(), nil)
+	app.oauthServer = oauthServerMemory.NewService(app.token, nil)
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/oauth/clients",
+		`{"name":"dashboard","type":"public","redirect_uris":["`+oauthRedirectURI+`"],"grant_types":["authorization_code"],"scopes":["users:read"]}`)
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var credentials oauthserver.ClientCredentials
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &credentials))
+	return app, &credentials.Client
+}
+
+func authorizeQuery(clientID, redirectURI string) string {
+	sum := sha256.Sum256([]byte(oauthVerifier))
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"users:read"},
+		"state":                 {"xyz"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+}
+
+func tokenRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestOAuth_GivenSignedInUser_WhenRunningAuthorizationCodeFlow_ThenClientCallsAPIWithScopedToken(t *testing.T) {
+	app, client := newOAuthTestApp(t)
+
+	// Authorize as the signed-in user
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/oauth/authorize?"+authorizeQuery(client.ID, oauthRedirectURI), ""))
+	require.Equal(t, http.StatusFound, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+
+	// Exchange the code
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, tokenRequest(url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {client.ID},
+		"code":          {location.Query().Get("code")},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {oauthVerifier},
+	}))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Bearer", response["token_type"])
+	assert.Equal(t, "users:read", response["scope"])
+
+	// Call the API within and beyond the granted scope
+	accessToken := response["access_token"].(string)
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/api/auth/me", accessToken))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/api/notifications", accessToken))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestOAuthAuthorize_GivenUnregisteredRedirectURI_WhenAuthorizing_ThenRefusesWithoutRedirecting(t *testing.T) {
+	app, client := newOAuthTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/oauth/authorize?"+authorizeQuery(client.ID, "https://evil.example.com/callback"), ""))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), "invalid_redirect_uri")
+}
+
+func TestOAuthAuthorize_GivenUnknownScope_WhenAuthorizing_ThenRedirectsWithError(t *testing.T) {
+	app, client := newOAuthTestApp(t)
+	query := strings.Replace(authorizeQuery(client.ID, oauthRedirectURI), "scope=users%3Aread", "scope=admin", 1)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodGet, "/oauth/authorize?"+query, ""))
+
+	require.Equal(t, http.StatusFound, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_scope", location.Query().Get("error"))
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+}
+
+func TestOAuthAuthorize_GivenImpersonationToken_WhenAuthorizing_ThenIsForbidden(t *testing.T) {
+	app, client := newOAuthTestApp(t)
+	impersonation, err := app.token.GenerateImpersonationToken(t.Context(), "admin-1", "user-1", []string{"users:read"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/oauth/authorize?"+authorizeQuery(client.ID, oauthRedirectURI), impersonation.Token))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), user.ErrForbidden.Code)
+}
+
+func TestOAuthToken_GivenConfidentialClient_WhenAuthenticatingWithBasicAuth_ThenIssuesTokenOrRFC6749Error(t *testing.T) {
+	app, _ := newOAuthTestApp(t)
+	credentials, err := app.oauthServer.RegisterClient(t.Context(), oauthserver.ClientRequest{
+		Name:       "billing-sync",
+		Type:       oauthserver.ClientTypeConfidential,
+		GrantTypes: []string{oauthserver.GrantTypeClientCredentials},
+		Scopes:     []string{"users:read"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		secret   string
+		expected int
+	}{
+		{name: "Given the client secret, When requesting a token, Then issues it", secret: credentials.Secret, expected: http.StatusOK},
+		{name: "Given a wrong secret, When requesting a token, Then returns invalid_client", secret: "wrong", expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tokenRequest(url.Values{"grant_type": {"client_credentials"}})
+			req.SetBasicAuth(credentials.Client.ID, tt.secret)
+
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error":"invalid_client","error_description":"Client authentication failed"}`, rec.Body.String())
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			} else {
+				assert.Contains(t, rec.Body.String(), "access_token")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// submitFieldRequest is the body of an onboarding field answer
+type submitFieldRequest struct {
+	Value string `json:"value"`
+}
+
+// remindLaterRequest is the optional body of a remind-later request; without
+// a time the prompt is hidden for the configured delay
+type remindLaterRequest struct {
+	RemindAt time.Time `json:"remind_at"`
+}
+
+func (a *application) handleListOnboardingPrompts(w http.ResponseWriter, r *http.Request) {
+	prompts, err := a.profiling.Prompts(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prompts)
+}
+
+func (a *application) handleGetOnboarding(w http.ResponseWriter, r *http.Request) {
+	progress, err := a.profiling.Progress(r.Context(), claimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (a *application) handleSubmitOnboardingField(w http.ResponseWriter, r *http.Request) {
+	var req submitFieldRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	progress, err := a.profiling.Submit(r.Context(), claimsFromContext(r.Context()).UserID, r.PathValue("field"), req.Value)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (a *application) handleSkipOnboardingField(w http.ResponseWriter, r *http.Request) {
+	progress, err := a.profiling.Skip(r.Context(), claimsFromContext(r.Context()).UserID, r.PathValue("field"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (a *application) handleRemindLaterOnboardingField(w http.ResponseWriter, r *http.Request) {
+	var req remindLaterRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
+		return
+	}
+
+	progress, err := a.profiling.RemindLater(r.Context(), claimsFromContext(r.Context()).UserID, r.PathValue("field"), req.RemindAt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	profilingFactory "github.com/gentra/decorator-arch-go/internal/profiling/factory"
+	validationStandard "github.com/gentra/decorator-arch-go/internal/validation/standard"
+)
+
+func newOnboardingTestApp(t *testing.T) *application {
+	t.Helper()
+	app, _, _ := newAdminTestApp(t)
+	service, err := profilingFactory.NewFactory(profilingFactory.DefaultConfig(validationStandard.NewService())).Build()
+	require.NoError(t, err)
+	app.profiling = service
+	return app
+}
+
+func TestOnboarding_GivenAnswer_WhenSubmitting_ThenReturnsUpdatedProgress(t *testing.T) {
+	app := newOnboardingTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPut, "/api/users/profile/onboarding/job_title", `{"value":"Engineer"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var progress profiling.Progress
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &progress))
+	assert.Equal(t, "user-1", progress.UserID)
+	assert.Equal(t, profiling.FieldStatusCompleted, progress.Fields["job_title"].Status)
+}
+
+func TestOnboarding_GivenRequiredField_WhenSkipping_ThenReturnsBadRequest(t *testing.T) {
+	app := newOnboardingTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/profile/onboarding/job_title/skip", ""))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), profiling.ErrRequiredField.Code)
+}
+
+func TestOnboarding_GivenUnknownField_WhenDeferring_ThenReturnsNotFound(t *testing.T) {
+	app := newOnboardingTestApp(t)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "user-1", http.MethodPost, "/api/users/profile/onboarding/shoe_size/remind-later", ""))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
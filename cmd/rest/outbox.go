@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+)
+
+const defaultOutboxLimit = 100
+
+// handleListOutbox lists notifications captured in dry-run mode, newest first
+func (a *application) handleListOutbox(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := outbox.Filter{
+		Channel: outbox.Channel(query.Get("channel")),
+		To:      query.Get("to"),
+		Limit:   defaultOutboxLimit,
+	}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			badRequest(w, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	messages, err := a.outbox.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+func (a *application) handleGetOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	message, err := a.outbox.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, message)
+}
+
+func (a *application) handleClearOutbox(w http.ResponseWriter, r *http.Request) {
+	if err := a.outbox.Clear(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
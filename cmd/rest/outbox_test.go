@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationFactory "github.com/gentra/decorator-arch-go/internal/notification/factory"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+)
+
+func TestOutbox_GivenDryRunNotification_WhenAdminListsOutbox_ThenReturnsCapturedMessage(t *testing.T) {
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.outbox = outboxMemory.NewService(10)
+	config := notificationFactory.NewConfigBuilder().WithDryRun(app.outbox, false).Build()
+	var err error
+	app.notification, err = notificationFactory.NewFactory(config).Build()
+	require.NoError(t, err)
+
+	ctx := notification.WithDryRun(context.Background())
+	require.NoError(t, app.notification.SendWelcomeEmail(ctx, "jane@example.com", "Jane"))
+
+	req := authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/outbox?channel=email", "")
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var messages []outbox.Message
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&messages))
+	require.Len(t, messages, 1)
+	assert.Equal(t, "jane@example.com", messages[0].To)
+}
+
+func TestWithNotificationDryRun_GivenHeader_WhenServing_ThenMarksContextOnlyWhenAllowed(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         config
+		callerID       string
+		expectedDryRun bool
+	}{
+		{name: "Given an anonymous caller, When the header is not enabled, Then ignores it", expectedDryRun: false},
+		{name: "Given an anonymous caller, When the header is enabled, Then marks the context", config: config{NotificationDryRunHeader: true}, expectedDryRun: true},
+		{name: "Given an anonymous caller, When the header is enabled in production, Then ignores it", config: config{NotificationDryRunHeader: true, Production: true}, expectedDryRun: false},
+		{name: "Given a user, When the header is not enabled, Then ignores it", callerID: "user-1", expectedDryRun: false},
+		{name: "Given an administrator, When in production, Then marks the context", config: config{Production: true}, callerID: "admin-1", expectedDryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _, _ := newAdminTestApp(t)
+			tt.config.AdminUserIDs = app.config.AdminUserIDs
+			app.config = tt.config
+			var dryRun bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				dryRun = notification.IsDryRun(r.Context())
+			})
+			handler := app.withNotificationDryRun(next)
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+			if tt.callerID != "" {
+				handler = app.withNotificationDryRun(app.requireAuth(next))
+				req = authorizedRequest(t, app, tt.callerID, http.MethodPost, "/api/users/profile", "")
+			}
+			req.Header.Set(notificationDryRunHeader, "true")
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.expectedDryRun, dryRun)
+		})
+	}
+}
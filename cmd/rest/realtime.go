@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+const (
+	realtimeBufferSize  = 16
+	realtimeKeepAlive   = 30 * time.Second
+	sessionIDHeader     = "X-Session-ID"
+	sessionIDQueryParam = "session_id"
+)
+
+// realtimeClient is a single open session listening for events
+type realtimeClient struct {
+	sessionID string
+	events    chan events.Event
+}
+
+// realtimeHub fans out user events to the user's open sessions over
+// server-sent events. It is subscribed to the events domain as a handler.
+type realtimeHub struct {
+	mu      sync.RWMutex
+	clients map[string]map[*realtimeClient]struct{} // keyed by user ID
+}
+
+func newRealtimeHub() *realtimeHub {
+	return &realtimeHub{
+		clients: make(map[string]map[*realtimeClient]struct{}),
+	}
+}
+
+// Handle delivers an event to every session of its user except the one that caused it
+func (h *realtimeHub) Handle(ctx context.Context, event interface{}) error {
+	evt, ok := event.(events.Event)
+	if !ok {
+		return fmt.Errorf("unexpected event type %T", event)
+	}
+
+	originSessionID, _ := evt.Data["origin_session_id"].(string)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients[evt.AggregateID] {
+		if originSessionID != "" && client.sessionID == originSessionID {
+			continue
+		}
+
+		select {
+		case client.events <- evt:
+		default:
+			// Slow consumer; the client refetches on reconnect
+		}
+	}
+
+	return nil
+}
+
+// GetHandledEventTypes returns the event types pushed to open sessions
+func (h *realtimeHub) GetHandledEventTypes() []string {
+	return []string{
+		events.EventTypeUserPrefsUpdated,
+		events.EventTypeInAppNotificationCreated,
+		events.EventTypeInAppNotificationsRead,
+	}
+}
+
+// connect registers a session and returns its client and a function to disconnect it
+func (h *realtimeHub) connect(userID, sessionID string) (*realtimeClient, func()) {
+	client := &realtimeClient{
+		sessionID: sessionID,
+		events:    make(chan events.Event, realtimeBufferSize),
+	}
+
+	h.mu.Lock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*realtimeClient]struct{})
+	}
+	h.clients[userID][client] = struct{}{}
+	h.mu.Unlock()
+
+	return client, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.clients[userID], client)
+		if len(h.clients[userID]) == 0 {
+			delete(h.clients, userID)
+		}
+	}
+}
+
+// handleRealtime streams the user's events as server-sent events
+func (a *application) handleRealtime(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	claims := claimsFromContext(r.Context())
+	client, disconnect := a.realtime.connect(claims.UserID, sessionID(r))
+	defer disconnect()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(realtimeKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case evt := <-client.events:
+			payload, err := json.Marshal(evt.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, payload)
+			flusher.Flush()
+		}
+	}
+}
+
+// sessionID identifies the client session of a request. Clients send their own
+// session ID so their changes are not echoed back; otherwise the token ID is used.
+func sessionID(r *http.Request) string {
+	if id := r.Header.Get(sessionIDHeader); id != "" {
+		return id
+	}
+	if id := r.URL.Query().Get(sessionIDQueryParam); id != "" {
+		return id
+	}
+	if claims := claimsFromContext(r.Context()); claims != nil {
+		return claims.JTI
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+func TestRealtimeHub_GivenPreferencesUpdated_WhenHandling_ThenOtherSessionsReceiveEvent(t *testing.T) {
+	hub := newRealtimeHub()
+	origin, disconnectOrigin := hub.connect("user-1", "session-a")
+	defer disconnectOrigin()
+	other, disconnectOther := hub.connect("user-1", "session-b")
+	defer disconnectOther()
+	stranger, disconnectStranger := hub.connect("user-2", "session-c")
+	defer disconnectStranger()
+
+	event := events.Event{
+		ID:          "evt-1",
+		Type:        events.EventTypeUserPrefsUpdated,
+		AggregateID: "user-1",
+		Data: map[string]interface{}{
+			"origin_session_id": "session-a",
+			"changes": map[string]interface{}{
+				"theme": map[string]interface{}{"old": "light", "new": "dark"},
+			},
+		},
+	}
+
+	require.NoError(t, hub.Handle(context.Background(), event))
+
+	select {
+	case received := <-other.events:
+		assert.Equal(t, "evt-1", received.ID)
+	default:
+		t.Fatal("expected other session to receive the event")
+	}
+	assert.Empty(t, origin.events, "originating session must be excluded")
+	assert.Empty(t, stranger.events, "other users must not receive the event")
+}
+
+func TestRealtimeHub_GivenDisconnectedSession_WhenHandling_ThenNothingIsDelivered(t *testing.T) {
+	hub := newRealtimeHub()
+	client, disconnect := hub.connect("user-1", "session-a")
+	disconnect()
+
+	err := hub.Handle(context.Background(), events.Event{Type: events.EventTypeUserPrefsUpdated, AggregateID: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, client.events)
+	assert.Empty(t, hub.clients)
+}
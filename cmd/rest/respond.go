@@ -0,0 +1,417 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+)
+
+// apiError is the error body returned by every endpoint
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// validationMessage lists the messages of failed fields, which validation
+// rendered in the caller's language, without the English wording of their
+// Error strings
+func validationMessage(validationErrs validation.ValidationErrors) string {
+	messages := make([]string, len(validationErrs.Errors))
+	for i, validationErr := range validationErrs.Errors {
+		messages[i] = validationErr.Field + ": " + validationErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// writeError maps a domain error to an HTTP status and error envelope
+func writeError(w http.ResponseWriter, err error) {
+	status, body := mapError(err)
+	if status >= http.StatusInternalServerError {
+		log.Printf("Request failed: %v", err)
+	}
+	var stepUpErr auth.StepUpError
+	if errors.As(err, &stepUpErr) {
+		// Tell clients to send the user through sign-in again rather than
+		// refreshing, which keeps the original sign-in time
+		w.Header().Set("WWW-Authenticate", `Bearer error="step_up_required", max_age="`+strconv.Itoa(int(stepUpErr.MaxAge.Seconds()))+`"`)
+	}
+	writeJSON(w, status, map[string]apiError{"error": body})
+}
+
+// mapError converts domain errors into their HTTP representation
+func mapError(err error) (int, apiError) {
+	var userErr user.UserError
+	if errors.As(err, &userErr) {
+		return userErrorStatus(userErr.Code), apiError{Code: userErr.Code, Message: userErr.Message, Field: userErr.Field}
+	}
+
+	var storageErr storage.StorageError
+	if errors.As(err, &storageErr) {
+		return storageErrorStatus(storageErr.Code), apiError{Code: storageErr.Code, Message: storageErr.Message}
+	}
+
+	var tokenErr token.TokenError
+	if errors.As(err, &tokenErr) {
+		return http.StatusUnauthorized, apiError{Code: tokenErr.Code, Message: tokenErr.Message}
+	}
+
+	var policyErr tokenpolicy.PolicyError
+	if errors.As(err, &policyErr) {
+		return http.StatusForbidden, apiError{Code: policyErr.Code, Message: policyErr.Message}
+	}
+
+	var accountErr serviceaccount.ServiceAccountError
+	if errors.As(err, &accountErr) {
+		return serviceAccountErrorStatus(accountErr.Code), apiError{Code: accountErr.Code, Message: accountErr.Message, Field: accountErr.Field}
+	}
+
+	var oauthErr oauthserver.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErrorStatus(oauthErr.Code), apiError{Code: oauthErr.Code, Message: oauthErr.Message}
+	}
+
+	var captchaErr captcha.CaptchaError
+	if errors.As(err, &captchaErr) {
+		return captchaErrorStatus(captchaErr.Code), apiError{Code: captchaErr.Code, Message: captchaErr.Message}
+	}
+
+	var profilingErr profiling.ProfilingError
+	if errors.As(err, &profilingErr) {
+		return profilingErrorStatus(profilingErr.Code), apiError{Code: profilingErr.Code, Message: profilingErr.Message, Field: profilingErr.Field}
+	}
+
+	var notificationErr notification.NotificationError
+	if errors.As(err, &notificationErr) {
+		if notificationErr.Code == notification.ErrRateLimited.Code {
+			return http.StatusTooManyRequests, apiError{Code: notificationErr.Code, Message: notificationErr.Message}
+		}
+		return http.StatusBadRequest, apiError{Code: notificationErr.Code, Message: notificationErr.Message, Field: notificationErr.Field}
+	}
+
+	var historyErr notificationhistory.HistoryError
+	if errors.As(err, &historyErr) {
+		return historyErrorStatus(historyErr.Code), apiError{Code: historyErr.Code, Message: historyErr.Message}
+	}
+
+	var templateErr notificationtemplate.TemplateError
+	if errors.As(err, &templateErr) {
+		return templateErrorStatus(templateErr.Code), apiError{Code: templateErr.Code, Message: templateErr.Message, Field: templateErr.Field}
+	}
+
+	var inboxErr inbox.InboxError
+	if errors.As(err, &inboxErr) {
+		return inboxErrorStatus(inboxErr.Code), apiError{Code: inboxErr.Code, Message: inboxErr.Message, Field: inboxErr.Field}
+	}
+
+	var digestErr notificationdigest.DigestError
+	if errors.As(err, &digestErr) {
+		return digestErrorStatus(digestErr.Code), apiError{Code: digestErr.Code, Message: digestErr.Message, Field: digestErr.Field}
+	}
+
+	var scheduleErr notificationschedule.ScheduleError
+	if errors.As(err, &scheduleErr) {
+		return scheduleErrorStatus(scheduleErr.Code), apiError{Code: scheduleErr.Code, Message: scheduleErr.Message, Field: scheduleErr.Field}
+	}
+
+	var outboxErr outbox.OutboxError
+	if errors.As(err, &outboxErr) {
+		return http.StatusNotFound, apiError{Code: outboxErr.Code, Message: outboxErr.Message}
+	}
+
+	var deadLetterErr deadletter.DeadLetterError
+	if errors.As(err, &deadLetterErr) {
+		return http.StatusNotFound, apiError{Code: deadLetterErr.Code, Message: deadLetterErr.Message}
+	}
+
+	var replayErr eventreplay.ReplayError
+	if errors.As(err, &replayErr) {
+		if replayErr.Code == eventreplay.ErrJobNotFound.Code {
+			return http.StatusNotFound, apiError{Code: replayErr.Code, Message: replayErr.Message}
+		}
+		return http.StatusBadRequest, apiError{Code: replayErr.Code, Message: err.Error()}
+	}
+
+	var webhookErr webhook.WebhookError
+	if errors.As(err, &webhookErr) {
+		if webhookErr.Code == webhook.ErrEndpointNotFound.Code {
+			return http.StatusNotFound, apiError{Code: webhookErr.Code, Message: webhookErr.Message}
+		}
+		return http.StatusBadRequest, apiError{Code: webhookErr.Code, Message: err.Error()}
+	}
+
+	var authErr auth.AuthError
+	if errors.As(err, &authErr) {
+		return authErrorStatus(authErr.Code), apiError{Code: authErr.Code, Message: authErr.Message}
+	}
+
+	var ruleStoreErr rulestore.RuleStoreError
+	if errors.As(err, &ruleStoreErr) {
+		return ruleStoreErrorStatus(ruleStoreErr.Code), apiError{Code: ruleStoreErr.Code, Message: ruleStoreErr.Message}
+	}
+
+	var validationErrs validation.ValidationErrors
+	if errors.As(err, &validationErrs) && len(validationErrs.Errors) > 0 {
+		first := validationErrs.Errors[0]
+		return http.StatusBadRequest, apiError{Code: "VALIDATION_FAILED", Message: validationMessage(validationErrs), Field: first.Field}
+	}
+
+	var validationErr validation.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, apiError{Code: "VALIDATION_FAILED", Message: validationErr.Message, Field: validationErr.Field}
+	}
+
+	var ruleErr validationrule.ValidationRuleError
+	if errors.As(err, &ruleErr) {
+		return http.StatusBadRequest, apiError{Code: ruleErr.Code, Message: err.Error(), Field: ruleErr.Field}
+	}
+
+	return http.StatusInternalServerError, apiError{Code: "INTERNAL_ERROR", Message: "Internal server error"}
+}
+
+// userErrorStatus returns the HTTP status for a user domain error code
+func userErrorStatus(code string) int {
+	switch code {
+	case user.ErrUserNotFound.Code, user.ErrPreferencesNotFound.Code, user.ErrAvatarNotFound.Code:
+		return http.StatusNotFound
+	case user.ErrEmailAlreadyExists.Code, user.ErrConflict.Code, user.ErrIdempotencyKeyInUse.Code:
+		return http.StatusConflict
+	case user.ErrIdempotencyKeyReuse.Code:
+		return http.StatusUnprocessableEntity
+	case user.ErrInvalidCredentials.Code:
+		return http.StatusUnauthorized
+	case user.ErrForbidden.Code, user.ErrAccountDeactivated.Code, user.ErrEmailNotVerified.Code:
+		return http.StatusForbidden
+	case user.ErrRateLimited.Code:
+		return http.StatusTooManyRequests
+	case user.ErrAccountLocked.Code:
+		return http.StatusLocked
+	case user.ErrServiceUnavailable.Code:
+		return http.StatusServiceUnavailable
+	case user.ErrAvatarTooLarge.Code:
+		return http.StatusRequestEntityTooLarge
+	case user.ErrUnsupportedAvatar.Code:
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// storageErrorStatus returns the HTTP status for a blob storage error code
+func storageErrorStatus(code string) int {
+	if code == storage.ErrObjectNotFound.Code {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// serviceAccountErrorStatus returns the HTTP status for a service account error code
+func serviceAccountErrorStatus(code string) int {
+	switch code {
+	case serviceaccount.ErrNotFound.Code:
+		return http.StatusNotFound
+	case serviceaccount.ErrInvalidSecret.Code:
+		return http.StatusUnauthorized
+	case serviceaccount.ErrDisabled.Code, serviceaccount.ErrScopeNotDelegable.Code, serviceaccount.ErrScopeNotGranted.Code:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// oauthErrorStatus returns the HTTP status for an OAuth error code
+func oauthErrorStatus(code string) int {
+	switch code {
+	case oauthserver.ErrClientNotFound.Code:
+		return http.StatusNotFound
+	case oauthserver.ErrInvalidClient.Code:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// ruleStoreErrorStatus returns the HTTP status for a rule store error code
+func ruleStoreErrorStatus(code string) int {
+	if code == rulestore.ErrRuleNotFound.Code {
+		return http.StatusNotFound
+	}
+	return http.StatusConflict
+}
+
+// captchaErrorStatus returns the HTTP status for a captcha error code
+func captchaErrorStatus(code string) int {
+	switch code {
+	case captcha.ErrCaptchaUnavailable.Code:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// templateErrorStatus returns the HTTP status for a notification template
+// error code
+func templateErrorStatus(code string) int {
+	switch code {
+	case notificationtemplate.ErrTemplateNotFound.Code:
+		return http.StatusNotFound
+	case notificationtemplate.ErrReadOnly.Code:
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// historyErrorStatus returns the HTTP status for a notification history
+// error code
+func historyErrorStatus(code string) int {
+	if code == notificationhistory.ErrEntryNotFound.Code {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// inboxErrorStatus returns the HTTP status for an inbox error code
+func inboxErrorStatus(code string) int {
+	if code == inbox.ErrNotFound.Code {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// digestErrorStatus returns the HTTP status for a notification digest error
+// code
+func digestErrorStatus(code string) int {
+	if code == notificationdigest.ErrNotFound.Code {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// scheduleErrorStatus returns the HTTP status for a notification schedule
+// error code
+func scheduleErrorStatus(code string) int {
+	switch code {
+	case notificationschedule.ErrNotFound.Code:
+		return http.StatusNotFound
+	case notificationschedule.ErrNotCancellable.Code:
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// authErrorStatus returns the HTTP status for an authentication error code
+func authErrorStatus(code string) int {
+	switch code {
+	case auth.ErrInsufficientScope.Code:
+		return http.StatusForbidden
+	case auth.ErrTooManyAttempts.Code:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+// profilingErrorStatus returns the HTTP status for a progressive profiling error code
+func profilingErrorStatus(code string) int {
+	switch code {
+	case profiling.ErrUnknownField.Code:
+		return http.StatusNotFound
+	case profiling.ErrAlreadyAnswered.Code:
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// badRequest writes a generic malformed request error
+func badRequest(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]apiError{
+		"error": {Code: "BAD_REQUEST", Message: message},
+	})
+}
+
+// decodeJSON decodes the request body into dst, writing an error response on failure
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err := decoder.Decode(dst); err != nil {
+		badRequest(w, "request body must be valid JSON")
+		return false
+	}
+	return true
+}
+
+// versionETag renders a resource version as a strong entity tag
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the version a conditional update is based on from the
+// If-Match header. It reports false when the header is missing or "*", since
+// any version matches then.
+func ifMatchVersion(r *http.Request) (int, bool, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false, errors.New("If-Match must be a single quoted entity tag")
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return 0, false, errors.New("If-Match must name a version returned in an ETag")
+	}
+	return version, true, nil
+}
+
+// writeUpdateError writes the error of an update. A version conflict on a
+// conditional request means the If-Match precondition failed.
+func writeUpdateError(w http.ResponseWriter, err error, conditional bool) {
+	if conditional && (errors.Is(err, user.ErrConflict) || errors.Is(err, rulestore.ErrConflict)) {
+		_, body := mapError(err)
+		writeJSON(w, http.StatusPreconditionFailed, map[string]apiError{"error": body})
+		return
+	}
+	writeError(w, err)
+}
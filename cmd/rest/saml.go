@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// samlRequestCookie keeps the ID of the pending AuthnRequest between the
+// redirect to the identity provider and the response posted back, so a
+// response can only complete the sign-in started in the same browser
+const (
+	samlRequestCookie = "saml_request_id"
+	samlCookiePath    = "/api/auth/saml"
+	samlRequestTTL    = 10 * time.Minute
+)
+
+// handleSAMLMetadata serves the service provider metadata to register with
+// the identity provider
+func (a *application) handleSAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := saml.Metadata(a.config.SAML)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(metadata)
+}
+
+// handleSAMLLogin redirects to the identity provider with a new AuthnRequest
+func (a *application) handleSAMLLogin(w http.ResponseWriter, r *http.Request) {
+	request, err := saml.NewAuthnRequest(a.config.SAML, r.URL.Query().Get("RelayState"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The response arrives in a cross-site POST, which only carries
+	// SameSite=None cookies
+	http.SetCookie(w, &http.Cookie{
+		Name:     samlRequestCookie,
+		Value:    request.ID,
+		Path:     samlCookiePath,
+		MaxAge:   int(samlRequestTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	http.Redirect(w, r, request.RedirectURL, http.StatusFound)
+}
+
+// handleSAMLACS is the assertion consumer service. It validates the response
+// the identity provider posts and signs the user in like handleLogin.
+func (a *application) handleSAMLACS(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("SAMLResponse") == "" {
+		badRequest(w, "SAMLResponse is required")
+		return
+	}
+
+	credentials := auth.SAMLCredentials{SAMLResponse: r.PostForm.Get("SAMLResponse")}
+	if cookie, err := r.Cookie(samlRequestCookie); err == nil {
+		credentials.RequestID = cookie.Value
+	}
+	// A request ID is good for one response only
+	http.SetCookie(w, &http.Cookie{
+		Name:     samlRequestCookie,
+		Path:     samlCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	result, err := a.auth.Authenticate(r.Context(), saml.Strategy, credentials)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Sessions are issued by the token service, so they refresh and revoke
+	// like those of password logins
+	a.writeSession(w, r, result.User.ID, token.AMRFederated)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+// newSAMLTestApp returns an app trusting the identity provider, whose users
+// include jane.doe@example.com
+func newSAMLTestApp(t *testing.T, idp *testkit.SAMLIdentityProvider) *application {
+	t.Helper()
+	app, _, users := newAdminTestApp(t)
+	app.config.SAML = saml.Config{
+		EntityID:       testkit.SAMLSPEntityID,
+		ACSURL:         testkit.SAMLACSURL,
+		IdPEntityID:    testkit.SAMLIdPEntityID,
+		IdPSSOURL:      "https://idp.example.com/sso",
+		IdPCertificate: idp.CertificatePEM(),
+	}
+	tokenManager := authUsecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	app.auth = saml.NewService(app.config.SAML, users, tokenManager)
+
+	jane := testkit.NewUserBuilder().Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{Users: []*user.User{jane}}, nil)
+	users.On("GetByID", mock.Anything, jane.ID.String()).Return(jane, nil)
+	return app
+}
+
+// acsRequest posts the response to the ACS URL as the browser would
+func acsRequest(samlResponse, requestID string) *http.Request {
+	form := url.Values{"SAMLResponse": {samlResponse}}
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if requestID != "" {
+		req.AddCookie(&http.Cookie{Name: samlRequestCookie, Value: requestID})
+	}
+	return req
+}
+
+func TestSAML_GivenLogin_WhenIdPPostsSignedResponse_ThenReturnsSession(t *testing.T) {
+	// Arrange
+	idp := testkit.NewSAMLIdentityProvider(t)
+	app := newSAMLTestApp(t, idp)
+
+	login := httptest.NewRecorder()
+	app.routes().ServeHTTP(login, httptest.NewRequest(http.MethodGet, "/api/auth/saml/login", nil))
+	require.Equal(t, http.StatusFound, login.Code)
+	assert.True(t, strings.HasPrefix(login.Header().Get("Location"), "https://idp.example.com/sso?SAMLRequest="))
+	cookies := login.Result().Cookies()
+	require.Len(t, cookies, 1)
+	requestID := cookies[0].Value
+
+	response := idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, acsRequest(testkit.EncodeSAMLResponse(response), requestID))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result userview.AuthResultView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.NotEmpty(t, result.RefreshToken)
+
+	claims, err := app.token.ValidateToken(t.Context(), result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, testkit.DefaultEmail, claims.Email)
+}
+
+func TestSAML_GivenResponseForAnotherBrowser_WhenPosted_ThenIsUnauthorized(t *testing.T) {
+	idp := testkit.NewSAMLIdentityProvider(t)
+	app := newSAMLTestApp(t, idp)
+	response := idp.ResponseXML(t, testkit.NewSAMLAssertion("_request-1"))
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, acsRequest(testkit.EncodeSAMLResponse(response), ""))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "INVALID_SAML_RESPONSE")
+}
+
+func TestSAML_GivenMetadataRequest_WhenHandled_ThenServesServiceProviderMetadata(t *testing.T) {
+	app := newSAMLTestApp(t, testkit.NewSAMLIdentityProvider(t))
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/saml/metadata", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/samlmetadata+xml", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), testkit.SAMLACSURL)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	signingKeyMemory "github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	tokenFactory "github.com/gentra/decorator-arch-go/internal/token/factory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Check statuses reported by the self-test
+const (
+	checkPassed  = "pass"
+	checkFailed  = "fail"
+	checkSkipped = "skip"
+)
+
+// selfTestReport is the structured result of a startup self-test
+type selfTestReport struct {
+	Status     string        `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMS int64         `json:"duration_ms"`
+	Checks     []checkResult `json:"checks"`
+}
+
+// checkResult is the outcome of a single self-test check
+type checkResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// Passed reports whether no check failed
+func (r *selfTestReport) Passed() bool {
+	return r.Status == checkPassed
+}
+
+// Write prints the report as indented JSON
+func (r *selfTestReport) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// runSelfTest builds every factory, pings dependencies, issues and validates a
+// token and performs a dry-run validation. It never starts the HTTP server.
+func runSelfTest(ctx context.Context, cfg config) *selfTestReport {
+	report := &selfTestReport{Status: checkPassed, StartedAt: time.Now()}
+	app := &application{config: cfg}
+	defer app.Close()
+
+	// Build every component; components whose dependencies failed report
+	// their own error rather than being skipped
+	for _, c := range app.components() {
+		report.run("build:"+c.name, c.build)
+	}
+
+	// Dependency pings
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if app.db != nil {
+		report.run("ping:database", func() error { return app.pingDatabase(pingCtx) })
+	} else {
+		report.skip("ping:database", "database not built")
+	}
+
+	if app.redis != nil {
+		report.run("ping:redis", func() error { return app.pingRedis(pingCtx) })
+	} else {
+		report.skip("ping:redis", "REDIS_URL not set, cache layer disabled")
+	}
+
+	// Token round trip
+	if app.token != nil {
+		report.run("token:issue-validate", func() error { return app.checkTokenRoundTrip(ctx) })
+	} else {
+		report.skip("token:issue-validate", "token service not built")
+	}
+
+	// Dry-run validation of a known-good and a known-bad payload
+	if app.validation != nil {
+		report.run("validation:dry-run", func() error { return app.checkValidationDryRun(ctx) })
+	} else {
+		report.skip("validation:dry-run", "validation service not built")
+	}
+
+	report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// run executes a check and records its outcome
+func (r *selfTestReport) run(name string, check func() error) {
+	start := time.Now()
+	err := check()
+
+	result := checkResult{
+		Name:       name,
+		Status:     checkPassed,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = checkFailed
+		result.Error = err.Error()
+		r.Status = checkFailed
+	}
+
+	r.Checks = append(r.Checks, result)
+}
+
+// skip records a check that could not run
+func (r *selfTestReport) skip(name, reason string) {
+	r.Checks = append(r.Checks, checkResult{Name: name, Status: checkSkipped, Detail: reason})
+}
+
+// checkTokenRoundTrip issues a test token, validates it and revokes it again.
+// It runs on its own token service, so the test token never reaches the
+// issuance policies, token registry, revocation store or audit trail.
+func (a *application) checkTokenRoundTrip(ctx context.Context) error {
+	const userID = "00000000-0000-0000-0000-000000000000"
+
+	tokenService, err := a.selfTestTokenService()
+	if err != nil {
+		return fmt.Errorf("failed to build token service: %w", err)
+	}
+
+	tokenString, _, err := tokenService.GenerateAuthToken(ctx, userID, "selftest@example.com")
+	if err != nil {
+		return fmt.Errorf("failed to issue token: %w", err)
+	}
+
+	claims, err := tokenService.ValidateToken(ctx, tokenString)
+	if err != nil {
+		return fmt.Errorf("failed to validate issued token: %w", err)
+	}
+	if claims.UserID != userID {
+		return fmt.Errorf("validated token has user ID %q, expected %q", claims.UserID, userID)
+	}
+
+	return tokenService.RevokeToken(ctx, tokenString)
+}
+
+// selfTestTokenService builds a token service with the signing and
+// encryption settings of a.token but without its side effects: its tokens
+// and revocations stay in memory, rotated keys come from an in-memory store
+// and no policy, audit or metrics layer is added.
+func (a *application) selfTestTokenService() (token.Service, error) {
+	config := a.tokenConfig
+	config.TokenStore = nil
+	config.TokenRegistry = nil
+	config.RevocationStore = nil
+	config.Policies = nil
+	config.AuditService = nil
+	config.Features.EnableAuditLogging = false
+	config.Features.EnableMetrics = false
+	if config.KeyStore != nil {
+		config.KeyStore = signingKeyMemory.NewService()
+	}
+	return tokenFactory.NewFactory(config).Build()
+}
+
+// checkValidationDryRun runs registration validation without touching storage
+func (a *application) checkValidationDryRun(ctx context.Context) error {
+	valid := user.RegisterData{
+		Email:     "selftest@example.com",
+		Password:  "Dry-Run-42!",
+		FirstName: "Self",
+		LastName:  "Test",
+	}
+	if err := a.validation.ValidateUserRegistration(ctx, valid); err != nil {
+		return fmt.Errorf("valid registration was rejected: %w", err)
+	}
+
+	invalid := user.RegisterData{Email: "not-an-email"}
+	if err := a.validation.ValidateUserRegistration(ctx, invalid); err == nil {
+		return fmt.Errorf("invalid registration was accepted")
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tokenFactory "github.com/gentra/decorator-arch-go/internal/token/factory"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy/hook"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
+)
+
+func findCheck(t *testing.T, report *selfTestReport, name string) checkResult {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %q not found in report", name)
+	return checkResult{}
+}
+
+func TestRunSelfTest_GivenNoDatabase_WhenRunning_ThenReportsFailureWithDetails(t *testing.T) {
+	cfg := config{JWTSecret: "test-secret-key-for-testing-only"}
+
+	report := runSelfTest(context.Background(), cfg)
+
+	assert.False(t, report.Passed())
+	assert.Equal(t, checkFailed, findCheck(t, report, "build:database").Status)
+	assert.Equal(t, checkFailed, findCheck(t, report, "build:user").Status)
+	assert.Equal(t, checkSkipped, findCheck(t, report, "ping:database").Status)
+	assert.Equal(t, checkSkipped, findCheck(t, report, "ping:redis").Status)
+
+	// Checks that do not depend on the database still run
+	assert.Equal(t, checkPassed, findCheck(t, report, "build:token").Status)
+	assert.Equal(t, checkPassed, findCheck(t, report, "token:issue-validate").Status)
+	assert.Equal(t, checkPassed, findCheck(t, report, "validation:dry-run").Status)
+}
+
+func TestSelfTestReport_GivenResults_WhenWriting_ThenProducesJSON(t *testing.T) {
+	report := &selfTestReport{Status: checkPassed}
+	report.run("ok", func() error { return nil })
+	report.skip("skipped", "not configured")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "pass", decoded["status"])
+	assert.Len(t, decoded["checks"], 2)
+}
+
+func TestCheckTokenRoundTrip_GivenRegistryAndPolicy_WhenRunning_ThenLeavesThemUntouched(t *testing.T) {
+	// Arrange
+	registry := tokenStoreMemory.NewService()
+	vetoed := false
+	veto := hook.NewService("veto", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		vetoed = true
+		return errors.New("issuance refused")
+	}, nil)
+	app := &application{tokenConfig: tokenFactory.NewConfigBuilder().
+		WithSecretString("test-secret-key-for-testing-only").
+		WithTokenRegistry(registry).
+		WithPolicy(veto).
+		Build()}
+
+	// Act
+	err := app.checkTokenRoundTrip(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, vetoed)
+	records, err := registry.ListByUser(context.Background(), "00000000-0000-0000-0000-000000000000")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
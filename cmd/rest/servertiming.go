@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// debugTimingHeader opts an administrator's request into per-layer timings
+const debugTimingHeader = "X-Debug-Timing"
+
+// serverTimingWriter adds a Server-Timing header with the per-layer breakdown
+// just before the response headers are written
+type serverTimingWriter struct {
+	http.ResponseWriter
+	timings     *user.Timings
+	start       time.Time
+	wroteHeader bool
+}
+
+// withServerTiming collects decorator timings for the request and reports them
+// in a Server-Timing header
+func withServerTiming(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
+	timings := &user.Timings{}
+	writer := &serverTimingWriter{ResponseWriter: w, timings: timings, start: time.Now()}
+	return writer, r.WithContext(user.WithTimings(r.Context(), timings))
+}
+
+func (w *serverTimingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", formatServerTiming(w.timings.Layers(), time.Since(w.start)))
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *serverTimingWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush supports streaming responses
+func (w *serverTimingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// formatServerTiming renders layer timings as a Server-Timing header value
+func formatServerTiming(layers []user.LayerTiming, total time.Duration) string {
+	metrics := make([]string, 0, len(layers)+1)
+	for _, layer := range layers {
+		metric := fmt.Sprintf("%s;dur=%.3f", layer.Layer, milliseconds(layer.Duration))
+		if layer.Calls > 1 {
+			metric += fmt.Sprintf(";desc=\"%d calls\"", layer.Calls)
+		}
+		metrics = append(metrics, metric)
+	}
+	metrics = append(metrics, fmt.Sprintf("total;dur=%.3f", milliseconds(total)))
+	return strings.Join(metrics, ", ")
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/user/timing"
+)
+
+func TestServerTiming_GivenDebugHeader_WhenCallerIsAdmin_ThenReportsLayerTimings(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       string
+		debugHeader  bool
+		expectHeader bool
+	}{
+		{name: "admin with debug header", userID: "admin-1", debugHeader: true, expectHeader: true},
+		{name: "admin without debug header", userID: "admin-1", debugHeader: false, expectHeader: false},
+		{name: "regular user with debug header", userID: "user-1", debugHeader: true, expectHeader: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, users := newAdminTestApp(t)
+			users.On("GetByID", mock.Anything, tt.userID).Return(&user.User{Email: "a@example.com"}, nil)
+			app.users = timing.NewService(timing.NewService(users, "db"), "usecase")
+
+			req := authorizedRequest(t, app, tt.userID, http.MethodGet, "/api/users/profile", "")
+			if tt.debugHeader {
+				req.Header.Set(debugTimingHeader, "1")
+			}
+			rec := httptest.NewRecorder()
+
+			app.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			header := rec.Header().Get("Server-Timing")
+			if tt.expectHeader {
+				assert.Contains(t, header, "usecase;dur=")
+				assert.Contains(t, header, "db;dur=")
+				assert.Contains(t, header, "total;dur=")
+			} else {
+				assert.Empty(t, header)
+			}
+		})
+	}
+}
+
+func TestFormatServerTiming(t *testing.T) {
+	layers := []user.LayerTiming{
+		{Layer: "cache", Duration: 1500 * time.Microsecond, Calls: 1},
+		{Layer: "db", Duration: 3 * time.Millisecond, Calls: 2},
+	}
+
+	assert.Equal(t, `cache;dur=1.500, db;dur=3.000;desc="2 calls", total;dur=5.000`, formatServerTiming(layers, 5*time.Millisecond))
+}
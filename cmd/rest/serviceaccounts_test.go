@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	authUsecase "github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	serviceAccountMemory "github.com/gentra/decorator-arch-go/internal/serviceaccount/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// unrevokableTokens is a token service whose bulk revocation has no effect
+type unrevokableTokens struct {
+	token.Service
+}
+
+func (unrevokableTokens) RevokeAllTokensForUser(ctx context.Context, userID string) error {
+	return nil
+}
+
+func TestCreateServiceAccount_GivenRequestedScopes_WhenCreating_ThenDelegatesOnlyTheAdminsScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   string
+		expected int
+	}{
+		{
+			name:     "Given a scope API tokens are checked against, When an admin creates, Then succeeds",
+			scopes:   `["users:read"]`,
+			expected: http.StatusCreated,
+		},
+		{
+			name:     "Given every scope, When an admin creates, Then is forbidden",
+			scopes:   `["*"]`,
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "Given an unknown scope, When an admin creates, Then is forbidden",
+			scopes:   `["admin:write"]`,
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, auditSvc, _ := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+			app.serviceAccounts = serviceAccountMemory.NewService(app.token, auditSvc)
+			body := `{"organization_id":"org-1","name":"ci","scopes":` + tt.scopes + `}`
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/service-accounts", body))
+
+			// Assert
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusForbidden {
+				assert.Contains(t, rec.Body.String(), serviceaccount.ErrScopeNotDelegable.Code)
+			}
+		})
+	}
+}
+
+func TestServiceAccountToken_GivenDisabledAccount_WhenRevocationHadNoEffect_ThenTokenIsRejected(t *testing.T) {
+	// Arrange
+	app, auditSvc, users := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	users.On("GetByID", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+	app.auth = authUsecase.NewAuthOrchestratorWithTokenService(authUsecase.NewJWTTokenManager(testkit.TestSecret, time.Hour, time.Hour), app.token)
+	app.serviceAccounts = serviceAccountMemory.NewService(unrevokableTokens{app.token}, auditSvc)
+	credentials, err := app.serviceAccounts.Create(t.Context(), serviceaccount.CreateRequest{
+		OrganizationID: "org-1",
+		Name:           "ci",
+		Scopes:         []string{"users:read"},
+		CreatorScopes:  []string{"users:read"},
+	})
+	require.NoError(t, err)
+	apiToken, err := app.serviceAccounts.IssueToken(t.Context(), credentials.Account.ID, credentials.Secret, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/api/users/profile", apiToken.Token))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Act
+	require.NoError(t, app.serviceAccounts.Disable(t.Context(), credentials.Account.ID))
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, apiKeyRequest(http.MethodGet, "/api/users/profile", apiToken.Token))
+	introspectRec := httptest.NewRecorder()
+	introspectReq := authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/auth/introspect", `{"token":"`+apiToken.Token+`"}`)
+	app.routes().ServeHTTP(introspectRec, introspectReq)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), serviceaccount.ErrDisabled.Code)
+	var introspection struct {
+		Active bool `json:"active"`
+	}
+	require.NoError(t, json.Unmarshal(introspectRec.Body.Bytes(), &introspection))
+	assert.False(t, introspection.Active)
+}
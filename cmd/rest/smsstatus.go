@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/notification/twilio"
+)
+
+// handleSMSStatusCallback applies the delivery status Twilio reports for an
+// SMS to its notification history entry. Twilio signs the callback over the
+// URL it posted to, which behind proxies differs from the request's, so the
+// configured TWILIO_STATUS_CALLBACK_URL is checked instead.
+func (a *application) handleSMSStatusCallback(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		badRequest(w, "invalid form body")
+		return
+	}
+	if !twilio.Verify(a.config.TwilioAuthToken, a.config.TwilioStatusCallbackURL, r.PostForm, r.Header.Get(twilio.SignatureHeader)) {
+		writeJSON(w, http.StatusForbidden, map[string]apiError{
+			"error": {Code: "INVALID_SIGNATURE", Message: "Status callback signature does not match"},
+		})
+		return
+	}
+
+	sid, update, ok, err := twilio.ParseStatusCallback(r.PostForm)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+	if ok {
+		if _, err := a.history.UpdateStatus(r.Context(), sid, update); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
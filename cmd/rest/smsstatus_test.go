@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/twilio"
+	notificationHistoryMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+)
+
+func TestSMSStatusCallback_GivenReport_WhenPosted_ThenUpdatesHistory(t *testing.T) {
+	const callbackURL = "https://api.example.com/api/notifications/sms/status"
+	params := url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"delivered"}}
+
+	tests := []struct {
+		name           string
+		signature      string
+		expectedCode   int
+		expectedStatus notification.NotificationStatus
+	}{
+		{
+			name:           "Given a report signed by Twilio, When posted, Then marks the SMS delivered",
+			signature:      twilio.Sign("secret", callbackURL, params),
+			expectedCode:   http.StatusNoContent,
+			expectedStatus: notification.NotificationStatusDelivered,
+		},
+		{
+			name:           "Given a forged signature, When posted, Then rejects it and leaves the SMS pending",
+			signature:      twilio.Sign("guessed", callbackURL, params),
+			expectedCode:   http.StatusForbidden,
+			expectedStatus: notification.NotificationStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _, _ := newAdminTestApp(t)
+			app.config.SMSProvider = "twilio"
+			app.config.TwilioAuthToken = "secret"
+			app.config.TwilioStatusCallbackURL = callbackURL
+			app.history = notificationHistoryMemory.NewService()
+			_, err := app.history.Record(context.Background(), notification.NotificationHistory{
+				ID:     "SM1",
+				Type:   notification.NotificationTypeSMS,
+				Status: notification.NotificationStatusPending,
+			})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/api/notifications/sms/status", strings.NewReader(params.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set(twilio.SignatureHeader, tt.signature)
+			rec := httptest.NewRecorder()
+
+			// Act
+			app.routes().ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			entry, err := app.history.Get(context.Background(), "SM1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, entry.Status)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	userStepUp "github.com/gentra/decorator-arch-go/internal/user/stepup"
+)
+
+func TestChangePassword_GivenSignInAge_WhenChangingPassword_ThenRequiresRecentSignIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		signedIn time.Duration
+		expected int
+	}{
+		{name: "Given a recent sign-in, When changing the password, Then succeeds", signedIn: time.Minute, expected: http.StatusNoContent},
+		{name: "Given a stale sign-in, When changing the password, Then asks to sign in again", signedIn: time.Hour, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _, users := newAdminTestApp(t)
+			users.On("ChangePassword", mock.Anything, "user-1", "old", "new-password").Return(nil)
+			app.users = userStepUp.NewService(users, userStepUp.Config{MaxAge: 15 * time.Minute})
+
+			ctx := token.WithAuthentication(t.Context(), time.Now().Add(-tt.signedIn), token.AMRPassword)
+			accessToken, _, err := app.token.GenerateAuthToken(ctx, "user-1", "user-1@example.com")
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPut, "/api/users/password", strings.NewReader(`{"current_password":"old","new_password":"new-password"}`))
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.Contains(t, rec.Body.String(), auth.ErrStepUpRequired.Code)
+				assert.Equal(t, `Bearer error="step_up_required", max_age="900"`, rec.Header().Get("WWW-Authenticate"))
+				users.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// activeTokensView lists the caller's active tokens
+type activeTokensView struct {
+	Tokens []token.TokenInfo `json:"tokens"`
+}
+
+// handleListTokens lists the caller's active tokens with the device each
+// was issued to, so unfamiliar sessions can be spotted; deleting them signs
+// the caller out everywhere, like POST /api/auth/logout-all
+func (a *application) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	tokens, err := a.token.ListActiveTokens(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, activeTokensView{Tokens: tokens})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+func TestListTokens_GivenIssuedTokens_WhenListing_ThenReturnsCallersTokens(t *testing.T) {
+	// Arrange
+	tokens, err := jwt.NewServiceWithOptions(testkit.TokenConfig(), jwt.Options{Registry: tokenStoreMemory.NewService()})
+	require.NoError(t, err)
+	app := &application{token: tokens}
+	_, err = tokens.GenerateRefreshToken(t.Context(), "user-1")
+	require.NoError(t, err)
+	_, _, err = tokens.GenerateAuthToken(t.Context(), "user-2", "user-2@example.com")
+	require.NoError(t, err)
+
+	req := authorizedRequest(t, app, "user-1", http.MethodGet, "/api/users/me/tokens", "")
+	rec := httptest.NewRecorder()
+
+	// Act
+	app.routes().ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body activeTokensView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Tokens, 2)
+	for _, info := range body.Tokens {
+		assert.Equal(t, "user-1", info.UserID)
+	}
+}
+
+func TestRequireAuth_GivenTokenType_WhenCallingAuthenticatedRoute_ThenAcceptsOnlyAccessTokens(t *testing.T) {
+	tests := []struct {
+		name           string
+		generate       func(t *testing.T, app *application) string
+		expectedStatus int
+	}{
+		{
+			name: "Given an access token, When calling, Then accepts it",
+			generate: func(t *testing.T, app *application) string {
+				accessToken, _, err := app.token.GenerateAuthToken(t.Context(), "user-1", "user-1@example.com")
+				require.NoError(t, err)
+				return accessToken
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Given a refresh token, When calling, Then rejects it",
+			generate: func(t *testing.T, app *application) string {
+				refreshToken, err := app.token.GenerateRefreshToken(t.Context(), "user-1")
+				require.NoError(t, err)
+				return refreshToken
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Given a password reset token, When calling, Then rejects it",
+			generate: func(t *testing.T, app *application) string {
+				resetToken, err := app.token.GeneratePasswordResetToken(t.Context(), "user-1")
+				require.NoError(t, err)
+				return resetToken
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Given an email verification token, When calling, Then rejects it",
+			generate: func(t *testing.T, app *application) string {
+				verificationToken, err := app.token.GenerateEmailVerificationToken(t.Context(), "user-1")
+				require.NoError(t, err)
+				return verificationToken
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tokens, err := jwt.NewServiceWithOptions(testkit.TokenConfig(), jwt.Options{Registry: tokenStoreMemory.NewService()})
+			require.NoError(t, err)
+			app := &application{token: tokens}
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me/tokens", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.generate(t, app))
+			rec := httptest.NewRecorder()
+
+			// Act
+			app.routes().ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRefresh_GivenExchangedRefreshToken_WhenReused_ThenFailsAndRevokesItsFamily(t *testing.T) {
+	// Arrange
+	tokens, err := jwt.NewServiceWithOptions(testkit.TokenConfig(), jwt.Options{Registry: tokenStoreMemory.NewService()})
+	require.NoError(t, err)
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = bus.Close(ctx)
+	})
+	app := &application{token: tokens, publisher: bus}
+	stolen, err := tokens.GenerateRefreshToken(t.Context(), "user-1")
+	require.NoError(t, err)
+	refresh := func(refreshToken string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refresh_token":"`+refreshToken+`"}`))
+		app.routes().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := refresh(stolen)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var rotated userview.AuthResultView
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rotated))
+	require.NotEqual(t, stolen, rotated.RefreshToken)
+
+	// Act
+	rec = refresh(stolen)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "REFRESH_TOKEN_REUSED")
+	assert.Equal(t, http.StatusUnauthorized, refresh(rotated.RefreshToken).Code, "the rotated refresh token is revoked with its family")
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me/tokens", nil)
+	req.Header.Set("Authorization", "Bearer "+rotated.Token)
+	rec = httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "the access token issued by refreshing is revoked with its family")
+	published, err := bus.GetEventsByAggregate(t.Context(), "user-1", 0)
+	require.NoError(t, err)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, events.EventTypeTokenReuseDetected, published[0].Type)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	ruleStoreAudit "github.com/gentra/decorator-arch-go/internal/rulestore/audit"
+	ruleStoreMemory "github.com/gentra/decorator-arch-go/internal/rulestore/memory"
+	ruleStorePostgres "github.com/gentra/decorator-arch-go/internal/rulestore/postgres"
+	"github.com/gentra/decorator-arch-go/internal/validation/liverules"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/ruleset"
+)
+
+// validationRuleRequest is the body creating a validation rule. Rules are
+// enabled and of normal priority unless it says otherwise, and the rule ID
+// defaults to the name, as in rule set files.
+type validationRuleRequest struct {
+	RuleID      string                            `json:"rule_id"`
+	Name        string                            `json:"name"`
+	Description string                            `json:"description"`
+	Type        validationrule.ValidationRuleType `json:"type"`
+	Field       string                            `json:"field"`
+	Message     string                            `json:"message"`
+	Enabled     *bool                             `json:"enabled"`
+	Priority    *int                              `json:"priority"`
+	Metadata    map[string]string                 `json:"metadata"`
+	Parameters  map[string]interface{}            `json:"parameters"`
+}
+
+// validationRuleChange is the body changing a validation rule; fields left
+// out keep their value and metadata or parameters given replace the old ones
+type validationRuleChange struct {
+	Name        *string                            `json:"name"`
+	Description *string                            `json:"description"`
+	Type        *validationrule.ValidationRuleType `json:"type"`
+	Field       *string                            `json:"field"`
+	Message     *string                            `json:"message"`
+	Enabled     *bool                              `json:"enabled"`
+	Priority    *int                               `json:"priority"`
+	Metadata    map[string]string                  `json:"metadata"`
+	Parameters  map[string]interface{}             `json:"parameters"`
+}
+
+// buildValidationRules opens the rule store, seeds it with the rules of
+// ValidationRuleDir it does not hold yet and applies the stored rules on top
+// of the validation service
+func (a *application) buildValidationRules() error {
+	var store rulestore.Service
+	switch a.config.ValidationRuleStore {
+	case "":
+		return nil
+	case "memory":
+		store = ruleStoreMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for VALIDATION_RULE_STORE=postgres")
+		}
+		store = ruleStorePostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown VALIDATION_RULE_STORE %q", a.config.ValidationRuleStore)
+	}
+
+	if a.config.ValidationRuleDir != "" {
+		if err := seedValidationRules(context.Background(), store, a.config.ValidationRuleDir); err != nil {
+			return err
+		}
+	}
+
+	a.validationRules = ruleStoreAudit.NewService(store, a.audit)
+	a.liveRules = liverules.NewService(a.validation)
+	a.validation = a.liveRules
+	return a.reloadValidationRules(context.Background())
+}
+
+// seedValidationRules stores the rules declared in a directory, keeping the
+// stored version of rules already there
+func seedValidationRules(ctx context.Context, store rulestore.Service, dir string) error {
+	configs, err := ruleset.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		_, err := store.Create(ctx, rulestore.Rule{ValidationRuleConfig: config, UpdatedBy: "system:seed"})
+		if err != nil && !errors.Is(err, rulestore.ErrRuleExists) {
+			return fmt.Errorf("failed to seed validation rule %q: %w", config.RuleID, err)
+		}
+	}
+	return nil
+}
+
+// reloadValidationRules applies the stored rules to the validation service
+func (a *application) reloadValidationRules(ctx context.Context) error {
+	rules, err := a.validationRules.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list validation rules: %w", err)
+	}
+	return a.liveRules.Reload(rulestore.Configs(rules))
+}
+
+// runValidationRuleReload reloads the stored validation rules every interval
+// until the context is cancelled, picking up changes made on other instances
+func (a *application) runValidationRuleReload(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.reloadValidationRules(ctx); err != nil {
+				log.Printf("Validation rule reload failed: %v", err)
+			}
+		}
+	}
+}
+
+func (a *application) handleAdminListValidationRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := a.validationRules.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if rules == nil {
+		rules = []rulestore.Rule{}
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+func (a *application) handleAdminGetValidationRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := a.validationRules.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", versionETag(rule.Version))
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleAdminCreateValidationRule stores a new rule and applies it
+func (a *application) handleAdminCreateValidationRule(w http.ResponseWriter, r *http.Request) {
+	var req validationRuleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	config := validationrule.DefaultValidationRuleConfig()
+	config.RuleID, config.Name, config.Description = req.RuleID, req.Name, req.Description
+	config.Type, config.Field, config.Message = req.Type, req.Field, req.Message
+	if config.RuleID == "" {
+		config.RuleID = req.Name
+	}
+	if req.Enabled != nil {
+		config.Enabled = *req.Enabled
+	}
+	if req.Priority != nil {
+		config.Priority = *req.Priority
+	}
+	for key, value := range req.Metadata {
+		config.Metadata[key] = value
+	}
+	for key, value := range req.Parameters {
+		config.Parameters[key] = value
+	}
+	if err := a.checkValidationRule(r.Context(), config); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	created, err := a.validationRules.Create(r.Context(), rulestore.Rule{
+		ValidationRuleConfig: config,
+		UpdatedBy:            claimsFromContext(r.Context()).UserID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.applyValidationRules(r.Context())
+	w.Header().Set("ETag", versionETag(created.Version))
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleAdminUpdateValidationRule enables, disables or reconfigures a rule
+// and applies the change. With If-Match the change only applies to the
+// version named by the entity tag.
+func (a *application) handleAdminUpdateValidationRule(w http.ResponseWriter, r *http.Request) {
+	version, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		badRequest(w, err.Error())
+		return
+	}
+
+	var change validationRuleChange
+	if !decodeJSON(w, r, &change) {
+		return
+	}
+
+	rule, err := a.validationRules.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	change.apply(&rule.ValidationRuleConfig)
+	if err := a.checkValidationRule(r.Context(), rule.ValidationRuleConfig); err != nil {
+		writeError(w, err)
+		return
+	}
+	if conditional {
+		rule.Version = version
+	}
+	rule.UpdatedBy = claimsFromContext(r.Context()).UserID
+
+	updated, err := a.validationRules.Update(r.Context(), *rule)
+	if err != nil {
+		writeUpdateError(w, err, conditional)
+		return
+	}
+	a.applyValidationRules(r.Context())
+	w.Header().Set("ETag", versionETag(updated.Version))
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// handleAdminDeleteValidationRule removes a rule, which stops applying
+func (a *application) handleAdminDeleteValidationRule(w http.ResponseWriter, r *http.Request) {
+	if err := a.validationRules.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.applyValidationRules(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply sets the fields of the change on a rule configuration
+func (c validationRuleChange) apply(config *validationrule.ValidationRuleConfig) {
+	if c.Name != nil {
+		config.Name = *c.Name
+	}
+	if c.Description != nil {
+		config.Description = *c.Description
+	}
+	if c.Type != nil {
+		config.Type = *c.Type
+	}
+	if c.Field != nil {
+		config.Field = *c.Field
+	}
+	if c.Message != nil {
+		config.Message = *c.Message
+	}
+	if c.Enabled != nil {
+		config.Enabled = *c.Enabled
+	}
+	if c.Priority != nil {
+		config.Priority = *c.Priority
+	}
+	if c.Metadata != nil {
+		config.Metadata = c.Metadata
+	}
+	if c.Parameters != nil {
+		config.Parameters = c.Parameters
+	}
+}
+
+// checkValidationRule builds the stored rules with config in place of the
+// rule of its ID, so a rule that does not build or clashes with another
+// rule's name is rejected before it is stored
+func (a *application) checkValidationRule(ctx context.Context, config validationrule.ValidationRuleConfig) error {
+	rules, err := a.validationRules.List(ctx)
+	if err != nil {
+		return err
+	}
+	configs := []validationrule.ValidationRuleConfig{config}
+	for _, rule := range rules {
+		if rule.RuleID != config.RuleID {
+			configs = append(configs, rule.ValidationRuleConfig)
+		}
+	}
+	if !config.IsEnabled() {
+		// Disabled rules are not built, but must still be buildable to be
+		// enabled later
+		config.Enabled = true
+		configs[0] = config
+	}
+	_, err = ruleset.Build(configs)
+	return err
+}
+
+// applyValidationRules reloads the rules after a change on this instance;
+// other instances pick it up on their next reload. A failed reload is
+// retried then, as the change is already stored.
+func (a *application) applyValidationRules(ctx context.Context) {
+	if err := a.reloadValidationRules(ctx); err != nil {
+		log.Printf("Validation rule reload failed: %v", err)
+	}
+}
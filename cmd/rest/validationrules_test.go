@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
+)
+
+var longFirstName = user.RegisterData{Email: "jane@example.com", Password: "Str0ng!Pass", FirstName: "Bartholomew", LastName: "Doe"}
+
+func newValidationRuleTestApp(t *testing.T) (*application, *auditMock.MockAuditService) {
+	t.Helper()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.validation = tagvalidator.NewService()
+	app.config.ValidationRuleStore = "memory"
+	require.NoError(t, app.buildValidationRules())
+	return app, auditSvc
+}
+
+func serveAdmin(t *testing.T, app *application, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := authorizedRequest(t, app, "admin-1", method, path, body)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestValidationRules_GivenAdminChanges_WhenValidating_ThenAppliesThemWithoutRestart(t *testing.T) {
+	// Arrange
+	app, auditSvc := newValidationRuleTestApp(t)
+	ctx := t.Context()
+
+	// Act & Assert: a created rule applies at once
+	rec := serveAdmin(t, app, http.MethodPost, "/api/admin/validation-rules",
+		`{"name":"first_name_length","type":"length","field":"first_name","parameters":{"max":10}}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	assert.Error(t, app.validation.ValidateUserRegistration(ctx, longFirstName))
+
+	// Act & Assert: changing its parameters applies at once
+	rec = serveAdmin(t, app, http.MethodPatch, "/api/admin/validation-rules/first_name_length",
+		`{"parameters":{"max":20}}`, "If-Match", `"1"`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NoError(t, app.validation.ValidateUserRegistration(ctx, longFirstName))
+
+	// Act & Assert: a stale version is refused
+	rec = serveAdmin(t, app, http.MethodPatch, "/api/admin/validation-rules/first_name_length",
+		`{"enabled":false}`, "If-Match", `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// Act & Assert: disabling and deleting stop it applying
+	rec = serveAdmin(t, app, http.MethodPatch, "/api/admin/validation-rules/first_name_length",
+		`{"enabled":false,"parameters":{"max":5}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var disabled map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &disabled))
+	assert.Equal(t, false, disabled["enabled"])
+	assert.EqualValues(t, 3, disabled["version"])
+	assert.Equal(t, "admin-1", disabled["updated_by"])
+	assert.NoError(t, app.validation.ValidateUserRegistration(ctx, longFirstName))
+
+	rec = serveAdmin(t, app, http.MethodDelete, "/api/admin/validation-rules/first_name_length", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAdmin(t, app, http.MethodGet, "/api/admin/validation-rules/first_name_length", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Assert: every change is audited with the rule
+	for _, action := range []string{"validation_rule.create", "validation_rule.update", "validation_rule.delete"} {
+		auditSvc.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+			return entry.Action == action && entry.Resource == "validation_rule" &&
+				entry.ResourceID == "first_name_length" && entry.UserID == "admin-1"
+		}))
+	}
+}
+
+func TestCreateValidationRule_GivenInvalidRules_WhenCreating_ThenReturnsError(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expected     int
+		expectedCode string
+	}{
+		{
+			name:         "Given bounds that contradict each other, When creating, Then returns bad request",
+			body:         `{"name":"code_length","type":"length","parameters":{"min":5,"max":2}}`,
+			expected:     http.StatusBadRequest,
+			expectedCode: "INVALID_CONFIG",
+		},
+		{
+			name:         "Given an invalid disabled rule, When creating, Then returns bad request",
+			body:         `{"name":"code_pattern","type":"pattern","enabled":false,"parameters":{"pattern":"("}}`,
+			expected:     http.StatusBadRequest,
+			expectedCode: "INVALID_CONFIG",
+		},
+		{
+			name:         "Given a taken name, When creating, Then returns bad request",
+			body:         `{"rule_id":"other","name":"existing","type":"required"}`,
+			expected:     http.StatusBadRequest,
+			expectedCode: "INVALID_CONFIG",
+		},
+		{
+			name:         "Given a taken rule ID, When creating, Then returns conflict",
+			body:         `{"rule_id":"existing","name":"renamed","type":"required"}`,
+			expected:     http.StatusConflict,
+			expectedCode: "VALIDATION_RULE_EXISTS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, _ := newValidationRuleTestApp(t)
+			require.Equal(t, http.StatusCreated, serveAdmin(t, app, http.MethodPost, "/api/admin/validation-rules",
+				`{"name":"existing","type":"required"}`).Code)
+
+			// Act
+			rec := serveAdmin(t, app, http.MethodPost, "/api/admin/validation-rules", tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"code":"`+tt.expectedCode+`"`)
+		})
+	}
+}
+
+func TestValidationRules_GivenStoreDisabled_WhenCalling_ThenRoutesAreNotRegistered(t *testing.T) {
+	// Arrange
+	app, _, _ := newAdminTestApp(t)
+
+	// Act
+	rec := serveAdmin(t, app, http.MethodGet, "/api/admin/validation-rules", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	webhookMemory "github.com/gentra/decorator-arch-go/internal/webhook/memory"
+	webhookPostgres "github.com/gentra/decorator-arch-go/internal/webhook/postgres"
+)
+
+const defaultWebhookDeliveryLimit = 100
+
+// webhookRequest is the body registering a webhook endpoint. Endpoints are
+// active unless it says otherwise, and get a generated secret unless one is
+// given.
+type webhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// webhookChange is the body changing a webhook endpoint; fields left out
+// keep their value
+type webhookChange struct {
+	URL         *string  `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// buildWebhooks opens the registry of the endpoints events are delivered to;
+// buildEventHandlers then subscribes the delivery handler to the bus
+func (a *application) buildWebhooks() error {
+	switch a.config.WebhookStore {
+	case "":
+		return nil
+	case "memory":
+		a.webhooks = webhookMemory.NewService()
+	case "postgres":
+		if a.pool == nil {
+			return fmt.Errorf("DATABASE_URL is required for WEBHOOK_STORE=postgres")
+		}
+		a.webhooks = webhookPostgres.NewService(a.pool)
+	default:
+		return fmt.Errorf("unknown WEBHOOK_STORE %q", a.config.WebhookStore)
+	}
+	return nil
+}
+
+// handleListWebhooks lists the endpoints without their secrets
+func (a *application) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := a.webhooks.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, endpoints)
+}
+
+// handleGetWebhook returns an endpoint without its secret
+func (a *application) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := a.webhooks.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	endpoint.Secret = ""
+	writeJSON(w, http.StatusOK, endpoint)
+}
+
+// handleCreateWebhook registers an endpoint, returning its secret this once
+func (a *application) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	endpoint := webhook.Endpoint{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      true,
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	created, err := a.webhooks.Create(r.Context(), endpoint)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdateWebhook changes an endpoint's URL, event types, description or
+// active flag
+func (a *application) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var change webhookChange
+	if !decodeJSON(w, r, &change) {
+		return
+	}
+
+	endpoint, err := a.webhooks.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if change.URL != nil {
+		endpoint.URL = *change.URL
+	}
+	if change.EventTypes != nil {
+		endpoint.EventTypes = change.EventTypes
+	}
+	if change.Description != nil {
+		endpoint.Description = *change.Description
+	}
+	if change.Active != nil {
+		endpoint.Active = *change.Active
+	}
+
+	updated, err := a.webhooks.Update(r.Context(), *endpoint)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	updated.Secret = ""
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (a *application) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := a.webhooks.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRotateWebhookSecret replaces an endpoint's secret and returns the
+// new one; deliveries are signed with it from then on
+func (a *application) handleRotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := a.webhooks.RotateSecret(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, endpoint)
+}
+
+// handleListWebhookDeliveries lists the delivery attempts to an endpoint,
+// newest first, optionally for one event
+func (a *application) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := a.webhooks.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := webhook.DeliveryFilter{
+		EndpointID: endpoint.ID,
+		EventID:    query.Get("event_id"),
+		Limit:      defaultWebhookDeliveryLimit,
+	}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			badRequest(w, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	deliveries, err := a.webhooks.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
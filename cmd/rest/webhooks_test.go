@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	webhookMemory "github.com/gentra/decorator-arch-go/internal/webhook/memory"
+)
+
+func TestWebhooks_GivenAdmin_WhenRegisteringAndListing_ThenSecretIsOnlyShownOnCreate(t *testing.T) {
+	// Arrange
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.webhooks = webhookMemory.NewService()
+
+	// Act
+	createRec := httptest.NewRecorder()
+	app.routes().ServeHTTP(createRec, authorizedRequest(t, app, "admin-1", http.MethodPost, "/api/admin/webhooks",
+		`{"url":"https://hooks.example.com/users","event_types":["user.registered"]}`))
+	listRec := httptest.NewRecorder()
+	app.routes().ServeHTTP(listRec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/webhooks", ""))
+
+	// Assert
+	require.Equal(t, http.StatusCreated, createRec.Code)
+	var created webhook.Endpoint
+	require.NoError(t, json.NewDecoder(createRec.Body).Decode(&created))
+	assert.NotEmpty(t, created.Secret)
+	assert.True(t, created.Active)
+	require.Equal(t, http.StatusOK, listRec.Code)
+	var listed []webhook.Endpoint
+	require.NoError(t, json.NewDecoder(listRec.Body).Decode(&listed))
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, created.ID, listed[0].ID)
+		assert.Empty(t, listed[0].Secret)
+	}
+}
+
+func TestWebhooks_GivenInvalidOrUnknownEndpoint_WhenAdminManagesIt_ThenReturnsClientErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Given a relative URL, When creating, Then returns bad request",
+			method:         http.MethodPost,
+			path:           "/api/admin/webhooks",
+			body:           `{"url":"/users","event_types":["*"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   webhook.ErrInvalidEndpoint.Code,
+		},
+		{
+			name:           "Given an unknown endpoint, When rotating its secret, Then returns not found",
+			method:         http.MethodPost,
+			path:           "/api/admin/webhooks/missing/rotate-secret",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   webhook.ErrEndpointNotFound.Code,
+		},
+		{
+			name:           "Given an unknown endpoint, When listing its deliveries, Then returns not found",
+			method:         http.MethodGet,
+			path:           "/api/admin/webhooks/missing/deliveries",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   webhook.ErrEndpointNotFound.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app, auditSvc, _ := newAdminTestApp(t)
+			auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+			app.webhooks = webhookMemory.NewService()
+
+			// Act
+			rec := httptest.NewRecorder()
+			app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", tt.method, tt.path, tt.body))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedCode)
+		})
+	}
+}
+
+func TestWebhooks_GivenDeliveries_WhenAdminListsThem_ThenReturnsEndpointHistory(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	app, auditSvc, _ := newAdminTestApp(t)
+	auditSvc.On("Log", mock.Anything, mock.Anything).Return(nil)
+	app.webhooks = webhookMemory.NewService()
+	endpoint, err := app.webhooks.Create(ctx, webhook.Endpoint{URL: "https://hooks.example.com", EventTypes: []string{webhook.AllEvents}, Active: true})
+	require.NoError(t, err)
+	_, err = app.webhooks.RecordDelivery(ctx, webhook.Delivery{EndpointID: endpoint.ID, EventID: "event-1", Attempt: 1, StatusCode: 500})
+	require.NoError(t, err)
+
+	// Act
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, authorizedRequest(t, app, "admin-1", http.MethodGet, "/api/admin/webhooks/"+endpoint.ID+"/deliveries", ""))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	var deliveries []webhook.Delivery
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&deliveries))
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, "event-1", deliveries[0].EventID)
+		assert.Equal(t, 500, deliveries[0].StatusCode)
+	}
+}
@@ -3,6 +3,8 @@ package audit
 import (
 	"context"
 	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
 )
 
 // Service defines the audit domain interface - the ONLY interface in this domain
@@ -11,8 +13,14 @@ type Service interface {
 	GetAuditLogs(ctx context.Context, filters AuditFilters) ([]AuditEntry, error)
 	GetAuditLogsByUser(ctx context.Context, userID string, limit int) ([]AuditEntry, error)
 	GetAuditLogsByResource(ctx context.Context, resource, resourceID string, limit int) ([]AuditEntry, error)
+	AnonymizeUser(ctx context.Context, userID string) (int, error)
 }
 
+// AnonymousUserID replaces the ID of an erased user in anonymized entries.
+// Anonymized entries keep their action, resource type, outcome and time so the
+// trail stays complete, but can no longer be linked to the person.
+const AnonymousUserID = "anonymized"
+
 // Domain types and data structures
 
 // AuditEntry represents an audit log entry
@@ -29,19 +37,31 @@ type AuditEntry struct {
 	IPAddress  string      `json:"ip_address,omitempty"`
 	UserAgent  string      `json:"user_agent,omitempty"`
 	SessionID  string      `json:"session_id,omitempty"`
+
+	// CorrelationID links the entry to the request that caused it, and
+	// CausationID to the request or event that directly did
+	CorrelationID string `json:"correlation_id,omitempty"`
+	CausationID   string `json:"causation_id,omitempty"`
+
+	// ActorID and OnBehalfOfID are set when an admin impersonated a user:
+	// ActorID is the admin who acted and OnBehalfOfID the user acted as
+	ActorID      string `json:"actor_id,omitempty"`
+	OnBehalfOfID string `json:"on_behalf_of_id,omitempty"`
 }
 
 // AuditFilters for querying audit logs
 type AuditFilters struct {
-	UserID     string     `json:"user_id,omitempty"`
-	Action     string     `json:"action,omitempty"`
-	Resource   string     `json:"resource,omitempty"`
-	ResourceID string     `json:"resource_id,omitempty"`
-	Success    *bool      `json:"success,omitempty"`
-	StartTime  *time.Time `json:"start_time,omitempty"`
-	EndTime    *time.Time `json:"end_time,omitempty"`
-	Limit      int        `json:"limit,omitempty"`
-	Offset     int        `json:"offset,omitempty"`
+	UserID        string     `json:"user_id,omitempty"`
+	Action        string     `json:"action,omitempty"`
+	Resource      string     `json:"resource,omitempty"`
+	ResourceID    string     `json:"resource_id,omitempty"`
+	CorrelationID string     `json:"correlation_id,omitempty"`
+	ActorID       string     `json:"actor_id,omitempty"`
+	Success       *bool      `json:"success,omitempty"`
+	StartTime     *time.Time `json:"start_time,omitempty"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+	Limit         int        `json:"limit,omitempty"`
+	Offset        int        `json:"offset,omitempty"`
 }
 
 // AuditContext contains audit-related information from the request context
@@ -64,6 +84,17 @@ func (e *AuditEntry) IsValid() bool {
 	return e.Action != "" && e.Resource != "" && !e.Timestamp.IsZero()
 }
 
+// Correlate fills the correlation and causation IDs the entry lacks from the
+// context
+func (e *AuditEntry) Correlate(ctx context.Context) {
+	if e.CorrelationID == "" {
+		e.CorrelationID = correlation.CorrelationID(ctx)
+	}
+	if e.CausationID == "" {
+		e.CausationID = correlation.CausationID(ctx)
+	}
+}
+
 func (e *AuditEntry) SetSuccess() {
 	e.Success = true
 	e.Error = ""
@@ -76,6 +107,34 @@ func (e *AuditEntry) SetError(err error) {
 	}
 }
 
+// Anonymize removes the identity and personal data of the user from the entry
+// if the user performed or was the subject of it, and reports whether it did
+func (e *AuditEntry) Anonymize(userID string) bool {
+	if userID == "" || (e.UserID != userID && e.ResourceID != userID &&
+		e.ActorID != userID && e.OnBehalfOfID != userID) {
+		return false
+	}
+
+	// Connection data describes the actor; another actor's stays attributable
+	if e.UserID == userID {
+		e.UserID = AnonymousUserID
+		e.IPAddress = ""
+		e.UserAgent = ""
+		e.SessionID = ""
+	}
+	if e.ResourceID == userID {
+		e.ResourceID = AnonymousUserID
+	}
+	if e.ActorID == userID {
+		e.ActorID = AnonymousUserID
+	}
+	if e.OnBehalfOfID == userID {
+		e.OnBehalfOfID = AnonymousUserID
+	}
+	e.Details = nil
+	return true
+}
+
 // Helper methods for AuditContext
 func (ctx AuditContext) IsValid() bool {
 	return ctx.CurrentUserID != "" || ctx.IPAddress != ""
@@ -104,3 +163,14 @@ func ExtractAuditContext(ctx context.Context) AuditContext {
 	// Return empty context if not found
 	return AuditContext{}
 }
+
+// WithCorrelationID adds the request correlation ID to the context; see the
+// correlation package
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return correlation.WithCorrelationID(ctx, correlationID)
+}
+
+// ExtractCorrelationID extracts the request correlation ID from the context
+func ExtractCorrelationID(ctx context.Context) string {
+	return correlation.CorrelationID(ctx)
+}
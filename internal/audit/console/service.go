@@ -16,8 +16,9 @@ func NewService() audit.Service {
 	return &service{}
 }
 
-// Log writes the audit entry to console/stdout
+// Log writes the audit entry to console/stdout, with the correlation of the context
 func (s *service) Log(ctx context.Context, entry audit.AuditEntry) error {
+	entry.Correlate(ctx)
 	entryJSON, err := json.MarshalIndent(entry, "", "  ")
 	if err != nil {
 		return err
@@ -44,3 +45,9 @@ func (s *service) GetAuditLogsByResource(ctx context.Context, resource, resource
 	// Console audit doesn't support retrieval
 	return nil, nil
 }
+
+// AnonymizeUser is a no-op; entries written to the console cannot be rewritten
+// and must be anonymized by whatever collects the output
+func (s *service) AnonymizeUser(ctx context.Context, userID string) (int, error) {
+	return 0, nil
+}
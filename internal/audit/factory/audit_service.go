@@ -3,12 +3,13 @@ package factory
 import (
 	"github.com/gentra/decorator-arch-go/internal/audit"
 	"github.com/gentra/decorator-arch-go/internal/audit/console"
+	"github.com/gentra/decorator-arch-go/internal/audit/memory"
 )
 
 // Config contains all configuration for building the audit service
 type Config struct {
 	// Output configuration
-	OutputTarget string // "console", "file", "database", "external"
+	OutputTarget string // "console", "memory", "file", "database", "external"
 
 	// File output configuration (if OutputTarget = "file")
 	LogFilePath string
@@ -62,8 +63,11 @@ func NewFactory(config Config) *AuditServiceFactory {
 
 // Build assembles and returns the complete audit service based on configuration
 func (f *AuditServiceFactory) Build() (audit.Service, error) {
-	// For now, we only have console implementation
-	// In the future, we can add strategy pattern here for different outputs
+	// The memory target keeps entries queryable, e.g. for data exports in
+	// development; other outputs are not implemented yet
+	if f.config.OutputTarget == "memory" {
+		return memory.NewService(), nil
+	}
 
 	if f.config.Features.EnableConsoleOutput {
 		return console.NewService(), nil
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+)
+
+// service implements audit.Service interface using in-memory storage.
+// Entries are kept for the lifetime of the process, so it is meant for
+// development and tests where the trail must be queryable.
+type service struct {
+	entries []audit.AuditEntry
+	mu      sync.RWMutex
+}
+
+// NewService creates a new in-memory audit service
+func NewService() audit.Service {
+	return &service{}
+}
+
+// Log stores the audit entry, assigning an ID if it has none and the
+// correlation of the context
+func (s *service) Log(ctx context.Context, entry audit.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	entry.Correlate(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// GetAuditLogs retrieves matching entries, newest first
+func (s *service) GetAuditLogs(ctx context.Context, filters audit.AuditFilters) ([]audit.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []audit.AuditEntry
+	skipped := 0
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		entry := s.entries[i]
+		if !matches(entry, filters) {
+			continue
+		}
+		if skipped < filters.Offset {
+			skipped++
+			continue
+		}
+
+		result = append(result, entry)
+		if filters.Limit > 0 && len(result) == filters.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// GetAuditLogsByUser retrieves entries performed by a user, newest first
+func (s *service) GetAuditLogsByUser(ctx context.Context, userID string, limit int) ([]audit.AuditEntry, error) {
+	return s.GetAuditLogs(ctx, audit.AuditFilters{UserID: userID, Limit: limit})
+}
+
+// GetAuditLogsByResource retrieves entries about a resource, newest first
+func (s *service) GetAuditLogsByResource(ctx context.Context, resource, resourceID string, limit int) ([]audit.AuditEntry, error) {
+	return s.GetAuditLogs(ctx, audit.AuditFilters{Resource: resource, ResourceID: resourceID, Limit: limit})
+}
+
+// AnonymizeUser strips the user's identity and personal data from every entry
+// they performed or were the subject of, keeping the entries themselves
+func (s *service) AnonymizeUser(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	anonymized := 0
+	for i := range s.entries {
+		if s.entries[i].Anonymize(userID) {
+			anonymized++
+		}
+	}
+	return anonymized, nil
+}
+
+// Helper methods
+
+// matches reports whether the entry satisfies every set filter
+func matches(entry audit.AuditEntry, filters audit.AuditFilters) bool {
+	switch {
+	case filters.UserID != "" && entry.UserID != filters.UserID:
+		return false
+	case filters.Action != "" && entry.Action != filters.Action:
+		return false
+	case filters.Resource != "" && entry.Resource != filters.Resource:
+		return false
+	case filters.ResourceID != "" && entry.ResourceID != filters.ResourceID:
+		return false
+	case filters.CorrelationID != "" && entry.CorrelationID != filters.CorrelationID:
+		return false
+	case filters.ActorID != "" && entry.ActorID != filters.ActorID:
+		return false
+	case filters.Success != nil && entry.Success != *filters.Success:
+		return false
+	case filters.StartTime != nil && entry.Timestamp.Before(*filters.StartTime):
+		return false
+	case filters.EndTime != nil && entry.Timestamp.After(*filters.EndTime):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/audit/memory"
+)
+
+func TestMemoryAuditService_GivenEntries_WhenQuerying_ThenReturnsMatchesNewestFirst(t *testing.T) {
+	service := memory.NewService()
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, action := range []string{"user.login", "user.update_profile", "user.login"} {
+		require.NoError(t, service.Log(ctx, audit.AuditEntry{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			UserID:    "user-1",
+			Action:    action,
+			Resource:  "user",
+		}))
+	}
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{Timestamp: start, UserID: "user-2", Action: "user.login", Resource: "user"}))
+
+	logins, err := service.GetAuditLogs(ctx, audit.AuditFilters{UserID: "user-1", Action: "user.login"})
+	require.NoError(t, err)
+	require.Len(t, logins, 2)
+	assert.True(t, logins[0].Timestamp.After(logins[1].Timestamp))
+	assert.NotEmpty(t, logins[0].ID)
+
+	latest, err := service.GetAuditLogsByUser(ctx, "user-1", 1)
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "user.login", latest[0].Action)
+}
+
+func TestMemoryAuditService_GivenUserEntries_WhenAnonymizing_ThenKeepsTrailWithoutIdentity(t *testing.T) {
+	service := memory.NewService()
+	ctx := context.Background()
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{
+		UserID: "user-1", Action: "user.login", Resource: "user", ResourceID: "user-1",
+		IPAddress: "203.0.113.7", UserAgent: "curl/8.0", Details: map[string]interface{}{"email": "jane@example.com"},
+	}))
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{
+		UserID: "admin-1", Action: "user.update_profile", Resource: "user", ResourceID: "user-1", IPAddress: "198.51.100.1",
+	}))
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{UserID: "user-2", Action: "user.login", Resource: "user"}))
+
+	anonymized, err := service.AnonymizeUser(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, anonymized)
+
+	remaining, err := service.GetAuditLogsByUser(ctx, "user-1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	about, err := service.GetAuditLogsByResource(ctx, "user", audit.AnonymousUserID, 0)
+	require.NoError(t, err)
+	require.Len(t, about, 2)
+	assert.Equal(t, "admin-1", about[0].UserID, "other actors stay attributable")
+	assert.Equal(t, "198.51.100.1", about[0].IPAddress)
+	assert.Equal(t, audit.AnonymousUserID, about[1].UserID)
+	assert.Nil(t, about[1].Details)
+	assert.Empty(t, about[1].UserAgent)
+}
+
+func TestMemoryAuditService_GivenImpersonatedEntries_WhenQueryingByActor_ThenReturnsAdminsActions(t *testing.T) {
+	service := memory.NewService()
+	ctx := context.Background()
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{
+		UserID: "user-1", Action: "user.update_profile", Resource: "user", ResourceID: "user-1",
+		ActorID: "admin-1", OnBehalfOfID: "user-1",
+	}))
+	require.NoError(t, service.Log(ctx, audit.AuditEntry{UserID: "user-1", Action: "user.update_profile", Resource: "user", ResourceID: "user-1"}))
+
+	byActor, err := service.GetAuditLogs(ctx, audit.AuditFilters{ActorID: "admin-1"})
+	require.NoError(t, err)
+	require.Len(t, byActor, 1)
+	assert.Equal(t, "user-1", byActor[0].OnBehalfOfID)
+
+	anonymized, err := service.AnonymizeUser(ctx, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, anonymized)
+
+	byActor, err = service.GetAuditLogs(ctx, audit.AuditFilters{ActorID: audit.AnonymousUserID})
+	require.NoError(t, err)
+	require.Len(t, byActor, 1)
+	assert.Equal(t, "user-1", byActor[0].UserID, "the impersonated user stays attributable")
+}
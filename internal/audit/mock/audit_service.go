@@ -36,3 +36,9 @@ func (m *MockAuditService) GetAuditLogsByResource(ctx context.Context, resource,
 	args := m.Called(ctx, resource, resourceID, limit)
 	return args.Get(0).([]audit.AuditEntry), args.Error(1)
 }
+
+// AnonymizeUser mocks the AnonymizeUser method
+func (m *MockAuditService) AnonymizeUser(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
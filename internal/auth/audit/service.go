@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements auth.Service with audit logging capabilities
+// Authentications, refreshes and revocations are audited whether they
+// succeed or not. ValidateToken runs on every authenticated request, so only
+// rejected tokens are audited; Introspect only reports tokens as inactive,
+// so only its failures are. Entries never contain passwords or tokens.
+type service struct {
+	next         auth.Service
+	auditService audit.Service
+}
+
+// NewService creates a new audit-enabled auth service
+func NewService(next auth.Service, auditService audit.Service) auth.Service {
+	return &service{
+		next:         next,
+		auditService: auditService,
+	}
+}
+
+// Authenticate authenticates with audit logging of the strategy and, where
+// the credentials name one, the account
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	// Call next service
+	result, err := s.next.Authenticate(ctx, strategy, credentials)
+
+	// Log audit entry
+	details := map[string]interface{}{
+		"strategy": strategy,
+	}
+	switch creds := credentials.(type) {
+	case auth.BasicCredentials:
+		details["email"] = creds.Email
+	case auth.OAuthCredentials:
+		details["provider"] = creds.Provider
+	}
+	s.logAuditEntry(ctx, "auth.authenticate", resultUserID(result), details, err)
+
+	return result, err
+}
+
+// ValidateToken validates a token, auditing rejected ones
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	// Call next service
+	claims, err := s.next.ValidateToken(ctx, token)
+
+	// Log audit entry
+	if err != nil {
+		s.logAuditEntry(ctx, "auth.validate_token", "", nil, err)
+	}
+
+	return claims, err
+}
+
+// RefreshToken refreshes an access token with audit logging
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	// Call next service
+	result, err := s.next.RefreshToken(ctx, refreshToken)
+
+	// Log audit entry
+	details := map[string]interface{}{}
+	if result != nil {
+		details["strategy"] = result.Strategy
+	}
+	s.logAuditEntry(ctx, "auth.refresh_token", resultUserID(result), details, err)
+
+	return result, err
+}
+
+// RevokeToken revokes a token with audit logging
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	// Call next service
+	err := s.next.RevokeToken(ctx, token)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "auth.revoke_token", "", nil, err)
+
+	return err
+}
+
+// Introspect introspects a token, auditing failures
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	// Call next service
+	introspection, err := s.next.Introspect(ctx, token)
+
+	// Log audit entry
+	if err != nil {
+		s.logAuditEntry(ctx, "auth.introspect", "", nil, err)
+	}
+
+	return introspection, err
+}
+
+// RevokeAllForUser revokes every token of the user with audit logging
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	// Call next service
+	err := s.next.RevokeAllForUser(ctx, userID)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "auth.revoke_all_for_user", userID, nil, err)
+
+	return err
+}
+
+// GetSupportedStrategies passes through
+func (s *service) GetSupportedStrategies() []string {
+	return s.next.GetSupportedStrategies()
+}
+
+// Helper methods
+
+// logAuditEntry logs an audit entry with the provided information
+func (s *service) logAuditEntry(ctx context.Context, action, userID string, details interface{}, err error) {
+	auditCtx := audit.ExtractAuditContext(ctx)
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        auditCtx.CurrentUserID,
+		Action:        action,
+		Resource:      "auth",
+		ResourceID:    userID,
+		Details:       details,
+		Success:       err == nil,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+
+	// Signed-in users act as themselves
+	if userID != "" {
+		entry.UserID = userID
+	}
+	if entry.IPAddress == "" {
+		entry.IPAddress = user.ClientIPFromContext(ctx)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	// Log the entry on a detached context so the trail is recorded even when
+	// the caller disconnected mid-request.
+	// Don't fail the operation if audit logging fails
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+	s.auditService.Log(ctx, entry)
+}
+
+// resultUserID returns the ID of the authenticated user, if any
+func resultUserID(result *auth.AuthResult) string {
+	if result == nil || result.User == nil {
+		return ""
+	}
+	return result.User.ID
+}
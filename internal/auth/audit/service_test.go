@@ -0,0 +1,124 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authAudit "github.com/gentra/decorator-arch-go/internal/auth/audit"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func auditEntries(t *testing.T, auditService audit.Service) []audit.AuditEntry {
+	t.Helper()
+	entries, err := auditService.GetAuditLogs(context.Background(), audit.AuditFilters{Resource: "auth"})
+	require.NoError(t, err)
+	return entries
+}
+
+func TestAudit_GivenAuthentication_WhenCompleted_ThenAuditsOutcomeWithoutSecrets(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *auth.AuthResult
+		err        error
+		expectUser string
+	}{
+		{
+			name:       "Given valid credentials, When Authenticate is called, Then should audit the signed-in user",
+			result:     &auth.AuthResult{User: &auth.User{ID: "user-123"}, Token: "secret-token", Strategy: "basic"},
+			expectUser: "user-123",
+		},
+		{
+			name: "Given invalid credentials, When Authenticate is called, Then should audit the failure",
+			err:  auth.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := new(authmock.MockAuthStrategy)
+			next.On("Authenticate", mock.Anything, "basic", mock.Anything).Return(tt.result, tt.err)
+			auditService := auditMemory.NewService()
+			service := authAudit.NewService(next, auditService)
+			ctx := audit.WithCorrelationID(user.WithClientIP(context.Background(), "203.0.113.7"), "req-1")
+
+			// Act
+			_, err := service.Authenticate(ctx, "basic", auth.BasicCredentials{Email: "test@example.com", Password: "password123"})
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			entries := auditEntries(t, auditService)
+			require.Len(t, entries, 1)
+			entry := entries[0]
+			assert.Equal(t, "auth.authenticate", entry.Action)
+			assert.Equal(t, tt.err == nil, entry.Success)
+			assert.Equal(t, tt.expectUser, entry.UserID)
+			assert.Equal(t, "203.0.113.7", entry.IPAddress)
+			assert.Equal(t, "req-1", entry.CorrelationID)
+			assert.Equal(t, map[string]interface{}{"strategy": "basic", "email": "test@example.com"}, entry.Details)
+		})
+	}
+}
+
+func TestAudit_GivenTokenValidation_WhenTokenIsValid_ThenDoesNotAudit(t *testing.T) {
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, "good").Return(&auth.TokenClaims{UserID: "user-123"}, nil)
+	next.On("ValidateToken", mock.Anything, "bad").Return(nil, auth.ErrInvalidToken)
+	auditService := auditMemory.NewService()
+	service := authAudit.NewService(next, auditService)
+
+	_, _ = service.ValidateToken(context.Background(), "good")
+	_, _ = service.ValidateToken(context.Background(), "bad")
+
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "auth.validate_token", entries[0].Action)
+	assert.False(t, entries[0].Success)
+	assert.Equal(t, auth.ErrInvalidToken.Message, entries[0].Error)
+}
+
+func TestAudit_GivenRefreshAndRevoke_WhenCompleted_ThenAuditsBoth(t *testing.T) {
+	next := new(authmock.MockAuthStrategy)
+	next.On("RefreshToken", mock.Anything, "refresh").Return(&auth.AuthResult{User: &auth.User{ID: "user-123"}, Strategy: "basic"}, nil)
+	next.On("RevokeToken", mock.Anything, "access").Return(nil)
+	auditService := auditMemory.NewService()
+	service := authAudit.NewService(next, auditService)
+
+	_, err := service.RefreshToken(context.Background(), "refresh")
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeToken(context.Background(), "access"))
+
+	entries := auditEntries(t, auditService)
+	actions := []string{}
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.True(t, entry.Success)
+	}
+	assert.ElementsMatch(t, []string{"auth.refresh_token", "auth.revoke_token"}, actions)
+}
+
+func TestAudit_GivenRevokeAllForUser_WhenCompleted_ThenAuditsUser(t *testing.T) {
+	next := new(authmock.MockAuthStrategy)
+	next.On("RevokeAllForUser", mock.Anything, "user-123").Return(nil)
+	next.On("Introspect", mock.Anything, "access").Return(&auth.TokenIntrospection{Active: false}, nil)
+	auditService := auditMemory.NewService()
+	service := authAudit.NewService(next, auditService)
+
+	_, err := service.Introspect(context.Background(), "access")
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeAllForUser(context.Background(), "user-123"))
+
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 1, "inactive tokens are not audited")
+	assert.Equal(t, "auth.revoke_all_for_user", entries[0].Action)
+	assert.Equal(t, "user-123", entries[0].ResourceID)
+	assert.True(t, entries[0].Success)
+}
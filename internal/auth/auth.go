@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,10 @@ type Service interface {
 	RefreshToken(ctx context.Context, refreshToken string) (*AuthResult, error)
 	RevokeToken(ctx context.Context, token string) error
 
+	// Token introspection and revocation
+	Introspect(ctx context.Context, token string) (*TokenIntrospection, error)
+	RevokeAllForUser(ctx context.Context, userID string) error
+
 	// Service capabilities
 	GetSupportedStrategies() []string
 }
@@ -34,8 +39,26 @@ type TokenClaims struct {
 	Email     string    `json:"email"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiresAt time.Time `json:"expires_at"`
-	TokenType string    `json:"token_type"` // "access" or "refresh"
-	Strategy  string    `json:"strategy"`   // "basic", "oauth", etc.
+	TokenType string    `json:"token_type"`          // "access", "refresh", "api" or "guest"
+	Strategy  string    `json:"strategy"`            // "basic", "oauth", etc.
+	Scopes    []string  `json:"scopes,omitempty"`    // Granted to API keys and guests
+	FamilyID  string    `json:"family_id,omitempty"` // Refresh token family the token was issued in
+	AuthTime  time.Time `json:"auth_time,omitempty"` // When the user signed in; zero if unknown
+	AMR       []string  `json:"amr,omitempty"`       // How the user signed in, e.g. "pwd"
+}
+
+// TokenIntrospection describes a token the way an RFC 7662 introspection
+// response does. Invalid, expired and revoked tokens are only reported as
+// inactive, without any other field.
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`    // Space-separated scopes
+	Username  string `json:"username,omitempty"` // Email of the token's user
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"` // Unix seconds
+	IssuedAt  int64  `json:"iat,omitempty"` // Unix seconds
+	Subject   string `json:"sub,omitempty"` // ID of the token's user
+	Strategy  string `json:"strategy,omitempty"`
 }
 
 // User represents a user for authentication purposes
@@ -57,9 +80,13 @@ type BasicCredentials struct {
 	Password string `json:"password"`
 }
 
-// OAuthCredentials for OAuth authentication
+// OAuthCredentials for OAuth authentication; providers exchange Code for an
+// access token when it is set and use AccessToken as is otherwise. OpenID
+// Connect providers verify IDToken directly when a client already holds one.
 type OAuthCredentials struct {
-	Provider     string `json:"provider"` // "google", "github", etc.
+	Provider     string `json:"provider"`           // "google", "github", etc.
+	Code         string `json:"code,omitempty"`     // Authorization code from the provider's redirect
+	IDToken      string `json:"id_token,omitempty"` // OpenID Connect ID token
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int    `json:"expires_in"`
@@ -70,6 +97,30 @@ type JWTCredentials struct {
 	Token string `json:"token"`
 }
 
+// APIKeyCredentials for API key authentication with a long-lived API token
+type APIKeyCredentials struct {
+	Key string `json:"key"`
+}
+
+// GuestCredentials for guest authentication; guests have no credentials and
+// get a new guest identity each time
+type GuestCredentials struct{}
+
+// SAMLCredentials for SAML 2.0 single sign-on; SAMLResponse is the
+// base64 encoded response the identity provider posted to the ACS URL and
+// RequestID the ID of the AuthnRequest it answers, empty for sign-ins the
+// identity provider started
+type SAMLCredentials struct {
+	SAMLResponse string `json:"saml_response"`
+	RequestID    string `json:"request_id,omitempty"`
+}
+
+// MagicLinkCredentials for passwordless sign-in with the one-time token of
+// an emailed magic link
+type MagicLinkCredentials struct {
+	Token string `json:"token"`
+}
+
 // OAuth provider data structures
 
 // OAuthUserInfo contains user information from OAuth provider
@@ -112,10 +163,88 @@ var (
 	ErrInvalidRefreshToken   = AuthError{Code: "INVALID_REFRESH_TOKEN", Message: "Invalid refresh token"}
 	ErrUserAlreadyExists     = AuthError{Code: "USER_EXISTS", Message: "User already exists"}
 	ErrOAuthProviderNotFound = AuthError{Code: "OAUTH_PROVIDER_NOT_FOUND", Message: "OAuth provider not configured"}
+	ErrOAuthEmailNotVerified = AuthError{Code: "OAUTH_EMAIL_NOT_VERIFIED", Message: "OAuth provider did not share a verified email"}
+	ErrInsufficientScope     = AuthError{Code: "INSUFFICIENT_SCOPE", Message: "API key lacks the scope this operation requires"}
+	ErrInvalidSAMLResponse   = AuthError{Code: "INVALID_SAML_RESPONSE", Message: "SAML response is invalid"}
+	ErrGuestTokenRequired    = AuthError{Code: "GUEST_TOKEN_REQUIRED", Message: "Only guest tokens can be upgraded"}
+	ErrTooManyAttempts       = AuthError{Code: "TOO_MANY_ATTEMPTS", Message: "Too many failed attempts, try again later"}
+	ErrRefreshTokenReused    = AuthError{Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token was already used; the session was revoked"}
+	ErrStepUpRequired        = AuthError{Code: "STEP_UP_REQUIRED", Message: "Sign in again to perform this operation"}
 )
 
+// StepUpError is returned by RequireRecentAuth when the caller signed in
+// longer than MaxAge ago. It matches ErrStepUpRequired.
+type StepUpError struct {
+	MaxAge time.Duration
+}
+
+func (e StepUpError) Error() string {
+	return ErrStepUpRequired.Message
+}
+
+// Unwrap lets errors.Is and errors.As treat the error as ErrStepUpRequired
+func (e StepUpError) Unwrap() error {
+	return ErrStepUpRequired
+}
+
+// Authentication describes the sign-in behind the caller's token, taken from
+// its auth_time and amr claims
+type Authentication struct {
+	Time    time.Time
+	Methods []string
+}
+
+// authenticationKey is the context key carrying the caller's sign-in
+type authenticationKey struct{}
+
+// WithAuthentication returns a context carrying the caller's sign-in, for
+// operations that require a recent one
+func WithAuthentication(ctx context.Context, authentication Authentication) context.Context {
+	return context.WithValue(ctx, authenticationKey{}, authentication)
+}
+
+// AuthenticationFromContext returns the caller's sign-in, if known
+func AuthenticationFromContext(ctx context.Context) (Authentication, bool) {
+	authentication, ok := ctx.Value(authenticationKey{}).(Authentication)
+	return authentication, ok && !authentication.Time.IsZero()
+}
+
+// RequireRecentAuth returns a StepUpError unless the caller signed in within
+// maxAge. Callers whose sign-in is unknown, such as those using API keys or
+// impersonation tokens, must always step up.
+func RequireRecentAuth(ctx context.Context, maxAge time.Duration) error {
+	authentication, ok := AuthenticationFromContext(ctx)
+	if !ok || time.Since(authentication.Time) > maxAge {
+		return StepUpError{MaxAge: maxAge}
+	}
+	return nil
+}
+
 // Helper methods for domain types
 
+// NewTokenIntrospection returns the introspection of a token with the
+// claims, or an inactive one when claims is nil
+func NewTokenIntrospection(claims *TokenClaims) *TokenIntrospection {
+	if claims == nil {
+		return &TokenIntrospection{Active: false}
+	}
+	introspection := &TokenIntrospection{
+		Active:    true,
+		Scope:     strings.Join(claims.Scopes, " "),
+		Username:  claims.Email,
+		TokenType: claims.TokenType,
+		Subject:   claims.UserID,
+		Strategy:  claims.Strategy,
+	}
+	if !claims.ExpiresAt.IsZero() {
+		introspection.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	if !claims.IssuedAt.IsZero() {
+		introspection.IssuedAt = claims.IssuedAt.Unix()
+	}
+	return introspection
+}
+
 // Helper methods for User
 func (u *User) GetFullName() string {
 	return u.FirstName + " " + u.LastName
@@ -150,3 +279,25 @@ func (c *TokenClaims) IsAccessToken() bool {
 func (c *TokenClaims) IsRefreshToken() bool {
 	return c.TokenType == "refresh"
 }
+
+func (c *TokenClaims) IsAPIKey() bool {
+	return c.TokenType == "api"
+}
+
+func (c *TokenClaims) IsGuest() bool {
+	return c.TokenType == "guest"
+}
+
+// HasScope reports whether the claims grant the scope. "*" grants everything
+// and "resource:*" grants every action on a resource.
+func (c *TokenClaims) HasScope(scope string) bool {
+	for _, granted := range c.Scopes {
+		if granted == "*" || granted == scope {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(granted, "*"); ok && prefix != "" && strings.HasPrefix(scope, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package auth_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -404,6 +406,59 @@ func TestTokenClaims_IsRefreshToken(t *testing.T) {
 	}
 }
 
+func TestTokenClaims_HasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		scope    string
+		expected bool
+	}{
+		{
+			name:     "Given claims granting the scope, When HasScope is called, Then should return true",
+			scopes:   []string{"users:read"},
+			scope:    "users:read",
+			expected: true,
+		},
+		{
+			name:     "Given claims granting every action on the resource, When HasScope is called, Then should return true",
+			scopes:   []string{"users:*"},
+			scope:    "users:write",
+			expected: true,
+		},
+		{
+			name:     "Given claims granting everything, When HasScope is called, Then should return true",
+			scopes:   []string{"*"},
+			scope:    "notifications:read",
+			expected: true,
+		},
+		{
+			name:     "Given claims granting other scopes, When HasScope is called, Then should return false",
+			scopes:   []string{"users:read", "notifications:*"},
+			scope:    "users:write",
+			expected: false,
+		},
+		{
+			name:     "Given claims without scopes, When HasScope is called, Then should return false",
+			scopes:   nil,
+			scope:    "users:read",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			claims := auth.TokenClaims{TokenType: "api", Scopes: tt.scopes}
+
+			// Act
+			result := claims.HasScope(tt.scope)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestAuthError_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -576,4 +631,44 @@ func TestCreateUserData_Structure(t *testing.T) {
 		assert.Equal(t, "John", userData.FirstName)
 		assert.Equal(t, "Doe", userData.LastName)
 	})
-}
\ No newline at end of file
+}
+func TestRequireRecentAuth(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		expectStepUp bool
+	}{
+		{
+			name:         "Given sign-in within max age, When requiring recent auth, Then should allow",
+			ctx:          auth.WithAuthentication(context.Background(), auth.Authentication{Time: time.Now().Add(-time.Minute), Methods: []string{"pwd"}}),
+			expectStepUp: false,
+		},
+		{
+			name:         "Given sign-in older than max age, When requiring recent auth, Then should require step-up",
+			ctx:          auth.WithAuthentication(context.Background(), auth.Authentication{Time: time.Now().Add(-time.Hour)}),
+			expectStepUp: true,
+		},
+		{
+			name:         "Given unknown sign-in, When requiring recent auth, Then should require step-up",
+			ctx:          context.Background(),
+			expectStepUp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := auth.RequireRecentAuth(tt.ctx, 5*time.Minute)
+
+			// Assert
+			if !tt.expectStepUp {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, auth.ErrStepUpRequired)
+			var stepUp auth.StepUpError
+			assert.True(t, errors.As(err, &stepUp))
+			assert.Equal(t, 5*time.Minute, stepUp.MaxAge)
+		})
+	}
+}
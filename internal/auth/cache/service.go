@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+)
+
+// DefaultTTL is how long validated tokens are cached unless configured
+const DefaultTTL = 30 * time.Second
+
+// DefaultMaxEntries bounds the cache unless configured
+const DefaultMaxEntries = 10000
+
+// Config holds the size and TTL of the token validation cache
+type Config struct {
+	// TTL bounds how long a validation result is reused; tokens revoked
+	// on another instance or outside this chain stay valid here for at
+	// most this long
+	TTL time.Duration
+
+	// MaxEntries bounds cached validations and revocations together; the
+	// entries closest to expiry are evicted once the cache is full
+	MaxEntries int
+}
+
+// service implements auth.Service with cached token validation
+// ValidateToken results are kept in process, keyed by a hash of the token,
+// until the TTL or the token's own expiry passes, whichever comes first.
+// Failed validations are not cached. RevokeToken drops the cached result and
+// remembers the revocation until the TTL passes, so a validation that was
+// already under way cannot put the token back. RevokeAllForUser does the
+// same for every cached token of the user. Every other method, Introspect
+// included, passes through.
+type service struct {
+	next   auth.Service
+	config Config
+
+	mu           sync.Mutex
+	entries      map[string]entry
+	revokedUsers map[string]time.Time // When each user's tokens were revoked, kept for the TTL
+}
+
+// entry is a cached validation, or a revocation when claims is nil
+type entry struct {
+	claims    *auth.TokenClaims
+	expiresAt time.Time
+}
+
+// NewService creates a new caching auth service; zero config values use
+// DefaultTTL and DefaultMaxEntries
+func NewService(next auth.Service, config Config) auth.Service {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+
+	return &service{
+		next:         next,
+		config:       config,
+		entries:      make(map[string]entry),
+		revokedUsers: make(map[string]time.Time),
+	}
+}
+
+// Authenticate passes through
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	return s.next.Authenticate(ctx, strategy, credentials)
+}
+
+// ValidateToken returns cached claims and caches the claims of valid tokens
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	key := cacheKey(token)
+	if cached, ok := s.get(key); ok {
+		if cached.claims == nil {
+			return nil, auth.ErrInvalidToken
+		}
+		return copyClaims(cached.claims), nil
+	}
+
+	claims, err := s.next.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.config.TTL)
+	if !claims.ExpiresAt.IsZero() && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt
+	}
+	s.add(key, entry{claims: copyClaims(claims), expiresAt: expiresAt})
+	return claims, nil
+}
+
+// RefreshToken passes through
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return s.next.RefreshToken(ctx, refreshToken)
+}
+
+// RevokeToken revokes the token and replaces its cached claims with the revocation
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	if err := s.next.RevokeToken(ctx, token); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[cacheKey(token)] = entry{expiresAt: time.Now().Add(s.config.TTL)}
+	s.evict(time.Now())
+	return nil
+}
+
+// Introspect passes through
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.next.Introspect(ctx, token)
+}
+
+// RevokeAllForUser revokes the user's tokens and drops their cached claims
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	if err := s.next.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, e := range s.entries {
+		if e.claims != nil && e.claims.UserID == userID {
+			delete(s.entries, key)
+		}
+	}
+	for revokedUser, revokedAt := range s.revokedUsers {
+		if !now.Before(revokedAt.Add(s.config.TTL)) {
+			delete(s.revokedUsers, revokedUser)
+		}
+	}
+	s.revokedUsers[userID] = now
+	return nil
+}
+
+// GetSupportedStrategies passes through
+func (s *service) GetSupportedStrategies() []string {
+	return s.next.GetSupportedStrategies()
+}
+
+// Helper methods
+
+// get returns the entry under key while it lasts
+func (s *service) get(key string) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.expiresAt) {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// add caches a validation unless the token, or all of its user's tokens,
+// were revoked in the meantime
+func (s *service) add(key string, e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.entries[key]; ok && existing.claims == nil && now.Before(existing.expiresAt) {
+		return
+	}
+	if revokedAt, ok := s.revokedUsers[e.claims.UserID]; ok && now.Before(revokedAt.Add(s.config.TTL)) &&
+		!e.claims.IssuedAt.After(revokedAt) {
+		return
+	}
+	s.entries[key] = e
+	s.evict(now)
+}
+
+// evict drops expired entries and, while the cache is still over its
+// bound, the entry closest to expiry; the caller must hold the lock
+func (s *service) evict(now time.Time) {
+	if len(s.entries) <= s.config.MaxEntries {
+		return
+	}
+
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	for len(s.entries) > s.config.MaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, e := range s.entries {
+			if oldestKey == "" || e.expiresAt.Before(oldest) {
+				oldestKey, oldest = key, e.expiresAt
+			}
+		}
+		delete(s.entries, oldestKey)
+	}
+}
+
+// cacheKey hashes the token so the cache holds no usable credentials
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// copyClaims returns a copy callers can modify without changing the cache
+func copyClaims(claims *auth.TokenClaims) *auth.TokenClaims {
+	copied := *claims
+	copied.Scopes = append([]string(nil), claims.Scopes...)
+	return &copied
+}
@@ -0,0 +1,170 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authCache "github.com/gentra/decorator-arch-go/internal/auth/cache"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+)
+
+func validClaims(expiresAt time.Time) *auth.TokenClaims {
+	return &auth.TokenClaims{
+		UserID:    "user-123",
+		Email:     "test@example.com",
+		ExpiresAt: expiresAt,
+		TokenType: "api",
+		Scopes:    []string{"users:read"},
+	}
+}
+
+func TestCache_GivenValidToken_WhenValidatedTwice_ThenAsksNextOnce(t *testing.T) {
+	// Arrange
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, "token-1").Return(validClaims(time.Now().Add(time.Hour)), nil).Once()
+	service := authCache.NewService(next, authCache.Config{TTL: time.Minute})
+	ctx := context.Background()
+
+	// Act
+	first, err := service.ValidateToken(ctx, "token-1")
+	require.NoError(t, err)
+	first.Scopes[0] = "tampered"
+	second, err := service.ValidateToken(ctx, "token-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", second.UserID)
+	assert.Equal(t, []string{"users:read"}, second.Scopes, "callers cannot change cached claims")
+	next.AssertNumberOfCalls(t, "ValidateToken", 1)
+}
+
+func TestCache_GivenInvalidToken_WhenValidatedTwice_ThenAsksNextEachTime(t *testing.T) {
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, "bad").Return(nil, auth.ErrInvalidToken)
+	service := authCache.NewService(next, authCache.Config{})
+
+	_, firstErr := service.ValidateToken(context.Background(), "bad")
+	_, secondErr := service.ValidateToken(context.Background(), "bad")
+
+	assert.Equal(t, auth.ErrInvalidToken, firstErr)
+	assert.Equal(t, auth.ErrInvalidToken, secondErr)
+	next.AssertNumberOfCalls(t, "ValidateToken", 2)
+}
+
+func TestCache_GivenCachedValidation_WhenTTLOrTokenExpires_ThenAsksNextAgain(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		expiresIn time.Duration
+	}{
+		{
+			name:      "Given a short TTL, When it passes, Then should validate again",
+			ttl:       10 * time.Millisecond,
+			expiresIn: time.Hour,
+		},
+		{
+			name:      "Given a token expiring before the TTL, When it expires, Then should validate again",
+			ttl:       time.Hour,
+			expiresIn: 10 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := new(authmock.MockAuthStrategy)
+			next.On("ValidateToken", mock.Anything, "token-1").Return(validClaims(time.Now().Add(tt.expiresIn)), nil)
+			service := authCache.NewService(next, authCache.Config{TTL: tt.ttl})
+			_, err := service.ValidateToken(context.Background(), "token-1")
+			require.NoError(t, err)
+
+			// Act
+			time.Sleep(20 * time.Millisecond)
+			_, _ = service.ValidateToken(context.Background(), "token-1")
+
+			// Assert
+			next.AssertNumberOfCalls(t, "ValidateToken", 2)
+		})
+	}
+}
+
+func TestCache_GivenCachedToken_WhenRevoked_ThenRejectsIt(t *testing.T) {
+	// Arrange
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, "token-1").Return(validClaims(time.Now().Add(time.Hour)), nil)
+	next.On("RevokeToken", mock.Anything, "token-1").Return(nil)
+	service := authCache.NewService(next, authCache.Config{TTL: time.Minute})
+	ctx := context.Background()
+	_, err := service.ValidateToken(ctx, "token-1")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, service.RevokeToken(ctx, "token-1"))
+	claims, err := service.ValidateToken(ctx, "token-1")
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.Equal(t, auth.ErrInvalidToken, err)
+	next.AssertNumberOfCalls(t, "ValidateToken", 1)
+}
+
+func TestCache_GivenRevocationDuringValidation_WhenValidationFinishes_ThenDoesNotCacheIt(t *testing.T) {
+	// Arrange - the next layer still accepts the token while it is revoked
+	next := new(authmock.MockAuthStrategy)
+	service := authCache.NewService(next, authCache.Config{TTL: time.Minute})
+	ctx := context.Background()
+	next.On("RevokeToken", mock.Anything, "token-1").Return(nil)
+	next.On("ValidateToken", mock.Anything, "token-1").Run(func(mock.Arguments) {
+		require.NoError(t, service.RevokeToken(ctx, "token-1"))
+	}).Return(validClaims(time.Now().Add(time.Hour)), nil).Once()
+
+	// Act
+	_, firstErr := service.ValidateToken(ctx, "token-1")
+	_, secondErr := service.ValidateToken(ctx, "token-1")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Equal(t, auth.ErrInvalidToken, secondErr)
+}
+
+func TestCache_GivenCachedTokens_WhenAllOfUsersTokensRevoked_ThenValidatesThemAgain(t *testing.T) {
+	// Arrange
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, "token-1").Return(validClaims(time.Now().Add(time.Hour)), nil).Once()
+	next.On("ValidateToken", mock.Anything, "token-1").Return(nil, auth.ErrInvalidToken)
+	next.On("RevokeAllForUser", mock.Anything, "user-123").Return(nil)
+	service := authCache.NewService(next, authCache.Config{TTL: time.Minute})
+	ctx := context.Background()
+	_, err := service.ValidateToken(ctx, "token-1")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, service.RevokeAllForUser(ctx, "user-123"))
+	claims, err := service.ValidateToken(ctx, "token-1")
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.Equal(t, auth.ErrInvalidToken, err)
+	next.AssertNumberOfCalls(t, "ValidateToken", 2)
+}
+
+func TestCache_GivenFullCache_WhenCachingMore_ThenStaysWithinBound(t *testing.T) {
+	next := new(authmock.MockAuthStrategy)
+	next.On("ValidateToken", mock.Anything, mock.Anything).Return(validClaims(time.Now().Add(time.Hour)), nil)
+	service := authCache.NewService(next, authCache.Config{TTL: time.Minute, MaxEntries: 2})
+	ctx := context.Background()
+
+	for _, token := range []string{"token-1", "token-2", "token-3"} {
+		_, err := service.ValidateToken(ctx, token)
+		require.NoError(t, err)
+	}
+	_, _ = service.ValidateToken(ctx, "token-1")
+
+	next.AssertNumberOfCalls(t, "ValidateToken", 4)
+}
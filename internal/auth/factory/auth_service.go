@@ -4,8 +4,23 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
 	"github.com/gentra/decorator-arch-go/internal/auth"
+	authAudit "github.com/gentra/decorator-arch-go/internal/auth/audit"
+	authCache "github.com/gentra/decorator-arch-go/internal/auth/cache"
+	authMetrics "github.com/gentra/decorator-arch-go/internal/auth/metrics"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/github"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/google"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/oidc"
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
 	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
@@ -19,26 +34,89 @@ type Config struct {
 	// User integration (from user domain)
 	UserService user.Service
 
+	// Token integration (from token domain); validates API keys, and the
+	// tokens it issues are introspected and revoked with the auth service's
+	TokenService token.Service
+
 	// OAuth providers (now auth.Service implementations)
 	OAuthProviders map[string]auth.Service
 
+	// OAuth clients of the built-in providers; configured ones are added to
+	// OAuthProviders as "google" and "github"
+	GoogleOAuth oauth.Config
+	GitHubOAuth oauth.Config
+
+	// OpenID Connect providers such as Keycloak, Auth0 or Okta by the
+	// provider name credentials select them with
+	OIDCProviders map[string]oidc.Config
+
+	// SAML 2.0 identity provider this service provider trusts
+	SAML saml.Config
+
+	// Lifetime and scopes of guest tokens
+	Guest usecase.GuestConfig
+
+	// Lifetime of magic links; they are issued and consumed by TokenService
+	MagicLink usecase.MagicLinkConfig
+
+	// Records revoked tokens and users; instances sharing a store honour
+	// each other's revocations. Nil keeps them in process, where they are
+	// lost on restart. RevocationTTL is the least time a user revocation is
+	// kept.
+	RevocationStore revocation.Service
+	RevocationTTL   time.Duration
+
+	// Brute-force throttling of basic auth; disabled when ThrottleService is nil
+	ThrottleService throttle.Service
+	Throttle        usecase.ThrottleConfig
+
+	// Audit trail of authentications; required when EnableAudit is set
+	AuditService audit.Service
+
+	// Receives auth.token.reuse_detected when a rotated refresh token is
+	// used again; optional
+	EventsService events.Service
+
+	// Registerer for auth service metrics; nil uses prometheus.DefaultRegisterer
+	MetricsRegisterer prometheus.Registerer
+
+	// Size and TTL of the token validation cache; zero values use the
+	// authCache defaults
+	Cache authCache.Config
+
 	// Feature flags
 	Features FeatureFlags
 }
 
 // FeatureFlags controls which authentication strategies are enabled
 type FeatureFlags struct {
-	EnableBasicAuth bool
-	EnableOAuth     bool
-	EnableJWTAuth   bool
+	EnableBasicAuth  bool
+	EnableOAuth      bool
+	EnableJWTAuth    bool
+	EnableAPIKeyAuth bool
+	EnableSAML       bool
+	EnableGuestAuth  bool
+	EnableMagicLink  bool
+
+	// Decorators around the strategies
+	EnableCache   bool // Caches ValidateToken results in process
+	EnableAudit   bool // Audits authentications, refreshes, revocations and rejected tokens
+	EnableMetrics bool
 }
 
 // DefaultFeatureFlags returns default feature flag configuration
 func DefaultFeatureFlags() FeatureFlags {
 	return FeatureFlags{
-		EnableBasicAuth: true,
-		EnableOAuth:     false, // Disabled by default as it requires provider setup
-		EnableJWTAuth:   true,
+		EnableBasicAuth:  true,
+		EnableOAuth:      false, // Disabled by default as it requires provider setup
+		EnableJWTAuth:    true,
+		EnableAPIKeyAuth: false, // Disabled by default as it requires a token service
+		EnableSAML:       false, // Disabled by default as it requires identity provider setup
+		EnableGuestAuth:  false, // Disabled by default as guests act without an account
+		EnableMagicLink:  false, // Disabled by default as it requires a token service and email delivery
+		EnableCache:      false, // Disabled by default as revocations on other instances take up to the TTL to apply
+		EnableAudit:      false, // Disabled by default as it requires an audit service
+		EnableMetrics:    false, // Requires a metrics endpoint to be useful
 	}
 }
 
@@ -63,19 +141,36 @@ func (f *AuthServiceFactory) Build() (auth.Service, error) {
 
 	// Create JWT token manager (from usecase)
 	tokenManager := usecase.NewJWTTokenManager(f.config.JWTSecret, f.config.AccessTTL, f.config.RefreshTTL)
+	if f.config.RevocationStore != nil {
+		tokenManager = usecase.NewJWTTokenManagerWithRevocations(f.config.JWTSecret, f.config.AccessTTL, f.config.RefreshTTL,
+			f.config.RevocationStore, f.config.RevocationTTL)
+	}
 
-	// Create the auth orchestrator (business logic layer)
+	// Create the auth orchestrator (business logic layer); with a token
+	// service its tokens are introspected and revoked too
 	orchestrator := usecase.NewAuthOrchestrator(tokenManager)
+	if f.config.TokenService != nil {
+		orchestrator = usecase.NewAuthOrchestratorWithTokenService(tokenManager, f.config.TokenService)
+	}
+	if f.config.EventsService != nil {
+		orchestrator.SetEventPublisher(f.config.EventsService)
+	}
 
 	// Register enabled strategies
 	if f.config.Features.EnableBasicAuth {
 		basicStrategy := usecase.NewBasicAuthStrategy(f.config.UserService, tokenManager)
+		if f.config.ThrottleService != nil {
+			basicStrategy = usecase.NewBasicAuthStrategyWithThrottle(f.config.UserService, tokenManager, f.config.ThrottleService, f.config.Throttle)
+		}
 		orchestrator.RegisterStrategy("basic", basicStrategy)
 	}
 
-	if f.config.Features.EnableOAuth && len(f.config.OAuthProviders) > 0 {
-		oauthStrategy := usecase.NewOAuthAuthStrategy(f.config.UserService, tokenManager, f.config.OAuthProviders)
-		orchestrator.RegisterStrategy("oauth", oauthStrategy)
+	if f.config.Features.EnableOAuth {
+		providers := f.oauthProviders(tokenManager)
+		if len(providers) > 0 {
+			oauthStrategy := usecase.NewOAuthAuthStrategy(f.config.UserService, tokenManager, providers)
+			orchestrator.RegisterStrategy("oauth", oauthStrategy)
+		}
 	}
 
 	if f.config.Features.EnableJWTAuth {
@@ -83,8 +178,58 @@ func (f *AuthServiceFactory) Build() (auth.Service, error) {
 		orchestrator.RegisterStrategy("jwt", jwtStrategy)
 	}
 
-	// Return the orchestrator - pure composition, no business logic in factory
-	return orchestrator, nil
+	if f.config.Features.EnableAPIKeyAuth {
+		apiKeyStrategy := usecase.NewAPIKeyAuthStrategy(f.config.UserService, f.config.TokenService)
+		orchestrator.RegisterStrategy(usecase.APIKeyStrategy, apiKeyStrategy)
+	}
+
+	if f.config.Features.EnableSAML {
+		samlStrategy := saml.NewService(f.config.SAML, f.config.UserService, tokenManager)
+		orchestrator.RegisterStrategy(saml.Strategy, samlStrategy)
+	}
+
+	if f.config.Features.EnableGuestAuth {
+		guestStrategy := usecase.NewGuestAuthStrategy(f.config.UserService, tokenManager, f.config.Guest)
+		orchestrator.RegisterStrategy(usecase.GuestStrategy, guestStrategy)
+	}
+
+	if f.config.Features.EnableMagicLink {
+		magicLinkStrategy := usecase.NewMagicLinkAuthStrategy(f.config.UserService, f.config.TokenService, tokenManager)
+		orchestrator.RegisterStrategy(usecase.MagicLinkStrategy, magicLinkStrategy)
+	}
+
+	// Wrap the orchestrator in the enabled decorators - pure composition, no
+	// business logic in factory
+	var service auth.Service = orchestrator
+
+	// Add cache layer if enabled; innermost so audit and metrics see cache hits too
+	if f.config.Features.EnableCache {
+		service = authCache.NewService(service, f.config.Cache)
+	}
+
+	// Add audit layer if enabled
+	if f.config.Features.EnableAudit {
+		service = authAudit.NewService(service, f.config.AuditService)
+	}
+
+	// Add metrics layer if enabled; outermost so it measures the whole chain
+	if f.config.Features.EnableMetrics {
+		var err error
+		service, err = authMetrics.NewService(service, f.metricsRegisterer())
+		if err != nil {
+			return nil, fmt.Errorf("failed to add metrics layer: %w", err)
+		}
+	}
+
+	return service, nil
+}
+
+// metricsRegisterer returns the configured registerer or the Prometheus default
+func (f *AuthServiceFactory) metricsRegisterer() prometheus.Registerer {
+	if f.config.MetricsRegisterer == nil {
+		return prometheus.DefaultRegisterer
+	}
+	return f.config.MetricsRegisterer
 }
 
 // validateConfig validates the factory configuration
@@ -110,15 +255,109 @@ func (f *AuthServiceFactory) validateConfig() error {
 	}
 
 	// Validate that at least one strategy is enabled
-	if !f.config.Features.EnableBasicAuth && !f.config.Features.EnableOAuth && !f.config.Features.EnableJWTAuth {
+	if !f.config.Features.EnableBasicAuth && !f.config.Features.EnableOAuth && !f.config.Features.EnableJWTAuth &&
+		!f.config.Features.EnableAPIKeyAuth && !f.config.Features.EnableSAML && !f.config.Features.EnableGuestAuth &&
+		!f.config.Features.EnableMagicLink {
 		return fmt.Errorf("at least one authentication strategy must be enabled")
 	}
 
+	if f.config.Features.EnableAPIKeyAuth && f.config.TokenService == nil {
+		return fmt.Errorf("token service is required when API key auth is enabled")
+	}
+
+	if f.config.Features.EnableMagicLink && f.config.TokenService == nil {
+		return fmt.Errorf("token service is required when magic link auth is enabled")
+	}
+
+	if f.config.Features.EnableAudit && f.config.AuditService == nil {
+		return fmt.Errorf("audit service is required when audit is enabled")
+	}
+
+	if f.config.Features.EnableSAML {
+		if err := validateSAML(f.config.SAML); err != nil {
+			return err
+		}
+	}
+
 	// Validate OAuth configuration if enabled
-	if f.config.Features.EnableOAuth && len(f.config.OAuthProviders) == 0 {
-		return fmt.Errorf("OAuth providers must be configured when OAuth is enabled")
+	if f.config.Features.EnableOAuth {
+		if len(f.config.OAuthProviders) == 0 && len(f.config.OIDCProviders) == 0 &&
+			!f.config.GoogleOAuth.IsConfigured() && !f.config.GitHubOAuth.IsConfigured() {
+			return fmt.Errorf("OAuth providers must be configured when OAuth is enabled")
+		}
+		if err := validateOAuthClient(google.ProviderName, f.config.GoogleOAuth); err != nil {
+			return err
+		}
+		if err := validateOAuthClient(github.ProviderName, f.config.GitHubOAuth); err != nil {
+			return err
+		}
+		for name, provider := range f.config.OIDCProviders {
+			if provider.IssuerURL == "" {
+				return fmt.Errorf("%s OIDC issuer URL is required", name)
+			}
+			if !provider.IsConfigured() {
+				return fmt.Errorf("%s OIDC client ID is required", name)
+			}
+			if err := validateOAuthClient(name, provider.Config); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// oauthProviders returns the configured providers together with the OIDC
+// providers and the built-in ones that have a client configured
+func (f *AuthServiceFactory) oauthProviders(tokenManager *usecase.JWTTokenManager) map[string]auth.Service {
+	providers := make(map[string]auth.Service, len(f.config.OAuthProviders)+len(f.config.OIDCProviders)+2)
+	for name, provider := range f.config.OAuthProviders {
+		providers[name] = provider
 	}
+	for name, provider := range f.config.OIDCProviders {
+		providers[name] = oidc.NewService(provider, f.config.UserService, tokenManager)
+	}
+
+	if f.config.GoogleOAuth.IsConfigured() {
+		providers[google.ProviderName] = google.NewService(f.config.GoogleOAuth, f.config.UserService, tokenManager)
+	}
+	if f.config.GitHubOAuth.IsConfigured() {
+		providers[github.ProviderName] = github.NewService(f.config.GitHubOAuth, f.config.UserService, tokenManager)
+	}
+	return providers
+}
+
+// validateOAuthClient checks that a configured client can exchange codes
+func validateOAuthClient(provider string, config oauth.Config) error {
+	if !config.IsConfigured() {
+		return nil
+	}
+	if config.ClientSecret == "" {
+		return fmt.Errorf("%s OAuth client secret is required", provider)
+	}
+	if config.RedirectURI == "" {
+		return fmt.Errorf("%s OAuth redirect URI is required", provider)
+	}
+	return nil
+}
 
+// validateSAML checks that responses of the identity provider can be verified
+func validateSAML(config saml.Config) error {
+	if config.EntityID == "" {
+		return fmt.Errorf("SAML entity ID is required")
+	}
+	if config.ACSURL == "" {
+		return fmt.Errorf("SAML ACS URL is required")
+	}
+	if config.IdPEntityID == "" {
+		return fmt.Errorf("SAML identity provider entity ID is required")
+	}
+	if !config.IsConfigured() {
+		return fmt.Errorf("SAML identity provider SSO URL is required")
+	}
+	if _, err := config.Certificates(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -5,11 +5,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
 	"github.com/gentra/decorator-arch-go/internal/auth"
 	"github.com/gentra/decorator-arch-go/internal/auth/factory"
 	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/oidc"
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
 	"github.com/gentra/decorator-arch-go/internal/user"
 	usermock "github.com/gentra/decorator-arch-go/internal/user/mock"
 )
@@ -17,6 +26,13 @@ import (
 // MockOAuthProvider for testing - now using centralized mock
 type MockOAuthProvider = authmock.MockOAuthProvider
 
+// failingLoginUserService returns a user service rejecting every password
+func failingLoginUserService() *usermock.MockUserService {
+	userService := new(usermock.MockUserService)
+	userService.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	return userService
+}
+
 func TestAuthServiceFactory_Build(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -176,6 +192,204 @@ func TestAuthServiceFactory_Build(t *testing.T) {
 			expectError: true,
 			expectedErr: "at least one authentication strategy must be enabled",
 		},
+		{
+			name: "Given API key auth with a token service, When Build is called, Then should create auth service with the apikey strategy",
+			config: factory.Config{
+				JWTSecret:    []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:    time.Hour,
+				RefreshTTL:   24 * time.Hour,
+				UserService:  new(usermock.MockUserService),
+				TokenService: testkit.NewTokenService(t),
+				Features: factory.FeatureFlags{
+					EnableBasicAuth:  true,
+					EnableJWTAuth:    true,
+					EnableAPIKeyAuth: true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Contains(t, service.GetSupportedStrategies(), "apikey")
+			},
+		},
+		{
+			name: "Given API key auth without a token service, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				Features: factory.FeatureFlags{
+					EnableAPIKeyAuth: true,
+				},
+			},
+			expectError: true,
+			expectedErr: "token service is required when API key auth is enabled",
+		},
+		{
+			name: "Given magic link auth with a token service, When Build is called, Then should create auth service with the magic_link strategy",
+			config: factory.Config{
+				JWTSecret:    []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:    time.Hour,
+				RefreshTTL:   24 * time.Hour,
+				UserService:  new(usermock.MockUserService),
+				TokenService: testkit.NewTokenService(t),
+				Features: factory.FeatureFlags{
+					EnableMagicLink: true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Equal(t, []string{"magic_link"}, service.GetSupportedStrategies())
+			},
+		},
+		{
+			name: "Given magic link auth without a token service, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				Features: factory.FeatureFlags{
+					EnableMagicLink: true,
+				},
+			},
+			expectError: true,
+			expectedErr: "token service is required when magic link auth is enabled",
+		},
+		{
+			name: "Given guest auth enabled, When Build is called, Then should create auth service with the guest strategy",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableGuestAuth: true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Contains(t, service.GetSupportedStrategies(), "guest")
+
+				result, err := service.Authenticate(context.Background(), "guest", auth.GuestCredentials{})
+				assert.NoError(t, err)
+				assert.Equal(t, "guest", result.Strategy)
+			},
+		},
+		{
+			name: "Given a throttle store, When Build is called, Then should throttle failed basic auth attempts",
+			config: factory.Config{
+				JWTSecret:       []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:       time.Hour,
+				RefreshTTL:      24 * time.Hour,
+				UserService:     failingLoginUserService(),
+				ThrottleService: throttleMemory.NewService(),
+				Throttle:        usecase.ThrottleConfig{FreeAttempts: 1, BaseDelay: time.Minute},
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				credentials := auth.BasicCredentials{Email: "test@example.com", Password: "wrong-password"}
+
+				_, err := service.Authenticate(context.Background(), "basic", credentials)
+				assert.Equal(t, auth.ErrInvalidCredentials, err)
+				_, err = service.Authenticate(context.Background(), "basic", credentials)
+				assert.Equal(t, auth.ErrTooManyAttempts, err)
+			},
+		},
+		{
+			name: "Given audit without an audit service, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableAudit:     true,
+				},
+			},
+			expectError: true,
+			expectedErr: "audit service is required when audit is enabled",
+		},
+		{
+			name: "Given cache, audit and metrics enabled, When Build is called, Then should wrap the strategies in the decorators",
+			config: factory.Config{
+				JWTSecret:         []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:         time.Hour,
+				RefreshTTL:        24 * time.Hour,
+				UserService:       failingLoginUserService(),
+				AuditService:      auditMemory.NewService(),
+				MetricsRegisterer: prometheus.NewRegistry(),
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableGuestAuth: true,
+					EnableCache:     true,
+					EnableAudit:     true,
+					EnableMetrics:   true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.ElementsMatch(t, []string{"basic", "guest"}, service.GetSupportedStrategies())
+
+				result, err := service.Authenticate(context.Background(), "guest", auth.GuestCredentials{})
+				assert.NoError(t, err)
+				claims, err := service.ValidateToken(context.Background(), result.Token)
+				assert.NoError(t, err)
+				assert.True(t, claims.IsGuest())
+
+				assert.NoError(t, service.RevokeToken(context.Background(), result.Token))
+				_, err = service.ValidateToken(context.Background(), result.Token)
+				assert.Error(t, err, "revoked tokens are not served from the cache")
+			},
+		},
+		{
+			name: "Given a SAML identity provider, When Build is called, Then should create auth service with the saml strategy",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				SAML: saml.Config{
+					EntityID:       testkit.SAMLSPEntityID,
+					ACSURL:         testkit.SAMLACSURL,
+					IdPEntityID:    testkit.SAMLIdPEntityID,
+					IdPSSOURL:      "https://idp.example.com/sso",
+					IdPCertificate: testkit.NewSAMLIdentityProvider(t).CertificatePEM(),
+				},
+				Features: factory.FeatureFlags{
+					EnableSAML: true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Equal(t, []string{"saml"}, service.GetSupportedStrategies())
+			},
+		},
+		{
+			name: "Given SAML enabled without an identity provider certificate, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				SAML: saml.Config{
+					EntityID:    testkit.SAMLSPEntityID,
+					ACSURL:      testkit.SAMLACSURL,
+					IdPEntityID: testkit.SAMLIdPEntityID,
+					IdPSSOURL:   "https://idp.example.com/sso",
+				},
+				Features: factory.FeatureFlags{
+					EnableSAML: true,
+				},
+			},
+			expectError: true,
+			expectedErr: "SAML identity provider certificate is required",
+		},
 		{
 			name: "Given OAuth enabled but no providers configured, When Build is called, Then should return validation error",
 			config: factory.Config{
@@ -193,6 +407,100 @@ func TestAuthServiceFactory_Build(t *testing.T) {
 			expectError: true,
 			expectedErr: "OAuth providers must be configured when OAuth is enabled",
 		},
+		{
+			name: "Given a Google OAuth client, When Build is called, Then should create auth service with the oauth strategy",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				GoogleOAuth: oauth.Config{
+					ClientID:     "client-id",
+					ClientSecret: "client-secret",
+					RedirectURI:  "https://app.example.com/oauth/google/callback",
+				},
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableOAuth:     true,
+					EnableJWTAuth:   true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Contains(t, service.GetSupportedStrategies(), "oauth")
+			},
+		},
+		{
+			name: "Given a GitHub OAuth client without a secret, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				GitHubOAuth: oauth.Config{
+					ClientID:    "client-id",
+					RedirectURI: "https://app.example.com/oauth/github/callback",
+				},
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableOAuth:     true,
+					EnableJWTAuth:   true,
+				},
+			},
+			expectError: true,
+			expectedErr: "github OAuth client secret is required",
+		},
+		{
+			name: "Given an OIDC provider without an issuer, When Build is called, Then should return validation error",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				OIDCProviders: map[string]oidc.Config{
+					"keycloak": {Config: oauth.Config{
+						ClientID:     "client-id",
+						ClientSecret: "client-secret",
+						RedirectURI:  "https://app.example.com/oauth/keycloak/callback",
+					}},
+				},
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableOAuth:     true,
+					EnableJWTAuth:   true,
+				},
+			},
+			expectError: true,
+			expectedErr: "keycloak OIDC issuer URL is required",
+		},
+		{
+			name: "Given an OIDC provider, When Build is called, Then should create auth service with the oauth strategy without contacting the provider",
+			config: factory.Config{
+				JWTSecret:   []byte("test-secret-key-32-bytes-long!!!"),
+				AccessTTL:   time.Hour,
+				RefreshTTL:  24 * time.Hour,
+				UserService: new(usermock.MockUserService),
+				OIDCProviders: map[string]oidc.Config{
+					"keycloak": {
+						Config: oauth.Config{
+							ClientID:     "client-id",
+							ClientSecret: "client-secret",
+							RedirectURI:  "https://app.example.com/oauth/keycloak/callback",
+						},
+						IssuerURL: "https://keycloak.invalid/realms/main",
+					},
+				},
+				Features: factory.FeatureFlags{
+					EnableBasicAuth: true,
+					EnableOAuth:     true,
+					EnableJWTAuth:   true,
+				},
+			},
+			expectError: false,
+			validateService: func(t *testing.T, service auth.Service) {
+				assert.Contains(t, service.GetSupportedStrategies(), "oauth")
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+)
+
+// service implements auth.Service with Prometheus metrics
+type service struct {
+	next           auth.Service
+	requests       *prometheus.CounterVec
+	errors         *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	authentication *prometheus.CounterVec
+}
+
+// NewService creates a new auth service that records request counts, latencies
+// and errors per method, and authentications per strategy, and registers the
+// collectors with the registerer
+func NewService(next auth.Service, registerer prometheus.Registerer) (auth.Service, error) {
+	s := &service{
+		next: next,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "auth_service",
+			Name:      "requests_total",
+			Help:      "Total auth service calls by method and outcome.",
+		}, []string{"method", "outcome"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "auth_service",
+			Name:      "errors_total",
+			Help:      "Total auth service errors by method and error code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "auth_service",
+			Name:      "request_duration_seconds",
+			Help:      "Auth service call latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		authentication: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "auth_service",
+			Name:      "authentications_total",
+			Help:      "Total authentications by strategy and outcome.",
+		}, []string{"strategy", "outcome"}),
+	}
+
+	for _, collector := range []prometheus.Collector{s.requests, s.errors, s.duration, s.authentication} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Authenticate records metrics for authentication, per method and per strategy
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	defer s.observe("Authenticate", time.Now())
+
+	result, err := s.next.Authenticate(ctx, strategy, credentials)
+	s.record("Authenticate", err)
+	s.authentication.WithLabelValues(s.strategyLabel(strategy), outcome(err)).Inc()
+	return result, err
+}
+
+// ValidateToken records metrics for token validation
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	defer s.observe("ValidateToken", time.Now())
+
+	result, err := s.next.ValidateToken(ctx, token)
+	s.record("ValidateToken", err)
+	return result, err
+}
+
+// RefreshToken records metrics for token refreshes
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	defer s.observe("RefreshToken", time.Now())
+
+	result, err := s.next.RefreshToken(ctx, refreshToken)
+	s.record("RefreshToken", err)
+	return result, err
+}
+
+// RevokeToken records metrics for token revocation
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	defer s.observe("RevokeToken", time.Now())
+
+	err := s.next.RevokeToken(ctx, token)
+	s.record("RevokeToken", err)
+	return err
+}
+
+// Introspect records metrics for token introspection
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	defer s.observe("Introspect", time.Now())
+
+	result, err := s.next.Introspect(ctx, token)
+	s.record("Introspect", err)
+	return result, err
+}
+
+// RevokeAllForUser records metrics for revoking every token of a user
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	defer s.observe("RevokeAllForUser", time.Now())
+
+	err := s.next.RevokeAllForUser(ctx, userID)
+	s.record("RevokeAllForUser", err)
+	return err
+}
+
+// GetSupportedStrategies passes through
+func (s *service) GetSupportedStrategies() []string {
+	return s.next.GetSupportedStrategies()
+}
+
+// Helper methods
+
+// observe records the latency of a call
+func (s *service) observe(method string, start time.Time) {
+	s.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// record counts a call and, on failure, its error code
+func (s *service) record(method string, err error) {
+	s.requests.WithLabelValues(method, outcome(err)).Inc()
+	if err != nil {
+		s.errors.WithLabelValues(method, errorCode(err)).Inc()
+	}
+}
+
+// strategyLabel returns the strategy, or "unsupported" for names callers
+// made up, so the label keeps a low cardinality
+func (s *service) strategyLabel(strategy string) string {
+	for _, supported := range s.next.GetSupportedStrategies() {
+		if supported == strategy {
+			return strategy
+		}
+	}
+	return "unsupported"
+}
+
+// outcome returns the outcome label of a call
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return "error"
+}
+
+// errorCode returns a low-cardinality label for an error
+func errorCode(err error) string {
+	var authErr auth.AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Code
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "CANCELED"
+	}
+	return "INTERNAL"
+}
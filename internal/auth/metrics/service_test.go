@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/metrics"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+)
+
+func TestMetrics_GivenCalls_WhenCompleted_ThenCountsByMethodOutcomeAndStrategy(t *testing.T) {
+	// Arrange
+	registry := prometheus.NewRegistry()
+	next := new(authmock.MockAuthStrategy)
+	next.On("GetSupportedStrategies").Return([]string{"basic", "jwt"})
+	next.On("Authenticate", mock.Anything, "basic", mock.Anything).Return(&auth.AuthResult{Strategy: "basic"}, nil).Once()
+	next.On("Authenticate", mock.Anything, "basic", mock.Anything).Return(nil, auth.ErrInvalidCredentials).Once()
+	next.On("Authenticate", mock.Anything, "made-up", mock.Anything).Return(nil, auth.ErrUnsupportedStrategy)
+	next.On("ValidateToken", mock.Anything, "token").Return(nil, errors.New("connection refused"))
+	next.On("Introspect", mock.Anything, "token").Return(&auth.TokenIntrospection{Active: false}, nil)
+	next.On("RevokeAllForUser", mock.Anything, "user-123").Return(nil)
+
+	service, err := metrics.NewService(next, registry)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	_, _ = service.Authenticate(ctx, "basic", auth.BasicCredentials{})
+	_, _ = service.Authenticate(ctx, "basic", auth.BasicCredentials{})
+	_, _ = service.Authenticate(ctx, "made-up", nil)
+	_, _ = service.ValidateToken(ctx, "token")
+	_, _ = service.Introspect(ctx, "token")
+	_ = service.RevokeAllForUser(ctx, "user-123")
+
+	// Assert
+	expected := `
+# HELP auth_service_authentications_total Total authentications by strategy and outcome.
+# TYPE auth_service_authentications_total counter
+auth_service_authentications_total{outcome="error",strategy="basic"} 1
+auth_service_authentications_total{outcome="error",strategy="unsupported"} 1
+auth_service_authentications_total{outcome="success",strategy="basic"} 1
+# HELP auth_service_errors_total Total auth service errors by method and error code.
+# TYPE auth_service_errors_total counter
+auth_service_errors_total{code="INTERNAL",method="ValidateToken"} 1
+auth_service_errors_total{code="INVALID_CREDENTIALS",method="Authenticate"} 1
+auth_service_errors_total{code="UNSUPPORTED_STRATEGY",method="Authenticate"} 1
+# HELP auth_service_requests_total Total auth service calls by method and outcome.
+# TYPE auth_service_requests_total counter
+auth_service_requests_total{method="Authenticate",outcome="error"} 2
+auth_service_requests_total{method="Authenticate",outcome="success"} 1
+auth_service_requests_total{method="Introspect",outcome="success"} 1
+auth_service_requests_total{method="RevokeAllForUser",outcome="success"} 1
+auth_service_requests_total{method="ValidateToken",outcome="error"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"auth_service_authentications_total", "auth_service_errors_total", "auth_service_requests_total"))
+}
+
+func TestMetrics_GivenRegisteredCollectors_WhenRegisteringAgain_ThenReturnsError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	next := new(authmock.MockAuthStrategy)
+
+	_, err := metrics.NewService(next, registry)
+	require.NoError(t, err)
+	_, err = metrics.NewService(next, registry)
+
+	assert.Error(t, err)
+}
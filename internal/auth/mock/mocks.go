@@ -75,6 +75,19 @@ func (m *MockAuthStrategy) RevokeToken(ctx context.Context, token string) error
 	return args.Error(0)
 }
 
+func (m *MockAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenIntrospection), args.Error(1)
+}
+
+func (m *MockAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func (m *MockAuthStrategy) GetSupportedStrategies() []string {
 	args := m.Called()
 	return args.Get(0).([]string)
@@ -114,6 +127,19 @@ func (m *MockOAuthProvider) RevokeToken(ctx context.Context, token string) error
 	return args.Error(0)
 }
 
+func (m *MockOAuthProvider) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenIntrospection), args.Error(1)
+}
+
+func (m *MockOAuthProvider) RevokeAllForUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func (m *MockOAuthProvider) GetSupportedStrategies() []string {
 	args := m.Called()
 	return args.Get(0).([]string)
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// ProviderName is the provider name OAuth credentials select GitHub with
+const ProviderName = "github"
+
+// DefaultAPIURL is GitHub's REST API; GitHub Enterprise serves it under /api/v3
+const DefaultAPIURL = "https://api.github.com"
+
+// DefaultScopes are requested when the config names none
+var DefaultScopes = []string{"read:user", "user:email"}
+
+// service implements auth.Service for signing in with GitHub
+// It exchanges the authorization code for a GitHub access token, reads the
+// user and their primary verified email from the REST API and signs them in,
+// creating the user on their first sign-in.
+type service struct {
+	config       oauth.Config
+	oauth2       *oauth2.Config
+	userService  user.Service
+	tokenManager *usecase.JWTTokenManager
+}
+
+// NewService creates a new GitHub OAuth provider
+func NewService(config oauth.Config, userService user.Service, tokenManager *usecase.JWTTokenManager) auth.Service {
+	return &service{
+		config:       config,
+		oauth2:       config.OAuth2Config(endpoints.GitHub, DefaultScopes),
+		userService:  userService,
+		tokenManager: tokenManager,
+	}
+}
+
+// AuthCodeURL returns the GitHub authorization page URL to redirect users to;
+// state must be checked when GitHub redirects back with the code
+func AuthCodeURL(config oauth.Config, state string) string {
+	return config.OAuth2Config(endpoints.GitHub, DefaultScopes).AuthCodeURL(state)
+}
+
+// Authenticate handles only the "oauth" strategy
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != oauth.Strategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	ctx = s.config.Context(ctx)
+	token, err := oauth.Token(ctx, s.oauth2, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.fetchUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth.SignIn(ctx, s.userService, s.tokenManager, info)
+}
+
+// ValidateToken delegates to token manager
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	return s.tokenManager.ValidateToken(token)
+}
+
+// RefreshToken delegates to token manager
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return oauth.Refresh(s.tokenManager, refreshToken)
+}
+
+// RevokeToken delegates to token manager
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns oauth strategy
+func (s *service) GetSupportedStrategies() []string {
+	return []string{oauth.Strategy}
+}
+
+// Helper methods
+
+// githubUser is the authenticated user of GitHub's REST API
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// githubEmail is one of the authenticated user's emails
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// fetchUserInfo reads the signed-in GitHub user. The profile email may be
+// hidden or unverified, so the primary email is read from the email list.
+func (s *service) fetchUserInfo(ctx context.Context, token *oauth2.Token) (*auth.OAuthUserInfo, error) {
+	client := s.oauth2.Client(ctx, token)
+	baseURL := s.config.APIBaseURL(DefaultAPIURL)
+
+	var profile githubUser
+	if err := oauth.GetJSON(ctx, client, baseURL+"/user", &profile); err != nil {
+		return nil, err
+	}
+	var emails []githubEmail
+	if err := oauth.GetJSON(ctx, client, baseURL+"/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	info := &auth.OAuthUserInfo{ID: strconv.FormatInt(profile.ID, 10)}
+	for _, email := range emails {
+		if email.Primary {
+			info.Email = email.Email
+			info.Verified = email.Verified
+			break
+		}
+	}
+
+	name := profile.Name
+	if name == "" {
+		name = profile.Login
+	}
+	info.FirstName, info.LastName = oauth.SplitName(name)
+	return info, nil
+}
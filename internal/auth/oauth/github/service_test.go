@@ -0,0 +1,106 @@
+package github_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/github"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+// newGitHubServer fakes GitHub's token endpoint and user API; emails is the
+// body of /user/emails
+func newGitHubServer(t *testing.T, emails string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "valid-code", r.PostForm.Get("code"))
+		// GitHub answers form-encoded unless asked for JSON
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = w.Write([]byte("access_token=github-access-token&token_type=bearer&scope=read%3Auser%2Cuser%3Aemail"))
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer github-access-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"id":42,"login":"octocat","name":"Mona Lisa Octocat","email":null}`))
+	})
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(emails))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newService(server *httptest.Server, users user.Service) auth.Service {
+	config := oauth.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURI:  "https://app.example.com/oauth/github/callback",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		APIURL:       server.URL,
+	}
+	tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	return github.NewService(config, users, tokenManager)
+}
+
+func TestAuthenticate_GivenNewUser_WhenExchangingCode_ThenCreatesUserFromPrimaryEmail(t *testing.T) {
+	// Arrange
+	server := newGitHubServer(t, `[
+		{"email":"octo@users.noreply.github.com","primary":false,"verified":true},
+		{"email":"mona@example.com","primary":true,"verified":true}
+	]`)
+	users := &userMock.MockUserService{}
+	created := testkit.NewUserBuilder().WithEmail("mona@example.com").Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	users.On("Register", mock.Anything, mock.MatchedBy(func(data user.RegisterData) bool {
+		return data.Email == "mona@example.com" && data.FirstName == "Mona" && data.LastName == "Lisa Octocat"
+	})).Return(created, nil)
+	service := newService(server, users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "github", Code: "valid-code"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), result.User.ID)
+	assert.Equal(t, "oauth", result.Strategy)
+	users.AssertExpectations(t)
+}
+
+func TestAuthenticate_GivenUnverifiedPrimaryEmail_WhenExchangingCode_ThenReturnsEmailNotVerified(t *testing.T) {
+	// Arrange
+	server := newGitHubServer(t, `[{"email":"mona@example.com","primary":true,"verified":false}]`)
+	users := &userMock.MockUserService{}
+	service := newService(server, users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "github", Code: "valid-code"})
+
+	// Assert
+	assert.Equal(t, auth.ErrOAuthEmailNotVerified, err)
+	assert.Nil(t, result)
+	users.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_GivenOtherStrategy_WhenAuthenticating_ThenReturnsUnsupportedStrategy(t *testing.T) {
+	service := newService(newGitHubServer(t, `[]`), &userMock.MockUserService{})
+
+	result, err := service.Authenticate(context.Background(), "basic", auth.BasicCredentials{})
+
+	assert.Equal(t, auth.ErrUnsupportedStrategy, err)
+	assert.Nil(t, result)
+}
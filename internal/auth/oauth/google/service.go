@@ -0,0 +1,128 @@
+package google
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// ProviderName is the provider name OAuth credentials select Google with
+const ProviderName = "google"
+
+// DefaultAPIURL serves Google's OpenID Connect userinfo endpoint
+const DefaultAPIURL = "https://openidconnect.googleapis.com"
+
+// DefaultScopes are requested when the config names none
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// service implements auth.Service for signing in with Google
+// It exchanges the authorization code for a Google access token, reads the
+// user from the userinfo endpoint and signs them in, creating the user on
+// their first sign-in.
+type service struct {
+	config       oauth.Config
+	oauth2       *oauth2.Config
+	userService  user.Service
+	tokenManager *usecase.JWTTokenManager
+}
+
+// NewService creates a new Google OAuth provider
+func NewService(config oauth.Config, userService user.Service, tokenManager *usecase.JWTTokenManager) auth.Service {
+	return &service{
+		config:       config,
+		oauth2:       config.OAuth2Config(endpoints.Google, DefaultScopes),
+		userService:  userService,
+		tokenManager: tokenManager,
+	}
+}
+
+// AuthCodeURL returns the Google consent page URL to redirect users to; state
+// must be checked when Google redirects back with the code
+func AuthCodeURL(config oauth.Config, state string) string {
+	return config.OAuth2Config(endpoints.Google, DefaultScopes).AuthCodeURL(state)
+}
+
+// Authenticate handles only the "oauth" strategy
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != oauth.Strategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	ctx = s.config.Context(ctx)
+	token, err := oauth.Token(ctx, s.oauth2, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.fetchUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth.SignIn(ctx, s.userService, s.tokenManager, info)
+}
+
+// ValidateToken delegates to token manager
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	return s.tokenManager.ValidateToken(token)
+}
+
+// RefreshToken delegates to token manager
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return oauth.Refresh(s.tokenManager, refreshToken)
+}
+
+// RevokeToken delegates to token manager
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns oauth strategy
+func (s *service) GetSupportedStrategies() []string {
+	return []string{oauth.Strategy}
+}
+
+// Helper methods
+
+// userInfo is the userinfo response of Google's OpenID Connect API
+type userInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+// fetchUserInfo reads the signed-in Google user
+func (s *service) fetchUserInfo(ctx context.Context, token *oauth2.Token) (*auth.OAuthUserInfo, error) {
+	var info userInfo
+	client := s.oauth2.Client(ctx, token)
+	if err := oauth.GetJSON(ctx, client, s.config.APIBaseURL(DefaultAPIURL)+"/v1/userinfo", &info); err != nil {
+		return nil, err
+	}
+
+	return &auth.OAuthUserInfo{
+		ID:        info.Subject,
+		Email:     info.Email,
+		FirstName: info.GivenName,
+		LastName:  info.FamilyName,
+		Verified:  info.EmailVerified,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package google_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/google"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+// newGoogleServer fakes Google's token and userinfo endpoints for the code "valid-code"
+func newGoogleServer(t *testing.T, verified bool) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("code") != "valid-code" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"google-access-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v1/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer google-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":            "google-123",
+			"email":          "jane@example.com",
+			"email_verified": verified,
+			"given_name":     "Jane",
+			"family_name":    "Doe",
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newService(server *httptest.Server, users user.Service) auth.Service {
+	config := oauth.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURI:  "https://app.example.com/oauth/google/callback",
+		TokenURL:     server.URL + "/token",
+		APIURL:       server.URL,
+	}
+	tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	return google.NewService(config, users, tokenManager)
+}
+
+func TestAuthenticate_GivenNewUser_WhenExchangingCode_ThenCreatesUserAndReturnsTokens(t *testing.T) {
+	// Arrange
+	server := newGoogleServer(t, true)
+	users := &userMock.MockUserService{}
+	created := testkit.NewUserBuilder().WithEmail("jane@example.com").Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	users.On("Register", mock.Anything, mock.MatchedBy(func(data user.RegisterData) bool {
+		return data.Email == "jane@example.com" && data.FirstName == "Jane" && data.LastName == "Doe" && data.Password != ""
+	})).Return(created, nil)
+	service := newService(server, users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "google", Code: "valid-code"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), result.User.ID)
+	assert.Equal(t, "oauth", result.Strategy)
+	assert.NotEmpty(t, result.Token)
+	assert.NotEmpty(t, result.RefreshToken)
+	users.AssertExpectations(t)
+}
+
+func TestAuthenticate_GivenExistingUser_WhenExchangingCode_ThenSignsInWithoutCreating(t *testing.T) {
+	// Arrange
+	server := newGoogleServer(t, true)
+	users := &userMock.MockUserService{}
+	existing := testkit.NewUserBuilder().WithEmail("Jane@Example.com").Build()
+	users.On("List", mock.Anything, user.ListFilters{EmailPrefix: "jane@example.com", Limit: user.MaxListLimit}).
+		Return(&user.Page{Users: []*user.User{existing}}, nil)
+	service := newService(server, users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "google", Code: "valid-code"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID.String(), result.User.ID)
+	users.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_GivenUnverifiedEmail_WhenExchangingCode_ThenReturnsEmailNotVerified(t *testing.T) {
+	// Arrange
+	server := newGoogleServer(t, false)
+	users := &userMock.MockUserService{}
+	service := newService(server, users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "google", Code: "valid-code"})
+
+	// Assert
+	assert.Equal(t, auth.ErrOAuthEmailNotVerified, err)
+	assert.Nil(t, result)
+	users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_GivenRejectedCode_WhenExchanging_ThenReturnsInvalidCredentials(t *testing.T) {
+	// Arrange
+	server := newGoogleServer(t, true)
+	service := newService(server, &userMock.MockUserService{})
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "google", Code: "stolen-code"})
+
+	// Assert
+	assert.Equal(t, auth.ErrInvalidCredentials, err)
+	assert.Nil(t, result)
+}
+
+func TestAuthCodeURL_GivenConfig_WhenBuilding_ThenIncludesClientRedirectAndState(t *testing.T) {
+	config := oauth.Config{ClientID: "client-id", RedirectURI: "https://app.example.com/oauth/google/callback"}
+
+	authURL := google.AuthCodeURL(config, "state-123")
+
+	assert.Contains(t, authURL, "https://accounts.google.com/")
+	assert.Contains(t, authURL, "client_id=client-id")
+	assert.Contains(t, authURL, "state=state-123")
+	assert.Contains(t, authURL, "redirect_uri=https%3A%2F%2Fapp.example.com%2Foauth%2Fgoogle%2Fcallback")
+}
@@ -0,0 +1,271 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Strategy is the auth strategy OAuth providers serve
+const Strategy = "oauth"
+
+// DefaultHTTPTimeout bounds calls to the provider when no HTTP client is configured
+const DefaultHTTPTimeout = 10 * time.Second
+
+// Config is the OAuth client registered with a provider
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string   // Must match the redirect URI registered with the provider
+	Scopes       []string // Empty requests the provider's default scopes
+
+	// Endpoint overrides for self-hosted providers such as GitHub Enterprise;
+	// empty fields use the provider's public URLs
+	AuthURL  string
+	TokenURL string
+	APIURL   string // Base URL of the provider's user API
+
+	// HTTPClient makes the calls to the provider; nil uses a client with DefaultHTTPTimeout
+	HTTPClient *http.Client
+}
+
+// IsConfigured reports whether a client is registered with the provider
+func (c Config) IsConfigured() bool {
+	return c.ClientID != ""
+}
+
+// OAuth2Config returns the oauth2 configuration of the client, falling back
+// to endpoint and defaultScopes where the config leaves them empty
+func (c Config) OAuth2Config(endpoint oauth2.Endpoint, defaultScopes []string) *oauth2.Config {
+	if c.AuthURL != "" {
+		endpoint.AuthURL = c.AuthURL
+	}
+	if c.TokenURL != "" {
+		endpoint.TokenURL = c.TokenURL
+	}
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURI,
+		Endpoint:     endpoint,
+		Scopes:       scopes,
+	}
+}
+
+// APIBaseURL returns the configured API URL or fallback, without a trailing slash
+func (c Config) APIBaseURL(fallback string) string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+	return fallback
+}
+
+// Context returns ctx carrying the HTTP client oauth2 makes its calls with
+func (c Config) Context(ctx context.Context) context.Context {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, client)
+}
+
+// Token returns the provider token for the credentials, exchanging the
+// authorization code when one is given. Codes and tokens the provider
+// rejects fail with auth.ErrInvalidCredentials.
+func Token(ctx context.Context, config *oauth2.Config, credentials interface{}) (*oauth2.Token, error) {
+	creds, ok := credentials.(auth.OAuthCredentials)
+	if !ok {
+		return nil, fmt.Errorf("invalid credentials type for OAuth")
+	}
+
+	if creds.Code == "" {
+		if creds.AccessToken == "" {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return &oauth2.Token{AccessToken: creds.AccessToken, RefreshToken: creds.RefreshToken, TokenType: "Bearer"}, nil
+	}
+
+	token, err := config.Exchange(ctx, creds.Code)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	return token, nil
+}
+
+// GetJSON fetches url with the client and decodes the JSON response into
+// out. A token the provider refuses fails with auth.ErrInvalidCredentials.
+func GetJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create provider request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return auth.ErrInvalidCredentials
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("provider returned status %d for %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode provider response: %w", err)
+	}
+	return nil
+}
+
+// SignIn returns the auth result for the provider's user, creating the user
+// on their first sign-in. Only emails the provider verified are trusted, as
+// an unverified one would let anyone sign in to the account holding it.
+func SignIn(ctx context.Context, userService user.Service, tokenManager *usecase.JWTTokenManager, info *auth.OAuthUserInfo) (*auth.AuthResult, error) {
+	if info.Email == "" || !info.Verified {
+		return nil, auth.ErrOAuthEmailNotVerified
+	}
+
+	found, err := findUserByEmail(ctx, userService, info.Email)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		found, err = CreateUser(ctx, userService, auth.CreateUserData{
+			Email:     info.Email,
+			FirstName: info.FirstName,
+			LastName:  info.LastName,
+		})
+	}
+	// Another sign-in may have created the user in between
+	if errors.Is(err, user.ErrEmailAlreadyExists) {
+		found, err = findUserByEmail(ctx, userService, info.Email)
+		if err == nil && found == nil {
+			err = user.ErrUserNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := tokenManager.GenerateAuthToken(found.ID.String(), found.Email, token.AMRFederated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	refreshToken, err := tokenManager.GenerateRefreshToken(found.ID.String(), token.AMRFederated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &auth.AuthResult{
+		User: &auth.User{
+			ID:        found.ID.String(),
+			Email:     found.Email,
+			FirstName: found.FirstName,
+			LastName:  found.LastName,
+			CreatedAt: found.CreatedAt,
+			UpdatedAt: found.UpdatedAt,
+		},
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		Strategy:     Strategy,
+	}, nil
+}
+
+// CreateUser registers a user signing in through a provider. They never get
+// a password, so a random one that nobody knows is set. Names the provider
+// does not share default to the email's local part and "User", so the user
+// passes validation and can correct them later. The provider has already
+// told them apart from bots and verified their email, so no captcha is asked
+// for and no verification email is sent.
+func CreateUser(ctx context.Context, userService user.Service, data auth.CreateUserData) (*user.User, error) {
+	if data.Password == "" {
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		data.Password = password
+	}
+	if len(strings.TrimSpace(data.FirstName)) < 2 {
+		data.FirstName, _, _ = strings.Cut(data.Email, "@")
+	}
+	if len(strings.TrimSpace(data.LastName)) < 2 {
+		data.LastName = "User"
+	}
+
+	return userService.Register(user.WithCaptchaExemption(ctx), user.RegisterData{
+		Email:     data.Email,
+		Password:  data.Password,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+
+		EmailVerified: true,
+	})
+}
+
+// Refresh exchanges a refresh token of the token manager for an access token
+// and a new refresh token
+func Refresh(tokenManager *usecase.JWTTokenManager, refreshToken string) (*auth.AuthResult, error) {
+	result, err := tokenManager.RotateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = Strategy
+	return result, nil
+}
+
+// SplitName splits a display name into first and last name at the first space
+func SplitName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
+
+// Helper functions
+
+// findUserByEmail returns the user with the email, or nil if there is none
+func findUserByEmail(ctx context.Context, userService user.Service, email string) (*user.User, error) {
+	page, err := userService.List(ctx, user.ListFilters{EmailPrefix: email, Limit: user.MaxListLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	for _, u := range page.Users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+// randomPassword returns a password meeting the strength rules that nobody knows
+func randomPassword() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(random) + "Aa1!", nil
+}
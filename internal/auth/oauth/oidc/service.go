@@ -0,0 +1,301 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// DefaultScopes are requested when the config names none
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// DefaultKeyRefreshInterval is the least time between two JWKS fetches
+// triggered by ID tokens signed with an unknown key
+const DefaultKeyRefreshInterval = time.Minute
+
+// clockSkew is tolerated when checking the times in ID tokens
+const clockSkew = 30 * time.Second
+
+// signingMethods are the ID token algorithms accepted; HMAC is refused, as its
+// key would be the client secret rather than one the provider published
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Config is the client registered with an OpenID Connect provider
+type Config struct {
+	oauth.Config
+
+	// IssuerURL identifies the provider, e.g. https://tenant.auth0.com/ or
+	// https://keycloak.example.com/realms/main; its
+	// /.well-known/openid-configuration describes the endpoints and keys
+	IssuerURL string
+
+	// KeyRefreshInterval limits how often the JWKS is fetched again for
+	// unknown keys; zero uses DefaultKeyRefreshInterval
+	KeyRefreshInterval time.Duration
+}
+
+// service implements auth.Service for any OpenID Connect provider
+// It discovers the provider's endpoints from its issuer, exchanges the
+// authorization code, verifies the ID token against the provider's JWKS and
+// signs the user in from its claims, creating the user on their first
+// sign-in. Discovery and keys are fetched on first use and cached; keys are
+// fetched again when a token names one that is not known yet.
+type service struct {
+	config       Config
+	userService  user.Service
+	tokenManager *usecase.JWTTokenManager
+
+	mu            sync.Mutex
+	provider      *discoveryDocument
+	oauth2        *oauth2.Config
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// NewService creates a new OpenID Connect provider
+func NewService(config Config, userService user.Service, tokenManager *usecase.JWTTokenManager) auth.Service {
+	if config.KeyRefreshInterval <= 0 {
+		config.KeyRefreshInterval = DefaultKeyRefreshInterval
+	}
+
+	return &service{
+		config:       config,
+		userService:  userService,
+		tokenManager: tokenManager,
+	}
+}
+
+// AuthCodeURL discovers the provider and returns its authorization URL to
+// redirect users to; state must be checked when the provider redirects back
+func AuthCodeURL(ctx context.Context, config Config, state string) (string, error) {
+	provider, err := discover(config.Context(ctx), config)
+	if err != nil {
+		return "", err
+	}
+	return oauth2Config(config, provider).AuthCodeURL(state), nil
+}
+
+// Authenticate handles only the "oauth" strategy. Credentials carrying an ID
+// token are verified as is; otherwise the code is exchanged for one.
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != oauth.Strategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	ctx = s.config.Context(ctx)
+	oauth2Config, err := s.discovered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.idToken(ctx, oauth2Config, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.verify(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth.SignIn(ctx, s.userService, s.tokenManager, info)
+}
+
+// ValidateToken delegates to token manager
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	return s.tokenManager.ValidateToken(token)
+}
+
+// RefreshToken delegates to token manager
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return oauth.Refresh(s.tokenManager, refreshToken)
+}
+
+// RevokeToken delegates to token manager
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns oauth strategy
+func (s *service) GetSupportedStrategies() []string {
+	return []string{oauth.Strategy}
+}
+
+// Helper methods
+
+// discovered returns the oauth2 configuration of the provider, discovering
+// it on first use
+func (s *service) discovered(ctx context.Context) (*oauth2.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := discover(ctx, s.config)
+		if err != nil {
+			return nil, err
+		}
+		s.provider = provider
+		s.oauth2 = oauth2Config(s.config, provider)
+	}
+	return s.oauth2, nil
+}
+
+// idToken returns the ID token of the credentials, exchanging their code
+// for one if needed
+func (s *service) idToken(ctx context.Context, oauth2Config *oauth2.Config, credentials interface{}) (string, error) {
+	if creds, ok := credentials.(auth.OAuthCredentials); ok && creds.IDToken != "" {
+		return creds.IDToken, nil
+	}
+
+	token, err := oauth.Token(ctx, oauth2Config, credentials)
+	if err != nil {
+		return "", err
+	}
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return "", auth.ErrInvalidCredentials
+	}
+	return idToken, nil
+}
+
+// verify checks the ID token's signature, issuer, audience and lifetime and
+// maps its claims to the user info
+func (s *service) verify(ctx context.Context, idToken string) (*auth.OAuthUserInfo, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return s.key(ctx, kid)
+		},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(s.provider.Issuer),
+		jwt.WithAudience(s.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		var fetchErr *keyFetchError
+		if errors.As(err, &fetchErr) {
+			return nil, fetchErr.err
+		}
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	return userInfoFromClaims(claims), nil
+}
+
+// key returns the provider's key with the ID, fetching the JWKS again when
+// the key is unknown and the last fetch is old enough
+func (s *service) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key := lookupKey(s.keys, kid); key != nil {
+		return key, nil
+	}
+	if s.keys != nil && time.Since(s.keysFetchedAt) < s.config.KeyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := fetchKeys(ctx, s.provider.JWKSURI)
+	if err != nil {
+		return nil, &keyFetchError{err: err}
+	}
+	s.keys = keys
+	s.keysFetchedAt = time.Now()
+
+	if key := lookupKey(s.keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// discoveryDocument is the part of the provider's OpenID configuration used
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// discover reads the provider's OpenID configuration. The issuer it names
+// must be the configured one, or tokens from another issuer could pass.
+func discover(ctx context.Context, config Config) (*discoveryDocument, error) {
+	issuer := strings.TrimSuffix(config.IssuerURL, "/")
+
+	var provider discoveryDocument
+	if err := oauth.GetJSON(ctx, httpClient(ctx), issuer+"/.well-known/openid-configuration", &provider); err != nil {
+		return nil, fmt.Errorf("failed to discover OpenID provider: %w", err)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OpenID provider issuer %q does not match %q", provider.Issuer, config.IssuerURL)
+	}
+	if provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, fmt.Errorf("OpenID provider %q lacks a token endpoint or JWKS", provider.Issuer)
+	}
+	return &provider, nil
+}
+
+// oauth2Config returns the oauth2 configuration for the discovered provider;
+// endpoints set in the config take precedence
+func oauth2Config(config Config, provider *discoveryDocument) *oauth2.Config {
+	return config.OAuth2Config(oauth2.Endpoint{
+		AuthURL:  provider.AuthorizationEndpoint,
+		TokenURL: provider.TokenEndpoint,
+	}, DefaultScopes)
+}
+
+// userInfoFromClaims maps the standard OpenID Connect claims to the user info
+func userInfoFromClaims(claims jwt.MapClaims) *auth.OAuthUserInfo {
+	info := &auth.OAuthUserInfo{}
+	info.ID, _ = claims["sub"].(string)
+	info.Email, _ = claims["email"].(string)
+	info.FirstName, _ = claims["given_name"].(string)
+	info.LastName, _ = claims["family_name"].(string)
+
+	// Some providers send email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		info.Verified = verified
+	case string:
+		info.Verified = verified == "true"
+	}
+
+	if info.FirstName == "" && info.LastName == "" {
+		name, _ := claims["name"].(string)
+		info.FirstName, info.LastName = oauth.SplitName(name)
+	}
+	return info
+}
+
+// keyFetchError marks a failure to fetch the JWKS, which is the provider's
+// fault rather than the token's
+type keyFetchError struct {
+	err error
+}
+
+func (e *keyFetchError) Error() string {
+	return e.err.Error()
+}
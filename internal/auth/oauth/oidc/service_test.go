@@ -0,0 +1,267 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth/oidc"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+// fakeProvider is an OpenID Connect provider publishing its RSA key as "key-1"
+// and answering the code "valid-code" with idToken
+type fakeProvider struct {
+	server      *httptest.Server
+	key         *rsa.PrivateKey
+	issuer      string
+	idToken     string
+	jwksFetches atomic.Int32
+	extraKeys   []map[string]string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	discovery := func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.issuer,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	}
+	mux.HandleFunc("GET /realms/main/.well-known/openid-configuration", discovery)
+	// Answers for another issuer URL with the realm's issuer
+	mux.HandleFunc("GET /.well-known/openid-configuration", discovery)
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksFetches.Add(1)
+		keys := []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": append(keys, p.extraKeys...)})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("code") != "valid-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"id_token":     p.idToken,
+		})
+	})
+
+	p.server = httptest.NewServer(mux)
+	p.issuer = p.server.URL + "/realms/main"
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// config returns the client config for the provider
+func (p *fakeProvider) config() oidc.Config {
+	return oidc.Config{
+		Config: oauth.Config{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURI:  "https://app.example.com/oauth/keycloak/callback",
+		},
+		IssuerURL: p.issuer,
+	}
+}
+
+// sign returns an ID token with the claims, signed with the provider's key
+func (p *fakeProvider) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+	return signed
+}
+
+// validClaims returns the claims of a fresh ID token for jane@example.com
+func (p *fakeProvider) validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            p.issuer,
+		"aud":            "client-id",
+		"sub":            "kc-123",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	}
+}
+
+func newService(config oidc.Config, users user.Service) auth.Service {
+	tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	return oidc.NewService(config, users, tokenManager)
+}
+
+func TestAuthenticate_GivenValidCode_WhenExchanging_ThenVerifiesIDTokenAndCreatesUser(t *testing.T) {
+	// Arrange
+	provider := newFakeProvider(t)
+	provider.idToken = provider.sign(t, "key-1", provider.validClaims())
+	users := &userMock.MockUserService{}
+	created := testkit.NewUserBuilder().WithEmail("jane@example.com").Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	users.On("Register", mock.Anything, mock.MatchedBy(func(data user.RegisterData) bool {
+		return data.Email == "jane@example.com" && data.FirstName == "Jane" && data.LastName == "Doe"
+	})).Return(created, nil)
+	service := newService(provider.config(), users)
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{Provider: "keycloak", Code: "valid-code"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), result.User.ID)
+	assert.Equal(t, "oauth", result.Strategy)
+	users.AssertExpectations(t)
+}
+
+func TestAuthenticate_GivenInvalidIDToken_WhenVerifying_ThenReturnsInvalidCredentials(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		idToken func(p *fakeProvider) string
+	}{
+		{
+			name: "Given another audience, When verifying, Then returns invalid credentials",
+			idToken: func(p *fakeProvider) string {
+				claims := p.validClaims()
+				claims["aud"] = "other-client"
+				return p.sign(t, "key-1", claims)
+			},
+		},
+		{
+			name: "Given another issuer, When verifying, Then returns invalid credentials",
+			idToken: func(p *fakeProvider) string {
+				claims := p.validClaims()
+				claims["iss"] = "https://evil.example.com"
+				return p.sign(t, "key-1", claims)
+			},
+		},
+		{
+			name: "Given an expired token, When verifying, Then returns invalid credentials",
+			idToken: func(p *fakeProvider) string {
+				claims := p.validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return p.sign(t, "key-1", claims)
+			},
+		},
+		{
+			name: "Given a token signed with another key, When verifying, Then returns invalid credentials",
+			idToken: func(p *fakeProvider) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.validClaims())
+				token.Header["kid"] = "key-1"
+				signed, err := token.SignedString(otherKey)
+				require.NoError(t, err)
+				return signed
+			},
+		},
+		{
+			name: "Given an HMAC token signed with the client secret, When verifying, Then returns invalid credentials",
+			idToken: func(p *fakeProvider) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, p.validClaims()).SignedString([]byte("client-secret"))
+				require.NoError(t, err)
+				return signed
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider(t)
+			users := &userMock.MockUserService{}
+			service := newService(provider.config(), users)
+
+			result, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{IDToken: tt.idToken(provider)})
+
+			assert.Equal(t, auth.ErrInvalidCredentials, err)
+			assert.Nil(t, result)
+			users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_GivenRotatedKey_WhenVerifying_ThenFetchesKeysAgain(t *testing.T) {
+	// Arrange
+	provider := newFakeProvider(t)
+	users := &userMock.MockUserService{}
+	users.On("List", mock.Anything, mock.Anything).
+		Return(&user.Page{Users: []*user.User{testkit.NewUserBuilder().WithEmail("jane@example.com").Build()}}, nil)
+	config := provider.config()
+	config.KeyRefreshInterval = time.Nanosecond
+	service := newService(config, users)
+
+	_, err := service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{IDToken: provider.sign(t, "key-1", provider.validClaims())})
+	require.NoError(t, err)
+
+	// The provider rotates to an EC key
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	provider.extraKeys = []map[string]string{{
+		"kty": "EC",
+		"kid": "key-2",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+	}}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, provider.validClaims())
+	token.Header["kid"] = "key-2"
+	rotated, err := token.SignedString(ecKey)
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.Authenticate(context.Background(), "oauth", auth.OAuthCredentials{IDToken: rotated})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), provider.jwksFetches.Load())
+}
+
+func TestAuthCodeURL_GivenIssuerMismatch_WhenDiscovering_ThenReturnsError(t *testing.T) {
+	provider := newFakeProvider(t)
+	config := provider.config()
+	config.IssuerURL = provider.server.URL
+
+	_, err := oidc.AuthCodeURL(context.Background(), config, "state-123")
+
+	assert.ErrorContains(t, err, "does not match")
+}
@@ -0,0 +1,370 @@
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+)
+
+// SAML 2.0 namespaces and values
+const (
+	protocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bearerMethod       = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// weakAlgorithms are the SHA-1 signature and digest methods, which are refused
+var weakAlgorithms = map[string]bool{
+	dsig.RSASHA1SignatureMethod:              true,
+	"http://www.w3.org/2000/09/xmldsig#sha1": true,
+}
+
+// clockSkew is tolerated when checking the validity window of assertions
+const clockSkew = 90 * time.Second
+
+// Attribute names tried when the AttributeMap names none
+var (
+	defaultEmailAttributes = []string{
+		"email", "mail", "emailAddress", "urn:oid:0.9.2342.19200300.100.1.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+	}
+	defaultFirstNameAttributes = []string{
+		"givenName", "firstName", "urn:oid:2.5.4.42",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname",
+	}
+	defaultLastNameAttributes = []string{
+		"sn", "surname", "lastName", "urn:oid:2.5.4.4",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname",
+	}
+)
+
+// validatedAssertion is the part of a validated assertion used to sign in
+type validatedAssertion struct {
+	id        string
+	expiresAt time.Time
+	userInfo  *auth.OAuthUserInfo
+}
+
+// validateResponse checks a SAML response answering requestID and returns
+// its assertion. Either the response or the assertion must carry a valid
+// signature; everything read afterwards lies below the signed element.
+func (s *service) validateResponse(raw []byte, requestID string, now time.Time) (*validatedAssertion, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, invalidResponse(err.Error())
+	}
+	// Entity declarations could expand or alter the signed content
+	for _, token := range doc.Child {
+		if _, ok := token.(*etree.Directive); ok {
+			return nil, invalidResponse("document type declarations are not allowed")
+		}
+	}
+	response := doc.Root()
+	if response == nil || !isElement(response, protocolNamespace, "Response") {
+		return nil, invalidResponse("document is not a SAML response")
+	}
+
+	response, assertion, err := s.verifySignatures(response)
+	if err != nil {
+		return nil, err
+	}
+
+	status := child(response, protocolNamespace, "Status")
+	if status == nil || child(status, protocolNamespace, "StatusCode") == nil ||
+		child(status, protocolNamespace, "StatusCode").SelectAttrValue("Value", "") != statusSuccess {
+		return nil, invalidResponse("identity provider did not authenticate the user")
+	}
+	if destination := response.SelectAttrValue("Destination", ""); destination != "" && destination != s.config.ACSURL {
+		return nil, invalidResponse("response is addressed to " + destination)
+	}
+	if err := s.checkInResponseTo(response.SelectAttrValue("InResponseTo", ""), requestID); err != nil {
+		return nil, err
+	}
+
+	return s.validateAssertion(assertion, requestID, now)
+}
+
+// verifySignatures returns the response and its single assertion, taking
+// each from the signed copy when it is signed. An unsigned response needs a
+// signed assertion.
+func (s *service) verifySignatures(response *etree.Element) (*etree.Element, *etree.Element, error) {
+	if len(childElements(response, assertionNamespace, "EncryptedAssertion")) > 0 {
+		return nil, nil, invalidResponse("encrypted assertions are not supported")
+	}
+	assertions := childElements(response, assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, nil, invalidResponse("response must hold exactly one assertion")
+	}
+
+	signed, err := verifySignature(response, s.certificates)
+	switch {
+	case err == nil:
+		assertions = childElements(signed, assertionNamespace, "Assertion")
+		if len(assertions) != 1 {
+			return nil, nil, invalidResponse("response must hold exactly one assertion")
+		}
+		return signed, assertions[0], nil
+	case !errors.Is(err, dsig.ErrMissingSignature):
+		return nil, nil, invalidResponse(err.Error())
+	}
+
+	assertion, err := verifySignature(assertions[0], s.certificates)
+	if err != nil {
+		return nil, nil, invalidResponse(err.Error())
+	}
+	return response, assertion, nil
+}
+
+// validateAssertion checks the issuer, subject confirmation and conditions
+// of a signed assertion and maps its attributes to the user info
+func (s *service) validateAssertion(assertion *etree.Element, requestID string, now time.Time) (*validatedAssertion, error) {
+	id := assertion.SelectAttrValue("ID", "")
+	if id == "" {
+		return nil, invalidResponse("assertion lacks an ID")
+	}
+	if issuer := child(assertion, assertionNamespace, "Issuer"); issuer == nil || strings.TrimSpace(issuer.Text()) != s.config.IdPEntityID {
+		return nil, invalidResponse("assertion is not issued by the identity provider")
+	}
+
+	subject := child(assertion, assertionNamespace, "Subject")
+	if subject == nil {
+		return nil, invalidResponse("assertion lacks a subject")
+	}
+	expiresAt, err := s.checkSubjectConfirmation(subject, requestID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := child(assertion, assertionNamespace, "Conditions")
+	if conditions == nil {
+		return nil, invalidResponse("assertion lacks conditions")
+	}
+	notOnOrAfter, err := s.checkConditions(conditions, now)
+	if err != nil {
+		return nil, err
+	}
+	if !notOnOrAfter.IsZero() && notOnOrAfter.Before(expiresAt) {
+		expiresAt = notOnOrAfter
+	}
+
+	return &validatedAssertion{
+		id:        id,
+		expiresAt: expiresAt.Add(clockSkew),
+		userInfo:  s.userInfo(assertion, subject),
+	}, nil
+}
+
+// checkInResponseTo matches the request a response answers with the one
+// the caller sent. Unsolicited responses need AllowIdPInitiated.
+func (s *service) checkInResponseTo(inResponseTo, requestID string) error {
+	switch {
+	case inResponseTo == "" && requestID == "" && !s.config.AllowIdPInitiated:
+		return invalidResponse("unsolicited responses are not allowed")
+	case inResponseTo != requestID:
+		return invalidResponse("response does not answer the pending request")
+	}
+	return nil
+}
+
+// checkSubjectConfirmation requires a bearer confirmation for the ACS URL
+// that has not expired and returns its expiry
+func (s *service) checkSubjectConfirmation(subject *etree.Element, requestID string, now time.Time) (time.Time, error) {
+	for _, confirmation := range childElements(subject, assertionNamespace, "SubjectConfirmation") {
+		data := child(confirmation, assertionNamespace, "SubjectConfirmationData")
+		if confirmation.SelectAttrValue("Method", "") != bearerMethod || data == nil {
+			continue
+		}
+		if data.SelectAttrValue("Recipient", "") != s.config.ACSURL {
+			continue
+		}
+		if inResponseTo := data.SelectAttrValue("InResponseTo", ""); inResponseTo != "" && inResponseTo != requestID {
+			continue
+		}
+		notOnOrAfter, err := parseTime(data.SelectAttrValue("NotOnOrAfter", ""))
+		if err != nil || notOnOrAfter.IsZero() || !now.Before(notOnOrAfter.Add(clockSkew)) {
+			continue
+		}
+		return notOnOrAfter, nil
+	}
+	return time.Time{}, invalidResponse("assertion has no valid bearer confirmation for this service provider")
+}
+
+// checkConditions checks the validity window and that this service provider
+// is in every audience restriction; it returns the end of the window
+func (s *service) checkConditions(conditions *etree.Element, now time.Time) (time.Time, error) {
+	notBefore, err := parseTime(conditions.SelectAttrValue("NotBefore", ""))
+	if err != nil {
+		return time.Time{}, invalidResponse("malformed NotBefore")
+	}
+	notOnOrAfter, err := parseTime(conditions.SelectAttrValue("NotOnOrAfter", ""))
+	if err != nil {
+		return time.Time{}, invalidResponse("malformed NotOnOrAfter")
+	}
+	if !notBefore.IsZero() && now.Add(clockSkew).Before(notBefore) {
+		return time.Time{}, invalidResponse("assertion is not valid yet")
+	}
+	if !notOnOrAfter.IsZero() && !now.Before(notOnOrAfter.Add(clockSkew)) {
+		return time.Time{}, invalidResponse("assertion has expired")
+	}
+
+	restrictions := childElements(conditions, assertionNamespace, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return time.Time{}, invalidResponse("assertion is not restricted to an audience")
+	}
+	for _, restriction := range restrictions {
+		var found bool
+		for _, audience := range childElements(restriction, assertionNamespace, "Audience") {
+			if strings.TrimSpace(audience.Text()) == s.config.EntityID {
+				found = true
+			}
+		}
+		if !found {
+			return time.Time{}, invalidResponse("assertion is not issued for this service provider")
+		}
+	}
+	return notOnOrAfter, nil
+}
+
+// userInfo maps the assertion's attributes to the user info. The identity
+// provider is the authority for its users, so the email counts as verified.
+// Without an email attribute an email-formatted name ID is used.
+func (s *service) userInfo(assertion, subject *etree.Element) *auth.OAuthUserInfo {
+	attributes := map[string]string{}
+	if statement := child(assertion, assertionNamespace, "AttributeStatement"); statement != nil {
+		for _, attribute := range childElements(statement, assertionNamespace, "Attribute") {
+			value := child(attribute, assertionNamespace, "AttributeValue")
+			if value == nil {
+				continue
+			}
+			for _, name := range []string{attribute.SelectAttrValue("Name", ""), attribute.SelectAttrValue("FriendlyName", "")} {
+				if _, ok := attributes[name]; name != "" && !ok {
+					attributes[name] = strings.TrimSpace(value.Text())
+				}
+			}
+		}
+	}
+
+	info := &auth.OAuthUserInfo{
+		Email:     lookupAttribute(attributes, s.config.Attributes.Email, defaultEmailAttributes),
+		FirstName: lookupAttribute(attributes, s.config.Attributes.FirstName, defaultFirstNameAttributes),
+		LastName:  lookupAttribute(attributes, s.config.Attributes.LastName, defaultLastNameAttributes),
+	}
+	if nameID := child(subject, assertionNamespace, "NameID"); nameID != nil {
+		info.ID = strings.TrimSpace(nameID.Text())
+		if info.Email == "" && nameID.SelectAttrValue("Format", "") == EmailNameIDFormat {
+			info.Email = info.ID
+		}
+	}
+	if info.FirstName == "" && info.LastName == "" {
+		info.FirstName, info.LastName = oauth.SplitName(lookupAttribute(attributes, "", []string{"displayName", "name", "cn"}))
+	}
+	info.Verified = info.Email != ""
+	return info
+}
+
+// lookupAttribute returns the configured attribute, or the first of the
+// default names present
+func lookupAttribute(attributes map[string]string, configured string, defaults []string) string {
+	if configured != "" {
+		return attributes[configured]
+	}
+	for _, name := range defaults {
+		if value, ok := attributes[name]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// verifySignature checks the enveloped signature of the element against the
+// certificates and returns the signed copy of the element, without the
+// signature. The signature must reference the element itself, so a signed
+// element cannot be swapped for an unsigned one elsewhere in the document.
+func verifySignature(e *etree.Element, certificates []*x509.Certificate) (*etree.Element, error) {
+	for _, path := range []string{".//SignatureMethod", ".//DigestMethod"} {
+		for _, method := range e.FindElements(path) {
+			if weakAlgorithms[method.SelectAttrValue("Algorithm", "")] {
+				return nil, fmt.Errorf("unsupported algorithm %q", method.SelectAttrValue("Algorithm", ""))
+			}
+		}
+	}
+
+	// The element is verified on its own, so it takes the namespaces it
+	// inherits along
+	namespaces, err := etreeutils.NSBuildParentContext(e)
+	if err != nil {
+		return nil, err
+	}
+	e, err = etreeutils.NSDetatch(namespaces, e)
+	if err != nil {
+		return nil, err
+	}
+
+	// Signatures name no certificate, so each one is tried in turn
+	for _, certificate := range certificates {
+		validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+			Roots: []*x509.Certificate{certificate},
+		})
+		signed, err := validation.Validate(e)
+		if err == nil {
+			return signed, nil
+		}
+		if errors.Is(err, dsig.ErrMissingSignature) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("signature does not match the identity provider's certificate")
+}
+
+// isElement reports whether the element has the namespace and local name
+func isElement(e *etree.Element, namespace, local string) bool {
+	return e.Tag == local && e.NamespaceURI() == namespace
+}
+
+// childElements returns the child elements with the namespace and local name
+func childElements(e *etree.Element, namespace, local string) []*etree.Element {
+	var children []*etree.Element
+	for _, c := range e.ChildElements() {
+		if isElement(c, namespace, local) {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// child returns the first child element with the namespace and local name
+func child(e *etree.Element, namespace, local string) *etree.Element {
+	if children := childElements(e, namespace, local); len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
+// decodeBase64 decodes base64 content that may be wrapped over several lines
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
+
+// parseTime parses an xs:dateTime; empty values yield the zero time
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// invalidResponse wraps auth.ErrInvalidSAMLResponse with the reason
+func invalidResponse(reason string) error {
+	return fmt.Errorf("%w: %s", auth.ErrInvalidSAMLResponse, reason)
+}
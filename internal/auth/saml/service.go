@@ -0,0 +1,348 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/oauth"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Strategy is the auth strategy SAML single sign-on is served under
+const Strategy = "saml"
+
+// SAML bindings and name ID formats
+const (
+	PostBinding       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	RedirectBinding   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	EmailNameIDFormat = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+// Config is this service provider as registered with a SAML 2.0 identity provider
+type Config struct {
+	// EntityID identifies this service provider; it is the audience
+	// assertions must be issued for
+	EntityID string
+
+	// ACSURL is the assertion consumer service URL the identity provider
+	// posts responses to
+	ACSURL string
+
+	// IdPEntityID is the issuer assertions must name
+	IdPEntityID string
+
+	// IdPSSOURL is the identity provider's single sign-on endpoint for the
+	// HTTP-Redirect binding
+	IdPSSOURL string
+
+	// IdPCertificate is the PEM encoded signing certificate of the identity
+	// provider; it may hold several certificates while keys are rotated
+	IdPCertificate string
+
+	// AllowIdPInitiated accepts responses that answer no AuthnRequest, for
+	// sign-ins started from the identity provider's portal
+	AllowIdPInitiated bool
+
+	// Attributes names the assertion attributes holding user details
+	Attributes AttributeMap
+}
+
+// AttributeMap names the assertion attributes, by Name or FriendlyName,
+// holding user details. Empty fields fall back to the common LDAP, OID and
+// WS-Federation claim names.
+type AttributeMap struct {
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// IsConfigured reports whether an identity provider is configured
+func (c Config) IsConfigured() bool {
+	return c.IdPSSOURL != ""
+}
+
+// Certificates parses the identity provider's signing certificates
+func (c Config) Certificates() ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	rest := []byte(c.IdPCertificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SAML identity provider certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("SAML identity provider certificate is required")
+	}
+	return certificates, nil
+}
+
+// service implements auth.Service for SAML 2.0 single sign-on
+// It validates the responses an identity provider posts to the ACS URL:
+// their signature against the provider's certificate, issuer, audience,
+// recipient and validity window. The assertion's attributes sign the user
+// in, creating them on their first sign-in. Assertion IDs are remembered
+// until they expire so a captured response cannot be posted again.
+type service struct {
+	config       Config
+	certificates []*x509.Certificate
+	userService  user.Service
+	tokenManager *usecase.JWTTokenManager
+
+	mu       sync.Mutex
+	consumed map[string]time.Time
+}
+
+// NewService creates a new SAML service provider. The config is validated
+// by the factory; without a valid certificate every response is refused.
+func NewService(config Config, userService user.Service, tokenManager *usecase.JWTTokenManager) auth.Service {
+	certificates, _ := config.Certificates()
+
+	return &service{
+		config:       config,
+		certificates: certificates,
+		userService:  userService,
+		tokenManager: tokenManager,
+		consumed:     make(map[string]time.Time),
+	}
+}
+
+// Authenticate handles only the "saml" strategy
+func (s *service) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != Strategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	samlCreds, ok := credentials.(auth.SAMLCredentials)
+	if !ok {
+		return nil, fmt.Errorf("invalid credentials type for SAML auth")
+	}
+
+	raw, err := decodeBase64(samlCreds.SAMLResponse)
+	if err != nil {
+		return nil, invalidResponse("response is not base64 encoded")
+	}
+
+	now := time.Now()
+	assertion, err := s.validateResponse(raw, samlCreds.RequestID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.consume(assertion.id, assertion.expiresAt, now); err != nil {
+		return nil, err
+	}
+
+	result, err := oauth.SignIn(ctx, s.userService, s.tokenManager, assertion.userInfo)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = Strategy
+	return result, nil
+}
+
+// ValidateToken delegates to token manager
+func (s *service) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	return s.tokenManager.ValidateToken(token)
+}
+
+// RefreshToken delegates to token manager
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	result, err := oauth.Refresh(s.tokenManager, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = Strategy
+	return result, nil
+}
+
+// RevokeToken delegates to token manager
+func (s *service) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *service) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *service) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns saml strategy
+func (s *service) GetSupportedStrategies() []string {
+	return []string{Strategy}
+}
+
+// AuthnRequest is a request to the identity provider to authenticate a user
+type AuthnRequest struct {
+	// ID must be kept, e.g. in a cookie, and passed back as
+	// auth.SAMLCredentials.RequestID with the response
+	ID string
+
+	// RedirectURL sends the user to the identity provider with the request
+	// in the HTTP-Redirect binding
+	RedirectURL string
+}
+
+// NewAuthnRequest creates an AuthnRequest asking for the response to be
+// posted to the ACS URL. relayState is returned with the response.
+func NewAuthnRequest(config Config, relayState string) (*AuthnRequest, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := xml.Marshal(authnRequestXML{
+		ID:                          id,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 config.IdPSSOURL,
+		ProtocolBinding:             PostBinding,
+		AssertionConsumerServiceURL: config.ACSURL,
+		Issuer:                      config.EntityID,
+		NameIDPolicy:                nameIDPolicyXML{Format: EmailNameIDFormat, AllowCreate: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode AuthnRequest: %w", err)
+	}
+
+	// The HTTP-Redirect binding deflates and base64 encodes the request
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(request); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	redirectURL, err := url.Parse(config.IdPSSOURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML identity provider SSO URL: %w", err)
+	}
+	query := redirectURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	redirectURL.RawQuery = query.Encode()
+
+	return &AuthnRequest{ID: id, RedirectURL: redirectURL.String()}, nil
+}
+
+// Metadata returns the service provider metadata to register with the
+// identity provider
+func Metadata(config Config) ([]byte, error) {
+	metadata, err := xml.MarshalIndent(entityDescriptorXML{
+		EntityID: config.EntityID,
+		SPSSODescriptor: spSSODescriptorXML{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: protocolNamespace,
+			NameIDFormat:               EmailNameIDFormat,
+			AssertionConsumerService: []endpointXML{
+				{Binding: PostBinding, Location: config.ACSURL, Index: 0},
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SAML metadata: %w", err)
+	}
+	return append([]byte(xml.Header), metadata...), nil
+}
+
+// Helper methods
+
+// consume records the assertion ID until the assertion expires and refuses
+// IDs already seen
+func (s *service) consume(id string, expiresAt, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for consumedID, expiry := range s.consumed {
+		if now.After(expiry) {
+			delete(s.consumed, consumedID)
+		}
+	}
+	if _, ok := s.consumed[id]; ok {
+		return invalidResponse("assertion was already used")
+	}
+	s.consumed[id] = expiresAt
+	return nil
+}
+
+// newID returns a random SAML ID; IDs must not start with a digit
+func newID() (string, error) {
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate SAML ID: %w", err)
+	}
+	return "_" + hex.EncodeToString(raw), nil
+}
+
+// authnRequestXML is the AuthnRequest sent to the identity provider
+type authnRequestXML struct {
+	XMLName                     xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string          `xml:"ID,attr"`
+	Version                     string          `xml:"Version,attr"`
+	IssueInstant                string          `xml:"IssueInstant,attr"`
+	Destination                 string          `xml:"Destination,attr"`
+	ProtocolBinding             string          `xml:"ProtocolBinding,attr"`
+	AssertionConsumerServiceURL string          `xml:"AssertionConsumerServiceURL,attr"`
+	Issuer                      string          `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameIDPolicy                nameIDPolicyXML `xml:"NameIDPolicy"`
+}
+
+type nameIDPolicyXML struct {
+	Format      string `xml:"Format,attr"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+// entityDescriptorXML is the service provider metadata
+type entityDescriptorXML struct {
+	XMLName         xml.Name           `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string             `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptorXML `xml:"SPSSODescriptor"`
+}
+
+type spSSODescriptorXML struct {
+	AuthnRequestsSigned        bool          `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool          `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string        `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string        `xml:"NameIDFormat"`
+	AssertionConsumerService   []endpointXML `xml:"AssertionConsumerService"`
+}
+
+type endpointXML struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+	Index    int    `xml:"index,attr"`
+}
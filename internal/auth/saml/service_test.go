@@ -0,0 +1,305 @@
+package saml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/auth/saml"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+const requestID = "_request-1"
+
+func newConfig(idp *testkit.SAMLIdentityProvider) saml.Config {
+	return saml.Config{
+		EntityID:       testkit.SAMLSPEntityID,
+		ACSURL:         testkit.SAMLACSURL,
+		IdPEntityID:    testkit.SAMLIdPEntityID,
+		IdPSSOURL:      "https://idp.example.com/sso?tenant=acme",
+		IdPCertificate: idp.CertificatePEM(),
+	}
+}
+
+func newService(config saml.Config, users user.Service) auth.Service {
+	tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	return saml.NewService(config, users, tokenManager)
+}
+
+// existingUser returns a user service that finds jane.doe@example.com
+func existingUser() *userMock.MockUserService {
+	users := &userMock.MockUserService{}
+	users.On("List", mock.Anything, mock.Anything).
+		Return(&user.Page{Users: []*user.User{testkit.NewUserBuilder().Build()}}, nil)
+	return users
+}
+
+func TestAuthenticate_GivenSignedAssertion_WhenValidating_ThenCreatesUserFromAttributes(t *testing.T) {
+	// Arrange
+	idp := testkit.NewSAMLIdentityProvider(t)
+	users := &userMock.MockUserService{}
+	created := testkit.NewUserBuilder().Build()
+	users.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	users.On("Register", mock.Anything, mock.MatchedBy(func(data user.RegisterData) bool {
+		return data.Email == testkit.DefaultEmail && data.FirstName == "Jane" && data.LastName == "Doe"
+	})).Return(created, nil)
+	service := newService(newConfig(idp), users)
+	response := idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "saml", auth.SAMLCredentials{
+		SAMLResponse: testkit.EncodeSAMLResponse(response),
+		RequestID:    requestID,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), result.User.ID)
+	assert.Equal(t, "saml", result.Strategy)
+	assert.NotEmpty(t, result.Token)
+	users.AssertExpectations(t)
+}
+
+func TestAuthenticate_GivenSignedResponse_WhenValidating_ThenAcceptsUnsignedAssertionInside(t *testing.T) {
+	idp := testkit.NewSAMLIdentityProvider(t)
+	service := newService(newConfig(idp), existingUser())
+	response := idp.SignedResponseXML(t, testkit.NewSAMLAssertion(requestID))
+
+	result, err := service.Authenticate(context.Background(), "saml", auth.SAMLCredentials{
+		SAMLResponse: testkit.EncodeSAMLResponse(response),
+		RequestID:    requestID,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, testkit.DefaultEmail, result.User.Email)
+}
+
+func TestAuthenticate_GivenAssertionSerializedDifferently_WhenValidating_ThenCanonicalFormMatchesSignature(t *testing.T) {
+	// Arrange
+	idp := testkit.NewSAMLIdentityProvider(t)
+	service := newService(newConfig(idp), existingUser())
+	assertion := testkit.NewSAMLAssertion(requestID)
+	response := idp.ResponseXML(t, assertion)
+
+	// The namespace moves to the root, attributes are reordered and quoted
+	// differently, names use character references and the document gets a
+	// declaration, comments and line breaks; none of this is signed content
+	response = strings.Replace(response, `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID=`, `<saml:Assertion ID=`, 1)
+	response = strings.Replace(response, `<samlp:Response `, `<samlp:Response xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" `, 1)
+	conditions := `<saml:Conditions NotBefore="` + assertion.NotBefore.Format(time.RFC3339) + `" NotOnOrAfter="` + assertion.NotOnOrAfter.Format(time.RFC3339) + `">`
+	require.Contains(t, response, conditions)
+	response = strings.Replace(response, conditions,
+		"<saml:Conditions\n  NotOnOrAfter='"+assertion.NotOnOrAfter.Format(time.RFC3339)+"'\n  NotBefore='"+assertion.NotBefore.Format(time.RFC3339)+"'>", 1)
+	response = strings.Replace(response, `<saml:AttributeValue>Jane</saml:AttributeValue>`, `<saml:AttributeValue>J&#97;ne<!-- first name --></saml:AttributeValue>`, 1)
+	response = strings.Replace(response, `<samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode>`,
+		`<samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>`, 1)
+	response = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + response
+
+	// Act
+	result, err := service.Authenticate(context.Background(), "saml", auth.SAMLCredentials{
+		SAMLResponse: testkit.EncodeSAMLResponse(response),
+		RequestID:    requestID,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "saml", result.Strategy)
+}
+
+func TestAuthenticate_GivenInvalidResponse_WhenValidating_ThenReturnsInvalidSAMLResponse(t *testing.T) {
+	idp := testkit.NewSAMLIdentityProvider(t)
+	otherIdP := testkit.NewSAMLIdentityProvider(t)
+
+	tests := []struct {
+		name      string
+		requestID string
+		response  func(t *testing.T) string
+	}{
+		{
+			name:      "Given an attribute changed after signing, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				response := idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+				return strings.Replace(response, `<saml:AttributeValue>`+testkit.DefaultEmail, `<saml:AttributeValue>admin@example.com`, 1)
+			},
+		},
+		{
+			name:      "Given an assertion signed by another identity provider, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				return otherIdP.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+			},
+		},
+		{
+			name:      "Given an unsigned response, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				return idp.UnsignedResponseXML(testkit.NewSAMLAssertion(requestID))
+			},
+		},
+		{
+			name:      "Given a signed assertion wrapped next to an unsigned one, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				signed := idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+				forged := testkit.NewSAMLAssertion(requestID)
+				forged.ID = "_forged"
+				forged.Email = "admin@example.com"
+				unsigned := idp.UnsignedResponseXML(forged)
+				start := strings.Index(unsigned, "<saml:Assertion ")
+				end := strings.Index(unsigned, "</samlp:Response>")
+				return strings.Replace(signed, "</samlp:Response>", unsigned[start:end]+"</samlp:Response>", 1)
+			},
+		},
+		{
+			name:      "Given an assertion for another service provider, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				assertion := testkit.NewSAMLAssertion(requestID)
+				assertion.Audience = "https://other.example.com"
+				return idp.ResponseXML(t, assertion)
+			},
+		},
+		{
+			name:      "Given an assertion from another issuer, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				assertion := testkit.NewSAMLAssertion(requestID)
+				assertion.Issuer = "https://evil.example.com"
+				return idp.ResponseXML(t, assertion)
+			},
+		},
+		{
+			name:      "Given an expired assertion, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				assertion := testkit.NewSAMLAssertion(requestID)
+				assertion.NotBefore = time.Now().Add(-time.Hour)
+				assertion.NotOnOrAfter = time.Now().Add(-10 * time.Minute)
+				return idp.ResponseXML(t, assertion)
+			},
+		},
+		{
+			name:      "Given an assertion for another recipient, When validating, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				assertion := testkit.NewSAMLAssertion(requestID)
+				assertion.Recipient = "https://other.example.com/acs"
+				return idp.ResponseXML(t, assertion)
+			},
+		},
+		{
+			name:      "Given a response to another request, When validating, Then returns invalid response",
+			requestID: "_request-2",
+			response: func(t *testing.T) string {
+				return idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+			},
+		},
+		{
+			name:      "Given an unsolicited response, When IdP-initiated sign-in is not allowed, Then returns invalid response",
+			requestID: "",
+			response: func(t *testing.T) string {
+				return idp.ResponseXML(t, testkit.NewSAMLAssertion(""))
+			},
+		},
+		{
+			name:      "Given a document type declaration, When parsing, Then returns invalid response",
+			requestID: requestID,
+			response: func(t *testing.T) string {
+				return `<!DOCTYPE r [<!ENTITY e "x">]>` + idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &userMock.MockUserService{}
+			service := newService(newConfig(idp), users)
+			result, err := service.Authenticate(context.Background(), "saml", auth.SAMLCredentials{
+				SAMLResponse: testkit.EncodeSAMLResponse(tt.response(t)),
+				RequestID:    tt.requestID,
+			})
+
+			assert.True(t, errors.Is(err, auth.ErrInvalidSAMLResponse), "unexpected error: %v", err)
+			assert.Nil(t, result)
+			users.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_GivenUsedAssertion_WhenPostedAgain_ThenReturnsInvalidSAMLResponse(t *testing.T) {
+	idp := testkit.NewSAMLIdentityProvider(t)
+	service := newService(newConfig(idp), existingUser())
+	credentials := auth.SAMLCredentials{
+		SAMLResponse: testkit.EncodeSAMLResponse(idp.ResponseXML(t, testkit.NewSAMLAssertion(requestID))),
+		RequestID:    requestID,
+	}
+	_, err := service.Authenticate(context.Background(), "saml", credentials)
+	require.NoError(t, err)
+
+	_, err = service.Authenticate(context.Background(), "saml", credentials)
+
+	assert.ErrorIs(t, err, auth.ErrInvalidSAMLResponse)
+}
+
+func TestAuthenticate_GivenUnsolicitedResponse_WhenIdPInitiatedAllowed_ThenSignsIn(t *testing.T) {
+	idp := testkit.NewSAMLIdentityProvider(t)
+	config := newConfig(idp)
+	config.AllowIdPInitiated = true
+	service := newService(config, existingUser())
+
+	result, err := service.Authenticate(context.Background(), "saml", auth.SAMLCredentials{
+		SAMLResponse: testkit.EncodeSAMLResponse(idp.ResponseXML(t, testkit.NewSAMLAssertion(""))),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "saml", result.Strategy)
+}
+
+func TestNewAuthnRequest_GivenConfig_WhenCreating_ThenEncodesRequestForRedirectBinding(t *testing.T) {
+	// Arrange
+	config := newConfig(testkit.NewSAMLIdentityProvider(t))
+
+	// Act
+	request, err := saml.NewAuthnRequest(config, "state-123")
+
+	// Assert
+	require.NoError(t, err)
+	redirect, err := url.Parse(request.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", redirect.Host)
+	assert.Equal(t, "acme", redirect.Query().Get("tenant"))
+	assert.Equal(t, "state-123", redirect.Query().Get("RelayState"))
+
+	deflated, err := base64.StdEncoding.DecodeString(redirect.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+	assert.Contains(t, string(inflated), `ID="`+request.ID+`"`)
+	assert.Contains(t, string(inflated), `AssertionConsumerServiceURL="`+testkit.SAMLACSURL+`"`)
+	assert.Contains(t, string(inflated), testkit.SAMLSPEntityID+`</Issuer>`)
+}
+
+func TestMetadata_GivenConfig_WhenGenerating_ThenDescribesServiceProvider(t *testing.T) {
+	metadata, err := saml.Metadata(newConfig(testkit.NewSAMLIdentityProvider(t)))
+
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="`+testkit.SAMLSPEntityID+`"`)
+	assert.Contains(t, string(metadata), `WantAssertionsSigned="true"`)
+	assert.Contains(t, string(metadata), `Binding="`+saml.PostBinding+`" Location="`+testkit.SAMLACSURL+`"`)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// APIKeyStrategy is the strategy name API keys authenticate with
+const APIKeyStrategy = "apikey"
+
+// APIKeyAuthStrategy implements auth.Service for API key authentication
+// API keys are long-lived API tokens issued by token.Service.GenerateAPIToken.
+// Their scopes are carried into the claims so callers can check them per
+// operation; keys cannot be refreshed, only revoked and issued again.
+type APIKeyAuthStrategy struct {
+	userService  user.Service
+	tokenService token.Service
+}
+
+// NewAPIKeyAuthStrategy creates a new API key authentication strategy
+func NewAPIKeyAuthStrategy(userService user.Service, tokenService token.Service) auth.Service {
+	return &APIKeyAuthStrategy{
+		userService:  userService,
+		tokenService: tokenService,
+	}
+}
+
+// Authenticate handles only "apikey" strategy
+func (s *APIKeyAuthStrategy) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != APIKeyStrategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	apiKeyCreds, ok := credentials.(auth.APIKeyCredentials)
+	if !ok {
+		return nil, fmt.Errorf("invalid credentials type for API key auth")
+	}
+
+	claims, err := s.ValidateToken(ctx, apiKeyCreds.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	userDomainUser, err := s.userService.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	// Keys issued before a deactivation stop working with it
+	if !userDomainUser.IsActive() {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	return &auth.AuthResult{
+		User:      convertUserDomainToAuth(userDomainUser),
+		Token:     apiKeyCreds.Key,
+		ExpiresAt: claims.ExpiresAt,
+		Strategy:  APIKeyStrategy,
+	}, nil
+}
+
+// ValidateToken validates the API key and returns its claims with the granted scopes
+func (s *APIKeyAuthStrategy) ValidateToken(ctx context.Context, apiKey string) (*auth.TokenClaims, error) {
+	claims, err := s.tokenService.ValidateAPIToken(ctx, apiKey)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenExpired) {
+			return nil, auth.ErrTokenExpired
+		}
+		return nil, auth.ErrInvalidToken
+	}
+
+	return &auth.TokenClaims{
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: claims.ExpiresAt,
+		TokenType: claims.TokenType,
+		Strategy:  APIKeyStrategy,
+		Scopes:    claims.Scopes,
+	}, nil
+}
+
+// RefreshToken is not supported; API keys are issued again instead
+func (s *APIKeyAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return nil, auth.ErrInvalidRefreshToken
+}
+
+// RevokeToken delegates to token service
+func (s *APIKeyAuthStrategy) RevokeToken(ctx context.Context, apiKey string) error {
+	return s.tokenService.RevokeToken(ctx, apiKey)
+}
+
+// Introspect describes the API key with its granted scopes
+func (s *APIKeyAuthStrategy) Introspect(ctx context.Context, apiKey string) (*auth.TokenIntrospection, error) {
+	claims, err := s.ValidateToken(ctx, apiKey)
+	if err != nil {
+		return auth.NewTokenIntrospection(nil), nil
+	}
+	return auth.NewTokenIntrospection(claims), nil
+}
+
+// RevokeAllForUser delegates to token service
+func (s *APIKeyAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	return s.tokenService.RevokeAllTokensForUser(ctx, userID)
+}
+
+// GetSupportedStrategies returns apikey strategy
+func (s *APIKeyAuthStrategy) GetSupportedStrategies() []string {
+	return []string{APIKeyStrategy}
+}
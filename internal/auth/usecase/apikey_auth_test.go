@@ -0,0 +1,150 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func TestAPIKeyAuthStrategy_Authenticate(t *testing.T) {
+	t.Run("Given a valid API key, When Authenticate is called with apikey strategy, Then should authenticate its user", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		owner := testkit.NewUserBuilder().WithEmail("jane@example.com").Build()
+		mockUserService.On("GetByID", mock.Anything, owner.ID.String()).Return(owner, nil)
+		apiKey, err := tokens.GenerateAPIToken(context.Background(), owner.ID.String(), []string{"users:read"})
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(mockUserService, tokens)
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "apikey", auth.APIKeyCredentials{Key: apiKey.Token})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID.String(), result.User.ID)
+		assert.Equal(t, "jane@example.com", result.User.Email)
+		assert.Equal(t, apiKey.Token, result.Token)
+		assert.Equal(t, "apikey", result.Strategy)
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Given an API key of a deactivated user, When Authenticate is called, Then should return account deactivated error", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		owner := testkit.NewUserBuilder().Build()
+		deactivatedAt := time.Now()
+		owner.DeactivatedAt = &deactivatedAt
+		mockUserService.On("GetByID", mock.Anything, owner.ID.String()).Return(owner, nil)
+		apiKey, err := tokens.GenerateAPIToken(context.Background(), owner.ID.String(), []string{"users:read"})
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(mockUserService, tokens)
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "apikey", auth.APIKeyCredentials{Key: apiKey.Token})
+
+		// Assert
+		assert.Equal(t, user.ErrAccountDeactivated, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Given an access token, When Authenticate is called with apikey strategy, Then should return invalid token error", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		accessToken, _, err := tokens.GenerateAuthToken(context.Background(), "user-1", "user-1@example.com")
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(mockUserService, tokens)
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "apikey", auth.APIKeyCredentials{Key: accessToken})
+
+		// Assert
+		assert.Equal(t, auth.ErrInvalidToken, err)
+		assert.Nil(t, result)
+		mockUserService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a revoked API key, When Authenticate is called, Then should return invalid token error", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		apiKey, err := tokens.GenerateAPIToken(context.Background(), "user-1", []string{"users:read"})
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(mockUserService, tokens)
+		require.NoError(t, strategy.RevokeToken(context.Background(), apiKey.Token))
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "apikey", auth.APIKeyCredentials{Key: apiKey.Token})
+
+		// Assert
+		assert.Equal(t, auth.ErrInvalidToken, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Given another strategy name, When Authenticate is called, Then should return unsupported strategy error", func(t *testing.T) {
+		// Arrange
+		strategy := usecase.NewAPIKeyAuthStrategy(new(authmock.MockUserService), testkit.NewTokenService(t))
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "jwt", auth.APIKeyCredentials{Key: "key"})
+
+		// Assert
+		assert.Equal(t, auth.ErrUnsupportedStrategy, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAPIKeyAuthStrategy_ValidateToken(t *testing.T) {
+	t.Run("Given a valid API key, When ValidateToken is called, Then should return claims with its scopes", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		apiKey, err := tokens.GenerateAPIToken(context.Background(), "user-1", []string{"users:read", "notifications:*"})
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(new(authmock.MockUserService), tokens)
+
+		// Act
+		claims, err := strategy.ValidateToken(context.Background(), apiKey.Token)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+		assert.True(t, claims.IsAPIKey())
+		assert.Equal(t, "apikey", claims.Strategy)
+		assert.Equal(t, []string{"users:read", "notifications:*"}, claims.Scopes)
+		assert.True(t, claims.HasScope("notifications:read"))
+		assert.False(t, claims.HasScope("users:write"))
+	})
+
+	t.Run("Given an API key, When RefreshToken is called, Then should return invalid refresh token error", func(t *testing.T) {
+		// Arrange
+		tokens := testkit.NewTokenService(t)
+		apiKey, err := tokens.GenerateAPIToken(context.Background(), "user-1", []string{"users:read"})
+		require.NoError(t, err)
+
+		strategy := usecase.NewAPIKeyAuthStrategy(new(authmock.MockUserService), tokens)
+
+		// Act
+		result, err := strategy.RefreshToken(context.Background(), apiKey.Token)
+
+		// Assert
+		assert.Equal(t, auth.ErrInvalidRefreshToken, err)
+		assert.Nil(t, result)
+	})
+}
@@ -2,15 +2,25 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
 // AuthOrchestrator implements auth.Service and orchestrates different authentication strategies
 // This contains the core business logic for authentication management
 type AuthOrchestrator struct {
 	tokenManager    *JWTTokenManager
+	tokenService    token.Service  // Optional; tokens it issues are introspected and revoked too
+	eventPublisher  events.Service // Optional; announces refresh token reuse
 	strategyManager *StrategyManager
 }
 
@@ -22,6 +32,21 @@ func NewAuthOrchestrator(tokenManager *JWTTokenManager) *AuthOrchestrator {
 	}
 }
 
+// NewAuthOrchestratorWithTokenService creates an authentication orchestrator
+// that also introspects and revokes the tokens issued by tokenService, such
+// as API keys and the tokens of the REST API
+func NewAuthOrchestratorWithTokenService(tokenManager *JWTTokenManager, tokenService token.Service) *AuthOrchestrator {
+	orchestrator := NewAuthOrchestrator(tokenManager)
+	orchestrator.tokenService = tokenService
+	return orchestrator
+}
+
+// SetEventPublisher publishes an auth.token.reuse_detected event whenever a
+// rotated refresh token is used again
+func (s *AuthOrchestrator) SetEventPublisher(publisher events.Service) {
+	s.eventPublisher = publisher
+}
+
 // RegisterStrategy registers an authentication strategy
 func (s *AuthOrchestrator) RegisterStrategy(name string, strategy auth.Service) {
 	s.strategyManager.RegisterStrategy(name, strategy)
@@ -37,7 +62,8 @@ func (s *AuthOrchestrator) ValidateToken(ctx context.Context, token string) (*au
 	return s.tokenManager.ValidateToken(token)
 }
 
-// RefreshToken generates a new access token using a refresh token
+// RefreshToken exchanges a refresh token for a new access token and refresh
+// token. Reusing an exchanged refresh token revokes all tokens of its family.
 func (s *AuthOrchestrator) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
 	// Validate refresh token
 	claims, err := s.tokenManager.ValidateToken(refreshToken)
@@ -49,25 +75,14 @@ func (s *AuthOrchestrator) RefreshToken(ctx context.Context, refreshToken string
 		return nil, auth.ErrInvalidRefreshToken
 	}
 
-	// Generate new access token
-	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(claims.UserID, claims.Email)
+	result, err := s.tokenManager.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Create user from claims (simplified)
-	authUser := &auth.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			s.publishReuseDetected(ctx, claims)
+		}
+		return nil, err
 	}
-
-	return &auth.AuthResult{
-		User:         authUser,
-		Token:        accessToken,
-		RefreshToken: refreshToken, // Keep the same refresh token
-		ExpiresAt:    expiresAt,
-		Strategy:     claims.Strategy,
-	}, nil
+	return result, nil
 }
 
 // RevokeToken revokes an authentication token
@@ -75,11 +90,102 @@ func (s *AuthOrchestrator) RevokeToken(ctx context.Context, token string) error
 	return s.tokenManager.RevokeToken(token)
 }
 
+// Introspect describes the token. Tokens issued by the token service stay
+// active only while neither the token service nor the token manager has
+// revoked them.
+func (s *AuthOrchestrator) Introspect(ctx context.Context, tokenString string) (*auth.TokenIntrospection, error) {
+	if s.tokenService == nil {
+		return s.tokenManager.Introspect(tokenString), nil
+	}
+
+	claims, err := s.tokenService.ValidateToken(ctx, tokenString)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			return auth.NewTokenIntrospection(nil), nil
+		}
+		return s.tokenManager.Introspect(tokenString), nil
+	}
+	if s.tokenManager.IsRevoked(tokenString) {
+		return auth.NewTokenIntrospection(nil), nil
+	}
+
+	authClaims := &auth.TokenClaims{
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: claims.ExpiresAt,
+		TokenType: claims.TokenType,
+		Strategy:  "jwt",
+	}
+	switch claims.TokenType {
+	case "api":
+		apiClaims, err := s.tokenService.ValidateAPIToken(ctx, tokenString)
+		if err != nil {
+			return auth.NewTokenIntrospection(nil), nil
+		}
+		authClaims.Strategy = APIKeyStrategy
+		authClaims.Scopes = apiClaims.Scopes
+	case token.TokenTypeImpersonation:
+		impersonation, err := s.tokenService.ValidateImpersonationToken(ctx, tokenString)
+		if err != nil {
+			return auth.NewTokenIntrospection(nil), nil
+		}
+		authClaims.Scopes = impersonation.Scopes
+	}
+	return auth.NewTokenIntrospection(authClaims), nil
+}
+
+// RevokeAllForUser revokes every token issued to the user so far, including
+// those of the token service
+func (s *AuthOrchestrator) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	if s.tokenService == nil {
+		return nil
+	}
+	if err := s.tokenService.RevokeAllTokensForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
 // GetSupportedStrategies returns the list of supported authentication strategies
 func (s *AuthOrchestrator) GetSupportedStrategies() []string {
 	return s.strategyManager.GetSupportedStrategies()
 }
 
+// publishReuseDetected announces that the refresh token with the claims was
+// used again and its family revoked; failures are only logged
+func (s *AuthOrchestrator) publishReuseDetected(ctx context.Context, claims *auth.TokenClaims) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	now := time.Now()
+	event := events.Event{
+		ID:            uuid.NewString(),
+		Type:          events.EventTypeTokenReuseDetected,
+		AggregateID:   claims.UserID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":     claims.UserID,
+			"family_id":   claims.FamilyID,
+			"detected_at": now,
+		},
+		Metadata: events.EventMetadata{
+			UserID:    claims.UserID,
+			Source:    "auth",
+			IPAddress: user.ClientIPFromContext(ctx),
+		},
+		Timestamp: now,
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+	if err := s.eventPublisher.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish TokenReuseDetected event: %v", err)
+	}
+}
+
 // StrategyManager manages authentication strategies - this is core business logic
 type StrategyManager struct {
 	strategies map[string]auth.Service
@@ -11,6 +11,10 @@ import (
 	"github.com/gentra/decorator-arch-go/internal/auth"
 	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
 	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
 )
 
 func TestAuthOrchestrator_Authenticate(t *testing.T) {
@@ -204,12 +208,50 @@ func TestAuthOrchestrator_RefreshToken(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
 				assert.NotEmpty(t, result.Token)
-				assert.Equal(t, testToken, result.RefreshToken) // Should keep same refresh token
+				assert.NotEqual(t, testToken, result.RefreshToken) // Should rotate the refresh token
 			}
 		})
 	}
 }
 
+func TestAuthOrchestrator_RefreshToken_GivenRotatedRefreshToken_WhenReused_ThenRevokesFamilyAndPublishesEvent(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+	orchestrator := usecase.NewAuthOrchestrator(tokenManager)
+	publisher := eventsMemory.NewService(events.EventConfig{})
+	orchestrator.SetEventPublisher(publisher)
+	ctx := context.Background()
+
+	refreshToken, err := tokenManager.GenerateRefreshToken("user-123")
+	assert.NoError(t, err)
+	rotated, err := orchestrator.RefreshToken(ctx, refreshToken)
+	assert.NoError(t, err)
+	otherSession, err := tokenManager.GenerateRefreshToken("user-123")
+	assert.NoError(t, err)
+
+	// Act
+	result, err := orchestrator.RefreshToken(ctx, refreshToken)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Equal(t, auth.ErrRefreshTokenReused, err)
+
+	_, err = orchestrator.ValidateToken(ctx, rotated.Token)
+	assert.Error(t, err, "access tokens of the family are revoked")
+	_, err = orchestrator.RefreshToken(ctx, rotated.RefreshToken)
+	assert.Error(t, err, "refresh tokens of the family are revoked")
+	_, err = orchestrator.RefreshToken(ctx, otherSession)
+	assert.NoError(t, err, "other sessions keep working")
+
+	published, err := publisher.GetEvents(ctx, events.EventFilters{EventTypes: []string{events.EventTypeTokenReuseDetected}})
+	assert.NoError(t, err)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, "user-123", published[0].AggregateID)
+		assert.NotEmpty(t, published[0].Data["family_id"])
+	}
+}
+
 func TestAuthOrchestrator_RevokeToken(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -255,6 +297,113 @@ func TestAuthOrchestrator_RevokeToken(t *testing.T) {
 	}
 }
 
+func TestAuthOrchestrator_Introspect(t *testing.T) {
+	testCases := []struct {
+		name             string
+		setupToken       func(*usecase.JWTTokenManager, token.Service) string
+		expectedActive   bool
+		expectedStrategy string
+		expectedScope    string
+	}{
+		{
+			name: "Given token manager token, When Introspect is called, Then should describe active token",
+			setupToken: func(tokenManager *usecase.JWTTokenManager, tokenService token.Service) string {
+				token, _, _ := tokenManager.GenerateAuthToken("user-123", "test@example.com")
+				return token
+			},
+			expectedActive:   true,
+			expectedStrategy: "jwt",
+		},
+		{
+			name: "Given token service API key, When Introspect is called, Then should describe active token with scopes",
+			setupToken: func(tokenManager *usecase.JWTTokenManager, tokenService token.Service) string {
+				apiToken, _ := tokenService.GenerateAPIToken(context.Background(), "user-123", []string{"users:read", "users:write"})
+				return apiToken.Token
+			},
+			expectedActive:   true,
+			expectedStrategy: usecase.APIKeyStrategy,
+			expectedScope:    "users:read users:write",
+		},
+		{
+			name: "Given token service token revoked there, When Introspect is called, Then should report inactive token",
+			setupToken: func(tokenManager *usecase.JWTTokenManager, tokenService token.Service) string {
+				token, _, _ := tokenService.GenerateAuthToken(context.Background(), "user-123", "test@example.com")
+				_ = tokenService.RevokeToken(context.Background(), token)
+				return token
+			},
+			expectedActive: false,
+		},
+		{
+			name: "Given token service token revoked by token manager, When Introspect is called, Then should report inactive token",
+			setupToken: func(tokenManager *usecase.JWTTokenManager, tokenService token.Service) string {
+				token, _, _ := tokenService.GenerateAuthToken(context.Background(), "user-123", "test@example.com")
+				_ = tokenManager.RevokeToken(token)
+				return token
+			},
+			expectedActive: false,
+		},
+		{
+			name: "Given malformed token, When Introspect is called, Then should report inactive token",
+			setupToken: func(tokenManager *usecase.JWTTokenManager, tokenService token.Service) string {
+				return "invalid-token"
+			},
+			expectedActive: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tokenManager := usecase.NewJWTTokenManager(testkit.TestSecret, time.Hour, 24*time.Hour)
+			tokenService := testkit.NewTokenService(t)
+			orchestrator := usecase.NewAuthOrchestratorWithTokenService(tokenManager, tokenService)
+
+			testToken := tt.setupToken(tokenManager, tokenService)
+
+			// Act
+			introspection, err := orchestrator.Introspect(context.Background(), testToken)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedActive, introspection.Active)
+			if tt.expectedActive {
+				assert.Equal(t, "user-123", introspection.Subject)
+				assert.Equal(t, tt.expectedStrategy, introspection.Strategy)
+				assert.Equal(t, tt.expectedScope, introspection.Scope)
+			}
+		})
+	}
+}
+
+func TestAuthOrchestrator_RevokeAllForUser(t *testing.T) {
+	// Arrange
+	tokenManager := usecase.NewJWTTokenManager(testkit.TestSecret, time.Hour, 24*time.Hour)
+	tokenService := testkit.NewTokenService(t)
+	orchestrator := usecase.NewAuthOrchestratorWithTokenService(tokenManager, tokenService)
+	ctx := context.Background()
+
+	managerToken, _, err := tokenManager.GenerateAuthToken("user-123", "test@example.com")
+	assert.NoError(t, err)
+	serviceToken, _, err := tokenService.GenerateAuthToken(ctx, "user-123", "test@example.com")
+	assert.NoError(t, err)
+	otherToken, _, err := tokenService.GenerateAuthToken(ctx, "user-456", "other@example.com")
+	assert.NoError(t, err)
+
+	// Act
+	err = orchestrator.RevokeAllForUser(ctx, "user-123")
+
+	// Assert
+	assert.NoError(t, err)
+	_, err = tokenManager.ValidateToken(managerToken)
+	assert.Equal(t, auth.ErrInvalidToken, err)
+	_, err = tokenService.ValidateToken(ctx, serviceToken)
+	assert.Equal(t, token.ErrTokenRevoked, err)
+
+	introspection, err := orchestrator.Introspect(ctx, otherToken)
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+}
+
 func TestAuthOrchestrator_GetSupportedStrategies(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -2,16 +2,62 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
+// ThrottleConfig controls how basic auth slows down repeated failed attempts
+type ThrottleConfig struct {
+	FreeAttempts int           // Failed attempts per email and IP after which delays start
+	BaseDelay    time.Duration // Delay after the first delayed failure, doubled with each further one
+	MaxDelay     time.Duration // Longest delay between attempts
+	Window       time.Duration // Time without failures after which they are forgotten; at least MaxDelay
+}
+
+// DefaultThrottleConfig returns the default brute-force throttling configuration
+func DefaultThrottleConfig() ThrottleConfig {
+	return ThrottleConfig{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     5 * time.Minute,
+		Window:       15 * time.Minute,
+	}
+}
+
+// Delay returns how long to wait after the given number of failed attempts
+// before the next attempt is accepted
+func (c ThrottleConfig) Delay(failures int) time.Duration {
+	if failures < c.FreeAttempts {
+		return 0
+	}
+
+	delay := c.BaseDelay
+	for i := c.FreeAttempts; i < failures && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxDelay)
+}
+
 // BasicAuthStrategy implements auth.Service for basic username/password authentication
+// With a throttle store it also slows down brute-force attempts, independent
+// of any lockout in the user service: failed attempts are counted per email
+// and client IP, and once ThrottleConfig.FreeAttempts have failed each
+// further attempt must wait a doubling delay after the previous failure or
+// fails with auth.ErrTooManyAttempts without checking the password. A
+// successful authentication forgets the failures.
 type BasicAuthStrategy struct {
 	userService  user.Service
 	tokenManager *JWTTokenManager // Will move this to usecase package
+	throttle     throttle.Service
+	config       ThrottleConfig
 }
 
 // NewBasicAuthStrategy creates a new basic authentication strategy
@@ -22,6 +68,33 @@ func NewBasicAuthStrategy(userService user.Service, tokenManager *JWTTokenManage
 	}
 }
 
+// NewBasicAuthStrategyWithThrottle creates a basic authentication strategy
+// that throttles failed attempts in store; zero config values use
+// DefaultThrottleConfig
+func NewBasicAuthStrategyWithThrottle(userService user.Service, tokenManager *JWTTokenManager, store throttle.Service, config ThrottleConfig) auth.Service {
+	defaults := DefaultThrottleConfig()
+	if config.FreeAttempts <= 0 {
+		config.FreeAttempts = defaults.FreeAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	config.Window = max(config.Window, config.MaxDelay)
+
+	return &BasicAuthStrategy{
+		userService:  userService,
+		tokenManager: tokenManager,
+		throttle:     store,
+		config:       config,
+	}
+}
+
 // Authenticate handles only "basic" strategy
 func (s *BasicAuthStrategy) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
 	if strategy != "basic" {
@@ -33,19 +106,28 @@ func (s *BasicAuthStrategy) Authenticate(ctx context.Context, strategy string, c
 		return nil, fmt.Errorf("invalid credentials type for basic auth")
 	}
 
+	throttleKey := s.throttleKey(ctx, basicCreds.Email)
+	if err := s.checkThrottle(ctx, throttleKey); err != nil {
+		return nil, err
+	}
+
 	// Use user service to validate credentials
 	authResult, err := s.userService.Login(ctx, basicCreds.Email, basicCreds.Password)
 	if err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) {
+			s.recordFailure(ctx, throttleKey)
+		}
 		return nil, auth.ErrInvalidCredentials
 	}
+	s.resetThrottle(ctx, throttleKey)
 
 	// Generate tokens
-	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(authResult.User.ID.String(), authResult.User.Email)
+	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(authResult.User.ID.String(), authResult.User.Email, token.AMRPassword)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := s.tokenManager.GenerateRefreshToken(authResult.User.ID.String())
+	refreshToken, err := s.tokenManager.GenerateRefreshToken(authResult.User.ID.String(), token.AMRPassword)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -66,35 +148,13 @@ func (s *BasicAuthStrategy) ValidateToken(ctx context.Context, token string) (*a
 
 // RefreshToken delegates to token manager
 func (s *BasicAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
-	// Validate refresh token
-	claims, err := s.tokenManager.ValidateToken(refreshToken)
+	// Exchange the refresh token for a new one of the same family
+	result, err := s.tokenManager.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, err
 	}
-
-	if !claims.IsRefreshToken() {
-		return nil, auth.ErrInvalidRefreshToken
-	}
-
-	// Generate new access token
-	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(claims.UserID, claims.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Create user from claims
-	authUser := &auth.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-	}
-
-	return &auth.AuthResult{
-		User:         authUser,
-		Token:        accessToken,
-		RefreshToken: refreshToken, // Keep the same refresh token
-		ExpiresAt:    expiresAt,
-		Strategy:     "basic",
-	}, nil
+	result.Strategy = "basic"
+	return result, nil
 }
 
 // RevokeToken delegates to token manager
@@ -102,11 +162,65 @@ func (s *BasicAuthStrategy) RevokeToken(ctx context.Context, token string) error
 	return s.tokenManager.RevokeToken(token)
 }
 
+// Introspect delegates to token manager
+func (s *BasicAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *BasicAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
 // GetSupportedStrategies returns only basic auth
 func (s *BasicAuthStrategy) GetSupportedStrategies() []string {
 	return []string{"basic"}
 }
 
+// throttleKey returns the key failed attempts of email from the client IP
+// are counted under
+func (s *BasicAuthStrategy) throttleKey(ctx context.Context, email string) string {
+	return strings.ToLower(strings.TrimSpace(email)) + "|" + user.ClientIPFromContext(ctx)
+}
+
+// checkThrottle refuses the attempt while the delay after the latest failure lasts
+func (s *BasicAuthStrategy) checkThrottle(ctx context.Context, key string) error {
+	if s.throttle == nil {
+		return nil
+	}
+
+	attempts, err := s.throttle.Attempts(ctx, key)
+	if err != nil {
+		return fmt.Errorf("throttle store error: %w", err)
+	}
+	if time.Now().Before(attempts.LastFailure.Add(s.config.Delay(attempts.Failures))) {
+		return auth.ErrTooManyAttempts
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt; the attempt already failed, so store
+// errors are only logged
+func (s *BasicAuthStrategy) recordFailure(ctx context.Context, key string) {
+	if s.throttle == nil {
+		return
+	}
+	if _, err := s.throttle.RecordFailure(ctx, key, s.config.Window); err != nil {
+		log.Printf("Failed to record failed basic auth attempt: %v", err)
+	}
+}
+
+// resetThrottle forgets the failed attempts after a successful one
+func (s *BasicAuthStrategy) resetThrottle(ctx context.Context, key string) {
+	if s.throttle == nil {
+		return
+	}
+	if err := s.throttle.Reset(ctx, key); err != nil {
+		log.Printf("Failed to reset failed basic auth attempts: %v", err)
+	}
+}
+
 // Helper function to convert user domain to auth domain
 func convertUserDomainToAuth(userDomainUser *user.User) *auth.User {
 	if userDomainUser == nil {
@@ -12,6 +12,8 @@ import (
 	"github.com/gentra/decorator-arch-go/internal/auth"
 	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
 	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
@@ -196,3 +198,114 @@ func TestBasicAuthStrategy_GetSupportedStrategies(t *testing.T) {
 		assert.Equal(t, []string{"basic"}, strategies)
 	})
 }
+
+func TestThrottleConfig_Delay(t *testing.T) {
+	config := usecase.ThrottleConfig{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	testCases := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{name: "Given no failures, When Delay is called, Then should not delay", failures: 0, expected: 0},
+		{name: "Given fewer failures than free attempts, When Delay is called, Then should not delay", failures: 2, expected: 0},
+		{name: "Given the free attempts failed, When Delay is called, Then should return the base delay", failures: 3, expected: time.Second},
+		{name: "Given further failures, When Delay is called, Then should double the delay", failures: 5, expected: 4 * time.Second},
+		{name: "Given many failures, When Delay is called, Then should cap the delay", failures: 100, expected: 5 * time.Second},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, config.Delay(tt.failures))
+		})
+	}
+}
+
+func TestBasicAuthStrategy_GivenThrottle_WhenFreeAttemptsAreUsedUp_ThenRefusesWithoutCheckingPassword(t *testing.T) {
+	// Arrange
+	mockUserService := new(authmock.MockUserService)
+	mockUserService.On("Login", mock.Anything, "test@example.com", "wrong-password").Return(nil, user.ErrInvalidCredentials)
+	basicAuth := newThrottledBasicAuth(mockUserService, throttleMemory.NewService(), time.Minute)
+	ctx := user.WithClientIP(context.Background(), "203.0.113.7")
+	credentials := auth.BasicCredentials{Email: "test@example.com", Password: "wrong-password"}
+
+	// Act
+	_, firstErr := basicAuth.Authenticate(ctx, "basic", credentials)
+	_, secondErr := basicAuth.Authenticate(ctx, "basic", credentials)
+	_, thirdErr := basicAuth.Authenticate(ctx, "basic", auth.BasicCredentials{Email: "TEST@example.com", Password: "wrong-password"})
+
+	// Assert
+	assert.Equal(t, auth.ErrInvalidCredentials, firstErr)
+	assert.Equal(t, auth.ErrInvalidCredentials, secondErr)
+	assert.Equal(t, auth.ErrTooManyAttempts, thirdErr)
+	mockUserService.AssertNumberOfCalls(t, "Login", 2)
+}
+
+func TestBasicAuthStrategy_GivenThrottledEmail_WhenAnotherIPAttempts_ThenChecksPassword(t *testing.T) {
+	// Arrange
+	mockUserService := new(authmock.MockUserService)
+	mockUserService.On("Login", mock.Anything, "test@example.com", "wrong-password").Return(nil, user.ErrInvalidCredentials)
+	basicAuth := newThrottledBasicAuth(mockUserService, throttleMemory.NewService(), time.Minute)
+	credentials := auth.BasicCredentials{Email: "test@example.com", Password: "wrong-password"}
+	attacker := user.WithClientIP(context.Background(), "203.0.113.7")
+	for i := 0; i < 2; i++ {
+		_, _ = basicAuth.Authenticate(attacker, "basic", credentials)
+	}
+
+	// Act
+	_, err := basicAuth.Authenticate(user.WithClientIP(context.Background(), "198.51.100.1"), "basic", credentials)
+
+	// Assert
+	assert.Equal(t, auth.ErrInvalidCredentials, err)
+	mockUserService.AssertNumberOfCalls(t, "Login", 3)
+}
+
+func TestBasicAuthStrategy_GivenThrottle_WhenDelayHasPassed_ThenChecksPasswordAgain(t *testing.T) {
+	// Arrange
+	mockUserService := new(authmock.MockUserService)
+	mockUserService.On("Login", mock.Anything, "test@example.com", "wrong-password").Return(nil, user.ErrInvalidCredentials)
+	basicAuth := newThrottledBasicAuth(mockUserService, throttleMemory.NewService(), 10*time.Millisecond)
+	credentials := auth.BasicCredentials{Email: "test@example.com", Password: "wrong-password"}
+	for i := 0; i < 2; i++ {
+		_, _ = basicAuth.Authenticate(context.Background(), "basic", credentials)
+	}
+
+	// Act
+	time.Sleep(20 * time.Millisecond)
+	_, err := basicAuth.Authenticate(context.Background(), "basic", credentials)
+
+	// Assert
+	assert.Equal(t, auth.ErrInvalidCredentials, err)
+	mockUserService.AssertNumberOfCalls(t, "Login", 3)
+}
+
+func TestBasicAuthStrategy_GivenThrottle_WhenAuthenticationSucceeds_ThenForgetsFailures(t *testing.T) {
+	// Arrange
+	mockUserService := new(authmock.MockUserService)
+	mockUserService.On("Login", mock.Anything, "test@example.com", "wrong-password").Return(nil, user.ErrInvalidCredentials)
+	mockUserService.On("Login", mock.Anything, "test@example.com", "password123").Return(&user.AuthResult{
+		User: &user.User{ID: uuid.New(), Email: "test@example.com"},
+	}, nil)
+	store := throttleMemory.NewService()
+	basicAuth := newThrottledBasicAuth(mockUserService, store, time.Minute)
+	_, _ = basicAuth.Authenticate(context.Background(), "basic", auth.BasicCredentials{Email: "test@example.com", Password: "wrong-password"})
+
+	// Act
+	_, err := basicAuth.Authenticate(context.Background(), "basic", auth.BasicCredentials{Email: "test@example.com", Password: "password123"})
+
+	// Assert
+	assert.NoError(t, err)
+	attempts, storeErr := store.Attempts(context.Background(), "test@example.com|")
+	assert.NoError(t, storeErr)
+	assert.Zero(t, attempts.Failures)
+}
+
+// newThrottledBasicAuth creates basic auth allowing two failed attempts
+// before delaying by baseDelay
+func newThrottledBasicAuth(userService user.Service, store throttle.Service, baseDelay time.Duration) auth.Service {
+	tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+	return usecase.NewBasicAuthStrategyWithThrottle(userService, tokenManager, store, usecase.ThrottleConfig{
+		FreeAttempts: 2,
+		BaseDelay:    baseDelay,
+	})
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// GuestStrategy is the strategy name guests authenticate with
+const GuestStrategy = "guest"
+
+// DefaultGuestTTL is how long guest tokens live unless configured
+const DefaultGuestTTL = 30 * time.Minute
+
+// DefaultGuestScopes are granted to guest tokens unless configured
+var DefaultGuestScopes = []string{"guest"}
+
+// GuestConfig controls the tokens issued to guests
+type GuestConfig struct {
+	TTL    time.Duration // Lifetime of guest tokens, DefaultGuestTTL when zero
+	Scopes []string      // Granted to guest tokens, DefaultGuestScopes when empty
+}
+
+// GuestAuthStrategy implements auth.Service for guests without an account
+// Each authentication creates a new guest ID and a short-lived token limited
+// to the guest scopes; guest tokens cannot be refreshed. UpgradeGuest turns
+// the guest into a registered user under the same ID, so events and audit
+// entries recorded for the guest continue on the user's aggregate.
+type GuestAuthStrategy struct {
+	userService  user.Service
+	tokenManager *JWTTokenManager
+	config       GuestConfig
+}
+
+// NewGuestAuthStrategy creates a new guest authentication strategy
+func NewGuestAuthStrategy(userService user.Service, tokenManager *JWTTokenManager, config GuestConfig) *GuestAuthStrategy {
+	if config.TTL <= 0 {
+		config.TTL = DefaultGuestTTL
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultGuestScopes
+	}
+
+	return &GuestAuthStrategy{
+		userService:  userService,
+		tokenManager: tokenManager,
+		config:       config,
+	}
+}
+
+// Authenticate handles only "guest" strategy
+func (s *GuestAuthStrategy) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != GuestStrategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	if _, ok := credentials.(auth.GuestCredentials); !ok {
+		return nil, fmt.Errorf("invalid credentials type for guest auth")
+	}
+
+	guestID := uuid.New().String()
+	guestToken, expiresAt, err := s.tokenManager.GenerateGuestToken(guestID, s.config.TTL, s.config.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.AuthResult{
+		User:      &auth.User{ID: guestID},
+		Token:     guestToken,
+		ExpiresAt: expiresAt,
+		Strategy:  GuestStrategy,
+	}, nil
+}
+
+// UpgradeGuest registers the guest holding guestToken as a user with the
+// guest's ID and signs them in. The guest token is revoked.
+func (s *GuestAuthStrategy) UpgradeGuest(ctx context.Context, guestToken string, data user.RegisterData) (*auth.AuthResult, error) {
+	claims, err := s.ValidateToken(ctx, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	guestID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	// A guest is upgraded once; the storage layer would report the taken ID
+	// as a taken email otherwise
+	_, err = s.userService.GetByID(ctx, guestID.String())
+	if err == nil {
+		return nil, auth.ErrUserAlreadyExists
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	data.ID = guestID
+	registered, err := s.userService.Register(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account exists now, so a failed revocation must not fail the upgrade;
+	// the check above refuses the token for a second upgrade anyway
+	_ = s.tokenManager.RevokeToken(guestToken)
+
+	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(registered.ID.String(), registered.Email, token.AMRPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := s.tokenManager.GenerateRefreshToken(registered.ID.String(), token.AMRPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &auth.AuthResult{
+		User:         convertUserDomainToAuth(registered),
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		Strategy:     GuestStrategy,
+	}, nil
+}
+
+// ValidateToken validates guest tokens only
+func (s *GuestAuthStrategy) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	claims, err := s.tokenManager.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.IsGuest() {
+		return nil, auth.ErrGuestTokenRequired
+	}
+	return claims, nil
+}
+
+// RefreshToken is not supported; guests authenticate again or upgrade
+func (s *GuestAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	return nil, auth.ErrInvalidRefreshToken
+}
+
+// RevokeToken delegates to token manager
+func (s *GuestAuthStrategy) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *GuestAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *GuestAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns guest strategy
+func (s *GuestAuthStrategy) GetSupportedStrategies() []string {
+	return []string{GuestStrategy}
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func newGuestTokenManager() *usecase.JWTTokenManager {
+	return usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+}
+
+// signInGuest authenticates a new guest and returns the guest token
+func signInGuest(t *testing.T, strategy *usecase.GuestAuthStrategy) *auth.AuthResult {
+	t.Helper()
+	result, err := strategy.Authenticate(context.Background(), "guest", auth.GuestCredentials{})
+	require.NoError(t, err)
+	return result
+}
+
+func TestGuestAuthStrategy_Authenticate(t *testing.T) {
+	t.Run("Given guest credentials, When Authenticate is called with guest strategy, Then should issue a short-lived limited token", func(t *testing.T) {
+		// Arrange
+		strategy := usecase.NewGuestAuthStrategy(new(authmock.MockUserService), newGuestTokenManager(),
+			usecase.GuestConfig{TTL: 10 * time.Minute, Scopes: []string{"catalog:read"}})
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "guest", auth.GuestCredentials{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.User.ID)
+		assert.Empty(t, result.RefreshToken)
+		assert.Equal(t, "guest", result.Strategy)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), result.ExpiresAt, 2*time.Second)
+
+		claims, err := strategy.ValidateToken(context.Background(), result.Token)
+		require.NoError(t, err)
+		assert.True(t, claims.IsGuest())
+		assert.Equal(t, result.User.ID, claims.UserID)
+		assert.Equal(t, "guest", claims.Strategy)
+		assert.True(t, claims.HasScope("catalog:read"))
+		assert.False(t, claims.HasScope("users:read"))
+	})
+
+	t.Run("Given two guests, When Authenticate is called for each, Then should give them different IDs", func(t *testing.T) {
+		strategy := usecase.NewGuestAuthStrategy(new(authmock.MockUserService), newGuestTokenManager(), usecase.GuestConfig{})
+
+		first := signInGuest(t, strategy)
+		second := signInGuest(t, strategy)
+
+		assert.NotEqual(t, first.User.ID, second.User.ID)
+	})
+
+	t.Run("Given a guest token, When refreshing it, Then should return invalid refresh token error", func(t *testing.T) {
+		strategy := usecase.NewGuestAuthStrategy(new(authmock.MockUserService), newGuestTokenManager(), usecase.GuestConfig{})
+		guest := signInGuest(t, strategy)
+
+		result, err := strategy.RefreshToken(context.Background(), guest.Token)
+
+		assert.Equal(t, auth.ErrInvalidRefreshToken, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGuestAuthStrategy_UpgradeGuest(t *testing.T) {
+	t.Run("Given a guest token, When UpgradeGuest is called, Then should register the user under the guest ID", func(t *testing.T) {
+		// Arrange
+		tokenManager := newGuestTokenManager()
+		mockUserService := new(authmock.MockUserService)
+		strategy := usecase.NewGuestAuthStrategy(mockUserService, tokenManager, usecase.GuestConfig{})
+		guest := signInGuest(t, strategy)
+
+		registered := testkit.NewUserBuilder().WithID(guest.User.ID).Build()
+		mockUserService.On("GetByID", mock.Anything, guest.User.ID).Return(nil, user.ErrUserNotFound)
+		mockUserService.On("Register", mock.Anything, mock.MatchedBy(func(data user.RegisterData) bool {
+			return data.ID.String() == guest.User.ID && data.Email == testkit.DefaultEmail
+		})).Return(registered, nil)
+
+		// Act
+		result, err := strategy.UpgradeGuest(context.Background(), guest.Token, testkit.NewUserBuilder().BuildRegisterData())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, guest.User.ID, result.User.ID)
+		assert.Equal(t, testkit.DefaultEmail, result.User.Email)
+		assert.NotEmpty(t, result.RefreshToken)
+
+		claims, err := tokenManager.ValidateToken(result.Token)
+		require.NoError(t, err)
+		assert.True(t, claims.IsAccessToken())
+		assert.Equal(t, guest.User.ID, claims.UserID)
+
+		_, err = strategy.ValidateToken(context.Background(), guest.Token)
+		assert.Equal(t, auth.ErrInvalidToken, err, "guest token should be revoked")
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Given an already upgraded guest, When UpgradeGuest is called again, Then should return user exists error", func(t *testing.T) {
+		// Arrange
+		mockUserService := new(authmock.MockUserService)
+		strategy := usecase.NewGuestAuthStrategy(mockUserService, newGuestTokenManager(), usecase.GuestConfig{})
+		guest := signInGuest(t, strategy)
+		mockUserService.On("GetByID", mock.Anything, guest.User.ID).
+			Return(testkit.NewUserBuilder().WithID(guest.User.ID).Build(), nil)
+
+		// Act
+		result, err := strategy.UpgradeGuest(context.Background(), guest.Token, testkit.NewUserBuilder().BuildRegisterData())
+
+		// Assert
+		assert.Equal(t, auth.ErrUserAlreadyExists, err)
+		assert.Nil(t, result)
+		mockUserService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a registration the user domain rejects, When UpgradeGuest is called, Then should keep the guest token", func(t *testing.T) {
+		// Arrange
+		mockUserService := new(authmock.MockUserService)
+		strategy := usecase.NewGuestAuthStrategy(mockUserService, newGuestTokenManager(), usecase.GuestConfig{})
+		guest := signInGuest(t, strategy)
+		mockUserService.On("GetByID", mock.Anything, guest.User.ID).Return(nil, user.ErrUserNotFound)
+		mockUserService.On("Register", mock.Anything, mock.Anything).Return(nil, user.ErrEmailAlreadyExists)
+
+		// Act
+		result, err := strategy.UpgradeGuest(context.Background(), guest.Token, testkit.NewUserBuilder().BuildRegisterData())
+
+		// Assert
+		assert.Equal(t, user.ErrEmailAlreadyExists, err)
+		assert.Nil(t, result)
+		_, err = strategy.ValidateToken(context.Background(), guest.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Given an access token, When UpgradeGuest is called, Then should return guest token required error", func(t *testing.T) {
+		// Arrange
+		tokenManager := newGuestTokenManager()
+		mockUserService := new(authmock.MockUserService)
+		strategy := usecase.NewGuestAuthStrategy(mockUserService, tokenManager, usecase.GuestConfig{})
+		accessToken, _, err := tokenManager.GenerateAuthToken("user-1", "user-1@example.com")
+		require.NoError(t, err)
+
+		// Act
+		result, err := strategy.UpgradeGuest(context.Background(), accessToken, testkit.NewUserBuilder().BuildRegisterData())
+
+		// Assert
+		assert.Equal(t, auth.ErrGuestTokenRequired, err)
+		assert.Nil(t, result)
+		mockUserService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+	})
+}
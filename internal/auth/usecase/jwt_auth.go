@@ -45,6 +45,11 @@ func (s *JWTAuthStrategy) Authenticate(ctx context.Context, strategy string, cre
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
+	// Tokens issued before a deactivation stop working with it
+	if !userDomainUser.IsActive() {
+		return nil, user.ErrAccountDeactivated
+	}
+
 	return &auth.AuthResult{
 		User:      convertUserDomainToAuth(userDomainUser),
 		Token:     jwtCreds.Token,
@@ -60,35 +65,13 @@ func (s *JWTAuthStrategy) ValidateToken(ctx context.Context, token string) (*aut
 
 // RefreshToken delegates to token manager
 func (s *JWTAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
-	// Validate refresh token
-	claims, err := s.tokenManager.ValidateToken(refreshToken)
+	// Exchange the refresh token for a new one of the same family
+	result, err := s.tokenManager.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
-	}
-
-	if !claims.IsRefreshToken() {
-		return nil, auth.ErrInvalidRefreshToken
-	}
-
-	// Generate new access token
-	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(claims.UserID, claims.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Create user from claims
-	authUser := &auth.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
+		return nil, err
 	}
-
-	return &auth.AuthResult{
-		User:         authUser,
-		Token:        accessToken,
-		RefreshToken: refreshToken, // Keep the same refresh token
-		ExpiresAt:    expiresAt,
-		Strategy:     "jwt",
-	}, nil
+	result.Strategy = "jwt"
+	return result, nil
 }
 
 // RevokeToken delegates to token manager
@@ -96,6 +79,17 @@ func (s *JWTAuthStrategy) RevokeToken(ctx context.Context, token string) error {
 	return s.tokenManager.RevokeToken(token)
 }
 
+// Introspect delegates to token manager
+func (s *JWTAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *JWTAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
 // GetSupportedStrategies returns jwt strategy
 func (s *JWTAuthStrategy) GetSupportedStrategies() []string {
 	return []string{"jwt"}
@@ -54,6 +54,29 @@ func TestJWTAuthStrategy_Authenticate_Simple(t *testing.T) {
 		mockUserService.AssertExpectations(t)
 	})
 
+	t.Run("Given a token of a deactivated user, When Authenticate is called, Then should return account deactivated error", func(t *testing.T) {
+		// Arrange
+		mockUserService := new(authmock.MockUserService)
+		tokenManager := usecase.NewJWTTokenManager([]byte("test-secret-key-for-testing"), time.Hour, 24*time.Hour)
+		testToken, _, _ := tokenManager.GenerateAuthToken("550e8400-e29b-41d4-a716-446655440000", "test@example.com")
+
+		deactivatedAt := time.Now()
+		mockUserService.On("GetByID", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").Return(&user.User{
+			ID:            uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+			Email:         "test@example.com",
+			DeactivatedAt: &deactivatedAt,
+		}, nil)
+
+		jwtAuth := usecase.NewJWTAuthStrategy(mockUserService, tokenManager)
+
+		// Act
+		result, err := jwtAuth.Authenticate(context.Background(), "jwt", auth.JWTCredentials{Token: testToken})
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrAccountDeactivated)
+		assert.Nil(t, result)
+	})
+
 	t.Run("Given unsupported strategy, When Authenticate is called, Then should return unsupported strategy error", func(t *testing.T) {
 		// Arrange
 		mockUserService := new(authmock.MockUserService)
@@ -1,35 +1,69 @@
 package usecase
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
 )
 
 // JWTTokenManager handles JWT token operations (moved from factory to usecase)
+// Refresh tokens rotate: each one can be exchanged once, for an access token
+// and a new refresh token of the same family. A family starts at sign-in and
+// is revoked as a whole when an exchanged refresh token shows up again.
+// Revoked tokens and users are recorded in a revocation store; refresh token
+// rotation is tracked in process.
 type JWTTokenManager struct {
-	secret        []byte
-	accessTTL     time.Duration
-	refreshTTL    time.Duration
-	revokedTokens map[string]time.Time // Simple in-memory revocation list
-	mu            sync.RWMutex
+	secret          []byte
+	accessTTL       time.Duration
+	refreshTTL      time.Duration
+	revocations     revocation.Service
+	revocationTTL   time.Duration        // Least time a user revocation is kept
+	rotatedTokens   map[string]time.Time // Exchanged refresh tokens by JTI, until they expire
+	revokedFamilies map[string]time.Time // Until every token of the family has expired
+	mu              sync.RWMutex
 }
 
-// NewJWTTokenManager creates a new JWT token manager
+// NewJWTTokenManager creates a new JWT token manager keeping revocations in
+// process memory
 func NewJWTTokenManager(secret []byte, accessTTL, refreshTTL time.Duration) *JWTTokenManager {
+	return NewJWTTokenManagerWithRevocations(secret, accessTTL, refreshTTL, revocationMemory.NewService(), 0)
+}
+
+// NewJWTTokenManagerWithRevocations creates a JWT token manager recording
+// revocations in a store, which instances share to honour each other's
+// revocations. A user revocation is kept for revocationTTL or until the
+// user's access and refresh tokens have expired, whichever is longer.
+func NewJWTTokenManagerWithRevocations(secret []byte, accessTTL, refreshTTL time.Duration, revocations revocation.Service, revocationTTL time.Duration) *JWTTokenManager {
 	return &JWTTokenManager{
-		secret:        secret,
-		accessTTL:     accessTTL,
-		refreshTTL:    refreshTTL,
-		revokedTokens: make(map[string]time.Time),
+		secret:          secret,
+		accessTTL:       accessTTL,
+		refreshTTL:      refreshTTL,
+		revocations:     revocations,
+		revocationTTL:   revocationTTL,
+		rotatedTokens:   make(map[string]time.Time),
+		revokedFamilies: make(map[string]time.Time),
 	}
 }
 
-func (tm *JWTTokenManager) GenerateAuthToken(userID string, email string) (string, time.Time, error) {
+// GenerateAuthToken issues an access token for a user signing in now with
+// the given methods, such as auth.AMRPassword
+func (tm *JWTTokenManager) GenerateAuthToken(userID string, email string, methods ...string) (string, time.Time, error) {
+	return tm.generateAuthToken(userID, email, "", auth.Authentication{Time: time.Now(), Methods: methods})
+}
+
+// generateAuthToken issues an access token, belonging to the refresh token
+// family unless it is empty, for the sign-in described by authentication
+func (tm *JWTTokenManager) generateAuthToken(userID, email, family string, authentication auth.Authentication) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(tm.accessTTL)
 
@@ -38,9 +72,14 @@ func (tm *JWTTokenManager) GenerateAuthToken(userID string, email string) (strin
 		"email":      email,
 		"token_type": "access",
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
 		"exp":        expiresAt.Unix(),
 		"jti":        tm.generateJTI(userID, now, "access"),
 	}
+	if family != "" {
+		claims["fam"] = family
+	}
+	addAuthentication(claims, authentication)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(tm.secret)
@@ -51,7 +90,13 @@ func (tm *JWTTokenManager) GenerateAuthToken(userID string, email string) (strin
 	return tokenString, expiresAt, nil
 }
 
-func (tm *JWTTokenManager) GenerateRefreshToken(userID string) (string, error) {
+// GenerateRefreshToken issues the first refresh token of a new family, for
+// a user signing in now with the given methods
+func (tm *JWTTokenManager) GenerateRefreshToken(userID string, methods ...string) (string, error) {
+	return tm.generateRefreshToken(userID, randomID(), auth.Authentication{Time: time.Now(), Methods: methods})
+}
+
+func (tm *JWTTokenManager) generateRefreshToken(userID, family string, authentication auth.Authentication) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(tm.refreshTTL)
 
@@ -59,9 +104,12 @@ func (tm *JWTTokenManager) GenerateRefreshToken(userID string) (string, error) {
 		"user_id":    userID,
 		"token_type": "refresh",
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
 		"exp":        expiresAt.Unix(),
 		"jti":        tm.generateJTI(userID, now, "refresh"),
+		"fam":        family,
 	}
+	addAuthentication(claims, authentication)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(tm.secret)
@@ -72,6 +120,31 @@ func (tm *JWTTokenManager) GenerateRefreshToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateGuestToken issues a token for a guest without an account. It
+// lives for ttl rather than the access TTL and grants only the scopes.
+func (tm *JWTTokenManager) GenerateGuestToken(guestID string, ttl time.Duration, scopes []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := jwt.MapClaims{
+		"user_id":    guestID,
+		"token_type": "guest",
+		"scopes":     scopes,
+		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"exp":        expiresAt.Unix(),
+		"jti":        tm.generateJTI(guestID, now, "guest"),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(tm.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign guest token: %w", err)
+	}
+
+	return tokenString, expiresAt, nil
+}
+
 func (tm *JWTTokenManager) ValidateToken(tokenString string) (*auth.TokenClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -95,7 +168,11 @@ func (tm *JWTTokenManager) ValidateToken(tokenString string) (*auth.TokenClaims,
 
 	// Check if token is revoked
 	if jti, ok := claims["jti"].(string); ok {
-		if tm.isTokenRevoked(jti) {
+		revoked, err := tm.isTokenRevoked(jti)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
 			return nil, auth.ErrInvalidToken
 		}
 	}
@@ -104,12 +181,24 @@ func (tm *JWTTokenManager) ValidateToken(tokenString string) (*auth.TokenClaims,
 	userID, _ := claims["user_id"].(string)
 	email, _ := claims["email"].(string)
 	tokenType, _ := claims["token_type"].(string)
+	family, _ := claims["fam"].(string)
+
+	if family != "" && tm.isFamilyRevoked(family) {
+		return nil, auth.ErrInvalidToken
+	}
 
 	if userID == "" || tokenType == "" {
 		return nil, auth.ErrInvalidToken
 	}
 
-	issuedAt := time.Unix(int64(claims["iat"].(float64)), 0)
+	issuedAt, precision := issuedAtClaim(claims)
+	revoked, err := tm.isUserRevoked(userID, issuedAt, precision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, auth.ErrInvalidToken
+	}
 	expiresAt := time.Unix(int64(claims["exp"].(float64)), 0)
 
 	// Check if token is expired
@@ -117,13 +206,75 @@ func (tm *JWTTokenManager) ValidateToken(tokenString string) (*auth.TokenClaims,
 		return nil, auth.ErrTokenExpired
 	}
 
+	strategy := "jwt"
+	if tokenType == "guest" {
+		strategy = "guest"
+	}
+
 	return &auth.TokenClaims{
 		UserID:    userID,
 		Email:     email,
 		IssuedAt:  issuedAt,
 		ExpiresAt: expiresAt,
 		TokenType: tokenType,
-		Strategy:  "jwt",
+		Strategy:  strategy,
+		Scopes:    scopesClaim(claims["scopes"]),
+		FamilyID:  family,
+		AuthTime:  authTimeClaim(claims["auth_time"]),
+		AMR:       scopesClaim(claims["amr"]),
+	}, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for an access token and a new
+// refresh token of the same family. Exchanging a refresh token a second time
+// means it was copied, so the whole family is revoked, the legitimate
+// holder's tokens included, and auth.ErrRefreshTokenReused is returned.
+// Refresh tokens issued before families existed start a family of their own.
+func (tm *JWTTokenManager) RotateRefreshToken(refreshToken string) (*auth.AuthResult, error) {
+	claims, err := tm.ValidateToken(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+	if !claims.IsRefreshToken() {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+
+	jti := tm.tokenID(refreshToken)
+	if jti == "" {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	family := claims.FamilyID
+	if family == "" {
+		family = jti
+	}
+
+	tm.mu.Lock()
+	if _, rotated := tm.rotatedTokens[jti]; rotated {
+		tm.revokedFamilies[family] = time.Now().Add(tm.refreshTTL)
+		tm.mu.Unlock()
+		return nil, auth.ErrRefreshTokenReused
+	}
+	tm.rotatedTokens[jti] = claims.ExpiresAt
+	tm.cleanupExpiredRotations()
+	tm.mu.Unlock()
+
+	// Rotation is not a sign-in; the family keeps the original one
+	authentication := auth.Authentication{Time: claims.AuthTime, Methods: claims.AMR}
+	accessToken, expiresAt, err := tm.generateAuthToken(claims.UserID, claims.Email, family, authentication)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	newRefreshToken, err := tm.generateRefreshToken(claims.UserID, family, authentication)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &auth.AuthResult{
+		User:         &auth.User{ID: claims.UserID, Email: claims.Email},
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt,
+		Strategy:     claims.Strategy,
 	}, nil
 }
 
@@ -152,44 +303,165 @@ func (tm *JWTTokenManager) RevokeToken(tokenString string) error {
 
 	expiresAt := time.Unix(int64(claims["exp"].(float64)), 0)
 
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
-
-	// Add to revocation list with expiration time
-	tm.revokedTokens[jti] = expiresAt
-
-	// Clean up expired revoked tokens
-	tm.cleanupExpiredRevokedTokens()
+	// Keep the token revoked until it expires
+	return tm.revocations.RevokeToken(context.Background(), jti, expiresAt)
+}
 
-	return nil
+// RevokeAllForUser revokes every token issued to the user so far. Tokens
+// carry their issue time in microseconds, so a token issued right after,
+// such as on signing in again, stays valid.
+func (tm *JWTTokenManager) RevokeAllForUser(userID string) {
+	now := time.Now()
+	retention := max(tm.revocationTTL, tm.accessTTL, tm.refreshTTL)
+	if err := tm.revocations.RevokeUser(context.Background(), userID, now, now.Add(retention)); err != nil {
+		log.Printf("Failed to revoke tokens of user %s: %v", userID, err)
+	}
 }
 
-func (tm *JWTTokenManager) isTokenRevoked(jti string) bool {
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
+// Introspect describes the token, which is inactive when it does not
+// validate
+func (tm *JWTTokenManager) Introspect(tokenString string) *auth.TokenIntrospection {
+	claims, err := tm.ValidateToken(tokenString)
+	if err != nil {
+		return auth.NewTokenIntrospection(nil)
+	}
+	return auth.NewTokenIntrospection(claims)
+}
 
-	expiresAt, exists := tm.revokedTokens[jti]
-	if !exists {
+// IsRevoked reports whether the token was revoked on its own or with all of
+// its user's tokens. Tokens that do not parse are not revoked, and tokens
+// whose revocation cannot be checked are.
+func (tm *JWTTokenManager) IsRevoked(tokenString string) bool {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return tm.secret, nil
+	})
+	if err != nil {
 		return false
 	}
 
-	// If the revoked token has expired, it's no longer relevant
-	if time.Now().After(expiresAt) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
 		return false
 	}
 
-	return true
+	if jti, ok := claims["jti"].(string); ok {
+		if revoked, err := tm.isTokenRevoked(jti); err != nil || revoked {
+			return true
+		}
+	}
+	userID, _ := claims["user_id"].(string)
+	issuedAt, precision := issuedAtClaim(claims)
+	revoked, err := tm.isUserRevoked(userID, issuedAt, precision)
+	return err != nil || revoked
+}
+
+// tokenID returns the JTI of a token that already validated
+func (tm *JWTTokenManager) tokenID(tokenString string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return ""
+	}
+	jti, _ := claims["jti"].(string)
+	return jti
+}
+
+func (tm *JWTTokenManager) isFamilyRevoked(family string) bool {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	expiresAt, exists := tm.revokedFamilies[family]
+	return exists && time.Now().Before(expiresAt)
 }
 
-func (tm *JWTTokenManager) cleanupExpiredRevokedTokens() {
+// cleanupExpiredRotations forgets exchanged refresh tokens and revoked
+// families once their tokens can no longer validate; the caller must hold
+// the lock
+func (tm *JWTTokenManager) cleanupExpiredRotations() {
 	now := time.Now()
-	for jti, expiresAt := range tm.revokedTokens {
+	for jti, expiresAt := range tm.rotatedTokens {
 		if now.After(expiresAt) {
-			delete(tm.revokedTokens, jti)
+			delete(tm.rotatedTokens, jti)
 		}
 	}
+	for family, expiresAt := range tm.revokedFamilies {
+		if now.After(expiresAt) {
+			delete(tm.revokedFamilies, family)
+		}
+	}
+}
+
+func (tm *JWTTokenManager) isUserRevoked(userID string, issuedAt time.Time, precision time.Duration) (bool, error) {
+	revokedAt, err := tm.revocations.UserRevokedAt(context.Background(), userID)
+	if err != nil {
+		return false, err
+	}
+	return !revokedAt.IsZero() && !issuedAt.After(revokedAt.Truncate(precision)), nil
+}
+
+func (tm *JWTTokenManager) isTokenRevoked(jti string) (bool, error) {
+	return tm.revocations.IsTokenRevoked(context.Background(), jti)
+}
+
+// scopesClaim reads the scopes claim, which JSON decodes as []interface{}
+func scopesClaim(value interface{}) []string {
+	values, _ := value.([]interface{})
+	scopes := make([]string, 0, len(values))
+	for _, value := range values {
+		if scope, ok := value.(string); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil
+	}
+	return scopes
 }
 
+// addAuthentication records the sign-in behind a token in the auth_time and
+// amr claims of OpenID Connect
+func addAuthentication(claims jwt.MapClaims, authentication auth.Authentication) {
+	if authentication.Time.IsZero() {
+		return
+	}
+	claims["auth_time"] = authentication.Time.Unix()
+	if len(authentication.Methods) > 0 {
+		claims["amr"] = authentication.Methods
+	}
+}
+
+// authTimeClaim converts the auth_time claim, which is zero for tokens
+// issued before sign-ins were recorded
+func authTimeClaim(value interface{}) time.Time {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// issuedAtClaim converts the issue time, which is in microseconds in iat_us
+// and in whole seconds for tokens issued before it was added, and returns
+// the precision it has
+func issuedAtClaim(claims jwt.MapClaims) (time.Time, time.Duration) {
+	if micros, ok := claims["iat_us"].(float64); ok {
+		return time.UnixMicro(int64(micros)), time.Microsecond
+	}
+	seconds, _ := claims["iat"].(float64)
+	return time.Unix(int64(seconds), 0), time.Second
+}
+
+// generateJTI returns a unique token ID; the random suffix keeps tokens
+// issued in the same second apart
 func (tm *JWTTokenManager) generateJTI(userID string, issuedAt time.Time, tokenType string) string {
-	return fmt.Sprintf("%s-%s-%d", userID, tokenType, issuedAt.Unix())
+	return fmt.Sprintf("%s-%s-%d-%s", userID, tokenType, issuedAt.Unix(), randomID())
+}
+
+// randomID returns 16 random hex characters
+func randomID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
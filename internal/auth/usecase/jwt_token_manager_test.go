@@ -9,6 +9,8 @@ import (
 
 	"github.com/gentra/decorator-arch-go/internal/auth"
 	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
+	"github.com/gentra/decorator-arch-go/internal/token"
 )
 
 func TestJWTTokenManager_GenerateAuthToken(t *testing.T) {
@@ -452,3 +454,172 @@ func TestJWTTokenManager_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTTokenManager_RevokeAllForUser(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+
+	accessToken, _, err := tokenManager.GenerateAuthToken("user-123", "test@example.com")
+	assert.NoError(t, err)
+	refreshToken, err := tokenManager.GenerateRefreshToken("user-123")
+	assert.NoError(t, err)
+	otherToken, _, err := tokenManager.GenerateAuthToken("user-456", "other@example.com")
+	assert.NoError(t, err)
+
+	// Act
+	tokenManager.RevokeAllForUser("user-123")
+
+	// Assert
+	for _, token := range []string{accessToken, refreshToken} {
+		_, err := tokenManager.ValidateToken(token)
+		assert.Equal(t, auth.ErrInvalidToken, err)
+		assert.True(t, tokenManager.IsRevoked(token))
+	}
+	_, err = tokenManager.ValidateToken(otherToken)
+	assert.NoError(t, err)
+	assert.False(t, tokenManager.IsRevoked(otherToken))
+}
+
+func TestJWTTokenManager_GivenRevokedUser_WhenSigningInAgainRightAway_ThenNewTokenIsValid(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+	tokenManager.RevokeAllForUser("user-123")
+
+	// Act
+	accessToken, _, err := tokenManager.GenerateAuthToken("user-123", "test@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	_, err = tokenManager.ValidateToken(accessToken)
+	assert.NoError(t, err)
+	assert.False(t, tokenManager.IsRevoked(accessToken))
+}
+
+func TestJWTTokenManager_GivenSharedRevocationStore_WhenRevokingOnOneManager_ThenOtherRejectsTokens(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	store := revocationMemory.NewService()
+	first := usecase.NewJWTTokenManagerWithRevocations(secret, time.Hour, 24*time.Hour, store, time.Hour)
+	second := usecase.NewJWTTokenManagerWithRevocations(secret, time.Hour, 24*time.Hour, store, time.Hour)
+
+	revokedToken, _, err := first.GenerateAuthToken("user-123", "test@example.com")
+	require.NoError(t, err)
+	refreshToken, err := first.GenerateRefreshToken("user-456")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, first.RevokeToken(revokedToken))
+	first.RevokeAllForUser("user-456")
+
+	// Assert
+	_, err = second.ValidateToken(revokedToken)
+	assert.Equal(t, auth.ErrInvalidToken, err)
+	assert.True(t, second.IsRevoked(refreshToken))
+	_, err = second.RotateRefreshToken(refreshToken)
+	assert.Error(t, err)
+}
+
+func TestJWTTokenManager_Introspect(t *testing.T) {
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+
+	testCases := []struct {
+		name           string
+		setupToken     func() string
+		expectedActive bool
+	}{
+		{
+			name: "Given valid token, When Introspect is called, Then should describe active token",
+			setupToken: func() string {
+				token, _, _ := tokenManager.GenerateAuthToken("user-123", "test@example.com")
+				return token
+			},
+			expectedActive: true,
+		},
+		{
+			name: "Given revoked token, When Introspect is called, Then should report inactive token",
+			setupToken: func() string {
+				token, _, _ := tokenManager.GenerateAuthToken("user-789", "revoked@example.com")
+				_ = tokenManager.RevokeToken(token)
+				return token
+			},
+			expectedActive: false,
+		},
+		{
+			name: "Given malformed token, When Introspect is called, Then should report inactive token",
+			setupToken: func() string {
+				return "invalid.token.format"
+			},
+			expectedActive: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			token := tt.setupToken()
+
+			// Act
+			introspection := tokenManager.Introspect(token)
+
+			// Assert
+			assert.Equal(t, tt.expectedActive, introspection.Active)
+			if tt.expectedActive {
+				assert.Equal(t, "user-123", introspection.Subject)
+				assert.Equal(t, "test@example.com", introspection.Username)
+				assert.Equal(t, "access", introspection.TokenType)
+				assert.NotZero(t, introspection.ExpiresAt)
+			} else {
+				assert.Equal(t, &auth.TokenIntrospection{Active: false}, introspection)
+			}
+		})
+	}
+}
+
+func TestJWTTokenManager_RotateRefreshToken(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+	refreshToken, err := tokenManager.GenerateRefreshToken("user-123")
+	assert.NoError(t, err)
+
+	// Act
+	first, firstErr := tokenManager.RotateRefreshToken(refreshToken)
+	second, secondErr := tokenManager.RotateRefreshToken(first.RefreshToken)
+	_, reuseErr := tokenManager.RotateRefreshToken(first.RefreshToken)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.NotEqual(t, refreshToken, first.RefreshToken)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, auth.ErrRefreshTokenReused, reuseErr)
+
+	for _, token := range []string{first.Token, second.Token, second.RefreshToken} {
+		_, err := tokenManager.ValidateToken(token)
+		assert.Equal(t, auth.ErrInvalidToken, err, "every token of the family is revoked")
+	}
+}
+
+func TestJWTTokenManager_RotateRefreshToken_KeepsSignIn(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret-key-for-testing")
+	tokenManager := usecase.NewJWTTokenManager(secret, time.Hour, 24*time.Hour)
+	refreshToken, err := tokenManager.GenerateRefreshToken("user-123", token.AMRPassword)
+	require.NoError(t, err)
+	signIn, err := tokenManager.ValidateToken(refreshToken)
+	require.NoError(t, err)
+
+	// Act
+	rotated, err := tokenManager.RotateRefreshToken(refreshToken)
+	require.NoError(t, err)
+	claims, err := tokenManager.ValidateToken(rotated.Token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, signIn.AuthTime.IsZero())
+	assert.True(t, signIn.AuthTime.Equal(claims.AuthTime), "rotation is not a sign-in")
+	assert.Equal(t, []string{token.AMRPassword}, claims.AMR)
+}
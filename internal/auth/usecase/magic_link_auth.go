@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// MagicLinkStrategy is the strategy name magic links authenticate with
+const MagicLinkStrategy = "magic_link"
+
+// MagicLinkPurpose is the purpose magic link tokens are issued and consumed for
+const MagicLinkPurpose = "magic_link"
+
+// DefaultMagicLinkTTL is how long magic links stay valid unless configured
+const DefaultMagicLinkTTL = 15 * time.Minute
+
+// Rate limit patterns registered by NewMagicLinkSenderWithLimits
+const (
+	magicLinkPattern   = "auth:magic_link"
+	magicLinkIPPattern = "auth:magic_link:ip"
+)
+
+// MagicLinkConfig controls the magic links emailed to users
+type MagicLinkConfig struct {
+	TTL time.Duration // Lifetime of magic link tokens, DefaultMagicLinkTTL when zero
+
+	// Magic links requested per email and per client IP when sent with
+	// NewMagicLinkSenderWithLimits; 3 and 20 an hour when zero, like
+	// password reset requests
+	PerEmail ratelimit.RateLimitConfig
+	PerIP    ratelimit.RateLimitConfig
+}
+
+// withDefaults fills in the zero values of the configuration
+func (c MagicLinkConfig) withDefaults() MagicLinkConfig {
+	if c.TTL <= 0 {
+		c.TTL = DefaultMagicLinkTTL
+	}
+	if c.PerEmail.Limit == 0 {
+		c.PerEmail = ratelimit.RateLimitConfig{Limit: 3, Window: time.Hour}
+	}
+	if c.PerIP.Limit == 0 {
+		c.PerIP = ratelimit.RateLimitConfig{Limit: 20, Window: time.Hour}
+	}
+	return c
+}
+
+// MagicLinkAuthStrategy implements auth.Service for passwordless sign-in
+// with the one-time tokens MagicLinkSender emails. Each token signs in once:
+// it is consumed in the token service's revocation store, so a link opened
+// twice or on two instances at once only works the first time. Sessions are
+// recorded as one-time password sign-ins.
+type MagicLinkAuthStrategy struct {
+	userService  user.Service
+	tokenService token.Service
+	tokenManager *JWTTokenManager
+}
+
+// NewMagicLinkAuthStrategy creates a new magic link authentication strategy
+func NewMagicLinkAuthStrategy(userService user.Service, tokenService token.Service, tokenManager *JWTTokenManager) auth.Service {
+	return &MagicLinkAuthStrategy{
+		userService:  userService,
+		tokenService: tokenService,
+		tokenManager: tokenManager,
+	}
+}
+
+// Authenticate handles only "magic_link" strategy
+func (s *MagicLinkAuthStrategy) Authenticate(ctx context.Context, strategy string, credentials interface{}) (*auth.AuthResult, error) {
+	if strategy != MagicLinkStrategy {
+		return nil, auth.ErrUnsupportedStrategy
+	}
+
+	magicLinkCreds, ok := credentials.(auth.MagicLinkCredentials)
+	if !ok {
+		return nil, fmt.Errorf("invalid credentials type for magic link auth")
+	}
+
+	claims, err := s.tokenService.ConsumeOneTimeToken(ctx, magicLinkCreds.Token, MagicLinkPurpose)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenExpired) {
+			return nil, auth.ErrTokenExpired
+		}
+		return nil, auth.ErrInvalidToken
+	}
+
+	userDomainUser, err := s.userService.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	// Links sent before a deactivation stop working with it
+	if !userDomainUser.IsActive() {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(userDomainUser.ID.String(), userDomainUser.Email, token.AMROTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := s.tokenManager.GenerateRefreshToken(userDomainUser.ID.String(), token.AMROTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &auth.AuthResult{
+		User:         convertUserDomainToAuth(userDomainUser),
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		Strategy:     MagicLinkStrategy,
+	}, nil
+}
+
+// ValidateToken delegates to token manager
+func (s *MagicLinkAuthStrategy) ValidateToken(ctx context.Context, token string) (*auth.TokenClaims, error) {
+	return s.tokenManager.ValidateToken(token)
+}
+
+// RefreshToken delegates to token manager
+func (s *MagicLinkAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
+	// Exchange the refresh token for a new one of the same family
+	result, err := s.tokenManager.RotateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = MagicLinkStrategy
+	return result, nil
+}
+
+// RevokeToken delegates to token manager
+func (s *MagicLinkAuthStrategy) RevokeToken(ctx context.Context, token string) error {
+	return s.tokenManager.RevokeToken(token)
+}
+
+// Introspect delegates to token manager
+func (s *MagicLinkAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *MagicLinkAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
+// GetSupportedStrategies returns magic link strategy
+func (s *MagicLinkAuthStrategy) GetSupportedStrategies() []string {
+	return []string{MagicLinkStrategy}
+}
+
+// MagicLinkSender emails magic links that MagicLinkAuthStrategy signs users in with
+type MagicLinkSender struct {
+	userService   user.Service
+	tokenService  token.Service
+	notifications notification.Service
+	rateLimiter   ratelimit.Service // nil sends without limits
+	config        MagicLinkConfig
+}
+
+// NewMagicLinkSender creates a sender of magic links
+func NewMagicLinkSender(userService user.Service, tokenService token.Service, notifications notification.Service, config MagicLinkConfig) *MagicLinkSender {
+	return &MagicLinkSender{
+		userService:   userService,
+		tokenService:  tokenService,
+		notifications: notifications,
+		config:        config.withDefaults(),
+	}
+}
+
+// NewMagicLinkSenderWithLimits creates a sender of magic links that limits
+// requests per email and per client IP, so the emails cannot be used to
+// flood an inbox or to probe for accounts
+func NewMagicLinkSenderWithLimits(userService user.Service, tokenService token.Service, notifications notification.Service, rateLimiter ratelimit.Service, config MagicLinkConfig) (*MagicLinkSender, error) {
+	sender := NewMagicLinkSender(userService, tokenService, notifications, config)
+	limits := map[string]ratelimit.RateLimitConfig{
+		magicLinkPattern:   sender.config.PerEmail,
+		magicLinkIPPattern: sender.config.PerIP,
+	}
+	for pattern, limit := range limits {
+		if err := rateLimiter.SetLimit(context.Background(), pattern, limit); err != nil {
+			return nil, fmt.Errorf("invalid rate limit for %s: %w", pattern, err)
+		}
+	}
+
+	sender.rateLimiter = rateLimiter
+	return sender, nil
+}
+
+// Send emails a magic link to the user with the email. It fails with
+// user.ErrUserNotFound for unknown emails and user.ErrAccountDeactivated
+// for deactivated accounts, which callers should not reveal, and with
+// user.ErrRateLimited once the email or client IP requested too many.
+func (s *MagicLinkSender) Send(ctx context.Context, email string) error {
+	if err := s.allow(ctx, email); err != nil {
+		return err
+	}
+
+	recipient, err := s.findByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if !recipient.IsActive() {
+		return user.ErrAccountDeactivated
+	}
+
+	magicLinkToken, err := s.tokenService.GenerateOneTimeToken(ctx, MagicLinkPurpose, recipient.ID.String(), s.config.TTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	if err := s.notifications.SendMagicLinkEmail(ctx, recipient.Email, magicLinkToken); err != nil {
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+	return nil
+}
+
+// allow checks the per-email limit and, when the caller's IP is known, the
+// per-IP limit. Unknown emails count too, so probing for accounts is limited.
+func (s *MagicLinkSender) allow(ctx context.Context, email string) error {
+	if s.rateLimiter == nil {
+		return nil
+	}
+
+	keys := []string{fmt.Sprintf("%s:%s", magicLinkPattern, strings.ToLower(strings.TrimSpace(email)))}
+	if ip := user.ClientIPFromContext(ctx); ip != "" {
+		keys = append(keys, fmt.Sprintf("%s:%s", magicLinkIPPattern, ip))
+	}
+
+	for _, key := range keys {
+		allowed, err := s.rateLimiter.Allow(ctx, key)
+		if err != nil {
+			return fmt.Errorf("rate limiter error: %w", err)
+		}
+		if !allowed {
+			return user.ErrRateLimited
+		}
+	}
+	return nil
+}
+
+// findByEmail returns the user whose email matches exactly, ignoring case
+func (s *MagicLinkSender) findByEmail(ctx context.Context, email string) (*user.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, user.ErrUserNotFound
+	}
+
+	page, err := s.userService.List(ctx, user.ListFilters{EmailPrefix: email, Limit: user.MaxListLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	for _, candidate := range page.Users {
+		if strings.EqualFold(candidate.Email, email) {
+			return candidate, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
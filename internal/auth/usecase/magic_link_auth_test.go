@@ -0,0 +1,154 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	authmock "github.com/gentra/decorator-arch-go/internal/auth/mock"
+	"github.com/gentra/decorator-arch-go/internal/auth/usecase"
+	"github.com/gentra/decorator-arch-go/internal/notification/dryrun"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	ratelimitMemory "github.com/gentra/decorator-arch-go/internal/ratelimit/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func TestMagicLinkAuthStrategy_Authenticate(t *testing.T) {
+	t.Run("Given an emailed magic link, When Authenticate is called with its token, Then should sign the user in once", func(t *testing.T) {
+		// Arrange
+		tokenService := testkit.NewTokenService(t)
+		tokenManager := newGuestTokenManager()
+		mockUserService := new(authmock.MockUserService)
+		signedIn := testkit.NewUserBuilder().Build()
+		mockUserService.On("List", mock.Anything, mock.MatchedBy(func(filters user.ListFilters) bool {
+			return filters.EmailPrefix == testkit.DefaultEmail
+		})).Return(&user.Page{Users: []*user.User{signedIn}}, nil)
+		mockUserService.On("GetByID", mock.Anything, signedIn.ID.String()).Return(signedIn, nil)
+
+		box := outboxMemory.NewService(10)
+		notifications := dryrun.NewService(notificationMock.NewService(), box, dryrun.Config{Global: true})
+		sender := usecase.NewMagicLinkSender(mockUserService, tokenService, notifications, usecase.MagicLinkConfig{})
+		strategy := usecase.NewMagicLinkAuthStrategy(mockUserService, tokenService, tokenManager)
+		ctx := context.Background()
+
+		require.NoError(t, sender.Send(ctx, strings.ToUpper(testkit.DefaultEmail)))
+		messages, err := box.List(ctx, outbox.Filter{})
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "magic_link_email", messages[0].Kind)
+		assert.Equal(t, testkit.DefaultEmail, messages[0].To)
+		magicLinkToken := messages[0].Body[strings.LastIndex(messages[0].Body, " ")+1:]
+
+		// Act
+		result, err := strategy.Authenticate(ctx, "magic_link", auth.MagicLinkCredentials{Token: magicLinkToken})
+		_, replayErr := strategy.Authenticate(ctx, "magic_link", auth.MagicLinkCredentials{Token: magicLinkToken})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, signedIn.ID.String(), result.User.ID)
+		assert.Equal(t, "magic_link", result.Strategy)
+		assert.NotEmpty(t, result.RefreshToken)
+		claims, err := tokenManager.ValidateToken(result.Token)
+		require.NoError(t, err)
+		assert.Equal(t, []string{token.AMROTP}, claims.AMR)
+		assert.Equal(t, auth.ErrInvalidToken, replayErr)
+	})
+
+	t.Run("Given a token issued for another purpose, When Authenticate is called with it, Then should reject it", func(t *testing.T) {
+		// Arrange
+		tokenService := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		strategy := usecase.NewMagicLinkAuthStrategy(mockUserService, tokenService, newGuestTokenManager())
+		otherToken, err := tokenService.GenerateOneTimeToken(context.Background(), "email_change", testkit.DefaultUserID.String(), time.Minute)
+		require.NoError(t, err)
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "magic_link", auth.MagicLinkCredentials{Token: otherToken})
+
+		// Assert
+		assert.Equal(t, auth.ErrInvalidToken, err)
+		assert.Nil(t, result)
+		mockUserService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a deactivated user, When Authenticate is called with their magic link, Then should return account deactivated error", func(t *testing.T) {
+		// Arrange
+		tokenService := testkit.NewTokenService(t)
+		mockUserService := new(authmock.MockUserService)
+		deactivated := testkit.NewUserBuilder().Build()
+		deactivatedAt := time.Now()
+		deactivated.DeactivatedAt = &deactivatedAt
+		mockUserService.On("GetByID", mock.Anything, deactivated.ID.String()).Return(deactivated, nil)
+		strategy := usecase.NewMagicLinkAuthStrategy(mockUserService, tokenService, newGuestTokenManager())
+		magicLinkToken, err := tokenService.GenerateOneTimeToken(context.Background(), usecase.MagicLinkPurpose, deactivated.ID.String(), time.Minute)
+		require.NoError(t, err)
+
+		// Act
+		result, err := strategy.Authenticate(context.Background(), "magic_link", auth.MagicLinkCredentials{Token: magicLinkToken})
+
+		// Assert
+		assert.Equal(t, user.ErrAccountDeactivated, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestMagicLinkSender_Send(t *testing.T) {
+	t.Run("Given an unknown email, When Send is called, Then should return user not found without emailing", func(t *testing.T) {
+		// Arrange
+		mockUserService := new(authmock.MockUserService)
+		mockUserService.On("List", mock.Anything, mock.Anything).
+			Return(&user.Page{Users: []*user.User{testkit.NewUserBuilder().WithEmail("test@example.com.au").Build()}}, nil)
+		box := outboxMemory.NewService(10)
+		notifications := dryrun.NewService(notificationMock.NewService(), box, dryrun.Config{Global: true})
+		sender := usecase.NewMagicLinkSender(mockUserService, testkit.NewTokenService(t), notifications, usecase.MagicLinkConfig{})
+
+		// Act
+		err := sender.Send(context.Background(), testkit.DefaultEmail)
+
+		// Assert
+		assert.Equal(t, user.ErrUserNotFound, err)
+		messages, err := box.List(context.Background(), outbox.Filter{})
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("Given limits per email and IP, When Send is called beyond them, Then should return rate limited without emailing", func(t *testing.T) {
+		// Arrange
+		mockUserService := new(authmock.MockUserService)
+		mockUserService.On("List", mock.Anything, mock.Anything).
+			Return(&user.Page{Users: []*user.User{testkit.NewUserBuilder().Build()}}, nil)
+		box := outboxMemory.NewService(10)
+		notifications := dryrun.NewService(notificationMock.NewService(), box, dryrun.Config{Global: true})
+		sender, err := usecase.NewMagicLinkSenderWithLimits(mockUserService, testkit.NewTokenService(t), notifications, ratelimitMemory.NewService(nil), usecase.MagicLinkConfig{
+			PerEmail: ratelimit.RateLimitConfig{Limit: 2, Window: time.Hour},
+			PerIP:    ratelimit.RateLimitConfig{Limit: 3, Window: time.Hour},
+		})
+		require.NoError(t, err)
+		ctx := user.WithClientIP(context.Background(), "203.0.113.7")
+
+		// Act
+		require.NoError(t, sender.Send(ctx, testkit.DefaultEmail))
+		require.NoError(t, sender.Send(ctx, strings.ToUpper(testkit.DefaultEmail)))
+		perEmailErr := sender.Send(ctx, testkit.DefaultEmail)
+		assert.Equal(t, user.ErrUserNotFound, sender.Send(ctx, "nobody@example.com"))
+		perIPErr := sender.Send(ctx, "someone@example.com")
+
+		// Assert
+		assert.ErrorIs(t, perEmailErr, user.ErrRateLimited)
+		assert.ErrorIs(t, perIPErr, user.ErrRateLimited)
+		messages, err := box.List(context.Background(), outbox.Filter{})
+		require.NoError(t, err)
+		assert.Len(t, messages, 2)
+	})
+}
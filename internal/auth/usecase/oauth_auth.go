@@ -51,35 +51,13 @@ func (s *OAuthAuthStrategy) ValidateToken(ctx context.Context, token string) (*a
 
 // RefreshToken delegates to token manager
 func (s *OAuthAuthStrategy) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResult, error) {
-	// Validate refresh token
-	claims, err := s.tokenManager.ValidateToken(refreshToken)
+	// Exchange the refresh token for a new one of the same family
+	result, err := s.tokenManager.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, err
 	}
-
-	if !claims.IsRefreshToken() {
-		return nil, auth.ErrInvalidRefreshToken
-	}
-
-	// Generate new access token
-	accessToken, expiresAt, err := s.tokenManager.GenerateAuthToken(claims.UserID, claims.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Create user from claims
-	authUser := &auth.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-	}
-
-	return &auth.AuthResult{
-		User:         authUser,
-		Token:        accessToken,
-		RefreshToken: refreshToken, // Keep the same refresh token
-		ExpiresAt:    expiresAt,
-		Strategy:     "oauth",
-	}, nil
+	result.Strategy = "oauth"
+	return result, nil
 }
 
 // RevokeToken delegates to token manager
@@ -87,6 +65,17 @@ func (s *OAuthAuthStrategy) RevokeToken(ctx context.Context, token string) error
 	return s.tokenManager.RevokeToken(token)
 }
 
+// Introspect delegates to token manager
+func (s *OAuthAuthStrategy) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	return s.tokenManager.Introspect(token), nil
+}
+
+// RevokeAllForUser delegates to token manager
+func (s *OAuthAuthStrategy) RevokeAllForUser(ctx context.Context, userID string) error {
+	s.tokenManager.RevokeAllForUser(userID)
+	return nil
+}
+
 // GetSupportedStrategies returns oauth strategy
 func (s *OAuthAuthStrategy) GetSupportedStrategies() []string {
 	return []string{"oauth"}
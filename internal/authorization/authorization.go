@@ -0,0 +1,146 @@
+package authorization
+
+import "context"
+
+// Service defines the authorization domain interface - the ONLY interface in this domain
+// Implementations are policy engines deciding whether a subject may perform an
+// action on a resource; RBAC is the built-in engine, others can be plugged in
+type Service interface {
+	// Authorize returns nil when the request is allowed, ErrUnauthenticated when
+	// there is no subject and ErrPermissionDenied when the policy denies it
+	Authorize(ctx context.Context, req Request) error
+}
+
+// Domain types and models
+
+// Subject is the caller performing an action
+type Subject struct {
+	ID             string   `json:"id"`
+	Roles          []string `json:"roles,omitempty"`
+	OrganizationID string   `json:"organization_id,omitempty"`
+}
+
+// Resource is the object an action is performed on
+type Resource struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id,omitempty"`
+}
+
+// Request asks whether a subject may perform an action on a resource
+type Request struct {
+	Subject  Subject  `json:"subject"`
+	Action   string   `json:"action"`
+	Resource Resource `json:"resource"`
+}
+
+// Roles
+const (
+	RoleUser     = "user"
+	RoleOrgAdmin = "org_admin" // Administers the users of the subject's organization
+	RoleAdmin    = "admin"     // Platform administrator
+)
+
+// Actions on users; each is a permission that can be granted to a role
+const (
+	ActionUserUpdateProfile     = "user:update_profile"
+	ActionUserChangePassword    = "user:change_password"
+	ActionUserUpdatePreferences = "user:update_preferences"
+	ActionUserDeactivate        = "user:deactivate"
+	ActionUserDelete            = "user:delete"
+	ActionUserExportData        = "user:export_data"
+	ActionUserErase             = "user:erase"
+
+	// ActionUserList lists and searches across all users
+	ActionUserList = "user:list"
+
+	// ActionUserCleanupPreferences runs the stale preference cleanup across all users
+	ActionUserCleanupPreferences = "user:cleanup_preferences"
+
+	// ActionUserSetFeatureFlags switches a user's feature flags; owners are not
+	// granted it, so users cannot opt themselves into gated features
+	ActionUserSetFeatureFlags = "user:set_feature_flags"
+
+	// ActionAll grants every action when given to a role
+	ActionAll = "*"
+)
+
+// Resource types
+const (
+	ResourceTypeUser = "user"
+)
+
+// impersonationActions may be granted to an admin acting as another user.
+// Passwords, emails, data exports and account removal stay with the user.
+var impersonationActions = map[string]bool{
+	ActionUserUpdateProfile:     true,
+	ActionUserUpdatePreferences: true,
+	ActionUserDeactivate:        true,
+}
+
+// IsImpersonationAction reports whether an admin may be granted the action
+// while impersonating a user
+func IsImpersonationAction(action string) bool {
+	return impersonationActions[action]
+}
+
+// HasRole reports whether the subject holds the role
+func (s Subject) HasRole(role string) bool {
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAuthenticated reports whether the subject identifies a caller
+func (s Subject) IsAuthenticated() bool {
+	return s.ID != ""
+}
+
+// IsOwner reports whether the subject owns the resource
+func (r Request) IsOwner() bool {
+	return r.Subject.IsAuthenticated() && r.Subject.ID == r.Resource.OwnerID
+}
+
+// Context keys for the authenticated subject
+type contextKey string
+
+const (
+	SubjectContextKey contextKey = "authorization_subject"
+)
+
+// WithSubject stores the authenticated caller in the context
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, SubjectContextKey, subject)
+}
+
+// SubjectFromContext returns the authenticated caller, if any
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	subject, ok := ctx.Value(SubjectContextKey).(Subject)
+	return subject, ok && subject.IsAuthenticated()
+}
+
+// AuthorizationError represents domain-specific authorization errors
+type AuthorizationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Action  string `json:"action,omitempty"`
+}
+
+func (e AuthorizationError) Error() string {
+	return e.Message
+}
+
+// Is matches authorization errors by code so detailed errors match their sentinel
+func (e AuthorizationError) Is(target error) bool {
+	t, ok := target.(AuthorizationError)
+	return ok && t.Code == e.Code
+}
+
+// Common authorization error codes
+var (
+	ErrUnauthenticated  = AuthorizationError{Code: "UNAUTHENTICATED", Message: "Authentication required"}
+	ErrPermissionDenied = AuthorizationError{Code: "PERMISSION_DENIED", Message: "Permission denied"}
+)
@@ -0,0 +1,41 @@
+package authorization_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+)
+
+func TestSubjectFromContext(t *testing.T) {
+	t.Run("Given context with subject, When reading, Then returns subject", func(t *testing.T) {
+		subject := authorization.Subject{ID: "user-1", Roles: []string{authorization.RoleUser}}
+		ctx := authorization.WithSubject(context.Background(), subject)
+
+		got, ok := authorization.SubjectFromContext(ctx)
+
+		assert.True(t, ok)
+		assert.Equal(t, subject, got)
+		assert.True(t, got.HasRole(authorization.RoleUser))
+		assert.False(t, got.HasRole(authorization.RoleAdmin))
+	})
+
+	t.Run("Given subject without ID, When reading, Then reports no subject", func(t *testing.T) {
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{Roles: []string{authorization.RoleAdmin}})
+
+		_, ok := authorization.SubjectFromContext(ctx)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestAuthorizationError_Is(t *testing.T) {
+	t.Run("Given detailed denial, When matching sentinel, Then matches by code", func(t *testing.T) {
+		err := authorization.AuthorizationError{Code: "PERMISSION_DENIED", Message: "Permission denied for user:update_profile"}
+
+		assert.ErrorIs(t, err, authorization.ErrPermissionDenied)
+		assert.NotErrorIs(t, err, authorization.ErrUnauthenticated)
+	})
+}
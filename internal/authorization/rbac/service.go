@@ -0,0 +1,94 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+)
+
+// Config maps roles and ownership to the actions they grant
+type Config struct {
+	// RolePermissions grants actions on any resource to holders of a role;
+	// authorization.ActionAll grants every action
+	RolePermissions map[string][]string
+
+	// OwnerPermissions are granted to a subject on resources it owns
+	OwnerPermissions []string
+}
+
+// DefaultConfig lets users manage their own profile, password, preferences, account and
+// personal data and administrators manage everyone's
+func DefaultConfig() Config {
+	return Config{
+		RolePermissions: map[string][]string{
+			authorization.RoleAdmin: {authorization.ActionAll},
+		},
+		OwnerPermissions: []string{
+			authorization.ActionUserUpdateProfile,
+			authorization.ActionUserChangePassword,
+			authorization.ActionUserUpdatePreferences,
+			authorization.ActionUserDeactivate,
+			authorization.ActionUserDelete,
+			authorization.ActionUserExportData,
+			authorization.ActionUserErase,
+		},
+	}
+}
+
+// service implements authorization.Service with role-based access control
+type service struct {
+	roles map[string]map[string]bool
+	owner map[string]bool
+}
+
+// NewService creates a new RBAC policy engine
+func NewService(config Config) authorization.Service {
+	roles := make(map[string]map[string]bool, len(config.RolePermissions))
+	for role, actions := range config.RolePermissions {
+		roles[role] = toSet(actions)
+	}
+
+	return &service{
+		roles: roles,
+		owner: toSet(config.OwnerPermissions),
+	}
+}
+
+// Authorize allows the request when the subject owns the resource and owners may
+// perform the action, or when one of the subject's roles grants it
+func (s *service) Authorize(ctx context.Context, req authorization.Request) error {
+	if !req.Subject.IsAuthenticated() {
+		return authorization.ErrUnauthenticated
+	}
+
+	if req.IsOwner() && grants(s.owner, req.Action) {
+		return nil
+	}
+
+	for _, role := range req.Subject.Roles {
+		if grants(s.roles[role], req.Action) {
+			return nil
+		}
+	}
+
+	return authorization.AuthorizationError{
+		Code:    authorization.ErrPermissionDenied.Code,
+		Message: fmt.Sprintf("Permission denied for %s", req.Action),
+		Action:  req.Action,
+	}
+}
+
+// Helper methods
+
+func grants(actions map[string]bool, action string) bool {
+	return actions[authorization.ActionAll] || actions[action]
+}
+
+func toSet(actions []string) map[string]bool {
+	set := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		set[action] = true
+	}
+	return set
+}
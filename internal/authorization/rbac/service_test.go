@@ -0,0 +1,86 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/authorization/rbac"
+)
+
+func TestRBAC_Authorize(t *testing.T) {
+	owner := authorization.Resource{Type: authorization.ResourceTypeUser, ID: "user-1", OwnerID: "user-1"}
+
+	tests := []struct {
+		name        string
+		config      rbac.Config
+		request     authorization.Request
+		expectedErr error
+	}{
+		{
+			name:   "Given owner, When updating own profile, Then allows",
+			config: rbac.DefaultConfig(),
+			request: authorization.Request{
+				Subject:  authorization.Subject{ID: "user-1", Roles: []string{authorization.RoleUser}},
+				Action:   authorization.ActionUserUpdateProfile,
+				Resource: owner,
+			},
+		},
+		{
+			name:   "Given another user, When updating profile, Then denies",
+			config: rbac.DefaultConfig(),
+			request: authorization.Request{
+				Subject:  authorization.Subject{ID: "user-2", Roles: []string{authorization.RoleUser}},
+				Action:   authorization.ActionUserUpdateProfile,
+				Resource: owner,
+			},
+			expectedErr: authorization.ErrPermissionDenied,
+		},
+		{
+			name:   "Given admin, When updating another user's preferences, Then allows",
+			config: rbac.DefaultConfig(),
+			request: authorization.Request{
+				Subject:  authorization.Subject{ID: "admin-1", Roles: []string{authorization.RoleAdmin}},
+				Action:   authorization.ActionUserUpdatePreferences,
+				Resource: owner,
+			},
+		},
+		{
+			name:   "Given anonymous subject, When authorizing, Then requires authentication",
+			config: rbac.DefaultConfig(),
+			request: authorization.Request{
+				Action:   authorization.ActionUserUpdateProfile,
+				Resource: owner,
+			},
+			expectedErr: authorization.ErrUnauthenticated,
+		},
+		{
+			name: "Given role granting a single action, When performing another, Then denies",
+			config: rbac.Config{RolePermissions: map[string][]string{
+				"support": {authorization.ActionUserUpdatePreferences},
+			}},
+			request: authorization.Request{
+				Subject:  authorization.Subject{ID: "support-1", Roles: []string{"support"}},
+				Action:   authorization.ActionUserUpdateProfile,
+				Resource: owner,
+			},
+			expectedErr: authorization.ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := rbac.NewService(tt.config)
+
+			err := service.Authorize(context.Background(), tt.request)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package captcha
+
+import (
+	"context"
+)
+
+// Service defines the captcha domain interface - the ONLY interface in this domain
+// It checks the token a client got from solving a captcha widget with the
+// provider that issued it, so bots can be told apart from people before
+// they create accounts or guess passwords.
+type Service interface {
+	// Verify returns nil when the provider confirms the token. remoteIP is
+	// the caller's address, passed on to the provider when known.
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Supported providers
+const (
+	ProviderRecaptcha = "recaptcha"
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderTurnstile = "turnstile"
+	ProviderNoop      = "noop" // Accepts every token; for tests and local development
+)
+
+// CaptchaError represents domain-specific captcha errors
+type CaptchaError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e CaptchaError) Error() string {
+	return e.Message
+}
+
+// Common captcha error codes
+var (
+	ErrCaptchaRequired    = CaptchaError{Code: "CAPTCHA_REQUIRED", Message: "Complete the captcha to continue"}
+	ErrCaptchaInvalid     = CaptchaError{Code: "CAPTCHA_INVALID", Message: "The captcha was not solved or has expired"}
+	ErrCaptchaUnavailable = CaptchaError{Code: "CAPTCHA_UNAVAILABLE", Message: "The captcha could not be checked, try again later"}
+	ErrUnknownProvider    = CaptchaError{Code: "UNKNOWN_PROVIDER", Message: "Unknown captcha provider"}
+)
@@ -0,0 +1,63 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/hcaptcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/noop"
+	"github.com/gentra/decorator-arch-go/internal/captcha/recaptcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+	"github.com/gentra/decorator-arch-go/internal/captcha/turnstile"
+)
+
+// Config contains all configuration for building the captcha service
+type Config struct {
+	// Provider configuration
+	Provider string // "recaptcha", "hcaptcha", "turnstile", "noop"
+
+	// Provider settings; unused by the noop provider
+	SiteVerify siteverify.Config
+}
+
+// CaptchaServiceFactory creates the captcha service for the configured provider
+type CaptchaServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new captcha service factory with the given configuration
+func NewFactory(config Config) *CaptchaServiceFactory {
+	return &CaptchaServiceFactory{
+		config: config,
+	}
+}
+
+// Build returns the captcha service based on configuration
+func (f *CaptchaServiceFactory) Build() (captcha.Service, error) {
+	var newService func(siteverify.Config) captcha.Service
+	switch f.config.Provider {
+	case captcha.ProviderRecaptcha:
+		newService = recaptcha.NewService
+	case captcha.ProviderHCaptcha:
+		newService = hcaptcha.NewService
+	case captcha.ProviderTurnstile:
+		newService = turnstile.NewService
+	case captcha.ProviderNoop:
+		return noop.NewService(), nil
+	default:
+		return nil, fmt.Errorf("%w %q", captcha.ErrUnknownProvider, f.config.Provider)
+	}
+
+	if f.config.SiteVerify.Secret == "" {
+		return nil, fmt.Errorf("secret is required for captcha provider %q", f.config.Provider)
+	}
+	return newService(f.config.SiteVerify), nil
+}
+
+// DefaultConfig returns a configuration accepting every token, for tests
+// and local development
+func DefaultConfig() Config {
+	return Config{
+		Provider: captcha.ProviderNoop,
+	}
+}
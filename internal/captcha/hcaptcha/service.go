@@ -0,0 +1,17 @@
+package hcaptcha
+
+import (
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+)
+
+// DefaultURL is hCaptcha's siteverify endpoint
+const DefaultURL = "https://api.hcaptcha.com/siteverify"
+
+// NewService creates a captcha service verifying hCaptcha tokens
+func NewService(config siteverify.Config) captcha.Service {
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	return siteverify.NewService(config)
+}
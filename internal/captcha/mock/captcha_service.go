@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCaptchaService is a mock implementation of captcha.Service
+type MockCaptchaService struct {
+	mock.Mock
+}
+
+// Verify mocks the Verify method
+func (m *MockCaptchaService) Verify(ctx context.Context, token, remoteIP string) error {
+	args := m.Called(ctx, token, remoteIP)
+	return args.Error(0)
+}
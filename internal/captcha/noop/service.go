@@ -0,0 +1,22 @@
+package noop
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+)
+
+// service implements captcha.Service without a provider
+// It accepts every token, including none, so tests and local development
+// can run the captcha layer without solving captchas.
+type service struct{}
+
+// NewService creates a captcha service that accepts every token
+func NewService() captcha.Service {
+	return &service{}
+}
+
+// Verify always succeeds
+func (s *service) Verify(ctx context.Context, token, remoteIP string) error {
+	return nil
+}
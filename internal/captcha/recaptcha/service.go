@@ -0,0 +1,18 @@
+package recaptcha
+
+import (
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+)
+
+// DefaultURL is Google reCAPTCHA's siteverify endpoint
+const DefaultURL = "https://www.google.com/recaptcha/api/siteverify"
+
+// NewService creates a captcha service verifying reCAPTCHA v2 and v3
+// tokens. v3 tokens below config.MinScore are rejected; Google suggests 0.5.
+func NewService(config siteverify.Config) captcha.Service {
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	return siteverify.NewService(config)
+}
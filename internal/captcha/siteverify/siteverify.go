@@ -0,0 +1,119 @@
+package siteverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+)
+
+// DefaultHTTPTimeout bounds calls to the provider when the config has no HTTP client
+const DefaultHTTPTimeout = 5 * time.Second
+
+// Config configures a provider speaking the siteverify protocol, which
+// reCAPTCHA, hCaptcha and Turnstile share
+type Config struct {
+	Secret string // Secret key issued with the site key the widget uses
+	URL    string // siteverify endpoint; the provider packages fill in their own
+
+	// Hostname, when set, must match the site the captcha was solved on
+	Hostname string
+
+	// MinScore rejects reCAPTCHA v3 tokens scored below it; zero accepts any
+	// score, and providers that send no score are not affected
+	MinScore float64
+
+	// HTTPClient makes the calls to the provider; nil uses a client with DefaultHTTPTimeout
+	HTTPClient *http.Client
+}
+
+// response is the siteverify response body
+type response struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+	Hostname   string   `json:"hostname"`
+	Score      *float64 `json:"score"`
+}
+
+// service implements captcha.Service over the siteverify protocol
+// It posts the token, the secret and the caller's IP to the provider and
+// accepts the token when the provider reports success for the expected
+// hostname and, for scored tokens, a high enough score. A provider that
+// cannot be reached yields captcha.ErrCaptchaUnavailable.
+type service struct {
+	config Config
+	client *http.Client
+}
+
+// NewService creates a captcha service for the provider at config.URL
+func NewService(config Config) captcha.Service {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+
+	return &service{
+		config: config,
+		client: client,
+	}
+}
+
+// Verify asks the provider whether the token is valid
+func (s *service) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return captcha.ErrCaptchaRequired
+	}
+
+	form := url.Values{"secret": {s.config.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create siteverify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", captcha.ErrCaptchaUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: siteverify returned %s", captcha.ErrCaptchaUnavailable, resp.Status)
+	}
+
+	var result response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: failed to decode siteverify response: %v", captcha.ErrCaptchaUnavailable, err)
+	}
+
+	return s.check(result)
+}
+
+// check decides whether a siteverify response accepts the token
+func (s *service) check(result response) error {
+	for _, code := range result.ErrorCodes {
+		if code == "missing-input-secret" || code == "invalid-input-secret" {
+			// The site is misconfigured; the caller did nothing wrong
+			return fmt.Errorf("%w: siteverify rejected the secret: %s", captcha.ErrCaptchaUnavailable, code)
+		}
+	}
+	if !result.Success {
+		return captcha.ErrCaptchaInvalid
+	}
+	if s.config.Hostname != "" && !strings.EqualFold(result.Hostname, s.config.Hostname) {
+		return captcha.ErrCaptchaInvalid
+	}
+	if result.Score != nil && *result.Score < s.config.MinScore {
+		return captcha.ErrCaptchaInvalid
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package siteverify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+)
+
+// newProvider serves a siteverify endpoint answering with body and records
+// the form it was sent
+func newProvider(t *testing.T, status int, body map[string]interface{}) (*httptest.Server, *http.Request) {
+	t.Helper()
+	received := &http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		*received = *r
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestVerify_GivenProviderResponse_WhenVerifying_ThenAcceptsOnlyConfirmedTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        map[string]interface{}
+		config      siteverify.Config
+		expectedErr error
+	}{
+		{
+			name:   "Given success, When verifying, Then accepts the token",
+			status: http.StatusOK,
+			body:   map[string]interface{}{"success": true, "hostname": "app.example.com"},
+		},
+		{
+			name:        "Given failure, When verifying, Then returns invalid captcha",
+			status:      http.StatusOK,
+			body:        map[string]interface{}{"success": false, "error-codes": []string{"invalid-input-response"}},
+			expectedErr: captcha.ErrCaptchaInvalid,
+		},
+		{
+			name:        "Given another hostname, When verifying, Then returns invalid captcha",
+			status:      http.StatusOK,
+			body:        map[string]interface{}{"success": true, "hostname": "evil.example.com"},
+			config:      siteverify.Config{Hostname: "app.example.com"},
+			expectedErr: captcha.ErrCaptchaInvalid,
+		},
+		{
+			name:        "Given a score below the minimum, When verifying, Then returns invalid captcha",
+			status:      http.StatusOK,
+			body:        map[string]interface{}{"success": true, "score": 0.1},
+			config:      siteverify.Config{MinScore: 0.5},
+			expectedErr: captcha.ErrCaptchaInvalid,
+		},
+		{
+			name:        "Given a rejected secret, When verifying, Then returns unavailable",
+			status:      http.StatusOK,
+			body:        map[string]interface{}{"success": false, "error-codes": []string{"invalid-input-secret"}},
+			expectedErr: captcha.ErrCaptchaUnavailable,
+		},
+		{
+			name:        "Given a provider error, When verifying, Then returns unavailable",
+			status:      http.StatusBadGateway,
+			body:        map[string]interface{}{},
+			expectedErr: captcha.ErrCaptchaUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server, received := newProvider(t, tt.status, tt.body)
+			config := tt.config
+			config.URL = server.URL
+			config.Secret = "secret"
+			service := siteverify.NewService(config)
+
+			// Act
+			err := service.Verify(context.Background(), "token", "203.0.113.7")
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "secret", received.PostForm.Get("secret"))
+			assert.Equal(t, "token", received.PostForm.Get("response"))
+			assert.Equal(t, "203.0.113.7", received.PostForm.Get("remoteip"))
+		})
+	}
+}
+
+func TestVerify_GivenNoToken_WhenVerifying_ThenRequiresCaptchaWithoutCallingProvider(t *testing.T) {
+	// Arrange
+	service := siteverify.NewService(siteverify.Config{URL: "http://127.0.0.1:0"})
+
+	// Act
+	err := service.Verify(context.Background(), "", "")
+
+	// Assert
+	assert.ErrorIs(t, err, captcha.ErrCaptchaRequired)
+}
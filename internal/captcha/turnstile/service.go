@@ -0,0 +1,17 @@
+package turnstile
+
+import (
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/captcha/siteverify"
+)
+
+// DefaultURL is Cloudflare Turnstile's siteverify endpoint
+const DefaultURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+// NewService creates a captcha service verifying Cloudflare Turnstile tokens
+func NewService(config siteverify.Config) captcha.Service {
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	return siteverify.NewService(config)
+}
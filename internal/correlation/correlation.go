@@ -0,0 +1,85 @@
+// Package correlation carries the correlation and causation IDs of the work
+// a context belongs to. The correlation ID is shared by everything one
+// request set off, across events and the handlers they trigger; the
+// causation ID names the request or event that directly caused the work.
+// Events, audit entries and log lines take them from the context, so a
+// request can be followed through every domain it touches.
+package correlation
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const (
+	correlationKey contextKey = iota
+	causationKey
+)
+
+// NewID returns a new random ID for a correlation root
+func NewID() string {
+	return uuid.New().String()
+}
+
+// WithCorrelationID adds the correlation ID to the context
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationKey, correlationID)
+}
+
+// WithCausationID adds the ID of the request or event causing the work to
+// the context
+func WithCausationID(ctx context.Context, causationID string) context.Context {
+	return context.WithValue(ctx, causationKey, causationID)
+}
+
+// WithIDs adds both IDs to the context
+func WithIDs(ctx context.Context, correlationID, causationID string) context.Context {
+	return WithCausationID(WithCorrelationID(ctx, correlationID), causationID)
+}
+
+// CorrelationID returns the correlation ID of the context, empty without one
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationKey).(string)
+	return correlationID
+}
+
+// CausationID returns the causation ID of the context, empty without one
+func CausationID(ctx context.Context) string {
+	causationID, _ := ctx.Value(causationKey).(string)
+	return causationID
+}
+
+// Caused returns a context for work caused by the event with the ID, in the
+// event's correlation; an event without correlation ID starts its own
+func Caused(ctx context.Context, eventID, correlationID string) context.Context {
+	if correlationID == "" {
+		correlationID = eventID
+	}
+	return WithIDs(ctx, correlationID, eventID)
+}
+
+// Fields formats the IDs of the context as log fields, empty without them
+func Fields(ctx context.Context) string {
+	correlationID, causationID := CorrelationID(ctx), CausationID(ctx)
+	switch {
+	case correlationID == "" && causationID == "":
+		return ""
+	case causationID == "" || causationID == correlationID:
+		return fmt.Sprintf("correlation_id=%s", correlationID)
+	default:
+		return fmt.Sprintf("correlation_id=%s causation_id=%s", correlationID, causationID)
+	}
+}
+
+// Logf logs like log.Printf, followed by the log fields of the context
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	if fields := Fields(ctx); fields != "" {
+		format += " [" + fields + "]"
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,76 @@
+package correlation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+)
+
+func TestCaused_GivenEvent_WhenDerivingContext_ThenEventIsCauseInItsCorrelation(t *testing.T) {
+	tests := []struct {
+		name                string
+		correlationID       string
+		expectedCorrelation string
+	}{
+		{
+			name:                "Given an event with a correlation ID, When deriving the context, Then keeps its correlation",
+			correlationID:       "request-1",
+			expectedCorrelation: "request-1",
+		},
+		{
+			name:                "Given an event without correlation ID, When deriving the context, Then starts one at the event",
+			expectedCorrelation: "event-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := correlation.WithIDs(context.Background(), "other", "other")
+
+			// Act
+			caused := correlation.Caused(ctx, "event-1", tt.correlationID)
+
+			// Assert
+			assert.Equal(t, tt.expectedCorrelation, correlation.CorrelationID(caused))
+			assert.Equal(t, "event-1", correlation.CausationID(caused))
+		})
+	}
+}
+
+func TestFields_GivenContextIDs_WhenFormatting_ThenListsDistinctIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{
+			name:     "Given no IDs, When formatting, Then returns nothing",
+			ctx:      context.Background(),
+			expected: "",
+		},
+		{
+			name:     "Given the same correlation and causation, When formatting, Then lists the correlation once",
+			ctx:      correlation.WithIDs(context.Background(), "request-1", "request-1"),
+			expected: "correlation_id=request-1",
+		},
+		{
+			name:     "Given different IDs, When formatting, Then lists both",
+			ctx:      correlation.WithIDs(context.Background(), "request-1", "event-1"),
+			expected: "correlation_id=request-1 causation_id=event-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			fields := correlation.Fields(tt.ctx)
+
+			// Assert
+			assert.Equal(t, tt.expected, fields)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package deadletter
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Service defines the dead-letter domain interface - the ONLY interface in this domain.
+// It keeps the events a handler kept failing on after its retries, or
+// panicked on, with why they failed, until an administrator re-drives or
+// discards them.
+type Service interface {
+	// Add records a dead-lettered event and returns it with its ID assigned
+	Add(ctx context.Context, letter Letter) (*Letter, error)
+
+	// List returns dead-lettered events, oldest first
+	List(ctx context.Context, filter Filter) ([]Letter, error)
+
+	// Get returns a dead-lettered event, or ErrLetterNotFound
+	Get(ctx context.Context, id string) (*Letter, error)
+
+	// Remove deletes a dead-lettered event once it was re-driven or
+	// discarded, or returns ErrLetterNotFound
+	Remove(ctx context.Context, id string) error
+
+	// Depth returns how many dead-lettered events are waiting
+	Depth(ctx context.Context) (int, error)
+}
+
+// Domain types and data structures
+
+// Letter is an event a handler failed to process, with the failure
+type Letter struct {
+	ID    string       `json:"id"`
+	Event events.Event `json:"event"`
+
+	// Where the event failed: the event provider, the subscription and the
+	// handler's type
+	Provider     string `json:"provider"`
+	Subscription string `json:"subscription,omitempty"`
+	Handler      string `json:"handler,omitempty"`
+
+	// Why it failed: the last error, the attempts made and whether the
+	// handler panicked, which dead-letters an event without retries
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+	Poison   bool   `json:"poison,omitempty"`
+
+	FirstFailedAt  time.Time `json:"first_failed_at"`
+	DeadLetteredAt time.Time `json:"dead_lettered_at"`
+}
+
+// Filter narrows the letters returned by List
+type Filter struct {
+	EventType string `json:"event_type,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+// Matches reports whether the letter satisfies the filter
+func (f Filter) Matches(letter Letter) bool {
+	if f.EventType != "" && f.EventType != letter.Event.Type {
+		return false
+	}
+	return f.Provider == "" || f.Provider == letter.Provider
+}
+
+// DeadLetterError represents dead-letter domain errors
+type DeadLetterError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e DeadLetterError) Error() string {
+	return e.Message
+}
+
+// Common dead-letter errors
+var (
+	ErrLetterNotFound = DeadLetterError{Code: "DEAD_LETTER_NOT_FOUND", Message: "Dead-lettered event not found"}
+)
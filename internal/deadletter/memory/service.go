@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+)
+
+// service implements deadletter.Service interface in memory, for single
+// instances and tests
+type service struct {
+	mu      sync.RWMutex
+	letters []deadletter.Letter
+}
+
+// NewService creates an empty in-memory dead-letter queue
+func NewService() deadletter.Service {
+	return &service{}
+}
+
+// Add stores a letter, giving it an ID and times when it has none
+func (s *service) Add(ctx context.Context, letter deadletter.Letter) (*deadletter.Letter, error) {
+	if letter.ID == "" {
+		letter.ID = uuid.New().String()
+	}
+	if letter.DeadLetteredAt.IsZero() {
+		letter.DeadLetteredAt = time.Now()
+	}
+	if letter.FirstFailedAt.IsZero() {
+		letter.FirstFailedAt = letter.DeadLetteredAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, letter)
+	return &letter, nil
+}
+
+// List returns matching letters, oldest first
+func (s *service) List(ctx context.Context, filter deadletter.Filter) ([]deadletter.Letter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []deadletter.Letter{}
+	for _, letter := range s.letters {
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+		if filter.Matches(letter) {
+			result = append(result, letter)
+		}
+	}
+	return result, nil
+}
+
+// Get returns a letter
+func (s *service) Get(ctx context.Context, id string) (*deadletter.Letter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, letter := range s.letters {
+		if letter.ID == id {
+			return &letter, nil
+		}
+	}
+	return nil, deadletter.ErrLetterNotFound
+}
+
+// Remove deletes a letter
+func (s *service) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, letter := range s.letters {
+		if letter.ID == id {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			return nil
+		}
+	}
+	return deadletter.ErrLetterNotFound
+}
+
+// Depth returns how many letters are stored
+func (s *service) Depth(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.letters), nil
+}
@@ -0,0 +1,112 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func letterFor(eventType, provider string) deadletter.Letter {
+	return deadletter.Letter{
+		Event:    *testkit.NewEventBuilder().WithType(eventType).Build(),
+		Provider: provider,
+		Error:    "handler unavailable",
+		Attempts: 4,
+	}
+}
+
+func TestAdd_GivenLetter_WhenAdding_ThenAssignsIDAndTimes(t *testing.T) {
+	// Arrange
+	queue := memory.NewService()
+
+	// Act
+	added, err := queue.Add(context.Background(), letterFor(events.EventTypeUserRegistered, "memory"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.False(t, added.DeadLetteredAt.IsZero())
+	assert.Equal(t, added.DeadLetteredAt, added.FirstFailedAt)
+	stored, err := queue.Get(context.Background(), added.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *added, *stored)
+}
+
+func TestList_GivenFilter_WhenListing_ThenReturnsMatchingLettersOldestFirst(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        deadletter.Filter
+		expectedTypes []string
+	}{
+		{
+			name:          "Given no filter, When listing, Then returns every letter",
+			expectedTypes: []string{events.EventTypeUserRegistered, events.EventTypeUserUpdated, events.EventTypeUserRegistered},
+		},
+		{
+			name:          "Given an event type, When listing, Then returns only that type",
+			filter:        deadletter.Filter{EventType: events.EventTypeUserUpdated},
+			expectedTypes: []string{events.EventTypeUserUpdated},
+		},
+		{
+			name:          "Given a provider and a limit, When listing, Then returns the oldest matching letters",
+			filter:        deadletter.Filter{Provider: "memory", Limit: 1},
+			expectedTypes: []string{events.EventTypeUserRegistered},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			queue := memory.NewService()
+			for _, letter := range []deadletter.Letter{
+				letterFor(events.EventTypeUserRegistered, "memory"),
+				letterFor(events.EventTypeUserUpdated, "amqp"),
+				letterFor(events.EventTypeUserRegistered, "memory"),
+			} {
+				_, err := queue.Add(ctx, letter)
+				require.NoError(t, err)
+			}
+
+			// Act
+			letters, err := queue.List(ctx, tt.filter)
+
+			// Assert
+			require.NoError(t, err)
+			types := make([]string, len(letters))
+			for i, letter := range letters {
+				types[i] = letter.Event.Type
+			}
+			assert.Equal(t, tt.expectedTypes, types)
+		})
+	}
+}
+
+func TestRemove_GivenLetter_WhenRemoving_ThenItIsGoneAndDepthDrops(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	queue := memory.NewService()
+	added, err := queue.Add(ctx, letterFor(events.EventTypeUserRegistered, "memory"))
+	require.NoError(t, err)
+	_, err = queue.Add(ctx, letterFor(events.EventTypeUserUpdated, "memory"))
+	require.NoError(t, err)
+
+	// Act
+	err = queue.Remove(ctx, added.ID)
+
+	// Assert
+	require.NoError(t, err)
+	_, getErr := queue.Get(ctx, added.ID)
+	assert.ErrorIs(t, getErr, deadletter.ErrLetterNotFound)
+	assert.ErrorIs(t, queue.Remove(ctx, added.ID), deadletter.ErrLetterNotFound)
+	depth, err := queue.Depth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, depth)
+}
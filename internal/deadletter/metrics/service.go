@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+)
+
+// depthTimeout bounds how long a scrape waits for the queue depth
+const depthTimeout = 5 * time.Second
+
+// service implements deadletter.Service with Prometheus metrics
+type service struct {
+	next         deadletter.Service
+	deadLettered *prometheus.CounterVec
+	removed      prometheus.Counter
+}
+
+// NewService creates a new dead-letter queue that counts the events
+// dead-lettered and removed, reports the queue depth on every scrape, and
+// registers the collectors with the registerer
+func NewService(next deadletter.Service, registerer prometheus.Registerer) (deadletter.Service, error) {
+	s := &service{
+		next: next,
+		deadLettered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "events",
+			Name:      "dead_lettered_total",
+			Help:      "Total events dead-lettered by provider, event type and whether the handler panicked.",
+		}, []string{"provider", "event_type", "poison"}),
+		removed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "events",
+			Name:      "dead_letters_removed_total",
+			Help:      "Total dead-lettered events re-driven or discarded.",
+		}),
+	}
+	depth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "events",
+		Name:      "dead_letter_depth",
+		Help:      "Dead-lettered events waiting to be re-driven or discarded.",
+	}, s.depth)
+
+	for _, collector := range []prometheus.Collector{s.deadLettered, s.removed, depth} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Add counts the dead-lettered event
+func (s *service) Add(ctx context.Context, letter deadletter.Letter) (*deadletter.Letter, error) {
+	added, err := s.next.Add(ctx, letter)
+	if err == nil {
+		s.deadLettered.WithLabelValues(letter.Provider, letter.Event.Type, strconv.FormatBool(letter.Poison)).Inc()
+	}
+	return added, err
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filter deadletter.Filter) ([]deadletter.Letter, error) {
+	return s.next.List(ctx, filter)
+}
+
+// Get passes through
+func (s *service) Get(ctx context.Context, id string) (*deadletter.Letter, error) {
+	return s.next.Get(ctx, id)
+}
+
+// Remove counts the removed event
+func (s *service) Remove(ctx context.Context, id string) error {
+	err := s.next.Remove(ctx, id)
+	if err == nil {
+		s.removed.Inc()
+	}
+	return err
+}
+
+// Depth passes through
+func (s *service) Depth(ctx context.Context) (int, error) {
+	return s.next.Depth(ctx)
+}
+
+// depth reads the queue depth for the gauge; a failed read reports zero
+func (s *service) depth() float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), depthTimeout)
+	defer cancel()
+	depth, err := s.next.Depth(ctx)
+	if err != nil {
+		log.Printf("Failed to read dead-letter depth: %v", err)
+		return 0
+	}
+	return float64(depth)
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+)
+
+// letterColumns are the dead_letters columns scanLetter reads, in order
+const letterColumns = `id, event, provider, subscription, handler, error, attempts, poison,
+	first_failed_at, dead_lettered_at`
+
+// service implements deadletter.Service on the dead_letters table, so every
+// instance sharing the database sees the same dead-lettered events
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed dead-letter queue
+func NewService(pool *pgxpool.Pool) deadletter.Service {
+	return &service{pool: pool}
+}
+
+// Add inserts a letter, giving it an ID and times when it has none
+func (s *service) Add(ctx context.Context, letter deadletter.Letter) (*deadletter.Letter, error) {
+	if letter.ID == "" {
+		letter.ID = uuid.New().String()
+	}
+	if letter.DeadLetteredAt.IsZero() {
+		letter.DeadLetteredAt = time.Now()
+	}
+	if letter.FirstFailedAt.IsZero() {
+		letter.FirstFailedAt = letter.DeadLetteredAt
+	}
+	event, err := json.Marshal(letter.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO dead_letters (id, event_id, event_type, event, provider, subscription, handler, error,
+			attempts, poison, first_failed_at, dead_lettered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		letter.ID, letter.Event.ID, letter.Event.Type, event, letter.Provider, letter.Subscription, letter.Handler,
+		letter.Error, letter.Attempts, letter.Poison, letter.FirstFailedAt, letter.DeadLetteredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// List returns matching letters, oldest first
+func (s *service) List(ctx context.Context, filter deadletter.Filter) ([]deadletter.Letter, error) {
+	var conditions []string
+	var args []any
+	if filter.EventType != "" {
+		args = append(args, filter.EventType)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if filter.Provider != "" {
+		args = append(args, filter.Provider)
+		conditions = append(conditions, fmt.Sprintf("provider = $%d", len(args)))
+	}
+
+	query := `SELECT ` + letterColumns + ` FROM dead_letters`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY dead_lettered_at, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []deadletter.Letter{}
+	for rows.Next() {
+		letter, err := scanLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, *letter)
+	}
+	return letters, rows.Err()
+}
+
+// Get returns a letter
+func (s *service) Get(ctx context.Context, id string) (*deadletter.Letter, error) {
+	letter, err := scanLetter(s.pool.QueryRow(ctx, `SELECT `+letterColumns+` FROM dead_letters WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, deadletter.ErrLetterNotFound
+	}
+	return letter, err
+}
+
+// Remove deletes a letter
+func (s *service) Remove(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM dead_letters WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return deadletter.ErrLetterNotFound
+	}
+	return nil
+}
+
+// Depth counts the stored letters
+func (s *service) Depth(ctx context.Context) (int, error) {
+	var depth int
+	err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM dead_letters`).Scan(&depth)
+	return depth, err
+}
+
+// scanLetter reads one letter in letterColumns order
+func scanLetter(row pgx.Row) (*deadletter.Letter, error) {
+	var letter deadletter.Letter
+	var event []byte
+	if err := row.Scan(&letter.ID, &event, &letter.Provider, &letter.Subscription, &letter.Handler, &letter.Error,
+		&letter.Attempts, &letter.Poison, &letter.FirstFailedAt, &letter.DeadLetteredAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(event, &letter.Event); err != nil {
+		return nil, fmt.Errorf("failed to decode dead-lettered event %s: %w", letter.ID, err)
+	}
+	return &letter, nil
+}
@@ -0,0 +1,49 @@
+package device
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Service defines the device domain interface - the ONLY interface in this domain.
+// It remembers the devices each user signed in from, so sign-ins from
+// unrecognized devices can be told apart.
+type Service interface {
+	// Record remembers the device for the user, or refreshes its last
+	// sighting, and reports whether the user had signed in from it before
+	Record(ctx context.Context, userID string, device Device) (known bool, err error)
+
+	// List returns the user's known devices, most recently seen first
+	List(ctx context.Context, userID string) ([]Device, error)
+
+	// Forget removes a known device, so the next sign-in from it is from an
+	// unrecognized device again
+	Forget(ctx context.Context, userID, fingerprint string) error
+}
+
+// Device is a device a user signed in from
+type Device struct {
+	Fingerprint string    `json:"fingerprint"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address,omitempty"` // Address of the latest sign-in
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// Fingerprint identifies a device by the fingerprint its client sent, or by
+// its user agent when it sent none. The IP address is left out, as it
+// changes with the network a device is on. Without either the device cannot
+// be identified and the fingerprint is empty.
+func Fingerprint(clientFingerprint, userAgent string) string {
+	source := "client:" + clientFingerprint
+	if clientFingerprint == "" {
+		if userAgent == "" {
+			return ""
+		}
+		source = "user-agent:" + userAgent
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:16])
+}
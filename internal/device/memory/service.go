@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gentra/decorator-arch-go/internal/device"
+)
+
+// service implements device.Service in memory, for single instances and tests
+type service struct {
+	mu      sync.Mutex
+	devices map[string]map[string]device.Device // By user ID, then fingerprint
+}
+
+// NewService creates an in-memory device store
+func NewService() device.Service {
+	return &service{devices: make(map[string]map[string]device.Device)}
+}
+
+// Record stores the device, keeping when it was first seen
+func (s *service) Record(ctx context.Context, userID string, d device.Device) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, ok := s.devices[userID]
+	if !ok {
+		devices = make(map[string]device.Device)
+		s.devices[userID] = devices
+	}
+
+	existing, known := devices[d.Fingerprint]
+	if known {
+		d.FirstSeen = existing.FirstSeen
+	}
+	devices[d.Fingerprint] = d
+	return known, nil
+}
+
+// List returns the user's devices, most recently seen first
+func (s *service) List(ctx context.Context, userID string) ([]device.Device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices := make([]device.Device, 0, len(s.devices[userID]))
+	for _, d := range s.devices[userID] {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].LastSeen.After(devices[j].LastSeen)
+	})
+	return devices, nil
+}
+
+// Forget drops the device; unknown devices are ignored
+func (s *service) Forget(ctx context.Context, userID, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.devices[userID], fingerprint)
+	if len(s.devices[userID]) == 0 {
+		delete(s.devices, userID)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/device"
+	"github.com/gentra/decorator-arch-go/internal/device/memory"
+)
+
+func TestDevice_GivenNewDevice_WhenRecording_ThenReportsItUnknown(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	now := time.Now()
+
+	known, err := store.Record(ctx, "user-1", device.Device{Fingerprint: "fp-1", UserAgent: "Firefox", FirstSeen: now, LastSeen: now})
+
+	require.NoError(t, err)
+	assert.False(t, known)
+}
+
+func TestDevice_GivenKnownDevice_WhenRecordingAgain_ThenKeepsFirstSeenAndRefreshesTheRest(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	first := time.Now().Add(-time.Hour)
+	now := time.Now()
+	_, err := store.Record(ctx, "user-1", device.Device{Fingerprint: "fp-1", IPAddress: "203.0.113.7", FirstSeen: first, LastSeen: first})
+	require.NoError(t, err)
+
+	known, err := store.Record(ctx, "user-1", device.Device{Fingerprint: "fp-1", IPAddress: "198.51.100.2", FirstSeen: now, LastSeen: now})
+	require.NoError(t, err)
+	devices, err := store.List(ctx, "user-1")
+
+	require.NoError(t, err)
+	assert.True(t, known)
+	require.Len(t, devices, 1)
+	assert.Equal(t, first, devices[0].FirstSeen)
+	assert.Equal(t, now, devices[0].LastSeen)
+	assert.Equal(t, "198.51.100.2", devices[0].IPAddress)
+}
+
+func TestDevice_GivenSeveralDevices_WhenListing_ThenMostRecentComesFirstAndUsersAreSeparate(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	now := time.Now()
+	_, _ = store.Record(ctx, "user-1", device.Device{Fingerprint: "old", LastSeen: now.Add(-time.Hour)})
+	_, _ = store.Record(ctx, "user-1", device.Device{Fingerprint: "new", LastSeen: now})
+	_, _ = store.Record(ctx, "user-2", device.Device{Fingerprint: "other", LastSeen: now})
+
+	devices, err := store.List(ctx, "user-1")
+
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "new", devices[0].Fingerprint)
+	assert.Equal(t, "old", devices[1].Fingerprint)
+}
+
+func TestDevice_GivenForgottenDevice_WhenRecordingAgain_ThenItIsUnknown(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Record(ctx, "user-1", device.Device{Fingerprint: "fp-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Forget(ctx, "user-1", "fp-1"))
+	known, err := store.Record(ctx, "user-1", device.Device{Fingerprint: "fp-1"})
+
+	require.NoError(t, err)
+	assert.False(t, known)
+}
+
+func TestFingerprint_GivenClientFingerprintOrUserAgent_WhenFingerprinting_ThenPrefersTheClientFingerprint(t *testing.T) {
+	tests := []struct {
+		name              string
+		clientFingerprint string
+		userAgent         string
+		expectEmpty       bool
+	}{
+		{name: "client fingerprint", clientFingerprint: "abc", userAgent: "Firefox"},
+		{name: "user agent only", userAgent: "Firefox"},
+		{name: "neither", expectEmpty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			fingerprint := device.Fingerprint(tt.clientFingerprint, tt.userAgent)
+
+			// Assert
+			if tt.expectEmpty {
+				assert.Empty(t, fingerprint)
+				return
+			}
+			assert.Len(t, fingerprint, 32)
+			assert.Equal(t, fingerprint, device.Fingerprint(tt.clientFingerprint, tt.userAgent))
+		})
+	}
+
+	assert.Equal(t, device.Fingerprint("abc", "Firefox"), device.Fingerprint("abc", "Chrome"), "a browser update must not make the device unrecognized")
+	assert.NotEqual(t, device.Fingerprint("", "Firefox"), device.Fingerprint("Firefox", ""))
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/device"
+)
+
+// DefaultKeyPrefix namespaces known devices in a shared Redis
+const DefaultKeyPrefix = "devices:"
+
+// service implements device.Service on Redis, so every instance knows the
+// same devices. Each user's devices are a hash of JSON encoded devices keyed
+// by fingerprint.
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed device store using DefaultKeyPrefix
+func NewService(client *redis.Client) device.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed device store whose keys start
+// with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) device.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// Record stores the device, keeping when it was first seen
+func (s *service) Record(ctx context.Context, userID string, d device.Device) (bool, error) {
+	existing, err := s.client.HGet(ctx, s.key(userID), d.Fingerprint).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, err
+	}
+
+	known := err == nil
+	if known {
+		var stored device.Device
+		if err := json.Unmarshal(existing, &stored); err == nil {
+			d.FirstSeen = stored.FirstSeen
+		}
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return false, err
+	}
+	if err := s.client.HSet(ctx, s.key(userID), d.Fingerprint, data).Err(); err != nil {
+		return false, err
+	}
+	return known, nil
+}
+
+// List returns the user's devices, most recently seen first
+func (s *service) List(ctx context.Context, userID string) ([]device.Device, error) {
+	values, err := s.client.HGetAll(ctx, s.key(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]device.Device, 0, len(values))
+	for _, value := range values {
+		var d device.Device
+		if err := json.Unmarshal([]byte(value), &d); err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].LastSeen.After(devices[j].LastSeen)
+	})
+	return devices, nil
+}
+
+// Forget deletes the device from the user's hash
+func (s *service) Forget(ctx context.Context, userID, fingerprint string) error {
+	return s.client.HDel(ctx, s.key(userID), fingerprint).Err()
+}
+
+func (s *service) key(userID string) string {
+	return s.prefix + userID
+}
@@ -89,6 +89,12 @@ func (s *service) DecryptWithPurpose(ctx context.Context, ciphertext, purpose st
 	return s.decrypt(ciphertext, key)
 }
 
+// LookupCiphertexts is not supported: AES-GCM with random nonces never
+// produces the same ciphertext twice
+func (s *service) LookupCiphertexts(ctx context.Context, plaintext, purpose string) ([]string, error) {
+	return nil, encryption.ErrLookupUnsupported
+}
+
 // EncryptBatch encrypts multiple data items for a specific purpose
 func (s *service) EncryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error) {
 	key := s.getKeyForPurpose(purpose)
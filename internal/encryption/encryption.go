@@ -14,6 +14,11 @@ type Service interface {
 	EncryptWithPurpose(ctx context.Context, plaintext, purpose string) (string, error)
 	DecryptWithPurpose(ctx context.Context, ciphertext, purpose string) (string, error)
 
+	// LookupCiphertexts returns the ciphertexts plaintext may be stored as under
+	// every key still in use for the purpose, newest first, so encrypted columns
+	// can be matched by equality. Only deterministic purposes support lookups.
+	LookupCiphertexts(ctx context.Context, plaintext, purpose string) ([]string, error)
+
 	// Batch operations for efficiency
 	EncryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error)
 	DecryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error)
@@ -56,11 +61,12 @@ func (e EncryptionError) Error() string {
 
 // Common encryption error codes
 var (
-	ErrInvalidKey       = EncryptionError{Code: "INVALID_KEY", Message: "Invalid encryption key"}
-	ErrEncryptionFailed = EncryptionError{Code: "ENCRYPTION_FAILED", Message: "Encryption operation failed"}
-	ErrDecryptionFailed = EncryptionError{Code: "DECRYPTION_FAILED", Message: "Decryption operation failed"}
-	ErrKeyNotFound      = EncryptionError{Code: "KEY_NOT_FOUND", Message: "Encryption key not found"}
-	ErrInvalidData      = EncryptionError{Code: "INVALID_DATA", Message: "Invalid data format"}
+	ErrInvalidKey        = EncryptionError{Code: "INVALID_KEY", Message: "Invalid encryption key"}
+	ErrEncryptionFailed  = EncryptionError{Code: "ENCRYPTION_FAILED", Message: "Encryption operation failed"}
+	ErrDecryptionFailed  = EncryptionError{Code: "DECRYPTION_FAILED", Message: "Decryption operation failed"}
+	ErrKeyNotFound       = EncryptionError{Code: "KEY_NOT_FOUND", Message: "Encryption key not found"}
+	ErrInvalidData       = EncryptionError{Code: "INVALID_DATA", Message: "Invalid data format"}
+	ErrLookupUnsupported = EncryptionError{Code: "LOOKUP_UNSUPPORTED", Message: "Purpose does not support equality lookups"}
 )
 
 // Helper methods for EncryptedData
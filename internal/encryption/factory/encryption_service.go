@@ -6,13 +6,16 @@ import (
 
 	"github.com/gentra/decorator-arch-go/internal/encryption"
 	"github.com/gentra/decorator-arch-go/internal/encryption/aes"
+	encryptionKeyRing "github.com/gentra/decorator-arch-go/internal/encryption/keyring"
 	"github.com/gentra/decorator-arch-go/internal/encryption/noop"
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+	keyRingMemory "github.com/gentra/decorator-arch-go/internal/keyring/memory"
 )
 
 // Config contains all configuration for building the encryption service
 type Config struct {
 	// Encryption algorithm
-	Algorithm string // "aes", "noop"
+	Algorithm string // "aes", "keyring", "noop"
 
 	// Key management
 	DefaultKey  []byte
@@ -25,6 +28,11 @@ type Config struct {
 	AutoGenerateKeys bool
 	KeyRotationDays  int
 
+	// Key ring configuration (if Algorithm = "keyring"). When KeyRing is nil an
+	// in-memory ring is derived from DefaultKey.
+	KeyRing       keyring.Service
+	KeyRingConfig encryptionKeyRing.Config
+
 	// Feature flags
 	Features FeatureFlags
 }
@@ -70,6 +78,8 @@ func (f *EncryptionServiceFactory) Build() (encryption.Service, error) {
 	switch f.config.Algorithm {
 	case "aes":
 		return f.buildAESService()
+	case "keyring":
+		return f.buildKeyRingService()
 	case "noop":
 		return f.buildNoOpService()
 	default:
@@ -120,6 +130,29 @@ func (f *EncryptionServiceFactory) buildAESService() (encryption.Service, error)
 	return aes.NewService(singleKeyMap, defaultKey)
 }
 
+// buildKeyRingService creates an AES-GCM service with versioned, rotatable keys
+func (f *EncryptionServiceFactory) buildKeyRingService() (encryption.Service, error) {
+	ring := f.config.KeyRing
+	if ring == nil {
+		master := f.config.DefaultKey
+		if len(master) == 0 && f.config.AutoGenerateKeys {
+			var err error
+			master, err = f.generateKey()
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate master key: %w", err)
+			}
+		}
+
+		var err error
+		ring, err = keyRingMemory.NewService(master)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create key ring: %w", err)
+		}
+	}
+
+	return encryptionKeyRing.NewService(ring, f.config.KeyRingConfig), nil
+}
+
 // buildNoOpService creates a no-operation encryption service (for development/testing)
 func (f *EncryptionServiceFactory) buildNoOpService() (encryption.Service, error) {
 	return noop.NewService(), nil
@@ -164,6 +197,7 @@ func DefaultConfig() Config {
 		AutoGenerateKeys: true,
 		KeyRotationDays:  90,
 		PurposeKeys:      make(map[string][]byte),
+		KeyRingConfig:    encryptionKeyRing.DefaultConfig(),
 		Features:         DefaultFeatureFlags(),
 	}
 }
@@ -201,6 +235,13 @@ func (b *ConfigBuilder) WithPurposeKey(purpose string, key []byte) *ConfigBuilde
 	return b
 }
 
+// WithKeyRing selects key ring encryption backed by the given ring
+func (b *ConfigBuilder) WithKeyRing(ring keyring.Service) *ConfigBuilder {
+	b.config.Algorithm = "keyring"
+	b.config.KeyRing = ring
+	return b
+}
+
 // WithKeySize sets the encryption key size
 func (b *ConfigBuilder) WithKeySize(size int) *ConfigBuilder {
 	b.config.KeySize = size
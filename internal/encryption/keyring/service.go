@@ -0,0 +1,247 @@
+package keyring
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/encryption"
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+)
+
+// Config controls the key ring encryption service
+type Config struct {
+	// DeterministicPurposes are encrypted with a nonce derived from the plaintext,
+	// so equal values produce equal ciphertexts and can be looked up by equality
+	DeterministicPurposes []string
+}
+
+// DefaultConfig makes user emails deterministic so logins can find users by email
+func DefaultConfig() Config {
+	return Config{
+		DeterministicPurposes: []string{encryption.PurposeUserEmail},
+	}
+}
+
+// service implements encryption.Service with AES-256-GCM using versioned keys
+// from a key ring. Ciphertexts are prefixed with the ID of the key that wrote
+// them ("<key id>:<base64>"), so rotation never makes existing data unreadable.
+type service struct {
+	ring          keyring.Service
+	deterministic map[string]bool
+}
+
+// NewService creates a new key ring backed encryption service
+func NewService(ring keyring.Service, config Config) encryption.Service {
+	deterministic := make(map[string]bool, len(config.DeterministicPurposes))
+	for _, purpose := range config.DeterministicPurposes {
+		deterministic[purpose] = true
+	}
+
+	return &service{
+		ring:          ring,
+		deterministic: deterministic,
+	}
+}
+
+// Encrypt encrypts plaintext with the current default key
+func (s *service) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	return s.EncryptWithPurpose(ctx, plaintext, encryption.PurposeDefault)
+}
+
+// Decrypt decrypts ciphertext written with any default key version
+func (s *service) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	return s.DecryptWithPurpose(ctx, ciphertext, encryption.PurposeDefault)
+}
+
+// EncryptWithPurpose encrypts plaintext with the current key for the purpose
+func (s *service) EncryptWithPurpose(ctx context.Context, plaintext, purpose string) (string, error) {
+	key, err := s.ring.Current(ctx, purpose)
+	if err != nil {
+		return "", err
+	}
+	return s.encrypt(plaintext, purpose, key)
+}
+
+// DecryptWithPurpose decrypts ciphertext with the key version that wrote it
+func (s *service) DecryptWithPurpose(ctx context.Context, ciphertext, purpose string) (string, error) {
+	keyID, payload, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return "", encryption.ErrInvalidData
+	}
+
+	key, err := s.ring.Get(ctx, purpose, keyID)
+	if err != nil {
+		return "", err
+	}
+	return s.decrypt(payload, purpose, key)
+}
+
+// LookupCiphertexts encrypts plaintext under every key version of a deterministic purpose
+func (s *service) LookupCiphertexts(ctx context.Context, plaintext, purpose string) ([]string, error) {
+	if !s.deterministic[purpose] {
+		return nil, encryption.ErrLookupUnsupported
+	}
+
+	keys, err := s.ring.Keys(ctx, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertexts := make([]string, 0, len(keys))
+	for i := range keys {
+		ciphertext, err := s.encrypt(plaintext, purpose, &keys[i])
+		if err != nil {
+			return nil, err
+		}
+		ciphertexts = append(ciphertexts, ciphertext)
+	}
+	return ciphertexts, nil
+}
+
+// EncryptBatch encrypts multiple data items for a specific purpose
+func (s *service) EncryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error) {
+	result := make(map[string]string, len(data))
+	for field, plaintext := range data {
+		encrypted, err := s.EncryptWithPurpose(ctx, plaintext, purpose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt field '%s': %w", field, err)
+		}
+		result[field] = encrypted
+	}
+	return result, nil
+}
+
+// DecryptBatch decrypts multiple data items for a specific purpose
+func (s *service) DecryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error) {
+	result := make(map[string]string, len(data))
+	for field, ciphertext := range data {
+		decrypted, err := s.DecryptWithPurpose(ctx, ciphertext, purpose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt field '%s': %w", field, err)
+		}
+		result[field] = decrypted
+	}
+	return result, nil
+}
+
+// GenerateKey generates random key material without adding it to the ring
+func (s *service) GenerateKey() ([]byte, error) {
+	key := make([]byte, keyring.KeySize)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+// GenerateKeyForPurpose rotates the purpose to a new key and returns its material
+func (s *service) GenerateKeyForPurpose(purpose string) ([]byte, error) {
+	key, err := s.ring.Rotate(context.Background(), purpose)
+	if err != nil {
+		return nil, err
+	}
+	return key.Material, nil
+}
+
+// RotateKeys rotates every purpose in the ring; older versions stay readable
+func (s *service) RotateKeys() error {
+	ctx := context.Background()
+	purposes, err := s.ring.Purposes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, purpose := range purposes {
+		if _, err := s.ring.Rotate(ctx, purpose); err != nil {
+			return fmt.Errorf("failed to rotate key for purpose '%s': %w", purpose, err)
+		}
+	}
+	return nil
+}
+
+// RotateKeyForPurpose rotates the key for a specific purpose
+func (s *service) RotateKeyForPurpose(purpose string) error {
+	_, err := s.ring.Rotate(context.Background(), purpose)
+	return err
+}
+
+// Helper methods
+
+// encrypt seals plaintext with the key, binding the purpose as additional data
+// so a ciphertext cannot be replayed into a field of another purpose
+func (s *service) encrypt(plaintext, purpose string, key *keyring.Key) (string, error) {
+	gcm, err := s.cipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if s.deterministic[purpose] {
+		nonce, err = syntheticNonce(key, purpose, plaintext, gcm.NonceSize())
+		if err != nil {
+			return "", err
+		}
+	} else if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(purpose))
+	return key.ID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *service) decrypt(payload, purpose string, key *keyring.Key) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", encryption.ErrInvalidData
+	}
+
+	gcm, err := s.cipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", encryption.ErrInvalidData
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, []byte(purpose))
+	if err != nil {
+		return "", encryption.ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
+
+// cipher creates AES-GCM with an encryption subkey of the key material
+func (s *service) cipher(key *keyring.Key) (cipher.AEAD, error) {
+	subkey, err := hkdf.Key(sha256.New, key.Material, nil, "encryption", keyring.KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(subkey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// syntheticNonce derives the nonce from the plaintext with a separate MAC
+// subkey, so only equality of values is revealed
+func syntheticNonce(key *keyring.Key, purpose, plaintext string, size int) ([]byte, error) {
+	subkey, err := hkdf.Key(sha256.New, key.Material, nil, "synthetic-nonce", keyring.KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, subkey)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(plaintext))
+	return mac.Sum(nil)[:size], nil
+}
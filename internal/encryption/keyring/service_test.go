@@ -0,0 +1,85 @@
+package keyring_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/encryption"
+	encryptionKeyRing "github.com/gentra/decorator-arch-go/internal/encryption/keyring"
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+	keyRingMemory "github.com/gentra/decorator-arch-go/internal/keyring/memory"
+)
+
+func newService(t *testing.T) encryption.Service {
+	t.Helper()
+	ring, err := keyRingMemory.NewService(bytes.Repeat([]byte{1}, keyring.KeySize))
+	require.NoError(t, err)
+	return encryptionKeyRing.NewService(ring, encryptionKeyRing.DefaultConfig())
+}
+
+func TestKeyRingEncryption_GivenPlaintext_WhenRoundTripping_ThenRestoresValue(t *testing.T) {
+	service := newService(t)
+	ctx := context.Background()
+
+	ciphertext, err := service.EncryptWithPurpose(ctx, "Jane", encryption.PurposeUserName)
+	require.NoError(t, err)
+	plaintext, err := service.DecryptWithPurpose(ctx, ciphertext, encryption.PurposeUserName)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ciphertext, "v1:"))
+	assert.NotContains(t, ciphertext, "Jane")
+	assert.Equal(t, "Jane", plaintext)
+}
+
+func TestKeyRingEncryption_GivenPurposes_WhenEncryptingTwice_ThenOnlyDeterministicPurposesRepeat(t *testing.T) {
+	service := newService(t)
+	ctx := context.Background()
+
+	email1, _ := service.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	email2, _ := service.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	name1, _ := service.EncryptWithPurpose(ctx, "Jane", encryption.PurposeUserName)
+	name2, _ := service.EncryptWithPurpose(ctx, "Jane", encryption.PurposeUserName)
+
+	assert.Equal(t, email1, email2)
+	assert.NotEqual(t, name1, name2)
+
+	_, err := service.LookupCiphertexts(ctx, "Jane", encryption.PurposeUserName)
+	assert.ErrorIs(t, err, encryption.ErrLookupUnsupported)
+}
+
+func TestKeyRingEncryption_GivenRotatedKey_WhenReadingOldData_ThenStillDecryptsAndLooksUp(t *testing.T) {
+	service := newService(t)
+	ctx := context.Background()
+	old, err := service.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RotateKeyForPurpose(encryption.PurposeUserEmail))
+
+	current, err := service.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(current, "v2:"))
+
+	plaintext, err := service.DecryptWithPurpose(ctx, old, encryption.PurposeUserEmail)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", plaintext)
+
+	candidates, err := service.LookupCiphertexts(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	require.NoError(t, err)
+	assert.Equal(t, []string{current, old}, candidates)
+}
+
+func TestKeyRingEncryption_GivenCiphertextOfAnotherPurpose_WhenDecrypting_ThenFails(t *testing.T) {
+	service := newService(t)
+	ctx := context.Background()
+	ciphertext, err := service.EncryptWithPurpose(ctx, "Jane", encryption.PurposeUserName)
+	require.NoError(t, err)
+
+	_, err = service.DecryptWithPurpose(ctx, ciphertext, encryption.PurposeUserPhone)
+
+	assert.Error(t, err)
+}
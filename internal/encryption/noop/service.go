@@ -35,6 +35,11 @@ func (s *service) DecryptWithPurpose(ctx context.Context, ciphertext, purpose st
 	return ciphertext, nil
 }
 
+// LookupCiphertexts returns the plaintext as its only stored form
+func (s *service) LookupCiphertexts(ctx context.Context, plaintext, purpose string) ([]string, error) {
+	return []string{plaintext}, nil
+}
+
 // EncryptBatch returns all data as-is (no encryption)
 func (s *service) EncryptBatch(ctx context.Context, data map[string]string, purpose string) (map[string]string, error) {
 	result := make(map[string]string)
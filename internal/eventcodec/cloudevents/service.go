@@ -0,0 +1,158 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+const (
+	// ContentType is the media type of structured-mode CloudEvents in JSON
+	ContentType = "application/cloudevents+json"
+
+	// SpecVersion is the CloudEvents version events are encoded with
+	SpecVersion = "1.0"
+
+	// DefaultSource identifies the application as the source of events
+	// whose metadata names none
+	DefaultSource = "/decorator-arch-go"
+)
+
+// envelope is a CloudEvents 1.0 event in the JSON format. The event's
+// aggregate, versions and metadata travel as extension attributes, whose
+// names the specification limits to lowercase letters and digits.
+type envelope struct {
+	SpecVersion     string                 `json:"specversion"`
+	ID              string                 `json:"id"`
+	Source          string                 `json:"source"`
+	Type            string                 `json:"type"`
+	Subject         string                 `json:"subject,omitempty"`
+	Time            *time.Time             `json:"time,omitempty"`
+	DataContentType string                 `json:"datacontenttype,omitempty"`
+	Data            map[string]interface{} `json:"data,omitempty"`
+
+	AggregateType    string `json:"aggregatetype,omitempty"`
+	AggregateVersion int    `json:"aggregateversion,omitempty"`
+	SchemaVersion    int    `json:"schemaversion,omitempty"`
+	CorrelationID    string `json:"correlationid,omitempty"`
+	CausationID      string `json:"causationid,omitempty"`
+	UserID           string `json:"userid,omitempty"`
+	IPAddress        string `json:"ipaddress,omitempty"`
+	UserAgent        string `json:"useragent,omitempty"`
+
+	// Headers is the JSON object of the metadata headers, as extension
+	// values are scalars
+	Headers string `json:"headers,omitempty"`
+}
+
+// service implements eventcodec.Service with the CloudEvents 1.0 JSON
+// envelope, for brokers and gateways expecting CloudEvents: the event type
+// is the CloudEvents type, the aggregate ID its subject, the timestamp its
+// time and the data its data
+type service struct {
+	source string
+}
+
+// NewService creates a CloudEvents codec naming source as the source of
+// events whose metadata names none; empty uses DefaultSource
+func NewService(source string) eventcodec.Service {
+	if source == "" {
+		source = DefaultSource
+	}
+	return &service{source: source}
+}
+
+// Encode wraps the event in a CloudEvents envelope
+func (s *service) Encode(event events.Event) ([]byte, error) {
+	e := envelope{
+		SpecVersion:      SpecVersion,
+		ID:               event.ID,
+		Source:           event.Metadata.Source,
+		Type:             event.Type,
+		Subject:          event.AggregateID,
+		DataContentType:  "application/json",
+		Data:             event.Data,
+		AggregateType:    event.AggregateType,
+		AggregateVersion: event.Version,
+		SchemaVersion:    event.SchemaVersion,
+		CorrelationID:    event.Metadata.CorrelationID,
+		CausationID:      event.Metadata.CausationID,
+		UserID:           event.Metadata.UserID,
+		IPAddress:        event.Metadata.IPAddress,
+		UserAgent:        event.Metadata.UserAgent,
+	}
+	if e.Source == "" {
+		e.Source = s.source
+	}
+	if !event.Timestamp.IsZero() {
+		timestamp := event.Timestamp.UTC()
+		e.Time = &timestamp
+	}
+	if len(event.Metadata.Headers) > 0 {
+		headers, err := json.Marshal(event.Metadata.Headers)
+		if err != nil {
+			return nil, err
+		}
+		e.Headers = string(headers)
+	}
+	return json.Marshal(e)
+}
+
+// Decode unwraps a CloudEvents envelope. Bodies without specversion are read
+// as plain JSON events, so queues holding events published before the
+// switch to CloudEvents still drain.
+func (s *service) Decode(body []byte) (events.Event, error) {
+	var e envelope
+	if err := json.Unmarshal(body, &e); err != nil {
+		return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+	}
+	if e.SpecVersion == "" {
+		var event events.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+		}
+		return event, nil
+	}
+	if !strings.HasPrefix(e.SpecVersion, "1.") {
+		return events.Event{}, fmt.Errorf("%w: unsupported specversion %q", eventcodec.ErrDecodeFailed, e.SpecVersion)
+	}
+	if e.ID == "" || e.Type == "" || e.Source == "" {
+		return events.Event{}, fmt.Errorf("%w: id, source and type are required", eventcodec.ErrDecodeFailed)
+	}
+
+	event := events.Event{
+		ID:            e.ID,
+		Type:          e.Type,
+		AggregateID:   e.Subject,
+		AggregateType: e.AggregateType,
+		Version:       e.AggregateVersion,
+		Data:          e.Data,
+		SchemaVersion: e.SchemaVersion,
+		Metadata: events.EventMetadata{
+			UserID:        e.UserID,
+			CorrelationID: e.CorrelationID,
+			CausationID:   e.CausationID,
+			Source:        e.Source,
+			IPAddress:     e.IPAddress,
+			UserAgent:     e.UserAgent,
+		},
+	}
+	if e.Time != nil {
+		event.Timestamp = *e.Time
+	}
+	if e.Headers != "" {
+		if err := json.Unmarshal([]byte(e.Headers), &event.Metadata.Headers); err != nil {
+			return events.Event{}, fmt.Errorf("%w: headers: %v", eventcodec.ErrDecodeFailed, err)
+		}
+	}
+	return event, nil
+}
+
+// ContentType returns application/cloudevents+json
+func (s *service) ContentType() string {
+	return ContentType
+}
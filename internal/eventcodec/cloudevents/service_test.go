@@ -0,0 +1,138 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/cloudevents"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestEncode_GivenEvent_WhenEncoding_ThenWritesCloudEventsEnvelope(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         string
+		expectedSource string
+	}{
+		{
+			name:           "Given metadata naming a source, When encoding, Then uses it",
+			source:         "/user-service",
+			expectedSource: "/user-service",
+		},
+		{
+			name:           "Given no source, When encoding, Then names the application",
+			expectedSource: cloudevents.DefaultSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			codec := cloudevents.NewService("")
+			event := *testkit.NewEventBuilder().WithData("email", "jane@example.com").Build()
+			event.Metadata.Source = tt.source
+
+			// Act
+			body, err := codec.Encode(event)
+
+			// Assert
+			require.NoError(t, err)
+			var envelope map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &envelope))
+			assert.Equal(t, "1.0", envelope["specversion"])
+			assert.Equal(t, event.ID, envelope["id"])
+			assert.Equal(t, tt.expectedSource, envelope["source"])
+			assert.Equal(t, event.Type, envelope["type"])
+			assert.Equal(t, event.AggregateID, envelope["subject"])
+			assert.Equal(t, event.Timestamp.UTC().Format(time.RFC3339Nano), envelope["time"])
+			assert.Equal(t, map[string]interface{}{"email": "jane@example.com"}, envelope["data"])
+			assert.Equal(t, cloudevents.ContentType, codec.ContentType())
+		})
+	}
+}
+
+func TestDecode_GivenBody_WhenDecoding_ThenReadsTheEvent(t *testing.T) {
+	event := *testkit.NewEventBuilder().
+		WithData("email", "jane@example.com").
+		WithCorrelationID("request-1", "cause-1").
+		WithUserID("user-1").
+		Build()
+	event.SchemaVersion = 2
+	event.Metadata.Source = "/user-service"
+	event.Metadata.Headers = map[string]string{"dry_run": "true"}
+	encoded, err := cloudevents.NewService("").Encode(event)
+	require.NoError(t, err)
+	plain, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		body          string
+		expected      *events.Event
+		expectedError error
+	}{
+		{
+			name:     "Given an encoded event, When decoding, Then round-trips it",
+			body:     string(encoded),
+			expected: &event,
+		},
+		{
+			name:     "Given a plain JSON event, When decoding, Then reads it as published before CloudEvents",
+			body:     string(plain),
+			expected: &event,
+		},
+		{
+			name: "Given an external CloudEvent, When decoding, Then maps its attributes",
+			body: `{"specversion":"1.0","id":"ext-1","source":"/billing","type":"invoice.paid","subject":"invoice-1","time":"2026-01-02T03:04:05Z","data":{"amount":12}}`,
+			expected: &events.Event{
+				ID:          "ext-1",
+				Type:        "invoice.paid",
+				AggregateID: "invoice-1",
+				Data:        map[string]interface{}{"amount": float64(12)},
+				Metadata:    events.EventMetadata{Source: "/billing"},
+				Timestamp:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+		{
+			name:          "Given an unsupported spec version, When decoding, Then fails",
+			body:          `{"specversion":"0.3","id":"ext-1","source":"/billing","type":"invoice.paid"}`,
+			expectedError: eventcodec.ErrDecodeFailed,
+		},
+		{
+			name:          "Given a CloudEvent without type, When decoding, Then fails",
+			body:          `{"specversion":"1.0","id":"ext-1","source":"/billing"}`,
+			expectedError: eventcodec.ErrDecodeFailed,
+		},
+		{
+			name:          "Given a body that is not JSON, When decoding, Then fails",
+			body:          `not json`,
+			expectedError: eventcodec.ErrDecodeFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			codec := cloudevents.NewService("")
+
+			// Act
+			decoded, err := codec.Decode([]byte(tt.body))
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Timestamp.Equal(decoded.Timestamp))
+			decoded.Timestamp = tt.expected.Timestamp
+			assert.Equal(t, *tt.expected, decoded)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Compression algorithms
+const (
+	AlgorithmGzip = "gzip"
+	AlgorithmZstd = "zstd"
+)
+
+const (
+	// DefaultThreshold is the body size from which bodies are compressed
+	DefaultThreshold = 1024
+
+	// maxDecompressedSize bounds what a compressed body may expand to
+	maxDecompressedSize = 64 << 20
+)
+
+// Magic numbers opening gzip and zstd streams; neither JSON nor protobuf
+// event bodies start with them
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Config selects the algorithm and the size from which bodies are compressed
+type Config struct {
+	// Algorithm is "gzip" (or empty) or "zstd"
+	Algorithm string
+
+	// Threshold is the body size in bytes from which bodies are compressed;
+	// zero or less uses DefaultThreshold
+	Threshold int
+}
+
+// service decorates an eventcodec.Service, compressing the bodies it
+// encodes from a size threshold. Decode recognizes gzip and zstd streams
+// by their magic number whatever the configured algorithm, so consumers
+// read uncompressed bodies and those of producers configured differently.
+type service struct {
+	next      eventcodec.Service
+	algorithm string
+	threshold int
+
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+}
+
+// NewService creates a compressing codec around next
+func NewService(next eventcodec.Service, config Config) (eventcodec.Service, error) {
+	switch config.Algorithm {
+	case "":
+		config.Algorithm = AlgorithmGzip
+	case AlgorithmGzip, AlgorithmZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", config.Algorithm)
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultThreshold
+	}
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		next:        next,
+		algorithm:   config.Algorithm,
+		threshold:   config.Threshold,
+		zstdEncoder: encoder,
+		zstdDecoder: decoder,
+	}, nil
+}
+
+// Encode encodes the event with the decorated codec and compresses bodies
+// reaching the threshold
+func (s *service) Encode(event events.Event) ([]byte, error) {
+	body, err := s.next.Encode(event)
+	if err != nil || len(body) < s.threshold {
+		return body, err
+	}
+
+	if s.algorithm == AlgorithmZstd {
+		return s.zstdEncoder.EncodeAll(body, make([]byte, 0, len(body)/2)), nil
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// Decode decompresses gzip and zstd bodies and decodes them with the
+// decorated codec
+func (s *service) Decode(body []byte) (events.Event, error) {
+	switch {
+	case bytes.HasPrefix(body, zstdMagic):
+		decompressed, err := s.zstdDecoder.DecodeAll(body, nil)
+		if err != nil {
+			return events.Event{}, fmt.Errorf("%w: zstd: %v", eventcodec.ErrDecodeFailed, err)
+		}
+		body = decompressed
+	case bytes.HasPrefix(body, gzipMagic):
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return events.Event{}, fmt.Errorf("%w: gzip: %v", eventcodec.ErrDecodeFailed, err)
+		}
+		decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+		if err != nil {
+			return events.Event{}, fmt.Errorf("%w: gzip: %v", eventcodec.ErrDecodeFailed, err)
+		}
+		if len(decompressed) > maxDecompressedSize {
+			return events.Event{}, fmt.Errorf("%w: gzip: body expands beyond %d bytes", eventcodec.ErrDecodeFailed, maxDecompressedSize)
+		}
+		body = decompressed
+	}
+	return s.next.Decode(body)
+}
+
+// ContentType is that of the decorated codec; compressed bodies are told
+// apart by their magic number
+func (s *service) ContentType() string {
+	return s.next.ContentType()
+}
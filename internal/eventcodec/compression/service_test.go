@@ -0,0 +1,97 @@
+package compression_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/compression"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/plainjson"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestEncode_GivenThreshold_WhenEncoding_ThenCompressesLargeBodiesOnly(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           compression.Config
+		body             string
+		expectedPrefix   []byte
+		expectCompressed bool
+	}{
+		{
+			name:             "Given a body above the threshold and gzip, When encoding, Then gzips it",
+			config:           compression.Config{Algorithm: compression.AlgorithmGzip, Threshold: 256},
+			body:             strings.Repeat("contract clause ", 100),
+			expectedPrefix:   []byte{0x1f, 0x8b},
+			expectCompressed: true,
+		},
+		{
+			name:             "Given a body above the threshold and zstd, When encoding, Then compresses it with zstd",
+			config:           compression.Config{Algorithm: compression.AlgorithmZstd, Threshold: 256},
+			body:             strings.Repeat("contract clause ", 100),
+			expectedPrefix:   []byte{0x28, 0xb5, 0x2f, 0xfd},
+			expectCompressed: true,
+		},
+		{
+			name:           "Given a body below the threshold, When encoding, Then leaves it as is",
+			config:         compression.Config{Algorithm: compression.AlgorithmZstd, Threshold: 4096},
+			body:           "short",
+			expectedPrefix: []byte("{"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			codec, err := compression.NewService(plainjson.NewService(), tt.config)
+			require.NoError(t, err)
+			event := *testkit.NewEventBuilder().WithData("text", tt.body).Build()
+			uncompressed, err := plainjson.NewService().Encode(event)
+			require.NoError(t, err)
+
+			// Act
+			body, err := codec.Encode(event)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(body, tt.expectedPrefix))
+			if tt.expectCompressed {
+				assert.Less(t, len(body), len(uncompressed))
+			}
+			decoded, err := codec.Decode(body)
+			require.NoError(t, err)
+			assert.Equal(t, event.ID, decoded.ID)
+			assert.Equal(t, tt.body, decoded.Data["text"])
+		})
+	}
+}
+
+func TestDecode_GivenOtherAlgorithm_WhenDecoding_ThenRecognizesIt(t *testing.T) {
+	// Arrange
+	producer, err := compression.NewService(plainjson.NewService(), compression.Config{Algorithm: compression.AlgorithmZstd, Threshold: 1})
+	require.NoError(t, err)
+	consumer, err := compression.NewService(plainjson.NewService(), compression.Config{Algorithm: compression.AlgorithmGzip})
+	require.NoError(t, err)
+	event := *testkit.NewEventBuilder().Build()
+	body, err := producer.Encode(event)
+	require.NoError(t, err)
+
+	// Act
+	decoded, err := consumer.Decode(body)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, event.ID, decoded.ID)
+	assert.Equal(t, plainjson.ContentType, consumer.ContentType())
+}
+
+func TestNewService_GivenUnknownAlgorithm_WhenCreating_ThenFails(t *testing.T) {
+	// Act
+	_, err := compression.NewService(plainjson.NewService(), compression.Config{Algorithm: "brotli"})
+
+	// Assert
+	assert.Error(t, err)
+}
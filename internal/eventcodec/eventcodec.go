@@ -0,0 +1,43 @@
+package eventcodec
+
+import (
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Service defines the event codec domain interface - the ONLY interface in this domain.
+// It turns events into the message bodies brokers carry and back, so the
+// providers publishing to and consuming from external brokers agree on a
+// wire format chosen by EventConfig.Serialization.
+type Service interface {
+	// Encode returns the message body of the event
+	Encode(event events.Event) ([]byte, error)
+
+	// Decode reads an event from a message body, or returns ErrDecodeFailed
+	Decode(body []byte) (events.Event, error)
+
+	// ContentType is the media type of the bodies Encode returns
+	ContentType() string
+}
+
+// Serialization formats EventConfig.Serialization selects
+const (
+	SerializationJSON        = "json"
+	SerializationCloudEvents = "cloudevents"
+	SerializationProtobuf    = "protobuf"
+)
+
+// CodecError represents event codec domain errors
+type CodecError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e CodecError) Error() string {
+	return e.Message
+}
+
+// Common event codec errors
+var (
+	ErrDecodeFailed         = CodecError{Code: "DECODE_FAILED", Message: "Message body is not a valid event"}
+	ErrUnknownSerialization = CodecError{Code: "UNKNOWN_SERIALIZATION", Message: "Unknown event serialization"}
+)
@@ -0,0 +1,70 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/cloudevents"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/compression"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/plainjson"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/protobuf"
+)
+
+// Config contains all configuration for building the event codec
+type Config struct {
+	// Serialization selects the wire format: "json" (or empty) for plain
+	// JSON events, "cloudevents" for the CloudEvents 1.0 JSON envelope and
+	// "protobuf" for the eventpb.Event message
+	Serialization string
+
+	// Source names the application in CloudEvents whose metadata names no
+	// source; empty uses cloudevents.DefaultSource
+	Source string
+
+	// Compress compresses bodies from Compression.Threshold bytes with
+	// Compression.Algorithm
+	Compress    bool
+	Compression compression.Config
+}
+
+// DefaultConfig encodes plain JSON events
+func DefaultConfig() Config {
+	return Config{Serialization: eventcodec.SerializationJSON}
+}
+
+// EventCodecServiceFactory creates the event codec
+type EventCodecServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new event codec factory with the given configuration
+func NewFactory(config Config) *EventCodecServiceFactory {
+	return &EventCodecServiceFactory{config: config}
+}
+
+// Build returns the codec of the configured serialization, compressing
+// when enabled
+func (f *EventCodecServiceFactory) Build() (eventcodec.Service, error) {
+	codec, err := f.buildSerializer()
+	if err != nil {
+		return nil, err
+	}
+	if f.config.Compress {
+		return compression.NewService(codec, f.config.Compression)
+	}
+	return codec, nil
+}
+
+// buildSerializer creates the codec of the configured wire format
+func (f *EventCodecServiceFactory) buildSerializer() (eventcodec.Service, error) {
+	switch f.config.Serialization {
+	case "", eventcodec.SerializationJSON:
+		return plainjson.NewService(), nil
+	case eventcodec.SerializationCloudEvents:
+		return cloudevents.NewService(f.config.Source), nil
+	case eventcodec.SerializationProtobuf:
+		return protobuf.NewService(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", eventcodec.ErrUnknownSerialization, f.config.Serialization)
+	}
+}
@@ -0,0 +1,40 @@
+package plainjson
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// ContentType is the media type of plain JSON events
+const ContentType = "application/json"
+
+// service implements eventcodec.Service with the JSON form of events.Event
+// itself, the format the providers have always published
+type service struct{}
+
+// NewService creates a plain JSON event codec
+func NewService() eventcodec.Service {
+	return service{}
+}
+
+// Encode marshals the event as is
+func (service) Encode(event events.Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Decode unmarshals an event marshalled by Encode
+func (service) Decode(body []byte) (events.Event, error) {
+	var event events.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+	}
+	return event, nil
+}
+
+// ContentType returns application/json
+func (service) ContentType() string {
+	return ContentType
+}
@@ -0,0 +1,130 @@
+package protobuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/protobuf/eventpb"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// ContentType is the media type of protobuf events
+const ContentType = "application/x-protobuf"
+
+// service implements eventcodec.Service with the eventpb.Event message,
+// generated from eventpb/event.proto. Data travels as a
+// google.protobuf.Struct, so numbers decode as float64 as they do from JSON.
+type service struct{}
+
+// NewService creates a protobuf event codec
+func NewService() eventcodec.Service {
+	return service{}
+}
+
+// Encode marshals the event as an eventpb.Event
+func (service) Encode(event events.Event) ([]byte, error) {
+	message := &eventpb.Event{
+		Id:            event.ID,
+		Type:          event.Type,
+		AggregateId:   event.AggregateID,
+		AggregateType: event.AggregateType,
+		Version:       int64(event.Version),
+		SchemaVersion: int64(event.SchemaVersion),
+		Metadata: &eventpb.EventMetadata{
+			UserId:        event.Metadata.UserID,
+			CorrelationId: event.Metadata.CorrelationID,
+			CausationId:   event.Metadata.CausationID,
+			Source:        event.Metadata.Source,
+			Headers:       event.Metadata.Headers,
+			IpAddress:     event.Metadata.IPAddress,
+			UserAgent:     event.Metadata.UserAgent,
+		},
+	}
+	if event.Data != nil {
+		data, err := toStruct(event.Data)
+		if err != nil {
+			return nil, fmt.Errorf("event data: %w", err)
+		}
+		message.Data = data
+	}
+	if !event.Timestamp.IsZero() {
+		message.Timestamp = timestamppb.New(event.Timestamp)
+	}
+	return proto.Marshal(message)
+}
+
+// Decode unmarshals an eventpb.Event. Bodies starting with "{" are read as
+// plain JSON events, so queues holding events published before the switch
+// to protobuf still drain.
+func (service) Decode(body []byte) (events.Event, error) {
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		var event events.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+		}
+		return event, nil
+	}
+
+	var message eventpb.Event
+	if err := proto.Unmarshal(body, &message); err != nil {
+		return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+	}
+	if message.GetId() == "" || message.GetType() == "" {
+		return events.Event{}, fmt.Errorf("%w: id and type are required", eventcodec.ErrDecodeFailed)
+	}
+
+	metadata := message.GetMetadata()
+	event := events.Event{
+		ID:            message.GetId(),
+		Type:          message.GetType(),
+		AggregateID:   message.GetAggregateId(),
+		AggregateType: message.GetAggregateType(),
+		Version:       int(message.GetVersion()),
+		SchemaVersion: int(message.GetSchemaVersion()),
+		Metadata: events.EventMetadata{
+			UserID:        metadata.GetUserId(),
+			CorrelationID: metadata.GetCorrelationId(),
+			CausationID:   metadata.GetCausationId(),
+			Source:        metadata.GetSource(),
+			Headers:       metadata.GetHeaders(),
+			IPAddress:     metadata.GetIpAddress(),
+			UserAgent:     metadata.GetUserAgent(),
+		},
+	}
+	if message.Data != nil {
+		event.Data = message.Data.AsMap()
+	}
+	if message.Timestamp != nil {
+		event.Timestamp = message.Timestamp.AsTime()
+	}
+	return event, nil
+}
+
+// ContentType returns application/x-protobuf
+func (service) ContentType() string {
+	return ContentType
+}
+
+// toStruct converts event data to a Struct. Data built in Go may hold
+// values structpb does not take, such as typed slices or structs, so it
+// goes through JSON first as the JSON codecs would.
+func toStruct(data map[string]interface{}) (*structpb.Struct, error) {
+	if converted, err := structpb.NewStruct(data); err == nil {
+		return converted, nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(normalized)
+}
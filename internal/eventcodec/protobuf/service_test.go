@@ -0,0 +1,87 @@
+package protobuf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/protobuf"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestDecode_GivenBody_WhenDecoding_ThenReadsTheEvent(t *testing.T) {
+	event := *testkit.NewEventBuilder().
+		WithData("email", "jane@example.com").
+		WithData("roles", []interface{}{"admin"}).
+		WithData("attempts", 3).
+		WithCorrelationID("request-1", "cause-1").
+		WithUserID("user-1").
+		WithVersion(4).
+		Build()
+	event.SchemaVersion = 2
+	event.Metadata.Headers = map[string]string{"dry_run": "true"}
+	expected := event
+	expected.Data = map[string]interface{}{"email": "jane@example.com", "roles": []interface{}{"admin"}, "attempts": float64(3)}
+	encoded, err := protobuf.NewService().Encode(event)
+	require.NoError(t, err)
+	plain, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		body          []byte
+		expectedError error
+	}{
+		{
+			name: "Given an encoded event, When decoding, Then round-trips it with JSON numbers",
+			body: encoded,
+		},
+		{
+			name: "Given a plain JSON event, When decoding, Then reads it as published before protobuf",
+			body: plain,
+		},
+		{
+			name:          "Given bytes that are not an event, When decoding, Then fails",
+			body:          []byte{0xff, 0xff, 0xff},
+			expectedError: eventcodec.ErrDecodeFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			codec := protobuf.NewService()
+
+			// Act
+			decoded, err := codec.Decode(tt.body)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, expected.Timestamp.Equal(decoded.Timestamp))
+			decoded.Timestamp = expected.Timestamp
+			assert.Equal(t, expected, decoded)
+		})
+	}
+}
+
+func TestEncode_GivenEvent_WhenEncoding_ThenIsSmallerThanJSON(t *testing.T) {
+	// Arrange
+	event := *testkit.NewEventBuilder().WithData("document", map[string]interface{}{"pages": 12, "title": "Quarterly report"}).Build()
+	plain, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	// Act
+	encoded, err := protobuf.NewService().Encode(event)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Less(t, len(encoded), len(plain))
+	assert.Equal(t, protobuf.ContentType, protobuf.NewService().ContentType())
+}
@@ -0,0 +1,77 @@
+package auditprojection
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// HandlerID names the handler in its EventHandlerConfig
+const HandlerID = "audit-projection"
+
+// handledTypes are the auth events projected into the audit trail
+var handledTypes = []string{events.EventTypeUserLoggedIn, events.EventTypePasswordChanged}
+
+// service implements eventhandler.Service by recording auth events in the
+// audit store
+type service struct {
+	audit audit.Service
+}
+
+// NewService creates a handler projecting auth events into the audit store
+func NewService(auditService audit.Service) eventhandler.Service {
+	return &service{audit: auditService}
+}
+
+// DefaultConfig dispatches the projected auth events to the handler
+func DefaultConfig() eventhandler.EventHandlerConfig {
+	config := eventhandler.DefaultEventHandlerConfig()
+	config.HandlerID = HandlerID
+	config.EventTypes = append([]string(nil), handledTypes...)
+	return config
+}
+
+// Handle records the event as an audit entry with the event type as its
+// action. The entry takes the event's ID, so a store can recognise an event
+// delivered twice, and the event's correlation with the event as its cause.
+func (s *service) Handle(ctx context.Context, event interface{}) error {
+	e, ok := event.(events.Event)
+	if !ok || !handles(e.Type) {
+		return eventhandler.ErrInvalidEventType
+	}
+
+	userID, _ := e.Data["user_id"].(string)
+	if userID == "" {
+		userID = e.AggregateID
+	}
+	return s.audit.Log(ctx, audit.AuditEntry{
+		ID:            e.ID,
+		Timestamp:     e.Timestamp,
+		UserID:        userID,
+		Action:        e.Type,
+		Resource:      e.AggregateType,
+		ResourceID:    e.AggregateID,
+		Details:       e.Data,
+		Success:       true,
+		IPAddress:     e.Metadata.IPAddress,
+		UserAgent:     e.Metadata.UserAgent,
+		CorrelationID: e.Metadata.CorrelationID,
+		CausationID:   e.ID,
+	})
+}
+
+// GetHandledEventTypes returns the projected auth events
+func (s *service) GetHandledEventTypes() []string {
+	return append([]string(nil), handledTypes...)
+}
+
+func handles(eventType string) bool {
+	for _, t := range handledTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
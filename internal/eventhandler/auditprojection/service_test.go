@@ -0,0 +1,58 @@
+package auditprojection_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/auditprojection"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestHandle_GivenAuthEvent_WhenHandling_ThenRecordsAuditEntry(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := auditMemory.NewService()
+	handler := auditprojection.NewService(store)
+	event := *testkit.NewEventBuilder().
+		ForAggregate("user", "user-1").
+		WithType(events.EventTypeUserLoggedIn).
+		WithData("user_id", "user-1").
+		WithCorrelationID("corr-1", "").
+		Build()
+
+	// Act
+	err := handler.Handle(ctx, event)
+
+	// Assert
+	require.NoError(t, err)
+	entries, err := store.GetAuditLogs(ctx, audit.AuditFilters{Action: events.EventTypeUserLoggedIn})
+	require.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, event.ID, entries[0].ID)
+		assert.Equal(t, "user-1", entries[0].UserID)
+		assert.Equal(t, "user", entries[0].Resource)
+		assert.Equal(t, "corr-1", entries[0].CorrelationID)
+		assert.True(t, entries[0].Success)
+	}
+}
+
+func TestHandle_GivenUnprojectedEvent_WhenHandling_ThenReturnsInvalidEventType(t *testing.T) {
+	// Arrange
+	store := auditMemory.NewService()
+	handler := auditprojection.NewService(store)
+
+	// Act
+	err := handler.Handle(context.Background(), *testkit.NewEventBuilder().Build())
+
+	// Assert
+	assert.ErrorIs(t, err, eventhandler.ErrInvalidEventType)
+	entries, _ := store.GetAuditLogs(context.Background(), audit.AuditFilters{})
+	assert.Empty(t, entries)
+}
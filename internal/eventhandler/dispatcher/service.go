@@ -0,0 +1,364 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// deadLetterTimeout bounds how long a worker waits to dead-letter an event
+const deadLetterTimeout = 5 * time.Second
+
+// batchHandler is implemented by handlers that process a whole batch at
+// once; other handlers get the events of a batch one by one
+type batchHandler interface {
+	HandleBatch(ctx context.Context, events []interface{}) error
+}
+
+// Service implements eventhandler.Service by running a handler on a bounded
+// worker pool as its EventHandlerConfig says. Handle only queues the event:
+// events are grouped into batches of up to BatchSize, or whatever arrived
+// within BatchTimeout, and Concurrency workers hand them to the handler.
+// Every attempt is bounded by Timeout and failures are retried with the
+// configured backoff; events still failing after MaxRetries go to the
+// dead-letter queue when one is set, and are dropped otherwise.
+type Service struct {
+	handler eventhandler.Service
+	config  eventhandler.EventHandlerConfig
+
+	timeout       time.Duration
+	batchTimeout  time.Duration
+	initialDelay  time.Duration
+	maxDelay      time.Duration
+	backoffFactor float64
+
+	// queueMu guards sends on queue against Close closing it
+	queueMu sync.RWMutex
+	queue   chan interface{}
+	closed  bool
+
+	batches  chan []interface{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+
+	mu          sync.RWMutex
+	deadLetters deadletter.Service
+}
+
+// NewService starts a dispatcher running handler as configured. Empty
+// durations fall back to DefaultEventHandlerConfig, and BatchSize and
+// Concurrency to one.
+func NewService(handler eventhandler.Service, config eventhandler.EventHandlerConfig) (*Service, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+	defaults := eventhandler.DefaultEventHandlerConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.RetryConfig.MaxRetries < 0 {
+		return nil, fmt.Errorf("max retries cannot be negative")
+	}
+
+	s := &Service{
+		handler:       handler,
+		config:        config,
+		backoffFactor: config.RetryConfig.BackoffFactor,
+		queue:         make(chan interface{}, config.BatchSize*config.Concurrency),
+		batches:       make(chan []interface{}),
+		stop:          make(chan struct{}),
+	}
+	durations := []struct {
+		name     string
+		value    string
+		fallback string
+		target   *time.Duration
+	}{
+		{"timeout", config.Timeout, defaults.Timeout, &s.timeout},
+		{"batch timeout", config.BatchTimeout, defaults.BatchTimeout, &s.batchTimeout},
+		{"initial delay", config.RetryConfig.InitialDelay, defaults.RetryConfig.InitialDelay, &s.initialDelay},
+		{"max delay", config.RetryConfig.MaxDelay, defaults.RetryConfig.MaxDelay, &s.maxDelay},
+	}
+	for _, d := range durations {
+		value := d.value
+		if value == "" {
+			value = d.fallback
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid %s %q", d.name, d.value)
+		}
+		*d.target = parsed
+	}
+
+	go s.collect()
+	s.workers.Add(config.Concurrency)
+	for i := 0; i < config.Concurrency; i++ {
+		go s.work()
+	}
+	return s, nil
+}
+
+// Subscribe starts a dispatcher running handler as configured and subscribes
+// it to the bus for the configured event types, or for every event the
+// handler reports handling when there are none
+func Subscribe(ctx context.Context, bus events.Service, handler eventhandler.Service, config eventhandler.EventHandlerConfig) (*Service, error) {
+	if !config.IsEnabled() {
+		return nil, eventhandler.ErrHandlerDisabled
+	}
+	s, err := NewService(handler, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Subscribe(ctx, config.EventTypes, s); err != nil {
+		_ = s.Close(ctx)
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetDeadLetters sends the events the handler keeps failing on to the
+// dead-letter queue instead of dropping them
+func (s *Service) SetDeadLetters(queue deadletter.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLetters = queue
+}
+
+// Handle queues the event for the workers. It waits for room while the
+// queue is full, until the context ends.
+func (s *Service) Handle(ctx context.Context, event interface{}) error {
+	if e, ok := event.(events.Event); ok && len(s.config.EventTypes) > 0 && !s.config.HandlesEventType(e.Type) {
+		return eventhandler.ErrInvalidEventType
+	}
+
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	if s.closed {
+		return eventhandler.ErrHandlerDisabled
+	}
+
+	select {
+	case s.queue <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetHandledEventTypes returns the configured event types, or the handler's
+// when none are configured
+func (s *Service) GetHandledEventTypes() []string {
+	if len(s.config.EventTypes) > 0 {
+		return s.config.EventTypes
+	}
+	return s.handler.GetHandledEventTypes()
+}
+
+// Close stops accepting events and waits until the queued ones are handled.
+// When the context ends first, retries waiting for their backoff give up
+// and the events in them are dead-lettered.
+func (s *Service) Close(ctx context.Context) error {
+	s.queueMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.queueMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.stopOnce.Do(func() { close(s.stop) })
+		return ctx.Err()
+	}
+}
+
+// collect groups queued events into batches for the workers, handing over a
+// batch once it is full or BatchTimeout after its first event
+func (s *Service) collect() {
+	defer close(s.batches)
+
+	var batch []interface{}
+	timer := time.NewTimer(s.batchTimeout)
+	timer.Stop()
+	flush := func() {
+		timer.Stop()
+		if len(batch) > 0 {
+			s.batches <- batch
+			batch = nil
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) == 1 && s.config.BatchSize > 1 {
+				timer.Reset(s.batchTimeout)
+			}
+			if len(batch) >= s.config.BatchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// work handles batches until the collector stops
+func (s *Service) work() {
+	defer s.workers.Done()
+	for batch := range s.batches {
+		if h, ok := s.handler.(batchHandler); ok {
+			s.process(batch, func(ctx context.Context) error { return h.HandleBatch(ctx, batch) })
+			continue
+		}
+		for _, event := range batch {
+			s.process([]interface{}{event}, func(ctx context.Context) error { return s.handler.Handle(caused(ctx, event), event) })
+		}
+	}
+}
+
+// process runs handle until it succeeds, retrying failures after the
+// configured backoff up to MaxRetries times, and dead-letters the events
+// when it gives up
+func (s *Service) process(batch []interface{}, handle func(ctx context.Context) error) {
+	retry := s.config.RetryConfig
+	delay := s.initialDelay
+	var firstFailedAt time.Time
+
+	// Single events are logged in their correlation
+	logCtx := context.Background()
+	if len(batch) == 1 {
+		logCtx = caused(logCtx, batch[0])
+	}
+
+	for attempt := 0; ; attempt++ {
+		panicked, err := s.attempt(handle)
+		if err == nil {
+			return
+		}
+		if firstFailedAt.IsZero() {
+			firstFailedAt = time.Now()
+		}
+		if panicked || attempt >= retry.MaxRetries {
+			correlation.Logf(logCtx, "Handler %s giving up on %d events after %d attempts: %v", s.config.HandlerID, len(batch), attempt+1, err)
+			s.deadLetter(batch, err, attempt+1, panicked, firstFailedAt)
+			return
+		}
+		correlation.Logf(logCtx, "Handler %s failed, retrying in %s: %v", s.config.HandlerID, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			timer.Stop()
+			s.deadLetter(batch, err, attempt+1, false, firstFailedAt)
+			return
+		}
+
+		if s.backoffFactor > 1 {
+			delay = time.Duration(float64(delay) * s.backoffFactor)
+		}
+		if delay > s.maxDelay {
+			delay = s.maxDelay
+		}
+	}
+}
+
+// attempt calls handle once within Timeout, turning a panic into an error.
+// A handler ignoring its context is left running when the timeout passes.
+func (s *Service) attempt(handle func(ctx context.Context) error) (panicked bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	type result struct {
+		panicked bool
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{panicked: true, err: fmt.Errorf("handler panicked: %v", r)}
+			}
+		}()
+		done <- result{err: handle(ctx)}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil {
+			return false, eventhandler.ErrHandlerTimeout
+		}
+		return r.panicked, r.err
+	case <-ctx.Done():
+		return false, eventhandler.ErrHandlerTimeout
+	}
+}
+
+// deadLetter records the events of a batch the handler gave up on, if a
+// queue is set
+func (s *Service) deadLetter(batch []interface{}, err error, attempts int, poison bool, firstFailedAt time.Time) {
+	s.mu.RLock()
+	queue := s.deadLetters
+	s.mu.RUnlock()
+	if queue == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterTimeout)
+	defer cancel()
+	for _, item := range batch {
+		event, ok := item.(events.Event)
+		if !ok {
+			log.Printf("Cannot dead-letter %T for handler %s", item, s.config.HandlerID)
+			continue
+		}
+		letter := deadletter.Letter{
+			Event:         event,
+			Provider:      "dispatcher",
+			Subscription:  s.config.HandlerID,
+			Handler:       fmt.Sprintf("%T", s.handler),
+			Error:         err.Error(),
+			Attempts:      attempts,
+			Poison:        poison,
+			FirstFailedAt: firstFailedAt,
+		}
+		if _, err := queue.Add(ctx, letter); err != nil {
+			log.Printf("Failed to dead-letter event %s: %v", event.ID, err)
+		}
+	}
+}
+
+// caused puts the context in the correlation of the event it handles, which
+// the dispatcher's own workers do not inherit from the bus
+func caused(ctx context.Context, event interface{}) context.Context {
+	if e, ok := event.(events.Event); ok {
+		return correlation.Caused(ctx, e.ID, e.Metadata.CorrelationID)
+	}
+	return ctx
+}
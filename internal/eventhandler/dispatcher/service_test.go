@@ -0,0 +1,284 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	deadLetterMemory "github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/dispatcher"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recordingHandler records the events it handles, failing the first
+// failures calls and blocking each call until release is closed
+type recordingHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	inFlight int
+	peak     int
+	received []interface{}
+	batches  []int
+	release  chan struct{}
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	return h.handle(ctx, []interface{}{event})
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return nil
+}
+
+func (h *recordingHandler) handle(ctx context.Context, evts []interface{}) error {
+	h.mu.Lock()
+	h.calls++
+	h.inFlight++
+	if h.inFlight > h.peak {
+		h.peak = h.inFlight
+	}
+	failing := h.calls <= h.failures
+	h.mu.Unlock()
+
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inFlight--
+	if failing {
+		return errors.New("handler unavailable")
+	}
+	h.received = append(h.received, evts...)
+	h.batches = append(h.batches, len(evts))
+	return nil
+}
+
+func (h *recordingHandler) snapshot() (calls, peak int, received []interface{}, batches []int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls, h.peak, append([]interface{}(nil), h.received...), append([]int(nil), h.batches...)
+}
+
+// batchRecordingHandler takes whole batches
+type batchRecordingHandler struct {
+	recordingHandler
+}
+
+func (h *batchRecordingHandler) HandleBatch(ctx context.Context, evts []interface{}) error {
+	return h.handle(ctx, evts)
+}
+
+func testConfig() eventhandler.EventHandlerConfig {
+	config := eventhandler.DefaultEventHandlerConfig()
+	config.HandlerID = "test-handler"
+	config.RetryConfig.InitialDelay = "1ms"
+	config.RetryConfig.MaxDelay = "5ms"
+	return config
+}
+
+func newEvent() events.Event {
+	event := *testkit.NewEventBuilder().Build()
+	event.ID = uuid.New().String()
+	return event
+}
+
+func closeDispatcher(t *testing.T, s *dispatcher.Service) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestDispatcher_GivenBatchSize_WhenEventsArrive_ThenHandsThemOverInBatches(t *testing.T) {
+	tests := []struct {
+		name            string
+		published       int
+		expectedBatches []int
+	}{
+		{
+			name:            "Given a full batch, When events arrive, Then handles them together",
+			published:       3,
+			expectedBatches: []int{3},
+		},
+		{
+			name:            "Given a partial batch, When the batch timeout passes, Then handles what arrived",
+			published:       2,
+			expectedBatches: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := testConfig()
+			config.BatchSize = 3
+			config.BatchTimeout = "20ms"
+			handler := &batchRecordingHandler{}
+			s, err := dispatcher.NewService(handler, config)
+			require.NoError(t, err)
+
+			// Act
+			for i := 0; i < tt.published; i++ {
+				require.NoError(t, s.Handle(context.Background(), newEvent()))
+			}
+
+			// Assert
+			require.Eventually(t, func() bool {
+				_, _, received, _ := handler.snapshot()
+				return len(received) == tt.published
+			}, 5*time.Second, 5*time.Millisecond)
+			_, _, _, batches := handler.snapshot()
+			assert.Equal(t, tt.expectedBatches, batches)
+			closeDispatcher(t, s)
+		})
+	}
+}
+
+func TestDispatcher_GivenConcurrency_WhenHandlerIsSlow_ThenRunsAtMostThatManyAtOnce(t *testing.T) {
+	// Arrange
+	config := testConfig()
+	config.Concurrency = 2
+	handler := &recordingHandler{release: make(chan struct{})}
+	s, err := dispatcher.NewService(handler, config)
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Handle(context.Background(), newEvent()))
+	}
+	require.Eventually(t, func() bool {
+		calls, _, _, _ := handler.snapshot()
+		return calls == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	close(handler.release)
+	closeDispatcher(t, s)
+
+	// Assert
+	calls, peak, received, _ := handler.snapshot()
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, 2, peak)
+	assert.Len(t, received, 5)
+}
+
+func TestDispatcher_GivenFailingHandler_WhenHandling_ThenRetriesAndDeadLettersWhenGivingUp(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       *recordingHandler
+		timeout       string
+		expectedCalls int
+		delivered     bool
+		expectedError string
+	}{
+		{
+			name:          "Given a handler failing twice, When handling, Then succeeds on the third attempt",
+			handler:       &recordingHandler{failures: 2},
+			expectedCalls: 3,
+			delivered:     true,
+		},
+		{
+			name:          "Given a handler failing past the retries, When handling, Then dead-letters after MaxRetries",
+			handler:       &recordingHandler{failures: 10},
+			expectedCalls: 4,
+			expectedError: "handler unavailable",
+		},
+		{
+			name:          "Given a handler outliving its timeout, When handling, Then fails every attempt with a timeout",
+			handler:       &recordingHandler{release: make(chan struct{})},
+			timeout:       "5ms",
+			expectedCalls: 4,
+			expectedError: eventhandler.ErrHandlerTimeout.Message,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := testConfig()
+			if tt.timeout != "" {
+				config.Timeout = tt.timeout
+			}
+			s, err := dispatcher.NewService(tt.handler, config)
+			require.NoError(t, err)
+			queue := deadLetterMemory.NewService()
+			s.SetDeadLetters(queue)
+			event := newEvent()
+
+			// Act
+			require.NoError(t, s.Handle(context.Background(), event))
+			closeDispatcher(t, s)
+
+			// Assert
+			calls, _, received, _ := tt.handler.snapshot()
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.delivered, len(received) == 1)
+			letters, err := queue.List(context.Background(), deadletter.Filter{})
+			require.NoError(t, err)
+			if tt.delivered {
+				assert.Empty(t, letters)
+				return
+			}
+			if assert.Len(t, letters, 1) {
+				assert.Equal(t, event.ID, letters[0].Event.ID)
+				assert.Equal(t, "test-handler", letters[0].Subscription)
+				assert.Equal(t, tt.expectedCalls, letters[0].Attempts)
+				assert.Equal(t, tt.expectedError, letters[0].Error)
+			}
+		})
+	}
+}
+
+func TestSubscribe_GivenBus_WhenPublishing_ThenDispatchesConfiguredEventTypes(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	config := testConfig()
+	config.EventTypes = []string{events.EventTypeUserUpdated}
+	handler := &recordingHandler{}
+	s, err := dispatcher.Subscribe(ctx, bus, handler, config)
+	require.NoError(t, err)
+	updated := newEvent()
+	updated.Type = events.EventTypeUserUpdated
+
+	// Act
+	require.NoError(t, bus.Publish(ctx, newEvent()))
+	require.NoError(t, bus.Publish(ctx, updated))
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(closeCtx))
+	closeDispatcher(t, s)
+
+	// Assert
+	_, _, received, _ := handler.snapshot()
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, updated.ID, received[0].(events.Event).ID)
+	}
+}
+
+func TestSubscribe_GivenDisabledConfig_WhenSubscribing_ThenReturnsHandlerDisabled(t *testing.T) {
+	// Arrange
+	config := testConfig()
+	config.Enabled = false
+
+	// Act
+	s, err := dispatcher.Subscribe(context.Background(), eventsMemory.NewService(events.DefaultEventConfig()), &recordingHandler{}, config)
+
+	// Assert
+	assert.Nil(t, s)
+	assert.ErrorIs(t, err, eventhandler.ErrHandlerDisabled)
+}
@@ -22,6 +22,10 @@ type EventHandlerConfig struct {
 	BatchSize   int               `json:"batch_size"`
 	Concurrency int               `json:"concurrency"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// BatchTimeout is how long a partial batch waits for more events before
+	// it is handled anyway
+	BatchTimeout string `json:"batch_timeout,omitempty"`
 }
 
 // RetryConfig contains retry configuration for failed event handling
@@ -79,10 +83,11 @@ func (r *RetryConfig) IsValid() bool {
 // Default event handler configuration
 func DefaultEventHandlerConfig() EventHandlerConfig {
 	return EventHandlerConfig{
-		Enabled:     true,
-		BatchSize:   1,
-		Concurrency: 1,
-		Timeout:     "30s",
+		Enabled:      true,
+		BatchSize:    1,
+		BatchTimeout: "1s",
+		Concurrency:  1,
+		Timeout:      "30s",
 		RetryConfig: RetryConfig{
 			MaxRetries:    3,
 			InitialDelay:  "1s",
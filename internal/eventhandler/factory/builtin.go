@@ -0,0 +1,114 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/auditprojection"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/dispatcher"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/welcomeemail"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	"github.com/gentra/decorator-arch-go/internal/webhook/delivery"
+)
+
+// Registration pairs a handler with the configuration it is dispatched with
+type Registration struct {
+	Handler eventhandler.Service
+	Config  eventhandler.EventHandlerConfig
+}
+
+// BuiltinDependencies are the services the built-in handlers work with
+type BuiltinDependencies struct {
+	NotificationService notification.Service
+	AuditService        audit.Service
+	WebhookService      webhook.Service
+}
+
+// BuiltinHandlers returns the registrations of the handlers shipped with the
+// application named in handlerIDs, "welcome-email", "audit-projection" and
+// "webhooks", with their default configuration
+func BuiltinHandlers(handlerIDs []string, deps BuiltinDependencies) ([]Registration, error) {
+	var registrations []Registration
+	for _, id := range handlerIDs {
+		switch id {
+		case welcomeemail.HandlerID:
+			if deps.NotificationService == nil {
+				return nil, fmt.Errorf("notification service is required for the %s handler", id)
+			}
+			registrations = append(registrations, Registration{
+				Handler: welcomeemail.NewService(deps.NotificationService),
+				Config:  welcomeemail.DefaultConfig(),
+			})
+		case auditprojection.HandlerID:
+			if deps.AuditService == nil {
+				return nil, fmt.Errorf("audit service is required for the %s handler", id)
+			}
+			registrations = append(registrations, Registration{
+				Handler: auditprojection.NewService(deps.AuditService),
+				Config:  auditprojection.DefaultConfig(),
+			})
+		case delivery.HandlerID:
+			if deps.WebhookService == nil {
+				return nil, fmt.Errorf("webhook service is required for the %s handler", id)
+			}
+			registrations = append(registrations, Registration{
+				Handler: delivery.NewService(deps.WebhookService, nil),
+				Config:  delivery.DefaultConfig(),
+			})
+		default:
+			return nil, fmt.Errorf("%w: %s", eventhandler.ErrHandlerNotFound, id)
+		}
+	}
+	return registrations, nil
+}
+
+// ReplayableHandlers returns by ID every built-in handler whose services
+// deps provides, for replays into handlers whether subscribed or not
+func ReplayableHandlers(deps BuiltinDependencies) map[string]eventhandler.Service {
+	var handlerIDs []string
+	if deps.NotificationService != nil {
+		handlerIDs = append(handlerIDs, welcomeemail.HandlerID)
+	}
+	if deps.AuditService != nil {
+		handlerIDs = append(handlerIDs, auditprojection.HandlerID)
+	}
+	if deps.WebhookService != nil {
+		handlerIDs = append(handlerIDs, delivery.HandlerID)
+	}
+	registrations, _ := BuiltinHandlers(handlerIDs, deps)
+
+	handlers := make(map[string]eventhandler.Service, len(registrations))
+	for _, r := range registrations {
+		handlers[r.Config.HandlerID] = r.Handler
+	}
+	return handlers
+}
+
+// Subscribe dispatches the events of the bus to every enabled registration,
+// dead-lettering the events they give up on when deadLetters is set. The
+// dispatchers are returned to be closed once the bus has drained.
+func Subscribe(ctx context.Context, bus events.Service, registrations []Registration, deadLetters deadletter.Service) ([]*dispatcher.Service, error) {
+	var dispatchers []*dispatcher.Service
+	for _, r := range registrations {
+		if !r.Config.IsEnabled() {
+			continue
+		}
+		d, err := dispatcher.NewService(r.Handler, r.Config)
+		if err != nil {
+			return dispatchers, fmt.Errorf("handler %s: %w", r.Config.HandlerID, err)
+		}
+		dispatchers = append(dispatchers, d)
+		if deadLetters != nil {
+			d.SetDeadLetters(deadLetters)
+		}
+		if err := bus.Subscribe(ctx, r.Config.EventTypes, d); err != nil {
+			return dispatchers, fmt.Errorf("handler %s: %w", r.Config.HandlerID, err)
+		}
+	}
+	return dispatchers, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/dispatcher"
 )
 
 // Config contains all configuration for building the event handler service
@@ -12,6 +13,10 @@ type Config struct {
 	// Handler configuration
 	HandlerType string // "sync", "async", "batch", "stream"
 
+	// Handler is the handler the async and batch types run on the dispatcher
+	Handler   eventhandler.Service
+	HandlerID string
+
 	// Processing configuration
 	Concurrency    int
 	BufferSize     int
@@ -120,16 +125,44 @@ func (f *EventHandlerServiceFactory) buildSyncHandler() (eventhandler.Service, e
 	return nil, fmt.Errorf("synchronous event handler not yet implemented")
 }
 
-// buildAsyncHandler creates an asynchronous event handler (placeholder)
+// buildAsyncHandler runs the handler on the dispatcher's worker pool, one
+// event at a time
 func (f *EventHandlerServiceFactory) buildAsyncHandler() (eventhandler.Service, error) {
-	// TODO: Implement asynchronous event handler
-	return nil, fmt.Errorf("asynchronous event handler not yet implemented")
+	return f.buildDispatcher(1)
 }
 
-// buildBatchHandler creates a batch event handler (placeholder)
+// buildBatchHandler runs the handler on the dispatcher's worker pool in
+// batches of BatchSize
 func (f *EventHandlerServiceFactory) buildBatchHandler() (eventhandler.Service, error) {
-	// TODO: Implement batch event handler
-	return nil, fmt.Errorf("batch event handler not yet implemented")
+	return f.buildDispatcher(f.config.BatchSize)
+}
+
+// buildDispatcher starts a dispatcher for the handler with the processing
+// and retry settings; retries only happen when EnableRetryLogic is set
+func (f *EventHandlerServiceFactory) buildDispatcher(batchSize int) (eventhandler.Service, error) {
+	if f.config.Handler == nil {
+		return nil, fmt.Errorf("handler is required for %s event handlers", f.config.HandlerType)
+	}
+
+	config := eventhandler.EventHandlerConfig{
+		HandlerID:    f.config.HandlerID,
+		EventTypes:   f.config.EventTypes,
+		Enabled:      true,
+		Timeout:      durationString(f.config.ProcessTimeout),
+		BatchSize:    batchSize,
+		BatchTimeout: durationString(f.config.BatchTimeout),
+		Concurrency:  f.config.Concurrency,
+		RetryConfig: eventhandler.RetryConfig{
+			MaxRetries:    f.config.MaxRetries,
+			InitialDelay:  durationString(f.config.InitialDelay),
+			BackoffFactor: f.config.BackoffFactor,
+			MaxDelay:      durationString(f.config.MaxDelay),
+		},
+	}
+	if !f.config.Features.EnableRetryLogic {
+		config.RetryConfig.MaxRetries = 0
+	}
+	return dispatcher.NewService(f.config.Handler, config)
 }
 
 // buildStreamHandler creates a stream event handler (placeholder)
@@ -138,6 +171,15 @@ func (f *EventHandlerServiceFactory) buildStreamHandler() (eventhandler.Service,
 	return nil, fmt.Errorf("stream event handler not yet implemented")
 }
 
+// durationString formats a duration for EventHandlerConfig, leaving unset
+// ones empty for the dispatcher's defaults
+func durationString(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
 // DefaultConfig returns a sensible default configuration for the event handler service
 func DefaultConfig() Config {
 	return Config{
@@ -180,6 +222,13 @@ func (b *ConfigBuilder) WithHandlerType(handlerType string) *ConfigBuilder {
 	return b
 }
 
+// WithHandler sets the handler the async and batch types run
+func (b *ConfigBuilder) WithHandler(handlerID string, handler eventhandler.Service) *ConfigBuilder {
+	b.config.HandlerID = handlerID
+	b.config.Handler = handler
+	return b
+}
+
 // WithConcurrency sets the number of concurrent handlers
 func (b *ConfigBuilder) WithConcurrency(concurrency int) *ConfigBuilder {
 	b.config.Concurrency = concurrency
@@ -0,0 +1,62 @@
+package welcomeemail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// HandlerID names the handler in its EventHandlerConfig
+const HandlerID = "welcome-email"
+
+// service implements eventhandler.Service by sending new users the welcome
+// email when they register
+type service struct {
+	notifications notification.Service
+}
+
+// NewService creates a handler sending welcome emails through the
+// notification service
+func NewService(notifications notification.Service) eventhandler.Service {
+	return &service{notifications: notifications}
+}
+
+// DefaultConfig dispatches user.registered events to the handler
+func DefaultConfig() eventhandler.EventHandlerConfig {
+	config := eventhandler.DefaultEventHandlerConfig()
+	config.HandlerID = HandlerID
+	config.EventTypes = []string{events.EventTypeUserRegistered}
+	return config
+}
+
+// Handle sends the welcome email to the registered user. Registrations made
+// in notification dry-run mode carry it in their metadata, and the email is
+// then only captured.
+func (s *service) Handle(ctx context.Context, event interface{}) error {
+	e, ok := event.(events.Event)
+	if !ok || e.Type != events.EventTypeUserRegistered {
+		return eventhandler.ErrInvalidEventType
+	}
+
+	var data events.UserRegisteredData
+	if err := e.DecodeData(&data); err != nil {
+		return fmt.Errorf("%w: %v", eventhandler.ErrHandlingFailed, err)
+	}
+	if data.Email == "" {
+		return fmt.Errorf("%w: event %s has no email", eventhandler.ErrHandlingFailed, e.ID)
+	}
+
+	if e.Metadata.Headers[string(notification.DryRunContextKey)] == "true" {
+		ctx = notification.WithDryRun(ctx)
+	}
+	return s.notifications.SendWelcomeEmail(ctx, data.Email, strings.TrimSpace(data.FirstName+" "+data.LastName))
+}
+
+// GetHandledEventTypes returns the user registered event
+func (s *service) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered}
+}
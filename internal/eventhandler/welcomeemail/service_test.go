@@ -0,0 +1,106 @@
+package welcomeemail_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler/welcomeemail"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// welcomeInbox records the welcome emails sent
+type welcomeInbox struct {
+	notification.Service
+	sent []string
+	dry  []bool
+}
+
+func (i *welcomeInbox) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	i.sent = append(i.sent, userEmail+"|"+userName)
+	i.dry = append(i.dry, notification.IsDryRun(ctx))
+	return nil
+}
+
+func registeredEvent(headers map[string]string) events.Event {
+	event := *testkit.NewEventBuilder().
+		WithData("email", "jane@example.com").
+		WithData("first_name", "Jane").
+		WithData("last_name", "Doe").
+		Build()
+	event.Metadata.Headers = headers
+	return event
+}
+
+func TestHandle_GivenUserRegisteredEvent_WhenHandling_ThenSendsWelcomeEmail(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		expectedDry bool
+	}{
+		{
+			name: "Given a registration, When handling, Then sends the welcome email",
+		},
+		{
+			name:        "Given a dry-run registration, When handling, Then only captures the email",
+			headers:     map[string]string{string(notification.DryRunContextKey): "true"},
+			expectedDry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			inbox := &welcomeInbox{Service: notificationMock.NewService()}
+			handler := welcomeemail.NewService(inbox)
+
+			// Act
+			err := handler.Handle(context.Background(), registeredEvent(tt.headers))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, []string{"jane@example.com|Jane Doe"}, inbox.sent)
+			assert.Equal(t, []bool{tt.expectedDry}, inbox.dry)
+		})
+	}
+}
+
+func TestHandle_GivenUnusableEvent_WhenHandling_ThenFailsWithoutSending(t *testing.T) {
+	tests := []struct {
+		name          string
+		event         interface{}
+		expectedError error
+	}{
+		{
+			name:          "Given another event type, When handling, Then returns invalid event type",
+			event:         *testkit.NewEventBuilder().WithType(events.EventTypeUserUpdated).Build(),
+			expectedError: eventhandler.ErrInvalidEventType,
+		},
+		{
+			name:          "Given a registration without email, When handling, Then returns handling failed",
+			event:         *testkit.NewEventBuilder().Build(),
+			expectedError: eventhandler.ErrHandlingFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			inbox := &welcomeInbox{Service: notificationMock.NewService()}
+			handler := welcomeemail.NewService(inbox)
+
+			// Act
+			err := handler.Handle(context.Background(), tt.event)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Empty(t, inbox.sent)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package eventoutbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Service defines the event outbox domain interface - the ONLY interface in this domain.
+// It keeps events in the database that holds the state changes they
+// describe, so a change and its events commit together, until a relay
+// publishes them to the event bus. Events are published at least once;
+// consumers recognize repeats by the event ID.
+type Service interface {
+	// Append records events to be published. Inside a transaction carried by
+	// the context (see pgtx) they commit or roll back with it. Events without
+	// an ID or timestamp get one; appending an event ID again is a no-op.
+	Append(ctx context.Context, evts ...events.Event) error
+
+	// Relay publishes up to limit pending entries, oldest first, and marks
+	// the ones published. Once an entry of an aggregate fails, its later
+	// entries wait for the next relay, so each aggregate's events are
+	// published in the order they were appended.
+	Relay(ctx context.Context, limit int, publish PublishFunc) (*RelayReport, error)
+
+	// Prune removes entries published before the given time and returns how
+	// many were removed
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// Domain types and data structures
+
+// PublishFunc sends one event to the event bus, typically events.Service.Publish
+type PublishFunc func(ctx context.Context, event events.Event) error
+
+// Entry is an event waiting in the outbox
+type Entry struct {
+	ID          int64        `json:"id"`
+	Event       events.Event `json:"event"`
+	Attempts    int          `json:"attempts"`
+	LastError   string       `json:"last_error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	PublishedAt *time.Time   `json:"published_at,omitempty"`
+}
+
+// RelayReport summarizes a relay: the entries published, the ones that
+// failed, and the ones deferred behind an earlier failure of their aggregate
+type RelayReport struct {
+	Published int `json:"published"`
+	Failed    int `json:"failed"`
+	Deferred  int `json:"deferred"`
+}
+
+// Outcome is the result of relaying one entry; neither published nor
+// failed when Deferred
+type Outcome struct {
+	EntryID  int64
+	Err      error
+	Deferred bool
+}
+
+// Published reports whether the entry was published
+func (o Outcome) Published() bool {
+	return o.Err == nil && !o.Deferred
+}
+
+// DefaultRelayLimit is how many entries a relay publishes when limit is not positive
+const DefaultRelayLimit = 100
+
+// Prepare gives an event without an ID or timestamp one
+func Prepare(event events.Event) events.Event {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	return event
+}
+
+// Deliver publishes entries, given oldest first, in order. Entries of an
+// aggregate whose earlier entry failed, and every entry once the context
+// ends, are deferred. Backends mark the entries from the outcomes.
+func Deliver(ctx context.Context, entries []Entry, publish PublishFunc) ([]Outcome, RelayReport) {
+	outcomes := make([]Outcome, len(entries))
+	var report RelayReport
+	blocked := make(map[string]bool)
+
+	for i, entry := range entries {
+		outcomes[i].EntryID = entry.ID
+		aggregate := entry.Event.AggregateType + "/" + entry.Event.AggregateID
+		if blocked[aggregate] || ctx.Err() != nil {
+			outcomes[i].Deferred = true
+			report.Deferred++
+			continue
+		}
+
+		if err := publish(ctx, entry.Event); err != nil {
+			outcomes[i].Err = fmt.Errorf("%w: %v", ErrRelayFailed, err)
+			blocked[aggregate] = true
+			report.Failed++
+			continue
+		}
+		report.Published++
+	}
+	return outcomes, report
+}
+
+// OutboxError represents event outbox domain errors
+type OutboxError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e OutboxError) Error() string {
+	return e.Message
+}
+
+// Common event outbox errors
+var (
+	ErrAppendFailed = OutboxError{Code: "EVENT_OUTBOX_APPEND_FAILED", Message: "Failed to record event in the outbox"}
+	ErrRelayFailed  = OutboxError{Code: "EVENT_OUTBOX_RELAY_FAILED", Message: "Failed to relay event from the outbox"}
+)
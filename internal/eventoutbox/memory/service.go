@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// service implements eventoutbox.Service interface in memory, for single
+// instances and tests. It shares no transaction with any store, so appended
+// events are kept even when the change they describe is rolled back.
+type service struct {
+	mu       sync.Mutex
+	entries  []eventoutbox.Entry // by ID, which only grows
+	eventIDs map[string]bool
+	nextID   int64
+
+	// relayMu lets one relay publish at a time, keeping aggregates in order
+	relayMu sync.Mutex
+}
+
+// NewService creates an empty in-memory event outbox
+func NewService() eventoutbox.Service {
+	return &service{eventIDs: make(map[string]bool)}
+}
+
+// Append records events that were not appended before
+func (s *service) Append(ctx context.Context, evts ...events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range evts {
+		event = eventoutbox.Prepare(event)
+		if s.eventIDs[event.ID] {
+			continue
+		}
+		s.eventIDs[event.ID] = true
+		s.nextID++
+		s.entries = append(s.entries, eventoutbox.Entry{ID: s.nextID, Event: event, CreatedAt: time.Now()})
+	}
+	return nil
+}
+
+// Relay publishes the oldest pending entries without holding the store, so
+// appends are not held up by the event bus
+func (s *service) Relay(ctx context.Context, limit int, publish eventoutbox.PublishFunc) (*eventoutbox.RelayReport, error) {
+	if limit <= 0 {
+		limit = eventoutbox.DefaultRelayLimit
+	}
+	s.relayMu.Lock()
+	defer s.relayMu.Unlock()
+
+	s.mu.Lock()
+	var pending []eventoutbox.Entry
+	for _, entry := range s.entries {
+		if len(pending) == limit {
+			break
+		}
+		if entry.PublishedAt == nil {
+			pending = append(pending, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	outcomes, report := eventoutbox.Deliver(ctx, pending, publish)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, outcome := range outcomes {
+		if outcome.Deferred {
+			continue
+		}
+		entry := s.find(outcome.EntryID)
+		if entry == nil {
+			continue
+		}
+		entry.Attempts++
+		if outcome.Err != nil {
+			entry.LastError = outcome.Err.Error()
+			continue
+		}
+		published := now
+		entry.PublishedAt = &published
+	}
+	return &report, nil
+}
+
+// Prune removes entries published before the given time
+func (s *service) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.entries[:0]
+	removed := 0
+	for _, entry := range s.entries {
+		if entry.PublishedAt != nil && entry.PublishedAt.Before(before) {
+			delete(s.eventIDs, entry.Event.ID)
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	s.entries = kept
+	return removed, nil
+}
+
+// find returns the entry with the given ID, or nil once it was pruned
+func (s *service) find(id int64) *eventoutbox.Entry {
+	for i := range s.entries {
+		if s.entries[i].ID == id {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox"
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recorder publishes events by recording them, failing the event IDs in failing
+type recorder struct {
+	published []events.Event
+	failing   map[string]bool
+}
+
+func (r *recorder) publish(ctx context.Context, event events.Event) error {
+	if r.failing[event.ID] {
+		return errors.New("broker unavailable")
+	}
+	r.published = append(r.published, event)
+	return nil
+}
+
+func eventFor(aggregateID string) events.Event {
+	event := *testkit.NewEventBuilder().ForAggregate("user", aggregateID).Build()
+	event.ID = uuid.New().String()
+	return event
+}
+
+func ids(evts []events.Event) []string {
+	result := make([]string, len(evts))
+	for i, event := range evts {
+		result[i] = event.ID
+	}
+	return result
+}
+
+func TestRelay_GivenAppendedEvents_WhenRelaying_ThenPublishesThemOnceInOrder(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	outbox := memory.NewService()
+	first, second := eventFor("user-1"), eventFor("user-2")
+	require.NoError(t, outbox.Append(ctx, first, second))
+	require.NoError(t, outbox.Append(ctx, first))
+	bus := &recorder{}
+
+	// Act
+	report, err := outbox.Relay(ctx, 0, bus.publish)
+	require.NoError(t, err)
+	again, err := outbox.Relay(ctx, 0, bus.publish)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, eventoutbox.RelayReport{Published: 2}, *report)
+	assert.Equal(t, eventoutbox.RelayReport{}, *again)
+	assert.Equal(t, []string{first.ID, second.ID}, ids(bus.published))
+}
+
+func TestRelay_GivenFailingEntry_WhenRelaying_ThenDefersLaterEntriesOfItsAggregate(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	outbox := memory.NewService()
+	failing, later, other := eventFor("user-1"), eventFor("user-1"), eventFor("user-2")
+	require.NoError(t, outbox.Append(ctx, failing, later, other))
+	bus := &recorder{failing: map[string]bool{failing.ID: true}}
+
+	// Act
+	report, err := outbox.Relay(ctx, 0, bus.publish)
+	require.NoError(t, err)
+	delete(bus.failing, failing.ID)
+	retried, err := outbox.Relay(ctx, 0, bus.publish)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, eventoutbox.RelayReport{Published: 1, Failed: 1, Deferred: 1}, *report)
+	assert.Equal(t, eventoutbox.RelayReport{Published: 2}, *retried)
+	assert.Equal(t, []string{other.ID, failing.ID, later.ID}, ids(bus.published))
+}
+
+func TestAppend_GivenEventWithoutID_WhenAppending_ThenAssignsOne(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	outbox := memory.NewService()
+	event := eventFor("user-1")
+	event.ID = ""
+	bus := &recorder{}
+
+	// Act
+	require.NoError(t, outbox.Append(ctx, event))
+	_, err := outbox.Relay(ctx, 0, bus.publish)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, bus.published, 1)
+	assert.NotEmpty(t, bus.published[0].ID)
+}
+
+func TestPrune_GivenPublishedAndPendingEntries_WhenPruning_ThenRemovesOnlyPublished(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	outbox := memory.NewService()
+	require.NoError(t, outbox.Append(ctx, eventFor("user-1")))
+	_, err := outbox.Relay(ctx, 0, (&recorder{}).publish)
+	require.NoError(t, err)
+	pending := eventFor("user-2")
+	require.NoError(t, outbox.Append(ctx, pending))
+
+	// Act
+	removed, err := outbox.Prune(ctx, time.Now().Add(time.Second))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	bus := &recorder{}
+	_, err = outbox.Relay(ctx, 0, bus.publish)
+	require.NoError(t, err)
+	assert.Equal(t, []string{pending.ID}, ids(bus.published))
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/pgtx"
+)
+
+// relayLock is the advisory lock key a relay holds for its transaction, so
+// one instance relays at a time and aggregates stay in order
+const relayLock int64 = 0x6576656e746f7574 // "eventout"
+
+// service implements eventoutbox.Service on the outbox table. Appends join
+// the transaction carried by the context, so events commit with the user
+// changes written in it.
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed event outbox
+func NewService(pool *pgxpool.Pool) eventoutbox.Service {
+	return &service{pool: pool}
+}
+
+// Append inserts events, skipping event IDs already in the outbox
+func (s *service) Append(ctx context.Context, evts ...events.Event) error {
+	db := pgtx.Use(ctx, s.pool)
+	for _, event := range evts {
+		event = eventoutbox.Prepare(event)
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("%w: %v", eventoutbox.ErrAppendFailed, err)
+		}
+
+		if _, err := db.Exec(ctx, `
+			INSERT INTO outbox (event_id, event_type, aggregate_type, aggregate_id, payload, created_at)
+			VALUES ($1, $2, $3, $4, $5, now())
+			ON CONFLICT (event_id) DO NOTHING`,
+			event.ID, event.Type, event.AggregateType, event.AggregateID, payload); err != nil {
+			return fmt.Errorf("%w: %v", eventoutbox.ErrAppendFailed, err)
+		}
+	}
+	return nil
+}
+
+// Relay publishes pending entries inside a transaction holding the relay
+// lock; while another instance holds it there is nothing to do. An event
+// published just before the transaction fails to commit is published again
+// by the next relay.
+func (s *service) Relay(ctx context.Context, limit int, publish eventoutbox.PublishFunc) (*eventoutbox.RelayReport, error) {
+	if limit <= 0 {
+		limit = eventoutbox.DefaultRelayLimit
+	}
+
+	report := &eventoutbox.RelayReport{}
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLock).Scan(&locked); err != nil || !locked {
+			return err
+		}
+
+		entries, err := pending(ctx, tx, limit)
+		if err != nil {
+			return err
+		}
+
+		outcomes, delivered := eventoutbox.Deliver(ctx, entries, publish)
+		*report = delivered
+		return mark(context.WithoutCancel(ctx), tx, outcomes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Prune deletes entries published before the given time
+func (s *service) Prune(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM outbox WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// pending reads the oldest unpublished entries
+func pending(ctx context.Context, tx pgx.Tx, limit int) ([]eventoutbox.Entry, error) {
+	rows, err := tx.Query(ctx, `SELECT id, payload, attempts, last_error, created_at
+		FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []eventoutbox.Entry
+	for rows.Next() {
+		var entry eventoutbox.Entry
+		var payload []byte
+		if err := rows.Scan(&entry.ID, &payload, &entry.Attempts, &entry.LastError, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &entry.Event); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox entry %d: %w", entry.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// mark records the outcomes of a relay; deferred entries are left as they are
+func mark(ctx context.Context, tx pgx.Tx, outcomes []eventoutbox.Outcome) error {
+	var published []int64
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Published():
+			published = append(published, outcome.EntryID)
+		case outcome.Err != nil:
+			if _, err := tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`,
+				outcome.EntryID, outcome.Err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+	if len(published) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, published_at = now() WHERE id = ANY($1)`, published)
+	return err
+}
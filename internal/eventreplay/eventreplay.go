@@ -0,0 +1,110 @@
+package eventreplay
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Service defines the event replay domain interface - the ONLY interface in this domain.
+// It feeds stored events matching filters back into chosen handlers, in
+// the order they were stored and at a bounded rate, to rebuild read models
+// or backfill a handler added after the events were published. Replays run
+// in the background and report their progress until they finish.
+type Service interface {
+	// Start begins a replay and returns it running
+	Start(ctx context.Context, request Request) (*Job, error)
+
+	// Get returns a replay with its progress, or ErrJobNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// List returns every replay, newest first
+	List(ctx context.Context) ([]Job, error)
+
+	// Cancel stops a replay after the event it is handling, or returns
+	// ErrJobNotFound; finished replays are left as they are
+	Cancel(ctx context.Context, id string) (*Job, error)
+}
+
+// Domain types and data structures
+
+// Request selects the events to replay and the handlers they go to
+type Request struct {
+	// Filters select the events; Limit caps how many are read and Offset
+	// is ignored
+	Filters events.EventFilters `json:"filters"`
+
+	// Handlers are the IDs of the handlers the events are replayed into;
+	// each only gets the event types it handles
+	Handlers []string `json:"handlers"`
+
+	// Rate caps the events read per second; zero replays as fast as the
+	// handlers go
+	Rate float64 `json:"rate,omitempty"`
+
+	// DryRun reads and counts the events each handler would get without
+	// handing them over
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Status is the state of a replay
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job is a replay and its progress
+type Job struct {
+	ID      string  `json:"id"`
+	Request Request `json:"request"`
+	Status  Status  `json:"status"`
+
+	// Read counts the events read, Handled the handler calls that succeeded,
+	// Skipped the events no selected handler handles and Failed the handler
+	// calls that failed; a dry run counts the calls it would make as handled
+	Read    int `json:"read"`
+	Handled int `json:"handled"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+
+	// LastEventID is the last event read, and LastError the last failure
+	// of a handler or of reading the events
+	LastEventID string `json:"last_event_id,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// IsFinished reports whether the replay stopped
+func (j Job) IsFinished() bool {
+	return j.Status != StatusRunning
+}
+
+// IsValid reports whether the request names at least one handler and a
+// non-negative rate
+func (r Request) IsValid() bool {
+	return len(r.Handlers) > 0 && r.Rate >= 0
+}
+
+// ReplayError represents event replay domain errors
+type ReplayError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e ReplayError) Error() string {
+	return e.Message
+}
+
+// Common event replay errors
+var (
+	ErrJobNotFound    = ReplayError{Code: "REPLAY_NOT_FOUND", Message: "Event replay not found"}
+	ErrInvalidRequest = ReplayError{Code: "INVALID_REPLAY", Message: "A replay needs at least one handler and a non-negative rate"}
+	ErrUnknownHandler = ReplayError{Code: "UNKNOWN_HANDLER", Message: "No event handler with that ID"}
+)
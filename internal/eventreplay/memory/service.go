@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// pageSize is how many events a replay reads per query
+const pageSize = 500
+
+// service implements eventreplay.Service, reading events from an events
+// service that stores them, such as the event store or the in-memory bus,
+// and keeping the replays of this instance in memory
+type service struct {
+	source   events.Service
+	handlers map[string]eventhandler.Service
+
+	mu      sync.RWMutex
+	jobs    map[string]*eventreplay.Job
+	order   []string
+	cancels map[string]context.CancelFunc
+}
+
+// NewService creates a replayer of the events source stores into the
+// handlers, by handler ID
+func NewService(source events.Service, handlers map[string]eventhandler.Service) eventreplay.Service {
+	return &service{
+		source:   source,
+		handlers: handlers,
+		jobs:     make(map[string]*eventreplay.Job),
+		cancels:  make(map[string]context.CancelFunc),
+	}
+}
+
+// Start checks the request and runs the replay in the background. The
+// replay keeps the correlation of ctx but not its deadline.
+func (s *service) Start(ctx context.Context, request eventreplay.Request) (*eventreplay.Job, error) {
+	if !request.IsValid() {
+		return nil, eventreplay.ErrInvalidRequest
+	}
+	handlers := make(map[string]eventhandler.Service, len(request.Handlers))
+	for _, id := range request.Handlers {
+		handler, ok := s.handlers[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", eventreplay.ErrUnknownHandler, id)
+		}
+		handlers[id] = handler
+	}
+
+	job := &eventreplay.Job{
+		ID:        uuid.New().String(),
+		Request:   request,
+		Status:    eventreplay.StatusRunning,
+		StartedAt: time.Now(),
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	s.cancels[job.ID] = cancel
+	started := *job
+	s.mu.Unlock()
+
+	go s.run(runCtx, job.ID, request, handlers)
+	return &started, nil
+}
+
+// Get returns a copy of the replay
+func (s *service) Get(ctx context.Context, id string) (*eventreplay.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, eventreplay.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// List returns copies of every replay, newest first
+func (s *service) List(ctx context.Context) ([]eventreplay.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]eventreplay.Job, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, *s.jobs[s.order[i]])
+	}
+	return jobs, nil
+}
+
+// Cancel stops a running replay and marks it cancelled
+func (s *service) Cancel(ctx context.Context, id string) (*eventreplay.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, eventreplay.ErrJobNotFound
+	}
+	if !job.IsFinished() {
+		s.cancels[id]()
+		s.finish(job, eventreplay.StatusCancelled, "")
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// run reads the events a page at a time, paced to the request's rate, and
+// hands each to the handlers declaring its type
+func (s *service) run(ctx context.Context, id string, request eventreplay.Request, handlers map[string]eventhandler.Service) {
+	var pace *time.Ticker
+	if request.Rate > 0 {
+		pace = time.NewTicker(time.Duration(float64(time.Second) / request.Rate))
+		defer pace.Stop()
+	}
+
+	filters := request.Filters
+	remaining := filters.Limit
+	offset := 0
+	for {
+		size := pageSize
+		if filters.Limit > 0 && remaining < size {
+			size = remaining
+		}
+		if size == 0 {
+			s.complete(id, eventreplay.StatusCompleted, "")
+			return
+		}
+
+		filters.Offset, filters.Limit = offset, size
+		page, err := s.source.GetEvents(ctx, filters)
+		if err != nil {
+			s.complete(id, eventreplay.StatusFailed, err.Error())
+			return
+		}
+		for _, event := range page {
+			if pace != nil {
+				select {
+				case <-pace.C:
+				case <-ctx.Done():
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			s.replay(ctx, id, event, handlers, request.DryRun)
+		}
+		if len(page) < size {
+			s.complete(id, eventreplay.StatusCompleted, "")
+			return
+		}
+		offset += len(page)
+		remaining -= len(page)
+	}
+}
+
+// replay hands one event to the handlers declaring its type, in the event's
+// correlation, and records the outcome
+func (s *service) replay(ctx context.Context, id string, event events.Event, handlers map[string]eventhandler.Service, dryRun bool) {
+	ctx = correlation.Caused(ctx, event.ID, event.Metadata.CorrelationID)
+	handled, failed := 0, 0
+	var lastError string
+	for handlerID, handler := range handlers {
+		if !handles(handler, event.Type) {
+			continue
+		}
+		if dryRun {
+			handled++
+			continue
+		}
+		if err := handler.Handle(ctx, event); err != nil {
+			failed++
+			lastError = fmt.Sprintf("handler %s failed on event %s: %v", handlerID, event.ID, err)
+			correlation.Logf(ctx, "Replay %s: %s", id, lastError)
+			continue
+		}
+		handled++
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := s.jobs[id]
+	job.Read++
+	job.LastEventID = event.ID
+	job.Handled += handled
+	job.Failed += failed
+	if handled == 0 && failed == 0 {
+		job.Skipped++
+	}
+	if lastError != "" {
+		job.LastError = lastError
+	}
+}
+
+// complete finishes a replay still running
+func (s *service) complete(id string, status eventreplay.Status, lastError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job := s.jobs[id]; !job.IsFinished() {
+		s.finish(job, status, lastError)
+	}
+}
+
+// finish marks a replay finished; the caller holds the lock
+func (s *service) finish(job *eventreplay.Job, status eventreplay.Status, lastError string) {
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+	if lastError != "" {
+		job.LastError = lastError
+	}
+	delete(s.cancels, job.ID)
+}
+
+// handles reports whether the handler takes events of the type; handlers
+// declaring no types take every event
+func handles(handler eventhandler.Service, eventType string) bool {
+	types := handler.GetHandledEventTypes()
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay"
+	"github.com/gentra/decorator-arch-go/internal/eventreplay/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recordingHandler records the events it handles and their causation,
+// failing with err when set
+type recordingHandler struct {
+	types []string
+	err   error
+
+	mu         sync.Mutex
+	handled    []string
+	causations []string
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled = append(h.handled, event.(events.Event).ID)
+	h.causations = append(h.causations, correlation.CausationID(ctx))
+	return h.err
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return h.types
+}
+
+func (h *recordingHandler) Handled() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.handled...)
+}
+
+// newSource returns a bus storing registrations and logins, alternating
+func newSource(t *testing.T, count int) (events.Service, []events.Event) {
+	t.Helper()
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = bus.Close(ctx)
+	})
+
+	var published []events.Event
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < count; i++ {
+		eventType := events.EventTypeUserRegistered
+		if i%2 == 1 {
+			eventType = events.EventTypeUserLoggedIn
+		}
+		event := *testkit.NewEventBuilder().WithType(eventType).Build()
+		event.ID = fmt.Sprintf("event-%02d", i)
+		event.Timestamp = base.Add(time.Duration(i) * time.Second)
+		require.NoError(t, bus.Publish(context.Background(), event))
+		published = append(published, event)
+	}
+	return bus, published
+}
+
+func waitFinished(t *testing.T, service eventreplay.Service, id string) *eventreplay.Job {
+	t.Helper()
+	var job *eventreplay.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = service.Get(context.Background(), id)
+		return err == nil && job.IsFinished()
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestStart_GivenStoredEvents_WhenReplaying_ThenHandsEachHandlerItsTypes(t *testing.T) {
+	tests := []struct {
+		name            string
+		filters         events.EventFilters
+		dryRun          bool
+		handlerErr      error
+		expectedRead    int
+		expectedHandled int
+		expectedSkipped int
+		expectedFailed  int
+		expectedCalls   int
+	}{
+		{
+			name:            "Given every event, When replaying, Then the handler gets its type only",
+			expectedRead:    6,
+			expectedHandled: 3,
+			expectedSkipped: 3,
+			expectedCalls:   3,
+		},
+		{
+			name:            "Given a type filter, When replaying, Then reads only that type",
+			filters:         events.EventFilters{EventTypes: []string{events.EventTypeUserRegistered}},
+			expectedRead:    3,
+			expectedHandled: 3,
+			expectedCalls:   3,
+		},
+		{
+			name:            "Given a limit, When replaying, Then stops after it",
+			filters:         events.EventFilters{Limit: 2},
+			expectedRead:    2,
+			expectedHandled: 1,
+			expectedSkipped: 1,
+			expectedCalls:   1,
+		},
+		{
+			name:            "Given a dry run, When replaying, Then counts without handling",
+			dryRun:          true,
+			expectedRead:    6,
+			expectedHandled: 3,
+			expectedSkipped: 3,
+		},
+		{
+			name:            "Given a failing handler, When replaying, Then counts failures and carries on",
+			handlerErr:      errors.New("projection unavailable"),
+			expectedRead:    6,
+			expectedFailed:  3,
+			expectedSkipped: 3,
+			expectedCalls:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			source, published := newSource(t, 6)
+			handler := &recordingHandler{types: []string{events.EventTypeUserRegistered}, err: tt.handlerErr}
+			service := memory.NewService(source, map[string]eventhandler.Service{"projection": handler})
+
+			// Act
+			started, err := service.Start(context.Background(), eventreplay.Request{
+				Filters:  tt.filters,
+				Handlers: []string{"projection"},
+				DryRun:   tt.dryRun,
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, eventreplay.StatusRunning, started.Status)
+			job := waitFinished(t, service, started.ID)
+			assert.Equal(t, eventreplay.StatusCompleted, job.Status)
+			assert.Equal(t, tt.expectedRead, job.Read)
+			assert.Equal(t, tt.expectedHandled, job.Handled)
+			assert.Equal(t, tt.expectedSkipped, job.Skipped)
+			assert.Equal(t, tt.expectedFailed, job.Failed)
+			assert.Len(t, handler.Handled(), tt.expectedCalls)
+			if tt.handlerErr != nil {
+				assert.Contains(t, job.LastError, "projection unavailable")
+			}
+			if tt.expectedCalls > 0 {
+				assert.Equal(t, published[0].ID, handler.Handled()[0])
+				assert.Equal(t, published[0].ID, handler.causations[0])
+			}
+		})
+	}
+}
+
+func TestStart_GivenInvalidRequest_WhenStarting_ThenRejectsIt(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  eventreplay.Request
+		expected eventreplay.ReplayError
+	}{
+		{
+			name:     "Given no handlers, When starting, Then returns invalid replay",
+			request:  eventreplay.Request{},
+			expected: eventreplay.ErrInvalidRequest,
+		},
+		{
+			name:     "Given a negative rate, When starting, Then returns invalid replay",
+			request:  eventreplay.Request{Handlers: []string{"projection"}, Rate: -1},
+			expected: eventreplay.ErrInvalidRequest,
+		},
+		{
+			name:     "Given an unknown handler, When starting, Then returns unknown handler",
+			request:  eventreplay.Request{Handlers: []string{"billing"}},
+			expected: eventreplay.ErrUnknownHandler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			source, _ := newSource(t, 0)
+			service := memory.NewService(source, map[string]eventhandler.Service{"projection": &recordingHandler{}})
+
+			// Act
+			job, err := service.Start(context.Background(), tt.request)
+
+			// Assert
+			assert.Nil(t, job)
+			assert.ErrorIs(t, err, tt.expected)
+			jobs, err := service.List(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, jobs)
+		})
+	}
+}
+
+func TestCancel_GivenSlowReplay_WhenCancelling_ThenStopsIt(t *testing.T) {
+	// Arrange
+	source, _ := newSource(t, 6)
+	handler := &recordingHandler{}
+	service := memory.NewService(source, map[string]eventhandler.Service{"projection": handler})
+	started, err := service.Start(context.Background(), eventreplay.Request{Handlers: []string{"projection"}, Rate: 20})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(handler.Handled()) > 0 }, 2*time.Second, 5*time.Millisecond)
+
+	// Act
+	cancelled, err := service.Cancel(context.Background(), started.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, eventreplay.StatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.FinishedAt)
+	time.Sleep(150 * time.Millisecond)
+	job, err := service.Get(context.Background(), started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eventreplay.StatusCancelled, job.Status)
+	assert.Less(t, job.Read, 6)
+	_, err = service.Cancel(context.Background(), "missing")
+	assert.ErrorIs(t, err, eventreplay.ErrJobNotFound)
+}
@@ -0,0 +1,591 @@
+package amqp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	deadLetterMemory "github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// fakeBroker emulates the parts of a RabbitMQ broker this client uses: topic
+// and fanout exchanges, queues with dead-letter exchanges, consumers with
+// explicit acks and publisher confirms
+type fakeBroker struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	credentials string
+	vhost       string
+	exchanges   map[string]string
+	queueArgs   map[string]map[string]string
+	bindings    map[string][][2]string // exchange -> (queue, routing key)
+	queues      map[string][]fakeMessage
+	headers     []map[string]string
+	nackPublish bool
+
+	// consumers by queue, with the connection and channel they consume on
+	consumers map[string]fakeConsumer
+	unacked   map[uint64]fakeMessage
+	delivered uint64
+}
+
+type fakeMessage struct {
+	queue      string
+	exchange   string
+	routingKey string
+	body       []byte
+}
+
+type fakeConsumer struct {
+	conn    *fakeConn
+	channel uint16
+	tag     string
+}
+
+type fakeConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	writer  *bufio.Writer
+	confirm map[uint16]uint64 // publish sequence of channels in confirm mode
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &fakeBroker{
+		listener:  listener,
+		exchanges: make(map[string]string),
+		queueArgs: make(map[string]map[string]string),
+		bindings:  make(map[string][][2]string),
+		queues:    make(map[string][]fakeMessage),
+		consumers: make(map[string]fakeConsumer),
+		unacked:   make(map[uint64]fakeMessage),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(&fakeConn{
+				Conn:    conn,
+				reader:  bufio.NewReader(conn),
+				writer:  bufio.NewWriter(conn),
+				confirm: make(map[uint16]uint64),
+			})
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "amqp://events:secret@" + b.listener.Addr().String() + "/staging"
+}
+
+func (c *fakeConn) send(channel uint16, kind byte, payload []byte) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = writeFrame(c.writer, frame{kind: kind, channel: channel, payload: payload})
+	_ = c.writer.Flush()
+}
+
+func (c *fakeConn) method(channel uint16, e *encoder) {
+	c.send(channel, frameMethod, e.bytes())
+}
+
+func (c *fakeConn) next() (frame, method, error) {
+	for {
+		f, err := readFrame(c.reader, 0)
+		if err != nil {
+			return frame{}, method{}, err
+		}
+		if f.kind == frameHeartbeat {
+			continue
+		}
+		if f.kind != frameMethod {
+			return f, method{}, nil
+		}
+		m, err := decodeMethod(f.payload)
+		return f, m, err
+	}
+}
+
+func (b *fakeBroker) serve(c *fakeConn) {
+	defer c.Close()
+
+	header := make([]byte, len(protocolHeader))
+	if _, err := c.reader.Read(header); err != nil {
+		return
+	}
+	c.method(0, newMethod(connectionStart).octet(0).octet(9).table(nil).longstr("PLAIN AMQPLAIN").longstr("en_US"))
+	_, startOk, err := c.next()
+	if err != nil || startOk.id != connectionStartOk {
+		return
+	}
+	d := decoder{data: startOk.args}
+	d.skipTable()
+	d.shortstr()
+	response := d.longstr()
+
+	c.method(0, newMethod(connectionTune).short(2047).long(131072).short(0))
+	if _, m, err := c.next(); err != nil || m.id != connectionTuneOk {
+		return
+	}
+	_, open, err := c.next()
+	if err != nil || open.id != connectionOpen {
+		return
+	}
+	d = decoder{data: open.args}
+	b.mu.Lock()
+	b.credentials = response
+	b.vhost = d.shortstr()
+	b.mu.Unlock()
+	c.method(0, newMethod(connectionOpenOk).shortstr(""))
+
+	for {
+		f, m, err := c.next()
+		if err != nil {
+			return
+		}
+		if f.kind != frameMethod {
+			continue
+		}
+		if !b.handle(c, f.channel, m) {
+			return
+		}
+	}
+}
+
+// handle answers a method, reporting false once the connection closes
+func (b *fakeBroker) handle(c *fakeConn, channel uint16, m method) bool {
+	d := decoder{data: m.args}
+	switch m.id {
+	case channelOpen:
+		c.method(channel, newMethod(channelOpenOk).longstr(""))
+	case channelClose:
+		b.mu.Lock()
+		for queue, consumer := range b.consumers {
+			if consumer.conn == c && consumer.channel == channel {
+				delete(b.consumers, queue)
+			}
+		}
+		b.mu.Unlock()
+		c.method(channel, newMethod(channelCloseOk))
+	case connectionClose:
+		c.method(0, newMethod(connectionCloseOk))
+		return false
+	case exchangeDeclare:
+		d.short()
+		name, kind := d.shortstr(), d.shortstr()
+		b.mu.Lock()
+		b.exchanges[name] = kind
+		b.mu.Unlock()
+		c.method(channel, newMethod(exchangeDeclareOk))
+	case queueDeclare:
+		d.short()
+		name := d.shortstr()
+		d.octet()
+		b.mu.Lock()
+		b.queueArgs[name] = decodeStringTable(&d)
+		b.mu.Unlock()
+		c.method(channel, newMethod(queueDeclareOk).shortstr(name).long(0).long(0))
+	case queueBind:
+		d.short()
+		queue, exchange, key := d.shortstr(), d.shortstr(), d.shortstr()
+		b.mu.Lock()
+		b.bindings[exchange] = append(b.bindings[exchange], [2]string{queue, key})
+		b.mu.Unlock()
+		c.method(channel, newMethod(queueBindOk))
+	case basicQos:
+		c.method(channel, newMethod(basicQosOk))
+	case confirmSelect:
+		c.confirm[channel] = 0
+		c.method(channel, newMethod(confirmSelectOk))
+	case basicConsume:
+		d.short()
+		queue, tag := d.shortstr(), d.shortstr()
+		b.mu.Lock()
+		b.consumers[queue] = fakeConsumer{conn: c, channel: channel, tag: tag}
+		pending := b.queues[queue]
+		delete(b.queues, queue)
+		b.mu.Unlock()
+		c.method(channel, newMethod(basicConsumeOk).shortstr(tag))
+		for _, message := range pending {
+			b.enqueue(message)
+		}
+	case basicPublish:
+		d.short()
+		exchange, routingKey := d.shortstr(), d.shortstr()
+		b.publish(c, channel, exchange, routingKey)
+	case basicAck:
+		b.mu.Lock()
+		delete(b.unacked, d.longlong())
+		b.mu.Unlock()
+	case basicNack:
+		tag := d.longlong()
+		requeue := d.octet()&2 != 0
+		b.mu.Lock()
+		message := b.unacked[tag]
+		delete(b.unacked, tag)
+		deadLetter := b.queueArgs[message.queue]["x-dead-letter-exchange"]
+		b.mu.Unlock()
+		if requeue {
+			b.enqueue(message)
+		} else if deadLetter != "" {
+			b.route(deadLetter, message.routingKey, message.body)
+		}
+	}
+	return true
+}
+
+// publish reads the content of a published message, routes it and confirms it
+func (b *fakeBroker) publish(c *fakeConn, channel uint16, exchange, routingKey string) {
+	f, _, err := c.next()
+	if err != nil || f.kind != frameHeader {
+		return
+	}
+	header, _ := decodeContentHeader(f.payload)
+	d := decoder{data: f.payload[12:]}
+	flags := d.short()
+	if flags&flagContentType != 0 {
+		d.shortstr()
+	}
+	headers := map[string]string{}
+	if flags&flagHeaders != 0 {
+		headers = decodeStringTable(&d)
+	}
+
+	var body []byte
+	for uint64(len(body)) < header.bodySize {
+		f, _, err := c.next()
+		if err != nil {
+			return
+		}
+		body = append(body, f.payload...)
+	}
+
+	b.mu.Lock()
+	b.headers = append(b.headers, headers)
+	nack := b.nackPublish
+	b.mu.Unlock()
+	if !nack {
+		b.route(exchange, routingKey, body)
+	}
+
+	if sequence, confirming := c.confirm[channel]; confirming {
+		sequence++
+		c.confirm[channel] = sequence
+		reply := basicAck
+		if nack {
+			reply = basicNack
+		}
+		c.method(channel, newMethod(reply).longlong(sequence).bits(false))
+	}
+}
+
+// route delivers a message to the queues bound to the exchange
+func (b *fakeBroker) route(exchange, routingKey string, body []byte) {
+	b.mu.Lock()
+	var queues []string
+	for _, binding := range b.bindings[exchange] {
+		if b.exchanges[exchange] == "fanout" || binding[1] == "#" || binding[1] == routingKey {
+			queues = append(queues, binding[0])
+		}
+	}
+	b.mu.Unlock()
+
+	for _, queue := range queues {
+		b.enqueue(fakeMessage{queue: queue, exchange: exchange, routingKey: routingKey, body: body})
+	}
+}
+
+// enqueue hands a message to the queue's consumer or keeps it in the queue
+func (b *fakeBroker) enqueue(message fakeMessage) {
+	b.mu.Lock()
+	consumer, consuming := b.consumers[message.queue]
+	if !consuming {
+		b.queues[message.queue] = append(b.queues[message.queue], message)
+		b.mu.Unlock()
+		return
+	}
+	b.delivered++
+	tag := b.delivered
+	b.unacked[tag] = message
+	b.mu.Unlock()
+
+	consumer.conn.method(consumer.channel, newMethod(basicDeliver).
+		shortstr(consumer.tag).
+		longlong(tag).
+		bits(false).
+		shortstr(message.exchange).
+		shortstr(message.routingKey))
+	consumer.conn.send(consumer.channel, frameHeader, encodeContentHeader(len(message.body)))
+	if len(message.body) > 0 {
+		consumer.conn.send(consumer.channel, frameBody, message.body)
+	}
+}
+
+func (b *fakeBroker) queued(queue string) []fakeMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]fakeMessage(nil), b.queues[queue]...)
+}
+
+// decodeStringTable reads a field table keeping its string values
+func decodeStringTable(d *decoder) map[string]string {
+	fields := decoder{data: d.take(int(d.long()))}
+	table := make(map[string]string)
+	for len(fields.data) > 0 && fields.err == nil {
+		key := fields.shortstr()
+		switch fields.octet() {
+		case 'S':
+			table[key] = fields.longstr()
+		default:
+			return table
+		}
+	}
+	return table
+}
+
+type recordingHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	received chan events.Event
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("handler unavailable")
+	}
+	h.received <- event.(events.Event)
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered}
+}
+
+func newTestService(t *testing.T, broker *fakeBroker) events.Service {
+	t.Helper()
+	config := DefaultConfig()
+	config.URL = broker.url()
+	config.Queue = "app"
+	config.Retry.InitialDelay = time.Millisecond
+	config.Retry.MaxDelay = time.Millisecond
+
+	bus, err := NewService(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { closeService(bus) })
+	return bus
+}
+
+func closeService(bus events.Service) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = bus.(*service).Close(ctx)
+}
+
+func TestAMQPEvents_GivenSubscription_WhenPublishing_ThenDeliversThroughDomainExchange(t *testing.T) {
+	// Arrange
+	broker := newFakeBroker(t)
+	service := newTestService(t, broker)
+	handler := &recordingHandler{received: make(chan events.Event, 1)}
+	require.NoError(t, service.Subscribe(context.Background(), nil, handler))
+	event := testkit.NewEventBuilder().WithCorrelationID("corr-1", "").Build()
+
+	// Act
+	err := service.Publish(context.Background(), *event)
+
+	// Assert
+	require.NoError(t, err)
+	select {
+	case received := <-handler.received:
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, event.AggregateID, received.AggregateID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	assert.Equal(t, "\x00events\x00secret", broker.credentials)
+	assert.Equal(t, "staging", broker.vhost)
+	assert.Equal(t, "topic", broker.exchanges["user.events"])
+	assert.Equal(t, "topic", broker.exchanges["auth.events"], "configured domains are declared up front")
+	assert.Equal(t, "fanout", broker.exchanges["user.events.dlx"])
+	assert.Equal(t, "user.events.dlx", broker.queueArgs["app.user.events"]["x-dead-letter-exchange"])
+	assert.Contains(t, broker.bindings["user.events"], [2]string{"app.user.events", events.EventTypeUserRegistered})
+	assert.Contains(t, broker.bindings["user.events.dlx"], [2]string{"app.user.events.dead-letter", ""})
+	assert.Equal(t, "corr-1", broker.headers[0]["correlation_id"])
+	assert.Equal(t, events.EventTypeUserRegistered, broker.headers[0]["event_type"])
+}
+
+func TestAMQPEvents_GivenFailingHandler_WhenDelivering_ThenRetriesThenDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		delivered  bool
+		deadLetter int
+	}{
+		{
+			name:      "Given a handler failing twice, When delivering, Then acks on the third attempt",
+			failures:  2,
+			delivered: true,
+		},
+		{
+			name:       "Given a handler failing past the retries, When delivering, Then dead-letters the message",
+			failures:   10,
+			deadLetter: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			broker := newFakeBroker(t)
+			service := newTestService(t, broker)
+			handler := &recordingHandler{failures: tt.failures, received: make(chan events.Event, 1)}
+			require.NoError(t, service.Subscribe(context.Background(), []string{"user.events"}, handler))
+
+			// Act
+			require.NoError(t, service.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+
+			// Assert
+			if tt.delivered {
+				select {
+				case <-handler.received:
+				case <-time.After(5 * time.Second):
+					t.Fatal("event was not delivered")
+				}
+				return
+			}
+			require.Eventually(t, func() bool {
+				return len(broker.queued("app.user.events.dead-letter")) == tt.deadLetter
+			}, 5*time.Second, 10*time.Millisecond)
+			var event events.Event
+			require.NoError(t, json.Unmarshal(broker.queued("app.user.events.dead-letter")[0].body, &event))
+			assert.Equal(t, events.EventTypeUserRegistered, event.Type)
+			handler.mu.Lock()
+			defer handler.mu.Unlock()
+			assert.Equal(t, 4, handler.calls, "the first attempt and three retries")
+		})
+	}
+}
+
+func TestAMQPEvents_GivenDeadLetterStore_WhenHandlerGivesUp_ThenRecordsAndAcksMessage(t *testing.T) {
+	// Arrange
+	broker := newFakeBroker(t)
+	config := DefaultConfig()
+	config.URL = broker.url()
+	config.Queue = "app"
+	config.Retry.InitialDelay = time.Millisecond
+	config.Retry.MaxDelay = time.Millisecond
+	config.DeadLetters = deadLetterMemory.NewService()
+	service, err := NewService(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { closeService(service) })
+	handler := &recordingHandler{failures: 10, received: make(chan events.Event, 1)}
+	require.NoError(t, service.Subscribe(context.Background(), []string{"user.events"}, handler))
+	event := testkit.NewEventBuilder().Build()
+
+	// Act
+	require.NoError(t, service.Publish(context.Background(), *event))
+
+	// Assert
+	var letters []deadletter.Letter
+	require.Eventually(t, func() bool {
+		letters, _ = config.DeadLetters.List(context.Background(), deadletter.Filter{})
+		return len(letters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, event.ID, letters[0].Event.ID)
+	assert.Equal(t, "amqp", letters[0].Provider)
+	assert.Equal(t, "app.user.events", letters[0].Subscription)
+	assert.Equal(t, 4, letters[0].Attempts)
+	assert.Equal(t, "handler unavailable", letters[0].Error)
+	assert.False(t, letters[0].Poison)
+	assert.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.unacked) == 0
+	}, 5*time.Second, 10*time.Millisecond, "the message is acknowledged")
+	assert.Empty(t, broker.queued("app.user.events.dead-letter"))
+}
+
+func TestAMQPEvents_GivenBrokerRejectsPublish_WhenPublishing_ThenReturnsPublishFailed(t *testing.T) {
+	// Arrange
+	broker := newFakeBroker(t)
+	service := newTestService(t, broker)
+	broker.mu.Lock()
+	broker.nackPublish = true
+	broker.mu.Unlock()
+
+	// Act
+	err := service.Publish(context.Background(), *testkit.NewEventBuilder().Build())
+
+	// Assert
+	assert.ErrorIs(t, err, events.ErrPublishFailed)
+}
+
+func TestAMQPEvents_GivenBatch_WhenPublishing_ThenRoutesEveryEventByType(t *testing.T) {
+	// Arrange
+	broker := newFakeBroker(t)
+	service := newTestService(t, broker)
+	user := testkit.NewEventBuilder().Build()
+	login := testkit.NewEventBuilder().WithType(events.EventTypeUserLoggedIn).Build()
+	broker.mu.Lock()
+	broker.bindings["user.events"] = append(broker.bindings["user.events"], [2]string{"audit", "#"})
+	broker.bindings["auth.events"] = append(broker.bindings["auth.events"], [2]string{"audit", events.EventTypeUserLoggedIn})
+	broker.mu.Unlock()
+
+	// Act
+	err := service.PublishBatch(context.Background(), []events.Event{*user, *login})
+
+	// Assert
+	require.NoError(t, err)
+	queued := broker.queued("audit")
+	if assert.Len(t, queued, 2) {
+		assert.Equal(t, "user.events", queued[0].exchange)
+		assert.Equal(t, "auth.events", queued[1].exchange)
+		assert.Equal(t, events.EventTypeUserLoggedIn, queued[1].routingKey)
+	}
+}
+
+func TestAMQPEvents_GivenInvalidUse_WhenCalled_ThenReturnsErrors(t *testing.T) {
+	// Arrange
+	broker := newFakeBroker(t)
+	service := newTestService(t, broker)
+	withoutQueue := DefaultConfig()
+	withoutQueue.URL = broker.url()
+	noQueue, err := NewService(context.Background(), withoutQueue)
+	require.NoError(t, err)
+	defer closeService(noQueue)
+
+	// Act & Assert
+	assert.ErrorIs(t, service.Publish(context.Background(), events.Event{Type: events.EventTypeUserRegistered}), events.ErrInvalidEvent)
+	assert.ErrorIs(t, noQueue.Subscribe(context.Background(), nil, &recordingHandler{}), events.ErrSubscriptionFailed)
+	_, err = service.GetEvents(context.Background(), events.EventFilters{})
+	assert.ErrorIs(t, err, events.ErrQueryNotSupported)
+	_, err = NewService(context.Background(), Config{URL: "http://" + broker.listener.Addr().String()})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "scheme"))
+}
@@ -0,0 +1,345 @@
+// Minimal AMQP 0-9-1 frame codec the fake broker in service_test.go speaks.
+
+package amqp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// AMQP 0-9-1 frame types and the octet ending every frame
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+)
+
+// protocolHeader opens every AMQP 0-9-1 connection
+var protocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}
+
+// Class and method IDs of the methods the fake broker speaks
+const (
+	classConnection = 10
+	classChannel    = 20
+	classExchange   = 40
+	classQueue      = 50
+	classBasic      = 60
+	classConfirm    = 85
+)
+
+// methodID identifies a method by class and method
+type methodID struct {
+	class, method uint16
+}
+
+var (
+	connectionStart   = methodID{classConnection, 10}
+	connectionStartOk = methodID{classConnection, 11}
+	connectionTune    = methodID{classConnection, 30}
+	connectionTuneOk  = methodID{classConnection, 31}
+	connectionOpen    = methodID{classConnection, 40}
+	connectionOpenOk  = methodID{classConnection, 41}
+	connectionClose   = methodID{classConnection, 50}
+	connectionCloseOk = methodID{classConnection, 51}
+
+	channelOpen    = methodID{classChannel, 10}
+	channelOpenOk  = methodID{classChannel, 11}
+	channelClose   = methodID{classChannel, 40}
+	channelCloseOk = methodID{classChannel, 41}
+
+	exchangeDeclare   = methodID{classExchange, 10}
+	exchangeDeclareOk = methodID{classExchange, 11}
+
+	queueDeclare   = methodID{classQueue, 10}
+	queueDeclareOk = methodID{classQueue, 11}
+	queueBind      = methodID{classQueue, 20}
+	queueBindOk    = methodID{classQueue, 21}
+
+	basicQos       = methodID{classBasic, 10}
+	basicQosOk     = methodID{classBasic, 11}
+	basicConsume   = methodID{classBasic, 20}
+	basicConsumeOk = methodID{classBasic, 21}
+	basicPublish   = methodID{classBasic, 40}
+	basicDeliver   = methodID{classBasic, 60}
+	basicAck       = methodID{classBasic, 80}
+	basicNack      = methodID{classBasic, 120}
+
+	confirmSelect   = methodID{classConfirm, 10}
+	confirmSelectOk = methodID{classConfirm, 11}
+)
+
+func (m methodID) String() string {
+	return fmt.Sprintf("%d.%d", m.class, m.method)
+}
+
+// frame is a single AMQP frame
+type frame struct {
+	kind    byte
+	channel uint16
+	payload []byte
+}
+
+// readFrame reads the next frame; frames larger than maxSize are refused
+func readFrame(r *bufio.Reader, maxSize uint32) (frame, error) {
+	var header [7]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return frame{}, err
+	}
+	size := binary.BigEndian.Uint32(header[3:])
+	if maxSize > 0 && size > maxSize {
+		return frame{}, fmt.Errorf("frame of %d bytes exceeds the negotiated %d", size, maxSize)
+	}
+
+	payload := make([]byte, size+1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return frame{}, err
+	}
+	if payload[size] != frameEnd {
+		return frame{}, errors.New("malformed frame: missing frame end")
+	}
+	return frame{kind: header[0], channel: binary.BigEndian.Uint16(header[1:]), payload: payload[:size]}, nil
+}
+
+// writeFrame appends a frame to the buffer
+func writeFrame(w *bufio.Writer, f frame) error {
+	var header [7]byte
+	header[0] = f.kind
+	binary.BigEndian.PutUint16(header[1:], f.channel)
+	binary.BigEndian.PutUint32(header[3:], uint32(len(f.payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(f.payload); err != nil {
+		return err
+	}
+	return w.WriteByte(frameEnd)
+}
+
+// method is a decoded method frame: its ID and undecoded arguments
+type method struct {
+	id   methodID
+	args []byte
+}
+
+// decodeMethod splits a method frame payload
+func decodeMethod(payload []byte) (method, error) {
+	if len(payload) < 4 {
+		return method{}, errors.New("malformed method frame")
+	}
+	return method{
+		id:   methodID{binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:])},
+		args: payload[4:],
+	}, nil
+}
+
+// encoder builds method arguments and content headers
+type encoder struct {
+	buf bytes.Buffer
+}
+
+// newMethod starts the payload of a method frame
+func newMethod(id methodID) *encoder {
+	e := &encoder{}
+	e.short(id.class)
+	e.short(id.method)
+	return e
+}
+
+func (e *encoder) octet(v byte) *encoder {
+	e.buf.WriteByte(v)
+	return e
+}
+
+func (e *encoder) short(v uint16) *encoder {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	e.buf.Write(b[:])
+	return e
+}
+
+func (e *encoder) long(v uint32) *encoder {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+	return e
+}
+
+func (e *encoder) longlong(v uint64) *encoder {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf.Write(b[:])
+	return e
+}
+
+// shortstr writes a string of at most 255 bytes; longer ones are truncated
+func (e *encoder) shortstr(v string) *encoder {
+	if len(v) > 255 {
+		v = v[:255]
+	}
+	e.buf.WriteByte(byte(len(v)))
+	e.buf.WriteString(v)
+	return e
+}
+
+func (e *encoder) longstr(v string) *encoder {
+	e.long(uint32(len(v)))
+	e.buf.WriteString(v)
+	return e
+}
+
+// bits packs consecutive bit arguments into one octet, the first in the
+// lowest bit
+func (e *encoder) bits(values ...bool) *encoder {
+	var packed byte
+	for i, value := range values {
+		if value {
+			packed |= 1 << uint(i)
+		}
+	}
+	return e.octet(packed)
+}
+
+// table writes a field table. Keys are written sorted so the encoding is
+// stable; values may be strings, booleans, integers, time.Time and nested
+// tables.
+func (e *encoder) table(t map[string]interface{}) *encoder {
+	var fields encoder
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields.shortstr(key)
+		fields.value(t[key])
+	}
+	e.long(uint32(fields.buf.Len()))
+	e.buf.Write(fields.buf.Bytes())
+	return e
+}
+
+func (e *encoder) value(v interface{}) {
+	switch v := v.(type) {
+	case string:
+		e.octet('S').longstr(v)
+	case bool:
+		e.octet('t')
+		if v {
+			e.octet(1)
+		} else {
+			e.octet(0)
+		}
+	case int:
+		e.octet('l').longlong(uint64(int64(v)))
+	case int32:
+		e.octet('I').long(uint32(v))
+	case int64:
+		e.octet('l').longlong(uint64(v))
+	case time.Time:
+		e.octet('T').longlong(uint64(v.Unix()))
+	case map[string]interface{}:
+		e.octet('F').table(v)
+	default:
+		e.octet('V')
+	}
+}
+
+func (e *encoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// decoder reads method arguments; the first error sticks and zero values
+// are returned from then on
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = errors.New("malformed method arguments")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) octet() byte {
+	if b := d.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) short() uint16 {
+	if b := d.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) long() uint32 {
+	if b := d.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) longlong() uint64 {
+	if b := d.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) shortstr() string {
+	return string(d.take(int(d.octet())))
+}
+
+func (d *decoder) longstr() string {
+	return string(d.take(int(d.long())))
+}
+
+// skipTable skips a field table, whose contents the fake broker never needs
+func (d *decoder) skipTable() {
+	d.take(int(d.long()))
+}
+
+// contentHeader is the part of a content header frame the fake broker reads
+type contentHeader struct {
+	bodySize uint64
+}
+
+func decodeContentHeader(payload []byte) (contentHeader, error) {
+	d := decoder{data: payload}
+	d.short() // class
+	d.short() // weight
+	size := d.longlong()
+	return contentHeader{bodySize: size}, d.err
+}
+
+// Property flags of the content header properties the fake broker reads
+const (
+	flagContentType = 1 << 15
+	flagHeaders     = 1 << 13
+)
+
+// encodeContentHeader builds the header frame payload of a basic message
+// without properties
+func encodeContentHeader(bodySize int) []byte {
+	var e encoder
+	e.short(classBasic).short(0).longlong(uint64(bodySize)).short(0)
+	return e.bytes()
+}
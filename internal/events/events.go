@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/gentra/decorator-arch-go/internal/correlation"
 	"github.com/gentra/decorator-arch-go/internal/eventhandler"
 )
 
@@ -35,6 +36,10 @@ type Event struct {
 	Data          map[string]interface{} `json:"data"`
 	Metadata      EventMetadata          `json:"metadata"`
 	Timestamp     time.Time              `json:"timestamp"`
+
+	// SchemaVersion is the version of the payload contract Data follows,
+	// distinct from the aggregate Version; zero is version 1
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
 
 // EventMetadata contains metadata about an event
@@ -72,13 +77,18 @@ type EventSubscription struct {
 
 // EventConfig contains configuration for the event service
 type EventConfig struct {
-	Provider      string            `json:"provider"`      // inmemory, redis, kafka, etc.
+	Provider      string            `json:"provider"`      // inmemory, sns, pubsub, redis, kafka, etc.
 	BufferSize    int               `json:"buffer_size"`   // Buffer size for async processing
 	RetryConfig   RetryConfig       `json:"retry_config"`  // Retry configuration
-	Serialization string            `json:"serialization"` // json, protobuf, etc.
+	Serialization string            `json:"serialization"` // json, cloudevents or protobuf
 	Compression   bool              `json:"compression"`   // Enable compression
 	Persistence   bool              `json:"persistence"`   // Enable event persistence
 	Topics        map[string]string `json:"topics"`        // Topic configuration
+
+	// CompressionAlgorithm is gzip (default) or zstd, and bodies smaller
+	// than CompressionThreshold bytes are sent uncompressed
+	CompressionAlgorithm string `json:"compression_algorithm,omitempty"`
+	CompressionThreshold int    `json:"compression_threshold,omitempty"`
 }
 
 // RetryConfig contains retry configuration for failed events
@@ -108,6 +118,10 @@ var (
 	ErrPublishFailed      = EventError{Code: "PUBLISH_FAILED", Message: "Failed to publish event"}
 	ErrSubscriptionFailed = EventError{Code: "SUBSCRIPTION_FAILED", Message: "Failed to create subscription"}
 	ErrVersionConflict    = EventError{Code: "VERSION_CONFLICT", Message: "Event version conflict"}
+	ErrQueryNotSupported  = EventError{Code: "QUERY_NOT_SUPPORTED", Message: "Event provider does not store events for querying or replay"}
+	ErrSchemaViolation    = EventError{Code: "SCHEMA_VIOLATION", Message: "Event data does not match its schema"}
+
+	ErrSubscriptionNotSupported = EventError{Code: "SUBSCRIPTION_NOT_SUPPORTED", Message: "Event provider does not deliver events to subscribers"}
 )
 
 // Helper methods for Event
@@ -120,12 +134,40 @@ func (e *Event) WithMetadata(metadata EventMetadata) *Event {
 	return e
 }
 
+// Attributes returns the routing attributes of the event, for brokers that
+// filter or order messages without decoding their bodies
+func (e *Event) Attributes() map[string]string {
+	attributes := map[string]string{
+		"event_type":     e.Type,
+		"aggregate_type": e.AggregateType,
+		"aggregate_id":   e.AggregateID,
+	}
+	if e.Metadata.CorrelationID != "" {
+		attributes["correlation_id"] = e.Metadata.CorrelationID
+	}
+	if e.Metadata.Source != "" {
+		attributes["source"] = e.Metadata.Source
+	}
+	return attributes
+}
+
 func (e *Event) WithUserContext(userID, correlationID string) *Event {
 	e.Metadata.UserID = userID
 	e.Metadata.CorrelationID = correlationID
 	return e
 }
 
+// Correlate fills the correlation and causation IDs the metadata lacks from
+// the context
+func (m *EventMetadata) Correlate(ctx context.Context) {
+	if m.CorrelationID == "" {
+		m.CorrelationID = correlation.CorrelationID(ctx)
+	}
+	if m.CausationID == "" {
+		m.CausationID = correlation.CausationID(ctx)
+	}
+}
+
 // Helper methods for EventFilters
 func (f *EventFilters) IsValid() bool {
 	return len(f.EventTypes) > 0 || f.AggregateID != "" || len(f.AggregateTypes) > 0
@@ -162,6 +204,8 @@ func DefaultEventConfig() EventConfig {
 			BackoffFactor: 2.0,
 			MaxDelay:      time.Minute * 5,
 		},
+		CompressionAlgorithm: "gzip",
+		CompressionThreshold: 1024,
 		Topics: map[string]string{
 			"user.events":     "user-domain-events",
 			"auth.events":     "auth-domain-events",
@@ -176,14 +220,22 @@ const (
 	// User domain events
 	EventTypeUserRegistered   = "user.registered"
 	EventTypeUserUpdated      = "user.updated"
+	EventTypeUserDeactivated  = "user.deactivated"
 	EventTypeUserDeleted      = "user.deleted"
+	EventTypeUserErased       = "user.erased"
 	EventTypeUserPrefsUpdated = "user.preferences.updated"
+	EventTypeEmailVerified    = "user.email_verified"
 
 	// Auth domain events
-	EventTypeUserLoggedIn    = "auth.user.logged_in"
-	EventTypeUserLoggedOut   = "auth.user.logged_out"
-	EventTypePasswordChanged = "auth.password.changed"
-	EventTypeTokenRefreshed  = "auth.token.refreshed"
+	EventTypeUserLoggedIn       = "auth.user.logged_in"
+	EventTypeUserLoggedOut      = "auth.user.logged_out"
+	EventTypePasswordChanged    = "auth.password.changed"
+	EventTypeTokenRefreshed     = "auth.token.refreshed"
+	EventTypeTokenReuseDetected = "auth.token.reuse_detected"
+
+	// Notification domain events
+	EventTypeInAppNotificationCreated = "notification.in_app.created"
+	EventTypeInAppNotificationsRead   = "notification.in_app.read"
 
 	// System events
 	EventTypeSystemStarted = "system.started"
@@ -122,6 +122,42 @@ func TestEvent_WithUserContext(t *testing.T) {
 	})
 }
 
+func TestEvent_Attributes(t *testing.T) {
+	t.Run("Given event without correlation or source, When Attributes is called, Then should only return routing attributes", func(t *testing.T) {
+		// Arrange
+		event := events.Event{
+			Type:          "user.registered",
+			AggregateID:   "user-456",
+			AggregateType: "user",
+		}
+
+		// Act
+		result := event.Attributes()
+
+		// Assert
+		assert.Equal(t, map[string]string{
+			"event_type":     "user.registered",
+			"aggregate_type": "user",
+			"aggregate_id":   "user-456",
+		}, result)
+	})
+
+	t.Run("Given event with metadata, When Attributes is called, Then should include correlation ID and source", func(t *testing.T) {
+		// Arrange
+		event := events.Event{
+			Type:     "user.registered",
+			Metadata: events.EventMetadata{CorrelationID: "corr-123", Source: "user-service"},
+		}
+
+		// Act
+		result := event.Attributes()
+
+		// Assert
+		assert.Equal(t, "corr-123", result["correlation_id"])
+		assert.Equal(t, "user-service", result["source"])
+	})
+}
+
 func TestEventFilters_IsValid(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -433,6 +469,11 @@ func TestEventConstants(t *testing.T) {
 			constant:     events.EventTypeTokenRefreshed,
 			expectedStr:  "auth.token.refreshed",
 		},
+		{
+			name:         "Given EventTypeTokenReuseDetected constant, When accessing string value, Then should have correct value",
+			constant:     events.EventTypeTokenReuseDetected,
+			expectedStr:  "auth.token.reuse_detected",
+		},
 		{
 			name:         "Given EventTypeSystemStarted constant, When accessing string value, Then should have correct value",
 			constant:     events.EventTypeSystemStarted,
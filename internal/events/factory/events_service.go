@@ -1,20 +1,41 @@
 package factory
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/compression"
+	eventCodecFactory "github.com/gentra/decorator-arch-go/internal/eventcodec/factory"
 	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsAMQP "github.com/gentra/decorator-arch-go/internal/events/amqp"
 	"github.com/gentra/decorator-arch-go/internal/events/memory"
+	eventsPropagation "github.com/gentra/decorator-arch-go/internal/events/propagation"
+	eventsPubSub "github.com/gentra/decorator-arch-go/internal/events/pubsub"
+	eventsSchema "github.com/gentra/decorator-arch-go/internal/events/schema"
+	eventsSNS "github.com/gentra/decorator-arch-go/internal/events/sns"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
 )
 
 // Config contains all configuration for building the events service
 type Config struct {
 	// Provider configuration
-	Provider string // "memory", "redis", "kafka", "nats", "rabbitmq"
+	// Empty falls back to EventConfig.Provider
+	Provider string // "memory" (or "inmemory"), "sns", "pubsub", "amqp" (or "rabbitmq"), "redis", "kafka", "nats"
 
 	// Memory provider settings
 	BufferSize int
 
+	// AWS SNS fan-out with SQS consumption
+	SNS eventsSNS.Config
+
+	// Google Cloud Pub/Sub
+	PubSub eventsPubSub.Config
+
+	// AMQP 0-9-1 brokers such as RabbitMQ
+	AMQP eventsAMQP.Config
+
 	// Redis provider settings (for future implementation)
 	RedisURL      string
 	RedisPassword string
@@ -28,9 +49,21 @@ type Config struct {
 	NATSServers []string
 	NATSSubject string
 
-	// Event processing configuration
+	// Event processing configuration; Serialization and Compression select
+	// the codec of the SNS, Pub/Sub and AMQP providers unless their Codec is
+	// set
 	EventConfig events.EventConfig
 
+	// DeadLetters keeps the events the memory and AMQP providers' handlers
+	// give up on, for administrators to re-drive or discard; nil keeps the
+	// providers' own behaviour
+	DeadLetters deadletter.Service
+
+	// Schemas holds the payload contracts published events are validated
+	// against and read events are upcast with, when EnableEventValidation
+	// is set; nil leaves payloads unchecked
+	Schemas eventschema.Service
+
 	// Feature flags
 	Features FeatureFlags
 }
@@ -49,6 +82,11 @@ type FeatureFlags struct {
 	EnableEventValidation  bool
 	EnableMetrics          bool
 	EnableTracing          bool
+
+	// EnableCorrelation fills the correlation and causation IDs of published
+	// events from the context and runs handlers in the correlation of the
+	// events they handle
+	EnableCorrelation bool
 }
 
 // DefaultFeatureFlags returns default feature flag configuration
@@ -66,6 +104,7 @@ func DefaultFeatureFlags() FeatureFlags {
 		EnableEventValidation:  true,
 		EnableMetrics:          false,
 		EnableTracing:          false,
+		EnableCorrelation:      true,
 	}
 }
 
@@ -83,24 +122,52 @@ func NewFactory(config Config) *EventsServiceFactory {
 
 // Build assembles and returns the complete events service based on configuration
 func (f *EventsServiceFactory) Build() (events.Service, error) {
-	switch f.config.Provider {
-	case "memory":
+	service, err := f.buildProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	// Payload contracts wrap the provider, so every provider checks them
+	if f.config.Schemas != nil && f.config.Features.EnableEventValidation {
+		service = eventsSchema.NewService(service, f.config.Schemas)
+	}
+	// Correlation is outermost, so handlers run in it whatever else wraps them
+	if f.config.Features.EnableCorrelation {
+		service = eventsPropagation.NewService(service)
+	}
+	return service, nil
+}
+
+// buildProvider creates the configured provider
+func (f *EventsServiceFactory) buildProvider() (events.Service, error) {
+	provider := f.config.Provider
+	if provider == "" {
+		provider = f.config.EventConfig.Provider
+	}
+
+	switch provider {
+	case "memory", "inmemory":
 		return f.buildMemoryService()
+	case "sns":
+		return f.buildSNSService()
+	case "pubsub":
+		return f.buildPubSubService()
 	case "redis":
 		return f.buildRedisService()
 	case "kafka":
 		return f.buildKafkaService()
 	case "nats":
 		return f.buildNATSService()
-	case "rabbitmq":
-		return f.buildRabbitMQService()
+	case "amqp", "rabbitmq":
+		return f.buildAMQPService()
 	default:
 		// Default to memory provider
 		return f.buildMemoryService()
 	}
 }
 
-// buildMemoryService creates an in-memory events service
+// buildMemoryService creates an in-memory event bus; failed deliveries are
+// only retried when EnableRetryLogic is set
 func (f *EventsServiceFactory) buildMemoryService() (events.Service, error) {
 	eventConfig := f.config.EventConfig
 
@@ -111,13 +178,54 @@ func (f *EventsServiceFactory) buildMemoryService() (events.Service, error) {
 	if f.config.Features.EnableCompression {
 		eventConfig.Compression = true
 	}
+	if !f.config.Features.EnableRetryLogic {
+		eventConfig.RetryConfig.MaxRetries = 0
+	}
 
 	// Set buffer size if specified
 	if f.config.BufferSize > 0 {
 		eventConfig.BufferSize = f.config.BufferSize
 	}
 
-	return memory.NewService(eventConfig), nil
+	service := memory.NewService(eventConfig)
+	if f.config.DeadLetters != nil {
+		service.SetDeadLetters(f.config.DeadLetters)
+	}
+	return service, nil
+}
+
+// buildSNSService creates an SNS/SQS events service; the dead-letter queue is
+// only wired when EnableDeadLetterQueue is set
+func (f *EventsServiceFactory) buildSNSService() (events.Service, error) {
+	config := f.config.SNS
+	if !f.config.Features.EnableDeadLetterQueue {
+		config.DeadLetterQueueARN = ""
+	}
+	if config.Codec == nil {
+		codec, err := f.buildCodec()
+		if err != nil {
+			return nil, err
+		}
+		config.Codec = codec
+	}
+	return eventsSNS.NewService(context.Background(), config)
+}
+
+// buildPubSubService creates a Pub/Sub events service; the dead-letter topic is
+// only wired when EnableDeadLetterQueue is set
+func (f *EventsServiceFactory) buildPubSubService() (events.Service, error) {
+	config := f.config.PubSub
+	if !f.config.Features.EnableDeadLetterQueue {
+		config.DeadLetterTopicID = ""
+	}
+	if config.Codec == nil {
+		codec, err := f.buildCodec()
+		if err != nil {
+			return nil, err
+		}
+		config.Codec = codec
+	}
+	return eventsPubSub.NewService(context.Background(), config)
 }
 
 // buildRedisService creates a Redis-based events service (placeholder)
@@ -138,10 +246,47 @@ func (f *EventsServiceFactory) buildNATSService() (events.Service, error) {
 	return nil, fmt.Errorf("NATS events provider not yet implemented")
 }
 
-// buildRabbitMQService creates a RabbitMQ-based events service (placeholder)
-func (f *EventsServiceFactory) buildRabbitMQService() (events.Service, error) {
-	// TODO: Implement RabbitMQ events service
-	return nil, fmt.Errorf("RabbitMQ events provider not yet implemented")
+// buildAMQPService creates an AMQP events service retrying with the event
+// retry configuration; failed deliveries are retried only when
+// EnableRetryLogic is set and dead-lettered only when EnableDeadLetterQueue is
+func (f *EventsServiceFactory) buildAMQPService() (events.Service, error) {
+	config := f.config.AMQP
+	config.Retry = f.config.EventConfig.RetryConfig
+	if len(f.config.EventConfig.Topics) > 0 {
+		config.Topics = f.config.EventConfig.Topics
+	}
+	if !f.config.Features.EnableRetryLogic {
+		config.Retry.MaxRetries = 0
+	}
+	if !f.config.Features.EnableDeadLetterQueue {
+		config.DeadLetter = false
+	}
+	if f.config.DeadLetters != nil {
+		config.DeadLetters = f.config.DeadLetters
+	}
+	if config.Codec == nil {
+		codec, err := f.buildCodec()
+		if err != nil {
+			return nil, err
+		}
+		config.Codec = codec
+	}
+	return eventsAMQP.NewService(context.Background(), config)
+}
+
+// buildCodec creates the codec of the configured serialization, with which
+// the broker providers encode message bodies, compressing them when
+// Compression or EnableCompression is set
+func (f *EventsServiceFactory) buildCodec() (eventcodec.Service, error) {
+	eventConfig := f.config.EventConfig
+	return eventCodecFactory.NewFactory(eventCodecFactory.Config{
+		Serialization: eventConfig.Serialization,
+		Compress:      eventConfig.Compression || f.config.Features.EnableCompression,
+		Compression: compression.Config{
+			Algorithm: eventConfig.CompressionAlgorithm,
+			Threshold: eventConfig.CompressionThreshold,
+		},
+	}).Build()
 }
 
 // DefaultConfig returns a sensible default configuration for the events service
@@ -149,6 +294,8 @@ func DefaultConfig() Config {
 	return Config{
 		Provider:    "memory",
 		BufferSize:  1000,
+		SNS:         eventsSNS.DefaultConfig(),
+		AMQP:        eventsAMQP.DefaultConfig(),
 		EventConfig: events.DefaultEventConfig(),
 		Features:    DefaultFeatureFlags(),
 	}
@@ -200,6 +347,55 @@ func (b *ConfigBuilder) WithNATSConfig(servers []string, subject string) *Config
 	return b
 }
 
+// WithSNSConfig selects the SNS/SQS provider
+func (b *ConfigBuilder) WithSNSConfig(config eventsSNS.Config) *ConfigBuilder {
+	b.config.Provider = "sns"
+	b.config.SNS = config
+	if config.DeadLetterQueueARN != "" {
+		b.config.Features.EnableDeadLetterQueue = true
+	}
+	return b
+}
+
+// WithPubSubConfig selects the Pub/Sub provider
+func (b *ConfigBuilder) WithPubSubConfig(config eventsPubSub.Config) *ConfigBuilder {
+	b.config.Provider = "pubsub"
+	b.config.PubSub = config
+	if config.DeadLetterTopicID != "" {
+		b.config.Features.EnableDeadLetterQueue = true
+	}
+	return b
+}
+
+// WithAMQPConfig selects the AMQP provider
+func (b *ConfigBuilder) WithAMQPConfig(config eventsAMQP.Config) *ConfigBuilder {
+	b.config.Provider = "amqp"
+	b.config.AMQP = config
+	if config.DeadLetter {
+		b.config.Features.EnableDeadLetterQueue = true
+	}
+	return b
+}
+
+// WithDeadLetters sets the dead-letter queue for events handlers give up on
+func (b *ConfigBuilder) WithDeadLetters(queue deadletter.Service) *ConfigBuilder {
+	b.config.DeadLetters = queue
+	return b
+}
+
+// WithSchemas sets the payload contracts events are validated and upcast with
+func (b *ConfigBuilder) WithSchemas(schemas eventschema.Service) *ConfigBuilder {
+	b.config.Schemas = schemas
+	return b
+}
+
+// WithSerialization sets the wire format of the broker providers, "json",
+// "cloudevents" or "protobuf"
+func (b *ConfigBuilder) WithSerialization(serialization string) *ConfigBuilder {
+	b.config.EventConfig.Serialization = serialization
+	return b
+}
+
 // WithEventConfig sets the event configuration
 func (b *ConfigBuilder) WithEventConfig(eventConfig events.EventConfig) *ConfigBuilder {
 	b.config.EventConfig = eventConfig
@@ -224,6 +420,15 @@ func (b *ConfigBuilder) EnableCompression() *ConfigBuilder {
 	return b
 }
 
+// WithCompression compresses the message bodies of the broker providers
+// from threshold bytes with algorithm, "gzip" or "zstd"
+func (b *ConfigBuilder) WithCompression(algorithm string, threshold int) *ConfigBuilder {
+	b.config.Features.EnableCompression = true
+	b.config.EventConfig.CompressionAlgorithm = algorithm
+	b.config.EventConfig.CompressionThreshold = threshold
+	return b
+}
+
 // EnableRetryLogic enables retry logic for failed events
 func (b *ConfigBuilder) EnableRetryLogic() *ConfigBuilder {
 	b.config.Features.EnableRetryLogic = true
@@ -3,68 +3,119 @@ package memory
 import (
 	"context"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
 	"github.com/gentra/decorator-arch-go/internal/eventhandler"
 	"github.com/gentra/decorator-arch-go/internal/events"
 )
 
-// service implements events.Service interface using in-memory storage
-type service struct {
-	events        []events.Event
-	subscriptions map[string]*events.EventSubscription
-	handlers      map[string][]eventhandler.Service
+// Service implements events.Service interface as an in-process bus. Published
+// events are kept for querying and queued for a dispatcher that routes each
+// of them to the matching subscriptions, every one of which has a worker
+// delivering its events in order. The bus queue and each subscription's
+// queue hold up to the configured buffer size, so publishers wait once a
+// subscriber falls that far behind. Failed deliveries are retried with the
+// configured backoff, so handlers see an event at least once and must
+// tolerate duplicates. The concrete type is exported so the owner can drain
+// the bus with Close and inspect its subscriptions.
+type Service struct {
+	config events.EventConfig
+
 	mu            sync.RWMutex
-	config        events.EventConfig
+	events        []events.Event
+	subscriptions map[string]*subscription
+
+	// queueMu keeps Close from closing the queue while events are sent
+	queueMu    sync.RWMutex
+	closed     bool
+	queue      chan events.Event
+	dispatched chan struct{}
+	stop       chan struct{}
+	stopOnce   sync.Once
+	workers    sync.WaitGroup
+
+	// deadLetters keeps the events handlers kept failing on; nil drops them
+	deadLetters deadletter.Service
 }
 
-// NewService creates a new in-memory event service
-func NewService(config events.EventConfig) events.Service {
+// subscription is an entry of the subscriber registry, with the queue its
+// worker delivers
+type subscription struct {
+	events.EventSubscription
+	handledTypes map[string]bool
+	queue        chan events.Event
+	retired      chan struct{}
+}
+
+// NewService creates a new in-memory event bus and starts its dispatcher
+func NewService(config events.EventConfig) *Service {
 	if !config.IsValid() {
 		config = events.DefaultEventConfig()
 	}
 
-	return &service{
-		events:        make([]events.Event, 0),
-		subscriptions: make(map[string]*events.EventSubscription),
-		handlers:      make(map[string][]eventhandler.Service),
+	s := &Service{
 		config:        config,
+		events:        make([]events.Event, 0),
+		subscriptions: make(map[string]*subscription),
+		queue:         make(chan events.Event, config.BufferSize),
+		dispatched:    make(chan struct{}),
+		stop:          make(chan struct{}),
 	}
+	go s.dispatch()
+	return s
 }
 
-// Publish publishes an event
-func (s *service) Publish(ctx context.Context, event events.Event) error {
-	if !event.IsValid() {
-		return events.ErrInvalidEvent
-	}
+// SetDeadLetters sends the events handlers keep failing or panic on to the
+// dead-letter queue instead of dropping them
+func (s *Service) SetDeadLetters(queue deadletter.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLetters = queue
+}
 
-	// Set timestamp if not provided
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
+// Publish stores the event and queues it for its subscribers. It waits for
+// room in the buffer while it is full, until the context ends.
+func (s *Service) Publish(ctx context.Context, event events.Event) error {
+	if err := prepare(&event); err != nil {
+		return err
 	}
 
-	// Generate ID if not provided
-	if event.ID == "" {
-		event.ID = uuid.New().String()
+	if err := s.enqueue(ctx, event); err != nil {
+		return err
 	}
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Store the event
 	s.events = append(s.events, event)
+	s.mu.Unlock()
+	return nil
+}
 
-	// Handle the event asynchronously
-	go s.handleEvent(ctx, event)
+// enqueue hands the event to the dispatcher
+func (s *Service) enqueue(ctx context.Context, event events.Event) error {
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("%w: event bus is closed", events.ErrPublishFailed)
+	}
 
-	return nil
+	select {
+	case s.queue <- event:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: event buffer is full: %v", events.ErrPublishFailed, ctx.Err())
+	}
 }
 
 // PublishBatch publishes multiple events
-func (s *service) PublishBatch(ctx context.Context, eventList []events.Event) error {
+func (s *Service) PublishBatch(ctx context.Context, eventList []events.Event) error {
 	for _, event := range eventList {
 		if err := s.Publish(ctx, event); err != nil {
 			return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
@@ -73,78 +124,137 @@ func (s *service) PublishBatch(ctx context.Context, eventList []events.Event) er
 	return nil
 }
 
-// Subscribe subscribes to events by topics
-func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+// Subscribe registers the handler for the given topics, which may name event
+// types, keys of the configured topics such as "user.events", or the topics
+// they map to. Without topics the handler receives every event; either way
+// only the event types the handler reports handling are delivered, when it
+// reports any.
+func (s *Service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
 	if handler == nil {
 		return fmt.Errorf("handler cannot be nil")
 	}
 
-	subscriptionID := uuid.New().String()
+	sub := &subscription{
+		EventSubscription: events.EventSubscription{
+			ID:        uuid.New().String(),
+			Topics:    append([]string(nil), topics...),
+			Handler:   handler,
+			CreatedAt: time.Now(),
+			Active:    true,
+		},
+		handledTypes: handledTypes(handler),
+		queue:        make(chan events.Event, s.config.BufferSize),
+		retired:      make(chan struct{}),
+	}
 
-	subscription := &events.EventSubscription{
-		ID:        subscriptionID,
-		Topics:    topics,
-		Handler:   handler,
-		CreatedAt: time.Now(),
-		Active:    true,
+	s.queueMu.RLock()
+	closed := s.closed
+	s.queueMu.RUnlock()
+	if closed {
+		return fmt.Errorf("%w: event bus is closed", events.ErrSubscriptionFailed)
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	s.subscriptions[subscriptionID] = subscription
-
-	// Register handler for each event type it handles
-	for _, eventType := range handler.GetHandledEventTypes() {
-		s.handlers[eventType] = append(s.handlers[eventType], handler)
-	}
-
+	s.subscriptions[sub.ID] = sub
+	s.workers.Add(1)
+	go s.work(sub)
 	return nil
 }
 
-// Unsubscribe removes a subscription
-func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+// Unsubscribe removes a subscription; events already in its queue are still
+// delivered
+func (s *Service) Unsubscribe(ctx context.Context, subscriptionID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	subscription, exists := s.subscriptions[subscriptionID]
+	sub, exists := s.subscriptions[subscriptionID]
 	if !exists {
 		return fmt.Errorf("subscription %s not found", subscriptionID)
 	}
+	close(sub.retired)
+	delete(s.subscriptions, subscriptionID)
+	return nil
+}
 
-	// Mark subscription as inactive
-	subscription.Active = false
+// Subscriptions returns the registered subscriptions, oldest first
+func (s *Service) Subscriptions() []events.EventSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	// Remove handlers
-	for _, eventType := range subscription.Handler.GetHandledEventTypes() {
-		handlers := s.handlers[eventType]
-		for i, handler := range handlers {
-			if handler == subscription.Handler {
-				s.handlers[eventType] = append(handlers[:i], handlers[i+1:]...)
-				break
-			}
+	result := make([]events.EventSubscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		result = append(result, sub.EventSubscription)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
 		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Topic returns the configured topic the event type is routed to: the value
+// of the topic key made of its first segment, so "user.registered" goes to
+// the topic of "user.events". Types without one are their own topic.
+func (s *Service) Topic(eventType string) string {
+	if topic, ok := s.config.Topics[topicKey(eventType)]; ok {
+		return topic
 	}
+	return eventType
+}
 
-	delete(s.subscriptions, subscriptionID)
-	return nil
+// Close stops accepting events and waits until the queued ones have been
+// delivered, retries included. When the context ends first the pending
+// retries are abandoned and its error returned.
+func (s *Service) Close(ctx context.Context) error {
+	s.queueMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.queueMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		<-s.dispatched
+		s.mu.Lock()
+		for id, sub := range s.subscriptions {
+			close(sub.retired)
+			delete(s.subscriptions, id)
+		}
+		s.mu.Unlock()
+		s.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.stopOnce.Do(func() { close(s.stop) })
+		return ctx.Err()
+	}
 }
 
 // GetEvents retrieves events based on filters
-func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+func (s *Service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var result []events.Event
 
 	for _, event := range s.events {
-		if s.matchesFilters(event, filters) {
+		if matchesFilters(event, filters) {
 			result = append(result, event)
 		}
 	}
 
 	// Apply pagination
-	if filters.Offset > 0 && filters.Offset < len(result) {
+	if filters.Offset >= len(result) {
+		result = nil
+	} else if filters.Offset > 0 {
 		result = result[filters.Offset:]
 	}
 
@@ -156,7 +266,7 @@ func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([
 }
 
 // GetEventsByAggregate retrieves events for a specific aggregate
-func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+func (s *Service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
 	filters := events.EventFilters{
 		AggregateID: aggregateID,
 		Limit:       limit,
@@ -166,44 +276,210 @@ func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string,
 }
 
 // ReplayEvents replays events for an aggregate
-func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+func (s *Service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
+	var replay []events.Event
 	for _, event := range s.events {
 		if event.AggregateID == aggregateID && event.Version >= fromVersion {
-			if err := handler.Handle(ctx, event); err != nil {
-				return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
-			}
+			replay = append(replay, event)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, event := range replay {
+		if err := handler.Handle(ctx, event); err != nil {
+			return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
 		}
 	}
 
 	return nil
 }
 
-// handleEvent processes an event by calling registered handlers
-func (s *service) handleEvent(ctx context.Context, event events.Event) {
+// dispatch hands every queued event to the subscriptions it matches until the
+// queue is closed and empty
+func (s *Service) dispatch() {
+	defer close(s.dispatched)
+
+	for event := range s.queue {
+		for _, sub := range s.matching(event) {
+			select {
+			case sub.queue <- event:
+			case <-sub.retired:
+			case <-s.stop:
+			}
+		}
+	}
+}
+
+// work delivers the subscription's events until it is retired and its queue
+// is empty, or the bus stops waiting for it
+func (s *Service) work(sub *subscription) {
+	defer s.workers.Done()
+
+	for {
+		select {
+		case event := <-sub.queue:
+			s.deliver(event, sub)
+		case <-sub.retired:
+			for len(sub.queue) > 0 {
+				select {
+				case event := <-sub.queue:
+					s.deliver(event, sub)
+				case <-s.stop:
+					return
+				}
+			}
+			return
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// matching returns the subscriptions the event is routed to
+func (s *Service) matching(event events.Event) []*subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*subscription
+	for _, sub := range s.subscriptions {
+		if len(sub.handledTypes) > 0 && !sub.handledTypes[event.Type] {
+			continue
+		}
+		if s.routes(event.Type, sub.Topics) {
+			matched = append(matched, sub)
+		}
+	}
+	return matched
+}
+
+// routes reports whether an event type belongs to any of the topics
+func (s *Service) routes(eventType string, topics []string) bool {
+	if len(topics) == 0 {
+		return true
+	}
+	key := topicKey(eventType)
+	topic := s.Topic(eventType)
+	for _, t := range topics {
+		if t == eventType || t == key || t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver calls the handler until it succeeds, retrying failures after the
+// configured backoff up to MaxRetries times. Events it gives up on, and
+// events the handler panics on, go to the dead-letter queue when one is set.
+func (s *Service) deliver(event events.Event, sub *subscription) {
+	retry := s.config.RetryConfig
+	delay := retry.InitialDelay
+	var firstFailedAt time.Time
+	logCtx := correlation.Caused(context.Background(), event.ID, event.Metadata.CorrelationID)
+
+	for attempt := 0; ; attempt++ {
+		panicked, err := handle(event, sub)
+		if err == nil {
+			return
+		}
+		if firstFailedAt.IsZero() {
+			firstFailedAt = time.Now()
+		}
+		if panicked || attempt >= retry.MaxRetries {
+			correlation.Logf(logCtx, "Giving up on event %s for subscription %s after %d attempts: %v", event.ID, sub.ID, attempt+1, err)
+			s.deadLetter(deadletter.Letter{
+				Event:         event,
+				Provider:      "memory",
+				Subscription:  sub.ID,
+				Handler:       fmt.Sprintf("%T", sub.Handler),
+				Error:         err.Error(),
+				Attempts:      attempt + 1,
+				Poison:        panicked,
+				FirstFailedAt: firstFailedAt,
+			})
+			return
+		}
+		correlation.Logf(logCtx, "Error handling event %s for subscription %s, retrying in %s: %v", event.ID, sub.ID, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			timer.Stop()
+			log.Printf("Abandoning event %s for subscription %s on shutdown", event.ID, sub.ID)
+			return
+		}
+
+		if retry.BackoffFactor > 1 {
+			delay = time.Duration(float64(delay) * retry.BackoffFactor)
+		}
+		if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+}
+
+// handle calls the handler once, turning a panic into an error
+func handle(event events.Event, sub *subscription) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked, err = true, fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return false, sub.Handler.Handle(context.Background(), event)
+}
+
+// deadLetterTimeout bounds how long a worker waits to dead-letter an event
+const deadLetterTimeout = 5 * time.Second
+
+// deadLetter records an event the bus gave up on, if a queue is set
+func (s *Service) deadLetter(letter deadletter.Letter) {
 	s.mu.RLock()
-	handlers, exists := s.handlers[event.Type]
+	queue := s.deadLetters
 	s.mu.RUnlock()
+	if queue == nil {
+		return
+	}
 
-	if !exists {
-		return // No handlers for this event type
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterTimeout)
+	defer cancel()
+	if _, err := queue.Add(ctx, letter); err != nil {
+		log.Printf("Failed to dead-letter event %s: %v", letter.Event.ID, err)
 	}
+}
 
-	for _, handler := range handlers {
-		go func(h eventhandler.Service) {
-			if err := h.Handle(ctx, event); err != nil {
-				// In a real implementation, you might want to log this error
-				// or implement retry logic
-				fmt.Printf("Error handling event %s: %v\n", event.ID, err)
-			}
-		}(handler)
+// prepare fills the ID and timestamp and validates the event
+func prepare(event *events.Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if !event.IsValid() {
+		return events.ErrInvalidEvent
+	}
+	return nil
+}
+
+// topicKey returns the configured topic key of an event type, its first
+// segment followed by ".events"
+func topicKey(eventType string) string {
+	domain, _, _ := strings.Cut(eventType, ".")
+	return domain + ".events"
+}
+
+// handledTypes returns the set of event types the handler accepts
+func handledTypes(handler eventhandler.Service) map[string]bool {
+	types := make(map[string]bool)
+	for _, eventType := range handler.GetHandledEventTypes() {
+		types[eventType] = true
 	}
+	return types
 }
 
 // matchesFilters checks if an event matches the given filters
-func (s *service) matchesFilters(event events.Event, filters events.EventFilters) bool {
+func matchesFilters(event events.Event, filters events.EventFilters) bool {
 	// Check event types
 	if len(filters.EventTypes) > 0 {
 		found := false
@@ -0,0 +1,316 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/deadletter"
+	deadLetterMemory "github.com/gentra/decorator-arch-go/internal/deadletter/memory"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recordingHandler records the events it receives, failing the first
+// failures calls
+type recordingHandler struct {
+	mu       sync.Mutex
+	types    []string
+	failures int
+	calls    int
+	received []events.Event
+	release  chan struct{}
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	if h.release != nil {
+		<-h.release
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("handler unavailable")
+	}
+	h.received = append(h.received, event.(events.Event))
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return h.types
+}
+
+func (h *recordingHandler) snapshot() (int, []events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls, append([]events.Event(nil), h.received...)
+}
+
+func testConfig() events.EventConfig {
+	config := events.DefaultEventConfig()
+	config.RetryConfig.InitialDelay = time.Millisecond
+	config.RetryConfig.MaxDelay = 5 * time.Millisecond
+	return config
+}
+
+func closeBus(t *testing.T, bus *eventsMemory.Service) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(ctx))
+}
+
+func TestMemoryEvents_GivenTopics_WhenPublishing_ThenRoutesToMatchingSubscriptions(t *testing.T) {
+	// Arrange
+	bus := eventsMemory.NewService(testConfig())
+	byType := &recordingHandler{}
+	byKey := &recordingHandler{}
+	byTopic := &recordingHandler{}
+	otherDomain := &recordingHandler{}
+	filtered := &recordingHandler{types: []string{events.EventTypeUserUpdated}}
+	ctx := context.Background()
+	require.NoError(t, bus.Subscribe(ctx, []string{events.EventTypeUserRegistered}, byType))
+	require.NoError(t, bus.Subscribe(ctx, []string{"user.events"}, byKey))
+	require.NoError(t, bus.Subscribe(ctx, []string{"user-domain-events"}, byTopic))
+	require.NoError(t, bus.Subscribe(ctx, []string{"auth.events"}, otherDomain))
+	require.NoError(t, bus.Subscribe(ctx, nil, filtered))
+
+	// Act
+	event := testkit.NewEventBuilder().Build()
+	require.NoError(t, bus.Publish(ctx, *event))
+	closeBus(t, bus)
+
+	// Assert
+	for name, handler := range map[string]*recordingHandler{"event type": byType, "topic key": byKey, "topic": byTopic} {
+		_, received := handler.snapshot()
+		if assert.Len(t, received, 1, name) {
+			assert.Equal(t, event.ID, received[0].ID, name)
+		}
+	}
+	calls, _ := otherDomain.snapshot()
+	assert.Zero(t, calls, "other topics are not delivered")
+	calls, _ = filtered.snapshot()
+	assert.Zero(t, calls, "types the handler does not handle are not delivered")
+	assert.Equal(t, "user-domain-events", bus.Topic(events.EventTypeUserRegistered))
+}
+
+func TestMemoryEvents_GivenFailingHandler_WhenPublishing_ThenRetriesUntilDelivered(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		expectedCalls int
+		delivered     bool
+	}{
+		{
+			name:          "Given a handler failing twice, When publishing, Then delivers on the third attempt",
+			failures:      2,
+			expectedCalls: 3,
+			delivered:     true,
+		},
+		{
+			name:          "Given a handler failing past the retries, When publishing, Then gives up after MaxRetries",
+			failures:      10,
+			expectedCalls: 4,
+			delivered:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			bus := eventsMemory.NewService(testConfig())
+			handler := &recordingHandler{failures: tt.failures}
+			require.NoError(t, bus.Subscribe(context.Background(), nil, handler))
+
+			// Act
+			require.NoError(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+			closeBus(t, bus)
+
+			// Assert
+			calls, received := handler.snapshot()
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.delivered, len(received) == 1)
+		})
+	}
+}
+
+// panickingHandler panics on every event
+type panickingHandler struct{}
+
+func (panickingHandler) Handle(ctx context.Context, event interface{}) error {
+	panic("malformed payload")
+}
+
+func (panickingHandler) GetHandledEventTypes() []string {
+	return nil
+}
+
+func TestMemoryEvents_GivenDeadLetterQueue_WhenHandlerGivesUp_ThenDeadLettersEvent(t *testing.T) {
+	tests := []struct {
+		name             string
+		handler          eventhandler.Service
+		expectedAttempts int
+		expectedPoison   bool
+		expectedError    string
+	}{
+		{
+			name:             "Given a handler failing past the retries, When publishing, Then dead-letters after MaxRetries",
+			handler:          &recordingHandler{failures: 10},
+			expectedAttempts: 4,
+			expectedError:    "handler unavailable",
+		},
+		{
+			name:             "Given a panicking handler, When publishing, Then dead-letters it as poison without retrying",
+			handler:          panickingHandler{},
+			expectedAttempts: 1,
+			expectedPoison:   true,
+			expectedError:    "handler panicked: malformed payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			bus := eventsMemory.NewService(testConfig())
+			queue := deadLetterMemory.NewService()
+			bus.SetDeadLetters(queue)
+			require.NoError(t, bus.Subscribe(context.Background(), nil, tt.handler))
+			event := testkit.NewEventBuilder().Build()
+
+			// Act
+			require.NoError(t, bus.Publish(context.Background(), *event))
+			closeBus(t, bus)
+
+			// Assert
+			letters, err := queue.List(context.Background(), deadletter.Filter{})
+			require.NoError(t, err)
+			require.Len(t, letters, 1)
+			assert.Equal(t, event.ID, letters[0].Event.ID)
+			assert.Equal(t, "memory", letters[0].Provider)
+			assert.NotEmpty(t, letters[0].Subscription)
+			assert.Equal(t, tt.expectedAttempts, letters[0].Attempts)
+			assert.Equal(t, tt.expectedPoison, letters[0].Poison)
+			assert.Equal(t, tt.expectedError, letters[0].Error)
+		})
+	}
+}
+
+func TestMemoryEvents_GivenFullBuffer_WhenPublishing_ThenWaitsUntilContextEnds(t *testing.T) {
+	// Arrange
+	config := testConfig()
+	config.BufferSize = 1
+	bus := eventsMemory.NewService(config)
+	handler := &recordingHandler{release: make(chan struct{})}
+	require.NoError(t, bus.Subscribe(context.Background(), nil, handler))
+
+	// The dispatcher hands the first event to the blocked handler and the
+	// second fills the buffer
+	require.NoError(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+	require.NoError(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+	require.Eventually(t, func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		return bus.Publish(ctx, *testkit.NewEventBuilder().Build()) != nil
+	}, time.Second, 20*time.Millisecond)
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := bus.Publish(ctx, *testkit.NewEventBuilder().Build())
+
+	// Assert
+	assert.ErrorIs(t, err, events.ErrPublishFailed)
+	close(handler.release)
+	closeBus(t, bus)
+}
+
+func TestMemoryEvents_GivenQueuedEvents_WhenClosing_ThenDrainsThemAndRejectsNewOnes(t *testing.T) {
+	// Arrange
+	bus := eventsMemory.NewService(testConfig())
+	handler := &recordingHandler{}
+	require.NoError(t, bus.Subscribe(context.Background(), nil, handler))
+	for i := 0; i < 50; i++ {
+		require.NoError(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+	}
+
+	// Act
+	closeBus(t, bus)
+
+	// Assert
+	_, received := handler.snapshot()
+	assert.Len(t, received, 50)
+	assert.ErrorIs(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()), events.ErrPublishFailed)
+	assert.ErrorIs(t, bus.Subscribe(context.Background(), nil, handler), events.ErrSubscriptionFailed)
+}
+
+func TestMemoryEvents_GivenStuckRetries_WhenClosingPastDeadline_ThenAbandonsThem(t *testing.T) {
+	// Arrange
+	config := testConfig()
+	config.RetryConfig.InitialDelay = time.Hour
+	config.RetryConfig.MaxDelay = time.Hour
+	bus := eventsMemory.NewService(config)
+	handler := &recordingHandler{failures: 1}
+	require.NoError(t, bus.Subscribe(context.Background(), nil, handler))
+	require.NoError(t, bus.Publish(context.Background(), *testkit.NewEventBuilder().Build()))
+	require.Eventually(t, func() bool {
+		calls, _ := handler.snapshot()
+		return calls == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := bus.Close(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMemoryEvents_GivenSubscriptions_WhenUnsubscribing_ThenRemovesFromRegistry(t *testing.T) {
+	// Arrange
+	bus := eventsMemory.NewService(testConfig())
+	handler := &recordingHandler{}
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"user.events"}, handler))
+	require.NoError(t, bus.Subscribe(context.Background(), []string{"auth.events"}, handler))
+	subscriptions := bus.Subscriptions()
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, []string{"user.events"}, subscriptions[0].Topics)
+
+	// Act
+	err := bus.Unsubscribe(context.Background(), subscriptions[0].ID)
+
+	// Assert
+	require.NoError(t, err)
+	remaining := bus.Subscriptions()
+	if assert.Len(t, remaining, 1) {
+		assert.Equal(t, subscriptions[1].ID, remaining[0].ID)
+	}
+	assert.Error(t, bus.Unsubscribe(context.Background(), subscriptions[0].ID))
+	closeBus(t, bus)
+}
+
+func TestMemoryEvents_GivenEventWithoutID_WhenPublishing_ThenAssignsOneAndStoresIt(t *testing.T) {
+	// Arrange
+	bus := eventsMemory.NewService(testConfig())
+	event := testkit.NewEventBuilder().Build()
+	event.ID = ""
+
+	// Act
+	err := bus.Publish(context.Background(), *event)
+
+	// Assert
+	require.NoError(t, err)
+	stored, err := bus.GetEventsByAggregate(context.Background(), event.AggregateID, 0)
+	require.NoError(t, err)
+	if assert.Len(t, stored, 1) {
+		assert.NotEmpty(t, stored[0].ID)
+	}
+	closeBus(t, bus)
+}
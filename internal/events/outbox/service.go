@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/eventoutbox"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// service implements events.Service by recording published events in the
+// event outbox instead of sending them. Inside a transaction carried by the
+// context they commit with the change they describe; the outbox relay then
+// publishes them to next. Subscriptions and queries go straight to next.
+type service struct {
+	next   events.Service
+	outbox eventoutbox.Service
+}
+
+// NewService creates an events decorator publishing through the outbox
+func NewService(next events.Service, outbox eventoutbox.Service) events.Service {
+	return &service{
+		next:   next,
+		outbox: outbox,
+	}
+}
+
+// Publish records the event in the outbox
+func (s *service) Publish(ctx context.Context, event events.Event) error {
+	return s.outbox.Append(ctx, event)
+}
+
+// PublishBatch records the events in the outbox
+func (s *service) PublishBatch(ctx context.Context, evts []events.Event) error {
+	return s.outbox.Append(ctx, evts...)
+}
+
+// Subscribe passes through
+func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	return s.next.Subscribe(ctx, topics, handler)
+}
+
+// Unsubscribe passes through
+func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return s.next.Unsubscribe(ctx, subscriptionID)
+}
+
+// GetEvents passes through; events still in the outbox are not included
+func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	return s.next.GetEvents(ctx, filters)
+}
+
+// GetEventsByAggregate passes through
+func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	return s.next.GetEventsByAggregate(ctx, aggregateID, limit)
+}
+
+// ReplayEvents passes through
+func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	return s.next.ReplayEvents(ctx, aggregateID, fromVersion, handler)
+}
@@ -0,0 +1,45 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventOutboxMemory "github.com/gentra/decorator-arch-go/internal/eventoutbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	eventsOutbox "github.com/gentra/decorator-arch-go/internal/events/outbox"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestPublish_GivenOutbox_WhenPublishing_ThenBusReceivesEventOnlyOnceRelayed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	outbox := eventOutboxMemory.NewService()
+	service := eventsOutbox.NewService(bus, outbox)
+	event := testkit.NewEventBuilder().Build()
+
+	// Act
+	require.NoError(t, service.Publish(ctx, *event))
+	before, err := bus.GetEventsByAggregate(ctx, event.AggregateID, 0)
+	require.NoError(t, err)
+	report, err := outbox.Relay(ctx, 0, bus.Publish)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Empty(t, before)
+	assert.Equal(t, 1, report.Published)
+	after, err := service.GetEventsByAggregate(ctx, event.AggregateID, 0)
+	require.NoError(t, err)
+	if assert.Len(t, after, 1) {
+		assert.Equal(t, event.ID, after[0].ID)
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(closeCtx))
+}
@@ -0,0 +1,227 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Typed payloads of the domain events. Event.Data carries them as a map, so
+// events cross brokers and stores unchanged; EncodeData and Event.DecodeData
+// convert between the two. The schema registry (see eventschema) holds the
+// payload of every version of an event type.
+
+// UserRegisteredData is the payload of user.registered
+type UserRegisteredData struct {
+	UserID       string    `json:"user_id"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserRegisteredData) Validate() error {
+	return required("user_id", d.UserID, "email", d.Email)
+}
+
+// UserUpdatedData is the payload of user.updated, with the old and new
+// value of every changed field
+type UserUpdatedData struct {
+	UserID    string                 `json:"user_id"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Changes   map[string]interface{} `json:"changes"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserUpdatedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// UserDeactivatedData is the payload of user.deactivated
+type UserDeactivatedData struct {
+	UserID        string    `json:"user_id"`
+	DeactivatedAt time.Time `json:"deactivated_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserDeactivatedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// UserDeletedData is the payload of user.deleted
+type UserDeletedData struct {
+	UserID    string    `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserDeletedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// UserErasedData is the payload of user.erased
+type UserErasedData struct {
+	UserID   string    `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserErasedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// EmailVerifiedData is the payload of user.email_verified
+type EmailVerifiedData struct {
+	UserID     string     `json:"user_id"`
+	Email      string     `json:"email"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// Validate checks the fields handlers rely on
+func (d EmailVerifiedData) Validate() error {
+	return required("user_id", d.UserID, "email", d.Email)
+}
+
+// PreferencesUpdatedData is the payload of user.preferences.updated, with
+// the preferences after the update
+type PreferencesUpdatedData struct {
+	UserID          string                 `json:"user_id"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	OriginSessionID string                 `json:"origin_session_id,omitempty"`
+	Changes         map[string]interface{} `json:"changes"`
+	Preferences     PreferencesSnapshot    `json:"preferences"`
+}
+
+// Validate checks the fields handlers rely on
+func (d PreferencesUpdatedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// PreferencesSnapshot is the state of a user's preferences in
+// PreferencesUpdatedData
+type PreferencesSnapshot struct {
+	Theme              string          `json:"theme"`
+	Language           string          `json:"language"`
+	Timezone           string          `json:"timezone"`
+	EmailNotifications bool            `json:"email_notifications"`
+	PushNotifications  bool            `json:"push_notifications"`
+	SMSNotifications   bool            `json:"sms_notifications"`
+	NotificationTypes  map[string]bool `json:"notification_types,omitempty"`
+}
+
+// UserLoggedInData is the payload of auth.user.logged_in
+type UserLoggedInData struct {
+	UserID  string    `json:"user_id"`
+	Email   string    `json:"email,omitempty"`
+	LoginAt time.Time `json:"login_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d UserLoggedInData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// PasswordChangedData is the payload of auth.password.changed; Reset marks
+// a change made with a password reset token
+type PasswordChangedData struct {
+	UserID    string    `json:"user_id"`
+	ChangedAt time.Time `json:"changed_at"`
+	Reset     bool      `json:"reset,omitempty"`
+}
+
+// Validate checks the fields handlers rely on
+func (d PasswordChangedData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// TokenReuseDetectedData is the payload of auth.token.reuse_detected
+type TokenReuseDetectedData struct {
+	UserID     string    `json:"user_id"`
+	FamilyID   string    `json:"family_id"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Validate checks the fields handlers rely on
+func (d TokenReuseDetectedData) Validate() error {
+	return required("user_id", d.UserID, "family_id", d.FamilyID)
+}
+
+// InAppNotificationCreatedData is the payload of notification.in_app.created
+type InAppNotificationCreatedData struct {
+	UserID         string                 `json:"user_id"`
+	NotificationID string                 `json:"notification_id"`
+	Title          string                 `json:"title"`
+	Body           string                 `json:"body,omitempty"`
+	Category       string                 `json:"category,omitempty"`
+	Link           string                 `json:"link,omitempty"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+	Priority       string                 `json:"priority,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UnreadCount    int                    `json:"unread_count"`
+}
+
+// Validate checks the fields handlers rely on
+func (d InAppNotificationCreatedData) Validate() error {
+	return required("user_id", d.UserID, "notification_id", d.NotificationID)
+}
+
+// InAppNotificationsReadData is the payload of notification.in_app.read,
+// naming the notifications read, or All of them
+type InAppNotificationsReadData struct {
+	UserID          string    `json:"user_id"`
+	NotificationIDs []string  `json:"notification_ids,omitempty"`
+	All             bool      `json:"all,omitempty"`
+	UnreadCount     int       `json:"unread_count"`
+	ReadAt          time.Time `json:"read_at"`
+	OriginSessionID string    `json:"origin_session_id,omitempty"`
+}
+
+// Validate checks the fields handlers rely on
+func (d InAppNotificationsReadData) Validate() error {
+	return required("user_id", d.UserID)
+}
+
+// EncodeData converts a typed payload into Event.Data
+func EncodeData(payload interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, fmt.Errorf("%w: payload is not an object: %v", ErrInvalidEvent, err)
+	}
+	return data, nil
+}
+
+// DecodeData converts Event.Data into the typed payload v points to
+func (e *Event) DecodeData(v interface{}) error {
+	encoded, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if err := json.Unmarshal(encoded, v); err != nil {
+		return fmt.Errorf("%w: data of event %s: %v", ErrInvalidEvent, e.ID, err)
+	}
+	return nil
+}
+
+// EffectiveSchemaVersion returns the schema version of the event's payload;
+// events published before payloads were versioned are version 1
+func (e *Event) EffectiveSchemaVersion() int {
+	if e.SchemaVersion <= 0 {
+		return 1
+	}
+	return e.SchemaVersion
+}
+
+// required reports the first empty field of name/value pairs
+func required(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			return fmt.Errorf("%s is required", pairs[i])
+		}
+	}
+	return nil
+}
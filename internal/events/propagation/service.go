@@ -0,0 +1,104 @@
+package propagation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// service implements events.Service by carrying correlation across events.
+// Published events take the correlation and causation IDs of the context
+// unless they carry their own, and an event published outside any
+// correlation starts one under its own ID. Handlers run with a context in
+// the correlation of the event they handle, caused by it, so the events
+// they publish and the audit entries and log lines they write link back.
+type service struct {
+	next events.Service
+}
+
+// NewService creates an events decorator propagating correlation
+func NewService(next events.Service) events.Service {
+	return &service{next: next}
+}
+
+// Publish correlates the event with the context
+func (s *service) Publish(ctx context.Context, event events.Event) error {
+	return s.next.Publish(ctx, correlate(ctx, event))
+}
+
+// PublishBatch correlates every event with the context
+func (s *service) PublishBatch(ctx context.Context, evts []events.Event) error {
+	correlated := make([]events.Event, len(evts))
+	for i, event := range evts {
+		correlated[i] = correlate(ctx, event)
+	}
+	return s.next.PublishBatch(ctx, correlated)
+}
+
+// Subscribe runs the handler in the correlation of each event
+func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	return s.next.Subscribe(ctx, topics, &causedHandler{next: handler})
+}
+
+// Unsubscribe passes through
+func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return s.next.Unsubscribe(ctx, subscriptionID)
+}
+
+// GetEvents passes through
+func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	return s.next.GetEvents(ctx, filters)
+}
+
+// GetEventsByAggregate passes through
+func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	return s.next.GetEventsByAggregate(ctx, aggregateID, limit)
+}
+
+// ReplayEvents runs the handler in the correlation of each replayed event
+func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	return s.next.ReplayEvents(ctx, aggregateID, fromVersion, &causedHandler{next: handler})
+}
+
+// Close drains the decorated bus when it has to be
+func (s *service) Close(ctx context.Context) error {
+	if bus, ok := s.next.(interface{ Close(context.Context) error }); ok {
+		return bus.Close(ctx)
+	}
+	return nil
+}
+
+// correlate fills the IDs the event lacks, giving it an ID to root a new
+// correlation at when the context has none
+func correlate(ctx context.Context, event events.Event) events.Event {
+	event.Metadata.Correlate(ctx)
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = event.ID
+	}
+	return event
+}
+
+// causedHandler hands events to the handler with a context caused by them
+type causedHandler struct {
+	next eventhandler.Service
+}
+
+// Handle runs the handler in the event's correlation
+func (h *causedHandler) Handle(ctx context.Context, event interface{}) error {
+	if e, ok := event.(events.Event); ok {
+		ctx = correlation.Caused(ctx, e.ID, e.Metadata.CorrelationID)
+	}
+	return h.next.Handle(ctx, event)
+}
+
+// GetHandledEventTypes returns the handler's event types
+func (h *causedHandler) GetHandledEventTypes() []string {
+	return h.next.GetHandledEventTypes()
+}
@@ -0,0 +1,121 @@
+package propagation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/events/propagation"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// followUpHandler publishes a user.updated event for every event it handles
+type followUpHandler struct {
+	bus events.Service
+}
+
+func (h *followUpHandler) Handle(ctx context.Context, event interface{}) error {
+	e := event.(events.Event)
+	followUp := *testkit.NewEventBuilder().WithType(events.EventTypeUserUpdated).Build()
+	followUp.ID = ""
+	followUp.AggregateID = e.AggregateID
+	return h.bus.Publish(ctx, followUp)
+}
+
+func (h *followUpHandler) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered}
+}
+
+func newBus(t *testing.T) (*eventsMemory.Service, events.Service) {
+	t.Helper()
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = bus.Close(ctx)
+	})
+	return bus, propagation.NewService(bus)
+}
+
+func TestPublish_GivenContextCorrelation_WhenPublishing_ThenEventCarriesIt(t *testing.T) {
+	tests := []struct {
+		name                string
+		ctx                 context.Context
+		metadata            events.EventMetadata
+		expectedCorrelation string
+		expectedCausation   string
+	}{
+		{
+			name:                "Given a request context, When publishing, Then takes its IDs",
+			ctx:                 correlation.WithIDs(context.Background(), "request-1", "cause-1"),
+			expectedCorrelation: "request-1",
+			expectedCausation:   "cause-1",
+		},
+		{
+			name:                "Given an event with its own IDs, When publishing, Then keeps them",
+			ctx:                 correlation.WithIDs(context.Background(), "request-1", "cause-1"),
+			metadata:            events.EventMetadata{CorrelationID: "own", CausationID: "own-cause"},
+			expectedCorrelation: "own",
+			expectedCausation:   "own-cause",
+		},
+		{
+			name:                "Given no correlation, When publishing, Then starts one at the event",
+			ctx:                 context.Background(),
+			expectedCorrelation: "00000000-0000-4000-8000-0000000000e1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			bus, service := newBus(t)
+			event := *testkit.NewEventBuilder().Build()
+			event.Metadata.CorrelationID = tt.metadata.CorrelationID
+			event.Metadata.CausationID = tt.metadata.CausationID
+
+			// Act
+			err := service.Publish(tt.ctx, event)
+
+			// Assert
+			require.NoError(t, err)
+			published, err := bus.GetEventsByAggregate(context.Background(), event.AggregateID, 0)
+			require.NoError(t, err)
+			if assert.Len(t, published, 1) {
+				assert.Equal(t, tt.expectedCorrelation, published[0].Metadata.CorrelationID)
+				assert.Equal(t, tt.expectedCausation, published[0].Metadata.CausationID)
+			}
+		})
+	}
+}
+
+func TestSubscribe_GivenHandlerPublishing_WhenHandling_ThenFollowUpIsCausedByTheEvent(t *testing.T) {
+	// Arrange
+	bus, service := newBus(t)
+	require.NoError(t, service.Subscribe(context.Background(), []string{events.EventTypeUserRegistered}, &followUpHandler{bus: service}))
+	event := *testkit.NewEventBuilder().Build()
+	ctx := correlation.WithIDs(context.Background(), "request-1", "cause-1")
+
+	// Act
+	require.NoError(t, service.Publish(ctx, event))
+
+	// Assert
+	var followUp *events.Event
+	assert.Eventually(t, func() bool {
+		published, err := bus.GetEvents(context.Background(), events.EventFilters{EventTypes: []string{events.EventTypeUserUpdated}})
+		if err != nil || len(published) == 0 {
+			return false
+		}
+		followUp = &published[0]
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	if followUp != nil {
+		assert.Equal(t, "request-1", followUp.Metadata.CorrelationID)
+		assert.Equal(t, event.ID, followUp.Metadata.CausationID)
+	}
+}
@@ -0,0 +1,326 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	gpubsub "cloud.google.com/go/pubsub"
+	"github.com/google/uuid"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/plainjson"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Config contains the Pub/Sub topic events are published to and the
+// subscription this service consumes
+type Config struct {
+	// ProjectID owns the topic and subscription. Credentials come from
+	// Application Default Credentials, e.g. workload identity.
+	ProjectID string
+
+	TopicID        string
+	SubscriptionID string
+
+	// EnableOrdering publishes with the aggregate ID as ordering key, so each
+	// aggregate's events are delivered in order
+	EnableOrdering bool
+
+	// DeadLetterTopicID receives messages that failed MaxDeliveryAttempts
+	// deliveries; it applies to subscriptions created by this service
+	DeadLetterTopicID   string
+	MaxDeliveryAttempts int
+
+	// EmulatorHost connects to a Pub/Sub emulator without authentication,
+	// e.g. localhost:8085; PUBSUB_EMULATOR_HOST is honored as well
+	EmulatorHost string
+
+	// CreateIfMissing creates the topic and subscription on first use, for
+	// emulators and development projects
+	CreateIfMissing bool
+
+	// Codec encodes the message bodies; nil publishes plain JSON events
+	Codec eventcodec.Service
+}
+
+// service implements events.Service with Google Cloud Pub/Sub.
+// Pub/Sub is a bus, not a store, so querying and replay are not supported.
+type service struct {
+	client *gpubsub.Client
+	topic  *gpubsub.Topic
+	config Config
+
+	mu            sync.Mutex
+	subscriptions map[string]context.CancelFunc
+}
+
+// NewService creates a new Pub/Sub events service
+func NewService(ctx context.Context, config Config) (events.Service, error) {
+	if config.ProjectID == "" || config.TopicID == "" {
+		return nil, fmt.Errorf("Pub/Sub project and topic are required")
+	}
+	if config.MaxDeliveryAttempts <= 0 {
+		config.MaxDeliveryAttempts = 5
+	}
+	if config.Codec == nil {
+		config.Codec = plainjson.NewService()
+	}
+
+	var options []option.ClientOption
+	if config.EmulatorHost != "" {
+		options = append(options,
+			option.WithEndpoint(config.EmulatorHost),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+	}
+
+	client, err := gpubsub.NewClient(ctx, config.ProjectID, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+
+	topic, err := ensureTopic(ctx, client, config.TopicID, config.CreateIfMissing)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	topic.EnableMessageOrdering = config.EnableOrdering
+
+	return &service{
+		client:        client,
+		topic:         topic,
+		config:        config,
+		subscriptions: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// Publish publishes an event and waits for the server to accept it
+func (s *service) Publish(ctx context.Context, event events.Event) error {
+	result, err := s.publish(ctx, event)
+	if err != nil {
+		return err
+	}
+	return s.await(ctx, result, event)
+}
+
+// PublishBatch publishes every event before waiting, letting the client batch them
+func (s *service) PublishBatch(ctx context.Context, eventList []events.Event) error {
+	results := make([]*gpubsub.PublishResult, len(eventList))
+	for i, event := range eventList {
+		result, err := s.publish(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+		results[i] = result
+	}
+
+	for i, result := range results {
+		if err := s.await(ctx, result, eventList[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe receives from the subscription and dispatches the handler's event
+// types to it. Failed messages are nacked and redelivered, moving to the
+// dead-letter topic after MaxDeliveryAttempts.
+func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+	if s.config.SubscriptionID == "" {
+		return fmt.Errorf("%w: Pub/Sub subscription is required", events.ErrSubscriptionFailed)
+	}
+
+	subscription, err := s.ensureSubscription(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Receiving outlives the caller's request
+	receiveCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	s.subscriptions[uuid.New().String()] = cancel
+	s.mu.Unlock()
+
+	types := handledTypes(handler)
+	go func() {
+		err := subscription.Receive(receiveCtx, func(ctx context.Context, message *gpubsub.Message) {
+			if s.deliver(ctx, message.Data, types, handler) {
+				message.Ack()
+			} else {
+				message.Nack()
+			}
+		})
+		if err != nil {
+			log.Printf("Stopped receiving events from %s: %v", s.config.SubscriptionID, err)
+		}
+	}()
+	return nil
+}
+
+// Unsubscribe stops receiving for a subscription
+func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, exists := s.subscriptions[subscriptionID]
+	if !exists {
+		return fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+	cancel()
+	delete(s.subscriptions, subscriptionID)
+	return nil
+}
+
+// GetEvents is not supported; Pub/Sub does not retain events for querying
+func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	return nil, events.ErrQueryNotSupported
+}
+
+// GetEventsByAggregate is not supported; Pub/Sub does not retain events for querying
+func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	return nil, events.ErrQueryNotSupported
+}
+
+// ReplayEvents is not supported; Pub/Sub does not retain events for querying
+func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	return events.ErrQueryNotSupported
+}
+
+// Helper methods
+
+func (s *service) publish(ctx context.Context, event events.Event) (*gpubsub.PublishResult, error) {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if !event.IsValid() {
+		return nil, events.ErrInvalidEvent
+	}
+
+	data, err := s.config.Codec.Encode(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	message := &gpubsub.Message{
+		Data:       data,
+		Attributes: event.Attributes(),
+	}
+	if s.config.EnableOrdering {
+		message.OrderingKey = event.AggregateID
+	}
+	return s.topic.Publish(ctx, message), nil
+}
+
+// await waits for a publish; after a failure the ordering key is resumed so
+// later events of the aggregate are not rejected forever
+func (s *service) await(ctx context.Context, result *gpubsub.PublishResult, event events.Event) error {
+	if _, err := result.Get(ctx); err != nil {
+		if s.config.EnableOrdering {
+			s.topic.ResumePublish(event.AggregateID)
+		}
+		return fmt.Errorf("%w: %v", events.ErrPublishFailed, err)
+	}
+	return nil
+}
+
+// deliver hands a message to the handler and reports whether it may be acked.
+// Undecodable messages are nacked so they end up in the dead-letter topic.
+func (s *service) deliver(ctx context.Context, data []byte, types map[string]bool, handler eventhandler.Service) bool {
+	event, err := s.config.Codec.Decode(data)
+	if err != nil {
+		log.Printf("Failed to decode event message: %v", err)
+		return false
+	}
+	if !types[event.Type] {
+		return true
+	}
+
+	if err := handler.Handle(ctx, event); err != nil {
+		log.Printf("Error handling event %s: %v", event.ID, err)
+		return false
+	}
+	return true
+}
+
+// ensureSubscription returns the subscription, creating it with ordering and
+// dead-lettering when configured to
+func (s *service) ensureSubscription(ctx context.Context) (*gpubsub.Subscription, error) {
+	subscription := s.client.Subscription(s.config.SubscriptionID)
+	if !s.config.CreateIfMissing {
+		return subscription, nil
+	}
+
+	exists, err := subscription.Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", events.ErrSubscriptionFailed, err)
+	}
+	if exists {
+		return subscription, nil
+	}
+
+	config := gpubsub.SubscriptionConfig{
+		Topic:                 s.topic,
+		EnableMessageOrdering: s.config.EnableOrdering,
+	}
+	if s.config.DeadLetterTopicID != "" {
+		deadLetter, err := ensureTopic(ctx, s.client, s.config.DeadLetterTopicID, true)
+		if err != nil {
+			return nil, err
+		}
+		config.DeadLetterPolicy = &gpubsub.DeadLetterPolicy{
+			DeadLetterTopic:     deadLetter.String(),
+			MaxDeliveryAttempts: s.config.MaxDeliveryAttempts,
+		}
+	}
+
+	subscription, err = s.client.CreateSubscription(ctx, s.config.SubscriptionID, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", events.ErrSubscriptionFailed, err)
+	}
+	return subscription, nil
+}
+
+// ensureTopic returns the topic, creating it when allowed and missing
+func ensureTopic(ctx context.Context, client *gpubsub.Client, topicID string, create bool) (*gpubsub.Topic, error) {
+	topic := client.Topic(topicID)
+	if !create {
+		return topic, nil
+	}
+
+	exists, err := topic.Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check Pub/Sub topic %s: %w", topicID, err)
+	}
+	if exists {
+		return topic, nil
+	}
+
+	topic, err = client.CreateTopic(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub topic %s: %w", topicID, err)
+	}
+	return topic, nil
+}
+
+// handledTypes returns the set of event types the handler accepts
+func handledTypes(handler eventhandler.Service) map[string]bool {
+	types := make(map[string]bool)
+	for _, eventType := range handler.GetHandledEventTypes() {
+		types[eventType] = true
+	}
+	return types
+}
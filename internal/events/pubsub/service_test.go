@@ -0,0 +1,113 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsPubSub "github.com/gentra/decorator-arch-go/internal/events/pubsub"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recordingHandler collects handled events, failing the first attempts when asked to
+type recordingHandler struct {
+	received chan events.Event
+	failures atomic.Int32
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	if h.failures.Add(-1) >= 0 {
+		return errors.New("temporary failure")
+	}
+	h.received <- event.(events.Event)
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered, events.EventTypeUserUpdated}
+}
+
+// ignoreDeadlineExtensions drops ack deadline extensions. pstest applies the
+// client's receipt extension even when it arrives after a nack, which would
+// hold the nacked message until the extended deadline expires.
+type ignoreDeadlineExtensions struct{}
+
+func (ignoreDeadlineExtensions) React(req interface{}) (bool, interface{}, error) {
+	modify, ok := req.(*pubsubpb.ModifyAckDeadlineRequest)
+	return ok && modify.AckDeadlineSeconds > 0, &emptypb.Empty{}, nil
+}
+
+func newTestService(t *testing.T, ordering bool) events.Service {
+	t.Helper()
+	server := pstest.NewServer(pstest.ServerReactorOption{
+		FuncName: "ModifyAckDeadline",
+		Reactor:  ignoreDeadlineExtensions{},
+	})
+	t.Cleanup(func() { _ = server.Close() })
+
+	service, err := eventsPubSub.NewService(context.Background(), eventsPubSub.Config{
+		ProjectID:         "test-project",
+		TopicID:           "user-events",
+		SubscriptionID:    "user-events-realtime",
+		EnableOrdering:    ordering,
+		DeadLetterTopicID: "user-events-dead-letter",
+		EmulatorHost:      server.Addr,
+		CreateIfMissing:   true,
+	})
+	require.NoError(t, err)
+	return service
+}
+
+func receive(t *testing.T, handler *recordingHandler) events.Event {
+	t.Helper()
+	select {
+	case event := <-handler.received:
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatal("event was not delivered")
+		return events.Event{}
+	}
+}
+
+func TestPubSubEvents_GivenOrderedAggregate_WhenPublishingBatch_ThenDeliversInOrder(t *testing.T) {
+	service := newTestService(t, true)
+	handler := &recordingHandler{received: make(chan events.Event, 2)}
+	require.NoError(t, service.Subscribe(context.Background(), nil, handler))
+
+	first := testkit.NewEventBuilder().Build()
+	second := testkit.NewEventBuilder().WithType(events.EventTypeUserUpdated).WithVersion(2).Build()
+	second.ID = "00000000-0000-4000-8000-0000000000e2"
+	require.NoError(t, service.PublishBatch(context.Background(), []events.Event{*first, *second}))
+
+	assert.Equal(t, first.ID, receive(t, handler).ID)
+	assert.Equal(t, second.ID, receive(t, handler).ID)
+}
+
+func TestPubSubEvents_GivenHandlerFailure_WhenReceiving_ThenRedelivers(t *testing.T) {
+	service := newTestService(t, false)
+	handler := &recordingHandler{received: make(chan events.Event, 1)}
+	handler.failures.Store(1)
+	require.NoError(t, service.Subscribe(context.Background(), nil, handler))
+
+	event := testkit.NewEventBuilder().Build()
+	require.NoError(t, service.Publish(context.Background(), *event))
+
+	assert.Equal(t, event.ID, receive(t, handler).ID)
+}
+
+func TestPubSubEvents_GivenReplay_WhenCalled_ThenReturnsNotSupported(t *testing.T) {
+	service := newTestService(t, false)
+
+	err := service.ReplayEvents(context.Background(), "user-1", 0, &recordingHandler{})
+
+	assert.ErrorIs(t, err, events.ErrQueryNotSupported)
+}
@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
+)
+
+// service implements events.Service by holding events to their payload
+// contracts. Published events are stamped with the latest schema version of
+// their type when they carry none and rejected when their data does not
+// match it; events read back, replayed or delivered to subscribers are
+// upcast to the latest version first, so handlers only see current payloads.
+type service struct {
+	next    events.Service
+	schemas eventschema.Service
+}
+
+// NewService creates an events decorator validating and upcasting payloads
+// with the schema registry
+func NewService(next events.Service, schemas eventschema.Service) events.Service {
+	return &service{
+		next:    next,
+		schemas: schemas,
+	}
+}
+
+// Publish validates the event before publishing it
+func (s *service) Publish(ctx context.Context, event events.Event) error {
+	event, err := s.validate(ctx, event)
+	if err != nil {
+		return err
+	}
+	return s.next.Publish(ctx, event)
+}
+
+// PublishBatch validates every event, publishing none unless all match
+func (s *service) PublishBatch(ctx context.Context, evts []events.Event) error {
+	validated := make([]events.Event, len(evts))
+	for i, event := range evts {
+		var err error
+		if validated[i], err = s.validate(ctx, event); err != nil {
+			return err
+		}
+	}
+	return s.next.PublishBatch(ctx, validated)
+}
+
+// Subscribe delivers events upcast to the handler
+func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	return s.next.Subscribe(ctx, topics, &upcastingHandler{next: handler, schemas: s.schemas})
+}
+
+// Unsubscribe passes through
+func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return s.next.Unsubscribe(ctx, subscriptionID)
+}
+
+// GetEvents returns the events upcast
+func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	evts, err := s.next.GetEvents(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return s.upcast(ctx, evts)
+}
+
+// GetEventsByAggregate returns the events upcast
+func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	evts, err := s.next.GetEventsByAggregate(ctx, aggregateID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.upcast(ctx, evts)
+}
+
+// ReplayEvents replays the events upcast to the handler
+func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	return s.next.ReplayEvents(ctx, aggregateID, fromVersion, &upcastingHandler{next: handler, schemas: s.schemas})
+}
+
+// Close drains the decorated bus when it has to be
+func (s *service) Close(ctx context.Context) error {
+	if bus, ok := s.next.(interface{ Close(context.Context) error }); ok {
+		return bus.Close(ctx)
+	}
+	return nil
+}
+
+// validate stamps the event with the latest schema version of its type when
+// it has none and checks its data against that version
+func (s *service) validate(ctx context.Context, event events.Event) (events.Event, error) {
+	if event.SchemaVersion == 0 {
+		event.SchemaVersion = s.schemas.Latest(event.Type)
+	}
+	return event, s.schemas.Validate(ctx, event)
+}
+
+// upcast migrates events to the latest version of their type
+func (s *service) upcast(ctx context.Context, evts []events.Event) ([]events.Event, error) {
+	for i, event := range evts {
+		upcast, err := s.schemas.Upcast(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		evts[i] = upcast
+	}
+	return evts, nil
+}
+
+// upcastingHandler hands events to the handler at their latest version
+type upcastingHandler struct {
+	next    eventhandler.Service
+	schemas eventschema.Service
+}
+
+// Handle upcasts the event, leaving anything but events.Event as is
+func (h *upcastingHandler) Handle(ctx context.Context, event interface{}) error {
+	if e, ok := event.(events.Event); ok {
+		upcast, err := h.schemas.Upcast(ctx, e)
+		if err != nil {
+			return err
+		}
+		event = upcast
+	}
+	return h.next.Handle(ctx, event)
+}
+
+// GetHandledEventTypes returns the handler's event types
+func (h *upcastingHandler) GetHandledEventTypes() []string {
+	return h.next.GetHandledEventTypes()
+}
@@ -0,0 +1,129 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	eventsSchema "github.com/gentra/decorator-arch-go/internal/events/schema"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
+	"github.com/gentra/decorator-arch-go/internal/eventschema/factory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// recordingHandler collects the events it is handed
+type recordingHandler struct {
+	handled []events.Event
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	h.handled = append(h.handled, event.(events.Event))
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered}
+}
+
+// withEmailDomain is a user.registered v2 adding the email's domain
+func withEmailDomain() eventschema.Schema {
+	return eventschema.Schema{
+		EventType: events.EventTypeUserRegistered,
+		Version:   2,
+		Payload:   func() interface{} { return &map[string]interface{}{} },
+		Upcast: func(data map[string]interface{}) (map[string]interface{}, error) {
+			data["email_domain"] = "example.com"
+			return data, nil
+		},
+	}
+}
+
+func newBus(t *testing.T, schemas ...eventschema.Schema) (*eventsMemory.Service, events.Service) {
+	t.Helper()
+	registry, err := factory.NewFactory(factory.Config{IncludeBuiltin: true, Schemas: schemas}).Build()
+	require.NoError(t, err)
+	bus := eventsMemory.NewService(events.DefaultEventConfig())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = bus.Close(ctx)
+	})
+	return bus, eventsSchema.NewService(bus, registry)
+}
+
+func TestPublish_GivenEventData_WhenPublishing_ThenOnlyPublishesMatchingEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string]interface{}
+		expectedError error
+	}{
+		{
+			name:          "Given data missing the email, When publishing, Then rejects the event",
+			data:          map[string]interface{}{"user_id": "user-1"},
+			expectedError: events.ErrSchemaViolation,
+		},
+		{
+			name: "Given data matching the contract, When publishing, Then stamps its version and publishes it",
+			data: map[string]interface{}{"user_id": "user-1", "email": "jane@example.com", "registered_at": time.Now()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			bus, service := newBus(t)
+			event := *testkit.NewEventBuilder().Build()
+			event.Data = tt.data
+
+			// Act
+			err := service.Publish(ctx, event)
+
+			// Assert
+			published, getErr := bus.GetEventsByAggregate(ctx, event.AggregateID, 0)
+			require.NoError(t, getErr)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, published)
+				return
+			}
+			require.NoError(t, err)
+			if assert.Len(t, published, 1) {
+				assert.Equal(t, 1, published[0].SchemaVersion)
+			}
+		})
+	}
+}
+
+func TestReplayEvents_GivenStoredOldVersion_WhenReplaying_ThenHandlerGetsLatestVersion(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus, service := newBus(t, withEmailDomain())
+	old := *testkit.NewEventBuilder().
+		WithData("user_id", "user-1").
+		WithData("email", "jane@example.com").
+		Build()
+	old.SchemaVersion = 1
+	require.NoError(t, bus.Publish(ctx, old))
+	handler := &recordingHandler{}
+
+	// Act
+	err := service.ReplayEvents(ctx, old.AggregateID, 0, handler)
+
+	// Assert
+	require.NoError(t, err)
+	if assert.Len(t, handler.handled, 1) {
+		assert.Equal(t, 2, handler.handled[0].SchemaVersion)
+		assert.Equal(t, "example.com", handler.handled[0].Data["email_domain"])
+	}
+	queried, err := service.GetEventsByAggregate(ctx, old.AggregateID, 0)
+	require.NoError(t, err)
+	if assert.Len(t, queried, 1) {
+		assert.Equal(t, 2, queried[0].SchemaVersion)
+	}
+}
@@ -0,0 +1,462 @@
+package sns
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/plainjson"
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// contentEncodingAttribute marks messages whose body is base64, as SNS
+// messages are text and binary codecs, such as protobuf or compression,
+// write bytes
+const contentEncodingAttribute = "content_encoding"
+
+// maxBatchSize is the largest PublishBatch request SNS accepts
+const maxBatchSize = 10
+
+// Config contains the SNS topic events are published to and the SQS queue
+// subscribed to it that this service consumes
+type Config struct {
+	// Region and Profile select the AWS configuration; when empty the default
+	// chain is used (environment, shared config, IAM roles for tasks and pods)
+	Region  string
+	Profile string
+
+	// Endpoint overrides the AWS endpoints, e.g. http://localhost:4566 for
+	// LocalStack; emulators get placeholder credentials
+	Endpoint string
+
+	// TopicARN receives every published event. For ".fifo" topics events are
+	// ordered per aggregate and deduplicated by event ID.
+	TopicARN string
+
+	// QueueURL is the queue Subscribe polls; it must be subscribed to TopicARN
+	QueueURL string
+
+	// DeadLetterQueueARN, when set, receives messages that failed
+	// MaxReceiveCount deliveries; Subscribe wires the queue's redrive policy
+	DeadLetterQueueARN string
+	MaxReceiveCount    int
+
+	// Long polling settings for Subscribe
+	WaitTime    time.Duration
+	MaxMessages int32
+	RetryDelay  time.Duration // Pause after a failed receive
+
+	// Codec encodes the message bodies; nil publishes plain JSON events
+	Codec eventcodec.Service
+}
+
+// DefaultConfig returns long polling defaults without any AWS resources
+func DefaultConfig() Config {
+	return Config{
+		MaxReceiveCount: 5,
+		WaitTime:        20 * time.Second,
+		MaxMessages:     10,
+		RetryDelay:      5 * time.Second,
+	}
+}
+
+// service implements events.Service with SNS fan-out and SQS consumption.
+// SNS is a bus, not a store, so querying and replay are not supported.
+type service struct {
+	sns    *awssns.Client
+	sqs    *sqs.Client
+	config Config
+
+	mu            sync.Mutex
+	subscriptions map[string]context.CancelFunc
+}
+
+// NewService creates a new SNS/SQS events service
+func NewService(ctx context.Context, config Config) (events.Service, error) {
+	if config.TopicARN == "" {
+		return nil, fmt.Errorf("SNS topic ARN is required")
+	}
+	config = withDefaults(config)
+
+	options := []func(*awsconfig.LoadOptions) error{}
+	if config.Region != "" {
+		options = append(options, awsconfig.WithRegion(config.Region))
+	}
+	if config.Profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(config.Profile))
+	}
+	if config.Endpoint != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider("local", "local", ""),
+		))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	return &service{
+		sns: awssns.NewFromConfig(awsConfig, func(o *awssns.Options) {
+			if config.Endpoint != "" {
+				o.BaseEndpoint = aws.String(config.Endpoint)
+			}
+		}),
+		sqs: sqs.NewFromConfig(awsConfig, func(o *sqs.Options) {
+			if config.Endpoint != "" {
+				o.BaseEndpoint = aws.String(config.Endpoint)
+			}
+		}),
+		config:        config,
+		subscriptions: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// Publish publishes an event to the SNS topic
+func (s *service) Publish(ctx context.Context, event events.Event) error {
+	if err := prepare(&event); err != nil {
+		return err
+	}
+
+	body, err := s.config.Codec.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	message, attributes := encodeMessage(body, messageAttributes(&event))
+	input := &awssns.PublishInput{
+		TopicArn:          aws.String(s.config.TopicARN),
+		Message:           aws.String(message),
+		MessageAttributes: attributes,
+	}
+	if s.isFIFO() {
+		input.MessageGroupId = aws.String(event.AggregateID)
+		input.MessageDeduplicationId = aws.String(event.ID)
+	}
+
+	if _, err := s.sns.Publish(ctx, input); err != nil {
+		return fmt.Errorf("%w: %v", events.ErrPublishFailed, err)
+	}
+	return nil
+}
+
+// PublishBatch publishes events in batches of up to ten
+func (s *service) PublishBatch(ctx context.Context, eventList []events.Event) error {
+	for start := 0; start < len(eventList); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(eventList))
+		if err := s.publishBatch(ctx, eventList[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe polls the SQS queue and dispatches the handler's event types to it.
+// Messages are deleted once handled; failed messages are redelivered and move
+// to the dead-letter queue after MaxReceiveCount attempts.
+func (s *service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+	if s.config.QueueURL == "" {
+		return fmt.Errorf("%w: SQS queue URL is required", events.ErrSubscriptionFailed)
+	}
+
+	if s.config.DeadLetterQueueARN != "" {
+		if err := s.wireDeadLetterQueue(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Polling outlives the caller's request
+	pollCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	s.subscriptions[uuid.New().String()] = cancel
+	s.mu.Unlock()
+
+	go s.poll(pollCtx, handledTypes(handler), handler)
+	return nil
+}
+
+// Unsubscribe stops polling for a subscription
+func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, exists := s.subscriptions[subscriptionID]
+	if !exists {
+		return fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+	cancel()
+	delete(s.subscriptions, subscriptionID)
+	return nil
+}
+
+// GetEvents is not supported; SNS does not retain events
+func (s *service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	return nil, events.ErrQueryNotSupported
+}
+
+// GetEventsByAggregate is not supported; SNS does not retain events
+func (s *service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	return nil, events.ErrQueryNotSupported
+}
+
+// ReplayEvents is not supported; SNS does not retain events
+func (s *service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	return events.ErrQueryNotSupported
+}
+
+// Helper methods
+
+func (s *service) publishBatch(ctx context.Context, batch []events.Event) error {
+	entries := make([]snstypes.PublishBatchRequestEntry, 0, len(batch))
+	for i := range batch {
+		event := batch[i]
+		if err := prepare(&event); err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+
+		body, err := s.config.Codec.Encode(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+		}
+
+		message, attributes := encodeMessage(body, messageAttributes(&event))
+		entry := snstypes.PublishBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(i)),
+			Message:           aws.String(message),
+			MessageAttributes: attributes,
+		}
+		if s.isFIFO() {
+			entry.MessageGroupId = aws.String(event.AggregateID)
+			entry.MessageDeduplicationId = aws.String(event.ID)
+		}
+		entries = append(entries, entry)
+	}
+
+	output, err := s.sns.PublishBatch(ctx, &awssns.PublishBatchInput{
+		TopicArn:                   aws.String(s.config.TopicARN),
+		PublishBatchRequestEntries: entries,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", events.ErrPublishFailed, err)
+	}
+	if len(output.Failed) > 0 {
+		failed := output.Failed[0]
+		return fmt.Errorf("%w: %d of %d events rejected: %s", events.ErrPublishFailed,
+			len(output.Failed), len(entries), aws.ToString(failed.Message))
+	}
+	return nil
+}
+
+// poll receives messages until the subscription is canceled
+func (s *service) poll(ctx context.Context, types map[string]bool, handler eventhandler.Service) {
+	for ctx.Err() == nil {
+		output, err := s.sqs.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(s.config.QueueURL),
+			MaxNumberOfMessages:   s.config.MaxMessages,
+			WaitTimeSeconds:       int32(s.config.WaitTime / time.Second),
+			MessageAttributeNames: []string{contentEncodingAttribute},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to receive events from %s: %v", s.config.QueueURL, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(s.config.RetryDelay):
+			}
+			continue
+		}
+
+		for _, message := range output.Messages {
+			var encoding string
+			if attribute, ok := message.MessageAttributes[contentEncodingAttribute]; ok {
+				encoding = aws.ToString(attribute.StringValue)
+			}
+			if !s.deliver(ctx, aws.ToString(message.Body), encoding, types, handler) {
+				continue
+			}
+			if _, err := s.sqs.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(s.config.QueueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			}); err != nil {
+				log.Printf("Failed to delete message %s: %v", aws.ToString(message.MessageId), err)
+			}
+		}
+	}
+}
+
+// deliver hands a message to the handler and reports whether it may be deleted.
+// Undecodable messages are kept so they end up in the dead-letter queue.
+func (s *service) deliver(ctx context.Context, body, encoding string, types map[string]bool, handler eventhandler.Service) bool {
+	event, err := decode(s.config.Codec, body, encoding)
+	if err != nil {
+		log.Printf("Failed to decode event message: %v", err)
+		return false
+	}
+	if !types[event.Type] {
+		return true
+	}
+
+	if err := handler.Handle(ctx, event); err != nil {
+		log.Printf("Error handling event %s: %v", event.ID, err)
+		return false
+	}
+	return true
+}
+
+// wireDeadLetterQueue sets the queue's redrive policy to the dead-letter queue
+func (s *service) wireDeadLetterQueue(ctx context.Context) error {
+	policy, err := json.Marshal(map[string]string{
+		"deadLetterTargetArn": s.config.DeadLetterQueueARN,
+		"maxReceiveCount":     strconv.Itoa(s.config.MaxReceiveCount),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sqs.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(s.config.QueueURL),
+		Attributes: map[string]string{"RedrivePolicy": string(policy)},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: failed to configure dead-letter queue: %v", events.ErrSubscriptionFailed, err)
+	}
+	return nil
+}
+
+func (s *service) isFIFO() bool {
+	return strings.HasSuffix(s.config.TopicARN, ".fifo")
+}
+
+// withDefaults fills unset polling settings
+func withDefaults(config Config) Config {
+	defaults := DefaultConfig()
+	if config.MaxReceiveCount <= 0 {
+		config.MaxReceiveCount = defaults.MaxReceiveCount
+	}
+	if config.WaitTime <= 0 {
+		config.WaitTime = defaults.WaitTime
+	}
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = defaults.MaxMessages
+	}
+	if config.Codec == nil {
+		config.Codec = plainjson.NewService()
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	return config
+}
+
+// prepare fills the ID and timestamp and validates the event
+func prepare(event *events.Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if !event.IsValid() {
+		return events.ErrInvalidEvent
+	}
+	return nil
+}
+
+// messageAttributes exposes the event's routing attributes for SNS filter policies
+func messageAttributes(event *events.Event) map[string]snstypes.MessageAttributeValue {
+	attributes := make(map[string]snstypes.MessageAttributeValue)
+	for name, value := range event.Attributes() {
+		if value == "" {
+			continue
+		}
+		attributes[name] = snstypes.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	return attributes
+}
+
+// decode reads an event from an SQS body, unwrapping the SNS notification
+// envelope unless the subscription uses raw message delivery, where the
+// content encoding comes from the SQS message attributes instead
+func decode(codec eventcodec.Service, body, encoding string) (events.Event, error) {
+	var envelope struct {
+		Type              string `json:"Type"`
+		Message           string `json:"Message"`
+		MessageAttributes map[string]struct {
+			Value string `json:"Value"`
+		} `json:"MessageAttributes"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err == nil && envelope.Type == "Notification" {
+		body = envelope.Message
+		encoding = envelope.MessageAttributes[contentEncodingAttribute].Value
+	}
+
+	if encoding != "base64" {
+		return codec.Decode([]byte(body))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return events.Event{}, fmt.Errorf("%w: %v", eventcodec.ErrDecodeFailed, err)
+	}
+	return codec.Decode(decoded)
+}
+
+// encodeMessage returns the body as SNS message text, base64-encoding
+// bodies SNS and SQS would reject and marking them in the attributes
+func encodeMessage(body []byte, attributes map[string]snstypes.MessageAttributeValue) (string, map[string]snstypes.MessageAttributeValue) {
+	if isText(body) {
+		return string(body), attributes
+	}
+	attributes[contentEncodingAttribute] = snstypes.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String("base64"),
+	}
+	return base64.StdEncoding.EncodeToString(body), attributes
+}
+
+// isText reports whether the body is UTF-8 without the control characters
+// SQS rejects; JSON bodies always are
+func isText(body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	for _, b := range body {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// handledTypes returns the set of event types the handler accepts
+func handledTypes(handler eventhandler.Service) map[string]bool {
+	types := make(map[string]bool)
+	for _, eventType := range handler.GetHandledEventTypes() {
+		types[eventType] = true
+	}
+	return types
+}
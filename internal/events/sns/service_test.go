@@ -0,0 +1,212 @@
+package sns_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventcodec"
+	"github.com/gentra/decorator-arch-go/internal/eventcodec/compression"
+	eventCodecFactory "github.com/gentra/decorator-arch-go/internal/eventcodec/factory"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsSNS "github.com/gentra/decorator-arch-go/internal/events/sns"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// fakeAWS emulates an SNS topic fanned out to a single SQS queue, the way
+// LocalStack does, speaking the SNS query and SQS JSON protocols
+type fakeAWS struct {
+	mu         sync.Mutex
+	published  []http.Header
+	attributes []map[string]string
+	queue      []string
+	deleted    int
+	redrive    string
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch target := r.Header.Get("X-Amz-Target"); target {
+	case "":
+		f.serveSNS(w, r)
+	case "AmazonSQS.ReceiveMessage":
+		messages := []map[string]string{}
+		for i, body := range f.queue {
+			sum := md5.Sum([]byte(body))
+			messages = append(messages, map[string]string{
+				"MessageId":     fmt.Sprintf("m-%d", i),
+				"ReceiptHandle": fmt.Sprintf("r-%d", i),
+				"Body":          body,
+				"MD5OfBody":     hex.EncodeToString(sum[:]),
+			})
+		}
+		f.queue = nil
+		writeAWSJSON(w, map[string]interface{}{"Messages": messages})
+	case "AmazonSQS.DeleteMessage":
+		f.deleted++
+		writeAWSJSON(w, map[string]interface{}{})
+	case "AmazonSQS.SetQueueAttributes":
+		var input struct {
+			Attributes map[string]string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		f.redrive = input.Attributes["RedrivePolicy"]
+		writeAWSJSON(w, map[string]interface{}{})
+	default:
+		http.Error(w, "unsupported target "+target, http.StatusBadRequest)
+	}
+}
+
+func (f *fakeAWS) serveSNS(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	if r.Form.Get("Action") != "Publish" {
+		http.Error(w, "unsupported action", http.StatusBadRequest)
+		return
+	}
+
+	attributes := map[string]string{}
+	for i := 1; r.Form.Get(fmt.Sprintf("MessageAttributes.entry.%d.Name", i)) != ""; i++ {
+		attributes[r.Form.Get(fmt.Sprintf("MessageAttributes.entry.%d.Name", i))] =
+			r.Form.Get(fmt.Sprintf("MessageAttributes.entry.%d.Value.StringValue", i))
+	}
+	f.attributes = append(f.attributes, attributes)
+
+	messageAttributes := map[string]interface{}{}
+	for name, value := range attributes {
+		messageAttributes[name] = map[string]string{"Type": "String", "Value": value}
+	}
+	envelope, _ := json.Marshal(map[string]interface{}{
+		"Type":              "Notification",
+		"Message":           r.Form.Get("Message"),
+		"MessageAttributes": messageAttributes,
+	})
+	f.queue = append(f.queue, string(envelope))
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprint(w, `<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>1</MessageId></PublishResult><ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></PublishResponse>`)
+}
+
+func writeAWSJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// recordingHandler collects the events it handles
+type recordingHandler struct {
+	received chan events.Event
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	h.received <- event.(events.Event)
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return []string{events.EventTypeUserRegistered}
+}
+
+func newTestService(t *testing.T, fake *fakeAWS) events.Service {
+	t.Helper()
+	return newTestServiceWithCodec(t, fake, nil)
+}
+
+func newTestServiceWithCodec(t *testing.T, fake *fakeAWS, codec eventcodec.Service) events.Service {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config := eventsSNS.DefaultConfig()
+	config.Region = "us-east-1"
+	config.Endpoint = server.URL
+	config.TopicARN = "arn:aws:sns:us-east-1:000000000000:user-events"
+	config.QueueURL = server.URL + "/000000000000/user-events"
+	config.DeadLetterQueueARN = "arn:aws:sqs:us-east-1:000000000000:user-events-dlq"
+	config.WaitTime = time.Second
+	config.Codec = codec
+
+	service, err := eventsSNS.NewService(context.Background(), config)
+	require.NoError(t, err)
+	return service
+}
+
+func TestSNSEvents_GivenSubscription_WhenPublishing_ThenDeliversThroughQueue(t *testing.T) {
+	fake := &fakeAWS{}
+	service := newTestService(t, fake)
+	handler := &recordingHandler{received: make(chan events.Event, 1)}
+
+	require.NoError(t, service.Subscribe(context.Background(), nil, handler))
+	event := testkit.NewEventBuilder().WithCorrelationID("corr-1", "").Build()
+	require.NoError(t, service.Publish(context.Background(), *event))
+
+	select {
+	case received := <-handler.received:
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, event.AggregateID, received.AggregateID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, events.EventTypeUserRegistered, fake.attributes[0]["event_type"])
+	assert.Equal(t, "corr-1", fake.attributes[0]["correlation_id"])
+	assert.JSONEq(t, `{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:000000000000:user-events-dlq","maxReceiveCount":"5"}`, fake.redrive)
+}
+
+func TestSNSEvents_GivenBinaryCodec_WhenPublishing_ThenDeliversBase64Body(t *testing.T) {
+	// Arrange
+	codec, err := eventCodecFactory.NewFactory(eventCodecFactory.Config{
+		Serialization: eventcodec.SerializationProtobuf,
+		Compress:      true,
+		Compression:   compression.Config{Algorithm: compression.AlgorithmZstd, Threshold: 1},
+	}).Build()
+	require.NoError(t, err)
+	fake := &fakeAWS{}
+	service := newTestServiceWithCodec(t, fake, codec)
+	handler := &recordingHandler{received: make(chan events.Event, 1)}
+	require.NoError(t, service.Subscribe(context.Background(), nil, handler))
+	event := testkit.NewEventBuilder().WithData("email", "jane@example.com").Build()
+
+	// Act
+	require.NoError(t, service.Publish(context.Background(), *event))
+
+	// Assert
+	select {
+	case received := <-handler.received:
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, "jane@example.com", received.Data["email"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, "base64", fake.attributes[0]["content_encoding"])
+}
+
+func TestSNSEvents_GivenInvalidEvent_WhenPublishing_ThenReturnsInvalidEvent(t *testing.T) {
+	service := newTestService(t, &fakeAWS{})
+
+	err := service.Publish(context.Background(), events.Event{Type: events.EventTypeUserRegistered})
+
+	assert.ErrorIs(t, err, events.ErrInvalidEvent)
+}
+
+func TestSNSEvents_GivenQuery_WhenCalled_ThenReturnsNotSupported(t *testing.T) {
+	service := newTestService(t, &fakeAWS{})
+
+	_, err := service.GetEventsByAggregate(context.Background(), "user-1", 10)
+
+	assert.ErrorIs(t, err, events.ErrQueryNotSupported)
+}
@@ -0,0 +1,465 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/pgtx"
+)
+
+// AnyVersion appends events whatever version their aggregate is at
+const AnyVersion = -1
+
+// replayPageSize is how many events Replay reads per query
+const replayPageSize = 500
+
+// uniqueViolation is the SQLSTATE Postgres reports for a duplicate key
+const uniqueViolation = "23505"
+
+// versionConstraint keeps one event per aggregate version
+const versionConstraint = "event_store_aggregate_version_key"
+
+// eventColumns are the event_store columns read by scanEvent, in scan order
+const eventColumns = `event_id, event_type, aggregate_type, aggregate_id, version, schema_version, data, metadata, occurred_at`
+
+// Snapshot is the state of an aggregate as of a version, so loading it only
+// needs the events after that version
+type Snapshot struct {
+	AggregateID   string                 `json:"aggregate_id"`
+	AggregateType string                 `json:"aggregate_type"`
+	Version       int                    `json:"version"`
+	State         map[string]interface{} `json:"state"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// Service implements events.Service as a Postgres event store for event
+// sourcing. Every event is kept in the event_store table under its
+// aggregate and version; appends naming a version the aggregate has moved
+// past fail with events.ErrVersionConflict. Writes join the transaction
+// carried by the context (see pgtx). The store delivers nothing to
+// subscribers; Replay feeds stored events to a handler instead.
+type Service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres event store
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{pool: pool}
+}
+
+// Append stores events of one aggregate after expectedVersion, numbering
+// them from expectedVersion+1, and returns the aggregate's new version. It
+// fails with events.ErrVersionConflict when the aggregate is at another
+// version, or an event carries a version other than the one it would get;
+// AnyVersion appends after the current version. Either every event is
+// stored or none.
+func (s *Service) Append(ctx context.Context, aggregateID string, expectedVersion int, evts ...events.Event) (int, error) {
+	if aggregateID == "" {
+		return 0, fmt.Errorf("%w: aggregate ID is required", events.ErrInvalidEvent)
+	}
+
+	var version int
+	err := pgx.BeginFunc(ctx, pgtx.Use(ctx, s.pool), func(tx pgx.Tx) error {
+		// Appends to one aggregate take turns, so only a stale expectedVersion conflicts
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, aggregateID); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM event_store WHERE aggregate_id = $1`,
+			aggregateID).Scan(&version); err != nil {
+			return err
+		}
+		if expectedVersion != AnyVersion && expectedVersion != version {
+			return conflict(aggregateID, expectedVersion, version)
+		}
+
+		for _, event := range evts {
+			version++
+			prepared, err := prepare(event, aggregateID, version)
+			if err != nil {
+				return err
+			}
+			if err := insert(ctx, tx, prepared); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Publish appends the event to its aggregate. An event with a version must
+// be the next one of its aggregate; without one it gets the next version.
+func (s *Service) Publish(ctx context.Context, event events.Event) error {
+	expected := AnyVersion
+	if event.Version > 0 {
+		expected = event.Version - 1
+	}
+	_, err := s.Append(ctx, event.AggregateID, expected, event)
+	return err
+}
+
+// PublishBatch publishes the events in order in one transaction, so either
+// every event is stored or none
+func (s *Service) PublishBatch(ctx context.Context, evts []events.Event) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		for _, event := range evts {
+			if err := s.Publish(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Subscribe is not supported; use Replay to feed stored events to a handler
+func (s *Service) Subscribe(ctx context.Context, topics []string, handler eventhandler.Service) error {
+	return events.ErrSubscriptionNotSupported
+}
+
+// Unsubscribe is not supported
+func (s *Service) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return events.ErrSubscriptionNotSupported
+}
+
+// Load returns every event of an aggregate, by version
+func (s *Service) Load(ctx context.Context, aggregateID string) ([]events.Event, error) {
+	return s.loadFrom(ctx, aggregateID, 0)
+}
+
+// LoadLatest returns the latest snapshot of an aggregate, nil without one,
+// and the events after it
+func (s *Service) LoadLatest(ctx context.Context, aggregateID string) (*Snapshot, []events.Event, error) {
+	snapshot, err := s.LoadSnapshot(ctx, aggregateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := 0
+	if snapshot != nil {
+		from = snapshot.Version + 1
+	}
+	evts, err := s.loadFrom(ctx, aggregateID, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, evts, nil
+}
+
+// SaveSnapshot stores the state of an aggregate as of snapshot.Version,
+// which must have been appended; an older snapshot than the stored one is
+// ignored
+func (s *Service) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	if snapshot.AggregateID == "" || snapshot.Version <= 0 {
+		return fmt.Errorf("%w: snapshots need an aggregate ID and a version", events.ErrInvalidEvent)
+	}
+	state, err := json.Marshal(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("%w: %v", events.ErrInvalidEvent, err)
+	}
+
+	db := pgtx.Use(ctx, s.pool)
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM event_store WHERE aggregate_id = $1 AND version = $2)`,
+		snapshot.AggregateID, snapshot.Version).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: version %d of aggregate %s is not stored", events.ErrEventNotFound, snapshot.Version, snapshot.AggregateID)
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO event_snapshots (aggregate_id, aggregate_type, version, state, created_at)
+		VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (aggregate_id) DO UPDATE
+		SET aggregate_type = EXCLUDED.aggregate_type, version = EXCLUDED.version,
+			state = EXCLUDED.state, created_at = EXCLUDED.created_at
+		WHERE event_snapshots.version < EXCLUDED.version`,
+		snapshot.AggregateID, snapshot.AggregateType, snapshot.Version, state)
+	return err
+}
+
+// LoadSnapshot returns the latest snapshot of an aggregate, or nil without one
+func (s *Service) LoadSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	var snapshot Snapshot
+	var state []byte
+	err := pgtx.Use(ctx, s.pool).QueryRow(ctx, `
+		SELECT aggregate_id, aggregate_type, version, state, created_at
+		FROM event_snapshots WHERE aggregate_id = $1`, aggregateID).
+		Scan(&snapshot.AggregateID, &snapshot.AggregateType, &snapshot.Version, &state, &snapshot.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(state, &snapshot.State); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of aggregate %s: %w", aggregateID, err)
+	}
+	return &snapshot, nil
+}
+
+// GetEvents returns the events matching the filters in the order they were stored
+func (s *Service) GetEvents(ctx context.Context, filters events.EventFilters) ([]events.Event, error) {
+	conditions, args := filterConditions(filters)
+	query := `SELECT ` + eventColumns + ` FROM event_store` + where(conditions) + ` ORDER BY position`
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filters.Offset > 0 {
+		args = append(args, filters.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return s.query(ctx, query, args...)
+}
+
+// GetEventsByAggregate returns up to limit events of an aggregate, by
+// version; zero returns them all
+func (s *Service) GetEventsByAggregate(ctx context.Context, aggregateID string, limit int) ([]events.Event, error) {
+	if limit <= 0 {
+		return s.Load(ctx, aggregateID)
+	}
+	return s.query(ctx, `SELECT `+eventColumns+` FROM event_store
+		WHERE aggregate_id = $1 ORDER BY version LIMIT $2`, aggregateID, limit)
+}
+
+// ReplayEvents feeds the events of an aggregate from fromVersion on to the handler, by version
+func (s *Service) ReplayEvents(ctx context.Context, aggregateID string, fromVersion int, handler eventhandler.Service) error {
+	evts, err := s.loadFrom(ctx, aggregateID, fromVersion)
+	if err != nil {
+		return err
+	}
+	return handle(ctx, evts, handler)
+}
+
+// Replay feeds every stored event matching the filters to the handler, in
+// the order they were stored, reading them a page at a time. Events of a
+// type the handler does not declare are skipped. filters.Limit caps how
+// many events are read; filters.Offset is ignored.
+func (s *Service) Replay(ctx context.Context, filters events.EventFilters, handler eventhandler.Service) error {
+	conditions, args := filterConditions(filters)
+	conditions = append(conditions, fmt.Sprintf("position > $%d", len(args)+1))
+	query := `SELECT position, ` + eventColumns + ` FROM event_store` + where(conditions) +
+		fmt.Sprintf(" ORDER BY position LIMIT $%d", len(args)+2)
+
+	handled := make(map[string]bool)
+	for _, eventType := range handler.GetHandledEventTypes() {
+		handled[eventType] = true
+	}
+
+	var position int64
+	remaining := filters.Limit
+	for {
+		pageSize := replayPageSize
+		if filters.Limit > 0 && remaining < pageSize {
+			pageSize = remaining
+		}
+		if pageSize == 0 {
+			return nil
+		}
+
+		page, last, err := s.page(ctx, query, append(args, position, pageSize)...)
+		if err != nil {
+			return err
+		}
+		for _, event := range page {
+			if len(handled) > 0 && !handled[event.Type] {
+				continue
+			}
+			if err := handler.Handle(ctx, event); err != nil {
+				return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+		position = last
+		remaining -= len(page)
+	}
+}
+
+// loadFrom returns the events of an aggregate from a version on, by version
+func (s *Service) loadFrom(ctx context.Context, aggregateID string, fromVersion int) ([]events.Event, error) {
+	return s.query(ctx, `SELECT `+eventColumns+` FROM event_store
+		WHERE aggregate_id = $1 AND version >= $2 ORDER BY version`, aggregateID, fromVersion)
+}
+
+// query reads the events a query returns
+func (s *Service) query(ctx context.Context, query string, args ...any) ([]events.Event, error) {
+	rows, err := pgtx.Use(ctx, s.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []events.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *event)
+	}
+	return result, rows.Err()
+}
+
+// page reads a page of Replay and the position of its last event
+func (s *Service) page(ctx context.Context, query string, args ...any) ([]events.Event, int64, error) {
+	rows, err := pgtx.Use(ctx, s.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var result []events.Event
+	var position int64
+	for rows.Next() {
+		event, err := scanEvent(rows, &position)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, *event)
+	}
+	return result, position, rows.Err()
+}
+
+// prepare checks an event against the aggregate and version it is appended
+// at, and gives it an ID and timestamp when it has none
+func prepare(event events.Event, aggregateID string, version int) (events.Event, error) {
+	switch {
+	case event.AggregateID == "":
+		event.AggregateID = aggregateID
+	case event.AggregateID != aggregateID:
+		return event, fmt.Errorf("%w: event of aggregate %s appended to %s", events.ErrInvalidEvent, event.AggregateID, aggregateID)
+	}
+	if event.Version != 0 && event.Version != version {
+		return event, conflict(aggregateID, event.Version-1, version-1)
+	}
+	event.Version = version
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Type == "" {
+		return event, fmt.Errorf("%w: event type is required", events.ErrInvalidEvent)
+	}
+	return event, nil
+}
+
+// insert stores one event; a concurrent append of the same version is a conflict
+func insert(ctx context.Context, tx pgx.Tx, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", events.ErrInvalidEvent, err)
+	}
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("%w: %v", events.ErrInvalidEvent, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO event_store (event_id, event_type, aggregate_type, aggregate_id, version, schema_version, data, metadata, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		event.ID, event.Type, event.AggregateType, event.AggregateID, event.Version, event.EffectiveSchemaVersion(),
+		data, metadata, event.Timestamp)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		if pgErr.ConstraintName == versionConstraint {
+			return conflict(event.AggregateID, event.Version-1, event.Version)
+		}
+		return fmt.Errorf("%w: event %s is already stored", events.ErrInvalidEvent, event.ID)
+	}
+	return err
+}
+
+// conflict reports an append expecting another version than the aggregate's
+func conflict(aggregateID string, expected, actual int) error {
+	return fmt.Errorf("%w: aggregate %s is at version %d, not %d", events.ErrVersionConflict, aggregateID, actual, expected)
+}
+
+// handle feeds events to a handler in order
+func handle(ctx context.Context, evts []events.Event, handler eventhandler.Service) error {
+	for _, event := range evts {
+		if err := handler.Handle(ctx, event); err != nil {
+			return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// filterConditions translates filters into SQL conditions and their arguments
+func filterConditions(filters events.EventFilters) ([]string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filters.EventTypes) > 0 {
+		add("event_type = ANY($%d)", filters.EventTypes)
+	}
+	if filters.AggregateID != "" {
+		add("aggregate_id = $%d", filters.AggregateID)
+	}
+	if len(filters.AggregateTypes) > 0 {
+		add("aggregate_type = ANY($%d)", filters.AggregateTypes)
+	}
+	if filters.StartTime != nil {
+		add("occurred_at >= $%d", *filters.StartTime)
+	}
+	if filters.EndTime != nil {
+		add("occurred_at <= $%d", *filters.EndTime)
+	}
+	if filters.UserID != "" {
+		add("metadata->>'user_id' = $%d", filters.UserID)
+	}
+	if filters.CorrelationID != "" {
+		add("metadata->>'correlation_id' = $%d", filters.CorrelationID)
+	}
+	return conditions, args
+}
+
+// where joins conditions into a WHERE clause, empty without conditions
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// scanEvent reads one event, and into leading the columns selected before it
+func scanEvent(row pgx.Row, leading ...any) (*events.Event, error) {
+	var event events.Event
+	var data, metadata []byte
+	dest := append(leading, &event.ID, &event.Type, &event.AggregateType, &event.AggregateID, &event.Version,
+		&event.SchemaVersion, &data, &metadata, &event.Timestamp)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &event.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", event.ID, err)
+	}
+	if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", event.ID, err)
+	}
+	return &event, nil
+}
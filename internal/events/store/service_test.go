@@ -0,0 +1,145 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventStore "github.com/gentra/decorator-arch-go/internal/events/store"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// testDatabaseEnv names a migrated Postgres database the tests may write to
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+func newStore(t *testing.T) *eventStore.Service {
+	t.Helper()
+	databaseURL := os.Getenv(testDatabaseEnv)
+	if databaseURL == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return eventStore.NewService(pool)
+}
+
+// newEvent builds an event of a fresh aggregate unless aggregateID is given
+func newEvent(aggregateID string) events.Event {
+	event := *testkit.NewEventBuilder().ForAggregate("user", aggregateID).Build()
+	event.ID = ""
+	event.Version = 0
+	return event
+}
+
+// recordingHandler records the events replayed to it
+type recordingHandler struct {
+	mu       sync.Mutex
+	types    []string
+	received []events.Event
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, event interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.received = append(h.received, event.(events.Event))
+	return nil
+}
+
+func (h *recordingHandler) GetHandledEventTypes() []string {
+	return h.types
+}
+
+func TestAppend_GivenExpectedVersion_WhenAppending_ThenNumbersEventsAndRejectsStaleVersions(t *testing.T) {
+	// Arrange
+	store := newStore(t)
+	ctx := context.Background()
+	aggregateID := uuid.New().String()
+
+	// Act
+	version, err := store.Append(ctx, aggregateID, 0, newEvent(aggregateID), newEvent(aggregateID))
+	require.NoError(t, err)
+	_, staleErr := store.Append(ctx, aggregateID, 1, newEvent(aggregateID))
+	loaded, err := store.Load(ctx, aggregateID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.ErrorIs(t, staleErr, events.ErrVersionConflict)
+	if assert.Len(t, loaded, 2) {
+		assert.Equal(t, 1, loaded[0].Version)
+		assert.Equal(t, 2, loaded[1].Version)
+	}
+}
+
+func TestPublish_GivenEventOfTakenVersion_WhenPublishing_ThenReturnsVersionConflict(t *testing.T) {
+	// Arrange
+	store := newStore(t)
+	ctx := context.Background()
+	aggregateID := uuid.New().String()
+	first := newEvent(aggregateID)
+	first.Version = 1
+	require.NoError(t, store.Publish(ctx, first))
+
+	// Act
+	again := newEvent(aggregateID)
+	again.Version = 1
+	err := store.Publish(ctx, again)
+
+	// Assert
+	assert.ErrorIs(t, err, events.ErrVersionConflict)
+}
+
+func TestLoadLatest_GivenSnapshot_WhenLoading_ThenReturnsOnlyLaterEvents(t *testing.T) {
+	// Arrange
+	store := newStore(t)
+	ctx := context.Background()
+	aggregateID := uuid.New().String()
+	_, err := store.Append(ctx, aggregateID, eventStore.AnyVersion, newEvent(aggregateID), newEvent(aggregateID), newEvent(aggregateID))
+	require.NoError(t, err)
+	require.NoError(t, store.SaveSnapshot(ctx, eventStore.Snapshot{
+		AggregateID: aggregateID, AggregateType: "user", Version: 2, State: map[string]interface{}{"logins": float64(2)},
+	}))
+
+	// Act
+	snapshot, later, err := store.LoadLatest(ctx, aggregateID)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, 2, snapshot.Version)
+	assert.Equal(t, float64(2), snapshot.State["logins"])
+	if assert.Len(t, later, 1) {
+		assert.Equal(t, 3, later[0].Version)
+	}
+}
+
+func TestReplay_GivenFilters_WhenReplaying_ThenFeedsMatchingEventsInOrder(t *testing.T) {
+	// Arrange
+	store := newStore(t)
+	ctx := context.Background()
+	aggregateID := uuid.New().String()
+	updated := newEvent(aggregateID)
+	updated.Type = events.EventTypeUserUpdated
+	_, err := store.Append(ctx, aggregateID, 0, newEvent(aggregateID), updated, newEvent(aggregateID))
+	require.NoError(t, err)
+	handler := &recordingHandler{types: []string{events.EventTypeUserRegistered}}
+
+	// Act
+	err = store.Replay(ctx, events.EventFilters{AggregateID: aggregateID}, handler)
+
+	// Assert
+	require.NoError(t, err)
+	if assert.Len(t, handler.received, 2) {
+		assert.Equal(t, 1, handler.received[0].Version)
+		assert.Equal(t, 3, handler.received[1].Version)
+	}
+}
@@ -0,0 +1,85 @@
+package eventschema
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// Service defines the event schema domain interface - the ONLY interface in this domain.
+// It keeps the payload contract of every version of every event type,
+// checks published events against them and migrates events of older
+// versions to the latest one when they are read back.
+type Service interface {
+	// Register adds the contract of a version of an event type, or returns
+	// ErrSchemaExists when that version is already registered
+	Register(schema Schema) error
+
+	// Lookup returns the contract of a version of an event type, or
+	// ErrSchemaNotFound
+	Lookup(eventType string, version int) (*Schema, error)
+
+	// Latest returns the highest registered version of an event type, zero
+	// when the type has no contract
+	Latest(eventType string) int
+
+	// Validate checks the event's data against the contract of its schema
+	// version, returning an error wrapping events.ErrSchemaViolation when
+	// it does not match. Events of types without a contract pass.
+	Validate(ctx context.Context, event events.Event) error
+
+	// Upcast migrates the event's data through the upcasters of every
+	// version after its own, returning it at the latest version. Events
+	// already at the latest version, or of types without a contract, are
+	// returned unchanged.
+	Upcast(ctx context.Context, event events.Event) (events.Event, error)
+}
+
+// Domain types and data structures
+
+// Schema is the payload contract of one version of an event type
+type Schema struct {
+	EventType string `json:"event_type"`
+	Version   int    `json:"version"`
+
+	// Payload returns a new value of the typed payload, such as
+	// &events.UserRegisteredData{}, the data is decoded into; it is
+	// validated with its Validate method when it has one
+	Payload func() interface{} `json:"-"`
+
+	// Upcast migrates data of the previous version to this one; version 1
+	// has none
+	Upcast Upcaster `json:"-"`
+}
+
+// Upcaster migrates the data of an event from one schema version to the next
+type Upcaster func(data map[string]interface{}) (map[string]interface{}, error)
+
+// Validator is implemented by typed payloads checking their own fields
+type Validator interface {
+	Validate() error
+}
+
+// IsValid reports whether the schema names its event type and version and
+// can decode its payload
+func (s Schema) IsValid() bool {
+	return s.EventType != "" && s.Version > 0 && s.Payload != nil
+}
+
+// SchemaError represents event schema domain errors
+type SchemaError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e SchemaError) Error() string {
+	return e.Message
+}
+
+// Common event schema errors
+var (
+	ErrSchemaNotFound = SchemaError{Code: "SCHEMA_NOT_FOUND", Message: "Event schema not found"}
+	ErrSchemaExists   = SchemaError{Code: "SCHEMA_EXISTS", Message: "Event schema version already registered"}
+	ErrInvalidSchema  = SchemaError{Code: "INVALID_SCHEMA", Message: "Invalid event schema"}
+	ErrMissingUpcast  = SchemaError{Code: "MISSING_UPCAST", Message: "Event schema version has no upcaster from the previous version"}
+)
@@ -0,0 +1,67 @@
+package factory
+
+import (
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
+	"github.com/gentra/decorator-arch-go/internal/eventschema/registry"
+)
+
+// Config contains all configuration for building the event schema registry
+type Config struct {
+	// IncludeBuiltin registers the contracts of the events the application
+	// publishes, from BuiltinSchemas
+	IncludeBuiltin bool
+
+	// Schemas are registered after the built-in ones, adding event types or
+	// later versions of them
+	Schemas []eventschema.Schema
+}
+
+// DefaultConfig registers the built-in contracts
+func DefaultConfig() Config {
+	return Config{IncludeBuiltin: true}
+}
+
+// EventSchemaServiceFactory creates the event schema registry
+type EventSchemaServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new event schema factory with the given configuration
+func NewFactory(config Config) *EventSchemaServiceFactory {
+	return &EventSchemaServiceFactory{config: config}
+}
+
+// Build returns a registry holding the configured contracts
+func (f *EventSchemaServiceFactory) Build() (eventschema.Service, error) {
+	var schemas []eventschema.Schema
+	if f.config.IncludeBuiltin {
+		schemas = append(schemas, BuiltinSchemas()...)
+	}
+	schemas = append(schemas, f.config.Schemas...)
+	return registry.NewService(schemas...)
+}
+
+// BuiltinSchemas returns version 1 of the contract of every event the user,
+// auth and inbox domains publish with a typed payload
+func BuiltinSchemas() []eventschema.Schema {
+	return []eventschema.Schema{
+		v1(events.EventTypeUserRegistered, func() interface{} { return &events.UserRegisteredData{} }),
+		v1(events.EventTypeUserUpdated, func() interface{} { return &events.UserUpdatedData{} }),
+		v1(events.EventTypeUserDeactivated, func() interface{} { return &events.UserDeactivatedData{} }),
+		v1(events.EventTypeUserDeleted, func() interface{} { return &events.UserDeletedData{} }),
+		v1(events.EventTypeUserErased, func() interface{} { return &events.UserErasedData{} }),
+		v1(events.EventTypeEmailVerified, func() interface{} { return &events.EmailVerifiedData{} }),
+		v1(events.EventTypeUserPrefsUpdated, func() interface{} { return &events.PreferencesUpdatedData{} }),
+		v1(events.EventTypeUserLoggedIn, func() interface{} { return &events.UserLoggedInData{} }),
+		v1(events.EventTypePasswordChanged, func() interface{} { return &events.PasswordChangedData{} }),
+		v1(events.EventTypeTokenReuseDetected, func() interface{} { return &events.TokenReuseDetectedData{} }),
+		v1(events.EventTypeInAppNotificationCreated, func() interface{} { return &events.InAppNotificationCreatedData{} }),
+		v1(events.EventTypeInAppNotificationsRead, func() interface{} { return &events.InAppNotificationsReadData{} }),
+	}
+}
+
+// v1 is the first contract of an event type
+func v1(eventType string, payload func() interface{}) eventschema.Schema {
+	return eventschema.Schema{EventType: eventType, Version: 1, Payload: payload}
+}
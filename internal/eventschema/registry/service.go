@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
+)
+
+// key identifies a version of an event type
+type key struct {
+	eventType string
+	version   int
+}
+
+// service implements eventschema.Service as an in-memory registry; contracts
+// are code, registered at startup
+type service struct {
+	mu      sync.RWMutex
+	schemas map[key]eventschema.Schema
+	latest  map[string]int
+}
+
+// NewService creates a registry with the schemas registered
+func NewService(schemas ...eventschema.Schema) (eventschema.Service, error) {
+	s := &service{
+		schemas: make(map[key]eventschema.Schema),
+		latest:  make(map[string]int),
+	}
+	for _, schema := range schemas {
+		if err := s.Register(schema); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Register adds a contract; versions after the first must migrate the
+// previous version's data
+func (s *service) Register(schema eventschema.Schema) error {
+	if !schema.IsValid() {
+		return fmt.Errorf("%w: %s version %d needs an event type, a positive version and a payload",
+			eventschema.ErrInvalidSchema, schema.EventType, schema.Version)
+	}
+	if schema.Version > 1 && schema.Upcast == nil {
+		return fmt.Errorf("%w: %s version %d", eventschema.ErrMissingUpcast, schema.EventType, schema.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key{eventType: schema.EventType, version: schema.Version}
+	if _, exists := s.schemas[k]; exists {
+		return fmt.Errorf("%w: %s version %d", eventschema.ErrSchemaExists, schema.EventType, schema.Version)
+	}
+	s.schemas[k] = schema
+	if schema.Version > s.latest[schema.EventType] {
+		s.latest[schema.EventType] = schema.Version
+	}
+	return nil
+}
+
+// Lookup returns a registered contract
+func (s *service) Lookup(eventType string, version int) (*eventschema.Schema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, ok := s.schemas[key{eventType: eventType, version: version}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s version %d", eventschema.ErrSchemaNotFound, eventType, version)
+	}
+	return &schema, nil
+}
+
+// Latest returns the highest registered version of the event type
+func (s *service) Latest(eventType string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest[eventType]
+}
+
+// Validate decodes the data into the typed payload of its version,
+// rejecting fields the payload does not declare, then runs the payload's
+// own checks
+func (s *service) Validate(ctx context.Context, event events.Event) error {
+	if s.Latest(event.Type) == 0 {
+		return nil
+	}
+	version := event.EffectiveSchemaVersion()
+	schema, err := s.Lookup(event.Type, version)
+	if err != nil {
+		return fmt.Errorf("%w: %s has no version %d", events.ErrSchemaViolation, event.Type, version)
+	}
+
+	encoded, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", events.ErrSchemaViolation, err)
+	}
+	payload := schema.Payload()
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return fmt.Errorf("%w: %s version %d: %v", events.ErrSchemaViolation, event.Type, version, err)
+	}
+	if validator, ok := payload.(eventschema.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("%w: %s version %d: %v", events.ErrSchemaViolation, event.Type, version, err)
+		}
+	}
+	return nil
+}
+
+// Upcast runs the upcasters of every version after the event's, in order
+func (s *service) Upcast(ctx context.Context, event events.Event) (events.Event, error) {
+	latest := s.Latest(event.Type)
+	version := event.EffectiveSchemaVersion()
+	if latest == 0 || version >= latest {
+		return event, nil
+	}
+
+	// Upcasters may change the map they are given; the caller's event keeps its data
+	data := make(map[string]interface{}, len(event.Data))
+	for field, value := range event.Data {
+		data[field] = value
+	}
+	for next := version + 1; next <= latest; next++ {
+		schema, err := s.Lookup(event.Type, next)
+		if err != nil {
+			return event, err
+		}
+		if data, err = schema.Upcast(data); err != nil {
+			return event, fmt.Errorf("failed to upcast event %s to %s version %d: %w", event.ID, event.Type, next, err)
+		}
+	}
+
+	event.Data = data
+	event.SchemaVersion = latest
+	return event, nil
+}
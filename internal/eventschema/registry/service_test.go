@@ -0,0 +1,195 @@
+package registry_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/eventschema"
+	"github.com/gentra/decorator-arch-go/internal/eventschema/registry"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+// registeredV2 splits the name of user.registered v1 into first and last name
+func registeredV2() eventschema.Schema {
+	return eventschema.Schema{
+		EventType: events.EventTypeUserRegistered,
+		Version:   2,
+		Payload:   func() interface{} { return &events.UserRegisteredData{} },
+		Upcast: func(data map[string]interface{}) (map[string]interface{}, error) {
+			name, _ := data["name"].(string)
+			first, last, _ := strings.Cut(name, " ")
+			delete(data, "name")
+			data["first_name"] = first
+			data["last_name"] = last
+			return data, nil
+		},
+	}
+}
+
+// registeredV1 declares a single name field
+type registeredV1 struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+func newRegistry(t *testing.T) eventschema.Service {
+	t.Helper()
+	schemas, err := registry.NewService(
+		eventschema.Schema{
+			EventType: events.EventTypeUserRegistered,
+			Version:   1,
+			Payload:   func() interface{} { return &registeredV1{} },
+		},
+		registeredV2(),
+	)
+	require.NoError(t, err)
+	return schemas
+}
+
+func TestRegister_GivenInvalidSchemas_WhenRegistering_ThenFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   eventschema.Schema
+		expected error
+	}{
+		{
+			name:     "Given a schema without payload, When registering, Then fails as invalid",
+			schema:   eventschema.Schema{EventType: "custom.event", Version: 1},
+			expected: eventschema.ErrInvalidSchema,
+		},
+		{
+			name: "Given a second version without upcaster, When registering, Then fails as missing upcast",
+			schema: eventschema.Schema{EventType: "custom.event", Version: 2,
+				Payload: func() interface{} { return &map[string]interface{}{} }},
+			expected: eventschema.ErrMissingUpcast,
+		},
+		{
+			name:     "Given a version already registered, When registering, Then fails as existing",
+			schema:   registeredV2(),
+			expected: eventschema.ErrSchemaExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			schemas := newRegistry(t)
+
+			// Act
+			err := schemas.Register(tt.schema)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestValidate_GivenEventData_WhenValidating_ThenChecksContractOfItsVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     string
+		schemaVersion int
+		data          map[string]interface{}
+		expectedError bool
+	}{
+		{
+			name:          "Given data matching the latest version, When validating, Then passes",
+			eventType:     events.EventTypeUserRegistered,
+			schemaVersion: 2,
+			data:          map[string]interface{}{"user_id": "user-1", "email": "jane@example.com", "first_name": "Jane"},
+		},
+		{
+			name:      "Given an unversioned event matching version 1, When validating, Then passes",
+			eventType: events.EventTypeUserRegistered,
+			data:      map[string]interface{}{"user_id": "user-1", "email": "jane@example.com", "name": "Jane Doe"},
+		},
+		{
+			name:          "Given a field the version does not declare, When validating, Then fails",
+			eventType:     events.EventTypeUserRegistered,
+			schemaVersion: 2,
+			data:          map[string]interface{}{"user_id": "user-1", "email": "jane@example.com", "name": "Jane Doe"},
+			expectedError: true,
+		},
+		{
+			name:          "Given a payload failing its own checks, When validating, Then fails",
+			eventType:     events.EventTypeUserRegistered,
+			schemaVersion: 2,
+			data:          map[string]interface{}{"user_id": "user-1"},
+			expectedError: true,
+		},
+		{
+			name:          "Given a version that is not registered, When validating, Then fails",
+			eventType:     events.EventTypeUserRegistered,
+			schemaVersion: 3,
+			data:          map[string]interface{}{"user_id": "user-1", "email": "jane@example.com"},
+			expectedError: true,
+		},
+		{
+			name:      "Given a type without contract, When validating, Then passes",
+			eventType: "custom.event",
+			data:      map[string]interface{}{"anything": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			schemas := newRegistry(t)
+			event := *testkit.NewEventBuilder().WithType(tt.eventType).Build()
+			event.Data = tt.data
+			event.SchemaVersion = tt.schemaVersion
+
+			// Act
+			err := schemas.Validate(context.Background(), event)
+
+			// Assert
+			if tt.expectedError {
+				assert.ErrorIs(t, err, events.ErrSchemaViolation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpcast_GivenOldVersion_WhenUpcasting_ThenMigratesDataToLatestVersion(t *testing.T) {
+	// Arrange
+	schemas := newRegistry(t)
+	event := *testkit.NewEventBuilder().
+		WithData("user_id", "user-1").
+		WithData("email", "jane@example.com").
+		WithData("name", "Jane Doe").
+		Build()
+
+	// Act
+	upcast, err := schemas.Upcast(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, upcast.SchemaVersion)
+	assert.Equal(t, "Jane", upcast.Data["first_name"])
+	assert.Equal(t, "Doe", upcast.Data["last_name"])
+	assert.NotContains(t, upcast.Data, "name")
+	assert.Equal(t, "Jane Doe", event.Data["name"], "the original event keeps its data")
+	assert.NoError(t, schemas.Validate(context.Background(), upcast))
+}
+
+func TestUpcast_GivenLatestVersion_WhenUpcasting_ThenReturnsEventUnchanged(t *testing.T) {
+	// Arrange
+	schemas := newRegistry(t)
+	event := *testkit.NewEventBuilder().WithData("first_name", "Jane").Build()
+	event.SchemaVersion = 2
+
+	// Act
+	upcast, err := schemas.Upcast(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, event, upcast)
+}
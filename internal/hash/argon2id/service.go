@@ -0,0 +1,157 @@
+package argon2id
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+)
+
+// Params are the argon2id cost parameters
+type Params struct {
+	Memory      uint32 // Memory in KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams follow the second recommended option of RFC 9106 with
+// a smaller memory cost suitable for login requests
+func DefaultParams() Params {
+	return Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// service implements hash.Service with argon2id. Hashes use the PHC string
+// format "$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>"
+// with unpadded base64 salt and key.
+type service struct {
+	params Params
+}
+
+// NewService creates an argon2id hash service; zero parameters use DefaultParams
+func NewService(params Params) hash.Service {
+	defaults := DefaultParams()
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = defaults.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	return &service{params: params}
+}
+
+// Hash hashes the password with a random salt and the configured parameters
+func (s *service) Hash(ctx context.Context, password string) (string, error) {
+	salt := make([]byte, s.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.params.Iterations, s.params.Memory, s.params.Parallelism, s.params.KeyLength)
+	return encode(s.params, salt, key), nil
+}
+
+// Compare checks the password against an argon2id hash with the parameters
+// the hash was made with
+func (s *service) Compare(ctx context.Context, encodedHash, password string) error {
+	if !s.Supports(encodedHash) {
+		return hash.ErrUnknownAlgorithm
+	}
+
+	params, salt, key, err := decode(encodedHash)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return hash.ErrMismatch
+	}
+	return nil
+}
+
+// NeedsRehash reports hashes of other algorithms and other parameters
+func (s *service) NeedsRehash(encodedHash string) bool {
+	if !s.Supports(encodedHash) {
+		return true
+	}
+	params, _, _, err := decode(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != s.params.Memory || params.Iterations != s.params.Iterations ||
+		params.Parallelism != s.params.Parallelism || params.KeyLength != s.params.KeyLength ||
+		params.SaltLength != s.params.SaltLength
+}
+
+// Supports reports argon2id hashes
+func (s *service) Supports(encodedHash string) bool {
+	return hash.Identify(encodedHash) == hash.AlgorithmArgon2id
+}
+
+// Helper methods
+
+func encode(params Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decode(encodedHash string) (Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+	if version != argon2.Version {
+		return Params{}, nil, nil, hash.ErrUnknownAlgorithm
+	}
+
+	var params Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Params{}, nil, nil, hash.ErrMalformedHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,98 @@
+package argon2id_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+)
+
+// testParams keep hashing fast in tests
+var testParams = argon2id.Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestService_GivenPassword_WhenHashedAndCompared_ThenMatchesOnlyThatPassword(t *testing.T) {
+	// Arrange
+	service := argon2id.NewService(testParams)
+	ctx := context.Background()
+
+	// Act
+	encoded, err := service.Hash(ctx, "Str0ngPassw0rd!")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"), encoded)
+	assert.NoError(t, service.Compare(ctx, encoded, "Str0ngPassw0rd!"))
+	assert.Equal(t, hash.ErrMismatch, service.Compare(ctx, encoded, "wrong-password"))
+	assert.False(t, service.NeedsRehash(encoded))
+}
+
+func TestService_GivenSamePassword_WhenHashedTwice_ThenUsesDifferentSalts(t *testing.T) {
+	service := argon2id.NewService(testParams)
+
+	first, err := service.Hash(context.Background(), "Str0ngPassw0rd!")
+	require.NoError(t, err)
+	second, err := service.Hash(context.Background(), "Str0ngPassw0rd!")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestService_GivenHashWithOtherParameters_WhenCheckingRehash_ThenReportsIt(t *testing.T) {
+	ctx := context.Background()
+	old, err := argon2id.NewService(testParams).Hash(ctx, "Str0ngPassw0rd!")
+	require.NoError(t, err)
+
+	stronger := testParams
+	stronger.Iterations = 2
+	service := argon2id.NewService(stronger)
+
+	assert.True(t, service.NeedsRehash(old))
+	assert.True(t, service.NeedsRehash("$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"))
+	assert.NoError(t, service.Compare(ctx, old, "Str0ngPassw0rd!"), "hashes verify with their own parameters")
+}
+
+func TestService_GivenMalformedHash_WhenComparing_ThenReturnsHashError(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		expected error
+	}{
+		{
+			name:     "Given a hash of another algorithm, When comparing, Then returns unknown algorithm",
+			encoded:  "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			expected: hash.ErrUnknownAlgorithm,
+		},
+		{
+			name:     "Given another argon2 version, When comparing, Then returns unknown algorithm",
+			encoded:  "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+			expected: hash.ErrUnknownAlgorithm,
+		},
+		{
+			name:     "Given missing sections, When comparing, Then returns malformed hash",
+			encoded:  "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ",
+			expected: hash.ErrMalformedHash,
+		},
+		{
+			name:     "Given zero parameters, When comparing, Then returns malformed hash",
+			encoded:  "$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+			expected: hash.ErrMalformedHash,
+		},
+		{
+			name:     "Given an invalid salt encoding, When comparing, Then returns malformed hash",
+			encoded:  "$argon2id$v=19$m=1024,t=1,p=1$not*base64$a2V5a2V5",
+			expected: hash.ErrMalformedHash,
+		},
+	}
+
+	service := argon2id.NewService(testParams)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.Compare(context.Background(), tt.encoded, "password"))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package bcrypt
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+)
+
+// DefaultCost is the cost used when none is configured
+const DefaultCost = bcrypt.DefaultCost
+
+// service implements hash.Service with bcrypt. Hashes use the standard
+// "$2a$<cost>$" encoding, which existing password hashes already have.
+type service struct {
+	cost int
+}
+
+// NewService creates a bcrypt hash service; costs outside bcrypt's range use DefaultCost
+func NewService(cost int) hash.Service {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultCost
+	}
+	return &service{cost: cost}
+}
+
+// Hash hashes the password with the configured cost
+func (s *service) Hash(ctx context.Context, password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", hash.ErrPasswordTooLong
+		}
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Compare checks the password against a bcrypt hash
+func (s *service) Compare(ctx context.Context, encodedHash, password string) error {
+	if !s.Supports(encodedHash) {
+		return hash.ErrUnknownAlgorithm
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return hash.ErrMismatch
+	default:
+		return hash.ErrMalformedHash
+	}
+}
+
+// NeedsRehash reports hashes of other algorithms and other costs
+func (s *service) NeedsRehash(encodedHash string) bool {
+	if !s.Supports(encodedHash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != s.cost
+}
+
+// Supports reports bcrypt hashes
+func (s *service) Supports(encodedHash string) bool {
+	return hash.Identify(encodedHash) == hash.AlgorithmBcrypt
+}
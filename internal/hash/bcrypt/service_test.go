@@ -0,0 +1,55 @@
+package bcrypt_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/bcrypt"
+)
+
+func TestService_GivenPassword_WhenHashedAndCompared_ThenMatchesOnlyThatPassword(t *testing.T) {
+	// Arrange
+	service := bcrypt.NewService(4)
+	ctx := context.Background()
+
+	// Act
+	encoded, err := service.Hash(ctx, "Str0ngPassw0rd!")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$04$"))
+	assert.NoError(t, service.Compare(ctx, encoded, "Str0ngPassw0rd!"))
+	assert.Equal(t, hash.ErrMismatch, service.Compare(ctx, encoded, "wrong-password"))
+	assert.False(t, service.NeedsRehash(encoded))
+}
+
+func TestService_GivenOutdatedHash_WhenCheckingRehash_ThenReportsIt(t *testing.T) {
+	ctx := context.Background()
+	cheap, err := bcrypt.NewService(4).Hash(ctx, "Str0ngPassw0rd!")
+	require.NoError(t, err)
+
+	service := bcrypt.NewService(5)
+
+	assert.True(t, service.NeedsRehash(cheap), "lower cost")
+	assert.True(t, service.NeedsRehash("$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$a2V5"), "other algorithm")
+	assert.NoError(t, service.Compare(ctx, cheap, "Str0ngPassw0rd!"), "old costs still verify")
+}
+
+func TestService_GivenForeignOrBrokenHash_WhenComparing_ThenReturnsHashError(t *testing.T) {
+	service := bcrypt.NewService(4)
+	ctx := context.Background()
+
+	assert.Equal(t, hash.ErrUnknownAlgorithm, service.Compare(ctx, "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$a2V5", "password"))
+	assert.Equal(t, hash.ErrMalformedHash, service.Compare(ctx, "$2a$10$short", "password"))
+}
+
+func TestService_GivenPasswordOverBcryptLimit_WhenHashing_ThenReturnsPasswordTooLong(t *testing.T) {
+	_, err := bcrypt.NewService(4).Hash(context.Background(), strings.Repeat("a", 73))
+
+	assert.Equal(t, hash.ErrPasswordTooLong, err)
+}
@@ -0,0 +1,66 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+	"github.com/gentra/decorator-arch-go/internal/hash/bcrypt"
+	"github.com/gentra/decorator-arch-go/internal/hash/versioned"
+)
+
+// Config contains all configuration for building the hash service
+type Config struct {
+	// Algorithm new passwords are hashed with: "bcrypt" or "argon2id".
+	// Hashes of the other algorithm are still verified and rehashed with
+	// this one on the next login.
+	Algorithm string
+
+	// BcryptCost is the bcrypt work factor; zero uses bcrypt.DefaultCost
+	BcryptCost int
+
+	// Argon2id parameters; zero fields use argon2id.DefaultParams
+	Argon2id argon2id.Params
+}
+
+// HashServiceFactory creates and assembles the complete hash service
+type HashServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new hash service factory with the given configuration
+func NewFactory(config Config) *HashServiceFactory {
+	return &HashServiceFactory{
+		config: config,
+	}
+}
+
+// Build assembles the hash service hashing with the configured algorithm
+func (f *HashServiceFactory) Build() (hash.Service, error) {
+	bcryptService := bcrypt.NewService(f.config.BcryptCost)
+	argon2idService := argon2id.NewService(f.config.Argon2id)
+
+	switch f.config.Algorithm {
+	case hash.AlgorithmBcrypt, "":
+		return versioned.NewService(bcryptService, argon2idService), nil
+	case hash.AlgorithmArgon2id:
+		return versioned.NewService(argon2idService, bcryptService), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", f.config.Algorithm)
+	}
+}
+
+// DefaultConfig hashes with bcrypt at its default cost, as passwords always were
+func DefaultConfig() Config {
+	return Config{
+		Algorithm:  hash.AlgorithmBcrypt,
+		BcryptCost: bcrypt.DefaultCost,
+		Argon2id:   argon2id.DefaultParams(),
+	}
+}
+
+// NewDefaultService returns the hash service of DefaultConfig
+func NewDefaultService() hash.Service {
+	service, _ := NewFactory(DefaultConfig()).Build()
+	return service
+}
@@ -0,0 +1,69 @@
+package factory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+	"github.com/gentra/decorator-arch-go/internal/hash/factory"
+)
+
+func TestBuild_GivenAlgorithm_WhenBuilding_ThenHashesWithItAndVerifiesBoth(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		expected  string
+		err       bool
+	}{
+		{
+			name:      "Given bcrypt, When building, Then hashes with bcrypt",
+			algorithm: hash.AlgorithmBcrypt,
+			expected:  hash.AlgorithmBcrypt,
+		},
+		{
+			name:      "Given argon2id, When building, Then hashes with argon2id",
+			algorithm: hash.AlgorithmArgon2id,
+			expected:  hash.AlgorithmArgon2id,
+		},
+		{
+			name:      "Given no algorithm, When building, Then hashes with bcrypt",
+			algorithm: "",
+			expected:  hash.AlgorithmBcrypt,
+		},
+		{
+			name:      "Given an unknown algorithm, When building, Then returns an error",
+			algorithm: "md5",
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := factory.Config{
+				Algorithm:  tt.algorithm,
+				BcryptCost: 4,
+				Argon2id:   argon2id.Params{Memory: 1024, Iterations: 1, Parallelism: 1},
+			}
+
+			// Act
+			service, err := factory.NewFactory(config).Build()
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			encoded, err := service.Hash(context.Background(), "Str0ngPassw0rd!")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hash.Identify(encoded))
+			assert.True(t, service.Supports("$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"))
+			assert.True(t, service.Supports("$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"))
+		})
+	}
+}
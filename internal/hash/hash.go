@@ -0,0 +1,63 @@
+package hash
+
+import (
+	"context"
+	"strings"
+)
+
+// Service defines the password hashing domain interface - the ONLY interface in this domain
+type Service interface {
+	// Hash returns the encoded hash of the password. The encoding starts with
+	// a versioned prefix naming the algorithm and its parameters, so hashes
+	// stay verifiable after the configured algorithm changes.
+	Hash(ctx context.Context, password string) (string, error)
+
+	// Compare checks the password against an encoded hash and returns
+	// ErrMismatch when it does not match
+	Compare(ctx context.Context, encodedHash, password string) error
+
+	// NeedsRehash reports whether the hash was made with another algorithm or
+	// other parameters than Hash uses now, so it should be replaced the next
+	// time the password is known
+	NeedsRehash(encodedHash string) bool
+
+	// Supports reports whether Compare understands the hash's encoding
+	Supports(encodedHash string) bool
+}
+
+// Supported algorithms
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// HashError represents domain-specific hashing errors
+type HashError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e HashError) Error() string {
+	return e.Message
+}
+
+// Common hashing error codes
+var (
+	ErrMismatch         = HashError{Code: "HASH_MISMATCH", Message: "Password does not match the hash"}
+	ErrMalformedHash    = HashError{Code: "MALFORMED_HASH", Message: "Encoded hash is malformed"}
+	ErrUnknownAlgorithm = HashError{Code: "UNKNOWN_ALGORITHM", Message: "Hash uses an unsupported algorithm"}
+	ErrPasswordTooLong  = HashError{Code: "PASSWORD_TOO_LONG", Message: "Password is too long to hash"}
+)
+
+// Identify returns the algorithm an encoded hash was made with, or "" when
+// the prefix is not recognized
+func Identify(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encodedHash, "$2a$"), strings.HasPrefix(encodedHash, "$2b$"), strings.HasPrefix(encodedHash, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
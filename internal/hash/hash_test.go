@@ -0,0 +1,44 @@
+package hash_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+)
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		algorithm string
+	}{
+		{
+			name:      "Given a bcrypt hash, When Identify is called, Then should return bcrypt",
+			encoded:   "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			algorithm: hash.AlgorithmBcrypt,
+		},
+		{
+			name:      "Given an argon2id hash, When Identify is called, Then should return argon2id",
+			encoded:   "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ$a2V5a2V5",
+			algorithm: hash.AlgorithmArgon2id,
+		},
+		{
+			name:      "Given an unknown prefix, When Identify is called, Then should return empty",
+			encoded:   "$argon2i$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ$a2V5a2V5",
+			algorithm: "",
+		},
+		{
+			name:      "Given a plaintext password, When Identify is called, Then should return empty",
+			encoded:   "hunter2",
+			algorithm: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.algorithm, hash.Identify(tt.encoded))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package versioned
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+)
+
+// service implements hash.Service across algorithms. New hashes use the
+// current algorithm; hashes of the legacy algorithms are still compared by
+// the service recognizing their prefix and are reported for rehashing.
+type service struct {
+	current hash.Service
+	legacy  []hash.Service
+}
+
+// NewService creates a hash service hashing with current and comparing
+// hashes of current and every legacy algorithm
+func NewService(current hash.Service, legacy ...hash.Service) hash.Service {
+	return &service{
+		current: current,
+		legacy:  legacy,
+	}
+}
+
+// Hash hashes with the current algorithm
+func (s *service) Hash(ctx context.Context, password string) (string, error) {
+	return s.current.Hash(ctx, password)
+}
+
+// Compare delegates to the algorithm that made the hash
+func (s *service) Compare(ctx context.Context, encodedHash, password string) error {
+	for _, algorithm := range s.algorithms() {
+		if algorithm.Supports(encodedHash) {
+			return algorithm.Compare(ctx, encodedHash, password)
+		}
+	}
+	return hash.ErrUnknownAlgorithm
+}
+
+// NeedsRehash reports every hash the current algorithm would not produce
+func (s *service) NeedsRehash(encodedHash string) bool {
+	return s.current.NeedsRehash(encodedHash)
+}
+
+// Supports reports hashes of the current and legacy algorithms
+func (s *service) Supports(encodedHash string) bool {
+	for _, algorithm := range s.algorithms() {
+		if algorithm.Supports(encodedHash) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) algorithms() []hash.Service {
+	return append([]hash.Service{s.current}, s.legacy...)
+}
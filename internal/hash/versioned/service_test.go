@@ -0,0 +1,45 @@
+package versioned_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+	"github.com/gentra/decorator-arch-go/internal/hash/bcrypt"
+	"github.com/gentra/decorator-arch-go/internal/hash/versioned"
+)
+
+func TestService_GivenLegacyHash_WhenComparing_ThenVerifiesAndReportsRehash(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	legacy := bcrypt.NewService(4)
+	current := argon2id.NewService(argon2id.Params{Memory: 1024, Iterations: 1, Parallelism: 1})
+	service := versioned.NewService(current, legacy)
+	legacyHash, err := legacy.Hash(ctx, "Str0ngPassw0rd!")
+	require.NoError(t, err)
+
+	// Act
+	compareErr := service.Compare(ctx, legacyHash, "Str0ngPassw0rd!")
+	currentHash, hashErr := service.Hash(ctx, "Str0ngPassw0rd!")
+
+	// Assert
+	assert.NoError(t, compareErr)
+	assert.True(t, service.NeedsRehash(legacyHash))
+	require.NoError(t, hashErr)
+	assert.Equal(t, hash.AlgorithmArgon2id, hash.Identify(currentHash))
+	assert.False(t, service.NeedsRehash(currentHash))
+	assert.Equal(t, hash.ErrMismatch, service.Compare(ctx, legacyHash, "wrong-password"))
+}
+
+func TestService_GivenHashOfUnconfiguredAlgorithm_WhenComparing_ThenReturnsUnknownAlgorithm(t *testing.T) {
+	service := versioned.NewService(bcrypt.NewService(4))
+
+	err := service.Compare(context.Background(), "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5", "password")
+
+	assert.Equal(t, hash.ErrUnknownAlgorithm, err)
+	assert.False(t, service.Supports("$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"))
+}
@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Service defines the idempotency domain interface - the ONLY interface in this domain.
+// It remembers the outcome of operations by a client-chosen key, so a retried
+// request returns the original result instead of running the operation twice.
+type Service interface {
+	// Reserve claims key for an operation whose request hashes to
+	// fingerprint. It returns nil when the caller now holds the key and must
+	// Complete or Release it, or the live record another request left for
+	// the key. Reservations expire after ttl so a crashed caller cannot
+	// block the key for good.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+
+	// Complete stores the response of the operation holding key, replayed
+	// to requests reusing the key until ttl elapses
+	Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error
+
+	// Release drops the reservation of an operation that failed, so a retry
+	// runs it again
+	Release(ctx context.Context, key string) error
+}
+
+// Domain types and data structures
+
+// Record is what is stored for an idempotency key
+type Record struct {
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"`
+	Status      Status          `json:"status"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+// Status tells whether the operation holding a key has finished
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+)
+
+// IsCompleted reports whether the record holds a response to replay
+func (r *Record) IsCompleted() bool {
+	return r.Status == StatusCompleted
+}
+
+// IsExpired reports whether the record no longer claims its key at now
+func (r *Record) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IdempotencyError represents idempotency domain errors
+type IdempotencyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e IdempotencyError) Error() string {
+	return e.Message
+}
+
+// Common idempotency errors
+var (
+	ErrKeyNotReserved = IdempotencyError{Code: "KEY_NOT_RESERVED", Message: "Idempotency key is not reserved"}
+)
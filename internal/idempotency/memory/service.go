@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+)
+
+// service implements idempotency.Service in memory, for single instances and tests
+type service struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+// NewService creates an in-memory idempotency store
+func NewService() idempotency.Service {
+	return &service{records: make(map[string]idempotency.Record)}
+}
+
+// Reserve claims the key unless a live record holds it
+func (s *service) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.records[key]; ok && !existing.IsExpired(now) {
+		return &existing, nil
+	}
+
+	s.records[key] = idempotency.Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      idempotency.StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete stores the response of a reserved key
+func (s *service) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || record.IsCompleted() {
+		return idempotency.ErrKeyNotReserved
+	}
+
+	record.Status = idempotency.StatusCompleted
+	record.Response = append([]byte(nil), response...)
+	record.ExpiresAt = time.Now().Add(ttl)
+	s.records[key] = record
+	s.evictExpired()
+	return nil
+}
+
+// Release drops the reservation of a key
+func (s *service) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && !record.IsCompleted() {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// Helper methods
+
+// evictExpired drops expired records so the map does not grow without bound;
+// the caller must hold the lock
+func (s *service) evictExpired() {
+	now := time.Now()
+	for key, record := range s.records {
+		if record.IsExpired(now) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+	"github.com/gentra/decorator-arch-go/internal/idempotency/memory"
+)
+
+func TestIdempotency_GivenFreeKey_WhenReserving_ThenClaimsKey(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+
+	existing, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	again, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, idempotency.StatusPending, again.Status)
+	assert.Equal(t, "fp-1", again.Fingerprint)
+}
+
+func TestIdempotency_GivenCompletedKey_WhenReserving_ThenReturnsResponse(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, "key-1", []byte(`{"id":"user-1"}`), time.Hour))
+
+	existing, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.True(t, existing.IsCompleted())
+	assert.JSONEq(t, `{"id":"user-1"}`, string(existing.Response))
+}
+
+func TestIdempotency_GivenReleasedKey_WhenReserving_ThenClaimsKeyAgain(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "key-1"))
+
+	existing, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func TestIdempotency_GivenCompletedKey_WhenReleasing_ThenKeepsResponse(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, "key-1", []byte(`null`), time.Hour))
+
+	require.NoError(t, store.Release(ctx, "key-1"))
+
+	existing, err := store.Reserve(ctx, "key-1", "fp-1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.True(t, existing.IsCompleted())
+}
+
+func TestIdempotency_GivenExpiredReservation_WhenReserving_ThenClaimsKeyAgain(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Reserve(ctx, "key-1", "fp-1", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	existing, err := store.Reserve(ctx, "key-1", "fp-2", time.Minute)
+
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func TestIdempotency_GivenUnreservedKey_WhenCompleting_ThenReturnsNotReserved(t *testing.T) {
+	store := memory.NewService()
+
+	err := store.Complete(context.Background(), "key-1", []byte(`null`), time.Hour)
+
+	assert.ErrorIs(t, err, idempotency.ErrKeyNotReserved)
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+)
+
+// reserveAttempts bounds the retries when a competing record expires or is
+// released between claiming and reading it
+const reserveAttempts = 3
+
+// service implements idempotency.Service on the idempotency_keys table, so
+// every instance sharing the database sees the same keys
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed idempotency store
+func NewService(pool *pgxpool.Pool) idempotency.Service {
+	return &service{pool: pool}
+}
+
+// Reserve inserts a pending record, taking over the key if its record expired
+func (s *service) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*idempotency.Record, error) {
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		tag, err := s.pool.Exec(ctx, `
+			INSERT INTO idempotency_keys (key, fingerprint, status, created_at, expires_at)
+			VALUES ($1, $2, $3, now(), now() + make_interval(secs => $4))
+			ON CONFLICT (key) DO UPDATE SET
+				fingerprint = EXCLUDED.fingerprint,
+				status = EXCLUDED.status,
+				response = NULL,
+				created_at = EXCLUDED.created_at,
+				expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()`,
+			key, fingerprint, idempotency.StatusPending, ttl.Seconds())
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		existing, err := s.find(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		return existing, err
+	}
+	return nil, errors.New("idempotency key changed hands while reserving it")
+}
+
+// Complete stores the response of a pending record
+func (s *service) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = $2, response = $3, expires_at = now() + make_interval(secs => $4)
+		WHERE key = $1 AND status = $5`,
+		key, idempotency.StatusCompleted, response, ttl.Seconds(), idempotency.StatusPending)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return idempotency.ErrKeyNotReserved
+	}
+	return nil
+}
+
+// Release deletes a pending record
+func (s *service) Release(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE key = $1 AND status = $2`,
+		key, idempotency.StatusPending)
+	return err
+}
+
+// Helper methods
+
+// find reads the live record for key, returning pgx.ErrNoRows when there is none
+func (s *service) find(ctx context.Context, key string) (*idempotency.Record, error) {
+	var record idempotency.Record
+	err := s.pool.QueryRow(ctx, `
+		SELECT key, fingerprint, status, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND expires_at > now()`, key).
+		Scan(&record.Key, &record.Fingerprint, &record.Status, &record.Response, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+)
+
+// DefaultKeyPrefix namespaces idempotency records in a shared Redis
+const DefaultKeyPrefix = "idempotency:"
+
+// reserveAttempts bounds the retries when a competing record expires or is
+// released between claiming and reading it
+const reserveAttempts = 3
+
+// service implements idempotency.Service on Redis; records expire with the
+// key TTL, so no cleanup is needed
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed idempotency store using DefaultKeyPrefix
+func NewService(client *redis.Client) idempotency.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed idempotency store whose keys
+// start with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) idempotency.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// Reserve stores a pending record with SET NX, or returns the one already stored
+func (s *service) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*idempotency.Record, error) {
+	now := time.Now()
+	payload, err := json.Marshal(idempotency.Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      idempotency.StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		reserved, err := s.client.SetNX(ctx, s.redisKey(key), payload, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		existing, err := s.get(ctx, key)
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		return existing, err
+	}
+	return nil, errors.New("idempotency key changed hands while reserving it")
+}
+
+// Complete replaces the pending record with the response
+func (s *service) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	record, err := s.get(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		return idempotency.ErrKeyNotReserved
+	}
+	if err != nil {
+		return err
+	}
+	if record.IsCompleted() {
+		return idempotency.ErrKeyNotReserved
+	}
+
+	record.Status = idempotency.StatusCompleted
+	record.Response = response
+	record.ExpiresAt = time.Now().Add(ttl)
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.SetArgs(ctx, s.redisKey(key), payload, redis.SetArgs{Mode: "XX", TTL: ttl}).Err()
+	if errors.Is(err, redis.Nil) {
+		return idempotency.ErrKeyNotReserved
+	}
+	return err
+}
+
+// Release deletes the record while it is still pending
+func (s *service) Release(ctx context.Context, key string) error {
+	record, err := s.get(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if record.IsCompleted() {
+		return nil
+	}
+	return s.client.Del(ctx, s.redisKey(key)).Err()
+}
+
+// Helper methods
+
+// redisKey returns the Redis key holding the record for key
+func (s *service) redisKey(key string) string {
+	return s.prefix + key
+}
+
+// get reads the record for key, returning redis.Nil when there is none
+func (s *service) get(ctx context.Context, key string) (*idempotency.Record, error) {
+	payload, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var record idempotency.Record
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
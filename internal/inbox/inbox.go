@@ -0,0 +1,174 @@
+package inbox
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// Service defines the inbox domain interface - the ONLY interface in this
+// domain. It keeps the in-app notifications of each user, shown in the
+// application until the user reads them.
+type Service interface {
+	// Deliver adds a notification to its user's inbox, returning it with
+	// its ID and creation time assigned when it had none
+	Deliver(ctx context.Context, n Notification) (*Notification, error)
+
+	// List returns a page of the user's notifications, newest first, with
+	// the number of unread ones
+	List(ctx context.Context, userID string, query Query) (*Page, error)
+
+	// UnreadCount returns the number of notifications the user has not read
+	UnreadCount(ctx context.Context, userID string) (int, error)
+
+	// MarkRead marks one of the user's notifications read, or returns
+	// ErrNotFound when the user has no such notification. Notifications
+	// already read keep the time they were first read.
+	MarkRead(ctx context.Context, userID, id string) (*Notification, error)
+
+	// MarkAllRead marks every unread notification of the user read and
+	// returns how many there were
+	MarkAllRead(ctx context.Context, userID string) (int, error)
+}
+
+// Domain types and data structures
+
+// Notification is an in-app notification in a user's inbox
+type Notification struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Title  string `json:"title"`
+	Body   string `json:"body,omitempty"`
+
+	// Category is the notification type, as in the user's
+	// NotificationTypes preferences
+	Category string `json:"category,omitempty"`
+
+	// Link is where the application takes the user from the notification
+	Link string `json:"link,omitempty"`
+
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Priority  notification.Priority  `json:"priority,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+}
+
+// Read reports whether the user read the notification
+func (n Notification) Read() bool {
+	return n.ReadAt != nil
+}
+
+// Validate checks that the notification has a user and a title
+func (n Notification) Validate() error {
+	if n.UserID == "" {
+		return InboxError{Code: ErrInvalidNotification.Code, Message: "user is required", Field: "user_id"}
+	}
+	if n.Title == "" {
+		return InboxError{Code: ErrInvalidNotification.Code, Message: "title is required", Field: "title"}
+	}
+	return nil
+}
+
+// Page sizes of List
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Query selects a page of a user's notifications. Pages after the first
+// are selected with the NextCursor of the previous one, which stays stable
+// while new notifications arrive.
+type Query struct {
+	UnreadOnly bool   `json:"unread_only,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	Cursor     string `json:"cursor,omitempty"`
+}
+
+// LimitOrDefault returns the page size: DefaultLimit when not positive, at
+// most MaxLimit
+func (q Query) LimitOrDefault() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultLimit
+	case q.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return q.Limit
+	}
+}
+
+// Matches reports whether the notification belongs on the query's pages
+func (q Query) Matches(n Notification) bool {
+	return !q.UnreadOnly || !n.Read()
+}
+
+// Page is one page of a user's notifications returned by List
+type Page struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+	Limit         int            `json:"limit"`
+	NextCursor    string         `json:"next_cursor,omitempty"` // Set when more notifications follow
+}
+
+// Cursor is the position after the last notification of a page
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// CursorAfter returns the cursor of a page ending with the notification
+func CursorAfter(n Notification) Cursor {
+	return Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+// Encode returns the opaque form of the cursor used in Page.NextCursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor issued in Page.NextCursor
+func DecodeCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Follows reports whether the notification comes after the cursor, newest
+// first
+func (c Cursor) Follows(n Notification) bool {
+	if n.CreatedAt.Equal(c.CreatedAt) {
+		return n.ID < c.ID
+	}
+	return n.CreatedAt.Before(c.CreatedAt)
+}
+
+// InboxError represents inbox domain errors
+type InboxError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e InboxError) Error() string {
+	return e.Message
+}
+
+// Is matches inbox errors by code so detailed errors match their sentinel
+func (e InboxError) Is(target error) bool {
+	t, ok := target.(InboxError)
+	return ok && t.Code == e.Code
+}
+
+// Common inbox errors
+var (
+	ErrNotFound            = InboxError{Code: "IN_APP_NOTIFICATION_NOT_FOUND", Message: "Notification not found"}
+	ErrInvalidNotification = InboxError{Code: "INVALID_IN_APP_NOTIFICATION", Message: "Invalid in-app notification"}
+	ErrInvalidCursor       = InboxError{Code: "INVALID_CURSOR", Message: "Invalid page cursor", Field: "cursor"}
+)
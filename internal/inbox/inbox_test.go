@@ -0,0 +1,73 @@
+package inbox_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+)
+
+func TestCursor_GivenEncodedCursor_WhenDecoding_ThenReturnsThePosition(t *testing.T) {
+	tests := []struct {
+		name          string
+		encoded       func() string
+		expectedError error
+	}{
+		{
+			name: "Given a cursor issued by a page, When decoding, Then returns its position",
+			encoded: func() string {
+				return inbox.Cursor{CreatedAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), ID: "n-1"}.Encode()
+			},
+		},
+		{
+			name:          "Given a malformed cursor, When decoding, Then rejects it",
+			encoded:       func() string { return "not-a-cursor" },
+			expectedError: inbox.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			cursor, err := inbox.DecodeCursor(tt.encoded())
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "n-1", cursor.ID)
+			assert.True(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC).Equal(cursor.CreatedAt))
+		})
+	}
+}
+
+func TestCursorFollows_GivenNotifications_WhenComparing_ThenOrdersThemNewestFirst(t *testing.T) {
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	cursor := inbox.CursorAfter(inbox.Notification{ID: "n-5", CreatedAt: at})
+
+	tests := []struct {
+		name     string
+		n        inbox.Notification
+		expected bool
+	}{
+		{name: "Given an older notification, When comparing, Then it follows", n: inbox.Notification{ID: "n-9", CreatedAt: at.Add(-time.Second)}, expected: true},
+		{name: "Given a newer notification, When comparing, Then it does not follow", n: inbox.Notification{ID: "n-1", CreatedAt: at.Add(time.Second)}},
+		{name: "Given a tie with a lower ID, When comparing, Then it follows", n: inbox.Notification{ID: "n-4", CreatedAt: at}, expected: true},
+		{name: "Given the cursor's own notification, When comparing, Then it does not follow", n: inbox.Notification{ID: "n-5", CreatedAt: at}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			follows := cursor.Follows(tt.n)
+
+			// Assert
+			assert.Equal(t, tt.expected, follows)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+)
+
+// service implements inbox.Service interface in memory, for single
+// instances and tests; inboxes are lost on restart
+type service struct {
+	mu    sync.RWMutex
+	users map[string][]*inbox.Notification // keyed by user ID, newest first
+}
+
+// NewService creates empty in-memory inboxes
+func NewService() inbox.Service {
+	return &service{users: make(map[string][]*inbox.Notification)}
+}
+
+// Deliver adds a notification to its user's inbox
+func (s *service) Deliver(ctx context.Context, n inbox.Notification) (*inbox.Notification, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := n
+	notifications := append(s.users[n.UserID], &stored)
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return inbox.CursorAfter(*notifications[i]).Follows(*notifications[j])
+	})
+	s.users[n.UserID] = notifications
+	return &n, nil
+}
+
+// List returns a page of the user's notifications, newest first
+func (s *service) List(ctx context.Context, userID string, query inbox.Query) (*inbox.Page, error) {
+	var cursor *inbox.Cursor
+	if query.Cursor != "" {
+		decoded, err := inbox.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &decoded
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	page := &inbox.Page{Notifications: []inbox.Notification{}, Limit: query.LimitOrDefault()}
+	for _, n := range s.users[userID] {
+		if !n.Read() {
+			page.UnreadCount++
+		}
+		if !query.Matches(*n) || (cursor != nil && !cursor.Follows(*n)) {
+			continue
+		}
+		if len(page.Notifications) == page.Limit {
+			page.NextCursor = inbox.CursorAfter(page.Notifications[page.Limit-1]).Encode()
+			continue
+		}
+		page.Notifications = append(page.Notifications, *n)
+	}
+	return page, nil
+}
+
+// UnreadCount returns the number of notifications the user has not read
+func (s *service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, n := range s.users[userID] {
+		if !n.Read() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications read
+func (s *service) MarkRead(ctx context.Context, userID, id string) (*inbox.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range s.users[userID] {
+		if n.ID != id {
+			continue
+		}
+		if n.ReadAt == nil {
+			now := time.Now()
+			n.ReadAt = &now
+		}
+		read := *n
+		return &read, nil
+	}
+	return nil, inbox.ErrNotFound
+}
+
+// MarkAllRead marks every unread notification of the user read
+func (s *service) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	count := 0
+	for _, n := range s.users[userID] {
+		if n.ReadAt == nil {
+			n.ReadAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
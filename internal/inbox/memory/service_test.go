@@ -0,0 +1,159 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/inbox/memory"
+)
+
+// deliverAll delivers count notifications to the user, a minute apart and
+// oldest first, returning their IDs newest first
+func deliverAll(t *testing.T, service inbox.Service, userID string, count int) []string {
+	t.Helper()
+	start := time.Now().Add(-time.Hour)
+	ids := make([]string, count)
+	for i := 0; i < count; i++ {
+		delivered, err := service.Deliver(context.Background(), inbox.Notification{
+			UserID:    userID,
+			Title:     fmt.Sprintf("Notification %d", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+		ids[count-1-i] = delivered.ID
+	}
+	return ids
+}
+
+func pageIDs(page *inbox.Page) []string {
+	ids := make([]string, len(page.Notifications))
+	for i, n := range page.Notifications {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+func TestList_GivenManyNotifications_WhenPaging_ThenReturnsThemNewestFirstOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service := memory.NewService()
+	ids := deliverAll(t, service, "user-1", 5)
+	deliverAll(t, service, "user-2", 2)
+
+	// Act
+	first, err := service.List(ctx, "user-1", inbox.Query{Limit: 2})
+	require.NoError(t, err)
+	_, err = service.Deliver(ctx, inbox.Notification{UserID: "user-1", Title: "Arrived while paging"})
+	require.NoError(t, err)
+	second, err := service.List(ctx, "user-1", inbox.Query{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	last, err := service.List(ctx, "user-1", inbox.Query{Limit: 2, Cursor: second.NextCursor})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, ids[:2], pageIDs(first))
+	assert.Equal(t, ids[2:4], pageIDs(second))
+	assert.Equal(t, ids[4:], pageIDs(last))
+	assert.Empty(t, last.NextCursor)
+	assert.Equal(t, 5, first.UnreadCount)
+	assert.Equal(t, 6, last.UnreadCount)
+}
+
+func TestMarkRead_GivenUnreadNotifications_WhenMarkingRead_ThenUpdatesUnreadCount(t *testing.T) {
+	tests := []struct {
+		name           string
+		act            func(service inbox.Service, ids []string) error
+		expectedUnread int
+		expectedError  error
+	}{
+		{
+			name: "Given an unread notification, When marking it read, Then it no longer counts",
+			act: func(service inbox.Service, ids []string) error {
+				_, err := service.MarkRead(context.Background(), "user-1", ids[0])
+				return err
+			},
+			expectedUnread: 2,
+		},
+		{
+			name: "Given a notification read twice, When marking it read, Then keeps the first read time",
+			act: func(service inbox.Service, ids []string) error {
+				first, err := service.MarkRead(context.Background(), "user-1", ids[0])
+				if err != nil {
+					return err
+				}
+				second, err := service.MarkRead(context.Background(), "user-1", ids[0])
+				if err != nil {
+					return err
+				}
+				if !first.ReadAt.Equal(*second.ReadAt) {
+					return fmt.Errorf("read time moved from %s to %s", first.ReadAt, second.ReadAt)
+				}
+				return nil
+			},
+			expectedUnread: 2,
+		},
+		{
+			name: "Given another user's notification, When marking it read, Then reports it not found",
+			act: func(service inbox.Service, ids []string) error {
+				_, err := service.MarkRead(context.Background(), "user-2", ids[0])
+				return err
+			},
+			expectedUnread: 3,
+			expectedError:  inbox.ErrNotFound,
+		},
+		{
+			name: "Given unread notifications, When marking all read, Then none is left",
+			act: func(service inbox.Service, ids []string) error {
+				count, err := service.MarkAllRead(context.Background(), "user-1")
+				if err == nil && count != 3 {
+					return fmt.Errorf("marked %d notifications read, want 3", count)
+				}
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := memory.NewService()
+			ids := deliverAll(t, service, "user-1", 3)
+
+			// Act
+			err := tt.act(service, ids)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			unread, err := service.UnreadCount(context.Background(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedUnread, unread)
+			page, err := service.List(context.Background(), "user-1", inbox.Query{UnreadOnly: true})
+			require.NoError(t, err)
+			assert.Len(t, page.Notifications, tt.expectedUnread)
+		})
+	}
+}
+
+func TestDeliver_GivenNotificationWithoutTitle_WhenDelivering_ThenRejectsIt(t *testing.T) {
+	// Arrange
+	service := memory.NewService()
+
+	// Act
+	_, err := service.Deliver(context.Background(), inbox.Notification{UserID: "user-1"})
+
+	// Assert
+	var inboxErr inbox.InboxError
+	require.ErrorAs(t, err, &inboxErr)
+	assert.ErrorIs(t, err, inbox.ErrInvalidNotification)
+	assert.Equal(t, "title", inboxErr.Field)
+}
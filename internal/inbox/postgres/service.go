@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+)
+
+// notificationColumns are the in_app_notifications columns scanNotification
+// reads, in order
+const notificationColumns = `id, user_id, title, body, category, link, data, priority, created_at, read_at`
+
+// service implements inbox.Service on the in_app_notifications table
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates Postgres-backed inboxes
+func NewService(pool *pgxpool.Pool) inbox.Service {
+	return &service{pool: pool}
+}
+
+// Deliver inserts a notification into its user's inbox
+func (s *service) Deliver(ctx context.Context, n inbox.Notification) (*inbox.Notification, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO in_app_notifications (`+notificationColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		n.ID, n.UserID, n.Title, n.Body, n.Category, n.Link, data, n.Priority, n.CreatedAt, n.ReadAt)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// List returns a page of the user's notifications, newest first, reading
+// one more than the page to learn whether another follows
+func (s *service) List(ctx context.Context, userID string, query inbox.Query) (*inbox.Page, error) {
+	args := []any{userID}
+	where := `user_id = $1`
+	if query.UnreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+	if query.Cursor != "" {
+		cursor, err := inbox.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		where += fmt.Sprintf(` AND (created_at, id) < ($%d, $%d)`, len(args)-1, len(args))
+	}
+	page := &inbox.Page{Notifications: []inbox.Notification{}, Limit: query.LimitOrDefault()}
+	args = append(args, page.Limit+1)
+
+	rows, err := s.pool.Query(ctx, `SELECT `+notificationColumns+` FROM in_app_notifications WHERE `+where+
+		fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Notifications = append(page.Notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(page.Notifications) > page.Limit {
+		page.Notifications = page.Notifications[:page.Limit]
+		page.NextCursor = inbox.CursorAfter(page.Notifications[page.Limit-1]).Encode()
+	}
+
+	page.UnreadCount, err = s.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// UnreadCount returns the number of notifications the user has not read
+func (s *service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM in_app_notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications read, keeping the time it
+// was first read
+func (s *service) MarkRead(ctx context.Context, userID, id string) (*inbox.Notification, error) {
+	n, err := scanNotification(s.pool.QueryRow(ctx, `
+		UPDATE in_app_notifications SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+notificationColumns,
+		id, userID, time.Now()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, inbox.ErrNotFound
+	}
+	return n, err
+}
+
+// MarkAllRead marks every unread notification of the user read
+func (s *service) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	tag, err := s.pool.Exec(ctx, `UPDATE in_app_notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`,
+		userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// scanNotification reads one notification in notificationColumns order
+func scanNotification(row pgx.Row) (*inbox.Notification, error) {
+	var n inbox.Notification
+	var data []byte
+	if err := row.Scan(&n.ID, &n.UserID, &n.Title, &n.Body, &n.Category, &n.Link, &data, &n.Priority,
+		&n.CreatedAt, &n.ReadAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &n.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode data of notification %s: %w", n.ID, err)
+	}
+	return &n, nil
+}
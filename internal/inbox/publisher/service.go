@@ -0,0 +1,118 @@
+package publisher
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements inbox.Service by publishing an event for every
+// notification delivered and every notification read, so the user's open
+// sessions on any instance update their inbox and unread badge. Publishing
+// failures are logged: the inbox already changed and clients catch up
+// when they list it again.
+type service struct {
+	next    inbox.Service
+	publish PublishFunc
+}
+
+// PublishFunc publishes an event, typically events.Service.Publish
+type PublishFunc func(ctx context.Context, event events.Event) error
+
+// NewService creates inboxes publishing their changes with publish
+func NewService(next inbox.Service, publish PublishFunc) inbox.Service {
+	return &service{next: next, publish: publish}
+}
+
+// Deliver adds the notification and publishes notification.in_app.created
+func (s *service) Deliver(ctx context.Context, n inbox.Notification) (*inbox.Notification, error) {
+	delivered, err := s.next.Deliver(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.next.UnreadCount(ctx, delivered.UserID)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to count unread notifications of user %s: %v", delivered.UserID, err)
+	}
+	s.publishEvent(ctx, events.EventTypeInAppNotificationCreated, delivered.UserID, events.InAppNotificationCreatedData{
+		UserID:         delivered.UserID,
+		NotificationID: delivered.ID,
+		Title:          delivered.Title,
+		Body:           delivered.Body,
+		Category:       delivered.Category,
+		Link:           delivered.Link,
+		Data:           delivered.Data,
+		Priority:       string(delivered.Priority),
+		CreatedAt:      delivered.CreatedAt,
+		UnreadCount:    unread,
+	})
+	return delivered, nil
+}
+
+// List delegates to the next service
+func (s *service) List(ctx context.Context, userID string, query inbox.Query) (*inbox.Page, error) {
+	return s.next.List(ctx, userID, query)
+}
+
+// UnreadCount delegates to the next service
+func (s *service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.UnreadCount(ctx, userID)
+}
+
+// MarkRead marks the notification read and publishes notification.in_app.read
+func (s *service) MarkRead(ctx context.Context, userID, id string) (*inbox.Notification, error) {
+	read, err := s.next.MarkRead(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.publishRead(ctx, userID, events.InAppNotificationsReadData{NotificationIDs: []string{id}, ReadAt: *read.ReadAt})
+	return read, nil
+}
+
+// MarkAllRead marks the notifications read and, when there were unread
+// ones, publishes notification.in_app.read
+func (s *service) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	count, err := s.next.MarkAllRead(ctx, userID)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	s.publishRead(ctx, userID, events.InAppNotificationsReadData{All: true, ReadAt: time.Now()})
+	return count, nil
+}
+
+// publishRead publishes the read notifications with the unread count left;
+// the session that read them is not notified
+func (s *service) publishRead(ctx context.Context, userID string, data events.InAppNotificationsReadData) {
+	unread, err := s.next.UnreadCount(ctx, userID)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to count unread notifications of user %s: %v", userID, err)
+	}
+	data.UserID, data.UnreadCount, data.OriginSessionID = userID, unread, user.SessionIDFromContext(ctx)
+	s.publishEvent(ctx, events.EventTypeInAppNotificationsRead, userID, data)
+}
+
+func (s *service) publishEvent(ctx context.Context, eventType, userID string, payload interface{}) {
+	data, err := events.EncodeData(payload)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	event := events.Event{
+		Type:          eventType,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data:          data,
+		Metadata: events.EventMetadata{
+			UserID: userID,
+			Source: "inbox",
+		},
+	}
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish %s event: %v", eventType, err)
+	}
+}
@@ -0,0 +1,86 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/inbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/inbox/publisher"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// recorder publishes events by recording them, failing with err when set
+type recorder struct {
+	published []events.Event
+	err       error
+}
+
+func (r *recorder) publish(ctx context.Context, event events.Event) error {
+	r.published = append(r.published, event)
+	return r.err
+}
+
+func TestInboxEvents_GivenInboxChanges_WhenApplied_ThenPublishesThemForTheUser(t *testing.T) {
+	// Arrange
+	ctx := user.WithSessionID(context.Background(), "session-1")
+	bus := &recorder{}
+	service := publisher.NewService(memory.NewService(), bus.publish)
+
+	// Act
+	first, err := service.Deliver(ctx, inbox.Notification{UserID: "user-1", Title: "Sale", Link: "/sale"})
+	require.NoError(t, err)
+	_, err = service.Deliver(ctx, inbox.Notification{UserID: "user-1", Title: "Reminder"})
+	require.NoError(t, err)
+	_, err = service.MarkRead(ctx, "user-1", first.ID)
+	require.NoError(t, err)
+	_, err = service.MarkAllRead(ctx, "user-1")
+	require.NoError(t, err)
+	_, err = service.MarkAllRead(ctx, "user-1")
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, bus.published, 4)
+	var created events.InAppNotificationCreatedData
+	require.NoError(t, bus.published[0].DecodeData(&created))
+	assert.Equal(t, events.EventTypeInAppNotificationCreated, bus.published[0].Type)
+	assert.Equal(t, "user-1", bus.published[0].AggregateID)
+	assert.Equal(t, first.ID, created.NotificationID)
+	assert.Equal(t, "/sale", created.Link)
+	assert.Equal(t, 1, created.UnreadCount)
+
+	var read events.InAppNotificationsReadData
+	require.NoError(t, bus.published[2].DecodeData(&read))
+	assert.Equal(t, events.EventTypeInAppNotificationsRead, bus.published[2].Type)
+	assert.Equal(t, []string{first.ID}, read.NotificationIDs)
+	assert.Equal(t, 1, read.UnreadCount)
+	assert.Equal(t, "session-1", read.OriginSessionID)
+
+	var all events.InAppNotificationsReadData
+	require.NoError(t, bus.published[3].DecodeData(&all))
+	assert.True(t, all.All)
+	assert.Equal(t, 0, all.UnreadCount)
+}
+
+func TestInboxEvents_GivenFailingPublisher_WhenDelivering_ThenStillDelivers(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := &recorder{err: errors.New("broker unavailable")}
+	inboxes := memory.NewService()
+	service := publisher.NewService(inboxes, bus.publish)
+
+	// Act
+	delivered, err := service.Deliver(ctx, inbox.Notification{UserID: "user-1", Title: "Sale"})
+
+	// Assert
+	require.NoError(t, err)
+	page, err := inboxes.List(ctx, "user-1", inbox.Query{})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 1)
+	assert.Equal(t, delivered.ID, page.Notifications[0].ID)
+}
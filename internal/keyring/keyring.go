@@ -0,0 +1,55 @@
+package keyring
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the key ring domain interface - the ONLY interface in this domain.
+// A key ring holds every version of the keys for a purpose so data written
+// under a previous key stays readable after rotation.
+type Service interface {
+	// Current returns the key new data for the purpose must be written with
+	Current(ctx context.Context, purpose string) (*Key, error)
+
+	// Get returns a specific key version, including retired ones
+	Get(ctx context.Context, purpose, keyID string) (*Key, error)
+
+	// Keys returns every version for the purpose, newest first
+	Keys(ctx context.Context, purpose string) ([]Key, error)
+
+	// Rotate creates a new current key for the purpose, keeping older versions
+	Rotate(ctx context.Context, purpose string) (*Key, error)
+
+	// Purposes returns the purposes that have keys
+	Purposes(ctx context.Context) ([]string, error)
+}
+
+// Domain types and data structures
+
+// Key is one version of a purpose key
+type Key struct {
+	ID        string    `json:"id"`
+	Purpose   string    `json:"purpose"`
+	Material  []byte    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KeySize is the size of key material in bytes (AES-256)
+const KeySize = 32
+
+// KeyRingError represents key ring domain errors
+type KeyRingError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e KeyRingError) Error() string {
+	return e.Message
+}
+
+// Common key ring errors
+var (
+	ErrKeyNotFound        = KeyRingError{Code: "KEY_NOT_FOUND", Message: "Key not found in key ring"}
+	ErrInvalidKeyMaterial = KeyRingError{Code: "INVALID_KEY_MATERIAL", Message: "Key material must be 32 bytes"}
+)
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+)
+
+// service implements keyring.Service in memory. Initial keys are derived from
+// a master secret so they survive restarts; rotated keys are random and only
+// live as long as the process, so production deployments should persist them.
+type service struct {
+	mu     sync.RWMutex
+	master []byte
+	keys   map[string][]keyring.Key // purpose -> versions, oldest first
+}
+
+// NewService creates a key ring whose first key for every purpose is derived
+// from the master secret with HKDF-SHA256
+func NewService(master []byte) (keyring.Service, error) {
+	if len(master) != keyring.KeySize {
+		return nil, keyring.ErrInvalidKeyMaterial
+	}
+
+	return &service{
+		master: master,
+		keys:   make(map[string][]keyring.Key),
+	}, nil
+}
+
+// Current returns the newest key for the purpose, deriving the first one on demand
+func (s *service) Current(ctx context.Context, purpose string) (*keyring.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.versions(purpose)
+	if err != nil {
+		return nil, err
+	}
+	key := versions[len(versions)-1]
+	return &key, nil
+}
+
+// Get returns a key version by ID
+func (s *service) Get(ctx context.Context, purpose, keyID string) (*keyring.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.versions(purpose)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range versions {
+		if key.ID == keyID {
+			found := key
+			return &found, nil
+		}
+	}
+	return nil, keyring.ErrKeyNotFound
+}
+
+// Keys returns every version for the purpose, newest first
+func (s *service) Keys(ctx context.Context, purpose string) ([]keyring.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.versions(purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]keyring.Key, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		result = append(result, versions[i])
+	}
+	return result, nil
+}
+
+// Rotate adds a random key as the new current version
+func (s *service) Rotate(ctx context.Context, purpose string) (*keyring.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.versions(purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	material := make([]byte, keyring.KeySize)
+	if _, err := rand.Read(material); err != nil {
+		return nil, fmt.Errorf("failed to generate key for purpose '%s': %w", purpose, err)
+	}
+
+	key := keyring.Key{
+		ID:        fmt.Sprintf("v%d", len(versions)+1),
+		Purpose:   purpose,
+		Material:  material,
+		CreatedAt: time.Now(),
+	}
+	s.keys[purpose] = append(versions, key)
+	return &key, nil
+}
+
+// Purposes returns the purposes that have keys, sorted
+func (s *service) Purposes(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	purposes := make([]string, 0, len(s.keys))
+	for purpose := range s.keys {
+		purposes = append(purposes, purpose)
+	}
+	sort.Strings(purposes)
+	return purposes, nil
+}
+
+// Helper methods
+
+// versions returns the keys for a purpose, deriving the first version from the
+// master secret if none exist yet. Callers must hold the write lock.
+func (s *service) versions(purpose string) ([]keyring.Key, error) {
+	if versions, ok := s.keys[purpose]; ok {
+		return versions, nil
+	}
+
+	material, err := hkdf.Key(sha256.New, s.master, nil, "keyring:"+purpose, keyring.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key for purpose '%s': %w", purpose, err)
+	}
+
+	versions := []keyring.Key{{
+		ID:        "v1",
+		Purpose:   purpose,
+		Material:  material,
+		CreatedAt: time.Now(),
+	}}
+	s.keys[purpose] = versions
+	return versions, nil
+}
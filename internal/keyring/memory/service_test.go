@@ -0,0 +1,68 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+	"github.com/gentra/decorator-arch-go/internal/keyring/memory"
+)
+
+var master = bytes.Repeat([]byte{7}, keyring.KeySize)
+
+func TestKeyRing_GivenSameMaster_WhenCreatingRings_ThenDerivesSameInitialKeys(t *testing.T) {
+	ctx := context.Background()
+	first, err := memory.NewService(master)
+	require.NoError(t, err)
+	second, err := memory.NewService(master)
+	require.NoError(t, err)
+
+	a, err := first.Current(ctx, "user.email")
+	require.NoError(t, err)
+	b, err := second.Current(ctx, "user.email")
+	require.NoError(t, err)
+	other, err := first.Current(ctx, "user.name")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1", a.ID)
+	assert.Equal(t, a.Material, b.Material)
+	assert.NotEqual(t, a.Material, other.Material, "purposes must not share keys")
+}
+
+func TestKeyRing_GivenRotation_WhenReadingKeys_ThenKeepsOlderVersions(t *testing.T) {
+	ctx := context.Background()
+	ring, err := memory.NewService(master)
+	require.NoError(t, err)
+	original, err := ring.Current(ctx, "user.email")
+	require.NoError(t, err)
+
+	rotated, err := ring.Rotate(ctx, "user.email")
+	require.NoError(t, err)
+
+	current, err := ring.Current(ctx, "user.email")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", rotated.ID)
+	assert.Equal(t, rotated.ID, current.ID)
+
+	old, err := ring.Get(ctx, "user.email", original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, original.Material, old.Material)
+
+	keys, err := ring.Keys(ctx, "user.email")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{"v2", "v1"}, []string{keys[0].ID, keys[1].ID})
+
+	_, err = ring.Get(ctx, "user.email", "v9")
+	assert.ErrorIs(t, err, keyring.ErrKeyNotFound)
+}
+
+func TestKeyRing_GivenShortMaster_WhenCreating_ThenReturnsError(t *testing.T) {
+	_, err := memory.NewService([]byte("too-short"))
+
+	assert.ErrorIs(t, err, keyring.ErrInvalidKeyMaterial)
+}
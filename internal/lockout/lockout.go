@@ -0,0 +1,27 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the lockout domain interface - the ONLY interface in this domain.
+// It counts failed attempts per key, such as an email or an IP address, and
+// remembers which keys are locked until when.
+type Service interface {
+	// RecordFailure counts a failed attempt against key and returns how many
+	// failures it collected since the first one, counting this one. The count
+	// starts over once window has passed since that first failure.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+
+	// Lock locks key for duration and starts its failure count over, so the
+	// key gets the full number of attempts once the lock lifts by itself
+	Lock(ctx context.Context, key string, duration time.Duration) error
+
+	// LockedUntil returns when the lock on key lifts, or the zero time when
+	// key is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+
+	// Reset lifts the lock on key and forgets its failed attempts
+	Reset(ctx context.Context, key string) error
+}
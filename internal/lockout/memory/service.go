@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/lockout"
+)
+
+// service implements lockout.Service in memory, for single instances and tests
+type service struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is the state kept for one key
+type entry struct {
+	failures    int
+	windowEnds  time.Time
+	lockedUntil time.Time
+}
+
+// NewService creates an in-memory lockout store
+func NewService() lockout.Service {
+	return &service{entries: make(map[string]entry)}
+}
+
+// RecordFailure counts the failure in the key's current window
+func (s *service) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e := s.entries[key]
+	if !now.Before(e.windowEnds) {
+		e.failures = 0
+		e.windowEnds = now.Add(window)
+	}
+	e.failures++
+	s.entries[key] = e
+	s.evictExpired(now)
+	return e.failures, nil
+}
+
+// Lock locks the key until duration elapses and forgets its failures
+func (s *service) Lock(ctx context.Context, key string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry{lockedUntil: time.Now().Add(duration)}
+	return nil
+}
+
+// LockedUntil returns the end of the key's lock while it lasts
+func (s *service) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.lockedUntil) {
+		return time.Time{}, nil
+	}
+	return e.lockedUntil, nil
+}
+
+// Reset drops everything kept for the key
+func (s *service) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Helper methods
+
+// evictExpired drops keys whose window and lock have both passed, so the map
+// does not grow without bound; the caller must hold the lock
+func (s *service) evictExpired(now time.Time) {
+	for key, e := range s.entries {
+		if !now.Before(e.windowEnds) && !now.Before(e.lockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
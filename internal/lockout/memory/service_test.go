@@ -0,0 +1,83 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/lockout/memory"
+)
+
+func TestLockout_GivenFailuresInWindow_WhenRecording_ThenCountsThem(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+
+	_, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+	require.NoError(t, err)
+	failures, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, failures)
+}
+
+func TestLockout_GivenExpiredWindow_WhenRecording_ThenStartsOver(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.RecordFailure(ctx, "email:jane@example.com", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	failures, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+}
+
+func TestLockout_GivenLockedKey_WhenCoolDownPasses_ThenUnlocks(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	require.NoError(t, store.Lock(ctx, "ip:203.0.113.7", 10*time.Millisecond))
+
+	lockedUntil, err := store.LockedUntil(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, lockedUntil.IsZero())
+
+	time.Sleep(20 * time.Millisecond)
+	lockedUntil, err = store.LockedUntil(ctx, "ip:203.0.113.7")
+
+	require.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+}
+
+func TestLockout_GivenLockedKey_WhenResetting_ThenForgetsLockAndFailures(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Lock(ctx, "email:jane@example.com", time.Minute))
+
+	require.NoError(t, store.Reset(ctx, "email:jane@example.com"))
+
+	lockedUntil, err := store.LockedUntil(ctx, "email:jane@example.com")
+	require.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+	failures, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+}
+
+func TestLockout_GivenFailures_WhenLocking_ThenStartsCountingOver(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Lock(ctx, "email:jane@example.com", time.Minute))
+	failures, err := store.RecordFailure(ctx, "email:jane@example.com", time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/lockout"
+)
+
+// DefaultKeyPrefix namespaces lockout state in a shared Redis
+const DefaultKeyPrefix = "lockout:"
+
+// service implements lockout.Service on Redis, so every instance sees the
+// same failures and locks; both expire with their key TTL
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed lockout store using DefaultKeyPrefix
+func NewService(client *redis.Client) lockout.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed lockout store whose keys start
+// with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) lockout.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// RecordFailure increments the failure counter, which expires window after
+// its first failure
+func (s *service) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, s.failuresKey(key))
+		pipe.ExpireNX(ctx, s.failuresKey(key), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// Lock stores when the lock lifts in a key expiring at that time and deletes
+// the failure counter
+func (s *service) Lock(ctx context.Context, key string, duration time.Duration) error {
+	until := time.Now().Add(duration)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.lockedKey(key), until.UnixMilli(), duration)
+		pipe.Del(ctx, s.failuresKey(key))
+		return nil
+	})
+	return err
+}
+
+// LockedUntil reads the end of the lock, if the key holding it still exists
+func (s *service) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	value, err := s.client.Get(ctx, s.lockedKey(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(millis), nil
+}
+
+// Reset deletes the failure counter and the lock
+func (s *service) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.failuresKey(key), s.lockedKey(key)).Err()
+}
+
+// Helper methods
+
+// failuresKey returns the Redis key counting the failures of key
+func (s *service) failuresKey(key string) string {
+	return s.prefix + "failures:" + key
+}
+
+// lockedKey returns the Redis key holding the lock of key
+func (s *service) lockedKey(key string) string {
+	return s.prefix + "locked:" + key
+}
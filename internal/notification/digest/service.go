@@ -0,0 +1,186 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/dispatcher"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config controls how notifications are held for digests
+type Config struct {
+	// Preferences looks up the users' timezone, in which daily digests are
+	// due, and the notification types they turned off, which are never
+	// held; nil holds in UTC
+	Preferences dispatcher.PreferenceLookup
+
+	// Now returns the current time; time.Now when nil
+	Now func() time.Time
+}
+
+// service implements notification.Service by holding the push
+// notifications of the types users opted into their digest, instead of
+// sending them, until the digest worker sends them as one email. Urgent
+// notifications, dry runs and notifications without a type are sent at
+// once, and so is every notification whose digest settings cannot be read
+// or held, rather than being lost.
+type service struct {
+	next    notification.Service
+	digests notificationdigest.Service
+	config  Config
+}
+
+// NewService creates a notification service holding notifications for digests
+func NewService(next notification.Service, digests notificationdigest.Service, config Config) notification.Service {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &service{next: next, digests: digests, config: config}
+}
+
+// SendWelcomeEmail delegates to the next service
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	return s.next.SendWelcomeEmail(ctx, userEmail, userName)
+}
+
+// SendPasswordResetEmail delegates to the next service
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return s.next.SendPasswordResetEmail(ctx, userEmail, resetToken)
+}
+
+// SendProfileUpdateNotification delegates to the next service
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	return s.next.SendProfileUpdateNotification(ctx, userID, changes)
+}
+
+// SendVerificationEmail delegates to the next service
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	return s.next.SendVerificationEmail(ctx, userEmail, verificationToken)
+}
+
+// SendNewDeviceLoginEmail delegates to the next service
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	return s.next.SendNewDeviceLoginEmail(ctx, userEmail, login)
+}
+
+// SendMagicLinkEmail delegates to the next service
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	return s.next.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+}
+
+// SendPushNotification holds the notification for the user's digest, or
+// else sends it on
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	if userID == "" {
+		userID = push.UserID
+	}
+	if s.hold(ctx, userID, push) {
+		return nil
+	}
+	return s.next.SendPushNotification(ctx, userID, push)
+}
+
+// SendSMSNotification delegates to the next service
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	return s.next.SendSMSNotification(ctx, phoneNumber, message)
+}
+
+// SendBulkEmail delegates to the next service
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	return s.next.SendBulkEmail(ctx, emails)
+}
+
+// SendBulkPush holds the notifications their users collect in digests and
+// sends the others on
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	send := make([]notification.PushNotification, 0, len(notifications))
+	for _, push := range notifications {
+		if !s.hold(ctx, push.UserID, push) {
+			send = append(send, push)
+		}
+	}
+	if len(send) == 0 {
+		return nil
+	}
+	return s.next.SendBulkPush(ctx, send)
+}
+
+// GetNotificationHistory delegates to the next service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the next service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount delegates to the next service
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.GetUnreadCount(ctx, userID)
+}
+
+// hold adds the notification to the user's digest when the user collects
+// its type there, reporting whether it did
+func (s *service) hold(ctx context.Context, userID string, push notification.PushNotification) bool {
+	if userID == "" || push.Category == "" || push.Priority == notification.PriorityUrgent || notification.IsDryRun(ctx) {
+		return false
+	}
+	settings, err := s.digests.GetSettings(ctx, userID)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to load the digest settings of user %s: %v", userID, err)
+		return false
+	}
+	if !settings.Collects(push.Category, push.Priority) {
+		return false
+	}
+
+	timezone, accepted := s.preferences(ctx, userID, push.Category)
+	if !accepted {
+		return false
+	}
+	dueAt, err := settings.NextDelivery(s.config.Now(), timezone)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to resolve the digest time of user %s in %q, using UTC: %v", userID, timezone, err)
+		if dueAt, err = settings.NextDelivery(s.config.Now(), ""); err != nil {
+			return false
+		}
+	}
+
+	_, err = s.digests.Hold(ctx, notificationdigest.Item{
+		UserID:   userID,
+		Type:     push.Category,
+		Title:    push.Title,
+		Body:     push.Body,
+		Data:     push.Data,
+		Priority: push.Priority,
+	}, dueAt)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to hold a notification for the digest of user %s: %v", userID, err)
+		return false
+	}
+	return true
+}
+
+// preferences returns the user's timezone and whether the user takes
+// notifications of the type at all; those turned off are left to the
+// dispatcher to drop
+func (s *service) preferences(ctx context.Context, userID, notificationType string) (string, bool) {
+	if s.config.Preferences == nil {
+		return "", true
+	}
+	prefs, err := s.config.Preferences(ctx, userID)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to load notification preferences of user %s: %v", userID, err)
+		return "", true
+	}
+	if prefs == nil {
+		return "", true
+	}
+	enabled, known := prefs.NotificationTypes[user.NotificationType(notificationType)]
+	return prefs.Timezone, !known || enabled
+}
@@ -0,0 +1,136 @@
+package digest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/digest"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	digestMemory "github.com/gentra/decorator-arch-go/internal/notificationdigest/memory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// pushRecorder records the push notifications sent on
+type pushRecorder struct {
+	notification.Service
+	sent []string
+}
+
+func (p *pushRecorder) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	p.sent = append(p.sent, push.Title)
+	return nil
+}
+
+func (p *pushRecorder) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	for _, push := range notifications {
+		p.sent = append(p.sent, push.Title)
+	}
+	return nil
+}
+
+func TestSendPushNotification_GivenDigestSettings_WhenSending_ThenHoldsOptedInNotifications(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          func() context.Context
+		push         notification.PushNotification
+		prefs        user.UserPreferences
+		expectedSent []string
+		expectedHeld []string
+	}{
+		{
+			name:         "Given an opted-in type, When sending, Then holds it for the digest",
+			ctx:          context.Background,
+			push:         notification.PushNotification{Title: "Spring sale", Category: "marketing", Priority: notification.PriorityLow},
+			expectedHeld: []string{"Spring sale"},
+		},
+		{
+			name:         "Given an urgent notification, When sending, Then sends it at once",
+			ctx:          context.Background,
+			push:         notification.PushNotification{Title: "Flash sale ends", Category: "marketing", Priority: notification.PriorityUrgent},
+			expectedSent: []string{"Flash sale ends"},
+		},
+		{
+			name:         "Given a type not opted in, When sending, Then sends it at once",
+			ctx:          context.Background,
+			push:         notification.PushNotification{Title: "Task assigned", Category: "task_assigned"},
+			expectedSent: []string{"Task assigned"},
+		},
+		{
+			name:         "Given a type the user turned off, When sending, Then leaves it to the dispatcher",
+			ctx:          context.Background,
+			push:         notification.PushNotification{Title: "Spring sale", Category: "marketing"},
+			prefs:        user.UserPreferences{NotificationTypes: map[user.NotificationType]bool{"marketing": false}},
+			expectedSent: []string{"Spring sale"},
+		},
+		{
+			name:         "Given a dry-run request, When sending, Then sends it on",
+			ctx:          func() context.Context { return notification.WithDryRun(context.Background()) },
+			push:         notification.PushNotification{Title: "Spring sale", Category: "marketing"},
+			expectedSent: []string{"Spring sale"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			digests := digestMemory.NewService()
+			_, err := digests.UpdateSettings(context.Background(), notificationdigest.Settings{
+				UserID: "user-1", Frequency: notificationdigest.FrequencyHourly, Types: []string{"marketing"},
+			})
+			require.NoError(t, err)
+			sender := &pushRecorder{Service: notificationMock.NewService()}
+			prefs := tt.prefs
+			service := digest.NewService(sender, digests, digest.Config{
+				Preferences: func(ctx context.Context, userID string) (*user.UserPreferences, error) { return &prefs, nil },
+				Now:         func() time.Time { return time.Date(2026, 3, 2, 12, 25, 0, 0, time.UTC) },
+			})
+
+			// Act
+			err = service.SendPushNotification(tt.ctx(), "user-1", tt.push)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSent, sender.sent)
+			pending, err := digests.Pending(context.Background(), "user-1")
+			if tt.expectedHeld == nil {
+				assert.ErrorIs(t, err, notificationdigest.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, pending.Items, len(tt.expectedHeld))
+			assert.Equal(t, tt.expectedHeld[0], pending.Items[0].Title)
+			assert.Equal(t, time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC), pending.DueAt)
+		})
+	}
+}
+
+func TestSendBulkPush_GivenMixedNotifications_WhenSending_ThenSendsOnlyThoseNotHeld(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	digests := digestMemory.NewService()
+	_, err := digests.UpdateSettings(ctx, notificationdigest.Settings{
+		UserID: "user-1", Frequency: notificationdigest.FrequencyDaily, Types: []string{"marketing"},
+	})
+	require.NoError(t, err)
+	sender := &pushRecorder{Service: notificationMock.NewService()}
+	service := digest.NewService(sender, digests, digest.Config{})
+
+	// Act
+	err = service.SendBulkPush(ctx, []notification.PushNotification{
+		{UserID: "user-1", Title: "Spring sale", Category: "marketing"},
+		{UserID: "user-2", Title: "Spring sale", Category: "marketing"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Spring sale"}, sender.sent)
+	pending, err := digests.Pending(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, pending.Items, 1)
+}
@@ -0,0 +1,110 @@
+package dispatcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// spans are the windows a notification.RateLimit caps, in its field order
+var spans = [3]struct {
+	name string
+	size time.Duration
+}{
+	{"minute", time.Minute},
+	{"hour", time.Hour},
+	{"day", 24 * time.Hour},
+}
+
+// limiter enforces a notification.RateLimit per key with sliding window
+// counters: each window keeps the count of the current fixed window and of
+// the one before it, weighted by how much of it the sliding window still
+// covers. That bounds memory to a few counters per key, where a log of send
+// times would keep up to MaxPerDay of them.
+type limiter struct {
+	mu    sync.Mutex
+	keys  map[string]*usage
+	swept time.Time
+}
+
+// usage is the sends counted for one key
+type usage struct {
+	windows [3]window
+	last    time.Time
+}
+
+// window counts sends in fixed windows of its span starting at start
+type window struct {
+	start    time.Time
+	current  int
+	previous int
+}
+
+func newLimiter() *limiter {
+	return &limiter{keys: make(map[string]*usage)}
+}
+
+// allow counts a send for the key, or returns notification.ErrRateLimited
+// naming the limit it would exceed without counting it
+func (l *limiter) allow(key string, limit notification.RateLimit, now time.Time) error {
+	maxima := [3]int{limit.MaxPerMinute, limit.MaxPerHour, limit.MaxPerDay}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	u, ok := l.keys[key]
+	if !ok {
+		u = &usage{}
+		l.keys[key] = u
+	}
+	for i, span := range spans {
+		u.windows[i].roll(now, span.size)
+		if maxima[i] > 0 && u.windows[i].estimate(now, span.size)+1 > float64(maxima[i]) {
+			return notification.NotificationError{
+				Code:    notification.ErrRateLimited.Code,
+				Message: fmt.Sprintf("Limit of %d notifications per %s reached", maxima[i], span.name),
+			}
+		}
+	}
+	for i := range u.windows {
+		u.windows[i].current++
+	}
+	u.last = now
+	return nil
+}
+
+// sweep forgets keys unused for two days, whose counts no longer matter,
+// at most once an hour
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Hour {
+		return
+	}
+	l.swept = now
+	for key, u := range l.keys {
+		if now.Sub(u.last) >= 2*spans[len(spans)-1].size {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// roll moves the window forward to the fixed window holding now
+func (w *window) roll(now time.Time, size time.Duration) {
+	elapsed := now.Sub(w.start)
+	switch {
+	case w.start.IsZero() || elapsed >= 2*size:
+		w.start = now.Truncate(size)
+		w.current, w.previous = 0, 0
+	case elapsed >= size:
+		w.start = w.start.Add(size)
+		w.current, w.previous = 0, w.current
+	}
+}
+
+// estimate is the count over the sliding window ending now
+func (w *window) estimate(now time.Time, size time.Duration) float64 {
+	covered := 1 - float64(now.Sub(w.start))/float64(size)
+	return float64(w.previous)*covered + float64(w.current)
+}
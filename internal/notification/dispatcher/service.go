@@ -0,0 +1,374 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// PreferenceLookup returns a user's preferences; nil preferences send every
+// notification
+type PreferenceLookup func(ctx context.Context, userID string) (*user.UserPreferences, error)
+
+// Config selects the providers of each channel and the rules applied before
+// handing them a notification
+type Config struct {
+	// Email, Push and SMS send the notifications of their channel; nil
+	// channels use the decorated service
+	Email notification.Service
+	Push  notification.Service
+	SMS   notification.Service
+
+	// Preferences looks up whether users accept a channel; nil sends
+	// regardless of preferences
+	Preferences PreferenceLookup
+
+	// RateLimits caps the notifications each recipient gets per channel,
+	// keyed "email", "push" and "sms"; channels without one are not limited
+	RateLimits map[string]notification.RateLimit
+
+	// Retry retries sends failing for other reasons than the notification
+	// itself; zero MaxRetries sends once
+	Retry notification.RetryConfig
+
+	// History records every notification handed to a provider, sent or
+	// failed, under the user it was for; nil records none
+	History notificationhistory.Service
+
+	// SMSRecordedByProvider leaves SMS out of History for an SMS provider
+	// recording them itself, as the twilio provider does under the message
+	// SID its delivery reports refer to
+	SMSRecordedByProvider bool
+}
+
+// service orchestrates the notification channels. Each notification goes to
+// its channel's provider unless the recipient turned the channel off in
+// their preferences, or already got RateLimits of them on the channel within
+// the last minute, hour or day. Account emails such as password resets are
+// always sent: users cannot turn them off. Sends failing for reasons other
+// than a notification.NotificationError are retried with backoff. Once
+// handed to their provider, notifications are recorded in History with
+// whether they were sent; those held back, captured by a dry run or recorded
+// by their sender, as scheduled ones are, are not.
+// Emails are recorded under the user tagged with notification.WithRecipient.
+// Notification history and read state go to the decorated service.
+type service struct {
+	next    notification.Service
+	email   notification.Service
+	push    notification.Service
+	sms     notification.Service
+	config  Config
+	limiter *limiter
+}
+
+// NewService creates a dispatcher in front of next
+func NewService(next notification.Service, config Config) notification.Service {
+	s := &service{next: next, email: next, push: next, sms: next, config: config, limiter: newLimiter()}
+	if config.Email != nil {
+		s.email = config.Email
+	}
+	if config.Push != nil {
+		s.push = config.Push
+	}
+	if config.SMS != nil {
+		s.sms = config.SMS
+	}
+	return s
+}
+
+// Channels of RateLimits
+const (
+	channelEmail = "email"
+	channelPush  = "push"
+	channelSMS   = "sms"
+)
+
+// SendWelcomeEmail sends the welcome email
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	return s.dispatch(ctx, channelEmail, userEmail, emailEntry("welcome_email", userEmail), func(ctx context.Context) error {
+		return s.email.SendWelcomeEmail(ctx, userEmail, userName)
+	})
+}
+
+// SendPasswordResetEmail sends the password reset email
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return s.dispatch(ctx, channelEmail, userEmail, emailEntry("password_reset_email", userEmail), func(ctx context.Context) error {
+		return s.email.SendPasswordResetEmail(ctx, userEmail, resetToken)
+	})
+}
+
+// SendProfileUpdateNotification emails the user about profile changes when
+// they accept email notifications
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	if ok, err := s.accepts(ctx, userID, channelEmail, ""); !ok || err != nil {
+		return err
+	}
+	entry := notification.NotificationHistory{UserID: userID, Data: map[string]interface{}{"kind": "profile_update"}}
+	return s.dispatch(ctx, channelEmail, userID, entry, func(ctx context.Context) error {
+		return s.email.SendProfileUpdateNotification(ctx, userID, changes)
+	})
+}
+
+// SendVerificationEmail sends the email verification email
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	return s.dispatch(ctx, channelEmail, userEmail, emailEntry("verification_email", userEmail), func(ctx context.Context) error {
+		return s.email.SendVerificationEmail(ctx, userEmail, verificationToken)
+	})
+}
+
+// SendNewDeviceLoginEmail sends the new device sign-in alert
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	return s.dispatch(ctx, channelEmail, userEmail, emailEntry("new_device_login_email", userEmail), func(ctx context.Context) error {
+		return s.email.SendNewDeviceLoginEmail(ctx, userEmail, login)
+	})
+}
+
+// SendMagicLinkEmail sends the magic link sign-in email
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	return s.dispatch(ctx, channelEmail, userEmail, emailEntry("magic_link_email", userEmail), func(ctx context.Context) error {
+		return s.email.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+	})
+}
+
+// SendPushNotification sends the push notification when the user accepts
+// push notifications and its category
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	if ok, err := s.accepts(ctx, userID, channelPush, push.Category); !ok || err != nil {
+		return err
+	}
+	return s.dispatch(ctx, channelPush, userID, pushEntry(userID, push), func(ctx context.Context) error {
+		return s.push.SendPushNotification(ctx, userID, push)
+	})
+}
+
+// SendSMSNotification sends the SMS, checking the preferences of the user
+// tagged with notification.WithRecipient. Untagged SMS, such as sign-in
+// codes, are always sent.
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	if userID := notification.RecipientFromContext(ctx); userID != "" {
+		if ok, err := s.accepts(ctx, userID, channelSMS, ""); !ok || err != nil {
+			return err
+		}
+	}
+	entry := notification.NotificationHistory{Body: message, Data: map[string]interface{}{"kind": "sms", "to": phoneNumber}}
+	return s.dispatch(ctx, channelSMS, phoneNumber, entry, func(ctx context.Context) error {
+		return s.sms.SendSMSNotification(ctx, phoneNumber, message)
+	})
+}
+
+// SendBulkEmail sends the emails whose recipients are within their rate
+// limit, and reports the others with notification.ErrRateLimited
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	allowed := make([]notification.EmailNotification, 0, len(emails))
+	for _, email := range emails {
+		if s.allow(channelEmail, email.To) == nil {
+			allowed = append(allowed, email)
+		}
+	}
+	if len(allowed) > 0 {
+		err := s.retry(ctx, func(ctx context.Context) error { return s.email.SendBulkEmail(ctx, allowed) })
+		for _, email := range allowed {
+			entry := emailEntry("bulk_email", email.To)
+			entry.Title, entry.Body, entry.Priority = email.Subject, email.Body, email.Priority
+			s.record(ctx, channelEmail, entry, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return heldBack(len(emails) - len(allowed))
+}
+
+// SendBulkPush sends the push notifications their users accept and are
+// within their rate limit, and reports those held back by rate limits with
+// notification.ErrRateLimited
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	allowed := make([]notification.PushNotification, 0, len(notifications))
+	limited := 0
+	for _, push := range notifications {
+		ok, err := s.accepts(ctx, push.UserID, channelPush, push.Category)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if s.allow(channelPush, push.UserID) != nil {
+			limited++
+			continue
+		}
+		allowed = append(allowed, push)
+	}
+	if len(allowed) > 0 {
+		err := s.retry(ctx, func(ctx context.Context) error { return s.push.SendBulkPush(ctx, allowed) })
+		for _, push := range allowed {
+			s.record(ctx, channelPush, pushEntry(push.UserID, push), err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return heldBack(limited)
+}
+
+// GetNotificationHistory delegates to the decorated service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the decorated service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount delegates to the decorated service
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.GetUnreadCount(ctx, userID)
+}
+
+// accepts reports whether the user takes notifications on the channel and,
+// when the notification has a category naming a notification type, of
+// that type
+func (s *service) accepts(ctx context.Context, userID, channel, category string) (bool, error) {
+	if s.config.Preferences == nil || userID == "" {
+		return true, nil
+	}
+	prefs, err := s.config.Preferences(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load notification preferences of user %s: %w", userID, err)
+	}
+	if prefs == nil {
+		return true, nil
+	}
+
+	accepted := map[string]bool{
+		channelEmail: prefs.EmailNotifications,
+		channelPush:  prefs.PushNotifications,
+		channelSMS:   prefs.SMSNotifications,
+	}[channel]
+	if enabled, known := prefs.NotificationTypes[user.NotificationType(category)]; category != "" && known && !enabled {
+		accepted = false
+	}
+	if !accepted {
+		correlation.Logf(ctx, "Skipping %s notification for user %s: turned off in their preferences", channel, userID)
+	}
+	return accepted, nil
+}
+
+// dispatch sends a notification to the recipient within the channel's rate
+// limit, retrying failures, and records the outcome as entry
+func (s *service) dispatch(ctx context.Context, channel, recipient string, entry notification.NotificationHistory, send func(ctx context.Context) error) error {
+	if err := s.allow(channel, recipient); err != nil {
+		return err
+	}
+	err := s.retry(ctx, send)
+	s.record(ctx, channel, entry, err)
+	return err
+}
+
+// record stores a notification handed to the channel's provider in the
+// history, as sent or, with the error, failed. The notification is already
+// with the provider, so history failures are logged rather than returned for
+// callers not to send it again.
+func (s *service) record(ctx context.Context, channel string, entry notification.NotificationHistory, sendErr error) {
+	if s.config.History == nil || notification.IsDryRun(ctx) || notification.IsRecorded(ctx) ||
+		(channel == channelSMS && s.config.SMSRecordedByProvider) {
+		return
+	}
+
+	entry.Type = notification.NotificationType(channel)
+	if entry.UserID == "" {
+		entry.UserID = notification.RecipientFromContext(ctx)
+	}
+	if entry.Priority == "" {
+		entry.Priority = notification.PriorityNormal
+	}
+	entry.Status = notification.NotificationStatusPending
+	update := notificationhistory.StatusUpdate{Status: notification.NotificationStatusSent}
+	if sendErr != nil {
+		update = notificationhistory.StatusUpdate{Status: notification.NotificationStatusFailed, Error: sendErr.Error()}
+	}
+	update.Apply(&entry)
+
+	if _, err := s.config.History.Record(ctx, entry); err != nil {
+		correlation.Logf(ctx, "Failed to record %s notification in the history: %v", channel, err)
+	}
+}
+
+// allow counts a notification to the recipient against the channel's limit
+func (s *service) allow(channel, recipient string) error {
+	limit, ok := s.config.RateLimits[channel]
+	if !ok {
+		return nil
+	}
+	return s.limiter.allow(channel+":"+recipient, limit, time.Now())
+}
+
+// retry calls send until it succeeds, waiting InitialDelay and then growing
+// by BackoffFactor up to MaxDelay between attempts. Notification errors,
+// such as an invalid recipient, fail the same way every time and are not
+// retried.
+func (s *service) retry(ctx context.Context, send func(ctx context.Context) error) error {
+	retry := s.config.Retry
+	delay := retry.InitialDelay
+	for attempt := 0; ; attempt++ {
+		err := send(ctx)
+		var notificationErr notification.NotificationError
+		if err == nil || attempt >= retry.MaxRetries || errors.As(err, &notificationErr) {
+			return err
+		}
+		correlation.Logf(ctx, "Notification failed, retrying in %s: %v", delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+
+		if retry.BackoffFactor > 1 {
+			delay = time.Duration(float64(delay) * retry.BackoffFactor)
+		}
+		if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+}
+
+// emailEntry is the history entry of an email of the kind; the content is
+// rendered by the provider, and tokens it carries are left out
+func emailEntry(kind, to string) notification.NotificationHistory {
+	return notification.NotificationHistory{Data: map[string]interface{}{"kind": kind, "to": to}}
+}
+
+// pushEntry is the history entry of a push notification to the user
+func pushEntry(userID string, push notification.PushNotification) notification.NotificationHistory {
+	if userID == "" {
+		userID = push.UserID
+	}
+	return notification.NotificationHistory{
+		UserID:   userID,
+		Title:    push.Title,
+		Body:     push.Body,
+		Data:     map[string]interface{}{"kind": "push", "category": push.Category},
+		Priority: push.Priority,
+	}
+}
+
+// heldBack reports the notifications of a bulk send held back by rate limits
+func heldBack(count int) error {
+	if count == 0 {
+		return nil
+	}
+	return notification.NotificationError{
+		Code:    notification.ErrRateLimited.Code,
+		Message: fmt.Sprintf("%d notifications held back by rate limits", count),
+	}
+}
@@ -0,0 +1,275 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/dispatcher"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	historyMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// channel records the notifications a provider was handed, failing the
+// first failures of them
+type channel struct {
+	notification.Service
+	failures int
+	err      error
+	sent     []string
+}
+
+func (c *channel) send(recipient string) error {
+	c.sent = append(c.sent, recipient)
+	if len(c.sent) <= c.failures {
+		return c.err
+	}
+	return nil
+}
+
+func (c *channel) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	return c.send(userID)
+}
+
+func (c *channel) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return c.send(userEmail)
+}
+
+func (c *channel) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	return c.send(userID)
+}
+
+func (c *channel) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	return c.send(phoneNumber)
+}
+
+func newChannel() *channel {
+	return &channel{Service: notificationMock.NewService()}
+}
+
+// preferencesOf looks up fixed preferences
+func preferencesOf(prefs user.UserPreferences) dispatcher.PreferenceLookup {
+	return func(ctx context.Context, userID string) (*user.UserPreferences, error) {
+		return &prefs, nil
+	}
+}
+
+func TestDispatch_GivenPreferences_WhenSending_ThenRoutesAcceptedChannelsOnly(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefs         user.UserPreferences
+		send          func(ctx context.Context, s notification.Service) error
+		expectedEmail int
+		expectedPush  int
+		expectedSMS   int
+	}{
+		{
+			name:  "Given email notifications off, When sending a profile update, Then skips it",
+			prefs: user.UserPreferences{PushNotifications: true},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendProfileUpdateNotification(ctx, "user-1", map[string]interface{}{"first_name": "Jane"})
+			},
+		},
+		{
+			name:  "Given email notifications off, When sending a password reset, Then sends it anyway",
+			prefs: user.UserPreferences{},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendPasswordResetEmail(ctx, "jane@example.com", "reset-token")
+			},
+			expectedEmail: 1,
+		},
+		{
+			name:  "Given push notifications on, When sending a push, Then routes it to the push channel",
+			prefs: user.UserPreferences{PushNotifications: true},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendPushNotification(ctx, "user-1", notification.PushNotification{UserID: "user-1", Title: "Hello"})
+			},
+			expectedPush: 1,
+		},
+		{
+			name: "Given a push category turned off, When sending a push of it, Then skips it",
+			prefs: user.UserPreferences{
+				PushNotifications: true,
+				NotificationTypes: map[user.NotificationType]bool{user.NotificationMarketing: false},
+			},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendPushNotification(ctx, "user-1", notification.PushNotification{UserID: "user-1", Title: "Sale", Category: string(user.NotificationMarketing)})
+			},
+		},
+		{
+			name:  "Given SMS notifications off, When sending an SMS for the user, Then skips it",
+			prefs: user.UserPreferences{EmailNotifications: true},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendSMSNotification(notification.WithRecipient(ctx, "user-1"), "+14155552671", "Your order shipped")
+			},
+		},
+		{
+			name:  "Given SMS notifications off, When sending an SMS for no user, Then sends it",
+			prefs: user.UserPreferences{},
+			send: func(ctx context.Context, s notification.Service) error {
+				return s.SendSMSNotification(ctx, "+14155552671", "Your code is 123456")
+			},
+			expectedSMS: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			email, push, sms := newChannel(), newChannel(), newChannel()
+			service := dispatcher.NewService(notificationMock.NewService(), dispatcher.Config{
+				Email:       email,
+				Push:        push,
+				SMS:         sms,
+				Preferences: preferencesOf(tt.prefs),
+			})
+
+			// Act
+			err := tt.send(context.Background(), service)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Len(t, email.sent, tt.expectedEmail)
+			assert.Len(t, push.sent, tt.expectedPush)
+			assert.Len(t, sms.sent, tt.expectedSMS)
+		})
+	}
+}
+
+func TestDispatch_GivenRateLimit_WhenSendingBeyondIt_ThenRefusesPerRecipient(t *testing.T) {
+	// Arrange
+	sms := newChannel()
+	service := dispatcher.NewService(notificationMock.NewService(), dispatcher.Config{
+		SMS:        sms,
+		RateLimits: map[string]notification.RateLimit{"sms": {MaxPerMinute: 2, MaxPerHour: 10}},
+	})
+	ctx := context.Background()
+
+	// Act
+	results := []error{
+		service.SendSMSNotification(ctx, "+14155552671", "one"),
+		service.SendSMSNotification(ctx, "+14155552671", "two"),
+		service.SendSMSNotification(ctx, "+14155552671", "three"),
+		service.SendSMSNotification(ctx, "+14155550000", "other recipient"),
+	}
+
+	// Assert
+	assert.NoError(t, results[0])
+	assert.NoError(t, results[1])
+	assert.ErrorIs(t, results[2], notification.ErrRateLimited)
+	assert.NoError(t, results[3])
+	assert.Equal(t, []string{"+14155552671", "+14155552671", "+14155550000"}, sms.sent)
+}
+
+func TestDispatch_GivenFailingProvider_WhenSending_ThenRetriesPerRetryConfig(t *testing.T) {
+	unavailable := errors.New("provider unavailable")
+
+	tests := []struct {
+		name             string
+		failures         int
+		err              error
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			name:             "Given a provider failing twice, When sending, Then succeeds on the third attempt",
+			failures:         2,
+			err:              unavailable,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Given a provider failing beyond the retries, When sending, Then gives up with its error",
+			failures:         10,
+			err:              unavailable,
+			expectedAttempts: 4,
+			expectedError:    unavailable,
+		},
+		{
+			name:             "Given an invalid recipient, When sending, Then does not retry",
+			failures:         10,
+			err:              notification.ErrInvalidRecipient,
+			expectedAttempts: 1,
+			expectedError:    notification.ErrInvalidRecipient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sms := newChannel()
+			sms.failures, sms.err = tt.failures, tt.err
+			service := dispatcher.NewService(notificationMock.NewService(), dispatcher.Config{
+				SMS: sms,
+				Retry: notification.RetryConfig{
+					MaxRetries:    3,
+					InitialDelay:  time.Millisecond,
+					BackoffFactor: 2,
+					MaxDelay:      3 * time.Millisecond,
+				},
+			})
+
+			// Act
+			err := service.SendSMSNotification(context.Background(), "+14155552671", "Your code is 123456")
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, sms.sent, tt.expectedAttempts)
+		})
+	}
+}
+
+func TestDispatch_GivenHistory_WhenSending_ThenRecordsEachNotificationHandedToAProvider(t *testing.T) {
+	// Arrange
+	email := newChannel()
+	email.failures, email.err = 1, notification.ErrInvalidRecipient
+	history := historyMemory.NewService()
+	service := dispatcher.NewService(notificationMock.NewService(), dispatcher.Config{
+		Email:      email,
+		History:    history,
+		RateLimits: map[string]notification.RateLimit{"push": {MaxPerMinute: 1}},
+	})
+	ctx := notification.WithRecipient(context.Background(), "user-1")
+	push := notification.PushNotification{Title: "Hello", Body: "World"}
+
+	// Act
+	failed := service.SendPasswordResetEmail(ctx, "bad@example", "reset-token")
+	sent := service.SendPasswordResetEmail(ctx, "jane@example.com", "reset-token")
+	recorded := service.SendPasswordResetEmail(notification.WithRecorded(ctx), "jane@example.com", "reset-token")
+	pushed := service.SendPushNotification(context.Background(), "user-1", push)
+	limited := service.SendPushNotification(context.Background(), "user-1", push)
+	dryRun := service.SendPushNotification(notification.WithDryRun(context.Background()), "user-2", push)
+
+	// Assert
+	assert.ErrorIs(t, failed, notification.ErrInvalidRecipient)
+	assert.NoError(t, sent)
+	assert.NoError(t, recorded)
+	assert.NoError(t, pushed)
+	assert.ErrorIs(t, limited, notification.ErrRateLimited)
+	assert.NoError(t, dryRun)
+
+	entries, err := history.List(context.Background(), notificationhistory.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, notification.NotificationTypePush, entries[0].Type)
+	assert.Equal(t, "Hello", entries[0].Title)
+	assert.Equal(t, notification.NotificationStatusSent, entries[0].Status)
+	assert.Equal(t, notification.NotificationTypeEmail, entries[1].Type)
+	assert.Equal(t, notification.NotificationStatusSent, entries[1].Status)
+	assert.Equal(t, notification.NotificationStatusFailed, entries[2].Status)
+	assert.Equal(t, 1, entries[2].FailureCount)
+	for _, entry := range entries {
+		assert.Equal(t, "user-1", entry.UserID)
+		assert.NotContains(t, entry.Data, "token")
+	}
+}
@@ -0,0 +1,308 @@
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+)
+
+// Config controls when notifications are captured instead of delivered
+type Config struct {
+	// Global captures every notification; otherwise only contexts marked with
+	// notification.WithDryRun are captured
+	Global bool
+
+	// From is the sender recorded on captured emails
+	From string
+
+	// Templates renders the captured notifications; nil uses the templates
+	// shipped with the binary
+	Templates notificationtemplate.Service
+}
+
+// service implements notification.Service by rendering and validating messages
+// and writing them to an outbox instead of calling the real providers
+type service struct {
+	next      notification.Service
+	outbox    outbox.Service
+	templates notificationtemplate.Service
+	config    Config
+}
+
+// NewService creates a new notification service with dry-run support
+func NewService(next notification.Service, outboxService outbox.Service, config Config) notification.Service {
+	templates := config.Templates
+	if templates == nil {
+		templates = embedded.MustNewService(embedded.Config{})
+	}
+	return &service{
+		next:      next,
+		outbox:    outboxService,
+		templates: templates,
+		config:    config,
+	}
+}
+
+// SendWelcomeEmail captures the welcome email in dry-run mode
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendWelcomeEmail(ctx, userEmail, userName)
+	}
+
+	return s.captureTemplatedEmail(ctx, "welcome_email", userEmail, map[string]interface{}{"name": userName})
+}
+
+// SendPasswordResetEmail captures the password reset email in dry-run mode
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendPasswordResetEmail(ctx, userEmail, resetToken)
+	}
+
+	if resetToken == "" {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "reset token is required", Field: "reset_token"}
+	}
+	return s.captureTemplatedEmail(ctx, "password_reset_email", userEmail, map[string]interface{}{"token": resetToken})
+}
+
+// SendProfileUpdateNotification captures the profile update notification in dry-run mode
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendProfileUpdateNotification(ctx, userID, changes)
+	}
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	message, err := s.templates.Render(ctx, "profile_update", map[string]interface{}{"fields": strings.Join(fields, ", ")})
+	if err != nil {
+		return fmt.Errorf("failed to render profile_update: %w", err)
+	}
+	return s.capturePush(ctx, "profile_update", notification.PushNotification{
+		UserID: userID,
+		Title:  message.Subject,
+		Body:   message.Body,
+		Data:   changes,
+	})
+}
+
+// SendVerificationEmail captures the verification email in dry-run mode
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendVerificationEmail(ctx, userEmail, verificationToken)
+	}
+
+	if verificationToken == "" {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "verification token is required", Field: "verification_token"}
+	}
+	return s.captureTemplatedEmail(ctx, "verification_email", userEmail, map[string]interface{}{"token": verificationToken})
+}
+
+// SendMagicLinkEmail captures the magic link sign-in email in dry-run mode
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+	}
+
+	if magicLinkToken == "" {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "magic link token is required", Field: "magic_link_token"}
+	}
+	return s.captureTemplatedEmail(ctx, "magic_link_email", userEmail, map[string]interface{}{"token": magicLinkToken})
+}
+
+// SendNewDeviceLoginEmail captures the new device login alert in dry-run mode
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendNewDeviceLoginEmail(ctx, userEmail, login)
+	}
+
+	return s.captureTemplatedEmail(ctx, "new_device_login_email", userEmail, map[string]interface{}{
+		"user_agent": login.UserAgent,
+		"ip_address": login.IPAddress,
+		"login_at":   login.LoginAt.UTC().Format(time.RFC1123),
+	})
+}
+
+// SendPushNotification captures the push notification in dry-run mode
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendPushNotification(ctx, userID, push)
+	}
+
+	push.UserID = userID
+	return s.capturePush(ctx, "push", push)
+}
+
+// SendSMSNotification captures the SMS in dry-run mode
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendSMSNotification(ctx, phoneNumber, message)
+	}
+
+	if strings.TrimSpace(phoneNumber) == "" {
+		return notification.NotificationError{Code: notification.ErrInvalidRecipient.Code, Message: "phone number is required", Field: "phone_number"}
+	}
+	if message == "" {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "message is required", Field: "message"}
+	}
+	return s.capture(ctx, outbox.Message{
+		Channel: outbox.ChannelSMS,
+		Kind:    "sms",
+		To:      phoneNumber,
+		Body:    message,
+	})
+}
+
+// SendBulkEmail captures every email in dry-run mode, rendering those naming
+// a template and validating all of them first
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendBulkEmail(ctx, emails)
+	}
+
+	emails = append([]notification.EmailNotification(nil), emails...)
+	for i, email := range emails {
+		if email.Template == "" {
+			continue
+		}
+		message, err := s.templates.Render(ctx, email.Template, email.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to render %s for %s: %w", email.Template, email.To, err)
+		}
+		emails[i].Subject, emails[i].Body, emails[i].BodyHTML = message.Subject, message.Body, message.HTML
+	}
+	for _, email := range emails {
+		if err := validateEmail(email); err != nil {
+			return err
+		}
+	}
+	for _, email := range emails {
+		if err := s.captureEmail(ctx, "bulk_email", email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendBulkPush captures every push notification in dry-run mode, validating all of them first
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	if !s.dryRun(ctx) {
+		return s.next.SendBulkPush(ctx, notifications)
+	}
+
+	for _, push := range notifications {
+		if !push.IsValid() {
+			return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "push notification requires a user and title"}
+		}
+	}
+	for _, push := range notifications {
+		if err := s.capturePush(ctx, "bulk_push", push); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNotificationHistory delegates to the next service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the next service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount delegates to the next service
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.GetUnreadCount(ctx, userID)
+}
+
+// Helper methods
+
+func (s *service) dryRun(ctx context.Context) bool {
+	return s.config.Global || notification.IsDryRun(ctx)
+}
+
+// captureTemplatedEmail renders the template named kind for the recipient
+// in the languages of the context and captures it
+func (s *service) captureTemplatedEmail(ctx context.Context, kind, to string, variables map[string]interface{}) error {
+	message, err := s.templates.Render(ctx, kind, variables)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", kind, err)
+	}
+	return s.captureEmail(ctx, kind, notification.EmailNotification{
+		To:       to,
+		Subject:  message.Subject,
+		Body:     message.Body,
+		BodyHTML: message.HTML,
+	})
+}
+
+func (s *service) captureEmail(ctx context.Context, kind string, email notification.EmailNotification) error {
+	if email.From == "" {
+		email.From = s.config.From
+	}
+	if err := validateEmail(email); err != nil {
+		return err
+	}
+
+	body := email.Body
+	if body == "" {
+		body = email.BodyHTML
+	}
+	return s.capture(ctx, outbox.Message{
+		Channel: outbox.ChannelEmail,
+		Kind:    kind,
+		To:      email.To,
+		From:    email.From,
+		Subject: email.Subject,
+		Body:    body,
+		Data:    email.Variables,
+	})
+}
+
+func (s *service) capturePush(ctx context.Context, kind string, push notification.PushNotification) error {
+	if !push.IsValid() {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "push notification requires a user and title"}
+	}
+
+	return s.capture(ctx, outbox.Message{
+		Channel: outbox.ChannelPush,
+		Kind:    kind,
+		To:      push.UserID,
+		Subject: push.Title,
+		Body:    push.Body,
+		Data:    push.Data,
+	})
+}
+
+func (s *service) capture(ctx context.Context, message outbox.Message) error {
+	message.CorrelationID = audit.ExtractCorrelationID(ctx)
+	if _, err := s.outbox.Capture(ctx, message); err != nil {
+		return fmt.Errorf("failed to capture notification: %w", err)
+	}
+	return nil
+}
+
+// validateEmail applies the checks a real provider would reject on
+func validateEmail(email notification.EmailNotification) error {
+	if _, err := mail.ParseAddress(email.To); err != nil {
+		return notification.NotificationError{Code: notification.ErrInvalidRecipient.Code, Message: fmt.Sprintf("invalid recipient %q", email.To), Field: "to"}
+	}
+	if !email.IsValid() {
+		return notification.NotificationError{Code: notification.ErrInvalidMessage.Code, Message: "email requires a subject and body"}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package dryrun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/dryrun"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+func newDryRunService(global bool) (notification.Service, outbox.Service) {
+	box := outboxMemory.NewService(10)
+	return dryrun.NewService(notificationMock.NewService(), box, dryrun.Config{Global: global, From: "noreply@example.com"}), box
+}
+
+func TestDryRun_GivenGlobalMode_WhenSendingWelcomeEmail_ThenCapturesRenderedMessage(t *testing.T) {
+	service, box := newDryRunService(true)
+	ctx := audit.WithCorrelationID(context.Background(), "corr-1")
+
+	err := service.SendWelcomeEmail(ctx, "jane@example.com", "Jane Doe")
+
+	require.NoError(t, err)
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, outbox.ChannelEmail, messages[0].Channel)
+	assert.Equal(t, "welcome_email", messages[0].Kind)
+	assert.Equal(t, "jane@example.com", messages[0].To)
+	assert.Equal(t, "noreply@example.com", messages[0].From)
+	assert.Contains(t, messages[0].Body, "Jane Doe")
+	assert.Equal(t, "corr-1", messages[0].CorrelationID)
+}
+
+func TestDryRun_GivenRequestLanguage_WhenSendingWelcomeEmail_ThenRendersItsTemplateVariant(t *testing.T) {
+	service, box := newDryRunService(true)
+	ctx := translator.WithLanguages(context.Background(), []string{"es-MX", "en"})
+
+	err := service.SendWelcomeEmail(ctx, "juan@example.com", "Juan")
+
+	require.NoError(t, err)
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "¡Bienvenido!", messages[0].Subject)
+	assert.Equal(t, "Hola Juan, te damos la bienvenida a nuestra plataforma.", messages[0].Body)
+}
+
+func TestDryRun_GivenGlobalMode_WhenSendingNewDeviceLoginEmail_ThenCapturesDeviceDetails(t *testing.T) {
+	service, box := newDryRunService(true)
+	ctx := context.Background()
+
+	err := service.SendNewDeviceLoginEmail(ctx, "jane@example.com", notification.DeviceLogin{
+		UserAgent: "Firefox on Linux",
+		IPAddress: "203.0.113.7",
+		LoginAt:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	require.NoError(t, err)
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "new_device_login_email", messages[0].Kind)
+	assert.Equal(t, "jane@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "Firefox on Linux")
+	assert.Contains(t, messages[0].Body, "203.0.113.7")
+}
+
+func TestDryRun_GivenGlobalMode_WhenSendingMagicLinkEmail_ThenCapturesToken(t *testing.T) {
+	service, box := newDryRunService(true)
+	ctx := context.Background()
+
+	err := service.SendMagicLinkEmail(ctx, "jane@example.com", "magic-token")
+
+	require.NoError(t, err)
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "magic_link_email", messages[0].Kind)
+	assert.Equal(t, "jane@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "magic-token")
+}
+
+func TestDryRun_GivenRequestDryRun_WhenSending_ThenOnlyMarkedRequestsAreCaptured(t *testing.T) {
+	service, box := newDryRunService(false)
+	ctx := context.Background()
+
+	require.NoError(t, service.SendSMSNotification(ctx, "+15550100", "delivered"))
+	require.NoError(t, service.SendSMSNotification(notification.WithDryRun(ctx), "+15550101", "captured"))
+
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "+15550101", messages[0].To)
+	assert.Equal(t, "captured", messages[0].Body)
+}
+
+func TestDryRun_GivenInvalidMessages_WhenSending_ThenRejectsWithoutCapturing(t *testing.T) {
+	tests := []struct {
+		name    string
+		send    func(notification.Service) error
+		wantErr error
+	}{
+		{
+			name: "malformed email recipient",
+			send: func(s notification.Service) error {
+				return s.SendWelcomeEmail(context.Background(), "not-an-email", "Jane")
+			},
+			wantErr: notification.ErrInvalidRecipient,
+		},
+		{
+			name: "empty SMS",
+			send: func(s notification.Service) error {
+				return s.SendSMSNotification(context.Background(), "+15550100", "")
+			},
+			wantErr: notification.ErrInvalidMessage,
+		},
+		{
+			name: "bulk email with one invalid entry",
+			send: func(s notification.Service) error {
+				return s.SendBulkEmail(context.Background(), []notification.EmailNotification{
+					{To: "a@example.com", Subject: "Hi", Body: "Hello"},
+					{To: "b@example.com", Subject: "Hi"},
+				})
+			},
+			wantErr: notification.ErrInvalidMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, box := newDryRunService(true)
+
+			err := tt.send(service)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			messages, _ := box.List(context.Background(), outbox.Filter{})
+			assert.Empty(t, messages)
+		})
+	}
+}
@@ -2,9 +2,23 @@ package factory
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/gentra/decorator-arch-go/internal/inbox"
 	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/digest"
+	"github.com/gentra/decorator-arch-go/internal/notification/dispatcher"
+	"github.com/gentra/decorator-arch-go/internal/notification/dryrun"
+	"github.com/gentra/decorator-arch-go/internal/notification/inapp"
 	"github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notification/scheduler"
+	"github.com/gentra/decorator-arch-go/internal/notification/twilio"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
 )
 
 // Config contains all configuration for building the notification service
@@ -37,6 +51,23 @@ type Config struct {
 	TwilioAuthToken  string
 	TwilioFromNumber string
 
+	// TwilioStatusCallbackURL is the public URL Twilio posts delivery status
+	// reports to; empty requests none
+	TwilioStatusCallbackURL string
+
+	// History records the notifications sent, with their delivery status,
+	// and those delivered to inboxes; the twilio SMS provider requires it
+	History notificationhistory.Service
+
+	// RateLimits caps the notifications each recipient gets per channel when
+	// rate limiting is enabled; the twilio SMS provider also caps the
+	// segments the account sends with RateLimits["sms"]
+	RateLimits map[string]notification.RateLimit
+
+	// Preferences looks up the users' notification preferences, which the
+	// dispatcher respects; nil sends regardless of preferences
+	Preferences dispatcher.PreferenceLookup
+
 	// General notification settings
 	DefaultFromEmail  string
 	DefaultFromName   string
@@ -47,6 +78,30 @@ type Config struct {
 	TemplateDir string
 	Templates   map[string]string
 
+	// TemplateService renders the notifications providers send; nil uses
+	// the templates shipped with the binary, replaced or added to by the YAML
+	// template files of TemplateDir
+	TemplateService notificationtemplate.Service
+
+	// Inbox receives an in-app copy of the push notifications and profile
+	// updates sent to users; nil keeps no inbox
+	Inbox inbox.Service
+
+	// Digests holds the push notifications of the types users collect in
+	// a digest, for the digest worker to send as one email; nil sends
+	// every notification at once
+	Digests notificationdigest.Service
+
+	// Schedule queues the bulk emails with a future ScheduledAt, for the
+	// schedule's worker to send when due; nil sends them at once
+	Schedule notificationschedule.Service
+
+	// Dry-run configuration; when Outbox is set, notifications are captured there
+	// for every request if DryRun is true, or for requests marked with
+	// notification.WithDryRun otherwise
+	DryRun bool
+	Outbox outbox.Service
+
 	// Feature flags
 	Features FeatureFlags
 }
@@ -97,6 +152,31 @@ func NewFactory(config Config) *NotificationServiceFactory {
 
 // Build assembles and returns the complete notification service based on configuration
 func (f *NotificationServiceFactory) Build() (notification.Service, error) {
+	service, err := f.buildProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	service, err = f.addSMSProvider(service)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err = f.addDryRunLayer(service)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err = f.addInbox(f.addDigest(f.addDispatcher(service)))
+	if err != nil {
+		return nil, err
+	}
+
+	return f.addScheduler(service), nil
+}
+
+// buildProvider creates the notification service for the configured provider
+func (f *NotificationServiceFactory) buildProvider() (notification.Service, error) {
 	// For now, we only have mock implementation
 	// In the future, we can add strategy pattern here for different providers
 
@@ -120,6 +200,108 @@ func (f *NotificationServiceFactory) Build() (notification.Service, error) {
 	}
 }
 
+// addSMSProvider sends SMS through the configured SMS provider in front of
+// the service handling every other notification
+func (f *NotificationServiceFactory) addSMSProvider(next notification.Service) (notification.Service, error) {
+	if f.config.SMSProvider != "twilio" {
+		return next, nil
+	}
+	if f.config.History == nil {
+		return nil, fmt.Errorf("the twilio SMS provider requires a notification history")
+	}
+
+	return twilio.NewService(next, f.config.History, twilio.Config{
+		AccountSID:        f.config.TwilioAccountSID,
+		AuthToken:         f.config.TwilioAuthToken,
+		FromNumber:        f.config.TwilioFromNumber,
+		StatusCallbackURL: f.config.TwilioStatusCallbackURL,
+		RateLimit:         f.config.RateLimits["sms"],
+	})
+}
+
+// addDryRunLayer wraps the provider so dry-run notifications go to the outbox
+func (f *NotificationServiceFactory) addDryRunLayer(next notification.Service) (notification.Service, error) {
+	if f.config.Outbox == nil {
+		if f.config.DryRun {
+			return nil, fmt.Errorf("dry-run mode requires an outbox")
+		}
+		return next, nil
+	}
+
+	templates, err := f.buildTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return dryrun.NewService(next, f.config.Outbox, dryrun.Config{
+		Global:    f.config.DryRun,
+		From:      f.config.DefaultFromEmail,
+		Templates: templates,
+	}), nil
+}
+
+// buildTemplates returns the configured template service, or else loads the
+// shipped templates and those of TemplateDir
+func (f *NotificationServiceFactory) buildTemplates() (notificationtemplate.Service, error) {
+	if f.config.TemplateService != nil {
+		return f.config.TemplateService, nil
+	}
+	return embedded.NewService(embedded.Config{Dir: f.config.TemplateDir})
+}
+
+// addDispatcher routes notifications by channel, respecting preferences
+// and, when enabled, rate limits and retries, and records them in History
+func (f *NotificationServiceFactory) addDispatcher(next notification.Service) notification.Service {
+	config := dispatcher.Config{
+		Preferences:           f.config.Preferences,
+		History:               f.config.History,
+		SMSRecordedByProvider: f.config.SMSProvider == "twilio",
+	}
+	if f.config.Features.EnableRateLimiting {
+		config.RateLimits = f.config.RateLimits
+	}
+	if f.config.Features.EnableRetryLogic {
+		config.Retry = notification.DefaultNotificationConfig().RetryConfig
+		config.Retry.MaxRetries = f.config.MaxRetries
+		config.Retry.InitialDelay = time.Duration(f.config.RetryDelaySeconds) * time.Second
+	}
+	return dispatcher.NewService(next, config)
+}
+
+// addDigest holds notifications for digests in front of the dispatcher, so
+// users who turned push notifications off still get the digests they chose
+func (f *NotificationServiceFactory) addDigest(next notification.Service) notification.Service {
+	if f.config.Digests == nil {
+		return next
+	}
+	return digest.NewService(next, f.config.Digests, digest.Config{Preferences: f.config.Preferences})
+}
+
+// addInbox delivers in-app copies in front of the dispatcher, so users who
+// turned a channel off still find its notifications in the application
+func (f *NotificationServiceFactory) addInbox(next notification.Service) (notification.Service, error) {
+	if f.config.Inbox == nil {
+		return next, nil
+	}
+	templates, err := f.buildTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return inapp.NewService(next, f.config.Inbox, inapp.Config{
+		Templates:   templates,
+		Preferences: f.config.Preferences,
+		History:     f.config.History,
+	}), nil
+}
+
+// addScheduler queues scheduled emails in front of everything else, so they
+// meet preferences and rate limits when they are sent rather than queued
+func (f *NotificationServiceFactory) addScheduler(next notification.Service) notification.Service {
+	if f.config.Schedule == nil {
+		return next
+	}
+	return scheduler.NewService(next, f.config.Schedule)
+}
+
 // buildMockService creates a mock notification service for testing/development
 func (f *NotificationServiceFactory) buildMockService() (notification.Service, error) {
 	return mock.NewService(), nil
@@ -154,6 +336,7 @@ func DefaultConfig() Config {
 		MaxRetries:        3,
 		RetryDelaySeconds: 5,
 		Templates:         make(map[string]string),
+		RateLimits:        notification.DefaultNotificationConfig().RateLimits,
 		Features:          DefaultFeatureFlags(),
 	}
 }
@@ -226,6 +409,53 @@ func (b *ConfigBuilder) WithTwilioConfig(accountSID, authToken, fromNumber strin
 	return b
 }
 
+// WithTwilioStatusCallback sets the public URL Twilio reports delivery
+// statuses to
+func (b *ConfigBuilder) WithTwilioStatusCallback(callbackURL string) *ConfigBuilder {
+	b.config.TwilioStatusCallbackURL = callbackURL
+	return b
+}
+
+// WithHistory sets the notification history sent notifications are recorded in
+func (b *ConfigBuilder) WithHistory(history notificationhistory.Service) *ConfigBuilder {
+	b.config.History = history
+	return b
+}
+
+// WithInbox sets the inbox in-app notifications are delivered to
+func (b *ConfigBuilder) WithInbox(inboxService inbox.Service) *ConfigBuilder {
+	b.config.Inbox = inboxService
+	return b
+}
+
+// WithDigests sets the store notifications are held in for digests
+func (b *ConfigBuilder) WithDigests(digests notificationdigest.Service) *ConfigBuilder {
+	b.config.Digests = digests
+	return b
+}
+
+// WithSchedule sets the schedule scheduled emails are queued in
+func (b *ConfigBuilder) WithSchedule(schedule notificationschedule.Service) *ConfigBuilder {
+	b.config.Schedule = schedule
+	return b
+}
+
+// WithRateLimit caps the notifications sent on a channel: "email", "push"
+// or "sms"
+func (b *ConfigBuilder) WithRateLimit(channel string, limit notification.RateLimit) *ConfigBuilder {
+	if b.config.RateLimits == nil {
+		b.config.RateLimits = make(map[string]notification.RateLimit)
+	}
+	b.config.RateLimits[channel] = limit
+	return b
+}
+
+// WithPreferences sets the lookup of the users' notification preferences
+func (b *ConfigBuilder) WithPreferences(lookup dispatcher.PreferenceLookup) *ConfigBuilder {
+	b.config.Preferences = lookup
+	return b
+}
+
 // WithDefaultSender sets default sender information
 func (b *ConfigBuilder) WithDefaultSender(email, name string) *ConfigBuilder {
 	b.config.DefaultFromEmail = email
@@ -246,6 +476,12 @@ func (b *ConfigBuilder) WithTemplateDir(dir string) *ConfigBuilder {
 	return b
 }
 
+// WithTemplateService sets the templates notifications are rendered from
+func (b *ConfigBuilder) WithTemplateService(templates notificationtemplate.Service) *ConfigBuilder {
+	b.config.TemplateService = templates
+	return b
+}
+
 // WithTemplate adds a template mapping
 func (b *ConfigBuilder) WithTemplate(name, path string) *ConfigBuilder {
 	if b.config.Templates == nil {
@@ -255,6 +491,14 @@ func (b *ConfigBuilder) WithTemplate(name, path string) *ConfigBuilder {
 	return b
 }
 
+// WithDryRun captures notifications in the outbox; global captures every
+// notification rather than only those for requests marked as dry-run
+func (b *ConfigBuilder) WithDryRun(outboxService outbox.Service, global bool) *ConfigBuilder {
+	b.config.Outbox = outboxService
+	b.config.DryRun = global
+	return b
+}
+
 // WithFeatures sets the feature flags
 func (b *ConfigBuilder) WithFeatures(features FeatureFlags) *ConfigBuilder {
 	b.config.Features = features
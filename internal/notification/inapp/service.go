@@ -0,0 +1,216 @@
+package inapp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/dispatcher"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config controls which notifications reach the users' inboxes
+type Config struct {
+	// Templates renders the profile update notification; nil uses the
+	// templates shipped with the binary
+	Templates notificationtemplate.Service
+
+	// Preferences looks up the notification types users turned off, which
+	// stay out of their inbox; nil keeps every notification
+	Preferences dispatcher.PreferenceLookup
+
+	// History records the notifications delivered to inboxes; nil records
+	// none
+	History notificationhistory.Service
+}
+
+// service implements notification.Service by also delivering the
+// notifications addressed to a user, push notifications and profile
+// updates, to their in-app inbox, whatever channels they turned off. The
+// inbox copy does not depend on the other channels: inbox failures are
+// logged and the notification is still handed to next. Delivered copies are
+// recorded in the history under the inbox notification's ID. Unread counts
+// come from the inbox.
+type service struct {
+	next      notification.Service
+	inbox     inbox.Service
+	templates notificationtemplate.Service
+	config    Config
+}
+
+// NewService creates a notification service delivering to in-app inboxes
+func NewService(next notification.Service, inboxService inbox.Service, config Config) notification.Service {
+	templates := config.Templates
+	if templates == nil {
+		templates = embedded.MustNewService(embedded.Config{})
+	}
+	return &service{next: next, inbox: inboxService, templates: templates, config: config}
+}
+
+// SendWelcomeEmail delegates to the next service
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	return s.next.SendWelcomeEmail(ctx, userEmail, userName)
+}
+
+// SendPasswordResetEmail delegates to the next service
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return s.next.SendPasswordResetEmail(ctx, userEmail, resetToken)
+}
+
+// SendProfileUpdateNotification tells the user in-app which profile fields
+// changed, then sends the notification on
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	message, err := s.templates.Render(ctx, "profile_update", map[string]interface{}{"fields": strings.Join(fields, ", ")})
+	if err != nil {
+		correlation.Logf(ctx, "Failed to render the in-app profile update of user %s: %v", userID, err)
+	} else {
+		s.deliver(ctx, inbox.Notification{
+			UserID:   userID,
+			Title:    message.Subject,
+			Body:     message.Body,
+			Category: "profile_update",
+			Priority: notification.PriorityNormal,
+		})
+	}
+	return s.next.SendProfileUpdateNotification(ctx, userID, changes)
+}
+
+// SendVerificationEmail delegates to the next service
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	return s.next.SendVerificationEmail(ctx, userEmail, verificationToken)
+}
+
+// SendNewDeviceLoginEmail delegates to the next service
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	return s.next.SendNewDeviceLoginEmail(ctx, userEmail, login)
+}
+
+// SendMagicLinkEmail delegates to the next service
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	return s.next.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+}
+
+// SendPushNotification delivers the push notification in-app too
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	s.deliver(ctx, fromPush(userID, push))
+	return s.next.SendPushNotification(ctx, userID, push)
+}
+
+// SendSMSNotification delegates to the next service
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	return s.next.SendSMSNotification(ctx, phoneNumber, message)
+}
+
+// SendBulkEmail delegates to the next service
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	return s.next.SendBulkEmail(ctx, emails)
+}
+
+// SendBulkPush delivers every push notification in-app too
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	for _, push := range notifications {
+		s.deliver(ctx, fromPush(push.UserID, push))
+	}
+	return s.next.SendBulkPush(ctx, notifications)
+}
+
+// GetNotificationHistory delegates to the next service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the next service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount returns the number of unread notifications in the user's
+// inbox
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.inbox.UnreadCount(ctx, userID)
+}
+
+// deliver adds the notification to the inbox unless the request is a dry
+// run or the user turned its category off
+func (s *service) deliver(ctx context.Context, n inbox.Notification) {
+	if n.UserID == "" || notification.IsDryRun(ctx) || !s.accepts(ctx, n.UserID, n.Category) {
+		return
+	}
+	delivered, err := s.inbox.Deliver(ctx, n)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to deliver in-app notification to user %s: %v", n.UserID, err)
+		return
+	}
+	s.record(ctx, delivered)
+}
+
+// record stores a notification delivered to an inbox in the history
+func (s *service) record(ctx context.Context, n *inbox.Notification) {
+	if s.config.History == nil {
+		return
+	}
+
+	entry := notification.NotificationHistory{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		Type:      notification.NotificationTypeInApp,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      map[string]interface{}{"kind": "in_app", "category": n.Category},
+		Status:    notification.NotificationStatusPending,
+		Priority:  n.Priority,
+		CreatedAt: n.CreatedAt,
+	}
+	if entry.Priority == "" {
+		entry.Priority = notification.PriorityNormal
+	}
+	notificationhistory.StatusUpdate{Status: notification.NotificationStatusDelivered, At: n.CreatedAt}.Apply(&entry)
+
+	if _, err := s.config.History.Record(ctx, entry); err != nil {
+		correlation.Logf(ctx, "Failed to record in-app notification %s in the history: %v", n.ID, err)
+	}
+}
+
+// accepts reports whether the user takes notifications of the category
+func (s *service) accepts(ctx context.Context, userID, category string) bool {
+	if s.config.Preferences == nil || category == "" {
+		return true
+	}
+	prefs, err := s.config.Preferences(ctx, userID)
+	if err != nil {
+		correlation.Logf(ctx, "Failed to load notification preferences of user %s: %v", userID, err)
+		return true
+	}
+	if prefs == nil {
+		return true
+	}
+	enabled, known := prefs.NotificationTypes[user.NotificationType(category)]
+	return !known || enabled
+}
+
+// fromPush is the in-app copy of a push notification
+func fromPush(userID string, push notification.PushNotification) inbox.Notification {
+	if userID == "" {
+		userID = push.UserID
+	}
+	return inbox.Notification{
+		UserID:   userID,
+		Title:    push.Title,
+		Body:     push.Body,
+		Category: push.Category,
+		Data:     push.Data,
+		Priority: push.Priority,
+	}
+}
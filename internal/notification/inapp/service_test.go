@@ -0,0 +1,118 @@
+package inapp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/inbox"
+	inboxMemory "github.com/gentra/decorator-arch-go/internal/inbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/inapp"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// preferencesOf looks up fixed preferences
+func preferencesOf(prefs user.UserPreferences) func(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return func(ctx context.Context, userID string) (*user.UserPreferences, error) {
+		return &prefs, nil
+	}
+}
+
+func TestInApp_GivenNotificationForUser_WhenSending_ThenDeliversItToTheInbox(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            func() context.Context
+		prefs          user.UserPreferences
+		send           func(ctx context.Context, service notification.Service) error
+		expectedTitles []string
+	}{
+		{
+			name: "Given a push notification, When sending, Then delivers a copy in-app",
+			ctx:  context.Background,
+			send: func(ctx context.Context, service notification.Service) error {
+				return service.SendPushNotification(ctx, "user-1", notification.PushNotification{Title: "Sale", Body: "20% off", Category: "marketing"})
+			},
+			expectedTitles: []string{"Sale"},
+		},
+		{
+			name:  "Given push notifications turned off, When sending, Then still delivers the copy in-app",
+			ctx:   context.Background,
+			prefs: user.UserPreferences{PushNotifications: false},
+			send: func(ctx context.Context, service notification.Service) error {
+				return service.SendPushNotification(ctx, "user-1", notification.PushNotification{Title: "Sale", Body: "20% off"})
+			},
+			expectedTitles: []string{"Sale"},
+		},
+		{
+			name:  "Given a category the user turned off, When sending, Then keeps it out of the inbox",
+			ctx:   context.Background,
+			prefs: user.UserPreferences{NotificationTypes: map[user.NotificationType]bool{"marketing": false}},
+			send: func(ctx context.Context, service notification.Service) error {
+				return service.SendPushNotification(ctx, "user-1", notification.PushNotification{Title: "Sale", Body: "20% off", Category: "marketing"})
+			},
+		},
+		{
+			name: "Given a dry-run request, When sending, Then keeps it out of the inbox",
+			ctx:  func() context.Context { return notification.WithDryRun(context.Background()) },
+			send: func(ctx context.Context, service notification.Service) error {
+				return service.SendPushNotification(ctx, "user-1", notification.PushNotification{Title: "Sale", Body: "20% off"})
+			},
+		},
+		{
+			name: "Given a profile update, When sending, Then delivers the rendered notification in-app",
+			ctx:  context.Background,
+			send: func(ctx context.Context, service notification.Service) error {
+				return service.SendProfileUpdateNotification(ctx, "user-1", map[string]interface{}{"last_name": "Doe", "first_name": "Jane"})
+			},
+			expectedTitles: []string{"Profile Updated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			inboxes := inboxMemory.NewService()
+			service := inapp.NewService(notificationMock.NewService(), inboxes, inapp.Config{Preferences: preferencesOf(tt.prefs)})
+
+			// Act
+			err := tt.send(tt.ctx(), service)
+
+			// Assert
+			require.NoError(t, err)
+			page, err := inboxes.List(context.Background(), "user-1", inbox.Query{})
+			require.NoError(t, err)
+			titles := []string{}
+			for _, n := range page.Notifications {
+				titles = append(titles, n.Title)
+			}
+			if tt.expectedTitles == nil {
+				tt.expectedTitles = []string{}
+			}
+			assert.Equal(t, tt.expectedTitles, titles)
+			unread, err := service.GetUnreadCount(context.Background(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.expectedTitles), unread)
+		})
+	}
+}
+
+func TestInApp_GivenProfileUpdate_WhenSending_ThenListsTheChangedFields(t *testing.T) {
+	// Arrange
+	inboxes := inboxMemory.NewService()
+	service := inapp.NewService(notificationMock.NewService(), inboxes, inapp.Config{})
+
+	// Act
+	err := service.SendProfileUpdateNotification(context.Background(), "user-1", map[string]interface{}{"last_name": "Doe", "first_name": "Jane"})
+
+	// Assert
+	require.NoError(t, err)
+	page, err := inboxes.List(context.Background(), "user-1", inbox.Query{})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 1)
+	assert.Equal(t, "Your profile was updated: first_name, last_name", page.Notifications[0].Body)
+	assert.Equal(t, "profile_update", page.Notifications[0].Category)
+}
@@ -46,6 +46,18 @@ func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verifica
 	return nil
 }
 
+// SendNewDeviceLoginEmail sends a new device login alert (mock implementation)
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	log.Printf("MOCK NOTIFICATION: New device login email sent to %s (%s from %s)", userEmail, login.UserAgent, login.IPAddress)
+	return nil
+}
+
+// SendMagicLinkEmail sends a magic link sign-in email (mock implementation)
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	log.Printf("MOCK NOTIFICATION: Magic link email sent to %s with token %s", userEmail, magicLinkToken[:min(len(magicLinkToken), 8)]+"...")
+	return nil
+}
+
 // SendPushNotification sends a push notification (mock implementation)
 func (s *service) SendPushNotification(ctx context.Context, userID string, notification notification.PushNotification) error {
 	log.Printf("MOCK NOTIFICATION: Push notification sent to user %s: %s - %s", userID, notification.Title, notification.Body)
@@ -2,7 +2,10 @@ package notification
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf16"
 )
 
 // Service defines the notification domain interface - the ONLY interface in this domain
@@ -12,6 +15,8 @@ type Service interface {
 	SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error
 	SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error
 	SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error
+	SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login DeviceLogin) error
+	SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error
 	
 	// Push notifications
 	SendPushNotification(ctx context.Context, userID string, notification PushNotification) error
@@ -31,6 +36,57 @@ type Service interface {
 
 // Domain types and data structures
 
+// contextKey is the type for notification context keys
+type contextKey string
+
+// DryRunContextKey marks a request whose notifications must not reach real providers
+const DryRunContextKey contextKey = "notification_dry_run"
+
+// WithDryRun marks the context so notifications are rendered and captured
+// instead of being delivered
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, DryRunContextKey, true)
+}
+
+// IsDryRun reports whether notifications for this context must not be delivered
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(DryRunContextKey).(bool)
+	return dryRun
+}
+
+// RecordedContextKey marks notifications already recorded in the history
+const RecordedContextKey contextKey = "notification_recorded"
+
+// WithRecorded marks the context of notifications their sender recorded in
+// the history itself, such as scheduled ones, so they are not recorded again
+func WithRecorded(ctx context.Context) context.Context {
+	return context.WithValue(ctx, RecordedContextKey, true)
+}
+
+// IsRecorded reports whether the sender already recorded the notifications
+// for this context in the history
+func IsRecorded(ctx context.Context) bool {
+	recorded, _ := ctx.Value(RecordedContextKey).(bool)
+	return recorded
+}
+
+// RecipientContextKey carries the ID of the user a notification addressed by
+// email or phone number is for
+const RecipientContextKey contextKey = "notification_recipient"
+
+// WithRecipient tags the context with the user the notifications sent with it
+// are for, so providers addressing them by email or phone number can record
+// them in the user's history
+func WithRecipient(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, RecipientContextKey, userID)
+}
+
+// RecipientFromContext returns the user tagged with WithRecipient, or ""
+func RecipientFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(RecipientContextKey).(string)
+	return userID
+}
+
 // EmailNotification represents an email notification
 type EmailNotification struct {
 	ID          string                 `json:"id"`
@@ -59,6 +115,13 @@ type PushNotification struct {
 	Priority Priority               `json:"priority"`
 }
 
+// DeviceLogin describes a sign-in from a device the user had not used before
+type DeviceLogin struct {
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	LoginAt   time.Time `json:"login_at"`
+}
+
 // SMSNotification represents an SMS notification
 type SMSNotification struct {
 	ID          string    `json:"id"`
@@ -112,6 +175,7 @@ const (
 	NotificationStatusDelivered NotificationStatus = "delivered"
 	NotificationStatusFailed    NotificationStatus = "failed"
 	NotificationStatusRead      NotificationStatus = "read"
+	NotificationStatusCancelled NotificationStatus = "cancelled"
 )
 
 // Priority enum
@@ -172,6 +236,56 @@ func (p *PushNotification) HasData() bool {
 	return len(p.Data) > 0
 }
 
+// e164Pattern matches phone numbers in E.164 format: a plus sign, a country
+// code not starting with zero and at most 15 digits in all
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// ValidatePhoneNumber checks the number is in E.164 format, e.g. +14155552671
+func ValidatePhoneNumber(phoneNumber string) error {
+	if !e164Pattern.MatchString(phoneNumber) {
+		return NotificationError{
+			Code:    ErrInvalidRecipient.Code,
+			Message: "Phone number must be in E.164 format, e.g. +14155552671",
+			Field:   "phone_number",
+		}
+	}
+	return nil
+}
+
+// GSM 03.38 characters; those of gsmExtension take two septets
+const (
+	gsmBasic     = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsmExtension = "^{}\\[~]|€\f"
+)
+
+// SMSSegments returns how many SMS segments carriers bill the message as.
+// GSM-7 messages fit 160 characters in one segment and 153 per segment once
+// split; any other character switches the whole message to UCS-2, which fits
+// 70 and 67.
+func SMSSegments(message string) int {
+	septets := 0
+	for _, r := range message {
+		switch {
+		case strings.ContainsRune(gsmBasic, r):
+			septets++
+		case strings.ContainsRune(gsmExtension, r):
+			septets += 2
+		default:
+			return segments(len(utf16.Encode([]rune(message))), 70, 67)
+		}
+	}
+	return segments(septets, 160, 153)
+}
+
+// segments splits length units into those fitting a single segment or, when
+// longer, into concatenated segments of multipart units each
+func segments(length, single, multipart int) int {
+	if length <= single {
+		return 1
+	}
+	return (length + multipart - 1) / multipart
+}
+
 // Helper methods for NotificationHistory
 func (n *NotificationHistory) IsRead() bool {
 	return n.ReadAt != nil
@@ -211,3 +325,27 @@ func DefaultNotificationConfig() NotificationConfig {
 		},
 	}
 }
+
+// NotificationError represents notification domain errors
+type NotificationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e NotificationError) Error() string {
+	return e.Message
+}
+
+// Is matches notification errors by code so detailed errors match their sentinel
+func (e NotificationError) Is(target error) bool {
+	t, ok := target.(NotificationError)
+	return ok && t.Code == e.Code
+}
+
+// Common notification errors
+var (
+	ErrInvalidRecipient = NotificationError{Code: "INVALID_RECIPIENT", Message: "Invalid notification recipient"}
+	ErrInvalidMessage   = NotificationError{Code: "INVALID_MESSAGE", Message: "Invalid notification message"}
+	ErrRateLimited      = NotificationError{Code: "NOTIFICATION_RATE_LIMITED", Message: "Notification rate limit exceeded"}
+)
@@ -1,6 +1,7 @@
 package notification_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -282,6 +283,94 @@ func TestNotificationHistory_IsRead(t *testing.T) {
 	}
 }
 
+func TestValidatePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		phoneNumber string
+		expectValid bool
+	}{
+		{
+			name:        "Given an E.164 number, When ValidatePhoneNumber is called, Then should accept it",
+			phoneNumber: "+14155552671",
+			expectValid: true,
+		},
+		{
+			name:        "Given a number without plus sign, When ValidatePhoneNumber is called, Then should reject it",
+			phoneNumber: "14155552671",
+		},
+		{
+			name:        "Given a number with spaces, When ValidatePhoneNumber is called, Then should reject it",
+			phoneNumber: "+1 415 555 2671",
+		},
+		{
+			name:        "Given a country code starting with zero, When ValidatePhoneNumber is called, Then should reject it",
+			phoneNumber: "+0415552671",
+		},
+		{
+			name:        "Given more than 15 digits, When ValidatePhoneNumber is called, Then should reject it",
+			phoneNumber: "+1415555267112345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := notification.ValidatePhoneNumber(tt.phoneNumber)
+
+			// Assert
+			if tt.expectValid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, notification.ErrInvalidRecipient)
+		})
+	}
+}
+
+func TestSMSSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected int
+	}{
+		{
+			name:     "Given 160 GSM characters, When SMSSegments is called, Then should return one segment",
+			message:  strings.Repeat("a", 160),
+			expected: 1,
+		},
+		{
+			name:     "Given 161 GSM characters, When SMSSegments is called, Then should split in segments of 153",
+			message:  strings.Repeat("a", 161),
+			expected: 2,
+		},
+		{
+			name:     "Given extension characters, When SMSSegments is called, Then should count them twice",
+			message:  strings.Repeat("a", 151) + "{}[]€",
+			expected: 2,
+		},
+		{
+			name:     "Given a character outside GSM, When SMSSegments is called, Then should count UCS-2 segments of 67",
+			message:  strings.Repeat("a", 70) + "✓",
+			expected: 2,
+		},
+		{
+			name:     "Given 70 UCS-2 characters, When SMSSegments is called, Then should return one segment",
+			message:  strings.Repeat("ж", 70),
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := notification.SMSSegments(tt.message)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestNotificationHistory_IsSent(t *testing.T) {
 	tests := []struct {
 		name    string
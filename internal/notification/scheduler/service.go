@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+// service implements notification.Service by queuing the bulk emails with a
+// future ScheduledAt in the notification schedule, whose worker sends them
+// through the service again when due. Everything else goes straight to next.
+type service struct {
+	next     notification.Service
+	schedule notificationschedule.Service
+}
+
+// NewService creates a notification service deferring scheduled emails
+func NewService(next notification.Service, schedule notificationschedule.Service) notification.Service {
+	return &service{next: next, schedule: schedule}
+}
+
+// SendWelcomeEmail delegates to the next service
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	return s.next.SendWelcomeEmail(ctx, userEmail, userName)
+}
+
+// SendPasswordResetEmail delegates to the next service
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return s.next.SendPasswordResetEmail(ctx, userEmail, resetToken)
+}
+
+// SendProfileUpdateNotification delegates to the next service
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	return s.next.SendProfileUpdateNotification(ctx, userID, changes)
+}
+
+// SendVerificationEmail delegates to the next service
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	return s.next.SendVerificationEmail(ctx, userEmail, verificationToken)
+}
+
+// SendNewDeviceLoginEmail delegates to the next service
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	return s.next.SendNewDeviceLoginEmail(ctx, userEmail, login)
+}
+
+// SendMagicLinkEmail delegates to the next service
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	return s.next.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+}
+
+// SendPushNotification delegates to the next service
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	return s.next.SendPushNotification(ctx, userID, push)
+}
+
+// SendSMSNotification delegates to the next service
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	return s.next.SendSMSNotification(ctx, phoneNumber, message)
+}
+
+// SendBulkEmail queues the emails scheduled for later and sends the others.
+// Dry-run requests are never queued, so their emails are captured at once.
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	if notification.IsDryRun(ctx) {
+		return s.next.SendBulkEmail(ctx, emails)
+	}
+
+	now := make([]notification.EmailNotification, 0, len(emails))
+	for i := range emails {
+		if !emails[i].IsScheduled() {
+			now = append(now, emails[i])
+			continue
+		}
+		email := emails[i]
+		_, err := s.schedule.Schedule(ctx, notificationschedule.Scheduled{
+			UserID:  notification.RecipientFromContext(ctx),
+			Channel: notification.NotificationTypeEmail,
+			Email:   &email,
+			SendAt:  *email.ScheduledAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(now) == 0 {
+		return nil
+	}
+	return s.next.SendBulkEmail(ctx, now)
+}
+
+// SendBulkPush delegates to the next service
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	return s.next.SendBulkPush(ctx, notifications)
+}
+
+// GetNotificationHistory delegates to the next service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the next service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount delegates to the next service
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.GetUnreadCount(ctx, userID)
+}
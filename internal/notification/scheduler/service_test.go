@@ -0,0 +1,103 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notification/scheduler"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule/memory"
+)
+
+// mailer records the emails the provider was handed
+type mailer struct {
+	notification.Service
+	sent []string
+}
+
+func (m *mailer) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	for _, email := range emails {
+		m.sent = append(m.sent, email.To)
+	}
+	return nil
+}
+
+func TestSendBulkEmail_GivenScheduledEmails_WhenSending_ThenQueuesThemUntilDue(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            func() context.Context
+		expectedSent   []string
+		expectedQueued int
+	}{
+		{
+			name:           "Given an email scheduled for later, When sending, Then queues it and sends the others",
+			ctx:            context.Background,
+			expectedSent:   []string{"now@example.com"},
+			expectedQueued: 1,
+		},
+		{
+			name:         "Given a dry-run request, When sending, Then hands every email on at once",
+			ctx:          func() context.Context { return notification.WithDryRun(context.Background()) },
+			expectedSent: []string{"now@example.com", "later@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := notification.WithRecipient(tt.ctx(), "user-1")
+			provider := &mailer{Service: notificationMock.NewService()}
+			schedule := memory.NewService()
+			service := scheduler.NewService(provider, schedule)
+			later := time.Now().Add(time.Hour)
+
+			// Act
+			err := service.SendBulkEmail(ctx, []notification.EmailNotification{
+				{To: "now@example.com", Subject: "Now", Body: "Sent at once"},
+				{To: "later@example.com", Subject: "Later", Body: "Sent in an hour", ScheduledAt: &later},
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSent, provider.sent)
+			queued, err := schedule.List(ctx, notificationschedule.Filter{UserID: "user-1"})
+			require.NoError(t, err)
+			require.Len(t, queued, tt.expectedQueued)
+			if tt.expectedQueued > 0 {
+				assert.Equal(t, "later@example.com", queued[0].Email.To)
+				assert.True(t, later.Equal(queued[0].SendAt))
+			}
+		})
+	}
+}
+
+func TestSendWith_GivenQueuedEmail_WhenDelivered_ThenSendsItThroughTheSchedulerWithoutQueuingAgain(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	provider := &mailer{Service: notificationMock.NewService()}
+	schedule := memory.NewService()
+	service := scheduler.NewService(provider, schedule)
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, service.SendBulkEmail(ctx, []notification.EmailNotification{
+		{To: "later@example.com", Subject: "Later", Body: "Sent in an hour", ScheduledAt: &later},
+	}))
+	queued, err := schedule.List(ctx, notificationschedule.Filter{})
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+
+	// Act
+	err = notificationschedule.SendWith(service)(ctx, queued[0])
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later@example.com"}, provider.sent)
+	again, err := schedule.List(ctx, notificationschedule.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, again, 1)
+}
@@ -0,0 +1,397 @@
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+)
+
+const (
+	// DefaultBaseURL is the Twilio REST API
+	DefaultBaseURL = "https://api.twilio.com"
+
+	// SignatureHeader carries the signature of status callbacks
+	SignatureHeader = "X-Twilio-Signature"
+
+	// ProviderName is recorded in the data of the history entries of sent
+	// messages
+	ProviderName = "twilio"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Config contains the Twilio account messages are sent from
+type Config struct {
+	AccountSID string
+	AuthToken  string
+
+	// FromNumber is the E.164 number messages are sent from
+	FromNumber string
+
+	// StatusCallbackURL is the public URL of the status callback route
+	// Twilio reports deliveries to; empty requests no reports
+	StatusCallbackURL string
+
+	// RateLimit caps the segments sent per minute, hour and day; zero
+	// limits are not enforced
+	RateLimit notification.RateLimit
+
+	// BaseURL replaces DefaultBaseURL, for tests
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// service decorates a notification.Service, sending SMS through the Twilio
+// Messages API and delegating every other notification to next. Each message
+// is recorded in the notification history under its Twilio message SID, so
+// the status callbacks Twilio sends later update it. Rate limits count the
+// segments a message is billed as rather than messages, keeping spend within
+// RateLimits["sms"] whatever the message lengths.
+type service struct {
+	next    notification.Service
+	history notificationhistory.Service
+	config  Config
+	client  *http.Client
+
+	mu   sync.Mutex
+	sent []usage
+}
+
+// usage is the segments of one message sent at a time
+type usage struct {
+	at       time.Time
+	segments int
+}
+
+// NewService creates a Twilio SMS provider in front of next
+func NewService(next notification.Service, history notificationhistory.Service, config Config) (notification.Service, error) {
+	if config.AccountSID == "" || config.AuthToken == "" {
+		return nil, fmt.Errorf("twilio account SID and auth token are required")
+	}
+	if err := notification.ValidatePhoneNumber(config.FromNumber); err != nil {
+		return nil, fmt.Errorf("twilio from number: %w", err)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &service{next: next, history: history, config: config, client: client}, nil
+}
+
+// SendSMSNotification sends the message to an E.164 number and records it in
+// the history of the user tagged with notification.WithRecipient
+func (s *service) SendSMSNotification(ctx context.Context, phoneNumber string, message string) error {
+	if err := notification.ValidatePhoneNumber(phoneNumber); err != nil {
+		return err
+	}
+	if strings.TrimSpace(message) == "" {
+		return notification.ErrInvalidMessage
+	}
+
+	segments := notification.SMSSegments(message)
+	now := time.Now()
+	if err := s.reserve(now, segments); err != nil {
+		return err
+	}
+
+	entry := notification.NotificationHistory{
+		UserID:   notification.RecipientFromContext(ctx),
+		Type:     notification.NotificationTypeSMS,
+		Body:     message,
+		Priority: notification.PriorityNormal,
+		Data:     map[string]interface{}{"provider": ProviderName, "to": phoneNumber, "segments": segments},
+	}
+	sent, err := s.send(ctx, phoneNumber, message)
+	if err != nil {
+		// Twilio bills nothing for messages it refused
+		s.release(now, segments)
+		entry.ID = uuid.New().String()
+		entry.Status = notification.NotificationStatusPending
+		notificationhistory.StatusUpdate{Status: notification.NotificationStatusFailed, Error: err.Error()}.Apply(&entry)
+		s.record(ctx, entry)
+		return err
+	}
+
+	entry.ID = sent.SID
+	entry.Status = notification.NotificationStatusPending
+	if update, ok := statusUpdate(sent.Status, "", ""); ok {
+		update.Apply(&entry)
+	}
+	s.record(ctx, entry)
+	return nil
+}
+
+// recipientErrors are the Twilio error codes refusing the number messaged:
+// invalid, in a region the account may not reach, unreachable by the sender,
+// unsubscribed or not a mobile number
+var recipientErrors = map[int]bool{21211: true, 21408: true, 21610: true, 21612: true, 21614: true}
+
+// createdMessage is the part of a Twilio Messages API response read back
+type createdMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// apiError is the body of a failed Twilio API call
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// send creates the message with the Twilio Messages API
+func (s *service) send(ctx context.Context, to, body string) (*createdMessage, error) {
+	form := url.Values{"To": {to}, "From": {s.config.FromNumber}, "Body": {body}}
+	if s.config.StatusCallbackURL != "" {
+		form.Set("StatusCallback", s.config.StatusCallbackURL)
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(s.config.BaseURL, "/"), url.PathEscape(s.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.config.AccountSID, s.config.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("twilio: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var failure apiError
+		if json.Unmarshal(raw, &failure) == nil && failure.Message != "" {
+			if recipientErrors[failure.Code] {
+				return nil, notification.NotificationError{Code: notification.ErrInvalidRecipient.Code, Message: failure.Message, Field: "phone_number"}
+			}
+			return nil, fmt.Errorf("twilio: %s (code %d)", failure.Message, failure.Code)
+		}
+		return nil, fmt.Errorf("twilio: unexpected status %d", resp.StatusCode)
+	}
+
+	var sent createdMessage
+	if err := json.Unmarshal(raw, &sent); err != nil {
+		return nil, fmt.Errorf("twilio: %w", err)
+	}
+	if sent.SID == "" {
+		return nil, fmt.Errorf("twilio: response names no message SID")
+	}
+	return &sent, nil
+}
+
+// record stores the entry in the history. The message is already with
+// Twilio when it fails, so the failure is logged rather than returned for
+// callers not to send it again.
+func (s *service) record(ctx context.Context, entry notification.NotificationHistory) {
+	if _, err := s.history.Record(ctx, entry); err != nil {
+		log.Printf("twilio: failed to record SMS %s in the notification history: %v", entry.ID, err)
+	}
+}
+
+// reserve counts the segments against the per minute, hour and day limits,
+// or returns notification.ErrRateLimited naming the limit they would exceed
+func (s *service) reserve(now time.Time, segments int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Only a day of usage is ever looked at
+	kept := s.sent[:0]
+	for _, u := range s.sent {
+		if now.Sub(u.at) < 24*time.Hour {
+			kept = append(kept, u)
+		}
+	}
+	s.sent = kept
+
+	limits := []struct {
+		name   string
+		window time.Duration
+		max    int
+	}{
+		{"minute", time.Minute, s.config.RateLimit.MaxPerMinute},
+		{"hour", time.Hour, s.config.RateLimit.MaxPerHour},
+		{"day", 24 * time.Hour, s.config.RateLimit.MaxPerDay},
+	}
+	for _, limit := range limits {
+		if limit.max <= 0 {
+			continue
+		}
+		used := 0
+		for _, u := range s.sent {
+			if now.Sub(u.at) < limit.window {
+				used += u.segments
+			}
+		}
+		if used+segments > limit.max {
+			return notification.NotificationError{
+				Code:    notification.ErrRateLimited.Code,
+				Message: fmt.Sprintf("SMS limit of %d segments per %s reached", limit.max, limit.name),
+			}
+		}
+	}
+
+	s.sent = append(s.sent, usage{at: now, segments: segments})
+	return nil
+}
+
+// release gives back the segments reserved at the time
+func (s *service) release(at time.Time, segments int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.sent {
+		if u.at.Equal(at) && u.segments == segments {
+			s.sent = append(s.sent[:i], s.sent[i+1:]...)
+			return
+		}
+	}
+}
+
+// Sign returns the signature Twilio sends in SignatureHeader with a callback
+// posted to the URL: the base64 HMAC-SHA1, keyed with the auth token, of the
+// URL followed by each form parameter's name and value sorted by name
+func Sign(authToken, callbackURL string, params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(callbackURL))
+	for _, name := range names {
+		for _, value := range params[name] {
+			mac.Write([]byte(name + value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a callback posted to the URL was signed by Twilio
+func Verify(authToken, callbackURL string, params url.Values, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(authToken, callbackURL, params)))
+}
+
+// ParseStatusCallback reads the message SID and the status a status callback
+// reports for it. Statuses that change nothing in the history, such as
+// "sending", report ok false.
+func ParseStatusCallback(params url.Values) (sid string, update notificationhistory.StatusUpdate, ok bool, err error) {
+	sid = params.Get("MessageSid")
+	if sid == "" {
+		return "", update, false, fmt.Errorf("status callback names no MessageSid")
+	}
+	status := params.Get("MessageStatus")
+	if status == "" {
+		return "", update, false, fmt.Errorf("status callback names no MessageStatus")
+	}
+	update, ok = statusUpdate(status, params.Get("ErrorCode"), params.Get("ErrorMessage"))
+	return sid, update, ok, nil
+}
+
+// statusUpdate maps a Twilio message status to the history status
+func statusUpdate(status, errorCode, errorMessage string) (notificationhistory.StatusUpdate, bool) {
+	switch status {
+	case "sent":
+		return notificationhistory.StatusUpdate{Status: notification.NotificationStatusSent}, true
+	case "delivered":
+		return notificationhistory.StatusUpdate{Status: notification.NotificationStatusDelivered}, true
+	case "read":
+		return notificationhistory.StatusUpdate{Status: notification.NotificationStatusRead}, true
+	case "failed", "undelivered", "canceled":
+		reason := status
+		if errorCode != "" {
+			reason += ": error " + errorCode
+		}
+		if errorMessage != "" {
+			reason += ": " + errorMessage
+		}
+		return notificationhistory.StatusUpdate{Status: notification.NotificationStatusFailed, Error: reason}, true
+	default:
+		// accepted, scheduled, queued and sending leave the message pending
+		return notificationhistory.StatusUpdate{}, false
+	}
+}
+
+// Every other notification goes to the decorated service
+
+// SendWelcomeEmail delegates to the decorated service
+func (s *service) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
+	return s.next.SendWelcomeEmail(ctx, userEmail, userName)
+}
+
+// SendPasswordResetEmail delegates to the decorated service
+func (s *service) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	return s.next.SendPasswordResetEmail(ctx, userEmail, resetToken)
+}
+
+// SendProfileUpdateNotification delegates to the decorated service
+func (s *service) SendProfileUpdateNotification(ctx context.Context, userID string, changes map[string]interface{}) error {
+	return s.next.SendProfileUpdateNotification(ctx, userID, changes)
+}
+
+// SendVerificationEmail delegates to the decorated service
+func (s *service) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	return s.next.SendVerificationEmail(ctx, userEmail, verificationToken)
+}
+
+// SendNewDeviceLoginEmail delegates to the decorated service
+func (s *service) SendNewDeviceLoginEmail(ctx context.Context, userEmail string, login notification.DeviceLogin) error {
+	return s.next.SendNewDeviceLoginEmail(ctx, userEmail, login)
+}
+
+// SendMagicLinkEmail delegates to the decorated service
+func (s *service) SendMagicLinkEmail(ctx context.Context, userEmail, magicLinkToken string) error {
+	return s.next.SendMagicLinkEmail(ctx, userEmail, magicLinkToken)
+}
+
+// SendPushNotification delegates to the decorated service
+func (s *service) SendPushNotification(ctx context.Context, userID string, push notification.PushNotification) error {
+	return s.next.SendPushNotification(ctx, userID, push)
+}
+
+// SendBulkEmail delegates to the decorated service
+func (s *service) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	return s.next.SendBulkEmail(ctx, emails)
+}
+
+// SendBulkPush delegates to the decorated service
+func (s *service) SendBulkPush(ctx context.Context, notifications []notification.PushNotification) error {
+	return s.next.SendBulkPush(ctx, notifications)
+}
+
+// GetNotificationHistory delegates to the decorated service
+func (s *service) GetNotificationHistory(ctx context.Context, userID string, limit int) ([]notification.NotificationHistory, error) {
+	return s.next.GetNotificationHistory(ctx, userID, limit)
+}
+
+// MarkAsRead delegates to the decorated service
+func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
+	return s.next.MarkAsRead(ctx, notificationID)
+}
+
+// GetUnreadCount delegates to the decorated service
+func (s *service) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.next.GetUnreadCount(ctx, userID)
+}
@@ -0,0 +1,235 @@
+package twilio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notification/twilio"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	historyMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+)
+
+// fakeTwilio answers the Messages API like Twilio, refusing the numbers in
+// refused, and keeps the forms it was posted
+type fakeTwilio struct {
+	refused map[string]bool
+	posted  []url.Values
+}
+
+func (f *fakeTwilio) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sid, token, _ := r.BasicAuth()
+	if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || sid != "AC123" || token != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_ = r.ParseForm()
+	f.posted = append(f.posted, r.PostForm)
+	w.Header().Set("Content-Type", "application/json")
+	if f.refused[r.PostForm.Get("To")] {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": 21211, "message": "The 'To' number is not a valid phone number.", "status": 400}`))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(`{"sid": "SM` + r.PostForm.Get("To")[1:] + `", "status": "queued"}`))
+}
+
+func newService(t *testing.T, api *fakeTwilio, limit notification.RateLimit) (notification.Service, notificationhistory.Service) {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	history := historyMemory.NewService()
+	service, err := twilio.NewService(mock.NewService(), history, twilio.Config{
+		AccountSID:        "AC123",
+		AuthToken:         "secret",
+		FromNumber:        "+15005550006",
+		StatusCallbackURL: "https://api.example.com/api/notifications/sms/status",
+		RateLimit:         limit,
+		BaseURL:           server.URL,
+	})
+	require.NoError(t, err)
+	return service, history
+}
+
+func TestSendSMSNotification_GivenRecipient_WhenSending_ThenSendsAndRecordsIt(t *testing.T) {
+	tests := []struct {
+		name           string
+		phoneNumber    string
+		message        string
+		expectedError  error
+		expectedPosts  int
+		expectedStatus notification.NotificationStatus
+	}{
+		{
+			name:           "Given an E.164 number, When sending, Then posts to Twilio and records the message pending",
+			phoneNumber:    "+14155552671",
+			message:        "Your code is 123456",
+			expectedPosts:  1,
+			expectedStatus: notification.NotificationStatusPending,
+		},
+		{
+			name:          "Given a number not in E.164 format, When sending, Then fails without calling Twilio",
+			phoneNumber:   "(415) 555-2671",
+			message:       "Your code is 123456",
+			expectedError: notification.ErrInvalidRecipient,
+		},
+		{
+			name:          "Given an empty message, When sending, Then fails without calling Twilio",
+			phoneNumber:   "+14155552671",
+			message:       " ",
+			expectedError: notification.ErrInvalidMessage,
+		},
+		{
+			name:           "Given a number Twilio refuses, When sending, Then fails and records the failure",
+			phoneNumber:    "+15005550001",
+			message:        "Your code is 123456",
+			expectedError:  notification.ErrInvalidRecipient,
+			expectedPosts:  1,
+			expectedStatus: notification.NotificationStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			api := &fakeTwilio{refused: map[string]bool{"+15005550001": true}}
+			service, history := newService(t, api, notification.RateLimit{})
+			ctx := notification.WithRecipient(context.Background(), "user-1")
+
+			// Act
+			err := service.SendSMSNotification(ctx, tt.phoneNumber, tt.message)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, api.posted, tt.expectedPosts)
+			entries, err := history.List(context.Background(), notificationhistory.Filter{UserID: "user-1"})
+			require.NoError(t, err)
+			if tt.expectedPosts == 0 {
+				assert.Empty(t, entries)
+				return
+			}
+			assert.Equal(t, "+15005550006", api.posted[0].Get("From"))
+			assert.Equal(t, "https://api.example.com/api/notifications/sms/status", api.posted[0].Get("StatusCallback"))
+			require.Len(t, entries, 1)
+			assert.Equal(t, notification.NotificationTypeSMS, entries[0].Type)
+			assert.Equal(t, tt.expectedStatus, entries[0].Status)
+			assert.Equal(t, tt.message, entries[0].Body)
+		})
+	}
+}
+
+func TestSendSMSNotification_GivenRateLimit_WhenSending_ThenCountsSegments(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         notification.RateLimit
+		messages      []string
+		expectedSent  int
+		expectedError error
+	}{
+		{
+			name:         "Given short messages within the limit, When sending, Then sends them all",
+			limit:        notification.RateLimit{MaxPerMinute: 3},
+			messages:     []string{"one", "two", "three"},
+			expectedSent: 3,
+		},
+		{
+			name:          "Given a long message billed as several segments, When sending, Then it counts them all",
+			limit:         notification.RateLimit{MaxPerMinute: 3},
+			messages:      []string{strings.Repeat("a", 307), "short"},
+			expectedSent:  1,
+			expectedError: notification.ErrRateLimited,
+		},
+		{
+			name:          "Given an hourly limit, When sending beyond it, Then refuses the message",
+			limit:         notification.RateLimit{MaxPerMinute: 10, MaxPerHour: 2},
+			messages:      []string{"one", "two", "three"},
+			expectedSent:  2,
+			expectedError: notification.ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			api := &fakeTwilio{}
+			service, _ := newService(t, api, tt.limit)
+
+			// Act
+			var err error
+			for _, message := range tt.messages {
+				if err = service.SendSMSNotification(context.Background(), "+14155552671", message); err != nil {
+					break
+				}
+			}
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, api.posted, tt.expectedSent)
+		})
+	}
+}
+
+func TestParseStatusCallback_GivenSignedCallback_WhenParsing_ThenMapsTheStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         url.Values
+		expectedOK     bool
+		expectedStatus notification.NotificationStatus
+		expectedError  string
+	}{
+		{
+			name:           "Given a delivered report, When parsing, Then reports delivered",
+			params:         url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"delivered"}},
+			expectedOK:     true,
+			expectedStatus: notification.NotificationStatusDelivered,
+		},
+		{
+			name:           "Given an undelivered report, When parsing, Then reports a failure with the error code",
+			params:         url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}},
+			expectedOK:     true,
+			expectedStatus: notification.NotificationStatusFailed,
+			expectedError:  "undelivered: error 30003",
+		},
+		{
+			name:   "Given a sending report, When parsing, Then reports nothing to apply",
+			params: url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"sending"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			callbackURL := "https://api.example.com/api/notifications/sms/status"
+			signature := twilio.Sign("secret", callbackURL, tt.params)
+
+			// Act
+			sid, update, ok, err := twilio.ParseStatusCallback(tt.params)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, twilio.Verify("secret", callbackURL, tt.params, signature))
+			assert.False(t, twilio.Verify("other", callbackURL, tt.params, signature))
+			assert.Equal(t, "SM1", sid)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedStatus, update.Status)
+			assert.Equal(t, tt.expectedError, update.Error)
+		})
+	}
+}
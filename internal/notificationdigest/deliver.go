@@ -0,0 +1,44 @@
+package notificationdigest
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+)
+
+// EmailLookup returns the email address a user's digest is sent to
+type EmailLookup func(ctx context.Context, userID string) (string, error)
+
+// SendWith returns a DeliverFunc rendering each digest with the
+// notification_digest template and sending it as a single email to the
+// address emails returns, through the notification service
+func SendWith(service notification.Service, templates notificationtemplate.Service, emails EmailLookup) DeliverFunc {
+	return func(ctx context.Context, digest Digest) error {
+		to, err := emails(ctx, digest.UserID)
+		if err != nil {
+			return err
+		}
+
+		items := make([]map[string]interface{}, len(digest.Items))
+		for i, item := range digest.Items {
+			items[i] = map[string]interface{}{"title": item.Title, "body": item.Body, "type": item.Type}
+		}
+		message, err := templates.Render(ctx, "notification_digest", map[string]interface{}{
+			"count": len(digest.Items),
+			"items": items,
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx = notification.WithRecipient(ctx, digest.UserID)
+		return service.SendBulkEmail(ctx, []notification.EmailNotification{{
+			To:       to,
+			Subject:  message.Subject,
+			Body:     message.Body,
+			BodyHTML: message.HTML,
+			Priority: notification.PriorityLow,
+		}})
+	}
+}
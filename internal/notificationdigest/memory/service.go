@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+)
+
+// service implements notificationdigest.Service interface in memory, for
+// single instances and tests; held notifications are lost on restart
+type service struct {
+	mu       sync.Mutex
+	settings map[string]notificationdigest.Settings
+	pending  map[string]*notificationdigest.Digest
+}
+
+// NewService creates an empty in-memory notification digest store
+func NewService() notificationdigest.Service {
+	return &service{
+		settings: make(map[string]notificationdigest.Settings),
+		pending:  make(map[string]*notificationdigest.Digest),
+	}
+}
+
+// GetSettings returns the user's digest settings
+func (s *service) GetSettings(ctx context.Context, userID string) (*notificationdigest.Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	settings, ok := s.settings[userID]
+	if !ok {
+		settings = notificationdigest.DefaultSettings(userID)
+	}
+	settings.Types = append([]string{}, settings.Types...)
+	return &settings, nil
+}
+
+// UpdateSettings stores the user's digest settings
+func (s *service) UpdateSettings(ctx context.Context, settings notificationdigest.Settings) (*notificationdigest.Settings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	settings = settings.Normalized()
+	settings.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[settings.UserID] = settings
+	settings.Types = append([]string{}, settings.Types...)
+	return &settings, nil
+}
+
+// Hold adds the notification to the user's pending digest
+func (s *service) Hold(ctx context.Context, item notificationdigest.Item, dueAt time.Time) (*notificationdigest.Item, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	if item.ID == "" {
+		item.ID = uuid.New().String()
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hold([]notificationdigest.Item{item}, item.UserID, dueAt)
+	return &item, nil
+}
+
+// hold adds the items to the user's pending digest, due at dueAt or at the
+// pending digest's due time when earlier. The caller holds the lock.
+func (s *service) hold(items []notificationdigest.Item, userID string, dueAt time.Time) {
+	digest, ok := s.pending[userID]
+	if !ok {
+		digest = &notificationdigest.Digest{UserID: userID, DueAt: dueAt}
+		s.pending[userID] = digest
+	}
+	if dueAt.Before(digest.DueAt) {
+		digest.DueAt = dueAt
+	}
+	digest.Items = append(digest.Items, items...)
+	sort.SliceStable(digest.Items, func(i, j int) bool {
+		return digest.Items[i].CreatedAt.Before(digest.Items[j].CreatedAt)
+	})
+}
+
+// Pending returns the notifications held for the user
+func (s *service) Pending(ctx context.Context, userID string) (*notificationdigest.Digest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digest, ok := s.pending[userID]
+	if !ok {
+		return nil, notificationdigest.ErrNotFound
+	}
+	copied := *digest
+	copied.Items = append([]notificationdigest.Item{}, digest.Items...)
+	return &copied, nil
+}
+
+// Deliver takes the due digests out of the pending ones, delivers them
+// without holding the lock and holds those that failed again
+func (s *service) Deliver(ctx context.Context, options notificationdigest.DeliveryOptions, deliver notificationdigest.DeliverFunc) (*notificationdigest.DeliveryReport, error) {
+	due := s.take(options.LimitOrDefault(), time.Now())
+
+	report := &notificationdigest.DeliveryReport{}
+	for _, digest := range due {
+		err := deliver(ctx, digest)
+		report.Count(err)
+		if err != nil && notificationdigest.Retryable(err) {
+			s.mu.Lock()
+			s.hold(digest.Items, digest.UserID, time.Now().Add(options.RetryDelayOrDefault()))
+			s.mu.Unlock()
+		}
+	}
+	return report, nil
+}
+
+// take removes up to limit digests due at now, soonest first
+func (s *service) take(limit int, now time.Time) []notificationdigest.Digest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []notificationdigest.Digest{}
+	for _, digest := range s.pending {
+		if !digest.DueAt.After(now) {
+			due = append(due, *digest)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].DueAt.Equal(due[j].DueAt) {
+			return due[i].UserID < due[j].UserID
+		}
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, digest := range due {
+		delete(s.pending, digest.UserID)
+	}
+	return due
+}
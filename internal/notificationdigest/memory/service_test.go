@@ -0,0 +1,126 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest/memory"
+)
+
+func hold(t *testing.T, service notificationdigest.Service, userID, title string, dueAt time.Time) {
+	t.Helper()
+	_, err := service.Hold(context.Background(), notificationdigest.Item{UserID: userID, Type: "marketing", Title: title}, dueAt)
+	require.NoError(t, err)
+}
+
+func TestSettings_GivenUser_WhenUpdating_ThenStoresNormalizedSettings(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service := memory.NewService()
+
+	// Act
+	before, err := service.GetSettings(ctx, "user-1")
+	require.NoError(t, err)
+	_, err = service.UpdateSettings(ctx, notificationdigest.Settings{
+		UserID:    "user-1",
+		Frequency: notificationdigest.FrequencyDaily,
+		Types:     []string{"system_updates", "marketing", "marketing"},
+	})
+	require.NoError(t, err)
+	after, err := service.GetSettings(ctx, "user-1")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, notificationdigest.FrequencyOff, before.Frequency)
+	assert.Equal(t, notificationdigest.FrequencyDaily, after.Frequency)
+	assert.Equal(t, []string{"marketing", "system_updates"}, after.Types)
+}
+
+func TestDeliver_GivenHeldNotifications_WhenDelivering_ThenSendsEachDueDigestOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service := memory.NewService()
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	hold(t, service, "user-1", "Spring sale", past)
+	hold(t, service, "user-1", "New dashboard", future)
+	hold(t, service, "user-2", "Weekly tips", future)
+
+	var delivered []notificationdigest.Digest
+	deliver := func(ctx context.Context, digest notificationdigest.Digest) error {
+		delivered = append(delivered, digest)
+		return nil
+	}
+
+	// Act
+	report, err := service.Deliver(ctx, notificationdigest.DeliveryOptions{}, deliver)
+	require.NoError(t, err)
+	again, err := service.Deliver(ctx, notificationdigest.DeliveryOptions{}, deliver)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, report.Sent)
+	assert.Equal(t, 0, again.Sent)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "user-1", delivered[0].UserID)
+	require.Len(t, delivered[0].Items, 2)
+	assert.Equal(t, "Spring sale", delivered[0].Items[0].Title)
+	assert.Equal(t, "New dashboard", delivered[0].Items[1].Title)
+	_, err = service.Pending(ctx, "user-1")
+	assert.ErrorIs(t, err, notificationdigest.ErrNotFound)
+	pending, err := service.Pending(ctx, "user-2")
+	require.NoError(t, err)
+	assert.Len(t, pending.Items, 1)
+}
+
+func TestDeliver_GivenFailingDelivery_WhenDelivering_ThenHoldsTheDigestAgainOrDropsIt(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedReport  notificationdigest.DeliveryReport
+		expectedPending int
+	}{
+		{
+			name:            "Given a transient failure, When delivering, Then holds the digest for a retry",
+			err:             errors.New("smtp unavailable"),
+			expectedReport:  notificationdigest.DeliveryReport{Retried: 1},
+			expectedPending: 1,
+		},
+		{
+			name:           "Given a permanent notification error, When delivering, Then drops the digest",
+			err:            notification.NotificationError{Code: "INVALID_RECIPIENT", Message: "invalid"},
+			expectedReport: notificationdigest.DeliveryReport{Dropped: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			service := memory.NewService()
+			hold(t, service, "user-1", "Spring sale", time.Now().Add(-time.Minute))
+
+			// Act
+			report, err := service.Deliver(ctx, notificationdigest.DeliveryOptions{RetryDelay: time.Hour},
+				func(ctx context.Context, digest notificationdigest.Digest) error { return tt.err })
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedReport, *report)
+			pending, err := service.Pending(ctx, "user-1")
+			if tt.expectedPending == 0 {
+				assert.ErrorIs(t, err, notificationdigest.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, pending.Items, tt.expectedPending)
+			assert.True(t, pending.DueAt.After(time.Now().Add(50*time.Minute)))
+		})
+	}
+}
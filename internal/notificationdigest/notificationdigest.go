@@ -0,0 +1,276 @@
+package notificationdigest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+// Service defines the notification digest domain interface - the ONLY
+// interface in this domain. It holds the low-priority notifications users
+// chose to receive as a digest and hands each user's held notifications
+// over as one digest once its window closes.
+type Service interface {
+	// GetSettings returns the user's digest settings; users who never set
+	// them get FrequencyOff
+	GetSettings(ctx context.Context, userID string) (*Settings, error)
+
+	// UpdateSettings validates and stores the user's digest settings.
+	// Notifications already held stay in the pending digest.
+	UpdateSettings(ctx context.Context, settings Settings) (*Settings, error)
+
+	// Hold adds a notification to its user's pending digest, returning it
+	// with its ID assigned. The digest is due at dueAt unless the user
+	// already has one pending, which the notification joins.
+	Hold(ctx context.Context, item Item, dueAt time.Time) (*Item, error)
+
+	// Pending returns the notifications held for the user, oldest first,
+	// or ErrNotFound when there are none
+	Pending(ctx context.Context, userID string) (*Digest, error)
+
+	// Deliver hands up to options.Limit due digests to deliver, soonest
+	// first, and drops their notifications once delivered. Each digest is
+	// taken by one instance even with several running. Digests that fail
+	// are held again for options.RetryDelay, except when deliver fails
+	// with a notification.NotificationError that retrying does not fix.
+	Deliver(ctx context.Context, options DeliveryOptions, deliver DeliverFunc) (*DeliveryReport, error)
+}
+
+// Domain types and data structures
+
+// Frequency is how often a user gets their digest
+type Frequency string
+
+const (
+	FrequencyOff    Frequency = "off"
+	FrequencyHourly Frequency = "hourly"
+	FrequencyDaily  Frequency = "daily"
+)
+
+// DefaultDeliverAt is the local time daily digests are sent at when the user
+// chose none
+const DefaultDeliverAt = "08:00"
+
+// Settings are a user's digest settings. Notifications of the Types the
+// user opted into are held for the digest unless they are
+// notification.PriorityUrgent; all others are sent at once.
+type Settings struct {
+	UserID    string    `json:"user_id"`
+	Frequency Frequency `json:"frequency"`
+
+	// DeliverAt is the local time, "HH:MM" in the user's timezone, daily
+	// digests are sent at; DefaultDeliverAt when empty
+	DeliverAt string `json:"deliver_at,omitempty"`
+
+	// Types are the notification types, as in the user's NotificationTypes
+	// preferences, collected in the digest
+	Types []string `json:"types"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the frequency, the local delivery time and the types
+func (s Settings) Validate() error {
+	if strings.TrimSpace(s.UserID) == "" {
+		return DigestError{Code: ErrInvalidSettings.Code, Message: "user_id is required", Field: "user_id"}
+	}
+	switch s.Frequency {
+	case FrequencyOff, FrequencyHourly, FrequencyDaily:
+	default:
+		return DigestError{Code: ErrInvalidSettings.Code, Message: fmt.Sprintf("frequency must be %s, %s or %s", FrequencyOff, FrequencyHourly, FrequencyDaily), Field: "frequency"}
+	}
+	if s.DeliverAt != "" {
+		if _, err := time.Parse("15:04", s.DeliverAt); err != nil {
+			return DigestError{Code: ErrInvalidSettings.Code, Message: fmt.Sprintf("deliver_at %q must be HH:MM", s.DeliverAt), Field: "deliver_at"}
+		}
+	}
+	for _, notificationType := range s.Types {
+		if strings.TrimSpace(notificationType) == "" {
+			return DigestError{Code: ErrInvalidSettings.Code, Message: "types must not be empty", Field: "types"}
+		}
+	}
+	return nil
+}
+
+// Normalized returns the settings with their types sorted and deduplicated
+func (s Settings) Normalized() Settings {
+	seen := make(map[string]bool, len(s.Types))
+	types := make([]string, 0, len(s.Types))
+	for _, notificationType := range s.Types {
+		if !seen[notificationType] {
+			seen[notificationType] = true
+			types = append(types, notificationType)
+		}
+	}
+	sort.Strings(types)
+	s.Types = types
+	return s
+}
+
+// Collects reports whether notifications of the type and priority are held
+// for the digest: the user gets digests, opted the type in and the
+// notification is not urgent
+func (s Settings) Collects(notificationType string, priority notification.Priority) bool {
+	if s.Frequency == FrequencyOff || s.Frequency == "" || priority == notification.PriorityUrgent {
+		return false
+	}
+	for _, collected := range s.Types {
+		if collected == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// NextDelivery returns when a digest started at now is due: the top of the
+// next hour for hourly digests, or the next DeliverAt in the timezone for
+// daily ones. An empty timezone is UTC.
+func (s Settings) NextDelivery(now time.Time, timezone string) (time.Time, error) {
+	if s.Frequency == FrequencyHourly {
+		return now.Truncate(time.Hour).Add(time.Hour), nil
+	}
+	deliverAt := s.DeliverAt
+	if deliverAt == "" {
+		deliverAt = DefaultDeliverAt
+	}
+	return notificationschedule.NextLocalTime(now, timezone, deliverAt)
+}
+
+// DefaultSettings returns the settings of a user who never set them
+func DefaultSettings(userID string) Settings {
+	return Settings{UserID: userID, Frequency: FrequencyOff, Types: []string{}}
+}
+
+// Item is a notification held for a digest
+type Item struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+
+	// Type is the notification type the user opted into the digest
+	Type string `json:"type"`
+
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Priority  notification.Priority  `json:"priority,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// Validate checks that the notification has a user, a type and a title
+func (i Item) Validate() error {
+	if strings.TrimSpace(i.UserID) == "" {
+		return DigestError{Code: ErrInvalidItem.Code, Message: "user_id is required", Field: "user_id"}
+	}
+	if strings.TrimSpace(i.Type) == "" {
+		return DigestError{Code: ErrInvalidItem.Code, Message: "type is required", Field: "type"}
+	}
+	if strings.TrimSpace(i.Title) == "" {
+		return DigestError{Code: ErrInvalidItem.Code, Message: "title is required", Field: "title"}
+	}
+	return nil
+}
+
+// Digest is the notifications held for a user, sent together once DueAt
+// has come
+type Digest struct {
+	UserID string    `json:"user_id"`
+	Items  []Item    `json:"items"`
+	DueAt  time.Time `json:"due_at"`
+}
+
+// DeliverFunc delivers one digest
+type DeliverFunc func(ctx context.Context, digest Digest) error
+
+// DeliveryOptions configures a delivery run
+type DeliveryOptions struct {
+	// Limit caps the digests delivered; DefaultDeliveryLimit when not
+	// positive
+	Limit int
+
+	// RetryDelay is how long digests that failed are held before the next
+	// attempt; DefaultRetryDelay when not positive
+	RetryDelay time.Duration
+}
+
+// Delivery defaults
+const (
+	DefaultDeliveryLimit = 100
+	DefaultRetryDelay    = 5 * time.Minute
+)
+
+// LimitOrDefault returns the number of digests to deliver
+func (o DeliveryOptions) LimitOrDefault() int {
+	if o.Limit <= 0 {
+		return DefaultDeliveryLimit
+	}
+	return o.Limit
+}
+
+// RetryDelayOrDefault returns how long digests that failed are held
+func (o DeliveryOptions) RetryDelayOrDefault() time.Duration {
+	if o.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+	return o.RetryDelay
+}
+
+// Retryable reports whether a digest that failed to deliver with err is held
+// again. Notification errors such as an invalid recipient fail again on
+// retry, except rate limits, which lift.
+func Retryable(err error) bool {
+	var notificationErr notification.NotificationError
+	if !errors.As(err, &notificationErr) {
+		return true
+	}
+	return notificationErr.Code == notification.ErrRateLimited.Code
+}
+
+// DeliveryReport summarizes a delivery run: the digests sent, those held
+// again to be retried and those dropped for good
+type DeliveryReport struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Dropped int `json:"dropped"`
+}
+
+// Count adds the outcome of delivering a digest to the report
+func (r *DeliveryReport) Count(err error) {
+	switch {
+	case err == nil:
+		r.Sent++
+	case Retryable(err):
+		r.Retried++
+	default:
+		r.Dropped++
+	}
+}
+
+// DigestError represents notification digest domain errors
+type DigestError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e DigestError) Error() string {
+	return e.Message
+}
+
+// Is matches digest errors by code so detailed errors match their sentinel
+func (e DigestError) Is(target error) bool {
+	t, ok := target.(DigestError)
+	return ok && t.Code == e.Code
+}
+
+// Common notification digest errors
+var (
+	ErrNotFound        = DigestError{Code: "DIGEST_NOT_FOUND", Message: "No notifications are held for a digest"}
+	ErrInvalidSettings = DigestError{Code: "INVALID_DIGEST_SETTINGS", Message: "Invalid digest settings"}
+	ErrInvalidItem     = DigestError{Code: "INVALID_DIGEST_ITEM", Message: "Invalid digest notification"}
+)
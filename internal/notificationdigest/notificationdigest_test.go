@@ -0,0 +1,166 @@
+package notificationdigest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+)
+
+func TestCollects_GivenSettings_WhenCheckingNotification_ThenHoldsOnlyOptedInTypesBelowUrgent(t *testing.T) {
+	daily := notificationdigest.Settings{UserID: "user-1", Frequency: notificationdigest.FrequencyDaily, Types: []string{"marketing"}}
+
+	tests := []struct {
+		name     string
+		settings notificationdigest.Settings
+		typ      string
+		priority notification.Priority
+		expected bool
+	}{
+		{name: "Given an opted-in low priority type, When checking, Then holds it", settings: daily, typ: "marketing", priority: notification.PriorityLow, expected: true},
+		{name: "Given an opted-in type without priority, When checking, Then holds it", settings: daily, typ: "marketing", expected: true},
+		{name: "Given an urgent notification, When checking, Then sends it at once", settings: daily, typ: "marketing", priority: notification.PriorityUrgent},
+		{name: "Given a type not opted in, When checking, Then sends it at once", settings: daily, typ: "task_assigned", priority: notification.PriorityLow},
+		{name: "Given digests turned off, When checking, Then sends it at once", settings: notificationdigest.Settings{Frequency: notificationdigest.FrequencyOff, Types: []string{"marketing"}}, typ: "marketing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			collects := tt.settings.Collects(tt.typ, tt.priority)
+
+			// Assert
+			assert.Equal(t, tt.expected, collects)
+		})
+	}
+}
+
+func TestNextDelivery_GivenFrequency_WhenResolving_ThenClosesTheWindow(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 2, 12, 25, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		settings notificationdigest.Settings
+		timezone string
+		expected time.Time
+	}{
+		{
+			name:     "Given hourly digests, When resolving, Then is due at the top of the next hour",
+			settings: notificationdigest.Settings{Frequency: notificationdigest.FrequencyHourly},
+			expected: time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Given daily digests without a time, When resolving, Then is due at the default local time",
+			settings: notificationdigest.Settings{Frequency: notificationdigest.FrequencyDaily},
+			timezone: "Asia/Tokyo",
+			expected: time.Date(2026, 3, 3, 8, 0, 0, 0, tokyo),
+		},
+		{
+			name:     "Given daily digests at a local time, When resolving, Then is due at its next occurrence",
+			settings: notificationdigest.Settings{Frequency: notificationdigest.FrequencyDaily, DeliverAt: "18:30"},
+			expected: time.Date(2026, 3, 2, 18, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			dueAt, err := tt.settings.NextDelivery(now, tt.timezone)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(dueAt), "expected %s, got %s", tt.expected, dueAt)
+		})
+	}
+}
+
+func TestValidate_GivenInvalidSettings_WhenValidating_ThenNamesTheField(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      notificationdigest.Settings
+		expectedField string
+	}{
+		{name: "Given an unknown frequency, When validating, Then rejects it", settings: notificationdigest.Settings{UserID: "user-1", Frequency: "weekly"}, expectedField: "frequency"},
+		{name: "Given a malformed local time, When validating, Then rejects it", settings: notificationdigest.Settings{UserID: "user-1", Frequency: notificationdigest.FrequencyDaily, DeliverAt: "8am"}, expectedField: "deliver_at"},
+		{name: "Given an empty type, When validating, Then rejects it", settings: notificationdigest.Settings{UserID: "user-1", Frequency: notificationdigest.FrequencyHourly, Types: []string{" "}}, expectedField: "types"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.settings.Validate()
+
+			// Assert
+			var digestErr notificationdigest.DigestError
+			require.ErrorAs(t, err, &digestErr)
+			assert.ErrorIs(t, err, notificationdigest.ErrInvalidSettings)
+			assert.Equal(t, tt.expectedField, digestErr.Field)
+		})
+	}
+}
+
+// emailRecorder records the emails sent through it
+type emailRecorder struct {
+	notification.Service
+	sent []notification.EmailNotification
+}
+
+func (r *emailRecorder) SendBulkEmail(ctx context.Context, emails []notification.EmailNotification) error {
+	r.sent = append(r.sent, emails...)
+	return nil
+}
+
+func TestSendWith_GivenDigest_WhenDelivered_ThenSendsOneSummaryEmail(t *testing.T) {
+	// Arrange
+	sender := &emailRecorder{Service: notificationMock.NewService()}
+	emails := func(ctx context.Context, userID string) (string, error) { return userID + "@example.com", nil }
+	deliver := notificationdigest.SendWith(sender, embedded.MustNewService(embedded.Config{}), emails)
+
+	// Act
+	err := deliver(context.Background(), notificationdigest.Digest{
+		UserID: "user-1",
+		Items: []notificationdigest.Item{
+			{UserID: "user-1", Type: "marketing", Title: "Spring sale", Body: "20% off"},
+			{UserID: "user-1", Type: "system_updates", Title: "New dashboard"},
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "user-1@example.com", sender.sent[0].To)
+	assert.Equal(t, "Your notification digest: 2 new", sender.sent[0].Subject)
+	assert.Contains(t, sender.sent[0].Body, "- Spring sale: 20% off\n- New dashboard")
+}
+
+func TestRetryable_GivenDeliveryError_WhenChecking_ThenRetriesAllButPermanentNotificationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Given a transient error, When checking, Then retries", err: errors.New("connection reset"), expected: true},
+		{name: "Given a rate limit, When checking, Then retries", err: notification.ErrRateLimited, expected: true},
+		{name: "Given an invalid recipient, When checking, Then drops the digest", err: notification.NotificationError{Code: "INVALID_RECIPIENT", Message: "invalid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			retryable := notificationdigest.Retryable(tt.err)
+
+			// Assert
+			assert.Equal(t, tt.expected, retryable)
+		})
+	}
+}
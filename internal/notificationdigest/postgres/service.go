@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationdigest"
+)
+
+// itemColumns are the notification_digest_items columns scanItem reads, in
+// order
+const itemColumns = `id, user_id, type, title, body, data, priority, created_at, due_at`
+
+// service implements notificationdigest.Service on the
+// notification_digest_settings and notification_digest_items tables.
+// Delivery deletes the due users' items in one statement before handing
+// them over, so each digest goes to one instance; a digest whose instance
+// stops while delivering it is lost.
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed notification digest store
+func NewService(pool *pgxpool.Pool) notificationdigest.Service {
+	return &service{pool: pool}
+}
+
+// GetSettings returns the user's digest settings
+func (s *service) GetSettings(ctx context.Context, userID string) (*notificationdigest.Settings, error) {
+	var settings notificationdigest.Settings
+	err := s.pool.QueryRow(ctx, `
+		SELECT user_id, frequency, deliver_at, types, updated_at
+		FROM notification_digest_settings WHERE user_id = $1`, userID).
+		Scan(&settings.UserID, &settings.Frequency, &settings.DeliverAt, &settings.Types, &settings.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = notificationdigest.DefaultSettings(userID)
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if settings.Types == nil {
+		settings.Types = []string{}
+	}
+	return &settings, nil
+}
+
+// UpdateSettings upserts the user's digest settings
+func (s *service) UpdateSettings(ctx context.Context, settings notificationdigest.Settings) (*notificationdigest.Settings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	settings = settings.Normalized()
+	settings.UpdatedAt = time.Now()
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO notification_digest_settings (user_id, frequency, deliver_at, types, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET frequency = EXCLUDED.frequency, deliver_at = EXCLUDED.deliver_at, types = EXCLUDED.types,
+			updated_at = EXCLUDED.updated_at`,
+		settings.UserID, settings.Frequency, settings.DeliverAt, settings.Types, settings.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Hold inserts the notification, due with the user's pending digest if any
+func (s *service) Hold(ctx context.Context, item notificationdigest.Item, dueAt time.Time) (*notificationdigest.Item, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	if item.ID == "" {
+		item.ID = uuid.New().String()
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	if err := s.insert(ctx, []notificationdigest.Item{item}, dueAt); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// insert adds the items of one user, due at dueAt or at the user's pending
+// digest's due time when earlier
+func (s *service) insert(ctx context.Context, items []notificationdigest.Item, dueAt time.Time) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, item := range items {
+			data, err := json.Marshal(item.Data)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, `
+				INSERT INTO notification_digest_items (id, user_id, type, title, body, data, priority, created_at, due_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+					LEAST($9, COALESCE((SELECT MIN(due_at) FROM notification_digest_items WHERE user_id = $2), $9)))`,
+				item.ID, item.UserID, item.Type, item.Title, item.Body, data, item.Priority, item.CreatedAt, dueAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Pending returns the notifications held for the user
+func (s *service) Pending(ctx context.Context, userID string) (*notificationdigest.Digest, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+itemColumns+` FROM notification_digest_items
+		WHERE user_id = $1 ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	digests, err := scanDigests(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(digests) == 0 {
+		return nil, notificationdigest.ErrNotFound
+	}
+	return &digests[0], nil
+}
+
+// Deliver deletes the items of the users whose digest is due, delivers the
+// digests outside any transaction and inserts those that failed again
+func (s *service) Deliver(ctx context.Context, options notificationdigest.DeliveryOptions, deliver notificationdigest.DeliverFunc) (*notificationdigest.DeliveryReport, error) {
+	rows, err := s.pool.Query(ctx, `
+		DELETE FROM notification_digest_items
+		WHERE user_id IN (
+			SELECT user_id FROM notification_digest_items
+			GROUP BY user_id
+			HAVING MIN(due_at) <= $1
+			ORDER BY MIN(due_at), user_id
+			LIMIT $2
+		)
+		RETURNING `+itemColumns,
+		time.Now(), options.LimitOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	due, err := scanDigests(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &notificationdigest.DeliveryReport{}
+	for _, digest := range due {
+		deliverErr := deliver(ctx, digest)
+		report.Count(deliverErr)
+		if deliverErr == nil || !notificationdigest.Retryable(deliverErr) {
+			continue
+		}
+		if err := s.insert(ctx, digest.Items, time.Now().Add(options.RetryDelayOrDefault())); err != nil {
+			return report, fmt.Errorf("failed to hold the digest of user %s again: %w", digest.UserID, err)
+		}
+	}
+	return report, nil
+}
+
+// scanDigests groups the items of rows into one digest per user, soonest
+// due first with their items oldest first, closing the rows
+func scanDigests(rows pgx.Rows) ([]notificationdigest.Digest, error) {
+	defer rows.Close()
+	byUser := make(map[string]*notificationdigest.Digest)
+	for rows.Next() {
+		item, dueAt, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		digest, ok := byUser[item.UserID]
+		if !ok {
+			digest = &notificationdigest.Digest{UserID: item.UserID, DueAt: dueAt}
+			byUser[item.UserID] = digest
+		}
+		if dueAt.Before(digest.DueAt) {
+			digest.DueAt = dueAt
+		}
+		digest.Items = append(digest.Items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	digests := make([]notificationdigest.Digest, 0, len(byUser))
+	for _, digest := range byUser {
+		sort.SliceStable(digest.Items, func(i, j int) bool {
+			return digest.Items[i].CreatedAt.Before(digest.Items[j].CreatedAt)
+		})
+		digests = append(digests, *digest)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		if digests[i].DueAt.Equal(digests[j].DueAt) {
+			return digests[i].UserID < digests[j].UserID
+		}
+		return digests[i].DueAt.Before(digests[j].DueAt)
+	})
+	return digests, nil
+}
+
+// scanItem reads one held notification in itemColumns order, with the due
+// time of its digest
+func scanItem(row pgx.Row) (*notificationdigest.Item, time.Time, error) {
+	var item notificationdigest.Item
+	var data []byte
+	var dueAt time.Time
+	if err := row.Scan(&item.ID, &item.UserID, &item.Type, &item.Title, &item.Body, &data, &item.Priority,
+		&item.CreatedAt, &dueAt); err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := json.Unmarshal(data, &item.Data); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode digest notification %s: %w", item.ID, err)
+	}
+	return &item, dueAt, nil
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+)
+
+// service implements notificationhistory.Service interface in memory, for
+// single instances and tests
+type service struct {
+	mu      sync.RWMutex
+	entries []notification.NotificationHistory
+}
+
+// NewService creates an empty in-memory notification history
+func NewService() notificationhistory.Service {
+	return &service{}
+}
+
+// Record stores an entry, giving it an ID and creation time when it has none
+func (s *service) Record(ctx context.Context, entry notification.NotificationHistory) (*notification.NotificationHistory, error) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return &entry, nil
+}
+
+// Get returns an entry
+func (s *service) Get(ctx context.Context, id string) (*notification.NotificationHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, entry := range s.entries {
+		if entry.ID == id {
+			return &entry, nil
+		}
+	}
+	return nil, notificationhistory.ErrEntryNotFound
+}
+
+// UpdateStatus applies a status report to an entry
+func (s *service) UpdateStatus(ctx context.Context, id string, update notificationhistory.StatusUpdate) (*notification.NotificationHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.entries {
+		if s.entries[i].ID == id {
+			update.Apply(&s.entries[i])
+			entry := s.entries[i]
+			return &entry, nil
+		}
+	}
+	return nil, notificationhistory.ErrEntryNotFound
+}
+
+// List returns matching entries, newest first
+func (s *service) List(ctx context.Context, filter notificationhistory.Filter) ([]notification.NotificationHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []notification.NotificationHistory{}
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+		if filter.Matches(s.entries[i]) {
+			result = append(result, s.entries[i])
+		}
+	}
+	return result, nil
+}
+
+// Query returns a page of matching entries, newest first
+func (s *service) Query(ctx context.Context, query notificationhistory.Query) (*notificationhistory.Page, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	var cursor *notificationhistory.Cursor
+	if query.Cursor != "" {
+		decoded, err := notificationhistory.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &decoded
+	}
+
+	s.mu.RLock()
+	matching := []notification.NotificationHistory{}
+	for _, entry := range s.entries {
+		if query.Matches(entry) && (cursor == nil || cursor.Follows(entry)) {
+			matching = append(matching, entry)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].CreatedAt.Equal(matching[j].CreatedAt) {
+			return matching[i].ID > matching[j].ID
+		}
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+
+	limit := query.LimitOrDefault()
+	page := &notificationhistory.Page{Notifications: matching, Limit: limit}
+	if len(matching) > limit {
+		page.Notifications = matching[:limit]
+		page.NextCursor = notificationhistory.CursorAfter(matching[limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// DeleteUser removes the user's entries
+func (s *service) DeleteUser(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if entry.UserID != userID {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(s.entries) - len(kept)
+	s.entries = kept
+	return removed, nil
+}
+
+// Stats counts the matching entries by channel and status
+func (s *service) Stats(ctx context.Context, filter notificationhistory.Filter) (*notificationhistory.Stats, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Status = ""
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := []notificationhistory.StatusCount{}
+	for _, entry := range s.entries {
+		if filter.Matches(entry) {
+			counts = append(counts, notificationhistory.StatusCount{Channel: entry.Type, Status: entry.Status, Count: 1})
+		}
+	}
+	return notificationhistory.Summarize(counts), nil
+}
@@ -0,0 +1,261 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+)
+
+func TestUpdateStatus_GivenReport_WhenUpdating_ThenAppliesIt(t *testing.T) {
+	sentAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	deliveredAt := sentAt.Add(time.Minute)
+
+	tests := []struct {
+		name                 string
+		reports              []notificationhistory.StatusUpdate
+		expectedStatus       notification.NotificationStatus
+		expectedSentAt       *time.Time
+		expectedFailureCount int
+		expectedLastError    string
+	}{
+		{
+			name: "Given sent then delivered reports, When updating, Then is delivered and sent at the first report",
+			reports: []notificationhistory.StatusUpdate{
+				{Status: notification.NotificationStatusSent, At: sentAt},
+				{Status: notification.NotificationStatusDelivered, At: deliveredAt},
+			},
+			expectedStatus: notification.NotificationStatusDelivered,
+			expectedSentAt: &sentAt,
+		},
+		{
+			name: "Given a sent report arriving after delivered, When updating, Then stays delivered",
+			reports: []notificationhistory.StatusUpdate{
+				{Status: notification.NotificationStatusDelivered, At: deliveredAt},
+				{Status: notification.NotificationStatusSent, At: sentAt},
+			},
+			expectedStatus: notification.NotificationStatusDelivered,
+			expectedSentAt: &deliveredAt,
+		},
+		{
+			name: "Given a failure report, When updating, Then counts the failure with its error",
+			reports: []notificationhistory.StatusUpdate{
+				{Status: notification.NotificationStatusFailed, Error: "undelivered: error 30003", At: sentAt},
+			},
+			expectedStatus:       notification.NotificationStatusFailed,
+			expectedFailureCount: 1,
+			expectedLastError:    "undelivered: error 30003",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			history := memory.NewService()
+			entry, err := history.Record(ctx, notification.NotificationHistory{
+				UserID: "user-1",
+				Type:   notification.NotificationTypeSMS,
+				Status: notification.NotificationStatusPending,
+			})
+			require.NoError(t, err)
+
+			// Act
+			for _, report := range tt.reports {
+				_, err = history.UpdateStatus(ctx, entry.ID, report)
+				require.NoError(t, err)
+			}
+
+			// Assert
+			updated, err := history.Get(ctx, entry.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, updated.Status)
+			assert.Equal(t, tt.expectedSentAt, updated.SentAt)
+			assert.Equal(t, tt.expectedFailureCount, updated.FailureCount)
+			assert.Equal(t, tt.expectedLastError, updated.LastError)
+		})
+	}
+}
+
+func TestUpdateStatus_GivenUnknownEntry_WhenUpdating_ThenFails(t *testing.T) {
+	// Arrange
+	history := memory.NewService()
+
+	// Act
+	_, err := history.UpdateStatus(context.Background(), "SM-missing", notificationhistory.StatusUpdate{Status: notification.NotificationStatusSent})
+
+	// Assert
+	assert.ErrorIs(t, err, notificationhistory.ErrEntryNotFound)
+}
+
+func TestList_GivenFilter_WhenListing_ThenReturnsMatchingEntriesNewestFirst(t *testing.T) {
+	tests := []struct {
+		name           string
+		filter         notificationhistory.Filter
+		expectedBodies []string
+	}{
+		{
+			name:           "Given a user, When listing, Then returns the user's entries newest first",
+			filter:         notificationhistory.Filter{UserID: "user-1"},
+			expectedBodies: []string{"third", "first"},
+		},
+		{
+			name:           "Given a type and a limit, When listing, Then returns the newest matching entries",
+			filter:         notificationhistory.Filter{Type: notification.NotificationTypeSMS, Limit: 1},
+			expectedBodies: []string{"second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			history := memory.NewService()
+			for _, entry := range []notification.NotificationHistory{
+				{UserID: "user-1", Type: notification.NotificationTypeSMS, Body: "first"},
+				{UserID: "user-2", Type: notification.NotificationTypeSMS, Body: "second"},
+				{UserID: "user-1", Type: notification.NotificationTypeEmail, Body: "third"},
+			} {
+				_, err := history.Record(ctx, entry)
+				require.NoError(t, err)
+			}
+
+			// Act
+			entries, err := history.List(ctx, tt.filter)
+
+			// Assert
+			require.NoError(t, err)
+			bodies := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				bodies = append(bodies, entry.Body)
+			}
+			assert.Equal(t, tt.expectedBodies, bodies)
+		})
+	}
+}
+
+func TestDeleteUser_GivenEntriesOfSeveralUsers_WhenDeleting_ThenRemovesOnlyTheUsers(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	history := memory.NewService()
+	for _, entry := range []notification.NotificationHistory{
+		{UserID: "user-1", Type: notification.NotificationTypeSMS, Body: "first"},
+		{UserID: "user-2", Type: notification.NotificationTypeSMS, Body: "second"},
+		{UserID: "user-1", Type: notification.NotificationTypeEmail, Body: "third"},
+	} {
+		_, err := history.Record(ctx, entry)
+		require.NoError(t, err)
+	}
+
+	// Act
+	removed, err := history.DeleteUser(ctx, "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	remaining, err := history.List(ctx, notificationhistory.Filter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "second", remaining[0].Body)
+}
+
+func TestQuery_GivenTimeRange_WhenPaging_ThenReturnsEachMatchingEntryOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	history := memory.NewService()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, body := range []string{"too early", "first", "second", "third", "too late"} {
+		_, err := history.Record(ctx, notification.NotificationHistory{
+			UserID:    "user-1",
+			Type:      notification.NotificationTypeEmail,
+			Body:      body,
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	from, to := start.Add(time.Hour), start.Add(4*time.Hour)
+	query := notificationhistory.Query{Filter: notificationhistory.Filter{UserID: "user-1", From: &from, To: &to, Limit: 2}}
+
+	// Act
+	first, err := history.Query(ctx, query)
+	require.NoError(t, err)
+	query.Cursor = first.NextCursor
+	second, err := history.Query(ctx, query)
+	require.NoError(t, err)
+
+	// Assert
+	bodies := []string{}
+	for _, entry := range append(first.Notifications, second.Notifications...) {
+		bodies = append(bodies, entry.Body)
+	}
+	assert.Equal(t, []string{"third", "second", "first"}, bodies)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestQuery_GivenInvalidQuery_WhenQuerying_ThenRejectsIt(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		query         notificationhistory.Query
+		expectedError error
+	}{
+		{name: "Given a reversed time range, When querying, Then rejects it", query: notificationhistory.Query{Filter: notificationhistory.Filter{From: &from, To: &to}}, expectedError: notificationhistory.ErrInvalidQuery},
+		{name: "Given a malformed cursor, When querying, Then rejects it", query: notificationhistory.Query{Cursor: "bogus"}, expectedError: notificationhistory.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			history := memory.NewService()
+
+			// Act
+			_, err := history.Query(context.Background(), tt.query)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestStats_GivenEntriesOnSeveralChannels_WhenAggregating_ThenComputesRatesPerChannel(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	history := memory.NewService()
+	for _, entry := range []notification.NotificationHistory{
+		{UserID: "user-1", Type: notification.NotificationTypeSMS, Status: notification.NotificationStatusDelivered},
+		{UserID: "user-1", Type: notification.NotificationTypeSMS, Status: notification.NotificationStatusDelivered},
+		{UserID: "user-2", Type: notification.NotificationTypeSMS, Status: notification.NotificationStatusSent},
+		{UserID: "user-2", Type: notification.NotificationTypeSMS, Status: notification.NotificationStatusFailed},
+		{UserID: "user-2", Type: notification.NotificationTypeSMS, Status: notification.NotificationStatusPending},
+		{UserID: "user-1", Type: notification.NotificationTypeEmail, Status: notification.NotificationStatusFailed},
+	} {
+		_, err := history.Record(ctx, entry)
+		require.NoError(t, err)
+	}
+
+	// Act
+	stats, err := history.Stats(ctx, notificationhistory.Filter{Status: notification.NotificationStatusFailed})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats.Channels, 2)
+	email, sms := stats.Channels[0], stats.Channels[1]
+	assert.Equal(t, notification.NotificationTypeEmail, email.Channel)
+	assert.Equal(t, 1.0, email.FailureRate)
+	assert.Equal(t, notification.NotificationTypeSMS, sms.Channel)
+	assert.Equal(t, 5, sms.Total)
+	assert.Equal(t, 1, sms.Pending)
+	assert.Equal(t, 0.5, sms.DeliveryRate)
+	assert.Equal(t, 0.25, sms.FailureRate)
+	assert.Equal(t, 6, stats.Overall.Total)
+	assert.Equal(t, 0.4, stats.Overall.DeliveryRate)
+}
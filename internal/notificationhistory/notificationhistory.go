@@ -0,0 +1,298 @@
+package notificationhistory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// Service defines the notification history domain interface - the ONLY
+// interface in this domain. It keeps the notifications sent to users with
+// their delivery status, which providers update as they learn of it.
+type Service interface {
+	// Record stores a notification and returns it with its ID and creation
+	// time assigned when it had none
+	Record(ctx context.Context, entry notification.NotificationHistory) (*notification.NotificationHistory, error)
+
+	// Get returns a notification, or ErrEntryNotFound
+	Get(ctx context.Context, id string) (*notification.NotificationHistory, error)
+
+	// UpdateStatus applies a delivery status report to a notification, or
+	// returns ErrEntryNotFound
+	UpdateStatus(ctx context.Context, id string, update StatusUpdate) (*notification.NotificationHistory, error)
+
+	// List returns matching notifications, newest first
+	List(ctx context.Context, filter Filter) ([]notification.NotificationHistory, error)
+
+	// Query returns a page of matching notifications, newest first
+	Query(ctx context.Context, query Query) (*Page, error)
+
+	// Stats counts the matching notifications by channel and status, with
+	// the delivery and failure rates of each channel. The filter's Status
+	// and Limit are ignored.
+	Stats(ctx context.Context, filter Filter) (*Stats, error)
+
+	// DeleteUser removes every notification of the user, as when their
+	// personal data is erased, and returns how many were removed
+	DeleteUser(ctx context.Context, userID string) (int, error)
+}
+
+// Domain types and data structures
+
+// StatusUpdate is a delivery status reported for a notification, such as a
+// provider's delivery receipt
+type StatusUpdate struct {
+	Status notification.NotificationStatus `json:"status"`
+
+	// Error explains a failed delivery
+	Error string `json:"error,omitempty"`
+
+	// At is when the status was reached; zero uses the time it is applied
+	At time.Time `json:"at,omitempty"`
+}
+
+// Apply updates the entry with the reported status. The first sent or
+// delivered report stamps SentAt, and each failure is counted with its error.
+// Reports arriving out of order never move a delivered or read notification
+// back to sent.
+func (u StatusUpdate) Apply(entry *notification.NotificationHistory) {
+	at := u.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch u.Status {
+	case notification.NotificationStatusSent:
+		if entry.Status == notification.NotificationStatusDelivered || entry.Status == notification.NotificationStatusRead {
+			return
+		}
+	case notification.NotificationStatusFailed:
+		entry.FailureCount++
+		entry.LastError = u.Error
+	case notification.NotificationStatusRead:
+		if entry.ReadAt == nil {
+			entry.ReadAt = &at
+		}
+	}
+	if entry.SentAt == nil && (u.Status == notification.NotificationStatusSent || u.Status == notification.NotificationStatusDelivered) {
+		entry.SentAt = &at
+	}
+	entry.Status = u.Status
+}
+
+// Filter narrows the notifications returned by List
+type Filter struct {
+	UserID string                          `json:"user_id,omitempty"`
+	Type   notification.NotificationType   `json:"type,omitempty"`
+	Status notification.NotificationStatus `json:"status,omitempty"`
+	From   *time.Time                      `json:"from,omitempty"` // Created at or after
+	To     *time.Time                      `json:"to,omitempty"`   // Created before
+	Limit  int                             `json:"limit,omitempty"`
+}
+
+// Matches reports whether the entry satisfies the filter
+func (f Filter) Matches(entry notification.NotificationHistory) bool {
+	if f.UserID != "" && f.UserID != entry.UserID {
+		return false
+	}
+	if f.Type != "" && f.Type != entry.Type {
+		return false
+	}
+	if f.From != nil && entry.CreatedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && !entry.CreatedAt.Before(*f.To) {
+		return false
+	}
+	return f.Status == "" || f.Status == entry.Status
+}
+
+// Validate checks that the time range is not reversed
+func (f Filter) Validate() error {
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return HistoryError{Code: ErrInvalidQuery.Code, Message: "to must not be before from"}
+	}
+	return nil
+}
+
+// Page sizes of Query
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Query selects a page of matching notifications. Pages after the first
+// are selected with the NextCursor of the previous one, which stays stable
+// while new notifications are recorded.
+type Query struct {
+	Filter
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// LimitOrDefault returns the page size: DefaultLimit when not positive, at
+// most MaxLimit
+func (q Query) LimitOrDefault() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultLimit
+	case q.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return q.Limit
+	}
+}
+
+// Page is one page of notifications returned by Query
+type Page struct {
+	Notifications []notification.NotificationHistory `json:"notifications"`
+	Limit         int                                `json:"limit"`
+	NextCursor    string                             `json:"next_cursor,omitempty"` // Set when more notifications follow
+}
+
+// Cursor is the position after the last notification of a page
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// CursorAfter returns the cursor of a page ending with the entry
+func CursorAfter(entry notification.NotificationHistory) Cursor {
+	return Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
+
+// Encode returns the opaque form of the cursor used in Page.NextCursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor issued in Page.NextCursor
+func DecodeCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Follows reports whether the entry comes after the cursor, newest first
+func (c Cursor) Follows(entry notification.NotificationHistory) bool {
+	if entry.CreatedAt.Equal(c.CreatedAt) {
+		return entry.ID < c.ID
+	}
+	return entry.CreatedAt.Before(c.CreatedAt)
+}
+
+// Stats are the delivery statistics of the notifications matching a filter,
+// per channel and over all channels
+type Stats struct {
+	Channels []ChannelStats `json:"channels"`
+	Overall  ChannelStats   `json:"overall"`
+}
+
+// ChannelStats counts the notifications of a channel by status.
+// DeliveryRate is the share of the attempted notifications, those neither
+// pending nor cancelled, confirmed delivered or read; FailureRate the share
+// that failed. Notifications only sent to a provider that reports no
+// delivery count in neither.
+type ChannelStats struct {
+	Channel      notification.NotificationType `json:"channel,omitempty"`
+	Total        int                           `json:"total"`
+	Pending      int                           `json:"pending"`
+	Sent         int                           `json:"sent"`
+	Delivered    int                           `json:"delivered"`
+	Read         int                           `json:"read"`
+	Failed       int                           `json:"failed"`
+	Cancelled    int                           `json:"cancelled"`
+	DeliveryRate float64                       `json:"delivery_rate"`
+	FailureRate  float64                       `json:"failure_rate"`
+}
+
+// Add counts n notifications in the status
+func (c *ChannelStats) Add(status notification.NotificationStatus, n int) {
+	c.Total += n
+	switch status {
+	case notification.NotificationStatusPending:
+		c.Pending += n
+	case notification.NotificationStatusSent:
+		c.Sent += n
+	case notification.NotificationStatusDelivered:
+		c.Delivered += n
+	case notification.NotificationStatusRead:
+		c.Read += n
+	case notification.NotificationStatusFailed:
+		c.Failed += n
+	case notification.NotificationStatusCancelled:
+		c.Cancelled += n
+	}
+}
+
+// rates computes the delivery and failure rates from the counts
+func (c *ChannelStats) rates() {
+	attempted := c.Total - c.Pending - c.Cancelled
+	if attempted <= 0 {
+		c.DeliveryRate, c.FailureRate = 0, 0
+		return
+	}
+	c.DeliveryRate = float64(c.Delivered+c.Read) / float64(attempted)
+	c.FailureRate = float64(c.Failed) / float64(attempted)
+}
+
+// StatusCount is the number of notifications of a channel in a status
+type StatusCount struct {
+	Channel notification.NotificationType
+	Status  notification.NotificationStatus
+	Count   int
+}
+
+// Summarize builds the statistics of the counts, with the channels ordered
+// by name
+func Summarize(counts []StatusCount) *Stats {
+	byChannel := make(map[notification.NotificationType]*ChannelStats)
+	stats := &Stats{Channels: []ChannelStats{}}
+	for _, count := range counts {
+		channel, ok := byChannel[count.Channel]
+		if !ok {
+			channel = &ChannelStats{Channel: count.Channel}
+			byChannel[count.Channel] = channel
+		}
+		channel.Add(count.Status, count.Count)
+		stats.Overall.Add(count.Status, count.Count)
+	}
+
+	for _, channel := range byChannel {
+		channel.rates()
+		stats.Channels = append(stats.Channels, *channel)
+	}
+	sort.Slice(stats.Channels, func(i, j int) bool { return stats.Channels[i].Channel < stats.Channels[j].Channel })
+	stats.Overall.rates()
+	return stats
+}
+
+// HistoryError represents notification history domain errors
+type HistoryError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e HistoryError) Error() string {
+	return e.Message
+}
+
+// Is matches history errors by code so detailed errors match their sentinel
+func (e HistoryError) Is(target error) bool {
+	t, ok := target.(HistoryError)
+	return ok && t.Code == e.Code
+}
+
+// Common notification history errors
+var (
+	ErrEntryNotFound = HistoryError{Code: "NOTIFICATION_NOT_FOUND", Message: "Notification not found"}
+	ErrInvalidCursor = HistoryError{Code: "INVALID_CURSOR", Message: "Invalid page cursor"}
+	ErrInvalidQuery  = HistoryError{Code: "INVALID_HISTORY_QUERY", Message: "Invalid notification history query"}
+)
@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+)
+
+// entryColumns are the notification_history columns scanEntry reads, in order
+const entryColumns = `id, user_id, type, title, body, data, status, priority, created_at, sent_at, read_at,
+	failure_count, last_error`
+
+// service implements notificationhistory.Service on the notification_history
+// table, so status reports reaching any instance update the same entries
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed notification history
+func NewService(pool *pgxpool.Pool) notificationhistory.Service {
+	return &service{pool: pool}
+}
+
+// Record inserts an entry, giving it an ID and creation time when it has none
+func (s *service) Record(ctx context.Context, entry notification.NotificationHistory) (*notification.NotificationHistory, error) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO notification_history (`+entryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		entry.ID, entry.UserID, entry.Type, entry.Title, entry.Body, data, entry.Status, entry.Priority,
+		entry.CreatedAt, entry.SentAt, entry.ReadAt, entry.FailureCount, entry.LastError)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Get returns an entry
+func (s *service) Get(ctx context.Context, id string) (*notification.NotificationHistory, error) {
+	entry, err := scanEntry(s.pool.QueryRow(ctx, `SELECT `+entryColumns+` FROM notification_history WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, notificationhistory.ErrEntryNotFound
+	}
+	return entry, err
+}
+
+// UpdateStatus applies a status report to an entry, locking its row so
+// concurrent reports for the same notification apply one after the other
+func (s *service) UpdateStatus(ctx context.Context, id string, update notificationhistory.StatusUpdate) (*notification.NotificationHistory, error) {
+	var entry *notification.NotificationHistory
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		entry, err = scanEntry(tx.QueryRow(ctx, `SELECT `+entryColumns+` FROM notification_history WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+
+		update.Apply(entry)
+		_, err = tx.Exec(ctx, `
+			UPDATE notification_history
+			SET status = $2, sent_at = $3, read_at = $4, failure_count = $5, last_error = $6
+			WHERE id = $1`,
+			entry.ID, entry.Status, entry.SentAt, entry.ReadAt, entry.FailureCount, entry.LastError)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, notificationhistory.ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns matching entries, newest first
+func (s *service) List(ctx context.Context, filter notificationhistory.Filter) ([]notification.NotificationHistory, error) {
+	conditions, args := where(filter)
+	query := `SELECT ` + entryColumns + ` FROM notification_history` + conditions + ` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows)
+}
+
+// Query returns a page of matching entries, newest first, reading one entry
+// more than the page holds to learn whether another page follows
+func (s *service) Query(ctx context.Context, query notificationhistory.Query) (*notificationhistory.Page, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	conditions, args := where(query.Filter)
+	if query.Cursor != "" {
+		cursor, err := notificationhistory.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		clause := fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+		if conditions == "" {
+			conditions = ` WHERE ` + clause
+		} else {
+			conditions += ` AND ` + clause
+		}
+	}
+
+	limit := query.LimitOrDefault()
+	args = append(args, limit+1)
+	rows, err := s.pool.Query(ctx, `SELECT `+entryColumns+` FROM notification_history`+conditions+
+		fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := scanAll(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &notificationhistory.Page{Notifications: entries, Limit: limit}
+	if len(entries) > limit {
+		page.Notifications = entries[:limit]
+		page.NextCursor = notificationhistory.CursorAfter(entries[limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// Stats counts the matching entries by channel and status in the database
+func (s *service) Stats(ctx context.Context, filter notificationhistory.Filter) (*notificationhistory.Stats, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Status = ""
+	conditions, args := where(filter)
+
+	rows, err := s.pool.Query(ctx, `SELECT type, status, COUNT(*) FROM notification_history`+conditions+
+		` GROUP BY type, status`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []notificationhistory.StatusCount{}
+	for rows.Next() {
+		var count notificationhistory.StatusCount
+		if err := rows.Scan(&count.Channel, &count.Status, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notificationhistory.Summarize(counts), nil
+}
+
+// DeleteUser deletes the user's entries
+func (s *service) DeleteUser(ctx context.Context, userID string) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM notification_history WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// where returns the WHERE clause selecting the entries matching the filter,
+// empty when it matches all, and its arguments
+func where(filter notificationhistory.Filter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return ` WHERE ` + strings.Join(conditions, " AND "), args
+}
+
+// scanAll reads the entries of rows, closing them
+func scanAll(rows pgx.Rows) ([]notification.NotificationHistory, error) {
+	defer rows.Close()
+	entries := []notification.NotificationHistory{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// scanEntry reads one entry in entryColumns order
+func scanEntry(row pgx.Row) (*notification.NotificationHistory, error) {
+	var entry notification.NotificationHistory
+	var data []byte
+	if err := row.Scan(&entry.ID, &entry.UserID, &entry.Type, &entry.Title, &entry.Body, &data, &entry.Status,
+		&entry.Priority, &entry.CreatedAt, &entry.SentAt, &entry.ReadAt, &entry.FailureCount, &entry.LastError); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entry.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode data of notification %s: %w", entry.ID, err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,41 @@
+package notificationschedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// SendWith returns a DeliverFunc sending each notification through the
+// notification service on its channel. Emails are sent without their
+// ScheduledAt, so a scheduling layer in the service does not queue them again,
+// and marked recorded, as the schedule keeps their history entry.
+func SendWith(service notification.Service) DeliverFunc {
+	return func(ctx context.Context, scheduled Scheduled) error {
+		ctx = notification.WithRecorded(ctx)
+		if scheduled.UserID != "" {
+			ctx = notification.WithRecipient(ctx, scheduled.UserID)
+		}
+
+		switch {
+		case scheduled.Channel == notification.NotificationTypeEmail && scheduled.Email != nil:
+			email := *scheduled.Email
+			email.ScheduledAt = nil
+			return service.SendBulkEmail(ctx, []notification.EmailNotification{email})
+		case scheduled.Channel == notification.NotificationTypePush && scheduled.Push != nil:
+			userID := scheduled.Push.UserID
+			if userID == "" {
+				userID = scheduled.UserID
+			}
+			return service.SendPushNotification(ctx, userID, *scheduled.Push)
+		case scheduled.Channel == notification.NotificationTypeSMS && scheduled.SMS != nil:
+			return service.SendSMSNotification(ctx, scheduled.SMS.PhoneNumber, scheduled.SMS.Message)
+		default:
+			return notification.NotificationError{
+				Code:    ErrInvalidNotification.Code,
+				Message: fmt.Sprintf("scheduled notification %s has no %s payload", scheduled.ID, scheduled.Channel),
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/correlation"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+// service implements notificationschedule.Service by recording scheduled
+// notifications in the notification history, under their schedule ID, and
+// updating their status as they are cancelled, sent or fail. History
+// failures are logged without failing the schedule, which already changed.
+type service struct {
+	next    notificationschedule.Service
+	history notificationhistory.Service
+}
+
+// NewService creates a schedule recording its notifications in history
+func NewService(next notificationschedule.Service, history notificationhistory.Service) notificationschedule.Service {
+	return &service{next: next, history: history}
+}
+
+// Schedule queues the notification and records it pending
+func (s *service) Schedule(ctx context.Context, scheduled notificationschedule.Scheduled) (*notificationschedule.Scheduled, error) {
+	created, err := s.next.Schedule(ctx, scheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := notification.NotificationHistory{
+		ID:        created.ID,
+		UserID:    created.UserID,
+		Type:      created.Channel,
+		Status:    notification.NotificationStatusPending,
+		Data:      map[string]interface{}{"scheduled_for": created.SendAt},
+		CreatedAt: created.CreatedAt,
+	}
+	switch {
+	case created.Email != nil:
+		entry.Title, entry.Body, entry.Priority = created.Email.Subject, created.Email.Body, created.Email.Priority
+	case created.Push != nil:
+		entry.Title, entry.Body, entry.Priority = created.Push.Title, created.Push.Body, created.Push.Priority
+	case created.SMS != nil:
+		entry.Body, entry.Priority = created.SMS.Message, created.SMS.Priority
+	}
+	if _, err := s.history.Record(ctx, entry); err != nil {
+		correlation.Logf(ctx, "Failed to record scheduled notification %s in history: %v", created.ID, err)
+	}
+	return created, nil
+}
+
+// Get delegates to the next service
+func (s *service) Get(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	return s.next.Get(ctx, id)
+}
+
+// List delegates to the next service
+func (s *service) List(ctx context.Context, filter notificationschedule.Filter) ([]notificationschedule.Scheduled, error) {
+	return s.next.List(ctx, filter)
+}
+
+// Cancel cancels the notification and records it cancelled
+func (s *service) Cancel(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	cancelled, err := s.next.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.update(ctx, id, notificationhistory.StatusUpdate{Status: notification.NotificationStatusCancelled})
+	return cancelled, nil
+}
+
+// Deliver records each delivery attempt: sent, or failed with its error,
+// including the attempts that will be retried
+func (s *service) Deliver(ctx context.Context, options notificationschedule.DeliveryOptions, deliver notificationschedule.DeliverFunc) (*notificationschedule.DeliveryReport, error) {
+	return s.next.Deliver(ctx, options, func(ctx context.Context, scheduled notificationschedule.Scheduled) error {
+		err := deliver(ctx, scheduled)
+		if err != nil {
+			s.update(ctx, scheduled.ID, notificationhistory.StatusUpdate{Status: notification.NotificationStatusFailed, Error: err.Error()})
+		} else {
+			s.update(ctx, scheduled.ID, notificationhistory.StatusUpdate{Status: notification.NotificationStatusSent})
+		}
+		return err
+	})
+}
+
+func (s *service) update(ctx context.Context, id string, update notificationhistory.StatusUpdate) {
+	if _, err := s.history.UpdateStatus(ctx, id, update); err != nil {
+		correlation.Logf(ctx, "Failed to record status %s of scheduled notification %s in history: %v", update.Status, id, err)
+	}
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+// service implements notificationschedule.Service interface in memory, for
+// single instances and tests; notifications scheduled are lost on restart
+type service struct {
+	mu        sync.Mutex
+	scheduled map[string]*notificationschedule.Scheduled
+}
+
+// NewService creates an empty in-memory notification schedule
+func NewService() notificationschedule.Service {
+	return &service{scheduled: make(map[string]*notificationschedule.Scheduled)}
+}
+
+// Schedule queues a notification as pending
+func (s *service) Schedule(ctx context.Context, scheduled notificationschedule.Scheduled) (*notificationschedule.Scheduled, error) {
+	if err := scheduled.Validate(); err != nil {
+		return nil, err
+	}
+	if scheduled.ID == "" {
+		scheduled.ID = uuid.New().String()
+	}
+	scheduled.Status = notificationschedule.StatusPending
+	scheduled.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := scheduled
+	s.scheduled[scheduled.ID] = &stored
+	return &scheduled, nil
+}
+
+// Get returns a scheduled notification
+func (s *service) Get(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled, ok := s.scheduled[id]
+	if !ok {
+		return nil, notificationschedule.ErrNotFound
+	}
+	copied := *scheduled
+	return &copied, nil
+}
+
+// List returns matching scheduled notifications, soonest first
+func (s *service) List(ctx context.Context, filter notificationschedule.Filter) ([]notificationschedule.Scheduled, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []notificationschedule.Scheduled{}
+	for _, scheduled := range s.scheduled {
+		if filter.Matches(*scheduled) {
+			result = append(result, *scheduled)
+		}
+	}
+	sortBySendAt(result)
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// Cancel cancels a notification not being delivered
+func (s *service) Cancel(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled, ok := s.scheduled[id]
+	if !ok {
+		return nil, notificationschedule.ErrNotFound
+	}
+	if !scheduled.Cancellable(time.Now()) {
+		return nil, notificationschedule.ErrNotCancellable
+	}
+	scheduled.Status = notificationschedule.StatusCancelled
+	scheduled.LeasedBy, scheduled.LeaseExpiresAt = "", nil
+	copied := *scheduled
+	return &copied, nil
+}
+
+// Deliver leases the due notifications, delivers them without holding the
+// lock and records the outcomes of those still leased to the owner
+func (s *service) Deliver(ctx context.Context, options notificationschedule.DeliveryOptions, deliver notificationschedule.DeliverFunc) (*notificationschedule.DeliveryReport, error) {
+	leased := s.lease(options, time.Now())
+
+	report := &notificationschedule.DeliveryReport{}
+	for _, scheduled := range leased {
+		err := deliver(ctx, scheduled)
+
+		s.mu.Lock()
+		stored := s.scheduled[scheduled.ID]
+		if stored.Status == notificationschedule.StatusSending && stored.LeasedBy == options.Owner {
+			stored.Record(err, options, time.Now())
+			report.Count(*stored)
+		}
+		s.mu.Unlock()
+	}
+	return report, nil
+}
+
+// lease marks up to the limit of due notifications, soonest first, as
+// being delivered by the owner
+func (s *service) lease(options notificationschedule.DeliveryOptions, now time.Time) []notificationschedule.Scheduled {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []notificationschedule.Scheduled{}
+	for _, scheduled := range s.scheduled {
+		if scheduled.Due(now) {
+			due = append(due, *scheduled)
+		}
+	}
+	sortBySendAt(due)
+	if len(due) > options.LimitOrDefault() {
+		due = due[:options.LimitOrDefault()]
+	}
+
+	expires := now.Add(options.LeaseOrDefault())
+	for i := range due {
+		stored := s.scheduled[due[i].ID]
+		stored.Status, stored.LeasedBy, stored.LeaseExpiresAt = notificationschedule.StatusSending, options.Owner, &expires
+		due[i] = *stored
+	}
+	return due
+}
+
+func sortBySendAt(scheduled []notificationschedule.Scheduled) {
+	sort.Slice(scheduled, func(i, j int) bool {
+		if scheduled[i].SendAt.Equal(scheduled[j].SendAt) {
+			return scheduled[i].ID < scheduled[j].ID
+		}
+		return scheduled[i].SendAt.Before(scheduled[j].SendAt)
+	})
+}
@@ -0,0 +1,258 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule/memory"
+)
+
+// recorder delivers notifications by recording their IDs, failing those in
+// failing
+type recorder struct {
+	delivered []string
+	failing   map[string]error
+}
+
+func (r *recorder) deliver(ctx context.Context, scheduled notificationschedule.Scheduled) error {
+	if err := r.failing[scheduled.ID]; err != nil {
+		return err
+	}
+	r.delivered = append(r.delivered, scheduled.ID)
+	return nil
+}
+
+func pushAt(id string, sendAt time.Time) notificationschedule.Scheduled {
+	return notificationschedule.Scheduled{
+		ID:      id,
+		UserID:  "user-1",
+		Channel: notification.NotificationTypePush,
+		Push:    &notification.PushNotification{UserID: "user-1", Title: "Reminder", Body: "Your trial ends soon"},
+		SendAt:  sendAt,
+	}
+}
+
+func TestSchedule_GivenInvalidNotification_WhenScheduling_ThenRejectsIt(t *testing.T) {
+	tests := []struct {
+		name          string
+		scheduled     notificationschedule.Scheduled
+		expectedField string
+	}{
+		{
+			name:          "Given no payload for the channel, When scheduling, Then rejects it",
+			scheduled:     notificationschedule.Scheduled{Channel: notification.NotificationTypeEmail, SendAt: time.Now()},
+			expectedField: "email",
+		},
+		{
+			name:          "Given an unsupported channel, When scheduling, Then rejects it",
+			scheduled:     notificationschedule.Scheduled{Channel: notification.NotificationTypeInApp, SendAt: time.Now()},
+			expectedField: "channel",
+		},
+		{
+			name: "Given no send time, When scheduling, Then rejects it",
+			scheduled: notificationschedule.Scheduled{
+				Channel: notification.NotificationTypeSMS,
+				SMS:     &notification.SMSNotification{PhoneNumber: "+14155550100", Message: "Hi"},
+			},
+			expectedField: "send_at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			schedule := memory.NewService()
+
+			// Act
+			_, err := schedule.Schedule(context.Background(), tt.scheduled)
+
+			// Assert
+			var scheduleErr notificationschedule.ScheduleError
+			require.ErrorAs(t, err, &scheduleErr)
+			assert.ErrorIs(t, err, notificationschedule.ErrInvalidNotification)
+			assert.Equal(t, tt.expectedField, scheduleErr.Field)
+		})
+	}
+}
+
+func TestDeliver_GivenScheduledNotifications_WhenDelivering_ThenSendsOnlyDueOnesOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	schedule := memory.NewService()
+	now := time.Now()
+	for _, scheduled := range []notificationschedule.Scheduled{
+		pushAt("later", now.Add(time.Hour)),
+		pushAt("second", now.Add(-time.Minute)),
+		pushAt("first", now.Add(-time.Hour)),
+	} {
+		_, err := schedule.Schedule(ctx, scheduled)
+		require.NoError(t, err)
+	}
+	worker := &recorder{}
+	options := notificationschedule.DeliveryOptions{Owner: "worker-1"}
+
+	// Act
+	report, err := schedule.Deliver(ctx, options, worker.deliver)
+	require.NoError(t, err)
+	again, err := schedule.Deliver(ctx, options, worker.deliver)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, notificationschedule.DeliveryReport{Sent: 2}, *report)
+	assert.Equal(t, notificationschedule.DeliveryReport{}, *again)
+	assert.Equal(t, []string{"first", "second"}, worker.delivered)
+	sent, err := schedule.Get(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, notificationschedule.StatusSent, sent.Status)
+	assert.NotNil(t, sent.SentAt)
+	later, err := schedule.Get(ctx, "later")
+	require.NoError(t, err)
+	assert.Equal(t, notificationschedule.StatusPending, later.Status)
+}
+
+func TestDeliver_GivenFailingDelivery_WhenDelivering_ThenRetriesLaterOrFails(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		maxAttempts    int
+		expectedStatus notificationschedule.Status
+		expectedReport notificationschedule.DeliveryReport
+	}{
+		{
+			name:           "Given a transient failure, When delivering, Then retries it later",
+			err:            errors.New("provider unavailable"),
+			expectedStatus: notificationschedule.StatusPending,
+			expectedReport: notificationschedule.DeliveryReport{Retried: 1},
+		},
+		{
+			name:           "Given the last attempt failing, When delivering, Then fails it",
+			err:            errors.New("provider unavailable"),
+			maxAttempts:    1,
+			expectedStatus: notificationschedule.StatusFailed,
+			expectedReport: notificationschedule.DeliveryReport{Failed: 1},
+		},
+		{
+			name:           "Given an invalid notification, When delivering, Then fails it at once",
+			err:            notification.ErrInvalidRecipient,
+			expectedStatus: notificationschedule.StatusFailed,
+			expectedReport: notificationschedule.DeliveryReport{Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			schedule := memory.NewService()
+			_, err := schedule.Schedule(ctx, pushAt("reminder", time.Now().Add(-time.Minute)))
+			require.NoError(t, err)
+			worker := &recorder{failing: map[string]error{"reminder": tt.err}}
+			options := notificationschedule.DeliveryOptions{Owner: "worker-1", MaxAttempts: tt.maxAttempts}
+
+			// Act
+			report, err := schedule.Deliver(ctx, options, worker.deliver)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedReport, *report)
+			stored, err := schedule.Get(ctx, "reminder")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, stored.Status)
+			assert.Equal(t, 1, stored.Attempts)
+			assert.Equal(t, tt.err.Error(), stored.LastError)
+			if tt.expectedStatus == notificationschedule.StatusPending {
+				assert.True(t, stored.SendAt.After(time.Now()), "a retry must wait for its backoff")
+			}
+		})
+	}
+}
+
+func TestDeliver_GivenExpiredLease_WhenDelivering_ThenAnotherWorkerTakesOverAndTheFirstCannotRecord(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	schedule := memory.NewService()
+	_, err := schedule.Schedule(ctx, pushAt("reminder", time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+	second := &recorder{}
+	var report *notificationschedule.DeliveryReport
+
+	// Act: the first worker's lease expires while it delivers, so the
+	// second leases the notification again and sends it
+	first, err := schedule.Deliver(ctx, notificationschedule.DeliveryOptions{Owner: "worker-1", Lease: time.Nanosecond},
+		func(ctx context.Context, scheduled notificationschedule.Scheduled) error {
+			time.Sleep(time.Millisecond)
+			report, err = schedule.Deliver(ctx, notificationschedule.DeliveryOptions{Owner: "worker-2"}, second.deliver)
+			return errors.New("timed out")
+		})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, notificationschedule.DeliveryReport{}, *first)
+	assert.Equal(t, notificationschedule.DeliveryReport{Sent: 1}, *report)
+	assert.Equal(t, []string{"reminder"}, second.delivered)
+	stored, err := schedule.Get(ctx, "reminder")
+	require.NoError(t, err)
+	assert.Equal(t, notificationschedule.StatusSent, stored.Status)
+}
+
+func TestCancel_GivenScheduledNotification_WhenCancelling_ThenCancelsOnlyUnsentOnes(t *testing.T) {
+	tests := []struct {
+		name          string
+		deliverFirst  bool
+		id            string
+		expectedError error
+	}{
+		{
+			name: "Given a pending notification, When cancelling, Then cancels it",
+			id:   "reminder",
+		},
+		{
+			name:          "Given a sent notification, When cancelling, Then refuses",
+			deliverFirst:  true,
+			id:            "reminder",
+			expectedError: notificationschedule.ErrNotCancellable,
+		},
+		{
+			name:          "Given an unknown notification, When cancelling, Then reports it not found",
+			id:            "missing",
+			expectedError: notificationschedule.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			schedule := memory.NewService()
+			_, err := schedule.Schedule(ctx, pushAt("reminder", time.Now().Add(-time.Minute)))
+			require.NoError(t, err)
+			worker := &recorder{}
+			if tt.deliverFirst {
+				_, err := schedule.Deliver(ctx, notificationschedule.DeliveryOptions{Owner: "worker-1"}, worker.deliver)
+				require.NoError(t, err)
+			}
+
+			// Act
+			cancelled, err := schedule.Cancel(ctx, tt.id)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, notificationschedule.StatusCancelled, cancelled.Status)
+			report, err := schedule.Deliver(ctx, notificationschedule.DeliveryOptions{Owner: "worker-1"}, worker.deliver)
+			require.NoError(t, err)
+			assert.Equal(t, notificationschedule.DeliveryReport{}, *report)
+			assert.Empty(t, worker.delivered)
+		})
+	}
+}
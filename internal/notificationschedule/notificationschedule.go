@@ -0,0 +1,285 @@
+package notificationschedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+)
+
+// Service defines the notification schedule domain interface - the ONLY
+// interface in this domain. It queues notifications to deliver later and
+// leases the due ones to workers, so each is delivered by one worker even
+// with several instances running, and picked up again when its worker dies
+// before reporting it.
+type Service interface {
+	// Schedule queues a notification for delivery at its SendAt, returning
+	// it with its ID assigned
+	Schedule(ctx context.Context, scheduled Scheduled) (*Scheduled, error)
+
+	// Get returns a scheduled notification, or ErrNotFound
+	Get(ctx context.Context, id string) (*Scheduled, error)
+
+	// List returns matching scheduled notifications, soonest first
+	List(ctx context.Context, filter Filter) ([]Scheduled, error)
+
+	// Cancel cancels a notification before it is sent. Notifications being
+	// delivered or already done cannot be cancelled: ErrNotCancellable.
+	Cancel(ctx context.Context, id string) (*Scheduled, error)
+
+	// Deliver leases up to options.Limit due notifications to options.Owner,
+	// hands each to deliver and records the outcome: sent, retried later,
+	// or failed once options.MaxAttempts are used or deliver fails with a
+	// notification.NotificationError, which retrying does not fix
+	Deliver(ctx context.Context, options DeliveryOptions, deliver DeliverFunc) (*DeliveryReport, error)
+}
+
+// Domain types and data structures
+
+// Status is the state of a scheduled notification
+type Status string
+
+const (
+	// StatusPending waits for its SendAt, or for a retry
+	StatusPending Status = "pending"
+	// StatusSending is leased to a worker delivering it; it is due again
+	// once the lease expires without an outcome
+	StatusSending   Status = "sending"
+	StatusSent      Status = "sent"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Scheduled is a notification queued for later delivery. It carries the
+// notification of its Channel: Email, Push or SMS.
+type Scheduled struct {
+	ID      string                        `json:"id"`
+	UserID  string                        `json:"user_id,omitempty"`
+	Channel notification.NotificationType `json:"channel"`
+
+	Email *notification.EmailNotification `json:"email,omitempty"`
+	Push  *notification.PushNotification  `json:"push,omitempty"`
+	SMS   *notification.SMSNotification   `json:"sms,omitempty"`
+
+	// SendAt is when the notification is due
+	SendAt time.Time `json:"send_at"`
+
+	// Timezone is the zone a local send time was resolved in, if any
+	Timezone string `json:"timezone,omitempty"`
+
+	Status         Status     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	LeasedBy       string     `json:"leased_by,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
+
+// Validate checks that the notification carries the payload of its channel
+// and a send time
+func (s Scheduled) Validate() error {
+	var ok bool
+	switch s.Channel {
+	case notification.NotificationTypeEmail:
+		ok = s.Email != nil && s.Email.IsValid()
+	case notification.NotificationTypePush:
+		ok = s.Push != nil && s.Push.IsValid()
+	case notification.NotificationTypeSMS:
+		ok = s.SMS != nil && s.SMS.PhoneNumber != "" && s.SMS.Message != ""
+	default:
+		return ScheduleError{Code: ErrInvalidNotification.Code, Message: fmt.Sprintf("unsupported channel %q", s.Channel), Field: "channel"}
+	}
+	if !ok {
+		return ScheduleError{Code: ErrInvalidNotification.Code, Message: fmt.Sprintf("a valid %s notification is required", s.Channel), Field: string(s.Channel)}
+	}
+	if s.SendAt.IsZero() {
+		return ScheduleError{Code: ErrInvalidNotification.Code, Message: "send time is required", Field: "send_at"}
+	}
+	return nil
+}
+
+// Due reports whether a worker may lease the notification at now: it is
+// pending and its send time has come, or its last worker's lease expired
+func (s Scheduled) Due(now time.Time) bool {
+	switch s.Status {
+	case StatusPending:
+		return !s.SendAt.After(now)
+	case StatusSending:
+		return s.LeaseExpiresAt != nil && !s.LeaseExpiresAt.After(now)
+	default:
+		return false
+	}
+}
+
+// Cancellable reports whether the notification can still be cancelled at
+// now: it is pending, or its worker's lease expired before it reported
+func (s Scheduled) Cancellable(now time.Time) bool {
+	return s.Status == StatusPending || (s.Status == StatusSending && s.Due(now))
+}
+
+// Record applies the outcome of a delivery attempt: sent, or else pending
+// again after a backoff growing with the attempts, or failed for good
+func (s *Scheduled) Record(err error, options DeliveryOptions, now time.Time) {
+	s.Attempts++
+	s.LeasedBy, s.LeaseExpiresAt = "", nil
+	if err == nil {
+		s.Status, s.LastError, s.SentAt = StatusSent, "", &now
+		return
+	}
+
+	s.LastError = err.Error()
+	var notificationErr notification.NotificationError
+	if s.Attempts >= options.maxAttempts() || errors.As(err, &notificationErr) {
+		s.Status = StatusFailed
+		return
+	}
+	s.Status = StatusPending
+	s.SendAt = now.Add(options.retryDelay() << (s.Attempts - 1))
+}
+
+// Filter narrows the scheduled notifications returned by List
+type Filter struct {
+	UserID string `json:"user_id,omitempty"`
+	Status Status `json:"status,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// Matches reports whether the notification satisfies the filter
+func (f Filter) Matches(s Scheduled) bool {
+	if f.UserID != "" && f.UserID != s.UserID {
+		return false
+	}
+	return f.Status == "" || f.Status == s.Status
+}
+
+// DeliverFunc delivers one scheduled notification
+type DeliverFunc func(ctx context.Context, scheduled Scheduled) error
+
+// DeliveryOptions configures a delivery run
+type DeliveryOptions struct {
+	// Owner identifies the worker the notifications are leased to
+	Owner string
+
+	// Limit caps the notifications leased; DefaultDeliveryLimit when not
+	// positive
+	Limit int
+
+	// Lease is how long the worker has to deliver them before other workers
+	// may; DefaultLease when not positive
+	Lease time.Duration
+
+	// MaxAttempts is how many times a notification is tried;
+	// DefaultMaxAttempts when not positive
+	MaxAttempts int
+
+	// RetryDelay is the wait before the first retry, doubling with each
+	// one; DefaultRetryDelay when not positive
+	RetryDelay time.Duration
+}
+
+// Delivery defaults
+const (
+	DefaultDeliveryLimit = 100
+	DefaultLease         = time.Minute
+	DefaultMaxAttempts   = 5
+	DefaultRetryDelay    = time.Minute
+)
+
+// LimitOrDefault returns the number of notifications to lease
+func (o DeliveryOptions) LimitOrDefault() int {
+	if o.Limit <= 0 {
+		return DefaultDeliveryLimit
+	}
+	return o.Limit
+}
+
+// LeaseOrDefault returns the lease duration
+func (o DeliveryOptions) LeaseOrDefault() time.Duration {
+	if o.Lease <= 0 {
+		return DefaultLease
+	}
+	return o.Lease
+}
+
+func (o DeliveryOptions) maxAttempts() int {
+	if o.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return o.MaxAttempts
+}
+
+func (o DeliveryOptions) retryDelay() time.Duration {
+	if o.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+	return o.RetryDelay
+}
+
+// DeliveryReport summarizes a delivery run: the notifications sent, those
+// to be retried and those that failed for good
+type DeliveryReport struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+}
+
+// Count adds the outcome of a notification to the report
+func (r *DeliveryReport) Count(s Scheduled) {
+	switch s.Status {
+	case StatusSent:
+		r.Sent++
+	case StatusFailed:
+		r.Failed++
+	default:
+		r.Retried++
+	}
+}
+
+// NextLocalTime returns the first time after now at which clocks in the
+// timezone read clock, e.g. "09:00" for "send at 9am local time". An empty
+// timezone is UTC. Days are counted on the local calendar, so the time
+// stays at the same local hour across daylight saving changes.
+func NextLocalTime(now time.Time, timezone, clock string) (time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, ScheduleError{Code: ErrInvalidNotification.Code, Message: fmt.Sprintf("unknown timezone %q", timezone), Field: "timezone"}
+	}
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, ScheduleError{Code: ErrInvalidNotification.Code, Message: fmt.Sprintf("local time %q must be HH:MM", clock), Field: "local_time"}
+	}
+
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, location)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, at.Hour(), at.Minute(), 0, 0, location)
+	}
+	return next, nil
+}
+
+// ScheduleError represents notification schedule domain errors
+type ScheduleError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e ScheduleError) Error() string {
+	return e.Message
+}
+
+// Is matches schedule errors by code so detailed errors match their sentinel
+func (e ScheduleError) Is(target error) bool {
+	t, ok := target.(ScheduleError)
+	return ok && t.Code == e.Code
+}
+
+// Common notification schedule errors
+var (
+	ErrNotFound            = ScheduleError{Code: "SCHEDULED_NOTIFICATION_NOT_FOUND", Message: "Scheduled notification not found"}
+	ErrNotCancellable      = ScheduleError{Code: "NOTIFICATION_NOT_CANCELLABLE", Message: "The notification is being sent or already done"}
+	ErrInvalidNotification = ScheduleError{Code: "INVALID_SCHEDULED_NOTIFICATION", Message: "Invalid scheduled notification"}
+)
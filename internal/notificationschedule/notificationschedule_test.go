@@ -0,0 +1,159 @@
+package notificationschedule_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+func TestNextLocalTime_GivenTimezoneAndClock_WhenResolving_ThenReturnsNextLocalOccurrence(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		timezone      string
+		clock         string
+		expected      time.Time
+		expectedField string
+	}{
+		{
+			name:     "Given a local time later today, When resolving, Then returns it today",
+			now:      time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+			timezone: "America/New_York",
+			clock:    "09:00",
+			expected: time.Date(2026, 3, 2, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:     "Given a local time already past today, When resolving, Then returns it tomorrow",
+			now:      time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+			timezone: "America/New_York",
+			clock:    "09:00",
+			expected: time.Date(2026, 3, 3, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:     "Given the night clocks spring forward, When resolving, Then keeps the local hour",
+			now:      time.Date(2026, 3, 7, 20, 0, 0, 0, time.UTC),
+			timezone: "America/New_York",
+			clock:    "09:00",
+			expected: time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Given no timezone, When resolving, Then uses UTC",
+			now:      time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+			clock:    "09:30",
+			expected: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:          "Given an unknown timezone, When resolving, Then rejects it",
+			now:           time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+			timezone:      "Mars/Olympus_Mons",
+			clock:         "09:00",
+			expectedField: "timezone",
+		},
+		{
+			name:          "Given a malformed clock, When resolving, Then rejects it",
+			now:           time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+			clock:         "9am",
+			expectedField: "local_time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			next, err := notificationschedule.NextLocalTime(tt.now, tt.timezone, tt.clock)
+
+			// Assert
+			if tt.expectedField != "" {
+				var scheduleErr notificationschedule.ScheduleError
+				require.ErrorAs(t, err, &scheduleErr)
+				assert.ErrorIs(t, err, notificationschedule.ErrInvalidNotification)
+				assert.Equal(t, tt.expectedField, scheduleErr.Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(next), "expected %s, got %s", tt.expected, next)
+		})
+	}
+}
+
+func TestRecord_GivenDeliveryOutcome_WhenRecording_ThenSendsRetriesOrFails(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	options := notificationschedule.DeliveryOptions{MaxAttempts: 3, RetryDelay: time.Minute}
+
+	tests := []struct {
+		name           string
+		attempts       int
+		err            error
+		expectedStatus notificationschedule.Status
+		expectedSendAt time.Time
+	}{
+		{
+			name:           "Given a delivered notification, When recording, Then marks it sent",
+			expectedStatus: notificationschedule.StatusSent,
+		},
+		{
+			name:           "Given a first failure, When recording, Then retries after the retry delay",
+			err:            errors.New("provider unavailable"),
+			expectedStatus: notificationschedule.StatusPending,
+			expectedSendAt: now.Add(time.Minute),
+		},
+		{
+			name:           "Given a second failure, When recording, Then doubles the delay",
+			attempts:       1,
+			err:            errors.New("provider unavailable"),
+			expectedStatus: notificationschedule.StatusPending,
+			expectedSendAt: now.Add(2 * time.Minute),
+		},
+		{
+			name:           "Given the last attempt failing, When recording, Then marks it failed",
+			attempts:       2,
+			err:            errors.New("provider unavailable"),
+			expectedStatus: notificationschedule.StatusFailed,
+		},
+		{
+			name:           "Given an invalid notification, When recording, Then fails it without retrying",
+			err:            notification.ErrInvalidMessage,
+			expectedStatus: notificationschedule.StatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			expires := now
+			scheduled := notificationschedule.Scheduled{
+				Status:         notificationschedule.StatusSending,
+				Attempts:       tt.attempts,
+				SendAt:         now.Add(-time.Hour),
+				LeasedBy:       "worker-1",
+				LeaseExpiresAt: &expires,
+			}
+
+			// Act
+			scheduled.Record(tt.err, options, now)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, scheduled.Status)
+			assert.Equal(t, tt.attempts+1, scheduled.Attempts)
+			assert.Empty(t, scheduled.LeasedBy)
+			assert.Nil(t, scheduled.LeaseExpiresAt)
+			if !tt.expectedSendAt.IsZero() {
+				assert.Equal(t, tt.expectedSendAt, scheduled.SendAt)
+			}
+			if tt.err != nil {
+				assert.Equal(t, tt.err.Error(), scheduled.LastError)
+			} else {
+				assert.NotNil(t, scheduled.SentAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationschedule"
+)
+
+// scheduledColumns are the scheduled_notifications columns scanScheduled
+// reads, in order
+const scheduledColumns = `id, user_id, channel, payload, send_at, timezone, status, attempts, last_error, leased_by,
+	lease_expires_at, created_at, sent_at`
+
+// payload is the notification a scheduled notification carries, stored as JSON
+type payload struct {
+	Email *notification.EmailNotification `json:"email,omitempty"`
+	Push  *notification.PushNotification  `json:"push,omitempty"`
+	SMS   *notification.SMSNotification   `json:"sms,omitempty"`
+}
+
+// service implements notificationschedule.Service on the
+// scheduled_notifications table. Workers on every instance lease due rows
+// with FOR UPDATE SKIP LOCKED, so each row goes to one of them.
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed notification schedule
+func NewService(pool *pgxpool.Pool) notificationschedule.Service {
+	return &service{pool: pool}
+}
+
+// Schedule inserts a notification as pending
+func (s *service) Schedule(ctx context.Context, scheduled notificationschedule.Scheduled) (*notificationschedule.Scheduled, error) {
+	if err := scheduled.Validate(); err != nil {
+		return nil, err
+	}
+	if scheduled.ID == "" {
+		scheduled.ID = uuid.New().String()
+	}
+	scheduled.Status = notificationschedule.StatusPending
+	scheduled.CreatedAt = time.Now()
+	data, err := json.Marshal(payload{Email: scheduled.Email, Push: scheduled.Push, SMS: scheduled.SMS})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO scheduled_notifications (id, user_id, channel, payload, send_at, timezone, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		scheduled.ID, scheduled.UserID, scheduled.Channel, data, scheduled.SendAt, scheduled.Timezone,
+		scheduled.Status, scheduled.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// Get returns a scheduled notification
+func (s *service) Get(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	scheduled, err := scanScheduled(s.pool.QueryRow(ctx, `SELECT `+scheduledColumns+` FROM scheduled_notifications WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, notificationschedule.ErrNotFound
+	}
+	return scheduled, err
+}
+
+// List returns matching scheduled notifications, soonest first
+func (s *service) List(ctx context.Context, filter notificationschedule.Filter) ([]notificationschedule.Scheduled, error) {
+	var conditions []string
+	var args []any
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	query := `SELECT ` + scheduledColumns + ` FROM scheduled_notifications`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY send_at, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows)
+}
+
+// Cancel cancels a notification not being delivered
+func (s *service) Cancel(ctx context.Context, id string) (*notificationschedule.Scheduled, error) {
+	scheduled, err := scanScheduled(s.pool.QueryRow(ctx, `
+		UPDATE scheduled_notifications
+		SET status = $2, leased_by = '', lease_expires_at = NULL
+		WHERE id = $1 AND (status = $3 OR (status = $4 AND lease_expires_at <= $5))
+		RETURNING `+scheduledColumns,
+		id, notificationschedule.StatusCancelled, notificationschedule.StatusPending, notificationschedule.StatusSending, time.Now()))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return scheduled, err
+	}
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, notificationschedule.ErrNotCancellable
+}
+
+// Deliver leases the due notifications, delivers them outside any
+// transaction and records the outcomes of those still leased to the owner
+func (s *service) Deliver(ctx context.Context, options notificationschedule.DeliveryOptions, deliver notificationschedule.DeliverFunc) (*notificationschedule.DeliveryReport, error) {
+	now := time.Now()
+	rows, err := s.pool.Query(ctx, `
+		UPDATE scheduled_notifications
+		SET status = $1, leased_by = $2, lease_expires_at = $3
+		WHERE id IN (
+			SELECT id FROM scheduled_notifications
+			WHERE (status = $4 AND send_at <= $5) OR (status = $1 AND lease_expires_at <= $5)
+			ORDER BY send_at, id
+			LIMIT $6
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+scheduledColumns,
+		notificationschedule.StatusSending, options.Owner, now.Add(options.LeaseOrDefault()),
+		notificationschedule.StatusPending, now, options.LimitOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	leased, err := scanAll(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &notificationschedule.DeliveryReport{}
+	for _, scheduled := range leased {
+		deliverErr := deliver(ctx, scheduled)
+		scheduled.Record(deliverErr, options, time.Now())
+
+		tag, err := s.pool.Exec(ctx, `
+			UPDATE scheduled_notifications
+			SET status = $3, attempts = $4, last_error = $5, send_at = $6, sent_at = $7, leased_by = '', lease_expires_at = NULL
+			WHERE id = $1 AND status = $8 AND leased_by = $2`,
+			scheduled.ID, options.Owner, scheduled.Status, scheduled.Attempts, scheduled.LastError,
+			scheduled.SendAt, scheduled.SentAt, notificationschedule.StatusSending)
+		if err != nil {
+			return report, fmt.Errorf("failed to record delivery of scheduled notification %s: %w", scheduled.ID, err)
+		}
+		if tag.RowsAffected() > 0 {
+			report.Count(scheduled)
+		}
+	}
+	return report, nil
+}
+
+// scanAll reads the scheduled notifications of rows, closing them
+func scanAll(rows pgx.Rows) ([]notificationschedule.Scheduled, error) {
+	defer rows.Close()
+	result := []notificationschedule.Scheduled{}
+	for rows.Next() {
+		scheduled, err := scanScheduled(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *scheduled)
+	}
+	return result, rows.Err()
+}
+
+// scanScheduled reads one scheduled notification in scheduledColumns order
+func scanScheduled(row pgx.Row) (*notificationschedule.Scheduled, error) {
+	var scheduled notificationschedule.Scheduled
+	var data []byte
+	if err := row.Scan(&scheduled.ID, &scheduled.UserID, &scheduled.Channel, &data, &scheduled.SendAt, &scheduled.Timezone,
+		&scheduled.Status, &scheduled.Attempts, &scheduled.LastError, &scheduled.LeasedBy, &scheduled.LeaseExpiresAt,
+		&scheduled.CreatedAt, &scheduled.SentAt); err != nil {
+		return nil, err
+	}
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled notification %s: %w", scheduled.ID, err)
+	}
+	scheduled.Email, scheduled.Push, scheduled.SMS = p.Email, p.Push, p.SMS
+	return &scheduled, nil
+}
@@ -0,0 +1,178 @@
+package embedded
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// templates holds the notification templates shipped with the binary, one
+// YAML file per template with a variant per language
+//
+//go:embed templates/*.yaml
+var templates embed.FS
+
+// Config configures the shipped templates
+type Config struct {
+	// DefaultLanguage renders templates no requested language has a variant
+	// of, and defaults to translator.DefaultLanguage
+	DefaultLanguage string
+
+	// Dir holds YAML template files replacing or adding to the embedded
+	// ones, in the same format; empty uses the embedded ones only
+	Dir string
+}
+
+// file is the format of a template file
+type file struct {
+	Name      string                          `yaml:"name"`
+	Channel   notification.NotificationType   `yaml:"channel"`
+	Variables []notificationtemplate.Variable `yaml:"variables"`
+	Variants  map[string]struct {
+		Subject string `yaml:"subject"`
+		Body    string `yaml:"body"`
+		HTML    string `yaml:"html"`
+	} `yaml:"variants"`
+}
+
+// service implements notificationtemplate.Service interface from templates
+// loaded once at start-up. They are version 0 and read-only: decorate it
+// with a store such as memory or postgres to override them.
+type service struct {
+	templates       map[string]notificationtemplate.Template
+	defaultLanguage string
+}
+
+// NewService loads the embedded templates and those of config.Dir,
+// validating each of them
+func NewService(config Config) (notificationtemplate.Service, error) {
+	defaultLanguage := translator.NormalizeLanguage(config.DefaultLanguage)
+	if defaultLanguage == "" {
+		defaultLanguage = translator.DefaultLanguage
+	}
+
+	s := &service{templates: make(map[string]notificationtemplate.Template), defaultLanguage: defaultLanguage}
+	if err := s.load(templates, "templates"); err != nil {
+		return nil, err
+	}
+	if config.Dir != "" {
+		if err := s.load(os.DirFS(config.Dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// MustNewService is like NewService but panics when the templates cannot be
+// loaded, for the embedded templates only
+func MustNewService(config Config) notificationtemplate.Service {
+	service, err := NewService(config)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// Render renders the variant of the first language of the context that has
+// one, or of the default language
+func (s *service) Render(ctx context.Context, name string, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	for _, language := range notificationtemplate.Languages(ctx, s.defaultLanguage) {
+		if tmpl, ok := s.templates[notificationtemplate.Key(name, language)]; ok {
+			return tmpl.Render(variables)
+		}
+	}
+	return nil, notificationtemplate.ErrTemplateNotFound
+}
+
+// Preview validates and renders a template
+func (s *service) Preview(ctx context.Context, tmpl notificationtemplate.Template, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	return tmpl.Render(variables)
+}
+
+// Get returns a template variant
+func (s *service) Get(ctx context.Context, name, language string) (*notificationtemplate.Template, error) {
+	tmpl, ok := s.templates[notificationtemplate.Key(name, language)]
+	if !ok {
+		return nil, notificationtemplate.ErrTemplateNotFound
+	}
+	return &tmpl, nil
+}
+
+// List returns every template variant by name and language
+func (s *service) List(ctx context.Context) ([]notificationtemplate.Template, error) {
+	list := make([]notificationtemplate.Template, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		list = append(list, tmpl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return notificationtemplate.Key(list[i].Name, list[i].Language) < notificationtemplate.Key(list[j].Name, list[j].Language)
+	})
+	return list, nil
+}
+
+// Versions returns the only version of a template variant
+func (s *service) Versions(ctx context.Context, name, language string) ([]notificationtemplate.Template, error) {
+	tmpl, err := s.Get(ctx, name, language)
+	if err != nil {
+		return nil, err
+	}
+	return []notificationtemplate.Template{*tmpl}, nil
+}
+
+// Save refuses to change the shipped templates
+func (s *service) Save(ctx context.Context, tmpl notificationtemplate.Template) (*notificationtemplate.Template, error) {
+	return nil, notificationtemplate.ErrReadOnly
+}
+
+// Revert has nothing to revert to
+func (s *service) Revert(ctx context.Context, name, language string) error {
+	return notificationtemplate.ErrReadOnly
+}
+
+// load adds the template files of a directory of fsys
+func (s *service) load(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+		for language, variant := range f.Variants {
+			tmpl := notificationtemplate.Template{
+				Name:      f.Name,
+				Language:  translator.NormalizeLanguage(language),
+				Channel:   f.Channel,
+				Subject:   variant.Subject,
+				Body:      variant.Body,
+				HTML:      variant.HTML,
+				Variables: f.Variables,
+			}
+			if err := tmpl.Validate(); err != nil {
+				return fmt.Errorf("invalid %s template in %s: %w", language, p, err)
+			}
+			s.templates[notificationtemplate.Key(tmpl.Name, tmpl.Language)] = tmpl
+		}
+	}
+	return nil
+}
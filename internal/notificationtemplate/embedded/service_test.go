@@ -0,0 +1,100 @@
+package embedded_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+func TestRender_GivenRequestedLanguages_WhenRendering_ThenUsesFirstLanguageWithAVariant(t *testing.T) {
+	// Arrange
+	service, err := embedded.NewService(embedded.Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		languages        []string
+		expectedLanguage string
+		expectedSubject  string
+	}{
+		{
+			name:             "Given a supported language, When rendering, Then renders its variant",
+			languages:        []string{"es"},
+			expectedLanguage: "es",
+			expectedSubject:  "¡Bienvenido!",
+		},
+		{
+			name:             "Given a regional language, When rendering, Then falls back to its base language",
+			languages:        []string{"fr-CA"},
+			expectedLanguage: "fr",
+			expectedSubject:  "Bienvenue !",
+		},
+		{
+			name:             "Given unsupported languages only, When rendering, Then renders the default language",
+			languages:        []string{"ja"},
+			expectedLanguage: "en",
+			expectedSubject:  "Welcome!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := translator.WithLanguages(context.Background(), tt.languages)
+
+			// Act
+			message, err := service.Render(ctx, "welcome_email", map[string]interface{}{"name": "Jane"})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLanguage, message.Language)
+			assert.Equal(t, tt.expectedSubject, message.Subject)
+			assert.Contains(t, message.Body, "Jane")
+			assert.Contains(t, message.HTML, "Jane")
+		})
+	}
+}
+
+func TestNewService_GivenTemplateDir_WhenLoading_ThenReplacesAndAddsTemplates(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "order_shipped.yaml"), []byte(`
+name: order_shipped
+channel: sms
+variables:
+  - name: order
+    required: true
+variants:
+  en:
+    body: "Your order {{.order}} shipped"
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte(`
+name: broken
+channel: sms
+variants:
+  en:
+    body: "Hi {{.name}}"
+`), 0o600))
+
+	// Act
+	_, err := embedded.NewService(embedded.Config{Dir: dir})
+	require.NoError(t, os.Remove(filepath.Join(dir, "broken.yaml")))
+	service, loadErr := embedded.NewService(embedded.Config{Dir: dir})
+
+	// Assert
+	assert.ErrorIs(t, err, notificationtemplate.ErrInvalidTemplate)
+	require.NoError(t, loadErr)
+	message, err := service.Render(context.Background(), "order_shipped", map[string]interface{}{"order": "A-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Your order A-1 shipped", message.Body)
+	_, err = service.Save(context.Background(), notificationtemplate.Template{})
+	assert.ErrorIs(t, err, notificationtemplate.ErrReadOnly)
+}
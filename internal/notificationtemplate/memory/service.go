@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// service implements notificationtemplate.Service interface by keeping
+// saved template versions in memory on top of the templates of next, for
+// single instances and tests
+type service struct {
+	next            notificationtemplate.Service
+	defaultLanguage string
+
+	mu       sync.RWMutex
+	versions map[string][]notificationtemplate.Template // oldest first
+}
+
+// NewService creates in-memory template overrides in front of next, which
+// renders them. defaultLanguage defaults to translator.DefaultLanguage.
+func NewService(next notificationtemplate.Service, defaultLanguage string) notificationtemplate.Service {
+	if defaultLanguage == "" {
+		defaultLanguage = translator.DefaultLanguage
+	}
+	return &service{
+		next:            next,
+		defaultLanguage: translator.NormalizeLanguage(defaultLanguage),
+		versions:        make(map[string][]notificationtemplate.Template),
+	}
+}
+
+// Render renders the current version of the variant of the first language
+// of the context that has one, or of the default language
+func (s *service) Render(ctx context.Context, name string, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	for _, language := range notificationtemplate.Languages(ctx, s.defaultLanguage) {
+		tmpl, err := s.Get(ctx, name, language)
+		if errors.Is(err, notificationtemplate.ErrTemplateNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.next.Preview(ctx, *tmpl, variables)
+	}
+	return nil, notificationtemplate.ErrTemplateNotFound
+}
+
+// Preview delegates to the next service
+func (s *service) Preview(ctx context.Context, tmpl notificationtemplate.Template, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	return s.next.Preview(ctx, tmpl, variables)
+}
+
+// Get returns the latest saved version of a variant, or else that of next
+func (s *service) Get(ctx context.Context, name, language string) (*notificationtemplate.Template, error) {
+	s.mu.RLock()
+	versions := s.versions[notificationtemplate.Key(name, language)]
+	s.mu.RUnlock()
+	if len(versions) > 0 {
+		tmpl := versions[len(versions)-1]
+		return &tmpl, nil
+	}
+	return s.next.Get(ctx, name, language)
+}
+
+// List returns the variants of next with the saved ones replacing them
+func (s *service) List(ctx context.Context) ([]notificationtemplate.Template, error) {
+	shipped, err := s.next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	current := make(map[string]notificationtemplate.Template, len(shipped)+len(s.versions))
+	for _, tmpl := range shipped {
+		current[notificationtemplate.Key(tmpl.Name, tmpl.Language)] = tmpl
+	}
+	for key, versions := range s.versions {
+		current[key] = versions[len(versions)-1]
+	}
+	s.mu.RUnlock()
+
+	list := make([]notificationtemplate.Template, 0, len(current))
+	for _, tmpl := range current {
+		list = append(list, tmpl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return notificationtemplate.Key(list[i].Name, list[i].Language) < notificationtemplate.Key(list[j].Name, list[j].Language)
+	})
+	return list, nil
+}
+
+// Versions returns the saved versions of a variant, newest first, followed
+// by those of next
+func (s *service) Versions(ctx context.Context, name, language string) ([]notificationtemplate.Template, error) {
+	s.mu.RLock()
+	saved := s.versions[notificationtemplate.Key(name, language)]
+	versions := make([]notificationtemplate.Template, 0, len(saved)+1)
+	for i := len(saved) - 1; i >= 0; i-- {
+		versions = append(versions, saved[i])
+	}
+	s.mu.RUnlock()
+
+	shipped, err := s.next.Versions(ctx, name, language)
+	if errors.Is(err, notificationtemplate.ErrTemplateNotFound) && len(versions) > 0 {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(versions, shipped...), nil
+}
+
+// Save validates a variant and stores it as its next version
+func (s *service) Save(ctx context.Context, tmpl notificationtemplate.Template) (*notificationtemplate.Template, error) {
+	tmpl.Language = translator.NormalizeLanguage(tmpl.Language)
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := notificationtemplate.Key(tmpl.Name, tmpl.Language)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmpl.Version = len(s.versions[key]) + 1
+	tmpl.UpdatedAt = time.Now()
+	s.versions[key] = append(s.versions[key], tmpl)
+	return &tmpl, nil
+}
+
+// Revert drops the saved versions of a variant
+func (s *service) Revert(ctx context.Context, name, language string) error {
+	key := notificationtemplate.Key(name, language)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.versions[key]) == 0 {
+		return notificationtemplate.ErrTemplateNotFound
+	}
+	delete(s.versions, key)
+	return nil
+}
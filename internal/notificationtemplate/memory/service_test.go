@@ -0,0 +1,76 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/embedded"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate/memory"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+func TestSave_GivenOverride_WhenRendering_ThenRendersItUntilReverted(t *testing.T) {
+	// Arrange
+	service := memory.NewService(embedded.MustNewService(embedded.Config{}), "en")
+	ctx := context.Background()
+	shipped, err := service.Get(ctx, "welcome_email", "en")
+	require.NoError(t, err)
+	override := *shipped
+	override.Body = "Hello {{.name}}, glad you are here."
+	override.HTML = ""
+	override.UpdatedBy = "admin-1"
+
+	// Act
+	first, saveErr := service.Save(ctx, override)
+	override.Body = "Hey {{.name}}!"
+	second, secondErr := service.Save(ctx, override)
+
+	// Assert
+	require.NoError(t, saveErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, 2, second.Version)
+
+	message, err := service.Render(ctx, "welcome_email", map[string]interface{}{"name": "Jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hey Jane!", message.Body)
+	assert.Equal(t, 2, message.Version)
+
+	spanish, err := service.Render(translator.WithLanguages(ctx, []string{"es"}), "welcome_email", map[string]interface{}{"name": "Jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "es", spanish.Language)
+
+	versions, err := service.Versions(ctx, "welcome_email", "en")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, []int{2, 1, 0}, []int{versions[0].Version, versions[1].Version, versions[2].Version})
+
+	require.NoError(t, service.Revert(ctx, "welcome_email", "en"))
+	message, err = service.Render(ctx, "welcome_email", map[string]interface{}{"name": "Jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Jane, welcome to our platform.", message.Body)
+	assert.ErrorIs(t, service.Revert(ctx, "welcome_email", "en"), notificationtemplate.ErrTemplateNotFound)
+}
+
+func TestSave_GivenInvalidTemplate_WhenSaving_ThenRejectsIt(t *testing.T) {
+	// Arrange
+	service := memory.NewService(embedded.MustNewService(embedded.Config{}), "en")
+	ctx := context.Background()
+	shipped, err := service.Get(ctx, "welcome_email", "en")
+	require.NoError(t, err)
+	broken := *shipped
+	broken.Body = "Hi {{.nmae}}"
+
+	// Act
+	_, err = service.Save(ctx, broken)
+
+	// Assert
+	assert.ErrorIs(t, err, notificationtemplate.ErrInvalidTemplate)
+	current, getErr := service.Get(ctx, "welcome_email", "en")
+	require.NoError(t, getErr)
+	assert.Equal(t, 0, current.Version)
+}
@@ -0,0 +1,342 @@
+package notificationtemplate
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// actionPattern matches the Go template actions of a template
+var actionPattern = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// voidElements are the HTML elements without a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// mjmlDefaults are the attributes of the MJML components before those set
+// on them, as documented by MJML
+var mjmlDefaults = map[string]map[string]string{
+	"mj-body":    {"width": "600px"},
+	"mj-section": {"padding": "20px 0", "text-align": "center"},
+	"mj-column":  {"vertical-align": "top"},
+	"mj-text": {
+		"color": "#000000", "font-family": "Ubuntu, Helvetica, Arial, sans-serif", "font-size": "13px",
+		"line-height": "1.5", "align": "left", "padding": "10px 25px",
+	},
+	"mj-button": {
+		"background-color": "#414141", "color": "#ffffff", "font-family": "Ubuntu, Helvetica, Arial, sans-serif",
+		"font-size": "13px", "padding": "10px 25px", "inner-padding": "10px 25px", "border-radius": "3px", "align": "center",
+	},
+	"mj-image":   {"padding": "10px 25px", "align": "center"},
+	"mj-divider": {"border-color": "#000000", "border-style": "solid", "border-width": "4px", "padding": "10px 25px"},
+	"mj-spacer":  {"height": "20px"},
+}
+
+// mjmlNode is an element or, without a name, a text of an MJML document
+type mjmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*mjmlNode
+	text     string
+}
+
+// attr returns an attribute of the node, or its MJML default
+func (n *mjmlNode) attr(name string) string {
+	for _, attr := range n.attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return mjmlDefaults[n.name][name]
+}
+
+// elements returns the child elements of the node
+func (n *mjmlNode) elements() []*mjmlNode {
+	elements := make([]*mjmlNode, 0, len(n.children))
+	for _, child := range n.children {
+		if child.name != "" {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// MJMLToHTML renders the common MJML components - sections, columns, text,
+// buttons, images, dividers, spacers and raw HTML - to responsive HTML
+// email markup. Go template actions pass through untouched, so the result
+// can be executed as an HTML template. Other MJML components are rejected.
+func MJMLToHTML(source string) (string, error) {
+	var actions []string
+	source = actionPattern.ReplaceAllStringFunc(source, func(action string) string {
+		actions = append(actions, action)
+		return fmt.Sprintf("__mjml_action_%d__", len(actions)-1)
+	})
+
+	root, err := parseMJML(source)
+	if err != nil {
+		return "", err
+	}
+	if root.name != "mjml" {
+		return "", fmt.Errorf("MJML must start with <mjml>, not <%s>", root.name)
+	}
+
+	var head, body *mjmlNode
+	for _, child := range root.elements() {
+		switch child.name {
+		case "mj-head":
+			head = child
+		case "mj-body":
+			body = child
+		default:
+			return "", fmt.Errorf("unsupported MJML element <%s> in <mjml>", child.name)
+		}
+	}
+	if body == nil {
+		return "", errors.New("MJML requires an <mj-body>")
+	}
+
+	var title, preview, styles strings.Builder
+	if head != nil {
+		for _, child := range head.elements() {
+			switch child.name {
+			case "mj-title":
+				title.WriteString(html.EscapeString(innerText(child)))
+			case "mj-preview":
+				preview.WriteString(html.EscapeString(innerText(child)))
+			case "mj-style":
+				styles.WriteString(innerText(child))
+			case "mj-attributes", "mj-font", "mj-breakpoint":
+			default:
+				return "", fmt.Errorf("unsupported MJML element <%s> in <mj-head>", child.name)
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(`<!doctype html><html><head><meta charset="utf-8">`)
+	out.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1">`)
+	out.WriteString(`<title>` + title.String() + `</title>`)
+	out.WriteString(`<style>@media only screen and (max-width:480px){.mj-column{max-width:100% !important}}` + styles.String() + `</style>`)
+	out.WriteString(`</head><body style="margin:0;padding:0">`)
+	if preview.Len() > 0 {
+		out.WriteString(`<div style="display:none;max-height:0;overflow:hidden">` + preview.String() + `</div>`)
+	}
+	if err := renderMJML(&out, body); err != nil {
+		return "", err
+	}
+	out.WriteString(`</body></html>`)
+
+	rendered := out.String()
+	for i := len(actions) - 1; i >= 0; i-- {
+		rendered = strings.ReplaceAll(rendered, fmt.Sprintf("__mjml_action_%d__", i), actions[i])
+	}
+	return rendered, nil
+}
+
+// parseMJML parses an MJML document leniently, accepting the HTML it
+// embeds such as <br> or &nbsp;
+func parseMJML(source string) (*mjmlNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(source))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	document := &mjmlNode{}
+	stack := []*mjmlNode{document}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid MJML: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &mjmlNode{name: strings.ToLower(t.Name.Local), attrs: t.Attr}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &mjmlNode{text: string(t)})
+		}
+	}
+
+	elements := document.elements()
+	if len(elements) != 1 {
+		return nil, errors.New("invalid MJML: expected a single root element")
+	}
+	return elements[0], nil
+}
+
+// renderMJML writes the HTML of an MJML component
+func renderMJML(out *strings.Builder, n *mjmlNode) error {
+	switch n.name {
+	case "mj-body":
+		fmt.Fprintf(out, `<div style="margin:0;padding:0%s"><div style="margin:0 auto;max-width:%s">`,
+			background(n), attr(n, "width"))
+		if err := renderChildren(out, n, "mj-section", "mj-wrapper", "mj-raw"); err != nil {
+			return err
+		}
+		out.WriteString(`</div></div>`)
+
+	case "mj-wrapper":
+		fmt.Fprintf(out, `<div style="padding:%s%s">`, orDefault(attr(n, "padding"), "0"), background(n))
+		if err := renderChildren(out, n, "mj-section", "mj-raw"); err != nil {
+			return err
+		}
+		out.WriteString(`</div>`)
+
+	case "mj-section":
+		fmt.Fprintf(out, `<table role="presentation" border="0" cellpadding="0" cellspacing="0" width="100%%" style="border:0%s">`+
+			`<tbody><tr><td style="padding:%s;text-align:%s;font-size:0">`,
+			background(n), attr(n, "padding"), attr(n, "text-align"))
+		columns := 0
+		for _, child := range n.elements() {
+			if child.name == "mj-column" {
+				columns++
+			}
+		}
+		for _, child := range n.elements() {
+			switch child.name {
+			case "mj-column":
+			case "mj-raw":
+				writeHTML(out, child.children)
+				continue
+			default:
+				return fmt.Errorf("unsupported MJML element <%s> in <mj-section>", child.name)
+			}
+			width := child.attr("width")
+			if width == "" {
+				width = fmt.Sprintf("%g%%", 100/float64(columns))
+			}
+			fmt.Fprintf(out, `<div class="mj-column" style="display:inline-block;vertical-align:%s;width:100%%;max-width:%s;font-size:13px;text-align:left%s">`,
+				attr(child, "vertical-align"), html.EscapeString(width), background(child))
+			if err := renderChildren(out, child, "mj-text", "mj-button", "mj-image", "mj-divider", "mj-spacer", "mj-raw"); err != nil {
+				return err
+			}
+			out.WriteString(`</div>`)
+		}
+		out.WriteString(`</td></tr></tbody></table>`)
+
+	case "mj-text":
+		fmt.Fprintf(out, `<div style="padding:%s;font-family:%s;font-size:%s;line-height:%s;text-align:%s;color:%s">`,
+			attr(n, "padding"), attr(n, "font-family"), attr(n, "font-size"), attr(n, "line-height"), attr(n, "align"), attr(n, "color"))
+		writeHTML(out, n.children)
+		out.WriteString(`</div>`)
+
+	case "mj-button":
+		fmt.Fprintf(out, `<div style="padding:%s;text-align:%s"><a href="%s" target="_blank" style="display:inline-block;background-color:%s;color:%s;`+
+			`font-family:%s;font-size:%s;padding:%s;border-radius:%s;text-decoration:none">`,
+			attr(n, "padding"), attr(n, "align"), attr(n, "href"), attr(n, "background-color"), attr(n, "color"),
+			attr(n, "font-family"), attr(n, "font-size"), attr(n, "inner-padding"), attr(n, "border-radius"))
+		writeHTML(out, n.children)
+		out.WriteString(`</a></div>`)
+
+	case "mj-image":
+		fmt.Fprintf(out, `<div style="padding:%s;text-align:%s">`, attr(n, "padding"), attr(n, "align"))
+		image := fmt.Sprintf(`<img src="%s" alt="%s" style="border:0;display:inline-block;width:100%%;max-width:%s;height:auto">`,
+			attr(n, "src"), attr(n, "alt"), orDefault(attr(n, "width"), "100%"))
+		if href := attr(n, "href"); href != "" {
+			image = fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, href, image)
+		}
+		out.WriteString(image + `</div>`)
+
+	case "mj-divider":
+		fmt.Fprintf(out, `<div style="padding:%s"><p style="border-top:%s %s %s;margin:0;font-size:1px;line-height:0"></p></div>`,
+			attr(n, "padding"), attr(n, "border-style"), attr(n, "border-width"), attr(n, "border-color"))
+
+	case "mj-spacer":
+		fmt.Fprintf(out, `<div style="height:%s;line-height:%s">&#8202;</div>`, attr(n, "height"), attr(n, "height"))
+
+	case "mj-raw":
+		writeHTML(out, n.children)
+
+	default:
+		return fmt.Errorf("unsupported MJML element <%s>", n.name)
+	}
+	return nil
+}
+
+// renderChildren renders the child components of a node, which may only be
+// of the allowed kinds
+func renderChildren(out *strings.Builder, n *mjmlNode, allowed ...string) error {
+	for _, child := range n.elements() {
+		ok := false
+		for _, name := range allowed {
+			ok = ok || child.name == name
+		}
+		if !ok {
+			return fmt.Errorf("unsupported MJML element <%s> in <%s>", child.name, n.name)
+		}
+		if err := renderMJML(out, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHTML writes the HTML content of a component back out
+func writeHTML(out *strings.Builder, nodes []*mjmlNode) {
+	for _, n := range nodes {
+		if n.name == "" {
+			out.WriteString(html.EscapeString(n.text))
+			continue
+		}
+		out.WriteString("<" + n.name)
+		for _, a := range n.attrs {
+			fmt.Fprintf(out, ` %s="%s"`, a.Name.Local, html.EscapeString(a.Value))
+		}
+		out.WriteString(">")
+		if voidElements[n.name] {
+			continue
+		}
+		writeHTML(out, n.children)
+		out.WriteString("</" + n.name + ">")
+	}
+}
+
+// innerText returns the text of a node
+func innerText(n *mjmlNode) string {
+	var text strings.Builder
+	for _, child := range n.children {
+		if child.name == "" {
+			text.WriteString(child.text)
+		} else {
+			text.WriteString(innerText(child))
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// background returns the CSS declaration of the background color of a
+// node, or nothing when it has none
+func background(n *mjmlNode) string {
+	if color := attr(n, "background-color"); color != "" {
+		return ";background-color:" + color
+	}
+	return ""
+}
+
+// attr returns an attribute of a node escaped for an HTML attribute value
+func attr(n *mjmlNode, name string) string {
+	return html.EscapeString(n.attr(name))
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,293 @@
+package notificationtemplate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// Service defines the notification template domain interface - the ONLY
+// interface in this domain. It renders the subject and bodies of
+// notifications from named templates with a variant per language, which
+// administrators can override without a deploy.
+type Service interface {
+	// Render renders the named template in the first language of the
+	// context that has a variant, trying each language before its base
+	// language, or else in the default language
+	Render(ctx context.Context, name string, variables map[string]interface{}) (*Message, error)
+
+	// Preview renders a template that need not be saved, e.g. an edit
+	// before saving it
+	Preview(ctx context.Context, tmpl Template, variables map[string]interface{}) (*Message, error)
+
+	// Get returns the current version of a template variant, or
+	// ErrTemplateNotFound
+	Get(ctx context.Context, name, language string) (*Template, error)
+
+	// List returns the current version of every template variant, ordered
+	// by name and language
+	List(ctx context.Context) ([]Template, error)
+
+	// Versions returns the saved versions of a template variant, newest
+	// first, ending with the shipped one when there is one
+	Versions(ctx context.Context, name, language string) ([]Template, error)
+
+	// Save validates a template variant and stores it as its new current
+	// version, returning it with its version assigned
+	Save(ctx context.Context, tmpl Template) (*Template, error)
+
+	// Revert drops the saved versions of a template variant, going back to
+	// the shipped one, or returns ErrTemplateNotFound when it has none
+	Revert(ctx context.Context, name, language string) error
+}
+
+// Domain types and data structures
+
+// Template is the variant of a notification template in one language.
+// Subject, Body and HTML are Go templates executed against the variables,
+// e.g. "Hi {{.name}}"; HTML may be MJML, starting with <mjml>, rendered to
+// responsive HTML.
+type Template struct {
+	Name     string                        `json:"name"`
+	Language string                        `json:"language"`
+	Channel  notification.NotificationType `json:"channel"`
+
+	// Subject is the email subject or push title; SMS have none
+	Subject string `json:"subject,omitempty"`
+
+	// Body is the plain text body
+	Body string `json:"body"`
+
+	// HTML is the HTML body of emails, optional
+	HTML string `json:"html,omitempty"`
+
+	// Variables declares the variables the template may use
+	Variables []Variable `json:"variables,omitempty"`
+
+	// Version counts the saved versions of the variant; the shipped
+	// variant is version 0
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// Variable is a variable a template may use
+type Variable struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Message is a rendered template
+type Message struct {
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Version  int    `json:"version"`
+	Subject  string `json:"subject,omitempty"`
+	Body     string `json:"body"`
+	HTML     string `json:"html,omitempty"`
+}
+
+// IsMJML reports whether the HTML body is MJML markup
+func (t Template) IsMJML() bool {
+	return strings.HasPrefix(strings.TrimSpace(t.HTML), "<mjml")
+}
+
+// Validate checks a template before it is saved: it needs a name, language,
+// known channel and body, its texts must parse, and they may only use the
+// variables it declares
+func (t Template) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return TemplateError{Code: ErrInvalidTemplate.Code, Message: "name is required", Field: "name"}
+	}
+	if strings.TrimSpace(t.Language) == "" {
+		return TemplateError{Code: ErrInvalidTemplate.Code, Message: "language is required", Field: "language"}
+	}
+	switch t.Channel {
+	case notification.NotificationTypeEmail, notification.NotificationTypePush, notification.NotificationTypeSMS:
+	default:
+		return TemplateError{Code: ErrInvalidTemplate.Code, Message: fmt.Sprintf("unsupported channel %q", t.Channel), Field: "channel"}
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return TemplateError{Code: ErrInvalidTemplate.Code, Message: "body is required", Field: "body"}
+	}
+	if t.Channel == notification.NotificationTypeEmail && strings.TrimSpace(t.Subject) == "" {
+		return TemplateError{Code: ErrInvalidTemplate.Code, Message: "emails require a subject", Field: "subject"}
+	}
+
+	declared := make(map[string]bool, len(t.Variables))
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
+	}
+	for field, text := range map[string]string{"subject": t.Subject, "body": t.Body, "html": t.HTML} {
+		used, err := referencedVariables(text)
+		if err != nil {
+			return TemplateError{Code: ErrInvalidTemplate.Code, Message: fmt.Sprintf("invalid %s: %v", field, err), Field: field}
+		}
+		for _, name := range used {
+			if !declared[name] {
+				return TemplateError{Code: ErrInvalidTemplate.Code, Message: fmt.Sprintf("%s uses undeclared variable %q", field, name), Field: field}
+			}
+		}
+	}
+	if t.IsMJML() {
+		if _, err := MJMLToHTML(t.HTML); err != nil {
+			return TemplateError{Code: ErrInvalidTemplate.Code, Message: "invalid html: " + err.Error(), Field: "html"}
+		}
+	}
+	return nil
+}
+
+// Bind checks the variables a template is rendered with and returns them
+// with the optional variables left out set to empty, so the template can
+// test them. It reports required variables missing with ErrMissingVariable
+// and undeclared ones, usually misspelled, with ErrUnknownVariable.
+func (t Template) Bind(variables map[string]interface{}) (map[string]interface{}, error) {
+	declared := make(map[string]bool, len(t.Variables))
+	bound := make(map[string]interface{}, len(t.Variables))
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
+		value, ok := variables[variable.Name]
+		if !ok && variable.Required {
+			return nil, TemplateError{Code: ErrMissingVariable.Code, Message: fmt.Sprintf("variable %q is required", variable.Name), Field: variable.Name}
+		}
+		if !ok {
+			value = ""
+		}
+		bound[variable.Name] = value
+	}
+
+	unknown := make([]string, 0)
+	for name := range variables {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, TemplateError{Code: ErrUnknownVariable.Code, Message: fmt.Sprintf("unknown variables %s", strings.Join(unknown, ", ")), Field: unknown[0]}
+	}
+	return bound, nil
+}
+
+// Key identifies a template variant
+func Key(name, language string) string {
+	return name + "/" + translator.NormalizeLanguage(language)
+}
+
+// Languages returns the languages to look a template up in, in order: those
+// of the context, each followed by its base language, then the default one
+func Languages(ctx context.Context, defaultLanguage string) []string {
+	seen := make(map[string]bool)
+	languages := make([]string, 0)
+	add := func(language string) {
+		if language != "" && !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	for _, language := range translator.LanguagesFromContext(ctx) {
+		add(translator.NormalizeLanguage(language))
+		add(translator.BaseLanguage(language))
+	}
+	add(translator.NormalizeLanguage(defaultLanguage))
+	return languages
+}
+
+// referencedVariables returns the top-level variables a template text uses,
+// e.g. name for {{.name}} or {{$.name}}. Fields used where range or with
+// moved the dot refer to the element, not to a variable, and are skipped.
+func referencedVariables(text string) ([]string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Tree == nil {
+		return nil, nil
+	}
+
+	var used []string
+	var walk func(node parse.Node, atRoot bool)
+	walk = func(node parse.Node, atRoot bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, atRoot)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, atRoot)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, atRoot)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, atRoot)
+			}
+		case *parse.FieldNode:
+			if atRoot {
+				used = append(used, n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				used = append(used, n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, atRoot)
+		case *parse.IfNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, atRoot)
+			walk(n.ElseList, atRoot)
+		case *parse.RangeNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		case *parse.WithNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		}
+	}
+	walk(tmpl.Tree.Root, true)
+	return used, nil
+}
+
+// TemplateError represents notification template domain errors
+type TemplateError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e TemplateError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so errors with a specific message still match
+// the common error they refine
+func (e TemplateError) Is(target error) bool {
+	t, ok := target.(TemplateError)
+	return ok && t.Code == e.Code
+}
+
+// Common notification template errors
+var (
+	ErrTemplateNotFound = TemplateError{Code: "TEMPLATE_NOT_FOUND", Message: "Notification template not found"}
+	ErrInvalidTemplate  = TemplateError{Code: "INVALID_TEMPLATE", Message: "Invalid notification template"}
+	ErrMissingVariable  = TemplateError{Code: "MISSING_TEMPLATE_VARIABLE", Message: "Missing template variable"}
+	ErrUnknownVariable  = TemplateError{Code: "UNKNOWN_TEMPLATE_VARIABLE", Message: "Unknown template variable"}
+	ErrReadOnly         = TemplateError{Code: "TEMPLATES_READ_ONLY", Message: "Notification templates cannot be changed"}
+)
@@ -0,0 +1,165 @@
+package notificationtemplate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+)
+
+func welcomeTemplate() notificationtemplate.Template {
+	return notificationtemplate.Template{
+		Name:     "welcome_email",
+		Language: "en",
+		Channel:  notification.NotificationTypeEmail,
+		Subject:  "Welcome {{.name}}",
+		Body:     "Hi {{.name}}{{if .team}}, you joined {{.team}}{{end}}.",
+		Variables: []notificationtemplate.Variable{
+			{Name: "name", Required: true},
+			{Name: "team"},
+		},
+	}
+}
+
+func TestValidate_GivenTemplate_WhenValidating_ThenChecksTextsAndVariables(t *testing.T) {
+	tests := []struct {
+		name          string
+		change        func(tmpl *notificationtemplate.Template)
+		expectedField string
+	}{
+		{
+			name:   "Given a template using declared variables only, When validating, Then accepts it",
+			change: func(tmpl *notificationtemplate.Template) {},
+		},
+		{
+			name:          "Given a template using an undeclared variable, When validating, Then rejects it",
+			change:        func(tmpl *notificationtemplate.Template) { tmpl.Body = "Hi {{.first_name}}" },
+			expectedField: "body",
+		},
+		{
+			name: "Given fields of range elements, When validating, Then does not take them for variables",
+			change: func(tmpl *notificationtemplate.Template) {
+				tmpl.Body = "{{range .team}}{{.member}} {{end}}{{$.name}}"
+			},
+		},
+		{
+			name:          "Given a template that does not parse, When validating, Then rejects it",
+			change:        func(tmpl *notificationtemplate.Template) { tmpl.Subject = "Welcome {{.name" },
+			expectedField: "subject",
+		},
+		{
+			name:          "Given an email without subject, When validating, Then rejects it",
+			change:        func(tmpl *notificationtemplate.Template) { tmpl.Subject = "" },
+			expectedField: "subject",
+		},
+		{
+			name: "Given MJML with an unsupported component, When validating, Then rejects it",
+			change: func(tmpl *notificationtemplate.Template) {
+				tmpl.HTML = "<mjml><mj-body><mj-carousel/></mj-body></mjml>"
+			},
+			expectedField: "html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := welcomeTemplate()
+			tt.change(&tmpl)
+
+			// Act
+			err := tmpl.Validate()
+
+			// Assert
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var templateErr notificationtemplate.TemplateError
+			require.ErrorAs(t, err, &templateErr)
+			assert.ErrorIs(t, err, notificationtemplate.ErrInvalidTemplate)
+			assert.Equal(t, tt.expectedField, templateErr.Field)
+		})
+	}
+}
+
+func TestRender_GivenVariables_WhenRendering_ThenBindsThemToTheTemplate(t *testing.T) {
+	tests := []struct {
+		name            string
+		variables       map[string]interface{}
+		expectedBody    string
+		expectedSubject string
+		expectedError   error
+	}{
+		{
+			name:            "Given every variable, When rendering, Then renders them",
+			variables:       map[string]interface{}{"name": "Jane", "team": "Sales"},
+			expectedSubject: "Welcome Jane",
+			expectedBody:    "Hi Jane, you joined Sales.",
+		},
+		{
+			name:            "Given an optional variable left out, When rendering, Then renders it empty",
+			variables:       map[string]interface{}{"name": "Jane"},
+			expectedSubject: "Welcome Jane",
+			expectedBody:    "Hi Jane.",
+		},
+		{
+			name:          "Given a required variable left out, When rendering, Then fails",
+			variables:     map[string]interface{}{"team": "Sales"},
+			expectedError: notificationtemplate.ErrMissingVariable,
+		},
+		{
+			name:          "Given a misspelled variable, When rendering, Then fails",
+			variables:     map[string]interface{}{"name": "Jane", "taem": "Sales"},
+			expectedError: notificationtemplate.ErrUnknownVariable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := welcomeTemplate()
+
+			// Act
+			message, err := tmpl.Render(tt.variables)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, message.Subject)
+			assert.Equal(t, tt.expectedBody, message.Body)
+		})
+	}
+}
+
+func TestRender_GivenMJML_WhenRendering_ThenRendersEscapedHTML(t *testing.T) {
+	// Arrange
+	tmpl := welcomeTemplate()
+	tmpl.HTML = `<mjml>
+		<mj-head><mj-title>Welcome</mj-title></mj-head>
+		<mj-body>
+			<mj-section>
+				<mj-column><mj-text color="#333333">Hi {{.name}},<br>welcome!</mj-text></mj-column>
+				<mj-column><mj-button href="https://example.com/start">Get started</mj-button></mj-column>
+			</mj-section>
+		</mj-body>
+	</mjml>`
+
+	// Act
+	message, err := tmpl.Render(map[string]interface{}{"name": "<Jane>"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, message.HTML, "<title>Welcome</title>")
+	assert.Contains(t, message.HTML, "color:#333333")
+	assert.Contains(t, message.HTML, "Hi &lt;Jane&gt;,<br>welcome!")
+	assert.Contains(t, message.HTML, `max-width:50%`)
+	assert.Contains(t, message.HTML, `<a href="https://example.com/start"`)
+	assert.NotContains(t, message.HTML, "<mj-")
+}
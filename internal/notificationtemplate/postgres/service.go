@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/notificationtemplate"
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// templateColumns are the notification_templates columns scanTemplate reads,
+// in order
+const templateColumns = `name, language, version, channel, subject, body, html, variables, updated_at, updated_by`
+
+// service implements notificationtemplate.Service by keeping saved template
+// versions in the notification_templates table on top of the templates of
+// next, so every instance renders the same overrides
+type service struct {
+	next            notificationtemplate.Service
+	pool            *pgxpool.Pool
+	defaultLanguage string
+}
+
+// NewService creates Postgres-backed template overrides in front of next,
+// which renders them. defaultLanguage defaults to translator.DefaultLanguage.
+func NewService(next notificationtemplate.Service, pool *pgxpool.Pool, defaultLanguage string) notificationtemplate.Service {
+	if defaultLanguage == "" {
+		defaultLanguage = translator.DefaultLanguage
+	}
+	return &service{next: next, pool: pool, defaultLanguage: translator.NormalizeLanguage(defaultLanguage)}
+}
+
+// Render renders the current version of the variant of the first language
+// of the context that has one, or of the default language
+func (s *service) Render(ctx context.Context, name string, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	for _, language := range notificationtemplate.Languages(ctx, s.defaultLanguage) {
+		tmpl, err := s.Get(ctx, name, language)
+		if errors.Is(err, notificationtemplate.ErrTemplateNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.next.Preview(ctx, *tmpl, variables)
+	}
+	return nil, notificationtemplate.ErrTemplateNotFound
+}
+
+// Preview delegates to the next service
+func (s *service) Preview(ctx context.Context, tmpl notificationtemplate.Template, variables map[string]interface{}) (*notificationtemplate.Message, error) {
+	return s.next.Preview(ctx, tmpl, variables)
+}
+
+// Get returns the latest saved version of a variant, or else that of next
+func (s *service) Get(ctx context.Context, name, language string) (*notificationtemplate.Template, error) {
+	tmpl, err := scanTemplate(s.pool.QueryRow(ctx, `
+		SELECT `+templateColumns+` FROM notification_templates
+		WHERE name = $1 AND language = $2
+		ORDER BY version DESC LIMIT 1`,
+		name, translator.NormalizeLanguage(language)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.next.Get(ctx, name, language)
+	}
+	return tmpl, err
+}
+
+// List returns the variants of next with the saved ones replacing them
+func (s *service) List(ctx context.Context) ([]notificationtemplate.Template, error) {
+	shipped, err := s.next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]notificationtemplate.Template, len(shipped))
+	for _, tmpl := range shipped {
+		current[notificationtemplate.Key(tmpl.Name, tmpl.Language)] = tmpl
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT ON (name, language) `+templateColumns+` FROM notification_templates
+		ORDER BY name, language, version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := scanTemplates(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, tmpl := range saved {
+		current[notificationtemplate.Key(tmpl.Name, tmpl.Language)] = tmpl
+	}
+
+	list := make([]notificationtemplate.Template, 0, len(current))
+	for _, tmpl := range current {
+		list = append(list, tmpl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return notificationtemplate.Key(list[i].Name, list[i].Language) < notificationtemplate.Key(list[j].Name, list[j].Language)
+	})
+	return list, nil
+}
+
+// Versions returns the saved versions of a variant, newest first, followed
+// by those of next
+func (s *service) Versions(ctx context.Context, name, language string) ([]notificationtemplate.Template, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+templateColumns+` FROM notification_templates
+		WHERE name = $1 AND language = $2
+		ORDER BY version DESC`,
+		name, translator.NormalizeLanguage(language))
+	if err != nil {
+		return nil, err
+	}
+	versions, err := scanTemplates(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	shipped, err := s.next.Versions(ctx, name, language)
+	if errors.Is(err, notificationtemplate.ErrTemplateNotFound) && len(versions) > 0 {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(versions, shipped...), nil
+}
+
+// Save validates a variant and inserts it as its next version
+func (s *service) Save(ctx context.Context, tmpl notificationtemplate.Template) (*notificationtemplate.Template, error) {
+	tmpl.Language = translator.NormalizeLanguage(tmpl.Language)
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	variables, err := json.Marshal(tmpl.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO notification_templates (name, language, version, channel, subject, body, html, variables, updated_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7, $8
+		FROM notification_templates WHERE name = $1 AND language = $2
+		RETURNING version, updated_at`,
+		tmpl.Name, tmpl.Language, tmpl.Channel, tmpl.Subject, tmpl.Body, tmpl.HTML, variables, tmpl.UpdatedBy,
+	).Scan(&tmpl.Version, &tmpl.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// Revert deletes the saved versions of a variant
+func (s *service) Revert(ctx context.Context, name, language string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM notification_templates WHERE name = $1 AND language = $2`,
+		name, translator.NormalizeLanguage(language))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notificationtemplate.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// scanTemplates reads the templates of rows, closing them
+func scanTemplates(rows pgx.Rows) ([]notificationtemplate.Template, error) {
+	defer rows.Close()
+	templates := []notificationtemplate.Template{}
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tmpl)
+	}
+	return templates, rows.Err()
+}
+
+// scanTemplate reads one template in templateColumns order
+func scanTemplate(row pgx.Row) (*notificationtemplate.Template, error) {
+	var tmpl notificationtemplate.Template
+	var variables []byte
+	if err := row.Scan(&tmpl.Name, &tmpl.Language, &tmpl.Version, &tmpl.Channel, &tmpl.Subject, &tmpl.Body, &tmpl.HTML,
+		&variables, &tmpl.UpdatedAt, &tmpl.UpdatedBy); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variables, &tmpl.Variables); err != nil {
+		return nil, fmt.Errorf("failed to decode variables of template %s/%s: %w", tmpl.Name, tmpl.Language, err)
+	}
+	return &tmpl, nil
+}
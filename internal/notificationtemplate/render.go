@@ -0,0 +1,61 @@
+package notificationtemplate
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"text/template"
+)
+
+// Render binds the variables and executes the subject, body and HTML of the
+// template, converting MJML to HTML first. The HTML body escapes variables
+// for the context they appear in; the subject and plain text body do not.
+func (t Template) Render(variables map[string]interface{}) (*Message, error) {
+	bound, err := t.Bind(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &Message{Name: t.Name, Language: t.Language, Version: t.Version}
+	if message.Subject, err = executeText("subject", t.Subject, bound); err != nil {
+		return nil, err
+	}
+	if message.Body, err = executeText("body", t.Body, bound); err != nil {
+		return nil, err
+	}
+	if t.HTML == "" {
+		return message, nil
+	}
+
+	source := t.HTML
+	if t.IsMJML() {
+		if source, err = MJMLToHTML(source); err != nil {
+			return nil, TemplateError{Code: ErrInvalidTemplate.Code, Message: "invalid html: " + err.Error(), Field: "html"}
+		}
+	}
+	tmpl, err := htmltemplate.New("html").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, TemplateError{Code: ErrInvalidTemplate.Code, Message: "invalid html: " + err.Error(), Field: "html"}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, bound); err != nil {
+		return nil, TemplateError{Code: ErrInvalidTemplate.Code, Message: "failed to render html: " + err.Error(), Field: "html"}
+	}
+	message.HTML = out.String()
+	return message, nil
+}
+
+// executeText executes a plain text template
+func executeText(field, text string, variables map[string]interface{}) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", TemplateError{Code: ErrInvalidTemplate.Code, Message: "invalid " + field + ": " + err.Error(), Field: field}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, variables); err != nil {
+		return "", TemplateError{Code: ErrInvalidTemplate.Code, Message: "failed to render " + field + ": " + err.Error(), Field: field}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,77 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/memory"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/postgres"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// Config contains all configuration for building the OAuth authorization server
+type Config struct {
+	// Provider configuration
+	Provider string // "memory", "postgres"
+
+	// Pool is the connection pool of the postgres provider
+	Pool *pgxpool.Pool
+
+	// Domain services
+	TokenService token.Service
+	AuditService audit.Service
+}
+
+// OAuthServerServiceFactory creates and assembles the OAuth authorization server
+type OAuthServerServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new OAuth authorization server factory with the given configuration
+func NewFactory(config Config) *OAuthServerServiceFactory {
+	return &OAuthServerServiceFactory{
+		config: config,
+	}
+}
+
+// Build assembles and returns the OAuth authorization server based on configuration
+func (f *OAuthServerServiceFactory) Build() (oauthserver.Service, error) {
+	if f.config.TokenService == nil {
+		return nil, fmt.Errorf("token service is required")
+	}
+
+	switch f.config.Provider {
+	case "", "memory":
+		return f.buildMemoryService()
+	case "postgres":
+		return f.buildPostgresService()
+	default:
+		return nil, fmt.Errorf("unknown OAuth server provider %q", f.config.Provider)
+	}
+}
+
+// buildMemoryService creates an in-memory OAuth authorization server
+func (f *OAuthServerServiceFactory) buildMemoryService() (oauthserver.Service, error) {
+	return memory.NewService(f.config.TokenService, f.config.AuditService), nil
+}
+
+// buildPostgresService creates an OAuth authorization server whose clients
+// and codes are stored in Postgres and shared by every instance
+func (f *OAuthServerServiceFactory) buildPostgresService() (oauthserver.Service, error) {
+	if f.config.Pool == nil {
+		return nil, fmt.Errorf("postgres connection pool is required")
+	}
+	return postgres.NewService(f.config.Pool, f.config.TokenService, f.config.AuditService), nil
+}
+
+// DefaultConfig returns a sensible default configuration for the OAuth authorization server
+func DefaultConfig(tokenService token.Service, auditService audit.Service) Config {
+	return Config{
+		Provider:     "memory",
+		TokenService: tokenService,
+		AuditService: auditService,
+	}
+}
@@ -0,0 +1,341 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// service implements oauthserver.Service interface using in-memory storage
+type service struct {
+	clients      map[string]*record
+	codes        map[string]*pendingCode
+	mu           sync.Mutex
+	tokenService token.Service
+	auditService audit.Service
+}
+
+// record is a stored client with its hashed secret; public clients have none
+type record struct {
+	client     oauthserver.Client
+	secretHash [sha256.Size]byte
+}
+
+// pendingCode is an issued authorization code. Codes are kept after their
+// exchange until they expire, so a replayed code revokes the token it got.
+type pendingCode struct {
+	clientID      string
+	userID        string
+	redirectURI   string
+	scopes        []string
+	codeChallenge string
+	expiresAt     time.Time
+	redeemed      bool
+	issuedToken   string // The token the code was exchanged for
+}
+
+// NewService creates a new in-memory OAuth authorization server
+func NewService(tokenService token.Service, auditService audit.Service) oauthserver.Service {
+	return &service{
+		clients:      make(map[string]*record),
+		codes:        make(map[string]*pendingCode),
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// RegisterClient registers a client; confidential clients get a secret
+func (s *service) RegisterClient(ctx context.Context, req oauthserver.ClientRequest) (*oauthserver.ClientCredentials, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := req.NewClient(uuid.New().String(), time.Now())
+
+	rec := &record{client: client}
+	credentials := &oauthserver.ClientCredentials{Client: client}
+	if client.Type == oauthserver.ClientTypeConfidential {
+		secret, hash, err := oauthserver.GenerateSecret(oauthserver.SecretSize)
+		if err != nil {
+			return nil, err
+		}
+		rec.secretHash = hash
+		credentials.Secret = secret
+	}
+
+	s.mu.Lock()
+	s.clients[client.ID] = rec
+	s.mu.Unlock()
+
+	s.logAudit(ctx, "oauth_client.register", client.ID, "", nil, map[string]interface{}{
+		"type":        client.Type,
+		"grant_types": client.GrantTypes,
+		"scopes":      client.Scopes,
+	})
+
+	return credentials, nil
+}
+
+// GetClient returns a client by ID
+func (s *service) GetClient(ctx context.Context, id string) (*oauthserver.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, exists := s.clients[id]
+	if !exists {
+		return nil, oauthserver.ErrClientNotFound
+	}
+
+	client := rec.client
+	return &client, nil
+}
+
+// ListClients returns every client ordered by registration time
+func (s *service) ListClients(ctx context.Context) ([]oauthserver.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients := make([]oauthserver.Client, 0, len(s.clients))
+	for _, rec := range s.clients {
+		clients = append(clients, rec.client)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.Before(clients[j].CreatedAt)
+	})
+
+	return clients, nil
+}
+
+// DeleteClient removes the client and its pending codes, and revokes the
+// tokens it obtained for itself. Tokens issued to it on behalf of users
+// stay valid until they expire or the users log out everywhere.
+func (s *service) DeleteClient(ctx context.Context, id string) error {
+	s.mu.Lock()
+	rec, exists := s.clients[id]
+	if !exists {
+		s.mu.Unlock()
+		return oauthserver.ErrClientNotFound
+	}
+	delete(s.clients, id)
+	for code, pending := range s.codes {
+		if pending.clientID == id {
+			delete(s.codes, code)
+		}
+	}
+	s.mu.Unlock()
+
+	err := s.tokenService.RevokeAllTokensForUser(ctx, rec.client.Principal())
+	s.logAudit(ctx, "oauth_client.delete", id, "", err, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke OAuth client tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Authorize checks the request against the client's registration and issues
+// a code bound to its redirect URI, scopes and PKCE challenge
+func (s *service) Authorize(ctx context.Context, req oauthserver.AuthorizeRequest) (*oauthserver.AuthorizationCode, error) {
+	client, err := s.GetClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, oauthserver.ErrInvalidClient
+	}
+	scopes, err := client.AuthorizeScopes(req)
+	if err != nil {
+		return nil, err
+	}
+
+	code, _, err := oauthserver.GenerateSecret(oauthserver.CodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(oauthserver.CodeTTL)
+
+	s.mu.Lock()
+	s.removeExpiredCodes(now)
+	s.codes[code] = &pendingCode{
+		clientID:      client.ID,
+		userID:        req.UserID,
+		redirectURI:   req.RedirectURI,
+		scopes:        scopes,
+		codeChallenge: req.CodeChallenge,
+		expiresAt:     expiresAt,
+	}
+	s.mu.Unlock()
+
+	s.logAudit(ctx, "oauth.authorize", client.ID, req.UserID, nil, map[string]interface{}{
+		"scopes": scopes,
+	})
+
+	return &oauthserver.AuthorizationCode{
+		Code:        code,
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// Token dispatches the request to its grant
+func (s *service) Token(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	switch req.GrantType {
+	case oauthserver.GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, req)
+	case oauthserver.GrantTypeClientCredentials:
+		return s.clientCredentials(ctx, req)
+	case "":
+		return nil, oauthserver.OAuthError{Code: oauthserver.ErrInvalidRequest.Code, Message: "grant_type is required"}
+	default:
+		return nil, oauthserver.ErrUnsupportedGrantType
+	}
+}
+
+// exchangeCode redeems an authorization code once for a token scoped to what
+// the user granted. A code presented again revokes the token it was
+// exchanged for, as it may have been stolen.
+func (s *service) exchangeCode(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(oauthserver.GrantTypeAuthorizationCode) {
+		return nil, oauthserver.ErrUnauthorizedClient
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	pending, exists := s.codes[req.Code]
+	if exists && pending.redeemed {
+		replayed := pending.issuedToken
+		delete(s.codes, req.Code)
+		s.mu.Unlock()
+
+		if replayed != "" {
+			if err := s.tokenService.RevokeToken(ctx, replayed); err != nil {
+				log.Printf("Failed to revoke token of replayed authorization code: %v", err)
+			}
+		}
+		s.logAudit(ctx, "oauth.code_replayed", client.ID, pending.userID, oauthserver.ErrInvalidGrant, nil)
+		return nil, oauthserver.ErrInvalidGrant
+	}
+	if !exists || pending.clientID != client.ID || now.After(pending.expiresAt) || pending.redirectURI != req.RedirectURI {
+		s.mu.Unlock()
+		return nil, oauthserver.ErrInvalidGrant
+	}
+	if err := oauthserver.CheckCodeVerifier(req.CodeVerifier, pending.codeChallenge); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	// Claim the code before issuing so concurrent exchanges cannot both succeed
+	pending.redeemed = true
+	s.mu.Unlock()
+
+	ctx = oauthserver.CodeTokenContext(ctx, *client)
+	response, apiToken, err := s.issueToken(ctx, pending.userID, pending.scopes)
+
+	s.mu.Lock()
+	if err != nil {
+		delete(s.codes, req.Code)
+	} else {
+		pending.issuedToken = apiToken
+	}
+	s.mu.Unlock()
+
+	s.logAudit(ctx, "oauth.token", client.ID, pending.userID, err, map[string]interface{}{
+		"grant_type": oauthserver.GrantTypeAuthorizationCode,
+		"scopes":     pending.scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// clientCredentials issues a confidential client a token for itself
+func (s *service) clientCredentials(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Type != oauthserver.ClientTypeConfidential || !client.AllowsGrant(oauthserver.GrantTypeClientCredentials) {
+		return nil, oauthserver.ErrUnauthorizedClient
+	}
+
+	scopes, err := client.GrantedScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oauthserver.ClientTokenContext(ctx, *client)
+	response, _, err := s.issueToken(ctx, client.Principal(), scopes)
+	s.logAudit(ctx, "oauth.token", client.ID, client.Principal(), err, map[string]interface{}{
+		"grant_type": oauthserver.GrantTypeClientCredentials,
+		"scopes":     scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// issueToken issues an API token for subject limited to scopes
+func (s *service) issueToken(ctx context.Context, subject string, scopes []string) (*oauthserver.TokenResponse, string, error) {
+	apiToken, err := s.tokenService.GenerateAPIToken(ctx, subject, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return oauthserver.NewTokenResponse(apiToken, scopes), apiToken.Token, nil
+}
+
+// authenticateClient returns the client when the credentials match
+func (s *service) authenticateClient(id, secret string) (*oauthserver.Client, error) {
+	s.mu.Lock()
+	rec, exists := s.clients[id]
+	var client oauthserver.Client
+	valid := false
+	if exists {
+		client = rec.client
+		valid = client.VerifySecret(secret, rec.secretHash[:])
+	}
+	s.mu.Unlock()
+
+	if !exists || !valid {
+		return nil, oauthserver.ErrInvalidClient
+	}
+	return &client, nil
+}
+
+// removeExpiredCodes drops codes past their expiry; callers hold the lock
+func (s *service) removeExpiredCodes(now time.Time) {
+	for code, pending := range s.codes {
+		if now.After(pending.expiresAt) {
+			delete(s.codes, code)
+		}
+	}
+}
+
+// logAudit records a client lifecycle or grant event, attributed to the
+// calling user or, when there is none, to subject
+func (s *service) logAudit(ctx context.Context, action, clientID, subject string, err error, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := oauthserver.NewAuditEntry(ctx, action, clientID, subject, err, details)
+	if logErr := s.auditService.Log(ctx, entry); logErr != nil {
+		log.Printf("Failed to audit %s for OAuth client %s: %v", action, clientID, logErr)
+	}
+}
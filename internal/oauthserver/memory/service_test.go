@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/memory"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/oauthservertest"
+)
+
+func TestMemoryService_Conformance(t *testing.T) {
+	oauthservertest.RunServiceConformance(t, memory.NewService)
+}
@@ -0,0 +1,447 @@
+package oauthserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// Service defines the OAuth authorization server domain interface - the ONLY interface in this domain
+// It lets registered clients obtain scoped access tokens from the token
+// domain: first-party SPAs through the authorization code grant with PKCE on
+// behalf of a signed-in user, and machine clients through the client
+// credentials grant on their own behalf.
+type Service interface {
+	// Client management
+	RegisterClient(ctx context.Context, req ClientRequest) (*ClientCredentials, error)
+	GetClient(ctx context.Context, id string) (*Client, error)
+	ListClients(ctx context.Context) ([]Client, error)
+	DeleteClient(ctx context.Context, id string) error
+
+	// Authorize issues an authorization code for the signed-in user, to be
+	// exchanged at the token endpoint by the same client
+	Authorize(ctx context.Context, req AuthorizeRequest) (*AuthorizationCode, error)
+
+	// Token handles a token endpoint request for any supported grant
+	Token(ctx context.Context, req TokenRequest) (*TokenResponse, error)
+}
+
+// Domain types and data structures
+
+// Client types as defined by RFC 6749 section 2.1
+const (
+	ClientTypePublic       = "public"       // Cannot keep a secret, e.g. SPAs; must use PKCE
+	ClientTypeConfidential = "confidential" // Authenticates with a client secret
+)
+
+// Supported grant types
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// CodeChallengeMethodS256 is the only PKCE method accepted; "plain" offers no
+// protection against intercepted codes
+const CodeChallengeMethodS256 = "S256"
+
+// CodeTTL is how long an authorization code can be exchanged
+const CodeTTL = 10 * time.Minute
+
+// Sizes in random bytes of client secrets and authorization codes
+const (
+	SecretSize = 32
+	CodeSize   = 32
+)
+
+// PrincipalPrefix marks token subjects and audit identities that are OAuth
+// clients acting on their own behalf
+const PrincipalPrefix = "oauth_client:"
+
+// Client is an application registered to obtain tokens
+type Client struct {
+	ID           string    `json:"client_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	RedirectURIs []string  `json:"redirect_uris,omitempty"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Principal returns the identity used in tokens the client obtains for itself
+func (c *Client) Principal() string {
+	return PrincipalPrefix + c.ID
+}
+
+// AllowsGrant reports whether the client was registered for the grant type
+func (c *Client) AllowsGrant(grantType string) bool {
+	for _, g := range c.GrantTypes {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRedirectURI reports whether the URI exactly matches a registered one
+func (c *Client) AllowsRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifySecret reports whether secret authenticates the client against the
+// stored hash. Public clients authenticate with their ID alone; PKCE
+// protects their codes.
+func (c *Client) VerifySecret(secret string, secretHash []byte) bool {
+	if c.Type == ClientTypePublic {
+		return secret == ""
+	}
+	hash := HashSecret(secret)
+	return subtle.ConstantTimeCompare(hash[:], secretHash) == 1
+}
+
+// GrantedScopes returns the requested scopes, or every registered scope when
+// none are requested, failing when any was not registered for the client
+func (c *Client) GrantedScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return append([]string(nil), c.Scopes...), nil
+	}
+
+	for _, scope := range requested {
+		if !containsScope(c.Scopes, scope) {
+			err := ErrInvalidScope
+			err.Message = fmt.Sprintf("%s: %s", err.Message, scope)
+			return nil, err
+		}
+	}
+	return append([]string(nil), requested...), nil
+}
+
+// AuthorizeScopes checks an authorization request against the client's
+// registration and returns the scopes its code grants
+func (c *Client) AuthorizeScopes(req AuthorizeRequest) ([]string, error) {
+	if !c.AllowsRedirectURI(req.RedirectURI) {
+		return nil, ErrInvalidRedirectURI
+	}
+	if req.ResponseType != "code" {
+		return nil, ErrUnsupportedResponseType
+	}
+	if !c.AllowsGrant(GrantTypeAuthorizationCode) {
+		return nil, ErrUnauthorizedClient
+	}
+	if req.UserID == "" {
+		return nil, OAuthError{Code: ErrInvalidRequest.Code, Message: "A signed-in user is required"}
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != CodeChallengeMethodS256 {
+		return nil, OAuthError{Code: ErrInvalidRequest.Code, Message: "PKCE with code_challenge_method S256 is required"}
+	}
+
+	return c.GrantedScopes(req.Scopes)
+}
+
+// ClientRequest contains data for registering a client
+type ClientRequest struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types"`
+	Scopes       []string `json:"scopes"`
+
+	// CreatedBy is the administrator registering the client
+	CreatedBy string `json:"-"`
+}
+
+// ClientCredentials is returned when a client is registered. Confidential
+// clients get their secret only here; the service stores a hash.
+type ClientCredentials struct {
+	Client Client `json:"client"`
+	Secret string `json:"client_secret,omitempty"`
+}
+
+// Validate checks a registration is complete and consistent
+func (r ClientRequest) Validate() error {
+	invalid := func(message string) error {
+		return OAuthError{Code: ErrInvalidClientMetadata.Code, Message: message}
+	}
+
+	if strings.TrimSpace(r.Name) == "" {
+		return invalid("Client name is required")
+	}
+	if r.Type != ClientTypePublic && r.Type != ClientTypeConfidential {
+		return invalid("Client type must be public or confidential")
+	}
+	if len(r.GrantTypes) == 0 {
+		return invalid("At least one grant type is required")
+	}
+
+	for _, grantType := range r.GrantTypes {
+		switch grantType {
+		case GrantTypeAuthorizationCode:
+			if len(r.RedirectURIs) == 0 {
+				return invalid("The authorization code grant requires a redirect URI")
+			}
+		case GrantTypeClientCredentials:
+			if r.Type != ClientTypeConfidential {
+				return invalid("The client credentials grant requires a confidential client")
+			}
+		default:
+			return invalid(fmt.Sprintf("Unsupported grant type: %s", grantType))
+		}
+	}
+
+	for _, uri := range r.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" {
+			return OAuthError{Code: ErrInvalidRedirectURI.Code, Message: fmt.Sprintf("Redirect URI must be absolute and without a fragment: %s", uri)}
+		}
+	}
+
+	return nil
+}
+
+// NewClient returns the client registered by a valid request
+func (r ClientRequest) NewClient(id string, createdAt time.Time) Client {
+	return Client{
+		ID:           id,
+		Name:         strings.TrimSpace(r.Name),
+		Type:         r.Type,
+		RedirectURIs: append([]string(nil), r.RedirectURIs...),
+		GrantTypes:   append([]string(nil), r.GrantTypes...),
+		Scopes:       append([]string(nil), r.Scopes...),
+		CreatedBy:    r.CreatedBy,
+		CreatedAt:    createdAt,
+	}
+}
+
+// AuthorizeRequest is an authorization endpoint request made for a signed-in user
+type AuthorizeRequest struct {
+	ResponseType        string   `json:"response_type"` // Must be "code"
+	ClientID            string   `json:"client_id"`
+	RedirectURI         string   `json:"redirect_uri"`
+	Scopes              []string `json:"scopes"`
+	State               string   `json:"state,omitempty"`
+	CodeChallenge       string   `json:"code_challenge"`
+	CodeChallengeMethod string   `json:"code_challenge_method"`
+
+	// UserID is the signed-in user granting the client access
+	UserID string `json:"-"`
+}
+
+// AuthorizationCode is a single-use code the client exchanges for a token
+type AuthorizationCode struct {
+	Code        string    `json:"code"`
+	RedirectURI string    `json:"redirect_uri"`
+	State       string    `json:"state,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// RedirectURL returns the redirect URI carrying the code and state
+func (c *AuthorizationCode) RedirectURL() string {
+	params := url.Values{"code": {c.Code}}
+	if c.State != "" {
+		params.Set("state", c.State)
+	}
+	return AppendQuery(c.RedirectURI, params)
+}
+
+// TokenRequest is a token endpoint request
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string // client_credentials only; empty requests every registered scope
+
+	// authorization_code only
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+// TokenResponse is the successful token endpoint response of RFC 6749 section 5.1.
+// No refresh token is issued: SPAs run the authorization code flow again and
+// machine clients request a new token with their credentials.
+type TokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"` // "Bearer"
+	ExpiresIn   int64     `json:"expires_in"` // seconds
+	Scope       string    `json:"scope,omitempty"`
+	ExpiresAt   time.Time `json:"-"`
+}
+
+// NewTokenResponse describes the API token issued for scopes
+func NewTokenResponse(apiToken *token.APIToken, scopes []string) *TokenResponse {
+	return &TokenResponse{
+		AccessToken: apiToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(apiToken.ExpiresAt).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+		ExpiresAt:   apiToken.ExpiresAt,
+	}
+}
+
+// CheckCodeVerifier checks the PKCE verifier of a code exchange against the
+// challenge the code was issued for
+func CheckCodeVerifier(verifier, challenge string) error {
+	if !ValidCodeVerifier(verifier) || !VerifyCodeChallenge(verifier, challenge) {
+		return OAuthError{Code: ErrInvalidGrant.Code, Message: "The code verifier does not match the code challenge"}
+	}
+	return nil
+}
+
+// VerifyCodeChallenge reports whether the PKCE verifier hashes to the S256 challenge
+func VerifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// ValidCodeVerifier reports whether the verifier has the length and
+// characters RFC 7636 section 4.1 requires
+func ValidCodeVerifier(verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, r := range verifier {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '.' || r == '_' || r == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParseScope splits a space-delimited OAuth scope parameter
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// AppendQuery adds params to the query of uri, keeping any it already has
+func AppendQuery(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// GenerateSecret returns a new random secret of size bytes and its hash
+func GenerateSecret(size int) (string, [sha256.Size]byte, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", [sha256.Size]byte{}, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+	return secret, HashSecret(secret), nil
+}
+
+// HashSecret returns the hash client secrets and codes are stored and
+// looked up by
+func HashSecret(secret string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(secret))
+}
+
+// CodeTokenContext adds the claims of tokens a client obtains on behalf of a
+// user to the tokens issued with ctx
+func CodeTokenContext(ctx context.Context, client Client) context.Context {
+	return token.WithExtraClaims(ctx, map[string]interface{}{"client_id": client.ID})
+}
+
+// ClientTokenContext adds the claims of tokens a client obtains for itself
+// to the tokens issued with ctx
+func ClientTokenContext(ctx context.Context, client Client) context.Context {
+	return token.WithExtraClaims(ctx, map[string]interface{}{
+		"principal_type": "oauth_client",
+		"client_id":      client.ID,
+	})
+}
+
+// NewAuditEntry describes a client lifecycle or grant event, attributed to
+// the calling user or, when there is none, to subject
+func NewAuditEntry(ctx context.Context, action, clientID, subject string, err error, details map[string]interface{}) audit.AuditEntry {
+	auditCtx := audit.ExtractAuditContext(ctx)
+	actor := auditCtx.CurrentUserID
+	if actor == "" {
+		actor = subject
+	}
+
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        actor,
+		Action:        action,
+		Resource:      "oauth_client",
+		ResourceID:    clientID,
+		Details:       details,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+	if err != nil {
+		entry.SetError(err)
+	} else {
+		entry.SetSuccess()
+	}
+	return entry
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthError represents domain-specific OAuth errors. Codes are the error
+// values of RFC 6749, which clients match on.
+type OAuthError struct {
+	Code    string `json:"error"`
+	Message string `json:"error_description"`
+}
+
+func (e OAuthError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code so detailed messages still match the sentinels
+func (e OAuthError) Is(target error) bool {
+	t, ok := target.(OAuthError)
+	return ok && t.Code == e.Code
+}
+
+// Common OAuth error codes
+var (
+	ErrInvalidRequest          = OAuthError{Code: "invalid_request", Message: "The request is missing a parameter or is malformed"}
+	ErrInvalidClient           = OAuthError{Code: "invalid_client", Message: "Client authentication failed"}
+	ErrInvalidGrant            = OAuthError{Code: "invalid_grant", Message: "The authorization code is invalid, expired or already used"}
+	ErrUnauthorizedClient      = OAuthError{Code: "unauthorized_client", Message: "The client is not allowed to use this grant type"}
+	ErrUnsupportedGrantType    = OAuthError{Code: "unsupported_grant_type", Message: "The grant type is not supported"}
+	ErrUnsupportedResponseType = OAuthError{Code: "unsupported_response_type", Message: "Only the code response type is supported"}
+	ErrInvalidScope            = OAuthError{Code: "invalid_scope", Message: "The requested scope exceeds the client's scopes"}
+	ErrInvalidRedirectURI      = OAuthError{Code: "invalid_redirect_uri", Message: "The redirect URI is not registered for the client"}
+	ErrClientNotFound          = OAuthError{Code: "client_not_found", Message: "OAuth client not found"}
+	ErrInvalidClientMetadata   = OAuthError{Code: "invalid_client_metadata", Message: "The client registration is invalid"}
+)
@@ -0,0 +1,317 @@
+package oauthservertest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// NewServiceFunc creates the OAuth authorization server under test
+type NewServiceFunc func(tokenService token.Service, auditService audit.Service) oauthserver.Service
+
+// RunServiceConformance checks that an oauthserver.Service behaves like the
+// contract the REST API relies on. newService is called once per subtest;
+// the services may share a database, as every subtest registers its own
+// clients. Stores run the same suite so they stay interchangeable.
+func RunServiceConformance(t *testing.T, newService NewServiceFunc) {
+	t.Helper()
+
+	t.Run("RegisterClient", func(t *testing.T) { testRegisterClient(t, newService) })
+	t.Run("Authorize", func(t *testing.T) { testAuthorize(t, newService) })
+	t.Run("Given valid verifier, When exchanging, Then issues scoped token for user", func(t *testing.T) { testExchangeCode(t, newService) })
+	t.Run("Given wrong verifier, When exchanging, Then returns invalid grant", func(t *testing.T) { testWrongVerifier(t, newService) })
+	t.Run("Given exchanged code, When replayed, Then fails and revokes issued token", func(t *testing.T) { testReplayedCode(t, newService) })
+	t.Run("Given confidential client, When requesting token, Then issues token for client", func(t *testing.T) { testClientCredentials(t, newService) })
+	t.Run("Given issued client token, When deleting, Then revokes token and forgets client", func(t *testing.T) { testDeleteClient(t, newService) })
+	t.Run("Given unsupported grant, When requesting, Then returns unsupported grant type", func(t *testing.T) { testUnsupportedGrant(t, newService) })
+}
+
+const (
+	redirectURI = "https://app.example.com/callback"
+	verifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk-long-enough"
+)
+
+func newTestService(t *testing.T, newService NewServiceFunc) (oauthserver.Service, token.Service) {
+	t.Helper()
+	tokenService := testkit.NewTokenService(t)
+
+	auditService := &auditMock.MockAuditService{}
+	auditService.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	return newService(tokenService, auditService), tokenService
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func registerSPA(t *testing.T, service oauthserver.Service) *oauthserver.Client {
+	t.Helper()
+	credentials, err := service.RegisterClient(context.Background(), oauthserver.ClientRequest{
+		Name:         "dashboard",
+		Type:         oauthserver.ClientTypePublic,
+		RedirectURIs: []string{redirectURI},
+		GrantTypes:   []string{oauthserver.GrantTypeAuthorizationCode},
+		Scopes:       []string{"users:read", "users:write"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, credentials.Secret)
+	return &credentials.Client
+}
+
+func authorize(t *testing.T, service oauthserver.Service, client *oauthserver.Client, scopes []string) *oauthserver.AuthorizationCode {
+	t.Helper()
+	code, err := service.Authorize(context.Background(), oauthserver.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ID,
+		RedirectURI:         redirectURI,
+		Scopes:              scopes,
+		State:               "xyz",
+		CodeChallenge:       challenge(verifier),
+		CodeChallengeMethod: oauthserver.CodeChallengeMethodS256,
+		UserID:              "user-1",
+	})
+	require.NoError(t, err)
+	return code
+}
+
+func exchange(service oauthserver.Service, client *oauthserver.Client, code, codeVerifier string) (*oauthserver.TokenResponse, error) {
+	return service.Token(context.Background(), oauthserver.TokenRequest{
+		GrantType:    oauthserver.GrantTypeAuthorizationCode,
+		ClientID:     client.ID,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: codeVerifier,
+	})
+}
+
+func testRegisterClient(t *testing.T, newService NewServiceFunc) {
+	tests := []struct {
+		name         string
+		req          oauthserver.ClientRequest
+		expectedCode string
+	}{
+		{
+			name:         "Given missing name, When registering, Then returns invalid metadata",
+			req:          oauthserver.ClientRequest{Type: oauthserver.ClientTypePublic, GrantTypes: []string{oauthserver.GrantTypeAuthorizationCode}, RedirectURIs: []string{redirectURI}},
+			expectedCode: oauthserver.ErrInvalidClientMetadata.Code,
+		},
+		{
+			name:         "Given public client with client credentials, When registering, Then returns invalid metadata",
+			req:          oauthserver.ClientRequest{Name: "spa", Type: oauthserver.ClientTypePublic, GrantTypes: []string{oauthserver.GrantTypeClientCredentials}},
+			expectedCode: oauthserver.ErrInvalidClientMetadata.Code,
+		},
+		{
+			name:         "Given authorization code without redirect URI, When registering, Then returns invalid metadata",
+			req:          oauthserver.ClientRequest{Name: "spa", Type: oauthserver.ClientTypePublic, GrantTypes: []string{oauthserver.GrantTypeAuthorizationCode}},
+			expectedCode: oauthserver.ErrInvalidClientMetadata.Code,
+		},
+		{
+			name:         "Given relative redirect URI, When registering, Then returns invalid redirect URI",
+			req:          oauthserver.ClientRequest{Name: "spa", Type: oauthserver.ClientTypePublic, GrantTypes: []string{oauthserver.GrantTypeAuthorizationCode}, RedirectURIs: []string{"/callback"}},
+			expectedCode: oauthserver.ErrInvalidRedirectURI.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _ := newTestService(t, newService)
+
+			// Act
+			_, err := service.RegisterClient(context.Background(), tt.req)
+
+			// Assert
+			var oauthErr oauthserver.OAuthError
+			require.ErrorAs(t, err, &oauthErr)
+			assert.Equal(t, tt.expectedCode, oauthErr.Code)
+		})
+	}
+}
+
+func testExchangeCode(t *testing.T, newService NewServiceFunc) {
+	// Arrange
+	service, tokenService := newTestService(t, newService)
+	client := registerSPA(t, service)
+	code := authorize(t, service, client, []string{"users:read"})
+
+	// Act
+	response, err := exchange(service, client, code.Code, verifier)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, "users:read", response.Scope)
+	assert.Positive(t, response.ExpiresIn)
+	assert.True(t, strings.HasPrefix(code.RedirectURL(), redirectURI+"?code="))
+	assert.Contains(t, code.RedirectURL(), "state=xyz")
+
+	claims, err := tokenService.ValidateAPIToken(context.Background(), response.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, []string{"users:read"}, claims.Scopes)
+	assert.Equal(t, client.ID, claims.Custom["client_id"])
+}
+
+func testWrongVerifier(t *testing.T, newService NewServiceFunc) {
+	// Arrange
+	service, _ := newTestService(t, newService)
+	client := registerSPA(t, service)
+	code := authorize(t, service, client, nil)
+
+	// Act
+	_, err := exchange(service, client, code.Code, strings.Repeat("a", 43))
+
+	// Assert
+	assert.ErrorIs(t, err, oauthserver.ErrInvalidGrant)
+}
+
+func testReplayedCode(t *testing.T, newService NewServiceFunc) {
+	// Arrange
+	service, tokenService := newTestService(t, newService)
+	client := registerSPA(t, service)
+	code := authorize(t, service, client, nil)
+	first, err := exchange(service, client, code.Code, verifier)
+	require.NoError(t, err)
+
+	// Act
+	_, replayErr := exchange(service, client, code.Code, verifier)
+
+	// Assert
+	assert.ErrorIs(t, replayErr, oauthserver.ErrInvalidGrant)
+	_, err = tokenService.ValidateToken(context.Background(), first.AccessToken)
+	assert.Error(t, err)
+}
+
+func testAuthorize(t *testing.T, newService NewServiceFunc) {
+	tests := []struct {
+		name        string
+		modify      func(*oauthserver.AuthorizeRequest)
+		expectedErr error
+	}{
+		{
+			name:        "Given unregistered redirect URI, When authorizing, Then returns invalid redirect URI",
+			modify:      func(r *oauthserver.AuthorizeRequest) { r.RedirectURI = "https://evil.example.com/callback" },
+			expectedErr: oauthserver.ErrInvalidRedirectURI,
+		},
+		{
+			name:        "Given plain PKCE method, When authorizing, Then returns invalid request",
+			modify:      func(r *oauthserver.AuthorizeRequest) { r.CodeChallengeMethod = "plain" },
+			expectedErr: oauthserver.ErrInvalidRequest,
+		},
+		{
+			name:        "Given scope not registered, When authorizing, Then returns invalid scope",
+			modify:      func(r *oauthserver.AuthorizeRequest) { r.Scopes = []string{"admin"} },
+			expectedErr: oauthserver.ErrInvalidScope,
+		},
+		{
+			name:        "Given token response type, When authorizing, Then returns unsupported response type",
+			modify:      func(r *oauthserver.AuthorizeRequest) { r.ResponseType = "token" },
+			expectedErr: oauthserver.ErrUnsupportedResponseType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _ := newTestService(t, newService)
+			client := registerSPA(t, service)
+			req := oauthserver.AuthorizeRequest{
+				ResponseType:        "code",
+				ClientID:            client.ID,
+				RedirectURI:         redirectURI,
+				CodeChallenge:       challenge(verifier),
+				CodeChallengeMethod: oauthserver.CodeChallengeMethodS256,
+				UserID:              "user-1",
+			}
+			tt.modify(&req)
+
+			// Act
+			_, err := service.Authorize(context.Background(), req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func testClientCredentials(t *testing.T, newService NewServiceFunc) {
+	// Arrange
+	service, tokenService := newTestService(t, newService)
+	credentials, err := service.RegisterClient(context.Background(), oauthserver.ClientRequest{
+		Name:       "billing-sync",
+		Type:       oauthserver.ClientTypeConfidential,
+		GrantTypes: []string{oauthserver.GrantTypeClientCredentials},
+		Scopes:     []string{"users:read"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, credentials.Secret)
+
+	// Act
+	response, err := service.Token(context.Background(), oauthserver.TokenRequest{
+		GrantType:    oauthserver.GrantTypeClientCredentials,
+		ClientID:     credentials.Client.ID,
+		ClientSecret: credentials.Secret,
+	})
+	_, wrongSecretErr := service.Token(context.Background(), oauthserver.TokenRequest{
+		GrantType:    oauthserver.GrantTypeClientCredentials,
+		ClientID:     credentials.Client.ID,
+		ClientSecret: "wrong",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	claims, err := tokenService.ValidateAPIToken(context.Background(), response.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, credentials.Client.Principal(), claims.UserID)
+	assert.Equal(t, []string{"users:read"}, claims.Scopes)
+	assert.ErrorIs(t, wrongSecretErr, oauthserver.ErrInvalidClient)
+}
+
+func testDeleteClient(t *testing.T, newService NewServiceFunc) {
+	// Arrange
+	service, tokenService := newTestService(t, newService)
+	credentials, err := service.RegisterClient(context.Background(), oauthserver.ClientRequest{
+		Name:       "billing-sync",
+		Type:       oauthserver.ClientTypeConfidential,
+		GrantTypes: []string{oauthserver.GrantTypeClientCredentials},
+	})
+	require.NoError(t, err)
+	response, err := service.Token(context.Background(), oauthserver.TokenRequest{
+		GrantType:    oauthserver.GrantTypeClientCredentials,
+		ClientID:     credentials.Client.ID,
+		ClientSecret: credentials.Secret,
+	})
+	require.NoError(t, err)
+
+	// Act
+	err = service.DeleteClient(context.Background(), credentials.Client.ID)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = tokenService.ValidateToken(context.Background(), response.AccessToken)
+	assert.Error(t, err)
+	_, err = service.GetClient(context.Background(), credentials.Client.ID)
+	assert.ErrorIs(t, err, oauthserver.ErrClientNotFound)
+}
+
+func testUnsupportedGrant(t *testing.T, newService NewServiceFunc) {
+	service, _ := newTestService(t, newService)
+
+	_, err := service.Token(context.Background(), oauthserver.TokenRequest{GrantType: "password"})
+
+	assert.ErrorIs(t, err, oauthserver.ErrUnsupportedGrantType)
+}
@@ -0,0 +1,383 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// clientColumns are the oauth_clients columns scanClient reads, in order
+const clientColumns = `id, name, type, redirect_uris, grant_types, scopes, created_by, created_at`
+
+// service implements oauthserver.Service on the oauth_clients and
+// oauth_authorization_codes tables, so clients and codes survive restarts
+// and a code issued by one instance can be exchanged at another
+type service struct {
+	pool         *pgxpool.Pool
+	tokenService token.Service
+	auditService audit.Service
+}
+
+// pendingCode is a stored authorization code
+type pendingCode struct {
+	clientID      string
+	userID        string
+	redirectURI   string
+	scopes        []string
+	codeChallenge string
+	expiresAt     time.Time
+	redeemed      bool
+}
+
+// NewService creates a Postgres-backed OAuth authorization server
+func NewService(pool *pgxpool.Pool, tokenService token.Service, auditService audit.Service) oauthserver.Service {
+	return &service{
+		pool:         pool,
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// RegisterClient registers a client; confidential clients get a secret
+func (s *service) RegisterClient(ctx context.Context, req oauthserver.ClientRequest) (*oauthserver.ClientCredentials, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := req.NewClient(uuid.New().String(), time.Now())
+
+	var secretHash []byte
+	credentials := &oauthserver.ClientCredentials{}
+	if client.Type == oauthserver.ClientTypeConfidential {
+		secret, hash, err := oauthserver.GenerateSecret(oauthserver.SecretSize)
+		if err != nil {
+			return nil, err
+		}
+		secretHash = hash[:]
+		credentials.Secret = secret
+	}
+
+	stored, err := scanClient(s.pool.QueryRow(ctx, `
+		INSERT INTO oauth_clients (id, name, type, redirect_uris, grant_types, scopes, secret_hash, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+clientColumns,
+		client.ID, client.Name, client.Type, nonNil(client.RedirectURIs), client.GrantTypes, nonNil(client.Scopes),
+		secretHash, client.CreatedBy, client.CreatedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register OAuth client: %w", err)
+	}
+	credentials.Client = *stored
+
+	s.logAudit(ctx, "oauth_client.register", client.ID, "", nil, map[string]interface{}{
+		"type":        client.Type,
+		"grant_types": client.GrantTypes,
+		"scopes":      client.Scopes,
+	})
+
+	return credentials, nil
+}
+
+// GetClient returns a client by ID
+func (s *service) GetClient(ctx context.Context, id string) (*oauthserver.Client, error) {
+	client, err := scanClient(s.pool.QueryRow(ctx, `SELECT `+clientColumns+` FROM oauth_clients WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, oauthserver.ErrClientNotFound
+	}
+	return client, err
+}
+
+// ListClients returns every client ordered by registration time
+func (s *service) ListClients(ctx context.Context) ([]oauthserver.Client, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+clientColumns+` FROM oauth_clients ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []oauthserver.Client{}
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, *client)
+	}
+	return clients, rows.Err()
+}
+
+// DeleteClient removes the client and, by cascade, its pending codes, and
+// revokes the tokens it obtained for itself. Tokens issued to it on behalf
+// of users stay valid until they expire or the users log out everywhere.
+func (s *service) DeleteClient(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM oauth_clients WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return oauthserver.ErrClientNotFound
+	}
+
+	client := oauthserver.Client{ID: id}
+	err = s.tokenService.RevokeAllTokensForUser(ctx, client.Principal())
+	s.logAudit(ctx, "oauth_client.delete", id, "", err, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke OAuth client tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Authorize checks the request against the client's registration and issues
+// a code bound to its redirect URI, scopes and PKCE challenge
+func (s *service) Authorize(ctx context.Context, req oauthserver.AuthorizeRequest) (*oauthserver.AuthorizationCode, error) {
+	client, err := s.GetClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, oauthserver.ErrInvalidClient
+	}
+
+	scopes, err := client.AuthorizeScopes(req)
+	if err != nil {
+		return nil, err
+	}
+
+	code, codeHash, err := oauthserver.GenerateSecret(oauthserver.CodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(oauthserver.CodeTTL)
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM oauth_authorization_codes WHERE expires_at < $1`, now); err != nil {
+		log.Printf("Failed to remove expired authorization codes: %v", err)
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		codeHash[:], client.ID, req.UserID, req.RedirectURI, scopes, req.CodeChallenge, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	s.logAudit(ctx, "oauth.authorize", client.ID, req.UserID, nil, map[string]interface{}{
+		"scopes": scopes,
+	})
+
+	return &oauthserver.AuthorizationCode{
+		Code:        code,
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// Token dispatches the request to its grant
+func (s *service) Token(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	switch req.GrantType {
+	case oauthserver.GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, req)
+	case oauthserver.GrantTypeClientCredentials:
+		return s.clientCredentials(ctx, req)
+	case "":
+		return nil, oauthserver.OAuthError{Code: oauthserver.ErrInvalidRequest.Code, Message: "grant_type is required"}
+	default:
+		return nil, oauthserver.ErrUnsupportedGrantType
+	}
+}
+
+// exchangeCode redeems an authorization code once for a token scoped to what
+// the user granted. A code presented again revokes the token it was
+// exchanged for, as it may have been stolen.
+func (s *service) exchangeCode(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(oauthserver.GrantTypeAuthorizationCode) {
+		return nil, oauthserver.ErrUnauthorizedClient
+	}
+
+	codeHash := oauthserver.HashSecret(req.Code)
+	var pending pendingCode
+	err = s.pool.QueryRow(ctx, `
+		SELECT client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, redeemed_at IS NOT NULL
+		FROM oauth_authorization_codes
+		WHERE code_hash = $1`, codeHash[:]).Scan(
+		&pending.clientID, &pending.userID, &pending.redirectURI, &pending.scopes, &pending.codeChallenge,
+		&pending.expiresAt, &pending.redeemed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, oauthserver.ErrInvalidGrant
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if pending.redeemed {
+		return nil, s.replayed(ctx, client.ID, codeHash[:], pending.userID)
+	}
+	if pending.clientID != client.ID || time.Now().After(pending.expiresAt) || pending.redirectURI != req.RedirectURI {
+		return nil, oauthserver.ErrInvalidGrant
+	}
+	if err := oauthserver.CheckCodeVerifier(req.CodeVerifier, pending.codeChallenge); err != nil {
+		return nil, err
+	}
+
+	// Claim the code before issuing so concurrent exchanges, on this or any
+	// other instance, cannot both succeed
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE oauth_authorization_codes SET redeemed_at = $2
+		WHERE code_hash = $1 AND redeemed_at IS NULL`, codeHash[:], time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, s.replayed(ctx, client.ID, codeHash[:], pending.userID)
+	}
+
+	ctx = oauthserver.CodeTokenContext(ctx, *client)
+	response, apiToken, err := s.issueToken(ctx, pending.userID, pending.scopes)
+
+	if err != nil {
+		_, deleteErr := s.pool.Exec(ctx, `DELETE FROM oauth_authorization_codes WHERE code_hash = $1`, codeHash[:])
+		if deleteErr != nil {
+			log.Printf("Failed to remove authorization code after failed exchange: %v", deleteErr)
+		}
+	} else {
+		_, updateErr := s.pool.Exec(ctx, `
+			UPDATE oauth_authorization_codes SET issued_token = $2 WHERE code_hash = $1`, codeHash[:], apiToken)
+		if updateErr != nil {
+			log.Printf("Failed to record token of exchanged authorization code: %v", updateErr)
+		}
+	}
+
+	s.logAudit(ctx, "oauth.token", client.ID, pending.userID, err, map[string]interface{}{
+		"grant_type": oauthserver.GrantTypeAuthorizationCode,
+		"scopes":     pending.scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// clientCredentials issues a confidential client a token for itself
+func (s *service) clientCredentials(ctx context.Context, req oauthserver.TokenRequest) (*oauthserver.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Type != oauthserver.ClientTypeConfidential || !client.AllowsGrant(oauthserver.GrantTypeClientCredentials) {
+		return nil, oauthserver.ErrUnauthorizedClient
+	}
+
+	scopes, err := client.GrantedScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oauthserver.ClientTokenContext(ctx, *client)
+	response, _, err := s.issueToken(ctx, client.Principal(), scopes)
+	s.logAudit(ctx, "oauth.token", client.ID, client.Principal(), err, map[string]interface{}{
+		"grant_type": oauthserver.GrantTypeClientCredentials,
+		"scopes":     scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Helper methods
+
+// replayed forgets a code presented after its exchange and revokes the
+// token it was exchanged for
+func (s *service) replayed(ctx context.Context, clientID string, codeHash []byte, userID string) error {
+	var issuedToken *string
+	err := s.pool.QueryRow(ctx, `
+		DELETE FROM oauth_authorization_codes WHERE code_hash = $1
+		RETURNING issued_token`, codeHash).Scan(&issuedToken)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Failed to remove replayed authorization code: %v", err)
+	}
+
+	if issuedToken != nil && *issuedToken != "" {
+		if err := s.tokenService.RevokeToken(ctx, *issuedToken); err != nil {
+			log.Printf("Failed to revoke token of replayed authorization code: %v", err)
+		}
+	}
+	s.logAudit(ctx, "oauth.code_replayed", clientID, userID, oauthserver.ErrInvalidGrant, nil)
+	return oauthserver.ErrInvalidGrant
+}
+
+// issueToken issues an API token for subject limited to scopes
+func (s *service) issueToken(ctx context.Context, subject string, scopes []string) (*oauthserver.TokenResponse, string, error) {
+	apiToken, err := s.tokenService.GenerateAPIToken(ctx, subject, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return oauthserver.NewTokenResponse(apiToken, scopes), apiToken.Token, nil
+}
+
+// authenticateClient returns the client when the credentials match
+func (s *service) authenticateClient(ctx context.Context, id, secret string) (*oauthserver.Client, error) {
+	var secretHash []byte
+	client, err := scanClient(s.pool.QueryRow(ctx, `
+		SELECT `+clientColumns+`, secret_hash
+		FROM oauth_clients
+		WHERE id = $1`, id), &secretHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, oauthserver.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.VerifySecret(secret, secretHash) {
+		return nil, oauthserver.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// logAudit records a client lifecycle or grant event
+func (s *service) logAudit(ctx context.Context, action, clientID, subject string, err error, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := oauthserver.NewAuditEntry(ctx, action, clientID, subject, err, details)
+	if logErr := s.auditService.Log(ctx, entry); logErr != nil {
+		log.Printf("Failed to audit %s for OAuth client %s: %v", action, clientID, logErr)
+	}
+}
+
+// scanClient reads the clientColumns of a row, followed by extra columns
+func scanClient(row pgx.Row, extra ...any) (*oauthserver.Client, error) {
+	var client oauthserver.Client
+	dest := append([]any{
+		&client.ID, &client.Name, &client.Type, &client.RedirectURIs, &client.GrantTypes, &client.Scopes,
+		&client.CreatedBy, &client.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// nonNil returns values, or an empty slice for the NOT NULL array columns
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
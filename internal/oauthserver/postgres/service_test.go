@@ -0,0 +1,85 @@
+package postgres_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/oauthservertest"
+	"github.com/gentra/decorator-arch-go/internal/oauthserver/postgres"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// testDatabaseEnv names a migrated Postgres database the tests may write to
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	databaseURL := os.Getenv(testDatabaseEnv)
+	if databaseURL == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestPostgresService_Conformance(t *testing.T) {
+	pool := newTestPool(t)
+
+	oauthservertest.RunServiceConformance(t, func(tokenService token.Service, auditService audit.Service) oauthserver.Service {
+		return postgres.NewService(pool, tokenService, auditService)
+	})
+}
+
+func TestPostgresService_GivenCodeIssuedByOneInstance_WhenExchangedAtAnother_ThenIssuesToken(t *testing.T) {
+	// Arrange
+	pool := newTestPool(t)
+	tokenService := testkit.NewTokenService(t)
+	first := postgres.NewService(pool, tokenService, nil)
+	second := postgres.NewService(pool, tokenService, nil)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk-long-enough"
+	sum := sha256.Sum256([]byte(verifier))
+	credentials, err := first.RegisterClient(context.Background(), oauthserver.ClientRequest{
+		Name:         "dashboard",
+		Type:         oauthserver.ClientTypePublic,
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes:   []string{oauthserver.GrantTypeAuthorizationCode},
+		Scopes:       []string{"users:read"},
+	})
+	require.NoError(t, err)
+	code, err := first.Authorize(context.Background(), oauthserver.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            credentials.Client.ID,
+		RedirectURI:         "https://app.example.com/callback",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: oauthserver.CodeChallengeMethodS256,
+		UserID:              "user-1",
+	})
+	require.NoError(t, err)
+
+	// Act
+	response, err := second.Token(context.Background(), oauthserver.TokenRequest{
+		GrantType:    oauthserver.GrantTypeAuthorizationCode,
+		ClientID:     credentials.Client.ID,
+		Code:         code.Code,
+		RedirectURI:  "https://app.example.com/callback",
+		CodeVerifier: verifier,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "users:read", response.Scope)
+}
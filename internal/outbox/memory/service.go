@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+)
+
+// DefaultCapacity is the number of messages kept when no capacity is given
+const DefaultCapacity = 1000
+
+// service implements outbox.Service in memory, keeping the most recent messages
+type service struct {
+	mu       sync.RWMutex
+	messages []outbox.Message
+	capacity int
+}
+
+// NewService creates an in-memory outbox that drops the oldest messages once
+// capacity is reached
+func NewService(capacity int) outbox.Service {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &service{capacity: capacity}
+}
+
+// Capture stores a message, evicting the oldest one when full
+func (s *service) Capture(ctx context.Context, message outbox.Message) (*outbox.Message, error) {
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+	if message.CapturedAt.IsZero() {
+		message.CapturedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.messages) >= s.capacity {
+		s.messages = s.messages[1:]
+	}
+	s.messages = append(s.messages, message)
+
+	return &message, nil
+}
+
+// List returns matching messages, newest first
+func (s *service) List(ctx context.Context, filter outbox.Filter) ([]outbox.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []outbox.Message{}
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if !filter.Matches(s.messages[i]) {
+			continue
+		}
+		result = append(result, s.messages[i])
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Get returns a message by ID
+func (s *service) Get(ctx context.Context, id string) (*outbox.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, message := range s.messages {
+		if message.ID == id {
+			found := message
+			return &found, nil
+		}
+	}
+	return nil, outbox.ErrMessageNotFound
+}
+
+// Clear removes every message
+func (s *service) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = nil
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	"github.com/gentra/decorator-arch-go/internal/outbox/memory"
+)
+
+func TestOutbox_GivenFullOutbox_WhenCapturing_ThenEvictsOldestAndListsNewestFirst(t *testing.T) {
+	box := memory.NewService(2)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		_, err := box.Capture(ctx, outbox.Message{Channel: outbox.ChannelSMS, To: fmt.Sprintf("+1555000%d", i)})
+		require.NoError(t, err)
+	}
+
+	messages, err := box.List(ctx, outbox.Filter{})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "+15550003", messages[0].To)
+	assert.Equal(t, "+15550002", messages[1].To)
+}
+
+func TestOutbox_GivenMixedMessages_WhenListingWithFilter_ThenReturnsMatches(t *testing.T) {
+	box := memory.NewService(10)
+	ctx := context.Background()
+	_, _ = box.Capture(ctx, outbox.Message{Channel: outbox.ChannelEmail, To: "a@example.com"})
+	_, _ = box.Capture(ctx, outbox.Message{Channel: outbox.ChannelEmail, To: "b@example.com"})
+	_, _ = box.Capture(ctx, outbox.Message{Channel: outbox.ChannelPush, To: "a@example.com"})
+
+	messages, err := box.List(ctx, outbox.Filter{Channel: outbox.ChannelEmail, To: "a@example.com"})
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, outbox.ChannelEmail, messages[0].Channel)
+}
+
+func TestOutbox_GivenCapturedMessage_WhenGettingAndClearing_ThenBehavesAsExpected(t *testing.T) {
+	box := memory.NewService(10)
+	ctx := context.Background()
+	captured, err := box.Capture(ctx, outbox.Message{Channel: outbox.ChannelEmail, To: "a@example.com"})
+	require.NoError(t, err)
+	require.NotEmpty(t, captured.ID)
+
+	found, err := box.Get(ctx, captured.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", found.To)
+
+	require.NoError(t, box.Clear(ctx))
+	_, err = box.Get(ctx, captured.ID)
+	assert.ErrorIs(t, err, outbox.ErrMessageNotFound)
+}
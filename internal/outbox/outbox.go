@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the outbox domain interface - the ONLY interface in this domain.
+// An outbox captures rendered notifications that were intercepted instead of
+// being delivered, so they can be inspected from an admin viewer.
+type Service interface {
+	// Capture stores a rendered message and returns it with its ID assigned
+	Capture(ctx context.Context, message Message) (*Message, error)
+
+	// List returns captured messages, newest first
+	List(ctx context.Context, filter Filter) ([]Message, error)
+
+	// Get returns a single captured message
+	Get(ctx context.Context, id string) (*Message, error)
+
+	// Clear removes every captured message
+	Clear(ctx context.Context) error
+}
+
+// Domain types and data structures
+
+// Message represents a rendered notification captured instead of being sent
+type Message struct {
+	ID            string                 `json:"id"`
+	Channel       Channel                `json:"channel"`
+	Kind          string                 `json:"kind"`
+	To            string                 `json:"to"`
+	From          string                 `json:"from,omitempty"`
+	Subject       string                 `json:"subject,omitempty"`
+	Body          string                 `json:"body"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	CapturedAt    time.Time              `json:"captured_at"`
+}
+
+// Filter narrows the captured messages returned by List
+type Filter struct {
+	Channel Channel `json:"channel,omitempty"`
+	To      string  `json:"to,omitempty"`
+	Limit   int     `json:"limit,omitempty"`
+}
+
+// Channel identifies the delivery channel a message was meant for
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelPush  Channel = "push"
+	ChannelSMS   Channel = "sms"
+)
+
+// Matches reports whether the message satisfies the filter
+func (f Filter) Matches(message Message) bool {
+	if f.Channel != "" && f.Channel != message.Channel {
+		return false
+	}
+	return f.To == "" || f.To == message.To
+}
+
+// OutboxError represents outbox domain errors
+type OutboxError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e OutboxError) Error() string {
+	return e.Message
+}
+
+// Common outbox errors
+var (
+	ErrMessageNotFound = OutboxError{Code: "MESSAGE_NOT_FOUND", Message: "Outbox message not found"}
+)
@@ -0,0 +1,74 @@
+// Package pgtx carries a Postgres transaction in a context, so stores of
+// different domains sharing one database commit their writes together.
+package pgtx
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is satisfied by both the pool and a transaction
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type contextKey struct{}
+
+// holder is the transaction stored in a context. It is marked done once the
+// transaction ends, so work detached from the request, which keeps the
+// context values, falls back to the pool instead of a closed transaction.
+type holder struct {
+	tx   pgx.Tx
+	done atomic.Bool
+}
+
+// Run calls fn with a context carrying a new transaction, committing it when
+// fn succeeds and rolling it back otherwise. Inside a transaction carried by
+// ctx the new one is a savepoint of it.
+func Run(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) (err error) {
+	tx, err := Use(ctx, pool).Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	h := &holder{tx: tx}
+	defer func() {
+		h.done.Store(true)
+		if r := recover(); r != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(r)
+		}
+		if err != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, contextKey{}, h)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// FromContext returns the transaction carried by the context, if it is still open
+func FromContext(ctx context.Context) (pgx.Tx, bool) {
+	h, ok := ctx.Value(contextKey{}).(*holder)
+	if !ok || h.done.Load() {
+		return nil, false
+	}
+	return h.tx, true
+}
+
+// Use returns the transaction carried by the context, or the pool outside one
+func Use(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return pool
+}
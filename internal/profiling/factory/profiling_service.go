@@ -0,0 +1,88 @@
+package factory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/profiling/memory"
+	"github.com/gentra/decorator-arch-go/internal/profiling/postgres"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// Config contains all configuration for building the progressive profiling service
+type Config struct {
+	// Provider configuration
+	Provider string // "memory", "postgres"
+
+	// Pool is the connection pool of the postgres provider
+	Pool *pgxpool.Pool
+
+	// Prompt definitions in onboarding order
+	Prompts     []profiling.Prompt
+	RemindAfter time.Duration
+
+	// Domain services; submitted values are validated with it, including any
+	// custom rules registered on it
+	ValidationService validation.Service
+}
+
+// ProfilingServiceFactory creates and assembles the progressive profiling service
+type ProfilingServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new progressive profiling service factory with the given configuration
+func NewFactory(config Config) *ProfilingServiceFactory {
+	return &ProfilingServiceFactory{
+		config: config,
+	}
+}
+
+// Build assembles and returns the progressive profiling service based on configuration
+func (f *ProfilingServiceFactory) Build() (profiling.Service, error) {
+	if f.config.ValidationService == nil {
+		return nil, fmt.Errorf("validation service is required")
+	}
+
+	switch f.config.Provider {
+	case "memory":
+		return f.buildMemoryService()
+	case "postgres":
+		return f.buildPostgresService()
+	default:
+		// Default to memory provider
+		return f.buildMemoryService()
+	}
+}
+
+// buildMemoryService creates an in-memory progressive profiling service
+func (f *ProfilingServiceFactory) buildMemoryService() (profiling.Service, error) {
+	return memory.NewService(f.config.ValidationService, memory.Config{
+		Prompts:     f.config.Prompts,
+		RemindAfter: f.config.RemindAfter,
+	})
+}
+
+// buildPostgresService creates a progressive profiling service stored in Postgres
+func (f *ProfilingServiceFactory) buildPostgresService() (profiling.Service, error) {
+	if f.config.Pool == nil {
+		return nil, fmt.Errorf("postgres connection pool is required")
+	}
+	return postgres.NewService(f.config.Pool, f.config.ValidationService, postgres.Config{
+		Prompts:     f.config.Prompts,
+		RemindAfter: f.config.RemindAfter,
+	})
+}
+
+// DefaultConfig returns a sensible default configuration for the progressive profiling service
+func DefaultConfig(validationService validation.Service) Config {
+	return Config{
+		Provider:          "memory",
+		Prompts:           profiling.DefaultPrompts(),
+		RemindAfter:       profiling.DefaultRemindAfter,
+		ValidationService: validationService,
+	}
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// service implements profiling.Service interface using in-memory storage
+type service struct {
+	prompts     []profiling.Prompt
+	byField     map[string]profiling.Prompt
+	remindAfter time.Duration
+	validation  validation.Service
+
+	users map[string]map[string]profiling.FieldProgress
+	mu    sync.RWMutex
+}
+
+// Config contains the prompt definitions and reminder settings
+type Config struct {
+	Prompts     []profiling.Prompt
+	RemindAfter time.Duration
+}
+
+// DefaultConfig returns the default prompts and reminder delay
+func DefaultConfig() Config {
+	return Config{
+		Prompts:     profiling.DefaultPrompts(),
+		RemindAfter: profiling.DefaultRemindAfter,
+	}
+}
+
+// NewService creates a new in-memory progressive profiling service.
+// Submitted values are checked with the validation service, so prompts may
+// reference custom rules registered on it.
+func NewService(validationService validation.Service, config Config) (profiling.Service, error) {
+	if validationService == nil {
+		return nil, fmt.Errorf("validation service is required")
+	}
+
+	byField, err := profiling.IndexPrompts(config.Prompts)
+	if err != nil {
+		return nil, err
+	}
+
+	remindAfter := config.RemindAfter
+	if remindAfter <= 0 {
+		remindAfter = profiling.DefaultRemindAfter
+	}
+
+	return &service{
+		prompts:     append([]profiling.Prompt(nil), config.Prompts...),
+		byField:     byField,
+		remindAfter: remindAfter,
+		validation:  validationService,
+		users:       make(map[string]map[string]profiling.FieldProgress),
+	}, nil
+}
+
+// Prompts returns every prompt definition in onboarding order
+func (s *service) Prompts(ctx context.Context) ([]profiling.Prompt, error) {
+	return append([]profiling.Prompt(nil), s.prompts...), nil
+}
+
+// Progress returns the user's onboarding state, per-field status and the prompts due now
+func (s *service) Progress(ctx context.Context, userID string) (*profiling.Progress, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, profiling.ErrInvalidUserID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return profiling.NewProgress(userID, s.prompts, s.users[userID], time.Now()), nil
+}
+
+// Submit validates and records the user's answer to a prompt
+func (s *service) Submit(ctx context.Context, userID, field, value string) (*profiling.Progress, error) {
+	prompt, err := profiling.LookupPrompt(s.byField, userID, field)
+	if err != nil {
+		return nil, err
+	}
+	value, err = profiling.CheckValue(ctx, s.validation, prompt, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(userID, field, func(now time.Time, _ profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.FieldProgress{Status: profiling.FieldStatusCompleted, Value: value, UpdatedAt: &now}, nil
+	})
+}
+
+// Skip dismisses an optional prompt for good, discarding any earlier answer
+func (s *service) Skip(ctx context.Context, userID, field string) (*profiling.Progress, error) {
+	prompt, err := profiling.LookupPrompt(s.byField, userID, field)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Required {
+		return nil, profiling.FieldError(profiling.ErrRequiredField, field)
+	}
+
+	return s.update(userID, field, func(now time.Time, _ profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.FieldProgress{Status: profiling.FieldStatusSkipped, UpdatedAt: &now}, nil
+	})
+}
+
+// RemindLater hides an unanswered prompt until the given time. A zero time
+// uses the configured reminder delay.
+func (s *service) RemindLater(ctx context.Context, userID, field string, until time.Time) (*profiling.Progress, error) {
+	if _, err := profiling.LookupPrompt(s.byField, userID, field); err != nil {
+		return nil, err
+	}
+
+	return s.update(userID, field, func(now time.Time, current profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.RemindLaterField(current, field, until, now, s.remindAfter)
+	})
+}
+
+// Helper methods
+
+// update applies a change to one field under the write lock and returns the resulting progress
+func (s *service) update(userID, field string, change func(now time.Time, current profiling.FieldProgress) (profiling.FieldProgress, error)) (*profiling.Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	fields := s.users[userID]
+	if fields == nil {
+		fields = make(map[string]profiling.FieldProgress)
+		s.users[userID] = fields
+	}
+
+	next, err := change(now, profiling.StoredField(fields, field))
+	if err != nil {
+		return nil, err
+	}
+	fields[field] = next
+
+	return profiling.NewProgress(userID, s.prompts, fields, now), nil
+}
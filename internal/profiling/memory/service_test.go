@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/profiling/memory"
+	"github.com/gentra/decorator-arch-go/internal/profiling/profilingtest"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+func TestMemoryService_Conformance(t *testing.T) {
+	profilingtest.RunServiceConformance(t, func(validationService validation.Service, prompts []profiling.Prompt) (profiling.Service, error) {
+		return memory.NewService(validationService, memory.Config{Prompts: prompts})
+	})
+}
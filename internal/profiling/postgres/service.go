@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// fieldColumns are the profile_fields columns scanFields reads, in order
+const fieldColumns = `field, status, value, remind_at, updated_at`
+
+// service implements profiling.Service on the profile_fields table, so
+// onboarding progress survives restarts and every instance sees the same one
+type service struct {
+	pool        *pgxpool.Pool
+	prompts     []profiling.Prompt
+	byField     map[string]profiling.Prompt
+	remindAfter time.Duration
+	validation  validation.Service
+}
+
+// Config contains the prompt definitions and reminder settings
+type Config struct {
+	Prompts     []profiling.Prompt
+	RemindAfter time.Duration
+}
+
+// NewService creates a Postgres-backed progressive profiling service.
+// Submitted values are checked with the validation service, so prompts may
+// reference custom rules registered on it.
+func NewService(pool *pgxpool.Pool, validationService validation.Service, config Config) (profiling.Service, error) {
+	if validationService == nil {
+		return nil, fmt.Errorf("validation service is required")
+	}
+
+	byField, err := profiling.IndexPrompts(config.Prompts)
+	if err != nil {
+		return nil, err
+	}
+
+	remindAfter := config.RemindAfter
+	if remindAfter <= 0 {
+		remindAfter = profiling.DefaultRemindAfter
+	}
+
+	return &service{
+		pool:        pool,
+		prompts:     append([]profiling.Prompt(nil), config.Prompts...),
+		byField:     byField,
+		remindAfter: remindAfter,
+		validation:  validationService,
+	}, nil
+}
+
+// Prompts returns every prompt definition in onboarding order
+func (s *service) Prompts(ctx context.Context) ([]profiling.Prompt, error) {
+	return append([]profiling.Prompt(nil), s.prompts...), nil
+}
+
+// Progress returns the user's onboarding state, per-field status and the prompts due now
+func (s *service) Progress(ctx context.Context, userID string) (*profiling.Progress, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, profiling.ErrInvalidUserID
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT `+fieldColumns+` FROM profile_fields WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := scanFields(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiling.NewProgress(userID, s.prompts, fields, time.Now()), nil
+}
+
+// Submit validates and records the user's answer to a prompt
+func (s *service) Submit(ctx context.Context, userID, field, value string) (*profiling.Progress, error) {
+	prompt, err := profiling.LookupPrompt(s.byField, userID, field)
+	if err != nil {
+		return nil, err
+	}
+	value, err = profiling.CheckValue(ctx, s.validation, prompt, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, field, func(now time.Time, _ profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.FieldProgress{Status: profiling.FieldStatusCompleted, Value: value, UpdatedAt: &now}, nil
+	})
+}
+
+// Skip dismisses an optional prompt for good, discarding any earlier answer
+func (s *service) Skip(ctx context.Context, userID, field string) (*profiling.Progress, error) {
+	prompt, err := profiling.LookupPrompt(s.byField, userID, field)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Required {
+		return nil, profiling.FieldError(profiling.ErrRequiredField, field)
+	}
+
+	return s.update(ctx, userID, field, func(now time.Time, _ profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.FieldProgress{Status: profiling.FieldStatusSkipped, UpdatedAt: &now}, nil
+	})
+}
+
+// RemindLater hides an unanswered prompt until the given time. A zero time
+// uses the configured reminder delay.
+func (s *service) RemindLater(ctx context.Context, userID, field string, until time.Time) (*profiling.Progress, error) {
+	if _, err := profiling.LookupPrompt(s.byField, userID, field); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, field, func(now time.Time, current profiling.FieldProgress) (profiling.FieldProgress, error) {
+		return profiling.RemindLaterField(current, field, until, now, s.remindAfter)
+	})
+}
+
+// Helper methods
+
+// update applies a change to one field and returns the resulting progress.
+// Changes to one user take turns, so a change always sees the latest answer.
+func (s *service) update(ctx context.Context, userID, field string, change func(now time.Time, current profiling.FieldProgress) (profiling.FieldProgress, error)) (*profiling.Progress, error) {
+	var progress *profiling.Progress
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, userID); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `SELECT `+fieldColumns+` FROM profile_fields WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		fields, err := scanFields(rows)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		next, err := change(now, profiling.StoredField(fields, field))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO profile_fields (user_id, field, status, value, remind_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, field) DO UPDATE
+			SET status = EXCLUDED.status, value = EXCLUDED.value, remind_at = EXCLUDED.remind_at,
+				updated_at = EXCLUDED.updated_at`,
+			userID, field, string(next.Status), next.Value, next.RemindAt, next.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to save profile field %s: %w", field, err)
+		}
+		fields[field] = next
+
+		progress = profiling.NewProgress(userID, s.prompts, fields, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// scanFields reads the fieldColumns of every row, keyed by field
+func scanFields(rows pgx.Rows) (map[string]profiling.FieldProgress, error) {
+	defer rows.Close()
+
+	fields := make(map[string]profiling.FieldProgress)
+	for rows.Next() {
+		var field, status string
+		var f profiling.FieldProgress
+		if err := rows.Scan(&field, &status, &f.Value, &f.RemindAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		f.Status = profiling.FieldStatus(status)
+		fields[field] = f
+	}
+	return fields, rows.Err()
+}
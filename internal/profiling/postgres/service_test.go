@@ -0,0 +1,33 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/profiling/postgres"
+	"github.com/gentra/decorator-arch-go/internal/profiling/profilingtest"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// testDatabaseEnv names a migrated Postgres database the tests may write to
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+func TestPostgresService_Conformance(t *testing.T) {
+	databaseURL := os.Getenv(testDatabaseEnv)
+	if databaseURL == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	profilingtest.RunServiceConformance(t, func(validationService validation.Service, prompts []profiling.Prompt) (profiling.Service, error) {
+		return postgres.NewService(pool, validationService, postgres.Config{Prompts: prompts})
+	})
+}
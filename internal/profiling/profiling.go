@@ -0,0 +1,310 @@
+package profiling
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// Service defines the progressive profiling domain interface - the ONLY interface in this domain
+// Registration only asks for the essentials; the remaining profile fields are
+// collected over time through prompts. Which prompts are shown is driven by
+// the onboarding state machine: users move through the states in order, and a
+// state is finished once each of its prompts has been answered or skipped.
+type Service interface {
+	// Prompts returns every prompt definition in onboarding order
+	Prompts(ctx context.Context) ([]Prompt, error)
+
+	// Progress returns the user's onboarding state, per-field status and the prompts due now
+	Progress(ctx context.Context, userID string) (*Progress, error)
+
+	// Field responses
+	Submit(ctx context.Context, userID, field, value string) (*Progress, error)
+	Skip(ctx context.Context, userID, field string) (*Progress, error)
+	RemindLater(ctx context.Context, userID, field string, until time.Time) (*Progress, error)
+}
+
+// Domain types and data structures
+
+// State is a step of the onboarding state machine
+type State string
+
+// Onboarding states in the order users move through them
+const (
+	StateBasics   State = "basics"
+	StateDetails  State = "details"
+	StateComplete State = "complete"
+)
+
+// States lists every onboarding state in order
+var States = []State{StateBasics, StateDetails, StateComplete}
+
+// Next returns the state that follows s; StateComplete is terminal
+func (s State) Next() State {
+	for i, state := range States {
+		if state == s && i+1 < len(States) {
+			return States[i+1]
+		}
+	}
+	return StateComplete
+}
+
+// IsValid reports whether s is a known onboarding state
+func (s State) IsValid() bool {
+	return s.index() >= 0
+}
+
+func (s State) index() int {
+	for i, state := range States {
+		if state == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Prompt defines a profile field collected after registration
+type Prompt struct {
+	Field       string `json:"field"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	State       State  `json:"state"`
+	Required    bool   `json:"required"`
+
+	// Rules are passed to validation.Service.ValidateField, so they may be
+	// validator tags or the names of registered custom rules
+	Rules string `json:"rules,omitempty"`
+}
+
+// FieldStatus is how the user has responded to a prompt
+type FieldStatus string
+
+// Field statuses
+const (
+	FieldStatusPending     FieldStatus = "pending"
+	FieldStatusCompleted   FieldStatus = "completed"
+	FieldStatusSkipped     FieldStatus = "skipped"
+	FieldStatusRemindLater FieldStatus = "remind_later"
+)
+
+// FieldProgress tracks a single prompt for a user
+type FieldProgress struct {
+	Status    FieldStatus `json:"status"`
+	Value     string      `json:"value,omitempty"`
+	RemindAt  *time.Time  `json:"remind_at,omitempty"`
+	UpdatedAt *time.Time  `json:"updated_at,omitempty"`
+}
+
+// IsAnswered reports whether the prompt no longer holds up onboarding
+func (f FieldProgress) IsAnswered() bool {
+	return f.Status == FieldStatusCompleted || f.Status == FieldStatusSkipped
+}
+
+// IsDue reports whether the prompt should be shown at the given time
+func (f FieldProgress) IsDue(now time.Time) bool {
+	switch f.Status {
+	case FieldStatusPending:
+		return true
+	case FieldStatusRemindLater:
+		return f.RemindAt == nil || !now.Before(*f.RemindAt)
+	default:
+		return false
+	}
+}
+
+// Progress is a user's position in onboarding
+type Progress struct {
+	UserID string                   `json:"user_id"`
+	State  State                    `json:"state"`
+	Fields map[string]FieldProgress `json:"fields"`
+
+	// Due are the prompts of the current state that should be shown now
+	Due []Prompt `json:"due"`
+
+	// Completion is the percentage of prompts the user has filled in
+	Completion int `json:"completion"`
+}
+
+// IsComplete reports whether the user has finished onboarding
+func (p *Progress) IsComplete() bool {
+	return p.State == StateComplete
+}
+
+// Value returns the submitted value of a completed field
+func (p *Progress) Value(field string) (string, bool) {
+	f, ok := p.Fields[field]
+	if !ok || f.Status != FieldStatusCompleted {
+		return "", false
+	}
+	return f.Value, true
+}
+
+// ProfilingError represents domain-specific progressive profiling errors
+type ProfilingError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e ProfilingError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code so errors carrying a field still match the sentinels
+func (e ProfilingError) Is(target error) bool {
+	t, ok := target.(ProfilingError)
+	return ok && t.Code == e.Code
+}
+
+// Common progressive profiling error codes
+var (
+	ErrUnknownField    = ProfilingError{Code: "UNKNOWN_PROFILE_FIELD", Message: "No prompt is defined for this field"}
+	ErrEmptyValue      = ProfilingError{Code: "EMPTY_PROFILE_VALUE", Message: "A value is required; skip the prompt instead"}
+	ErrRequiredField   = ProfilingError{Code: "PROFILE_FIELD_REQUIRED", Message: "Required prompts cannot be skipped"}
+	ErrAlreadyAnswered = ProfilingError{Code: "PROFILE_FIELD_ANSWERED", Message: "The prompt has already been answered"}
+	ErrInvalidRemindAt = ProfilingError{Code: "INVALID_REMIND_AT", Message: "Reminder time must be in the future"}
+	ErrInvalidPrompt   = ProfilingError{Code: "INVALID_PROMPT", Message: "Invalid prompt definition"}
+	ErrDuplicatePrompt = ProfilingError{Code: "DUPLICATE_PROMPT", Message: "A prompt is already defined for this field"}
+	ErrInvalidUserID   = ProfilingError{Code: "INVALID_USER_ID", Message: "User ID is required"}
+)
+
+// DefaultRemindAfter is how long a prompt stays hidden when no reminder time is given
+const DefaultRemindAfter = 24 * time.Hour
+
+// DefaultPrompts returns the fields collected after registration
+func DefaultPrompts() []Prompt {
+	return []Prompt{
+		{Field: "job_title", Label: "Job title", State: StateBasics, Required: true, Rules: "max=100"},
+		{Field: "company", Label: "Company", State: StateBasics, Rules: "max=100"},
+		{Field: "phone", Label: "Phone number", Description: "Used for account recovery", State: StateDetails, Rules: "e164"},
+		{Field: "website", Label: "Website", State: StateDetails, Rules: "url,max=255"},
+		{Field: "bio", Label: "About you", State: StateDetails, Rules: "max=500"},
+	}
+}
+
+// Helpers shared by the storage implementations
+
+// IndexPrompts checks the prompt definitions and indexes them by field
+func IndexPrompts(prompts []Prompt) (map[string]Prompt, error) {
+	byField := make(map[string]Prompt, len(prompts))
+	for _, prompt := range prompts {
+		if strings.TrimSpace(prompt.Field) == "" || !prompt.State.IsValid() || prompt.State == StateComplete {
+			return nil, FieldError(ErrInvalidPrompt, prompt.Field)
+		}
+		if _, exists := byField[prompt.Field]; exists {
+			return nil, FieldError(ErrDuplicatePrompt, prompt.Field)
+		}
+		byField[prompt.Field] = prompt
+	}
+	return byField, nil
+}
+
+// LookupPrompt validates the user ID and returns the prompt defined for the field
+func LookupPrompt(byField map[string]Prompt, userID, field string) (Prompt, error) {
+	if strings.TrimSpace(userID) == "" {
+		return Prompt{}, ErrInvalidUserID
+	}
+
+	prompt, ok := byField[field]
+	if !ok {
+		return Prompt{}, FieldError(ErrUnknownField, field)
+	}
+	return prompt, nil
+}
+
+// CheckValue trims a submitted value and checks it against the prompt's
+// rules with the validation service
+func CheckValue(ctx context.Context, validationService validation.Service, prompt Prompt, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", FieldError(ErrEmptyValue, prompt.Field)
+	}
+	if prompt.Rules != "" {
+		if err := validationService.ValidateField(ctx, prompt.Field, value, prompt.Rules); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// RemindLaterField hides an unanswered field until the given time, or for
+// remindAfter when the time is zero
+func RemindLaterField(current FieldProgress, field string, until, now time.Time, remindAfter time.Duration) (FieldProgress, error) {
+	if current.IsAnswered() {
+		return current, FieldError(ErrAlreadyAnswered, field)
+	}
+
+	remindAt := until
+	if remindAt.IsZero() {
+		remindAt = now.Add(remindAfter)
+	}
+	if !remindAt.After(now) {
+		return current, FieldError(ErrInvalidRemindAt, field)
+	}
+	return FieldProgress{Status: FieldStatusRemindLater, RemindAt: &remindAt, UpdatedAt: &now}, nil
+}
+
+// StoredField returns the stored progress for a field, pending if the user has not responded
+func StoredField(fields map[string]FieldProgress, field string) FieldProgress {
+	if f, ok := fields[field]; ok {
+		return f
+	}
+	return FieldProgress{Status: FieldStatusPending}
+}
+
+// NewProgress derives the user's state from their stored responses
+func NewProgress(userID string, prompts []Prompt, stored map[string]FieldProgress, now time.Time) *Progress {
+	fields := make(map[string]FieldProgress, len(prompts))
+	for _, prompt := range prompts {
+		fields[prompt.Field] = StoredField(stored, prompt.Field)
+	}
+
+	// Advance through the states until one still has unanswered prompts
+	state := States[0]
+	for state != StateComplete && stateAnswered(state, prompts, fields) {
+		state = state.Next()
+	}
+
+	progress := &Progress{
+		UserID: userID,
+		State:  state,
+		Fields: fields,
+		Due:    []Prompt{},
+	}
+
+	completed := 0
+	for _, prompt := range prompts {
+		f := fields[prompt.Field]
+		if f.Status == FieldStatusCompleted {
+			completed++
+		}
+		if prompt.State == state && f.IsDue(now) {
+			progress.Due = append(progress.Due, prompt)
+		}
+	}
+	if len(prompts) == 0 {
+		progress.Completion = 100
+	} else {
+		progress.Completion = completed * 100 / len(prompts)
+	}
+
+	return progress
+}
+
+// stateAnswered reports whether every prompt of the state has been answered or skipped
+func stateAnswered(state State, prompts []Prompt, fields map[string]FieldProgress) bool {
+	for _, prompt := range prompts {
+		if prompt.State == state && !fields[prompt.Field].IsAnswered() {
+			return false
+		}
+	}
+	return true
+}
+
+// FieldError attaches the field to a profiling error
+func FieldError(err ProfilingError, field string) error {
+	err.Field = field
+	return err
+}
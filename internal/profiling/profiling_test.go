@@ -0,0 +1,46 @@
+package profiling_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+)
+
+func TestState_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    profiling.State
+		expected profiling.State
+	}{
+		{name: "Given basics state, When Next is called, Then should return details", state: profiling.StateBasics, expected: profiling.StateDetails},
+		{name: "Given details state, When Next is called, Then should return complete", state: profiling.StateDetails, expected: profiling.StateComplete},
+		{name: "Given complete state, When Next is called, Then should stay complete", state: profiling.StateComplete, expected: profiling.StateComplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.state.Next())
+		})
+	}
+}
+
+func TestFieldProgress_IsAnswered(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   profiling.FieldStatus
+		expected bool
+	}{
+		{name: "Given pending field, When IsAnswered is called, Then should return false", status: profiling.FieldStatusPending, expected: false},
+		{name: "Given deferred field, When IsAnswered is called, Then should return false", status: profiling.FieldStatusRemindLater, expected: false},
+		{name: "Given completed field, When IsAnswered is called, Then should return true", status: profiling.FieldStatusCompleted, expected: true},
+		{name: "Given skipped field, When IsAnswered is called, Then should return true", status: profiling.FieldStatusSkipped, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, profiling.FieldProgress{Status: tt.status}.IsAnswered())
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package profilingtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/profiling"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	validationStandard "github.com/gentra/decorator-arch-go/internal/validation/standard"
+)
+
+// NewServiceFunc creates the progressive profiling service under test with
+// the prompts and the default reminder delay
+type NewServiceFunc func(validationService validation.Service, prompts []profiling.Prompt) (profiling.Service, error)
+
+// RunServiceConformance checks that a profiling.Service behaves like the
+// contract the REST API relies on. newService is called once per subtest;
+// the services may share a database, as every subtest answers prompts for
+// new users. Stores run the same suite so they stay interchangeable.
+func RunServiceConformance(t *testing.T, newService NewServiceFunc) {
+	t.Helper()
+
+	t.Run("Progress", func(t *testing.T) { testProgress(t, newService) })
+	t.Run("Advance", func(t *testing.T) { testAdvance(t, newService) })
+	t.Run("Submit", func(t *testing.T) { testSubmit(t, newService) })
+	t.Run("Skip", func(t *testing.T) { testSkip(t, newService) })
+	t.Run("RemindLater", func(t *testing.T) { testRemindLater(t, newService) })
+	t.Run("DuplicatePrompts", func(t *testing.T) { testDuplicatePrompts(t, newService) })
+}
+
+// noCompanyNamedAcme is a custom rule registered on the validation service
+type noCompanyNamedAcme struct{}
+
+func (noCompanyNamedAcme) Validate(ctx context.Context, value interface{}) error {
+	if value == "Acme" {
+		return errors.New("company name is reserved")
+	}
+	return nil
+}
+
+func (noCompanyNamedAcme) Name() string        { return "not_acme" }
+func (noCompanyNamedAcme) Description() string { return "Rejects the reserved company name" }
+
+func newTestService(t *testing.T, newService NewServiceFunc) profiling.Service {
+	t.Helper()
+	validationSvc := validationStandard.NewService()
+	require.NoError(t, validationSvc.AddCustomRule("not_acme", noCompanyNamedAcme{}))
+
+	service, err := newService(validationSvc, []profiling.Prompt{
+		{Field: "job_title", Label: "Job title", State: profiling.StateBasics, Required: true, Rules: "max=20"},
+		{Field: "company", Label: "Company", State: profiling.StateBasics, Rules: "max=50,not_acme"},
+		{Field: "phone", Label: "Phone number", State: profiling.StateDetails, Rules: "e164"},
+	})
+	require.NoError(t, err)
+	return service
+}
+
+// newUserID returns a user no other subtest answers prompts for
+func newUserID() string {
+	return "user-" + uuid.NewString()
+}
+
+func dueFields(progress *profiling.Progress) []string {
+	fields := []string{}
+	for _, prompt := range progress.Due {
+		fields = append(fields, prompt.Field)
+	}
+	return fields
+}
+
+func testProgress(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given a new user, When getting progress, Then shows the first state prompts", func(t *testing.T) {
+		service := newTestService(t, newService)
+
+		progress, err := service.Progress(context.Background(), newUserID())
+
+		require.NoError(t, err)
+		assert.Equal(t, profiling.StateBasics, progress.State)
+		assert.Equal(t, []string{"job_title", "company"}, dueFields(progress))
+		assert.Equal(t, profiling.FieldStatusPending, progress.Fields["phone"].Status)
+		assert.Equal(t, 0, progress.Completion)
+	})
+}
+
+func testAdvance(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given a state answered, When submitting and skipping, Then advances to the next state", func(t *testing.T) {
+		service := newTestService(t, newService)
+		ctx := context.Background()
+		userID := newUserID()
+
+		_, err := service.Submit(ctx, userID, "job_title", "  Engineer ")
+		require.NoError(t, err)
+		progress, err := service.Skip(ctx, userID, "company")
+		require.NoError(t, err)
+
+		assert.Equal(t, profiling.StateDetails, progress.State)
+		assert.Equal(t, []string{"phone"}, dueFields(progress))
+		value, ok := progress.Value("job_title")
+		assert.True(t, ok)
+		assert.Equal(t, "Engineer", value)
+		assert.Equal(t, 33, progress.Completion)
+
+		progress, err = service.Submit(ctx, userID, "phone", "+14155550100")
+		require.NoError(t, err)
+		assert.True(t, progress.IsComplete())
+		assert.Empty(t, progress.Due)
+	})
+}
+
+func testSubmit(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given invalid values, When submitting, Then rejects them with the registered rules", func(t *testing.T) {
+		service := newTestService(t, newService)
+		ctx := context.Background()
+		userID := newUserID()
+
+		_, err := service.Submit(ctx, userID, "company", "Acme")
+		var validationErr validation.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "not_acme", validationErr.Rule)
+
+		_, err = service.Submit(ctx, userID, "phone", "not a phone")
+		assert.ErrorAs(t, err, &validationErr)
+
+		_, err = service.Submit(ctx, userID, "company", "  ")
+		assert.ErrorIs(t, err, profiling.ErrEmptyValue)
+
+		_, err = service.Submit(ctx, userID, "shoe_size", "42")
+		assert.ErrorIs(t, err, profiling.ErrUnknownField)
+	})
+}
+
+func testSkip(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given a required prompt, When skipping, Then returns the required error", func(t *testing.T) {
+		service := newTestService(t, newService)
+
+		_, err := service.Skip(context.Background(), newUserID(), "job_title")
+
+		assert.ErrorIs(t, err, profiling.ErrRequiredField)
+	})
+}
+
+func testRemindLater(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given remind later, When getting progress, Then hides the prompt without advancing", func(t *testing.T) {
+		service := newTestService(t, newService)
+		ctx := context.Background()
+		userID := newUserID()
+
+		progress, err := service.RemindLater(ctx, userID, "job_title", time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, profiling.StateBasics, progress.State)
+		assert.Equal(t, []string{"company"}, dueFields(progress))
+		field := progress.Fields["job_title"]
+		assert.Equal(t, profiling.FieldStatusRemindLater, field.Status)
+		require.NotNil(t, field.RemindAt)
+		assert.WithinDuration(t, time.Now().Add(profiling.DefaultRemindAfter), *field.RemindAt, time.Minute)
+		assert.False(t, field.IsDue(time.Now()))
+		assert.True(t, field.IsDue(field.RemindAt.Add(time.Second)))
+
+		_, err = service.RemindLater(ctx, userID, "company", time.Now().Add(-time.Hour))
+		assert.ErrorIs(t, err, profiling.ErrInvalidRemindAt)
+
+		_, err = service.Submit(ctx, userID, "job_title", "Engineer")
+		require.NoError(t, err)
+		_, err = service.RemindLater(ctx, userID, "job_title", time.Time{})
+		assert.ErrorIs(t, err, profiling.ErrAlreadyAnswered)
+	})
+}
+
+func testDuplicatePrompts(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given duplicate prompts, When creating, Then returns an error", func(t *testing.T) {
+		_, err := newService(validationStandard.NewService(), []profiling.Prompt{
+			{Field: "company", State: profiling.StateBasics},
+			{Field: "company", State: profiling.StateDetails},
+		})
+
+		assert.ErrorIs(t, err, profiling.ErrDuplicatePrompt)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gentra/decorator-arch-go/internal/ratelimit"
 	"github.com/gentra/decorator-arch-go/internal/ratelimit/memory"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit/tokenbucket"
 )
 
 // Config contains all configuration for building the rate limit service
@@ -111,6 +112,10 @@ func (f *RateLimitServiceFactory) buildMemoryService() (ratelimit.Service, error
 		defaultLimits = ratelimit.GetDefaultRateLimitConfigs()
 	}
 
+	if f.config.Algorithm == "token_bucket" {
+		return tokenbucket.NewService(defaultLimits), nil
+	}
+
 	return memory.NewService(defaultLimits), nil
 }
 
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,7 +37,7 @@ func NewService(defaultLimits map[string]ratelimit.RateLimitConfig) ratelimit.Se
 // Allow checks if a request is allowed for the given key
 func (s *service) Allow(ctx context.Context, key string) (bool, error) {
 	s.mu.RLock()
-	config, exists := s.limits[getPatternFromKey(key)]
+	config, exists := s.limitFor(key)
 	s.mu.RUnlock()
 
 	if !exists {
@@ -90,7 +91,7 @@ func (s *service) Reset(ctx context.Context, key string) error {
 // GetStatus returns the current rate limit status for a key
 func (s *service) GetStatus(ctx context.Context, key string) (*ratelimit.RateLimitStatus, error) {
 	s.mu.RLock()
-	config, exists := s.limits[getPatternFromKey(key)]
+	config, exists := s.limitFor(key)
 	counter := s.counters[key]
 	s.mu.RUnlock()
 
@@ -179,28 +180,21 @@ func (s *service) RemoveLimit(ctx context.Context, pattern string) error {
 	return nil
 }
 
-// Helper function to extract the pattern from a rate limit key
-func getPatternFromKey(key string) string {
-	// Extract the pattern part from keys like "user:register:email@example.com"
-	// This is a simple implementation - in production you might use regex
-	if len(key) > 13 && key[:13] == "user:register" {
-		return "user:register"
-	}
-	if len(key) > 10 && key[:10] == "user:login" {
-		return "user:login"
-	}
-	if len(key) > 9 && key[:9] == "user:read" {
-		return "user:read"
-	}
-	if len(key) > 11 && key[:11] == "user:update" {
-		return "user:update"
-	}
-	if len(key) > 15 && key[:15] == "user:prefs:read" {
-		return "user:prefs:read"
+// limitFor returns the configuration of the longest pattern that prefixes the
+// key at a ":" boundary, so "user:login:ip:10.0.0.1" gets the limit of
+// "user:login:ip" rather than that of "user:login", falling back to the
+// "default" pattern. The caller holds the lock.
+func (s *service) limitFor(key string) (ratelimit.RateLimitConfig, bool) {
+	best := ""
+	for pattern := range s.limits {
+		if len(pattern) > len(best) && (key == pattern || strings.HasPrefix(key, pattern+":")) {
+			best = pattern
+		}
 	}
-	if len(key) > 17 && key[:17] == "user:prefs:update" {
-		return "user:prefs:update"
+	if best == "" {
+		best = "default"
 	}
 
-	return "default"
+	config, exists := s.limits[best]
+	return config, exists
 }
@@ -0,0 +1,204 @@
+package tokenbucket
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+)
+
+// service implements ratelimit.Service interface using in-memory token buckets
+type service struct {
+	limits  map[string]ratelimit.RateLimitConfig
+	buckets map[string]*bucket
+	mu      sync.RWMutex
+}
+
+// bucket holds the tokens available for a specific key. A full bucket allows
+// a burst of Limit requests; tokens refill continuously at Limit per Window.
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+	mu         sync.Mutex
+}
+
+// NewService creates a new in-memory token bucket rate limiter
+func NewService(defaultLimits map[string]ratelimit.RateLimitConfig) ratelimit.Service {
+	if defaultLimits == nil {
+		defaultLimits = ratelimit.GetDefaultRateLimitConfigs()
+	}
+
+	limits := make(map[string]ratelimit.RateLimitConfig, len(defaultLimits))
+	for pattern, config := range defaultLimits {
+		limits[pattern] = config
+	}
+
+	return &service{
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the key's bucket if one is available
+func (s *service) Allow(ctx context.Context, key string) (bool, error) {
+	config, exists := s.limitFor(key)
+	if !exists {
+		// If no specific limit is configured, allow the request
+		return true, nil
+	}
+
+	b := s.bucketFor(key, config)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(config, time.Now())
+	if b.tokens < 1 {
+		return false, nil
+	}
+
+	b.tokens--
+	return true, nil
+}
+
+// Reset refills the bucket for a key
+func (s *service) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buckets, key)
+	return nil
+}
+
+// GetStatus returns the current rate limit status for a key
+func (s *service) GetStatus(ctx context.Context, key string) (*ratelimit.RateLimitStatus, error) {
+	config, exists := s.limitFor(key)
+	if !exists {
+		// No limit configured
+		return &ratelimit.RateLimitStatus{
+			Key:            key,
+			Limit:          -1, // No limit
+			Remaining:      -1,
+			ResetTime:      time.Now().Add(time.Hour),
+			WindowDuration: time.Hour,
+		}, nil
+	}
+
+	b := s.bucketFor(key, config)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(config, now)
+
+	interval := refillInterval(config)
+	status := &ratelimit.RateLimitStatus{
+		Key:            key,
+		Limit:          config.Limit,
+		Remaining:      int(b.tokens),
+		ResetTime:      now.Add(time.Duration((float64(config.Limit) - b.tokens) * float64(interval))),
+		WindowDuration: config.Window,
+	}
+
+	if b.tokens < 1 {
+		status.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	}
+
+	return status, nil
+}
+
+// SetLimit sets a rate limit configuration for a pattern
+func (s *service) SetLimit(ctx context.Context, pattern string, config ratelimit.RateLimitConfig) error {
+	if !config.IsValid() {
+		return &ratelimit.RateLimitError{
+			Key:    pattern,
+			Limit:  config.Limit,
+			Window: config.Window,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limits[pattern] = config
+	return nil
+}
+
+// GetLimit returns the rate limit configuration for a pattern
+func (s *service) GetLimit(ctx context.Context, pattern string) (*ratelimit.RateLimitConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if config, exists := s.limits[pattern]; exists {
+		return &config, nil
+	}
+
+	return nil, nil // No limit configured
+}
+
+// RemoveLimit removes a rate limit configuration for a pattern
+func (s *service) RemoveLimit(ctx context.Context, pattern string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.limits, pattern)
+	return nil
+}
+
+// limitFor returns the configuration of the longest pattern that prefixes the key,
+// falling back to the "default" pattern
+func (s *service) limitFor(key string) (ratelimit.RateLimitConfig, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	best := ""
+	for pattern := range s.limits {
+		if len(pattern) > len(best) && (key == pattern || strings.HasPrefix(key, pattern+":")) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		best = "default"
+	}
+
+	config, exists := s.limits[best]
+	return config, exists
+}
+
+// bucketFor returns the bucket for a key, creating a full one if needed
+func (s *service) bucketFor(key string, config ratelimit.RateLimitConfig) *bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{
+			tokens:     float64(config.Limit),
+			lastRefill: time.Now(),
+		}
+		s.buckets[key] = b
+	}
+	return b
+}
+
+// refill adds the tokens accrued since the last refill, up to the bucket capacity
+func (b *bucket) refill(config ratelimit.RateLimitConfig, now time.Time) {
+	elapsed := now.Sub(b.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens += elapsed.Seconds() * config.RequestsPerSecond()
+	if b.tokens > float64(config.Limit) {
+		b.tokens = float64(config.Limit)
+	}
+	b.lastRefill = now
+}
+
+// refillInterval returns the time needed to accrue one token
+func refillInterval(config ratelimit.RateLimitConfig) time.Duration {
+	return config.Window / time.Duration(config.Limit)
+}
@@ -0,0 +1,84 @@
+package tokenbucket_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit/tokenbucket"
+)
+
+func TestAllow_GivenBurstLimit_WhenExhausted_ThenBlocksUntilRefill(t *testing.T) {
+	ctx := context.Background()
+	service := tokenbucket.NewService(map[string]ratelimit.RateLimitConfig{
+		"user:login": {Limit: 2, Window: 100 * time.Millisecond},
+	})
+
+	for i := 0; i < 2; i++ {
+		allowed, err := service.Allow(ctx, "user:login:a@example.com")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, err := service.Allow(ctx, "user:login:a@example.com")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	status, err := service.GetStatus(ctx, "user:login:a@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Remaining)
+	assert.Greater(t, status.RetryAfter, time.Duration(0))
+
+	time.Sleep(60 * time.Millisecond)
+
+	allowed, err = service.Allow(ctx, "user:login:a@example.com")
+	require.NoError(t, err)
+	assert.True(t, allowed, "one token should have been refilled")
+}
+
+func TestAllow_GivenNestedPatterns_WhenMatching_ThenUsesLongestPrefix(t *testing.T) {
+	ctx := context.Background()
+	service := tokenbucket.NewService(map[string]ratelimit.RateLimitConfig{
+		"user:login":    {Limit: 1, Window: time.Hour},
+		"user:login:ip": {Limit: 3, Window: time.Hour},
+	})
+
+	tests := []struct {
+		name     string
+		key      string
+		expected int
+	}{
+		{name: "Given per-email key, When checking, Then user:login limit applies", key: "user:login:a@example.com", expected: 1},
+		{name: "Given per-IP key, When checking, Then user:login:ip limit applies", key: "user:login:ip:10.0.0.1", expected: 3},
+		{name: "Given unmatched key without default, When checking, Then it is unlimited", key: "other:key", expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := service.GetStatus(ctx, tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status.Limit)
+		})
+	}
+}
+
+func TestReset_GivenExhaustedBucket_WhenReset_ThenAllowsAgain(t *testing.T) {
+	ctx := context.Background()
+	service := tokenbucket.NewService(map[string]ratelimit.RateLimitConfig{
+		"user:register": {Limit: 1, Window: time.Hour},
+	})
+
+	allowed, _ := service.Allow(ctx, "user:register:a@example.com")
+	assert.True(t, allowed)
+	allowed, _ = service.Allow(ctx, "user:register:a@example.com")
+	assert.False(t, allowed)
+
+	require.NoError(t, service.Reset(ctx, "user:register:a@example.com"))
+
+	allowed, _ = service.Allow(ctx, "user:register:a@example.com")
+	assert.True(t, allowed)
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+)
+
+// userRevocation is a revocation of all of a user's tokens
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// service implements revocation.Service in memory, for single instances and
+// tests. Revocations are lost on restart. Expired tokens are swept when
+// tokens are revoked and by Cleanup, which also drops expired users.
+type service struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]userRevocation
+}
+
+// NewService creates an in-memory revocation store
+func NewService() revocation.Service {
+	return &service{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]userRevocation),
+	}
+}
+
+// RevokeToken records the token until it expires and drops the expired ones
+func (s *service) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = expiresAt
+	s.sweepTokens(time.Now())
+	return nil
+}
+
+// IsTokenRevoked reports whether the token is recorded and unexpired
+func (s *service) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiresAt, exists := s.tokens[tokenID]
+	return exists && time.Now().Before(expiresAt), nil
+}
+
+// ConsumeToken records the token unless it is recorded and unexpired;
+// tokens that have already expired cannot be consumed
+func (s *service) ConsumeToken(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(expiresAt) {
+		return false, nil
+	}
+	if revokedUntil, exists := s.tokens[tokenID]; exists && now.Before(revokedUntil) {
+		return false, nil
+	}
+	s.tokens[tokenID] = expiresAt
+	s.sweepTokens(now)
+	return true, nil
+}
+
+// RevokeUser records the user's revocation until expiresAt
+func (s *service) RevokeUser(ctx context.Context, userID string, revokedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[userID] = userRevocation{revokedAt: revokedAt, expiresAt: expiresAt}
+	return nil
+}
+
+// UserRevokedAt returns the user's unexpired revocation time
+func (s *service) UserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revoked, exists := s.users[userID]
+	if !exists || !time.Now().Before(revoked.expiresAt) {
+		return time.Time{}, nil
+	}
+	return revoked.revokedAt, nil
+}
+
+// Cleanup drops the expired token and user revocations
+func (s *service) Cleanup(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweepTokens(now)
+	for userID, revoked := range s.users {
+		if !now.Before(revoked.expiresAt) {
+			delete(s.users, userID)
+		}
+	}
+	return nil
+}
+
+// Helper methods
+
+// sweepTokens drops the expired token revocations; callers hold mu
+func (s *service) sweepTokens(now time.Time) {
+	for tokenID, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, tokenID)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/revocation/memory"
+)
+
+func TestRevocationStore_GivenRevokedToken_WhenChecking_ThenReportsRevokedUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	require.NoError(t, store.RevokeToken(ctx, "live", time.Now().Add(time.Hour)))
+	require.NoError(t, store.RevokeToken(ctx, "expired", time.Now().Add(-time.Second)))
+
+	revoked, err := store.IsTokenRevoked(ctx, "live")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = store.IsTokenRevoked(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = store.IsTokenRevoked(ctx, "unknown")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestRevocationStore_GivenRevokedUser_WhenRevokingAgain_ThenReturnsLatestTime(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	first := time.Now().Add(-time.Minute)
+	second := time.Now()
+	require.NoError(t, store.RevokeUser(ctx, "user123", first, first.Add(time.Hour)))
+	require.NoError(t, store.RevokeUser(ctx, "user123", second, second.Add(time.Hour)))
+
+	revokedAt, err := store.UserRevokedAt(ctx, "user123")
+
+	require.NoError(t, err)
+	assert.True(t, second.Equal(revokedAt))
+	other, err := store.UserRevokedAt(ctx, "user456")
+	require.NoError(t, err)
+	assert.True(t, other.IsZero())
+}
+
+func TestRevocationStore_GivenExpiredRevocations_WhenCleaningUp_ThenKeepsLiveOnes(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	now := time.Now()
+	require.NoError(t, store.RevokeUser(ctx, "expired", now.Add(-time.Hour), now.Add(-time.Second)))
+	require.NoError(t, store.RevokeUser(ctx, "live", now, now.Add(time.Hour)))
+	require.NoError(t, store.RevokeToken(ctx, "t1", now.Add(time.Hour)))
+
+	// Act
+	err := store.Cleanup(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	expired, err := store.UserRevokedAt(ctx, "expired")
+	require.NoError(t, err)
+	assert.True(t, expired.IsZero())
+	live, err := store.UserRevokedAt(ctx, "live")
+	require.NoError(t, err)
+	assert.True(t, now.Equal(live))
+	revoked, err := store.IsTokenRevoked(ctx, "t1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevocationStore_GivenToken_WhenConsumedTwice_ThenOnlyTheFirstSucceeds(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	expiresAt := time.Now().Add(time.Hour)
+
+	first, err := store.ConsumeToken(ctx, "one-time", expiresAt)
+	require.NoError(t, err)
+	second, err := store.ConsumeToken(ctx, "one-time", expiresAt)
+	require.NoError(t, err)
+	expired, err := store.ConsumeToken(ctx, "expired", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.False(t, expired)
+	revoked, err := store.IsTokenRevoked(ctx, "one-time")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+)
+
+// DefaultKeyPrefix namespaces revocations in a shared Redis
+const DefaultKeyPrefix = "revocation:"
+
+// service implements revocation.Service on Redis so every instance sees the
+// same revoked tokens and users. Each revocation is a key that expires with
+// it, so Redis drops expired entries itself and Cleanup has nothing to do.
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed revocation store using DefaultKeyPrefix
+func NewService(client *redis.Client) revocation.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed revocation store whose keys
+// start with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) revocation.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// RevokeToken sets a key for the token that expires with it; tokens that
+// have already expired are not recorded
+func (s *service) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if !time.Now().Before(expiresAt) {
+		return nil
+	}
+	return s.client.SetArgs(ctx, s.tokenKey(tokenID), 1, redis.SetArgs{ExpireAt: expiresAt}).Err()
+}
+
+// IsTokenRevoked reports whether the token's key exists
+func (s *service) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := s.client.Exists(ctx, s.tokenKey(tokenID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ConsumeToken sets the token's key only if it does not exist, with SET NX.
+// Tokens that have already expired cannot be consumed.
+func (s *service) ConsumeToken(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	if !time.Now().Before(expiresAt) {
+		return false, nil
+	}
+	err := s.client.SetArgs(ctx, s.tokenKey(tokenID), 1, redis.SetArgs{Mode: "NX", ExpireAt: expiresAt}).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RevokeUser stores the revocation time, in Unix nanoseconds, under a key
+// that expires at expiresAt
+func (s *service) RevokeUser(ctx context.Context, userID string, revokedAt, expiresAt time.Time) error {
+	if !time.Now().Before(expiresAt) {
+		return nil
+	}
+	return s.client.SetArgs(ctx, s.userKey(userID), revokedAt.UnixNano(), redis.SetArgs{ExpireAt: expiresAt}).Err()
+}
+
+// UserRevokedAt reads the user's revocation time
+func (s *service) UserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	value, err := s.client.Get(ctx, s.userKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// Cleanup does nothing; the keys expire on their own
+func (s *service) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// Helper methods
+
+// tokenKey returns the Redis key of a revoked token
+func (s *service) tokenKey(tokenID string) string {
+	return s.prefix + "token:" + tokenID
+}
+
+// userKey returns the Redis key of a user's revocation
+func (s *service) userKey(userID string) string {
+	return s.prefix + "user:" + userID
+}
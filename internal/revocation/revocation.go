@@ -0,0 +1,39 @@
+package revocation
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the revocation domain interface - the ONLY interface in this domain.
+// It records revoked tokens and users for token services that cannot forget
+// a token on their own, such as JWT. Every instance sharing a store sees the
+// same revocations, and a persistent store keeps them across restarts.
+type Service interface {
+	// RevokeToken records the token ID as revoked until the token expires
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// IsTokenRevoked reports whether the token ID was revoked and has not
+	// expired yet
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// ConsumeToken revokes the token ID like RevokeToken unless it already
+	// is, and reports whether this call revoked it. The check and the
+	// revocation are atomic, so of concurrent calls for a token exactly one
+	// reports true; single-use tokens are spent this way.
+	ConsumeToken(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error)
+
+	// RevokeUser revokes every token issued to the user up to revokedAt and
+	// remembers it until expiresAt, which should be no earlier than the
+	// expiry of the longest-lived of those tokens. A later revocation of the
+	// user replaces an earlier one.
+	RevokeUser(ctx context.Context, userID string, revokedAt, expiresAt time.Time) error
+
+	// UserRevokedAt returns when the user's tokens were last revoked, or the
+	// zero time when they were not or the revocation has expired
+	UserRevokedAt(ctx context.Context, userID string) (time.Time, error)
+
+	// Cleanup drops the revocations past their expiry. Stores whose entries
+	// expire on their own do nothing.
+	Cleanup(ctx context.Context) error
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+)
+
+// service implements rulestore.Service with audit logging capabilities.
+// Every change is audited whether it succeeds or not, with the rule before
+// and after it, so the trail shows which parameters changed and who changed
+// them. Reads are not audited.
+type service struct {
+	next         rulestore.Service
+	auditService audit.Service
+}
+
+// NewService creates a new audit-enabled rule store
+func NewService(next rulestore.Service, auditService audit.Service) rulestore.Service {
+	return &service{
+		next:         next,
+		auditService: auditService,
+	}
+}
+
+// List passes through
+func (s *service) List(ctx context.Context) ([]rulestore.Rule, error) {
+	return s.next.List(ctx)
+}
+
+// Get passes through
+func (s *service) Get(ctx context.Context, ruleID string) (*rulestore.Rule, error) {
+	return s.next.Get(ctx, ruleID)
+}
+
+// Create stores a rule with audit logging
+func (s *service) Create(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	created, err := s.next.Create(ctx, rule)
+
+	details := map[string]interface{}{"after": rule.ValidationRuleConfig}
+	if created != nil {
+		details["after"] = created.ValidationRuleConfig
+		details["version"] = created.Version
+	}
+	s.logAuditEntry(ctx, "validation_rule.create", rule.RuleID, details, err)
+
+	return created, err
+}
+
+// Update changes a rule with audit logging of its configuration before and
+// after
+func (s *service) Update(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	before, _ := s.next.Get(ctx, rule.RuleID)
+	updated, err := s.next.Update(ctx, rule)
+
+	details := map[string]interface{}{"after": rule.ValidationRuleConfig}
+	if before != nil {
+		details["before"] = before.ValidationRuleConfig
+	}
+	if updated != nil {
+		details["after"] = updated.ValidationRuleConfig
+		details["version"] = updated.Version
+	}
+	s.logAuditEntry(ctx, "validation_rule.update", rule.RuleID, details, err)
+
+	return updated, err
+}
+
+// Delete removes a rule with audit logging of its last configuration
+func (s *service) Delete(ctx context.Context, ruleID string) error {
+	before, _ := s.next.Get(ctx, ruleID)
+	err := s.next.Delete(ctx, ruleID)
+
+	details := map[string]interface{}{}
+	if before != nil {
+		details["before"] = before.ValidationRuleConfig
+	}
+	s.logAuditEntry(ctx, "validation_rule.delete", ruleID, details, err)
+
+	return err
+}
+
+// Helper methods
+
+// logAuditEntry logs an audit entry about a rule, attributed to the current
+// user of the audit context
+func (s *service) logAuditEntry(ctx context.Context, action, ruleID string, details map[string]interface{}, err error) {
+	auditCtx := audit.ExtractAuditContext(ctx)
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        auditCtx.CurrentUserID,
+		Action:        action,
+		Resource:      "validation_rule",
+		ResourceID:    ruleID,
+		Details:       details,
+		Success:       err == nil,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	// Don't fail the change if audit logging fails
+	s.auditService.Log(ctx, entry)
+}
@@ -0,0 +1,67 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	ruleStoreAudit "github.com/gentra/decorator-arch-go/internal/rulestore/audit"
+	"github.com/gentra/decorator-arch-go/internal/rulestore/memory"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+func newRule(min int) rulestore.Rule {
+	config := validationrule.DefaultValidationRuleConfig()
+	config.RuleID, config.Name, config.Type = "name_length", "name_length", validationrule.ValidationRuleTypeLength
+	config.Parameters["min"] = min
+	return rulestore.Rule{ValidationRuleConfig: config}
+}
+
+func TestUpdate_GivenParameterChange_WhenUpdating_ThenAuditsRuleBeforeAndAfterForCurrentUser(t *testing.T) {
+	// Arrange
+	auditService := &auditMock.MockAuditService{}
+	auditService.On("Log", mock.Anything, mock.Anything).Return(nil)
+	store := ruleStoreAudit.NewService(memory.NewService(), auditService)
+	ctx := audit.WithAuditContext(context.Background(), "admin-1", "203.0.113.7", "curl", "session-1")
+	_, err := store.Create(ctx, newRule(2))
+	require.NoError(t, err)
+
+	// Act
+	_, err = store.Update(ctx, newRule(3))
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, auditService.Calls, 2)
+	entry := auditService.Calls[1].Arguments.Get(1).(audit.AuditEntry)
+	assert.Equal(t, "validation_rule.update", entry.Action)
+	assert.Equal(t, "validation_rule", entry.Resource)
+	assert.Equal(t, "name_length", entry.ResourceID)
+	assert.Equal(t, "admin-1", entry.UserID)
+	assert.True(t, entry.Success)
+	details := entry.Details.(map[string]interface{})
+	assert.Equal(t, 2, details["before"].(validationrule.ValidationRuleConfig).Parameters["min"])
+	assert.Equal(t, 3, details["after"].(validationrule.ValidationRuleConfig).Parameters["min"])
+	assert.Equal(t, 2, details["version"])
+}
+
+func TestDelete_GivenUnknownRule_WhenDeleting_ThenAuditsFailure(t *testing.T) {
+	// Arrange
+	auditService := &auditMock.MockAuditService{}
+	auditService.On("Log", mock.Anything, mock.Anything).Return(nil)
+	store := ruleStoreAudit.NewService(memory.NewService(), auditService)
+
+	// Act
+	err := store.Delete(context.Background(), "missing")
+
+	// Assert
+	assert.ErrorIs(t, err, rulestore.ErrRuleNotFound)
+	auditService.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		return entry.Action == "validation_rule.delete" && !entry.Success && entry.Error == rulestore.ErrRuleNotFound.Message
+	}))
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+)
+
+// service implements rulestore.Service interface in memory, for single
+// instances and tests. Rules are copied in and out, so callers never share
+// their maps with the store.
+type service struct {
+	mu    sync.RWMutex
+	rules map[string]rulestore.Rule
+}
+
+// NewService creates an empty in-memory rule store
+func NewService() rulestore.Service {
+	return &service{rules: make(map[string]rulestore.Rule)}
+}
+
+// List returns every rule, by priority then rule ID
+func (s *service) List(ctx context.Context) ([]rulestore.Rule, error) {
+	s.mu.RLock()
+	rules := make([]rulestore.Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, clone(rule))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].RuleID < rules[j].RuleID
+	})
+	return rules, nil
+}
+
+// Get returns a rule
+func (s *service) Get(ctx context.Context, ruleID string) (*rulestore.Rule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.rules[ruleID]
+	if !ok {
+		return nil, rulestore.ErrRuleNotFound
+	}
+	rule = clone(rule)
+	return &rule, nil
+}
+
+// Create stores a new rule at version 1
+func (s *service) Create(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[rule.RuleID]; ok || s.nameTaken(rule.Name, "") {
+		return nil, rulestore.ErrRuleExists
+	}
+
+	now := time.Now()
+	rule = clone(rule)
+	rule.Version, rule.CreatedAt, rule.UpdatedAt = 1, now, now
+	s.rules[rule.RuleID] = rule
+	stored := clone(rule)
+	return &stored, nil
+}
+
+// Update replaces a rule's configuration, checking its version
+func (s *service) Update(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.rules[rule.RuleID]
+	if !ok {
+		return nil, rulestore.ErrRuleNotFound
+	}
+	if rule.Version != 0 && rule.Version != existing.Version {
+		return nil, rulestore.ErrConflict
+	}
+	if s.nameTaken(rule.Name, rule.RuleID) {
+		return nil, rulestore.ErrRuleExists
+	}
+
+	rule = clone(rule)
+	rule.Version, rule.CreatedAt, rule.UpdatedAt = existing.Version+1, existing.CreatedAt, time.Now()
+	s.rules[rule.RuleID] = rule
+	stored := clone(rule)
+	return &stored, nil
+}
+
+// Delete removes a rule
+func (s *service) Delete(ctx context.Context, ruleID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[ruleID]; !ok {
+		return rulestore.ErrRuleNotFound
+	}
+	delete(s.rules, ruleID)
+	return nil
+}
+
+// nameTaken reports whether a rule other than ruleID has the name; the
+// caller holds the lock
+func (s *service) nameTaken(name, ruleID string) bool {
+	for id, rule := range s.rules {
+		if id != ruleID && rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// clone copies a rule with its metadata and parameters
+func clone(rule rulestore.Rule) rulestore.Rule {
+	if rule.Metadata != nil {
+		metadata := make(map[string]string, len(rule.Metadata))
+		for key, value := range rule.Metadata {
+			metadata[key] = value
+		}
+		rule.Metadata = metadata
+	}
+	if rule.Parameters != nil {
+		parameters := make(map[string]interface{}, len(rule.Parameters))
+		for key, value := range rule.Parameters {
+			parameters[key] = value
+		}
+		rule.Parameters = parameters
+	}
+	return rule
+}
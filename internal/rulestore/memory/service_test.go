@@ -0,0 +1,141 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	"github.com/gentra/decorator-arch-go/internal/rulestore/memory"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+func newRule(ruleID, name string, priority int) rulestore.Rule {
+	config := validationrule.DefaultValidationRuleConfig()
+	config.RuleID, config.Name, config.Priority = ruleID, name, priority
+	config.Type = validationrule.ValidationRuleTypeLength
+	config.Parameters["min"] = 2
+	return rulestore.Rule{ValidationRuleConfig: config}
+}
+
+func TestCreate_GivenRules_WhenCreating_ThenStoresThemAtVersionOneInPriorityOrder(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+
+	// Act
+	_, err := store.Create(ctx, newRule("b", "name_b", 100))
+	require.NoError(t, err)
+	created, err := store.Create(ctx, newRule("a", "name_a", 10))
+	require.NoError(t, err)
+	rules, err := store.List(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, created.Version)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "a", rules[0].RuleID)
+	assert.Equal(t, "b", rules[1].RuleID)
+}
+
+func TestCreate_GivenTakenIDOrName_WhenCreating_ThenReturnsErrRuleExists(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Create(ctx, newRule("a", "name_a", 100))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		rule rulestore.Rule
+	}{
+		{name: "Given a taken rule ID, When creating, Then returns rule exists", rule: newRule("a", "other", 100)},
+		{name: "Given a taken name, When creating, Then returns rule exists", rule: newRule("b", "name_a", 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := store.Create(ctx, tt.rule)
+
+			// Assert
+			assert.ErrorIs(t, err, rulestore.ErrRuleExists)
+		})
+	}
+}
+
+func TestUpdate_GivenVersions_WhenUpdating_ThenChecksVersionAndBumpsIt(t *testing.T) {
+	tests := []struct {
+		name          string
+		ruleID        string
+		version       int
+		expectedErr   error
+		expectedAfter int
+	}{
+		{name: "Given the current version, When updating, Then bumps the version", ruleID: "a", version: 1, expectedAfter: 2},
+		{name: "Given no version, When updating, Then bumps the version", ruleID: "a", expectedAfter: 2},
+		{name: "Given a stale version, When updating, Then returns conflict", ruleID: "a", version: 7, expectedErr: rulestore.ErrConflict},
+		{name: "Given an unknown rule, When updating, Then returns not found", ruleID: "missing", expectedErr: rulestore.ErrRuleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			store := memory.NewService()
+			_, err := store.Create(ctx, newRule("a", "name_a", 100))
+			require.NoError(t, err)
+			rule := newRule(tt.ruleID, "name_a", 100)
+			rule.Enabled = false
+			rule.Version = tt.version
+
+			// Act
+			updated, err := store.Update(ctx, rule)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAfter, updated.Version)
+			assert.False(t, updated.Enabled)
+		})
+	}
+}
+
+func TestGet_GivenStoredRule_WhenCallerChangesParameters_ThenStoreIsUnchanged(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Create(ctx, newRule("a", "name_a", 100))
+	require.NoError(t, err)
+
+	// Act
+	rule, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	rule.Parameters["min"] = 50
+
+	// Assert
+	stored, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Parameters["min"])
+}
+
+func TestDelete_GivenRules_WhenDeleting_ThenRemovesThemOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.Create(ctx, newRule("a", "name_a", 100))
+	require.NoError(t, err)
+
+	// Act
+	err = store.Delete(ctx, "a")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Delete(ctx, "a"), rulestore.ErrRuleNotFound)
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, rulestore.ErrRuleNotFound)
+}
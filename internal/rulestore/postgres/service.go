@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/rulestore"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+const uniqueViolation = "23505"
+
+// ruleColumns are the columns scanRule reads, in order
+const ruleColumns = `rule_id, name, description, type, field, message, enabled, priority,
+	metadata, parameters, version, created_at, updated_at, updated_by`
+
+// service implements rulestore.Service on the validation_rules table, so
+// every instance sharing the database validates with the same rules
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed rule store
+func NewService(pool *pgxpool.Pool) rulestore.Service {
+	return &service{pool: pool}
+}
+
+// List returns every rule, by priority then rule ID
+func (s *service) List(ctx context.Context) ([]rulestore.Rule, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+ruleColumns+` FROM validation_rules ORDER BY priority, rule_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []rulestore.Rule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// Get returns a rule
+func (s *service) Get(ctx context.Context, ruleID string) (*rulestore.Rule, error) {
+	rule, err := scanRule(s.pool.QueryRow(ctx, `SELECT `+ruleColumns+` FROM validation_rules WHERE rule_id = $1`, ruleID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, rulestore.ErrRuleNotFound
+	}
+	return rule, err
+}
+
+// Create inserts a new rule at version 1
+func (s *service) Create(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	metadata, parameters, err := encodeMaps(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := scanRule(s.pool.QueryRow(ctx, `
+		INSERT INTO validation_rules (rule_id, name, description, type, field, message, enabled, priority,
+			metadata, parameters, version, created_at, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1, now(), now(), $11)
+		ON CONFLICT DO NOTHING
+		RETURNING `+ruleColumns,
+		rule.RuleID, rule.Name, rule.Description, string(rule.Type), rule.Field, rule.Message, rule.Enabled, rule.Priority,
+		metadata, parameters, rule.UpdatedBy))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, rulestore.ErrRuleExists
+	}
+	return created, err
+}
+
+// Update replaces a rule's configuration when its version still matches
+func (s *service) Update(ctx context.Context, rule rulestore.Rule) (*rulestore.Rule, error) {
+	metadata, parameters, err := encodeMaps(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanRule(s.pool.QueryRow(ctx, `
+		UPDATE validation_rules
+		SET name = $2, description = $3, type = $4, field = $5, message = $6, enabled = $7, priority = $8,
+			metadata = $9, parameters = $10, version = version + 1, updated_at = now(), updated_by = $11
+		WHERE rule_id = $1 AND ($12 = 0 OR version = $12)
+		RETURNING `+ruleColumns,
+		rule.RuleID, rule.Name, rule.Description, string(rule.Type), rule.Field, rule.Message, rule.Enabled, rule.Priority,
+		metadata, parameters, rule.UpdatedBy, rule.Version))
+	switch {
+	case isUniqueViolation(err):
+		return nil, rulestore.ErrRuleExists
+	case errors.Is(err, pgx.ErrNoRows):
+		// Either the rule is gone or its version moved on
+		if _, err := s.Get(ctx, rule.RuleID); err != nil {
+			return nil, err
+		}
+		return nil, rulestore.ErrConflict
+	}
+	return updated, err
+}
+
+// Delete removes a rule
+func (s *service) Delete(ctx context.Context, ruleID string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM validation_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return rulestore.ErrRuleNotFound
+	}
+	return nil
+}
+
+// Helper methods
+
+// scanRule reads a row of ruleColumns
+func scanRule(row pgx.Row) (*rulestore.Rule, error) {
+	var rule rulestore.Rule
+	var ruleType string
+	var metadata, parameters []byte
+	err := row.Scan(&rule.RuleID, &rule.Name, &rule.Description, &ruleType, &rule.Field, &rule.Message, &rule.Enabled, &rule.Priority,
+		&metadata, &parameters, &rule.Version, &rule.CreatedAt, &rule.UpdatedAt, &rule.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	rule.Type = validationrule.ValidationRuleType(ruleType)
+	if err := json.Unmarshal(metadata, &rule.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of rule %s: %w", rule.RuleID, err)
+	}
+	if err := json.Unmarshal(parameters, &rule.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to decode parameters of rule %s: %w", rule.RuleID, err)
+	}
+	return &rule, nil
+}
+
+// encodeMaps encodes a rule's metadata and parameters as JSON objects
+func encodeMaps(rule rulestore.Rule) ([]byte, []byte, error) {
+	metadata, parameters := []byte("{}"), []byte("{}")
+	var err error
+	if rule.Metadata != nil {
+		if metadata, err = json.Marshal(rule.Metadata); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode metadata: %w", err)
+		}
+	}
+	if rule.Parameters != nil {
+		if parameters, err = json.Marshal(rule.Parameters); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode parameters: %w", err)
+		}
+	}
+	return metadata, parameters, nil
+}
+
+// isUniqueViolation reports whether err is a duplicate key error
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,70 @@
+package rulestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// Service defines the rule store domain interface - the ONLY interface in this domain.
+// It keeps the validation rule configurations administrators manage at
+// runtime, so every instance sharing the store validates with the same rules.
+type Service interface {
+	// List returns every stored rule, by priority then rule ID
+	List(ctx context.Context) ([]Rule, error)
+
+	// Get returns a rule, or ErrRuleNotFound
+	Get(ctx context.Context, ruleID string) (*Rule, error)
+
+	// Create stores a new rule at version 1; ErrRuleExists when its rule ID
+	// or name is taken
+	Create(ctx context.Context, rule Rule) (*Rule, error)
+
+	// Update replaces the configuration of a rule and bumps its version. A
+	// non-zero rule.Version must match the stored version, otherwise the
+	// rule changed since it was read and ErrConflict is returned.
+	Update(ctx context.Context, rule Rule) (*Rule, error)
+
+	// Delete removes a rule, or returns ErrRuleNotFound
+	Delete(ctx context.Context, ruleID string) error
+}
+
+// Domain types and data structures
+
+// Rule is a stored validation rule configuration with its version and who
+// changed it last
+type Rule struct {
+	validationrule.ValidationRuleConfig
+
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// Configs returns the configurations of the rules
+func Configs(rules []Rule) []validationrule.ValidationRuleConfig {
+	configs := make([]validationrule.ValidationRuleConfig, len(rules))
+	for i, rule := range rules {
+		configs[i] = rule.ValidationRuleConfig
+	}
+	return configs
+}
+
+// RuleStoreError represents rule store domain errors
+type RuleStoreError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e RuleStoreError) Error() string {
+	return e.Message
+}
+
+// Common rule store errors
+var (
+	ErrRuleNotFound = RuleStoreError{Code: "VALIDATION_RULE_NOT_FOUND", Message: "Validation rule not found"}
+	ErrRuleExists   = RuleStoreError{Code: "VALIDATION_RULE_EXISTS", Message: "A validation rule with this ID or name already exists"}
+	ErrConflict     = RuleStoreError{Code: "VALIDATION_RULE_CONFLICT", Message: "Validation rule was changed since it was read"}
+)
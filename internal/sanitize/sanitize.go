@@ -0,0 +1,87 @@
+package sanitize
+
+import "context"
+
+// Service defines the sanitization domain interface - the ONLY interface in this domain
+type Service interface {
+	// Sanitize cleans the fields of the struct data points to in place with
+	// the sanitizers registered for them, and reports the fields it changed
+	Sanitize(ctx context.Context, data interface{}) (Report, error)
+
+	// SanitizeField cleans a single value with the sanitizers registered for
+	// the field
+	SanitizeField(ctx context.Context, field, value string) (string, Report)
+
+	// Register adds sanitizers to a field, named by its JSON name or a
+	// dotted path of JSON names for nested fields. They run in the order
+	// registered.
+	Register(field string, sanitizers ...Sanitizer) error
+}
+
+// Domain types and data structures
+
+// Sanitizer rewrites a string value into its clean form. Sanitizers must be
+// idempotent: cleaning a clean value leaves it unchanged.
+type Sanitizer struct {
+	Name  string
+	Apply func(value string) string
+}
+
+// Change records that sanitizers rewrote a field. Values are left out so
+// reports can be audited without holding personal data.
+type Change struct {
+	Field      string   `json:"field"`
+	Sanitizers []string `json:"sanitizers"` // The sanitizers that changed the value, in order
+}
+
+// Report lists the changes sanitization made
+type Report struct {
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Changed reports whether sanitization changed anything
+func (r Report) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// Merge adds the changes of another report
+func (r *Report) Merge(other Report) {
+	r.Changes = append(r.Changes, other.Changes...)
+}
+
+// Context key the report of a request's sanitization is carried under
+type reportKey struct{}
+
+// WithReport returns a context carrying the report, merged into any report it
+// already carries, so layers below sanitization can record what it changed
+func WithReport(ctx context.Context, report Report) context.Context {
+	if !report.Changed() {
+		return ctx
+	}
+	merged, _ := ReportFromContext(ctx)
+	merged.Changes = append(append([]Change(nil), merged.Changes...), report.Changes...)
+	return context.WithValue(ctx, reportKey{}, merged)
+}
+
+// ReportFromContext returns the sanitization report the context carries
+func ReportFromContext(ctx context.Context) (Report, bool) {
+	report, ok := ctx.Value(reportKey{}).(Report)
+	return report, ok
+}
+
+// SanitizeError represents a sanitization error
+type SanitizeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e SanitizeError) Error() string {
+	return e.Message
+}
+
+// Common sanitization errors. Sanitize returns ErrUnsupportedData for
+// anything but a pointer to a struct.
+var (
+	ErrUnsupportedData = SanitizeError{Code: "UNSUPPORTED_DATA", Message: "only pointers to structs can be sanitized"}
+	ErrInvalidField    = SanitizeError{Code: "INVALID_FIELD", Message: "sanitizers need a field and a name and function each"}
+)
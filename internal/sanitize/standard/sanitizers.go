@@ -0,0 +1,106 @@
+package standard
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+)
+
+// Built-in sanitizers
+var (
+	// TrimSpace removes leading and trailing white space
+	TrimSpace = sanitize.Sanitizer{Name: "trim_space", Apply: strings.TrimSpace}
+
+	// CollapseSpace replaces runs of white space with a single space
+	CollapseSpace = sanitize.Sanitizer{Name: "collapse_space", Apply: collapseSpace}
+
+	// StripControl removes control and invisible formatting characters, such
+	// as NUL, line breaks and bidirectional overrides. The zero-width
+	// joiners some scripts spell words with are kept.
+	StripControl = sanitize.Sanitizer{Name: "strip_control", Apply: stripControl}
+
+	// NormalizeEmail trims an email address and brings it to Unicode NFC, so
+	// addresses typed with composed and decomposed accents are the same
+	NormalizeEmail = sanitize.Sanitizer{Name: "normalize_email", Apply: normalizeEmail}
+
+	// CanonicalTimezone rewrites IANA time zone names to their canonical
+	// spelling, e.g. "america/new york" to "America/New_York"; names that
+	// are not time zones are left for validation to reject
+	CanonicalTimezone = sanitize.Sanitizer{Name: "canonical_timezone", Apply: canonicalTimezone}
+)
+
+// UserFields returns the sanitizers of the fields of user data: registration,
+// profile updates and preferences. Passwords are never sanitized, so they
+// are checked and hashed exactly as typed.
+func UserFields() map[string][]sanitize.Sanitizer {
+	name := []sanitize.Sanitizer{StripControl, TrimSpace, CollapseSpace}
+	return map[string][]sanitize.Sanitizer{
+		"email":      {StripControl, NormalizeEmail},
+		"first_name": name,
+		"last_name":  name,
+		"language":   {StripControl, TrimSpace},
+		"timezone":   {StripControl, TrimSpace, CanonicalTimezone},
+	}
+}
+
+func collapseSpace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func stripControl(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\u200c' || r == '\u200d' {
+			return r
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func normalizeEmail(value string) string {
+	return norm.NFC.String(strings.TrimSpace(value))
+}
+
+func canonicalTimezone(value string) string {
+	name := strings.TrimSpace(value)
+	if name == "" || strings.EqualFold(name, "Local") {
+		return value
+	}
+	if _, err := time.LoadLocation(name); err == nil {
+		return name
+	}
+
+	// Try the usual capitalization of zone names: each word capitalized and
+	// separated by underscores, and abbreviations such as UTC upper-cased
+	candidates := []string{titleZone(name), strings.ToUpper(name)}
+	for _, candidate := range candidates {
+		if _, err := time.LoadLocation(candidate); err == nil {
+			return candidate
+		}
+	}
+	return value
+}
+
+// titleZone capitalizes the words of a zone name, separating them with
+// underscores
+func titleZone(name string) string {
+	words := strings.FieldsFunc(strings.ReplaceAll(name, " ", "_"), func(r rune) bool { return r == '_' })
+	name = strings.Join(words, "_")
+
+	var b strings.Builder
+	capitalize := true
+	for _, r := range strings.ToLower(name) {
+		if capitalize {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+		capitalize = r == '/' || r == '_' || r == '-'
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package standard
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+)
+
+// service implements sanitize.Service interface by rewriting the string
+// fields of structs, found by their JSON names, with the sanitizers
+// registered for them. Fields held through pointers are given new values
+// rather than rewritten in place, so callers sharing them are unaffected.
+type service struct {
+	mu         sync.RWMutex
+	sanitizers map[string][]sanitize.Sanitizer
+}
+
+// NewService creates a sanitization service with the sanitizers of the
+// fields given, e.g. UserFields()
+func NewService(fields map[string][]sanitize.Sanitizer) sanitize.Service {
+	s := &service{sanitizers: make(map[string][]sanitize.Sanitizer, len(fields))}
+	for field, sanitizers := range fields {
+		s.sanitizers[field] = append([]sanitize.Sanitizer(nil), sanitizers...)
+	}
+	return s
+}
+
+// Sanitize cleans the registered fields of the struct data points to. Fields
+// the struct does not have, or leaves unset through a nil pointer, are
+// skipped. Changes are reported in field order.
+func (s *service) Sanitize(ctx context.Context, data interface{}) (sanitize.Report, error) {
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return sanitize.Report{}, sanitize.ErrUnsupportedData
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fields := make([]string, 0, len(s.sanitizers))
+	for field := range s.sanitizers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var report sanitize.Report
+	for _, field := range fields {
+		target, ok := settableField(value.Elem(), field)
+		if !ok {
+			continue
+		}
+		cleaned, applied := apply(s.sanitizers[field], target.String())
+		if len(applied) == 0 {
+			continue
+		}
+		target.SetString(cleaned)
+		report.Changes = append(report.Changes, sanitize.Change{Field: field, Sanitizers: applied})
+	}
+	return report, nil
+}
+
+// SanitizeField cleans a value with the sanitizers of the field
+func (s *service) SanitizeField(ctx context.Context, field, value string) (string, sanitize.Report) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cleaned, applied := apply(s.sanitizers[field], value)
+	if len(applied) == 0 {
+		return value, sanitize.Report{}
+	}
+	return cleaned, sanitize.Report{Changes: []sanitize.Change{{Field: field, Sanitizers: applied}}}
+}
+
+// Register adds sanitizers to a field
+func (s *service) Register(field string, sanitizers ...sanitize.Sanitizer) error {
+	if field == "" || len(sanitizers) == 0 {
+		return sanitize.ErrInvalidField
+	}
+	for _, sanitizer := range sanitizers {
+		if sanitizer.Name == "" || sanitizer.Apply == nil {
+			return sanitize.ErrInvalidField
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sanitizers[field] = append(s.sanitizers[field], sanitizers...)
+	return nil
+}
+
+// apply runs the sanitizers over a value, returning the clean value and the
+// names of the sanitizers that changed it
+func apply(sanitizers []sanitize.Sanitizer, value string) (string, []string) {
+	var applied []string
+	for _, sanitizer := range sanitizers {
+		if cleaned := sanitizer.Apply(value); cleaned != value {
+			value = cleaned
+			applied = append(applied, sanitizer.Name)
+		}
+	}
+	return value, applied
+}
+
+// settableField finds the string field at a dotted path of JSON names. A
+// field held through a pointer is pointed at a copy of its value, which is
+// returned instead.
+func settableField(current reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for current.Kind() == reflect.Pointer {
+			if current.IsNil() {
+				return reflect.Value{}, false
+			}
+			detached := reflect.New(current.Type().Elem())
+			detached.Elem().Set(current.Elem())
+			current.Set(detached)
+			current = detached.Elem()
+		}
+		if current.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		field, ok := structField(current, name)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		current = field
+	}
+
+	if current.Kind() == reflect.Pointer {
+		if current.IsNil() || current.Type().Elem().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		detached := reflect.New(current.Type().Elem())
+		detached.Elem().Set(current.Elem())
+		current.Set(detached)
+		current = detached.Elem()
+	}
+	if current.Kind() != reflect.String || !current.CanSet() {
+		return reflect.Value{}, false
+	}
+	return current, true
+}
+
+// structField returns the exported field of a struct named name in JSON
+func structField(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if jsonName == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,113 @@
+package standard_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+	"github.com/gentra/decorator-arch-go/internal/sanitize/standard"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+func TestSanitizers_GivenDirtyValues_WhenApplied_ThenReturnsCleanValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		sanitizer sanitize.Sanitizer
+		value     string
+		expected  string
+	}{
+		{name: "Given padded text, When trimming, Then removes the padding", sanitizer: standard.TrimSpace, value: "  Jane \t", expected: "Jane"},
+		{name: "Given runs of spaces, When collapsing, Then keeps single spaces", sanitizer: standard.CollapseSpace, value: "Mary   Ann\tLee", expected: "Mary Ann Lee"},
+		{name: "Given control and bidi characters, When stripping, Then removes them", sanitizer: standard.StripControl, value: "Ja\x00ne\n\u202e", expected: "Jane"},
+		{name: "Given a zero-width non-joiner, When stripping, Then keeps it", sanitizer: standard.StripControl, value: "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", expected: "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645"},
+		{name: "Given a decomposed accent, When normalizing an email, Then composes it", sanitizer: standard.NormalizeEmail, value: " jose\u0301@example.com ", expected: "jos\u00e9@example.com"},
+		{name: "Given a lower-cased zone with a space, When canonicalizing, Then spells it canonically", sanitizer: standard.CanonicalTimezone, value: "america/new york", expected: "America/New_York"},
+		{name: "Given a lower-cased abbreviation, When canonicalizing, Then upper-cases it", sanitizer: standard.CanonicalTimezone, value: "utc", expected: "UTC"},
+		{name: "Given a canonical zone, When canonicalizing, Then leaves it", sanitizer: standard.CanonicalTimezone, value: "Europe/Paris", expected: "Europe/Paris"},
+		{name: "Given an unknown zone, When canonicalizing, Then leaves it for validation", sanitizer: standard.CanonicalTimezone, value: "mars/olympus", expected: "mars/olympus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := tt.sanitizer.Apply(tt.value)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, result, tt.sanitizer.Apply(result))
+		})
+	}
+}
+
+func TestSanitize_GivenUserFields_WhenSanitizing_ThenCleansInPlaceAndReportsChanges(t *testing.T) {
+	// Arrange
+	service := standard.NewService(standard.UserFields())
+	data := user.RegisterData{Email: " jane@example.com", Password: " keep me ", FirstName: "Mary  Ann", LastName: "Doe"}
+
+	// Act
+	report, err := service.Sanitize(context.Background(), &data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, user.RegisterData{Email: "jane@example.com", Password: " keep me ", FirstName: "Mary Ann", LastName: "Doe"}, data)
+	assert.Equal(t, sanitize.Report{Changes: []sanitize.Change{
+		{Field: "email", Sanitizers: []string{"normalize_email"}},
+		{Field: "first_name", Sanitizers: []string{"collapse_space"}},
+	}}, report)
+}
+
+func TestSanitize_GivenFieldsThroughPointers_WhenSanitizing_ThenLeavesCallersValuesAlone(t *testing.T) {
+	// Arrange
+	service := standard.NewService(standard.UserFields())
+	firstName := " Jane "
+	data := user.UpdateProfileData{FirstName: &firstName}
+
+	// Act
+	report, err := service.Sanitize(context.Background(), &data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", *data.FirstName)
+	assert.Equal(t, " Jane ", firstName)
+	assert.Nil(t, data.LastName)
+	assert.True(t, report.Changed())
+}
+
+func TestSanitize_GivenNonPointer_WhenSanitizing_ThenReturnsUnsupportedData(t *testing.T) {
+	// Arrange
+	service := standard.NewService(standard.UserFields())
+
+	// Act
+	_, err := service.Sanitize(context.Background(), user.RegisterData{})
+
+	// Assert
+	assert.Equal(t, sanitize.ErrUnsupportedData, err)
+}
+
+func TestRegister_GivenSanitizers_WhenSanitizingField_ThenAppliesThemInOrder(t *testing.T) {
+	// Arrange
+	service := standard.NewService(nil)
+	upper := sanitize.Sanitizer{Name: "upper", Apply: func(value string) string {
+		if value == "ab" {
+			return "AB"
+		}
+		return value
+	}}
+
+	// Act
+	registerErr := service.Register("code", standard.TrimSpace, upper)
+	invalidErr := service.Register("code", sanitize.Sanitizer{Name: "nothing"})
+	value, report := service.SanitizeField(context.Background(), "code", " ab ")
+	untouched, emptyReport := service.SanitizeField(context.Background(), "other", " ab ")
+
+	// Assert
+	require.NoError(t, registerErr)
+	assert.Equal(t, sanitize.ErrInvalidField, invalidErr)
+	assert.Equal(t, "AB", value)
+	assert.Equal(t, sanitize.Report{Changes: []sanitize.Change{{Field: "code", Sanitizers: []string{"trim_space", "upper"}}}}, report)
+	assert.Equal(t, " ab ", untouched)
+	assert.False(t, emptyReport.Changed())
+}
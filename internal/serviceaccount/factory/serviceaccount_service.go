@@ -0,0 +1,77 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount/memory"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount/postgres"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// Config contains all configuration for building the service account service
+type Config struct {
+	// Provider configuration
+	Provider string // "memory", "postgres"
+
+	// Pool is the connection pool of the postgres provider
+	Pool *pgxpool.Pool
+
+	// Domain services
+	TokenService token.Service
+	AuditService audit.Service
+}
+
+// ServiceAccountServiceFactory creates and assembles the service account service
+type ServiceAccountServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new service account service factory with the given configuration
+func NewFactory(config Config) *ServiceAccountServiceFactory {
+	return &ServiceAccountServiceFactory{
+		config: config,
+	}
+}
+
+// Build assembles and returns the service account service based on configuration
+func (f *ServiceAccountServiceFactory) Build() (serviceaccount.Service, error) {
+	if f.config.TokenService == nil {
+		return nil, fmt.Errorf("token service is required")
+	}
+
+	switch f.config.Provider {
+	case "memory":
+		return f.buildMemoryService()
+	case "postgres":
+		return f.buildPostgresService()
+	default:
+		// Default to memory provider
+		return f.buildMemoryService()
+	}
+}
+
+// buildMemoryService creates an in-memory service account service
+func (f *ServiceAccountServiceFactory) buildMemoryService() (serviceaccount.Service, error) {
+	return memory.NewService(f.config.TokenService, f.config.AuditService), nil
+}
+
+// buildPostgresService creates a service account service stored in Postgres
+func (f *ServiceAccountServiceFactory) buildPostgresService() (serviceaccount.Service, error) {
+	if f.config.Pool == nil {
+		return nil, fmt.Errorf("postgres connection pool is required")
+	}
+	return postgres.NewService(f.config.Pool, f.config.TokenService, f.config.AuditService), nil
+}
+
+// DefaultConfig returns a sensible default configuration for the service account service
+func DefaultConfig(tokenService token.Service, auditService audit.Service) Config {
+	return Config{
+		Provider:     "memory",
+		TokenService: tokenService,
+		AuditService: auditService,
+	}
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// service implements serviceaccount.Service interface using in-memory storage
+type service struct {
+	accounts     map[string]*record
+	mu           sync.RWMutex
+	tokenService token.Service
+	auditService audit.Service
+}
+
+// record is a stored service account with its hashed secret
+type record struct {
+	account    serviceaccount.ServiceAccount
+	secretHash [sha256.Size]byte
+}
+
+// NewService creates a new in-memory service account service
+func NewService(tokenService token.Service, auditService audit.Service) serviceaccount.Service {
+	return &service{
+		accounts:     make(map[string]*record),
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// Create creates a service account with a subset of the creator's scopes
+func (s *service) Create(ctx context.Context, req serviceaccount.CreateRequest) (*serviceaccount.Credentials, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := serviceaccount.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	account := serviceaccount.ServiceAccount{
+		ID:              uuid.New().String(),
+		OrganizationID:  req.OrganizationID,
+		Name:            req.Name,
+		Description:     req.Description,
+		Scopes:          append([]string(nil), req.Scopes...),
+		CreatedBy:       req.CreatedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		SecretRotatedAt: now,
+	}
+
+	s.mu.Lock()
+	s.accounts[account.ID] = &record{account: account, secretHash: hash}
+	s.mu.Unlock()
+
+	s.logAudit(ctx, "service_account.create", account, nil, map[string]interface{}{
+		"organization_id": account.OrganizationID,
+		"scopes":          account.Scopes,
+	})
+
+	return &serviceaccount.Credentials{Account: account, Secret: secret}, nil
+}
+
+// Get returns a service account by ID
+func (s *service) Get(ctx context.Context, id string) (*serviceaccount.ServiceAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, exists := s.accounts[id]
+	if !exists {
+		return nil, serviceaccount.ErrNotFound
+	}
+
+	account := rec.account
+	return &account, nil
+}
+
+// ListByOrganization returns the organization's service accounts ordered by creation time
+func (s *service) ListByOrganization(ctx context.Context, organizationID string) ([]serviceaccount.ServiceAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var accounts []serviceaccount.ServiceAccount
+	for _, rec := range s.accounts {
+		if rec.account.OrganizationID == organizationID {
+			accounts = append(accounts, rec.account)
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].CreatedAt.Before(accounts[j].CreatedAt)
+	})
+
+	return accounts, nil
+}
+
+// RotateSecret replaces the account secret; the previous secret stops working immediately
+func (s *service) RotateSecret(ctx context.Context, id string) (*serviceaccount.Credentials, error) {
+	secret, hash, err := serviceaccount.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	rec, exists := s.accounts[id]
+	if !exists {
+		s.mu.Unlock()
+		return nil, serviceaccount.ErrNotFound
+	}
+	if rec.account.Disabled {
+		s.mu.Unlock()
+		return nil, serviceaccount.ErrDisabled
+	}
+
+	now := time.Now()
+	rec.secretHash = hash
+	rec.account.SecretRotatedAt = now
+	rec.account.UpdatedAt = now
+	account := rec.account
+	s.mu.Unlock()
+
+	s.logAudit(ctx, "service_account.rotate_secret", account, nil, nil)
+
+	return &serviceaccount.Credentials{Account: account, Secret: secret}, nil
+}
+
+// Disable disables the account and revokes its tokens
+func (s *service) Disable(ctx context.Context, id string) error {
+	s.mu.Lock()
+	rec, exists := s.accounts[id]
+	if !exists {
+		s.mu.Unlock()
+		return serviceaccount.ErrNotFound
+	}
+
+	now := time.Now()
+	rec.account.Disabled = true
+	rec.account.DisabledAt = &now
+	rec.account.UpdatedAt = now
+	account := rec.account
+	s.mu.Unlock()
+
+	err := s.tokenService.RevokeAllTokensForUser(ctx, account.Principal())
+	s.logAudit(ctx, "service_account.disable", account, err, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke service account tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IssueToken authenticates the account and issues an API token limited to the
+// requested scopes, or all granted scopes when none are requested
+func (s *service) IssueToken(ctx context.Context, id, secret string, scopes []string) (*token.APIToken, error) {
+	s.mu.RLock()
+	rec, exists := s.accounts[id]
+	var account serviceaccount.ServiceAccount
+	var valid bool
+	if exists {
+		account = rec.account
+		hash := serviceaccount.HashSecret(secret)
+		valid = subtle.ConstantTimeCompare(hash[:], rec.secretHash[:]) == 1
+	}
+	s.mu.RUnlock()
+
+	if !exists || !valid {
+		return nil, serviceaccount.ErrInvalidSecret
+	}
+	if account.Disabled {
+		return nil, serviceaccount.ErrDisabled
+	}
+
+	scopes, err := account.TokenScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = serviceaccount.TokenContext(ctx, account)
+
+	apiToken, err := s.tokenService.GenerateAPIToken(ctx, account.Principal(), scopes)
+	s.logAudit(ctx, "service_account.issue_token", account, err, map[string]interface{}{
+		"scopes": scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken.Name = account.Name
+	return apiToken, nil
+}
+
+// logAudit records a lifecycle event of the account
+func (s *service) logAudit(ctx context.Context, action string, account serviceaccount.ServiceAccount, err error, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := serviceaccount.NewAuditEntry(ctx, action, account, err, details)
+	if logErr := s.auditService.Log(ctx, entry); logErr != nil {
+		log.Printf("Failed to audit %s for service account %s: %v", action, account.ID, logErr)
+	}
+}
@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount/memory"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount/serviceaccounttest"
+)
+
+func TestMemoryService_Conformance(t *testing.T) {
+	serviceaccounttest.RunServiceConformance(t, memory.NewService)
+}
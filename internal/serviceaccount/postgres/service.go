@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// accountColumns are the service_accounts columns scanAccount reads, in order
+const accountColumns = `id, organization_id, name, description, scopes, created_by, created_at, updated_at,
+	secret_rotated_at, disabled_at`
+
+// service implements serviceaccount.Service on the service_accounts table,
+// so accounts survive restarts and every instance authenticates the same ones
+type service struct {
+	pool         *pgxpool.Pool
+	tokenService token.Service
+	auditService audit.Service
+}
+
+// NewService creates a Postgres-backed service account service
+func NewService(pool *pgxpool.Pool, tokenService token.Service, auditService audit.Service) serviceaccount.Service {
+	return &service{
+		pool:         pool,
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// Create creates a service account with a subset of the creator's scopes
+func (s *service) Create(ctx context.Context, req serviceaccount.CreateRequest) (*serviceaccount.Credentials, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := serviceaccount.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scopes := append([]string{}, req.Scopes...)
+	account, err := scanAccount(s.pool.QueryRow(ctx, `
+		INSERT INTO service_accounts (id, organization_id, name, description, scopes, secret_hash, created_by,
+			created_at, updated_at, secret_rotated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $8)
+		RETURNING `+accountColumns,
+		uuid.New().String(), req.OrganizationID, req.Name, req.Description, scopes, hash[:], req.CreatedBy, now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	s.logAudit(ctx, "service_account.create", *account, nil, map[string]interface{}{
+		"organization_id": account.OrganizationID,
+		"scopes":          account.Scopes,
+	})
+
+	return &serviceaccount.Credentials{Account: *account, Secret: secret}, nil
+}
+
+// Get returns a service account by ID
+func (s *service) Get(ctx context.Context, id string) (*serviceaccount.ServiceAccount, error) {
+	account, err := scanAccount(s.pool.QueryRow(ctx, `SELECT `+accountColumns+` FROM service_accounts WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, serviceaccount.ErrNotFound
+	}
+	return account, err
+}
+
+// ListByOrganization returns the organization's service accounts ordered by creation time
+func (s *service) ListByOrganization(ctx context.Context, organizationID string) ([]serviceaccount.ServiceAccount, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+accountColumns+`
+		FROM service_accounts
+		WHERE organization_id = $1
+		ORDER BY created_at, id`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []serviceaccount.ServiceAccount
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, rows.Err()
+}
+
+// RotateSecret replaces the account secret; the previous secret stops working immediately
+func (s *service) RotateSecret(ctx context.Context, id string) (*serviceaccount.Credentials, error) {
+	secret, hash, err := serviceaccount.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := scanAccount(s.pool.QueryRow(ctx, `
+		UPDATE service_accounts
+		SET secret_hash = $2, secret_rotated_at = $3, updated_at = $3
+		WHERE id = $1 AND disabled_at IS NULL
+		RETURNING `+accountColumns,
+		id, hash[:], time.Now()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.unchangedError(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logAudit(ctx, "service_account.rotate_secret", *account, nil, nil)
+
+	return &serviceaccount.Credentials{Account: *account, Secret: secret}, nil
+}
+
+// Disable disables the account and revokes its tokens
+func (s *service) Disable(ctx context.Context, id string) error {
+	now := time.Now()
+	account, err := scanAccount(s.pool.QueryRow(ctx, `
+		UPDATE service_accounts
+		SET disabled_at = $2, updated_at = $2
+		WHERE id = $1
+		RETURNING `+accountColumns,
+		id, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return serviceaccount.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenService.RevokeAllTokensForUser(ctx, account.Principal())
+	s.logAudit(ctx, "service_account.disable", *account, err, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke service account tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IssueToken authenticates the account and issues an API token limited to the
+// requested scopes, or all granted scopes when none are requested
+func (s *service) IssueToken(ctx context.Context, id, secret string, scopes []string) (*token.APIToken, error) {
+	var storedHash []byte
+	account, err := scanAccount(s.pool.QueryRow(ctx, `
+		SELECT `+accountColumns+`, secret_hash
+		FROM service_accounts
+		WHERE id = $1`, id), &storedHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, serviceaccount.ErrInvalidSecret
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash := serviceaccount.HashSecret(secret)
+	if subtle.ConstantTimeCompare(hash[:], storedHash) != 1 {
+		return nil, serviceaccount.ErrInvalidSecret
+	}
+	if account.Disabled {
+		return nil, serviceaccount.ErrDisabled
+	}
+
+	scopes, err = account.TokenScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = serviceaccount.TokenContext(ctx, *account)
+
+	apiToken, err := s.tokenService.GenerateAPIToken(ctx, account.Principal(), scopes)
+	s.logAudit(ctx, "service_account.issue_token", *account, err, map[string]interface{}{
+		"scopes": scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken.Name = account.Name
+	return apiToken, nil
+}
+
+// Helper methods
+
+// unchangedError explains why an update of an enabled account matched no row
+func (s *service) unchangedError(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return serviceaccount.ErrDisabled
+}
+
+// logAudit records a lifecycle event of the account
+func (s *service) logAudit(ctx context.Context, action string, account serviceaccount.ServiceAccount, err error, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := serviceaccount.NewAuditEntry(ctx, action, account, err, details)
+	if logErr := s.auditService.Log(ctx, entry); logErr != nil {
+		log.Printf("Failed to audit %s for service account %s: %v", action, account.ID, logErr)
+	}
+}
+
+// scanAccount reads the accountColumns of a row, followed by extra columns
+func scanAccount(row pgx.Row, extra ...any) (*serviceaccount.ServiceAccount, error) {
+	var account serviceaccount.ServiceAccount
+	dest := append([]any{
+		&account.ID, &account.OrganizationID, &account.Name, &account.Description, &account.Scopes,
+		&account.CreatedBy, &account.CreatedAt, &account.UpdatedAt, &account.SecretRotatedAt, &account.DisabledAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	account.Disabled = account.DisabledAt != nil
+	return &account, nil
+}
@@ -0,0 +1,215 @@
+package serviceaccount
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// Service defines the service account domain interface - the ONLY interface in this domain
+// Service accounts are non-human identities owned by an organization. They hold a
+// delegated subset of their creator's scopes and authenticate with a secret.
+type Service interface {
+	// Lifecycle management
+	Create(ctx context.Context, req CreateRequest) (*Credentials, error)
+	Get(ctx context.Context, id string) (*ServiceAccount, error)
+	ListByOrganization(ctx context.Context, organizationID string) ([]ServiceAccount, error)
+	RotateSecret(ctx context.Context, id string) (*Credentials, error)
+	Disable(ctx context.Context, id string) error
+
+	// Token issuance restricted to the account's granted scopes
+	IssueToken(ctx context.Context, id, secret string, scopes []string) (*token.APIToken, error)
+}
+
+// Domain types and data structures
+
+// ServiceAccount represents a non-human identity owned by an organization
+type ServiceAccount struct {
+	ID              string     `json:"id"`
+	OrganizationID  string     `json:"organization_id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description,omitempty"`
+	Scopes          []string   `json:"scopes"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	SecretRotatedAt time.Time  `json:"secret_rotated_at"`
+	Disabled        bool       `json:"disabled"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+}
+
+// CreateRequest contains data for creating a service account
+type CreateRequest struct {
+	OrganizationID string   `json:"organization_id"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Scopes         []string `json:"scopes"`
+
+	// CreatedBy is the user delegating permissions; CreatorScopes are the
+	// permissions that user or organization holds. Scopes must be a subset.
+	CreatedBy     string   `json:"created_by"`
+	CreatorScopes []string `json:"-"`
+}
+
+// Credentials is returned when a secret is created or rotated. The secret is
+// only ever returned here; the service stores a hash.
+type Credentials struct {
+	Account ServiceAccount `json:"account"`
+	Secret  string         `json:"secret"`
+}
+
+// PrincipalPrefix marks token subjects and audit identities that are service accounts
+const PrincipalPrefix = "service_account:"
+
+// Principal returns the identity used in tokens and audit entries for the account
+func (a *ServiceAccount) Principal() string {
+	return PrincipalPrefix + a.ID
+}
+
+// HasScope reports whether the account was granted the scope
+func (a *ServiceAccount) HasScope(scope string) bool {
+	return ScopeAllowed(a.Scopes, scope)
+}
+
+// TokenScopes returns the scopes a token requested with scopes is issued
+// for: all granted scopes when none are requested, or ErrScopeNotGranted
+// when one of them was not granted
+func (a *ServiceAccount) TokenScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return a.Scopes, nil
+	}
+	for _, scope := range scopes {
+		if !a.HasScope(scope) {
+			err := ErrScopeNotGranted
+			err.Message = fmt.Sprintf("%s: %s", err.Message, scope)
+			return nil, err
+		}
+	}
+	return scopes, nil
+}
+
+// Validate checks the request names an organization and the account, and
+// only delegates scopes the creator holds
+func (r CreateRequest) Validate() error {
+	if strings.TrimSpace(r.OrganizationID) == "" {
+		return ErrInvalidOrg
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrInvalidName
+	}
+	for _, scope := range r.Scopes {
+		if !ScopeAllowed(r.CreatorScopes, scope) {
+			err := ErrScopeNotDelegable
+			err.Message = fmt.Sprintf("%s: %s", err.Message, scope)
+			return err
+		}
+	}
+	return nil
+}
+
+// secretSize is the number of random bytes in a secret
+const secretSize = 32
+
+// GenerateSecret returns a new random secret and the hash stores keep of it
+func GenerateSecret() (string, [sha256.Size]byte, error) {
+	raw := make([]byte, secretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", [sha256.Size]byte{}, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+	return secret, HashSecret(secret), nil
+}
+
+// HashSecret returns the hash a secret is stored and compared as
+func HashSecret(secret string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(secret))
+}
+
+// TokenContext adds the claims identifying the account to the API tokens
+// issued with ctx
+func TokenContext(ctx context.Context, account ServiceAccount) context.Context {
+	return token.WithExtraClaims(ctx, map[string]interface{}{
+		"principal_type":  "service_account",
+		"organization_id": account.OrganizationID,
+	})
+}
+
+// NewAuditEntry describes a lifecycle event of the account. Token issuance
+// is attributed to the service account itself; management operations to
+// the calling user.
+func NewAuditEntry(ctx context.Context, action string, account ServiceAccount, err error, details map[string]interface{}) audit.AuditEntry {
+	auditCtx := audit.ExtractAuditContext(ctx)
+	actor := auditCtx.CurrentUserID
+	if action == "service_account.issue_token" || actor == "" {
+		actor = account.Principal()
+	}
+
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        actor,
+		Action:        action,
+		Resource:      "service_account",
+		ResourceID:    account.ID,
+		Details:       details,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+	if err != nil {
+		entry.SetError(err)
+	} else {
+		entry.SetSuccess()
+	}
+	return entry
+}
+
+// ScopeAllowed reports whether a scope is covered by the granted scopes.
+// "*" grants everything and "resource:*" grants every action on a resource.
+func ScopeAllowed(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == "*" || g == scope {
+			return true
+		}
+		if len(g) > 1 && g[len(g)-1] == '*' && len(scope) >= len(g)-1 && scope[:len(g)-1] == g[:len(g)-1] {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceAccountError represents domain-specific service account errors
+type ServiceAccountError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+func (e ServiceAccountError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code so detailed messages still match the sentinels
+func (e ServiceAccountError) Is(target error) bool {
+	t, ok := target.(ServiceAccountError)
+	return ok && t.Code == e.Code
+}
+
+// Common service account error codes
+var (
+	ErrNotFound          = ServiceAccountError{Code: "SERVICE_ACCOUNT_NOT_FOUND", Message: "Service account not found"}
+	ErrDisabled          = ServiceAccountError{Code: "SERVICE_ACCOUNT_DISABLED", Message: "Service account is disabled"}
+	ErrInvalidSecret     = ServiceAccountError{Code: "INVALID_SERVICE_ACCOUNT_SECRET", Message: "Invalid service account credentials"}
+	ErrInvalidName       = ServiceAccountError{Code: "INVALID_NAME", Message: "Service account name is required", Field: "name"}
+	ErrInvalidOrg        = ServiceAccountError{Code: "INVALID_ORGANIZATION", Message: "Organization is required", Field: "organization_id"}
+	ErrScopeNotDelegable = ServiceAccountError{Code: "SCOPE_NOT_DELEGABLE", Message: "Scope exceeds the creator's permissions", Field: "scopes"}
+	ErrScopeNotGranted   = ServiceAccountError{Code: "SCOPE_NOT_GRANTED", Message: "Scope not granted to the service account", Field: "scopes"}
+)
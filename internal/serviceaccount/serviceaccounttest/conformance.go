@@ -0,0 +1,195 @@
+package serviceaccounttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	"github.com/gentra/decorator-arch-go/internal/serviceaccount"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// NewServiceFunc creates the service account service under test
+type NewServiceFunc func(tokenService token.Service, auditService audit.Service) serviceaccount.Service
+
+// RunServiceConformance checks that a serviceaccount.Service behaves like
+// the contract the REST API relies on. newService is called once per
+// subtest; the services may share a database, as every subtest creates its
+// accounts in new organizations. Stores run the same suite so they stay
+// interchangeable.
+func RunServiceConformance(t *testing.T, newService NewServiceFunc) {
+	t.Helper()
+
+	t.Run("Create", func(t *testing.T) { testCreate(t, newService) })
+	t.Run("IssueToken", func(t *testing.T) { testIssueToken(t, newService) })
+	t.Run("RotateSecret", func(t *testing.T) { testRotateSecret(t, newService) })
+	t.Run("Disable", func(t *testing.T) { testDisable(t, newService) })
+	t.Run("ListByOrganization", func(t *testing.T) { testListByOrganization(t, newService) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newService) })
+}
+
+func newTestService(t *testing.T, newService NewServiceFunc) (serviceaccount.Service, token.Service, *auditMock.MockAuditService) {
+	t.Helper()
+	tokenService := testkit.NewTokenService(t)
+
+	auditService := &auditMock.MockAuditService{}
+	auditService.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	return newService(tokenService, auditService), tokenService, auditService
+}
+
+// createAccount creates an account in a new organization, so services
+// sharing a database see only their own accounts in it
+func createAccount(t *testing.T, service serviceaccount.Service) *serviceaccount.Credentials {
+	t.Helper()
+	credentials, err := service.Create(context.Background(), serviceaccount.CreateRequest{
+		OrganizationID: "org-" + uuid.NewString(),
+		Name:           "ci-deployer",
+		Scopes:         []string{"projects:read", "projects:deploy"},
+		CreatedBy:      "user-1",
+		CreatorScopes:  []string{"projects:*", "users:read"},
+	})
+	require.NoError(t, err)
+	return credentials
+}
+
+func testCreate(t *testing.T, newService NewServiceFunc) {
+	tests := []struct {
+		name         string
+		req          serviceaccount.CreateRequest
+		expectedCode string
+	}{
+		{
+			name:         "Given scope outside creator permissions, When creating, Then returns not delegable",
+			req:          serviceaccount.CreateRequest{OrganizationID: "org-1", Name: "bot", Scopes: []string{"users:write"}, CreatorScopes: []string{"users:read"}},
+			expectedCode: serviceaccount.ErrScopeNotDelegable.Code,
+		},
+		{
+			name:         "Given missing organization, When creating, Then returns invalid organization",
+			req:          serviceaccount.CreateRequest{Name: "bot"},
+			expectedCode: serviceaccount.ErrInvalidOrg.Code,
+		},
+		{
+			name:         "Given missing name, When creating, Then returns invalid name",
+			req:          serviceaccount.CreateRequest{OrganizationID: "org-1"},
+			expectedCode: serviceaccount.ErrInvalidName.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _ := newTestService(t, newService)
+
+			credentials, err := service.Create(context.Background(), tt.req)
+
+			assert.Nil(t, credentials)
+			var accountErr serviceaccount.ServiceAccountError
+			require.ErrorAs(t, err, &accountErr)
+			assert.Equal(t, tt.expectedCode, accountErr.Code)
+		})
+	}
+}
+
+func testIssueToken(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given valid credentials, When IssueToken is called, Then should scope the token to the service account", func(t *testing.T) {
+		service, tokenService, auditService := newTestService(t, newService)
+		credentials := createAccount(t, service)
+		ctx := context.Background()
+
+		apiToken, err := service.IssueToken(ctx, credentials.Account.ID, credentials.Secret, []string{"projects:read"})
+		require.NoError(t, err)
+
+		claims, err := tokenService.ValidateAPIToken(ctx, apiToken.Token)
+		require.NoError(t, err)
+		assert.Equal(t, credentials.Account.Principal(), claims.UserID)
+		assert.Equal(t, []string{"projects:read"}, claims.Scopes)
+		assert.Equal(t, "service_account", claims.Custom["principal_type"])
+		assert.Equal(t, credentials.Account.OrganizationID, claims.Custom["organization_id"])
+
+		auditService.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+			return entry.Action == "service_account.issue_token" && entry.UserID == credentials.Account.Principal()
+		}))
+
+		_, err = service.IssueToken(ctx, credentials.Account.ID, credentials.Secret, []string{"users:read"})
+		assert.ErrorIs(t, err, serviceaccount.ErrScopeNotGranted)
+	})
+}
+
+func testRotateSecret(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given a rotated secret, When the old secret is used, Then should reject it", func(t *testing.T) {
+		service, _, _ := newTestService(t, newService)
+		credentials := createAccount(t, service)
+		ctx := context.Background()
+
+		rotated, err := service.RotateSecret(ctx, credentials.Account.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, credentials.Secret, rotated.Secret)
+
+		_, err = service.IssueToken(ctx, credentials.Account.ID, credentials.Secret, nil)
+		assert.ErrorIs(t, err, serviceaccount.ErrInvalidSecret)
+
+		_, err = service.IssueToken(ctx, credentials.Account.ID, rotated.Secret, nil)
+		assert.NoError(t, err)
+	})
+}
+
+func testDisable(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given a disabled account, When IssueToken is called, Then should reject it", func(t *testing.T) {
+		service, _, auditService := newTestService(t, newService)
+		credentials := createAccount(t, service)
+		ctx := audit.WithAuditContext(context.Background(), "admin-1", "10.0.0.1", "test", "session-1")
+
+		require.NoError(t, service.Disable(ctx, credentials.Account.ID))
+
+		_, err := service.IssueToken(ctx, credentials.Account.ID, credentials.Secret, nil)
+		assert.ErrorIs(t, err, serviceaccount.ErrDisabled)
+
+		account, err := service.Get(ctx, credentials.Account.ID)
+		require.NoError(t, err)
+		assert.True(t, account.Disabled)
+
+		auditService.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+			return entry.Action == "service_account.disable" && entry.UserID == "admin-1"
+		}))
+	})
+}
+
+func testListByOrganization(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given accounts in several organizations, When listing one, Then should return only its accounts", func(t *testing.T) {
+		service, _, _ := newTestService(t, newService)
+		credentials := createAccount(t, service)
+		_, err := service.Create(context.Background(), serviceaccount.CreateRequest{OrganizationID: "org-" + uuid.NewString(), Name: "other"})
+		require.NoError(t, err)
+
+		accounts, err := service.ListByOrganization(context.Background(), credentials.Account.OrganizationID)
+
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		assert.Equal(t, "ci-deployer", accounts[0].Name)
+	})
+}
+
+func testNotFound(t *testing.T, newService NewServiceFunc) {
+	t.Run("Given an unknown ID, When managing the account, Then should return not found or invalid credentials", func(t *testing.T) {
+		service, _, _ := newTestService(t, newService)
+		ctx := context.Background()
+		id := uuid.NewString()
+
+		_, getErr := service.Get(ctx, id)
+		_, rotateErr := service.RotateSecret(ctx, id)
+		disableErr := service.Disable(ctx, id)
+		_, issueErr := service.IssueToken(ctx, id, "secret", nil)
+
+		assert.ErrorIs(t, getErr, serviceaccount.ErrNotFound)
+		assert.ErrorIs(t, rotateErr, serviceaccount.ErrNotFound)
+		assert.ErrorIs(t, disableErr, serviceaccount.ErrNotFound)
+		assert.ErrorIs(t, issueErr, serviceaccount.ErrInvalidSecret)
+	})
+}
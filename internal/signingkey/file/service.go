@@ -0,0 +1,198 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+)
+
+// record is how a key is stored on disk
+type record struct {
+	ID         string     `json:"id"`
+	Algorithm  string     `json:"algorithm"`
+	PrivateKey string     `json:"private_key"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// service implements signingkey.Service with one JSON file per key in a
+// directory. Files are written atomically and readable by the owner only.
+// Rotations are serialized within the process, so one process should own the
+// directory; instances sharing keys should use the Postgres or Redis store.
+type service struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewService creates a signing key store in dir, creating it if needed
+func NewService(dir string) (signingkey.Service, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create signing key directory: %w", err)
+	}
+	return &service{dir: dir}, nil
+}
+
+// Current returns the newest key that has not been retired
+func (s *service) Current(ctx context.Context) (*signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return current(keys)
+}
+
+// Keys reads every key file, newest first
+func (s *service) Keys(ctx context.Context) ([]signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// Rotate marks the previous key retired, then writes the new one
+func (s *service) Rotate(ctx context.Context, key signingkey.Key, previousID string) (*signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	existing, _ := current(keys)
+	if existing != nil && existing.ID != previousID {
+		return existing, nil
+	}
+
+	// Retire first: a crash in between leaves no current key, and the next
+	// rotation creates one, rather than two keys that both look current
+	if existing != nil {
+		retiredAt := time.Now()
+		existing.RetiredAt = &retiredAt
+		if err := s.write(*existing); err != nil {
+			return nil, err
+		}
+	}
+	key.RetiredAt = nil
+	if err := s.write(key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Prune removes the files of keys retired before the time
+func (s *service) Prune(ctx context.Context, retiredBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.IsRetired() && key.RetiredAt.Before(retiredBefore) {
+			if err := os.Remove(s.path(key.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Helper methods
+
+// path returns the file holding the key with the ID
+func (s *service) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads every key file, newest first; callers hold mu
+func (s *service) load() ([]signingkey.Key, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key directory: %w", err)
+	}
+
+	var keys []signingkey.Key
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		var stored record
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", entry.Name(), err)
+		}
+		privateKey, err := signingkey.DecodePrivateKey([]byte(stored.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, signingkey.Key{
+			ID:         stored.ID,
+			Algorithm:  stored.Algorithm,
+			PrivateKey: privateKey,
+			CreatedAt:  stored.CreatedAt,
+			RetiredAt:  stored.RetiredAt,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// write stores the key through a temporary file renamed into place, so a
+// crash never leaves a partial key behind
+func (s *service) write(key signingkey.Key) error {
+	privateKey, err := signingkey.EncodePrivateKey(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: string(privateKey),
+		CreatedAt:  key.CreatedAt,
+		RetiredAt:  key.RetiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".key-*")
+	if err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(key.ID))
+}
+
+// current returns the newest key that has not been retired
+func current(keys []signingkey.Key) (*signingkey.Key, error) {
+	for _, key := range keys {
+		if !key.IsRetired() {
+			return &key, nil
+		}
+	}
+	return nil, signingkey.ErrNoCurrentKey
+}
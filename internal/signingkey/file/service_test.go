@@ -0,0 +1,82 @@
+package file_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/file"
+)
+
+func newKey(t *testing.T, algorithm string) signingkey.Key {
+	t.Helper()
+	key, err := signingkey.GenerateKey(algorithm)
+	require.NoError(t, err)
+	return *key
+}
+
+func TestSigningKeys_GivenRotatedKeys_WhenReopeningDirectory_ThenLoadsSameKeys(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := file.NewService(dir)
+	require.NoError(t, err)
+
+	first, err := store.Rotate(ctx, newKey(t, signingkey.AlgorithmRS256), "")
+	require.NoError(t, err)
+	second, err := store.Rotate(ctx, newKey(t, signingkey.AlgorithmES256), first.ID)
+	require.NoError(t, err)
+
+	reopened, err := file.NewService(dir)
+	require.NoError(t, err)
+	current, err := reopened.Current(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, current.ID)
+	assert.Equal(t, signingkey.AlgorithmES256, current.Algorithm)
+	assert.True(t, current.PrivateKey.Public().(*ecdsa.PublicKey).Equal(second.PrivateKey.Public()))
+
+	keys, err := reopened.Keys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, first.ID, keys[1].ID)
+	assert.True(t, keys[1].IsRetired())
+}
+
+func TestSigningKeys_GivenKeyFile_WhenWritten_ThenOnlyOwnerCanRead(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := file.NewService(dir)
+	require.NoError(t, err)
+
+	key, err := store.Rotate(ctx, newKey(t, signingkey.AlgorithmES256), "")
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, key.ID+".json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestSigningKeys_GivenExpiredKey_WhenPruning_ThenRemovesItsFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := file.NewService(dir)
+	require.NoError(t, err)
+	first, err := store.Rotate(ctx, newKey(t, signingkey.AlgorithmES256), "")
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, newKey(t, signingkey.AlgorithmES256), first.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Prune(ctx, time.Now().Add(time.Second)))
+
+	_, err = os.Stat(filepath.Join(dir, first.ID+".json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+)
+
+// service implements signingkey.Service in memory, for single instances and
+// tests; keys are lost when the process exits
+type service struct {
+	mu   sync.Mutex
+	keys []signingkey.Key // newest first
+}
+
+// NewService creates an in-memory signing key store
+func NewService() signingkey.Service {
+	return &service{}
+}
+
+// Current returns the newest key unless it has been retired
+func (s *service) Current(ctx context.Context) (*signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.current()
+}
+
+// Keys returns a copy of every key, newest first
+func (s *service) Keys(ctx context.Context) ([]signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.keys), nil
+}
+
+// Rotate retires the current key and prepends the new one
+func (s *service) Rotate(ctx context.Context, key signingkey.Key, previousID string) (*signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, _ := s.current()
+	if current != nil && current.ID != previousID {
+		return current, nil
+	}
+
+	if current != nil {
+		retiredAt := time.Now()
+		s.keys[0].RetiredAt = &retiredAt
+	}
+	key.RetiredAt = nil
+	s.keys = append([]signingkey.Key{key}, s.keys...)
+	return &key, nil
+}
+
+// Prune drops keys retired before the time
+func (s *service) Prune(ctx context.Context, retiredBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = slices.DeleteFunc(s.keys, func(key signingkey.Key) bool {
+		return key.IsRetired() && key.RetiredAt.Before(retiredBefore)
+	})
+	return nil
+}
+
+// Helper methods
+
+// current returns the newest key while it is not retired; callers hold mu
+func (s *service) current() (*signingkey.Key, error) {
+	if len(s.keys) == 0 || s.keys[0].IsRetired() {
+		return nil, signingkey.ErrNoCurrentKey
+	}
+	key := s.keys[0]
+	return &key, nil
+}
@@ -0,0 +1,85 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+)
+
+func newKey(t *testing.T) signingkey.Key {
+	t.Helper()
+	key, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	return *key
+}
+
+func TestSigningKeys_GivenEmptyStore_WhenReadingCurrent_ThenReturnsNoCurrentKey(t *testing.T) {
+	_, err := memory.NewService().Current(context.Background())
+
+	assert.ErrorIs(t, err, signingkey.ErrNoCurrentKey)
+}
+
+func TestSigningKeys_GivenRotation_WhenReadingKeys_ThenRetiresPreviousKey(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	first, err := store.Rotate(ctx, newKey(t), "")
+	require.NoError(t, err)
+
+	second, err := store.Rotate(ctx, newKey(t), first.ID)
+	require.NoError(t, err)
+
+	current, err := store.Current(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, current.ID)
+
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{second.ID, first.ID}, []string{keys[0].ID, keys[1].ID})
+	assert.False(t, keys[0].IsRetired())
+	assert.True(t, keys[1].IsRetired())
+}
+
+func TestSigningKeys_GivenStalePreviousID_WhenRotating_ThenKeepsWinningKey(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	first, err := store.Rotate(ctx, newKey(t), "")
+	require.NoError(t, err)
+	winner, err := store.Rotate(ctx, newKey(t), first.ID)
+	require.NoError(t, err)
+
+	// A second instance that also saw first as current loses the race
+	result, err := store.Rotate(ctx, newKey(t), first.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, winner.ID, result.ID)
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestSigningKeys_GivenRetiredKeys_WhenPruning_ThenDropsOnlyExpiredOnes(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	first, err := store.Rotate(ctx, newKey(t), "")
+	require.NoError(t, err)
+	second, err := store.Rotate(ctx, newKey(t), first.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Prune(ctx, time.Now().Add(-time.Hour)))
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2, "recently retired keys are kept")
+
+	require.NoError(t, store.Prune(ctx, time.Now().Add(time.Second)))
+	keys, err = store.Keys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, second.ID, keys[0].ID)
+}
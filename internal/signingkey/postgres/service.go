@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+)
+
+// rotationLockID is the transaction-level advisory lock serializing
+// rotations across instances, including the first one when no row exists yet
+const rotationLockID = 0x5349474e4b4559 // "SIGNKEY"
+
+// service implements signingkey.Service on the signing_keys table, so every
+// instance sharing the database signs with the same key. Private keys are
+// stored as PKCS #8 PEM, so access to the table must be restricted like any
+// other secret.
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed signing key store
+func NewService(pool *pgxpool.Pool) signingkey.Service {
+	return &service{pool: pool}
+}
+
+// Current returns the newest key that has not been retired
+func (s *service) Current(ctx context.Context) (*signingkey.Key, error) {
+	key, err := s.current(ctx, s.pool)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, signingkey.ErrNoCurrentKey
+	}
+	return key, err
+}
+
+// Keys returns every key, newest first
+func (s *service) Keys(ctx context.Context) ([]signingkey.Key, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, algorithm, private_key, created_at, retired_at
+		FROM signing_keys
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []signingkey.Key
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// Rotate retires the current key and inserts the new one in a transaction
+// holding the rotation lock
+func (s *service) Rotate(ctx context.Context, key signingkey.Key, previousID string) (*signingkey.Key, error) {
+	privateKey, err := signingkey.EncodePrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated *signingkey.Key
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, rotationLockID); err != nil {
+			return err
+		}
+
+		existing, err := s.current(ctx, tx)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if existing != nil && existing.ID != previousID {
+			rotated = existing
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE signing_keys SET retired_at = now() WHERE retired_at IS NULL`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO signing_keys (id, algorithm, private_key, created_at)
+			VALUES ($1, $2, $3, $4)`,
+			key.ID, key.Algorithm, privateKey, key.CreatedAt); err != nil {
+			return err
+		}
+		key.RetiredAt = nil
+		rotated = &key
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// Prune deletes keys retired before the time
+func (s *service) Prune(ctx context.Context, retiredBefore time.Time) error {
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM signing_keys WHERE retired_at < $1`, retiredBefore)
+	return err
+}
+
+// Helper methods
+
+// querier is satisfied by both the pool and a transaction
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// current reads the newest unretired key, returning pgx.ErrNoRows when there is none
+func (s *service) current(ctx context.Context, q querier) (*signingkey.Key, error) {
+	return scanKey(q.QueryRow(ctx, `
+		SELECT id, algorithm, private_key, created_at, retired_at
+		FROM signing_keys
+		WHERE retired_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1`))
+}
+
+// scanKey reads a signing_keys row and decodes its private key
+func scanKey(row pgx.Row) (*signingkey.Key, error) {
+	var key signingkey.Key
+	var privateKey []byte
+	if err := row.Scan(&key.ID, &key.Algorithm, &privateKey, &key.CreatedAt, &key.RetiredAt); err != nil {
+		return nil, err
+	}
+
+	signer, err := signingkey.DecodePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key.PrivateKey = signer
+	return &key, nil
+}
@@ -0,0 +1,204 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+)
+
+// DefaultKeyPrefix namespaces signing keys in a shared Redis
+const DefaultKeyPrefix = "signingkey:"
+
+// record is how a key is stored in the keys hash
+type record struct {
+	ID         string     `json:"id"`
+	Algorithm  string     `json:"algorithm"`
+	PrivateKey string     `json:"private_key"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// service implements signingkey.Service on Redis: a hash holds every key by
+// ID and a string names the current one. Rotations watch that string, so
+// instances racing to rotate settle on one key. Private keys are stored as
+// PKCS #8 PEM, so the Redis must be protected like any other secret store.
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed signing key store using DefaultKeyPrefix
+func NewService(client *redis.Client) signingkey.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed signing key store whose keys
+// start with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) signingkey.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// Current returns the key named by the current pointer
+func (s *service) Current(ctx context.Context) (*signingkey.Key, error) {
+	return s.current(ctx, s.client)
+}
+
+// Keys reads the keys hash, newest first
+func (s *service) Keys(ctx context.Context) ([]signingkey.Key, error) {
+	payloads, err := s.client.HGetAll(ctx, s.keysKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]signingkey.Key, 0, len(payloads))
+	for _, payload := range payloads {
+		key, err := decode([]byte(payload))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Rotate retires the current key and stores the new one in a transaction
+// watching the current pointer
+func (s *service) Rotate(ctx context.Context, key signingkey.Key, previousID string) (*signingkey.Key, error) {
+	key.RetiredAt = nil
+	payload, err := encode(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var winner *signingkey.Key
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		existing, err := s.current(ctx, tx)
+		if err != nil && !errors.Is(err, signingkey.ErrNoCurrentKey) {
+			return err
+		}
+		if existing != nil && existing.ID != previousID {
+			winner = existing
+			return nil
+		}
+
+		var retired []byte
+		if existing != nil {
+			retiredAt := time.Now()
+			existing.RetiredAt = &retiredAt
+			if retired, err = encode(*existing); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if retired != nil {
+				pipe.HSet(ctx, s.keysKey(), existing.ID, retired)
+			}
+			pipe.HSet(ctx, s.keysKey(), key.ID, payload)
+			pipe.Set(ctx, s.currentKey(), key.ID, 0)
+			return nil
+		})
+		winner = &key
+		return err
+	}, s.currentKey())
+	if errors.Is(err, redis.TxFailedErr) {
+		// Another instance rotated while this one was preparing
+		return s.Current(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return winner, nil
+}
+
+// Prune deletes the keys retired before the time from the hash
+func (s *service) Prune(ctx context.Context, retiredBefore time.Time) error {
+	keys, err := s.Keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var expired []string
+	for _, key := range keys {
+		if key.IsRetired() && key.RetiredAt.Before(retiredBefore) {
+			expired = append(expired, key.ID)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return s.client.HDel(ctx, s.keysKey(), expired...).Err()
+}
+
+// Helper methods
+
+// keysKey returns the Redis hash holding every key
+func (s *service) keysKey() string {
+	return s.prefix + "keys"
+}
+
+// currentKey returns the Redis key naming the current key
+func (s *service) currentKey() string {
+	return s.prefix + "current"
+}
+
+// current reads the key named by the current pointer
+func (s *service) current(ctx context.Context, client redis.Cmdable) (*signingkey.Key, error) {
+	id, err := client.Get(ctx, s.currentKey()).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, signingkey.ErrNoCurrentKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := client.HGet(ctx, s.keysKey(), id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, signingkey.ErrNoCurrentKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(payload)
+}
+
+// encode serializes a key with its private key as PKCS #8 PEM
+func encode(key signingkey.Key) ([]byte, error) {
+	privateKey, err := signingkey.EncodePrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(record{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: string(privateKey),
+		CreatedAt:  key.CreatedAt,
+		RetiredAt:  key.RetiredAt,
+	})
+}
+
+// decode reads a key stored by encode
+func decode(payload []byte) (*signingkey.Key, error) {
+	var stored record
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return nil, err
+	}
+	privateKey, err := signingkey.DecodePrivateKey([]byte(stored.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	return &signingkey.Key{
+		ID:         stored.ID,
+		Algorithm:  stored.Algorithm,
+		PrivateKey: privateKey,
+		CreatedAt:  stored.CreatedAt,
+		RetiredAt:  stored.RetiredAt,
+	}, nil
+}
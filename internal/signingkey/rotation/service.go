@@ -0,0 +1,161 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+)
+
+// Config controls when signing keys are replaced and how long the replaced
+// ones are kept
+type Config struct {
+	// Algorithm is what new keys are generated for; a current key of another
+	// algorithm is replaced straight away
+	Algorithm string
+
+	// Interval is the age at which the current key is replaced; zero only
+	// creates the first key
+	Interval time.Duration
+
+	// Retention is how long a retired key is kept after it was replaced. It
+	// should be at least the lifetime of the longest-lived token, or tokens
+	// signed with the key stop verifying early. Zero keeps retired keys.
+	Retention time.Duration
+
+	// RefreshInterval is how often the store is read again to pick up
+	// rotations made by other instances; defaults to DefaultRefreshInterval
+	RefreshInterval time.Duration
+}
+
+// DefaultRefreshInterval is how long the keys read from the store are reused
+const DefaultRefreshInterval = time.Minute
+
+// service implements signingkey.Service by generating keys on schedule in
+// front of a store. Rotation happens lazily when the keys are read, so there
+// is no background goroutine to stop, and the store settles races between
+// instances that find the key due at the same time.
+type service struct {
+	next   signingkey.Service
+	config Config
+
+	mu       sync.Mutex
+	current  *signingkey.Key
+	keys     []signingkey.Key
+	loadedAt time.Time
+}
+
+// NewService creates a signing key service that rotates the keys in next
+func NewService(next signingkey.Service, config Config) signingkey.Service {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
+	}
+	return &service{next: next, config: config}
+}
+
+// Current returns the current key, creating or replacing it when it is due
+func (s *service) Current(ctx context.Context) (*signingkey.Key, error) {
+	current, _, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := *current
+	return &key, nil
+}
+
+// Keys returns every stored key after rotating the current one if it is due
+func (s *service) Keys(ctx context.Context) ([]signingkey.Key, error) {
+	_, keys, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(keys), nil
+}
+
+// Rotate replaces the current key ahead of schedule
+func (s *service) Rotate(ctx context.Context, key signingkey.Key, previousID string) (*signingkey.Key, error) {
+	defer s.invalidate()
+	return s.next.Rotate(ctx, key, previousID)
+}
+
+// Prune deletes the keys retired before the time
+func (s *service) Prune(ctx context.Context, retiredBefore time.Time) error {
+	defer s.invalidate()
+	return s.next.Prune(ctx, retiredBefore)
+}
+
+// Helper methods
+
+// snapshot returns the cached keys, reading them again once they are stale
+// or the current key is due for rotation
+func (s *service) snapshot(ctx context.Context) (*signingkey.Key, []signingkey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.current != nil && now.Sub(s.loadedAt) < s.config.RefreshInterval && !s.due(s.current, now) {
+		return s.current, s.keys, nil
+	}
+
+	current, err := s.next.Current(ctx)
+	if err != nil && !errors.Is(err, signingkey.ErrNoCurrentKey) {
+		return nil, nil, err
+	}
+	if current == nil || s.due(current, now) {
+		if current, err = s.rotate(ctx, current, now); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keys, err := s.next.Keys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.current, s.keys, s.loadedAt = current, keys, now
+	return current, keys, nil
+}
+
+// rotate generates a key to replace current, which may be nil, and prunes
+// the keys past their retention
+func (s *service) rotate(ctx context.Context, current *signingkey.Key, now time.Time) (*signingkey.Key, error) {
+	key, err := signingkey.GenerateKey(s.config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var previousID string
+	if current != nil {
+		previousID = current.ID
+	}
+	rotated, err := s.next.Rotate(ctx, *key, previousID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.Retention > 0 {
+		if err := s.next.Prune(ctx, now.Add(-s.config.Retention)); err != nil {
+			return nil, err
+		}
+	}
+	return rotated, nil
+}
+
+// due reports whether the key should be replaced
+func (s *service) due(key *signingkey.Key, now time.Time) bool {
+	if key.Algorithm != s.config.Algorithm {
+		return true
+	}
+	return s.config.Interval > 0 && now.Sub(key.CreatedAt) >= s.config.Interval
+}
+
+// invalidate drops the cached keys so the next read goes to the store
+func (s *service) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current, s.keys = nil, nil
+}
@@ -0,0 +1,84 @@
+package rotation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/rotation"
+)
+
+func TestRotation_GivenEmptyStore_WhenReadingCurrent_ThenCreatesFirstKey(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	keys := rotation.NewService(store, rotation.Config{Algorithm: signingkey.AlgorithmES256, Interval: time.Hour})
+
+	current, err := keys.Current(ctx)
+	require.NoError(t, err)
+	again, err := keys.Current(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, signingkey.AlgorithmES256, current.Algorithm)
+	assert.Equal(t, current.ID, again.ID)
+	stored, err := store.Current(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, current.ID, stored.ID)
+}
+
+func TestRotation_GivenKeyOlderThanInterval_WhenReadingCurrent_ThenRotates(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	old, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	old.CreatedAt = time.Now().Add(-2 * time.Hour)
+	_, err = store.Rotate(ctx, *old, "")
+	require.NoError(t, err)
+	keys := rotation.NewService(store, rotation.Config{Algorithm: signingkey.AlgorithmES256, Interval: time.Hour, Retention: time.Hour})
+
+	current, err := keys.Current(ctx)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, old.ID, current.ID)
+	all, err := keys.Keys(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2, "the retired key is kept for its retention")
+	assert.Equal(t, old.ID, all[1].ID)
+	assert.True(t, all[1].IsRetired())
+}
+
+func TestRotation_GivenKeyOfOtherAlgorithm_WhenReadingCurrent_ThenReplacesIt(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	old, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, *old, "")
+	require.NoError(t, err)
+	keys := rotation.NewService(store, rotation.Config{Algorithm: signingkey.AlgorithmRS256, Interval: time.Hour})
+
+	current, err := keys.Current(ctx)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, old.ID, current.ID)
+	assert.Equal(t, signingkey.AlgorithmRS256, current.Algorithm)
+}
+
+func TestRotation_GivenManualRotation_WhenReadingCurrent_ThenSeesNewKeyImmediately(t *testing.T) {
+	ctx := context.Background()
+	keys := rotation.NewService(memory.NewService(), rotation.Config{Algorithm: signingkey.AlgorithmES256, Interval: time.Hour})
+	first, err := keys.Current(ctx)
+	require.NoError(t, err)
+	next, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+
+	_, err = keys.Rotate(ctx, *next, first.ID)
+	require.NoError(t, err)
+
+	current, err := keys.Current(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, current.ID)
+}
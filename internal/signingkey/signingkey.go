@@ -0,0 +1,130 @@
+package signingkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the signing key domain interface - the ONLY interface in this domain.
+// It keeps the asymmetric keys tokens are signed with, so every instance
+// signs with the same current key and still verifies tokens signed with the
+// keys before it.
+type Service interface {
+	// Current returns the key new tokens are signed with, or ErrNoCurrentKey
+	Current(ctx context.Context) (*Key, error)
+
+	// Keys returns every stored key, newest first
+	Keys(ctx context.Context) ([]Key, error)
+
+	// Rotate makes key the current key and retires the previous one, as long
+	// as the current key is still previousID or there is none. Otherwise
+	// another instance rotated first and its key is returned, so concurrent
+	// rotations settle on a single new key.
+	Rotate(ctx context.Context, key Key, previousID string) (*Key, error)
+
+	// Prune deletes the keys retired before the time
+	Prune(ctx context.Context, retiredBefore time.Time) error
+}
+
+// Domain types and data structures
+
+// Key is one signing key. Retired keys no longer sign tokens but still
+// verify the ones they signed.
+type Key struct {
+	ID         string        `json:"id"`
+	Algorithm  string        `json:"algorithm"`
+	PrivateKey crypto.Signer `json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+	RetiredAt  *time.Time    `json:"retired_at,omitempty"`
+}
+
+// Signing algorithms keys are generated for
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// rsaKeyBits is the size of generated RS256 keys
+const rsaKeyBits = 2048
+
+// IsRetired reports whether the key has been replaced
+func (k *Key) IsRetired() bool {
+	return k.RetiredAt != nil
+}
+
+// GenerateKey creates a key for the algorithm: 2048-bit RSA for RS256 or
+// P-256 ECDSA for ES256
+func GenerateKey(algorithm string) (*Key, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", algorithm, err)
+	}
+
+	return &Key{
+		ID:         uuid.New().String(),
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// EncodePrivateKey encodes a private key as PKCS #8 PEM for storage
+func EncodePrivateKey(privateKey crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// DecodePrivateKey decodes a private key stored by EncodePrivateKey
+func DecodePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("stored private key is not PKCS #8 PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// SigningKeyError represents signing key domain errors
+type SigningKeyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e SigningKeyError) Error() string {
+	return e.Message
+}
+
+// Common signing key errors
+var (
+	ErrNoCurrentKey         = SigningKeyError{Code: "NO_CURRENT_SIGNING_KEY", Message: "No signing key has been created yet"}
+	ErrUnsupportedAlgorithm = SigningKeyError{Code: "UNSUPPORTED_SIGNING_ALGORITHM", Message: "Signing keys are generated for RS256 or ES256 only"}
+)
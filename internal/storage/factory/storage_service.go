@@ -0,0 +1,46 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	"github.com/gentra/decorator-arch-go/internal/storage/local"
+	storageS3 "github.com/gentra/decorator-arch-go/internal/storage/s3"
+)
+
+// Config contains all configuration for building the storage service
+type Config struct {
+	// Provider configuration
+	Provider string // "local" (default) or "s3"
+
+	// Local disk provider settings
+	Local local.Config
+
+	// AWS S3 or S3-compatible provider settings
+	S3 storageS3.Config
+}
+
+// StorageServiceFactory creates the blob storage service
+type StorageServiceFactory struct {
+	config Config
+}
+
+// NewFactory creates a new storage service factory with the given configuration
+func NewFactory(config Config) *StorageServiceFactory {
+	return &StorageServiceFactory{
+		config: config,
+	}
+}
+
+// Build returns the storage service for the configured provider
+func (f *StorageServiceFactory) Build() (storage.Service, error) {
+	switch f.config.Provider {
+	case "", "local":
+		return local.NewService(f.config.Local)
+	case "s3":
+		return storageS3.NewService(context.Background(), f.config.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage provider %q", f.config.Provider)
+	}
+}
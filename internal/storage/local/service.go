@@ -0,0 +1,168 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+)
+
+// Config contains the directory objects are written to and the URL they are served from
+type Config struct {
+	// Root is the directory holding every object; it is created if missing
+	Root string
+
+	// BaseURL is prepended to object keys by URL, e.g. "/media" when the REST
+	// server serves the directory itself or a CDN origin in front of it
+	BaseURL string
+}
+
+// DefaultConfig returns a data directory served under /media
+func DefaultConfig() Config {
+	return Config{
+		Root:    "data/media",
+		BaseURL: "/media",
+	}
+}
+
+// service implements storage.Service on the local filesystem. Links are
+// public, so it suits development and single-node deployments.
+type service struct {
+	root    string
+	baseURL string
+}
+
+// NewService creates a new local-disk storage service
+func NewService(config Config) (storage.Service, error) {
+	if config.Root == "" {
+		return nil, fmt.Errorf("storage root directory is required")
+	}
+	if err := os.MkdirAll(config.Root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	return &service{
+		root:    config.Root,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+	}, nil
+}
+
+// Put writes the object to a temporary file and moves it into place, so
+// readers never see a partially written object
+func (s *service) Put(ctx context.Context, key string, content io.Reader, contentType string) (*storage.Object, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return nil, fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return &storage.Object{
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		ModifiedAt:  time.Now(),
+	}, nil
+}
+
+// Get opens the object for reading; the content type is inferred from the key's extension
+func (s *service) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, storage.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, storage.ErrObjectNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return file, &storage.Object{
+		Key:         key,
+		ContentType: contentType,
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime(),
+	}, nil
+}
+
+// Delete removes the object; deleting a missing object is not an error
+func (s *service) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// URL returns the public link of the object under the base URL
+func (s *service) URL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	escaped := make([]string, 0, strings.Count(key, "/")+1)
+	for _, segment := range strings.Split(key, "/") {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	return s.baseURL + "/" + strings.Join(escaped, "/"), nil
+}
+
+// Helper methods
+
+// path resolves a key to its file below the root directory
+func (s *service) path(key string) (string, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
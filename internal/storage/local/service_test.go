@@ -0,0 +1,71 @@
+package local_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	"github.com/gentra/decorator-arch-go/internal/storage/local"
+)
+
+func newTestService(t *testing.T) storage.Service {
+	t.Helper()
+	service, err := local.NewService(local.Config{Root: t.TempDir(), BaseURL: "/media/"})
+	require.NoError(t, err)
+	return service
+}
+
+func TestLocalStorage_GivenStoredObject_WhenGetting_ThenReturnsContentAndMetadata(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+
+	stored, err := service.Put(ctx, "avatars/user-1/a.png", strings.NewReader("png-bytes"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), stored.Size)
+
+	body, object, err := service.Get(ctx, "avatars/user-1/a.png")
+	require.NoError(t, err)
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(content))
+	assert.Equal(t, "image/png", object.ContentType)
+	assert.Equal(t, int64(9), object.Size)
+}
+
+func TestLocalStorage_GivenDeletedObject_WhenGetting_ThenReturnsNotFound(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+
+	_, err := service.Put(ctx, "avatars/a.png", strings.NewReader("x"), "image/png")
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(ctx, "avatars/a.png"))
+	require.NoError(t, service.Delete(ctx, "avatars/a.png"))
+
+	_, _, err = service.Get(ctx, "avatars/a.png")
+
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestLocalStorage_GivenTraversalKey_WhenPutting_ThenReturnsInvalidKey(t *testing.T) {
+	service := newTestService(t)
+
+	_, err := service.Put(context.Background(), "../escape.png", strings.NewReader("x"), "image/png")
+
+	assert.ErrorIs(t, err, storage.ErrInvalidKey)
+}
+
+func TestLocalStorage_GivenKey_WhenBuildingURL_ThenJoinsBaseURL(t *testing.T) {
+	service := newTestService(t)
+
+	url, err := service.URL(context.Background(), "avatars/user 1/a.png", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/media/avatars/user%201/a.png", url)
+}
@@ -0,0 +1,218 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+)
+
+// Config contains the bucket objects are stored in and how links to them are built
+type Config struct {
+	// Region and Profile select the AWS configuration; when empty the default
+	// chain is used (environment, shared config, IAM roles for tasks and pods)
+	Region  string
+	Profile string
+
+	// Endpoint overrides the S3 endpoint, e.g. http://localhost:4566 for
+	// LocalStack or a MinIO server; emulators get placeholder credentials
+	Endpoint     string
+	UsePathStyle bool
+
+	// Bucket holds every object; keys are stored below Prefix when set
+	Bucket string
+	Prefix string
+
+	// PublicBaseURL, when set, is prepended to keys instead of presigning
+	// links, for buckets served publicly or through a CDN
+	PublicBaseURL string
+}
+
+// service implements storage.Service on an S3 bucket
+type service struct {
+	client    *awss3.Client
+	presigner *awss3.PresignClient
+	config    Config
+}
+
+// NewService creates a new S3 storage service
+func NewService(ctx context.Context, config Config) (storage.Service, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+	config.PublicBaseURL = strings.TrimSuffix(config.PublicBaseURL, "/")
+
+	options := []func(*awsconfig.LoadOptions) error{}
+	if config.Region != "" {
+		options = append(options, awsconfig.WithRegion(config.Region))
+	}
+	if config.Profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(config.Profile))
+	}
+	if config.Endpoint != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider("local", "local", ""),
+		))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := awss3.NewFromConfig(awsConfig, func(o *awss3.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+		o.UsePathStyle = config.UsePathStyle
+	})
+
+	return &service{
+		client:    client,
+		presigner: awss3.NewPresignClient(client),
+		config:    config,
+	}, nil
+}
+
+// Put uploads the object. The content is buffered so its length is known
+// up front; callers are expected to cap the size of what they pass in.
+func (s *service) Put(ctx context.Context, key string, content io.Reader, contentType string) (*storage.Object, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object content: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket:        aws.String(s.config.Bucket),
+		Key:           aws.String(objectKey),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload object: %w", err)
+	}
+
+	return &storage.Object{
+		Key:         key,
+		ContentType: contentType,
+		Size:        int64(len(body)),
+		ModifiedAt:  time.Now(),
+	}, nil
+}
+
+// Get streams the object from the bucket
+func (s *service) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output, err := s.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(objectKey),
+	})
+	if isNotFound(err) {
+		return nil, nil, storage.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download object: %w", err)
+	}
+
+	return output.Body, &storage.Object{
+		Key:         key,
+		ContentType: aws.ToString(output.ContentType),
+		Size:        aws.ToInt64(output.ContentLength),
+		ModifiedAt:  aws.ToTime(output.LastModified),
+	}, nil
+}
+
+// Delete removes the object; S3 treats deleting a missing object as success
+func (s *service) Delete(ctx context.Context, key string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObject(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// URL returns the public link when a base URL is configured, otherwise a
+// presigned GET link valid for expiry
+func (s *service) URL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if s.config.PublicBaseURL != "" {
+		escaped := make([]string, 0, strings.Count(objectKey, "/")+1)
+		for _, segment := range strings.Split(objectKey, "/") {
+			escaped = append(escaped, url.PathEscape(segment))
+		}
+		return s.config.PublicBaseURL + "/" + strings.Join(escaped, "/"), nil
+	}
+
+	if expiry <= 0 {
+		expiry = storage.DefaultURLExpiry
+	}
+	request, err := s.presigner.PresignGetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(objectKey),
+	}, awss3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object URL: %w", err)
+	}
+	return request.URL, nil
+}
+
+// Helper methods
+
+// objectKey validates a key and places it below the configured prefix
+func (s *service) objectKey(key string) (string, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return "", err
+	}
+	if s.config.Prefix == "" {
+		return key, nil
+	}
+	return s.config.Prefix + "/" + key, nil
+}
+
+// isNotFound reports whether S3 answered that the object does not exist
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "NoSuchKey", "NotFound":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package s3_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	storageS3 "github.com/gentra/decorator-arch-go/internal/storage/s3"
+)
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+// fakeS3 emulates a path-style S3 endpoint for PutObject, GetObject and DeleteObject
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(object.body)))
+		_, _ = w.Write(object.body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestService(t *testing.T, fake *fakeS3, config storageS3.Config) storage.Service {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config.Region = "us-east-1"
+	config.Endpoint = server.URL
+	config.UsePathStyle = true
+	config.Bucket = "media"
+
+	service, err := storageS3.NewService(context.Background(), config)
+	require.NoError(t, err)
+	return service
+}
+
+func TestS3Storage_GivenStoredObject_WhenGetting_ThenReturnsContentUnderPrefix(t *testing.T) {
+	fake := &fakeS3{objects: map[string]fakeObject{}}
+	service := newTestService(t, fake, storageS3.Config{Prefix: "uploads/"})
+	ctx := context.Background()
+
+	_, err := service.Put(ctx, "avatars/a.png", strings.NewReader("png-bytes"), "image/png")
+	require.NoError(t, err)
+
+	body, object, err := service.Get(ctx, "avatars/a.png")
+	require.NoError(t, err)
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(content))
+	assert.Equal(t, "image/png", object.ContentType)
+	assert.Contains(t, fake.objects, "/media/uploads/avatars/a.png")
+}
+
+func TestS3Storage_GivenMissingObject_WhenGetting_ThenReturnsNotFound(t *testing.T) {
+	service := newTestService(t, &fakeS3{objects: map[string]fakeObject{}}, storageS3.Config{})
+
+	_, _, err := service.Get(context.Background(), "avatars/missing.png")
+
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestS3Storage_GivenNoPublicBaseURL_WhenBuildingURL_ThenPresignsLink(t *testing.T) {
+	service := newTestService(t, &fakeS3{objects: map[string]fakeObject{}}, storageS3.Config{})
+
+	url, err := service.URL(context.Background(), "avatars/a.png", 5*time.Minute)
+
+	require.NoError(t, err)
+	assert.Contains(t, url, "/media/avatars/a.png?")
+	assert.Contains(t, url, "X-Amz-Expires=300")
+	assert.Contains(t, url, "X-Amz-Signature=")
+}
+
+func TestS3Storage_GivenPublicBaseURL_WhenBuildingURL_ThenJoinsPrefixedKey(t *testing.T) {
+	service := newTestService(t, &fakeS3{objects: map[string]fakeObject{}}, storageS3.Config{
+		Prefix:        "uploads",
+		PublicBaseURL: "https://cdn.example.com/",
+	})
+
+	url, err := service.URL(context.Background(), "avatars/a.png", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/uploads/avatars/a.png", url)
+}
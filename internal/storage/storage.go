@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Service defines the blob storage domain interface - the ONLY interface in this domain
+// Objects are addressed by slash-separated keys such as "avatars/<user>/<id>.png";
+// keys are relative, so implementations can place them under a directory or prefix.
+type Service interface {
+	// Object operations
+	Put(ctx context.Context, key string, content io.Reader, contentType string) (*Object, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+
+	// URL returns a link clients can fetch the object from. Implementations
+	// that sign links make them valid for expiry; public links ignore it.
+	URL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Domain types and data structures
+
+// Object describes a stored blob
+type Object struct {
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	ModifiedAt  time.Time `json:"modified_at"`
+}
+
+// DefaultURLExpiry is how long signed links stay valid when no expiry is given
+const DefaultURLExpiry = 15 * time.Minute
+
+// ValidateKey rejects keys that are empty, absolute or could escape the storage root
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// StorageError represents domain-specific storage errors
+type StorageError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e StorageError) Error() string {
+	return e.Message
+}
+
+// Common storage error codes
+var (
+	ErrObjectNotFound = StorageError{Code: "OBJECT_NOT_FOUND", Message: "Object not found"}
+	ErrInvalidKey     = StorageError{Code: "INVALID_OBJECT_KEY", Message: "Invalid object key"}
+)
@@ -0,0 +1,36 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/storage"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Given nested relative key, When ValidateKey is called, Then should accept it", key: "avatars/user-1/a.png"},
+		{name: "Given empty key, When ValidateKey is called, Then should reject it", key: "", wantErr: true},
+		{name: "Given absolute key, When ValidateKey is called, Then should reject it", key: "/etc/passwd", wantErr: true},
+		{name: "Given parent segment, When ValidateKey is called, Then should reject it", key: "avatars/../secrets", wantErr: true},
+		{name: "Given empty segment, When ValidateKey is called, Then should reject it", key: "avatars//a.png", wantErr: true},
+		{name: "Given backslash, When ValidateKey is called, Then should reject it", key: `avatars\a.png`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := storage.ValidateKey(tt.key)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, storage.ErrInvalidKey)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
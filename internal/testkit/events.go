@@ -0,0 +1,83 @@
+package testkit
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+)
+
+// EventBuilder builds events.Event values
+type EventBuilder struct {
+	event events.Event
+}
+
+// NewEventBuilder starts from a user.registered event for DefaultUserID
+func NewEventBuilder() *EventBuilder {
+	return &EventBuilder{event: events.Event{
+		ID:            uuid.MustParse("00000000-0000-4000-8000-0000000000e1").String(),
+		Type:          events.EventTypeUserRegistered,
+		AggregateID:   DefaultUserID.String(),
+		AggregateType: "user",
+		Version:       1,
+		Data:          map[string]interface{}{},
+		Metadata: events.EventMetadata{
+			UserID: DefaultUserID.String(),
+			Source: "testkit",
+		},
+		Timestamp: Epoch,
+	}}
+}
+
+// WithType sets the event type
+func (b *EventBuilder) WithType(eventType string) *EventBuilder {
+	b.event.Type = eventType
+	return b
+}
+
+// ForAggregate sets the aggregate the event belongs to
+func (b *EventBuilder) ForAggregate(aggregateType, aggregateID string) *EventBuilder {
+	b.event.AggregateType = aggregateType
+	b.event.AggregateID = aggregateID
+	return b
+}
+
+// WithVersion sets the aggregate version
+func (b *EventBuilder) WithVersion(version int) *EventBuilder {
+	b.event.Version = version
+	return b
+}
+
+// WithData sets a single data field
+func (b *EventBuilder) WithData(key string, value interface{}) *EventBuilder {
+	b.event.Data[key] = value
+	return b
+}
+
+// WithCorrelationID sets the correlation and causation IDs
+func (b *EventBuilder) WithCorrelationID(correlationID, causationID string) *EventBuilder {
+	b.event.Metadata.CorrelationID = correlationID
+	b.event.Metadata.CausationID = causationID
+	return b
+}
+
+// WithUserID sets the acting user in the metadata
+func (b *EventBuilder) WithUserID(userID string) *EventBuilder {
+	b.event.Metadata.UserID = userID
+	return b
+}
+
+// Build returns a new copy of the event
+func (b *EventBuilder) Build() *events.Event {
+	event := b.event
+	event.Data = make(map[string]interface{}, len(b.event.Data))
+	for key, value := range b.event.Data {
+		event.Data[key] = value
+	}
+	if b.event.Metadata.Headers != nil {
+		event.Metadata.Headers = make(map[string]string, len(b.event.Metadata.Headers))
+		for key, value := range b.event.Metadata.Headers {
+			event.Metadata.Headers[key] = value
+		}
+	}
+	return &event
+}
@@ -0,0 +1,58 @@
+package testkit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnv rewrites golden files instead of comparing against them
+// when set to "1", e.g. UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenPath returns testdata/<name>.golden relative to the test's package
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden compares got with the golden file for name. Strings and byte
+// slices are compared verbatim; anything else as indented JSON.
+func AssertGolden(t testing.TB, name string, got interface{}) {
+	t.Helper()
+	actual := goldenBytes(t, got)
+	path := GoldenPath(name)
+
+	if shouldUpdateGolden() {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, actual, 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file; run with %s=1 to create it", UpdateGoldenEnv)
+	assert.Equal(t, string(expected), string(actual), "output differs from %s", path)
+}
+
+// Helper methods
+
+func shouldUpdateGolden() bool {
+	return os.Getenv(UpdateGoldenEnv) == "1"
+}
+
+func goldenBytes(t testing.TB, got interface{}) []byte {
+	t.Helper()
+	switch v := got.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	require.NoError(t, err)
+	return append(data, '\n')
+}
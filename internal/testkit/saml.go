@@ -0,0 +1,201 @@
+package testkit
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SAML endpoints the assertions built by NewSAMLAssertion are issued for
+const (
+	SAMLIdPEntityID = "https://idp.example.com/metadata"
+	SAMLSPEntityID  = "https://app.example.com/saml/metadata"
+	SAMLACSURL      = "https://app.example.com/api/auth/saml/acs"
+)
+
+// SAMLIdentityProvider signs SAML responses with a self-signed certificate.
+// The assertions are written in their exclusive canonical form, so their
+// digest is computed over the exact bytes without needing a canonicalizer.
+type SAMLIdentityProvider struct {
+	key         *rsa.PrivateKey
+	certificate string
+}
+
+// SAMLAssertion describes the assertion of a response
+type SAMLAssertion struct {
+	ID           string
+	Issuer       string
+	Audience     string
+	Recipient    string
+	InResponseTo string
+	NameID       string
+	Email        string
+	FirstName    string
+	LastName     string
+	NotBefore    time.Time
+	NotOnOrAfter time.Time
+}
+
+// NewSAMLIdentityProvider creates an identity provider with a fresh key
+func NewSAMLIdentityProvider(t testing.TB) *SAMLIdentityProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &SAMLIdentityProvider{
+		key:         key,
+		certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// CertificatePEM returns the PEM encoded signing certificate
+func (p *SAMLIdentityProvider) CertificatePEM() string {
+	return p.certificate
+}
+
+// NewSAMLAssertion returns a valid assertion for jane.doe@example.com issued
+// by SAMLIdPEntityID to SAMLSPEntityID, answering requestID
+func NewSAMLAssertion(requestID string) SAMLAssertion {
+	now := time.Now().UTC().Truncate(time.Second)
+	return SAMLAssertion{
+		ID:           "_assertion-" + strings.TrimPrefix(requestID, "_"),
+		Issuer:       SAMLIdPEntityID,
+		Audience:     SAMLSPEntityID,
+		Recipient:    SAMLACSURL,
+		InResponseTo: requestID,
+		NameID:       DefaultEmail,
+		Email:        DefaultEmail,
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		NotBefore:    now.Add(-time.Minute),
+		NotOnOrAfter: now.Add(5 * time.Minute),
+	}
+}
+
+// ResponseXML returns a response whose assertion is signed
+func (p *SAMLIdentityProvider) ResponseXML(t testing.TB, assertion SAMLAssertion) string {
+	t.Helper()
+	return p.response(assertion, p.sign(t, assertion.ID, p.assertion(assertion)))
+}
+
+// SignedResponseXML returns a response signed as a whole, with an unsigned assertion
+func (p *SAMLIdentityProvider) SignedResponseXML(t testing.TB, assertion SAMLAssertion) string {
+	t.Helper()
+	return p.sign(t, "_response", p.response(assertion, p.assertion(assertion)))
+}
+
+// UnsignedResponseXML returns a response without any signature
+func (p *SAMLIdentityProvider) UnsignedResponseXML(assertion SAMLAssertion) string {
+	return p.response(assertion, p.assertion(assertion))
+}
+
+// EncodeSAMLResponse base64 encodes a response as the HTTP-POST binding posts it
+func EncodeSAMLResponse(responseXML string) string {
+	return base64.StdEncoding.EncodeToString([]byte(responseXML))
+}
+
+// Helper methods
+
+const (
+	samlProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	xmlDsig       = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// assertion writes the assertion in canonical form
+func (p *SAMLIdentityProvider) assertion(a SAMLAssertion) string {
+	var confirmation strings.Builder
+	confirmation.WriteString(`<saml:SubjectConfirmationData`)
+	if a.InResponseTo != "" {
+		confirmation.WriteString(` InResponseTo="` + a.InResponseTo + `"`)
+	}
+	confirmation.WriteString(` NotOnOrAfter="` + samlTime(a.NotOnOrAfter) + `" Recipient="` + a.Recipient + `"></saml:SubjectConfirmationData>`)
+
+	return `<saml:Assertion xmlns:saml="` + samlAssertion + `" ID="` + a.ID + `" IssueInstant="` + samlTime(a.NotBefore) + `" Version="2.0">` +
+		`<saml:Issuer>` + a.Issuer + `</saml:Issuer>` +
+		`<saml:Subject>` +
+		`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">` + a.NameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` + confirmation.String() + `</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + samlTime(a.NotBefore) + `" NotOnOrAfter="` + samlTime(a.NotOnOrAfter) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + a.Audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		samlAttribute("email", a.Email) + samlAttribute("givenName", a.FirstName) + samlAttribute("sn", a.LastName) +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+// response wraps the assertion in a successful response in canonical form
+func (p *SAMLIdentityProvider) response(a SAMLAssertion, assertion string) string {
+	inResponseTo := ""
+	if a.InResponseTo != "" {
+		inResponseTo = ` InResponseTo="` + a.InResponseTo + `"`
+	}
+	return `<samlp:Response xmlns:samlp="` + samlProtocol + `" Destination="` + a.Recipient + `" ID="_response"` + inResponseTo +
+		` IssueInstant="` + samlTime(a.NotBefore) + `" Version="2.0">` +
+		`<saml:Issuer xmlns:saml="` + samlAssertion + `">` + a.Issuer + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode></samlp:Status>` +
+		assertion +
+		`</samlp:Response>`
+}
+
+// sign inserts an enveloped signature referencing id after the first
+// Issuer of the canonical element
+func (p *SAMLIdentityProvider) sign(t testing.TB, id, element string) string {
+	t.Helper()
+	digest := sha256.Sum256([]byte(element))
+
+	signedInfo := `<ds:SignedInfo xmlns:ds="` + xmlDsig + `">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+
+	hashed := sha256.Sum256([]byte(signedInfo))
+	value, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+
+	// In the document SignedInfo inherits the ds namespace from Signature
+	signature := `<ds:Signature xmlns:ds="` + xmlDsig + `">` +
+		strings.Replace(signedInfo, `<ds:SignedInfo xmlns:ds="`+xmlDsig+`">`, `<ds:SignedInfo>`, 1) +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(value) + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+
+	before, after, found := strings.Cut(element, "</saml:Issuer>")
+	require.True(t, found)
+	return before + "</saml:Issuer>" + signature + after
+}
+
+func samlAttribute(name, value string) string {
+	return `<saml:Attribute Name="` + name + `"><saml:AttributeValue>` + value + `</saml:AttributeValue></saml:Attribute>`
+}
+
+func samlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
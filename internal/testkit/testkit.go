@@ -0,0 +1,24 @@
+// Package testkit provides fluent builders for domain objects and golden-file
+// helpers for tests. Builders start from valid, deterministic defaults so a
+// test only spells out the fields it is actually about:
+//
+//	u := testkit.NewUserBuilder().WithEmail("jane@example.com").Build()
+//
+// Defaults use fixed IDs and the Epoch timestamp so results can be compared
+// against golden files.
+package testkit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Epoch is the fixed point in time every builder uses by default
+var Epoch = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Default identities shared by the builders so related objects line up
+var (
+	DefaultUserID = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	DefaultEmail  = "jane.doe@example.com"
+)
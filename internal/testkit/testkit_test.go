@@ -0,0 +1,59 @@
+package testkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+)
+
+func TestUserBuilder_GivenOverrides_WhenBuilding_ThenKeepsDefaultsForTheRest(t *testing.T) {
+	builder := testkit.NewUserBuilder().WithEmail("john@example.com").WithName("John", "Smith")
+
+	first := builder.Build()
+	first.FirstName = "Changed"
+	second := builder.Build()
+
+	assert.Equal(t, testkit.DefaultUserID, second.ID)
+	assert.Equal(t, "john@example.com", second.Email)
+	assert.Equal(t, "John Smith", second.GetFullName())
+	assert.Equal(t, testkit.Epoch, second.CreatedAt)
+	assert.Equal(t, "john@example.com", builder.BuildRegisterData().Email)
+}
+
+func TestPreferencesBuilder_GivenBuiltPreferences_WhenMutated_ThenBuilderIsUnaffected(t *testing.T) {
+	builder := testkit.NewPreferencesBuilder().WithTheme("dark").WithNotificationType("marketing", false)
+
+	first := builder.Build()
+	first.NotificationTypes["marketing"] = true
+
+	assert.False(t, builder.Build().NotificationTypes["marketing"])
+	assert.Equal(t, "dark", builder.Build().Theme)
+}
+
+func TestClaimsBuilder_GivenCustomClaims_WhenSigning_ThenValidatedTokenCarriesThem(t *testing.T) {
+	service := testkit.NewTokenService(t)
+
+	signed := testkit.NewClaimsBuilder().
+		ForUser("user-1", "user-1@example.com").
+		WithCustomClaim("tenant", "acme").
+		Sign(t, service)
+
+	claims, err := service.ValidateToken(context.Background(), signed)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "acme", claims.Custom["tenant"])
+}
+
+func TestGolden_GivenBuilderDefaults_WhenSerialized_ThenMatchGoldenFiles(t *testing.T) {
+	testkit.AssertGolden(t, "user", testkit.NewUserBuilder().Build())
+	testkit.AssertGolden(t, "event", testkit.NewEventBuilder().
+		WithType(events.EventTypeUserUpdated).
+		WithData("field", "email").
+		WithCorrelationID("corr-1", "").
+		Build())
+}
@@ -0,0 +1,118 @@
+package testkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+)
+
+// TestSecret signs every token issued by NewTokenService
+var TestSecret = []byte("test-secret-key-for-testing-only")
+
+// TokenConfig returns an HS256 token configuration suitable for tests
+func TokenConfig() token.TokenConfig {
+	return token.TokenConfig{
+		Secret:          TestSecret,
+		AccessTTL:       15 * time.Minute,
+		RefreshTTL:      time.Hour,
+		ResetTTL:        time.Hour,
+		VerificationTTL: time.Hour,
+		Algorithm:       "HS256",
+	}
+}
+
+// NewTokenService creates a JWT token service signed with TestSecret
+func NewTokenService(t testing.TB) token.Service {
+	t.Helper()
+	service, err := jwt.NewService(TokenConfig())
+	require.NoError(t, err)
+	return service
+}
+
+// ClaimsBuilder builds token.TokenClaims values and signed tokens carrying them
+type ClaimsBuilder struct {
+	claims token.TokenClaims
+}
+
+// NewClaimsBuilder starts from valid access token claims for DefaultUserID
+func NewClaimsBuilder() *ClaimsBuilder {
+	return &ClaimsBuilder{claims: token.TokenClaims{
+		UserID:    DefaultUserID.String(),
+		Email:     DefaultEmail,
+		TokenType: "auth",
+		IssuedAt:  Epoch,
+		ExpiresAt: Epoch.Add(15 * time.Minute),
+		JTI:       uuid.MustParse("00000000-0000-4000-8000-0000000000a1").String(),
+		Custom:    map[string]interface{}{},
+	}}
+}
+
+// ForUser sets the subject of the token
+func (b *ClaimsBuilder) ForUser(userID, email string) *ClaimsBuilder {
+	b.claims.UserID = userID
+	b.claims.Email = email
+	return b
+}
+
+// WithTokenType sets the token type (auth, refresh, reset, verification)
+func (b *ClaimsBuilder) WithTokenType(tokenType string) *ClaimsBuilder {
+	b.claims.TokenType = tokenType
+	return b
+}
+
+// WithLifetime sets the issue and expiry times
+func (b *ClaimsBuilder) WithLifetime(issuedAt time.Time, ttl time.Duration) *ClaimsBuilder {
+	b.claims.IssuedAt = issuedAt
+	b.claims.ExpiresAt = issuedAt.Add(ttl)
+	return b
+}
+
+// Expired moves the expiry into the past relative to the wall clock
+func (b *ClaimsBuilder) Expired() *ClaimsBuilder {
+	b.claims.IssuedAt = time.Now().Add(-time.Hour)
+	b.claims.ExpiresAt = time.Now().Add(-time.Minute)
+	return b
+}
+
+// WithIssuer sets the issuer and audience
+func (b *ClaimsBuilder) WithIssuer(issuer, audience string) *ClaimsBuilder {
+	b.claims.Issuer = issuer
+	b.claims.Audience = audience
+	return b
+}
+
+// WithCustomClaim adds a non-reserved custom claim
+func (b *ClaimsBuilder) WithCustomClaim(name string, value interface{}) *ClaimsBuilder {
+	b.claims.Custom[name] = value
+	return b
+}
+
+// Build returns a new copy of the claims
+func (b *ClaimsBuilder) Build() *token.TokenClaims {
+	claims := b.claims
+	claims.Custom = make(map[string]interface{}, len(b.claims.Custom))
+	for name, value := range b.claims.Custom {
+		claims.Custom[name] = value
+	}
+	return &claims
+}
+
+// Sign issues a real access token for the claims' subject, embedding the
+// custom claims. Times are chosen by the service, not the builder.
+func (b *ClaimsBuilder) Sign(t testing.TB, service token.Service) string {
+	t.Helper()
+	ctx := context.Background()
+	if len(b.claims.Custom) > 0 {
+		ctx = token.WithExtraClaims(ctx, b.Build().Custom)
+	}
+
+	signed, _, err := service.GenerateAuthToken(ctx, b.claims.UserID, b.claims.Email)
+	require.NoError(t, err)
+	return signed
+}
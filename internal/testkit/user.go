@@ -0,0 +1,159 @@
+package testkit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// UserBuilder builds user.User values
+type UserBuilder struct {
+	user user.User
+}
+
+// NewUserBuilder starts from a valid user with deterministic defaults
+func NewUserBuilder() *UserBuilder {
+	return &UserBuilder{user: user.User{
+		ID:        DefaultUserID,
+		Email:     DefaultEmail,
+		FirstName: "Jane",
+		LastName:  "Doe",
+		CreatedAt: Epoch,
+		UpdatedAt: Epoch,
+		Version:   1,
+	}}
+}
+
+// WithID sets the user ID
+func (b *UserBuilder) WithID(id string) *UserBuilder {
+	b.user.ID = uuid.MustParse(id)
+	return b
+}
+
+// WithRandomID gives the user a fresh random ID
+func (b *UserBuilder) WithRandomID() *UserBuilder {
+	b.user.ID = uuid.New()
+	return b
+}
+
+// WithEmail sets the email address
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+// WithName sets the first and last name
+func (b *UserBuilder) WithName(firstName, lastName string) *UserBuilder {
+	b.user.FirstName = firstName
+	b.user.LastName = lastName
+	return b
+}
+
+// WithPasswordHash sets the stored password hash
+func (b *UserBuilder) WithPasswordHash(hash string) *UserBuilder {
+	b.user.PasswordHash = hash
+	return b
+}
+
+// WithVersion sets the optimistic locking version
+func (b *UserBuilder) WithVersion(version int) *UserBuilder {
+	b.user.Version = version
+	return b
+}
+
+// Build returns a new copy of the user
+func (b *UserBuilder) Build() *user.User {
+	u := b.user
+	return &u
+}
+
+// BuildRegisterData returns registration data matching the user
+func (b *UserBuilder) BuildRegisterData() user.RegisterData {
+	return user.RegisterData{
+		Email:     b.user.Email,
+		Password:  "Str0ngPassw0rd!",
+		FirstName: b.user.FirstName,
+		LastName:  b.user.LastName,
+	}
+}
+
+// BuildAuthResult returns a login result for the user
+func (b *UserBuilder) BuildAuthResult() *user.AuthResult {
+	return &user.AuthResult{
+		User:         b.Build(),
+		Token:        "access-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    Epoch.Add(15 * time.Minute),
+	}
+}
+
+// PreferencesBuilder builds user.UserPreferences values
+type PreferencesBuilder struct {
+	prefs user.UserPreferences
+}
+
+// NewPreferencesBuilder starts from the default preferences of DefaultUserID
+func NewPreferencesBuilder() *PreferencesBuilder {
+	return &PreferencesBuilder{prefs: user.UserPreferences{
+		ID:                 uuid.MustParse("00000000-0000-4000-8000-0000000000f1"),
+		UserID:             DefaultUserID,
+		EmailNotifications: true,
+		PushNotifications:  true,
+		Theme:              "light",
+		Language:           "en",
+		Timezone:           "UTC",
+		NotificationTypes:  map[user.NotificationType]bool{},
+		CreatedAt:          Epoch,
+		UpdatedAt:          Epoch,
+	}}
+}
+
+// ForUser sets the owner of the preferences
+func (b *PreferencesBuilder) ForUser(userID string) *PreferencesBuilder {
+	b.prefs.UserID = uuid.MustParse(userID)
+	return b
+}
+
+// WithTheme sets the theme
+func (b *PreferencesBuilder) WithTheme(theme string) *PreferencesBuilder {
+	b.prefs.Theme = theme
+	return b
+}
+
+// WithLanguage sets the language
+func (b *PreferencesBuilder) WithLanguage(language string) *PreferencesBuilder {
+	b.prefs.Language = language
+	return b
+}
+
+// WithTimezone sets the timezone
+func (b *PreferencesBuilder) WithTimezone(timezone string) *PreferencesBuilder {
+	b.prefs.Timezone = timezone
+	return b
+}
+
+// WithChannels sets which notification channels are enabled
+func (b *PreferencesBuilder) WithChannels(email, push, sms bool) *PreferencesBuilder {
+	b.prefs.EmailNotifications = email
+	b.prefs.PushNotifications = push
+	b.prefs.SMSNotifications = sms
+	return b
+}
+
+// WithNotificationType enables or disables a notification type
+func (b *PreferencesBuilder) WithNotificationType(notificationType user.NotificationType, enabled bool) *PreferencesBuilder {
+	b.prefs.NotificationTypes[notificationType] = enabled
+	return b
+}
+
+// Build returns a new copy of the preferences
+func (b *PreferencesBuilder) Build() *user.UserPreferences {
+	prefs := b.prefs
+	prefs.NotificationTypes = make(map[user.NotificationType]bool, len(b.prefs.NotificationTypes))
+	for notificationType, enabled := range b.prefs.NotificationTypes {
+		prefs.NotificationTypes[notificationType] = enabled
+	}
+	return &prefs
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+)
+
+// service implements throttle.Service in memory, for single instances and tests
+type service struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is the state kept for one key
+type entry struct {
+	attempts  throttle.Attempts
+	expiresAt time.Time
+}
+
+// NewService creates an in-memory throttle store
+func NewService() throttle.Service {
+	return &service{entries: make(map[string]entry)}
+}
+
+// Attempts returns the key's failures until they expire
+func (s *service) Attempts(ctx context.Context, key string) (throttle.Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.expiresAt) {
+		return throttle.Attempts{}, nil
+	}
+	return e.attempts, nil
+}
+
+// RecordFailure counts the failure and extends the key's expiry to ttl from now
+func (s *service) RecordFailure(ctx context.Context, key string, ttl time.Duration) (throttle.Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e := s.entries[key]
+	if !now.Before(e.expiresAt) {
+		e = entry{}
+	}
+	e.attempts.Failures++
+	e.attempts.LastFailure = now
+	e.expiresAt = now.Add(ttl)
+	s.entries[key] = e
+	s.evictExpired(now)
+	return e.attempts, nil
+}
+
+// Reset drops everything kept for the key
+func (s *service) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Helper methods
+
+// evictExpired drops keys whose failures expired, so the map does not grow
+// without bound; the caller must hold the lock
+func (s *service) evictExpired(now time.Time) {
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/throttle/memory"
+)
+
+func TestThrottle_GivenFailures_WhenRecording_ThenCountsThemWithLatestTime(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	before := time.Now()
+
+	_, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	recorded, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	attempts, err := store.Attempts(ctx, "jane@example.com|203.0.113.7")
+
+	require.NoError(t, err)
+	assert.Equal(t, recorded, attempts)
+	assert.Equal(t, 2, attempts.Failures)
+	assert.False(t, attempts.LastFailure.Before(before))
+}
+
+func TestThrottle_GivenExpiredFailures_WhenReading_ThenHasNone(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	attempts, err := store.Attempts(ctx, "jane@example.com|203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, attempts.Failures)
+
+	recorded, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recorded.Failures)
+}
+
+func TestThrottle_GivenFailures_WhenResetting_ThenForgetsThem(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	_, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Reset(ctx, "jane@example.com|203.0.113.7"))
+	attempts, err := store.Attempts(ctx, "jane@example.com|203.0.113.7")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts.Failures)
+	assert.True(t, attempts.LastFailure.IsZero())
+}
+
+func TestThrottle_GivenSeparateKeys_WhenRecording_ThenCountsEachOnItsOwn(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+
+	_, err := store.RecordFailure(ctx, "jane@example.com|203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	attempts, err := store.Attempts(ctx, "jane@example.com|198.51.100.1")
+
+	require.NoError(t, err)
+	assert.Zero(t, attempts.Failures)
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+)
+
+// DefaultKeyPrefix namespaces throttle state in a shared Redis
+const DefaultKeyPrefix = "throttle:"
+
+// Hash fields holding a key's attempts
+const (
+	failuresField    = "failures"
+	lastFailureField = "last_failure"
+)
+
+// service implements throttle.Service on Redis, so every instance slows down
+// the same attempts. Each key is a hash of its failure count and the time of
+// the latest failure, expiring with the key TTL.
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed throttle store using DefaultKeyPrefix
+func NewService(client *redis.Client) throttle.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed throttle store whose keys start
+// with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) throttle.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// Attempts reads the key's hash; an expired or missing key has no failures
+func (s *service) Attempts(ctx context.Context, key string) (throttle.Attempts, error) {
+	values, err := s.client.HGetAll(ctx, s.prefix+key).Result()
+	if err != nil {
+		return throttle.Attempts{}, err
+	}
+	return parseAttempts(values[failuresField], values[lastFailureField])
+}
+
+// RecordFailure increments the failure count, stores the failure time and
+// moves the key's expiry to ttl from now
+func (s *service) RecordFailure(ctx context.Context, key string, ttl time.Duration) (throttle.Attempts, error) {
+	now := time.Now()
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.HIncrBy(ctx, s.prefix+key, failuresField, 1)
+		pipe.HSet(ctx, s.prefix+key, lastFailureField, now.UnixMilli())
+		pipe.PExpire(ctx, s.prefix+key, ttl)
+		return nil
+	})
+	if err != nil {
+		return throttle.Attempts{}, err
+	}
+	return throttle.Attempts{Failures: int(incr.Val()), LastFailure: time.UnixMilli(now.UnixMilli())}, nil
+}
+
+// Reset deletes the key's hash
+func (s *service) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Helper methods
+
+// parseAttempts converts the stored hash fields; empty fields mean no failures
+func parseAttempts(failures, lastFailure string) (throttle.Attempts, error) {
+	if failures == "" {
+		return throttle.Attempts{}, nil
+	}
+
+	count, err := strconv.Atoi(failures)
+	if err != nil {
+		return throttle.Attempts{}, err
+	}
+	attempts := throttle.Attempts{Failures: count}
+	if lastFailure != "" {
+		millis, err := strconv.ParseInt(lastFailure, 10, 64)
+		if err != nil {
+			return throttle.Attempts{}, err
+		}
+		attempts.LastFailure = time.UnixMilli(millis)
+	}
+	return attempts, nil
+}
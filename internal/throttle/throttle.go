@@ -0,0 +1,29 @@
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the throttle domain interface - the ONLY interface in this domain.
+// It remembers the failed attempts made under a key, such as an email and IP
+// address pair, so callers can slow down each further attempt.
+type Service interface {
+	// Attempts returns the failures counted against key, or zero Attempts
+	// when there are none
+	Attempts(ctx context.Context, key string) (Attempts, error)
+
+	// RecordFailure counts a failed attempt against key and returns the
+	// updated Attempts. The failures are forgotten once ttl passes without
+	// another one.
+	RecordFailure(ctx context.Context, key string, ttl time.Duration) (Attempts, error)
+
+	// Reset forgets the failed attempts of key
+	Reset(ctx context.Context, key string) error
+}
+
+// Attempts are the failed attempts counted against a key
+type Attempts struct {
+	Failures    int
+	LastFailure time.Time
+}
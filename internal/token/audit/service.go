@@ -0,0 +1,308 @@
+package audit
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements token.Service with audit logging capabilities
+// Issuances, refreshes and revocations are audited whether they succeed or
+// not. Validations run on every authenticated request, so they are left to
+// the auth service's audit trail. Entries never contain tokens.
+type service struct {
+	next         token.Service
+	auditService audit.Service
+}
+
+// NewService creates a new audit-enabled token service
+func NewService(next token.Service, auditService audit.Service) token.Service {
+	return &service{
+		next:         next,
+		auditService: auditService,
+	}
+}
+
+// GenerateAuthToken generates an auth token with audit logging
+func (s *service) GenerateAuthToken(ctx context.Context, userID string, email string) (string, time.Time, error) {
+	// Call next service
+	tokenString, expiresAt, err := s.next.GenerateAuthToken(ctx, userID, email)
+
+	// Log audit entry
+	details := issueDetails(tokenpolicy.TokenTypeAuth, expiresAt)
+	details["email"] = email
+	s.logAuditEntry(ctx, "token.issue", "", userID, details, err)
+
+	return tokenString, expiresAt, err
+}
+
+// GenerateAuthTokenWithClaims generates an auth token with audit logging of
+// the names of the extra claims, whose values may be personal data
+func (s *service) GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error) {
+	// Call next service
+	tokenString, expiresAt, err := s.next.GenerateAuthTokenWithClaims(ctx, userID, email, extra)
+
+	// Log audit entry
+	details := issueDetails(tokenpolicy.TokenTypeAuth, expiresAt)
+	details["email"] = email
+	details["claims"] = slices.Sorted(maps.Keys(extra))
+	s.logAuditEntry(ctx, "token.issue", "", userID, details, err)
+
+	return tokenString, expiresAt, err
+}
+
+// GenerateRefreshToken generates a refresh token with audit logging
+func (s *service) GenerateRefreshToken(ctx context.Context, userID string) (string, error) {
+	// Call next service
+	tokenString, err := s.next.GenerateRefreshToken(ctx, userID)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.issue", "", userID, issueDetails(tokenpolicy.TokenTypeRefresh, time.Time{}), err)
+
+	return tokenString, err
+}
+
+// GenerateAPIToken generates an API token with audit logging of its scopes
+func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*token.APIToken, error) {
+	// Call next service
+	apiToken, err := s.next.GenerateAPIToken(ctx, userID, scopes)
+
+	// Log audit entry
+	var expiresAt time.Time
+	if apiToken != nil {
+		expiresAt = apiToken.ExpiresAt
+	}
+	details := issueDetails(tokenpolicy.TokenTypeAPI, expiresAt)
+	details["scopes"] = scopes
+	if apiToken != nil {
+		details["token_id"] = apiToken.ID
+	}
+	s.logAuditEntry(ctx, "token.issue", "", userID, details, err)
+
+	return apiToken, err
+}
+
+// GeneratePasswordResetToken generates a password reset token with audit logging
+func (s *service) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	// Call next service
+	tokenString, err := s.next.GeneratePasswordResetToken(ctx, userID)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.issue", "", userID, issueDetails(tokenpolicy.TokenTypeReset, time.Time{}), err)
+
+	return tokenString, err
+}
+
+// GenerateEmailVerificationToken generates an email verification token with audit logging
+func (s *service) GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
+	// Call next service
+	tokenString, err := s.next.GenerateEmailVerificationToken(ctx, userID)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.issue", "", userID, issueDetails(tokenpolicy.TokenTypeVerification, time.Time{}), err)
+
+	return tokenString, err
+}
+
+// GenerateImpersonationToken generates an impersonation token with audit
+// logging, attributed to the acting admin
+func (s *service) GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*token.ImpersonationToken, error) {
+	// Call next service
+	impersonation, err := s.next.GenerateImpersonationToken(ctx, actorID, userID, scopes)
+
+	// Log audit entry
+	var expiresAt time.Time
+	if impersonation != nil {
+		expiresAt = impersonation.ExpiresAt
+	}
+	details := issueDetails(tokenpolicy.TokenTypeImpersonation, expiresAt)
+	details["scopes"] = scopes
+	s.logAuditEntry(ctx, "token.issue", actorID, userID, details, err)
+
+	return impersonation, err
+}
+
+// GenerateOneTimeToken generates a one-time token with audit logging of its purpose
+func (s *service) GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	// Call next service
+	tokenString, err := s.next.GenerateOneTimeToken(ctx, purpose, subject, ttl)
+
+	// Log audit entry
+	details := issueDetails(tokenpolicy.TokenTypeOneTime, time.Time{})
+	details["purpose"] = purpose
+	details["ttl"] = ttl.String()
+	s.logAuditEntry(ctx, "token.issue", "", subject, details, err)
+
+	return tokenString, err
+}
+
+// ConsumeOneTimeToken consumes a one-time token with audit logging of its
+// purpose and ID; failed attempts are audited too, as they may be replays
+func (s *service) ConsumeOneTimeToken(ctx context.Context, tokenString, purpose string) (*token.OneTimeClaims, error) {
+	// Call next service
+	claims, err := s.next.ConsumeOneTimeToken(ctx, tokenString, purpose)
+
+	// Log audit entry
+	var subject string
+	details := map[string]interface{}{"token_type": tokenpolicy.TokenTypeOneTime, "purpose": purpose}
+	if claims != nil {
+		subject = claims.Subject
+		details["token_id"] = claims.JTI
+	}
+	s.logAuditEntry(ctx, "token.consume", "", subject, details, err)
+
+	return claims, err
+}
+
+// ValidateToken passes through
+func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidateToken(ctx, tokenString)
+}
+
+// ValidateAPIToken passes through
+func (s *service) ValidateAPIToken(ctx context.Context, tokenString string) (*token.APITokenClaims, error) {
+	return s.next.ValidateAPIToken(ctx, tokenString)
+}
+
+// ValidatePasswordResetToken passes through
+func (s *service) ValidatePasswordResetToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidatePasswordResetToken(ctx, tokenString)
+}
+
+// ValidateEmailVerificationToken passes through
+func (s *service) ValidateEmailVerificationToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidateEmailVerificationToken(ctx, tokenString)
+}
+
+// ValidateImpersonationToken passes through
+func (s *service) ValidateImpersonationToken(ctx context.Context, tokenString string) (*token.ImpersonationClaims, error) {
+	return s.next.ValidateImpersonationToken(ctx, tokenString)
+}
+
+// RefreshToken exchanges a refresh token with audit logging of its owner and ID
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*token.TokenPair, error) {
+	// Look the token up first, as rotation may revoke it
+	userID, details := s.describe(ctx, refreshToken)
+
+	// Call next service
+	pair, err := s.next.RefreshToken(ctx, refreshToken)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.refresh", "", userID, details, err)
+
+	return pair, err
+}
+
+// RevokeToken revokes a token with audit logging of its owner and ID
+func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
+	// Look the token up first, as it no longer validates once revoked
+	userID, details := s.describe(ctx, tokenString)
+
+	// Call next service
+	err := s.next.RevokeToken(ctx, tokenString)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.revoke", "", userID, details, err)
+
+	return err
+}
+
+// RevokeAllTokensForUser revokes every token of the user with audit logging
+func (s *service) RevokeAllTokensForUser(ctx context.Context, userID string) error {
+	// Call next service
+	err := s.next.RevokeAllTokensForUser(ctx, userID)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "token.revoke_all", "", userID, nil, err)
+
+	return err
+}
+
+// GetTokenInfo passes through
+func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.TokenInfo, error) {
+	return s.next.GetTokenInfo(ctx, tokenString)
+}
+
+// ListActiveTokens passes through
+func (s *service) ListActiveTokens(ctx context.Context, userID string) ([]token.TokenInfo, error) {
+	return s.next.ListActiveTokens(ctx, userID)
+}
+
+// PublicKeys passes through
+func (s *service) PublicKeys(ctx context.Context) (*token.JWKSet, error) {
+	return s.next.PublicKeys(ctx)
+}
+
+// Helper methods
+
+// logAuditEntry logs an audit entry about the tokens of userID. The entry
+// is attributed to actorID, or else to the current user of the audit
+// context, or else to userID itself.
+func (s *service) logAuditEntry(ctx context.Context, action, actorID, userID string, details interface{}, err error) {
+	auditCtx := audit.ExtractAuditContext(ctx)
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        auditCtx.CurrentUserID,
+		Action:        action,
+		Resource:      "token",
+		ResourceID:    userID,
+		Details:       details,
+		Success:       err == nil,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+
+	if actorID != "" {
+		entry.UserID = actorID
+	}
+	if entry.UserID == "" {
+		entry.UserID = userID
+	}
+	userAgent, ipAddress := token.ExtractDevice(ctx)
+	if entry.UserAgent == "" {
+		entry.UserAgent = userAgent
+	}
+	if entry.IPAddress == "" {
+		entry.IPAddress = ipAddress
+	}
+	if entry.IPAddress == "" {
+		entry.IPAddress = user.ClientIPFromContext(ctx)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	// Log the entry on a detached context so the trail is recorded even when
+	// the caller disconnected mid-request.
+	// Don't fail the operation if audit logging fails
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+	s.auditService.Log(ctx, entry)
+}
+
+// describe returns the owner of a token and its ID and type, or nothing for
+// tokens that do not validate
+func (s *service) describe(ctx context.Context, tokenString string) (string, map[string]interface{}) {
+	info, err := s.next.GetTokenInfo(ctx, tokenString)
+	if err != nil || info == nil {
+		return "", nil
+	}
+	return info.UserID, map[string]interface{}{"token_id": info.ID, "token_type": info.TokenType}
+}
+
+// issueDetails describes an issued token without the token itself
+func issueDetails(tokenType string, expiresAt time.Time) map[string]interface{} {
+	details := map[string]interface{}{"token_type": tokenType}
+	if !expiresAt.IsZero() {
+		details["expires_at"] = expiresAt
+	}
+	return details
+}
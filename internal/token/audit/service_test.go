@@ -0,0 +1,123 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	tokenAudit "github.com/gentra/decorator-arch-go/internal/token/audit"
+)
+
+func auditEntries(t *testing.T, auditService audit.Service) []audit.AuditEntry {
+	t.Helper()
+	entries, err := auditService.GetAuditLogs(context.Background(), audit.AuditFilters{Resource: "token"})
+	require.NoError(t, err)
+	return entries
+}
+
+func TestAudit_GivenIssuedToken_WhenRevoked_ThenAuditsBothWithoutTheToken(t *testing.T) {
+	// Arrange
+	auditService := auditMemory.NewService()
+	service := tokenAudit.NewService(testkit.NewTokenService(t), auditService)
+	ctx := token.WithDevice(audit.WithCorrelationID(context.Background(), "req-1"), "test-agent", "203.0.113.7")
+
+	// Act
+	apiToken, err := service.GenerateAPIToken(ctx, "user-123", []string{"read"})
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeToken(ctx, apiToken.Token))
+
+	// Assert
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 2)
+	actions := map[string]audit.AuditEntry{}
+	for _, entry := range entries {
+		actions[entry.Action] = entry
+		assert.True(t, entry.Success)
+		assert.Equal(t, "user-123", entry.UserID)
+		assert.Equal(t, "user-123", entry.ResourceID)
+		assert.Equal(t, "203.0.113.7", entry.IPAddress)
+		assert.Equal(t, "test-agent", entry.UserAgent)
+		assert.Equal(t, "req-1", entry.CorrelationID)
+		assert.NotContains(t, entry.Details, "token")
+	}
+
+	issued := actions["token.issue"].Details.(map[string]interface{})
+	assert.Equal(t, "api", issued["token_type"])
+	assert.Equal(t, []string{"read"}, issued["scopes"])
+	assert.Equal(t, apiToken.ID, issued["token_id"])
+
+	revoked := actions["token.revoke"].Details.(map[string]interface{})
+	assert.Equal(t, apiToken.ID, revoked["token_id"])
+}
+
+func TestAudit_GivenImpersonation_WhenIssued_ThenAttributesEntryToTheActor(t *testing.T) {
+	// Arrange
+	auditService := auditMemory.NewService()
+	service := tokenAudit.NewService(testkit.NewTokenService(t), auditService)
+
+	// Act
+	_, err := service.GenerateImpersonationToken(context.Background(), "admin-1", "user-123", []string{"read"})
+	require.NoError(t, err)
+
+	// Assert
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "admin-1", entries[0].UserID)
+	assert.Equal(t, "user-123", entries[0].ResourceID)
+}
+
+func TestAudit_GivenInvalidToken_WhenRevokedOrValidated_ThenAuditsOnlyTheRevocation(t *testing.T) {
+	// Arrange
+	auditService := auditMemory.NewService()
+	service := tokenAudit.NewService(testkit.NewTokenService(t), auditService)
+	ctx := context.Background()
+
+	// Act
+	_, validateErr := service.ValidateToken(ctx, "not-a-token")
+	revokeErr := service.RevokeToken(ctx, "not-a-token")
+
+	// Assert
+	assert.Error(t, validateErr)
+	assert.Error(t, revokeErr)
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "token.revoke", entries[0].Action)
+	assert.False(t, entries[0].Success)
+	assert.NotEmpty(t, entries[0].Error)
+}
+
+func TestAudit_GivenOneTimeToken_WhenConsumedTwice_ThenAuditsEveryAttempt(t *testing.T) {
+	// Arrange
+	auditService := auditMemory.NewService()
+	service := tokenAudit.NewService(testkit.NewTokenService(t), auditService)
+	ctx := context.Background()
+	oneTimeToken, err := service.GenerateOneTimeToken(ctx, "magic_link", "user-123", time.Minute)
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+	require.NoError(t, err)
+	_, err = service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+	assert.Equal(t, token.ErrTokenAlreadyUsed, err)
+
+	// Assert
+	entries := auditEntries(t, auditService)
+	require.Len(t, entries, 3)
+	outcomes := map[bool]int{}
+	for _, entry := range entries {
+		details := entry.Details.(map[string]interface{})
+		assert.Equal(t, "magic_link", details["purpose"])
+		assert.NotContains(t, details, "token")
+		if entry.Action == "token.consume" {
+			outcomes[entry.Success]++
+		}
+	}
+	assert.Equal(t, map[bool]int{true: 1, false: 1}, outcomes)
+}
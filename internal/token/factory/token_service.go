@@ -1,12 +1,29 @@
 package factory
 
 import (
+	"context"
+	"crypto"
 	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/rotation"
 	"github.com/gentra/decorator-arch-go/internal/token"
+	tokenAudit "github.com/gentra/decorator-arch-go/internal/token/audit"
 	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+	tokenMetrics "github.com/gentra/decorator-arch-go/internal/token/metrics"
+	"github.com/gentra/decorator-arch-go/internal/token/opaque"
+	"github.com/gentra/decorator-arch-go/internal/token/policy"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
 )
 
 // Config contains all configuration for building the token service
@@ -21,24 +38,67 @@ type Config struct {
 	AutoGenerateSecret bool
 	SecretSize         int
 
-	// RSA/ECDSA keys (for future implementation)
+	// PEM files holding the RS256 or ES256 key pair, used when the JWT
+	// configuration has no SigningKey or VerificationKey of its own. With
+	// only a public key the service validates tokens but cannot issue them.
 	PrivateKeyPath string
 	PublicKeyPath  string
 
-	// Token storage (for opaque tokens)
+	// PEM public keys of replaced signing keys, kept for verification and
+	// published until the tokens they signed expire
+	RetiredKeyFiles []RetiredKeyFile
+
+	// PEM file holding the RSA key pair of RSA-OAEP-256 token encryption,
+	// used when the JWT configuration's encryption has no PrivateKey
+	EncryptionKeyPath string
+
+	// KeyStore keeps the keys generated when EnableRotation is set. It
+	// defaults to an in-memory store, whose keys are lost on restart along
+	// with every token they signed; instances sharing tokens need a shared
+	// store.
+	KeyStore signingkey.Service
+
+	// Token storage (for opaque tokens). TokenStore keeps their claims;
+	// without one only the "memory" provider works, which keeps them in
+	// process and loses them on restart.
 	StorageProvider string // "memory", "redis", "database"
 	StorageConfig   map[string]interface{}
+	TokenStore      tokenstore.Service
 
-	// Security settings
+	// TokenRegistry records the JWTs issued, so users can list their active
+	// tokens and are held to JWTConfig.MaxActiveTokens by revoking the
+	// oldest. Without one JWTs are not listed.
+	TokenRegistry tokenstore.Service
+
+	// Security settings. With EnableBlacklist JWT revocations are recorded
+	// in RevocationStore, or in memory without one, and user revocations are
+	// kept for at least BlacklistTTL. Without it they stay in process.
 	EnableBlacklist  bool
 	BlacklistTTL     time.Duration
+	RevocationStore  revocation.Service
 	EnableRotation   bool
 	RotationInterval time.Duration
 
+	// Issuance policies run before and after every token is issued
+	Policies []tokenpolicy.Service
+
+	// Audit trail of issuances and revocations; required when
+	// EnableAuditLogging is set
+	AuditService audit.Service
+
+	// Registerer for token service metrics; nil uses prometheus.DefaultRegisterer
+	MetricsRegisterer prometheus.Registerer
+
 	// Feature flags
 	Features FeatureFlags
 }
 
+// RetiredKeyFile is the PEM public key file of a replaced signing key
+type RetiredKeyFile struct {
+	Path      string
+	RetiredAt time.Time
+}
+
 // FeatureFlags controls token service behavior
 type FeatureFlags struct {
 	EnableJWTProvider        bool
@@ -92,40 +152,230 @@ func (f *TokenServiceFactory) Build() (token.Service, error) {
 	// Prepare token configuration
 	tokenConfig := f.config.JWTConfig
 
-	// Auto-generate secret if needed
-	if f.config.AutoGenerateSecret && len(tokenConfig.Secret) == 0 {
-		secret, err := f.generateSecret()
+	if f.config.Features.EnableAuditLogging && f.config.AuditService == nil {
+		return nil, fmt.Errorf("audit service is required when audit logging is enabled")
+	}
+
+	// Opaque tokens are not signed, so they need none of the key setup
+	if f.config.Provider == "opaque" {
+		service, err := f.buildOpaqueService(tokenConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+			return nil, err
+		}
+		return f.decorate(service)
+	}
+
+	var keyStore signingkey.Service
+	if f.config.EnableRotation {
+		var err error
+		if keyStore, err = f.buildKeyStore(&tokenConfig); err != nil {
+			return nil, err
 		}
-		tokenConfig.Secret = secret
+	}
+
+	if tokenConfig.Algorithm == token.AlgorithmHS256 {
+		// Auto-generate secret if needed
+		if f.config.AutoGenerateSecret && len(tokenConfig.Secret) == 0 {
+			secret, err := f.generateSecret()
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+			}
+			tokenConfig.Secret = secret
+		}
+	} else {
+		if keyStore == nil {
+			if err := f.loadKeys(&tokenConfig); err != nil {
+				return nil, err
+			}
+		}
+		// A secret alongside an asymmetric key keeps HS256 tokens valid,
+		// which is only wanted while HMAC signatures are enabled
+		if !f.config.Features.EnableHMACSignature {
+			tokenConfig.Secret = nil
+		}
+	}
+
+	if err := f.loadEncryptionKey(&tokenConfig); err != nil {
+		return nil, err
 	}
 
 	// Validate configuration
 	if !tokenConfig.IsValid() {
 		return nil, fmt.Errorf("invalid token configuration")
 	}
+	if !f.signatureEnabled(tokenConfig.Algorithm) {
+		return nil, fmt.Errorf("%s signatures are not enabled", tokenConfig.Algorithm)
+	}
+
+	// Any other provider defaults to JWT
+	service, err := f.buildJWTService(tokenConfig, keyStore)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.decorate(service)
+}
+
+// decorate wraps the provider in the enabled decorators - pure composition,
+// no business logic in factory
+func (f *TokenServiceFactory) decorate(service token.Service) (token.Service, error) {
+	// Add policy layer if any issuance policies are registered; innermost so
+	// audit and metrics see the tokens policies refuse too
+	if len(f.config.Policies) > 0 {
+		service = policy.NewService(service, f.config.Policies...)
+	}
 
-	switch f.config.Provider {
-	case "jwt":
-		return f.buildJWTService(tokenConfig)
-	case "opaque":
-		return f.buildOpaqueService()
+	// Add audit layer if enabled
+	if f.config.Features.EnableAuditLogging {
+		service = tokenAudit.NewService(service, f.config.AuditService)
+	}
+
+	// Add metrics layer if enabled; outermost so it measures the whole chain
+	if f.config.Features.EnableMetrics {
+		var err error
+		service, err = tokenMetrics.NewService(service, f.metricsRegisterer())
+		if err != nil {
+			return nil, fmt.Errorf("failed to add metrics layer: %w", err)
+		}
+	}
+
+	return service, nil
+}
+
+// metricsRegisterer returns the configured registerer or the Prometheus default
+func (f *TokenServiceFactory) metricsRegisterer() prometheus.Registerer {
+	if f.config.MetricsRegisterer == nil {
+		return prometheus.DefaultRegisterer
+	}
+	return f.config.MetricsRegisterer
+}
+
+// signatureEnabled reports whether the feature flags allow the algorithm
+func (f *TokenServiceFactory) signatureEnabled(algorithm string) bool {
+	switch algorithm {
+	case token.AlgorithmHS256:
+		return f.config.Features.EnableHMACSignature
+	case token.AlgorithmRS256:
+		return f.config.Features.EnableRSASignature
+	case token.AlgorithmES256:
+		return f.config.Features.EnableECDSASignature
 	default:
-		// Default to JWT provider
-		return f.buildJWTService(tokenConfig)
+		return false
 	}
 }
 
-// buildJWTService creates a JWT-based token service
-func (f *TokenServiceFactory) buildJWTService(tokenConfig token.TokenConfig) (token.Service, error) {
-	return jwt.NewService(tokenConfig)
+// loadKeys reads the PEM key files into the token configuration; signing and
+// verification keys it already carries take precedence
+func (f *TokenServiceFactory) loadKeys(tokenConfig *token.TokenConfig) error {
+	if f.config.PrivateKeyPath != "" && tokenConfig.SigningKey == nil {
+		signingKey, err := jwt.LoadPrivateKey(f.config.PrivateKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load JWT signing key: %w", err)
+		}
+		tokenConfig.SigningKey = signingKey
+	}
+	if f.config.PublicKeyPath != "" && tokenConfig.VerificationKey == nil {
+		verificationKey, err := jwt.LoadPublicKey(f.config.PublicKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load JWT verification key: %w", err)
+		}
+		tokenConfig.VerificationKey = verificationKey
+	}
+	return f.loadRetiredKeys(tokenConfig)
 }
 
-// buildOpaqueService creates an opaque token service (placeholder)
-func (f *TokenServiceFactory) buildOpaqueService() (token.Service, error) {
-	// TODO: Implement opaque token service
-	return nil, fmt.Errorf("opaque token provider not yet implemented")
+// loadEncryptionKey reads the PEM RSA key pair of token encryption into the
+// token configuration, unless it already carries one
+func (f *TokenServiceFactory) loadEncryptionKey(tokenConfig *token.TokenConfig) error {
+	if f.config.EncryptionKeyPath == "" || tokenConfig.Encryption.PrivateKey != nil {
+		return nil
+	}
+	signer, err := jwt.LoadPrivateKey(f.config.EncryptionKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT encryption key: %w", err)
+	}
+	privateKey, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("JWT encryption key must be an RSA key")
+	}
+	tokenConfig.Encryption.PrivateKey = privateKey
+	return nil
+}
+
+// loadRetiredKeys reads the PEM public keys of replaced signing keys into the
+// token configuration
+func (f *TokenServiceFactory) loadRetiredKeys(tokenConfig *token.TokenConfig) error {
+	for _, retired := range f.config.RetiredKeyFiles {
+		retiredKey, err := jwt.LoadPublicKey(retired.Path)
+		if err != nil {
+			return fmt.Errorf("failed to load retired JWT key: %w", err)
+		}
+		tokenConfig.RetiredKeys = append(tokenConfig.RetiredKeys, token.RetiredKey{Key: retiredKey, RetiredAt: retired.RetiredAt})
+	}
+	return nil
+}
+
+// buildKeyStore wraps the key store with scheduled rotation and signs with
+// its current key, generating the first one if the store is empty. Retired
+// keys are kept for as long as the tokens they signed remain valid.
+func (f *TokenServiceFactory) buildKeyStore(tokenConfig *token.TokenConfig) (signingkey.Service, error) {
+	if tokenConfig.Algorithm == token.AlgorithmHS256 {
+		return nil, fmt.Errorf("key rotation requires RS256 or ES256 signatures")
+	}
+	if err := f.loadRetiredKeys(tokenConfig); err != nil {
+		return nil, err
+	}
+
+	store := f.config.KeyStore
+	if store == nil {
+		store = memory.NewService()
+	}
+	store = rotation.NewService(store, rotation.Config{
+		Algorithm: tokenConfig.Algorithm,
+		Interval:  f.config.RotationInterval,
+		Retention: jwt.TokenLifetime(*tokenConfig),
+	})
+
+	current, err := store.Current(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing key: %w", err)
+	}
+	tokenConfig.SigningKey = current.PrivateKey
+	tokenConfig.VerificationKey = nil
+	tokenConfig.KeyID = current.ID
+	return store, nil
+}
+
+// buildJWTService creates a JWT-based token service, signing with the keys
+// of keyStore when it is set, recording revocations in the revocation store
+// and issued tokens in the registry
+func (f *TokenServiceFactory) buildJWTService(tokenConfig token.TokenConfig, keyStore signingkey.Service) (token.Service, error) {
+	options := jwt.Options{Keys: keyStore, Registry: f.config.TokenRegistry}
+	if f.config.EnableBlacklist {
+		options.Revocations = f.config.RevocationStore
+		options.RevocationTTL = f.config.BlacklistTTL
+	}
+	return jwt.NewServiceWithOptions(tokenConfig, options)
+}
+
+// buildOpaqueService creates an opaque token service keeping claims in the
+// configured token store, or in memory for the "memory" storage provider,
+// spending one-time tokens in the revocation store
+func (f *TokenServiceFactory) buildOpaqueService(tokenConfig token.TokenConfig) (token.Service, error) {
+	if !f.config.Features.EnableOpaqueProvider {
+		return nil, fmt.Errorf("opaque token provider is not enabled")
+	}
+
+	store := f.config.TokenStore
+	if store == nil {
+		switch f.config.StorageProvider {
+		case "", "memory":
+			store = tokenStoreMemory.NewService()
+		default:
+			return nil, fmt.Errorf("token storage provider %q requires a token store", f.config.StorageProvider)
+		}
+	}
+	return opaque.NewServiceWithRevocations(tokenConfig, store, f.config.RevocationStore)
 }
 
 // generateSecret generates a random secret for JWT signing
@@ -205,6 +455,23 @@ func (b *ConfigBuilder) WithIssuerAndAudience(issuer, audience string) *ConfigBu
 	return b
 }
 
+// WithValidationOptions rejects tokens from other issuers or for other
+// audiences than the configured ones, and tolerates clockSkew of clock drift
+// when checking token lifetimes
+func (b *ConfigBuilder) WithValidationOptions(requireIssuer, requireAudience bool, clockSkew time.Duration) *ConfigBuilder {
+	b.config.JWTConfig.RequireIssuer = requireIssuer
+	b.config.JWTConfig.RequireAudience = requireAudience
+	b.config.JWTConfig.ClockSkew = clockSkew
+	return b
+}
+
+// WithClockSkew accepts tokens up to clockSkew past their expiry and before
+// their not-before or issued-at times, for instances whose clocks disagree
+func (b *ConfigBuilder) WithClockSkew(clockSkew time.Duration) *ConfigBuilder {
+	b.config.JWTConfig.ClockSkew = clockSkew
+	return b
+}
+
 // WithAlgorithm sets the JWT signing algorithm
 func (b *ConfigBuilder) WithAlgorithm(algorithm string) *ConfigBuilder {
 	b.config.JWTConfig.Algorithm = algorithm
@@ -215,9 +482,61 @@ func (b *ConfigBuilder) WithAlgorithm(algorithm string) *ConfigBuilder {
 func (b *ConfigBuilder) WithRSAKeys(privateKeyPath, publicKeyPath string) *ConfigBuilder {
 	b.config.PrivateKeyPath = privateKeyPath
 	b.config.PublicKeyPath = publicKeyPath
-	b.config.JWTConfig.Algorithm = "RS256"
-	b.config.Features.EnableRSASignature = true
-	b.config.Features.EnableHMACSignature = false
+	return b.useAlgorithm(token.AlgorithmRS256)
+}
+
+// WithECDSAKeys sets P-256 ECDSA private and public key paths
+func (b *ConfigBuilder) WithECDSAKeys(privateKeyPath, publicKeyPath string) *ConfigBuilder {
+	b.config.PrivateKeyPath = privateKeyPath
+	b.config.PublicKeyPath = publicKeyPath
+	return b.useAlgorithm(token.AlgorithmES256)
+}
+
+// WithSigningKey signs RS256 or ES256 tokens with the given signer, such as
+// a KMS or HSM backed key
+func (b *ConfigBuilder) WithSigningKey(algorithm string, signingKey crypto.Signer) *ConfigBuilder {
+	b.config.JWTConfig.SigningKey = signingKey
+	return b.useAlgorithm(algorithm)
+}
+
+// WithRetiredKey keeps verifying tokens signed by a replaced key, and
+// publishing its PEM public key, until they expire
+func (b *ConfigBuilder) WithRetiredKey(publicKeyPath string, retiredAt time.Time) *ConfigBuilder {
+	b.config.RetiredKeyFiles = append(b.config.RetiredKeyFiles, RetiredKeyFile{Path: publicKeyPath, RetiredAt: retiredAt})
+	return b
+}
+
+// WithDirectEncryption encrypts tokens with a shared 256-bit key, which
+// every instance validating them needs
+func (b *ConfigBuilder) WithDirectEncryption(key []byte) *ConfigBuilder {
+	b.config.JWTConfig.Encryption.Algorithm = token.EncryptionDirect
+	b.config.JWTConfig.Encryption.Key = key
+	return b
+}
+
+// WithRSAEncryption encrypts tokens for the RSA key pair in the PEM file,
+// with a fresh content key per token
+func (b *ConfigBuilder) WithRSAEncryption(privateKeyPath string) *ConfigBuilder {
+	b.config.JWTConfig.Encryption.Algorithm = token.EncryptionRSAOAEP256
+	b.config.EncryptionKeyPath = privateKeyPath
+	return b
+}
+
+// WithEncryptionMigration sets how far migrating to encrypted tokens has
+// got: decryptOnly accepts encrypted tokens but still issues signed ones,
+// and required rejects signed tokens
+func (b *ConfigBuilder) WithEncryptionMigration(decryptOnly, required bool) *ConfigBuilder {
+	b.config.JWTConfig.Encryption.DecryptOnly = decryptOnly
+	b.config.JWTConfig.Encryption.Required = required
+	return b
+}
+
+// useAlgorithm switches signing to the algorithm, enabling only its signatures
+func (b *ConfigBuilder) useAlgorithm(algorithm string) *ConfigBuilder {
+	b.config.JWTConfig.Algorithm = algorithm
+	b.config.Features.EnableHMACSignature = algorithm == token.AlgorithmHS256
+	b.config.Features.EnableRSASignature = algorithm == token.AlgorithmRS256
+	b.config.Features.EnableECDSASignature = algorithm == token.AlgorithmES256
 	return b
 }
 
@@ -230,6 +549,14 @@ func (b *ConfigBuilder) WithTokenRevocation(enable bool, blacklistTTL time.Durat
 	return b
 }
 
+// WithRevocationStore records JWT revocations in the store, such as a Redis
+// one shared by every instance, and enables the blacklist
+func (b *ConfigBuilder) WithRevocationStore(store revocation.Service) *ConfigBuilder {
+	b.config.RevocationStore = store
+	b.config.EnableBlacklist = true
+	return b
+}
+
 // WithKeyRotation enables automatic key rotation
 func (b *ConfigBuilder) WithKeyRotation(enable bool, interval time.Duration) *ConfigBuilder {
 	b.config.EnableRotation = enable
@@ -237,6 +564,13 @@ func (b *ConfigBuilder) WithKeyRotation(enable bool, interval time.Duration) *Co
 	return b
 }
 
+// WithKeyStore signs with rotated RS256 or ES256 keys kept in the store; a
+// nil store keeps them in memory
+func (b *ConfigBuilder) WithKeyStore(algorithm string, store signingkey.Service) *ConfigBuilder {
+	b.config.KeyStore = store
+	return b.useAlgorithm(algorithm)
+}
+
 // WithMaxActiveTokens sets the maximum active tokens per user
 func (b *ConfigBuilder) WithMaxActiveTokens(max int) *ConfigBuilder {
 	b.config.JWTConfig.MaxActiveTokens = max
@@ -250,6 +584,27 @@ func (b *ConfigBuilder) WithStorageProvider(provider string, config map[string]i
 	return b
 }
 
+// WithTokenStore issues opaque tokens whose claims are kept in the store
+func (b *ConfigBuilder) WithTokenStore(store tokenstore.Service) *ConfigBuilder {
+	b.config.Provider = "opaque"
+	b.config.TokenStore = store
+	b.config.Features.EnableOpaqueProvider = true
+	return b
+}
+
+// WithTokenRegistry records issued JWTs in the store, so they can be listed
+// and held to the maximum number of active tokens
+func (b *ConfigBuilder) WithTokenRegistry(store tokenstore.Service) *ConfigBuilder {
+	b.config.TokenRegistry = store
+	return b
+}
+
+// WithPolicy registers an issuance policy
+func (b *ConfigBuilder) WithPolicy(p tokenpolicy.Service) *ConfigBuilder {
+	b.config.Policies = append(b.config.Policies, p)
+	return b
+}
+
 // WithFeatures sets the feature flags
 func (b *ConfigBuilder) WithFeatures(features FeatureFlags) *ConfigBuilder {
 	b.config.Features = features
@@ -281,6 +636,20 @@ func (b *ConfigBuilder) EnableAuditLogging() *ConfigBuilder {
 	return b
 }
 
+// WithAuditService audits token issuances and revocations in the service
+func (b *ConfigBuilder) WithAuditService(auditService audit.Service) *ConfigBuilder {
+	b.config.AuditService = auditService
+	b.config.Features.EnableAuditLogging = true
+	return b
+}
+
+// WithMetricsRegisterer registers the token metrics with the registerer
+// instead of the Prometheus default
+func (b *ConfigBuilder) WithMetricsRegisterer(registerer prometheus.Registerer) *ConfigBuilder {
+	b.config.MetricsRegisterer = registerer
+	return b
+}
+
 // ForDevelopment configures the service for development use
 func (b *ConfigBuilder) ForDevelopment() *ConfigBuilder {
 	b.config.Provider = "jwt"
@@ -2,13 +2,32 @@ package factory_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMemory "github.com/gentra/decorator-arch-go/internal/audit/memory"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/memory"
 	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/token/factory"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
 )
 
 func TestDefaultFeatureFlags_GivenNoParameters_WhenCreating_ThenReturnsDefaults(t *testing.T) {
@@ -109,7 +128,7 @@ func TestBuild_GivenJWTConfig_WhenBuilding_ThenReturnsJWTService(t *testing.T) {
 	}
 }
 
-func TestBuild_GivenOpaqueProvider_WhenBuilding_ThenReturnsError(t *testing.T) {
+func TestBuild_GivenOpaqueProviderNotEnabled_WhenBuilding_ThenReturnsError(t *testing.T) {
 	config := factory.Config{
 		Provider:  "opaque",
 		JWTConfig: token.DefaultTokenConfig(),
@@ -120,10 +139,66 @@ func TestBuild_GivenOpaqueProvider_WhenBuilding_ThenReturnsError(t *testing.T) {
 	service, err := fact.Build()
 
 	assert.Error(t, err)
-	// Could be either "opaque token provider not yet implemented" or "invalid token configuration"
 	assert.Nil(t, service)
 }
 
+func TestBuild_GivenOpaqueProvider_WhenBuilding_ThenIssuesStoredTokens(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := tokenStoreMemory.NewService()
+	config := factory.NewConfigBuilder().WithTokenStore(store).Build()
+
+	// Act
+	service, err := factory.NewFactory(config).Build()
+
+	// Assert
+	require.NoError(t, err)
+	tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	_, err = store.Get(ctx, tokenstore.ID(tokenString))
+	assert.NoError(t, err)
+
+	claims, err := service.ValidateToken(ctx, tokenString)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+}
+
+func TestBuild_GivenOpaqueProviderWithoutStore_WhenBuilding_ThenRequiresOneForSharedStorage(t *testing.T) {
+	config := factory.NewConfigBuilder().
+		WithProvider("opaque").
+		WithStorageProvider("redis", nil).
+		Build()
+	config.Features.EnableOpaqueProvider = true
+
+	service, err := factory.NewFactory(config).Build()
+
+	assert.Error(t, err)
+	assert.Nil(t, service)
+}
+
+func TestBuild_GivenSharedRevocationStore_WhenRevokingOnOneService_ThenOtherRejectsToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	config := factory.NewConfigBuilder().
+		WithSecretString("shared-secret-for-both-instances").
+		WithRevocationStore(revocationMemory.NewService()).
+		Build()
+	first, err := factory.NewFactory(config).Build()
+	require.NoError(t, err)
+	second, err := factory.NewFactory(config).Build()
+	require.NoError(t, err)
+	tokenString, _, err := first.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	err = first.RevokeToken(ctx, tokenString)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = second.ValidateToken(ctx, tokenString)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+}
+
 func TestBuild_GivenInvalidJWTConfig_WhenBuilding_ThenReturnsError(t *testing.T) {
 	config := factory.Config{
 		Provider:           "jwt",
@@ -140,6 +215,241 @@ func TestBuild_GivenInvalidJWTConfig_WhenBuilding_ThenReturnsError(t *testing.T)
 	assert.Nil(t, service)
 }
 
+// writeKeyPair writes the private key in PKCS #8 and the public key in PKIX
+// PEM files, returning their paths
+func writeKeyPair(t *testing.T, privateKey interface{}, publicKey interface{}) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	privateKeyPath := filepath.Join(dir, "private.pem")
+	publicKeyPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644))
+	return privateKeyPath, publicKeyPath
+}
+
+func TestBuild_GivenPEMKeyFiles_WhenBuilding_ThenSignsWithAsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		builder func(privateKeyPath, publicKeyPath string) *factory.ConfigBuilder
+		keys    func(t *testing.T) (string, string)
+	}{
+		{
+			name: "Given RSA key files, When building, Then issues RS256 tokens",
+			builder: func(privateKeyPath, publicKeyPath string) *factory.ConfigBuilder {
+				return factory.NewConfigBuilder().WithRSAKeys(privateKeyPath, publicKeyPath)
+			},
+			keys: func(t *testing.T) (string, string) { return writeKeyPair(t, rsaKey, &rsaKey.PublicKey) },
+		},
+		{
+			name: "Given ECDSA key files, When building, Then issues ES256 tokens",
+			builder: func(privateKeyPath, publicKeyPath string) *factory.ConfigBuilder {
+				return factory.NewConfigBuilder().WithECDSAKeys(privateKeyPath, publicKeyPath)
+			},
+			keys: func(t *testing.T) (string, string) { return writeKeyPair(t, ecdsaKey, &ecdsaKey.PublicKey) },
+		},
+		{
+			name: "Given a signer, When building, Then issues ES256 tokens",
+			builder: func(string, string) *factory.ConfigBuilder {
+				return factory.NewConfigBuilder().WithSigningKey(token.AlgorithmES256, ecdsaKey)
+			},
+			keys: func(*testing.T) (string, string) { return "", "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			privateKeyPath, publicKeyPath := tt.keys(t)
+			ctx := context.Background()
+
+			// Act
+			service, err := factory.NewFactory(tt.builder(privateKeyPath, publicKeyPath).Build()).Build()
+
+			// Assert
+			require.NoError(t, err)
+			tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+			claims, err := service.ValidateToken(ctx, tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, "user123", claims.UserID)
+		})
+	}
+}
+
+func TestBuild_GivenEncryptionKeys_WhenBuilding_ThenIssuesEncryptedTokens(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		builder func(t *testing.T) *factory.ConfigBuilder
+	}{
+		{
+			name: "Given a direct encryption key, When building, Then issues encrypted tokens",
+			builder: func(*testing.T) *factory.ConfigBuilder {
+				return factory.NewConfigBuilder().WithDirectEncryption([]byte("0123456789abcdef0123456789abcdef"))
+			},
+		},
+		{
+			name: "Given an RSA encryption key file, When building, Then issues encrypted tokens",
+			builder: func(t *testing.T) *factory.ConfigBuilder {
+				privateKeyPath, _ := writeKeyPair(t, rsaKey, &rsaKey.PublicKey)
+				return factory.NewConfigBuilder().WithRSAEncryption(privateKeyPath).WithEncryptionMigration(false, true)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+
+			// Act
+			service, err := factory.NewFactory(tt.builder(t).Build()).Build()
+
+			// Assert
+			require.NoError(t, err)
+			tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(tokenString, "."), 5)
+			claims, err := service.ValidateToken(ctx, tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, "user123", claims.UserID)
+		})
+	}
+}
+
+func TestBuild_GivenECDSAEncryptionKeyFile_WhenBuilding_ThenReturnsError(t *testing.T) {
+	// Arrange
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privateKeyPath, _ := writeKeyPair(t, ecdsaKey, &ecdsaKey.PublicKey)
+
+	// Act
+	service, err := factory.NewFactory(factory.NewConfigBuilder().WithRSAEncryption(privateKeyPath).Build()).Build()
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, service)
+}
+
+func TestBuild_GivenRetiredKeyFile_WhenBuilding_ThenPublishesBothKeys(t *testing.T) {
+	// Arrange
+	currentKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	retiredKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privateKeyPath, publicKeyPath := writeKeyPair(t, currentKey, &currentKey.PublicKey)
+	_, retiredKeyPath := writeKeyPair(t, retiredKey, &retiredKey.PublicKey)
+
+	config := factory.NewConfigBuilder().
+		WithECDSAKeys(privateKeyPath, publicKeyPath).
+		WithRetiredKey(retiredKeyPath, time.Now()).
+		Build()
+
+	// Act
+	service, err := factory.NewFactory(config).Build()
+
+	// Assert
+	require.NoError(t, err)
+	keys, err := service.PublicKeys(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, keys.Keys, 2)
+}
+
+func TestBuild_GivenKeyRotation_WhenBuilding_ThenSignsWithStoredKey(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	config := factory.NewConfigBuilder().
+		WithKeyStore(token.AlgorithmES256, store).
+		WithKeyRotation(true, time.Hour).
+		Build()
+
+	// Act
+	service, err := factory.NewFactory(config).Build()
+
+	// Assert
+	require.NoError(t, err)
+	current, err := store.Current(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, signingkey.AlgorithmES256, current.Algorithm)
+
+	tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, tokenString)
+	assert.NoError(t, err)
+
+	keys, err := service.PublicKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys.Keys, 1)
+	assert.Equal(t, current.ID, keys.Keys[0].KeyID)
+}
+
+func TestBuild_GivenKeyRotationWithHMAC_WhenBuilding_ThenReturnsError(t *testing.T) {
+	config := createTestConfig()
+	config.EnableRotation = true
+
+	service, err := factory.NewFactory(config).Build()
+
+	assert.Error(t, err)
+	assert.Nil(t, service)
+}
+
+func TestBuild_GivenAsymmetricKeysMisconfigured_WhenBuilding_ThenReturnsError(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		config func(t *testing.T) factory.Config
+	}{
+		{
+			name: "Given a missing key file, When building, Then returns error",
+			config: func(t *testing.T) factory.Config {
+				missing := filepath.Join(t.TempDir(), "missing.pem")
+				return factory.NewConfigBuilder().WithECDSAKeys(missing, "").Build()
+			},
+		},
+		{
+			name: "Given ECDSA keys for RS256, When building, Then returns error",
+			config: func(t *testing.T) factory.Config {
+				privateKeyPath, publicKeyPath := writeKeyPair(t, ecdsaKey, &ecdsaKey.PublicKey)
+				return factory.NewConfigBuilder().WithRSAKeys(privateKeyPath, publicKeyPath).Build()
+			},
+		},
+		{
+			name: "Given ES256 signatures disabled, When building, Then returns error",
+			config: func(t *testing.T) factory.Config {
+				config := factory.NewConfigBuilder().WithSigningKey(token.AlgorithmES256, ecdsaKey).Build()
+				config.Features.EnableECDSASignature = false
+				return config
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			service, err := factory.NewFactory(tt.config(t)).Build()
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, service)
+		})
+	}
+}
+
 func TestDefaultConfig_GivenNoParameters_WhenCreating_ThenReturnsValidConfig(t *testing.T) {
 	config := factory.DefaultConfig()
 
@@ -423,6 +733,45 @@ func TestBuild_GivenZeroSecretSize_WhenBuilding_ThenUsesDefaultSize(t *testing.T
 	}
 }
 
+func TestBuild_GivenMetricsAndAuditLogging_WhenIssuingTokens_ThenRecordsBoth(t *testing.T) {
+	// Arrange
+	registry := prometheus.NewRegistry()
+	auditService := auditMemory.NewService()
+	config := factory.NewConfigBuilder().
+		WithSecretString("my-very-secure-secret-key-for-testing").
+		WithAuditService(auditService).
+		WithMetricsRegisterer(registry).
+		EnableMetrics().
+		Build()
+	service, err := factory.NewFactory(config).Build()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	_, _, err = service.GenerateAuthToken(ctx, "user-123", "test@example.com")
+	require.NoError(t, err)
+
+	// Assert
+	count, err := testutil.GatherAndCount(registry, "token_service_issued_total")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	entries, err := auditService.GetAuditLogs(ctx, audit.AuditFilters{Resource: "token"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "token.issue", entries[0].Action)
+}
+
+func TestBuild_GivenAuditLoggingWithoutAuditService_WhenBuilding_ThenReturnsError(t *testing.T) {
+	config := factory.NewConfigBuilder().
+		WithSecretString("my-very-secure-secret-key-for-testing").
+		EnableAuditLogging().
+		Build()
+
+	_, err := factory.NewFactory(config).Build()
+
+	assert.ErrorContains(t, err, "audit service is required")
+}
+
 // Helper function to create a test configuration
 func createTestConfig() factory.Config {
 	config := factory.DefaultConfig()
@@ -0,0 +1,173 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// contentEncryption is the content encryption algorithm of every JWE token
+const contentEncryption = "A256GCM"
+
+// jweHeader is the protected header of an encrypted token; cty marks its
+// payload as a signed JWT
+type jweHeader struct {
+	Algorithm   string `json:"alg"`
+	Encryption  string `json:"enc"`
+	ContentType string `json:"cty"`
+	KeyID       string `json:"kid,omitempty"`
+}
+
+// encrypter wraps signed tokens in the JWE compact serialization and
+// unwraps them again. Signed tokens pass through unwrapping unchanged unless
+// encryption is required, so both forms validate while migrating. A nil
+// encrypter, for services without encryption, rejects encrypted tokens.
+type encrypter struct {
+	config token.EncryptionConfig
+}
+
+// newEncrypter returns the encrypter of the configuration, or nil when it
+// disables encryption
+func newEncrypter(config token.EncryptionConfig) (*encrypter, error) {
+	if !config.IsValid() {
+		switch config.Algorithm {
+		case token.EncryptionDirect:
+			return nil, fmt.Errorf("%s encryption requires a 256-bit key", config.Algorithm)
+		case token.EncryptionRSAOAEP256:
+			return nil, fmt.Errorf("%s encryption requires an RSA key of at least %d bits", config.Algorithm, minRSAKeyBits)
+		default:
+			return nil, fmt.Errorf("invalid token encryption %q", config.Algorithm)
+		}
+	}
+	if config.Algorithm == "" {
+		return nil, nil
+	}
+	return &encrypter{config: config}, nil
+}
+
+// seal encrypts a signed token, unless tokens are only decrypted
+func (e *encrypter) seal(signed string) (string, error) {
+	if e == nil || e.config.DecryptOnly {
+		return signed, nil
+	}
+
+	var contentKey, encryptedKey []byte
+	switch e.config.Algorithm {
+	case token.EncryptionDirect:
+		contentKey = e.config.Key
+	case token.EncryptionRSAOAEP256:
+		contentKey = make([]byte, 32)
+		if _, err := rand.Read(contentKey); err != nil {
+			return "", fmt.Errorf("failed to generate content key: %w", err)
+		}
+		var err error
+		encryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &e.config.PrivateKey.PublicKey, contentKey, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to wrap content key: %w", err)
+		}
+	}
+
+	header, err := json.Marshal(jweHeader{Algorithm: e.config.Algorithm, Encryption: contentEncryption, ContentType: "JWT", KeyID: e.config.KeyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWE header: %w", err)
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+
+	aead, err := newGCM(contentKey)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate IV: %w", err)
+	}
+	// The protected header is authenticated as the additional data
+	sealed := aead.Seal(nil, iv, []byte(signed), []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+
+	return strings.Join([]string{
+		encodedHeader,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// open decrypts an encrypted token to the signed token it carries. Anything
+// else is returned as is for the JWT parser to verify, unless encryption is
+// required, which rejects signed tokens.
+func (e *encrypter) open(tokenString string) (string, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 5 {
+		if e != nil && e.config.Required {
+			return "", token.ErrInvalidToken
+		}
+		return tokenString, nil
+	}
+	if e == nil {
+		return "", token.ErrInvalidToken
+	}
+
+	var header jweHeader
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return "", token.ErrMalformedToken
+	}
+	// Only the configured algorithms are accepted, whatever the header says
+	if header.Algorithm != e.config.Algorithm || header.Encryption != contentEncryption {
+		return "", token.ErrInvalidToken
+	}
+
+	var segments [4][]byte
+	for i, part := range parts[1:] {
+		if segments[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return "", token.ErrMalformedToken
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := segments[0], segments[1], segments[2], segments[3]
+
+	var contentKey []byte
+	switch e.config.Algorithm {
+	case token.EncryptionDirect:
+		if len(encryptedKey) != 0 {
+			return "", token.ErrInvalidToken
+		}
+		contentKey = e.config.Key
+	case token.EncryptionRSAOAEP256:
+		contentKey, err = rsa.DecryptOAEP(sha256.New(), nil, e.config.PrivateKey, encryptedKey, nil)
+		if err != nil || len(contentKey) != 32 {
+			return "", token.ErrInvalidToken
+		}
+	}
+
+	aead, err := newGCM(contentKey)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return "", token.ErrMalformedToken
+	}
+	signed, err := aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return "", token.ErrInvalidToken
+	}
+	return string(signed), nil
+}
+
+// newGCM returns AES-256-GCM keyed with the content key
+func newGCM(contentKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
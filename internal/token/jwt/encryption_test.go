@@ -0,0 +1,218 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+)
+
+func encryptedConfig(t *testing.T, algorithm string) token.TokenConfig {
+	t.Helper()
+	config := createValidTokenConfig()
+	config.Encryption.Algorithm = algorithm
+	switch algorithm {
+	case token.EncryptionDirect:
+		config.Encryption.Key = []byte("0123456789abcdef0123456789abcdef")
+	case token.EncryptionRSAOAEP256:
+		config.Encryption.PrivateKey = newRSAKey(t, 2048)
+	}
+	return config
+}
+
+func TestEncryption_GivenEncryptionAlgorithm_WhenIssuingAndValidating_ThenRoundTripsWithHiddenClaims(t *testing.T) {
+	for _, algorithm := range []string{token.EncryptionDirect, token.EncryptionRSAOAEP256} {
+		t.Run("Given "+algorithm+" encryption, When issuing and validating, Then round trips", func(t *testing.T) {
+			// Arrange
+			service, err := jwt.NewService(encryptedConfig(t, algorithm))
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			// Act
+			tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+			claims, err := service.ValidateToken(ctx, tokenString)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "user123", claims.UserID)
+			assert.Equal(t, "user@example.com", claims.Email)
+			parts := strings.Split(tokenString, ".")
+			require.Len(t, parts, 5)
+			header, err := base64.RawURLEncoding.DecodeString(parts[0])
+			require.NoError(t, err)
+			assert.Contains(t, string(header), `"alg":"`+algorithm+`"`)
+			assert.Contains(t, string(header), `"enc":"A256GCM"`)
+			for _, part := range parts {
+				decoded, _ := base64.RawURLEncoding.DecodeString(part)
+				assert.NotContains(t, string(decoded), "user@example.com")
+			}
+		})
+	}
+}
+
+func TestEncryption_GivenSignedTokenIssuedBeforeEncryption_WhenValidating_ThenAcceptsUnlessRequired(t *testing.T) {
+	// Arrange
+	signing, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	ctx := context.Background()
+	signed, _, err := signing.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	encrypting, err := jwt.NewService(encryptedConfig(t, token.EncryptionDirect))
+	require.NoError(t, err)
+	requiredConfig := encryptedConfig(t, token.EncryptionDirect)
+	requiredConfig.Encryption.Required = true
+	requiring, err := jwt.NewService(requiredConfig)
+	require.NoError(t, err)
+
+	// Act
+	claims, migratingErr := encrypting.ValidateToken(ctx, signed)
+	_, requiredErr := requiring.ValidateToken(ctx, signed)
+
+	// Assert
+	require.NoError(t, migratingErr)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.ErrorIs(t, requiredErr, token.ErrInvalidToken)
+}
+
+func TestEncryption_GivenDecryptOnly_WhenIssuing_ThenSignsButStillAcceptsEncryptedTokens(t *testing.T) {
+	// Arrange
+	config := encryptedConfig(t, token.EncryptionDirect)
+	encrypting, err := jwt.NewService(config)
+	require.NoError(t, err)
+	config.Encryption.DecryptOnly = true
+	decryptOnly, err := jwt.NewService(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	encrypted, _, err := encrypting.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	signed, _, err := decryptOnly.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	claims, validateErr := decryptOnly.ValidateToken(ctx, encrypted)
+
+	// Assert
+	assert.Len(t, strings.Split(signed, "."), 3)
+	require.NoError(t, validateErr)
+	assert.Equal(t, "user123", claims.UserID)
+}
+
+func TestEncryption_GivenUnreadableEncryptedToken_WhenValidating_ThenReturnsError(t *testing.T) {
+	// Arrange
+	config := encryptedConfig(t, token.EncryptionDirect)
+	service, err := jwt.NewService(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	encrypted, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	parts := strings.Split(encrypted, ".")
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	require.NoError(t, err)
+	ciphertext[0] ^= 0xff
+	parts[3] = base64.RawURLEncoding.EncodeToString(ciphertext)
+	tampered := strings.Join(parts, ".")
+
+	otherKey := encryptedConfig(t, token.EncryptionDirect)
+	otherKey.Encryption.Key = []byte("fedcba9876543210fedcba9876543210")
+	otherService, err := jwt.NewService(otherKey)
+	require.NoError(t, err)
+	plain, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	rsaService, err := jwt.NewService(encryptedConfig(t, token.EncryptionRSAOAEP256))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		service token.Service
+		token   string
+	}{
+		{name: "Given a tampered ciphertext, When validating, Then returns invalid token error", service: service, token: tampered},
+		{name: "Given another encryption key, When validating, Then returns invalid token error", service: otherService, token: encrypted},
+		{name: "Given a service without encryption, When validating, Then returns invalid token error", service: plain, token: encrypted},
+		{name: "Given another encryption algorithm, When validating, Then returns invalid token error", service: rsaService, token: encrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			claims, err := tt.service.ValidateToken(ctx, tt.token)
+
+			// Assert
+			assert.ErrorIs(t, err, token.ErrInvalidToken)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestNewService_GivenEncryptionKeys_WhenCreating_ThenChecksKeyMatchesAlgorithm(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      func(t *testing.T) token.TokenConfig
+		expectError bool
+	}{
+		{
+			name:   "Given dir with a 256-bit key, When creating, Then returns service",
+			config: func(t *testing.T) token.TokenConfig { return encryptedConfig(t, token.EncryptionDirect) },
+		},
+		{
+			name: "Given dir with a short key, When creating, Then returns error",
+			config: func(t *testing.T) token.TokenConfig {
+				config := encryptedConfig(t, token.EncryptionDirect)
+				config.Encryption.Key = []byte("too-short")
+				return config
+			},
+			expectError: true,
+		},
+		{
+			name: "Given RSA-OAEP-256 with a 1024-bit key, When creating, Then returns error",
+			config: func(t *testing.T) token.TokenConfig {
+				config := encryptedConfig(t, token.EncryptionRSAOAEP256)
+				config.Encryption.PrivateKey = newRSAKey(t, 1024)
+				return config
+			},
+			expectError: true,
+		},
+		{
+			name: "Given encryption required without an algorithm, When creating, Then returns error",
+			config: func(t *testing.T) token.TokenConfig {
+				config := createValidTokenConfig()
+				config.Encryption.Required = true
+				return config
+			},
+			expectError: true,
+		},
+		{
+			name: "Given an unknown algorithm, When creating, Then returns error",
+			config: func(t *testing.T) token.TokenConfig {
+				config := encryptedConfig(t, "A128KW")
+				return config
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			service, err := jwt.NewService(tt.config(t))
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, service)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, service)
+		})
+	}
+}
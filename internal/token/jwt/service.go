@@ -2,33 +2,116 @@ package jwt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
 	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
 )
 
+// apiTokenTTLFactor is how many access token lifetimes an API token lasts
+const apiTokenTTLFactor = 24
+
 // service implements token.Service interface using JWT
 type service struct {
 	config        token.TokenConfig
-	revokedTokens map[string]time.Time // Simple in-memory revocation list
-	mu            sync.RWMutex
+	keys          *keySet
+	signingKeys   signingkey.Service // nil when the configured keys are static
+	keysVersion   string
+	keysMu        sync.Mutex
+	revocations   revocation.Service
+	revocationTTL time.Duration      // Least time a user revocation is kept
+	registry      tokenstore.Service // nil when issued tokens are not recorded
+	encryption    *encrypter         // nil when tokens are only signed
+}
+
+// Options are the stores a JWT service keeps its state in; all are optional
+type Options struct {
+	// Keys signs with the current key of a signing key store, as for
+	// NewServiceWithKeys
+	Keys signingkey.Service
+
+	// Revocations records revoked tokens and users, in process when nil. A
+	// user revocation is kept for RevocationTTL or until every token issued
+	// before it has expired, whichever is longer.
+	Revocations   revocation.Service
+	RevocationTTL time.Duration
+
+	// Registry records the access, refresh, API and impersonation tokens
+	// issued, by jti, so a user's active tokens can be listed and held to
+	// MaxActiveTokens by revoking the oldest. Without it ListActiveTokens
+	// returns nothing.
+	Registry tokenstore.Service
 }
 
 // NewService creates a new JWT-based token service
 func NewService(config token.TokenConfig) (token.Service, error) {
+	return NewServiceWithOptions(config, Options{})
+}
+
+// NewServiceWithKeys creates a JWT-based token service that signs with the
+// current key of a signing key store and verifies with the keys it retired,
+// so the keys can be rotated without restarting. The configured algorithm and
+// keys are replaced by the store's; a configured Secret still verifies HS256
+// tokens and static RetiredKeys are still accepted.
+func NewServiceWithKeys(config token.TokenConfig, keys signingkey.Service) (token.Service, error) {
+	return NewServiceWithOptions(config, Options{Keys: keys})
+}
+
+// NewServiceWithRevocations creates a JWT-based token service that records
+// revocations in a store, which instances share to honour each other's
+// revocations. keys is as for NewServiceWithKeys, or nil to sign with the
+// configured keys. A user revocation is kept for revocationTTL or until every
+// token issued before it has expired, whichever is longer.
+func NewServiceWithRevocations(config token.TokenConfig, keys signingkey.Service, revocations revocation.Service, revocationTTL time.Duration) (token.Service, error) {
+	return NewServiceWithOptions(config, Options{Keys: keys, Revocations: revocations, RevocationTTL: revocationTTL})
+}
+
+// NewServiceWithOptions creates a JWT-based token service keeping its state
+// in the stores of options
+func NewServiceWithOptions(config token.TokenConfig, options Options) (token.Service, error) {
+	s := &service{
+		config:        config,
+		signingKeys:   options.Keys,
+		revocations:   options.Revocations,
+		revocationTTL: options.RevocationTTL,
+		registry:      options.Registry,
+	}
+	if s.revocations == nil {
+		s.revocations = revocationMemory.NewService()
+	}
+
+	encryption, err := newEncrypter(config.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token configuration: %w", err)
+	}
+	s.encryption = encryption
+
+	if s.signingKeys != nil {
+		if _, err := s.keySet(context.Background()); err != nil {
+			return nil, fmt.Errorf("invalid token configuration: %w", err)
+		}
+		return s, nil
+	}
+
 	if !config.IsValid() {
 		return nil, fmt.Errorf("invalid token configuration")
 	}
-
-	return &service{
-		config:        config,
-		revokedTokens: make(map[string]time.Time),
-	}, nil
+	staticKeys, err := newKeySet(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token configuration: %w", err)
+	}
+	s.keys = staticKeys
+	return s, nil
 }
 
 // GenerateAuthToken generates an authentication token
@@ -42,51 +125,81 @@ func (s *service) GenerateAuthToken(ctx context.Context, userID string, email st
 		"email":      email,
 		"token_type": "auth",
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
 		"exp":        expiresAt.Unix(),
 		"iss":        s.config.Issuer,
-		"aud":        s.config.Audience,
+		"aud":        s.audience(ctx),
 		"jti":        jti,
 	}
+	s.addExtraClaims(ctx, claims)
+	s.addAuthentication(ctx, claims)
+	family := familyFromContext(ctx)
+	if family != "" {
+		claims["fam"] = family
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.config.Secret)
+	tokenString, err := s.sign(ctx, claims)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
 
+	record := tokenstore.Record{ID: jti, UserID: userID, Email: email, TokenType: "auth", Family: family, IssuedAt: now, ExpiresAt: expiresAt}
+	if err := s.register(ctx, record); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to register token: %w", err)
+	}
+
 	return tokenString, expiresAt, nil
 }
 
-// GenerateRefreshToken generates a refresh token
+// GenerateAuthTokenWithClaims generates an authentication token carrying the
+// non-reserved claims of extra as well as those of the context
+func (s *service) GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error) {
+	return s.GenerateAuthToken(token.WithExtraClaims(ctx, extra), userID, email)
+}
+
+// GenerateRefreshToken generates the first refresh token of a new family
 func (s *service) GenerateRefreshToken(ctx context.Context, userID string) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(s.config.RefreshTTL)
 	jti := s.generateJTI(userID, now)
+	family := familyFromContext(ctx)
+	if family == "" {
+		family = uuid.NewString()
+	}
 
 	claims := jwt.MapClaims{
 		"user_id":    userID,
 		"token_type": "refresh",
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
 		"exp":        expiresAt.Unix(),
 		"iss":        s.config.Issuer,
-		"aud":        s.config.Audience,
+		"aud":        s.audience(ctx),
 		"jti":        jti,
+		"fam":        family,
 	}
+	s.addExtraClaims(ctx, claims)
+	s.addAuthentication(ctx, claims)
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := jwtToken.SignedString(s.config.Secret)
+	tokenString, err := s.sign(ctx, claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
+	record := tokenstore.Record{ID: jti, UserID: userID, TokenType: "refresh", Family: family, IssuedAt: now, ExpiresAt: expiresAt}
+	if err := s.register(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to register refresh token: %w", err)
+	}
+
 	return tokenString, nil
 }
 
 // GenerateAPIToken generates an API token with scopes
 func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*token.APIToken, error) {
 	now := time.Now()
-	expiresAt := now.Add(s.config.AccessTTL * 24) // API tokens last longer
-	id := uuid.New().String()
+	expiresAt := now.Add(s.config.AccessTTL * apiTokenTTLFactor) // API tokens last longer
 	jti := s.generateJTI(userID, now)
 
 	claims := jwt.MapClaims{
@@ -94,20 +207,27 @@ func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []
 		"token_type": "api",
 		"scopes":     scopes,
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
 		"exp":        expiresAt.Unix(),
 		"iss":        s.config.Issuer,
-		"aud":        s.config.Audience,
+		"aud":        s.audience(ctx),
 		"jti":        jti,
 	}
+	s.addExtraClaims(ctx, claims)
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := jwtToken.SignedString(s.config.Secret)
+	tokenString, err := s.sign(ctx, claims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign API token: %w", err)
 	}
 
+	record := tokenstore.Record{ID: jti, UserID: userID, TokenType: "api", Scopes: scopes, IssuedAt: now, ExpiresAt: expiresAt}
+	if err := s.register(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to register API token: %w", err)
+	}
+
 	return &token.APIToken{
-		ID:        id,
+		ID:        jti,
 		Token:     tokenString,
 		UserID:    userID,
 		Scopes:    scopes,
@@ -118,22 +238,184 @@ func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []
 
 // GeneratePasswordResetToken generates a password reset token
 func (s *service) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
-	return s.generateSpecialToken(userID, "reset", s.config.ResetTTL)
+	return s.generateSpecialToken(ctx, userID, "reset", s.config.ResetTTL)
 }
 
 // GenerateEmailVerificationToken generates an email verification token
 func (s *service) GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
-	return s.generateSpecialToken(userID, "verification", s.config.VerificationTTL)
+	return s.generateSpecialToken(ctx, userID, "verification", s.config.VerificationTTL)
 }
 
-// ValidateToken validates a token and returns claims
+// GenerateImpersonationToken generates a short-lived token for actorID to act
+// as userID; the actor is recorded in the RFC 8693 "act" claim
+func (s *service) GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*token.ImpersonationToken, error) {
+	ttl := s.config.ImpersonationTTL
+	if ttl <= 0 {
+		ttl = token.DefaultImpersonationTTL
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	jti := s.generateJTI(userID, now)
+
+	claims := jwt.MapClaims{
+		"user_id":    userID,
+		"token_type": token.TokenTypeImpersonation,
+		"act":        map[string]interface{}{"sub": actorID},
+		"scopes":     scopes,
+		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
+		"exp":        expiresAt.Unix(),
+		"iss":        s.config.Issuer,
+		"aud":        s.audience(ctx),
+		"jti":        jti,
+	}
+	s.addExtraClaims(ctx, claims)
+
+	tokenString, err := s.sign(ctx, claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign impersonation token: %w", err)
+	}
+
+	record := tokenstore.Record{
+		ID:        jti,
+		UserID:    userID,
+		TokenType: token.TokenTypeImpersonation,
+		Scopes:    scopes,
+		ActorID:   actorID,
+		IssuedAt:  now,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.register(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to register impersonation token: %w", err)
+	}
+
+	return &token.ImpersonationToken{
+		Token:     tokenString,
+		UserID:    userID,
+		ActorID:   actorID,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// GenerateOneTimeToken generates a token for subject that ConsumeOneTimeToken
+// accepts once for purpose. One-time tokens are not registered, as they are
+// spent rather than listed.
+func (s *service) GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	if purpose == "" || subject == "" || ttl <= 0 {
+		return "", fmt.Errorf("one-time token requires a purpose, a subject and a positive TTL")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := jwt.MapClaims{
+		"user_id":    subject,
+		"token_type": token.TokenTypeOneTime,
+		"purpose":    purpose,
+		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
+		"exp":        expiresAt.Unix(),
+		"iss":        s.config.Issuer,
+		"aud":        s.audience(ctx),
+		"jti":        s.generateJTI(subject, now),
+	}
+
+	tokenString, err := s.sign(ctx, claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign one-time token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ConsumeOneTimeToken validates a one-time token issued for purpose and
+// spends it in the revocation store, so that of concurrent consumers exactly
+// one succeeds. Spent and revoked tokens report ErrTokenAlreadyUsed.
+func (s *service) ConsumeOneTimeToken(ctx context.Context, tokenString, purpose string) (*token.OneTimeClaims, error) {
+	jwtToken, err := s.parse(ctx, tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := jwtToken.Claims.(jwt.MapClaims)
+	if !ok || !jwtToken.Valid {
+		return nil, token.ErrInvalidToken
+	}
+
+	subject, _ := claims["user_id"].(string)
+	tokenType, _ := claims["token_type"].(string)
+	tokenPurpose, _ := claims["purpose"].(string)
+	jti, _ := claims["jti"].(string)
+	if subject == "" || jti == "" {
+		return nil, token.ErrMalformedToken
+	}
+	if tokenType != token.TokenTypeOneTime || tokenPurpose != purpose {
+		return nil, token.ErrInvalidToken
+	}
+	if err := s.checkIssuerAndAudience(claims); err != nil {
+		return nil, err
+	}
+
+	issuedAt, precision := token.IssuedAt(claims)
+	expiresAt := time.Unix(int64(claims["exp"].(float64)), 0)
+	if time.Now().After(expiresAt.Add(s.config.ClockSkew)) {
+		return nil, token.ErrTokenExpired
+	}
+
+	revoked, err := s.isUserRevoked(ctx, subject, issuedAt, precision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, token.ErrTokenRevoked
+	}
+
+	consumed, err := s.revocations.ConsumeToken(ctx, jti, expiresAt.Add(s.config.ClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	if !consumed {
+		return nil, token.ErrTokenAlreadyUsed
+	}
+
+	return &token.OneTimeClaims{
+		Purpose:   tokenPurpose,
+		Subject:   subject,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		JTI:       jti,
+	}, nil
+}
+
+// ValidateToken validates a token and returns claims. Refresh tokens stop
+// validating once exchanged.
 func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
-	jwtToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	claims, err := s.validate(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IsRefreshToken() {
+		exchanged, err := s.revocations.IsTokenRevoked(ctx, exchangedKey(claims.JTI))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if exchanged {
+			return nil, token.ErrTokenRevoked
 		}
-		return s.config.Secret, nil
-	})
+	}
+	return claims, nil
+}
+
+// validate validates a token and returns claims, accepting refresh tokens
+// already exchanged so RefreshToken can tell them being reused
+func (s *service) validate(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	jwtToken, err := s.parse(ctx, tokenString)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -150,7 +432,11 @@ func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token
 
 	// Check if token is revoked
 	if jti, ok := claims["jti"].(string); ok {
-		if s.isTokenRevoked(jti) {
+		revoked, err := s.revocations.IsTokenRevoked(ctx, jti)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
 			return nil, token.ErrTokenRevoked
 		}
 	}
@@ -162,19 +448,46 @@ func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token
 	issuer, _ := claims["iss"].(string)
 	audience, _ := claims["aud"].(string)
 	jti, _ := claims["jti"].(string)
+	family, _ := claims["fam"].(string)
 
 	if userID == "" || tokenType == "" {
 		return nil, token.ErrMalformedToken
 	}
+	// One-time tokens are only accepted by ConsumeOneTimeToken, which spends them
+	if tokenType == token.TokenTypeOneTime {
+		return nil, token.ErrInvalidToken
+	}
+	if err := s.checkIssuerAndAudience(claims); err != nil {
+		return nil, err
+	}
 
-	issuedAt := time.Unix(int64(claims["iat"].(float64)), 0)
+	issuedAt, precision := token.IssuedAt(claims)
 	expiresAt := time.Unix(int64(claims["exp"].(float64)), 0)
 
-	// Check if token is expired
-	if time.Now().After(expiresAt) {
+	revoked, err := s.isUserRevoked(ctx, userID, issuedAt, precision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, token.ErrTokenRevoked
+	}
+	if family != "" {
+		revoked, err := s.revocations.IsTokenRevoked(ctx, familyKey(family))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, token.ErrTokenRevoked
+		}
+	}
+
+	// Check if token is expired, allowing for clock skew between instances
+	if time.Now().After(expiresAt.Add(s.config.ClockSkew)) {
 		return nil, token.ErrTokenExpired
 	}
 
+	authTime, amr := s.extractAuthentication(claims)
+
 	return &token.TokenClaims{
 		UserID:    userID,
 		Email:     email,
@@ -184,6 +497,10 @@ func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token
 		Issuer:    issuer,
 		Audience:  audience,
 		JTI:       jti,
+		AuthTime:  authTime,
+		AMR:       amr,
+		FamilyID:  family,
+		Custom:    s.extractCustomClaims(claims),
 	}, nil
 }
 
@@ -199,9 +516,10 @@ func (s *service) ValidateAPIToken(ctx context.Context, tokenString string) (*to
 	}
 
 	// Parse the token again to get scopes
-	jwtToken, _ := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return s.config.Secret, nil
-	})
+	jwtToken, err := s.parse(ctx, tokenString)
+	if err != nil {
+		return nil, token.ErrInvalidToken
+	}
 
 	jwtClaims := jwtToken.Claims.(jwt.MapClaims)
 	scopes, _ := jwtClaims["scopes"].([]interface{})
@@ -244,41 +562,141 @@ func (s *service) ValidateEmailVerificationToken(ctx context.Context, tokenStrin
 	return claims, nil
 }
 
-// RefreshToken generates a new access token from a refresh token
+// ValidateImpersonationToken validates an impersonation token and returns
+// its actor and scopes
+func (s *service) ValidateImpersonationToken(ctx context.Context, tokenString string) (*token.ImpersonationClaims, error) {
+	claims, err := s.ValidateToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.IsImpersonationToken() {
+		return nil, token.ErrInvalidToken
+	}
+
+	// Parse the token again to get the actor and scopes
+	jwtToken, err := s.parse(ctx, tokenString)
+	if err != nil {
+		return nil, token.ErrInvalidToken
+	}
+
+	jwtClaims := jwtToken.Claims.(jwt.MapClaims)
+	act, _ := jwtClaims["act"].(map[string]interface{})
+	actorID, _ := act["sub"].(string)
+	if actorID == "" {
+		return nil, token.ErrMalformedToken
+	}
+
+	scopes, _ := jwtClaims["scopes"].([]interface{})
+	scopeStrings := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if name, ok := scope.(string); ok {
+			scopeStrings = append(scopeStrings, name)
+		}
+	}
+
+	return &token.ImpersonationClaims{
+		TokenClaims: *claims,
+		ActorID:     actorID,
+		Scopes:      scopeStrings,
+	}, nil
+}
+
+// RefreshToken exchanges a refresh token for an access token and a new
+// refresh token of the same family. The exchange is recorded in the
+// revocation store, so across instances each refresh token is exchanged
+// once; presenting it again revokes its whole family, the legitimate
+// holder's tokens included. Refresh tokens issued before families existed
+// start a family of their own.
 func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*token.TokenPair, error) {
-	claims, err := s.ValidateToken(ctx, refreshToken)
+	claims, err := s.validate(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
-	if !claims.IsRefreshToken() {
+	if !claims.IsRefreshToken() || claims.JTI == "" {
 		return nil, token.ErrInvalidToken
 	}
+	family := claims.FamilyID
+	if family == "" {
+		family = claims.JTI
+	}
+
+	exchanged, err := s.revocations.ConsumeToken(ctx, exchangedKey(claims.JTI), claims.ExpiresAt.Add(s.config.ClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange refresh token: %w", err)
+	}
+	if !exchanged {
+		if err := s.revokeFamily(ctx, claims.UserID, family); err != nil {
+			return nil, fmt.Errorf("failed to revoke token family: %w", err)
+		}
+		return nil, token.RefreshTokenReuseError{UserID: claims.UserID, FamilyID: family}
+	}
+	if s.registry != nil {
+		if err := s.registry.Revoke(ctx, claims.JTI); err != nil {
+			return nil, fmt.Errorf("failed to unregister refresh token: %w", err)
+		}
+	}
 
-	// Generate new access token
+	// Refreshing is not signing in again, so the sign-in, custom claims and
+	// audience of the refresh token carry over
+	ctx = withFamily(ctx, family)
+	if !claims.AuthTime.IsZero() {
+		ctx = token.WithAuthentication(ctx, claims.AuthTime, claims.AMR...)
+	}
+	if len(claims.Custom) > 0 {
+		ctx = token.WithExtraClaims(ctx, claims.Custom)
+	}
+	if claims.Audience != "" {
+		ctx = token.WithAudience(ctx, claims.Audience)
+	}
 	accessToken, expiresAt, err := s.GenerateAuthToken(ctx, claims.UserID, claims.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
+	rotated, err := s.GenerateRefreshToken(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
 
 	return &token.TokenPair{
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken, // Keep the same refresh token
+		RefreshToken: rotated,
 		TokenType:    "bearer",
 		ExpiresIn:    int64(s.config.AccessTTL.Seconds()),
 		ExpiresAt:    expiresAt,
 	}, nil
 }
 
+// revokeFamily revokes every token of the refresh token family, in the
+// revocation store until the last of them has expired and in the registry
+func (s *service) revokeFamily(ctx context.Context, userID, family string) error {
+	expiresAt := time.Now().Add(max(s.config.AccessTTL, s.config.RefreshTTL) + s.config.ClockSkew)
+	if err := s.revocations.RevokeToken(ctx, familyKey(family), expiresAt); err != nil {
+		return err
+	}
+	if s.registry == nil {
+		return nil
+	}
+
+	records, err := s.registry.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Family == family && !record.IsRevoked() {
+			if err := s.registry.Revoke(ctx, record.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // RevokeToken revokes a token
 func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
 	// Parse token to get JTI
-	jwtToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.config.Secret, nil
-	})
+	jwtToken, err := s.parse(ctx, tokenString)
 
 	if err != nil {
 		return fmt.Errorf("failed to parse token for revocation: %w", err)
@@ -296,25 +714,41 @@ func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
 
 	expiresAt := time.Unix(int64(claims["exp"].(float64)), 0)
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Add to revocation list with expiration time
-	s.revokedTokens[jti] = expiresAt
-
-	// Clean up expired revoked tokens
-	s.cleanupExpiredRevokedTokens()
-
+	// Keep the token revoked until it expires
+	if err := s.revocations.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+	if s.registry != nil {
+		return s.registry.Revoke(ctx, jti)
+	}
 	return nil
 }
 
-// RevokeAllTokensForUser revokes all tokens for a user (placeholder)
+// RevokeAllTokensForUser revokes every token issued to the user so far.
+// Tokens carry their issue time in microseconds, so a token issued right
+// after, such as on signing in again, stays valid.
 func (s *service) RevokeAllTokensForUser(ctx context.Context, userID string) error {
-	// In a real implementation, this would query all tokens for the user and revoke them
-	// For this JWT implementation, we'd need to maintain a list of active tokens per user
+	now := time.Now()
+	retention := max(s.revocationTTL, TokenLifetime(s.config))
+	if err := s.revocations.RevokeUser(ctx, userID, now, now.Add(retention)); err != nil {
+		return err
+	}
+	if s.registry != nil {
+		return s.registry.RevokeUser(ctx, userID)
+	}
 	return nil
 }
 
+// isUserRevoked reports whether the user's tokens were revoked after a token
+// issued at issuedAt, compared at the precision the token recorded
+func (s *service) isUserRevoked(ctx context.Context, userID string, issuedAt time.Time, precision time.Duration) (bool, error) {
+	revokedAt, err := s.revocations.UserRevokedAt(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return token.RevokedSince(issuedAt, precision, revokedAt), nil
+}
+
 // GetTokenInfo returns information about a token
 func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.TokenInfo, error) {
 	claims, err := s.ValidateToken(ctx, tokenString)
@@ -328,20 +762,158 @@ func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.
 		TokenType: claims.TokenType,
 		CreatedAt: claims.IssuedAt,
 		ExpiresAt: claims.ExpiresAt,
-		IsRevoked: s.isTokenRevoked(claims.JTI),
+		IsRevoked: false, // ValidateToken rejects revoked tokens
 	}, nil
 }
 
-// ListActiveTokens lists active tokens for a user (placeholder)
+// ListActiveTokens lists the user's registered tokens that are neither
+// expired nor revoked, oldest first; without a registry there are none
 func (s *service) ListActiveTokens(ctx context.Context, userID string) ([]token.TokenInfo, error) {
-	// In a real implementation, this would query active tokens for the user
-	// For this JWT implementation, we'd need to maintain a registry of active tokens
-	return []token.TokenInfo{}, nil
+	tokens := []token.TokenInfo{}
+	if s.registry == nil {
+		return tokens, nil
+	}
+
+	records, err := s.active(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	for _, record := range records {
+		tokens = append(tokens, token.TokenInfo{
+			ID:        record.ID,
+			UserID:    record.UserID,
+			TokenType: record.TokenType,
+			CreatedAt: record.IssuedAt,
+			ExpiresAt: record.ExpiresAt,
+			Scopes:    record.Scopes,
+			UserAgent: record.UserAgent,
+			IPAddress: record.IPAddress,
+		})
+	}
+	return tokens, nil
+}
+
+// PublicKeys returns the keys tokens issued by this service verify with
+func (s *service) PublicKeys(ctx context.Context) (*token.JWKSet, error) {
+	keys, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys.publicKeys(time.Now()), nil
 }
 
 // Helper methods
 
-func (s *service) generateSpecialToken(userID, tokenType string, ttl time.Duration) (string, error) {
+// keySet returns the keys to sign and verify with. With a signing key store
+// they are rebuilt whenever the store's keys change.
+func (s *service) keySet(ctx context.Context) (*keySet, error) {
+	if s.signingKeys == nil {
+		return s.keys, nil
+	}
+
+	current, err := s.signingKeys.Current(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+	stored, err := s.signingKeys.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	version := current.ID
+	for _, key := range stored {
+		version += fmt.Sprintf(",%s:%t", key.ID, key.IsRetired())
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	if s.keys != nil && s.keysVersion == version {
+		return s.keys, nil
+	}
+
+	config := s.config
+	config.Algorithm = current.Algorithm
+	config.SigningKey = current.PrivateKey
+	config.VerificationKey = nil
+	config.KeyID = current.ID
+	config.RetiredKeys = append([]token.RetiredKey(nil), s.config.RetiredKeys...)
+	for _, key := range stored {
+		if key.IsRetired() {
+			config.RetiredKeys = append(config.RetiredKeys, token.RetiredKey{ID: key.ID, Key: key.PrivateKey.Public(), RetiredAt: *key.RetiredAt})
+		}
+	}
+
+	keys, err := newKeySet(config)
+	if err != nil {
+		return nil, err
+	}
+	s.keys, s.keysVersion = keys, version
+	return keys, nil
+}
+
+// sign signs the claims with the current key, and encrypts the signed token
+// when encryption is enabled
+func (s *service) sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	keys, err := s.keySet(ctx)
+	if err != nil {
+		return "", err
+	}
+	signed, err := keys.sign(claims)
+	if err != nil {
+		return "", err
+	}
+	return s.encryption.seal(signed)
+}
+
+// parse parses and verifies a token with the current and retired keys. The
+// exp, nbf and iat claims are checked with ClockSkew of leeway, and failing
+// them reports ErrTokenExpired or ErrTokenNotYetValid. Encrypted tokens are
+// decrypted first; signed ones are accepted as they are unless encryption is
+// required.
+func (s *service) parse(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	tokenString, err := s.encryption.open(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jwtToken, err := keys.parse(tokenString, jwt.WithLeeway(s.config.ClockSkew), jwt.WithIssuedAt())
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return nil, fmt.Errorf("%w: %w", token.ErrTokenExpired, err)
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return nil, fmt.Errorf("%w: %w", token.ErrTokenNotYetValid, err)
+	}
+	return jwtToken, err
+}
+
+// checkIssuerAndAudience rejects tokens from other issuers or for other
+// audiences when the configuration requires them to match
+func (s *service) checkIssuerAndAudience(claims jwt.MapClaims) error {
+	if s.config.RequireIssuer {
+		if issuer, _ := claims.GetIssuer(); issuer != s.config.Issuer {
+			return token.ErrInvalidIssuer
+		}
+	}
+	if s.config.RequireAudience {
+		if audience, _ := claims.GetAudience(); !slices.Contains(audience, s.config.Audience) {
+			return token.ErrInvalidAudience
+		}
+	}
+	return nil
+}
+
+// audience returns the audience of tokens issued with the context
+func (s *service) audience(ctx context.Context) string {
+	if audience := token.ExtractAudience(ctx); audience != "" {
+		return audience
+	}
+	return s.config.Audience
+}
+
+func (s *service) generateSpecialToken(ctx context.Context, userID, tokenType string, ttl time.Duration) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(ttl)
 	jti := s.generateJTI(userID, now)
@@ -350,42 +922,147 @@ func (s *service) generateSpecialToken(userID, tokenType string, ttl time.Durati
 		"user_id":    userID,
 		"token_type": tokenType,
 		"iat":        now.Unix(),
+		"iat_us":     now.UnixMicro(),
+		"nbf":        now.Unix(),
 		"exp":        expiresAt.Unix(),
 		"iss":        s.config.Issuer,
-		"aud":        s.config.Audience,
+		"aud":        s.audience(ctx),
 		"jti":        jti,
 	}
+	s.addExtraClaims(ctx, claims)
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return jwtToken.SignedString(s.config.Secret)
+	return s.sign(ctx, claims)
 }
 
-func (s *service) generateJTI(userID string, issuedAt time.Time) string {
-	return fmt.Sprintf("%s-%d", userID, issuedAt.Unix())
+// addExtraClaims copies non-reserved claims from the context into the token claims
+func (s *service) addExtraClaims(ctx context.Context, claims jwt.MapClaims) {
+	for name, value := range token.ExtractExtraClaims(ctx) {
+		if !token.IsReservedClaim(name) {
+			claims[name] = value
+		}
+	}
 }
 
-func (s *service) isTokenRevoked(jti string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// addAuthentication records the sign-in stored in the context, if any
+func (s *service) addAuthentication(ctx context.Context, claims jwt.MapClaims) {
+	authTime, methods, ok := token.ExtractAuthentication(ctx)
+	if !ok || authTime.IsZero() {
+		return
+	}
+	claims["auth_time"] = authTime.Unix()
+	if len(methods) > 0 {
+		claims["amr"] = methods
+	}
+}
 
-	expiresAt, exists := s.revokedTokens[jti]
-	if !exists {
-		return false
+// extractAuthentication returns the sign-in recorded in a parsed token
+func (s *service) extractAuthentication(claims jwt.MapClaims) (time.Time, []string) {
+	seconds, ok := claims["auth_time"].(float64)
+	if !ok {
+		return time.Time{}, nil
 	}
 
-	// If the revoked token has expired, it's no longer relevant
-	if time.Now().After(expiresAt) {
-		return false
+	values, _ := claims["amr"].([]interface{})
+	methods := make([]string, 0, len(values))
+	for _, value := range values {
+		if method, ok := value.(string); ok {
+			methods = append(methods, method)
+		}
 	}
+	return time.Unix(int64(seconds), 0), methods
+}
 
-	return true
+// extractCustomClaims returns the non-reserved claims of a parsed token
+func (s *service) extractCustomClaims(claims jwt.MapClaims) map[string]interface{} {
+	var custom map[string]interface{}
+	for name, value := range claims {
+		if token.IsReservedClaim(name) {
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]interface{})
+		}
+		custom[name] = value
+	}
+	return custom
 }
 
-func (s *service) cleanupExpiredRevokedTokens() {
+// familyContextKey carries the refresh token family tokens are issued to
+type familyContextKey struct{}
+
+// withFamily issues the tokens generated with the context to the refresh
+// token family
+func withFamily(ctx context.Context, family string) context.Context {
+	return context.WithValue(ctx, familyContextKey{}, family)
+}
+
+// familyFromContext returns the refresh token family of the context, if any
+func familyFromContext(ctx context.Context) string {
+	family, _ := ctx.Value(familyContextKey{}).(string)
+	return family
+}
+
+// familyKey is the revocation store key revoking a refresh token family
+func familyKey(family string) string {
+	return "refresh-family:" + family
+}
+
+// exchangedKey is the revocation store key spending a refresh token once it
+// was exchanged
+func exchangedKey(jti string) string {
+	return "refresh-exchanged:" + jti
+}
+
+// generateJTI returns a unique token ID; the random part keeps tokens issued
+// to the user in the same second apart
+func (s *service) generateJTI(userID string, issuedAt time.Time) string {
+	return fmt.Sprintf("%s-%d-%s", userID, issuedAt.Unix(), uuid.NewString())
+}
+
+// register records an issued token with the client it was issued to, then
+// revokes the user's oldest active tokens beyond MaxActiveTokens
+func (s *service) register(ctx context.Context, record tokenstore.Record) error {
+	if s.registry == nil {
+		return nil
+	}
+	record.UserAgent, record.IPAddress = token.ExtractDevice(ctx)
+	if err := s.registry.Save(ctx, record); err != nil {
+		return err
+	}
+	if s.config.MaxActiveTokens <= 0 {
+		return nil
+	}
+
+	records, err := s.active(ctx, record.UserID)
+	if err != nil {
+		return err
+	}
+	for _, oldest := range records[:max(len(records)-s.config.MaxActiveTokens, 0)] {
+		if err := s.revocations.RevokeToken(ctx, oldest.ID, oldest.ExpiresAt); err != nil {
+			return err
+		}
+		if err := s.registry.Revoke(ctx, oldest.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// active returns the user's registered tokens that are neither expired nor
+// revoked, oldest first
+func (s *service) active(ctx context.Context, userID string) ([]tokenstore.Record, error) {
+	records, err := s.registry.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
-	for jti, expiresAt := range s.revokedTokens {
-		if now.After(expiresAt) {
-			delete(s.revokedTokens, jti)
+	active := records[:0]
+	for _, record := range records {
+		if !record.IsRevoked() && !record.IsExpired(now) {
+			active = append(active, record)
 		}
 	}
+	slices.SortFunc(active, func(a, b tokenstore.Record) int { return a.IssuedAt.Compare(b.IssuedAt) })
+	return active, nil
 }
@@ -5,10 +5,14 @@ import (
 	"testing"
 	"time"
 
+	jwtlib "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
 	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+	tokenStoreMemory "github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
 )
 
 func TestNewService_GivenValidConfig_WhenCreating_ThenReturnsService(t *testing.T) {
@@ -300,6 +304,7 @@ func TestValidateToken_GivenExpiredToken_WhenValidating_ThenReturnsExpiredError(
 	// Create config with very short expiry
 	config := createValidTokenConfig()
 	config.AccessTTL = time.Millisecond
+	config.ClockSkew = 0
 	
 	service, err := jwt.NewService(config)
 	assert.NoError(t, err)
@@ -364,7 +369,7 @@ func TestRefreshToken_GivenValidRefreshToken_WhenRefreshing_ThenReturnsNewTokenP
 	assert.NoError(t, err)
 	assert.NotNil(t, tokenPair)
 	assert.NotEmpty(t, tokenPair.AccessToken)
-	assert.Equal(t, refreshToken, tokenPair.RefreshToken)
+	assert.NotEqual(t, refreshToken, tokenPair.RefreshToken)
 	assert.Equal(t, "bearer", tokenPair.TokenType)
 	assert.True(t, tokenPair.ExpiresAt.After(time.Now()))
 	assert.Greater(t, tokenPair.ExpiresIn, int64(0))
@@ -376,6 +381,30 @@ func TestRefreshToken_GivenValidRefreshToken_WhenRefreshing_ThenReturnsNewTokenP
 	assert.Equal(t, "auth", claims.TokenType)
 }
 
+func TestRefreshToken_GivenSignInRecordedInContext_WhenRefreshing_ThenAccessTokenKeepsOriginalSignIn(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	assert.NoError(t, err)
+
+	authTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	ctx := token.WithAuthentication(context.Background(), authTime, token.AMRPassword)
+
+	accessToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	assert.NoError(t, err)
+	refreshToken, err := service.GenerateRefreshToken(ctx, "user123")
+	assert.NoError(t, err)
+
+	// Refreshing later must not count as signing in again
+	tokenPair, err := service.RefreshToken(context.Background(), refreshToken)
+	assert.NoError(t, err)
+
+	for _, issued := range []string{accessToken, tokenPair.AccessToken} {
+		claims, err := service.ValidateToken(context.Background(), issued)
+		assert.NoError(t, err)
+		assert.True(t, authTime.Equal(claims.AuthTime))
+		assert.Equal(t, []string{token.AMRPassword}, claims.AMR)
+	}
+}
+
 func TestRefreshToken_GivenNonRefreshToken_WhenRefreshing_ThenReturnsError(t *testing.T) {
 	service, err := jwt.NewService(createValidTokenConfig())
 	assert.NoError(t, err)
@@ -429,17 +458,145 @@ func TestListActiveTokens_GivenUserID_WhenListing_ThenReturnsEmptyList(t *testin
 	assert.Empty(t, tokens)
 }
 
-func TestRevokeAllTokensForUser_GivenUserID_WhenRevoking_ThenSucceeds(t *testing.T) {
-	// This is a placeholder implementation in the JWT service
+func TestRevokeAllTokensForUser_GivenIssuedTokens_WhenRevoking_ThenOnlyUsersTokensAreRevoked(t *testing.T) {
+	// Arrange
 	service, err := jwt.NewService(createValidTokenConfig())
 	assert.NoError(t, err)
 
 	ctx := context.Background()
+	accessToken, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	assert.NoError(t, err)
+	refreshToken, err := service.GenerateRefreshToken(ctx, "user123")
+	assert.NoError(t, err)
+	otherToken, _, err := service.GenerateAuthToken(ctx, "user456", "other@example.com")
+	assert.NoError(t, err)
+
+	// Act
 	err = service.RevokeAllTokensForUser(ctx, "user123")
 
+	// Assert
+	assert.NoError(t, err)
+	_, err = service.ValidateToken(ctx, accessToken)
+	assert.Equal(t, token.ErrTokenRevoked, err)
+	_, err = service.RefreshToken(ctx, refreshToken)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.ValidateToken(ctx, otherToken)
+	assert.NoError(t, err)
+}
+
+func TestRevokeAllTokensForUser_GivenRevokedUser_WhenSigningInAgainRightAway_ThenNewTokenIsValid(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, service.RevokeAllTokensForUser(ctx, "user123"))
+
+	// Act
+	accessToken, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, accessToken)
 	assert.NoError(t, err)
 }
 
+func TestRevokeToken_GivenSharedRevocationStore_WhenRevokingOnOneService_ThenOtherRejectsToken(t *testing.T) {
+	// Arrange
+	store := revocationMemory.NewService()
+	first, err := jwt.NewServiceWithRevocations(createValidTokenConfig(), nil, store, time.Hour)
+	require.NoError(t, err)
+	second, err := jwt.NewServiceWithRevocations(createValidTokenConfig(), nil, store, time.Hour)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	revokedToken, _, err := first.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+	userToken, _, err := first.GenerateAuthToken(ctx, "user456", "other@example.com")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, first.RevokeToken(ctx, revokedToken))
+	require.NoError(t, first.RevokeAllTokensForUser(ctx, "user456"))
+
+	// Assert
+	_, err = second.ValidateToken(ctx, revokedToken)
+	assert.Equal(t, token.ErrTokenRevoked, err)
+	_, err = second.ValidateToken(ctx, userToken)
+	assert.Equal(t, token.ErrTokenRevoked, err)
+}
+
+func TestListActiveTokens_GivenRegistry_WhenListing_ThenReturnsIssuedTokensWithDevice(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewServiceWithOptions(createValidTokenConfig(), jwt.Options{Registry: tokenStoreMemory.NewService()})
+	require.NoError(t, err)
+
+	ctx := token.WithDevice(context.Background(), "Mozilla/5.0", "203.0.113.7")
+	accessToken, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+	_, err = service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	_, _, err = service.GenerateAuthToken(ctx, "user456", "other@example.com")
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeToken(ctx, accessToken))
+
+	// Act
+	tokens, err := service.ListActiveTokens(ctx, "user123")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "refresh", tokens[0].TokenType)
+	assert.Equal(t, "Mozilla/5.0", tokens[0].UserAgent)
+	assert.Equal(t, "203.0.113.7", tokens[0].IPAddress)
+	assert.NotEmpty(t, tokens[0].ID)
+}
+
+func TestGenerateAuthToken_GivenMaxActiveTokensReached_WhenIssuing_ThenRevokesOldest(t *testing.T) {
+	// Arrange
+	config := createValidTokenConfig()
+	config.MaxActiveTokens = 2
+	service, err := jwt.NewServiceWithOptions(config, jwt.Options{Registry: tokenStoreMemory.NewService()})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	oldest, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+	middle, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+
+	// Act
+	newest, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, oldest)
+	assert.Equal(t, token.ErrTokenRevoked, err)
+	for _, tokenString := range []string{middle, newest} {
+		_, err = service.ValidateToken(ctx, tokenString)
+		assert.NoError(t, err)
+	}
+	tokens, err := service.ListActiveTokens(ctx, "user123")
+	require.NoError(t, err)
+	assert.Len(t, tokens, 2)
+}
+
+func TestRevokeAllTokensForUser_GivenRegistry_WhenRevoking_ThenListsNoTokens(t *testing.T) {
+	service, err := jwt.NewServiceWithOptions(createValidTokenConfig(), jwt.Options{Registry: tokenStoreMemory.NewService()})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, _, err = service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+	_, err = service.GenerateAPIToken(ctx, "user123", []string{"read"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeAllTokensForUser(ctx, "user123"))
+
+	tokens, err := service.ListActiveTokens(ctx, "user123")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
 func TestValidateAPIToken_GivenValidAPIToken_WhenValidating_ThenReturnsAPIClaims(t *testing.T) {
 	service, err := jwt.NewService(createValidTokenConfig())
 	assert.NoError(t, err)
@@ -520,6 +677,62 @@ func TestValidateEmailVerificationToken_GivenValidVerificationToken_WhenValidati
 	assert.Equal(t, "verification", claims.TokenType)
 }
 
+func TestValidateImpersonationToken_GivenImpersonationToken_WhenValidating_ThenReturnsActorAndScopes(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	scopes := []string{"user:update_profile"}
+
+	// Generate impersonation token
+	issued, err := service.GenerateImpersonationToken(ctx, "admin-1", "user123", scopes)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", issued.ActorID)
+	assert.Equal(t, "user123", issued.UserID)
+	assert.WithinDuration(t, time.Now().Add(token.DefaultImpersonationTTL), issued.ExpiresAt, 5*time.Second)
+
+	// Validate impersonation token
+	claims, err := service.ValidateImpersonationToken(ctx, issued.Token)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, claims)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.Equal(t, "admin-1", claims.ActorID)
+	assert.Equal(t, scopes, claims.Scopes)
+	assert.True(t, claims.IsImpersonationToken())
+}
+
+func TestValidateImpersonationToken_GivenAuthToken_WhenValidating_ThenReturnsError(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Generate auth token (not impersonation token)
+	authToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	assert.NoError(t, err)
+
+	// Try to validate as impersonation token
+	claims, err := service.ValidateImpersonationToken(ctx, authToken)
+
+	assert.Equal(t, token.ErrInvalidToken, err)
+	assert.Nil(t, claims)
+}
+
+func TestRefreshToken_GivenImpersonationToken_WhenRefreshing_ThenReturnsError(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	issued, err := service.GenerateImpersonationToken(ctx, "admin-1", "user123", []string{"user:update_profile"})
+	assert.NoError(t, err)
+
+	tokenPair, err := service.RefreshToken(ctx, issued.Token)
+
+	assert.Error(t, err)
+	assert.Nil(t, tokenPair)
+}
+
 func TestJWTService_GivenCompleteWorkflow_WhenExecuting_ThenAllOperationsWork(t *testing.T) {
 	service, err := jwt.NewService(createValidTokenConfig())
 	assert.NoError(t, err)
@@ -570,9 +783,361 @@ func TestJWTService_GivenCompleteWorkflow_WhenExecuting_ThenAllOperationsWork(t
 	assert.Equal(t, token.ErrTokenRevoked, err)
 }
 
+func TestGenerateAuthTokenWithClaims_GivenExtraClaims_WhenValidating_ThenReturnsThemWithoutReservedOnes(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	ctx := token.WithExtraClaims(context.Background(), map[string]interface{}{"region": "eu"})
+	extra := map[string]interface{}{"tenant_id": "tenant-1", "role": "admin", "user_id": "someone-else"}
+
+	// Act
+	authToken, _, err := service.GenerateAuthTokenWithClaims(ctx, "user-123", "test@example.com", extra)
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(context.Background(), authToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.UserID)
+	assert.Equal(t, map[string]interface{}{"tenant_id": "tenant-1", "role": "admin", "region": "eu"}, claims.Custom)
+}
+
+func TestRefreshToken_GivenCustomClaimsAndAudience_WhenRefreshing_ThenAccessTokenKeepsThem(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	ctx := token.WithAudience(token.WithExtraClaims(context.Background(), map[string]interface{}{"tenant_id": "tenant-1"}), "billing")
+	refreshToken, err := service.GenerateRefreshToken(ctx, "user-123")
+	require.NoError(t, err)
+
+	// Act
+	pair, err := service.RefreshToken(context.Background(), refreshToken)
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(context.Background(), pair.AccessToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "billing", claims.Audience)
+	assert.Equal(t, "tenant-1", claims.Custom["tenant_id"])
+}
+
+func TestValidateToken_GivenRequiredIssuerAndAudience_WhenTokenDoesNotMatch_ThenReturnsError(t *testing.T) {
+	issuer := createValidTokenConfig()
+	issuer.Issuer = "other-issuer"
+	other, err := jwt.NewService(issuer)
+	require.NoError(t, err)
+
+	config := createValidTokenConfig()
+	config.RequireIssuer = true
+	config.RequireAudience = true
+	service, err := jwt.NewService(config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		issue       func(ctx context.Context) (string, time.Time, error)
+		expectedErr error
+	}{
+		{
+			name: "Given the configured issuer and audience, When validating, Then should accept the token",
+			issue: func(ctx context.Context) (string, time.Time, error) {
+				return service.GenerateAuthToken(ctx, "user-123", "test@example.com")
+			},
+		},
+		{
+			name: "Given another audience, When validating, Then should reject the token",
+			issue: func(ctx context.Context) (string, time.Time, error) {
+				return service.GenerateAuthToken(token.WithAudience(ctx, "billing"), "user-123", "test@example.com")
+			},
+			expectedErr: token.ErrInvalidAudience,
+		},
+		{
+			name: "Given another issuer, When validating, Then should reject the token",
+			issue: func(ctx context.Context) (string, time.Time, error) {
+				return other.GenerateAuthToken(ctx, "user-123", "test@example.com")
+			},
+			expectedErr: token.ErrInvalidIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authToken, _, err := tt.issue(context.Background())
+			require.NoError(t, err)
+
+			// Act
+			_, err = service.ValidateToken(context.Background(), authToken)
+
+			// Assert
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestValidateToken_GivenClockSkew_WhenTokenExpiredWithinIt_ThenAcceptsToken(t *testing.T) {
+	// Arrange
+	config := createValidTokenConfig()
+	now := time.Now()
+	expired, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{
+		"user_id":    "user-123",
+		"token_type": "auth",
+		"iat":        now.Add(-time.Hour).Unix(),
+		"exp":        now.Add(-30 * time.Second).Unix(),
+		"jti":        "expired-jti",
+	}).SignedString(config.Secret)
+	require.NoError(t, err)
+
+	config.ClockSkew = 0
+	strict, err := jwt.NewService(config)
+	require.NoError(t, err)
+	config.ClockSkew = time.Minute
+	lenient, err := jwt.NewService(config)
+	require.NoError(t, err)
+
+	// Act
+	_, strictErr := strict.ValidateToken(context.Background(), expired)
+	claims, lenientErr := lenient.ValidateToken(context.Background(), expired)
+
+	// Assert
+	assert.ErrorIs(t, strictErr, jwtlib.ErrTokenExpired)
+	assert.ErrorIs(t, strictErr, token.ErrTokenExpired)
+	require.NoError(t, lenientErr)
+	assert.Equal(t, "user-123", claims.UserID)
+}
+
+func TestNewService_GivenRequiredAudienceWithoutAudience_WhenCreating_ThenReturnsError(t *testing.T) {
+	config := createValidTokenConfig()
+	config.Audience = ""
+	config.RequireAudience = true
+
+	_, err := jwt.NewService(config)
+
+	assert.Error(t, err)
+}
+
 // Helper function to create a valid token configuration
 func createValidTokenConfig() token.TokenConfig {
 	config := token.DefaultTokenConfig()
 	config.Secret = []byte("test-secret-key-that-is-long-enough-for-hmac")
 	return config
-}
\ No newline at end of file
+}
+
+func TestConsumeOneTimeToken_GivenSharedRevocationStore_WhenConsumedConcurrently_ThenExactlyOneSucceeds(t *testing.T) {
+	// Arrange
+	store := revocationMemory.NewService()
+	first, err := jwt.NewServiceWithRevocations(createValidTokenConfig(), nil, store, time.Hour)
+	require.NoError(t, err)
+	second, err := jwt.NewServiceWithRevocations(createValidTokenConfig(), nil, store, time.Hour)
+	require.NoError(t, err)
+	ctx := context.Background()
+	oneTimeToken, err := first.GenerateOneTimeToken(ctx, "magic_link", "user123", time.Minute)
+	require.NoError(t, err)
+
+	// Act
+	results := make(chan error, 8)
+	for i := 0; i < cap(results); i++ {
+		service := first
+		if i%2 == 1 {
+			service = second
+		}
+		go func() {
+			_, err := service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+			results <- err
+		}()
+	}
+
+	// Assert
+	succeeded := 0
+	for i := 0; i < cap(results); i++ {
+		if err := <-results; err == nil {
+			succeeded++
+		} else {
+			assert.Equal(t, token.ErrTokenAlreadyUsed, err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestConsumeOneTimeToken_GivenToken_WhenMisused_ThenReturnsError(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	ctx := context.Background()
+	oneTimeToken, err := service.GenerateOneTimeToken(ctx, "magic_link", "user123", time.Minute)
+	require.NoError(t, err)
+	authToken, _, err := service.GenerateAuthToken(ctx, "user123", "test@example.com")
+	require.NoError(t, err)
+
+	// Act
+	_, wrongPurposeErr := service.ConsumeOneTimeToken(ctx, oneTimeToken, "email_change")
+	_, authTokenErr := service.ConsumeOneTimeToken(ctx, authToken, "magic_link")
+	_, validateErr := service.ValidateToken(ctx, oneTimeToken)
+	claims, err := service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+
+	// Assert
+	assert.Equal(t, token.ErrInvalidToken, wrongPurposeErr)
+	assert.Equal(t, token.ErrInvalidToken, authTokenErr)
+	assert.Equal(t, token.ErrInvalidToken, validateErr)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.Subject)
+	assert.Equal(t, "magic_link", claims.Purpose)
+	assert.NotEmpty(t, claims.JTI)
+}
+
+func TestGenerateOneTimeToken_GivenMissingPurposeOrTTL_WhenGenerating_ThenReturnsError(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+
+	_, noPurposeErr := service.GenerateOneTimeToken(context.Background(), "", "user123", time.Minute)
+	_, noTTLErr := service.GenerateOneTimeToken(context.Background(), "magic_link", "user123", 0)
+
+	assert.Error(t, noPurposeErr)
+	assert.Error(t, noTTLErr)
+}
+
+func TestValidateToken_GivenClockSkew_WhenCheckingLifetime_ThenToleratesDriftOnly(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		issuedAt    time.Time
+		notBefore   time.Time
+		expiresAt   time.Time
+		expectedErr error
+	}{
+		{
+			name:      "Given nbf within the skew, When validating, Then accepts the token",
+			issuedAt:  now,
+			notBefore: now.Add(20 * time.Second),
+			expiresAt: now.Add(time.Hour),
+		},
+		{
+			name:        "Given nbf beyond the skew, When validating, Then returns not yet valid error",
+			issuedAt:    now,
+			notBefore:   now.Add(2 * time.Minute),
+			expiresAt:   now.Add(time.Hour),
+			expectedErr: token.ErrTokenNotYetValid,
+		},
+		{
+			name:        "Given iat beyond the skew, When validating, Then returns not yet valid error",
+			issuedAt:    now.Add(2 * time.Minute),
+			notBefore:   now,
+			expiresAt:   now.Add(time.Hour),
+			expectedErr: token.ErrTokenNotYetValid,
+		},
+		{
+			name:        "Given exp beyond the skew, When validating, Then returns expired error",
+			issuedAt:    now.Add(-time.Hour),
+			notBefore:   now.Add(-time.Hour),
+			expiresAt:   now.Add(-2 * time.Minute),
+			expectedErr: token.ErrTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := createValidTokenConfig()
+			config.ClockSkew = time.Minute
+			service, err := jwt.NewService(config)
+			require.NoError(t, err)
+			tokenString, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{
+				"user_id":    "user-123",
+				"token_type": "auth",
+				"iat":        tt.issuedAt.Unix(),
+				"nbf":        tt.notBefore.Unix(),
+				"exp":        tt.expiresAt.Unix(),
+				"jti":        "skewed-jti",
+			}).SignedString(config.Secret)
+			require.NoError(t, err)
+
+			// Act
+			claims, err := service.ValidateToken(context.Background(), tokenString)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", claims.UserID)
+		})
+	}
+}
+
+func TestGenerateAuthToken_GivenValidUser_WhenGenerating_ThenSetsNotBefore(t *testing.T) {
+	// Arrange
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+
+	// Act
+	tokenString, _, err := service.GenerateAuthToken(context.Background(), "user-123", "test@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	parsed, _, err := jwtlib.NewParser().ParseUnverified(tokenString, jwtlib.MapClaims{})
+	require.NoError(t, err)
+	claims := parsed.Claims.(jwtlib.MapClaims)
+	assert.Equal(t, claims["iat"], claims["nbf"])
+}
+
+func TestConsumeOneTimeToken_GivenFutureNotBefore_WhenConsuming_ThenReturnsNotYetValidWithoutSpendingIt(t *testing.T) {
+	// Arrange
+	config := createValidTokenConfig()
+	service, err := jwt.NewService(config)
+	require.NoError(t, err)
+	now := time.Now()
+	oneTimeToken, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{
+		"user_id":    "user-123",
+		"token_type": token.TokenTypeOneTime,
+		"purpose":    "magic_link",
+		"iat":        now.Unix(),
+		"nbf":        now.Add(5 * time.Minute).Unix(),
+		"exp":        now.Add(time.Hour).Unix(),
+		"iss":        config.Issuer,
+		"aud":        config.Audience,
+		"jti":        "future-jti",
+	}).SignedString(config.Secret)
+	require.NoError(t, err)
+
+	// Act
+	claims, err := service.ConsumeOneTimeToken(context.Background(), oneTimeToken, "magic_link")
+
+	// Assert
+	assert.ErrorIs(t, err, token.ErrTokenNotYetValid)
+	assert.Nil(t, claims)
+}
+
+func TestRefreshToken_GivenExchangedRefreshToken_WhenReused_ThenRevokesItsFamily(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+	loginAccess, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	stolen, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	otherSession, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	rotated, err := service.RefreshToken(ctx, stolen)
+	require.NoError(t, err)
+
+	// Act
+	_, reuseErr := service.RefreshToken(ctx, stolen)
+
+	// Assert
+	var reuse token.RefreshTokenReuseError
+	require.ErrorAs(t, reuseErr, &reuse)
+	assert.ErrorIs(t, reuseErr, token.ErrRefreshTokenReused)
+	assert.Equal(t, "user123", reuse.UserID)
+	_, err = service.ValidateToken(ctx, rotated.AccessToken)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.RefreshToken(ctx, rotated.RefreshToken)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.ValidateToken(ctx, stolen)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.RefreshToken(ctx, otherSession)
+	assert.NoError(t, err, "other families are untouched")
+	_, err = service.ValidateToken(ctx, loginAccess)
+	assert.NoError(t, err)
+}
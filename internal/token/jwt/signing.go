@@ -0,0 +1,256 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for RS256
+const minRSAKeyBits = 2048
+
+// errNoSigningKey is returned when a service configured with only a
+// verification key is asked to issue a token
+var errNoSigningKey = errors.New("no signing key configured")
+
+// keySet holds the key tokens are signed with and the keys accepted when
+// verifying them. Current keys are looked up by algorithm and retired keys by
+// kid, and each key is only used with its own algorithm, so a token can never
+// have its signature checked with a key meant for another algorithm.
+type keySet struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	keyID      string
+	current    *token.JWK
+	verifyKeys map[string]interface{}
+	retired    map[string]retiredKey
+	algorithms []string
+}
+
+// retiredKey is a replaced key, accepted until the tokens it signed expire
+type retiredKey struct {
+	jwk   token.JWK
+	key   crypto.PublicKey
+	until time.Time
+}
+
+// newKeySet builds the keys for the configured algorithm, checking that the
+// key types and sizes match it
+func newKeySet(config token.TokenConfig) (*keySet, error) {
+	keys := &keySet{verifyKeys: make(map[string]interface{}), retired: make(map[string]retiredKey)}
+
+	switch config.Algorithm {
+	case token.AlgorithmHS256:
+		keys.method = jwt.SigningMethodHS256
+		keys.signKey = config.Secret
+	case token.AlgorithmRS256, token.AlgorithmES256:
+		publicKey := config.PublicKey()
+		if err := checkKey(config.Algorithm, publicKey); err != nil {
+			return nil, err
+		}
+		jwk, err := token.NewJWK(publicKey, config.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		keys.keyID = jwk.KeyID
+		keys.current = &jwk
+		keys.verifyKeys[config.Algorithm] = publicKey
+		if err := keys.setSigner(config, publicKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", config.Algorithm)
+	}
+
+	// HS256 stays accepted while a secret is configured, so tokens issued
+	// before switching to an asymmetric algorithm remain valid
+	keys.algorithms = []string{config.Algorithm}
+	if len(config.Secret) > 0 {
+		keys.verifyKeys[token.AlgorithmHS256] = config.Secret
+		if config.Algorithm != token.AlgorithmHS256 {
+			keys.algorithms = append(keys.algorithms, token.AlgorithmHS256)
+		}
+	}
+
+	lifetime := TokenLifetime(config)
+	for _, retired := range config.RetiredKeys {
+		jwk, err := token.NewJWK(retired.Key, retired.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retired key: %w", err)
+		}
+		if err := checkKey(jwk.Algorithm, retired.Key); err != nil {
+			return nil, fmt.Errorf("invalid retired key: %w", err)
+		}
+		if jwk.KeyID == keys.keyID {
+			return nil, fmt.Errorf("retired key %s is the current key", jwk.KeyID)
+		}
+		keys.retired[jwk.KeyID] = retiredKey{jwk: jwk, key: retired.Key, until: retired.RetiredAt.Add(lifetime)}
+		if !slices.Contains(keys.algorithms, jwk.Algorithm) {
+			keys.algorithms = append(keys.algorithms, jwk.Algorithm)
+		}
+	}
+
+	return keys, nil
+}
+
+// checkKey checks the public key can verify the asymmetric algorithm
+func checkKey(algorithm string, publicKey crypto.PublicKey) error {
+	switch algorithm {
+	case token.AlgorithmRS256:
+		rsaKey, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an RSA key", algorithm)
+		}
+		if rsaKey.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("%s requires an RSA key of at least %d bits", algorithm, minRSAKeyBits)
+		}
+	case token.AlgorithmES256:
+		ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an ECDSA key", algorithm)
+		}
+		if ecdsaKey.Curve != elliptic.P256() {
+			return fmt.Errorf("%s requires a P-256 key", algorithm)
+		}
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	return nil
+}
+
+// TokenLifetime is the longest any token issued with the configuration stays
+// valid, which is how long a retired key is kept
+func TokenLifetime(config token.TokenConfig) time.Duration {
+	impersonationTTL := config.ImpersonationTTL
+	if impersonationTTL <= 0 {
+		impersonationTTL = token.DefaultImpersonationTTL
+	}
+	return max(config.AccessTTL*apiTokenTTLFactor, config.RefreshTTL, config.ResetTTL, config.VerificationTTL, impersonationTTL)
+}
+
+// setSigner uses the configured signing key, if any, after checking it is
+// the private half of the verification key
+func (k *keySet) setSigner(config token.TokenConfig, publicKey crypto.PublicKey) error {
+	if config.SigningKey == nil {
+		return nil
+	}
+	equaler, ok := publicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !equaler.Equal(config.SigningKey.Public()) {
+		return fmt.Errorf("signing key does not match the verification key")
+	}
+
+	method := &signerMethod{SigningMethod: jwt.SigningMethodRS256, hash: crypto.SHA256}
+	if config.Algorithm == token.AlgorithmES256 {
+		method = &signerMethod{SigningMethod: jwt.SigningMethodES256, hash: crypto.SHA256, keySize: 32}
+	}
+	k.method = method
+	k.signKey = config.SigningKey
+	return nil
+}
+
+// sign signs the claims with the configured algorithm, naming the key in the
+// kid header when it is published
+func (k *keySet) sign(claims jwt.Claims) (string, error) {
+	if k.method == nil {
+		return "", errNoSigningKey
+	}
+	jwtToken := jwt.NewWithClaims(k.method, claims)
+	if k.keyID != "" {
+		jwtToken.Header["kid"] = k.keyID
+	}
+	return jwtToken.SignedString(k.signKey)
+}
+
+// publicKeys returns the current key and the retired keys still in use
+func (k *keySet) publicKeys(now time.Time) *token.JWKSet {
+	var retired []token.JWK
+	for _, key := range k.retired {
+		if now.Before(key.until) {
+			retired = append(retired, key.jwk)
+		}
+	}
+	slices.SortFunc(retired, func(a, b token.JWK) int { return strings.Compare(a.KeyID, b.KeyID) })
+
+	set := &token.JWKSet{Keys: []token.JWK{}}
+	if k.current != nil {
+		set.Keys = append(set.Keys, *k.current)
+	}
+	set.Keys = append(set.Keys, retired...)
+	return set
+}
+
+// parse parses and verifies a token. The algorithm in the token header must
+// be one the service accepts and, with the kid of a retired key, must be that
+// key's; it only picks the matching key, which rejects algorithm confusion
+// such as an HS256 token keyed with the public key, or an unsigned token.
+func (k *keySet) parse(tokenString string, options ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if kid, _ := t.Header["kid"].(string); kid != "" && kid != k.keyID {
+			retired, ok := k.retired[kid]
+			if !ok || retired.jwk.Algorithm != t.Method.Alg() || !time.Now().Before(retired.until) {
+				return nil, fmt.Errorf("unknown signing key: %s", kid)
+			}
+			return retired.key, nil
+		}
+
+		key, ok := k.verifyKeys[t.Method.Alg()]
+		if !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return key, nil
+	}, append(options, jwt.WithValidMethods(k.algorithms))...)
+}
+
+// signerMethod signs with a crypto.Signer, so private keys held in a KMS or
+// HSM work as well as in-memory ones. Verification is left to the embedded
+// standard method. It is deliberately not registered with the jwt package, so
+// parsing always resolves the standard method.
+type signerMethod struct {
+	jwt.SigningMethod
+	hash    crypto.Hash
+	keySize int // ECDSA coordinate size in bytes; zero for RSA
+}
+
+// Sign signs the string, converting ECDSA signatures from the ASN.1 form
+// crypto.Signer returns to the fixed-size r||s form of RFC 7518 section 3.4
+func (m *signerMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	hasher := m.hash.New()
+	hasher.Write([]byte(signingString))
+	signature, err := signer.Sign(rand.Reader, hasher.Sum(nil), m.hash)
+	if err != nil {
+		return nil, err
+	}
+	if m.keySize == 0 {
+		return signature, nil
+	}
+
+	var parsed struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	if parsed.R.BitLen() > m.keySize*8 || parsed.S.BitLen() > m.keySize*8 {
+		return nil, fmt.Errorf("invalid ECDSA signature size")
+	}
+	raw := make([]byte, 2*m.keySize)
+	parsed.R.FillBytes(raw[:m.keySize])
+	parsed.S.FillBytes(raw[m.keySize:])
+	return raw, nil
+}
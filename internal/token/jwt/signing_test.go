@@ -0,0 +1,577 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/signingkey"
+	"github.com/gentra/decorator-arch-go/internal/signingkey/memory"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+)
+
+// opaqueSigner hides the key type behind crypto.Signer, the way a KMS or HSM
+// backed signer does
+type opaqueSigner struct {
+	signer crypto.Signer
+}
+
+func (s opaqueSigner) Public() crypto.PublicKey { return s.signer.Public() }
+
+func (s opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.Sign(rand, digest, opts)
+}
+
+func newRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return key
+}
+
+func newECDSAKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func asymmetricConfig(algorithm string, signingKey crypto.Signer) token.TokenConfig {
+	config := token.DefaultTokenConfig()
+	config.Algorithm = algorithm
+	config.SigningKey = signingKey
+	return config
+}
+
+func TestNewService_GivenAsymmetricKeys_WhenCreating_ThenChecksKeyMatchesAlgorithm(t *testing.T) {
+	rsaKey := newRSAKey(t, 2048)
+	ecdsaKey := newECDSAKey(t, elliptic.P256())
+
+	tests := []struct {
+		name        string
+		config      func() token.TokenConfig
+		expectError bool
+	}{
+		{
+			name:   "Given RS256 with an RSA key, When creating, Then returns service",
+			config: func() token.TokenConfig { return asymmetricConfig(token.AlgorithmRS256, rsaKey) },
+		},
+		{
+			name:   "Given ES256 with a P-256 key, When creating, Then returns service",
+			config: func() token.TokenConfig { return asymmetricConfig(token.AlgorithmES256, ecdsaKey) },
+		},
+		{
+			name: "Given RS256 with only a public key, When creating, Then returns service",
+			config: func() token.TokenConfig {
+				config := asymmetricConfig(token.AlgorithmRS256, nil)
+				config.VerificationKey = &rsaKey.PublicKey
+				return config
+			},
+		},
+		{
+			name:        "Given RS256 with an ECDSA key, When creating, Then returns error",
+			config:      func() token.TokenConfig { return asymmetricConfig(token.AlgorithmRS256, ecdsaKey) },
+			expectError: true,
+		},
+		{
+			name:        "Given RS256 with a 1024 bit key, When creating, Then returns error",
+			config:      func() token.TokenConfig { return asymmetricConfig(token.AlgorithmRS256, newRSAKey(t, 1024)) },
+			expectError: true,
+		},
+		{
+			name: "Given ES256 with a P-384 key, When creating, Then returns error",
+			config: func() token.TokenConfig {
+				return asymmetricConfig(token.AlgorithmES256, newECDSAKey(t, elliptic.P384()))
+			},
+			expectError: true,
+		},
+		{
+			name: "Given a verification key of another pair, When creating, Then returns error",
+			config: func() token.TokenConfig {
+				config := asymmetricConfig(token.AlgorithmES256, ecdsaKey)
+				config.VerificationKey = &newECDSAKey(t, elliptic.P256()).PublicKey
+				return config
+			},
+			expectError: true,
+		},
+		{
+			name: "Given RS256 with only a secret, When creating, Then returns error",
+			config: func() token.TokenConfig {
+				config := createValidTokenConfig()
+				config.Algorithm = token.AlgorithmRS256
+				return config
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			service, err := jwt.NewService(tt.config())
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, service)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, service)
+			}
+		})
+	}
+}
+
+func TestAsymmetricSigning_GivenSigningKey_WhenIssuingAndValidating_ThenRoundTrips(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		key       crypto.Signer
+	}{
+		{name: "Given an RSA key, When signing RS256, Then validates", algorithm: token.AlgorithmRS256, key: newRSAKey(t, 2048)},
+		{name: "Given a P-256 key, When signing ES256, Then validates", algorithm: token.AlgorithmES256, key: newECDSAKey(t, elliptic.P256())},
+		{name: "Given an opaque RSA signer, When signing RS256, Then validates", algorithm: token.AlgorithmRS256, key: opaqueSigner{newRSAKey(t, 2048)}},
+		{name: "Given an opaque ECDSA signer, When signing ES256, Then validates", algorithm: token.AlgorithmES256, key: opaqueSigner{newECDSAKey(t, elliptic.P256())}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			service, err := jwt.NewService(asymmetricConfig(tt.algorithm, tt.key))
+			require.NoError(t, err)
+
+			// Act
+			authToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+			apiToken, err := service.GenerateAPIToken(ctx, "user123", []string{"read"})
+			require.NoError(t, err)
+
+			// Assert
+			claims, err := service.ValidateToken(ctx, authToken)
+			require.NoError(t, err)
+			assert.Equal(t, "user123", claims.UserID)
+			apiClaims, err := service.ValidateAPIToken(ctx, apiToken.Token)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"read"}, apiClaims.Scopes)
+
+			parsed, _, err := jwtlib.NewParser().ParseUnverified(authToken, jwtlib.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, parsed.Header["alg"])
+		})
+	}
+}
+
+func TestAsymmetricSigning_GivenOnlyVerificationKey_WhenUsed_ThenValidatesButCannotIssue(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	key := newECDSAKey(t, elliptic.P256())
+	issuer, err := jwt.NewService(asymmetricConfig(token.AlgorithmES256, key))
+	require.NoError(t, err)
+	config := asymmetricConfig(token.AlgorithmES256, nil)
+	config.VerificationKey = &key.PublicKey
+	verifier, err := jwt.NewService(config)
+	require.NoError(t, err)
+	tokenString, _, err := issuer.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	claims, validateErr := verifier.ValidateToken(ctx, tokenString)
+	_, _, generateErr := verifier.GenerateAuthToken(ctx, "user123", "user@example.com")
+
+	// Assert
+	require.NoError(t, validateErr)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.Error(t, generateErr)
+}
+
+func TestValidateToken_GivenAlgorithmConfusion_WhenValidating_ThenReturnsError(t *testing.T) {
+	rsaKey := newRSAKey(t, 2048)
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	claims := jwtlib.MapClaims{
+		"user_id":    "user123",
+		"token_type": "auth",
+		"iat":        time.Now().Unix(),
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"jti":        "jti-1",
+	}
+	sign := func(t *testing.T, method jwtlib.SigningMethod, key interface{}) string {
+		t.Helper()
+		tokenString, err := jwtlib.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return tokenString
+	}
+
+	tests := []struct {
+		name   string
+		config token.TokenConfig
+		token  func(t *testing.T) string
+	}{
+		{
+			name:   "Given HS256 keyed with the RSA public key, When validating on RS256, Then returns error",
+			config: asymmetricConfig(token.AlgorithmRS256, rsaKey),
+			token: func(t *testing.T) string {
+				return sign(t, jwtlib.SigningMethodHS256, publicKeyPEM)
+			},
+		},
+		{
+			name:   "Given an unsigned token, When validating on RS256, Then returns error",
+			config: asymmetricConfig(token.AlgorithmRS256, rsaKey),
+			token: func(t *testing.T) string {
+				return sign(t, jwtlib.SigningMethodNone, jwtlib.UnsafeAllowNoneSignatureType)
+			},
+		},
+		{
+			name:   "Given an ES256 token, When validating on RS256, Then returns error",
+			config: asymmetricConfig(token.AlgorithmRS256, rsaKey),
+			token: func(t *testing.T) string {
+				return sign(t, jwtlib.SigningMethodES256, newECDSAKey(t, elliptic.P256()))
+			},
+		},
+		{
+			name:   "Given an RS256 token, When validating on HS256, Then returns error",
+			config: createValidTokenConfig(),
+			token: func(t *testing.T) string {
+				return sign(t, jwtlib.SigningMethodRS256, rsaKey)
+			},
+		},
+		{
+			name:   "Given an unsigned token, When validating on HS256, Then returns error",
+			config: createValidTokenConfig(),
+			token: func(t *testing.T) string {
+				return sign(t, jwtlib.SigningMethodNone, jwtlib.UnsafeAllowNoneSignatureType)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, err := jwt.NewService(tt.config)
+			require.NoError(t, err)
+
+			// Act
+			_, err = service.ValidateToken(context.Background(), tt.token(t))
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateToken_GivenHS256TokenAfterSwitchingToES256_WhenValidating_ThenAcceptsOnlyWhileSecretIsSet(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepSecret  bool
+		expectError bool
+	}{
+		{name: "Given the secret is kept, When validating an HS256 token, Then accepts it", keepSecret: true},
+		{name: "Given the secret is dropped, When validating an HS256 token, Then returns error", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			previous, err := jwt.NewService(createValidTokenConfig())
+			require.NoError(t, err)
+			tokenString, _, err := previous.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+
+			config := asymmetricConfig(token.AlgorithmES256, newECDSAKey(t, elliptic.P256()))
+			if tt.keepSecret {
+				config.Secret = createValidTokenConfig().Secret
+			}
+			service, err := jwt.NewService(config)
+			require.NoError(t, err)
+
+			// Act
+			_, err = service.ValidateToken(ctx, tokenString)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseKeys_GivenPEMEncodings_WhenParsing_ThenReturnsKeys(t *testing.T) {
+	rsaKey := newRSAKey(t, 2048)
+	ecdsaKey := newECDSAKey(t, elliptic.P256())
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecdsaKey)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecdsaKey)
+	require.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		block    *pem.Block
+		parse    func([]byte) (interface{ Equal(crypto.PublicKey) bool }, error)
+		expected crypto.PublicKey
+	}{
+		{
+			name:     "Given a PKCS #1 private key, When parsing, Then returns the RSA key",
+			block:    &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+			parse:    parsePrivatePublicKey,
+			expected: &rsaKey.PublicKey,
+		},
+		{
+			name:     "Given a SEC 1 private key, When parsing, Then returns the ECDSA key",
+			block:    &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1},
+			parse:    parsePrivatePublicKey,
+			expected: &ecdsaKey.PublicKey,
+		},
+		{
+			name:     "Given a PKCS #8 private key, When parsing, Then returns the ECDSA key",
+			block:    &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8},
+			parse:    parsePrivatePublicKey,
+			expected: &ecdsaKey.PublicKey,
+		},
+		{
+			name:     "Given a PKIX public key, When parsing, Then returns the ECDSA key",
+			block:    &pem.Block{Type: "PUBLIC KEY", Bytes: pkix},
+			parse:    parsePublicKey,
+			expected: &ecdsaKey.PublicKey,
+		},
+		{
+			name:     "Given a PKCS #1 public key, When parsing, Then returns the RSA key",
+			block:    &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)},
+			parse:    parsePublicKey,
+			expected: &rsaKey.PublicKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			publicKey, err := tt.parse(pem.EncodeToMemory(tt.block))
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, publicKey.Equal(tt.expected))
+		})
+	}
+}
+
+func TestParseKeys_GivenUnsupportedInput_WhenParsing_ThenReturnsError(t *testing.T) {
+	_, privateErr := jwt.ParsePrivateKey([]byte("not a key"))
+	_, publicErr := jwt.ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte{1}}))
+
+	assert.Error(t, privateErr)
+	assert.Error(t, publicErr)
+}
+
+func parsePrivatePublicKey(data []byte) (interface{ Equal(crypto.PublicKey) bool }, error) {
+	signer, err := jwt.ParsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Public().(interface{ Equal(crypto.PublicKey) bool }), nil
+}
+
+func parsePublicKey(data []byte) (interface{ Equal(crypto.PublicKey) bool }, error) {
+	publicKey, err := jwt.ParsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return publicKey.(interface{ Equal(crypto.PublicKey) bool }), nil
+}
+
+func TestPublicKeys_GivenAsymmetricKey_WhenListing_ThenPublishesKeyNamedInTokens(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, err := jwt.NewService(asymmetricConfig(token.AlgorithmES256, newECDSAKey(t, elliptic.P256())))
+	require.NoError(t, err)
+	tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	keys, err := service.PublicKeys(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, keys.Keys, 1)
+	parsed, _, err := jwtlib.NewParser().ParseUnverified(tokenString, jwtlib.MapClaims{})
+	require.NoError(t, err)
+	key, ok := keys.Key(parsed.Header["kid"].(string))
+	require.True(t, ok)
+	assert.Equal(t, token.AlgorithmES256, key.Algorithm)
+	assert.Equal(t, "sig", key.Use)
+}
+
+func TestPublicKeys_GivenHMACSecret_WhenListing_ThenPublishesNothing(t *testing.T) {
+	service, err := jwt.NewService(createValidTokenConfig())
+	require.NoError(t, err)
+
+	keys, err := service.PublicKeys(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, keys.Keys)
+}
+
+func TestKeyRollover_GivenRetiredKey_WhenValidatingItsTokens_ThenAcceptsUntilTheyExpire(t *testing.T) {
+	tests := []struct {
+		name        string
+		retiredAgo  time.Duration
+		expectValid bool
+	}{
+		{name: "Given a key retired just now, When validating its token, Then accepts and publishes it", retiredAgo: 0, expectValid: true},
+		{name: "Given a key retired longer ago than any token lives, When validating its token, Then rejects and drops it", retiredAgo: 30 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			oldKey := newRSAKey(t, 2048)
+			previous, err := jwt.NewService(asymmetricConfig(token.AlgorithmRS256, oldKey))
+			require.NoError(t, err)
+			tokenString, _, err := previous.GenerateAuthToken(ctx, "user123", "user@example.com")
+			require.NoError(t, err)
+
+			config := asymmetricConfig(token.AlgorithmES256, newECDSAKey(t, elliptic.P256()))
+			config.RetiredKeys = []token.RetiredKey{{Key: &oldKey.PublicKey, RetiredAt: time.Now().Add(-tt.retiredAgo)}}
+			service, err := jwt.NewService(config)
+			require.NoError(t, err)
+
+			// Act
+			_, validateErr := service.ValidateToken(ctx, tokenString)
+			keys, err := service.PublicKeys(ctx)
+
+			// Assert
+			require.NoError(t, err)
+			oldJWK, err := token.NewJWK(&oldKey.PublicKey, "")
+			require.NoError(t, err)
+			_, published := keys.Key(oldJWK.KeyID)
+			if tt.expectValid {
+				assert.NoError(t, validateErr)
+				assert.True(t, published)
+				assert.Len(t, keys.Keys, 2)
+			} else {
+				assert.Error(t, validateErr)
+				assert.False(t, published)
+				assert.Len(t, keys.Keys, 1)
+			}
+		})
+	}
+}
+
+func TestKeyRollover_GivenRetiredKeyID_WhenTokenClaimsAnotherAlgorithm_ThenReturnsError(t *testing.T) {
+	// Arrange
+	oldKey := newRSAKey(t, 2048)
+	oldJWK, err := token.NewJWK(&oldKey.PublicKey, "")
+	require.NoError(t, err)
+	config := asymmetricConfig(token.AlgorithmES256, newECDSAKey(t, elliptic.P256()))
+	config.RetiredKeys = []token.RetiredKey{{Key: &oldKey.PublicKey, RetiredAt: time.Now()}}
+	service, err := jwt.NewService(config)
+	require.NoError(t, err)
+
+	forged := jwtlib.NewWithClaims(jwtlib.SigningMethodES256, jwtlib.MapClaims{
+		"user_id":    "user123",
+		"token_type": "auth",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = oldJWK.KeyID
+	tokenString, err := forged.SignedString(newECDSAKey(t, elliptic.P256()))
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.ValidateToken(context.Background(), tokenString)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestKeyStore_GivenRotatedKey_WhenIssuingAndValidating_ThenSignsWithNewKeyAndAcceptsOldTokens(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	first, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, *first, "")
+	require.NoError(t, err)
+	service, err := jwt.NewServiceWithKeys(token.DefaultTokenConfig(), store)
+	require.NoError(t, err)
+	oldToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	second, err := signingkey.GenerateKey(signingkey.AlgorithmRS256)
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, *second, first.ID)
+	require.NoError(t, err)
+	newToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Assert
+	oldParsed, _, err := jwtlib.NewParser().ParseUnverified(oldToken, jwtlib.MapClaims{})
+	require.NoError(t, err)
+	newParsed, _, err := jwtlib.NewParser().ParseUnverified(newToken, jwtlib.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, oldParsed.Header["kid"])
+	assert.Equal(t, second.ID, newParsed.Header["kid"])
+	assert.Equal(t, token.AlgorithmRS256, newParsed.Method.Alg())
+
+	_, err = service.ValidateToken(ctx, oldToken)
+	assert.NoError(t, err)
+	_, err = service.ValidateToken(ctx, newToken)
+	assert.NoError(t, err)
+
+	keys, err := service.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys.Keys, 2)
+	assert.Equal(t, second.ID, keys.Keys[0].KeyID)
+}
+
+func TestKeyStore_GivenPrunedKey_WhenValidatingItsTokens_ThenReturnsError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := memory.NewService()
+	first, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, *first, "")
+	require.NoError(t, err)
+	service, err := jwt.NewServiceWithKeys(token.DefaultTokenConfig(), store)
+	require.NoError(t, err)
+	oldToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	second, err := signingkey.GenerateKey(signingkey.AlgorithmES256)
+	require.NoError(t, err)
+	_, err = store.Rotate(ctx, *second, first.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.Prune(ctx, time.Now().Add(time.Second)))
+
+	// Act
+	_, err = service.ValidateToken(ctx, oldToken)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestKeyStore_GivenEmptyStore_WhenCreatingService_ThenReturnsError(t *testing.T) {
+	_, err := jwt.NewServiceWithKeys(token.DefaultTokenConfig(), memory.NewService())
+
+	assert.ErrorIs(t, err, signingkey.ErrNoCurrentKey)
+}
@@ -0,0 +1,272 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+)
+
+// service implements token.Service with Prometheus metrics
+type service struct {
+	next        token.Service
+	issued      *prometheus.CounterVec
+	validations *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	revocations *prometheus.CounterVec
+}
+
+// NewService creates a new token service that records tokens issued,
+// validated and revoked, validation latencies and the reasons validations
+// fail, and registers the collectors with the registerer
+func NewService(next token.Service, registerer prometheus.Registerer) (token.Service, error) {
+	s := &service{
+		next: next,
+		issued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "token_service",
+			Name:      "issued_total",
+			Help:      "Total token issuances by token type and outcome.",
+		}, []string{"type", "outcome"}),
+		validations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "token_service",
+			Name:      "validations_total",
+			Help:      "Total token validations by token type and outcome.",
+		}, []string{"type", "outcome"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "token_service",
+			Name:      "validation_failures_total",
+			Help:      "Total failed token validations by token type and reason.",
+		}, []string{"type", "reason"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "token_service",
+			Name:      "validation_duration_seconds",
+			Help:      "Token validation latency by token type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		revocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "token_service",
+			Name:      "revocations_total",
+			Help:      "Total revocations of single tokens and of every token of a user, by outcome.",
+		}, []string{"scope", "outcome"}),
+	}
+
+	for _, collector := range []prometheus.Collector{s.issued, s.validations, s.failures, s.duration, s.revocations} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// GenerateAuthToken records the issuance of an auth token
+func (s *service) GenerateAuthToken(ctx context.Context, userID string, email string) (string, time.Time, error) {
+	tokenString, expiresAt, err := s.next.GenerateAuthToken(ctx, userID, email)
+	s.recordIssue(tokenpolicy.TokenTypeAuth, err)
+	return tokenString, expiresAt, err
+}
+
+// GenerateAuthTokenWithClaims records the issuance of an auth token
+func (s *service) GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error) {
+	tokenString, expiresAt, err := s.next.GenerateAuthTokenWithClaims(ctx, userID, email, extra)
+	s.recordIssue(tokenpolicy.TokenTypeAuth, err)
+	return tokenString, expiresAt, err
+}
+
+// GenerateRefreshToken records the issuance of a refresh token
+func (s *service) GenerateRefreshToken(ctx context.Context, userID string) (string, error) {
+	tokenString, err := s.next.GenerateRefreshToken(ctx, userID)
+	s.recordIssue(tokenpolicy.TokenTypeRefresh, err)
+	return tokenString, err
+}
+
+// GenerateAPIToken records the issuance of an API token
+func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*token.APIToken, error) {
+	apiToken, err := s.next.GenerateAPIToken(ctx, userID, scopes)
+	s.recordIssue(tokenpolicy.TokenTypeAPI, err)
+	return apiToken, err
+}
+
+// GeneratePasswordResetToken records the issuance of a password reset token
+func (s *service) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	tokenString, err := s.next.GeneratePasswordResetToken(ctx, userID)
+	s.recordIssue(tokenpolicy.TokenTypeReset, err)
+	return tokenString, err
+}
+
+// GenerateEmailVerificationToken records the issuance of an email verification token
+func (s *service) GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
+	tokenString, err := s.next.GenerateEmailVerificationToken(ctx, userID)
+	s.recordIssue(tokenpolicy.TokenTypeVerification, err)
+	return tokenString, err
+}
+
+// GenerateImpersonationToken records the issuance of an impersonation token
+func (s *service) GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*token.ImpersonationToken, error) {
+	impersonation, err := s.next.GenerateImpersonationToken(ctx, actorID, userID, scopes)
+	s.recordIssue(tokenpolicy.TokenTypeImpersonation, err)
+	return impersonation, err
+}
+
+// GenerateOneTimeToken records the issuance of a one-time token
+func (s *service) GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	tokenString, err := s.next.GenerateOneTimeToken(ctx, purpose, subject, ttl)
+	s.recordIssue(tokenpolicy.TokenTypeOneTime, err)
+	return tokenString, err
+}
+
+// ConsumeOneTimeToken records the consumption of a one-time token as its
+// validation; tokens already used fail with TOKEN_ALREADY_USED
+func (s *service) ConsumeOneTimeToken(ctx context.Context, tokenString, purpose string) (*token.OneTimeClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeOneTime, time.Now())
+
+	claims, err := s.next.ConsumeOneTimeToken(ctx, tokenString, purpose)
+	s.recordValidation(tokenpolicy.TokenTypeOneTime, err)
+	return claims, err
+}
+
+// ValidateToken records the validation of an auth token
+func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeAuth, time.Now())
+
+	claims, err := s.next.ValidateToken(ctx, tokenString)
+	s.recordValidation(tokenpolicy.TokenTypeAuth, err)
+	return claims, err
+}
+
+// ValidateAPIToken records the validation of an API token
+func (s *service) ValidateAPIToken(ctx context.Context, tokenString string) (*token.APITokenClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeAPI, time.Now())
+
+	claims, err := s.next.ValidateAPIToken(ctx, tokenString)
+	s.recordValidation(tokenpolicy.TokenTypeAPI, err)
+	return claims, err
+}
+
+// ValidatePasswordResetToken records the validation of a password reset token
+func (s *service) ValidatePasswordResetToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeReset, time.Now())
+
+	claims, err := s.next.ValidatePasswordResetToken(ctx, tokenString)
+	s.recordValidation(tokenpolicy.TokenTypeReset, err)
+	return claims, err
+}
+
+// ValidateEmailVerificationToken records the validation of an email verification token
+func (s *service) ValidateEmailVerificationToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeVerification, time.Now())
+
+	claims, err := s.next.ValidateEmailVerificationToken(ctx, tokenString)
+	s.recordValidation(tokenpolicy.TokenTypeVerification, err)
+	return claims, err
+}
+
+// ValidateImpersonationToken records the validation of an impersonation token
+func (s *service) ValidateImpersonationToken(ctx context.Context, tokenString string) (*token.ImpersonationClaims, error) {
+	defer s.observe(tokenpolicy.TokenTypeImpersonation, time.Now())
+
+	claims, err := s.next.ValidateImpersonationToken(ctx, tokenString)
+	s.recordValidation(tokenpolicy.TokenTypeImpersonation, err)
+	return claims, err
+}
+
+// RefreshToken records the validation of the refresh token and, when it is
+// accepted, the issuance of the new pair
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*token.TokenPair, error) {
+	defer s.observe(tokenpolicy.TokenTypeRefresh, time.Now())
+
+	pair, err := s.next.RefreshToken(ctx, refreshToken)
+	s.recordValidation(tokenpolicy.TokenTypeRefresh, err)
+	if err == nil {
+		s.recordIssue(tokenpolicy.TokenTypeAuth, nil)
+		s.recordIssue(tokenpolicy.TokenTypeRefresh, nil)
+	}
+	return pair, err
+}
+
+// RevokeToken records the revocation of a token
+func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
+	err := s.next.RevokeToken(ctx, tokenString)
+	s.revocations.WithLabelValues("token", outcome(err)).Inc()
+	return err
+}
+
+// RevokeAllTokensForUser records the revocation of every token of a user
+func (s *service) RevokeAllTokensForUser(ctx context.Context, userID string) error {
+	err := s.next.RevokeAllTokensForUser(ctx, userID)
+	s.revocations.WithLabelValues("user", outcome(err)).Inc()
+	return err
+}
+
+// GetTokenInfo passes through
+func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.TokenInfo, error) {
+	return s.next.GetTokenInfo(ctx, tokenString)
+}
+
+// ListActiveTokens passes through
+func (s *service) ListActiveTokens(ctx context.Context, userID string) ([]token.TokenInfo, error) {
+	return s.next.ListActiveTokens(ctx, userID)
+}
+
+// PublicKeys passes through
+func (s *service) PublicKeys(ctx context.Context) (*token.JWKSet, error) {
+	return s.next.PublicKeys(ctx)
+}
+
+// Helper methods
+
+// observe records the latency of a validation
+func (s *service) observe(tokenType string, start time.Time) {
+	s.duration.WithLabelValues(tokenType).Observe(time.Since(start).Seconds())
+}
+
+// recordIssue counts an issuance
+func (s *service) recordIssue(tokenType string, err error) {
+	s.issued.WithLabelValues(tokenType, outcome(err)).Inc()
+}
+
+// recordValidation counts a validation and, on failure, its reason
+func (s *service) recordValidation(tokenType string, err error) {
+	s.validations.WithLabelValues(tokenType, outcome(err)).Inc()
+	if err != nil {
+		s.failures.WithLabelValues(tokenType, reason(err)).Inc()
+	}
+}
+
+// outcome returns the outcome label of a call
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return "error"
+}
+
+// reason returns a low-cardinality label for why a validation failed. JWTs
+// the parser rejects are labelled with the matching token error code.
+func reason(err error) string {
+	var tokenErr token.TokenError
+	switch {
+	case errors.As(err, &tokenErr):
+		return tokenErr.Code
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return token.ErrTokenExpired.Code
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return token.ErrTokenNotYetValid.Code
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return token.ErrInvalidSignature.Code
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return token.ErrMalformedToken.Code
+	case errors.Is(err, jwt.ErrTokenUnverifiable), errors.Is(err, jwt.ErrTokenInvalidClaims):
+		return token.ErrInvalidToken.Code
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "CANCELED"
+	}
+	return "INTERNAL"
+}
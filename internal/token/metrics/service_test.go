@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token/metrics"
+)
+
+func TestMetrics_GivenTokenLifecycle_WhenCompleted_ThenCountsIssuedValidatedAndRevoked(t *testing.T) {
+	// Arrange
+	registry := prometheus.NewRegistry()
+	service, err := metrics.NewService(testkit.NewTokenService(t), registry)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	authToken, _, err := service.GenerateAuthToken(ctx, "user-123", "test@example.com")
+	require.NoError(t, err)
+	_, err = service.GenerateAPIToken(ctx, "user-123", []string{"read"})
+	require.NoError(t, err)
+	_, _ = service.ValidateToken(ctx, authToken)
+	_, _ = service.ValidateToken(ctx, "not-a-token")
+	require.NoError(t, service.RevokeToken(ctx, authToken))
+	_, _ = service.ValidateToken(ctx, authToken)
+	require.NoError(t, service.RevokeAllTokensForUser(ctx, "user-123"))
+
+	// Assert
+	expected := `
+# HELP token_service_issued_total Total token issuances by token type and outcome.
+# TYPE token_service_issued_total counter
+token_service_issued_total{outcome="success",type="api"} 1
+token_service_issued_total{outcome="success",type="auth"} 1
+# HELP token_service_revocations_total Total revocations of single tokens and of every token of a user, by outcome.
+# TYPE token_service_revocations_total counter
+token_service_revocations_total{outcome="success",scope="token"} 1
+token_service_revocations_total{outcome="success",scope="user"} 1
+# HELP token_service_validation_failures_total Total failed token validations by token type and reason.
+# TYPE token_service_validation_failures_total counter
+token_service_validation_failures_total{reason="MALFORMED_TOKEN",type="auth"} 1
+token_service_validation_failures_total{reason="TOKEN_REVOKED",type="auth"} 1
+# HELP token_service_validations_total Total token validations by token type and outcome.
+# TYPE token_service_validations_total counter
+token_service_validations_total{outcome="error",type="auth"} 2
+token_service_validations_total{outcome="success",type="auth"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"token_service_issued_total", "token_service_revocations_total",
+		"token_service_validation_failures_total", "token_service_validations_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "token_service_validation_duration_seconds"))
+}
+
+func TestMetrics_GivenRegisteredCollectors_WhenRegisteringAgain_ThenReturnsError(t *testing.T) {
+	// Arrange
+	registry := prometheus.NewRegistry()
+	_, err := metrics.NewService(testkit.NewTokenService(t), registry)
+	require.NoError(t, err)
+
+	// Act
+	_, err = metrics.NewService(testkit.NewTokenService(t), registry)
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,574 @@
+package opaque
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/revocation"
+	revocationMemory "github.com/gentra/decorator-arch-go/internal/revocation/memory"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+)
+
+// handleBytes is the entropy of a token handle; 256 bits cannot be guessed
+const handleBytes = 32
+
+// apiTokenTTLFactor is how many access token lifetimes an API token lasts
+const apiTokenTTLFactor = 24
+
+// service implements token.Service with opaque tokens: random handles whose
+// claims are kept in a token store. Unlike JWTs they reveal nothing to the
+// holder and are revoked everywhere at once, at the cost of a store lookup
+// on every validation.
+type service struct {
+	config      token.TokenConfig
+	store       tokenstore.Service
+	revocations revocation.Service // Spends one-time and exchanged refresh tokens
+}
+
+// NewService creates an opaque token service keeping claims in store
+func NewService(config token.TokenConfig, store tokenstore.Service) (token.Service, error) {
+	return NewServiceWithRevocations(config, store, nil)
+}
+
+// NewServiceWithRevocations creates an opaque token service keeping claims
+// in store and spending one-time tokens and exchanged refresh tokens in
+// revocations, which instances share so a token is consumed once across all
+// of them. Without one, they are spent in process.
+func NewServiceWithRevocations(config token.TokenConfig, store tokenstore.Service, revocations revocation.Service) (token.Service, error) {
+	if config.AccessTTL <= 0 {
+		return nil, fmt.Errorf("invalid token configuration")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("opaque tokens require a token store")
+	}
+	if revocations == nil {
+		revocations = revocationMemory.NewService()
+	}
+	return &service{config: config, store: store, revocations: revocations}, nil
+}
+
+// GenerateAuthToken generates an authentication token
+func (s *service) GenerateAuthToken(ctx context.Context, userID string, email string) (string, time.Time, error) {
+	record := s.newRecord(ctx, userID, "auth", s.config.AccessTTL)
+	record.Email = email
+	record.Family = familyFromContext(ctx)
+	s.addAuthentication(ctx, &record)
+
+	handle, err := s.issue(ctx, &record)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to issue token: %w", err)
+	}
+	return handle, record.ExpiresAt, nil
+}
+
+// GenerateAuthTokenWithClaims issues an access token whose record also
+// holds the non-reserved claims of extra
+func (s *service) GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error) {
+	return s.GenerateAuthToken(token.WithExtraClaims(ctx, extra), userID, email)
+}
+
+// GenerateRefreshToken generates the first refresh token of a new family
+func (s *service) GenerateRefreshToken(ctx context.Context, userID string) (string, error) {
+	record := s.newRecord(ctx, userID, "refresh", s.config.RefreshTTL)
+	record.Family = familyFromContext(ctx)
+	if record.Family == "" {
+		record.Family = uuid.NewString()
+	}
+	s.addAuthentication(ctx, &record)
+
+	handle, err := s.issue(ctx, &record)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+	return handle, nil
+}
+
+// GenerateAPIToken generates an API token with scopes
+func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*token.APIToken, error) {
+	record := s.newRecord(ctx, userID, "api", s.config.AccessTTL*apiTokenTTLFactor) // API tokens last longer
+	record.Scopes = scopes
+
+	handle, err := s.issue(ctx, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue API token: %w", err)
+	}
+
+	return &token.APIToken{
+		ID:        record.ID,
+		Token:     handle,
+		UserID:    userID,
+		Scopes:    scopes,
+		CreatedAt: record.IssuedAt,
+		ExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// GeneratePasswordResetToken generates a password reset token
+func (s *service) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	record := s.newRecord(ctx, userID, "reset", s.config.ResetTTL)
+	return s.issue(ctx, &record)
+}
+
+// GenerateEmailVerificationToken generates an email verification token
+func (s *service) GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
+	record := s.newRecord(ctx, userID, "verification", s.config.VerificationTTL)
+	return s.issue(ctx, &record)
+}
+
+// GenerateImpersonationToken generates a short-lived token for actorID to act as userID
+func (s *service) GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*token.ImpersonationToken, error) {
+	ttl := s.config.ImpersonationTTL
+	if ttl <= 0 {
+		ttl = token.DefaultImpersonationTTL
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	record := s.newRecord(ctx, userID, token.TokenTypeImpersonation, ttl)
+	record.ActorID = actorID
+	record.Scopes = scopes
+
+	handle, err := s.issue(ctx, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue impersonation token: %w", err)
+	}
+
+	return &token.ImpersonationToken{
+		Token:     handle,
+		UserID:    userID,
+		ActorID:   actorID,
+		Scopes:    scopes,
+		ExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// GenerateOneTimeToken generates a token for subject that ConsumeOneTimeToken
+// accepts once for purpose
+func (s *service) GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	if purpose == "" || subject == "" || ttl <= 0 {
+		return "", fmt.Errorf("one-time token requires a purpose, a subject and a positive TTL")
+	}
+
+	record := s.newRecord(ctx, subject, token.TokenTypeOneTime, ttl)
+	record.Purpose = purpose
+	record.Custom = nil
+
+	handle, err := s.issue(ctx, &record)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue one-time token: %w", err)
+	}
+	return handle, nil
+}
+
+// ConsumeOneTimeToken validates a one-time token issued for purpose and
+// spends it in the revocation store, so that of concurrent consumers exactly
+// one succeeds. Spent tokens report ErrTokenAlreadyUsed.
+func (s *service) ConsumeOneTimeToken(ctx context.Context, tokenString, purpose string) (*token.OneTimeClaims, error) {
+	record, err := s.lookupType(ctx, tokenString, token.TokenTypeOneTime)
+	if err != nil {
+		return nil, err
+	}
+	if record.Purpose != purpose {
+		return nil, token.ErrInvalidToken
+	}
+
+	consumed, err := s.revocations.ConsumeToken(ctx, record.ID, record.ExpiresAt.Add(s.config.ClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	if !consumed {
+		return nil, token.ErrTokenAlreadyUsed
+	}
+
+	return &token.OneTimeClaims{
+		Purpose:   record.Purpose,
+		Subject:   record.UserID,
+		IssuedAt:  record.IssuedAt,
+		ExpiresAt: record.ExpiresAt,
+		JTI:       record.ID,
+	}, nil
+}
+
+// ValidateToken looks the token up and returns its claims. One-time tokens
+// are only accepted by ConsumeOneTimeToken, which spends them.
+func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	record, err := s.lookup(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if record.TokenType == token.TokenTypeOneTime {
+		return nil, token.ErrInvalidToken
+	}
+	return s.claims(record), nil
+}
+
+// ValidateAPIToken validates an API token
+func (s *service) ValidateAPIToken(ctx context.Context, tokenString string) (*token.APITokenClaims, error) {
+	record, err := s.lookupType(ctx, tokenString, "api")
+	if err != nil {
+		return nil, err
+	}
+
+	return &token.APITokenClaims{
+		TokenClaims: *s.claims(record),
+		Scopes:      scopesOf(record),
+	}, nil
+}
+
+// ValidatePasswordResetToken validates a password reset token
+func (s *service) ValidatePasswordResetToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	record, err := s.lookupType(ctx, tokenString, "reset")
+	if err != nil {
+		return nil, err
+	}
+	return s.claims(record), nil
+}
+
+// ValidateEmailVerificationToken validates an email verification token
+func (s *service) ValidateEmailVerificationToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	record, err := s.lookupType(ctx, tokenString, "verification")
+	if err != nil {
+		return nil, err
+	}
+	return s.claims(record), nil
+}
+
+// ValidateImpersonationToken validates an impersonation token and returns
+// its actor and scopes
+func (s *service) ValidateImpersonationToken(ctx context.Context, tokenString string) (*token.ImpersonationClaims, error) {
+	record, err := s.lookupType(ctx, tokenString, token.TokenTypeImpersonation)
+	if err != nil {
+		return nil, err
+	}
+	if record.ActorID == "" {
+		return nil, token.ErrMalformedToken
+	}
+
+	return &token.ImpersonationClaims{
+		TokenClaims: *s.claims(record),
+		ActorID:     record.ActorID,
+		Scopes:      scopesOf(record),
+	}, nil
+}
+
+// RefreshToken exchanges a refresh token for an access token and a new
+// refresh token of the same family, revoking the old one. Exchanges are
+// spent in the revocation store, so a revoked refresh token presented again
+// is told apart from one revoked at sign-out, and its family is revoked.
+// Refresh tokens stored before families existed start a family of their own.
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*token.TokenPair, error) {
+	record, err := s.find(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+	if record.TokenType != "refresh" {
+		return nil, token.ErrInvalidToken
+	}
+	family := record.Family
+	if family == "" {
+		family = record.ID
+	}
+
+	if record.IsRevoked() {
+		exchanged, err := s.revocations.IsTokenRevoked(ctx, exchangedKey(record.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check refresh token: %w", err)
+		}
+		if exchanged {
+			return nil, s.revokeFamily(ctx, record.UserID, family)
+		}
+	}
+	if err := s.check(record); err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+
+	exchanged, err := s.revocations.ConsumeToken(ctx, exchangedKey(record.ID), record.ExpiresAt.Add(s.config.ClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange refresh token: %w", err)
+	}
+	if !exchanged {
+		return nil, s.revokeFamily(ctx, record.UserID, family)
+	}
+	if err := s.store.Revoke(ctx, record.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	// Refreshing is not signing in again, so the sign-in, custom claims and
+	// audience of the refresh token carry over
+	ctx = withFamily(ctx, family)
+	if !record.AuthTime.IsZero() {
+		ctx = token.WithAuthentication(ctx, record.AuthTime, record.AMR...)
+	}
+	if len(record.Custom) > 0 {
+		ctx = token.WithExtraClaims(ctx, record.Custom)
+	}
+	if record.Audience != "" {
+		ctx = token.WithAudience(ctx, record.Audience)
+	}
+	accessToken, expiresAt, err := s.GenerateAuthToken(ctx, record.UserID, record.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	rotated, err := s.GenerateRefreshToken(ctx, record.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &token.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rotated,
+		TokenType:    "bearer",
+		ExpiresIn:    int64(s.config.AccessTTL.Seconds()),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// revokeFamily revokes the user's tokens of the refresh token family after
+// one of its refresh tokens was reused, returning the error reporting it
+func (s *service) revokeFamily(ctx context.Context, userID, family string) error {
+	records, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	for _, record := range records {
+		if (record.Family == family || record.ID == family) && !record.IsRevoked() {
+			if err := s.store.Revoke(ctx, record.ID); err != nil {
+				return fmt.Errorf("failed to revoke token family: %w", err)
+			}
+		}
+	}
+	return token.RefreshTokenReuseError{UserID: userID, FamilyID: family}
+}
+
+// RevokeToken revokes a token in the store, so it stops working everywhere
+func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
+	record, err := s.find(ctx, tokenString)
+	if err != nil {
+		return fmt.Errorf("failed to find token for revocation: %w", err)
+	}
+	return s.store.Revoke(ctx, record.ID)
+}
+
+// RevokeAllTokensForUser revokes every token issued to the user so far
+func (s *service) RevokeAllTokensForUser(ctx context.Context, userID string) error {
+	return s.store.RevokeUser(ctx, userID)
+}
+
+// GetTokenInfo returns information about a token, including whether it was
+// revoked
+func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.TokenInfo, error) {
+	record, err := s.find(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	info := s.info(record)
+	return &info, nil
+}
+
+// ListActiveTokens lists the user's tokens that are neither expired nor
+// revoked; one-time tokens are spent rather than listed
+func (s *service) ListActiveTokens(ctx context.Context, userID string) ([]token.TokenInfo, error) {
+	records, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	now := time.Now()
+	tokens := []token.TokenInfo{}
+	for i := range records {
+		if records[i].TokenType == token.TokenTypeOneTime {
+			continue
+		}
+		if !records[i].IsRevoked() && !records[i].IsExpired(now) {
+			tokens = append(tokens, s.info(&records[i]))
+		}
+	}
+	return tokens, nil
+}
+
+// PublicKeys returns an empty set: opaque tokens are only checked by this service
+func (s *service) PublicKeys(ctx context.Context) (*token.JWKSet, error) {
+	return &token.JWKSet{Keys: []token.JWK{}}, nil
+}
+
+// Helper methods
+
+// familyContextKey carries the refresh token family tokens are issued to
+type familyContextKey struct{}
+
+// withFamily issues the tokens generated with the context to the family
+func withFamily(ctx context.Context, family string) context.Context {
+	return context.WithValue(ctx, familyContextKey{}, family)
+}
+
+// familyFromContext returns the refresh token family of the context, if any
+func familyFromContext(ctx context.Context) string {
+	family, _ := ctx.Value(familyContextKey{}).(string)
+	return family
+}
+
+// exchangedKey is the revocation store key a refresh token is spent under
+// once exchanged
+func exchangedKey(id string) string {
+	return "refresh-exchanged:" + id
+}
+
+// newRecord starts the record of a token issued now, carrying the extra
+// claims and device of the context
+func (s *service) newRecord(ctx context.Context, userID, tokenType string, ttl time.Duration) tokenstore.Record {
+	now := time.Now()
+	record := tokenstore.Record{
+		UserID:    userID,
+		TokenType: tokenType,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+	record.UserAgent, record.IPAddress = token.ExtractDevice(ctx)
+	record.Audience = token.ExtractAudience(ctx)
+	for name, value := range token.ExtractExtraClaims(ctx) {
+		if token.IsReservedClaim(name) {
+			continue
+		}
+		if record.Custom == nil {
+			record.Custom = make(map[string]interface{})
+		}
+		record.Custom[name] = value
+	}
+	return record
+}
+
+// addAuthentication records the sign-in stored in the context, if any
+func (s *service) addAuthentication(ctx context.Context, record *tokenstore.Record) {
+	authTime, methods, ok := token.ExtractAuthentication(ctx)
+	if !ok || authTime.IsZero() {
+		return
+	}
+	record.AuthTime = authTime
+	record.AMR = methods
+}
+
+// issue generates a handle and stores the record under its hash, setting
+// the record's ID
+func (s *service) issue(ctx context.Context, record *tokenstore.Record) (string, error) {
+	raw := make([]byte, handleBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	handle := base64.RawURLEncoding.EncodeToString(raw)
+
+	record.ID = tokenstore.ID(handle)
+	if err := s.store.Save(ctx, *record); err != nil {
+		return "", err
+	}
+	return handle, nil
+}
+
+// find returns the stored record of a token, revoked or not
+func (s *service) find(ctx context.Context, tokenString string) (*tokenstore.Record, error) {
+	if tokenString == "" {
+		return nil, token.ErrMalformedToken
+	}
+	record, err := s.store.Get(ctx, tokenstore.ID(tokenString))
+	if errors.Is(err, tokenstore.ErrNotFound) {
+		return nil, token.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up token: %w", err)
+	}
+	return record, nil
+}
+
+// lookup returns the record of a token that is still valid
+func (s *service) lookup(ctx context.Context, tokenString string) (*tokenstore.Record, error) {
+	record, err := s.find(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// check reports why the record of a token is no longer valid, if it is not
+func (s *service) check(record *tokenstore.Record) error {
+	if record.IsRevoked() {
+		return token.ErrTokenRevoked
+	}
+	if record.IsExpired(time.Now().Add(-s.config.ClockSkew)) {
+		return token.ErrTokenExpired
+	}
+	if s.config.RequireAudience && s.audience(record) != s.config.Audience {
+		return token.ErrInvalidAudience
+	}
+	return nil
+}
+
+// lookupType returns the record of a valid token of the type
+func (s *service) lookupType(ctx context.Context, tokenString, tokenType string) (*tokenstore.Record, error) {
+	record, err := s.lookup(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if record.TokenType != tokenType {
+		return nil, token.ErrInvalidToken
+	}
+	return record, nil
+}
+
+// claims returns the token claims of a record
+func (s *service) claims(record *tokenstore.Record) *token.TokenClaims {
+	return &token.TokenClaims{
+		UserID:    record.UserID,
+		Email:     record.Email,
+		TokenType: record.TokenType,
+		IssuedAt:  record.IssuedAt,
+		ExpiresAt: record.ExpiresAt,
+		Issuer:    s.config.Issuer,
+		Audience:  s.audience(record),
+		JTI:       record.ID,
+		AuthTime:  record.AuthTime,
+		AMR:       record.AMR,
+		FamilyID:  record.Family,
+		Custom:    record.Custom,
+	}
+}
+
+// audience returns the audience a record was issued to
+func (s *service) audience(record *tokenstore.Record) string {
+	if record.Audience != "" {
+		return record.Audience
+	}
+	return s.config.Audience
+}
+
+// info returns the introspection view of a record
+func (s *service) info(record *tokenstore.Record) token.TokenInfo {
+	return token.TokenInfo{
+		ID:        record.ID,
+		UserID:    record.UserID,
+		TokenType: record.TokenType,
+		CreatedAt: record.IssuedAt,
+		ExpiresAt: record.ExpiresAt,
+		IsRevoked: record.IsRevoked(),
+		Scopes:    record.Scopes,
+		UserAgent: record.UserAgent,
+		IPAddress: record.IPAddress,
+	}
+}
+
+// scopesOf returns the scopes of a record, never nil
+func scopesOf(record *tokenstore.Record) []string {
+	if record.Scopes == nil {
+		return []string{}
+	}
+	return record.Scopes
+}
@@ -0,0 +1,302 @@
+package opaque_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/token/opaque"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
+)
+
+func newService(t *testing.T) (token.Service, tokenstore.Service) {
+	t.Helper()
+	store := memory.NewService()
+	service, err := opaque.NewService(token.DefaultTokenConfig(), store)
+	require.NoError(t, err)
+	return service, store
+}
+
+func TestNewService_GivenInvalidConfig_WhenCreating_ThenReturnsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		config token.TokenConfig
+		store  tokenstore.Service
+	}{
+		{name: "Given no access TTL, When creating, Then returns error", config: token.TokenConfig{}, store: memory.NewService()},
+		{name: "Given no store, When creating, Then returns error", config: token.DefaultTokenConfig()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := opaque.NewService(tt.config, tt.store)
+
+			assert.Error(t, err)
+			assert.Nil(t, service)
+		})
+	}
+}
+
+func TestGenerateAuthToken_GivenUser_WhenValidating_ThenReturnsStoredClaims(t *testing.T) {
+	// Arrange
+	service, store := newService(t)
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	ctx := token.WithAuthentication(context.Background(), authTime, token.AMRPassword)
+	ctx = token.WithExtraClaims(ctx, map[string]interface{}{"tenant": "acme"})
+
+	// Act
+	tokenString, expiresAt, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(context.Background(), tokenString)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.Equal(t, "auth", claims.TokenType)
+	assert.Equal(t, expiresAt, claims.ExpiresAt)
+	assert.Equal(t, authTime, claims.AuthTime)
+	assert.Equal(t, []string{token.AMRPassword}, claims.AMR)
+	assert.Equal(t, "acme", claims.Custom["tenant"])
+	assert.Equal(t, tokenstore.ID(tokenString), claims.JTI)
+
+	record, err := store.Get(context.Background(), claims.JTI)
+	require.NoError(t, err)
+	assert.NotEqual(t, tokenString, record.ID, "the store must not hold the token itself")
+}
+
+func TestGenerateAuthTokenWithClaims_GivenAudienceOverride_WhenValidating_ThenEnforcesRequiredAudience(t *testing.T) {
+	// Arrange
+	config := token.DefaultTokenConfig()
+	config.RequireAudience = true
+	service, err := opaque.NewService(config, memory.NewService())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	own, _, err := service.GenerateAuthTokenWithClaims(ctx, "user-123", "test@example.com", map[string]interface{}{"tenant_id": "tenant-1"})
+	require.NoError(t, err)
+	foreign, _, err := service.GenerateAuthToken(token.WithAudience(ctx, "billing"), "user-123", "test@example.com")
+	require.NoError(t, err)
+	claims, ownErr := service.ValidateToken(ctx, own)
+	_, foreignErr := service.ValidateToken(ctx, foreign)
+
+	// Assert
+	require.NoError(t, ownErr)
+	assert.Equal(t, "tenant-1", claims.Custom["tenant_id"])
+	assert.Equal(t, token.ErrInvalidAudience, foreignErr)
+}
+
+func TestValidateToken_GivenUnknownToken_WhenValidating_ThenReturnsError(t *testing.T) {
+	service, _ := newService(t)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "Given an empty token, When validating, Then returns malformed", token: "", err: token.ErrMalformedToken},
+		{name: "Given an unknown token, When validating, Then returns invalid", token: "not-a-token", err: token.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ValidateToken(context.Background(), tt.token)
+
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidateAPIToken_GivenTokensOfEachType_WhenValidating_ThenAcceptsOnlyMatchingType(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	apiToken, err := service.GenerateAPIToken(ctx, "user123", []string{"read", "write"})
+	require.NoError(t, err)
+	resetToken, err := service.GeneratePasswordResetToken(ctx, "user123")
+	require.NoError(t, err)
+
+	// Act
+	apiClaims, apiErr := service.ValidateAPIToken(ctx, apiToken.Token)
+	_, resetAsAPIErr := service.ValidateAPIToken(ctx, resetToken)
+	_, resetErr := service.ValidatePasswordResetToken(ctx, resetToken)
+	_, apiAsVerificationErr := service.ValidateEmailVerificationToken(ctx, apiToken.Token)
+
+	// Assert
+	require.NoError(t, apiErr)
+	assert.Equal(t, []string{"read", "write"}, apiClaims.Scopes)
+	assert.Equal(t, apiToken.ID, apiClaims.JTI)
+	assert.ErrorIs(t, resetAsAPIErr, token.ErrInvalidToken)
+	assert.NoError(t, resetErr)
+	assert.ErrorIs(t, apiAsVerificationErr, token.ErrInvalidToken)
+}
+
+func TestValidateImpersonationToken_GivenImpersonationToken_WhenValidating_ThenReturnsActor(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+	issued, err := service.GenerateImpersonationToken(ctx, "admin1", "user123", nil)
+	require.NoError(t, err)
+
+	claims, err := service.ValidateImpersonationToken(ctx, issued.Token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "admin1", claims.ActorID)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.Empty(t, claims.Scopes)
+}
+
+func TestRefreshToken_GivenRefreshToken_WhenRefreshing_ThenIssuesNewAccessToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	refreshToken, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	accessToken, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	pair, err := service.RefreshToken(ctx, refreshToken)
+	_, accessErr := service.RefreshToken(ctx, accessToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, refreshToken, pair.RefreshToken)
+	assert.NotEqual(t, accessToken, pair.AccessToken)
+	_, err = service.ValidateToken(ctx, pair.AccessToken)
+	assert.NoError(t, err)
+	assert.Error(t, accessErr)
+}
+
+func TestRefreshToken_GivenExchangedRefreshToken_WhenReused_ThenRevokesItsFamily(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	stolen, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	otherSession, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	rotated, err := service.RefreshToken(ctx, stolen)
+	require.NoError(t, err)
+
+	// Act
+	_, reuseErr := service.RefreshToken(ctx, stolen)
+
+	// Assert
+	assert.ErrorIs(t, reuseErr, token.ErrRefreshTokenReused)
+	_, err = service.ValidateToken(ctx, rotated.AccessToken)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.ValidateToken(ctx, rotated.RefreshToken)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.RefreshToken(ctx, otherSession)
+	assert.NoError(t, err, "other families are untouched")
+}
+
+func TestRevokeToken_GivenIssuedToken_WhenRevoked_ThenValidationFailsAndInfoReportsIt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	tokenString, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+
+	// Act
+	err = service.RevokeToken(ctx, tokenString)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, tokenString)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	info, err := service.GetTokenInfo(ctx, tokenString)
+	require.NoError(t, err)
+	assert.True(t, info.IsRevoked)
+	assert.False(t, info.IsActive())
+}
+
+func TestRevokeAllTokensForUser_GivenSeveralUsers_WhenRevoking_ThenOnlyRevokesThatUser(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	first, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	second, err := service.GenerateRefreshToken(ctx, "user123")
+	require.NoError(t, err)
+	other, _, err := service.GenerateAuthToken(ctx, "user456", "other@example.com")
+	require.NoError(t, err)
+
+	// Act
+	err = service.RevokeAllTokensForUser(ctx, "user123")
+
+	// Assert
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, first)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.ValidateToken(ctx, second)
+	assert.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = service.ValidateToken(ctx, other)
+	assert.NoError(t, err)
+
+	// Tokens issued afterwards work again
+	fresh, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(ctx, fresh)
+	assert.NoError(t, err)
+}
+
+func TestListActiveTokens_GivenRevokedToken_WhenListing_ThenReturnsOnlyActiveOnes(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newService(t)
+	kept, err := service.GenerateAPIToken(ctx, "user123", []string{"read"})
+	require.NoError(t, err)
+	revoked, _, err := service.GenerateAuthToken(ctx, "user123", "user@example.com")
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeToken(ctx, revoked))
+
+	// Act
+	tokens, err := service.ListActiveTokens(ctx, "user123")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, kept.ID, tokens[0].ID)
+	assert.Equal(t, "api", tokens[0].TokenType)
+	assert.Equal(t, []string{"read"}, tokens[0].Scopes)
+}
+
+func TestPublicKeys_GivenOpaqueTokens_WhenListing_ThenPublishesNothing(t *testing.T) {
+	service, _ := newService(t)
+
+	keys, err := service.PublicKeys(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, keys.Keys)
+}
+
+func TestConsumeOneTimeToken_GivenToken_WhenConsumedTwice_ThenSecondAttemptFails(t *testing.T) {
+	// Arrange
+	service, _ := newService(t)
+	ctx := context.Background()
+	oneTimeToken, err := service.GenerateOneTimeToken(ctx, "magic_link", "user123", time.Minute)
+	require.NoError(t, err)
+
+	// Act
+	_, wrongPurposeErr := service.ConsumeOneTimeToken(ctx, oneTimeToken, "email_change")
+	claims, err := service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+	_, replayErr := service.ConsumeOneTimeToken(ctx, oneTimeToken, "magic_link")
+
+	// Assert
+	assert.Equal(t, token.ErrInvalidToken, wrongPurposeErr)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.Subject)
+	assert.Equal(t, token.ErrTokenAlreadyUsed, replayErr)
+	_, err = service.ValidateToken(ctx, oneTimeToken)
+	assert.Equal(t, token.ErrInvalidToken, err)
+	active, err := service.ListActiveTokens(ctx, "user123")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
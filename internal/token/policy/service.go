@@ -0,0 +1,280 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+)
+
+// service implements token.Service with issuance policy hooks
+type service struct {
+	next     token.Service
+	policies []tokenpolicy.Service
+}
+
+// NewService creates a new token service that runs the given policies around token issuance
+func NewService(next token.Service, policies ...tokenpolicy.Service) token.Service {
+	return &service{
+		next:     next,
+		policies: policies,
+	}
+}
+
+// GenerateAuthToken generates an auth token if all policies allow it
+func (s *service) GenerateAuthToken(ctx context.Context, userID string, email string) (string, time.Time, error) {
+	req := tokenpolicy.IssueRequest{UserID: userID, Email: email, TokenType: tokenpolicy.TokenTypeAuth}
+
+	ctx, err := s.beforeIssue(ctx, &req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenString, expiresAt, err := s.next.GenerateAuthToken(ctx, userID, email)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if err := s.afterIssue(ctx, req, tokenpolicy.IssuedToken{Token: tokenString, ExpiresAt: expiresAt}); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// GenerateAuthTokenWithClaims generates an auth token carrying the extra
+// claims if all policies allow it; policies see the claims in the context
+func (s *service) GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error) {
+	return s.GenerateAuthToken(token.WithExtraClaims(ctx, extra), userID, email)
+}
+
+// GenerateRefreshToken generates a refresh token if all policies allow it
+func (s *service) GenerateRefreshToken(ctx context.Context, userID string) (string, error) {
+	return s.issueString(ctx, tokenpolicy.TokenTypeRefresh, userID, s.next.GenerateRefreshToken)
+}
+
+// GenerateAPIToken generates an API token if all policies allow it
+func (s *service) GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*token.APIToken, error) {
+	req := tokenpolicy.IssueRequest{UserID: userID, TokenType: tokenpolicy.TokenTypeAPI, Scopes: scopes}
+
+	ctx, err := s.beforeIssue(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken, err := s.next.GenerateAPIToken(ctx, userID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.afterIssue(ctx, req, tokenpolicy.IssuedToken{Token: apiToken.Token, ExpiresAt: apiToken.ExpiresAt}); err != nil {
+		return nil, err
+	}
+
+	return apiToken, nil
+}
+
+// GeneratePasswordResetToken generates a password reset token if all policies allow it
+func (s *service) GeneratePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	return s.issueString(ctx, tokenpolicy.TokenTypeReset, userID, s.next.GeneratePasswordResetToken)
+}
+
+// GenerateEmailVerificationToken generates an email verification token if all policies allow it
+func (s *service) GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
+	return s.issueString(ctx, tokenpolicy.TokenTypeVerification, userID, s.next.GenerateEmailVerificationToken)
+}
+
+// GenerateImpersonationToken generates an impersonation token if all policies allow it
+func (s *service) GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*token.ImpersonationToken, error) {
+	req := tokenpolicy.IssueRequest{UserID: userID, ActorID: actorID, TokenType: tokenpolicy.TokenTypeImpersonation, Scopes: scopes}
+
+	ctx, err := s.beforeIssue(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonationToken, err := s.next.GenerateImpersonationToken(ctx, actorID, userID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.afterIssue(ctx, req, tokenpolicy.IssuedToken{Token: impersonationToken.Token, ExpiresAt: impersonationToken.ExpiresAt}); err != nil {
+		return nil, err
+	}
+
+	return impersonationToken, nil
+}
+
+// GenerateOneTimeToken generates a one-time token if all policies allow it.
+// One-time tokens carry no extra claims, so policy annotations are dropped.
+func (s *service) GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	return s.issueString(ctx, tokenpolicy.TokenTypeOneTime, subject, func(ctx context.Context, subject string) (string, error) {
+		return s.next.GenerateOneTimeToken(ctx, purpose, subject, ttl)
+	})
+}
+
+// ConsumeOneTimeToken passes through to the next service
+func (s *service) ConsumeOneTimeToken(ctx context.Context, tokenString, purpose string) (*token.OneTimeClaims, error) {
+	return s.next.ConsumeOneTimeToken(ctx, tokenString, purpose)
+}
+
+// ValidateToken passes through to the next service
+func (s *service) ValidateToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidateToken(ctx, tokenString)
+}
+
+// ValidateAPIToken passes through to the next service
+func (s *service) ValidateAPIToken(ctx context.Context, tokenString string) (*token.APITokenClaims, error) {
+	return s.next.ValidateAPIToken(ctx, tokenString)
+}
+
+// ValidatePasswordResetToken passes through to the next service
+func (s *service) ValidatePasswordResetToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidatePasswordResetToken(ctx, tokenString)
+}
+
+// ValidateEmailVerificationToken passes through to the next service
+func (s *service) ValidateEmailVerificationToken(ctx context.Context, tokenString string) (*token.TokenClaims, error) {
+	return s.next.ValidateEmailVerificationToken(ctx, tokenString)
+}
+
+// ValidateImpersonationToken passes through to the next service
+func (s *service) ValidateImpersonationToken(ctx context.Context, tokenString string) (*token.ImpersonationClaims, error) {
+	return s.next.ValidateImpersonationToken(ctx, tokenString)
+}
+
+// RefreshToken issues a new access token if all policies allow it. Refresh
+// tokens that do not validate, such as exchanged ones being reused, go to
+// the next service, which rejects them and revokes their family on reuse.
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*token.TokenPair, error) {
+	claims, err := s.next.ValidateToken(ctx, refreshToken)
+	if err != nil {
+		return s.next.RefreshToken(ctx, refreshToken)
+	}
+
+	if !claims.IsRefreshToken() {
+		return nil, token.ErrInvalidToken
+	}
+
+	req := tokenpolicy.IssueRequest{UserID: claims.UserID, Email: claims.Email, TokenType: tokenpolicy.TokenTypeAuth}
+
+	ctx, err = s.beforeIssue(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.next.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.afterIssue(ctx, req, tokenpolicy.IssuedToken{Token: pair.AccessToken, ExpiresAt: pair.ExpiresAt}); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// RevokeToken passes through to the next service
+func (s *service) RevokeToken(ctx context.Context, tokenString string) error {
+	return s.next.RevokeToken(ctx, tokenString)
+}
+
+// RevokeAllTokensForUser passes through to the next service
+func (s *service) RevokeAllTokensForUser(ctx context.Context, userID string) error {
+	return s.next.RevokeAllTokensForUser(ctx, userID)
+}
+
+// GetTokenInfo passes through to the next service
+func (s *service) GetTokenInfo(ctx context.Context, tokenString string) (*token.TokenInfo, error) {
+	return s.next.GetTokenInfo(ctx, tokenString)
+}
+
+// ListActiveTokens passes through to the next service
+func (s *service) ListActiveTokens(ctx context.Context, userID string) ([]token.TokenInfo, error) {
+	return s.next.ListActiveTokens(ctx, userID)
+}
+
+// PublicKeys passes through to the next service
+func (s *service) PublicKeys(ctx context.Context) (*token.JWKSet, error) {
+	return s.next.PublicKeys(ctx)
+}
+
+// Helper methods
+
+// issueString runs the policies around generators that only return the token string
+func (s *service) issueString(ctx context.Context, tokenType, userID string, generate func(context.Context, string) (string, error)) (string, error) {
+	req := tokenpolicy.IssueRequest{UserID: userID, TokenType: tokenType}
+
+	ctx, err := s.beforeIssue(ctx, &req)
+	if err != nil {
+		return "", err
+	}
+
+	tokenString, err := generate(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.afterIssue(ctx, req, tokenpolicy.IssuedToken{Token: tokenString}); err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// beforeIssue runs every pre-issue hook and returns a context carrying the annotated claims
+func (s *service) beforeIssue(ctx context.Context, req *tokenpolicy.IssueRequest) (context.Context, error) {
+	for _, p := range s.policies {
+		if err := p.BeforeIssue(ctx, req); err != nil {
+			return ctx, policyError(p, err)
+		}
+
+		for name := range req.Claims {
+			if token.IsReservedClaim(name) {
+				err := tokenpolicy.ErrReservedClaim
+				err.Message = fmt.Sprintf("%s: %s", err.Message, name)
+				err.Policy = p.Name()
+				return ctx, err
+			}
+		}
+	}
+
+	if len(req.Claims) == 0 {
+		return ctx, nil
+	}
+	return token.WithExtraClaims(ctx, req.Claims), nil
+}
+
+// afterIssue runs every post-issue hook and revokes the token if one of them fails
+func (s *service) afterIssue(ctx context.Context, req tokenpolicy.IssueRequest, issued tokenpolicy.IssuedToken) error {
+	for _, p := range s.policies {
+		if err := p.AfterIssue(ctx, req, issued); err != nil {
+			if revokeErr := s.next.RevokeToken(ctx, issued.Token); revokeErr != nil {
+				log.Printf("Failed to revoke token rejected by policy %s: %v", p.Name(), revokeErr)
+			}
+			return policyError(p, err)
+		}
+	}
+	return nil
+}
+
+// policyError maps hook failures to typed policy errors
+func policyError(p tokenpolicy.Service, err error) error {
+	var policyErr tokenpolicy.PolicyError
+	if errors.As(err, &policyErr) {
+		if policyErr.Policy == "" {
+			policyErr.Policy = p.Name()
+		}
+		return policyErr
+	}
+
+	denied := tokenpolicy.ErrIssuanceDenied
+	denied.Policy = p.Name()
+	denied.Message = fmt.Sprintf("%s: %v", denied.Message, err)
+	return denied
+}
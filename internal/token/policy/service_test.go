@@ -0,0 +1,152 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/token/jwt"
+	"github.com/gentra/decorator-arch-go/internal/token/policy"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy/hook"
+)
+
+func newJWTService(t *testing.T) token.Service {
+	t.Helper()
+	service, err := jwt.NewService(token.TokenConfig{
+		Secret:          []byte("test-secret-key-for-testing-only"),
+		AccessTTL:       15 * time.Minute,
+		RefreshTTL:      24 * time.Hour,
+		ResetTTL:        time.Hour,
+		VerificationTTL: 24 * time.Hour,
+		Issuer:          "test-issuer",
+		Audience:        "test-audience",
+		Algorithm:       "HS256",
+	})
+	require.NoError(t, err)
+	return service
+}
+
+func TestGenerateAuthToken_GivenPolicies_WhenIssuing_ThenAppliesVetoOrAnnotations(t *testing.T) {
+	suspended := hook.NewService("suspension", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		if req.UserID == "suspended-user" {
+			return tokenpolicy.ErrUserSuspended
+		}
+		return nil
+	}, nil)
+	tenant := hook.NewService("tenant", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		req.AddClaim("tenant_id", "tenant-1")
+		return nil
+	}, nil)
+	failing := hook.NewService("failing", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		return errors.New("seat service unavailable")
+	}, nil)
+	reserved := hook.NewService("reserved", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		req.AddClaim("user_id", "someone-else")
+		return nil
+	}, nil)
+
+	tests := []struct {
+		name         string
+		policies     []tokenpolicy.Service
+		userID       string
+		expectedCode string
+		expectedTag  string
+	}{
+		{
+			name:        "Given annotating policy, When issuing, Then token carries claim",
+			policies:    []tokenpolicy.Service{suspended, tenant},
+			userID:      "user-1",
+			expectedTag: "tenant-1",
+		},
+		{
+			name:         "Given suspended user, When issuing, Then typed error is returned",
+			policies:     []tokenpolicy.Service{suspended, tenant},
+			userID:       "suspended-user",
+			expectedCode: tokenpolicy.ErrUserSuspended.Code,
+		},
+		{
+			name:         "Given policy returning plain error, When issuing, Then it is mapped to issuance denied",
+			policies:     []tokenpolicy.Service{failing},
+			userID:       "user-1",
+			expectedCode: tokenpolicy.ErrIssuanceDenied.Code,
+		},
+		{
+			name:         "Given policy setting reserved claim, When issuing, Then issuance fails",
+			policies:     []tokenpolicy.Service{reserved},
+			userID:       "user-1",
+			expectedCode: tokenpolicy.ErrReservedClaim.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := policy.NewService(newJWTService(t), tt.policies...)
+
+			tokenString, _, err := service.GenerateAuthToken(ctx, tt.userID, "user@example.com")
+
+			if tt.expectedCode != "" {
+				var policyErr tokenpolicy.PolicyError
+				require.True(t, errors.As(err, &policyErr))
+				assert.Equal(t, tt.expectedCode, policyErr.Code)
+				assert.NotEmpty(t, policyErr.Policy)
+				assert.Empty(t, tokenString)
+				return
+			}
+
+			require.NoError(t, err)
+			claims, err := service.ValidateToken(ctx, tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, tt.userID, claims.UserID)
+			assert.Equal(t, tt.expectedTag, claims.Custom["tenant_id"])
+		})
+	}
+}
+
+func TestGenerateAPIToken_GivenFailingAfterHook_WhenIssuing_ThenTokenIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	var issued string
+	audit := hook.NewService("audit", nil, func(ctx context.Context, req tokenpolicy.IssueRequest, token tokenpolicy.IssuedToken) error {
+		issued = token.Token
+		assert.Equal(t, tokenpolicy.TokenTypeAPI, req.TokenType)
+		assert.Equal(t, []string{"read"}, req.Scopes)
+		return tokenpolicy.ErrSeatLimitExceeded
+	})
+	next := newJWTService(t)
+	service := policy.NewService(next, audit)
+
+	apiToken, err := service.GenerateAPIToken(ctx, "user-1", []string{"read"})
+
+	assert.Nil(t, apiToken)
+	assert.ErrorIs(t, err, error(tokenpolicy.PolicyError{Code: "SEAT_LIMIT_EXCEEDED", Message: tokenpolicy.ErrSeatLimitExceeded.Message, Policy: "audit"}))
+	require.NotEmpty(t, issued)
+	_, err = next.ValidateAPIToken(ctx, issued)
+	assert.Error(t, err)
+}
+
+func TestRefreshToken_GivenSuspendedUser_WhenRefreshing_ThenIssuanceIsDenied(t *testing.T) {
+	ctx := context.Background()
+	suspendedUsers := map[string]bool{}
+	suspension := hook.NewService("suspension", func(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+		if suspendedUsers[req.UserID] {
+			return tokenpolicy.ErrUserSuspended
+		}
+		return nil
+	}, nil)
+	service := policy.NewService(newJWTService(t), suspension)
+
+	refreshToken, err := service.GenerateRefreshToken(ctx, "user-1")
+	require.NoError(t, err)
+
+	suspendedUsers["user-1"] = true
+	pair, err := service.RefreshToken(ctx, refreshToken)
+
+	assert.Nil(t, pair)
+	assert.ErrorIs(t, err, error(tokenpolicy.PolicyError{Code: "USER_SUSPENDED", Message: tokenpolicy.ErrUserSuspended.Message, Policy: "suspension"}))
+}
@@ -2,6 +2,14 @@ package token
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
 	"time"
 )
 
@@ -9,18 +17,31 @@ import (
 type Service interface {
 	// Token generation
 	GenerateAuthToken(ctx context.Context, userID string, email string) (string, time.Time, error)
+	GenerateAuthTokenWithClaims(ctx context.Context, userID string, email string, extra map[string]interface{}) (string, time.Time, error)
 	GenerateRefreshToken(ctx context.Context, userID string) (string, error)
 	GenerateAPIToken(ctx context.Context, userID string, scopes []string) (*APIToken, error)
 	GeneratePasswordResetToken(ctx context.Context, userID string) (string, error)
 	GenerateEmailVerificationToken(ctx context.Context, userID string) (string, error)
+	GenerateImpersonationToken(ctx context.Context, actorID, userID string, scopes []string) (*ImpersonationToken, error)
+
+	// One-time tokens: short-lived tokens bound to a purpose, e.g. magic link
+	// sign-in, that can be consumed exactly once
+	GenerateOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error)
+	ConsumeOneTimeToken(ctx context.Context, token, purpose string) (*OneTimeClaims, error)
 
 	// Token validation
 	ValidateToken(ctx context.Context, token string) (*TokenClaims, error)
 	ValidateAPIToken(ctx context.Context, token string) (*APITokenClaims, error)
 	ValidatePasswordResetToken(ctx context.Context, token string) (*TokenClaims, error)
 	ValidateEmailVerificationToken(ctx context.Context, token string) (*TokenClaims, error)
+	ValidateImpersonationToken(ctx context.Context, token string) (*ImpersonationClaims, error)
 
 	// Token management
+
+	// RefreshToken exchanges a refresh token for an access token and a new
+	// refresh token of the same family, spending the old one. Exchanging a
+	// refresh token a second time means it was copied, so every token of its
+	// family is revoked and a RefreshTokenReuseError is returned.
 	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 	RevokeToken(ctx context.Context, token string) error
 	RevokeAllTokensForUser(ctx context.Context, userID string) error
@@ -28,6 +49,10 @@ type Service interface {
 	// Token introspection
 	GetTokenInfo(ctx context.Context, token string) (*TokenInfo, error)
 	ListActiveTokens(ctx context.Context, userID string) ([]TokenInfo, error)
+
+	// Key distribution: the public keys other services verify tokens with.
+	// HS256 services publish an empty set, since their secret is never shared.
+	PublicKeys(ctx context.Context) (*JWKSet, error)
 }
 
 // Domain types and data structures
@@ -42,6 +67,18 @@ type TokenClaims struct {
 	Issuer    string    `json:"issuer,omitempty"`
 	Audience  string    `json:"audience,omitempty"`
 	JTI       string    `json:"jti,omitempty"` // JWT ID
+
+	// AuthTime and AMR describe the sign-in behind access and refresh
+	// tokens: when it happened and how (RFC 8176 method values)
+	AuthTime time.Time `json:"auth_time,omitempty"`
+	AMR      []string  `json:"amr,omitempty"`
+
+	// FamilyID is the refresh token family of refresh tokens and of the
+	// access tokens issued by refreshing; a family starts at sign-in
+	FamilyID string `json:"family_id,omitempty"`
+
+	// Custom holds non-standard claims, e.g. annotations added by issuance policies
+	Custom map[string]interface{} `json:"custom,omitempty"`
 }
 
 // APIToken represents an API token with scopes
@@ -63,6 +100,69 @@ type APITokenClaims struct {
 	Name   string   `json:"name,omitempty"`
 }
 
+// TokenTypeImpersonation is the type of tokens an admin acts as another user with
+const TokenTypeImpersonation = "impersonation"
+
+// DefaultImpersonationTTL is how long impersonation tokens last when the
+// configuration sets no TTL
+const DefaultImpersonationTTL = 15 * time.Minute
+
+// DefaultClockSkew is the clock drift between instances the default
+// configuration tolerates when validating tokens
+const DefaultClockSkew = 30 * time.Second
+
+// IssuedAtMicrosClaim carries a JWT's issue time in Unix microseconds. The
+// standard iat claim has whole seconds, which cannot tell a token issued
+// right after a user's tokens were revoked from one issued right before.
+const IssuedAtMicrosClaim = "iat_us"
+
+// IssuedAt returns the issue time of JWT claims and the precision it was
+// recorded with: microseconds, or whole seconds for tokens issued before
+// IssuedAtMicrosClaim was added
+func IssuedAt(claims map[string]interface{}) (time.Time, time.Duration) {
+	if micros, ok := claims[IssuedAtMicrosClaim].(float64); ok {
+		return time.UnixMicro(int64(micros)), time.Microsecond
+	}
+	seconds, _ := claims["iat"].(float64)
+	return time.Unix(int64(seconds), 0), time.Second
+}
+
+// RevokedSince reports whether a token issued at issuedAt, recorded with
+// precision, was issued no later than revokedAt and so is revoked by it
+func RevokedSince(issuedAt time.Time, precision time.Duration, revokedAt time.Time) bool {
+	return !revokedAt.IsZero() && !issuedAt.After(revokedAt.Truncate(precision))
+}
+
+// ImpersonationToken is a short-lived access token issued to an admin to act
+// as another user. It carries the admin as the actor and is limited to the
+// scopes granted at issuance; it cannot be refreshed.
+type ImpersonationToken struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`  // The impersonated user
+	ActorID   string    `json:"actor_id"` // The admin acting as the user
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImpersonationClaims represents claims in an impersonation token
+type ImpersonationClaims struct {
+	TokenClaims
+	ActorID string   `json:"actor_id"`
+	Scopes  []string `json:"scopes"`
+}
+
+// TokenTypeOneTime is the type of tokens that can be consumed exactly once
+const TokenTypeOneTime = "one_time"
+
+// OneTimeClaims represents the claims of a consumed one-time token
+type OneTimeClaims struct {
+	Purpose   string    `json:"purpose"`
+	Subject   string    `json:"subject"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	JTI       string    `json:"jti"`
+}
+
 // TokenPair represents an access token and refresh token pair
 type TokenPair struct {
 	AccessToken  string    `json:"access_token"`
@@ -87,6 +187,55 @@ type TokenInfo struct {
 	IPAddress string     `json:"ip_address,omitempty"`
 }
 
+// Signing algorithms supported by the token service
+const (
+	AlgorithmHS256 = "HS256" // HMAC with SHA-256, signed and verified with Secret
+	AlgorithmRS256 = "RS256" // RSASSA-PKCS1-v1_5 with SHA-256
+	AlgorithmES256 = "ES256" // ECDSA with P-256 and SHA-256
+)
+
+// Key management algorithms of encrypted tokens, whose content is always
+// encrypted with AES-256-GCM
+const (
+	EncryptionDirect     = "dir"          // encrypted with the shared Key itself
+	EncryptionRSAOAEP256 = "RSA-OAEP-256" // a fresh key per token, wrapped with PrivateKey's public half
+)
+
+// EncryptionConfig makes the JWT service issue JWE (RFC 7516) tokens, whose
+// claims clients and intermediaries cannot read, instead of only signed ones.
+// Tokens are signed first and the whole JWS is encrypted, as a nested JWT.
+type EncryptionConfig struct {
+	Algorithm  string          `json:"algorithm"` // EncryptionDirect or EncryptionRSAOAEP256; empty disables encryption
+	Key        []byte          `json:"-"`         // 256-bit key for EncryptionDirect
+	PrivateKey *rsa.PrivateKey `json:"-"`         // key of at least 2048 bits for EncryptionRSAOAEP256
+	KeyID      string          `json:"key_id"`    // kid header of encrypted tokens, if any
+
+	// Validation accepts signed tokens as well as encrypted ones, so tokens
+	// issued before encryption was enabled keep working. DecryptOnly still
+	// issues signed tokens, for instances sharing tokens to all accept
+	// encrypted ones before any issues them. Required rejects signed tokens,
+	// once those issued before encryption have expired.
+	DecryptOnly bool `json:"decrypt_only"`
+	Required    bool `json:"required"`
+}
+
+// IsValid reports whether the configuration has the key its algorithm needs
+func (c EncryptionConfig) IsValid() bool {
+	if c.DecryptOnly && c.Required {
+		return false
+	}
+	switch c.Algorithm {
+	case "":
+		return !c.Required
+	case EncryptionDirect:
+		return len(c.Key) == 32
+	case EncryptionRSAOAEP256:
+		return c.PrivateKey != nil && c.PrivateKey.N.BitLen() >= 2048
+	default:
+		return false
+	}
+}
+
 // TokenConfig contains configuration for token service
 type TokenConfig struct {
 	// JWT configuration
@@ -96,10 +245,42 @@ type TokenConfig struct {
 	ResetTTL        time.Duration `json:"reset_ttl"`        // Password reset token TTL
 	VerificationTTL time.Duration `json:"verification_ttl"` // Email verification token TTL
 
+	// Impersonation token TTL; zero uses DefaultImpersonationTTL
+	ImpersonationTTL time.Duration `json:"impersonation_ttl"`
+
 	// Token settings
 	Issuer    string `json:"issuer"`    // Token issuer
 	Audience  string `json:"audience"`  // Token audience
-	Algorithm string `json:"algorithm"` // Signing algorithm (HS256, RS256, etc.)
+	Algorithm string `json:"algorithm"` // Signing algorithm (HS256, RS256 or ES256)
+
+	// SigningKey signs RS256 and ES256 tokens. Any crypto.Signer works, so
+	// a key held in a KMS or HSM can be used without exporting it.
+	// VerificationKey verifies them and defaults to SigningKey's public key;
+	// a service given only a VerificationKey can validate but not issue
+	// tokens. A Secret set alongside an asymmetric key keeps HS256 tokens
+	// valid, so tokens issued before switching algorithms still work.
+	SigningKey      crypto.Signer    `json:"-"`
+	VerificationKey crypto.PublicKey `json:"-"`
+
+	// KeyID is the kid header of RS256 and ES256 tokens and of the published
+	// key; empty uses the key's RFC 7638 thumbprint. RetiredKeys are keys
+	// replaced by the current one: they keep verifying the tokens they
+	// signed, and stay published, until the longest-lived of those expires.
+	KeyID       string       `json:"key_id"`
+	RetiredKeys []RetiredKey `json:"-"`
+
+	// Validation settings. RequireIssuer and RequireAudience reject tokens
+	// whose iss is not Issuer or whose aud does not include Audience, which
+	// must then be set. ClockSkew is how far clocks of the instances issuing
+	// and validating tokens may disagree: tokens are still accepted that long
+	// after exp, and already accepted that long before nbf and iat.
+	RequireIssuer   bool          `json:"require_issuer"`
+	RequireAudience bool          `json:"require_audience"`
+	ClockSkew       time.Duration `json:"clock_skew"`
+
+	// Encryption makes the JWT service issue encrypted tokens; the opaque
+	// provider ignores it, as its tokens carry no claims
+	Encryption EncryptionConfig `json:"encryption"`
 
 	// Security settings
 	EnableRefresh    bool `json:"enable_refresh"`    // Enable refresh tokens
@@ -107,6 +288,45 @@ type TokenConfig struct {
 	MaxActiveTokens  int  `json:"max_active_tokens"` // Max active tokens per user
 }
 
+// RetiredKey is a verification key replaced by a newer signing key
+type RetiredKey struct {
+	ID        string           // kid of the tokens it signed; empty uses its thumbprint
+	Key       crypto.PublicKey // RSA key for RS256 or P-256 key for ES256
+	RetiredAt time.Time        // when it stopped signing tokens
+}
+
+// JWK is a public key in RFC 7517 JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+
+	// RSA modulus and exponent
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Elliptic curve and point coordinates
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKSet is an RFC 7517 JWK Set, as served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Key returns the key with the kid, if the set has it
+func (s *JWKSet) Key(keyID string) (JWK, bool) {
+	for _, key := range s.Keys {
+		if key.KeyID == keyID {
+			return key, true
+		}
+	}
+	return JWK{}, false
+}
+
 // TokenError represents domain-specific token errors
 type TokenError struct {
 	Code    string `json:"code"`
@@ -120,15 +340,139 @@ func (e TokenError) Error() string {
 
 // Common token error codes
 var (
-	ErrInvalidToken      = TokenError{Code: "INVALID_TOKEN", Message: "Invalid or expired token"}
-	ErrTokenExpired      = TokenError{Code: "TOKEN_EXPIRED", Message: "Token has expired"}
-	ErrTokenRevoked      = TokenError{Code: "TOKEN_REVOKED", Message: "Token has been revoked"}
-	ErrInvalidSignature  = TokenError{Code: "INVALID_SIGNATURE", Message: "Invalid token signature"}
-	ErrMalformedToken    = TokenError{Code: "MALFORMED_TOKEN", Message: "Malformed token"}
-	ErrTokenNotFound     = TokenError{Code: "TOKEN_NOT_FOUND", Message: "Token not found"}
-	ErrInsufficientScope = TokenError{Code: "INSUFFICIENT_SCOPE", Message: "Insufficient token scope"}
+	ErrInvalidToken       = TokenError{Code: "INVALID_TOKEN", Message: "Invalid or expired token"}
+	ErrTokenExpired       = TokenError{Code: "TOKEN_EXPIRED", Message: "Token has expired"}
+	ErrTokenRevoked       = TokenError{Code: "TOKEN_REVOKED", Message: "Token has been revoked"}
+	ErrTokenNotYetValid   = TokenError{Code: "TOKEN_NOT_YET_VALID", Message: "Token is not valid yet"}
+	ErrInvalidSignature   = TokenError{Code: "INVALID_SIGNATURE", Message: "Invalid token signature"}
+	ErrMalformedToken     = TokenError{Code: "MALFORMED_TOKEN", Message: "Malformed token"}
+	ErrTokenNotFound      = TokenError{Code: "TOKEN_NOT_FOUND", Message: "Token not found"}
+	ErrInsufficientScope  = TokenError{Code: "INSUFFICIENT_SCOPE", Message: "Insufficient token scope"}
+	ErrInvalidIssuer      = TokenError{Code: "INVALID_ISSUER", Message: "Token was issued by an untrusted issuer"}
+	ErrInvalidAudience    = TokenError{Code: "INVALID_AUDIENCE", Message: "Token is not intended for this audience"}
+	ErrTokenAlreadyUsed   = TokenError{Code: "TOKEN_ALREADY_USED", Message: "Token has already been used"}
+	ErrRefreshTokenReused = TokenError{Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token was already used; the session was revoked"}
 )
 
+// RefreshTokenReuseError reports that a refresh token already exchanged was
+// presented again and its family revoked. It unwraps to ErrRefreshTokenReused.
+type RefreshTokenReuseError struct {
+	UserID   string
+	FamilyID string
+}
+
+func (e RefreshTokenReuseError) Error() string {
+	return ErrRefreshTokenReused.Message
+}
+
+// Unwrap returns ErrRefreshTokenReused
+func (e RefreshTokenReuseError) Unwrap() error {
+	return ErrRefreshTokenReused
+}
+
+// Authentication methods recorded in the amr claim
+const (
+	AMRPassword  = "pwd" // Password, as defined by RFC 8176
+	AMRFederated = "fed" // Sign-in at an external identity provider, e.g. SAML
+	AMROTP       = "otp" // One-time password, e.g. a magic link, as defined by RFC 8176
+)
+
+// Context keys for token issuance
+type contextKey string
+
+const (
+	ExtraClaimsContextKey    contextKey = "extra_claims"
+	AuthenticationContextKey contextKey = "authentication"
+	DeviceContextKey         contextKey = "device"
+	AudienceContextKey       contextKey = "audience"
+)
+
+// reservedClaims are managed by the token service and cannot be overridden by extra claims
+var reservedClaims = map[string]bool{
+	"user_id": true, "email": true, "token_type": true, "scopes": true, "act": true,
+	"iat": true, "exp": true, "nbf": true, "iss": true, "aud": true, "sub": true, "jti": true,
+	"auth_time": true, "amr": true, "purpose": true, "fam": true, IssuedAtMicrosClaim: true,
+}
+
+// IsReservedClaim reports whether a claim name is managed by the token service
+func IsReservedClaim(name string) bool {
+	return reservedClaims[name]
+}
+
+// WithExtraClaims adds claims to embed in tokens issued with the returned context.
+// Claims already present in the context are kept unless overridden.
+func WithExtraClaims(ctx context.Context, claims map[string]interface{}) context.Context {
+	merged := make(map[string]interface{})
+	for name, value := range ExtractExtraClaims(ctx) {
+		merged[name] = value
+	}
+	for name, value := range claims {
+		if !IsReservedClaim(name) {
+			merged[name] = value
+		}
+	}
+
+	return context.WithValue(ctx, ExtraClaimsContextKey, merged)
+}
+
+// ExtractExtraClaims returns the extra claims stored in the context
+func ExtractExtraClaims(ctx context.Context) map[string]interface{} {
+	if claims, ok := ctx.Value(ExtraClaimsContextKey).(map[string]interface{}); ok {
+		return claims
+	}
+	return nil
+}
+
+// authentication is the sign-in stored by WithAuthentication
+type authentication struct {
+	time    time.Time
+	methods []string
+}
+
+// WithAuthentication marks access and refresh tokens issued with the returned
+// context as following a sign-in at authTime with the given methods, which
+// they carry in their auth_time and amr claims
+func WithAuthentication(ctx context.Context, authTime time.Time, methods ...string) context.Context {
+	return context.WithValue(ctx, AuthenticationContextKey, authentication{time: authTime, methods: methods})
+}
+
+// ExtractAuthentication returns the sign-in stored in the context, if any
+func ExtractAuthentication(ctx context.Context) (time.Time, []string, bool) {
+	auth, ok := ctx.Value(AuthenticationContextKey).(authentication)
+	return auth.time, auth.methods, ok
+}
+
+// device is the client stored by WithDevice
+type device struct {
+	userAgent string
+	ipAddress string
+}
+
+// WithDevice records the client that tokens issued with the returned context
+// are handed to, so users can tell their sessions apart when listing them
+func WithDevice(ctx context.Context, userAgent, ipAddress string) context.Context {
+	return context.WithValue(ctx, DeviceContextKey, device{userAgent: userAgent, ipAddress: ipAddress})
+}
+
+// ExtractDevice returns the user agent and IP address stored in the context
+func ExtractDevice(ctx context.Context) (string, string) {
+	d, _ := ctx.Value(DeviceContextKey).(device)
+	return d.userAgent, d.ipAddress
+}
+
+// WithAudience issues the tokens of the returned context to audience instead
+// of the configured one, e.g. to the one service of a deployment they are for
+func WithAudience(ctx context.Context, audience string) context.Context {
+	return context.WithValue(ctx, AudienceContextKey, audience)
+}
+
+// ExtractAudience returns the audience stored in the context, or "" for the
+// configured one
+func ExtractAudience(ctx context.Context) string {
+	audience, _ := ctx.Value(AudienceContextKey).(string)
+	return audience
+}
+
 // Helper methods for TokenClaims
 func (c *TokenClaims) IsValid() bool {
 	return c.UserID != "" && !c.ExpiresAt.IsZero()
@@ -146,6 +490,11 @@ func (c *TokenClaims) IsRefreshToken() bool {
 	return c.TokenType == "refresh"
 }
 
+// IsImpersonationToken reports whether an admin holds the token to act as the user
+func (c *TokenClaims) IsImpersonationToken() bool {
+	return c.TokenType == TokenTypeImpersonation
+}
+
 func (c *TokenClaims) TimeUntilExpiry() time.Duration {
 	return time.Until(c.ExpiresAt)
 }
@@ -188,7 +537,98 @@ func (i *TokenInfo) IsExpired() bool {
 
 // Helper methods for TokenConfig
 func (c *TokenConfig) IsValid() bool {
-	return len(c.Secret) > 0 && c.AccessTTL > 0 && c.Algorithm != ""
+	if c.AccessTTL <= 0 || c.ClockSkew < 0 {
+		return false
+	}
+	if (c.RequireIssuer && c.Issuer == "") || (c.RequireAudience && c.Audience == "") {
+		return false
+	}
+	if !c.Encryption.IsValid() {
+		return false
+	}
+
+	switch c.Algorithm {
+	case AlgorithmHS256:
+		return len(c.Secret) > 0
+	case AlgorithmRS256:
+		_, ok := c.PublicKey().(*rsa.PublicKey)
+		return ok
+	case AlgorithmES256:
+		_, ok := c.PublicKey().(*ecdsa.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
+
+// PublicKey returns the key verifying asymmetric signatures: VerificationKey,
+// or the public half of SigningKey
+func (c *TokenConfig) PublicKey() crypto.PublicKey {
+	if c.VerificationKey != nil {
+		return c.VerificationKey
+	}
+	if c.SigningKey != nil {
+		return c.SigningKey.Public()
+	}
+	return nil
+}
+
+// NewJWK returns the signature verification JWK of an RSA or P-256 ECDSA
+// public key. An empty keyID is replaced by the key's RFC 7638 thumbprint.
+func NewJWK(key crypto.PublicKey, keyID string) (JWK, error) {
+	var jwk JWK
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{
+			KeyType:   "RSA",
+			Algorithm: AlgorithmRS256,
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return JWK{}, fmt.Errorf("unsupported elliptic curve %s", key.Curve.Params().Name)
+		}
+		point, err := key.ECDH()
+		if err != nil {
+			return JWK{}, fmt.Errorf("invalid ECDSA key: %w", err)
+		}
+		// Uncompressed point: 0x04 || X || Y
+		coordinates := point.Bytes()[1:]
+		size := len(coordinates) / 2
+		jwk = JWK{
+			KeyType:   "EC",
+			Algorithm: AlgorithmES256,
+			Curve:     "P-256",
+			X:         base64.RawURLEncoding.EncodeToString(coordinates[:size]),
+			Y:         base64.RawURLEncoding.EncodeToString(coordinates[size:]),
+		}
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	jwk.Use = "sig"
+	jwk.KeyID = keyID
+	if jwk.KeyID == "" {
+		jwk.KeyID = jwk.Thumbprint()
+	}
+	return jwk, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key, computed
+// over its required members in lexicographic order
+func (k JWK) Thumbprint() string {
+	var canonical string
+	switch k.KeyType {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Curve, k.X, k.Y)
+	default:
+		return ""
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
 // Default token configuration
@@ -198,11 +638,13 @@ func DefaultTokenConfig() TokenConfig {
 		RefreshTTL:       24 * time.Hour,
 		ResetTTL:         30 * time.Minute,
 		VerificationTTL:  24 * time.Hour,
+		ImpersonationTTL: DefaultImpersonationTTL,
 		Issuer:           "decorator-arch-go",
 		Audience:         "api",
-		Algorithm:        "HS256",
+		Algorithm:        AlgorithmHS256,
 		EnableRefresh:    true,
 		EnableRevocation: true,
 		MaxActiveTokens:  10,
+		ClockSkew:        DefaultClockSkew,
 	}
 }
@@ -1,10 +1,17 @@
 package token_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gentra/decorator-arch-go/internal/token"
 )
@@ -568,6 +575,11 @@ func TestTokenInfo_IsExpired(t *testing.T) {
 }
 
 func TestTokenConfig_IsValid(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
 	tests := []struct {
 		name   string
 		config token.TokenConfig
@@ -609,6 +621,51 @@ func TestTokenConfig_IsValid(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Given token config with unknown algorithm, When IsValid is called, Then should return false",
+			config: token.TokenConfig{
+				Secret:    []byte("secret-key"),
+				AccessTTL: time.Hour,
+				Algorithm: "none",
+			},
+			expected: false,
+		},
+		{
+			name: "Given RS256 token config with an RSA signing key, When IsValid is called, Then should return true",
+			config: token.TokenConfig{
+				AccessTTL:  time.Hour,
+				Algorithm:  token.AlgorithmRS256,
+				SigningKey: rsaKey,
+			},
+			expected: true,
+		},
+		{
+			name: "Given ES256 token config with only an ECDSA verification key, When IsValid is called, Then should return true",
+			config: token.TokenConfig{
+				AccessTTL:       time.Hour,
+				Algorithm:       token.AlgorithmES256,
+				VerificationKey: &ecdsaKey.PublicKey,
+			},
+			expected: true,
+		},
+		{
+			name: "Given RS256 token config with only a secret, When IsValid is called, Then should return false",
+			config: token.TokenConfig{
+				Secret:    []byte("secret-key"),
+				AccessTTL: time.Hour,
+				Algorithm: token.AlgorithmRS256,
+			},
+			expected: false,
+		},
+		{
+			name: "Given ES256 token config with an RSA signing key, When IsValid is called, Then should return false",
+			config: token.TokenConfig{
+				AccessTTL:  time.Hour,
+				Algorithm:  token.AlgorithmES256,
+				SigningKey: rsaKey,
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -638,6 +695,7 @@ func TestDefaultTokenConfig(t *testing.T) {
 		assert.True(t, config.EnableRefresh)
 		assert.True(t, config.EnableRevocation)
 		assert.Equal(t, 10, config.MaxActiveTokens)
+		assert.Equal(t, token.DefaultClockSkew, config.ClockSkew)
 	})
 }
 
@@ -726,4 +784,59 @@ func TestTokenErrors_Constants(t *testing.T) {
 			assert.NotEmpty(t, tt.err.Message)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNewJWK_GivenRFC7638ExampleKey_WhenConverting_ThenUsesItsThumbprintAsKeyID(t *testing.T) {
+	// Arrange
+	modulus, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: 65537}
+
+	// Act
+	jwk, err := token.NewJWK(key, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", jwk.KeyID)
+	assert.Equal(t, "AQAB", jwk.E)
+	assert.Equal(t, token.AlgorithmRS256, jwk.Algorithm)
+}
+
+func TestNewJWK_GivenPublicKeys_WhenConverting_ThenReturnsJWKOrError(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		key         interface{}
+		keyID       string
+		expectError bool
+	}{
+		{name: "Given a P-256 key and key ID, When converting, Then returns an ES256 JWK with the ID", key: &p256Key.PublicKey, keyID: "key-1"},
+		{name: "Given a P-384 key, When converting, Then returns error", key: &p384Key.PublicKey, expectError: true},
+		{name: "Given a symmetric secret, When converting, Then returns error", key: []byte("secret"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			jwk, err := token.NewJWK(tt.key, tt.keyID)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "EC", jwk.KeyType)
+			assert.Equal(t, "P-256", jwk.Curve)
+			assert.Equal(t, token.AlgorithmES256, jwk.Algorithm)
+			assert.Equal(t, tt.keyID, jwk.KeyID)
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			require.NoError(t, err)
+			assert.Len(t, x, 32)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"context"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+)
+
+// BeforeFunc is called before a token is issued
+type BeforeFunc func(ctx context.Context, req *tokenpolicy.IssueRequest) error
+
+// AfterFunc is called after a token is issued
+type AfterFunc func(ctx context.Context, req tokenpolicy.IssueRequest, issued tokenpolicy.IssuedToken) error
+
+// service implements tokenpolicy.Service using plain functions
+type service struct {
+	name   string
+	before BeforeFunc
+	after  AfterFunc
+}
+
+// NewService creates a token policy from hook functions; either function may be nil
+func NewService(name string, before BeforeFunc, after AfterFunc) tokenpolicy.Service {
+	return &service{
+		name:   name,
+		before: before,
+		after:  after,
+	}
+}
+
+// Name returns the policy name
+func (s *service) Name() string {
+	return s.name
+}
+
+// BeforeIssue runs the pre-issue hook if set
+func (s *service) BeforeIssue(ctx context.Context, req *tokenpolicy.IssueRequest) error {
+	if s.before == nil {
+		return nil
+	}
+	return s.before(ctx, req)
+}
+
+// AfterIssue runs the post-issue hook if set
+func (s *service) AfterIssue(ctx context.Context, req tokenpolicy.IssueRequest, issued tokenpolicy.IssuedToken) error {
+	if s.after == nil {
+		return nil
+	}
+	return s.after(ctx, req, issued)
+}
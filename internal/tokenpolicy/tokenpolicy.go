@@ -0,0 +1,76 @@
+package tokenpolicy
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the token policy domain interface - the ONLY interface in this domain
+// Policies run around token issuance to veto tokens or annotate their claims
+type Service interface {
+	// Name identifies the policy in errors and logs
+	Name() string
+
+	// BeforeIssue runs before a token is signed. Returning an error vetoes issuance;
+	// the request's Claims may be extended to annotate the token.
+	BeforeIssue(ctx context.Context, req *IssueRequest) error
+
+	// AfterIssue runs after a token is signed. Returning an error revokes the token.
+	AfterIssue(ctx context.Context, req IssueRequest, issued IssuedToken) error
+}
+
+// Domain types and models
+
+// IssueRequest describes a token about to be issued
+type IssueRequest struct {
+	UserID    string                 `json:"user_id"`
+	Email     string                 `json:"email,omitempty"`
+	TokenType string                 `json:"token_type"`         // auth, refresh, api, reset, verification, impersonation, one_time
+	ActorID   string                 `json:"actor_id,omitempty"` // Admin acting as the user, for impersonation tokens
+	Scopes    []string               `json:"scopes,omitempty"`
+	Claims    map[string]interface{} `json:"claims,omitempty"` // Extra claims embedded in the token
+}
+
+// IssuedToken describes a token that has been issued
+type IssuedToken struct {
+	Token     string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Token types passed to policies
+const (
+	TokenTypeAuth          = "auth"
+	TokenTypeRefresh       = "refresh"
+	TokenTypeAPI           = "api"
+	TokenTypeReset         = "reset"
+	TokenTypeVerification  = "verification"
+	TokenTypeImpersonation = "impersonation"
+	TokenTypeOneTime       = "one_time"
+)
+
+// AddClaim annotates the token with an extra claim
+func (r *IssueRequest) AddClaim(name string, value interface{}) {
+	if r.Claims == nil {
+		r.Claims = make(map[string]interface{})
+	}
+	r.Claims[name] = value
+}
+
+// PolicyError represents domain-specific token policy errors
+type PolicyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Policy  string `json:"policy,omitempty"`
+}
+
+func (e PolicyError) Error() string {
+	return e.Message
+}
+
+// Common token policy error codes
+var (
+	ErrIssuanceDenied    = PolicyError{Code: "ISSUANCE_DENIED", Message: "Token issuance denied by policy"}
+	ErrUserSuspended     = PolicyError{Code: "USER_SUSPENDED", Message: "User account is suspended"}
+	ErrSeatLimitExceeded = PolicyError{Code: "SEAT_LIMIT_EXCEEDED", Message: "Tenant seat limit exceeded"}
+	ErrReservedClaim     = PolicyError{Code: "RESERVED_CLAIM", Message: "Policy attempted to set a reserved claim"}
+)
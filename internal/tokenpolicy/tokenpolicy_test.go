@@ -0,0 +1,25 @@
+package tokenpolicy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenpolicy"
+)
+
+func TestIssueRequest_AddClaim(t *testing.T) {
+	t.Run("Given request without claims, When adding claim, Then claims map is created", func(t *testing.T) {
+		req := tokenpolicy.IssueRequest{UserID: "user-1", TokenType: tokenpolicy.TokenTypeAuth}
+
+		req.AddClaim("tenant_id", "tenant-1")
+
+		assert.Equal(t, map[string]interface{}{"tenant_id": "tenant-1"}, req.Claims)
+	})
+}
+
+func TestPolicyError_Error(t *testing.T) {
+	t.Run("Given policy error, When formatting, Then returns message", func(t *testing.T) {
+		assert.Equal(t, "User account is suspended", tokenpolicy.ErrUserSuspended.Error())
+	})
+}
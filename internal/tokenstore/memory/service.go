@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+)
+
+// service implements tokenstore.Service in memory, for single instances and
+// tests. Expired records are swept when records are saved.
+type service struct {
+	mu      sync.Mutex
+	records map[string]tokenstore.Record
+	byUser  map[string]map[string]struct{}
+}
+
+// NewService creates an in-memory token store
+func NewService() tokenstore.Service {
+	return &service{
+		records: make(map[string]tokenstore.Record),
+		byUser:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Save stores the record and drops the expired ones
+func (s *service) Save(ctx context.Context, record tokenstore.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(time.Now())
+	s.records[record.ID] = clone(record)
+	ids, ok := s.byUser[record.UserID]
+	if !ok {
+		ids = make(map[string]struct{})
+		s.byUser[record.UserID] = ids
+	}
+	ids[record.ID] = struct{}{}
+	return nil
+}
+
+// Get returns a copy of the record while it has not expired
+func (s *service) Get(ctx context.Context, id string) (*tokenstore.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[id]
+	if !ok || record.IsExpired(time.Now()) {
+		return nil, tokenstore.ErrNotFound
+	}
+	record = clone(record)
+	return &record, nil
+}
+
+// Revoke marks the record revoked
+func (s *service) Revoke(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoke(id, time.Now())
+	return nil
+}
+
+// RevokeUser marks every record of the user revoked
+func (s *service) RevokeUser(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id := range s.byUser[userID] {
+		s.revoke(id, now)
+	}
+	return nil
+}
+
+// ListByUser returns copies of the user's unexpired records, oldest first
+func (s *service) ListByUser(ctx context.Context, userID string) ([]tokenstore.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var records []tokenstore.Record
+	for id := range s.byUser[userID] {
+		if record := s.records[id]; !record.IsExpired(now) {
+			records = append(records, clone(record))
+		}
+	}
+	slices.SortFunc(records, func(a, b tokenstore.Record) int { return a.IssuedAt.Compare(b.IssuedAt) })
+	return records, nil
+}
+
+// Helper methods
+
+// revoke marks a record revoked unless it already is; callers hold mu
+func (s *service) revoke(id string, now time.Time) {
+	record, ok := s.records[id]
+	if !ok || record.IsRevoked() {
+		return
+	}
+	record.RevokedAt = &now
+	s.records[id] = record
+}
+
+// sweep drops the expired records; callers hold mu
+func (s *service) sweep(now time.Time) {
+	for id, record := range s.records {
+		if !record.IsExpired(now) {
+			continue
+		}
+		delete(s.records, id)
+		delete(s.byUser[record.UserID], id)
+		if len(s.byUser[record.UserID]) == 0 {
+			delete(s.byUser, record.UserID)
+		}
+	}
+}
+
+// clone copies the slices and map of a record so callers cannot change
+// stored ones
+func clone(record tokenstore.Record) tokenstore.Record {
+	record.Scopes = slices.Clone(record.Scopes)
+	record.AMR = slices.Clone(record.AMR)
+	record.Custom = maps.Clone(record.Custom)
+	return record
+}
@@ -0,0 +1,70 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+	"github.com/gentra/decorator-arch-go/internal/tokenstore/memory"
+)
+
+func record(id, userID string, ttl time.Duration) tokenstore.Record {
+	now := time.Now()
+	return tokenstore.Record{ID: id, UserID: userID, TokenType: "auth", IssuedAt: now, ExpiresAt: now.Add(ttl)}
+}
+
+func TestTokenStore_GivenSavedRecord_WhenGetting_ThenReturnsCopy(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	saved := record("t1", "user123", time.Hour)
+	saved.Scopes = []string{"read"}
+	require.NoError(t, store.Save(ctx, saved))
+
+	got, err := store.Get(ctx, "t1")
+	require.NoError(t, err)
+	got.Scopes[0] = "admin"
+
+	again, err := store.Get(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read"}, again.Scopes)
+}
+
+func TestTokenStore_GivenExpiredRecord_WhenGetting_ThenReturnsNotFound(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	require.NoError(t, store.Save(ctx, record("t1", "user123", -time.Second)))
+
+	_, err := store.Get(ctx, "t1")
+	assert.ErrorIs(t, err, tokenstore.ErrNotFound)
+
+	records, err := store.ListByUser(ctx, "user123")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestTokenStore_GivenUserRecords_WhenRevokingUser_ThenMarksOnlyTheirs(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewService()
+	require.NoError(t, store.Save(ctx, record("t1", "user123", time.Hour)))
+	require.NoError(t, store.Save(ctx, record("t2", "user123", time.Hour)))
+	require.NoError(t, store.Save(ctx, record("t3", "user456", time.Hour)))
+
+	require.NoError(t, store.RevokeUser(ctx, "user123"))
+
+	records, err := store.ListByUser(ctx, "user123")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.True(t, records[0].IsRevoked())
+	assert.True(t, records[1].IsRevoked())
+	other, err := store.Get(ctx, "t3")
+	require.NoError(t, err)
+	assert.False(t, other.IsRevoked())
+}
+
+func TestTokenStore_GivenUnknownID_WhenRevoking_ThenIgnoresIt(t *testing.T) {
+	assert.NoError(t, memory.NewService().Revoke(context.Background(), "missing"))
+}
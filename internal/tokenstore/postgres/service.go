@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+)
+
+// service implements tokenstore.Service on the opaque_tokens table, so every
+// instance sharing the database accepts the same tokens. The claims are kept
+// as JSON next to the columns queries filter on.
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed token store
+func NewService(pool *pgxpool.Pool) tokenstore.Service {
+	return &service{pool: pool}
+}
+
+// Save inserts the record and deletes the user's expired ones
+func (s *service) Save(ctx context.Context, record tokenstore.Record) error {
+	claims, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM opaque_tokens WHERE user_id = $1 AND expires_at <= now()`, record.UserID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO opaque_tokens (id, user_id, claims, issued_at, expires_at, revoked_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			record.ID, record.UserID, claims, record.IssuedAt, record.ExpiresAt, record.RevokedAt)
+		return err
+	})
+}
+
+// Get reads the record while it has not expired
+func (s *service) Get(ctx context.Context, id string) (*tokenstore.Record, error) {
+	record, err := scanRecord(s.pool.QueryRow(ctx, `
+		SELECT claims, revoked_at FROM opaque_tokens
+		WHERE id = $1 AND expires_at > now()`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, tokenstore.ErrNotFound
+	}
+	return record, err
+}
+
+// Revoke stamps the record's revocation time
+func (s *service) Revoke(ctx context.Context, id string) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE opaque_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+// RevokeUser stamps the revocation time of every record of the user
+func (s *service) RevokeUser(ctx context.Context, userID string) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE opaque_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
+
+// ListByUser reads the user's unexpired records, oldest first
+func (s *service) ListByUser(ctx context.Context, userID string) ([]tokenstore.Record, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT claims, revoked_at FROM opaque_tokens
+		WHERE user_id = $1 AND expires_at > now()
+		ORDER BY issued_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []tokenstore.Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// Helper methods
+
+// scanRecord decodes the claims of a row; the revocation time comes from its
+// column, which revocations update
+func scanRecord(row pgx.Row) (*tokenstore.Record, error) {
+	var claims []byte
+	var record tokenstore.Record
+	var revokedAt *time.Time
+	if err := row.Scan(&claims, &revokedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(claims, &record); err != nil {
+		return nil, err
+	}
+	record.RevokedAt = revokedAt
+	return &record, nil
+}
@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/tokenstore"
+)
+
+// DefaultKeyPrefix namespaces token records in a shared Redis
+const DefaultKeyPrefix = "tokenstore:"
+
+// service implements tokenstore.Service on Redis. Records expire with their
+// key TTL, and a sorted set per user, scored by expiry, indexes them for
+// listing and revoking all of a user's tokens.
+type service struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewService creates a Redis-backed token store using DefaultKeyPrefix
+func NewService(client *redis.Client) tokenstore.Service {
+	return NewServiceWithPrefix(client, DefaultKeyPrefix)
+}
+
+// NewServiceWithPrefix creates a Redis-backed token store whose keys start
+// with prefix
+func NewServiceWithPrefix(client *redis.Client, prefix string) tokenstore.Service {
+	return &service{client: client, prefix: prefix}
+}
+
+// Save stores the record until it expires and adds it to the user's index
+func (s *service) Save(ctx context.Context, record tokenstore.Record) error {
+	now := time.Now()
+	ttl := record.ExpiresAt.Sub(now)
+	if ttl <= 0 {
+		return nil
+	}
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	userKey := s.userKey(record.UserID)
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.recordKey(record.ID), payload, ttl)
+	pipe.ZAdd(ctx, userKey, redis.Z{Score: float64(record.ExpiresAt.Unix()), Member: record.ID})
+	pipe.ZRemRangeByScore(ctx, userKey, "-inf", strconv.FormatInt(now.Unix(), 10))
+	last := pipe.ZRangeWithScores(ctx, userKey, -1, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// The index lives as long as the user's longest-lived token
+	if latest := last.Val(); len(latest) == 1 {
+		return s.client.ExpireAt(ctx, userKey, time.Unix(int64(latest[0].Score)+1, 0)).Err()
+	}
+	return nil
+}
+
+// Get reads the record; Redis drops it once it expires
+func (s *service) Get(ctx context.Context, id string) (*tokenstore.Record, error) {
+	payload, err := s.client.Get(ctx, s.recordKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, tokenstore.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record tokenstore.Record
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Revoke rewrites the record as revoked, keeping its TTL
+func (s *service) Revoke(ctx context.Context, id string) error {
+	record, err := s.Get(ctx, id)
+	if errors.Is(err, tokenstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil || record.IsRevoked() {
+		return err
+	}
+
+	revokedAt := time.Now()
+	record.RevokedAt = &revokedAt
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	err = s.client.SetArgs(ctx, s.recordKey(id), payload, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if errors.Is(err, redis.Nil) {
+		// The record expired in the meantime
+		return nil
+	}
+	return err
+}
+
+// RevokeUser revokes every unexpired record in the user's index
+func (s *service) RevokeUser(ctx context.Context, userID string) error {
+	ids, err := s.userTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.Revoke(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListByUser reads the unexpired records in the user's index, oldest first
+func (s *service) ListByUser(ctx context.Context, userID string) ([]tokenstore.Record, error) {
+	ids, err := s.userTokens(ctx, userID)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.recordKey(id)
+	}
+	payloads, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]tokenstore.Record, 0, len(payloads))
+	for _, payload := range payloads {
+		data, ok := payload.(string)
+		if !ok {
+			continue // expired since it was indexed
+		}
+		var record tokenstore.Record
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b tokenstore.Record) int { return a.IssuedAt.Compare(b.IssuedAt) })
+	return records, nil
+}
+
+// Helper methods
+
+// recordKey returns the Redis key holding a record
+func (s *service) recordKey(id string) string {
+	return s.prefix + "token:" + id
+}
+
+// userKey returns the Redis sorted set indexing a user's records
+func (s *service) userKey(userID string) string {
+	return s.prefix + "user:" + userID
+}
+
+// userTokens returns the IDs of the user's records that have not expired
+func (s *service) userTokens(ctx context.Context, userID string) ([]string, error) {
+	return s.client.ZRangeByScore(ctx, s.userKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+}
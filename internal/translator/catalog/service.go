@@ -0,0 +1,163 @@
+package catalog
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+// locales holds the translations shipped with the binary, one JSON object of
+// message templates per language named after it, e.g. en.json
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+// Config configures a catalog translator
+type Config struct {
+	// DefaultLanguage renders messages no requested language has, and
+	// defaults to translator.DefaultLanguage
+	DefaultLanguage string
+
+	// Languages restricts the catalog to these languages, plus the default
+	// language; every loaded language is kept when empty
+	Languages []string
+
+	// Loaders add translations on top of the embedded ones, later loaders
+	// replacing the messages of earlier ones
+	Loaders []translator.Loader
+}
+
+// service implements translator.Service interface from an in-memory catalog
+// built once from the embedded translations and the configured loaders
+type service struct {
+	catalog         translator.Catalog
+	defaultLanguage string
+}
+
+// NewService creates a new catalog translator
+func NewService(config Config) (translator.Service, error) {
+	defaultLanguage := translator.NormalizeLanguage(config.DefaultLanguage)
+	if defaultLanguage == "" {
+		defaultLanguage = translator.DefaultLanguage
+	}
+
+	catalog := translator.Catalog{}
+	for _, load := range append([]translator.Loader{EmbeddedLoader()}, config.Loaders...) {
+		loaded, err := load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load translations: %w", err)
+		}
+		catalog.Merge(loaded)
+	}
+
+	if len(config.Languages) > 0 {
+		keep := map[string]bool{defaultLanguage: true}
+		for _, language := range config.Languages {
+			keep[translator.NormalizeLanguage(language)] = true
+		}
+		for language := range catalog {
+			if !keep[language] {
+				delete(catalog, language)
+			}
+		}
+	}
+	if _, ok := catalog[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("no translations for default language %q", defaultLanguage)
+	}
+
+	return &service{catalog: catalog, defaultLanguage: defaultLanguage}, nil
+}
+
+// MustNewService is like NewService but panics when the catalog cannot be
+// built, for catalogs of embedded translations only
+func MustNewService(config Config) translator.Service {
+	service, err := NewService(config)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// Translate renders the message under key in the first requested language
+// that has it, trying each language before its base language, then in the
+// default language
+func (s *service) Translate(ctx context.Context, key string, params map[string]string) string {
+	for _, language := range translator.LanguagesFromContext(ctx) {
+		for _, candidate := range []string{translator.NormalizeLanguage(language), translator.BaseLanguage(language)} {
+			if template, ok := s.catalog[candidate][key]; ok {
+				return render(template, params)
+			}
+		}
+	}
+	if template, ok := s.catalog[s.defaultLanguage][key]; ok {
+		return render(template, params)
+	}
+	return key
+}
+
+// Languages lists the languages of the catalog in alphabetical order
+func (s *service) Languages() []string {
+	languages := make([]string, 0, len(s.catalog))
+	for language := range s.catalog {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// EmbeddedLoader loads the translations shipped with the binary
+func EmbeddedLoader() translator.Loader {
+	return func() (translator.Catalog, error) {
+		return loadFS(locales, "locales")
+	}
+}
+
+// DirLoader loads the translations in the JSON files of a directory, each
+// named after its language, e.g. de.json or pt-BR.json
+func DirLoader(dir string) translator.Loader {
+	return func() (translator.Catalog, error) {
+		return loadFS(os.DirFS(dir), ".")
+	}
+}
+
+// loadFS loads the JSON translation files of a directory of fsys
+func loadFS(fsys fs.FS, dir string) (translator.Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := translator.Catalog{}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		catalog.Merge(translator.Catalog{strings.TrimSuffix(path.Base(file), ".json"): messages})
+	}
+	return catalog, nil
+}
+
+// render replaces the {name} placeholders of a template with their params
+func render(template string, params map[string]string) string {
+	if len(params) == 0 {
+		return template
+	}
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
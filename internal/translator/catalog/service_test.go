@@ -0,0 +1,132 @@
+package catalog_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
+)
+
+func TestTranslate_GivenRequestedLanguages_WhenTranslating_ThenUsesFirstLanguageWithTheMessage(t *testing.T) {
+	// Arrange
+	service, err := catalog.NewService(catalog.Config{})
+	require.NoError(t, err)
+	params := map[string]string{"param": "8"}
+
+	tests := []struct {
+		name      string
+		languages []string
+		expected  string
+	}{
+		{
+			name:      "Given a supported language, When translating, Then renders it with params",
+			languages: []string{"es"},
+			expected:  "debe tener al menos 8 caracteres",
+		},
+		{
+			name:      "Given a regional language, When translating, Then falls back to its base language",
+			languages: []string{"fr-CA"},
+			expected:  "doit contenir au moins 8 caractères",
+		},
+		{
+			name:      "Given an unsupported language first, When translating, Then tries the next one",
+			languages: []string{"ja", "es"},
+			expected:  "debe tener al menos 8 caracteres",
+		},
+		{
+			name:     "Given no languages, When translating, Then renders the default language",
+			expected: "must be at least 8 characters long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := translator.WithLanguages(context.Background(), tt.languages)
+
+			// Act
+			message := service.Translate(ctx, "validation.min.string", params)
+
+			// Assert
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
+
+func TestTranslate_GivenUnknownKey_WhenTranslating_ThenReturnsKey(t *testing.T) {
+	// Arrange
+	service, err := catalog.NewService(catalog.Config{})
+	require.NoError(t, err)
+
+	// Act
+	message := service.Translate(context.Background(), "validation.unknown", nil)
+
+	// Assert
+	assert.Equal(t, "validation.unknown", message)
+}
+
+func TestNewService_GivenEmbeddedTranslations_WhenLoading_ThenEveryLanguageHasEveryEnglishKey(t *testing.T) {
+	// Arrange
+	loaded, err := catalog.EmbeddedLoader()()
+	require.NoError(t, err)
+
+	// Assert
+	require.Contains(t, loaded, "en")
+	for language, messages := range loaded {
+		for key := range loaded["en"] {
+			assert.Contains(t, messages, key, "%s lacks %s", language, key)
+		}
+	}
+}
+
+func TestNewService_GivenConfig_WhenBuilding_ThenAppliesLoadersAndLanguages(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeTranslations(t, dir, "de", map[string]string{"validation.required": "Feld ist erforderlich"})
+	writeTranslations(t, dir, "es", map[string]string{"validation.required": "obligatorio"})
+	ctx := context.Background()
+
+	// Act
+	loaded, loadErr := catalog.NewService(catalog.Config{Loaders: []translator.Loader{catalog.DirLoader(dir)}})
+	restricted, restrictErr := catalog.NewService(catalog.Config{DefaultLanguage: "es", Languages: []string{"fr"}})
+	_, missingErr := catalog.NewService(catalog.Config{DefaultLanguage: "ja"})
+
+	// Assert
+	require.NoError(t, loadErr)
+	assert.Equal(t, []string{"de", "en", "es", "fr"}, loaded.Languages())
+	assert.Equal(t, "Feld ist erforderlich", loaded.Translate(translator.WithLanguages(ctx, []string{"de"}), "validation.required", nil))
+	assert.Equal(t, "obligatorio", loaded.Translate(translator.WithLanguages(ctx, []string{"es"}), "validation.required", nil))
+
+	require.NoError(t, restrictErr)
+	assert.Equal(t, []string{"es", "fr"}, restricted.Languages())
+	assert.Equal(t, "el campo es obligatorio", restricted.Translate(translator.WithLanguages(ctx, []string{"en"}), "validation.required", nil))
+
+	assert.Error(t, missingErr)
+}
+
+func TestNewService_GivenMalformedTranslationFile_WhenBuilding_ThenReturnsError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.json"), []byte("{not json"), 0o600))
+
+	// Act
+	service, err := catalog.NewService(catalog.Config{Loaders: []translator.Loader{catalog.DirLoader(dir)}})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, service)
+}
+
+func writeTranslations(t *testing.T, dir, language string, messages map[string]string) {
+	t.Helper()
+	data, err := json.Marshal(messages)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, language+".json"), data, 0o600))
+}
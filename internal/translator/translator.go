@@ -0,0 +1,113 @@
+package translator
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language messages render in when none of the
+// requester's languages has a translation
+const DefaultLanguage = "en"
+
+// Service defines the translator domain interface - the ONLY interface in this domain.
+// It renders messages in the languages requesters prefer, falling back to a
+// default language.
+type Service interface {
+	// Translate renders the message under key in the first language of the
+	// context that has it, or else in the default language, replacing
+	// {name} placeholders with params. Unknown keys render as the key itself.
+	Translate(ctx context.Context, key string, params map[string]string) string
+
+	// Languages lists the languages messages can render in
+	Languages() []string
+}
+
+// Catalog holds message templates by language and key
+type Catalog map[string]map[string]string
+
+// Merge adds the messages of other to the catalog, replacing the messages
+// it already has under the same language and key
+func (c Catalog) Merge(other Catalog) {
+	for language, messages := range other {
+		language = NormalizeLanguage(language)
+		if c[language] == nil {
+			c[language] = make(map[string]string, len(messages))
+		}
+		for key, message := range messages {
+			c[language][key] = message
+		}
+	}
+}
+
+// Loader loads a catalog, e.g. the translations embedded in the binary or
+// those deployed next to it
+type Loader func() (Catalog, error)
+
+// languagesKey is the context key carrying the requester's languages
+type languagesKey struct{}
+
+// WithLanguages returns a context tagged with the requester's languages,
+// most preferred first
+func WithLanguages(ctx context.Context, languages []string) context.Context {
+	return context.WithValue(ctx, languagesKey{}, languages)
+}
+
+// LanguagesFromContext returns the requester's languages, most preferred
+// first, if any
+func LanguagesFromContext(ctx context.Context) []string {
+	languages, _ := ctx.Value(languagesKey{}).([]string)
+	return languages
+}
+
+// NormalizeLanguage lower-cases a language tag and separates its subtags
+// with hyphens, so "pt_BR" and "pt-br" name the same language
+func NormalizeLanguage(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// BaseLanguage returns the primary subtag of a language tag, e.g. "pt" for
+// "pt-br"
+func BaseLanguage(language string) string {
+	base, _, _ := strings.Cut(NormalizeLanguage(language), "-")
+	return base
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header
+// ordered by their quality, most preferred first. Wildcards and languages
+// with a zero or malformed quality are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		language, params, _ := strings.Cut(part, ";")
+		language = NormalizeLanguage(language)
+		if language == "" || language == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{language: language, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+	languages := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		languages = append(languages, r.language)
+	}
+	return languages
+}
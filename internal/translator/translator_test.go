@@ -0,0 +1,75 @@
+package translator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+)
+
+func TestParseAcceptLanguage_GivenHeader_WhenParsing_ThenOrdersLanguagesByQuality(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []string
+	}{
+		{
+			name:     "Given weighted languages, When parsing, Then returns most preferred first",
+			header:   "fr;q=0.7, es-MX, en;q=0.9",
+			expected: []string{"es-mx", "en", "fr"},
+		},
+		{
+			name:     "Given equal qualities, When parsing, Then keeps header order",
+			header:   "pt_BR, pt",
+			expected: []string{"pt-br", "pt"},
+		},
+		{
+			name:     "Given wildcards and excluded or malformed qualities, When parsing, Then leaves them out",
+			header:   "*, de;q=0, it;q=high, es",
+			expected: []string{"es"},
+		},
+		{
+			name:     "Given no header, When parsing, Then returns no languages",
+			header:   "",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			languages := translator.ParseAcceptLanguage(tt.header)
+
+			// Assert
+			assert.Equal(t, tt.expected, languages)
+		})
+	}
+}
+
+func TestCatalogMerge_GivenOverlappingCatalogs_WhenMerging_ThenLaterMessagesWin(t *testing.T) {
+	// Arrange
+	catalog := translator.Catalog{"en": {"greeting": "hello", "farewell": "bye"}}
+
+	// Act
+	catalog.Merge(translator.Catalog{"EN": {"greeting": "hi"}, "es": {"greeting": "hola"}})
+
+	// Assert
+	assert.Equal(t, translator.Catalog{
+		"en": {"greeting": "hi", "farewell": "bye"},
+		"es": {"greeting": "hola"},
+	}, catalog)
+}
+
+func TestWithLanguages_GivenContext_WhenTagged_ThenReturnsLanguages(t *testing.T) {
+	// Arrange
+	ctx := translator.WithLanguages(context.Background(), []string{"es", "en"})
+
+	// Act
+	languages := translator.LanguagesFromContext(ctx)
+
+	// Assert
+	assert.Equal(t, []string{"es", "en"}, languages)
+	assert.Nil(t, translator.LanguagesFromContext(context.Background()))
+}
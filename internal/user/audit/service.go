@@ -2,9 +2,12 @@ package audit
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
@@ -68,6 +71,44 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	return result, err
 }
 
+// GetByIDs retrieves several users with a single audit entry for the batch
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	// Call next service
+	result, err := s.next.GetByIDs(ctx, ids)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.get_by_ids", "user", "", map[string]interface{}{
+		"requested_user_ids": ids,
+		"found":              len(result),
+	}, err == nil, err)
+
+	return result, err
+}
+
+// List retrieves a page of users with audit logging. Search terms are not
+// recorded, only which filters were used.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	// Call next service
+	result, err := s.next.List(ctx, filters)
+
+	// Log audit entry
+	details := map[string]interface{}{
+		"sort_by":    filters.SortBy,
+		"sort_order": filters.SortOrder,
+		"limit":      filters.Limit,
+		"offset":     filters.Offset,
+		"cursor":     filters.Cursor != "",
+		"filtered":   filters.EmailPrefix != "" || filters.Name != "" || filters.CreatedAfter != nil || filters.CreatedBefore != nil,
+	}
+	if result != nil {
+		details["returned"] = len(result.Users)
+		details["total"] = result.Total
+	}
+	s.logAuditEntry(ctx, "user.list", "user", "", details, err == nil, err)
+
+	return result, err
+}
+
 // UpdateProfile updates user profile with audit logging
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	// Call next service
@@ -92,6 +133,127 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 	return result, err
 }
 
+// ChangePassword changes a user's password with audit logging; neither
+// password is ever written to the audit log
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	// Call next service
+	err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.change_password", "user", userID, map[string]interface{}{
+		"requested_user_id": userID,
+	}, err == nil, err)
+
+	return err
+}
+
+// RequestPasswordReset starts a password reset with audit logging
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	// Call next service
+	result, err := s.next.RequestPasswordReset(ctx, email)
+
+	// Log audit entry
+	userID := ""
+	if result != nil {
+		userID = result.ID.String()
+	}
+
+	s.logAuditEntry(ctx, "user.request_password_reset", "user", userID, map[string]interface{}{
+		"email": email,
+	}, err == nil, err)
+
+	return result, err
+}
+
+// ResetPassword resets a password with audit logging; the token and password
+// are never logged
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Call next service
+	err := s.next.ResetPassword(ctx, token, newPassword)
+
+	// Log audit entry for the user the usecase layer resolved the token to
+	userID := user.PasswordResetFromContext(ctx)
+	s.logAuditEntry(ctx, "user.reset_password", "user", userID, map[string]interface{}{
+		"requested_user_id": userID,
+	}, err == nil, err)
+
+	return err
+}
+
+// RequestEmailChange records an email change request with audit logging
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	// Call next service
+	err := s.next.RequestEmailChange(ctx, userID, newEmail)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.request_email_change", "user", userID, map[string]interface{}{
+		"requested_user_id": userID,
+		"new_email":         newEmail,
+	}, err == nil, err)
+
+	return err
+}
+
+// ConfirmEmailChange applies a confirmed email change with audit logging; the
+// confirmation token is never written to the audit log
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	// Call next service
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+
+	// Log audit entry
+	details := map[string]interface{}{
+		"requested_user_id": userID,
+	}
+	if result != nil {
+		details["new_email"] = result.Email
+	}
+	s.logAuditEntry(ctx, "user.confirm_email_change", "user", userID, details, err == nil, err)
+
+	return result, err
+}
+
+// VerifyEmail marks the user's email verified with audit logging; the
+// verification token is never written to the audit log
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	// Call next service
+	result, err := s.next.VerifyEmail(ctx, token)
+
+	// Log audit entry for the user the usecase layer resolved the token to
+	userID := user.EmailVerificationFromContext(ctx)
+	details := map[string]interface{}{
+		"requested_user_id": userID,
+	}
+	if result != nil {
+		details["email"] = result.Email
+	}
+	s.logAuditEntry(ctx, "user.verify_email", "user", userID, details, err == nil, err)
+
+	return result, err
+}
+
+// UploadAvatar stores a new avatar with audit logging; the image itself is
+// not logged, only its type and size
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	counter := &countingReader{r: content}
+
+	// Call next service
+	err := s.next.UploadAvatar(ctx, userID, counter, contentType)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.upload_avatar", "user", userID, map[string]interface{}{
+		"requested_user_id": userID,
+		"content_type":      contentType,
+		"bytes":             counter.n,
+	}, err == nil, err)
+
+	return err
+}
+
+// GetAvatarURL returns a link to the avatar (no audit logging for reads of public data)
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences retrieves user preferences with audit logging
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	// Call next service
@@ -120,21 +282,182 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 	return err
 }
 
+// UpdatePreferencesBulk updates several users' preferences, logging an entry
+// per user so each user's trail stays complete
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	// Call next service
+	err := s.next.UpdatePreferencesBulk(ctx, updates)
+
+	// Log audit entries
+	for userID, prefs := range updates {
+		s.logAuditEntry(ctx, "user.update_preferences", "user_preferences", userID, map[string]interface{}{
+			"theme":    prefs.Theme,
+			"language": prefs.Language,
+			"timezone": prefs.Timezone,
+			"bulk":     true,
+		}, err == nil, err)
+	}
+
+	return err
+}
+
+// Deactivate deactivates a user with audit logging
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	// Call next service
+	err := s.next.Deactivate(ctx, id)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.deactivate", "user", id, map[string]interface{}{
+		"requested_user_id": id,
+	}, err == nil, err)
+
+	return err
+}
+
+// Delete soft deletes a user with audit logging
+func (s *service) Delete(ctx context.Context, id string) error {
+	// Call next service
+	err := s.next.Delete(ctx, id)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.delete", "user", id, map[string]interface{}{
+		"requested_user_id": id,
+	}, err == nil, err)
+
+	return err
+}
+
+// ExportUserData adds the user's audit trail to the export, with audit logging
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	// Call next service
+	result, err := s.next.ExportUserData(ctx, userID)
+	if err == nil {
+		result.AuditEntries, err = s.collectEntries(ctx, userID)
+		if err != nil {
+			result, err = nil, fmt.Errorf("failed to collect audit entries: %w", err)
+		}
+	}
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.export_data", "user", userID, map[string]interface{}{
+		"requested_user_id": userID,
+	}, err == nil, err)
+
+	return result, err
+}
+
+// EraseUser erases a user's personal data and anonymizes their audit trail.
+// Audit entries are kept for accountability, but no longer identify the user.
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	// Call next service
+	err := s.next.EraseUser(ctx, userID)
+
+	// Log audit entry; it is anonymized below along with the rest of the trail
+	s.logAuditEntry(ctx, "user.erase", "user", userID, nil, err == nil, err)
+	if err != nil {
+		return err
+	}
+
+	// The data is already erased, so finish even if the caller went away
+	detached, cancel := user.DetachContext(ctx)
+	defer cancel()
+	if _, err := s.auditService.AnonymizeUser(detached, userID); err != nil {
+		return fmt.Errorf("failed to anonymize audit entries: %w", err)
+	}
+
+	return nil
+}
+
+// CleanupPreferences cleans up stale notification types with audit logging
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	// Call next service
+	report, err := s.next.CleanupPreferences(ctx, opts)
+
+	// Log audit entry with the counts, not the affected users
+	details := map[string]interface{}{"strip": opts.Strip}
+	if report != nil {
+		details["scanned"] = report.Scanned
+		details["affected"] = len(report.AffectedUsers)
+		details["unknown_keys"] = report.UnknownKeys
+	}
+	s.logAuditEntry(ctx, "user.cleanup_preferences", "user_preferences", "", details, err == nil, err)
+
+	return report, err
+}
+
+// GetFeatureFlags retrieves the user's feature flags without an audit entry;
+// flags are read on every request to a gated endpoint
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag switches a feature flag for the user with audit logging
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	// Call next service
+	err := s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+
+	// Log audit entry
+	s.logAuditEntry(ctx, "user.set_feature_flag", "user_feature_flags", userID, map[string]interface{}{
+		"flag":    flag,
+		"enabled": enabled,
+	}, err == nil, err)
+
+	return err
+}
+
+// collectEntries gathers the entries the user performed or was the subject
+// of, without duplicates
+func (s *service) collectEntries(ctx context.Context, userID string) ([]audit.AuditEntry, error) {
+	byUser, err := s.auditService.GetAuditLogsByUser(ctx, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+	aboutUser, err := s.auditService.GetAuditLogsByResource(ctx, "user", userID, 0)
+	if err != nil {
+		return nil, err
+	}
+	aboutPreferences, err := s.auditService.GetAuditLogsByResource(ctx, "user_preferences", userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]audit.AuditEntry, 0, len(byUser)+len(aboutUser)+len(aboutPreferences))
+	seen := make(map[string]bool)
+	for _, group := range [][]audit.AuditEntry{byUser, aboutUser, aboutPreferences} {
+		for _, entry := range group {
+			if entry.ID != "" && seen[entry.ID] {
+				continue
+			}
+			seen[entry.ID] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
 // logAuditEntry logs an audit entry with the provided information
 func (s *service) logAuditEntry(ctx context.Context, action, resource, resourceID string, details interface{}, success bool, err error) {
 	entry := audit.AuditEntry{
-		Timestamp:  time.Now(),
-		Action:     action,
-		Resource:   resource,
-		ResourceID: resourceID,
-		Details:    details,
-		Success:    success,
+		Timestamp:     time.Now(),
+		Action:        action,
+		Resource:      resource,
+		ResourceID:    resourceID,
+		Details:       details,
+		Success:       success,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
 	}
 
 	if err != nil {
 		entry.Error = err.Error()
 	}
 
+	// Record the fields sanitization rewrote before the call
+	if report, ok := sanitize.ReportFromContext(ctx); ok {
+		if fields, isMap := details.(map[string]interface{}); isMap {
+			fields["sanitized"] = report.Changes
+		}
+	}
+
 	// Extract audit context information if available
 	if auditCtx := extractAuditContext(ctx); auditCtx != nil {
 		entry.UserID = auditCtx.UserID
@@ -143,8 +466,17 @@ func (s *service) logAuditEntry(ctx context.Context, action, resource, resourceI
 		entry.SessionID = auditCtx.SessionID
 	}
 
-	// Log the entry using the audit domain service
+	// Attribute impersonated calls to the admin behind them
+	if imp, ok := user.ImpersonationFromContext(ctx); ok {
+		entry.ActorID = imp.ActorID
+		entry.OnBehalfOfID = imp.OnBehalfOfID
+	}
+
+	// Log the entry using the audit domain service on a detached context so
+	// the trail is recorded even when the caller disconnected mid-request.
 	// Don't fail the operation if audit logging fails
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
 	s.auditService.Log(ctx, entry)
 }
 
@@ -182,3 +514,15 @@ func WithAuditContext(ctx context.Context, userID, ipAddress, userAgent, session
 
 	return context.WithValue(ctx, AuditContextKey, auditCtx)
 }
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
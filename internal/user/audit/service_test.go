@@ -3,14 +3,20 @@ package audit_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
 	"github.com/gentra/decorator-arch-go/internal/user"
 	userAudit "github.com/gentra/decorator-arch-go/internal/user/audit"
 )
@@ -44,6 +50,22 @@ func (m *mockUserService) GetByID(ctx context.Context, id string) (*user.User, e
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *mockUserService) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*user.User), args.Error(1)
+}
+
+func (m *mockUserService) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Page), args.Error(1)
+}
+
 func (m *mockUserService) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	args := m.Called(ctx, id, data)
 	if args.Get(0) == nil {
@@ -52,6 +74,55 @@ func (m *mockUserService) UpdateProfile(ctx context.Context, id string, data use
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *mockUserService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockUserService) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockUserService) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	args := m.Called(ctx, userID, newEmail)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	args := m.Called(ctx, userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	args := m.Called(ctx, userID, content, contentType)
+	return args.Error(0)
+}
+
+func (m *mockUserService) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *mockUserService) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -65,6 +136,55 @@ func (m *mockUserService) UpdatePreferences(ctx context.Context, userID string,
 	return args.Error(0)
 }
 
+func (m *mockUserService) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	args := m.Called(ctx, updates)
+	return args.Error(0)
+}
+
+func (m *mockUserService) Deactivate(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockUserService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.DataExport), args.Error(1)
+}
+
+func (m *mockUserService) EraseUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockUserService) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.PreferenceCleanupReport), args.Error(1)
+}
+
+func (m *mockUserService) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.FeatureFlags), args.Error(1)
+}
+
+func (m *mockUserService) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	args := m.Called(ctx, userID, flag, enabled)
+	return args.Error(0)
+}
+
 type mockAuditService struct {
 	mock.Mock
 }
@@ -89,6 +209,11 @@ func (m *mockAuditService) GetAuditLogsByResource(ctx context.Context, resource,
 	return args.Get(0).([]audit.AuditEntry), args.Error(1)
 }
 
+func (m *mockAuditService) AnonymizeUser(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
 func TestNewService_GivenValidDependencies_WhenCreating_ThenReturnsService(t *testing.T) {
 	mockNext := &mockUserService{}
 	mockAudit := &mockAuditService{}
@@ -508,16 +633,246 @@ func TestUpdatePreferences_GivenPreferences_WhenUpdating_ThenLogsAuditAndCallsNe
 	}
 }
 
-func TestAuditContext_GivenContextWithAuditInfo_WhenLogging_ThenIncludesContextInEntry(t *testing.T) {
+func TestDeactivateAndDelete_GivenUserID_WhenCalled_ThenLogsAuditAndCallsNext(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		expectedAction string
+		nextError      error
+	}{
+		{
+			name:           "successful deactivation",
+			method:         "Deactivate",
+			expectedAction: "user.deactivate",
+		},
+		{
+			name:           "successful deletion",
+			method:         "Delete",
+			expectedAction: "user.delete",
+		},
+		{
+			name:           "deletion of unknown user",
+			method:         "Delete",
+			expectedAction: "user.delete",
+			nextError:      user.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockNext := &mockUserService{}
+			mockAudit := &mockAuditService{}
+			userID := "user123"
+
+			// Setup expectations
+			mockNext.On(tt.method, mock.Anything, userID).Return(tt.nextError)
+			mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+				return entry.Action == tt.expectedAction &&
+					entry.Resource == "user" &&
+					entry.ResourceID == userID &&
+					entry.Success == (tt.nextError == nil)
+			})).Return(nil)
+
+			service := userAudit.NewService(mockNext, mockAudit)
+
+			// Execute
+			var err error
+			if tt.method == "Deactivate" {
+				err = service.Deactivate(context.Background(), userID)
+			} else {
+				err = service.Delete(context.Background(), userID)
+			}
+
+			// Verify
+			assert.Equal(t, tt.nextError, err)
+			mockNext.AssertExpectations(t)
+			mockAudit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePassword_GivenPasswords_WhenChanging_ThenLogsAuditWithoutPasswords(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	userID := "user123"
+
+	// Setup expectations
+	mockNext.On("ChangePassword", mock.Anything, userID, "old-password", "new-password").Return(user.ErrPasswordReused)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details := fmt.Sprint(entry.Details)
+		return entry.Action == "user.change_password" &&
+			entry.ResourceID == userID &&
+			!entry.Success &&
+			!strings.Contains(details, "old-password") &&
+			!strings.Contains(details, "new-password")
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	err := service.ChangePassword(context.Background(), userID, "old-password", "new-password")
+
+	// Verify
+	assert.Equal(t, user.ErrPasswordReused, err)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestConfirmEmailChange_GivenToken_WhenConfirming_ThenLogsNewEmailWithoutToken(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	userID := "user123"
+
+	// Setup expectations
+	mockNext.On("ConfirmEmailChange", mock.Anything, userID, "confirm-token").Return(&user.User{Email: "new@example.com"}, nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		return entry.Action == "user.confirm_email_change" &&
+			entry.ResourceID == userID &&
+			entry.Success &&
+			entry.Details.(map[string]interface{})["new_email"] == "new@example.com" &&
+			!strings.Contains(fmt.Sprint(entry.Details), "confirm-token")
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	result, err := service.ConfirmEmailChange(context.Background(), userID, "confirm-token")
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", result.Email)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestVerifyEmail_GivenResolvedToken_WhenVerifying_ThenLogsUserWithoutToken(t *testing.T) {
 	mockNext := &mockUserService{}
 	mockAudit := &mockAuditService{}
+	userID := "user123"
+	ctx := user.WithEmailVerification(context.Background(), userID)
+
+	// Setup expectations
+	mockNext.On("VerifyEmail", mock.Anything, "verify-token").Return(&user.User{Email: "jane@example.com"}, nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		return entry.Action == "user.verify_email" &&
+			entry.ResourceID == userID &&
+			entry.Success &&
+			entry.Details.(map[string]interface{})["email"] == "jane@example.com" &&
+			!strings.Contains(fmt.Sprint(entry.Details), "verify-token")
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	result, err := service.VerifyEmail(ctx, "verify-token")
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", result.Email)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
 
+func TestResetPassword_GivenResolvedToken_WhenResetting_ThenLogsUserWithoutSecrets(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
 	userID := "user123"
-	userData := &user.User{
-		ID:    uuid.New(),
-		Email: "user@example.com",
+	ctx := user.WithPasswordReset(context.Background(), userID)
+
+	// Setup expectations
+	mockNext.On("ResetPassword", mock.Anything, "reset-token", "NewPassword456!").Return(nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details := fmt.Sprint(entry.Details)
+		return entry.Action == "user.reset_password" &&
+			entry.ResourceID == userID &&
+			entry.Success &&
+			!strings.Contains(details, "reset-token") &&
+			!strings.Contains(details, "NewPassword456!")
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	err := service.ResetPassword(ctx, "reset-token", "NewPassword456!")
+
+	// Verify
+	require.NoError(t, err)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestCleanupPreferences_GivenStaleKeys_WhenStripping_ThenLogsCountsWithoutUserIDs(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	opts := user.PreferenceCleanupOptions{Strip: true}
+	report := &user.PreferenceCleanupReport{
+		Scanned:       10,
+		UnknownKeys:   map[string]int{"legacy_digest": 2},
+		AffectedUsers: []string{"user-1", "user-2"},
+		Stripped:      true,
 	}
 
+	mockNext.On("CleanupPreferences", mock.Anything, opts).Return(report, nil)
+	var logged audit.AuditEntry
+	mockAudit.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { logged = args.Get(1).(audit.AuditEntry) }).
+		Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	result, err := service.CleanupPreferences(context.Background(), opts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, report, result)
+	assert.Equal(t, "user.cleanup_preferences", logged.Action)
+	assert.Equal(t, "user_preferences", logged.Resource)
+	assert.True(t, logged.Success)
+	assert.Equal(t, map[string]interface{}{
+		"strip":        true,
+		"scanned":      10,
+		"affected":     2,
+		"unknown_keys": map[string]int{"legacy_digest": 2},
+	}, logged.Details)
+}
+
+func TestUpdatePreferencesBulk_GivenSeveralUsers_WhenUpdating_ThenLogsEntryPerUser(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	updates := map[string]user.UserPreferences{
+		"user-1": {Theme: "dark"},
+		"user-2": {Theme: "light"},
+	}
+
+	mockNext.On("UpdatePreferencesBulk", mock.Anything, updates).Return(nil)
+	logged := map[string]audit.AuditEntry{}
+	mockAudit.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			entry := args.Get(1).(audit.AuditEntry)
+			logged[entry.ResourceID] = entry
+		}).
+		Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	err := service.UpdatePreferencesBulk(context.Background(), updates)
+
+	assert.NoError(t, err)
+	require.Len(t, logged, 2)
+	for userID, entry := range logged {
+		assert.Equal(t, "user.update_preferences", entry.Action)
+		assert.Equal(t, "user_preferences", entry.Resource)
+		assert.Equal(t, updates[userID].Theme, entry.Details.(map[string]interface{})["theme"])
+		assert.Equal(t, true, entry.Details.(map[string]interface{})["bulk"])
+	}
+}
+
+func TestAuditContext_GivenContextWithAuditInfo_WhenLogging_ThenIncludesContextInEntry(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+
+	userID := "user123"
+	userData := testkit.NewUserBuilder().WithRandomID().WithEmail("user@example.com").Build()
+
 	// Setup expectations
 	mockNext.On("GetByID", mock.Anything, userID).Return(userData, nil)
 	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
@@ -549,15 +904,40 @@ func TestAuditContext_GivenContextWithAuditInfo_WhenLogging_ThenIncludesContextI
 	mockAudit.AssertExpectations(t)
 }
 
+func TestAuditContext_GivenImpersonation_WhenLogging_ThenRecordsActorAndEffectiveUser(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	userID := "user123"
+
+	// Setup expectations
+	mockNext.On("UpdateProfile", mock.Anything, userID, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		return entry.UserID == userID &&
+			entry.ActorID == "admin-1" &&
+			entry.OnBehalfOfID == userID
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Create context of an admin acting as the user
+	ctx := userAudit.WithAuditContext(context.Background(), userID, "192.168.1.1", "test-agent", "session-456")
+	ctx = user.WithImpersonation(ctx, user.Impersonation{ActorID: "admin-1", OnBehalfOfID: userID})
+
+	// Execute
+	_, err := service.UpdateProfile(ctx, userID, user.UpdateProfileData{})
+
+	// Verify
+	require.NoError(t, err)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
 func TestAuditContext_GivenContextWithoutAuditInfo_WhenLogging_ThenSkipsContextInfo(t *testing.T) {
 	mockNext := &mockUserService{}
 	mockAudit := &mockAuditService{}
 
 	userID := "user123"
-	userData := &user.User{
-		ID:    uuid.New(),
-		Email: "user@example.com",
-	}
+	userData := testkit.NewUserBuilder().WithRandomID().WithEmail("user@example.com").Build()
 
 	// Setup expectations
 	mockNext.On("GetByID", mock.Anything, userID).Return(userData, nil)
@@ -589,10 +969,7 @@ func TestAuditService_GivenAuditLoggingFailure_WhenLogging_ThenOperationStillSuc
 	mockAudit := &mockAuditService{}
 
 	userID := "user123"
-	userData := &user.User{
-		ID:    uuid.New(),
-		Email: "user@example.com",
-	}
+	userData := testkit.NewUserBuilder().WithRandomID().WithEmail("user@example.com").Build()
 
 	// Setup expectations - audit logging fails but operation succeeds
 	mockNext.On("GetByID", mock.Anything, userID).Return(userData, nil)
@@ -610,4 +987,118 @@ func TestAuditService_GivenAuditLoggingFailure_WhenLogging_ThenOperationStillSuc
 
 	mockNext.AssertExpectations(t)
 	mockAudit.AssertExpectations(t)
-}
\ No newline at end of file
+}
+func TestAuditService_GivenCanceledContext_WhenLogging_ThenWritesOnDetachedContext(t *testing.T) {
+	// The audit trail must be recorded even if the caller disconnected mid-request
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+
+	userID := "user123"
+	ctx, cancel := context.WithCancel(audit.WithCorrelationID(context.Background(), "corr-1"))
+
+	mockNext.On("UpdatePreferences", mock.Anything, userID, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil)
+
+	var logErr error
+	var correlationID string
+	mockAudit.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			logCtx := args.Get(0).(context.Context)
+			logErr = logCtx.Err()
+			correlationID = audit.ExtractCorrelationID(logCtx)
+		}).
+		Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	err := service.UpdatePreferences(ctx, userID, user.UserPreferences{})
+
+	// Verify
+	assert.NoError(t, err)
+	assert.NoError(t, logErr)
+	assert.Equal(t, "corr-1", correlationID)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestUploadAvatar_GivenImage_WhenUploading_ThenLogsTypeAndSize(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	userID := "user123"
+
+	// Setup expectations
+	mockNext.On("UploadAvatar", mock.Anything, userID, mock.Anything, "image/png").
+		Run(func(args mock.Arguments) { _, _ = io.ReadAll(args.Get(2).(io.Reader)) }).
+		Return(nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details := entry.Details.(map[string]interface{})
+		return entry.Action == "user.upload_avatar" &&
+			entry.ResourceID == userID &&
+			entry.Success &&
+			details["content_type"] == "image/png" &&
+			details["bytes"] == int64(9)
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	err := service.UploadAvatar(context.Background(), userID, strings.NewReader("png-bytes"), "image/png")
+
+	// Verify
+	require.NoError(t, err)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestSetFeatureFlag_GivenFlagChange_WhenSetting_ThenLogsFlagAndValue(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	userID := "user123"
+
+	// Setup expectations
+	mockNext.On("SetFeatureFlag", mock.Anything, userID, "beta_dashboard", true).Return(nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details := entry.Details.(map[string]interface{})
+		return entry.Action == "user.set_feature_flag" &&
+			entry.Resource == "user_feature_flags" &&
+			entry.ResourceID == userID &&
+			entry.Success &&
+			details["flag"] == "beta_dashboard" &&
+			details["enabled"] == true
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+
+	// Execute
+	err := service.SetFeatureFlag(context.Background(), userID, "beta_dashboard", true)
+
+	// Verify
+	require.NoError(t, err)
+	mockNext.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestRegister_GivenSanitizedInput_WhenRegistering_ThenLogsTheSanitizedFields(t *testing.T) {
+	mockNext := &mockUserService{}
+	mockAudit := &mockAuditService{}
+	data := user.RegisterData{Email: "jane@example.com", FirstName: "Jane", LastName: "Doe"}
+	changes := []sanitize.Change{{Field: "first_name", Sanitizers: []string{"trim_space"}}}
+
+	// Setup expectations
+	mockNext.On("Register", mock.Anything, data).Return(&user.User{ID: uuid.New()}, nil)
+	mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details, ok := entry.Details.(map[string]interface{})
+		return ok && assert.ObjectsAreEqual(changes, details["sanitized"])
+	})).Return(nil)
+
+	service := userAudit.NewService(mockNext, mockAudit)
+	ctx := sanitize.WithReport(context.Background(), sanitize.Report{Changes: changes})
+
+	// Execute
+	_, err := service.Register(ctx, data)
+
+	// Verify
+	require.NoError(t, err)
+	mockAudit.AssertExpectations(t)
+}
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"io"
+	"log"
 
 	"github.com/google/uuid"
 
@@ -64,11 +66,89 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	return s.next.GetByID(ctx, id)
 }
 
+// GetByIDs retrieves several users (delegates to next service)
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List retrieves a page of users (delegates to next service)
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
 // UpdateProfile updates user profile (delegates to next service)
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	return s.next.UpdateProfile(ctx, id, data)
 }
 
+// ChangePassword verifies the current password with the auth domain before
+// delegating the change to the next service
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	u, err := s.next.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	authResult, err := s.authService.Authenticate(ctx, "basic", auth.BasicCredentials{
+		Email:    u.Email,
+		Password: currentPassword,
+	})
+	if err != nil {
+		// Convert auth domain errors back to user domain errors
+		if err == auth.ErrInvalidCredentials {
+			return user.ErrIncorrectPassword
+		}
+		if err == auth.ErrUserNotFound {
+			return user.ErrUserNotFound
+		}
+		return err
+	}
+
+	// Verification issued a session nobody asked for; revoke it straight away
+	if authResult.Token != "" {
+		if err := s.authService.RevokeToken(ctx, authResult.Token); err != nil {
+			log.Printf("Failed to revoke verification token for user %s: %v", userID, err)
+		}
+	}
+
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset starts a password reset (delegates to next service)
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword resets a forgotten password (delegates to next service)
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange records an email change request (delegates to next service)
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange applies a confirmed email change (delegates to next service)
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail marks the user's email verified (delegates to next service)
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar stores a new avatar (delegates to next service)
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL returns a link to the avatar (delegates to next service)
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences retrieves user preferences (delegates to next service)
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	return s.next.GetPreferences(ctx, userID)
@@ -79,6 +159,46 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 	return s.next.UpdatePreferences(ctx, userID, prefs)
 }
 
+// UpdatePreferencesBulk updates several users' preferences (delegates to next service)
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate deactivates a user (delegates to next service)
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete soft deletes a user (delegates to next service)
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData exports a user's data (delegates to next service)
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser erases a user's personal data (delegates to next service)
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences cleans up stale notification types (delegates to next service)
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags retrieves the user's feature flags (delegates to next service)
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag switches a feature flag for the user (delegates to next service)
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
 // This auth adapter only implements user.Service interface
 // All authentication logic is handled by the auth domain service internally
 
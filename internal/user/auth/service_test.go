@@ -3,6 +3,7 @@ package auth_test
 import (
 	"context"
 	"errors"
+	"io"
 	"testing"
 	"time"
 
@@ -44,6 +45,22 @@ func (m *mockUserService) GetByID(ctx context.Context, id string) (*user.User, e
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *mockUserService) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*user.User), args.Error(1)
+}
+
+func (m *mockUserService) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Page), args.Error(1)
+}
+
 func (m *mockUserService) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	args := m.Called(ctx, id, data)
 	if args.Get(0) == nil {
@@ -52,6 +69,55 @@ func (m *mockUserService) UpdateProfile(ctx context.Context, id string, data use
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *mockUserService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockUserService) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockUserService) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	args := m.Called(ctx, userID, newEmail)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	args := m.Called(ctx, userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserService) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	args := m.Called(ctx, userID, content, contentType)
+	return args.Error(0)
+}
+
+func (m *mockUserService) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *mockUserService) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -65,6 +131,55 @@ func (m *mockUserService) UpdatePreferences(ctx context.Context, userID string,
 	return args.Error(0)
 }
 
+func (m *mockUserService) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	args := m.Called(ctx, updates)
+	return args.Error(0)
+}
+
+func (m *mockUserService) Deactivate(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockUserService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.DataExport), args.Error(1)
+}
+
+func (m *mockUserService) EraseUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockUserService) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.PreferenceCleanupReport), args.Error(1)
+}
+
+func (m *mockUserService) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.FeatureFlags), args.Error(1)
+}
+
+func (m *mockUserService) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	args := m.Called(ctx, userID, flag, enabled)
+	return args.Error(0)
+}
+
 type mockAuthService struct {
 	mock.Mock
 }
@@ -106,6 +221,19 @@ func (m *mockAuthService) RevokeToken(ctx context.Context, token string) error {
 	return args.Error(0)
 }
 
+func (m *mockAuthService) Introspect(ctx context.Context, token string) (*auth.TokenIntrospection, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenIntrospection), args.Error(1)
+}
+
+func (m *mockAuthService) RevokeAllForUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func (m *mockAuthService) GetSupportedStrategies() []string {
 	args := m.Called()
 	return args.Get(0).([]string)
@@ -298,6 +426,57 @@ func TestLogin_GivenValidCredentials_WhenLoggingIn_ThenUsesAuthDomain(t *testing
 	}
 }
 
+func TestChangePassword_GivenCurrentPassword_WhenChanging_ThenVerifiesWithAuthDomain(t *testing.T) {
+	tests := []struct {
+		name          string
+		authResult    *auth.AuthResult
+		authError     error
+		expectedError error
+	}{
+		{
+			name:       "correct current password",
+			authResult: &auth.AuthResult{Token: "verification-token"},
+		},
+		{
+			name:          "incorrect current password",
+			authError:     auth.ErrInvalidCredentials,
+			expectedError: user.ErrIncorrectPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockNext := &mockUserService{}
+			mockAuth := &mockAuthService{}
+			userID := "user-123"
+
+			// Setup expectations
+			mockNext.On("GetByID", mock.Anything, userID).Return(&user.User{Email: "user@example.com"}, nil)
+			mockAuth.On("Authenticate", mock.Anything, "basic", auth.BasicCredentials{
+				Email:    "user@example.com",
+				Password: "old-password",
+			}).Return(tt.authResult, tt.authError)
+			if tt.expectedError == nil {
+				mockAuth.On("RevokeToken", mock.Anything, "verification-token").Return(nil)
+				mockNext.On("ChangePassword", mock.Anything, userID, "old-password", "new-password").Return(nil)
+			}
+
+			service := userAuth.NewService(mockNext, mockAuth)
+
+			// Execute
+			err := service.ChangePassword(context.Background(), userID, "old-password", "new-password")
+
+			// Verify
+			assert.Equal(t, tt.expectedError, err)
+			mockAuth.AssertExpectations(t)
+			mockNext.AssertExpectations(t)
+			if tt.expectedError != nil {
+				mockNext.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestLogin_GivenAuthUserWithInvalidUUID_WhenLoggingIn_ThenUsesNilUUID(t *testing.T) {
 	mockNext := &mockUserService{}
 	mockAuth := &mockAuthService{}
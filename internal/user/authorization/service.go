@@ -0,0 +1,241 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements user.Service with authorization checks
+// This decorator asks the authorization domain whether the caller stored in the
+// context may modify the target user. Registration, login and reads pass through
+// because they are either public or used internally without a caller. While an
+// admin impersonates a user, only the actions granted to the impersonation are
+// allowed, and never email changes.
+type service struct {
+	next          user.Service
+	authorization authorization.Service
+}
+
+// NewService creates a new authorization decorator for user service
+func NewService(next user.Service, authorizationService authorization.Service) user.Service {
+	return &service{
+		next:          next,
+		authorization: authorizationService,
+	}
+}
+
+// Register passes through; registration is public
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	return s.next.Register(ctx, data)
+}
+
+// Login passes through; login establishes the caller
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	return s.next.Login(ctx, email, password)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through, like GetByID
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List requires a role granting it; pages expose every user, not just the caller
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if err := s.authorize(ctx, authorization.ActionUserList, ""); err != nil {
+		return nil, err
+	}
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile requires the caller to own the profile or hold a granting role
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	if err := s.authorize(ctx, authorization.ActionUserUpdateProfile, id); err != nil {
+		return nil, err
+	}
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword requires the caller to own the account or hold a granting role
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if err := s.authorize(ctx, authorization.ActionUserChangePassword, userID); err != nil {
+		return err
+	}
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset passes through; the user is not signed in
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through; the reset token authorizes it
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange requires the caller to be allowed to update the profile;
+// impersonating admins cannot, as the new email would take over the account
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	if _, ok := user.ImpersonationFromContext(ctx); ok {
+		return user.ErrForbidden
+	}
+	if err := s.authorize(ctx, authorization.ActionUserUpdateProfile, userID); err != nil {
+		return err
+	}
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange requires the caller to be allowed to update the profile;
+// impersonating admins cannot, like RequestEmailChange
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	if _, ok := user.ImpersonationFromContext(ctx); ok {
+		return nil, user.ErrForbidden
+	}
+	if err := s.authorize(ctx, authorization.ActionUserUpdateProfile, userID); err != nil {
+		return nil, err
+	}
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through: the token itself proves the caller received
+// the verification email, so no signed-in subject is needed
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar requires the caller to be allowed to update the profile
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	if err := s.authorize(ctx, authorization.ActionUserUpdateProfile, userID); err != nil {
+		return err
+	}
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences requires the caller to own the preferences or hold a granting role
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	if err := s.authorize(ctx, authorization.ActionUserUpdatePreferences, userID); err != nil {
+		return err
+	}
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk requires the caller to be allowed to update every
+// user's preferences; one denial rejects the whole batch
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	for userID := range updates {
+		if err := s.authorize(ctx, authorization.ActionUserUpdatePreferences, userID); err != nil {
+			return err
+		}
+	}
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate requires the caller to own the account or hold a granting role
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	if err := s.authorize(ctx, authorization.ActionUserDeactivate, id); err != nil {
+		return err
+	}
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete requires the caller to own the account or hold a granting role
+func (s *service) Delete(ctx context.Context, id string) error {
+	if err := s.authorize(ctx, authorization.ActionUserDelete, id); err != nil {
+		return err
+	}
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData requires the caller to own the data or hold a granting role
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	if err := s.authorize(ctx, authorization.ActionUserExportData, userID); err != nil {
+		return nil, err
+	}
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser requires the caller to own the data or hold a granting role
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	if err := s.authorize(ctx, authorization.ActionUserErase, userID); err != nil {
+		return err
+	}
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences requires a role granting the cleanup; it touches every user
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	if err := s.authorize(ctx, authorization.ActionUserCleanupPreferences, ""); err != nil {
+		return nil, err
+	}
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through, like GetPreferences
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag requires a role granting it; owners cannot switch their own flags
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	if err := s.authorize(ctx, authorization.ActionUserSetFeatureFlags, userID); err != nil {
+		return err
+	}
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// authorize checks the action against the target user, translating policy
+// denials into user.ErrForbidden. Impersonated calls must also have been
+// granted the action.
+func (s *service) authorize(ctx context.Context, action, userID string) error {
+	subject, ok := authorization.SubjectFromContext(ctx)
+	if !ok {
+		return user.ErrForbidden
+	}
+	if imp, ok := user.ImpersonationFromContext(ctx); ok {
+		if !authorization.IsImpersonationAction(action) || !imp.Allows(action) {
+			return user.ErrForbidden
+		}
+	}
+
+	err := s.authorization.Authorize(ctx, authorization.Request{
+		Subject: subject,
+		Action:  action,
+		Resource: authorization.Resource{
+			Type:    authorization.ResourceTypeUser,
+			ID:      userID,
+			OwnerID: userID,
+		},
+	})
+
+	var authorizationErr authorization.AuthorizationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &authorizationErr):
+		return user.ErrForbidden
+	default:
+		return fmt.Errorf("authorization check failed: %w", err)
+	}
+}
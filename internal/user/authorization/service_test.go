@@ -0,0 +1,277 @@
+package authorization_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/authorization/rbac"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userAuthorization "github.com/gentra/decorator-arch-go/internal/user/authorization"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+// failingEngine is a pluggable policy engine that fails every check with an infrastructure error
+type failingEngine struct{}
+
+func (failingEngine) Authorize(context.Context, authorization.Request) error {
+	return errors.New("policy store unavailable")
+}
+
+func TestAuthorization_UpdateProfile(t *testing.T) {
+	const ownerID = "00000000-0000-4000-8000-000000000001"
+
+	tests := []struct {
+		name        string
+		subject     *authorization.Subject
+		callsNext   bool
+		expectedErr error
+	}{
+		{
+			name:      "Given the owner, When updating the profile, Then calls the next layer",
+			subject:   &authorization.Subject{ID: ownerID, Roles: []string{authorization.RoleUser}},
+			callsNext: true,
+		},
+		{
+			name:      "Given an admin, When updating another user's profile, Then calls the next layer",
+			subject:   &authorization.Subject{ID: "admin-1", Roles: []string{authorization.RoleUser, authorization.RoleAdmin}},
+			callsNext: true,
+		},
+		{
+			name:        "Given another user, When updating the profile, Then returns forbidden",
+			subject:     &authorization.Subject{ID: "user-2", Roles: []string{authorization.RoleUser}},
+			expectedErr: user.ErrForbidden,
+		},
+		{
+			name:        "Given no caller, When updating the profile, Then returns forbidden",
+			expectedErr: user.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &userMock.MockUserService{}
+			service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+			ctx := context.Background()
+			if tt.subject != nil {
+				ctx = authorization.WithSubject(ctx, *tt.subject)
+			}
+			if tt.callsNext {
+				next.On("UpdateProfile", mock.Anything, ownerID, mock.Anything).
+					Return(testkit.NewUserBuilder().Build(), nil)
+			}
+
+			_, err := service.UpdateProfile(ctx, ownerID, user.UpdateProfileData{})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				next.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				next.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestAuthorization_GivenAnotherUser_WhenUpdatingPreferences_ThenReturnsForbidden(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-2"})
+
+	err := service.UpdatePreferences(ctx, "user-1", *testkit.NewPreferencesBuilder().Build())
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+	next.AssertNotCalled(t, "UpdatePreferences", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthorization_GivenAnotherUser_WhenChangingPassword_ThenReturnsForbidden(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-2", Roles: []string{authorization.RoleUser}})
+
+	err := service.ChangePassword(ctx, "user-1", "old-password", "new-password")
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+	next.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthorization_GivenRegularUser_WhenCleaningUpPreferences_ThenReturnsForbidden(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-1", Roles: []string{authorization.RoleUser}})
+
+	_, err := service.CleanupPreferences(ctx, user.PreferenceCleanupOptions{Strip: true})
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+	next.AssertNotCalled(t, "CleanupPreferences", mock.Anything, mock.Anything)
+}
+
+func TestAuthorization_GivenAdmin_WhenCleaningUpPreferences_ThenCallsNext(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "admin-1", Roles: []string{authorization.RoleAdmin}})
+	next.On("CleanupPreferences", mock.Anything, user.PreferenceCleanupOptions{}).Return(&user.PreferenceCleanupReport{}, nil)
+
+	_, err := service.CleanupPreferences(ctx, user.PreferenceCleanupOptions{})
+
+	assert.NoError(t, err)
+	next.AssertExpectations(t)
+}
+
+func TestAuthorization_GivenRegularUser_WhenListingUsers_ThenReturnsForbidden(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-1", Roles: []string{authorization.RoleUser}})
+
+	_, err := service.List(ctx, user.ListFilters{})
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+	next.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestAuthorization_GivenBatchWithAnotherUser_WhenUpdatingPreferencesInBulk_ThenRejectsWholeBatch(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-1", Roles: []string{authorization.RoleUser}})
+
+	err := service.UpdatePreferencesBulk(ctx, map[string]user.UserPreferences{
+		"user-1": {Theme: "dark"},
+		"user-2": {Theme: "dark"},
+	})
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+	next.AssertNotCalled(t, "UpdatePreferencesBulk", mock.Anything, mock.Anything)
+}
+
+func TestAuthorization_GivenEngineFailure_WhenUpdatingPreferences_ThenReturnsWrappedError(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, failingEngine{})
+	ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-1"})
+
+	err := service.UpdatePreferences(ctx, "user-1", user.UserPreferences{})
+
+	assert.ErrorContains(t, err, "policy store unavailable")
+	assert.NotErrorIs(t, err, user.ErrForbidden)
+}
+
+func TestAuthorization_GivenNoCaller_WhenReading_ThenPassesThrough(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+	found := testkit.NewUserBuilder().Build()
+	next.On("GetByID", mock.Anything, "user-1").Return(found, nil)
+
+	result, err := service.GetByID(context.Background(), "user-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, found, result)
+}
+
+func TestAuthorization_Delete(t *testing.T) {
+	const ownerID = "00000000-0000-4000-8000-000000000001"
+
+	t.Run("Given the owner, When deleting the account, Then calls the next layer", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: ownerID})
+		next.On("Delete", mock.Anything, ownerID).Return(nil)
+
+		err := service.Delete(ctx, ownerID)
+
+		assert.NoError(t, err)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("Given another user, When deactivating the account, Then returns forbidden", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "user-2"})
+
+		err := service.Deactivate(ctx, ownerID)
+
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		next.AssertNotCalled(t, "Deactivate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthorization_SetFeatureFlag(t *testing.T) {
+	const ownerID = "00000000-0000-4000-8000-000000000001"
+
+	t.Run("Given an admin, When setting a user's feature flag, Then calls the next layer", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: "admin-1", Roles: []string{authorization.RoleAdmin}})
+		next.On("SetFeatureFlag", mock.Anything, ownerID, "beta_dashboard", true).Return(nil)
+
+		err := service.SetFeatureFlag(ctx, ownerID, "beta_dashboard", true)
+
+		assert.NoError(t, err)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("Given the owner, When setting their own feature flag, Then returns forbidden", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: ownerID, Roles: []string{authorization.RoleUser}})
+
+		err := service.SetFeatureFlag(ctx, ownerID, "beta_dashboard", true)
+
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		next.AssertNotCalled(t, "SetFeatureFlag", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthorization_Impersonation(t *testing.T) {
+	const ownerID = "00000000-0000-4000-8000-000000000001"
+
+	impersonating := func(scopes ...string) context.Context {
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: ownerID, Roles: []string{authorization.RoleUser}})
+		return user.WithImpersonation(ctx, user.Impersonation{ActorID: "admin-1", OnBehalfOfID: ownerID, Scopes: scopes})
+	}
+
+	t.Run("Given a granted scope, When updating the profile, Then calls the next layer", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+		next.On("UpdateProfile", mock.Anything, ownerID, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+
+		_, err := service.UpdateProfile(impersonating(authorization.ActionUserUpdateProfile), ownerID, user.UpdateProfileData{})
+
+		assert.NoError(t, err)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("Given an ungranted scope, When updating preferences, Then returns forbidden", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+
+		err := service.UpdatePreferences(impersonating(authorization.ActionUserUpdateProfile), ownerID, *testkit.NewPreferencesBuilder().Build())
+
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		next.AssertNotCalled(t, "UpdatePreferences", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a scope never allowed while impersonating, When changing the password, Then returns forbidden", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+
+		err := service.ChangePassword(impersonating(authorization.ActionUserChangePassword), ownerID, "old-password", "new-password")
+
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		next.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given the profile scope, When requesting an email change, Then returns forbidden", func(t *testing.T) {
+		next := &userMock.MockUserService{}
+		service := userAuthorization.NewService(next, rbac.NewService(rbac.DefaultConfig()))
+
+		err := service.RequestEmailChange(impersonating(authorization.ActionUserUpdateProfile), ownerID, "new@example.com")
+
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		next.AssertNotCalled(t, "RequestEmailChange", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
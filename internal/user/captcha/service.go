@@ -0,0 +1,249 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config controls when registrations and logins need a solved captcha
+type Config struct {
+	LoginFailures int           // Failed logins per email or client IP after which Login needs a captcha; negative never asks
+	FailureWindow time.Duration // Time after the last failed login for which failures are remembered
+	SkipRegister  bool          // Lets Register through without a captcha
+}
+
+// DefaultConfig returns the default captcha configuration
+func DefaultConfig() Config {
+	return Config{
+		LoginFailures: 3,
+		FailureWindow: 15 * time.Minute,
+	}
+}
+
+// service implements user.Service with captcha checks
+// This decorator makes Register, and Login once an email or client IP has
+// failed LoginFailures times within the window, check the token stored with
+// user.WithCaptchaToken with a captcha.Service, unless the request was
+// marked with user.WithCaptchaExemption. Missing and rejected tokens
+// fail with captcha errors without reaching the next layer. Failed logins
+// are counted in a throttle.Service, and a successful login forgets the
+// email's failures. Every other method passes through.
+type service struct {
+	next     user.Service
+	verifier captcha.Service
+	failures throttle.Service
+	config   Config
+}
+
+// NewService creates a new captcha decorator
+func NewService(next user.Service, verifier captcha.Service, failures throttle.Service, config Config) user.Service {
+	defaults := DefaultConfig()
+	if config.LoginFailures == 0 {
+		config.LoginFailures = defaults.LoginFailures
+	}
+	if config.FailureWindow <= 0 {
+		config.FailureWindow = defaults.FailureWindow
+	}
+
+	return &service{
+		next:     next,
+		verifier: verifier,
+		failures: failures,
+		config:   config,
+	}
+}
+
+// Login checks a captcha once the email or client IP failed too often and
+// counts failed attempts
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	if s.config.LoginFailures < 0 || user.IsCaptchaExempt(ctx) {
+		return s.next.Login(ctx, email, password)
+	}
+
+	keys := s.failureKeys(ctx, email)
+	if s.captchaRequired(ctx, keys) {
+		if err := s.verify(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.next.Login(ctx, email, password)
+	if errors.Is(err, user.ErrInvalidCredentials) {
+		for _, key := range keys {
+			if _, recordErr := s.failures.RecordFailure(ctx, key, s.config.FailureWindow); recordErr != nil {
+				log.Printf("Failed to record failed login: %v", recordErr)
+			}
+		}
+	}
+	if err == nil {
+		if resetErr := s.failures.Reset(ctx, keys[0]); resetErr != nil {
+			log.Printf("Failed to reset failed logins: %v", resetErr)
+		}
+	}
+	return result, err
+}
+
+// Register checks a captcha unless registrations skip it
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	if !s.config.SkipRegister && !user.IsCaptchaExempt(ctx) {
+		if err := s.verify(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return s.next.Register(ctx, data)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile passes through
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword passes through; a wrong current password is not a failed login
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset passes through
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange passes through
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange passes through
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar passes through
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences passes through
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk passes through
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate passes through
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete passes through
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser passes through
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences passes through
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// failureKeys returns the keys failed logins are counted under: the email,
+// always first, and the client IP when it is known
+func (s *service) failureKeys(ctx context.Context, email string) []string {
+	keys := []string{"email:" + strings.ToLower(strings.TrimSpace(email))}
+	if ip := user.ClientIPFromContext(ctx); ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+// captchaRequired reports whether any key failed too often. A count that
+// cannot be read asks for a captcha rather than failing the login.
+func (s *service) captchaRequired(ctx context.Context, keys []string) bool {
+	for _, key := range keys {
+		attempts, err := s.failures.Attempts(ctx, key)
+		if err != nil {
+			log.Printf("Failed to read failed logins: %v", err)
+			return true
+		}
+		if attempts.Failures >= s.config.LoginFailures {
+			return true
+		}
+	}
+	return false
+}
+
+// verify checks the caller's captcha token
+func (s *service) verify(ctx context.Context) error {
+	token := user.CaptchaTokenFromContext(ctx)
+	if token == "" {
+		return captcha.ErrCaptchaRequired
+	}
+	return s.verifier.Verify(ctx, token, user.ClientIPFromContext(ctx))
+}
@@ -0,0 +1,127 @@
+package captcha_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	captchaMock "github.com/gentra/decorator-arch-go/internal/captcha/mock"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userCaptcha "github.com/gentra/decorator-arch-go/internal/user/captcha"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+const email = "jane@example.com"
+
+// newService wraps a user service accepting the password "correct", with a
+// captcha provider accepting only the token "solved"
+func newService(t *testing.T, config userCaptcha.Config) (user.Service, *userMock.MockUserService) {
+	t.Helper()
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, "correct").Return(testkit.NewUserBuilder().WithEmail(email).BuildAuthResult(), nil)
+	next.On("Login", mock.Anything, email, "wrong").Return(nil, user.ErrInvalidCredentials)
+	next.On("Register", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().WithEmail(email).Build(), nil)
+
+	verifier := &captchaMock.MockCaptchaService{}
+	verifier.On("Verify", mock.Anything, "solved", mock.Anything).Return(nil)
+	verifier.On("Verify", mock.Anything, mock.Anything, mock.Anything).Return(captcha.ErrCaptchaInvalid)
+
+	return userCaptcha.NewService(next, verifier, throttleMemory.NewService(), config), next
+}
+
+func withCaptcha(token string) context.Context {
+	return user.WithCaptchaToken(user.WithClientIP(context.Background(), "203.0.113.7"), token)
+}
+
+func TestRegister_GivenCaptchaToken_WhenRegistering_ThenRequiresSolvedCaptcha(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		config      userCaptcha.Config
+		expectedErr error
+	}{
+		{name: "Given a solved captcha, When registering, Then registers", token: "solved"},
+		{name: "Given no captcha, When registering, Then returns captcha required", expectedErr: captcha.ErrCaptchaRequired},
+		{name: "Given a rejected captcha, When registering, Then returns invalid captcha", token: "forged", expectedErr: captcha.ErrCaptchaInvalid},
+		{name: "Given registrations skip captchas, When registering without one, Then registers", config: userCaptcha.Config{SkipRegister: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, next := newService(t, tt.config)
+
+			// Act
+			_, err := service.Register(withCaptcha(tt.token), user.RegisterData{Email: email})
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				next.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLogin_GivenRepeatedFailures_WhenLoggingIn_ThenRequiresCaptchaUntilSuccess(t *testing.T) {
+	// Arrange
+	service, _ := newService(t, userCaptcha.Config{LoginFailures: 2})
+	for i := 0; i < 2; i++ {
+		_, err := service.Login(withCaptcha(""), email, "wrong")
+		require.ErrorIs(t, err, user.ErrInvalidCredentials)
+	}
+
+	// Act
+	_, withoutCaptchaErr := service.Login(withCaptcha(""), email, "correct")
+	_, withCaptchaErr := service.Login(withCaptcha("solved"), email, "correct")
+
+	// Assert
+	assert.ErrorIs(t, withoutCaptchaErr, captcha.ErrCaptchaRequired)
+	assert.NoError(t, withCaptchaErr)
+}
+
+func TestLogin_GivenFailuresFromOneIP_WhenLoggingInAsAnotherUser_ThenRequiresCaptcha(t *testing.T) {
+	// Arrange
+	service, next := newService(t, userCaptcha.Config{LoginFailures: 1})
+	next.On("Login", mock.Anything, "other@example.com", "wrong").Return(nil, user.ErrInvalidCredentials)
+	_, err := service.Login(withCaptcha(""), "other@example.com", "wrong")
+	require.ErrorIs(t, err, user.ErrInvalidCredentials)
+
+	// Act
+	_, err = service.Login(withCaptcha(""), email, "correct")
+
+	// Assert
+	assert.ErrorIs(t, err, captcha.ErrCaptchaRequired)
+}
+
+func TestLogin_GivenNoFailures_WhenLoggingIn_ThenNeedsNoCaptcha(t *testing.T) {
+	// Arrange
+	service, _ := newService(t, userCaptcha.DefaultConfig())
+
+	// Act
+	result, err := service.Login(withCaptcha(""), email, "correct")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, email, result.User.Email)
+}
+
+func TestRegister_GivenFederatedSignIn_WhenRegisteringWithoutCaptcha_ThenRegisters(t *testing.T) {
+	// Arrange
+	service, _ := newService(t, userCaptcha.DefaultConfig())
+	ctx := user.WithCaptchaExemption(context.Background())
+
+	// Act
+	_, err := service.Register(ctx, user.RegisterData{Email: email})
+
+	// Assert
+	assert.NoError(t, err)
+}
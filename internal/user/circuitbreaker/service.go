@@ -0,0 +1,462 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// State is the state of the circuit breaker
+type State int
+
+const (
+	StateClosed   State = iota // Calls flow to the next layer
+	StateOpen                  // Calls fail fast with user.ErrServiceUnavailable
+	StateHalfOpen              // A limited number of probe calls test recovery
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Config controls when the breaker trips and how it recovers
+type Config struct {
+	FailureThreshold int           // Consecutive failures that trip the breaker
+	OpenTimeout      time.Duration // Time spent open before probing
+	HalfOpenProbes   int           // Concurrent probe calls allowed while half-open
+}
+
+// DefaultConfig returns the default circuit breaker configuration
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// service implements user.Service with a circuit breaker around the next layer
+type service struct {
+	next   user.Service
+	config Config
+
+	mu               sync.Mutex
+	state            State
+	failures         int
+	openedAt         time.Time
+	probesInProgress int // Probes admitted and not yet released, whatever the state
+}
+
+// NewService creates a new circuit-breaking user service
+func NewService(next user.Service, config Config) user.Service {
+	defaults := DefaultConfig()
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+
+	return &service{
+		next:   next,
+		config: config,
+	}
+}
+
+// Register guards user registration with the circuit breaker
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.Register(ctx, data)
+	s.release(probe, err)
+	return result, err
+}
+
+// Login guards user login with the circuit breaker
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.Login(ctx, email, password)
+	s.release(probe, err)
+	return result, err
+}
+
+// GetByID guards user retrieval with the circuit breaker
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByID(ctx, id)
+	s.release(probe, err)
+	return result, err
+}
+
+// GetByIDs guards batch lookups with the circuit breaker
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByIDs(ctx, ids)
+	s.release(probe, err)
+	return result, err
+}
+
+// List guards user listing with the circuit breaker
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.List(ctx, filters)
+	s.release(probe, err)
+	return result, err
+}
+
+// UpdateProfile guards profile updates with the circuit breaker
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.UpdateProfile(ctx, id, data)
+	s.release(probe, err)
+	return result, err
+}
+
+// ChangePassword guards password changes with the circuit breaker
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	s.release(probe, err)
+	return err
+}
+
+// RequestPasswordReset guards password reset requests with the circuit breaker
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	s.release(probe, err)
+	return result, err
+}
+
+// ResetPassword guards password resets with the circuit breaker
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.ResetPassword(ctx, token, newPassword)
+	s.release(probe, err)
+	return err
+}
+
+// RequestEmailChange guards email change requests with the circuit breaker
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.RequestEmailChange(ctx, userID, newEmail)
+	s.release(probe, err)
+	return err
+}
+
+// ConfirmEmailChange guards email change confirmations with the circuit breaker
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	s.release(probe, err)
+	return result, err
+}
+
+// VerifyEmail guards email verifications with the circuit breaker
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.VerifyEmail(ctx, token)
+	s.release(probe, err)
+	return result, err
+}
+
+// UploadAvatar guards avatar uploads with the circuit breaker
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.UploadAvatar(ctx, userID, content, contentType)
+	s.release(probe, err)
+	return err
+}
+
+// GetAvatarURL guards avatar link requests with the circuit breaker
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := s.next.GetAvatarURL(ctx, userID)
+	s.release(probe, err)
+	return result, err
+}
+
+// GetPreferences guards preferences retrieval with the circuit breaker
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetPreferences(ctx, userID)
+	s.release(probe, err)
+	return result, err
+}
+
+// UpdatePreferences guards preferences updates with the circuit breaker
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.UpdatePreferences(ctx, userID, prefs)
+	s.release(probe, err)
+	return err
+}
+
+// UpdatePreferencesBulk guards bulk preference updates with the circuit breaker
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.UpdatePreferencesBulk(ctx, updates)
+	s.release(probe, err)
+	return err
+}
+
+// Deactivate guards deactivations with the circuit breaker
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.Deactivate(ctx, id)
+	s.release(probe, err)
+	return err
+}
+
+// Delete guards deletions with the circuit breaker
+func (s *service) Delete(ctx context.Context, id string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.Delete(ctx, id)
+	s.release(probe, err)
+	return err
+}
+
+// ExportUserData guards data exports with the circuit breaker
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.ExportUserData(ctx, userID)
+	s.release(probe, err)
+	return result, err
+}
+
+// EraseUser guards erasures with the circuit breaker
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.EraseUser(ctx, userID)
+	s.release(probe, err)
+	return err
+}
+
+// CleanupPreferences guards cleanup runs with the circuit breaker
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.next.CleanupPreferences(ctx, opts)
+	s.release(probe, err)
+	return report, err
+}
+
+// GetFeatureFlags guards feature flag retrieval with the circuit breaker
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	probe, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetFeatureFlags(ctx, userID)
+	s.release(probe, err)
+	return result, err
+}
+
+// SetFeatureFlag guards feature flag updates with the circuit breaker
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	probe, err := s.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+	s.release(probe, err)
+	return err
+}
+
+// Helper methods
+
+// acquire admits a call or fails fast while the breaker is open. probe
+// reports whether the call was admitted as a half-open probe and must be
+// passed back to release.
+func (s *service) acquire() (probe bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == StateOpen {
+		if time.Since(s.openedAt) < s.config.OpenTimeout {
+			return false, user.ErrServiceUnavailable
+		}
+		s.transition(StateHalfOpen)
+	}
+
+	if s.state == StateHalfOpen {
+		if s.probesInProgress >= s.config.HalfOpenProbes {
+			return false, user.ErrServiceUnavailable
+		}
+		s.probesInProgress++
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// release records the outcome of an admitted call. Only probes decide
+// whether a half-open breaker closes; calls admitted before the breaker
+// opened say nothing about recovery and are ignored until it closes.
+func (s *service) release(probe bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if probe {
+		s.probesInProgress--
+	}
+
+	switch s.state {
+	case StateOpen:
+		return
+	case StateHalfOpen:
+		if !probe {
+			return
+		}
+		if isFailure(err) {
+			s.trip()
+		} else {
+			s.transition(StateClosed)
+		}
+		return
+	}
+
+	if !isFailure(err) {
+		s.failures = 0
+		return
+	}
+
+	s.failures++
+	if s.failures >= s.config.FailureThreshold {
+		s.trip()
+	}
+}
+
+func (s *service) trip() {
+	s.transition(StateOpen)
+	s.openedAt = time.Now()
+}
+
+func (s *service) transition(state State) {
+	if s.state == state {
+		return
+	}
+
+	log.Printf("User service circuit breaker %s -> %s", s.state, state)
+	s.state = state
+	s.failures = 0
+}
+
+// isFailure reports whether an error indicates an unhealthy dependency.
+// Domain errors such as not found or invalid credentials are normal outcomes,
+// and cancelled requests say nothing about the next layer's health.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var userErr user.UserError
+	if errors.As(err, &userErr) {
+		return false
+	}
+
+	return !errors.Is(err, context.Canceled)
+}
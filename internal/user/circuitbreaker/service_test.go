@@ -0,0 +1,108 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/user/circuitbreaker"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+var errDatabaseDown = errors.New("connection refused")
+
+func TestCircuitBreaker_GivenConsecutiveFailures_WhenThresholdReached_ThenFailsFast(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("GetByID", mock.Anything, "user-1").Return(nil, errDatabaseDown)
+	service := circuitbreaker.NewService(next, circuitbreaker.Config{FailureThreshold: 3, OpenTimeout: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := service.GetByID(ctx, "user-1")
+		assert.ErrorIs(t, err, errDatabaseDown)
+	}
+
+	_, err := service.GetByID(ctx, "user-1")
+
+	assert.ErrorIs(t, err, user.ErrServiceUnavailable)
+	next.AssertNumberOfCalls(t, "GetByID", 3)
+}
+
+func TestCircuitBreaker_GivenDomainErrors_WhenCalling_ThenBreakerStaysClosed(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	service := circuitbreaker.NewService(next, circuitbreaker.Config{FailureThreshold: 2, OpenTimeout: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := service.Login(ctx, "a@example.com", "wrong")
+		assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+	}
+
+	next.AssertNumberOfCalls(t, "Login", 5)
+}
+
+func TestCircuitBreaker_GivenOpenBreaker_WhenTimeoutElapses_ThenProbesAndRecovers(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := circuitbreaker.NewService(next, circuitbreaker.Config{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	next.On("UpdatePreferences", mock.Anything, "user-1", mock.Anything).Return(errDatabaseDown).Once()
+	assert.ErrorIs(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}), errDatabaseDown)
+	assert.ErrorIs(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}), user.ErrServiceUnavailable)
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Failed probe re-opens the breaker
+	next.On("UpdatePreferences", mock.Anything, "user-1", mock.Anything).Return(errDatabaseDown).Once()
+	assert.ErrorIs(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}), errDatabaseDown)
+	assert.ErrorIs(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}), user.ErrServiceUnavailable)
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Successful probe closes it again
+	next.On("UpdatePreferences", mock.Anything, "user-1", mock.Anything).Return(nil)
+	assert.NoError(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}))
+	assert.NoError(t, service.UpdatePreferences(ctx, "user-1", user.UserPreferences{}))
+	next.AssertNumberOfCalls(t, "UpdatePreferences", 4)
+}
+
+func TestCircuitBreaker_GivenCallAdmittedBeforeTripping_WhenItSucceedsWhileHalfOpen_ThenOnlyTheProbeDecides(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := circuitbreaker.NewService(next, circuitbreaker.Config{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenProbes: 1})
+	ctx := context.Background()
+
+	started, finishSlow, finishProbe := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	next.On("GetByID", mock.Anything, "slow").Run(func(mock.Arguments) { started <- struct{}{}; <-finishSlow }).Return(&user.User{}, nil)
+	next.On("GetByID", mock.Anything, "probe").Run(func(mock.Arguments) { started <- struct{}{}; <-finishProbe }).Return(nil, errDatabaseDown)
+	next.On("GetByID", mock.Anything, "broken").Return(nil, errDatabaseDown)
+
+	slowDone := make(chan error)
+	go func() { _, err := service.GetByID(ctx, "slow"); slowDone <- err }()
+	<-started
+	_, err := service.GetByID(ctx, "broken")
+	assert.ErrorIs(t, err, errDatabaseDown)
+
+	time.Sleep(30 * time.Millisecond)
+	probeDone := make(chan error)
+	go func() { _, err := service.GetByID(ctx, "probe"); probeDone <- err }()
+	<-started
+
+	// The stale call neither closes the breaker nor frees the probe slot
+	close(finishSlow)
+	assert.NoError(t, <-slowDone)
+	_, err = service.GetByID(ctx, "user-1")
+	assert.ErrorIs(t, err, user.ErrServiceUnavailable)
+
+	// The failed probe re-opens it
+	close(finishProbe)
+	assert.ErrorIs(t, <-probeDone, errDatabaseDown)
+	_, err = service.GetByID(ctx, "user-1")
+	assert.ErrorIs(t, err, user.ErrServiceUnavailable)
+	next.AssertNotCalled(t, "GetByID", mock.Anything, "user-1")
+}
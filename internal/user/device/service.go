@@ -0,0 +1,227 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/device"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements user.Service with new-device login alerts
+// This decorator fingerprints the device of every successful login from the
+// client's device fingerprint and user agent carried in the context, and
+// remembers it in a device.Service. When a user who already has known
+// devices signs in from one that is not among them, they are emailed about
+// the sign-in. A user's first device is remembered without an alert. A device
+// sending neither a fingerprint nor a user agent cannot be told apart from any
+// other, so it is never recognized and alerts like a new one. The login
+// already succeeded, so store and notification errors are only logged.
+// Erasing a user forgets their devices; every other method passes through.
+type service struct {
+	next          user.Service
+	store         device.Service
+	notifications notification.Service
+}
+
+// NewService creates a new device alert decorator
+func NewService(next user.Service, store device.Service, notifications notification.Service) user.Service {
+	return &service{
+		next:          next,
+		store:         store,
+		notifications: notifications,
+	}
+}
+
+// Login remembers the device and alerts the user when it is a new one
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	result, err := s.next.Login(ctx, email, password)
+	if err != nil || result == nil || result.User == nil {
+		return result, err
+	}
+
+	s.recordDevice(ctx, result.User)
+	return result, nil
+}
+
+// Register passes through
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	return s.next.Register(ctx, data)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile passes through
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword passes through; a wrong current password is not a failed login
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset passes through
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange passes through
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange passes through
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar passes through
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences passes through
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk passes through
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate passes through
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete passes through
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser erases the user and then forgets their devices, which hold the
+// user agents and IP addresses they signed in from
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	if err := s.next.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// The user is already erased, so finish even if the caller went away
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	devices, err := s.store.List(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+	for _, d := range devices {
+		if err := s.store.Forget(ctx, userID, d.Fingerprint); err != nil {
+			return fmt.Errorf("failed to forget device: %w", err)
+		}
+	}
+	return nil
+}
+
+// CleanupPreferences passes through
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// recordDevice remembers the login's device and sends the alert when the
+// user had signed in from other devices but never from this one. A device
+// that cannot be identified is not remembered and always counts as new, so
+// stripping the fingerprint and user agent does not suppress the alert.
+func (s *service) recordDevice(ctx context.Context, u *user.User) {
+	userAgent := user.UserAgentFromContext(ctx)
+	fingerprint := device.Fingerprint(user.DeviceFingerprintFromContext(ctx), userAgent)
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	known, err := s.store.List(ctx, u.ID.String())
+	if err != nil {
+		log.Printf("Failed to list known devices: %v", err)
+		return
+	}
+
+	now := time.Now()
+	ip := user.ClientIPFromContext(ctx)
+	recognized := false
+	if fingerprint != "" {
+		recognized, err = s.store.Record(ctx, u.ID.String(), device.Device{
+			Fingerprint: fingerprint,
+			UserAgent:   userAgent,
+			IPAddress:   ip,
+			FirstSeen:   now,
+			LastSeen:    now,
+		})
+		if err != nil {
+			log.Printf("Failed to record login device: %v", err)
+			return
+		}
+	}
+	if recognized || len(known) == 0 {
+		return
+	}
+
+	login := notification.DeviceLogin{UserAgent: userAgent, IPAddress: ip, LoginAt: now}
+	if err := s.notifications.SendNewDeviceLoginEmail(notification.WithRecipient(ctx, u.ID.String()), u.Email, login); err != nil {
+		log.Printf("Failed to send new device login alert: %v", err)
+	}
+}
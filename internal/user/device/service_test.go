@@ -0,0 +1,171 @@
+package device_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/device"
+	deviceMemory "github.com/gentra/decorator-arch-go/internal/device/memory"
+	"github.com/gentra/decorator-arch-go/internal/notification/dryrun"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/outbox"
+	outboxMemory "github.com/gentra/decorator-arch-go/internal/outbox/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userDevice "github.com/gentra/decorator-arch-go/internal/user/device"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+const email = "jane@example.com"
+
+// newService wraps a user service whose logins succeed, capturing alerts in
+// the returned outbox
+func newService(t *testing.T) (user.Service, *userMock.MockUserService, device.Service, outbox.Service) {
+	t.Helper()
+	next := &userMock.MockUserService{}
+	result := testkit.NewUserBuilder().WithEmail(email).BuildAuthResult()
+	next.On("Login", mock.Anything, email, "correct").Return(result, nil)
+	next.On("Login", mock.Anything, email, "wrong").Return(nil, user.ErrInvalidCredentials)
+
+	store := deviceMemory.NewService()
+	box := outboxMemory.NewService(10)
+	notifications := dryrun.NewService(notificationMock.NewService(), box, dryrun.Config{Global: true})
+	return userDevice.NewService(next, store, notifications), next, store, box
+}
+
+func loginContext(fingerprint, userAgent, ip string) context.Context {
+	ctx := user.WithDeviceFingerprint(context.Background(), fingerprint)
+	ctx = user.WithUserAgent(ctx, userAgent)
+	return user.WithClientIP(ctx, ip)
+}
+
+func alerts(t *testing.T, box outbox.Service) []outbox.Message {
+	t.Helper()
+	messages, err := box.List(context.Background(), outbox.Filter{})
+	require.NoError(t, err)
+	return messages
+}
+
+func TestLogin_GivenFirstDevice_WhenLoggingIn_ThenRemembersItWithoutAlert(t *testing.T) {
+	// Arrange
+	service, _, store, box := newService(t)
+
+	// Act
+	result, err := service.Login(loginContext("fp-laptop", "Firefox", "203.0.113.7"), email, "correct")
+
+	// Assert
+	require.NoError(t, err)
+	devices, err := store.List(context.Background(), result.User.ID.String())
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "Firefox", devices[0].UserAgent)
+	assert.Equal(t, "203.0.113.7", devices[0].IPAddress)
+	assert.Empty(t, alerts(t, box))
+}
+
+func TestLogin_GivenKnownDevice_WhenLoggingInFromNewDevice_ThenAlertsUser(t *testing.T) {
+	// Arrange
+	service, _, _, box := newService(t)
+	_, err := service.Login(loginContext("fp-laptop", "Firefox", "203.0.113.7"), email, "correct")
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.Login(loginContext("fp-phone", "Safari on iOS", "198.51.100.2"), email, "correct")
+
+	// Assert
+	require.NoError(t, err)
+	messages := alerts(t, box)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "new_device_login_email", messages[0].Kind)
+	assert.Equal(t, email, messages[0].To)
+	assert.Contains(t, messages[0].Body, "Safari on iOS")
+	assert.Contains(t, messages[0].Body, "198.51.100.2")
+}
+
+func TestLogin_GivenKnownDevice_WhenLoggingInFromItAgain_ThenDoesNotAlert(t *testing.T) {
+	// Arrange
+	service, _, _, box := newService(t)
+	_, err := service.Login(loginContext("fp-laptop", "Firefox", "203.0.113.7"), email, "correct")
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.Login(loginContext("fp-laptop", "Firefox 2", "198.51.100.2"), email, "correct")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, alerts(t, box))
+}
+
+func TestLogin_GivenKnownDevice_WhenLoggingInFromUnidentifiableDevice_ThenAlertsEveryTime(t *testing.T) {
+	// Arrange
+	service, _, _, box := newService(t)
+	_, err := service.Login(loginContext("fp-laptop", "Firefox", "203.0.113.7"), email, "correct")
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < 2; i++ {
+		_, err = service.Login(loginContext("", "", "198.51.100.2"), email, "correct")
+		require.NoError(t, err)
+	}
+
+	// Assert
+	messages := alerts(t, box)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "new_device_login_email", messages[0].Kind)
+	assert.Contains(t, messages[0].Body, "198.51.100.2")
+}
+
+func TestEraseUser_GivenKnownDevices_WhenErasing_ThenForgetsThem(t *testing.T) {
+	// Arrange
+	service, next, store, _ := newService(t)
+	next.On("EraseUser", mock.Anything, testkit.DefaultUserID.String()).Return(nil)
+	_, err := service.Login(loginContext("fp-laptop", "Firefox", "203.0.113.7"), email, "correct")
+	require.NoError(t, err)
+	_, err = service.Login(loginContext("fp-phone", "Safari on iOS", "198.51.100.2"), email, "correct")
+	require.NoError(t, err)
+
+	// Act
+	err = service.EraseUser(context.Background(), testkit.DefaultUserID.String())
+
+	// Assert
+	require.NoError(t, err)
+	devices, err := store.List(context.Background(), testkit.DefaultUserID.String())
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+}
+
+func TestLogin_GivenUnidentifiableOrFailedLogin_WhenLoggingIn_ThenRecordsNothing(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		password    string
+		expectedErr error
+	}{
+		{name: "no fingerprint or user agent", ctx: loginContext("", "", "203.0.113.7"), password: "correct"},
+		{name: "wrong password", ctx: loginContext("fp-laptop", "Firefox", "203.0.113.7"), password: "wrong", expectedErr: user.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _, store, _ := newService(t)
+
+			// Act
+			_, err := service.Login(tt.ctx, email, tt.password)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			devices, err := store.List(context.Background(), testkit.DefaultUserID.String())
+			require.NoError(t, err)
+			assert.Empty(t, devices)
+		})
+	}
+}
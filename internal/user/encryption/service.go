@@ -2,7 +2,9 @@ package encryption
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/gentra/decorator-arch-go/internal/encryption"
 	"github.com/gentra/decorator-arch-go/internal/user"
@@ -10,6 +12,8 @@ import (
 
 // service implements user.Service with encryption capabilities
 // This decorator wraps another user.Service and encrypts/decrypts sensitive data
+// (email, first and last name) so the layers below only ever see ciphertext.
+// Emails must use a deterministic purpose so they can be looked up on login.
 type service struct {
 	next              user.Service
 	encryptionService encryption.Service
@@ -23,31 +27,24 @@ func NewService(next user.Service, encryptionService encryption.Service) user.Se
 	}
 }
 
-// Register creates a new user with sensitive data encryption
+// Register creates a new user with sensitive data encryption. The email is
+// checked under every key version first, since storage only sees the current
+// key's ciphertext and would accept an address registered before a rotation.
 func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
-	// Encrypt sensitive fields before storing
-	if data.Email != "" {
-		encryptedEmail, err := s.encryptionService.EncryptWithPurpose(ctx, data.Email, encryption.PurposeUserEmail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt email: %w", err)
-		}
-		data.Email = encryptedEmail
+	if err := s.ensureEmailAvailable(ctx, data.Email, ""); err != nil {
+		return nil, err
 	}
 
-	if data.FirstName != "" {
-		encryptedFirstName, err := s.encryptionService.EncryptWithPurpose(ctx, data.FirstName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt first name: %w", err)
-		}
-		data.FirstName = encryptedFirstName
+	// Encrypt sensitive fields before storing
+	var err error
+	if data.Email, err = s.encrypt(ctx, data.Email, encryption.PurposeUserEmail, "email"); err != nil {
+		return nil, err
 	}
-
-	if data.LastName != "" {
-		encryptedLastName, err := s.encryptionService.EncryptWithPurpose(ctx, data.LastName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt last name: %w", err)
-		}
-		data.LastName = encryptedLastName
+	if data.FirstName, err = s.encrypt(ctx, data.FirstName, encryption.PurposeUserName, "first name"); err != nil {
+		return nil, err
+	}
+	if data.LastName, err = s.encrypt(ctx, data.LastName, encryption.PurposeUserName, "last name"); err != nil {
+		return nil, err
 	}
 
 	// Call next service with encrypted data
@@ -56,72 +53,34 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 		return nil, err
 	}
 
-	// Decrypt sensitive fields after retrieval
-	if result.Email != "" {
-		decryptedEmail, err := s.encryptionService.DecryptWithPurpose(ctx, result.Email, encryption.PurposeUserEmail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt email: %w", err)
-		}
-		result.Email = decryptedEmail
-	}
-
-	if result.FirstName != "" {
-		decryptedFirstName, err := s.encryptionService.DecryptWithPurpose(ctx, result.FirstName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt first name: %w", err)
-		}
-		result.FirstName = decryptedFirstName
-	}
-
-	if result.LastName != "" {
-		decryptedLastName, err := s.encryptionService.DecryptWithPurpose(ctx, result.LastName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt last name: %w", err)
-		}
-		result.LastName = decryptedLastName
-	}
-
-	return result, nil
+	return s.decryptUser(ctx, result)
 }
 
 // Login authenticates a user (encrypt email for lookup)
 func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
-	// Encrypt email for lookup in the database
-	encryptedEmail, err := s.encryptionService.EncryptWithPurpose(ctx, email, encryption.PurposeUserEmail)
+	// The email may be stored under any key version that is still in the ring,
+	// newest first; older versions are only tried when no user matched
+	candidates, err := s.encryptionService.LookupCiphertexts(ctx, email, encryption.PurposeUserEmail)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt email for login: %w", err)
 	}
 
-	// Call next service with encrypted email
-	result, err := s.next.Login(ctx, encryptedEmail, password)
+	var result *user.AuthResult
+	err = user.ErrInvalidCredentials
+	for _, encryptedEmail := range candidates {
+		result, err = s.next.Login(ctx, encryptedEmail, password)
+		if !errors.Is(err, user.ErrInvalidCredentials) {
+			break
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Decrypt user data in the result if present
 	if result.User != nil {
-		if result.User.Email != "" {
-			decryptedEmail, err := s.encryptionService.DecryptWithPurpose(ctx, result.User.Email, encryption.PurposeUserEmail)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt email: %w", err)
-			}
-			result.User.Email = decryptedEmail
-		}
-
-		if result.User.FirstName != "" {
-			decryptedFirstName, err := s.encryptionService.DecryptWithPurpose(ctx, result.User.FirstName, encryption.PurposeUserName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt first name: %w", err)
-			}
-			result.User.FirstName = decryptedFirstName
-		}
-
-		if result.User.LastName != "" {
-			decryptedLastName, err := s.encryptionService.DecryptWithPurpose(ctx, result.User.LastName, encryption.PurposeUserName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt last name: %w", err)
-			}
-			result.User.LastName = decryptedLastName
+		if result.User, err = s.decryptUser(ctx, result.User); err != nil {
+			return nil, err
 		}
 	}
 
@@ -136,63 +95,65 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 		return nil, err
 	}
 
-	if result == nil {
-		return nil, nil
+	return s.decryptUser(ctx, result)
+}
+
+// GetByIDs retrieves several users and decrypts sensitive data
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	// Call next service
+	result, err := s.next.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
 	}
 
-	// Decrypt sensitive fields after retrieval
-	if result.Email != "" {
-		decryptedEmail, err := s.encryptionService.DecryptWithPurpose(ctx, result.Email, encryption.PurposeUserEmail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt email: %w", err)
+	for id, u := range result {
+		if result[id], err = s.decryptUser(ctx, u); err != nil {
+			return nil, err
 		}
-		result.Email = decryptedEmail
 	}
+	return result, nil
+}
 
-	if result.FirstName != "" {
-		decryptedFirstName, err := s.encryptionService.DecryptWithPurpose(ctx, result.FirstName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt first name: %w", err)
-		}
-		result.FirstName = decryptedFirstName
+// List retrieves a page of users and decrypts sensitive data. Emails and names
+// are stored encrypted, so they can be neither searched nor sorted on.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if err := filters.EncryptedFieldsError(); err != nil {
+		return nil, err
 	}
 
-	if result.LastName != "" {
-		decryptedLastName, err := s.encryptionService.DecryptWithPurpose(ctx, result.LastName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt last name: %w", err)
-		}
-		result.LastName = decryptedLastName
+	// Call next service
+	result, err := s.next.List(ctx, filters)
+	if err != nil {
+		return nil, err
 	}
 
+	for i, u := range result.Users {
+		if result.Users[i], err = s.decryptUser(ctx, u); err != nil {
+			return nil, err
+		}
+	}
 	return result, nil
 }
 
 // UpdateProfile updates user profile with encryption
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
-	// Encrypt sensitive fields before updating
-	if data.Email != nil && *data.Email != "" {
-		encryptedEmail, err := s.encryptionService.EncryptWithPurpose(ctx, *data.Email, encryption.PurposeUserEmail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt email: %w", err)
+	if data.Email != nil {
+		if err := s.ensureEmailAvailable(ctx, *data.Email, id); err != nil {
+			return nil, err
 		}
-		data.Email = &encryptedEmail
 	}
 
-	if data.FirstName != nil && *data.FirstName != "" {
-		encryptedFirstName, err := s.encryptionService.EncryptWithPurpose(ctx, *data.FirstName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt first name: %w", err)
-		}
-		data.FirstName = &encryptedFirstName
+	// Encrypt sensitive fields before updating; changed values are always
+	// written with the current key
+	var err error
+	if data.Email, err = s.encryptOptional(ctx, data.Email, encryption.PurposeUserEmail, "email"); err != nil {
+		return nil, err
 	}
-
-	if data.LastName != nil && *data.LastName != "" {
-		encryptedLastName, err := s.encryptionService.EncryptWithPurpose(ctx, *data.LastName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt last name: %w", err)
-		}
-		data.LastName = &encryptedLastName
+	if data.FirstName, err = s.encryptOptional(ctx, data.FirstName, encryption.PurposeUserName, "first name"); err != nil {
+		return nil, err
+	}
+	if data.LastName, err = s.encryptOptional(ctx, data.LastName, encryption.PurposeUserName, "last name"); err != nil {
+		return nil, err
 	}
 
 	// Call next service with encrypted data
@@ -201,36 +162,85 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 		return nil, err
 	}
 
-	if result == nil {
-		return nil, nil
+	return s.decryptUser(ctx, result)
+}
+
+// ChangePassword changes a user's password (no encryption needed; passwords are hashed)
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset looks the email up under every key version that is
+// still in the ring, like Login, and decrypts the user found
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	candidates, err := s.encryptionService.LookupCiphertexts(ctx, email, encryption.PurposeUserEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt email for password reset: %w", err)
 	}
 
-	// Decrypt sensitive fields after retrieval
-	if result.Email != "" {
-		decryptedEmail, err := s.encryptionService.DecryptWithPurpose(ctx, result.Email, encryption.PurposeUserEmail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt email: %w", err)
+	var result *user.User
+	err = user.ErrUserNotFound
+	for _, encryptedEmail := range candidates {
+		result, err = s.next.RequestPasswordReset(ctx, encryptedEmail)
+		if !errors.Is(err, user.ErrUserNotFound) {
+			break
 		}
-		result.Email = decryptedEmail
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if result.FirstName != "" {
-		decryptedFirstName, err := s.encryptionService.DecryptWithPurpose(ctx, result.FirstName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt first name: %w", err)
-		}
-		result.FirstName = decryptedFirstName
+	return s.decryptUser(ctx, result)
+}
+
+// ResetPassword passes through; passwords are hashed, not encrypted
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange checks the new email under every key version, like
+// Register, and encrypts the pending email before it is stored
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	if err := s.ensureEmailAvailable(ctx, newEmail, userID); err != nil {
+		return err
 	}
 
-	if result.LastName != "" {
-		decryptedLastName, err := s.encryptionService.DecryptWithPurpose(ctx, result.LastName, encryption.PurposeUserName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt last name: %w", err)
-		}
-		result.LastName = decryptedLastName
+	encryptedEmail, err := s.encrypt(ctx, newEmail, encryption.PurposeUserEmail, "email")
+	if err != nil {
+		return err
 	}
 
-	return result, nil
+	return s.next.RequestEmailChange(ctx, userID, encryptedEmail)
+}
+
+// ConfirmEmailChange applies the pending email and decrypts the updated user
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decryptUser(ctx, result)
+}
+
+// VerifyEmail marks the user's email verified and decrypts the updated user
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	result, err := s.next.VerifyEmail(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decryptUser(ctx, result)
+}
+
+// UploadAvatar stores a new avatar (no encryption needed; images are not personal text fields)
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL returns a link to the avatar (no encryption needed)
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
 }
 
 // GetPreferences retrieves user preferences (no encryption needed for preferences)
@@ -246,3 +256,140 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 	// Just pass through to next service
 	return s.next.UpdatePreferences(ctx, userID, prefs)
 }
+
+// UpdatePreferencesBulk updates several users' preferences (no encryption needed for preferences)
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate deactivates a user (no encryption needed)
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete soft deletes a user (no encryption needed)
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData exports a user's data with the profile decrypted
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	export, err := s.next.ExportUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Profile, err = s.decryptUser(ctx, export.Profile); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// EraseUser erases a user's personal data (no encryption needed)
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences cleans up stale notification types (no encryption needed)
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags retrieves the user's feature flags (no encryption needed)
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag switches a feature flag for the user (no encryption needed)
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// ensureEmailAvailable returns ErrEmailAlreadyExists when a user other than
+// exceptUserID has the email stored under any key version still in the ring
+func (s *service) ensureEmailAvailable(ctx context.Context, email, exceptUserID string) error {
+	if email == "" {
+		return nil
+	}
+
+	candidates, err := s.encryptionService.LookupCiphertexts(ctx, email, encryption.PurposeUserEmail)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt email for uniqueness check: %w", err)
+	}
+
+	for _, encryptedEmail := range candidates {
+		page, err := s.next.List(ctx, user.ListFilters{EmailPrefix: encryptedEmail, Limit: user.MaxListLimit})
+		if err != nil {
+			return fmt.Errorf("failed to check email uniqueness: %w", err)
+		}
+		for _, u := range page.Users {
+			if u.Email == encryptedEmail && u.ID.String() != exceptUserID {
+				return user.ErrEmailAlreadyExists
+			}
+		}
+	}
+	return nil
+}
+
+// encrypt encrypts a non-empty field for the given purpose
+func (s *service) encrypt(ctx context.Context, value, purpose, field string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	encrypted, err := s.encryptionService.EncryptWithPurpose(ctx, value, purpose)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+	return encrypted, nil
+}
+
+// encryptOptional encrypts an optional update field, leaving nil and empty values untouched
+func (s *service) encryptOptional(ctx context.Context, value *string, purpose, field string) (*string, error) {
+	if value == nil || *value == "" {
+		return value, nil
+	}
+
+	encrypted, err := s.encrypt(ctx, *value, purpose, field)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// decrypt decrypts a non-empty field for the given purpose
+func (s *service) decrypt(ctx context.Context, value, purpose, field string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	decrypted, err := s.encryptionService.DecryptWithPurpose(ctx, value, purpose)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return decrypted, nil
+}
+
+// decryptUser decrypts the sensitive fields of a user returned by the next layer
+func (s *service) decryptUser(ctx context.Context, u *user.User) (*user.User, error) {
+	if u == nil {
+		return nil, nil
+	}
+
+	var err error
+	if u.Email, err = s.decrypt(ctx, u.Email, encryption.PurposeUserEmail, "email"); err != nil {
+		return nil, err
+	}
+	if u.PendingEmail, err = s.decrypt(ctx, u.PendingEmail, encryption.PurposeUserEmail, "pending email"); err != nil {
+		return nil, err
+	}
+	if u.FirstName, err = s.decrypt(ctx, u.FirstName, encryption.PurposeUserName, "first name"); err != nil {
+		return nil, err
+	}
+	if u.LastName, err = s.decrypt(ctx, u.LastName, encryption.PurposeUserName, "last name"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
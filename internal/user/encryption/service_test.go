@@ -0,0 +1,195 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/encryption"
+	encryptionKeyRing "github.com/gentra/decorator-arch-go/internal/encryption/keyring"
+	"github.com/gentra/decorator-arch-go/internal/keyring"
+	keyRingMemory "github.com/gentra/decorator-arch-go/internal/keyring/memory"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userEncryption "github.com/gentra/decorator-arch-go/internal/user/encryption"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+func newEncryptionService(t *testing.T) encryption.Service {
+	t.Helper()
+	ring, err := keyRingMemory.NewService(bytes.Repeat([]byte{3}, keyring.KeySize))
+	require.NoError(t, err)
+	return encryptionKeyRing.NewService(ring, encryptionKeyRing.DefaultConfig())
+}
+
+func TestEncryption_GivenRegistration_WhenRegistering_ThenNextLayerOnlySeesCiphertext(t *testing.T) {
+	next := &userMock.MockUserService{}
+	encryptionSvc := newEncryptionService(t)
+	service := userEncryption.NewService(next, encryptionSvc)
+
+	var stored user.RegisterData
+	created := &user.User{}
+	next.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	next.On("Register", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).(user.RegisterData)
+			created.Email, created.FirstName, created.LastName = stored.Email, stored.FirstName, stored.LastName
+		}).
+		Return(created, nil)
+
+	result, err := service.Register(context.Background(), user.RegisterData{
+		Email: "jane@example.com", Password: "secret", FirstName: "Jane", LastName: "Doe",
+	})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, "jane@example.com", stored.Email)
+	assert.NotEqual(t, "Jane", stored.FirstName)
+	assert.NotEqual(t, "Doe", stored.LastName)
+	assert.Equal(t, "secret", stored.Password, "passwords are hashed by storage, not encrypted")
+	assert.Equal(t, "jane@example.com", result.Email)
+	assert.Equal(t, "Jane Doe", result.GetFullName())
+}
+
+func TestEncryption_GivenEmailChangeRequest_WhenRequesting_ThenStoresPendingEmailAsCiphertext(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userEncryption.NewService(next, newEncryptionService(t))
+
+	stored := &user.User{}
+	next.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+	next.On("RequestEmailChange", mock.Anything, "user-1", mock.Anything).
+		Run(func(args mock.Arguments) { stored.PendingEmail = args.String(2) }).
+		Return(nil)
+	next.On("GetByID", mock.Anything, "user-1").Return(stored, nil)
+
+	require.NoError(t, service.RequestEmailChange(context.Background(), "user-1", "jane.new@example.com"))
+	assert.NotEqual(t, "jane.new@example.com", stored.PendingEmail)
+
+	found, err := service.GetByID(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "jane.new@example.com", found.PendingEmail)
+}
+
+func TestEncryption_GivenRotatedEmailKey_WhenLoggingIn_ThenFindsUserStoredUnderOldKey(t *testing.T) {
+	next := &userMock.MockUserService{}
+	encryptionSvc := newEncryptionService(t)
+	service := userEncryption.NewService(next, encryptionSvc)
+	ctx := context.Background()
+
+	storedEmail, err := encryptionSvc.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	require.NoError(t, err)
+	require.NoError(t, encryptionSvc.RotateKeyForPurpose(encryption.PurposeUserEmail))
+
+	next.On("Login", mock.Anything, storedEmail, "secret").
+		Return(&user.AuthResult{User: &user.User{Email: storedEmail}}, nil)
+	next.On("Login", mock.Anything, mock.Anything, "secret").
+		Return(nil, user.ErrInvalidCredentials)
+
+	result, err := service.Login(ctx, "jane@example.com", "secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", result.User.Email)
+	next.AssertNumberOfCalls(t, "Login", 2)
+}
+
+func TestEncryption_GivenEmailStoredUnderOldKey_WhenClaimingIt_ThenReturnsErrEmailAlreadyExists(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim func(service user.Service) error
+	}{
+		{
+			name: "Given email stored under old key, When registering, Then should return ErrEmailAlreadyExists",
+			claim: func(service user.Service) error {
+				_, err := service.Register(context.Background(), user.RegisterData{Email: "jane@example.com", Password: "secret"})
+				return err
+			},
+		},
+		{
+			name: "Given email stored under old key, When requesting an email change, Then should return ErrEmailAlreadyExists",
+			claim: func(service user.Service) error {
+				return service.RequestEmailChange(context.Background(), "00000000-0000-0000-0000-000000000002", "jane@example.com")
+			},
+		},
+		{
+			name: "Given email stored under old key, When updating the profile email, Then should return ErrEmailAlreadyExists",
+			claim: func(service user.Service) error {
+				email := "jane@example.com"
+				_, err := service.UpdateProfile(context.Background(), "00000000-0000-0000-0000-000000000002", user.UpdateProfileData{Email: &email})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := &userMock.MockUserService{}
+			encryptionSvc := newEncryptionService(t)
+			service := userEncryption.NewService(next, encryptionSvc)
+			ctx := context.Background()
+
+			storedEmail, err := encryptionSvc.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+			require.NoError(t, err)
+			require.NoError(t, encryptionSvc.RotateKeyForPurpose(encryption.PurposeUserEmail))
+
+			existing := &user.User{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Email: storedEmail}
+			next.On("List", mock.Anything, user.ListFilters{EmailPrefix: storedEmail, Limit: user.MaxListLimit}).
+				Return(&user.Page{Users: []*user.User{existing}, Total: 1}, nil)
+			next.On("List", mock.Anything, mock.Anything).Return(&user.Page{}, nil)
+
+			// Act
+			err = tt.claim(service)
+
+			// Assert
+			assert.ErrorIs(t, err, user.ErrEmailAlreadyExists)
+			next.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+			next.AssertNotCalled(t, "RequestEmailChange", mock.Anything, mock.Anything, mock.Anything)
+			next.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestEncryption_GivenUnknownEmail_WhenLoggingIn_ThenReturnsInvalidCredentials(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userEncryption.NewService(next, newEncryptionService(t))
+	next.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+
+	_, err := service.Login(context.Background(), "nobody@example.com", "secret")
+
+	assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+}
+
+func TestEncryption_GivenEmailSearch_WhenListing_ThenRejectsFilterOnCiphertext(t *testing.T) {
+	next := &userMock.MockUserService{}
+	service := userEncryption.NewService(next, newEncryptionService(t))
+
+	_, err := service.List(context.Background(), user.ListFilters{EmailPrefix: "jane"})
+
+	assert.ErrorIs(t, err, user.ErrInvalidListFilter)
+	next.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestEncryption_GivenStoredUsers_WhenListing_ThenDecryptsEveryUser(t *testing.T) {
+	next := &userMock.MockUserService{}
+	encryptionSvc := newEncryptionService(t)
+	service := userEncryption.NewService(next, encryptionSvc)
+	ctx := context.Background()
+
+	storedEmail, err := encryptionSvc.EncryptWithPurpose(ctx, "jane@example.com", encryption.PurposeUserEmail)
+	require.NoError(t, err)
+	storedName, err := encryptionSvc.EncryptWithPurpose(ctx, "Jane", encryption.PurposeUserName)
+	require.NoError(t, err)
+	next.On("List", mock.Anything, user.ListFilters{}).
+		Return(&user.Page{Users: []*user.User{{Email: storedEmail, FirstName: storedName}}, Total: 1}, nil)
+
+	page, err := service.List(ctx, user.ListFilters{})
+
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "jane@example.com", page.Users[0].Email)
+	assert.Equal(t, "Jane", page.Users[0].FirstName)
+}
@@ -4,21 +4,49 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/authorization/rbac"
+	"github.com/gentra/decorator-arch-go/internal/captcha"
+	"github.com/gentra/decorator-arch-go/internal/device"
 	"github.com/gentra/decorator-arch-go/internal/encryption"
 	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+	"github.com/gentra/decorator-arch-go/internal/lockout"
 	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
 	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	"github.com/gentra/decorator-arch-go/internal/throttle"
 	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/user"
 	userAudit "github.com/gentra/decorator-arch-go/internal/user/audit"
+	userAuthorization "github.com/gentra/decorator-arch-go/internal/user/authorization"
+	userCaptcha "github.com/gentra/decorator-arch-go/internal/user/captcha"
+	userCircuitBreaker "github.com/gentra/decorator-arch-go/internal/user/circuitbreaker"
+	userDevice "github.com/gentra/decorator-arch-go/internal/user/device"
 	userEncryption "github.com/gentra/decorator-arch-go/internal/user/encryption"
 	userGorm "github.com/gentra/decorator-arch-go/internal/user/gorm"
+	userIdempotency "github.com/gentra/decorator-arch-go/internal/user/idempotency"
+	userLockout "github.com/gentra/decorator-arch-go/internal/user/lockout"
+	"github.com/gentra/decorator-arch-go/internal/user/memorycache"
+	userMetrics "github.com/gentra/decorator-arch-go/internal/user/metrics"
+	userPostgres "github.com/gentra/decorator-arch-go/internal/user/postgres"
 	userRateLimit "github.com/gentra/decorator-arch-go/internal/user/ratelimit"
 	userRedis "github.com/gentra/decorator-arch-go/internal/user/redis"
+	userStepUp "github.com/gentra/decorator-arch-go/internal/user/stepup"
+	userTiming "github.com/gentra/decorator-arch-go/internal/user/timing"
+	userTracing "github.com/gentra/decorator-arch-go/internal/user/tracing"
+	userTransaction "github.com/gentra/decorator-arch-go/internal/user/transaction"
 	"github.com/gentra/decorator-arch-go/internal/user/usecase"
 	userValidation "github.com/gentra/decorator-arch-go/internal/user/validation"
 	"github.com/gentra/decorator-arch-go/internal/validation"
@@ -26,13 +54,104 @@ import (
 
 // Config contains all configuration for building the user service
 type Config struct {
+	// Storage backend: "gorm" (default) stores users through DB, "postgres"
+	// through the pgx Pool, and "sqlite" through a DB opened with sqlite.Open
+	StorageProvider string
+
 	// Database configuration
-	DB *gorm.DB
+	DB   *gorm.DB
+	Pool *pgxpool.Pool
+
+	// Recent passwords ChangePassword refuses to reuse; zero uses user.DefaultPasswordHistorySize
+	PasswordHistorySize int
+
+	// Hashes passwords on registration and verifies them on login, rehashing
+	// outdated hashes; nil uses the default hash service
+	PasswordHasher hash.Service
+
+	// Blob storage for avatar images; avatar uploads fail without it.
+	// Signed avatar links stay valid for AvatarURLExpiry, zero uses storage.DefaultURLExpiry.
+	AvatarStorage   storage.Service
+	AvatarURLExpiry time.Duration
+
+	// Cache backend: "redis" (default), "memory" for deployments without
+	// Redis, "tiered" for an in-process cache in front of Redis, or "none"
+	CacheProvider string
 
 	// Redis configuration
 	RedisClient *redis.Client
 	CacheTTL    time.Duration
 
+	// Entries kept by the memory cache; zero uses memorycache.DefaultMaxEntries
+	MemoryCacheSize int
+
+	// TTL of the in-process tier of the "tiered" cache; zero uses 30 seconds.
+	// Instances drop each other's changed users through Redis pub/sub on
+	// CacheInvalidationChannel (zero uses redis.DefaultInvalidationChannel).
+	L1CacheTTL               time.Duration
+	CacheInvalidationChannel string
+
+	// Per-method cache TTLs; zero values fall back to CacheTTL
+	UserCacheTTL         time.Duration
+	PreferencesCacheTTL  time.Duration
+	FeatureFlagsCacheTTL time.Duration
+
+	// TTL for cached List pages; zero uses the short redis.DefaultListCacheTTL
+	// rather than CacheTTL because pages are not invalidated on writes
+	ListCacheTTL time.Duration
+
+	// TTL for remembering that a user ID does not exist; zero disables
+	// negative caching
+	NotFoundCacheTTL time.Duration
+
+	// DisableCacheWriteThrough invalidates cached users on Register and
+	// UpdateProfile instead of caching the returned user
+	DisableCacheWriteThrough bool
+
+	// UserCacheTTLOverride picks a TTL for individual cached users; zero
+	// results fall back to UserCacheTTL
+	UserCacheTTLOverride func(u *user.User) time.Duration
+
+	// DisableCacheCoalescing lets concurrent cache misses for the same user
+	// each reach the database instead of sharing one load
+	DisableCacheCoalescing bool
+
+	// Per-user and per-IP limits for Register and Login
+	RateLimit userRateLimit.Config
+
+	// Circuit breaker protecting callers from storage and cache outages
+	CircuitBreaker userCircuitBreaker.Config
+
+	// Replay window and lock of idempotency keys; zero values use the
+	// userIdempotency defaults
+	Idempotency userIdempotency.Config
+
+	// Failed logins before emails and IPs are locked, and for how long;
+	// zero values use the userLockout defaults
+	Lockout userLockout.Config
+
+	// Failed logins after which Login needs a captcha, and whether Register
+	// does; zero values use the userCaptcha defaults
+	Captcha userCaptcha.Config
+
+	// How recently callers must have signed in to change their password or
+	// email or erase their account; zero uses the userStepUp default
+	StepUp userStepUp.Config
+
+	// How long after registering users can sign in without verifying their
+	// email; zero never blocks unverified users
+	EmailVerificationGracePeriod time.Duration
+
+	// DisableWelcomeEmail leaves the welcome email to the welcome-email
+	// event handler
+	DisableWelcomeEmail bool
+
+	// Registerer for user service metrics; nil uses prometheus.DefaultRegisterer
+	MetricsRegisterer prometheus.Registerer
+
+	// Tracer provider for user service spans; nil uses the global provider
+	TracerProvider trace.TracerProvider
+
 	// Domain services - these replace the old interfaces
 	AuditService        audit.Service
 	EncryptionService   encryption.Service
@@ -42,6 +161,35 @@ type Config struct {
 	TokenService        token.Service
 	EventsService       events.Service
 
+	// Notification history exported with and purged on erasure of a user's
+	// data; nil exports the history of NotificationService
+	NotificationHistory notificationhistory.Service
+
+	// Cleans input, e.g. trimming names and canonicalizing time zones, before
+	// the validation layer checks it; nil leaves input as it is
+	Sanitizer sanitize.Service
+
+	// Store for idempotency keys: memory for single instances, Redis or
+	// Postgres when several instances share the keys
+	IdempotencyService idempotency.Service
+
+	// Store for failed logins and locks: memory for single instances, Redis
+	// when several instances must see the same locks
+	LockoutService lockout.Service
+
+	// Store for the devices users signed in from: memory for single
+	// instances, Redis when several instances must recognize the same devices
+	DeviceService device.Service
+
+	// Captcha provider checking the tokens of registrations and suspicious
+	// logins, and the store counting failed logins for it: memory for single
+	// instances, Redis when several instances must see the same failures
+	CaptchaService  captcha.Service
+	CaptchaFailures throttle.Service
+
+	// Policy engine for profile and preference changes; nil uses the default RBAC policy
+	AuthorizationService authorization.Service
+
 	// Feature flags
 	Features FeatureFlags
 }
@@ -51,27 +199,52 @@ type Config struct {
 
 // FeatureFlags controls which layers are enabled
 type FeatureFlags struct {
-	EnableCache      bool
-	EnableAudit      bool
-	EnableRateLimit  bool
-	EnableEncryption bool
-	EnableValidation bool
+	EnableCache          bool
+	EnableCircuitBreaker bool
+	EnableAudit          bool
+	EnableRateLimit      bool
+	EnableEncryption     bool
+	EnableValidation     bool
+	EnableAuthorization  bool // Requires callers to be stored with authorization.WithSubject
+	EnableIdempotency    bool // Replays results for requests that carry user.WithIdempotencyKey
+	EnableLockout        bool // Locks accounts after repeated failed logins
+	EnableDeviceAlerts   bool // Emails users when they sign in from an unrecognized device
+	EnableCaptcha        bool // Requires captcha tokens stored with user.WithCaptchaToken
+	EnableStepUp         bool // Requires callers to be stored with auth.WithAuthentication
+	EnableTransactions   bool // Commits changes and their outbox events together; requires the postgres storage
+	EnableTiming         bool // Per-layer timings for requests that opt in via user.WithTimings
+	EnableMetrics        bool
+	EnableTracing        bool
 }
 
 // DefaultFeatureFlags returns default feature flag configuration
 func DefaultFeatureFlags() FeatureFlags {
 	return FeatureFlags{
-		EnableCache:      true,
-		EnableAudit:      true,
-		EnableRateLimit:  true,
-		EnableEncryption: false, // Disabled by default for demo purposes
-		EnableValidation: true,
+		EnableCache:          true,
+		EnableCircuitBreaker: true,
+		EnableAudit:          true,
+		EnableRateLimit:      true,
+		EnableEncryption:     false, // Disabled by default for demo purposes
+		EnableValidation:     true,
+		EnableAuthorization:  true,
+		EnableIdempotency:    false, // Requires an idempotency store
+		EnableLockout:        false, // Requires a lockout store
+		EnableDeviceAlerts:   false, // Requires a device store
+		EnableCaptcha:        false, // Requires a captcha provider
+		EnableStepUp:         false, // Requires tokens carrying auth_time
+		EnableTransactions:   false, // Requires the postgres storage and an event outbox
+		EnableTiming:         true,
+		EnableMetrics:        false, // Requires a metrics endpoint to be useful
+		EnableTracing:        true,  // No-op until a tracer provider is installed
 	}
 }
 
 // UserServiceFactory creates and assembles the complete user service decorator chain
 type UserServiceFactory struct {
 	config Config
+
+	// lockout is the lockout layer of the last built chain, if enabled
+	lockout userLockout.Service
 }
 
 // NewUserServiceFactory creates a new factory with the given configuration
@@ -83,11 +256,17 @@ func NewUserServiceFactory(config Config) *UserServiceFactory {
 
 // Build assembles and returns the complete user service with all enabled decorators
 func (f *UserServiceFactory) Build() (user.Service, error) {
+	// Validate that every enabled layer has its dependencies
+	if err := f.validateConfig(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Start with the storage layer (GORM)
 	service, err := f.buildStorageLayer()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build storage layer: %w", err)
 	}
+	service = f.addTiming(service, "db")
 
 	// Add cache layer if enabled
 	if f.config.Features.EnableCache {
@@ -95,40 +274,111 @@ func (f *UserServiceFactory) Build() (user.Service, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to add cache layer: %w", err)
 		}
+		service = f.addTiming(service, "cache")
+	}
+
+	// Add circuit breaker layer if enabled
+	if f.config.Features.EnableCircuitBreaker {
+		service = f.addTiming(f.addCircuitBreakerLayer(service), "circuitbreaker")
 	}
 
 	// Add audit layer if enabled
 	if f.config.Features.EnableAudit {
-		service = f.addAuditLayer(service)
+		service = f.addTiming(f.addAuditLayer(service), "audit")
 	}
 
-	// Add rate limiting layer if enabled
+	// Add encryption layer if enabled
+	if f.config.Features.EnableEncryption {
+		service, err = f.addEncryptionLayer(service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add encryption layer: %w", err)
+		}
+		service = f.addTiming(service, "encryption")
+	}
+
+	// Add rate limiting layer if enabled; outside encryption so each login
+	// is limited once, by the plain email rather than every key version's
+	// ciphertext
 	if f.config.Features.EnableRateLimit {
 		service, err = f.addRateLimitLayer(service)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add rate limit layer: %w", err)
 		}
+		service = f.addTiming(service, "ratelimit")
 	}
 
-	// Add encryption layer if enabled
-	if f.config.Features.EnableEncryption {
-		service, err = f.addEncryptionLayer(service)
-		if err != nil {
-			return nil, fmt.Errorf("failed to add encryption layer: %w", err)
-		}
+	// Add lockout layer if enabled; outside encryption so each login is
+	// counted once, against the plain email
+	if f.config.Features.EnableLockout {
+		f.lockout = f.addLockoutLayer(service)
+		service = f.addTiming(f.lockout, "lockout")
+	}
+
+	// Add device alert layer if enabled; outside lockout and encryption so
+	// only logins that succeeded are recorded, with the plain email
+	if f.config.Features.EnableDeviceAlerts {
+		service = f.addTiming(f.addDeviceLayer(service), "device")
+	}
+
+	// Add captcha layer if enabled; outside lockout so logins refused for a
+	// missing captcha are not counted as failed logins
+	if f.config.Features.EnableCaptcha {
+		service = f.addTiming(f.addCaptchaLayer(service), "captcha")
 	}
 
 	// Add validation layer if enabled
 	if f.config.Features.EnableValidation {
-		service = f.addValidationLayer(service)
+		service = f.addTiming(f.addValidationLayer(service), "validation")
 	}
 
 	// Add usecase layer (business logic) - always enabled
-	service = f.addUseCaseLayer(service)
+	service = f.addTiming(f.addUseCaseLayer(service), "usecase")
+
+	// Add transaction layer if enabled; outside the business logic so the
+	// events it publishes commit with the change
+	if f.config.Features.EnableTransactions {
+		service = f.addTiming(f.addTransactionLayer(service), "transaction")
+	}
+
+	// Add idempotency layer if enabled; outside the business logic so replayed
+	// requests send no second notification or event
+	if f.config.Features.EnableIdempotency {
+		service = f.addTiming(f.addIdempotencyLayer(service), "idempotency")
+	}
+
+	// Add step-up layer if enabled; outside idempotency so a stale sign-in is
+	// never replayed as the result of a retried request
+	if f.config.Features.EnableStepUp {
+		service = f.addTiming(f.addStepUpLayer(service), "stepup")
+	}
+
+	// Add authorization layer if enabled; outside the business logic so denied calls do no work
+	if f.config.Features.EnableAuthorization {
+		service = f.addTiming(f.addAuthorizationLayer(service), "authorization")
+	}
+
+	// Add metrics layer if enabled; outermost so it measures the whole chain
+	if f.config.Features.EnableMetrics {
+		service, err = f.addMetricsLayer(service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add metrics layer: %w", err)
+		}
+	}
+
+	// Add tracing layer if enabled; outermost so its span covers every other layer
+	if f.config.Features.EnableTracing {
+		service = f.addTracingLayer(service)
+	}
 
 	return service, nil
 }
 
+// Lockout returns the lockout layer of the chain built last, for admin
+// unlocks, or nil when lockout is disabled
+func (f *UserServiceFactory) Lockout() userLockout.Service {
+	return f.lockout
+}
+
 // BuildMinimal creates a minimal user service with only storage and usecase layers
 func (f *UserServiceFactory) BuildMinimal() (user.Service, error) {
 	// Start with storage layer
@@ -159,6 +409,8 @@ func (f *UserServiceFactory) BuildForTesting(enabledLayers []string) (user.Servi
 			if err != nil {
 				return nil, fmt.Errorf("failed to add cache layer: %w", err)
 			}
+		case "circuitbreaker":
+			service = f.addCircuitBreakerLayer(service)
 		case "audit":
 			service = f.addAuditLayer(service)
 		case "ratelimit":
@@ -182,27 +434,245 @@ func (f *UserServiceFactory) BuildForTesting(enabledLayers []string) (user.Servi
 	return service, nil
 }
 
+// validateConfig checks the dependencies of the enabled layers up front, so a
+// missing service fails the build instead of the first request that needs it
+func (f *UserServiceFactory) validateConfig() error {
+	features := f.config.Features
+
+	switch f.config.StorageProvider {
+	case "", "gorm", "sqlite":
+		if f.config.DB == nil {
+			return fmt.Errorf("database connection is required")
+		}
+	case "postgres":
+		if f.config.Pool == nil {
+			return fmt.Errorf("postgres connection pool is required")
+		}
+	default:
+		return fmt.Errorf("unknown storage provider %q", f.config.StorageProvider)
+	}
+
+	if features.EnableTransactions && f.config.StorageProvider != "postgres" {
+		return fmt.Errorf("postgres storage is required when transactions are enabled")
+	}
+
+	if features.EnableCache {
+		switch f.config.CacheProvider {
+		case "", "redis", "tiered":
+			if f.config.RedisClient == nil {
+				return fmt.Errorf("redis client is required for cache layer")
+			}
+		case "memory", "none":
+		default:
+			return fmt.Errorf("unknown cache provider %q", f.config.CacheProvider)
+		}
+	}
+
+	if features.EnableAudit && f.config.AuditService == nil {
+		return fmt.Errorf("audit service is required when audit is enabled")
+	}
+
+	if features.EnableRateLimit && f.config.RateLimitService == nil {
+		return fmt.Errorf("rate limit service is required when rate limiting is enabled")
+	}
+
+	if features.EnableEncryption && f.config.EncryptionService == nil {
+		return fmt.Errorf("encryption service is required when encryption is enabled")
+	}
+
+	if features.EnableValidation && f.config.ValidationService == nil {
+		return fmt.Errorf("validation service is required when validation is enabled")
+	}
+
+	if features.EnableIdempotency && f.config.IdempotencyService == nil {
+		return fmt.Errorf("idempotency service is required when idempotency is enabled")
+	}
+
+	if features.EnableLockout && f.config.LockoutService == nil {
+		return fmt.Errorf("lockout service is required when lockout is enabled")
+	}
+
+	if features.EnableDeviceAlerts && f.config.DeviceService == nil {
+		return fmt.Errorf("device service is required when device alerts are enabled")
+	}
+
+	if features.EnableDeviceAlerts && f.config.NotificationService == nil {
+		return fmt.Errorf("notification service is required when device alerts are enabled")
+	}
+
+	if features.EnableCaptcha && f.config.CaptchaService == nil {
+		return fmt.Errorf("captcha service is required when captchas are enabled")
+	}
+
+	if features.EnableCaptcha && f.config.CaptchaFailures == nil {
+		return fmt.Errorf("captcha failure store is required when captchas are enabled")
+	}
+
+	return nil
+}
+
 // Layer builders
 
 func (f *UserServiceFactory) buildStorageLayer() (user.Service, error) {
+	if f.config.StorageProvider == "postgres" {
+		if f.config.Pool == nil {
+			return nil, fmt.Errorf("postgres connection pool is required")
+		}
+
+		return userPostgres.NewServiceWithConfig(f.config.Pool, userPostgres.Config{
+			PasswordHistorySize: f.config.PasswordHistorySize,
+			Avatars:             f.config.AvatarStorage,
+			AvatarURLExpiry:     f.config.AvatarURLExpiry,
+			Hasher:              f.config.PasswordHasher,
+		}), nil
+	}
+
 	if f.config.DB == nil {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
-	return userGorm.NewService(f.config.DB), nil
+	return userGorm.NewServiceWithConfig(f.config.DB, userGorm.Config{
+		PasswordHistorySize: f.config.PasswordHistorySize,
+		Avatars:             f.config.AvatarStorage,
+		AvatarURLExpiry:     f.config.AvatarURLExpiry,
+		Hasher:              f.config.PasswordHasher,
+	}), nil
 }
 
 func (f *UserServiceFactory) addCacheLayer(next user.Service) (user.Service, error) {
+	switch f.config.CacheProvider {
+	case "", "redis":
+		return f.addRedisCacheLayer(next)
+	case "memory":
+		return f.addMemoryCacheLayer(next), nil
+	case "tiered":
+		return f.addTieredCacheLayer(next)
+	case "none":
+		return next, nil
+	default:
+		return nil, fmt.Errorf("unknown cache provider %q", f.config.CacheProvider)
+	}
+}
+
+func (f *UserServiceFactory) addRedisCacheLayer(next user.Service) (user.Service, error) {
 	if f.config.RedisClient == nil {
 		return nil, fmt.Errorf("redis client is required for cache layer")
 	}
 
+	config := userRedis.Config{
+		TTLs:              f.cacheTTLs(),
+		WriteThrough:      !f.config.DisableCacheWriteThrough,
+		UserTTL:           f.config.UserCacheTTLOverride,
+		DisableCoalescing: f.config.DisableCacheCoalescing,
+	}
+	if f.config.Features.EnableMetrics && !config.DisableCoalescing {
+		coalesced, err := userRedis.NewCoalescedCounter(f.metricsRegisterer())
+		if err != nil {
+			return nil, err
+		}
+		config.Coalesced = coalesced
+	}
+
+	return userRedis.NewServiceWithConfig(next, f.config.RedisClient, config), nil
+}
+
+func (f *UserServiceFactory) addMemoryCacheLayer(next user.Service) user.Service {
+	ttls := f.cacheTTLs()
+	return memorycache.NewService(next, memorycache.Config{
+		MaxEntries:   f.config.MemoryCacheSize,
+		User:         ttls.User,
+		Preferences:  ttls.Preferences,
+		FeatureFlags: ttls.FeatureFlags,
+		List:         ttls.List,
+		NotFound:     ttls.NotFound,
+		WriteThrough: !f.config.DisableCacheWriteThrough,
+	})
+}
+
+// addTieredCacheLayer puts a short-lived in-process cache in front of the
+// Redis cache. Writes on one instance drop the user from the others' first
+// tier, while the shared second tier keeps the database load of cold
+// instances low.
+func (f *UserServiceFactory) addTieredCacheLayer(next user.Service) (user.Service, error) {
+	service, err := f.addRedisCacheLayer(next)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := f.config.L1CacheTTL
+	if ttl == 0 {
+		ttl = 30 * time.Second
+	}
+	ttls := f.cacheTTLs()
+
+	return memorycache.NewService(service, memorycache.Config{
+		MaxEntries:   f.config.MemoryCacheSize,
+		User:         min(ttl, ttls.User),
+		Preferences:  min(ttl, ttls.Preferences),
+		FeatureFlags: min(ttl, ttls.FeatureFlags),
+		List:         min(ttl, ttls.List),
+		NotFound:     min(ttl, ttls.NotFound),
+		WriteThrough: !f.config.DisableCacheWriteThrough,
+		Broadcaster:  userRedis.NewBroadcaster(f.config.RedisClient, f.config.CacheInvalidationChannel),
+	}), nil
+}
+
+// cacheTTLs resolves the per-method cache TTLs, falling back to CacheTTL
+func (f *UserServiceFactory) cacheTTLs() userRedis.TTLConfig {
 	cacheTTL := f.config.CacheTTL
 	if cacheTTL == 0 {
 		cacheTTL = 5 * time.Minute // Default TTL
 	}
 
-	return userRedis.NewService(next, f.config.RedisClient, cacheTTL), nil
+	ttls := userRedis.TTLConfig{
+		User:         f.config.UserCacheTTL,
+		Preferences:  f.config.PreferencesCacheTTL,
+		FeatureFlags: f.config.FeatureFlagsCacheTTL,
+		List:         f.config.ListCacheTTL,
+		NotFound:     f.config.NotFoundCacheTTL,
+	}
+	if ttls.User == 0 {
+		ttls.User = cacheTTL
+	}
+	if ttls.Preferences == 0 {
+		ttls.Preferences = cacheTTL
+	}
+	if ttls.FeatureFlags == 0 {
+		ttls.FeatureFlags = cacheTTL
+	}
+	return ttls
+}
+
+func (f *UserServiceFactory) addMetricsLayer(next user.Service) (user.Service, error) {
+	return userMetrics.NewService(next, f.metricsRegisterer())
+}
+
+// metricsRegisterer returns the configured registerer or the Prometheus default
+func (f *UserServiceFactory) metricsRegisterer() prometheus.Registerer {
+	if f.config.MetricsRegisterer == nil {
+		return prometheus.DefaultRegisterer
+	}
+	return f.config.MetricsRegisterer
+}
+
+func (f *UserServiceFactory) addTracingLayer(next user.Service) user.Service {
+	provider := f.config.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return userTracing.NewService(next, provider)
+}
+
+// addTiming wraps a layer so it reports its own elapsed time
+func (f *UserServiceFactory) addTiming(next user.Service, layer string) user.Service {
+	if !f.config.Features.EnableTiming {
+		return next
+	}
+	return userTiming.NewService(next, layer)
+}
+
+func (f *UserServiceFactory) addCircuitBreakerLayer(next user.Service) user.Service {
+	return userCircuitBreaker.NewService(next, f.config.CircuitBreaker)
 }
 
 func (f *UserServiceFactory) addAuditLayer(next user.Service) user.Service {
@@ -210,15 +680,51 @@ func (f *UserServiceFactory) addAuditLayer(next user.Service) user.Service {
 }
 
 func (f *UserServiceFactory) addRateLimitLayer(next user.Service) (user.Service, error) {
-	return userRateLimit.NewService(next, f.config.RateLimitService), nil
+	return userRateLimit.NewServiceWithConfig(next, f.config.RateLimitService, f.config.RateLimit)
 }
 
 func (f *UserServiceFactory) addEncryptionLayer(next user.Service) (user.Service, error) {
 	return userEncryption.NewService(next, f.config.EncryptionService), nil
 }
 
+func (f *UserServiceFactory) addLockoutLayer(next user.Service) userLockout.Service {
+	var auditService audit.Service
+	if f.config.Features.EnableAudit {
+		auditService = f.config.AuditService
+	}
+	return userLockout.NewService(next, f.config.LockoutService, auditService, f.config.Lockout)
+}
+
+func (f *UserServiceFactory) addDeviceLayer(next user.Service) user.Service {
+	return userDevice.NewService(next, f.config.DeviceService, f.config.NotificationService)
+}
+
+func (f *UserServiceFactory) addCaptchaLayer(next user.Service) user.Service {
+	return userCaptcha.NewService(next, f.config.CaptchaService, f.config.CaptchaFailures, f.config.Captcha)
+}
+
 func (f *UserServiceFactory) addValidationLayer(next user.Service) user.Service {
-	return userValidation.NewService(next, f.config.ValidationService)
+	return userValidation.NewServiceWithSanitizer(next, f.config.ValidationService, f.config.Sanitizer)
+}
+
+func (f *UserServiceFactory) addAuthorizationLayer(next user.Service) user.Service {
+	authorizationService := f.config.AuthorizationService
+	if authorizationService == nil {
+		authorizationService = rbac.NewService(rbac.DefaultConfig())
+	}
+	return userAuthorization.NewService(next, authorizationService)
+}
+
+func (f *UserServiceFactory) addStepUpLayer(next user.Service) user.Service {
+	return userStepUp.NewService(next, f.config.StepUp)
+}
+
+func (f *UserServiceFactory) addTransactionLayer(next user.Service) user.Service {
+	return userTransaction.NewService(next, f.config.Pool)
+}
+
+func (f *UserServiceFactory) addIdempotencyLayer(next user.Service) user.Service {
+	return userIdempotency.NewServiceWithConfig(next, f.config.IdempotencyService, f.config.Idempotency)
 }
 
 func (f *UserServiceFactory) addUseCaseLayer(next user.Service) user.Service {
@@ -226,8 +732,12 @@ func (f *UserServiceFactory) addUseCaseLayer(next user.Service) user.Service {
 		NotificationService: f.config.NotificationService,
 		TokenService:        f.config.TokenService,
 		EventPublisher:      f.config.EventsService,
+		NotificationHistory: f.config.NotificationHistory,
 	}
-	return usecase.NewService(next, deps)
+	return usecase.NewServiceWithConfig(next, deps, usecase.Config{
+		EmailVerificationGracePeriod: f.config.EmailVerificationGracePeriod,
+		DisableWelcomeEmail:          f.config.DisableWelcomeEmail,
+	})
 }
 
 // Helper methods for creating common configurations
@@ -255,6 +765,8 @@ func NewDefaultConfig(
 		NotificationService: notificationSvc,
 		TokenService:        tokenSvc,
 		EventsService:       eventsSvc,
+		RateLimit:           userRateLimit.DefaultConfig(),
+		CircuitBreaker:      userCircuitBreaker.DefaultConfig(),
 		Features:            DefaultFeatureFlags(),
 	}
 }
@@ -275,6 +787,9 @@ func NewProductionConfig(
 		DB:                  db,
 		RedisClient:         redisClient,
 		CacheTTL:            10 * time.Minute,
+		NotFoundCacheTTL:    userRedis.DefaultNotFoundCacheTTL,
+		RateLimit:           userRateLimit.DefaultConfig(),
+		CircuitBreaker:      userCircuitBreaker.DefaultConfig(),
 		AuditService:        auditSvc,
 		EncryptionService:   encryptionSvc,
 		RateLimitService:    rateLimitSvc,
@@ -283,11 +798,22 @@ func NewProductionConfig(
 		TokenService:        tokenSvc,
 		EventsService:       eventsSvc,
 		Features: FeatureFlags{
-			EnableCache:      true,
-			EnableAudit:      true,
-			EnableRateLimit:  true,
-			EnableEncryption: true,
-			EnableValidation: true,
+			EnableCache:          true,
+			EnableCircuitBreaker: true,
+			EnableAudit:          true,
+			EnableRateLimit:      true,
+			EnableEncryption:     true,
+			EnableValidation:     true,
+			EnableAuthorization:  true,
+			EnableIdempotency:    false, // Requires an idempotency store
+			EnableLockout:        false, // Requires a lockout store
+			EnableDeviceAlerts:   false, // Requires a device store
+			EnableCaptcha:        false, // Requires a captcha provider
+			EnableStepUp:         false, // Requires tokens carrying auth_time
+			EnableTransactions:   false, // Requires the postgres storage and an event outbox
+			EnableTiming:         true,
+			EnableMetrics:        true,
+			EnableTracing:        true,
 		},
 	}
 }
@@ -314,11 +840,21 @@ func NewTestingConfig(
 		TokenService:        tokenSvc,
 		EventsService:       eventsSvc,
 		Features: FeatureFlags{
-			EnableCache:      false, // Disable cache for consistent testing
-			EnableAudit:      false, // Disable audit to reduce noise
-			EnableRateLimit:  false, // Disable rate limiting for testing
-			EnableEncryption: false, // Disable encryption for simpler testing
-			EnableValidation: true,  // Keep validation for testing business rules
+			EnableCache:          false, // Disable cache for consistent testing
+			EnableCircuitBreaker: false, // Disable circuit breaker for deterministic failures
+			EnableAudit:          false, // Disable audit to reduce noise
+			EnableRateLimit:      false, // Disable rate limiting for testing
+			EnableEncryption:     false, // Disable encryption for simpler testing
+			EnableValidation:     true,  // Keep validation for testing business rules
+			EnableAuthorization:  false, // Disable authorization so tests need no caller
+			EnableIdempotency:    false, // Disable idempotency so retries run again
+			EnableLockout:        false, // Disable lockout so failed logins can be repeated
+			EnableDeviceAlerts:   false, // Disable device alerts so logins send no emails
+			EnableCaptcha:        false, // Disable captchas so tests need no tokens
+			EnableStepUp:         false, // Disable step-up so tests need no sign-in time
+			EnableTiming:         false, // Disable timing to keep the chain minimal
+			EnableMetrics:        false, // Disable metrics to avoid global registration
+			EnableTracing:        false, // Disable tracing to keep the chain minimal
 		},
 	}
 }
@@ -340,42 +876,103 @@ type LayerInfo struct {
 // GetServiceInfo returns information about the configured service layers
 func (f *UserServiceFactory) GetServiceInfo() ServiceLayerInfo {
 	layers := []LayerInfo{
+		{
+			Name:        "Authorization",
+			Description: "Ownership and role checks for profile and preference changes",
+			Enabled:     f.config.Features.EnableAuthorization,
+		},
+		{
+			Name:        "StepUp",
+			Description: "Requires a recent sign-in to change credentials or erase the account",
+			Enabled:     f.config.Features.EnableStepUp,
+		},
+		{
+			Name:        "Idempotency",
+			Description: "Replays results of retried requests carrying an idempotency key",
+			Enabled:     f.config.Features.EnableIdempotency,
+		},
+		{
+			Name:        "Transaction",
+			Description: "Commits changes and the events they publish in one database transaction",
+			Enabled:     f.config.Features.EnableTransactions,
+		},
 		{
 			Name:        "UseCase",
 			Description: "Business logic and orchestration layer",
 			Enabled:     true, // Always enabled
 		},
+		{
+			Name:        "Metrics",
+			Description: "Prometheus request counts, latencies and errors",
+			Enabled:     f.config.Features.EnableMetrics,
+		},
+		{
+			Name:        "Tracing",
+			Description: "OpenTelemetry spans per operation",
+			Enabled:     f.config.Features.EnableTracing,
+		},
 		{
 			Name:        "Validation",
 			Description: "Input validation and business rules",
 			Enabled:     f.config.Features.EnableValidation,
 		},
 		{
-			Name:        "Encryption",
-			Description: "Data encryption for sensitive fields",
-			Enabled:     f.config.Features.EnableEncryption,
+			Name:        "Captcha",
+			Description: "Captcha checks on registration and on logins after repeated failures",
+			Enabled:     f.config.Features.EnableCaptcha,
+		},
+		{
+			Name:        "DeviceAlerts",
+			Description: "Emails users about sign-ins from unrecognized devices",
+			Enabled:     f.config.Features.EnableDeviceAlerts,
+		},
+		{
+			Name:        "Lockout",
+			Description: "Locks accounts and IPs after repeated failed logins",
+			Enabled:     f.config.Features.EnableLockout,
 		},
 		{
 			Name:        "RateLimit",
 			Description: "Rate limiting for API protection",
 			Enabled:     f.config.Features.EnableRateLimit,
 		},
+		{
+			Name:        "Encryption",
+			Description: "Data encryption for sensitive fields",
+			Enabled:     f.config.Features.EnableEncryption,
+		},
 		{
 			Name:        "Audit",
 			Description: "Activity logging and audit trail",
 			Enabled:     f.config.Features.EnableAudit,
 		},
+		{
+			Name:        "CircuitBreaker",
+			Description: "Fail-fast protection against storage and cache outages",
+			Enabled:     f.config.Features.EnableCircuitBreaker,
+		},
 		{
 			Name:        "Cache",
-			Description: "Redis caching for performance",
-			Enabled:     f.config.Features.EnableCache,
+			Description: "Redis or in-memory caching for performance",
+			Enabled:     f.config.Features.EnableCache && f.config.CacheProvider != "none",
 		},
 		{
 			Name:        "Storage",
-			Description: "Database persistence layer (GORM)",
+			Description: f.storageDescription(),
 			Enabled:     true, // Always enabled
 		},
 	}
 
 	return ServiceLayerInfo{Layers: layers}
 }
+
+// storageDescription names the configured storage backend
+func (f *UserServiceFactory) storageDescription() string {
+	switch f.config.StorageProvider {
+	case "postgres":
+		return "Database persistence layer (pgx)"
+	case "sqlite":
+		return "Database persistence layer (GORM on SQLite)"
+	}
+	return "Database persistence layer (GORM)"
+}
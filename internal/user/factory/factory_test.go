@@ -0,0 +1,180 @@
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditmock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	captchaNoop "github.com/gentra/decorator-arch-go/internal/captcha/noop"
+	deviceMemory "github.com/gentra/decorator-arch-go/internal/device/memory"
+	idempotencyMemory "github.com/gentra/decorator-arch-go/internal/idempotency/memory"
+	lockoutMemory "github.com/gentra/decorator-arch-go/internal/lockout/memory"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	throttleMemory "github.com/gentra/decorator-arch-go/internal/throttle/memory"
+	"github.com/gentra/decorator-arch-go/internal/user/factory"
+)
+
+func TestUserServiceFactory_Build(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      func(*factory.Config)
+		expectedErr string
+	}{
+		{
+			name:   "Given storage and an in-memory cache, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {},
+		},
+		{
+			name: "Given audit enabled with an audit service, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {
+				c.Features.EnableAudit = true
+				c.AuditService = new(auditmock.MockAuditService)
+			},
+		},
+		{
+			name:        "Given no database, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.DB = nil },
+			expectedErr: "database connection is required",
+		},
+		{
+			name:        "Given Postgres storage without a pool, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.StorageProvider = "postgres" },
+			expectedErr: "postgres connection pool is required",
+		},
+		{
+			name: "Given SQLite storage without a database, When Build is called, Then should return a validation error",
+			config: func(c *factory.Config) {
+				c.StorageProvider = "sqlite"
+				c.DB = nil
+			},
+			expectedErr: "database connection is required",
+		},
+		{
+			name:        "Given an unknown storage provider, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.StorageProvider = "mongo" },
+			expectedErr: `unknown storage provider "mongo"`,
+		},
+		{
+			name:        "Given the Redis cache without a client, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.CacheProvider = "redis" },
+			expectedErr: "redis client is required",
+		},
+		{
+			name:        "Given the tiered cache without a client, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.CacheProvider = "tiered" },
+			expectedErr: "redis client is required",
+		},
+		{
+			name:        "Given an unknown cache provider, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.CacheProvider = "memcached" },
+			expectedErr: `unknown cache provider "memcached"`,
+		},
+		{
+			name:        "Given audit enabled without an audit service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableAudit = true },
+			expectedErr: "audit service is required",
+		},
+		{
+			name:        "Given rate limiting enabled without a rate limit service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableRateLimit = true },
+			expectedErr: "rate limit service is required",
+		},
+		{
+			name:        "Given encryption enabled without an encryption service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableEncryption = true },
+			expectedErr: "encryption service is required",
+		},
+		{
+			name:        "Given validation enabled without a validation service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableValidation = true },
+			expectedErr: "validation service is required",
+		},
+		{
+			name:        "Given idempotency enabled without an idempotency service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableIdempotency = true },
+			expectedErr: "idempotency service is required",
+		},
+		{
+			name: "Given idempotency enabled with an in-memory store, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {
+				c.Features.EnableIdempotency = true
+				c.IdempotencyService = idempotencyMemory.NewService()
+			},
+		},
+		{
+			name:        "Given lockout enabled without a lockout service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableLockout = true },
+			expectedErr: "lockout service is required",
+		},
+		{
+			name: "Given lockout enabled with an in-memory store, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {
+				c.Features.EnableLockout = true
+				c.LockoutService = lockoutMemory.NewService()
+			},
+		},
+		{
+			name:        "Given device alerts enabled without a device service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableDeviceAlerts = true },
+			expectedErr: "device service is required",
+		},
+		{
+			name: "Given device alerts enabled with an in-memory store, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {
+				c.Features.EnableDeviceAlerts = true
+				c.DeviceService = deviceMemory.NewService()
+				c.NotificationService = notificationMock.NewService()
+			},
+		},
+		{
+			name:        "Given captchas enabled without a captcha service, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableCaptcha = true },
+			expectedErr: "captcha service is required",
+		},
+		{
+			name: "Given captchas enabled with the no-op provider, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) {
+				c.Features.EnableCaptcha = true
+				c.CaptchaService = captchaNoop.NewService()
+				c.CaptchaFailures = throttleMemory.NewService()
+			},
+		},
+		{
+			name:   "Given step-up enabled, When Build is called, Then should assemble the chain",
+			config: func(c *factory.Config) { c.Features.EnableStepUp = true },
+		},
+		{
+			name:        "Given transactions enabled with GORM storage, When Build is called, Then should return a validation error",
+			config:      func(c *factory.Config) { c.Features.EnableTransactions = true },
+			expectedErr: "postgres storage is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			config := factory.Config{
+				DB:            &gorm.DB{},
+				CacheProvider: "memory",
+				Features:      factory.FeatureFlags{EnableCache: true},
+			}
+			tc.config(&config)
+
+			// Act
+			service, err := factory.NewUserServiceFactory(config).Build()
+
+			// Assert
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				assert.Nil(t, service)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, service)
+		})
+	}
+}
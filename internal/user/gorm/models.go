@@ -10,16 +10,34 @@ import (
 
 // UserModel represents the GORM model for users table
 type UserModel struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	FirstName    string    `gorm:"not null" json:"first_name"`
-	LastName     string    `gorm:"not null" json:"last_name"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Email         string         `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null" json:"email"`
+	PasswordHash  string         `gorm:"not null" json:"-"`
+	FirstName     string         `gorm:"not null" json:"first_name"`
+	LastName      string         `gorm:"not null" json:"last_name"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeactivatedAt *time.Time     `json:"deactivated_at,omitempty"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Requested email change awaiting confirmation
+	PendingEmail           string     `gorm:"not null;default:''" json:"pending_email,omitempty"`
+	EmailChangeRequestedAt *time.Time `json:"email_change_requested_at,omitempty"`
+
+	// Set once the user proves they control Email
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// Set while a password reset is outstanding
+	PasswordResetRequestedAt *time.Time `json:"password_reset_requested_at,omitempty"`
+
+	// Key of the avatar image in blob storage
+	AvatarKey string `gorm:"not null;default:''" json:"avatar_key,omitempty"`
+
+	// Incremented by every update, for optimistic locking
+	Version int `gorm:"not null;default:1" json:"version"`
 
 	// Relationships
-	Preferences *UserPreferencesModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"preferences,omitempty"`
+	Preferences *UserPreferencesModel `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"preferences,omitempty"`
 }
 
 // UserPreferencesModel represents the GORM model for user_preferences table
@@ -35,11 +53,30 @@ type UserPreferencesModel struct {
 	NotificationTypes  datatypes.JSON `json:"notification_types"`
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
+	Version            int            `gorm:"not null;default:1" json:"version"`
 
 	// Relationships
 	User *UserModel `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+// PasswordHistoryModel represents the GORM model for password_history table.
+// Each row is a hash the user has since replaced.
+type PasswordHistoryModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index:idx_password_history_user_created,priority:1" json:"user_id"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `gorm:"index:idx_password_history_user_created,priority:2" json:"created_at"`
+}
+
+// UserFeatureFlagModel represents the GORM model for user_feature_flags table.
+// Each row is one flag switched on or off for a user.
+type UserFeatureFlagModel struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Flag      string    `gorm:"primaryKey;size:64" json:"flag"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // BeforeCreate will set a UUID rather than numeric ID for UserModel
 func (u *UserModel) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
@@ -65,3 +102,21 @@ func (UserModel) TableName() string {
 func (UserPreferencesModel) TableName() string {
 	return "user_preferences"
 }
+
+// BeforeCreate will set a UUID rather than numeric ID for PasswordHistoryModel
+func (p *PasswordHistoryModel) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName overrides the table name used by PasswordHistoryModel to `password_history`
+func (PasswordHistoryModel) TableName() string {
+	return "password_history"
+}
+
+// TableName overrides the table name used by UserFeatureFlagModel to `user_feature_flags`
+func (UserFeatureFlagModel) TableName() string {
+	return "user_feature_flags"
+}
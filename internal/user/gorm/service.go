@@ -4,40 +4,102 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	hashFactory "github.com/gentra/decorator-arch-go/internal/hash/factory"
+	"github.com/gentra/decorator-arch-go/internal/storage"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
+// errAvatarStorageDisabled is returned by the avatar methods when no storage is configured
+var errAvatarStorageDisabled = errors.New("avatar storage is not configured")
+
 // service implements the user.Service interface using GORM
 type service struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	passwordHistorySize int
+	avatars             storage.Service
+	avatarURLExpiry     time.Duration
+	hasher              hash.Service
+}
+
+// Config contains storage settings for the GORM user service
+type Config struct {
+	// PasswordHistorySize is how many recent passwords, including the current
+	// one, cannot be reused; zero uses user.DefaultPasswordHistorySize
+	PasswordHistorySize int
+
+	// Avatars stores avatar images; without it avatar uploads are rejected.
+	// AvatarURLExpiry is how long signed avatar links stay valid, zero uses
+	// storage.DefaultURLExpiry.
+	Avatars         storage.Service
+	AvatarURLExpiry time.Duration
+
+	// Hasher hashes and verifies passwords; nil uses the default hash
+	// service. Logins rehash passwords whose hash it reports as outdated.
+	Hasher hash.Service
 }
 
 // NewService creates a new GORM-based user service
 func NewService(db *gorm.DB) user.Service {
+	return NewServiceWithConfig(db, Config{})
+}
+
+// NewServiceWithConfig creates a new GORM-based user service with custom settings
+func NewServiceWithConfig(db *gorm.DB, config Config) user.Service {
+	historySize := config.PasswordHistorySize
+	if historySize <= 0 {
+		historySize = user.DefaultPasswordHistorySize
+	}
+	hasher := config.Hasher
+	if hasher == nil {
+		hasher = hashFactory.NewDefaultService()
+	}
+
 	return &service{
-		db: db,
+		db:                  db,
+		passwordHistorySize: historySize,
+		avatars:             config.Avatars,
+		avatarURLExpiry:     config.AvatarURLExpiry,
+		hasher:              hasher,
 	}
 }
 
 // Register creates a new user in the database
 func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	// Abort before the expensive hash if the caller has already gone away
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(ctx, data.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create user model
 	userModel := UserModel{
+		ID:           data.ID,
 		Email:        data.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		FirstName:    data.FirstName,
 		LastName:     data.LastName,
+		Version:      1,
+	}
+	if data.EmailVerified {
+		verifiedAt := time.Now()
+		userModel.EmailVerifiedAt = &verifiedAt
 	}
 
 	// Start transaction
@@ -77,6 +139,7 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 		Language:           defaultPrefs.Language,
 		Timezone:           defaultPrefs.Timezone,
 		NotificationTypes:  notificationTypesJSON,
+		Version:            defaultPrefs.Version,
 	}
 
 	if err := tx.Create(&prefsModel).Error; err != nil {
@@ -105,11 +168,30 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 		return nil, err
 	}
 
+	// Abort before the expensive comparison if the caller has gone away
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(password)); err != nil {
+	if err := s.hasher.Compare(ctx, userModel.PasswordHash, password); err != nil {
 		return nil, user.ErrInvalidCredentials
 	}
 
+	// Deactivated accounts are only reported once the password matched, so the
+	// account status is not revealed to someone guessing credentials
+	if userModel.DeactivatedAt != nil {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	if s.hasher.NeedsRehash(userModel.PasswordHash) {
+		if rehashed, err := s.rehashPassword(ctx, userModel.ID, userModel.PasswordHash, password); err == nil {
+			userModel.PasswordHash = rehashed
+		} else {
+			log.Printf("Failed to rehash password of user %s: %v", userModel.ID, err)
+		}
+	}
+
 	// Convert to domain model
 	domainUser := s.toDomainUser(&userModel)
 
@@ -122,15 +204,21 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 	return authResult, nil
 }
 
-// GetByID retrieves a user by ID
+// GetByID retrieves a user by ID; soft-deleted users are only returned when
+// the context includes deleted users
 func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, user.ErrUserNotFound
 	}
 
+	query := s.db.WithContext(ctx)
+	if user.IsDeletedIncluded(ctx) {
+		query = query.Unscoped()
+	}
+
 	var userModel UserModel
-	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&userModel).Error; err != nil {
+	if err := query.Where("id = ?", userID).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, user.ErrUserNotFound
 		}
@@ -140,6 +228,127 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	return s.toDomainUser(&userModel), nil
 }
 
+// GetByIDs retrieves the users with the given IDs in one query. IDs that are
+// malformed or match no user are left out of the result.
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	result := make(map[string]*user.User, len(ids))
+
+	userIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if userID, err := uuid.Parse(id); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query := s.db.WithContext(ctx)
+	if user.IsDeletedIncluded(ctx) {
+		query = query.Unscoped()
+	}
+
+	var models []UserModel
+	if err := query.Where("id IN ?", userIDs).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range models {
+		result[models[i].ID.String()] = s.toDomainUser(&models[i])
+	}
+	return result, nil
+}
+
+// List returns a page of users matching the filters. Cursor pages use keyset
+// pagination on the sort column with the ID as tie-breaker.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	filters = filters.WithDefaults()
+	column, ok := listSortColumns[filters.SortBy]
+	if !ok {
+		err := user.ErrInvalidListFilter
+		err.Field = "sort_by"
+		return nil, err
+	}
+	direction := "DESC"
+	comparison := "<"
+	if filters.SortOrder == user.SortAsc {
+		direction = "ASC"
+		comparison = ">"
+	}
+
+	query := s.db.WithContext(ctx).Model(&UserModel{})
+	if user.IsDeletedIncluded(ctx) {
+		query = query.Unscoped()
+	}
+	if filters.EmailPrefix != "" {
+		query = query.Where(`email LIKE ? ESCAPE '\'`, escapeLike(filters.EmailPrefix)+"%")
+	}
+	if filters.Name != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filters.Name)) + "%"
+		query = query.Where(`(LOWER(first_name) LIKE ? ESCAPE '\' OR LOWER(last_name) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if filters.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filters.CreatedAfter)
+	}
+	if filters.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filters.CreatedBefore)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	page := query.Session(&gorm.Session{})
+	if filters.Cursor != "" {
+		cursor, err := user.DecodeListCursor(filters.Cursor, filters)
+		if err != nil {
+			return nil, err
+		}
+		value, id, err := cursorPosition(filters.SortBy, cursor)
+		if err != nil {
+			return nil, err
+		}
+		page = page.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, comparison), value, value, id)
+	} else if filters.Offset > 0 {
+		page = page.Offset(filters.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var models []UserModel
+	if err := page.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).Limit(filters.Limit + 1).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := &user.Page{
+		Users: make([]*user.User, 0, len(models)),
+		Total: total,
+		Limit: filters.Limit,
+	}
+	if filters.Cursor == "" {
+		result.Offset = filters.Offset
+	}
+
+	hasMore := len(models) > filters.Limit
+	if hasMore {
+		models = models[:filters.Limit]
+	}
+	for i := range models {
+		result.Users = append(result.Users, s.toDomainUser(&models[i]))
+	}
+	if hasMore {
+		last := result.Users[len(result.Users)-1]
+		result.NextCursor = user.ListCursor{
+			SortBy:    filters.SortBy,
+			SortOrder: filters.SortOrder,
+			Value:     filters.SortValue(last),
+			ID:        last.ID.String(),
+		}.Encode()
+	}
+
+	return result, nil
+}
+
 // UpdateProfile updates user profile information
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	userID, err := uuid.Parse(id)
@@ -157,25 +366,378 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 	}
 	if data.Email != nil {
 		updates["email"] = *data.Email
+		// A new address has not been verified yet
+		updates["email_verified_at"] = gorm.Expr("CASE WHEN email = ? THEN email_verified_at END", *data.Email)
+		// Reset links sent to the old address no longer apply
+		updates["password_reset_requested_at"] = gorm.Expr("CASE WHEN email = ? THEN password_reset_requested_at END", *data.Email)
 	}
 
 	if len(updates) == 0 {
 		// No updates to make, just return the existing user
-		return s.GetByID(ctx, id)
+		existing, err := s.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if data.Version > 0 && existing.Version != data.Version {
+			return nil, user.ErrConflict
+		}
+		return existing, nil
 	}
+	updates["version"] = gorm.Expr("version + 1")
 
-	// Update user
-	if err := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) && data.Email != nil {
+	// Update user, only if it is still at the version the change is based on
+	query := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userID)
+	if data.Version > 0 {
+		query = query.Where("version = ?", data.Version)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) && data.Email != nil {
 			return nil, user.ErrEmailAlreadyExists
 		}
-		return nil, err
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Either the user does not exist or it changed since it was read
+		if _, err := s.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, user.ErrConflict
 	}
 
 	// Return updated user
 	return s.GetByID(ctx, id)
 }
 
+// ChangePassword replaces the user's password after verifying the current one.
+// The replaced hash is kept in the password history so the last
+// passwordHistorySize passwords cannot be chosen again.
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user.ErrUserNotFound
+		}
+		return err
+	}
+	if userModel.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	// Abort before the expensive comparisons if the caller has gone away
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.hasher.Compare(ctx, userModel.PasswordHash, currentPassword); err != nil {
+		return user.ErrIncorrectPassword
+	}
+
+	if err := s.checkPasswordReuse(ctx, &userModel, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only replace the hash that was verified, so a concurrent change wins once
+		result := tx.Model(&UserModel{}).
+			Where("id = ? AND password_hash = ?", parsedUserID, userModel.PasswordHash).
+			Updates(map[string]interface{}{
+				"password_hash": hashedPassword,
+				"version":       gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return user.ErrIncorrectPassword
+		}
+
+		if err := tx.Create(&PasswordHistoryModel{
+			UserID:       parsedUserID,
+			PasswordHash: userModel.PasswordHash,
+		}).Error; err != nil {
+			return err
+		}
+
+		return s.prunePasswordHistory(tx, parsedUserID)
+	})
+}
+
+// RequestPasswordReset marks a password reset as requested for the live user
+// with the given email, making tokens issued before now stale
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if userModel.DeactivatedAt != nil {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	// The version is kept: anyone may request a reset, and that must not
+	// make the user's own updates conflict
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userModel.ID).
+		Update("password_reset_requested_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, userModel.ID.String())
+}
+
+// ResetPassword replaces the password of the user named by
+// user.WithPasswordReset without the current password. The token is resolved
+// by the usecase layer before this is reached; the outstanding request is
+// cleared so the reset happens at most once.
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	parsedUserID, err := uuid.Parse(user.PasswordResetFromContext(ctx))
+	if err != nil {
+		return user.ErrInvalidResetToken
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user.ErrInvalidResetToken
+		}
+		return err
+	}
+	if userModel.PasswordResetRequestedAt == nil {
+		return user.ErrInvalidResetToken
+	}
+	if userModel.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	if err := s.checkPasswordReuse(ctx, &userModel, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Clearing the request redeems it, so concurrent resets succeed once
+		result := tx.Model(&UserModel{}).
+			Where("id = ? AND password_reset_requested_at IS NOT NULL", parsedUserID).
+			Updates(map[string]interface{}{
+				"password_hash":               hashedPassword,
+				"password_reset_requested_at": nil,
+				"version":                     gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return user.ErrInvalidResetToken
+		}
+
+		if err := tx.Create(&PasswordHistoryModel{
+			UserID:       parsedUserID,
+			PasswordHash: userModel.PasswordHash,
+		}).Error; err != nil {
+			return err
+		}
+
+		return s.prunePasswordHistory(tx, parsedUserID)
+	})
+}
+
+// RequestEmailChange records newEmail as the user's pending email, replacing
+// any earlier request. The address is checked against other live users now
+// and again on confirmation.
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user.ErrUserNotFound
+		}
+		return err
+	}
+	if userModel.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+	if userModel.Email == newEmail {
+		return user.ErrEmailUnchanged
+	}
+
+	var taken int64
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).
+		Where("email = ? AND id <> ?", newEmail, parsedUserID).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return user.ErrEmailAlreadyExists
+	}
+
+	return s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", parsedUserID).Updates(map[string]interface{}{
+		"pending_email":             newEmail,
+		"email_change_requested_at": time.Now(),
+		"version":                   gorm.Expr("version + 1"),
+	}).Error
+}
+
+// ConfirmEmailChange makes the pending email the user's email. The token is
+// verified by the usecase layer before this is reached.
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if userModel.PendingEmail == "" {
+		return nil, user.ErrNoPendingEmail
+	}
+
+	// Only apply the pending email that was read, so a newer request is not
+	// confirmed by the token of an older one
+	result := s.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND pending_email = ?", parsedUserID, userModel.PendingEmail).
+		Updates(map[string]interface{}{
+			"email":                       userModel.PendingEmail,
+			"pending_email":               "",
+			"email_change_requested_at":   nil,
+			"email_verified_at":           time.Now(), // The token proved the new address
+			"password_reset_requested_at": nil,        // Reset links went to the old address
+			"version":                     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, user.ErrEmailAlreadyExists
+		}
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, user.ErrNoPendingEmail
+	}
+
+	return s.GetByID(ctx, userID)
+}
+
+// VerifyEmail marks the user named by user.WithEmailVerification as verified.
+// The token is resolved by the usecase layer before this is reached; users
+// already verified keep their verification time.
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	userID := user.EmailVerificationFromContext(ctx)
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrInvalidVerifyToken
+	}
+
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND email_verified_at IS NULL", parsedUserID).
+		Updates(map[string]interface{}{
+			"email_verified_at": time.Now(),
+			"version":           gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, userID)
+}
+
+// UploadAvatar stores the image under a new key and points the user at it,
+// then removes the image it replaces. Every upload gets its own key so cached
+// links to the old avatar never serve the new one.
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	if s.avatars == nil {
+		return errAvatarStorageDisabled
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user.ErrUserNotFound
+		}
+		return err
+	}
+	if userModel.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	extension, ok := user.AvatarContentTypes[contentType]
+	if !ok {
+		return user.ErrUnsupportedAvatar
+	}
+
+	key := fmt.Sprintf("avatars/%s/%s%s", userModel.ID, uuid.New(), extension)
+	if _, err := s.avatars.Put(ctx, key, content, contentType); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userModel.ID).Updates(map[string]interface{}{
+		"avatar_key": key,
+		"version":    gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		// Nothing references the new image yet
+		_ = s.avatars.Delete(ctx, key)
+		return err
+	}
+
+	// A failed cleanup leaves an unreferenced image behind, not a broken avatar
+	if userModel.AvatarKey != "" {
+		_ = s.avatars.Delete(ctx, userModel.AvatarKey)
+	}
+	return nil
+}
+
+// GetAvatarURL returns a link to the user's avatar image
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	if s.avatars == nil {
+		return "", errAvatarStorageDisabled
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return "", user.ErrUserNotFound
+	}
+
+	var userModel UserModel
+	if err := s.db.WithContext(ctx).Select("id", "avatar_key").Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", user.ErrUserNotFound
+		}
+		return "", err
+	}
+	if userModel.AvatarKey == "" {
+		return "", user.ErrAvatarNotFound
+	}
+
+	return s.avatars.URL(ctx, userModel.AvatarKey, s.avatarURLExpiry)
+}
+
 // GetPreferences retrieves user preferences
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	parsedUserID, err := uuid.Parse(userID)
@@ -194,21 +756,313 @@ func (s *service) GetPreferences(ctx context.Context, userID string) (*user.User
 	return s.toDomainPreferences(&prefsModel)
 }
 
-// UpdatePreferences updates user preferences
+// UpdatePreferences updates user preferences. When prefs carries a version
+// the update only applies if the stored row still has it, otherwise
+// ErrConflict is returned.
 func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
 		return user.ErrUserNotFound
 	}
 
-	// Convert notification types to JSON
-	notificationTypesJSON, err := json.Marshal(prefs.NotificationTypes)
+	return updatePreferences(s.db.WithContext(ctx), parsedUserID, prefs)
+}
+
+// UpdatePreferencesBulk updates the preferences of several users in one
+// transaction; if any user has no preferences or a stale version nothing is
+// changed
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	// Apply updates in a fixed order so concurrent bulk updates lock rows consistently
+	userIDs := make([]string, 0, len(updates))
+	for userID := range updates {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			parsedUserID, err := uuid.Parse(userID)
+			if err != nil {
+				return user.ErrUserNotFound
+			}
+
+			if err := updatePreferences(tx, parsedUserID, updates[userID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Deactivate marks the user as deactivated; deactivating twice is a no-op
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	result := s.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND deactivated_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"deactivated_at": time.Now(),
+			"version":        gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing changed: either the user is already deactivated or does not exist
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete soft deletes the user by setting deleted_at; the row is kept
+func (s *service) Delete(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	result := s.db.WithContext(ctx).Where("id = ?", userID).Delete(&UserModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// ExportUserData collects the stored profile and preferences of the user
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	profile, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil && !errors.Is(err, user.ErrPreferencesNotFound) {
+		return nil, err
+	}
+
+	return &user.DataExport{
+		UserID:      userID,
+		ExportedAt:  time.Now(),
+		Profile:     profile,
+		Preferences: prefs,
+	}, nil
+}
+
+// EraseUser removes the user's personal data: preferences, feature flags and
+// the avatar are deleted and the user row is scrubbed and soft deleted,
+// keeping only its ID so references to it stay valid. Erasing an erased user
+// again is a no-op.
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	var avatarKey string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userModel UserModel
+		if err := tx.Unscoped().Select("id", "avatar_key").Where("id = ?", parsedUserID).First(&userModel).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return user.ErrUserNotFound
+			}
+			return err
+		}
+		avatarKey = userModel.AvatarKey
+
+		now := time.Now()
+		result := tx.Unscoped().Model(&UserModel{}).Where("id = ?", parsedUserID).Updates(map[string]interface{}{
+			"email":                       "",
+			"password_hash":               "",
+			"first_name":                  "",
+			"last_name":                   "",
+			"pending_email":               "",
+			"email_change_requested_at":   nil,
+			"email_verified_at":           nil,
+			"avatar_key":                  "",
+			"password_reset_requested_at": nil,
+			"deactivated_at":              gorm.Expr("COALESCE(deactivated_at, ?)", now),
+			"deleted_at":                  gorm.Expr("COALESCE(deleted_at, ?)", now),
+			"version":                     gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return user.ErrUserNotFound
+		}
+
+		if err := tx.Where("user_id = ?", parsedUserID).Delete(&PasswordHistoryModel{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", parsedUserID).Delete(&UserFeatureFlagModel{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", parsedUserID).Delete(&UserPreferencesModel{}).Error
+	})
 	if err != nil {
 		return err
 	}
 
-	// Update preferences
-	updates := map[string]interface{}{
+	// The image is removed once the row no longer points at it
+	if avatarKey != "" && s.avatars != nil {
+		return s.avatars.Delete(ctx, avatarKey)
+	}
+	return nil
+}
+
+// CleanupPreferences scans every stored preference row in batches for
+// notification types missing from the registry and, with opts.Strip, removes
+// them. Rows changed since they were read are left for the next run.
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = user.DefaultCleanupBatchSize
+	}
+
+	report := &user.PreferenceCleanupReport{
+		UnknownKeys:   make(map[string]int),
+		AffectedUsers: []string{},
+		Stripped:      opts.Strip,
+	}
+
+	var batch []UserPreferencesModel
+	result := s.db.WithContext(ctx).FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+		for i := range batch {
+			report.Scanned++
+
+			prefs, err := s.toDomainPreferences(&batch[i])
+			if err != nil {
+				return err
+			}
+			unknown := prefs.UnknownNotificationTypes()
+			if len(unknown) == 0 {
+				continue
+			}
+
+			report.AffectedUsers = append(report.AffectedUsers, prefs.UserID.String())
+			for _, notificationType := range unknown {
+				report.UnknownKeys[string(notificationType)]++
+				delete(prefs.NotificationTypes, notificationType)
+			}
+
+			if opts.Strip {
+				if err := s.stripNotificationTypes(ctx, &batch[i], prefs.NotificationTypes); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return report, nil
+}
+
+// GetFeatureFlags retrieves every flag switched for the user
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+	if err := s.requireUser(ctx, parsedUserID); err != nil {
+		return nil, err
+	}
+
+	var models []UserFeatureFlagModel
+	if err := s.db.WithContext(ctx).Where("user_id = ?", parsedUserID).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return toDomainFeatureFlags(parsedUserID, models), nil
+}
+
+// SetFeatureFlag stores the flag for the user, replacing any earlier setting
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+	if err := s.requireUser(ctx, parsedUserID); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "flag"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&UserFeatureFlagModel{
+		UserID:    parsedUserID,
+		Flag:      flag,
+		Enabled:   enabled,
+		UpdatedAt: time.Now(),
+	}).Error
+}
+
+// stripNotificationTypes writes the cleaned notification types back unless
+// the row was updated after it was read
+func (s *service) stripNotificationTypes(ctx context.Context, model *UserPreferencesModel, notificationTypes map[user.NotificationType]bool) error {
+	notificationTypesJSON, err := json.Marshal(notificationTypes)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&UserPreferencesModel{}).
+		Where("id = ? AND updated_at = ?", model.ID, model.UpdatedAt).
+		Updates(map[string]interface{}{
+			"notification_types": notificationTypesJSON,
+			"version":            gorm.Expr("version + 1"),
+		}).Error
+}
+
+// updatePreferences stores prefs for the user, checking the version when one
+// is given. A row that was not updated is told apart as missing or stale.
+func updatePreferences(db *gorm.DB, userID uuid.UUID, prefs user.UserPreferences) error {
+	query := db.Model(&UserPreferencesModel{}).Where("user_id = ?", userID)
+	if prefs.Version > 0 {
+		query = query.Where("version = ?", prefs.Version)
+	}
+
+	result := query.Updates(preferencesColumns(prefs))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&UserPreferencesModel{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return user.ErrPreferencesNotFound
+	}
+	return user.ErrConflict
+}
+
+// preferencesColumns returns the column updates that store prefs
+func preferencesColumns(prefs user.UserPreferences) map[string]interface{} {
+	// Notification types are stored as JSON; a map of bools always marshals
+	notificationTypesJSON, _ := json.Marshal(prefs.NotificationTypes)
+
+	return map[string]interface{}{
 		"email_notifications": prefs.EmailNotifications,
 		"push_notifications":  prefs.PushNotifications,
 		"sms_notifications":   prefs.SMSNotifications,
@@ -216,30 +1070,159 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 		"language":            prefs.Language,
 		"timezone":            prefs.Timezone,
 		"notification_types":  notificationTypesJSON,
+		"version":             gorm.Expr("version + 1"),
 	}
+}
 
-	if err := s.db.WithContext(ctx).Model(&UserPreferencesModel{}).Where("user_id = ?", parsedUserID).Updates(updates).Error; err != nil {
+// requireUser returns ErrUserNotFound unless the user exists and is not soft deleted
+func (s *service) requireUser(ctx context.Context, userID uuid.UUID) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", userID).Count(&count).Error; err != nil {
 		return err
 	}
+	if count == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// rehashPassword replaces a verified outdated hash with one of the current
+// algorithm. The version is kept, as the password itself did not change.
+func (s *service) rehashPassword(ctx context.Context, userID uuid.UUID, outdated, password string) (string, error) {
+	rehashed, err := s.hasher.Hash(ctx, password)
+	if err != nil {
+		return "", err
+	}
+
+	// A concurrent password change wins over the rehash
+	result := s.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND password_hash = ?", userID, outdated).
+		Update("password_hash", rehashed)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return outdated, nil
+	}
+	return rehashed, nil
+}
 
+// checkPasswordReuse returns ErrPasswordReused when newPassword is the user's
+// current password or one of the passwords it replaced. The current password
+// counts towards the history size.
+func (s *service) checkPasswordReuse(ctx context.Context, userModel *UserModel, newPassword string) error {
+	var history []PasswordHistoryModel
+	if s.passwordHistorySize > 1 {
+		if err := s.db.WithContext(ctx).
+			Where("user_id = ?", userModel.ID).
+			Order("created_at DESC").
+			Limit(s.passwordHistorySize - 1).
+			Find(&history).Error; err != nil {
+			return err
+		}
+	}
+	previous := append([]string{userModel.PasswordHash}, historyHashes(history)...)
+	for _, previousHash := range previous {
+		if s.hasher.Compare(ctx, previousHash, newPassword) == nil {
+			return user.ErrPasswordReused
+		}
+	}
 	return nil
 }
 
+// prunePasswordHistory drops history rows beyond what reuse checks look at;
+// the current password is the newest entry and lives on the user row
+func (s *service) prunePasswordHistory(tx *gorm.DB, userID uuid.UUID) error {
+	keep := tx.Model(&PasswordHistoryModel{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(s.passwordHistorySize - 1)
+
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&PasswordHistoryModel{}).Error
+}
+
+// historyHashes returns the password hashes of history rows
+func historyHashes(history []PasswordHistoryModel) []string {
+	hashes := make([]string, len(history))
+	for i, entry := range history {
+		hashes[i] = entry.PasswordHash
+	}
+	return hashes
+}
+
+// listSortColumns maps ListFilters.SortBy to the column it orders by
+var listSortColumns = map[string]string{
+	user.SortByCreatedAt: "created_at",
+	user.SortByEmail:     "email",
+	user.SortByFirstName: "first_name",
+	user.SortByLastName:  "last_name",
+}
+
+// likeEscaper escapes LIKE wildcards so filters match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// cursorPosition converts a decoded cursor into query arguments
+func cursorPosition(sortBy string, cursor user.ListCursor) (interface{}, uuid.UUID, error) {
+	id, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return nil, uuid.Nil, user.ErrInvalidCursor
+	}
+	if sortBy != user.SortByCreatedAt {
+		return cursor.Value, id, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, uuid.Nil, user.ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
+
 // Helper methods for converting between GORM models and domain models
 func (s *service) toDomainUser(model *UserModel) *user.User {
-	return &user.User{
-		ID:           model.ID,
-		Email:        model.Email,
-		PasswordHash: model.PasswordHash,
-		FirstName:    model.FirstName,
-		LastName:     model.LastName,
-		CreatedAt:    model.CreatedAt,
-		UpdatedAt:    model.UpdatedAt,
+	domainUser := &user.User{
+		ID:            model.ID,
+		Email:         model.Email,
+		PasswordHash:  model.PasswordHash,
+		FirstName:     model.FirstName,
+		LastName:      model.LastName,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+		DeactivatedAt: model.DeactivatedAt,
+
+		PendingEmail:             model.PendingEmail,
+		EmailChangeRequestedAt:   model.EmailChangeRequestedAt,
+		EmailVerifiedAt:          model.EmailVerifiedAt,
+		PasswordResetRequestedAt: model.PasswordResetRequestedAt,
+		AvatarKey:                model.AvatarKey,
+		Version:                  model.Version,
+	}
+	if model.DeletedAt.Valid {
+		deletedAt := model.DeletedAt.Time
+		domainUser.DeletedAt = &deletedAt
+	}
+	return domainUser
+}
+
+// toDomainFeatureFlags collects the flag rows of a user
+func toDomainFeatureFlags(userID uuid.UUID, models []UserFeatureFlagModel) *user.FeatureFlags {
+	flags := &user.FeatureFlags{UserID: userID, Flags: make(map[string]bool, len(models))}
+	for _, model := range models {
+		flags.Flags[model.Flag] = model.Enabled
+		if model.UpdatedAt.After(flags.UpdatedAt) {
+			flags.UpdatedAt = model.UpdatedAt
+		}
 	}
+	return flags
 }
 
 func (s *service) toDomainPreferences(model *UserPreferencesModel) (*user.UserPreferences, error) {
-	var notificationTypes map[string]bool
+	var notificationTypes map[user.NotificationType]bool
 	if err := json.Unmarshal(model.NotificationTypes, &notificationTypes); err != nil {
 		return nil, err
 	}
@@ -256,5 +1239,6 @@ func (s *service) toDomainPreferences(model *UserPreferencesModel) (*user.UserPr
 		NotificationTypes:  notificationTypes,
 		CreatedAt:          model.CreatedAt,
 		UpdatedAt:          model.UpdatedAt,
+		Version:            model.Version,
 	}, nil
 }
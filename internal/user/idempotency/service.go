@@ -0,0 +1,385 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/idempotency"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// DefaultTTL is how long results are replayed when no TTL is configured
+const DefaultTTL = 24 * time.Hour
+
+// DefaultLockTTL is how long an operation in progress holds its key when no
+// lock TTL is configured
+const DefaultLockTTL = time.Minute
+
+// Config contains the idempotency settings
+type Config struct {
+	// TTL is how long the result of an operation is replayed to requests
+	// reusing its key; zero uses DefaultTTL
+	TTL time.Duration
+
+	// LockTTL bounds how long an operation in progress holds its key, so a
+	// crashed instance does not block retries for the whole TTL; zero uses
+	// DefaultLockTTL
+	LockTTL time.Duration
+}
+
+// service implements user.Service with idempotency keys
+// This decorator runs mutating operations at most once per key stored with
+// user.WithIdempotencyKey and replays the stored result to retries. Keys are
+// scoped to the caller, and a key reused for a different request is rejected
+// with user.ErrIdempotencyKeyReuse. Registrations are anonymous, so their
+// keys are scoped to the client IP and the email instead. Failed operations
+// release their key so
+// the client can retry them. Reads, Login and UploadAvatar, whose content is
+// streamed, pass through.
+type service struct {
+	next    user.Service
+	store   idempotency.Service
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// NewService creates a new idempotency decorator using the default TTLs
+func NewService(next user.Service, store idempotency.Service) user.Service {
+	return NewServiceWithConfig(next, store, Config{})
+}
+
+// NewServiceWithConfig creates a new idempotency decorator with custom TTLs
+func NewServiceWithConfig(next user.Service, store idempotency.Service, config Config) user.Service {
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	lockTTL := config.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = DefaultLockTTL
+	}
+
+	return &service{
+		next:    next,
+		store:   store,
+		ttl:     ttl,
+		lockTTL: lockTTL,
+	}
+}
+
+// Register creates the user once per key. The key is scoped to the client
+// and the email, and the fingerprint covers a hash of the password, so only
+// a retry of the same registration replays the user; the password itself
+// never reaches the idempotency store.
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	scope := registrationScope(ctx, data.Email)
+	request := data
+	request.Password = passwordHash(scope, data.Password)
+
+	var result *user.User
+	err := s.doInScope(ctx, scope, "Register", request, &result, func() (err error) {
+		result, err = s.next.Register(ctx, data)
+		return err
+	})
+	return result, err
+}
+
+// Login passes through; tokens are never stored for replay
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	return s.next.Login(ctx, email, password)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile updates the profile once per key
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	request := map[string]interface{}{"id": id, "data": data}
+
+	var result *user.User
+	err := s.do(ctx, "UpdateProfile", request, &result, func() (err error) {
+		result, err = s.next.UpdateProfile(ctx, id, data)
+		return err
+	})
+	return result, err
+}
+
+// ChangePassword changes the password once per key; the passwords are left
+// out of the fingerprint so they never reach the idempotency store
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	request := map[string]interface{}{"user_id": userID}
+	return s.do(ctx, "ChangePassword", request, nil, func() error {
+		return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	})
+}
+
+// RequestPasswordReset passes through; a repeated request only sends another email
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword makes retries of a reset return the original result, rather
+// than failing because the token was already used
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	request := map[string]interface{}{"token": token}
+	return s.do(ctx, "ResetPassword", request, nil, func() error {
+		return s.next.ResetPassword(ctx, token, newPassword)
+	})
+}
+
+// RequestEmailChange sends the confirmation once per key
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	request := map[string]interface{}{"user_id": userID, "email": newEmail}
+	return s.do(ctx, "RequestEmailChange", request, nil, func() error {
+		return s.next.RequestEmailChange(ctx, userID, newEmail)
+	})
+}
+
+// ConfirmEmailChange confirms the change once per key
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	request := map[string]interface{}{"user_id": userID, "token": token}
+
+	var result *user.User
+	err := s.do(ctx, "ConfirmEmailChange", request, &result, func() (err error) {
+		result, err = s.next.ConfirmEmailChange(ctx, userID, token)
+		return err
+	})
+	return result, err
+}
+
+// VerifyEmail verifies the email once per key
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	request := map[string]interface{}{"token": token}
+
+	var result *user.User
+	err := s.do(ctx, "VerifyEmail", request, &result, func() (err error) {
+		result, err = s.next.VerifyEmail(ctx, token)
+		return err
+	})
+	return result, err
+}
+
+// UploadAvatar passes through; the streamed content cannot be fingerprinted
+// without buffering it
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences updates the preferences once per key
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	request := map[string]interface{}{"user_id": userID, "preferences": prefs}
+	return s.do(ctx, "UpdatePreferences", request, nil, func() error {
+		return s.next.UpdatePreferences(ctx, userID, prefs)
+	})
+}
+
+// UpdatePreferencesBulk applies the batch once per key
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.do(ctx, "UpdatePreferencesBulk", updates, nil, func() error {
+		return s.next.UpdatePreferencesBulk(ctx, updates)
+	})
+}
+
+// Deactivate deactivates the account once per key
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	request := map[string]interface{}{"id": id}
+	return s.do(ctx, "Deactivate", request, nil, func() error {
+		return s.next.Deactivate(ctx, id)
+	})
+}
+
+// Delete deletes the account once per key
+func (s *service) Delete(ctx context.Context, id string) error {
+	request := map[string]interface{}{"id": id}
+	return s.do(ctx, "Delete", request, nil, func() error {
+		return s.next.Delete(ctx, id)
+	})
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser erases the user's data once per key
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	request := map[string]interface{}{"user_id": userID}
+	return s.do(ctx, "EraseUser", request, nil, func() error {
+		return s.next.EraseUser(ctx, userID)
+	})
+}
+
+// CleanupPreferences passes through; repeating a cleanup changes nothing
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through; setting a flag twice changes nothing
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// do runs the operation once per idempotency key in the context, scoped to
+// the caller. The first request stores what run left in result; retries get
+// it decoded back into result without running again. Without a key run is
+// simply called.
+func (s *service) do(ctx context.Context, operation string, request, result interface{}, run func() error) error {
+	return s.doInScope(ctx, callerScope(ctx), operation, request, result, run)
+}
+
+// doInScope runs the operation like do, with the client's key in scope so
+// clients cannot see or collide with each other's keys
+func (s *service) doInScope(ctx context.Context, scope, operation string, request, result interface{}, run func() error) error {
+	key := user.IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return run()
+	}
+
+	fingerprint, err := fingerprintOf(operation, request)
+	if err != nil {
+		return err
+	}
+	key = scope + ":" + key
+
+	existing, err := s.store.Reserve(ctx, key, fingerprint, s.lockTTL)
+	if err != nil {
+		return fmt.Errorf("idempotency store error: %w", err)
+	}
+	if existing != nil {
+		return replay(existing, fingerprint, result)
+	}
+
+	if err := run(); err != nil {
+		s.release(ctx, key)
+		return err
+	}
+	s.complete(ctx, key, operation, result)
+	return nil
+}
+
+// release frees the key of a failed operation so it can be retried. It
+// outlives the request, or retries would be refused until the lock expires.
+func (s *service) release(ctx context.Context, key string) {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	if err := s.store.Release(ctx, key); err != nil {
+		log.Printf("Failed to release idempotency key: %v", err)
+	}
+}
+
+// complete stores the result of a successful operation for replay. The
+// operation already happened, so failures are only logged; retries are then
+// refused until the lock expires.
+func (s *service) complete(ctx context.Context, key, operation string, result interface{}) {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	response, err := json.Marshal(result)
+	if err == nil {
+		err = s.store.Complete(ctx, key, response, s.ttl)
+	}
+	if err != nil {
+		log.Printf("Failed to store idempotent result of %s: %v", operation, err)
+	}
+}
+
+// replay returns the outcome stored for a key, refusing keys that are still
+// in progress or were used for a different request
+func replay(record *idempotency.Record, fingerprint string, result interface{}) error {
+	if record.Fingerprint != fingerprint {
+		return user.ErrIdempotencyKeyReuse
+	}
+	if !record.IsCompleted() {
+		return user.ErrIdempotencyKeyInUse
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(record.Response, result); err != nil {
+		return fmt.Errorf("failed to decode idempotent result: %w", err)
+	}
+	return nil
+}
+
+// callerScope scopes keys to the authenticated caller
+func callerScope(ctx context.Context) string {
+	caller := "anonymous"
+	if subject, ok := authorization.SubjectFromContext(ctx); ok && subject.ID != "" {
+		caller = subject.ID
+	}
+	return "user:" + caller
+}
+
+// registrationScope scopes the keys of registrations, which have no caller,
+// to the client IP and the normalized email, so another client reusing a key
+// cannot replay someone else's registration
+func registrationScope(ctx context.Context, email string) string {
+	client := user.ClientIPFromContext(ctx)
+	if subject, ok := authorization.SubjectFromContext(ctx); ok && subject.ID != "" {
+		client = subject.ID
+	}
+	if client == "" {
+		client = "anonymous"
+	}
+	return "register:" + client + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// passwordHash stands in for the password in a fingerprint, salted with the
+// scope so equal passwords of different registrations hash apart
+func passwordHash(scope, password string) string {
+	sum := sha256.Sum256([]byte(scope + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// fingerprintOf hashes the operation and its request, so a key reused for
+// different input can be told apart from a retry
+func fingerprintOf(operation string, request interface{}) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"operation": operation,
+		"request":   request,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint %s: %w", operation, err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
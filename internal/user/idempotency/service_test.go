@@ -0,0 +1,189 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	idempotencyMemory "github.com/gentra/decorator-arch-go/internal/idempotency/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userIdempotency "github.com/gentra/decorator-arch-go/internal/user/idempotency"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+func TestRegister_GivenRepeatedKey_WhenRegistering_ThenReplaysFirstResult(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	registered := testkit.NewUserBuilder().Build()
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	next.On("Register", mock.Anything, data).Return(registered, nil).Once()
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+	ctx := user.WithIdempotencyKey(context.Background(), "signup-1")
+
+	// Act
+	first, err := service.Register(ctx, data)
+	require.NoError(t, err)
+	second, err := service.Register(ctx, data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, first.Email, second.Email)
+	next.AssertNumberOfCalls(t, "Register", 1)
+}
+
+func TestRegister_GivenNoKey_WhenRegistering_ThenRunsEveryTime(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	next.On("Register", mock.Anything, data).Return(testkit.NewUserBuilder().Build(), nil)
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+
+	// Act
+	_, err := service.Register(context.Background(), data)
+	require.NoError(t, err)
+	_, err = service.Register(context.Background(), data)
+
+	// Assert
+	require.NoError(t, err)
+	next.AssertNumberOfCalls(t, "Register", 2)
+}
+
+func TestRegister_GivenKeyReusedForOtherPassword_WhenRegistering_ThenReturnsKeyReuse(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Register", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+	ctx := user.WithIdempotencyKey(context.Background(), "signup-1")
+
+	_, err := service.Register(ctx, testkit.NewUserBuilder().BuildRegisterData())
+	require.NoError(t, err)
+
+	// Act
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	data.Password = "An0therPassw0rd!"
+	_, err = service.Register(ctx, data)
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrIdempotencyKeyReuse)
+	next.AssertNumberOfCalls(t, "Register", 1)
+}
+
+func TestRegister_GivenKeyReusedByOtherClient_WhenRegistering_ThenDoesNotReplay(t *testing.T) {
+	tests := []struct {
+		name  string
+		ip    string
+		email string
+	}{
+		{
+			name:  "Given same key from another IP, When registering, Then runs again",
+			ip:    "198.51.100.2",
+			email: "test@example.com",
+		},
+		{
+			name:  "Given same key for another email, When registering, Then runs again",
+			ip:    "198.51.100.1",
+			email: "other@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := &userMock.MockUserService{}
+			next.On("Register", mock.Anything, mock.Anything).Return(testkit.NewUserBuilder().Build(), nil)
+			service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+			first := user.WithIdempotencyKey(user.WithClientIP(context.Background(), "198.51.100.1"), "signup-1")
+			_, err := service.Register(first, testkit.NewUserBuilder().WithEmail("test@example.com").BuildRegisterData())
+			require.NoError(t, err)
+
+			// Act
+			second := user.WithIdempotencyKey(user.WithClientIP(context.Background(), tt.ip), "signup-1")
+			_, err = service.Register(second, testkit.NewUserBuilder().WithEmail(tt.email).BuildRegisterData())
+
+			// Assert
+			require.NoError(t, err)
+			next.AssertNumberOfCalls(t, "Register", 2)
+		})
+	}
+}
+
+func TestRegister_GivenFirstAttemptFailed_WhenRetrying_ThenRunsAgain(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	next.On("Register", mock.Anything, data).Return(nil, user.ErrServiceUnavailable).Once()
+	next.On("Register", mock.Anything, data).Return(testkit.NewUserBuilder().Build(), nil).Once()
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+	ctx := user.WithIdempotencyKey(context.Background(), "signup-1")
+
+	_, err := service.Register(ctx, data)
+	require.ErrorIs(t, err, user.ErrServiceUnavailable)
+
+	// Act
+	registered, err := service.Register(ctx, data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, registered)
+	next.AssertNumberOfCalls(t, "Register", 2)
+}
+
+func TestRegister_GivenFirstAttemptInProgress_WhenRetrying_ThenReturnsKeyInUse(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	next.On("Register", mock.Anything, data).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(testkit.NewUserBuilder().Build(), nil).Once()
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+	ctx := user.WithIdempotencyKey(context.Background(), "signup-1")
+
+	done := make(chan error)
+	go func() {
+		_, err := service.Register(ctx, data)
+		done <- err
+	}()
+	<-started
+
+	// Act
+	_, err := service.Register(ctx, data)
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrIdempotencyKeyInUse)
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestUpdatePreferences_GivenSameKeyFromTwoCallers_WhenUpdating_ThenRunsForEach(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("UpdatePreferences", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	service := userIdempotency.NewService(next, idempotencyMemory.NewService())
+	prefs := testkit.NewPreferencesBuilder().Build()
+
+	callers := []string{"user-1", "user-2"}
+	var errs []error
+
+	// Act
+	for _, caller := range callers {
+		ctx := authorization.WithSubject(context.Background(), authorization.Subject{ID: caller})
+		ctx = user.WithIdempotencyKey(ctx, "prefs-1")
+		errs = append(errs, service.UpdatePreferences(ctx, caller, *prefs))
+	}
+
+	// Assert
+	assert.NoError(t, errors.Join(errs...))
+	next.AssertNumberOfCalls(t, "UpdatePreferences", 2)
+}
@@ -0,0 +1,334 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/lockout"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config controls when accounts and IP addresses are locked and for how long
+type Config struct {
+	MaxAttempts      int           // Failed logins per email before the account is locked
+	MaxAttemptsPerIP int           // Failed logins per client IP before the IP is locked; negative disables it
+	Window           time.Duration // Time over which failed logins are counted
+	CoolDown         time.Duration // Time after which a lock lifts by itself
+}
+
+// DefaultConfig returns the default lockout configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:      5,
+		MaxAttemptsPerIP: 20,
+		Window:           15 * time.Minute,
+		CoolDown:         15 * time.Minute,
+	}
+}
+
+// Service is the user service with account lockout, adding the admin
+// operation that lifts a lock before its cool-down ends
+type Service interface {
+	user.Service
+
+	// Unlock lifts the lock on the account with email and forgets its
+	// failed logins
+	Unlock(ctx context.Context, email string) error
+}
+
+// service implements Service by counting failed logins
+// This decorator counts logins failing with user.ErrInvalidCredentials per
+// email and per client IP in a lockout.Service. Once either reaches its limit
+// within the window, logins for it fail with user.ErrAccountLocked without
+// reaching the next layer until the cool-down ends. A successful login
+// forgets the email's failures. Locks and unlocks are audited. Every other
+// method passes through.
+type service struct {
+	next         user.Service
+	store        lockout.Service
+	auditService audit.Service
+	config       Config
+}
+
+// NewService creates a new lockout decorator; auditService may be nil when
+// auditing is disabled
+func NewService(next user.Service, store lockout.Service, auditService audit.Service, config Config) Service {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.MaxAttemptsPerIP == 0 {
+		config.MaxAttemptsPerIP = defaults.MaxAttemptsPerIP
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = defaults.CoolDown
+	}
+
+	return &service{
+		next:         next,
+		store:        store,
+		auditService: auditService,
+		config:       config,
+	}
+}
+
+// Login refuses locked emails and IPs and counts failed attempts
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	subjects := s.subjects(ctx, email)
+	for _, subject := range subjects {
+		lockedUntil, err := s.store.LockedUntil(ctx, subject.key())
+		if err != nil {
+			return nil, fmt.Errorf("lockout store error: %w", err)
+		}
+		if !lockedUntil.IsZero() {
+			return nil, user.ErrAccountLocked
+		}
+	}
+
+	result, err := s.next.Login(ctx, email, password)
+	if errors.Is(err, user.ErrInvalidCredentials) {
+		if s.recordFailure(ctx, subjects) {
+			return nil, user.ErrAccountLocked
+		}
+		return nil, err
+	}
+	if err == nil {
+		if resetErr := s.store.Reset(ctx, subjects[0].key()); resetErr != nil {
+			log.Printf("Failed to reset failed logins: %v", resetErr)
+		}
+	}
+	return result, err
+}
+
+// Unlock lifts the lock on the email and records who lifted it
+func (s *service) Unlock(ctx context.Context, email string) error {
+	subject := lockSubject{scope: "email", value: normalizeEmail(email)}
+
+	lockedUntil, err := s.store.LockedUntil(ctx, subject.key())
+	if err == nil {
+		err = s.store.Reset(ctx, subject.key())
+	}
+	if err != nil {
+		err = fmt.Errorf("lockout store error: %w", err)
+	}
+
+	s.logAuditEntry(ctx, "user.account_unlocked", subject, map[string]interface{}{
+		"scope":      subject.scope,
+		"was_locked": !lockedUntil.IsZero(),
+	}, err)
+	return err
+}
+
+// Register passes through
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	return s.next.Register(ctx, data)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile passes through
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword passes through; a wrong current password is not a failed login
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset passes through
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange passes through
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange passes through
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar passes through
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences passes through
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk passes through
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate passes through
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete passes through
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser passes through
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences passes through
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// Helper methods
+
+// lockSubject is an email or client IP whose failed logins are counted
+type lockSubject struct {
+	scope       string
+	value       string
+	maxAttempts int
+}
+
+// key returns the key the subject is stored under
+func (l lockSubject) key() string {
+	return l.scope + ":" + l.value
+}
+
+// subjects returns the email, always first, and the client IP when it is
+// known and tracked
+func (s *service) subjects(ctx context.Context, email string) []lockSubject {
+	subjects := []lockSubject{{scope: "email", value: normalizeEmail(email), maxAttempts: s.config.MaxAttempts}}
+	if ip := user.ClientIPFromContext(ctx); ip != "" && s.config.MaxAttemptsPerIP > 0 {
+		subjects = append(subjects, lockSubject{scope: "ip", value: ip, maxAttempts: s.config.MaxAttemptsPerIP})
+	}
+	return subjects
+}
+
+// recordFailure counts a failed login against every subject, locks those
+// that reached their limit and reports whether any got locked. The login
+// already failed, so store errors are only logged.
+func (s *service) recordFailure(ctx context.Context, subjects []lockSubject) bool {
+	locked := false
+	for _, subject := range subjects {
+		failures, err := s.store.RecordFailure(ctx, subject.key(), s.config.Window)
+		if err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+			continue
+		}
+		if failures < subject.maxAttempts {
+			continue
+		}
+
+		err = s.store.Lock(ctx, subject.key(), s.config.CoolDown)
+		if err != nil {
+			log.Printf("Failed to lock %s after failed logins: %v", subject.scope, err)
+		} else {
+			locked = true
+		}
+		s.logAuditEntry(ctx, "user.account_locked", subject, map[string]interface{}{
+			"scope":        subject.scope,
+			"failures":     failures,
+			"locked_until": time.Now().Add(s.config.CoolDown),
+		}, err)
+	}
+	return locked
+}
+
+// logAuditEntry records a lock or unlock; audit failures never fail the login
+func (s *service) logAuditEntry(ctx context.Context, action string, subject lockSubject, details map[string]interface{}, err error) {
+	if s.auditService == nil {
+		return
+	}
+
+	auditCtx := audit.ExtractAuditContext(ctx)
+	entry := audit.AuditEntry{
+		Timestamp:     time.Now(),
+		UserID:        auditCtx.CurrentUserID,
+		Action:        action,
+		Resource:      "account_lockout",
+		ResourceID:    subject.value,
+		Details:       details,
+		Success:       err == nil,
+		IPAddress:     auditCtx.IPAddress,
+		UserAgent:     auditCtx.UserAgent,
+		SessionID:     auditCtx.SessionID,
+		CorrelationID: audit.ExtractCorrelationID(ctx),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+	if logErr := s.auditService.Log(ctx, entry); logErr != nil {
+		log.Printf("Failed to audit %s: %v", action, logErr)
+	}
+}
+
+// normalizeEmail folds the spellings of an email into one key
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,174 @@
+package lockout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	auditMock "github.com/gentra/decorator-arch-go/internal/audit/mock"
+	lockoutMemory "github.com/gentra/decorator-arch-go/internal/lockout/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userLockout "github.com/gentra/decorator-arch-go/internal/user/lockout"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+const email = "jane@example.com"
+
+func newConfig() userLockout.Config {
+	return userLockout.Config{MaxAttempts: 3, MaxAttemptsPerIP: 5, Window: time.Minute, CoolDown: time.Minute}
+}
+
+func failLogins(t *testing.T, service user.Service, ctx context.Context, email string, attempts int) {
+	t.Helper()
+	for i := 0; i < attempts; i++ {
+		_, err := service.Login(ctx, email, "wrong")
+		require.Error(t, err)
+	}
+}
+
+func TestLogin_GivenRepeatedFailures_WhenReachingLimit_ThenLocksAndAuditsAccount(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, "wrong").Return(nil, user.ErrInvalidCredentials)
+	auditSvc := &auditMock.MockAuditService{}
+	var logged audit.AuditEntry
+	auditSvc.On("Log", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { logged = args.Get(1).(audit.AuditEntry) }).
+		Return(nil).Once()
+	service := userLockout.NewService(next, lockoutMemory.NewService(), auditSvc, newConfig())
+	failLogins(t, service, context.Background(), email, 2)
+
+	// Act
+	_, third := service.Login(context.Background(), email, "wrong")
+	_, fourth := service.Login(context.Background(), email, "correct")
+
+	// Assert
+	assert.ErrorIs(t, third, user.ErrAccountLocked)
+	assert.ErrorIs(t, fourth, user.ErrAccountLocked)
+	next.AssertNumberOfCalls(t, "Login", 3)
+	auditSvc.AssertExpectations(t)
+	assert.Equal(t, "user.account_locked", logged.Action)
+	assert.Equal(t, email, logged.ResourceID)
+	assert.Equal(t, 3, logged.Details.(map[string]interface{})["failures"])
+}
+
+func TestLogin_GivenMixedCaseEmail_WhenFailing_ThenCountsAgainstSameAccount(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, "wrong").Return(nil, user.ErrInvalidCredentials)
+	service := userLockout.NewService(next, lockoutMemory.NewService(), nil, newConfig())
+	failLogins(t, service, context.Background(), "Jane@Example.com", 3)
+
+	// Act
+	_, err := service.Login(context.Background(), " jane@example.com", "wrong")
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrAccountLocked)
+	next.AssertNumberOfCalls(t, "Login", 3)
+}
+
+func TestLogin_GivenSuccessfulLogin_WhenFailingAgain_ThenStartsCountingOver(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, "wrong").Return(nil, user.ErrInvalidCredentials)
+	next.On("Login", mock.Anything, email, "correct").Return(&user.AuthResult{User: testkit.NewUserBuilder().Build()}, nil)
+	service := userLockout.NewService(next, lockoutMemory.NewService(), nil, newConfig())
+	failLogins(t, service, context.Background(), email, 2)
+
+	_, err := service.Login(context.Background(), email, "correct")
+	require.NoError(t, err)
+
+	// Act
+	_, err = service.Login(context.Background(), email, "wrong")
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+}
+
+func TestLogin_GivenOtherFailures_WhenLoggingIn_ThenDoesNotCountThem(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, mock.Anything).Return(nil, user.ErrServiceUnavailable)
+	service := userLockout.NewService(next, lockoutMemory.NewService(), nil, newConfig())
+	failLogins(t, service, context.Background(), email, 5)
+
+	// Act
+	_, err := service.Login(context.Background(), email, "correct")
+
+	// Assert
+	assert.ErrorIs(t, err, user.ErrServiceUnavailable)
+}
+
+func TestLogin_GivenFailuresAcrossEmailsFromOneIP_WhenReachingIPLimit_ThenLocksIP(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, "wrong").Return(nil, user.ErrInvalidCredentials)
+	service := userLockout.NewService(next, lockoutMemory.NewService(), nil, newConfig())
+	ctx := user.WithClientIP(context.Background(), "203.0.113.7")
+	for _, target := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		failLogins(t, service, ctx, target, 1)
+	}
+
+	// Act
+	_, fromIP := service.Login(ctx, "f@example.com", "wrong")
+	_, elsewhere := service.Login(user.WithClientIP(context.Background(), "198.51.100.1"), "f@example.com", "wrong")
+
+	// Assert
+	assert.ErrorIs(t, fromIP, user.ErrAccountLocked)
+	assert.ErrorIs(t, elsewhere, user.ErrInvalidCredentials)
+}
+
+func TestLogin_GivenLockedAccount_WhenCoolDownPasses_ThenUnlocksByItself(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	config := newConfig()
+	config.CoolDown = 20 * time.Millisecond
+	service := userLockout.NewService(next, lockoutMemory.NewService(), nil, config)
+	failLogins(t, service, context.Background(), email, 3)
+
+	// Act
+	time.Sleep(30 * time.Millisecond)
+	_, err := service.Login(context.Background(), email, "wrong")
+
+	// Assert
+	assert.NotErrorIs(t, err, user.ErrAccountLocked)
+	next.AssertNumberOfCalls(t, "Login", 4)
+}
+
+func TestUnlock_GivenLockedAccount_WhenAdminUnlocks_ThenLiftsLockAndAuditsIt(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, email, "wrong").Return(nil, user.ErrInvalidCredentials)
+	next.On("Login", mock.Anything, email, "correct").Return(&user.AuthResult{User: testkit.NewUserBuilder().Build()}, nil)
+	auditSvc := &auditMock.MockAuditService{}
+	auditSvc.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		return entry.Action == "user.account_locked"
+	})).Return(nil)
+	auditSvc.On("Log", mock.Anything, mock.MatchedBy(func(entry audit.AuditEntry) bool {
+		details := entry.Details.(map[string]interface{})
+		return entry.Action == "user.account_unlocked" &&
+			entry.UserID == "admin-1" &&
+			entry.ResourceID == email &&
+			entry.Success &&
+			details["was_locked"] == true
+	})).Return(nil).Once()
+	service := userLockout.NewService(next, lockoutMemory.NewService(), auditSvc, newConfig())
+	failLogins(t, service, context.Background(), email, 3)
+	ctx := audit.WithAuditContext(context.Background(), "admin-1", "", "", "")
+
+	// Act
+	err := service.Unlock(ctx, "JANE@example.com")
+	require.NoError(t, err)
+	_, loginErr := service.Login(context.Background(), email, "correct")
+
+	// Assert
+	assert.NoError(t, loginErr)
+	auditSvc.AssertExpectations(t)
+}
@@ -0,0 +1,94 @@
+package memorycache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a cached value and the time it stops being served
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lru is a size-bounded cache that evicts the least recently used entry once
+// it is full. Expired entries are dropped lazily, when they are read or evicted.
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // front is the most recently used
+}
+
+// newLRU creates a cache holding at most capacity entries
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns the value stored under key unless it has expired
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	cached := element.Value.(*entry)
+	if !time.Now().Before(cached.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return cached.value, true
+}
+
+// set stores value under key for ttl, evicting the least recently used entry when full
+func (c *lru) set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := c.items[key]; ok {
+		cached := element.Value.(*entry)
+		cached.value = value
+		cached.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// delete drops the keys; missing keys are ignored
+func (c *lru) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// remove unlinks the element; the caller holds the lock
+func (c *lru) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry).key)
+}
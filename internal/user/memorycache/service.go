@@ -0,0 +1,665 @@
+package memorycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config holds the size and TTLs of the in-process cache
+type Config struct {
+	// MaxEntries bounds users, preferences, feature flags and list pages
+	// together; the least recently used entry is evicted once the cache is full
+	MaxEntries int
+
+	User         time.Duration // GetByID and users cached after Register/Login/UpdateProfile
+	Preferences  time.Duration // GetPreferences
+	FeatureFlags time.Duration // GetFeatureFlags
+	List         time.Duration // List pages; defaults to DefaultListCacheTTL
+
+	// NotFound is how long GetByID remembers that a user does not exist; zero
+	// disables negative caching
+	NotFound time.Duration
+
+	// WriteThrough caches the users returned by Register and UpdateProfile so
+	// the next read is a hit; without it they are only invalidated
+	WriteThrough bool
+
+	// Broadcaster, when set, tells the caches of other instances to drop a
+	// user after it changes here, so the cache can sit in front of a shared
+	// Redis cache as a short-lived first tier
+	Broadcaster Broadcaster
+}
+
+// Broadcaster relays invalidations between the caches of several instances
+type Broadcaster interface {
+	// Publish announces that the user's data changed on this instance
+	Publish(ctx context.Context, userID string) error
+
+	// Subscribe calls drop for every user changed on another instance until
+	// ctx is done or the broadcaster's connection is closed
+	Subscribe(ctx context.Context, drop func(userID string)) error
+}
+
+// DefaultMaxEntries bounds the cache when Config.MaxEntries is not set
+const DefaultMaxEntries = 10000
+
+// DefaultListCacheTTL is how long a List page is cached. Pages are not
+// invalidated on writes, so they are only kept briefly.
+const DefaultListCacheTTL = 30 * time.Second
+
+// DefaultConfig returns a write-through cache of DefaultMaxEntries entries
+func DefaultConfig() Config {
+	return Config{
+		MaxEntries:   DefaultMaxEntries,
+		User:         5 * time.Minute,
+		Preferences:  5 * time.Minute,
+		FeatureFlags: 5 * time.Minute,
+		List:         DefaultListCacheTTL,
+		WriteThrough: true,
+	}
+}
+
+// notFoundMarker is cached in place of a user that does not exist. It is not
+// valid JSON, so it can never be mistaken for a cached user.
+var notFoundMarker = []byte("!not_found")
+
+// service implements the user.Service interface with an in-process LRU cache.
+// Entries are stored serialized, like the Redis decorator, so callers never
+// share a cached value. Each instance has its own cache, so without a
+// Broadcaster writes made by other instances are only seen once the local
+// entries expire.
+type service struct {
+	next   user.Service
+	cache  *lru
+	config Config
+}
+
+// NewService creates a new in-memory cached user service
+func NewService(next user.Service, config Config) user.Service {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+	if config.List <= 0 {
+		config.List = DefaultListCacheTTL
+	}
+
+	s := &service{
+		next:   next,
+		cache:  newLRU(config.MaxEntries),
+		config: config,
+	}
+
+	// The subscription lives as long as the broadcaster's connection
+	if config.Broadcaster != nil {
+		if err := config.Broadcaster.Subscribe(context.Background(), s.invalidateUser); err != nil {
+			fmt.Printf("Failed to subscribe to cache invalidations: %v\n", err)
+		}
+	}
+
+	return s
+}
+
+// Register creates a new user (cache aside pattern)
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	// Call next service to create user
+	result, err := s.next.Register(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the newly created user
+	if s.config.WriteThrough {
+		s.cacheUser(result)
+	}
+
+	return result, nil
+}
+
+// Login authenticates a user; credentials are never cached
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	result, err := s.next.Login(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the user data after successful login
+	if result.User != nil {
+		s.cacheUser(result.User)
+	}
+
+	return result, nil
+}
+
+// GetByID retrieves a user by ID (cache aside pattern)
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshUser(ctx, id)
+	}
+
+	if cached, ok := s.cache.get(s.getUserCacheKey(id)); ok {
+		// Cache hit - the user is known not to exist. Soft-deleted users
+		// are cached as missing, so reads that include them look again.
+		if isNotFound(cached) {
+			if !user.IsDeletedIncluded(ctx) {
+				return nil, user.ErrUserNotFound
+			}
+			return s.refreshUser(ctx, id)
+		}
+
+		var cachedUser user.User
+		if err := json.Unmarshal(cached, &cachedUser); err == nil {
+			return &cachedUser, nil
+		}
+	}
+
+	// Cache miss - get from next service
+	return s.refreshUser(ctx, id)
+}
+
+// GetByIDs retrieves several users; only the users missing from the cache
+// are loaded from the next service, and those are cached again
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	if user.IsCacheBypassed(ctx) || len(ids) == 0 {
+		return s.refreshUsers(ctx, ids)
+	}
+
+	result := make(map[string]*user.User, len(ids))
+	var misses []string
+	for _, id := range ids {
+		if cached, ok := s.cache.get(s.getUserCacheKey(id)); ok {
+			// Users known not to exist are left out, as the next layer would
+			if isNotFound(cached) {
+				if !user.IsDeletedIncluded(ctx) {
+					continue
+				}
+			} else {
+				var cachedUser user.User
+				if err := json.Unmarshal(cached, &cachedUser); err == nil {
+					result[id] = &cachedUser
+					continue
+				}
+			}
+		}
+		misses = append(misses, id)
+	}
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	loaded, err := s.refreshUsers(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for id, u := range loaded {
+		result[id] = u
+	}
+	return result, nil
+}
+
+// List retrieves a page of users (cache aside pattern). Pages are keyed by
+// the filters and expire after a short TTL instead of being invalidated.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshList(ctx, filters)
+	}
+
+	if cached, ok := s.cache.get(s.getListCacheKey(ctx, filters)); ok {
+		var cachedPage user.Page
+		if err := json.Unmarshal(cached, &cachedPage); err == nil {
+			return &cachedPage, nil
+		}
+	}
+
+	return s.refreshList(ctx, filters)
+}
+
+// UpdateProfile updates user profile (cache invalidation pattern)
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	result, err := s.next.UpdateProfile(ctx, id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the cached user, or drop it so the next read loads the update
+	if s.config.WriteThrough {
+		s.cacheUser(result)
+	} else {
+		s.cache.delete(s.getUserCacheKey(id))
+	}
+	s.broadcast(ctx, id)
+
+	return result, nil
+}
+
+// ChangePassword changes a user's password (cache invalidation pattern)
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the new updated_at is visible
+	s.cache.delete(s.getUserCacheKey(userID))
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// RequestPasswordReset invalidates the cached user, whose reset request changed
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := result.ID.String()
+	s.cache.delete(s.getUserCacheKey(userID))
+	s.broadcast(ctx, userID)
+
+	return result, nil
+}
+
+// ResetPassword invalidates the cached user once the password was reset
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := s.next.ResetPassword(ctx, token, newPassword); err != nil {
+		return err
+	}
+
+	if userID := user.PasswordResetFromContext(ctx); userID != "" {
+		s.cache.delete(s.getUserCacheKey(userID))
+		s.broadcast(ctx, userID)
+	}
+
+	return nil
+}
+
+// RequestEmailChange records a pending email (cache invalidation pattern)
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	if err := s.next.RequestEmailChange(ctx, userID, newEmail); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the pending email is visible
+	s.cache.delete(s.getUserCacheKey(userID))
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending email (cache invalidation pattern)
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the cached user with the one holding the new email
+	s.cacheUser(result)
+	s.broadcast(ctx, userID)
+
+	return result, nil
+}
+
+// VerifyEmail marks the user's email verified (cache invalidation pattern)
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	result, err := s.next.VerifyEmail(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the cached user with the verified one
+	s.cacheUser(result)
+	s.broadcast(ctx, result.ID.String())
+
+	return result, nil
+}
+
+// UploadAvatar stores a new avatar (cache invalidation pattern)
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	if err := s.next.UploadAvatar(ctx, userID, content, contentType); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the new avatar key is visible
+	s.cache.delete(s.getUserCacheKey(userID))
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// GetAvatarURL returns a link to the avatar; links may be signed and expire, so they are not cached
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences retrieves user preferences (cache aside pattern)
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshPreferences(ctx, userID)
+	}
+
+	if cached, ok := s.cache.get(s.getPreferencesCacheKey(userID)); ok {
+		var cachedPrefs user.UserPreferences
+		if err := json.Unmarshal(cached, &cachedPrefs); err == nil {
+			return &cachedPrefs, nil
+		}
+	}
+
+	// Cache miss - get from next service
+	return s.refreshPreferences(ctx, userID)
+}
+
+// UpdatePreferences updates user preferences and caches the new values
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	if err := s.next.UpdatePreferences(ctx, userID, prefs); err != nil {
+		return err
+	}
+
+	s.storeUpdatedPreferences(userID, prefs)
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// UpdatePreferencesBulk updates several users' preferences and caches the new values
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	if err := s.next.UpdatePreferencesBulk(ctx, updates); err != nil {
+		return err
+	}
+
+	for userID, prefs := range updates {
+		s.storeUpdatedPreferences(userID, prefs)
+		s.broadcast(ctx, userID)
+	}
+
+	return nil
+}
+
+// Deactivate deactivates a user (cache invalidation pattern)
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	if err := s.next.Deactivate(ctx, id); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the deactivation is visible
+	s.cache.delete(s.getUserCacheKey(id))
+	s.broadcast(ctx, id)
+
+	return nil
+}
+
+// Delete soft deletes a user (cache invalidation pattern)
+func (s *service) Delete(ctx context.Context, id string) error {
+	if err := s.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Invalidate every cached read of the user so reads return not found
+	s.invalidateUser(id)
+	s.broadcast(ctx, id)
+
+	return nil
+}
+
+// ExportUserData exports a user's data; exports are never served from cache
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser erases a user's personal data (cache invalidation pattern)
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	if err := s.next.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// Cached copies hold the personal data that was just erased
+	s.invalidateUser(userID)
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// CleanupPreferences removes stale notification types and drops the cached
+// preferences of every user whose preferences were stripped
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	report, err := s.next.CleanupPreferences(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Stripped {
+		for _, userID := range report.AffectedUsers {
+			s.cache.delete(s.getPreferencesCacheKey(userID))
+			s.broadcast(ctx, userID)
+		}
+	}
+
+	return report, nil
+}
+
+// GetFeatureFlags retrieves the user's feature flags (cache aside pattern)
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshFeatureFlags(ctx, userID)
+	}
+
+	if cached, ok := s.cache.get(s.getFeatureFlagsCacheKey(userID)); ok {
+		var cachedFlags user.FeatureFlags
+		if err := json.Unmarshal(cached, &cachedFlags); err == nil {
+			return &cachedFlags, nil
+		}
+	}
+
+	// Cache miss - get from next service
+	return s.refreshFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag switches a feature flag (cache invalidation pattern)
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	if err := s.next.SetFeatureFlag(ctx, userID, flag, enabled); err != nil {
+		return err
+	}
+
+	// Drop the cached flags so the next check sees the change
+	s.cache.delete(s.getFeatureFlagsCacheKey(userID))
+	s.broadcast(ctx, userID)
+
+	return nil
+}
+
+// Helper methods for caching operations
+//
+// Reads that miss the cache abort promptly once the caller has gone away
+// instead of querying the next layer, as in the Redis decorator. Cache writes
+// are in-process and cannot be interrupted; broadcasts run on a detached
+// context so other instances still hear of a change the caller abandoned.
+
+// refreshUser loads the user from the next service and repopulates the cache
+func (s *service) refreshUser(ctx context.Context, id string) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByID(ctx, id)
+	if errors.Is(err, user.ErrUserNotFound) && !user.IsDeletedIncluded(ctx) {
+		s.cacheNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheUser(result)
+
+	return result, nil
+}
+
+// refreshUsers loads users from the next service and repopulates the cache
+func (s *service) refreshUsers(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range result {
+		s.cacheUser(u)
+	}
+	if !user.IsDeletedIncluded(ctx) {
+		for _, id := range ids {
+			if _, found := result[id]; !found {
+				s.cacheNotFound(id)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// refreshPreferences loads preferences from the next service and repopulates the cache
+func (s *service) refreshPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cachePreferences(userID, result)
+
+	return result, nil
+}
+
+// refreshFeatureFlags loads feature flags from the next service and repopulates the cache
+func (s *service) refreshFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetFeatureFlags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheValue(s.getFeatureFlagsCacheKey(userID), result, s.config.FeatureFlags)
+
+	return result, nil
+}
+
+// refreshList loads a page from the next service and caches it
+func (s *service) refreshList(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheValue(s.getListCacheKey(ctx, filters), result, s.config.List)
+
+	return result, nil
+}
+
+func (s *service) cacheUser(u *user.User) {
+	// Never cache soft-deleted users; they are only read with an include-deleted
+	// context and must not be served to regular reads
+	if u.IsDeleted() {
+		return
+	}
+
+	s.cacheValue(s.getUserCacheKey(u.ID.String()), u, s.config.User)
+}
+
+// cacheNotFound remembers that the user does not exist for Config.NotFound
+func (s *service) cacheNotFound(id string) {
+	s.cache.set(s.getUserCacheKey(id), notFoundMarker, s.config.NotFound)
+}
+
+func (s *service) cachePreferences(userID string, prefs *user.UserPreferences) {
+	s.cacheValue(s.getPreferencesCacheKey(userID), prefs, s.config.Preferences)
+}
+
+// storeUpdatedPreferences caches the preferences a successful update stored,
+// or drops the cached copy when their new version is unknown
+func (s *service) storeUpdatedPreferences(userID string, prefs user.UserPreferences) {
+	updated, ok := prefs.Updated()
+	if !ok {
+		s.cache.delete(s.getPreferencesCacheKey(userID))
+		return
+	}
+	s.cachePreferences(userID, &updated)
+}
+
+// cacheValue serializes the value so later reads cannot alias the caller's copy
+func (s *service) cacheValue(cacheKey string, value any, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		fmt.Printf("Failed to cache %s: %v\n", cacheKey, err)
+		return
+	}
+	s.cache.set(cacheKey, data, ttl)
+}
+
+// broadcast tells other instances to drop their cached copies of the user
+func (s *service) broadcast(ctx context.Context, userID string) {
+	if s.config.Broadcaster == nil {
+		return
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	if err := s.config.Broadcaster.Publish(ctx, userID); err != nil {
+		fmt.Printf("Failed to broadcast cache invalidation for user %s: %v\n", userID, err)
+	}
+}
+
+// invalidateUser drops every cached read of the user
+func (s *service) invalidateUser(userID string) {
+	s.cache.delete(s.getUserCacheKey(userID), s.getPreferencesCacheKey(userID), s.getFeatureFlagsCacheKey(userID))
+}
+
+// isNotFound reports whether a cached value is the not-found marker
+func isNotFound(cached []byte) bool {
+	return string(cached) == string(notFoundMarker)
+}
+
+func (s *service) getUserCacheKey(userID string) string {
+	return fmt.Sprintf("user:%s", userID)
+}
+
+func (s *service) getPreferencesCacheKey(userID string) string {
+	return fmt.Sprintf("user_preferences:%s", userID)
+}
+
+func (s *service) getFeatureFlagsCacheKey(userID string) string {
+	return fmt.Sprintf("user_feature_flags:%s", userID)
+}
+
+// getListCacheKey hashes the filters into a fixed-size key. Reads that include
+// deleted users are cached separately.
+func (s *service) getListCacheKey(ctx context.Context, filters user.ListFilters) string {
+	data, _ := json.Marshal(struct {
+		Filters        user.ListFilters `json:"filters"`
+		IncludeDeleted bool             `json:"include_deleted"`
+	}{filters, user.IsDeletedIncluded(ctx)})
+
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("user_list:%s", hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,399 @@
+package memorycache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/user/memorycache"
+	usermock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	"github.com/gentra/decorator-arch-go/internal/user/sqlite"
+	"github.com/gentra/decorator-arch-go/internal/user/usertest"
+)
+
+func newTestUser(id string) *user.User {
+	return &user.User{ID: uuid.MustParse(id), Email: "cached@example.com", FirstName: "Jane"}
+}
+
+func TestMemoryCacheService_GetByID(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440001"
+
+	tests := []struct {
+		name          string
+		config        memorycache.Config
+		ctx           func() context.Context
+		wait          time.Duration
+		expectedCalls int
+	}{
+		{
+			name:          "Given a cached user, When GetByID is called again, Then should serve it without calling next service",
+			config:        memorycache.DefaultConfig(),
+			ctx:           context.Background,
+			expectedCalls: 1,
+		},
+		{
+			name:          "Given a cached user, When GetByID bypasses the cache, Then should fetch from next service",
+			config:        memorycache.DefaultConfig(),
+			ctx:           func() context.Context { return user.WithCacheBypass(context.Background()) },
+			expectedCalls: 2,
+		},
+		{
+			name:          "Given a cached user has expired, When GetByID is called again, Then should fetch from next service",
+			config:        memorycache.Config{User: 20 * time.Millisecond},
+			ctx:           context.Background,
+			wait:          40 * time.Millisecond,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockNext := new(usermock.MockUserService)
+			cache := memorycache.NewService(mockNext, tt.config)
+			mockNext.On("GetByID", mock.Anything, userID).Return(newTestUser(userID), nil)
+
+			// Act
+			_, err := cache.GetByID(context.Background(), userID)
+			require.NoError(t, err)
+			time.Sleep(tt.wait)
+			result, err := cache.GetByID(tt.ctx(), userID)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "cached@example.com", result.Email)
+			mockNext.AssertNumberOfCalls(t, "GetByID", tt.expectedCalls)
+		})
+	}
+}
+
+func TestMemoryCacheService_Eviction(t *testing.T) {
+	t.Run("Given a full cache, When another user is cached, Then should evict the least recently used user", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		config := memorycache.DefaultConfig()
+		config.MaxEntries = 2
+		cache := memorycache.NewService(mockNext, config)
+
+		ids := []string{
+			"550e8400-e29b-41d4-a716-446655440010",
+			"550e8400-e29b-41d4-a716-446655440011",
+			"550e8400-e29b-41d4-a716-446655440012",
+		}
+		for _, id := range ids {
+			mockNext.On("GetByID", mock.Anything, id).Return(newTestUser(id), nil)
+		}
+		ctx := context.Background()
+
+		// Act
+		_, _ = cache.GetByID(ctx, ids[0])
+		_, _ = cache.GetByID(ctx, ids[1])
+		_, _ = cache.GetByID(ctx, ids[0]) // ids[0] is now the most recently used
+		_, _ = cache.GetByID(ctx, ids[2]) // evicts ids[1]
+		_, _ = cache.GetByID(ctx, ids[0])
+		_, _ = cache.GetByID(ctx, ids[1])
+
+		// Assert
+		mockNext.AssertNumberOfCalls(t, "GetByID", 4)
+	})
+}
+
+func TestMemoryCacheService_Invalidation(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440020"
+
+	t.Run("Given write-through is enabled, When UpdateProfile succeeds, Then should serve the updated user from the cache", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		firstName := "Updated"
+		updated := newTestUser(userID)
+		updated.FirstName = firstName
+		mockNext.On("UpdateProfile", mock.Anything, userID, mock.Anything).Return(updated, nil).Once()
+
+		// Act
+		_, err := cache.UpdateProfile(context.Background(), userID, user.UpdateProfileData{FirstName: &firstName})
+		require.NoError(t, err)
+		result, err := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", result.FirstName)
+		mockNext.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a cached user, When Delete succeeds, Then should fetch from next service on the next read", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		mockNext.On("GetByID", mock.Anything, userID).Return(newTestUser(userID), nil).Once()
+		mockNext.On("Delete", mock.Anything, userID).Return(nil).Once()
+		mockNext.On("GetByID", mock.Anything, userID).Return(nil, user.ErrUserNotFound).Once()
+
+		// Act
+		_, err := cache.GetByID(context.Background(), userID)
+		require.NoError(t, err)
+		require.NoError(t, cache.Delete(context.Background(), userID))
+		_, err = cache.GetByID(context.Background(), userID)
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given versioned preferences, When UpdatePreferences succeeds, Then should serve them with the next version from the cache", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		prefs := user.UserPreferences{UserID: uuid.MustParse(userID), Theme: "dark", Version: 2}
+		mockNext.On("UpdatePreferences", mock.Anything, userID, prefs).Return(nil).Once()
+
+		// Act
+		require.NoError(t, cache.UpdatePreferences(context.Background(), userID, prefs))
+		result, err := cache.GetPreferences(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Version)
+		mockNext.AssertNotCalled(t, "GetPreferences", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given unversioned preferences, When UpdatePreferences succeeds, Then should reload them from next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		prefs := user.UserPreferences{UserID: uuid.MustParse(userID), Theme: "dark"}
+		stored := prefs
+		stored.Version = 5
+		mockNext.On("UpdatePreferences", mock.Anything, userID, prefs).Return(nil).Once()
+		mockNext.On("GetPreferences", mock.Anything, userID).Return(&stored, nil).Once()
+
+		// Act
+		require.NoError(t, cache.UpdatePreferences(context.Background(), userID, prefs))
+		result, err := cache.GetPreferences(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Version)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a served user, When the caller modifies it, Then should not change the cached copy", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		mockNext.On("GetByID", mock.Anything, userID).Return(newTestUser(userID), nil).Once()
+
+		// Act
+		first, err := cache.GetByID(context.Background(), userID)
+		require.NoError(t, err)
+		first.FirstName = "Mutated"
+		second, err := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", second.FirstName)
+	})
+}
+
+func TestMemoryCacheService_FeatureFlags(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440021"
+
+	t.Run("Given cached feature flags, When SetFeatureFlag succeeds, Then should fetch from next service on the next read", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := memorycache.NewService(mockNext, memorycache.DefaultConfig())
+		before := &user.FeatureFlags{UserID: uuid.MustParse(userID), Flags: map[string]bool{}}
+		after := &user.FeatureFlags{UserID: uuid.MustParse(userID), Flags: map[string]bool{"beta_dashboard": true}}
+		mockNext.On("GetFeatureFlags", mock.Anything, userID).Return(before, nil).Once()
+		mockNext.On("SetFeatureFlag", mock.Anything, userID, "beta_dashboard", true).Return(nil).Once()
+		mockNext.On("GetFeatureFlags", mock.Anything, userID).Return(after, nil).Once()
+
+		_, err := cache.GetFeatureFlags(context.Background(), userID)
+		require.NoError(t, err)
+		cached, err := cache.GetFeatureFlags(context.Background(), userID)
+		require.NoError(t, err)
+		require.False(t, cached.IsEnabled("beta_dashboard"))
+
+		// Act
+		require.NoError(t, cache.SetFeatureFlag(context.Background(), userID, "beta_dashboard", true))
+		result, err := cache.GetFeatureFlags(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.IsEnabled("beta_dashboard"))
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestMemoryCacheService_NegativeCaching(t *testing.T) {
+	t.Run("Given negative caching is enabled, When a missing user is looked up twice, Then should call next service once", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		config := memorycache.DefaultConfig()
+		config.NotFound = time.Minute
+		cache := memorycache.NewService(mockNext, config)
+		userID := "550e8400-e29b-41d4-a716-446655440030"
+		mockNext.On("GetByID", mock.Anything, userID).Return(nil, user.ErrUserNotFound).Once()
+
+		// Act
+		_, firstErr := cache.GetByID(context.Background(), userID)
+		_, secondErr := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		assert.ErrorIs(t, firstErr, user.ErrUserNotFound)
+		assert.ErrorIs(t, secondErr, user.ErrUserNotFound)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a missing user is cached, When GetByIDs is called, Then should leave the user out without calling next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		config := memorycache.DefaultConfig()
+		config.NotFound = time.Minute
+		cache := memorycache.NewService(mockNext, config)
+		ids := []string{"550e8400-e29b-41d4-a716-446655440031"}
+		mockNext.On("GetByIDs", mock.Anything, ids).Return(map[string]*user.User{}, nil).Once()
+
+		// Act
+		_, err := cache.GetByIDs(context.Background(), ids)
+		require.NoError(t, err)
+		result, err := cache.GetByIDs(context.Background(), ids)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+// fakeHub delivers invalidations between the broadcasters of several instances
+type fakeHub struct {
+	mu          sync.Mutex
+	subscribers map[*fakeBroadcaster]func(string)
+}
+
+type fakeBroadcaster struct {
+	hub *fakeHub
+}
+
+func (h *fakeHub) join() *fakeBroadcaster {
+	return &fakeBroadcaster{hub: h}
+}
+
+func (b *fakeBroadcaster) Publish(ctx context.Context, userID string) error {
+	b.hub.mu.Lock()
+	defer b.hub.mu.Unlock()
+
+	for subscriber, drop := range b.hub.subscribers {
+		if subscriber != b {
+			drop(userID)
+		}
+	}
+	return nil
+}
+
+func (b *fakeBroadcaster) Subscribe(ctx context.Context, drop func(string)) error {
+	b.hub.mu.Lock()
+	defer b.hub.mu.Unlock()
+
+	b.hub.subscribers[b] = drop
+	return nil
+}
+
+func TestMemoryCacheService_Broadcast(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440040"
+
+	t.Run("Given two instances cached the user, When one updates the profile, Then the other should reload the user from next service", func(t *testing.T) {
+		// Arrange
+		hub := &fakeHub{subscribers: map[*fakeBroadcaster]func(string){}}
+		config := memorycache.DefaultConfig()
+
+		nextA := new(usermock.MockUserService)
+		config.Broadcaster = hub.join()
+		instanceA := memorycache.NewService(nextA, config)
+
+		nextB := new(usermock.MockUserService)
+		config.Broadcaster = hub.join()
+		instanceB := memorycache.NewService(nextB, config)
+
+		firstName := "Updated"
+		updated := newTestUser(userID)
+		updated.FirstName = firstName
+		nextA.On("GetByID", mock.Anything, userID).Return(newTestUser(userID), nil).Once()
+		nextA.On("UpdateProfile", mock.Anything, userID, mock.Anything).Return(updated, nil).Once()
+		nextB.On("GetByID", mock.Anything, userID).Return(newTestUser(userID), nil).Once()
+		nextB.On("GetByID", mock.Anything, userID).Return(updated, nil).Once()
+
+		ctx := context.Background()
+		_, err := instanceA.GetByID(ctx, userID)
+		require.NoError(t, err)
+		_, err = instanceB.GetByID(ctx, userID)
+		require.NoError(t, err)
+
+		// Act
+		_, err = instanceA.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &firstName})
+		require.NoError(t, err)
+		resultA, errA := instanceA.GetByID(ctx, userID)
+		resultB, errB := instanceB.GetByID(ctx, userID)
+
+		// Assert
+		require.NoError(t, errA)
+		require.NoError(t, errB)
+		assert.Equal(t, "Updated", resultA.FirstName)
+		assert.Equal(t, "Updated", resultB.FirstName)
+		nextA.AssertExpectations(t)
+		nextB.AssertExpectations(t)
+	})
+
+	t.Run("Given another instance cached the preferences, When preferences are updated, Then the other should reload them from next service", func(t *testing.T) {
+		// Arrange
+		hub := &fakeHub{subscribers: map[*fakeBroadcaster]func(string){}}
+		config := memorycache.DefaultConfig()
+
+		nextA := new(usermock.MockUserService)
+		config.Broadcaster = hub.join()
+		instanceA := memorycache.NewService(nextA, config)
+
+		nextB := new(usermock.MockUserService)
+		config.Broadcaster = hub.join()
+		instanceB := memorycache.NewService(nextB, config)
+
+		prefs := user.UserPreferences{UserID: uuid.MustParse(userID), Theme: "dark"}
+		nextA.On("UpdatePreferences", mock.Anything, userID, prefs).Return(nil).Once()
+		nextB.On("GetPreferences", mock.Anything, userID).Return(&user.UserPreferences{Theme: "light"}, nil).Once()
+		nextB.On("GetPreferences", mock.Anything, userID).Return(&prefs, nil).Once()
+
+		ctx := context.Background()
+		_, err := instanceB.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, instanceA.UpdatePreferences(ctx, userID, prefs))
+		result, err := instanceB.GetPreferences(ctx, userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "dark", result.Theme)
+		nextB.AssertExpectations(t)
+	})
+}
+
+func TestMemoryCacheService_Conformance(t *testing.T) {
+	usertest.RunServiceConformance(t, func() user.Service {
+		db, err := sqlite.Open(sqlite.MemoryPath)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		return memorycache.NewService(sqlite.NewService(db), memorycache.DefaultConfig())
+	})
+}
@@ -0,0 +1,298 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements user.Service with Prometheus metrics
+type service struct {
+	next     user.Service
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewService creates a new user service that records request counts, latencies
+// and errors per method and registers the collectors with the registerer
+func NewService(next user.Service, registerer prometheus.Registerer) (user.Service, error) {
+	s := &service{
+		next: next,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "user_service",
+			Name:      "requests_total",
+			Help:      "Total user service calls by method and outcome.",
+		}, []string{"method", "outcome"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "user_service",
+			Name:      "errors_total",
+			Help:      "Total user service errors by method and error code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "user_service",
+			Name:      "request_duration_seconds",
+			Help:      "User service call latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	for _, collector := range []prometheus.Collector{s.requests, s.errors, s.duration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Register records metrics for user registration
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	defer s.observe("Register", time.Now())
+
+	result, err := s.next.Register(ctx, data)
+	s.record("Register", err)
+	return result, err
+}
+
+// Login records metrics for user login
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	defer s.observe("Login", time.Now())
+
+	result, err := s.next.Login(ctx, email, password)
+	s.record("Login", err)
+	return result, err
+}
+
+// GetByID records metrics for user retrieval
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	defer s.observe("GetByID", time.Now())
+
+	result, err := s.next.GetByID(ctx, id)
+	s.record("GetByID", err)
+	return result, err
+}
+
+// GetByIDs records metrics for batch lookups
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	defer s.observe("GetByIDs", time.Now())
+
+	result, err := s.next.GetByIDs(ctx, ids)
+	s.record("GetByIDs", err)
+	return result, err
+}
+
+// List records metrics for user listing
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	defer s.observe("List", time.Now())
+
+	result, err := s.next.List(ctx, filters)
+	s.record("List", err)
+	return result, err
+}
+
+// UpdateProfile records metrics for profile updates
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	defer s.observe("UpdateProfile", time.Now())
+
+	result, err := s.next.UpdateProfile(ctx, id, data)
+	s.record("UpdateProfile", err)
+	return result, err
+}
+
+// ChangePassword records metrics for password changes
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	defer s.observe("ChangePassword", time.Now())
+
+	err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	s.record("ChangePassword", err)
+	return err
+}
+
+// RequestPasswordReset records metrics for password reset requests
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	defer s.observe("RequestPasswordReset", time.Now())
+
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	s.record("RequestPasswordReset", err)
+	return result, err
+}
+
+// ResetPassword records metrics for password resets
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	defer s.observe("ResetPassword", time.Now())
+
+	err := s.next.ResetPassword(ctx, token, newPassword)
+	s.record("ResetPassword", err)
+	return err
+}
+
+// RequestEmailChange records metrics for email change requests
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	defer s.observe("RequestEmailChange", time.Now())
+
+	err := s.next.RequestEmailChange(ctx, userID, newEmail)
+	s.record("RequestEmailChange", err)
+	return err
+}
+
+// ConfirmEmailChange records metrics for email change confirmations
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	defer s.observe("ConfirmEmailChange", time.Now())
+
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	s.record("ConfirmEmailChange", err)
+	return result, err
+}
+
+// VerifyEmail records metrics for email verifications
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	defer s.observe("VerifyEmail", time.Now())
+
+	result, err := s.next.VerifyEmail(ctx, token)
+	s.record("VerifyEmail", err)
+	return result, err
+}
+
+// UploadAvatar records metrics for avatar uploads
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	defer s.observe("UploadAvatar", time.Now())
+
+	err := s.next.UploadAvatar(ctx, userID, content, contentType)
+	s.record("UploadAvatar", err)
+	return err
+}
+
+// GetAvatarURL records metrics for avatar link requests
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	defer s.observe("GetAvatarURL", time.Now())
+
+	result, err := s.next.GetAvatarURL(ctx, userID)
+	s.record("GetAvatarURL", err)
+	return result, err
+}
+
+// GetPreferences records metrics for preferences retrieval
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	defer s.observe("GetPreferences", time.Now())
+
+	result, err := s.next.GetPreferences(ctx, userID)
+	s.record("GetPreferences", err)
+	return result, err
+}
+
+// UpdatePreferences records metrics for preferences updates
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	defer s.observe("UpdatePreferences", time.Now())
+
+	err := s.next.UpdatePreferences(ctx, userID, prefs)
+	s.record("UpdatePreferences", err)
+	return err
+}
+
+// UpdatePreferencesBulk records metrics for bulk preference updates
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	defer s.observe("UpdatePreferencesBulk", time.Now())
+
+	err := s.next.UpdatePreferencesBulk(ctx, updates)
+	s.record("UpdatePreferencesBulk", err)
+	return err
+}
+
+// Deactivate records metrics for deactivations
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	defer s.observe("Deactivate", time.Now())
+
+	err := s.next.Deactivate(ctx, id)
+	s.record("Deactivate", err)
+	return err
+}
+
+// Delete records metrics for deletions
+func (s *service) Delete(ctx context.Context, id string) error {
+	defer s.observe("Delete", time.Now())
+
+	err := s.next.Delete(ctx, id)
+	s.record("Delete", err)
+	return err
+}
+
+// ExportUserData records metrics for data exports
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	defer s.observe("ExportUserData", time.Now())
+
+	result, err := s.next.ExportUserData(ctx, userID)
+	s.record("ExportUserData", err)
+	return result, err
+}
+
+// EraseUser records metrics for erasures
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	defer s.observe("EraseUser", time.Now())
+
+	err := s.next.EraseUser(ctx, userID)
+	s.record("EraseUser", err)
+	return err
+}
+
+// CleanupPreferences records metrics for cleanup runs
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	defer s.observe("CleanupPreferences", time.Now())
+
+	report, err := s.next.CleanupPreferences(ctx, opts)
+	s.record("CleanupPreferences", err)
+	return report, err
+}
+
+// GetFeatureFlags records metrics for feature flag retrieval
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	defer s.observe("GetFeatureFlags", time.Now())
+
+	result, err := s.next.GetFeatureFlags(ctx, userID)
+	s.record("GetFeatureFlags", err)
+	return result, err
+}
+
+// SetFeatureFlag records metrics for feature flag updates
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	defer s.observe("SetFeatureFlag", time.Now())
+
+	err := s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+	s.record("SetFeatureFlag", err)
+	return err
+}
+
+// Helper methods
+
+// observe records the latency of a call
+func (s *service) observe(method string, start time.Time) {
+	s.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// record counts a call and, on failure, its error code
+func (s *service) record(method string, err error) {
+	if err == nil {
+		s.requests.WithLabelValues(method, "success").Inc()
+		return
+	}
+
+	s.requests.WithLabelValues(method, "error").Inc()
+	s.errors.WithLabelValues(method, errorCode(err)).Inc()
+}
+
+// errorCode returns a low-cardinality label for an error
+func errorCode(err error) string {
+	var userErr user.UserError
+	if errors.As(err, &userErr) {
+		return userErr.Code
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "CANCELED"
+	}
+	return "INTERNAL"
+}
@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/user/metrics"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+)
+
+func TestMetrics_GivenCalls_WhenCompleted_ThenCountsByMethodAndOutcome(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	next := &userMock.MockUserService{}
+	next.On("GetByID", mock.Anything, "user-1").Return(&user.User{Email: "a@example.com"}, nil)
+	next.On("GetByID", mock.Anything, "missing").Return(nil, user.ErrUserNotFound)
+	next.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	service, err := metrics.NewService(next, registry)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, _ = service.GetByID(ctx, "user-1")
+	_, _ = service.GetByID(ctx, "user-1")
+	_, _ = service.GetByID(ctx, "missing")
+	_, _ = service.Login(ctx, "a@example.com", "secret")
+
+	expected := `
+# HELP user_service_requests_total Total user service calls by method and outcome.
+# TYPE user_service_requests_total counter
+user_service_requests_total{method="GetByID",outcome="error"} 1
+user_service_requests_total{method="GetByID",outcome="success"} 2
+user_service_requests_total{method="Login",outcome="error"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "user_service_requests_total"))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	codes := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "user_service_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "code" {
+					codes[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"USER_NOT_FOUND": 1, "INTERNAL": 1}, codes)
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "user_service_request_duration_seconds"))
+}
+
+func TestMetrics_GivenRegistryWithCollectors_WhenRegisteringTwice_ThenReturnsError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	_, err := metrics.NewService(&userMock.MockUserService{}, registry)
+	require.NoError(t, err)
+
+	_, err = metrics.NewService(&userMock.MockUserService{}, registry)
+	assert.Error(t, err)
+}
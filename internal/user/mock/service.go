@@ -2,10 +2,12 @@ package mock
 
 import (
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 
 	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
 	"github.com/gentra/decorator-arch-go/internal/validationrule"
 )
 
@@ -38,6 +40,22 @@ func (m *MockUserService) GetByID(ctx context.Context, id string) (*user.User, e
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *MockUserService) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*user.User), args.Error(1)
+}
+
+func (m *MockUserService) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Page), args.Error(1)
+}
+
 func (m *MockUserService) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	args := m.Called(ctx, id, data)
 	if args.Get(0) == nil {
@@ -46,6 +64,55 @@ func (m *MockUserService) UpdateProfile(ctx context.Context, id string, data use
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *MockUserService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	args := m.Called(ctx, userID, newEmail)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	args := m.Called(ctx, userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	args := m.Called(ctx, userID, content, contentType)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockUserService) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -59,14 +126,65 @@ func (m *MockUserService) UpdatePreferences(ctx context.Context, userID string,
 	return args.Error(0)
 }
 
+func (m *MockUserService) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	args := m.Called(ctx, updates)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Deactivate(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.DataExport), args.Error(1)
+}
+
+func (m *MockUserService) EraseUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.PreferenceCleanupReport), args.Error(1)
+}
+
+func (m *MockUserService) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.FeatureFlags), args.Error(1)
+}
+
+func (m *MockUserService) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	args := m.Called(ctx, userID, flag, enabled)
+	return args.Error(0)
+}
+
 // MockValidationService is a mock implementation of validation.Service
 type MockValidationService struct {
 	mock.Mock
 }
 
-func (m *MockValidationService) ValidateStruct(ctx context.Context, data interface{}) error {
-	args := m.Called(ctx, data)
-	return args.Error(0)
+func (m *MockValidationService) ValidateStruct(ctx context.Context, data interface{}, ruleSets ...validation.RuleSet) error {
+	if len(ruleSets) == 0 {
+		return m.Called(ctx, data).Error(0)
+	}
+	return m.Called(ctx, data, ruleSets).Error(0)
 }
 
 func (m *MockValidationService) ValidateField(ctx context.Context, field string, value interface{}, rules string) error {
@@ -0,0 +1,1221 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	hashFactory "github.com/gentra/decorator-arch-go/internal/hash/factory"
+	"github.com/gentra/decorator-arch-go/internal/pgtx"
+	"github.com/gentra/decorator-arch-go/internal/storage"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports for a duplicate key
+const uniqueViolation = "23505"
+
+// userColumns are the users columns read by scanUser, in scan order
+const userColumns = `id, email, password_hash, first_name, last_name, created_at, updated_at,
+	deactivated_at, deleted_at, pending_email, email_change_requested_at, email_verified_at,
+	password_reset_requested_at, avatar_key, version`
+
+// preferencesColumns are the user_preferences columns read by scanPreferences, in scan order
+const preferencesColumns = `id, user_id, email_notifications, push_notifications, sms_notifications,
+	theme, language, timezone, notification_types, created_at, updated_at, version`
+
+// errAvatarStorageDisabled is returned by the avatar methods when no storage is configured
+var errAvatarStorageDisabled = errors.New("avatar storage is not configured")
+
+// querier is satisfied by both the pool and a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// service implements the user.Service interface using pgx. It reads and
+// writes the same schema as the GORM service, so the two are interchangeable.
+type service struct {
+	pool                *pgxpool.Pool
+	passwordHistorySize int
+	avatars             storage.Service
+	avatarURLExpiry     time.Duration
+	hasher              hash.Service
+}
+
+// Config contains storage settings for the Postgres user service
+type Config struct {
+	// PasswordHistorySize is how many recent passwords, including the current
+	// one, cannot be reused; zero uses user.DefaultPasswordHistorySize
+	PasswordHistorySize int
+
+	// Avatars stores avatar images; without it avatar uploads are rejected.
+	// AvatarURLExpiry is how long signed avatar links stay valid, zero uses
+	// storage.DefaultURLExpiry.
+	Avatars         storage.Service
+	AvatarURLExpiry time.Duration
+
+	// Hasher hashes and verifies passwords; nil uses the default hash
+	// service. Logins rehash passwords whose hash it reports as outdated.
+	Hasher hash.Service
+}
+
+// NewService creates a new pgx-based user service
+func NewService(pool *pgxpool.Pool) user.Service {
+	return NewServiceWithConfig(pool, Config{})
+}
+
+// NewServiceWithConfig creates a new pgx-based user service with custom settings
+func NewServiceWithConfig(pool *pgxpool.Pool, config Config) user.Service {
+	historySize := config.PasswordHistorySize
+	if historySize <= 0 {
+		historySize = user.DefaultPasswordHistorySize
+	}
+	hasher := config.Hasher
+	if hasher == nil {
+		hasher = hashFactory.NewDefaultService()
+	}
+
+	return &service{
+		pool:                pool,
+		passwordHistorySize: historySize,
+		avatars:             config.Avatars,
+		avatarURLExpiry:     config.AvatarURLExpiry,
+		hasher:              hasher,
+	}
+}
+
+// db returns the transaction carried by the context, so changes commit
+// together with other stores' writes such as the event outbox, or the pool
+func (s *service) db(ctx context.Context) pgtx.Querier {
+	return pgtx.Use(ctx, s.pool)
+}
+
+// Register creates the user and its default preferences in one transaction
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	// Abort before the expensive hash if the caller has already gone away
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(ctx, data.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	id := data.ID
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	var verifiedAt *time.Time
+	if data.EmailVerified {
+		now := time.Now()
+		verifiedAt = &now
+	}
+
+	var created *user.User
+	err = pgx.BeginFunc(ctx, s.db(ctx), func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `INSERT INTO users (id, email, password_hash, first_name, last_name, email_verified_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING `+userColumns,
+			id, data.Email, hashedPassword, data.FirstName, data.LastName, verifiedAt)
+		if created, err = scanUser(row); err != nil {
+			if isUniqueViolation(err) {
+				return user.ErrEmailAlreadyExists
+			}
+			return err
+		}
+
+		prefs := user.DefaultUserPreferences(created.ID)
+		notificationTypesJSON, err := json.Marshal(prefs.NotificationTypes)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO user_preferences
+			(user_id, email_notifications, push_notifications, sms_notifications, theme, language, timezone, notification_types, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			created.ID, prefs.EmailNotifications, prefs.PushNotifications, prefs.SMSNotifications,
+			prefs.Theme, prefs.Language, prefs.Timezone, notificationTypesJSON, prefs.Version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Login authenticates a user and returns auth result
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	found, err := scanUser(s.db(ctx).QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1`, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Abort before the expensive comparison if the caller has gone away
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.hasher.Compare(ctx, found.PasswordHash, password); err != nil {
+		return nil, user.ErrInvalidCredentials
+	}
+
+	// Deactivated accounts are only reported once the password matched, so the
+	// account status is not revealed to someone guessing credentials
+	if found.DeactivatedAt != nil {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	if s.hasher.NeedsRehash(found.PasswordHash) {
+		if rehashed, err := s.rehashPassword(ctx, found.ID, found.PasswordHash, password); err == nil {
+			found.PasswordHash = rehashed
+		} else {
+			log.Printf("Failed to rehash password of user %s: %v", found.ID, err)
+		}
+	}
+
+	// Token and ExpiresAt are set by the authentication service in a higher layer
+	return &user.AuthResult{User: found}, nil
+}
+
+// GetByID retrieves a user by ID; soft-deleted users are only returned when
+// the context includes deleted users
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	if !user.IsDeletedIncluded(ctx) {
+		query += ` AND deleted_at IS NULL`
+	}
+
+	found, err := scanUser(s.db(ctx).QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
+
+// GetByIDs retrieves the users with the given IDs in one query. IDs that are
+// malformed or match no user are left out of the result.
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	result := make(map[string]*user.User, len(ids))
+
+	userIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if userID, err := uuid.Parse(id); err == nil {
+			userIDs = append(userIDs, userID.String())
+		}
+	}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ANY($1::uuid[])`
+	if !user.IsDeletedIncluded(ctx) {
+		query += ` AND deleted_at IS NULL`
+	}
+
+	users, err := s.queryUsers(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, found := range users {
+		result[found.ID.String()] = found
+	}
+	return result, nil
+}
+
+// List returns a page of users matching the filters. Cursor pages use keyset
+// pagination on the sort column with the ID as tie-breaker.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	filters = filters.WithDefaults()
+	column, ok := listSortColumns[filters.SortBy]
+	if !ok {
+		err := user.ErrInvalidListFilter
+		err.Field = "sort_by"
+		return nil, err
+	}
+	direction := "DESC"
+	comparison := "<"
+	if filters.SortOrder == user.SortAsc {
+		direction = "ASC"
+		comparison = ">"
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	bind := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !user.IsDeletedIncluded(ctx) {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filters.EmailPrefix != "" {
+		conditions = append(conditions, fmt.Sprintf(`email LIKE %s ESCAPE '\'`, bind(escapeLike(filters.EmailPrefix)+"%")))
+	}
+	if filters.Name != "" {
+		pattern := bind("%" + escapeLike(strings.ToLower(filters.Name)) + "%")
+		conditions = append(conditions, fmt.Sprintf(`(LOWER(first_name) LIKE %[1]s ESCAPE '\' OR LOWER(last_name) LIKE %[1]s ESCAPE '\')`, pattern))
+	}
+	if filters.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+bind(*filters.CreatedAfter))
+	}
+	if filters.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+bind(*filters.CreatedBefore))
+	}
+
+	var total int64
+	if err := s.db(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM users`+where(conditions), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	offset := ""
+	if filters.Cursor != "" {
+		cursor, err := user.DecodeListCursor(filters.Cursor, filters)
+		if err != nil {
+			return nil, err
+		}
+		value, id, err := cursorPosition(filters.SortBy, cursor)
+		if err != nil {
+			return nil, err
+		}
+		position := bind(value)
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND id %[2]s %[4]s))", column, comparison, position, bind(id)))
+	} else if filters.Offset > 0 {
+		offset = " OFFSET " + bind(filters.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	query := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY %s %s, id %s LIMIT %s%s`,
+		userColumns, where(conditions), column, direction, direction, bind(filters.Limit+1), offset)
+	users, err := s.queryUsers(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &user.Page{
+		Total: total,
+		Limit: filters.Limit,
+	}
+	if filters.Cursor == "" {
+		result.Offset = filters.Offset
+	}
+
+	hasMore := len(users) > filters.Limit
+	if hasMore {
+		users = users[:filters.Limit]
+	}
+	result.Users = users
+	if hasMore {
+		last := users[len(users)-1]
+		result.NextCursor = user.ListCursor{
+			SortBy:    filters.SortBy,
+			SortOrder: filters.SortOrder,
+			Value:     filters.SortValue(last),
+			ID:        last.ID.String(),
+		}.Encode()
+	}
+
+	return result, nil
+}
+
+// UpdateProfile updates user profile information
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	args := []any{userID}
+	var assignments []string
+	set := func(column string, value any) {
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if data.FirstName != nil {
+		set("first_name", *data.FirstName)
+	}
+	if data.LastName != nil {
+		set("last_name", *data.LastName)
+	}
+	if data.Email != nil {
+		set("email", *data.Email)
+		// A new address has not been verified yet
+		assignments = append(assignments, fmt.Sprintf("email_verified_at = CASE WHEN email = $%d THEN email_verified_at END", len(args)))
+		// Reset links sent to the old address no longer apply
+		assignments = append(assignments, fmt.Sprintf("password_reset_requested_at = CASE WHEN email = $%d THEN password_reset_requested_at END", len(args)))
+	}
+
+	if len(assignments) == 0 {
+		// No updates to make, just return the existing user
+		existing, err := s.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if data.Version > 0 && existing.Version != data.Version {
+			return nil, user.ErrConflict
+		}
+		return existing, nil
+	}
+
+	// Only update the user if it is still at the version the change is based on
+	args = append(args, data.Version)
+	updated, err := scanUser(s.db(ctx).QueryRow(ctx, fmt.Sprintf(`UPDATE users SET %s, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($%d = 0 OR version = $%[2]d)
+		RETURNING `+userColumns, strings.Join(assignments, ", "), len(args)), args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either the user does not exist or it changed since it was read
+			if _, err := s.findLiveUser(ctx, userID); err != nil {
+				return nil, err
+			}
+			return nil, user.ErrConflict
+		}
+		if isUniqueViolation(err) && data.Email != nil {
+			return nil, user.ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ChangePassword replaces the user's password after verifying the current one.
+// The replaced hash is kept in the password history so the last
+// passwordHistorySize passwords cannot be chosen again.
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	found, err := s.findLiveUser(ctx, parsedUserID)
+	if err != nil {
+		return err
+	}
+	if found.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	// Abort before the expensive comparisons if the caller has gone away
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.hasher.Compare(ctx, found.PasswordHash, currentPassword); err != nil {
+		return user.ErrIncorrectPassword
+	}
+
+	if err := s.checkPasswordReuse(ctx, found, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, s.db(ctx), func(tx pgx.Tx) error {
+		// Only replace the hash that was verified, so a concurrent change wins once
+		tag, err := tx.Exec(ctx, `UPDATE users SET password_hash = $1, version = version + 1, updated_at = NOW()
+			WHERE id = $2 AND password_hash = $3 AND deleted_at IS NULL`,
+			hashedPassword, parsedUserID, found.PasswordHash)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return user.ErrIncorrectPassword
+		}
+
+		return s.recordPasswordHistory(ctx, tx, parsedUserID, found.PasswordHash)
+	})
+}
+
+// RequestPasswordReset marks a password reset as requested for the live user
+// with the given email, making tokens issued before now stale
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	found, err := scanUser(s.db(ctx).QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1`, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if found.DeactivatedAt != nil {
+		return nil, user.ErrAccountDeactivated
+	}
+
+	// The version is kept: anyone may request a reset, and that must not
+	// make the user's own updates conflict
+	updated, err := scanUser(s.db(ctx).QueryRow(ctx, `UPDATE users
+		SET password_reset_requested_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		found.ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ResetPassword replaces the password of the user named by
+// user.WithPasswordReset without the current password. The token is resolved
+// by the usecase layer before this is reached; the outstanding request is
+// cleared so the reset happens at most once.
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	parsedUserID, err := uuid.Parse(user.PasswordResetFromContext(ctx))
+	if err != nil {
+		return user.ErrInvalidResetToken
+	}
+
+	found, err := s.findLiveUser(ctx, parsedUserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return user.ErrInvalidResetToken
+		}
+		return err
+	}
+	if found.PasswordResetRequestedAt == nil {
+		return user.ErrInvalidResetToken
+	}
+	if found.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	if err := s.checkPasswordReuse(ctx, found, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, s.db(ctx), func(tx pgx.Tx) error {
+		// Clearing the request redeems it, so concurrent resets succeed once
+		tag, err := tx.Exec(ctx, `UPDATE users SET password_hash = $1, password_reset_requested_at = NULL,
+			version = version + 1, updated_at = NOW()
+			WHERE id = $2 AND password_reset_requested_at IS NOT NULL AND deleted_at IS NULL`,
+			hashedPassword, parsedUserID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return user.ErrInvalidResetToken
+		}
+
+		return s.recordPasswordHistory(ctx, tx, parsedUserID, found.PasswordHash)
+	})
+}
+
+// RequestEmailChange records newEmail as the user's pending email, replacing
+// any earlier request. The address is checked against other live users now
+// and again on confirmation.
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	found, err := s.findLiveUser(ctx, parsedUserID)
+	if err != nil {
+		return err
+	}
+	if found.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+	if found.Email == newEmail {
+		return user.ErrEmailUnchanged
+	}
+
+	var taken bool
+	if err := s.db(ctx).QueryRow(ctx, `SELECT EXISTS (
+		SELECT 1 FROM users WHERE email = $1 AND id <> $2 AND deleted_at IS NULL)`,
+		newEmail, parsedUserID).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return user.ErrEmailAlreadyExists
+	}
+
+	_, err = s.db(ctx).Exec(ctx, `UPDATE users
+		SET pending_email = $1, email_change_requested_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`,
+		newEmail, parsedUserID)
+	return err
+}
+
+// ConfirmEmailChange makes the pending email the user's email. The token is
+// verified by the usecase layer before this is reached.
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	found, err := s.findLiveUser(ctx, parsedUserID)
+	if err != nil {
+		return nil, err
+	}
+	if found.PendingEmail == "" {
+		return nil, user.ErrNoPendingEmail
+	}
+
+	// Only apply the pending email that was read, so a newer request is not
+	// confirmed by the token of an older one
+	updated, err := scanUser(s.db(ctx).QueryRow(ctx, `UPDATE users
+		SET email = pending_email, pending_email = '', email_change_requested_at = NULL,
+			email_verified_at = NOW(), password_reset_requested_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND pending_email = $2 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		parsedUserID, found.PendingEmail))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrNoPendingEmail
+		}
+		if isUniqueViolation(err) {
+			return nil, user.ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// VerifyEmail marks the user named by user.WithEmailVerification as verified.
+// The token is resolved by the usecase layer before this is reached; users
+// already verified keep their verification time.
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	parsedUserID, err := uuid.Parse(user.EmailVerificationFromContext(ctx))
+	if err != nil {
+		return nil, user.ErrInvalidVerifyToken
+	}
+
+	if _, err := s.db(ctx).Exec(ctx, `UPDATE users
+		SET email_verified_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND email_verified_at IS NULL AND deleted_at IS NULL`,
+		parsedUserID); err != nil {
+		return nil, err
+	}
+
+	return s.findLiveUser(ctx, parsedUserID)
+}
+
+// UploadAvatar stores the image under a new key and points the user at it,
+// then removes the image it replaces. Every upload gets its own key so cached
+// links to the old avatar never serve the new one.
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	if s.avatars == nil {
+		return errAvatarStorageDisabled
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	found, err := s.findLiveUser(ctx, parsedUserID)
+	if err != nil {
+		return err
+	}
+	if found.DeactivatedAt != nil {
+		return user.ErrAccountDeactivated
+	}
+
+	extension, ok := user.AvatarContentTypes[contentType]
+	if !ok {
+		return user.ErrUnsupportedAvatar
+	}
+
+	key := fmt.Sprintf("avatars/%s/%s%s", found.ID, uuid.New(), extension)
+	if _, err := s.avatars.Put(ctx, key, content, contentType); err != nil {
+		return err
+	}
+
+	if _, err := s.db(ctx).Exec(ctx, `UPDATE users SET avatar_key = $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`,
+		key, found.ID); err != nil {
+		// Nothing references the new image yet
+		_ = s.avatars.Delete(ctx, key)
+		return err
+	}
+
+	// A failed cleanup leaves an unreferenced image behind, not a broken avatar
+	if found.AvatarKey != "" {
+		_ = s.avatars.Delete(ctx, found.AvatarKey)
+	}
+	return nil
+}
+
+// GetAvatarURL returns a link to the user's avatar image
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	if s.avatars == nil {
+		return "", errAvatarStorageDisabled
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return "", user.ErrUserNotFound
+	}
+
+	var avatarKey string
+	if err := s.db(ctx).QueryRow(ctx, `SELECT avatar_key FROM users WHERE id = $1 AND deleted_at IS NULL`,
+		parsedUserID).Scan(&avatarKey); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", user.ErrUserNotFound
+		}
+		return "", err
+	}
+	if avatarKey == "" {
+		return "", user.ErrAvatarNotFound
+	}
+
+	return s.avatars.URL(ctx, avatarKey, s.avatarURLExpiry)
+}
+
+// GetPreferences retrieves user preferences
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	prefs, err := scanPreferences(s.db(ctx).QueryRow(ctx,
+		`SELECT `+preferencesColumns+` FROM user_preferences WHERE user_id = $1`, parsedUserID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrPreferencesNotFound
+		}
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences updates user preferences. When prefs carries a version
+// the update only applies if the stored row still has it, otherwise
+// ErrConflict is returned.
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	return updatePreferences(ctx, s.db(ctx), parsedUserID, prefs)
+}
+
+// UpdatePreferencesBulk updates the preferences of several users in one
+// transaction; if any user has no preferences or a stale version nothing is
+// changed
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	// Apply updates in a fixed order so concurrent bulk updates lock rows consistently
+	userIDs := make([]string, 0, len(updates))
+	for userID := range updates {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	return pgx.BeginFunc(ctx, s.db(ctx), func(tx pgx.Tx) error {
+		for _, userID := range userIDs {
+			parsedUserID, err := uuid.Parse(userID)
+			if err != nil {
+				return user.ErrUserNotFound
+			}
+			if err := updatePreferences(ctx, tx, parsedUserID, updates[userID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Deactivate marks the user as deactivated; deactivating twice is a no-op
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	tag, err := s.db(ctx).Exec(ctx, `UPDATE users SET deactivated_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deactivated_at IS NULL AND deleted_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Nothing changed: either the user is already deactivated or does not exist
+	_, err = s.findLiveUser(ctx, userID)
+	return err
+}
+
+// Delete soft deletes the user by setting deleted_at; the row is kept
+func (s *service) Delete(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	tag, err := s.db(ctx).Exec(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// ExportUserData collects the stored profile and preferences of the user
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	profile, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil && !errors.Is(err, user.ErrPreferencesNotFound) {
+		return nil, err
+	}
+
+	return &user.DataExport{
+		UserID:      userID,
+		ExportedAt:  time.Now(),
+		Profile:     profile,
+		Preferences: prefs,
+	}, nil
+}
+
+// EraseUser removes the user's personal data: preferences, feature flags and
+// the avatar are deleted and the user row is scrubbed and soft deleted,
+// keeping only its ID so references to it stay valid. Erasing an erased user
+// again is a no-op.
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	var avatarKey string
+	err = pgx.BeginFunc(ctx, s.db(ctx), func(tx pgx.Tx) error {
+		// Lock the row so the avatar key read is the one being scrubbed
+		if err := tx.QueryRow(ctx, `SELECT avatar_key FROM users WHERE id = $1 FOR UPDATE`,
+			parsedUserID).Scan(&avatarKey); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return user.ErrUserNotFound
+			}
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE users SET
+			email = '', password_hash = '', first_name = '', last_name = '',
+			pending_email = '', email_change_requested_at = NULL, email_verified_at = NULL,
+			password_reset_requested_at = NULL, avatar_key = '',
+			deactivated_at = COALESCE(deactivated_at, NOW()),
+			deleted_at = COALESCE(deleted_at, NOW()),
+			version = version + 1,
+			updated_at = NOW()
+			WHERE id = $1`, parsedUserID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM password_history WHERE user_id = $1`, parsedUserID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM user_feature_flags WHERE user_id = $1`, parsedUserID); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `DELETE FROM user_preferences WHERE user_id = $1`, parsedUserID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The image is removed once the row no longer points at it
+	if avatarKey != "" && s.avatars != nil {
+		return s.avatars.Delete(ctx, avatarKey)
+	}
+	return nil
+}
+
+// CleanupPreferences scans every stored preference row in batches for
+// notification types missing from the registry and, with opts.Strip, removes
+// them. Rows changed since they were read are left for the next run.
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = user.DefaultCleanupBatchSize
+	}
+
+	report := &user.PreferenceCleanupReport{
+		UnknownKeys:   make(map[string]int),
+		AffectedUsers: []string{},
+		Stripped:      opts.Strip,
+	}
+
+	// Batches are read by ID so rows stripped along the way do not shift them
+	after := uuid.Nil
+	for {
+		rows, err := s.db(ctx).Query(ctx, `SELECT `+preferencesColumns+` FROM user_preferences
+			WHERE id > $1 ORDER BY id LIMIT $2`, after, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*user.UserPreferences, error) {
+			return scanPreferences(row)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prefs := range batch {
+			report.Scanned++
+
+			unknown := prefs.UnknownNotificationTypes()
+			if len(unknown) == 0 {
+				continue
+			}
+
+			report.AffectedUsers = append(report.AffectedUsers, prefs.UserID.String())
+			for _, notificationType := range unknown {
+				report.UnknownKeys[string(notificationType)]++
+				delete(prefs.NotificationTypes, notificationType)
+			}
+
+			if opts.Strip {
+				if err := s.stripNotificationTypes(ctx, prefs); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(batch) < batchSize {
+			return report, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// GetFeatureFlags retrieves every flag switched for the user
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+	if _, err := s.findLiveUser(ctx, parsedUserID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db(ctx).Query(ctx,
+		`SELECT flag, enabled, updated_at FROM user_feature_flags WHERE user_id = $1`, parsedUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := &user.FeatureFlags{UserID: parsedUserID, Flags: make(map[string]bool)}
+	for rows.Next() {
+		var (
+			flag      string
+			enabled   bool
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&flag, &enabled, &updatedAt); err != nil {
+			return nil, err
+		}
+		flags.Flags[flag] = enabled
+		if updatedAt.After(flags.UpdatedAt) {
+			flags.UpdatedAt = updatedAt
+		}
+	}
+	return flags, rows.Err()
+}
+
+// SetFeatureFlag stores the flag for the user, replacing any earlier setting
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return user.ErrUserNotFound
+	}
+
+	// Only live users get flags; the insert is skipped for anyone else
+	tag, err := s.db(ctx).Exec(ctx, `
+		INSERT INTO user_feature_flags (user_id, flag, enabled, updated_at)
+		SELECT id, $2, $3, NOW() FROM users WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_id, flag) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`,
+		parsedUserID, flag, enabled)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// stripNotificationTypes writes the cleaned notification types back unless
+// the row was updated after it was read
+func (s *service) stripNotificationTypes(ctx context.Context, prefs *user.UserPreferences) error {
+	notificationTypesJSON, err := json.Marshal(prefs.NotificationTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db(ctx).Exec(ctx, `UPDATE user_preferences
+		SET notification_types = $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND version = $3`,
+		notificationTypesJSON, prefs.ID, prefs.Version)
+	return err
+}
+
+// rehashPassword replaces a verified outdated hash with one of the current
+// algorithm. The version is kept, as the password itself did not change.
+func (s *service) rehashPassword(ctx context.Context, userID uuid.UUID, outdated, password string) (string, error) {
+	rehashed, err := s.hasher.Hash(ctx, password)
+	if err != nil {
+		return "", err
+	}
+
+	// A concurrent password change wins over the rehash
+	tag, err := s.db(ctx).Exec(ctx, `UPDATE users SET password_hash = $1, updated_at = NOW()
+		WHERE id = $2 AND password_hash = $3`, rehashed, userID, outdated)
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() == 0 {
+		return outdated, nil
+	}
+	return rehashed, nil
+}
+
+// findLiveUser loads a user that has not been soft deleted
+func (s *service) findLiveUser(ctx context.Context, userID uuid.UUID) (*user.User, error) {
+	found, err := scanUser(s.db(ctx).QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = $1 AND deleted_at IS NULL`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
+
+// queryUsers runs a query selecting userColumns and scans every row
+func (s *service) queryUsers(ctx context.Context, query string, args ...any) ([]*user.User, error) {
+	rows, err := s.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*user.User, error) {
+		return scanUser(row)
+	})
+}
+
+// updatePreferences stores prefs for the user, checking the version when one
+// is given. A row that was not updated is told apart as missing or stale.
+func updatePreferences(ctx context.Context, q querier, userID uuid.UUID, prefs user.UserPreferences) error {
+	// Notification types are stored as JSON; a map of bools always marshals
+	notificationTypesJSON, _ := json.Marshal(prefs.NotificationTypes)
+
+	tag, err := q.Exec(ctx, `UPDATE user_preferences SET
+		email_notifications = $2, push_notifications = $3, sms_notifications = $4,
+		theme = $5, language = $6, timezone = $7, notification_types = $8,
+		version = version + 1, updated_at = NOW()
+		WHERE user_id = $1 AND ($9 = 0 OR version = $9)`,
+		userID, prefs.EmailNotifications, prefs.PushNotifications, prefs.SMSNotifications,
+		prefs.Theme, prefs.Language, prefs.Timezone, notificationTypesJSON, prefs.Version)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM user_preferences WHERE user_id = $1)`,
+		userID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return user.ErrConflict
+	}
+	return user.ErrPreferencesNotFound
+}
+
+// listSortColumns maps ListFilters.SortBy to the column it orders by
+var listSortColumns = map[string]string{
+	user.SortByCreatedAt: "created_at",
+	user.SortByEmail:     "email",
+	user.SortByFirstName: "first_name",
+	user.SortByLastName:  "last_name",
+}
+
+// likeEscaper escapes LIKE wildcards so filters match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// where joins the conditions into a WHERE clause, empty when there are none
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// cursorPosition converts a decoded cursor into query arguments
+func cursorPosition(sortBy string, cursor user.ListCursor) (any, uuid.UUID, error) {
+	id, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return nil, uuid.Nil, user.ErrInvalidCursor
+	}
+	if sortBy != user.SortByCreatedAt {
+		return cursor.Value, id, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, uuid.Nil, user.ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
+
+// isUniqueViolation reports whether err is a duplicate key error
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// checkPasswordReuse returns ErrPasswordReused when newPassword is the user's
+// current password or one of the passwords it replaced. The current password
+// counts towards the history size.
+func (s *service) checkPasswordReuse(ctx context.Context, found *user.User, newPassword string) error {
+	previous := []string{found.PasswordHash}
+	if s.passwordHistorySize > 1 {
+		rows, err := s.db(ctx).Query(ctx, `SELECT password_hash FROM password_history
+			WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`,
+			found.ID, s.passwordHistorySize-1)
+		if err != nil {
+			return err
+		}
+		history, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		previous = append(previous, history...)
+	}
+	for _, previousHash := range previous {
+		if s.hasher.Compare(ctx, previousHash, newPassword) == nil {
+			return user.ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory keeps a replaced password hash for reuse checks
+func (s *service) recordPasswordHistory(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replacedHash string) error {
+	if _, err := tx.Exec(ctx, `INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)`,
+		userID, replacedHash); err != nil {
+		return err
+	}
+
+	// Drop history rows beyond what reuse checks look at; the current
+	// password is the newest entry and lives on the user row
+	_, err := tx.Exec(ctx, `DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+		SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2)`,
+		userID, s.passwordHistorySize-1)
+	return err
+}
+
+// Helper methods for scanning rows into domain models
+func scanUser(row pgx.Row) (*user.User, error) {
+	var scanned user.User
+	if err := row.Scan(
+		&scanned.ID,
+		&scanned.Email,
+		&scanned.PasswordHash,
+		&scanned.FirstName,
+		&scanned.LastName,
+		&scanned.CreatedAt,
+		&scanned.UpdatedAt,
+		&scanned.DeactivatedAt,
+		&scanned.DeletedAt,
+		&scanned.PendingEmail,
+		&scanned.EmailChangeRequestedAt,
+		&scanned.EmailVerifiedAt,
+		&scanned.PasswordResetRequestedAt,
+		&scanned.AvatarKey,
+		&scanned.Version,
+	); err != nil {
+		return nil, err
+	}
+	return &scanned, nil
+}
+
+func scanPreferences(row pgx.Row) (*user.UserPreferences, error) {
+	var (
+		scanned               user.UserPreferences
+		notificationTypesJSON []byte
+	)
+	if err := row.Scan(
+		&scanned.ID,
+		&scanned.UserID,
+		&scanned.EmailNotifications,
+		&scanned.PushNotifications,
+		&scanned.SMSNotifications,
+		&scanned.Theme,
+		&scanned.Language,
+		&scanned.Timezone,
+		&notificationTypesJSON,
+		&scanned.CreatedAt,
+		&scanned.UpdatedAt,
+		&scanned.Version,
+	); err != nil {
+		return nil, err
+	}
+
+	// The column is nullable; a missing value means no types were chosen
+	if len(notificationTypesJSON) > 0 {
+		if err := json.Unmarshal(notificationTypesJSON, &scanned.NotificationTypes); err != nil {
+			return nil, err
+		}
+	}
+	return &scanned, nil
+}
@@ -3,18 +3,64 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/gentra/decorator-arch-go/internal/ratelimit"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
+// Rate limit patterns registered by the decorator
+const (
+	registerPattern   = "user:register"
+	registerIPPattern = "user:register:ip"
+	loginPattern      = "user:login"
+	loginIPPattern    = "user:login:ip"
+	resetPattern      = "user:password:reset"
+	resetIPPattern    = "user:password:reset:ip"
+)
+
+// Config contains the per-operation limits enforced on authentication entry points
+type Config struct {
+	Register      OperationLimits
+	Login         OperationLimits
+	PasswordReset OperationLimits
+}
+
+// OperationLimits limits an operation per user (email) and per client IP.
+// A zero limit keeps whatever the rate limit service has configured for the pattern.
+type OperationLimits struct {
+	PerUser ratelimit.RateLimitConfig
+	PerIP   ratelimit.RateLimitConfig
+}
+
+// DefaultConfig returns the default authentication rate limits
+func DefaultConfig() Config {
+	return Config{
+		Register: OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 5, Window: time.Hour},
+			PerIP:   ratelimit.RateLimitConfig{Limit: 20, Window: time.Hour},
+		},
+		Login: OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 10, Window: 15 * time.Minute},
+			PerIP:   ratelimit.RateLimitConfig{Limit: 50, Window: 15 * time.Minute},
+		},
+		PasswordReset: OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 3, Window: time.Hour},
+			PerIP:   ratelimit.RateLimitConfig{Limit: 20, Window: time.Hour},
+		},
+	}
+}
+
 // service implements user.Service with rate limiting capabilities
 type service struct {
 	next             user.Service
 	rateLimitService ratelimit.Service
 }
 
-// NewService creates a new rate-limited user service
+// NewService creates a new rate-limited user service using the rate limits
+// already configured in the rate limit service
 func NewService(next user.Service, rateLimitService ratelimit.Service) user.Service {
 	return &service{
 		next:             next,
@@ -22,9 +68,51 @@ func NewService(next user.Service, rateLimitService ratelimit.Service) user.Serv
 	}
 }
 
-// Register applies rate limiting for user registration
+// NewServiceWithConfig creates a new rate-limited user service and registers
+// the per-user and per-IP limits for Register, Login and RequestPasswordReset
+func NewServiceWithConfig(next user.Service, rateLimitService ratelimit.Service, config Config) (user.Service, error) {
+	limits := map[string]ratelimit.RateLimitConfig{
+		registerPattern:   config.Register.PerUser,
+		registerIPPattern: config.Register.PerIP,
+		loginPattern:      config.Login.PerUser,
+		loginIPPattern:    config.Login.PerIP,
+		resetPattern:      config.PasswordReset.PerUser,
+		resetIPPattern:    config.PasswordReset.PerIP,
+	}
+
+	for pattern, limit := range limits {
+		if limit.Limit == 0 {
+			continue
+		}
+		if err := rateLimitService.SetLimit(context.Background(), pattern, limit); err != nil {
+			return nil, fmt.Errorf("invalid rate limit for %s: %w", pattern, err)
+		}
+	}
+
+	return NewService(next, rateLimitService), nil
+}
+
+// Register applies per-email and per-IP rate limiting for user registration
 func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
-	key := fmt.Sprintf("user:register:%s", data.Email)
+	if err := s.allowAuth(ctx, registerPattern, registerIPPattern, data.Email); err != nil {
+		return nil, err
+	}
+
+	return s.next.Register(ctx, data)
+}
+
+// Login applies per-email and per-IP rate limiting for user login attempts
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	if err := s.allowAuth(ctx, loginPattern, loginIPPattern, email); err != nil {
+		return nil, err
+	}
+
+	return s.next.Login(ctx, email, password)
+}
+
+// GetByID applies rate limiting for user data retrieval
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	key := fmt.Sprintf("user:read:%s", id)
 
 	allowed, err := s.rateLimitService.Allow(ctx, key)
 	if err != nil {
@@ -32,31 +120,32 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 	}
 
 	if !allowed {
-		return nil, fmt.Errorf("rate limit exceeded for registration")
+		return nil, user.ErrRateLimited
 	}
 
-	return s.next.Register(ctx, data)
+	return s.next.GetByID(ctx, id)
 }
 
-// Login applies rate limiting for user login attempts
-func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
-	key := fmt.Sprintf("user:login:%s", email)
-
-	allowed, err := s.rateLimitService.Allow(ctx, key)
+// GetByIDs applies one rate limit check per batch rather than per user
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	allowed, err := s.rateLimitService.Allow(ctx, "user:read:batch")
 	if err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
 	if !allowed {
-		return nil, fmt.Errorf("rate limit exceeded for login")
+		return nil, user.ErrRateLimited
 	}
 
-	return s.next.Login(ctx, email, password)
+	return s.next.GetByIDs(ctx, ids)
 }
 
-// GetByID applies rate limiting for user data retrieval
-func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
-	key := fmt.Sprintf("user:read:%s", id)
+// List applies rate limiting per client IP; searches are comparatively expensive
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	key := "user:list"
+	if ip := user.ClientIPFromContext(ctx); ip != "" {
+		key = fmt.Sprintf("%s:%s", key, ip)
+	}
 
 	allowed, err := s.rateLimitService.Allow(ctx, key)
 	if err != nil {
@@ -64,10 +153,10 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	}
 
 	if !allowed {
-		return nil, fmt.Errorf("rate limit exceeded for read")
+		return nil, user.ErrRateLimited
 	}
 
-	return s.next.GetByID(ctx, id)
+	return s.next.List(ctx, filters)
 }
 
 // UpdateProfile applies rate limiting for profile updates
@@ -80,12 +169,115 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 	}
 
 	if !allowed {
-		return nil, fmt.Errorf("rate limit exceeded for update")
+		return nil, user.ErrRateLimited
 	}
 
 	return s.next.UpdateProfile(ctx, id, data)
 }
 
+// ChangePassword applies rate limiting for password changes, which also
+// guards the current password against guessing
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	key := fmt.Sprintf("user:password:change:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset applies per-email and per-IP rate limiting, so reset
+// emails cannot be used to flood an inbox
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	if err := s.allowAuth(ctx, resetPattern, resetIPPattern, email); err != nil {
+		return nil, err
+	}
+
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through; reset tokens are signed and cannot be guessed
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange applies rate limiting for email change requests, each of
+// which sends an email
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	key := fmt.Sprintf("user:email:change:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange applies rate limiting for email change confirmations
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	key := fmt.Sprintf("user:email:confirm:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return nil, user.ErrRateLimited
+	}
+
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through; verification tokens are signed, so there is
+// nothing to guess
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar applies rate limiting for avatar uploads
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	key := fmt.Sprintf("user:avatar:upload:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL applies rate limiting for avatar link requests
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	key := fmt.Sprintf("user:avatar:read:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return "", user.ErrRateLimited
+	}
+
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences applies rate limiting for preferences retrieval
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	key := fmt.Sprintf("user:prefs:read:%s", userID)
@@ -96,7 +288,7 @@ func (s *service) GetPreferences(ctx context.Context, userID string) (*user.User
 	}
 
 	if !allowed {
-		return nil, fmt.Errorf("rate limit exceeded for preferences read")
+		return nil, user.ErrRateLimited
 	}
 
 	return s.next.GetPreferences(ctx, userID)
@@ -112,8 +304,146 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 	}
 
 	if !allowed {
-		return fmt.Errorf("rate limit exceeded for preferences update")
+		return user.ErrRateLimited
 	}
 
 	return s.next.UpdatePreferences(ctx, userID, prefs)
 }
+
+// UpdatePreferencesBulk applies one rate limit check per batch rather than per user
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	allowed, err := s.rateLimitService.Allow(ctx, "user:prefs:update:bulk")
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate applies rate limiting for deactivations
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	key := fmt.Sprintf("user:deactivate:%s", id)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete applies rate limiting for deletions
+func (s *service) Delete(ctx context.Context, id string) error {
+	key := fmt.Sprintf("user:delete:%s", id)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData applies rate limiting for data exports, which are expensive
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	key := fmt.Sprintf("user:export:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return nil, user.ErrRateLimited
+	}
+
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser applies rate limiting for erasures
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	key := fmt.Sprintf("user:erase:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences passes through; it is a maintenance job, not a user request
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags applies rate limiting for feature flag retrieval
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	key := fmt.Sprintf("user:flags:read:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return nil, user.ErrRateLimited
+	}
+
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag applies rate limiting for feature flag updates
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	key := fmt.Sprintf("user:flags:update:%s", userID)
+
+	allowed, err := s.rateLimitService.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	if !allowed {
+		return user.ErrRateLimited
+	}
+
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// allowAuth checks the per-user limit and, when the caller's IP is known, the
+// per-IP limit. Emails are keyed case-insensitively, as accounts are looked
+// up, so case variants share the per-user limit.
+func (s *service) allowAuth(ctx context.Context, userPattern, ipPattern, email string) error {
+	keys := []string{fmt.Sprintf("%s:%s", userPattern, strings.ToLower(strings.TrimSpace(email)))}
+	if ip := user.ClientIPFromContext(ctx); ip != "" {
+		keys = append(keys, fmt.Sprintf("%s:%s", ipPattern, ip))
+	}
+
+	for _, key := range keys {
+		allowed, err := s.rateLimitService.Allow(ctx, key)
+		if err != nil {
+			return fmt.Errorf("rate limiter error: %w", err)
+		}
+
+		if !allowed {
+			return user.ErrRateLimited
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/ratelimit"
+	ratelimitMemory "github.com/gentra/decorator-arch-go/internal/ratelimit/memory"
+	"github.com/gentra/decorator-arch-go/internal/ratelimit/tokenbucket"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	userRateLimit "github.com/gentra/decorator-arch-go/internal/user/ratelimit"
+)
+
+func newLimitedService(t *testing.T, next user.Service) user.Service {
+	t.Helper()
+	config := userRateLimit.Config{
+		Login: userRateLimit.OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 2, Window: time.Hour},
+			PerIP:   ratelimit.RateLimitConfig{Limit: 3, Window: time.Hour},
+		},
+		Register: userRateLimit.OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 1, Window: time.Hour},
+		},
+	}
+
+	service, err := userRateLimit.NewServiceWithConfig(next, tokenbucket.NewService(map[string]ratelimit.RateLimitConfig{}), config)
+	require.NoError(t, err)
+	return service
+}
+
+func TestLogin_GivenPerUserLimit_WhenExceeded_ThenReturnsErrRateLimited(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, "a@example.com", "password").Return(&user.AuthResult{}, nil)
+	service := newLimitedService(t, next)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Login(ctx, "a@example.com", "password")
+		require.NoError(t, err)
+	}
+
+	_, err := service.Login(ctx, "a@example.com", "password")
+
+	assert.ErrorIs(t, err, user.ErrRateLimited)
+	next.AssertNumberOfCalls(t, "Login", 2)
+}
+
+func TestLogin_GivenPerIPLimit_WhenSprayingAccounts_ThenReturnsErrRateLimited(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	service := newLimitedService(t, next)
+	ctx := user.WithClientIP(context.Background(), "10.0.0.1")
+
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}
+	var errs []error
+	for _, email := range emails {
+		_, err := service.Login(ctx, email, "guess")
+		errs = append(errs, err)
+	}
+
+	assert.ErrorIs(t, errs[2], user.ErrInvalidCredentials)
+	assert.ErrorIs(t, errs[3], user.ErrRateLimited)
+
+	// A different IP is not affected
+	_, err := service.Login(user.WithClientIP(context.Background(), "10.0.0.2"), "d@example.com", "guess")
+	assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+}
+
+func TestLogin_GivenDefaultMemoryLimiter_WhenSprayingAccounts_ThenAppliesPerIPLimit(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(nil, user.ErrInvalidCredentials)
+	config := userRateLimit.Config{
+		Login: userRateLimit.OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 1, Window: time.Hour},
+			PerIP:   ratelimit.RateLimitConfig{Limit: 3, Window: time.Hour},
+		},
+	}
+	service, err := userRateLimit.NewServiceWithConfig(next, ratelimitMemory.NewService(nil), config)
+	require.NoError(t, err)
+	ctx := user.WithClientIP(context.Background(), "10.0.0.1")
+
+	// Act
+	var errs []error
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		_, err := service.Login(ctx, email, "guess")
+		errs = append(errs, err)
+	}
+
+	// Assert
+	assert.ErrorIs(t, errs[0], user.ErrInvalidCredentials)
+	assert.ErrorIs(t, errs[2], user.ErrInvalidCredentials)
+	assert.ErrorIs(t, errs[3], user.ErrRateLimited)
+}
+
+func TestLogin_GivenEmailCaseVariants_WhenExceeded_ThenSharesPerUserLimit(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, mock.Anything, "password").Return(&user.AuthResult{}, nil)
+	service := newLimitedService(t, next)
+	ctx := context.Background()
+
+	// Act
+	_, err1 := service.Login(ctx, "a@example.com", "password")
+	_, err2 := service.Login(ctx, "A@Example.com", "password")
+	_, err3 := service.Login(ctx, " a@EXAMPLE.com ", "password")
+
+	// Assert
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.ErrorIs(t, err3, user.ErrRateLimited)
+	next.AssertNumberOfCalls(t, "Login", 2)
+}
+
+func TestRegister_GivenPerUserLimit_WhenExceeded_ThenReturnsErrRateLimited(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("Register", mock.Anything, mock.Anything).Return(&user.User{}, nil)
+	service := newLimitedService(t, next)
+	data := user.RegisterData{Email: "a@example.com"}
+
+	_, err := service.Register(context.Background(), data)
+	require.NoError(t, err)
+
+	_, err = service.Register(context.Background(), data)
+	assert.ErrorIs(t, err, user.ErrRateLimited)
+}
+
+func TestNewServiceWithConfig_GivenInvalidLimit_WhenCreating_ThenReturnsError(t *testing.T) {
+	config := userRateLimit.Config{
+		Login: userRateLimit.OperationLimits{
+			PerUser: ratelimit.RateLimitConfig{Limit: 5},
+		},
+	}
+
+	service, err := userRateLimit.NewServiceWithConfig(&userMock.MockUserService{}, tokenbucket.NewService(nil), config)
+
+	assert.Error(t, err)
+	assert.Nil(t, service)
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gentra/decorator-arch-go/internal/user/memorycache"
+)
+
+// DefaultInvalidationChannel is the pub/sub channel invalidations are sent on
+const DefaultInvalidationChannel = "user_cache_invalidations"
+
+// invalidation is the message published when a user changes
+type invalidation struct {
+	Origin string `json:"origin"`
+	UserID string `json:"user_id"`
+}
+
+// broadcaster implements memorycache.Broadcaster with Redis pub/sub. Every
+// instance subscribes to the channel and skips its own messages. Pub/sub
+// does not buffer messages, so invalidations sent while an instance is
+// reconnecting are lost and its first-tier entries live out their TTL.
+type broadcaster struct {
+	client  *redis.Client
+	channel string
+	origin  string
+}
+
+// NewBroadcaster creates a broadcaster for the in-process caches of several
+// instances sharing the Redis server
+func NewBroadcaster(client *redis.Client, channel string) memorycache.Broadcaster {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+
+	return &broadcaster{
+		client:  client,
+		channel: channel,
+		origin:  uuid.NewString(),
+	}
+}
+
+// Publish sends the invalidation to every subscribed instance
+func (b *broadcaster) Publish(ctx context.Context, userID string) error {
+	data, err := json.Marshal(invalidation{Origin: b.origin, UserID: userID})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe relays invalidations from other instances until ctx is done or
+// the client is closed; the subscription reconnects on its own after errors
+func (b *broadcaster) Subscribe(ctx context.Context, drop func(userID string)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	messages := pubsub.Channel()
+
+	go func() {
+		defer pubsub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var received invalidation
+				if err := json.Unmarshal([]byte(message.Payload), &received); err != nil {
+					fmt.Printf("Failed to decode cache invalidation: %v\n", err)
+					continue
+				}
+				if received.Origin != b.origin {
+					drop(received.UserID)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -2,28 +2,110 @@ package redis
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
 
+// TTLConfig holds the cache TTL for each cached read
+type TTLConfig struct {
+	User         time.Duration // GetByID and users cached after Register/Login/UpdateProfile
+	Preferences  time.Duration // GetPreferences
+	FeatureFlags time.Duration // GetFeatureFlags
+	List         time.Duration // List pages; defaults to DefaultListCacheTTL
+
+	// NotFound is how long GetByID remembers that a user does not exist, so
+	// repeated lookups of missing IDs do not reach the database; zero disables
+	// negative caching
+	NotFound time.Duration
+}
+
+// DefaultListCacheTTL is how long a List page is cached. Pages are not
+// invalidated on writes, so they are only kept briefly.
+const DefaultListCacheTTL = 30 * time.Second
+
+// DefaultNotFoundCacheTTL is a suggested TTLConfig.NotFound; it is short
+// because nothing invalidates the entry when the ID is later taken
+const DefaultNotFoundCacheTTL = 30 * time.Second
+
+// notFoundMarker is cached in place of a user that does not exist. It is not
+// valid JSON, so it can never be mistaken for a cached user.
+const notFoundMarker = "!not_found"
+
+// Config holds the caching strategy of the decorator
+type Config struct {
+	TTLs TTLConfig
+
+	// WriteThrough caches the users returned by Register and UpdateProfile so
+	// the next read is a hit; without it they are only invalidated
+	WriteThrough bool
+
+	// UserTTL overrides TTLs.User for individual users, e.g. to expire users
+	// with a pending email change sooner; a zero result keeps TTLs.User
+	UserTTL func(u *user.User) time.Duration
+
+	// DisableCoalescing lets concurrent GetByID misses for the same user each
+	// query the next service instead of sharing a single load
+	DisableCoalescing bool
+
+	// Coalesced counts GetByID calls served by another caller's load; nil
+	// disables the metric
+	Coalesced prometheus.Counter
+}
+
+// NewCoalescedCounter creates the counter of coalesced GetByID calls and
+// registers it with the registerer
+func NewCoalescedCounter(registerer prometheus.Registerer) (prometheus.Counter, error) {
+	coalesced := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "user_cache",
+		Name:      "coalesced_requests_total",
+		Help:      "GetByID cache misses that waited for a concurrent load of the same user.",
+	})
+
+	if err := registerer.Register(coalesced); err != nil {
+		return nil, err
+	}
+	return coalesced, nil
+}
+
 // service implements the user.Service interface with Redis caching
 type service struct {
 	next   user.Service
 	client *redis.Client
-	ttl    time.Duration
+	config Config
+	loads  singleflight.Group
 }
 
-// NewService creates a new Redis-backed user service
+// NewService creates a new Redis-backed user service using the same TTL for every cached read
 func NewService(next user.Service, client *redis.Client, ttl time.Duration) user.Service {
+	return NewServiceWithTTLs(next, client, TTLConfig{User: ttl, Preferences: ttl, FeatureFlags: ttl})
+}
+
+// NewServiceWithTTLs creates a new Redis-backed user service with per-method TTLs
+func NewServiceWithTTLs(next user.Service, client *redis.Client, ttls TTLConfig) user.Service {
+	return NewServiceWithConfig(next, client, Config{TTLs: ttls, WriteThrough: true})
+}
+
+// NewServiceWithConfig creates a new Redis-backed user service with a custom caching strategy
+func NewServiceWithConfig(next user.Service, client *redis.Client, config Config) user.Service {
+	if config.TTLs.List <= 0 {
+		config.TTLs.List = DefaultListCacheTTL
+	}
+
 	return &service{
 		next:   next,
 		client: client,
-		ttl:    ttl,
+		config: config,
 	}
 }
 
@@ -36,15 +118,18 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 	}
 
 	// Cache the newly created user
-	if err := s.cacheUser(ctx, result); err != nil {
-		// Log error but don't fail the registration
-		// In production, you'd use a proper logger
-		fmt.Printf("Failed to cache user after registration: %v\n", err)
+	if s.config.WriteThrough {
+		if err := s.cacheUser(ctx, result); err != nil {
+			// Log error but don't fail the registration
+			// In production, you'd use a proper logger
+			fmt.Printf("Failed to cache user after registration: %v\n", err)
+		}
 	}
 
 	// Invalidate email cache if it exists
-	emailCacheKey := s.getEmailCacheKey(data.Email)
-	s.client.Del(ctx, emailCacheKey)
+	if err := s.invalidate(ctx, s.getEmailCacheKey(data.Email)); err != nil {
+		fmt.Printf("Failed to invalidate email cache after registration: %v\n", err)
+	}
 
 	return result, nil
 }
@@ -70,10 +155,24 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 
 // GetByID retrieves a user by ID (cache aside pattern)
 func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshUser(ctx, id)
+	}
+
 	// Try to get from cache first
 	cacheKey := s.getUserCacheKey(id)
 	cached, err := s.client.Get(ctx, cacheKey).Result()
 	if err == nil {
+		// Cache hit - the user is known not to exist. Soft-deleted users
+		// are cached as missing, so reads that include them look again.
+		if cached == notFoundMarker {
+			if !user.IsDeletedIncluded(ctx) {
+				return nil, user.ErrUserNotFound
+			}
+			return s.loadUser(ctx, id)
+		}
+
 		// Cache hit - deserialize and return
 		var cachedUser user.User
 		if err := json.Unmarshal([]byte(cached), &cachedUser); err == nil {
@@ -87,19 +186,82 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	}
 
 	// Cache miss or error - get from next service
-	result, err := s.next.GetByID(ctx, id)
+	return s.loadUser(ctx, id)
+}
+
+// GetByIDs retrieves several users with a single MGET; only the users missing
+// from the cache are loaded from the next service, and those are cached again
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	if user.IsCacheBypassed(ctx) || len(ids) == 0 {
+		return s.refreshUsers(ctx, ids)
+	}
+
+	cacheKeys := make([]string, len(ids))
+	for i, id := range ids {
+		cacheKeys[i] = s.getUserCacheKey(id)
+	}
+
+	cached, err := s.client.MGet(ctx, cacheKeys...).Result()
 	if err != nil {
-		return nil, err
+		// Log cache error but continue to next service
+		fmt.Printf("Cache error for %d users: %v\n", len(ids), err)
+		return s.refreshUsers(ctx, ids)
 	}
 
-	// Cache the result
-	if err := s.cacheUser(ctx, result); err != nil {
-		fmt.Printf("Failed to cache user %s: %v\n", id, err)
+	result := make(map[string]*user.User, len(ids))
+	var misses []string
+	for i, id := range ids {
+		if data, ok := cached[i].(string); ok {
+			// Users known not to exist are left out, as the next layer would
+			if data == notFoundMarker && !user.IsDeletedIncluded(ctx) {
+				continue
+			}
+
+			var cachedUser user.User
+			if err := json.Unmarshal([]byte(data), &cachedUser); err == nil {
+				result[id] = &cachedUser
+				continue
+			}
+			fmt.Printf("Failed to deserialize cached user: %v\n", err)
+		}
+		misses = append(misses, id)
+	}
+	if len(misses) == 0 {
+		return result, nil
 	}
 
+	loaded, err := s.refreshUsers(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for id, u := range loaded {
+		result[id] = u
+	}
 	return result, nil
 }
 
+// List retrieves a page of users (cache aside pattern). Pages are keyed by
+// the filters and expire after a short TTL instead of being invalidated.
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshList(ctx, filters)
+	}
+
+	cacheKey := s.getListCacheKey(ctx, filters)
+	cached, err := s.client.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var cachedPage user.Page
+		if err := json.Unmarshal([]byte(cached), &cachedPage); err == nil {
+			return &cachedPage, nil
+		}
+		fmt.Printf("Failed to deserialize cached user page: %v\n", err)
+	} else if err != redis.Nil {
+		fmt.Printf("Cache error for user page %s: %v\n", cacheKey, err)
+	}
+
+	return s.refreshList(ctx, filters)
+}
+
 // UpdateProfile updates user profile (cache invalidation pattern)
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	// Call next service to update profile
@@ -108,25 +270,146 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 		return nil, err
 	}
 
-	// Invalidate cache for this user
-	cacheKey := s.getUserCacheKey(id)
-	if err := s.client.Del(ctx, cacheKey).Err(); err != nil {
+	// Replace the cached user, or drop it so the next read loads the update
+	if s.config.WriteThrough {
+		if err := s.cacheUser(ctx, result); err != nil {
+			fmt.Printf("Failed to cache updated user %s: %v\n", id, err)
+		}
+	} else if err := s.invalidate(ctx, s.getUserCacheKey(id)); err != nil {
 		fmt.Printf("Failed to invalidate cache for user %s: %v\n", id, err)
 	}
 
-	// If email was updated, invalidate old email cache
-	if data.Email != nil {
-		// We can't know the old email without another query, so we just cache the new data
-		if err := s.cacheUser(ctx, result); err != nil {
-			fmt.Printf("Failed to cache updated user %s: %v\n", id, err)
+	return result, nil
+}
+
+// ChangePassword changes a user's password (cache invalidation pattern)
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	// Call next service to change the password
+	if err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the new updated_at is visible
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset invalidates the cached user, whose reset request changed
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := result.ID.String()
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+
+	return result, nil
+}
+
+// ResetPassword invalidates the cached user once the password was reset
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := s.next.ResetPassword(ctx, token, newPassword); err != nil {
+		return err
+	}
+
+	if userID := user.PasswordResetFromContext(ctx); userID != "" {
+		if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+			fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
 		}
 	}
 
+	return nil
+}
+
+// RequestEmailChange records a pending email (cache invalidation pattern)
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	// Call next service to record the request
+	if err := s.next.RequestEmailChange(ctx, userID, newEmail); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the pending email is visible
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending email (cache invalidation pattern)
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	// Call next service to apply the change
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the cached user and drop anything cached under the new email
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+	if err := s.invalidate(ctx, s.getEmailCacheKey(result.Email)); err != nil {
+		fmt.Printf("Failed to invalidate email cache for user %s: %v\n", userID, err)
+	}
+	if err := s.cacheUser(ctx, result); err != nil {
+		fmt.Printf("Failed to cache updated user %s: %v\n", userID, err)
+	}
+
 	return result, nil
 }
 
+// VerifyEmail marks the user's email verified (cache invalidation pattern)
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	result, err := s.next.VerifyEmail(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the cached user with the verified one
+	userID := result.ID.String()
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+	if err := s.cacheUser(ctx, result); err != nil {
+		fmt.Printf("Failed to cache updated user %s: %v\n", userID, err)
+	}
+
+	return result, nil
+}
+
+// UploadAvatar stores a new avatar (cache invalidation pattern)
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	// Call next service to store the image
+	if err := s.next.UploadAvatar(ctx, userID, content, contentType); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the new avatar key is visible
+	if err := s.invalidate(ctx, s.getUserCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", userID, err)
+	}
+
+	return nil
+}
+
+// GetAvatarURL returns a link to the avatar; links may be signed and expire, so they are not cached
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences retrieves user preferences (cache aside pattern)
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshPreferences(ctx, userID)
+	}
+
 	// Try to get from cache first
 	cacheKey := s.getPreferencesCacheKey(userID)
 	cached, err := s.client.Get(ctx, cacheKey).Result()
@@ -144,17 +427,7 @@ func (s *service) GetPreferences(ctx context.Context, userID string) (*user.User
 	}
 
 	// Cache miss or error - get from next service
-	result, err := s.next.GetPreferences(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Cache the result
-	if err := s.cachePreferences(ctx, userID, result); err != nil {
-		fmt.Printf("Failed to cache preferences %s: %v\n", userID, err)
-	}
-
-	return result, nil
+	return s.refreshPreferences(ctx, userID)
 }
 
 // UpdatePreferences updates user preferences (cache invalidation pattern)
@@ -166,22 +439,301 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 	}
 
 	// Invalidate cache for these preferences
-	cacheKey := s.getPreferencesCacheKey(userID)
-	if err := s.client.Del(ctx, cacheKey).Err(); err != nil {
+	if err := s.invalidate(ctx, s.getPreferencesCacheKey(userID)); err != nil {
 		fmt.Printf("Failed to invalidate preferences cache for user %s: %v\n", userID, err)
 	}
 
-	// Cache the updated preferences
-	if err := s.cachePreferences(ctx, userID, &prefs); err != nil {
-		fmt.Printf("Failed to cache updated preferences %s: %v\n", userID, err)
+	// Cache the updated preferences once their stored version is known
+	if updated, ok := prefs.Updated(); ok {
+		if err := s.cachePreferences(ctx, userID, &updated); err != nil {
+			fmt.Printf("Failed to cache updated preferences %s: %v\n", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// UpdatePreferencesBulk updates several users' preferences and caches them in one pipeline
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	if err := s.next.UpdatePreferencesBulk(ctx, updates); err != nil {
+		return err
+	}
+
+	if err := s.cachePreferencesBulk(ctx, updates); err != nil {
+		fmt.Printf("Failed to cache updated preferences for %d users: %v\n", len(updates), err)
+		for userID := range updates {
+			if err := s.invalidate(ctx, s.getPreferencesCacheKey(userID)); err != nil {
+				fmt.Printf("Failed to invalidate preferences cache for user %s: %v\n", userID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Deactivate deactivates a user (cache invalidation pattern)
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	// Call next service to deactivate the user
+	if err := s.next.Deactivate(ctx, id); err != nil {
+		return err
+	}
+
+	// Invalidate cache for this user so the deactivation is visible
+	if err := s.invalidate(ctx, s.getUserCacheKey(id)); err != nil {
+		fmt.Printf("Failed to invalidate cache for user %s: %v\n", id, err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a user (cache invalidation pattern)
+func (s *service) Delete(ctx context.Context, id string) error {
+	// Call next service to delete the user
+	if err := s.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Invalidate every cached read of the user so reads return not found
+	s.invalidateUser(ctx, id)
+
+	return nil
+}
+
+// ExportUserData exports a user's data; exports are never served from cache
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser erases a user's personal data (cache invalidation pattern)
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	// Call next service to erase the user
+	if err := s.next.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// Cached copies hold the personal data that was just erased
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// CleanupPreferences removes stale notification types and drops the cached
+// preferences of every user whose preferences were stripped
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	report, err := s.next.CleanupPreferences(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Stripped {
+		for _, userID := range report.AffectedUsers {
+			cacheKey := s.getPreferencesCacheKey(userID)
+			if err := s.invalidate(ctx, cacheKey); err != nil {
+				fmt.Printf("Failed to invalidate cache %s: %v\n", cacheKey, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// GetFeatureFlags retrieves the user's feature flags (cache aside pattern)
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	// Skip the cached copy when the caller requires a fresh read
+	if user.IsCacheBypassed(ctx) {
+		return s.refreshFeatureFlags(ctx, userID)
+	}
+
+	cached, err := s.client.Get(ctx, s.getFeatureFlagsCacheKey(userID)).Result()
+	if err == nil {
+		var cachedFlags user.FeatureFlags
+		if err := json.Unmarshal([]byte(cached), &cachedFlags); err == nil {
+			return &cachedFlags, nil
+		}
+		fmt.Printf("Failed to deserialize cached feature flags: %v\n", err)
+	} else if err != redis.Nil {
+		fmt.Printf("Cache error for feature flags %s: %v\n", userID, err)
+	}
+
+	// Cache miss or error - get from next service
+	return s.refreshFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag switches a feature flag (cache invalidation pattern)
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	if err := s.next.SetFeatureFlag(ctx, userID, flag, enabled); err != nil {
+		return err
+	}
+
+	// Drop the cached flags so the next check sees the change
+	if err := s.invalidate(ctx, s.getFeatureFlagsCacheKey(userID)); err != nil {
+		fmt.Printf("Failed to invalidate feature flags cache for user %s: %v\n", userID, err)
 	}
 
 	return nil
 }
 
 // Helper methods for caching operations
+//
+// Cancellation policy: reads that miss the cache abort promptly once the caller
+// has gone away instead of querying the next layer, while cache writes and
+// invalidations run on a detached context so a disconnect after a successful
+// update cannot leave stale data behind.
+
+// refreshUser loads the user from the next service and repopulates the cache
+func (s *service) refreshUser(ctx context.Context, id string) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByID(ctx, id)
+	if errors.Is(err, user.ErrUserNotFound) && !user.IsDeletedIncluded(ctx) {
+		if err := s.cacheNotFound(ctx, id); err != nil {
+			fmt.Printf("Failed to cache missing user %s: %v\n", id, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheUser(ctx, result); err != nil {
+		fmt.Printf("Failed to cache user %s: %v\n", id, err)
+	}
+
+	return result, nil
+}
+
+// loadUser refreshes a user that missed the cache. Concurrent misses for the
+// same user share one load, which runs detached from any single caller so
+// that one disconnect does not fail the others; each caller still stops
+// waiting as soon as its own context ends.
+func (s *service) loadUser(ctx context.Context, id string) (*user.User, error) {
+	if s.config.DisableCoalescing {
+		return s.refreshUser(ctx, id)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Reads that include soft-deleted users may see a different result
+	key := id
+	if user.IsDeletedIncluded(ctx) {
+		key = "deleted:" + id
+	}
+
+	leader := false
+	loaded := s.loads.DoChan(key, func() (interface{}, error) {
+		leader = true
+		loadCtx, cancel := user.DetachContext(ctx)
+		defer cancel()
+		return s.refreshUser(loadCtx, id)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-loaded:
+		if result.Shared && !leader && s.config.Coalesced != nil {
+			s.config.Coalesced.Inc()
+		}
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		// Every caller gets its own copy, as it would from the cache
+		shared := *result.Val.(*user.User)
+		return &shared, nil
+	}
+}
+
+// refreshUsers loads users from the next service and caches them in one pipeline
+func (s *service) refreshUsers(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheUsers(ctx, result); err != nil {
+		fmt.Printf("Failed to cache %d users: %v\n", len(result), err)
+	}
+	if !user.IsDeletedIncluded(ctx) {
+		for _, id := range ids {
+			if _, found := result[id]; found {
+				continue
+			}
+			if err := s.cacheNotFound(ctx, id); err != nil {
+				fmt.Printf("Failed to cache missing user %s: %v\n", id, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// refreshPreferences loads preferences from the next service and repopulates the cache
+func (s *service) refreshPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cachePreferences(ctx, userID, result); err != nil {
+		fmt.Printf("Failed to cache preferences %s: %v\n", userID, err)
+	}
+
+	return result, nil
+}
+
+// refreshFeatureFlags loads feature flags from the next service and repopulates the cache
+func (s *service) refreshFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.GetFeatureFlags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheFeatureFlags(ctx, userID, result); err != nil {
+		fmt.Printf("Failed to cache feature flags %s: %v\n", userID, err)
+	}
+
+	return result, nil
+}
+
+// refreshList loads a page from the next service and caches it
+func (s *service) refreshList(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.next.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheList(ctx, filters, result); err != nil {
+		fmt.Printf("Failed to cache user page: %v\n", err)
+	}
+
+	return result, nil
+}
 
 func (s *service) cacheUser(ctx context.Context, u *user.User) error {
+	// Never cache soft-deleted users; they are only read with an include-deleted
+	// context and must not be served to regular reads
+	if u.IsDeleted() {
+		return nil
+	}
+
 	// Serialize user to JSON
 	data, err := json.Marshal(u)
 	if err != nil {
@@ -189,8 +741,57 @@ func (s *service) cacheUser(ctx context.Context, u *user.User) error {
 	}
 
 	// Store in cache with TTL
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
 	cacheKey := s.getUserCacheKey(u.ID.String())
-	return s.client.Set(ctx, cacheKey, data, s.ttl).Err()
+	return s.client.Set(ctx, cacheKey, data, s.userTTL(u)).Err()
+}
+
+// cacheNotFound remembers that the user does not exist for TTLs.NotFound
+func (s *service) cacheNotFound(ctx context.Context, id string) error {
+	if s.config.TTLs.NotFound <= 0 {
+		return nil
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	return s.client.Set(ctx, s.getUserCacheKey(id), notFoundMarker, s.config.TTLs.NotFound).Err()
+}
+
+// userTTL returns how long the user is cached, applying the per-user override
+func (s *service) userTTL(u *user.User) time.Duration {
+	if s.config.UserTTL != nil {
+		if ttl := s.config.UserTTL(u); ttl > 0 {
+			return ttl
+		}
+	}
+	return s.config.TTLs.User
+}
+
+// cacheUsers stores several users with a single round trip, skipping soft-deleted users
+func (s *service) cacheUsers(ctx context.Context, users map[string]*user.User) error {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	pipe := s.client.Pipeline()
+	for _, u := range users {
+		if u.IsDeleted() {
+			continue
+		}
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, s.getUserCacheKey(u.ID.String()), data, s.userTTL(u))
+	}
+	if pipe.Len() == 0 {
+		return nil
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (s *service) cachePreferences(ctx context.Context, userID string, prefs *user.UserPreferences) error {
@@ -201,8 +802,80 @@ func (s *service) cachePreferences(ctx context.Context, userID string, prefs *us
 	}
 
 	// Store in cache with TTL
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
 	cacheKey := s.getPreferencesCacheKey(userID)
-	return s.client.Set(ctx, cacheKey, data, s.ttl).Err()
+	return s.client.Set(ctx, cacheKey, data, s.config.TTLs.Preferences).Err()
+}
+
+// cachePreferencesBulk stores several users' preferences with a single round trip
+func (s *service) cachePreferencesBulk(ctx context.Context, prefs map[string]user.UserPreferences) error {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	pipe := s.client.Pipeline()
+	for userID, p := range prefs {
+		updated, ok := p.Updated()
+		if !ok {
+			// The stored version is unknown, so the next read reloads them
+			pipe.Del(ctx, s.getPreferencesCacheKey(userID))
+			continue
+		}
+
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, s.getPreferencesCacheKey(userID), data, s.config.TTLs.Preferences)
+	}
+	if pipe.Len() == 0 {
+		return nil
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *service) cacheFeatureFlags(ctx context.Context, userID string, flags *user.FeatureFlags) error {
+	data, err := json.Marshal(flags)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	return s.client.Set(ctx, s.getFeatureFlagsCacheKey(userID), data, s.config.TTLs.FeatureFlags).Err()
+}
+
+func (s *service) cacheList(ctx context.Context, filters user.ListFilters, page *user.Page) error {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+
+	cacheKey := s.getListCacheKey(ctx, filters)
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	return s.client.Set(ctx, cacheKey, data, s.config.TTLs.List).Err()
+}
+
+// invalidateUser drops every cached read of the user
+func (s *service) invalidateUser(ctx context.Context, userID string) {
+	for _, cacheKey := range []string{s.getUserCacheKey(userID), s.getPreferencesCacheKey(userID), s.getFeatureFlagsCacheKey(userID)} {
+		if err := s.invalidate(ctx, cacheKey); err != nil {
+			fmt.Printf("Failed to invalidate cache %s: %v\n", cacheKey, err)
+		}
+	}
+}
+
+func (s *service) invalidate(ctx context.Context, cacheKey string) error {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	return s.client.Del(ctx, cacheKey).Err()
 }
 
 func (s *service) getUserCacheKey(userID string) string {
@@ -213,6 +886,22 @@ func (s *service) getPreferencesCacheKey(userID string) string {
 	return fmt.Sprintf("user_preferences:%s", userID)
 }
 
+func (s *service) getFeatureFlagsCacheKey(userID string) string {
+	return fmt.Sprintf("user_feature_flags:%s", userID)
+}
+
+// getListCacheKey hashes the filters so search terms never appear in key names.
+// Reads that include deleted users are cached separately.
+func (s *service) getListCacheKey(ctx context.Context, filters user.ListFilters) string {
+	data, _ := json.Marshal(struct {
+		Filters        user.ListFilters `json:"filters"`
+		IncludeDeleted bool             `json:"include_deleted"`
+	}{filters, user.IsDeletedIncluded(ctx)})
+
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("user_list:%s", hex.EncodeToString(sum[:]))
+}
+
 func (s *service) getEmailCacheKey(email string) string {
 	return fmt.Sprintf("user_email:%s", email)
 }
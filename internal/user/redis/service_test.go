@@ -3,10 +3,14 @@ package redis_test
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -373,6 +377,425 @@ func TestUserCacheService_UpdateProfile(t *testing.T) {
 	}
 }
 
+func TestUserCacheService_Delete(t *testing.T) {
+	t.Run("Given user exists in cache, When Delete is called, Then should invalidate so reads go to next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewService(mockNext, redisClient, time.Minute)
+
+		userID := "550e8400-e29b-41d4-a716-446655440050"
+		cachedJSON, _ := json.Marshal(&user.User{ID: uuid.MustParse(userID), Email: "deleted@example.com"})
+		redisClient.Set(context.Background(), "user:"+userID, cachedJSON, time.Minute)
+		mockNext.On("Delete", mock.Anything, userID).Return(nil)
+		mockNext.On("GetByID", mock.Anything, userID).Return(nil, user.ErrUserNotFound)
+
+		// Act
+		err := cache.Delete(context.Background(), userID)
+		result, getErr := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, user.ErrUserNotFound, getErr)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a soft-deleted user, When GetByID includes deleted users, Then should return it without caching", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewService(mockNext, setupTestRedis(), time.Minute)
+
+		userID := "550e8400-e29b-41d4-a716-446655440051"
+		deletedAt := time.Now()
+		deleted := &user.User{ID: uuid.MustParse(userID), DeletedAt: &deletedAt}
+		ctx := user.WithIncludeDeleted(context.Background())
+		mockNext.On("GetByID", mock.Anything, userID).Return(deleted, nil).Twice()
+
+		// Act
+		first, err := cache.GetByID(ctx, userID)
+		require.NoError(t, err)
+		second, err := cache.GetByID(ctx, userID)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, first.IsDeleted())
+		assert.True(t, second.IsDeleted())
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_GetByIDs(t *testing.T) {
+	t.Run("Given users not in cache, When GetByIDs is called, Then should fetch the misses from next service in one call", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewService(mockNext, redisClient, time.Minute)
+
+		ids := []string{"550e8400-e29b-41d4-a716-446655440060", "550e8400-e29b-41d4-a716-446655440061"}
+		redisClient.Del(context.Background(), "user:"+ids[0], "user:"+ids[1])
+		found := map[string]*user.User{ids[0]: {ID: uuid.MustParse(ids[0]), Email: "first@example.com"}}
+		mockNext.On("GetByIDs", mock.Anything, ids).Return(found, nil).Once()
+
+		// Act
+		result, err := cache.GetByIDs(context.Background(), ids)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "first@example.com", result[ids[0]].Email)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_NegativeCaching(t *testing.T) {
+	t.Run("Given negative caching is enabled, When a missing user is looked up twice, Then should return not found both times", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithConfig(mockNext, redisClient, userRedis.Config{
+			TTLs: userRedis.TTLConfig{User: time.Minute, NotFound: time.Minute},
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440070"
+		redisClient.Del(context.Background(), "user:"+userID)
+		// The second lookup is served from the cache when Redis is available
+		mockNext.On("GetByID", mock.Anything, userID).Return(nil, user.ErrUserNotFound).Once()
+		mockNext.On("GetByID", mock.Anything, userID).Return(nil, user.ErrUserNotFound).Maybe()
+
+		// Act
+		_, firstErr := cache.GetByID(context.Background(), userID)
+		_, secondErr := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		assert.ErrorIs(t, firstErr, user.ErrUserNotFound)
+		assert.ErrorIs(t, secondErr, user.ErrUserNotFound)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a missing user is cached, When GetByID includes deleted users, Then should fetch from next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithConfig(mockNext, redisClient, userRedis.Config{
+			TTLs: userRedis.TTLConfig{User: time.Minute, NotFound: time.Minute},
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440071"
+		deletedUser := &user.User{ID: uuid.MustParse(userID), Email: "deleted@example.com"}
+		redisClient.Set(context.Background(), "user:"+userID, "!not_found", time.Minute)
+		mockNext.On("GetByID", mock.Anything, userID).Return(deletedUser, nil).Once()
+
+		// Act
+		result, err := cache.GetByID(user.WithIncludeDeleted(context.Background()), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "deleted@example.com", result.Email)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_WriteThrough(t *testing.T) {
+	t.Run("Given write-through is disabled, When GetByID follows UpdateProfile, Then should fetch the updated user from next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithConfig(mockNext, redisClient, userRedis.Config{
+			TTLs: userRedis.TTLConfig{User: time.Minute},
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440072"
+		firstName := "Updated"
+		updated := &user.User{ID: uuid.MustParse(userID), Email: "write@example.com", FirstName: firstName}
+		mockNext.On("UpdateProfile", mock.Anything, userID, mock.Anything).Return(updated, nil).Once()
+		mockNext.On("GetByID", mock.Anything, userID).Return(updated, nil).Once()
+
+		// Act
+		_, err := cache.UpdateProfile(context.Background(), userID, user.UpdateProfileData{FirstName: &firstName})
+		require.NoError(t, err)
+		result, err := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", result.FirstName)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a per-user TTL override, When GetByID caches the user, Then should expire the entry with the override", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithConfig(mockNext, redisClient, userRedis.Config{
+			TTLs:    userRedis.TTLConfig{User: time.Hour},
+			UserTTL: func(*user.User) time.Duration { return 10 * time.Second },
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440073"
+		redisClient.Del(context.Background(), "user:"+userID)
+		mockNext.On("GetByID", mock.Anything, userID).Return(&user.User{ID: uuid.MustParse(userID)}, nil).Once()
+
+		// Act
+		_, err := cache.GetByID(context.Background(), userID)
+
+		// Assert
+		require.NoError(t, err)
+		if ttl, err := redisClient.TTL(context.Background(), "user:"+userID).Result(); err == nil {
+			assert.LessOrEqual(t, ttl, 10*time.Second)
+		}
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_Coalescing(t *testing.T) {
+	t.Run("Given concurrent misses for the same user, When GetByID is called, Then should load the user from next service once", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		registry := prometheus.NewRegistry()
+		coalesced, err := userRedis.NewCoalescedCounter(registry)
+		require.NoError(t, err)
+		cache := userRedis.NewServiceWithConfig(mockNext, setupUnavailableRedis(), userRedis.Config{
+			TTLs:      userRedis.TTLConfig{User: time.Minute},
+			Coalesced: coalesced,
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440080"
+		release := make(chan struct{})
+		var loads atomic.Int32
+		mockNext.On("GetByID", mock.Anything, userID).
+			Run(func(mock.Arguments) {
+				loads.Add(1)
+				<-release
+			}).
+			Return(&user.User{ID: uuid.MustParse(userID), Email: "shared@example.com"}, nil)
+
+		// Act
+		const callers = 5
+		var wg sync.WaitGroup
+		results := make([]*user.User, callers)
+		errs := make([]error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = cache.GetByID(context.Background(), userID)
+			}(i)
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		// Assert
+		for i := 0; i < callers; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, "shared@example.com", results[i].Email)
+		}
+		assert.Equal(t, int32(1), loads.Load())
+		assert.Equal(t, float64(callers-1), testutil.ToFloat64(coalesced))
+	})
+
+	t.Run("Given coalescing is disabled, When concurrent misses call GetByID, Then should load the user once per caller", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewServiceWithConfig(mockNext, setupUnavailableRedis(), userRedis.Config{
+			TTLs:              userRedis.TTLConfig{User: time.Minute},
+			DisableCoalescing: true,
+		})
+
+		const callers = 3
+		userID := "550e8400-e29b-41d4-a716-446655440081"
+		arrived := make(chan struct{}, callers)
+		release := make(chan struct{})
+		mockNext.On("GetByID", mock.Anything, userID).
+			Run(func(mock.Arguments) {
+				arrived <- struct{}{}
+				<-release
+			}).
+			Return(&user.User{ID: uuid.MustParse(userID)}, nil)
+
+		// Act
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = cache.GetByID(context.Background(), userID)
+			}()
+		}
+		for i := 0; i < callers; i++ {
+			select {
+			case <-arrived:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected every caller to reach the next service")
+			}
+		}
+		close(release)
+		wg.Wait()
+
+		// Assert
+		mockNext.AssertNumberOfCalls(t, "GetByID", callers)
+	})
+
+	t.Run("Given a caller disconnects while waiting, When the shared load completes, Then should still serve the other callers", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewServiceWithConfig(mockNext, setupUnavailableRedis(), userRedis.Config{
+			TTLs: userRedis.TTLConfig{User: time.Minute},
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440082"
+		started := make(chan struct{})
+		release := make(chan struct{})
+		mockNext.On("GetByID", mock.Anything, userID).
+			Run(func(mock.Arguments) {
+				close(started)
+				<-release
+			}).
+			Return(&user.User{ID: uuid.MustParse(userID), Email: "kept@example.com"}, nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := cache.GetByID(ctx, userID)
+			leaderErr <- err
+		}()
+		<-started
+
+		followerResult := make(chan *user.User, 1)
+		go func() {
+			result, _ := cache.GetByID(context.Background(), userID)
+			followerResult <- result
+		}()
+
+		// Act
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		// Assert
+		result := <-followerResult
+		require.NotNil(t, result)
+		assert.Equal(t, "kept@example.com", result.Email)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_CacheBypass(t *testing.T) {
+	t.Run("Given user exists in cache, When GetByID is called with cache bypass, Then should fetch fresh data from next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithTTLs(mockNext, redisClient, userRedis.TTLConfig{
+			User:        time.Minute,
+			Preferences: 10 * time.Minute,
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440030"
+		staleUser := &user.User{ID: uuid.MustParse(userID), Email: "stale@example.com"}
+		freshUser := &user.User{ID: uuid.MustParse(userID), Email: "fresh@example.com"}
+
+		staleJSON, _ := json.Marshal(staleUser)
+		redisClient.Set(context.Background(), "user:"+userID, staleJSON, time.Minute)
+		mockNext.On("GetByID", mock.Anything, userID).Return(freshUser, nil).Once()
+
+		// Act
+		result, err := cache.GetByID(user.WithCacheBypass(context.Background()), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "fresh@example.com", result.Email)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given preferences exist in cache, When GetPreferences is called with cache bypass, Then should fetch fresh data from next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		redisClient := setupTestRedis()
+		cache := userRedis.NewServiceWithTTLs(mockNext, redisClient, userRedis.TTLConfig{
+			User:        time.Minute,
+			Preferences: 10 * time.Minute,
+		})
+
+		userID := "550e8400-e29b-41d4-a716-446655440031"
+		stalePrefs := &user.UserPreferences{UserID: uuid.MustParse(userID), Theme: "light"}
+		freshPrefs := &user.UserPreferences{UserID: uuid.MustParse(userID), Theme: "dark"}
+
+		staleJSON, _ := json.Marshal(stalePrefs)
+		redisClient.Set(context.Background(), "user_preferences:"+userID, staleJSON, time.Minute)
+		mockNext.On("GetPreferences", mock.Anything, userID).Return(freshPrefs, nil).Once()
+
+		// Act
+		result, err := cache.GetPreferences(user.WithCacheBypass(context.Background()), userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "dark", result.Theme)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserCacheService_Cancellation(t *testing.T) {
+	t.Run("Given a canceled context, When GetByID misses the cache, Then should abort without calling next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewService(mockNext, setupTestRedis(), time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		result, err := cache.GetByID(ctx, "550e8400-e29b-41d4-a716-446655440040")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, context.Canceled)
+		mockNext.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a canceled context, When GetPreferences misses the cache, Then should abort without calling next service", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewService(mockNext, setupTestRedis(), time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		result, err := cache.GetPreferences(ctx, "550e8400-e29b-41d4-a716-446655440041")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, context.Canceled)
+		mockNext.AssertNotCalled(t, "GetPreferences", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given the caller disconnects after the update, When UpdatePreferences completes, Then should still succeed", func(t *testing.T) {
+		// Arrange
+		mockNext := new(usermock.MockUserService)
+		cache := userRedis.NewService(mockNext, setupTestRedis(), time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		userID := "550e8400-e29b-41d4-a716-446655440042"
+		mockNext.On("UpdatePreferences", mock.Anything, userID, mock.Anything).
+			Run(func(mock.Arguments) { cancel() }).
+			Return(nil)
+
+		// Act
+		err := cache.UpdatePreferences(ctx, userID, user.UserPreferences{Theme: "dark"})
+
+		// Assert
+		require.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+// setupUnavailableRedis creates a Redis client whose commands fail at once,
+// so every read falls through to the next service
+func setupUnavailableRedis() *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:       "127.0.0.1:1",
+		MaxRetries: -1,
+	})
+}
+
 // setupTestRedis creates a Redis client for testing
 // In a real test environment, you might use a test container or embedded Redis
 func setupTestRedis() *redis.Client {
@@ -0,0 +1,59 @@
+package sqlite
+
+// schema mirrors the Postgres migrations for SQLite. IDs are generated by the
+// GORM models, so the tables have no UUID defaults, and timestamps and JSON
+// are stored as text.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		email TEXT NOT NULL,
+		password_hash TEXT NOT NULL,
+		first_name TEXT NOT NULL,
+		last_name TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		deactivated_at DATETIME,
+		deleted_at DATETIME,
+		pending_email TEXT NOT NULL DEFAULT '',
+		email_change_requested_at DATETIME,
+		email_verified_at DATETIME,
+		password_reset_requested_at DATETIME,
+		avatar_key TEXT NOT NULL DEFAULT '',
+		version INTEGER NOT NULL DEFAULT 1
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at)`,
+
+	// Soft-deleted users keep their row, so only live users must have unique emails
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL`,
+
+	`CREATE TABLE IF NOT EXISTS user_preferences (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL UNIQUE REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+		email_notifications BOOLEAN NOT NULL DEFAULT TRUE,
+		push_notifications BOOLEAN NOT NULL DEFAULT TRUE,
+		sms_notifications BOOLEAN NOT NULL DEFAULT FALSE,
+		theme TEXT NOT NULL DEFAULT 'light',
+		language TEXT NOT NULL DEFAULT 'en',
+		timezone TEXT NOT NULL DEFAULT 'UTC',
+		notification_types TEXT,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		version INTEGER NOT NULL DEFAULT 1
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS password_history (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+		password_hash TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_password_history_user_created ON password_history(user_id, created_at)`,
+
+	`CREATE TABLE IF NOT EXISTS user_feature_flags (
+		user_id TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+		flag TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, flag)
+	)`,
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userGorm "github.com/gentra/decorator-arch-go/internal/user/gorm"
+)
+
+// MemoryPath opens a private in-memory database that is gone once closed
+const MemoryPath = ":memory:"
+
+// Open opens the SQLite database file at path, creating it and the user
+// schema if needed. SQLite allows a single writer, so the pool is limited to
+// one connection; this also keeps every query on the same in-memory database.
+func Open(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path+"?_foreign_keys=on"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	for _, statement := range schema {
+		if err := db.Exec(statement).Error; err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// NewService creates a user service storing users in the SQLite database
+// opened with Open
+func NewService(db *gorm.DB) user.Service {
+	return NewServiceWithConfig(db, userGorm.Config{})
+}
+
+// NewServiceWithConfig creates a SQLite-backed user service with custom
+// settings. The schema matches the Postgres one, so the GORM storage layer
+// serves both databases.
+func NewServiceWithConfig(db *gorm.DB, config userGorm.Config) user.Service {
+	return userGorm.NewServiceWithConfig(db, config)
+}
@@ -0,0 +1,71 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/gentra/decorator-arch-go/internal/hash"
+	"github.com/gentra/decorator-arch-go/internal/hash/argon2id"
+	"github.com/gentra/decorator-arch-go/internal/hash/bcrypt"
+	"github.com/gentra/decorator-arch-go/internal/hash/versioned"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userGorm "github.com/gentra/decorator-arch-go/internal/user/gorm"
+	"github.com/gentra/decorator-arch-go/internal/user/sqlite"
+	"github.com/gentra/decorator-arch-go/internal/user/usertest"
+)
+
+func TestSQLiteService_Conformance(t *testing.T) {
+	usertest.RunServiceConformance(t, func() user.Service {
+		return sqlite.NewService(openTestDB(t))
+	})
+}
+
+func TestSQLiteService_GivenOutdatedPasswordHash_WhenLoggingIn_ThenRehashesWithCurrentAlgorithm(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db := openTestDB(t)
+	legacy := bcrypt.NewService(4)
+	current := argon2id.NewService(argon2id.Params{Memory: 1024, Iterations: 1, Parallelism: 1})
+	data := testkit.NewUserBuilder().BuildRegisterData()
+
+	_, err := sqlite.NewServiceWithConfig(db, userGorm.Config{Hasher: legacy}).Register(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, hash.AlgorithmBcrypt, hash.Identify(storedHash(t, db, data.Email)))
+
+	service := sqlite.NewServiceWithConfig(db, userGorm.Config{Hasher: versioned.NewService(current, legacy)})
+
+	// Act
+	_, err = service.Login(ctx, data.Email, data.Password)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, hash.AlgorithmArgon2id, hash.Identify(storedHash(t, db, data.Email)))
+	_, err = service.Login(ctx, data.Email, data.Password)
+	assert.NoError(t, err, "login should keep working with the new hash")
+	_, err = service.Login(ctx, data.Email, "wrong-password")
+	assert.Equal(t, user.ErrInvalidCredentials, err)
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := sqlite.Open(sqlite.MemoryPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func storedHash(t *testing.T, db *gorm.DB, email string) string {
+	t.Helper()
+	var model userGorm.UserModel
+	require.NoError(t, db.Where("email = ?", email).First(&model).Error)
+	return model.PasswordHash
+}
@@ -0,0 +1,172 @@
+package stepup
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Config controls how recent a sign-in sensitive operations require
+type Config struct {
+	MaxAge time.Duration // Time since the caller's sign-in after which they must sign in again
+}
+
+// DefaultConfig returns the default step-up configuration
+func DefaultConfig() Config {
+	return Config{MaxAge: 15 * time.Minute}
+}
+
+// service implements user.Service with step-up authentication
+// This decorator makes operations that would let a stolen session take over
+// or destroy an account - changing the password or email and erasing the
+// user - require the caller to have signed in within MaxAge, as recorded
+// with auth.WithAuthentication. Other callers get an auth.StepUpError and
+// must sign in again. Every other method passes through.
+type service struct {
+	next   user.Service
+	config Config
+}
+
+// NewService creates a new step-up decorator
+func NewService(next user.Service, config Config) user.Service {
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultConfig().MaxAge
+	}
+
+	return &service{
+		next:   next,
+		config: config,
+	}
+}
+
+// Login passes through; it is how callers step up
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	return s.next.Login(ctx, email, password)
+}
+
+// Register passes through
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	return s.next.Register(ctx, data)
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile passes through
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword requires a recent sign-in
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if err := auth.RequireRecentAuth(ctx, s.config.MaxAge); err != nil {
+		return err
+	}
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset passes through; the user is not signed in
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword passes through; the reset token proves the user controls their email
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange requires a recent sign-in
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	if err := auth.RequireRecentAuth(ctx, s.config.MaxAge); err != nil {
+		return err
+	}
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange passes through
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail passes through
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar passes through
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences passes through
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk passes through
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate passes through
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete passes through
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser requires a recent sign-in
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	if err := auth.RequireRecentAuth(ctx, s.config.MaxAge); err != nil {
+		return err
+	}
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences passes through
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
@@ -0,0 +1,109 @@
+package stepup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/auth"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	"github.com/gentra/decorator-arch-go/internal/user/stepup"
+)
+
+func signedInAgo(age time.Duration) context.Context {
+	return auth.WithAuthentication(context.Background(), auth.Authentication{
+		Time:    time.Now().Add(-age),
+		Methods: []string{token.AMRPassword},
+	})
+}
+
+func TestSensitiveOperations_GivenSignInAge_WhenCalling_ThenRequiresRecentSignIn(t *testing.T) {
+	operations := []struct {
+		name  string
+		setup func(*userMock.MockUserService)
+		call  func(user.Service, context.Context) error
+	}{
+		{
+			name: "ChangePassword",
+			setup: func(m *userMock.MockUserService) {
+				m.On("ChangePassword", mock.Anything, "user-1", "old", "new").Return(nil)
+			},
+			call: func(s user.Service, ctx context.Context) error {
+				return s.ChangePassword(ctx, "user-1", "old", "new")
+			},
+		},
+		{
+			name: "RequestEmailChange",
+			setup: func(m *userMock.MockUserService) {
+				m.On("RequestEmailChange", mock.Anything, "user-1", "new@example.com").Return(nil)
+			},
+			call: func(s user.Service, ctx context.Context) error {
+				return s.RequestEmailChange(ctx, "user-1", "new@example.com")
+			},
+		},
+		{
+			name:  "EraseUser",
+			setup: func(m *userMock.MockUserService) { m.On("EraseUser", mock.Anything, "user-1").Return(nil) },
+			call: func(s user.Service, ctx context.Context) error {
+				return s.EraseUser(ctx, "user-1")
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{name: "Given recent sign-in, When calling, Then passes through", ctx: signedInAgo(time.Minute)},
+		{name: "Given stale sign-in, When calling, Then requires step-up", ctx: signedInAgo(time.Hour), expectedErr: auth.ErrStepUpRequired},
+		{name: "Given unknown sign-in, When calling, Then requires step-up", ctx: context.Background(), expectedErr: auth.ErrStepUpRequired},
+	}
+
+	for _, op := range operations {
+		for _, tt := range tests {
+			t.Run(op.name+"/"+tt.name, func(t *testing.T) {
+				// Arrange
+				next := &userMock.MockUserService{}
+				op.setup(next)
+				service := stepup.NewService(next, stepup.Config{MaxAge: 5 * time.Minute})
+
+				// Act
+				err := op.call(service, tt.ctx)
+
+				// Assert
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+					var stepUpErr auth.StepUpError
+					require.True(t, errors.As(err, &stepUpErr))
+					assert.Equal(t, 5*time.Minute, stepUpErr.MaxAge)
+					assert.Empty(t, next.Calls)
+				} else {
+					require.NoError(t, err)
+					next.AssertExpectations(t)
+				}
+			})
+		}
+	}
+}
+
+func TestGetByID_GivenUnknownSignIn_WhenCalling_ThenPassesThrough(t *testing.T) {
+	// Arrange
+	next := &userMock.MockUserService{}
+	next.On("GetByID", mock.Anything, "user-1").Return(&user.User{}, nil)
+	service := stepup.NewService(next, stepup.DefaultConfig())
+
+	// Act
+	_, err := service.GetByID(context.Background(), "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	next.AssertExpectations(t)
+}
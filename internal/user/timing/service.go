@@ -0,0 +1,215 @@
+package timing
+
+import (
+	"context"
+	"io"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements user.Service by recording the time spent in the wrapped layer
+type service struct {
+	next  user.Service
+	layer string
+}
+
+// NewService wraps a layer so its own elapsed time is recorded under the given
+// name when the request context carries user.Timings
+func NewService(next user.Service, layer string) user.Service {
+	return &service{
+		next:  next,
+		layer: layer,
+	}
+}
+
+// Register records the layer time for user registration
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.Register(ctx, data)
+}
+
+// Login records the layer time for user login
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.Login(ctx, email, password)
+}
+
+// GetByID records the layer time for user retrieval
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs records the layer time for batch lookups
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List records the layer time for user listing
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile records the layer time for profile updates
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.UpdateProfile(ctx, id, data)
+}
+
+// ChangePassword records the layer time for password changes
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset records the layer time for password reset requests
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword records the layer time for password resets
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange records the layer time for email change requests
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange records the layer time for email change confirmations
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail records the layer time for email verifications
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar records the layer time for avatar uploads
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL records the layer time for avatar link requests
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences records the layer time for preferences retrieval
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences records the layer time for preferences updates
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.UpdatePreferences(ctx, userID, prefs)
+}
+
+// UpdatePreferencesBulk records the layer time for bulk preference updates
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.UpdatePreferencesBulk(ctx, updates)
+}
+
+// Deactivate records the layer time for deactivations
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete records the layer time for deletions
+func (s *service) Delete(ctx context.Context, id string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData records the layer time for data exports
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser records the layer time for erasures
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences records the layer time for cleanup runs
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags records the layer time for feature flag retrieval
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag records the layer time for feature flag updates
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	ctx, stop := user.StartLayerTiming(ctx, s.layer)
+	defer stop()
+
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
@@ -0,0 +1,331 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// instrumentationName identifies the spans produced by this decorator
+const instrumentationName = "github.com/gentra/decorator-arch-go/internal/user/tracing"
+
+// service implements user.Service with OpenTelemetry spans
+type service struct {
+	next   user.Service
+	tracer trace.Tracer
+}
+
+// NewService creates a new user service that starts a span per call as a
+// child of any span already in the context, such as one started by the HTTP layer
+func NewService(next user.Service, provider trace.TracerProvider) user.Service {
+	return &service{
+		next:   next,
+		tracer: provider.Tracer(instrumentationName),
+	}
+}
+
+// Register traces user registration
+func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	ctx, span := s.start(ctx, "Register")
+	defer span.End()
+
+	result, err := s.next.Register(ctx, data)
+	if result != nil {
+		span.SetAttributes(attribute.String("user.id", result.ID.String()))
+	}
+	s.finish(span, err)
+	return result, err
+}
+
+// Login traces user login
+func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	ctx, span := s.start(ctx, "Login")
+	defer span.End()
+
+	result, err := s.next.Login(ctx, email, password)
+	if result != nil && result.User != nil {
+		span.SetAttributes(attribute.String("user.id", result.User.ID.String()))
+	}
+	s.finish(span, err)
+	return result, err
+}
+
+// GetByID traces user retrieval
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	ctx, span := s.start(ctx, "GetByID", attribute.String("user.id", id))
+	defer span.End()
+
+	result, err := s.next.GetByID(ctx, id)
+	s.finish(span, err)
+	return result, err
+}
+
+// GetByIDs traces batch lookups
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	ctx, span := s.start(ctx, "GetByIDs", attribute.Int("user.batch.size", len(ids)))
+	defer span.End()
+
+	result, err := s.next.GetByIDs(ctx, ids)
+	span.SetAttributes(attribute.Int("user.batch.found", len(result)))
+	s.finish(span, err)
+	return result, err
+}
+
+// List traces user listing; search terms are not recorded
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	ctx, span := s.start(ctx, "List",
+		attribute.Int("user.list.limit", filters.Limit),
+		attribute.String("user.list.sort_by", filters.SortBy),
+		attribute.Bool("user.list.cursor", filters.Cursor != ""),
+	)
+	defer span.End()
+
+	result, err := s.next.List(ctx, filters)
+	if result != nil {
+		span.SetAttributes(
+			attribute.Int("user.list.returned", len(result.Users)),
+			attribute.Int64("user.list.total", result.Total),
+		)
+	}
+	s.finish(span, err)
+	return result, err
+}
+
+// UpdateProfile traces profile updates
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
+	ctx, span := s.start(ctx, "UpdateProfile", attribute.String("user.id", id))
+	defer span.End()
+
+	result, err := s.next.UpdateProfile(ctx, id, data)
+	s.finish(span, err)
+	return result, err
+}
+
+// ChangePassword traces password changes
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	ctx, span := s.start(ctx, "ChangePassword", attribute.String("user.id", userID))
+	defer span.End()
+
+	err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	s.finish(span, err)
+	return err
+}
+
+// RequestPasswordReset traces password reset requests
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	ctx, span := s.start(ctx, "RequestPasswordReset")
+	defer span.End()
+
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	if result != nil {
+		span.SetAttributes(attribute.String("user.id", result.ID.String()))
+	}
+	s.finish(span, err)
+	return result, err
+}
+
+// ResetPassword traces password resets
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := s.start(ctx, "ResetPassword")
+	defer span.End()
+
+	err := s.next.ResetPassword(ctx, token, newPassword)
+	s.finish(span, err)
+	return err
+}
+
+// RequestEmailChange traces email change requests
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	ctx, span := s.start(ctx, "RequestEmailChange", attribute.String("user.id", userID))
+	defer span.End()
+
+	err := s.next.RequestEmailChange(ctx, userID, newEmail)
+	s.finish(span, err)
+	return err
+}
+
+// ConfirmEmailChange traces email change confirmations
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	ctx, span := s.start(ctx, "ConfirmEmailChange", attribute.String("user.id", userID))
+	defer span.End()
+
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	s.finish(span, err)
+	return result, err
+}
+
+// VerifyEmail traces email verifications
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	ctx, span := s.start(ctx, "VerifyEmail")
+	defer span.End()
+
+	result, err := s.next.VerifyEmail(ctx, token)
+	s.finish(span, err)
+	return result, err
+}
+
+// UploadAvatar traces avatar uploads
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	ctx, span := s.start(ctx, "UploadAvatar", attribute.String("user.id", userID), attribute.String("avatar.content_type", contentType))
+	defer span.End()
+
+	err := s.next.UploadAvatar(ctx, userID, content, contentType)
+	s.finish(span, err)
+	return err
+}
+
+// GetAvatarURL traces avatar link requests
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	ctx, span := s.start(ctx, "GetAvatarURL", attribute.String("user.id", userID))
+	defer span.End()
+
+	result, err := s.next.GetAvatarURL(ctx, userID)
+	s.finish(span, err)
+	return result, err
+}
+
+// GetPreferences traces preferences retrieval
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	ctx, span := s.start(ctx, "GetPreferences", attribute.String("user.id", userID))
+	defer span.End()
+
+	result, err := s.next.GetPreferences(ctx, userID)
+	s.finish(span, err)
+	return result, err
+}
+
+// UpdatePreferences traces preferences updates
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	ctx, span := s.start(ctx, "UpdatePreferences", attribute.String("user.id", userID))
+	defer span.End()
+
+	err := s.next.UpdatePreferences(ctx, userID, prefs)
+	s.finish(span, err)
+	return err
+}
+
+// UpdatePreferencesBulk traces bulk preference updates
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	ctx, span := s.start(ctx, "UpdatePreferencesBulk", attribute.Int("user.batch.size", len(updates)))
+	defer span.End()
+
+	err := s.next.UpdatePreferencesBulk(ctx, updates)
+	s.finish(span, err)
+	return err
+}
+
+// Deactivate traces deactivations
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	ctx, span := s.start(ctx, "Deactivate", attribute.String("user.id", id))
+	defer span.End()
+
+	err := s.next.Deactivate(ctx, id)
+	s.finish(span, err)
+	return err
+}
+
+// Delete traces deletions
+func (s *service) Delete(ctx context.Context, id string) error {
+	ctx, span := s.start(ctx, "Delete", attribute.String("user.id", id))
+	defer span.End()
+
+	err := s.next.Delete(ctx, id)
+	s.finish(span, err)
+	return err
+}
+
+// ExportUserData traces data exports
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	ctx, span := s.start(ctx, "ExportUserData", attribute.String("user.id", userID))
+	defer span.End()
+
+	result, err := s.next.ExportUserData(ctx, userID)
+	s.finish(span, err)
+	return result, err
+}
+
+// EraseUser traces erasures
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	ctx, span := s.start(ctx, "EraseUser", attribute.String("user.id", userID))
+	defer span.End()
+
+	err := s.next.EraseUser(ctx, userID)
+	s.finish(span, err)
+	return err
+}
+
+// CleanupPreferences traces cleanup runs
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	ctx, span := s.start(ctx, "CleanupPreferences", attribute.Bool("user.cleanup.strip", opts.Strip))
+	defer span.End()
+
+	report, err := s.next.CleanupPreferences(ctx, opts)
+	if report != nil {
+		span.SetAttributes(
+			attribute.Int("user.cleanup.scanned", report.Scanned),
+			attribute.Int("user.cleanup.affected", len(report.AffectedUsers)),
+		)
+	}
+	s.finish(span, err)
+	return report, err
+}
+
+// GetFeatureFlags traces feature flag retrieval
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	ctx, span := s.start(ctx, "GetFeatureFlags", attribute.String("user.id", userID))
+	defer span.End()
+
+	result, err := s.next.GetFeatureFlags(ctx, userID)
+	s.finish(span, err)
+	return result, err
+}
+
+// SetFeatureFlag traces feature flag updates
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	ctx, span := s.start(ctx, "SetFeatureFlag",
+		attribute.String("user.id", userID),
+		attribute.String("user.feature_flag", flag),
+		attribute.Bool("user.feature_flag.enabled", enabled),
+	)
+	defer span.End()
+
+	err := s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+	s.finish(span, err)
+	return err
+}
+
+// Helper methods
+
+// start opens a span for the operation; email addresses and other PII are never recorded
+func (s *service) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("user.operation", operation))
+	return s.tracer.Start(ctx, "user."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// finish records the outcome on the span. Domain errors such as not found are
+// recorded with their code but do not mark the span as failed.
+func (s *service) finish(span trace.Span, err error) {
+	if err == nil {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+
+	var userErr user.UserError
+	if errors.As(err, &userErr) {
+		span.SetAttributes(attribute.String("user.error_code", userErr.Code))
+		span.AddEvent("domain_error", trace.WithAttributes(attribute.String("message", userErr.Message)))
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,85 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	"github.com/gentra/decorator-arch-go/internal/user/tracing"
+)
+
+func newTracedService(next user.Service) (user.Service, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return tracing.NewService(next, provider), recorder, provider
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTracing_GivenParentSpan_WhenCalling_ThenStartsChildSpanWithAttributes(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("GetByID", mock.Anything, "user-1").Return(&user.User{}, nil)
+	service, recorder, provider := newTracedService(next)
+
+	ctx, parent := provider.Tracer("http").Start(context.Background(), "GET /api/users/profile")
+	_, err := service.GetByID(ctx, "user-1")
+	parent.End()
+
+	require.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "user.GetByID", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.Equal(t, "user-1", attributeValue(span, "user.id"))
+	assert.Equal(t, "GetByID", attributeValue(span, "user.operation"))
+	assert.Equal(t, codes.Ok, span.Status().Code)
+}
+
+func TestTracing_GivenInfrastructureError_WhenCalling_ThenRecordsErrorStatus(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("UpdatePreferences", mock.Anything, "user-1", mock.Anything).Return(errors.New("connection refused"))
+	service, recorder, _ := newTracedService(next)
+
+	err := service.UpdatePreferences(context.Background(), "user-1", user.UserPreferences{})
+
+	require.Error(t, err)
+	span := recorder.Ended()[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "exception", span.Events()[0].Name)
+}
+
+func TestTracing_GivenDomainError_WhenCalling_ThenRecordsCodeWithoutFailingSpan(t *testing.T) {
+	next := &userMock.MockUserService{}
+	next.On("Login", mock.Anything, "a@example.com", "wrong").Return(nil, user.ErrInvalidCredentials)
+	service, recorder, _ := newTracedService(next)
+
+	_, err := service.Login(context.Background(), "a@example.com", "wrong")
+
+	assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+	span := recorder.Ended()[0]
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	assert.Equal(t, "INVALID_CREDENTIALS", attributeValue(span, "user.error_code"))
+	for _, attr := range span.Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "a@example.com")
+	}
+}
@@ -0,0 +1,187 @@
+package transaction
+
+import (
+	"context"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/pgtx"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// service implements user.Service with database transactions
+// This decorator runs every operation that changes a user and publishes an
+// event in one Postgres transaction carried by the context. The pgx storage
+// and the event outbox both write in it, so a change and its events commit
+// together or not at all. Side effects of the layers below that do not use
+// the database, such as cache updates and notifications, are not undone
+// when the transaction rolls back. Every other method passes through.
+type service struct {
+	next user.Service
+	pool *pgxpool.Pool
+}
+
+// NewService creates a new transaction decorator
+func NewService(next user.Service, pool *pgxpool.Pool) user.Service {
+	return &service{
+		next: next,
+		pool: pool,
+	}
+}
+
+// Register runs in a transaction
+func (s *service) Register(ctx context.Context, data user.RegisterData) (result *user.User, err error) {
+	err = pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		result, err = s.next.Register(ctx, data)
+		return err
+	})
+	return result, err
+}
+
+// Login runs in a transaction, as it may rehash the password
+func (s *service) Login(ctx context.Context, email, password string) (result *user.AuthResult, err error) {
+	err = pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		result, err = s.next.Login(ctx, email, password)
+		return err
+	})
+	return result, err
+}
+
+// GetByID passes through
+func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDs passes through
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
+// UpdateProfile runs in a transaction
+func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (result *user.User, err error) {
+	err = pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		result, err = s.next.UpdateProfile(ctx, id, data)
+		return err
+	})
+	return result, err
+}
+
+// ChangePassword runs in a transaction
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	})
+}
+
+// RequestPasswordReset passes through
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword runs in a transaction
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.ResetPassword(ctx, token, newPassword)
+	})
+}
+
+// RequestEmailChange passes through
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange runs in a transaction
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (result *user.User, err error) {
+	err = pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		result, err = s.next.ConfirmEmailChange(ctx, userID, token)
+		return err
+	})
+	return result, err
+}
+
+// VerifyEmail runs in a transaction
+func (s *service) VerifyEmail(ctx context.Context, token string) (result *user.User, err error) {
+	err = pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		result, err = s.next.VerifyEmail(ctx, token)
+		return err
+	})
+	return result, err
+}
+
+// UploadAvatar runs in a transaction
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.UploadAvatar(ctx, userID, content, contentType)
+	})
+}
+
+// GetAvatarURL passes through
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
+// GetPreferences passes through
+func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
+	return s.next.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences runs in a transaction
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs user.UserPreferences) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.UpdatePreferences(ctx, userID, prefs)
+	})
+}
+
+// UpdatePreferencesBulk runs in a transaction
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.UpdatePreferencesBulk(ctx, updates)
+	})
+}
+
+// Deactivate runs in a transaction
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.Deactivate(ctx, id)
+	})
+}
+
+// Delete runs in a transaction
+func (s *service) Delete(ctx context.Context, id string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.Delete(ctx, id)
+	})
+}
+
+// ExportUserData passes through
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser runs in a transaction
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	return pgtx.Run(ctx, s.pool, func(ctx context.Context) error {
+		return s.next.EraseUser(ctx, userID)
+	})
+}
+
+// CleanupPreferences passes through
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
@@ -2,14 +2,18 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/gentra/decorator-arch-go/internal/correlation"
 	"github.com/gentra/decorator-arch-go/internal/events"
 	"github.com/gentra/decorator-arch-go/internal/notification"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
 	"github.com/gentra/decorator-arch-go/internal/token"
 	"github.com/gentra/decorator-arch-go/internal/user"
 )
@@ -19,19 +23,46 @@ type Dependencies struct {
 	NotificationService notification.Service
 	TokenService        token.Service
 	EventPublisher      events.Service
+
+	// NotificationHistory holds the notifications sent to users, which
+	// ExportUserData exports and EraseUser purges; nil exports the history
+	// of NotificationService and purges nothing
+	NotificationHistory notificationhistory.Service
 }
 
+// Config contains the business rules of the usecase layer
+type Config struct {
+	// How long after registering users can sign in without verifying their
+	// email; zero never blocks unverified users
+	EmailVerificationGracePeriod time.Duration
+
+	// DisableWelcomeEmail leaves the welcome email to a handler of the user
+	// registered event
+	DisableWelcomeEmail bool
+}
+
+// verifyEmailClaim names the address a verification token proves, so tokens
+// sent for an email change or an earlier address cannot verify the current one
+const verifyEmailClaim = "verify_email"
+
 // service implements the user.Service interface with business logic
 type service struct {
-	next user.Service
-	deps Dependencies
+	next   user.Service
+	deps   Dependencies
+	config Config
 }
 
 // NewService creates a new usecase service with business logic
 func NewService(next user.Service, deps Dependencies) user.Service {
+	return NewServiceWithConfig(next, deps, Config{})
+}
+
+// NewServiceWithConfig creates a usecase service with custom business rules
+func NewServiceWithConfig(next user.Service, deps Dependencies, config Config) user.Service {
 	return &service{
-		next: next,
-		deps: deps,
+		next:   next,
+		deps:   deps,
+		config: config,
 	}
 }
 
@@ -43,17 +74,28 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 		return nil, err
 	}
 
-	// Business logic: Send welcome email (non-blocking)
+	// Business logic: Send welcome and verification emails (fire-and-forget).
+	// The detached context outlives the request but keeps its values, such as
+	// notification dry-run mode. The emails are recorded in the user's
+	// notification history.
+	backgroundCtx, cancel := user.DetachContext(ctx)
+	backgroundCtx = notification.WithRecipient(backgroundCtx, result.ID.String())
 	go func() {
-		// Use a background context to avoid cancellation affecting this operation
-		backgroundCtx := context.Background()
-		if err := s.deps.NotificationService.SendWelcomeEmail(
-			backgroundCtx,
-			result.Email,
-			result.GetFullName(),
-		); err != nil {
-			// Log error but don't fail the registration
-			log.Printf("Failed to send welcome email to %s: %v", result.Email, err)
+		defer cancel()
+		if !s.config.DisableWelcomeEmail {
+			if err := s.deps.NotificationService.SendWelcomeEmail(
+				backgroundCtx,
+				result.Email,
+				result.GetFullName(),
+			); err != nil {
+				// Log error but don't fail the registration
+				log.Printf("Failed to send welcome email to %s: %v", result.Email, err)
+			}
+		}
+		if !result.IsEmailVerified() {
+			if err := s.sendVerificationEmail(backgroundCtx, result); err != nil {
+				log.Printf("Failed to send verification email to %s: %v", result.Email, err)
+			}
 		}
 	}()
 
@@ -70,10 +112,14 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 			"registered_at": result.CreatedAt,
 		},
 	}
+	// Handlers sending the user emails must only capture them in dry-run mode
+	if notification.IsDryRun(ctx) {
+		event.Metadata.Headers = map[string]string{string(notification.DryRunContextKey): "true"}
+	}
 
-	if err := s.deps.EventPublisher.Publish(ctx, event); err != nil {
+	if err := s.publish(ctx, event); err != nil {
 		// Log event publishing failure but don't fail the operation
-		log.Printf("Failed to publish UserRegistered event: %v", err)
+		correlation.Logf(ctx, "Failed to publish UserRegistered event: %v", err)
 	}
 
 	return result, nil
@@ -87,8 +133,19 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 		return nil, err
 	}
 
-	// Business logic: Generate tokens
-	token, expiresAt, err := s.deps.TokenService.GenerateAuthToken(
+	// Business logic: Unverified users are refused once the grace period is
+	// over, and sent a fresh verification email as the first may have expired
+	if s.verificationOverdue(result.User) {
+		if err := s.sendVerificationEmail(ctx, result.User); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", result.User.ID, err)
+		}
+		return nil, user.ErrEmailNotVerified
+	}
+
+	// Business logic: Generate tokens recording the password sign-in, so
+	// sensitive operations can require a recent one
+	ctx = token.WithAuthentication(ctx, time.Now(), token.AMRPassword)
+	accessToken, expiresAt, err := s.deps.TokenService.GenerateAuthToken(
 		ctx,
 		result.User.ID.String(),
 		result.User.Email,
@@ -106,7 +163,7 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 	}
 
 	// Update auth result with tokens
-	result.Token = token
+	result.Token = accessToken
 	result.RefreshToken = refreshToken
 	result.ExpiresAt = expiresAt
 
@@ -122,8 +179,8 @@ func (s *service) Login(ctx context.Context, email, password string) (*user.Auth
 		},
 	}
 
-	if err := s.deps.EventPublisher.Publish(ctx, loginEvent); err != nil {
-		log.Printf("Failed to publish UserLoggedIn event: %v", err)
+	if err := s.publish(ctx, loginEvent); err != nil {
+		correlation.Logf(ctx, "Failed to publish UserLoggedIn event: %v", err)
 	}
 
 	return result, nil
@@ -134,6 +191,16 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	return s.next.GetByID(ctx, id)
 }
 
+// GetByIDs passes through to storage
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List passes through to storage
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	return s.next.List(ctx, filters)
+}
+
 // UpdateProfile updates user profile with business logic
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	// Get current user data for comparison
@@ -152,9 +219,10 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 	changes := s.detectProfileChanges(currentUser, result, data)
 
 	if len(changes) > 0 {
-		// Send notification about profile changes (non-blocking)
+		// Send notification about profile changes (fire-and-forget)
+		backgroundCtx, cancel := user.DetachContext(ctx)
 		go func() {
-			backgroundCtx := context.Background()
+			defer cancel()
 			if err := s.deps.NotificationService.SendProfileUpdateNotification(
 				backgroundCtx,
 				result.ID.String(),
@@ -176,14 +244,299 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 			},
 		}
 
-		if err := s.deps.EventPublisher.Publish(ctx, updateEvent); err != nil {
-			log.Printf("Failed to publish ProfileUpdated event: %v", err)
+		if err := s.publish(ctx, updateEvent); err != nil {
+			correlation.Logf(ctx, "Failed to publish ProfileUpdated event: %v", err)
 		}
 	}
 
 	return result, nil
 }
 
+// ChangePassword changes a user's password and publishes a password changed event
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword); err != nil {
+		return err
+	}
+
+	// Publish password changed event using events domain service
+	event := events.Event{
+		Type:          events.EventTypePasswordChanged,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":    userID,
+			"changed_at": time.Now(),
+		},
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish PasswordChanged event: %v", err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset starts a password reset for the user with the email and
+// sends them a token, redeemed with ResetPassword. Public callers should not
+// reveal whether a user was found.
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	result, err := s.next.RequestPasswordReset(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := result.ID.String()
+	resetToken, err := s.deps.TokenService.GeneratePasswordResetToken(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	// Send the reset email (fire-and-forget), so answering takes about as long
+	// for unknown emails
+	backgroundCtx, cancel := user.DetachContext(ctx)
+	backgroundCtx = notification.WithRecipient(backgroundCtx, userID)
+	go func() {
+		defer cancel()
+		if err := s.deps.NotificationService.SendPasswordResetEmail(backgroundCtx, result.Email, resetToken); err != nil {
+			log.Printf("Failed to send password reset email to user %s: %v", userID, err)
+		}
+	}()
+
+	return result, nil
+}
+
+// ResetPassword checks the token sent by RequestPasswordReset, replaces the
+// password, signs the user out everywhere and publishes a password changed
+// event. Each token resets the password at most once, and only tokens from
+// the latest request are accepted.
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	claims, err := s.deps.TokenService.ValidatePasswordResetToken(ctx, token)
+	if err != nil {
+		return user.ErrInvalidResetToken
+	}
+
+	// Read past the cache so the latest request is checked
+	currentUser, err := s.next.GetByID(user.WithCacheBypass(ctx), claims.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return user.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// Tokens carry whole seconds; one issued before the latest request was
+	// superseded by it, and none is valid once a reset used the request
+	requestedAt := currentUser.PasswordResetRequestedAt
+	if requestedAt == nil || claims.IssuedAt.Before(requestedAt.Truncate(time.Second)) {
+		return user.ErrInvalidResetToken
+	}
+
+	if err := s.next.ResetPassword(user.WithPasswordReset(ctx, claims.UserID), token, newPassword); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password is signed out, and the reset token with them
+	if err := s.deps.TokenService.RevokeAllTokensForUser(ctx, claims.UserID); err != nil {
+		log.Printf("Failed to revoke tokens after password reset for user %s: %v", claims.UserID, err)
+	}
+
+	// Publish password changed event using events domain service
+	event := events.Event{
+		Type:          events.EventTypePasswordChanged,
+		AggregateID:   claims.UserID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":    claims.UserID,
+			"changed_at": time.Now(),
+			"reset":      true,
+		},
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish PasswordChanged event: %v", err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange records the new email as pending and sends a confirmation
+// token to it; the email is only changed once the token is confirmed
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	if err := s.next.RequestEmailChange(ctx, userID, newEmail); err != nil {
+		return err
+	}
+
+	// Business logic: Prove the user controls the new address
+	verificationToken, err := s.deps.TokenService.GenerateEmailVerificationToken(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to generate email confirmation token: %w", err)
+	}
+
+	if err := s.deps.NotificationService.SendVerificationEmail(notification.WithRecipient(ctx, userID), newEmail, verificationToken); err != nil {
+		return fmt.Errorf("failed to send email confirmation: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange verifies the token sent by RequestEmailChange, applies the
+// pending email and publishes a profile updated event
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	claims, err := s.deps.TokenService.ValidateEmailVerificationToken(ctx, token)
+	if err != nil || claims.UserID != userID {
+		return nil, user.ErrInvalidEmailToken
+	}
+
+	// Read past the cache so the latest request is checked
+	currentUser, err := s.next.GetByID(user.WithCacheBypass(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.PendingEmail == "" {
+		return nil, user.ErrNoPendingEmail
+	}
+
+	// Tokens carry whole seconds; one issued before the latest request was
+	// sent for an earlier address
+	if requestedAt := currentUser.EmailChangeRequestedAt; requestedAt != nil && claims.IssuedAt.Before(requestedAt.Truncate(time.Second)) {
+		return nil, user.ErrInvalidEmailToken
+	}
+
+	result, err := s.next.ConfirmEmailChange(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is single use
+	if err := s.deps.TokenService.RevokeToken(ctx, token); err != nil {
+		log.Printf("Failed to revoke email confirmation token for user %s: %v", userID, err)
+	}
+
+	changes := map[string]interface{}{
+		"email": map[string]string{
+			"old": currentUser.Email,
+			"new": result.Email,
+		},
+	}
+
+	// Send notification about the email change (fire-and-forget)
+	backgroundCtx, cancel := user.DetachContext(ctx)
+	go func() {
+		defer cancel()
+		if err := s.deps.NotificationService.SendProfileUpdateNotification(backgroundCtx, userID, changes); err != nil {
+			log.Printf("Failed to send profile update notification: %v", err)
+		}
+	}()
+
+	// Publish profile updated event using events domain service
+	updateEvent := events.Event{
+		Type:          events.EventTypeUserUpdated,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":    userID,
+			"updated_at": result.UpdatedAt,
+			"changes":    changes,
+		},
+	}
+
+	if err := s.publish(ctx, updateEvent); err != nil {
+		correlation.Logf(ctx, "Failed to publish ProfileUpdated event: %v", err)
+	}
+
+	return result, nil
+}
+
+// VerifyEmail checks the token sent on registration, marks the email it was
+// issued for as verified and publishes a user updated event. Tokens for an
+// address the user no longer has are rejected.
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	claims, err := s.deps.TokenService.ValidateEmailVerificationToken(ctx, token)
+	if err != nil {
+		return nil, user.ErrInvalidVerifyToken
+	}
+	email, _ := claims.Custom[verifyEmailClaim].(string)
+	if email == "" {
+		return nil, user.ErrInvalidVerifyToken
+	}
+
+	// Read past the cache so a just-changed email is seen
+	currentUser, err := s.next.GetByID(user.WithCacheBypass(ctx), claims.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, user.ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	if currentUser.Email != email {
+		return nil, user.ErrInvalidVerifyToken
+	}
+
+	result, err := s.next.VerifyEmail(user.WithEmailVerification(ctx, claims.UserID), token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is single use
+	if err := s.deps.TokenService.RevokeToken(ctx, token); err != nil {
+		log.Printf("Failed to revoke email verification token for user %s: %v", claims.UserID, err)
+	}
+
+	if currentUser.IsEmailVerified() {
+		return result, nil
+	}
+
+	// Publish email verified event using events domain service
+	verifiedEvent := events.Event{
+		Type:          events.EventTypeEmailVerified,
+		AggregateID:   claims.UserID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":     claims.UserID,
+			"email":       result.Email,
+			"verified_at": result.EmailVerifiedAt,
+		},
+	}
+
+	if err := s.publish(ctx, verifiedEvent); err != nil {
+		correlation.Logf(ctx, "Failed to publish EmailVerified event: %v", err)
+	}
+
+	return result, nil
+}
+
+// UploadAvatar stores a new avatar and publishes a profile updated event
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	if err := s.next.UploadAvatar(ctx, userID, content, contentType); err != nil {
+		return err
+	}
+
+	// Publish profile updated event using events domain service
+	updateEvent := events.Event{
+		Type:          events.EventTypeUserUpdated,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":    userID,
+			"updated_at": time.Now(),
+			"changes": map[string]interface{}{
+				"avatar": map[string]string{"content_type": contentType},
+			},
+		},
+	}
+
+	if err := s.publish(ctx, updateEvent); err != nil {
+		correlation.Logf(ctx, "Failed to publish ProfileUpdated event: %v", err)
+	}
+
+	return nil
+}
+
+// GetAvatarURL returns a link to the user's avatar
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences retrieves user preferences with business logic for defaults
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	// Try to get preferences from next service
@@ -211,42 +564,238 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 		return err
 	}
 
-	// Business logic: Determine what changed
-	if currentPrefs != nil {
-		changes := s.detectPreferencesChanges(currentPrefs, &prefs)
-
-		if len(changes) > 0 {
-			// Publish preferences updated event using events domain service
-			prefsEvent := events.Event{
-				Type:          events.EventTypeUserPrefsUpdated,
-				AggregateID:   userID,
-				AggregateType: "user",
-				Data: map[string]interface{}{
-					"user_id":    userID,
-					"updated_at": time.Now(),
-					"preferences": map[string]interface{}{
-						"theme":               prefs.Theme,
-						"language":            prefs.Language,
-						"timezone":            prefs.Timezone,
-						"email_notifications": prefs.EmailNotifications,
-						"push_notifications":  prefs.PushNotifications,
-						"sms_notifications":   prefs.SMSNotifications,
-						"notification_types":  prefs.NotificationTypes,
-					},
-				},
-			}
+	// Business logic: Announce what changed
+	s.publishPreferencesUpdated(ctx, userID, currentPrefs, prefs)
 
-			if err := s.deps.EventPublisher.Publish(ctx, prefsEvent); err != nil {
-				log.Printf("Failed to publish PreferencesUpdated event: %v", err)
-			}
+	return nil
+}
+
+// UpdatePreferencesBulk updates several users' preferences and publishes a
+// preferences updated event for each user whose preferences changed
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	// Get current preferences for comparison
+	currentPrefs := make(map[string]*user.UserPreferences, len(updates))
+	for userID := range updates {
+		currentPrefs[userID], _ = s.next.GetPreferences(ctx, userID)
+	}
+
+	if err := s.next.UpdatePreferencesBulk(ctx, updates); err != nil {
+		return err
+	}
+
+	for userID, prefs := range updates {
+		s.publishPreferencesUpdated(ctx, userID, currentPrefs[userID], prefs)
+	}
+
+	return nil
+}
+
+// Deactivate deactivates a user, signs them out everywhere and publishes a
+// deactivation event
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	if err := s.next.Deactivate(ctx, id); err != nil {
+		return err
+	}
+
+	// Tokens issued before the deactivation must not outlive it
+	if err := s.deps.TokenService.RevokeAllTokensForUser(ctx, id); err != nil {
+		log.Printf("Failed to revoke tokens of deactivated user %s: %v", id, err)
+	}
+
+	// Publish user deactivated event using events domain service
+	event := events.Event{
+		Type:          events.EventTypeUserDeactivated,
+		AggregateID:   id,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":        id,
+			"deactivated_at": time.Now(),
+		},
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish UserDeactivated event: %v", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a user, signs them out everywhere and publishes a
+// deletion event
+func (s *service) Delete(ctx context.Context, id string) error {
+	if err := s.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.deps.TokenService.RevokeAllTokensForUser(ctx, id); err != nil {
+		log.Printf("Failed to revoke tokens of deleted user %s: %v", id, err)
+	}
+
+	// Publish user deleted event using events domain service
+	event := events.Event{
+		Type:          events.EventTypeUserDeleted,
+		AggregateID:   id,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":    id,
+			"deleted_at": time.Now(),
+		},
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish UserDeleted event: %v", err)
+	}
+
+	return nil
+}
+
+// ExportUserData adds the user's notification history to the export
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	result, err := s.next.ExportUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []notification.NotificationHistory
+	if s.deps.NotificationHistory != nil {
+		history, err = s.deps.NotificationHistory.List(ctx, notificationhistory.Filter{UserID: userID})
+	} else {
+		history, err = s.deps.NotificationService.GetNotificationHistory(ctx, userID, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect notification history: %w", err)
+	}
+	result.Notifications = history
+
+	return result, nil
+}
+
+// EraseUser erases a user's personal data and notification history and
+// publishes an erasure event so other services holding copies of the data
+// can erase theirs
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	if err := s.next.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if s.deps.NotificationHistory != nil {
+		// The user is already erased, so finish even if the caller went away
+		detached, cancel := user.DetachContext(ctx)
+		_, err := s.deps.NotificationHistory.DeleteUser(detached, userID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to purge notification history: %w", err)
 		}
 	}
 
+	// Publish user erased event using events domain service
+	event := events.Event{
+		Type:          events.EventTypeUserErased,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":   userID,
+			"erased_at": time.Now(),
+		},
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		correlation.Logf(ctx, "Failed to publish UserErased event: %v", err)
+	}
+
 	return nil
 }
 
+// CleanupPreferences passes through to storage
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags passes through to storage
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag passes through to storage
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
 // Helper methods for business logic
 
+// publish sends an event on a detached context so a client disconnect after
+// the change was committed cannot drop the event
+func (s *service) publish(ctx context.Context, event events.Event) error {
+	ctx, cancel := user.DetachContext(ctx)
+	defer cancel()
+
+	return s.deps.EventPublisher.Publish(ctx, event)
+}
+
+// sendVerificationEmail sends the user a token proving they control their
+// current email, redeemed with VerifyEmail
+func (s *service) sendVerificationEmail(ctx context.Context, u *user.User) error {
+	tokenCtx := token.WithExtraClaims(ctx, map[string]interface{}{verifyEmailClaim: u.Email})
+	verificationToken, err := s.deps.TokenService.GenerateEmailVerificationToken(tokenCtx, u.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to generate email verification token: %w", err)
+	}
+
+	return s.deps.NotificationService.SendVerificationEmail(notification.WithRecipient(ctx, u.ID.String()), u.Email, verificationToken)
+}
+
+// verificationOverdue reports whether the user has not verified their email
+// within the grace period after registering
+func (s *service) verificationOverdue(u *user.User) bool {
+	grace := s.config.EmailVerificationGracePeriod
+	return grace > 0 && !u.IsEmailVerified() && time.Since(u.CreatedAt) > grace
+}
+
+// publishPreferencesUpdated announces the changed preferences of a user.
+// Nothing is published when the previous preferences are unknown.
+func (s *service) publishPreferencesUpdated(ctx context.Context, userID string, currentPrefs *user.UserPreferences, prefs user.UserPreferences) {
+	if currentPrefs == nil {
+		return
+	}
+
+	changes := s.detectPreferencesChanges(currentPrefs, &prefs)
+	if len(changes) == 0 {
+		return
+	}
+
+	// Publish preferences updated event using events domain service.
+	// Other open sessions of the user refresh their settings from it;
+	// the originating session is excluded by the realtime channel.
+	prefsEvent := events.Event{
+		Type:          events.EventTypeUserPrefsUpdated,
+		AggregateID:   userID,
+		AggregateType: "user",
+		Data: map[string]interface{}{
+			"user_id":           userID,
+			"updated_at":        time.Now(),
+			"origin_session_id": user.SessionIDFromContext(ctx),
+			"changes":           changes,
+			"preferences": map[string]interface{}{
+				"theme":               prefs.Theme,
+				"language":            prefs.Language,
+				"timezone":            prefs.Timezone,
+				"email_notifications": prefs.EmailNotifications,
+				"push_notifications":  prefs.PushNotifications,
+				"sms_notifications":   prefs.SMSNotifications,
+				"notification_types":  prefs.NotificationTypes,
+			},
+		},
+		Metadata: events.EventMetadata{
+			UserID: userID,
+			Source: "user.usecase",
+		},
+	}
+
+	if err := s.publish(ctx, prefsEvent); err != nil {
+		correlation.Logf(ctx, "Failed to publish PreferencesUpdated event: %v", err)
+	}
+}
+
 func (s *service) detectProfileChanges(current, updated *user.User, data user.UpdateProfileData) map[string]interface{} {
 	changes := make(map[string]interface{})
 
@@ -278,32 +827,53 @@ func (s *service) detectPreferencesChanges(current, updated *user.UserPreference
 	changes := make(map[string]interface{})
 
 	if current.EmailNotifications != updated.EmailNotifications {
-		changes["email_notifications"] = updated.EmailNotifications
+		changes["email_notifications"] = map[string]interface{}{
+			"old": current.EmailNotifications,
+			"new": updated.EmailNotifications,
+		}
 	}
 
 	if current.PushNotifications != updated.PushNotifications {
-		changes["push_notifications"] = updated.PushNotifications
+		changes["push_notifications"] = map[string]interface{}{
+			"old": current.PushNotifications,
+			"new": updated.PushNotifications,
+		}
 	}
 
 	if current.SMSNotifications != updated.SMSNotifications {
-		changes["sms_notifications"] = updated.SMSNotifications
+		changes["sms_notifications"] = map[string]interface{}{
+			"old": current.SMSNotifications,
+			"new": updated.SMSNotifications,
+		}
 	}
 
 	if current.Theme != updated.Theme {
-		changes["theme"] = updated.Theme
+		changes["theme"] = map[string]interface{}{
+			"old": current.Theme,
+			"new": updated.Theme,
+		}
 	}
 
 	if current.Language != updated.Language {
-		changes["language"] = updated.Language
+		changes["language"] = map[string]interface{}{
+			"old": current.Language,
+			"new": updated.Language,
+		}
 	}
 
 	if current.Timezone != updated.Timezone {
-		changes["timezone"] = updated.Timezone
+		changes["timezone"] = map[string]interface{}{
+			"old": current.Timezone,
+			"new": updated.Timezone,
+		}
 	}
 
 	// Compare notification types
 	if !equalNotificationTypeMaps(current.NotificationTypes, updated.NotificationTypes) {
-		changes["notification_types"] = updated.NotificationTypes
+		changes["notification_types"] = map[string]interface{}{
+			"old": current.NotificationTypes,
+			"new": updated.NotificationTypes,
+		}
 	}
 
 	return changes
@@ -329,26 +899,8 @@ func (s *service) createDefaultPreferencesForUser(ctx context.Context, userID st
 }
 
 func (s *service) ensureCompletePreferences(prefs *user.UserPreferences) *user.UserPreferences {
-	// Ensure notification types map is not nil and has all required types
-	if prefs.NotificationTypes == nil {
-		prefs.NotificationTypes = make(map[string]bool)
-	}
-
-	// Add missing notification types with default values
-	defaultTypes := map[string]bool{
-		"task_assigned":   true,
-		"task_due_soon":   true,
-		"project_updated": true,
-		"project_invite":  true,
-		"system_updates":  false,
-		"marketing":       false,
-	}
-
-	for notificationType, defaultValue := range defaultTypes {
-		if _, exists := prefs.NotificationTypes[notificationType]; !exists {
-			prefs.NotificationTypes[notificationType] = defaultValue
-		}
-	}
+	// Add notification types declared after the preferences were stored
+	user.DefaultPreferenceSchema().Complete(prefs)
 
 	// Ensure required fields have default values
 	if prefs.Theme == "" {
@@ -366,7 +918,7 @@ func (s *service) ensureCompletePreferences(prefs *user.UserPreferences) *user.U
 
 // Utility functions
 
-func equalNotificationTypeMaps(a, b map[string]bool) bool {
+func equalNotificationTypeMaps(a, b map[user.NotificationType]bool) bool {
 	if len(a) != len(b) {
 		return false
 	}
@@ -0,0 +1,356 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	eventsMemory "github.com/gentra/decorator-arch-go/internal/events/memory"
+	"github.com/gentra/decorator-arch-go/internal/notification"
+	notificationMock "github.com/gentra/decorator-arch-go/internal/notification/mock"
+	"github.com/gentra/decorator-arch-go/internal/notificationhistory"
+	historyMemory "github.com/gentra/decorator-arch-go/internal/notificationhistory/memory"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/token"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	"github.com/gentra/decorator-arch-go/internal/user/sqlite"
+	"github.com/gentra/decorator-arch-go/internal/user/usecase"
+)
+
+// verificationInbox records the verification and password reset emails sent
+// by the service
+type verificationInbox struct {
+	notification.Service
+	tokens chan string
+	resets chan string
+}
+
+func (i *verificationInbox) SendVerificationEmail(ctx context.Context, userEmail, verificationToken string) error {
+	i.tokens <- verificationToken
+	return nil
+}
+
+func (i *verificationInbox) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	i.resets <- resetToken
+	return nil
+}
+
+// next waits for the next verification email, which registration sends in the background
+func (i *verificationInbox) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case verificationToken := <-i.tokens:
+		return verificationToken
+	case <-time.After(5 * time.Second):
+		t.Fatal("no verification email was sent")
+		return ""
+	}
+}
+
+// nextReset waits for the next password reset email, which is sent in the background
+func (i *verificationInbox) nextReset(t *testing.T) string {
+	t.Helper()
+	select {
+	case resetToken := <-i.resets:
+		return resetToken
+	case <-time.After(5 * time.Second):
+		t.Fatal("no password reset email was sent")
+		return ""
+	}
+}
+
+func newTestService(t *testing.T, config usecase.Config) (user.Service, *verificationInbox, token.Service) {
+	t.Helper()
+	db, err := sqlite.Open(sqlite.MemoryPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	inbox := &verificationInbox{Service: notificationMock.NewService(), tokens: make(chan string, 4), resets: make(chan string, 4)}
+	tokenService := testkit.NewTokenService(t)
+	service := usecase.NewServiceWithConfig(sqlite.NewService(db), usecase.Dependencies{
+		NotificationService: inbox,
+		TokenService:        tokenService,
+		EventPublisher:      eventsMemory.NewService(events.DefaultEventConfig()),
+	}, config)
+	return service, inbox, tokenService
+}
+
+func TestVerifyEmail_GivenRegistrationToken_WhenVerifying_ThenMarksUserVerifiedOnce(t *testing.T) {
+	// Arrange
+	service, inbox, _ := newTestService(t, usecase.Config{})
+	ctx := context.Background()
+	registered, err := service.Register(ctx, testkit.NewUserBuilder().BuildRegisterData())
+	require.NoError(t, err)
+	require.False(t, registered.IsEmailVerified())
+	verificationToken := inbox.next(t)
+
+	// Act
+	verified, err := service.VerifyEmail(ctx, verificationToken)
+	_, replayErr := service.VerifyEmail(ctx, verificationToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, registered.ID, verified.ID)
+	assert.True(t, verified.IsEmailVerified())
+	assert.ErrorIs(t, replayErr, user.ErrInvalidVerifyToken)
+}
+
+func TestVerifyEmail_GivenTokenNotFromRegistration_WhenVerifying_ThenReturnsInvalidToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(t *testing.T, service user.Service, tokens token.Service, inbox *verificationInbox, registered *user.User) string
+	}{
+		{
+			name: "Given an email change token, When verifying, Then returns invalid token",
+			token: func(t *testing.T, _ user.Service, tokens token.Service, _ *verificationInbox, registered *user.User) string {
+				changeToken, err := tokens.GenerateEmailVerificationToken(context.Background(), registered.ID.String())
+				require.NoError(t, err)
+				return changeToken
+			},
+		},
+		{
+			name: "Given a token for an address the user has since changed, When verifying, Then returns invalid token",
+			token: func(t *testing.T, service user.Service, _ token.Service, inbox *verificationInbox, registered *user.User) string {
+				oldToken := inbox.next(t)
+				newEmail := "jane.new@example.com"
+				_, err := service.UpdateProfile(context.Background(), registered.ID.String(), user.UpdateProfileData{Email: &newEmail})
+				require.NoError(t, err)
+				return oldToken
+			},
+		},
+		{
+			name: "Given a malformed token, When verifying, Then returns invalid token",
+			token: func(*testing.T, user.Service, token.Service, *verificationInbox, *user.User) string {
+				return "not-a-token"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, inbox, tokens := newTestService(t, usecase.Config{})
+			registered, err := service.Register(context.Background(), testkit.NewUserBuilder().BuildRegisterData())
+			require.NoError(t, err)
+			verificationToken := tt.token(t, service, tokens, inbox, registered)
+
+			// Act
+			_, err = service.VerifyEmail(context.Background(), verificationToken)
+
+			// Assert
+			assert.ErrorIs(t, err, user.ErrInvalidVerifyToken)
+		})
+	}
+}
+
+func TestLogin_GivenUnverifiedUser_WhenGracePeriodIsOver_ThenRefusesAndResendsVerification(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		verify      bool
+		expectedErr error
+	}{
+		{name: "Given no grace period, When logging in unverified, Then signs in", gracePeriod: 0},
+		{name: "Given a grace period not yet over, When logging in unverified, Then signs in", gracePeriod: time.Hour},
+		{name: "Given a grace period that is over, When logging in unverified, Then returns email not verified", gracePeriod: time.Nanosecond, expectedErr: user.ErrEmailNotVerified},
+		{name: "Given a grace period that is over, When logging in verified, Then signs in", gracePeriod: time.Nanosecond, verify: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, inbox, _ := newTestService(t, usecase.Config{EmailVerificationGracePeriod: tt.gracePeriod})
+			ctx := context.Background()
+			data := testkit.NewUserBuilder().BuildRegisterData()
+			_, err := service.Register(ctx, data)
+			require.NoError(t, err)
+			verificationToken := inbox.next(t)
+			if tt.verify {
+				_, err := service.VerifyEmail(ctx, verificationToken)
+				require.NoError(t, err)
+			}
+
+			// Act
+			result, err := service.Login(ctx, data.Email, data.Password)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				resent := inbox.next(t)
+				_, err := service.VerifyEmail(ctx, resent)
+				assert.NoError(t, err, "the resent token should verify the email")
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, result.Token)
+		})
+	}
+}
+
+func TestResetPassword_GivenEmailedToken_WhenResetting_ThenReplacesPasswordOnce(t *testing.T) {
+	// Arrange
+	service, inbox, _ := newTestService(t, usecase.Config{})
+	ctx := context.Background()
+	data := testkit.NewUserBuilder().BuildRegisterData()
+	_, err := service.Register(ctx, data)
+	require.NoError(t, err)
+	_, err = service.RequestPasswordReset(ctx, data.Email)
+	require.NoError(t, err)
+	resetToken := inbox.nextReset(t)
+
+	// Act
+	err = service.ResetPassword(ctx, resetToken, "NewPassword456!")
+	replayErr := service.ResetPassword(ctx, resetToken, "OtherPassword789!")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, replayErr, user.ErrInvalidResetToken)
+	_, err = service.Login(ctx, data.Email, "NewPassword456!")
+	assert.NoError(t, err)
+	_, err = service.Login(ctx, data.Email, data.Password)
+	assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+}
+
+func TestResetPassword_GivenTokenNotFromAResetRequest_WhenResetting_ThenReturnsInvalidToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(t *testing.T, service user.Service, tokens token.Service, inbox *verificationInbox, registered *user.User) string
+	}{
+		{
+			name: "Given an email verification token, When resetting, Then returns invalid token",
+			token: func(t *testing.T, _ user.Service, _ token.Service, inbox *verificationInbox, _ *user.User) string {
+				return inbox.next(t)
+			},
+		},
+		{
+			name: "Given a reset token without a request, When resetting, Then returns invalid token",
+			token: func(t *testing.T, _ user.Service, tokens token.Service, _ *verificationInbox, registered *user.User) string {
+				resetToken, err := tokens.GeneratePasswordResetToken(context.Background(), registered.ID.String())
+				require.NoError(t, err)
+				return resetToken
+			},
+		},
+		{
+			name: "Given a token sent to an address the user has since changed, When resetting, Then returns invalid token",
+			token: func(t *testing.T, service user.Service, _ token.Service, inbox *verificationInbox, registered *user.User) string {
+				_, err := service.RequestPasswordReset(context.Background(), registered.Email)
+				require.NoError(t, err)
+				resetToken := inbox.nextReset(t)
+				newEmail := "jane.new@example.com"
+				_, err = service.UpdateProfile(context.Background(), registered.ID.String(), user.UpdateProfileData{Email: &newEmail})
+				require.NoError(t, err)
+				return resetToken
+			},
+		},
+		{
+			name: "Given a malformed token, When resetting, Then returns invalid token",
+			token: func(*testing.T, user.Service, token.Service, *verificationInbox, *user.User) string {
+				return "not-a-token"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, inbox, tokens := newTestService(t, usecase.Config{})
+			registered, err := service.Register(context.Background(), testkit.NewUserBuilder().BuildRegisterData())
+			require.NoError(t, err)
+			resetToken := tt.token(t, service, tokens, inbox, registered)
+
+			// Act
+			err = service.ResetPassword(context.Background(), resetToken, "NewPassword456!")
+
+			// Assert
+			assert.ErrorIs(t, err, user.ErrInvalidResetToken)
+		})
+	}
+}
+
+func TestNotificationHistory_GivenHistoryStore_WhenExportingAndErasing_ThenExportsAndPurgesTheUsersEntries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	next := &userMock.MockUserService{}
+	next.On("ExportUserData", mock.Anything, "user-1").Return(&user.DataExport{}, nil)
+	next.On("EraseUser", mock.Anything, "user-1").Return(nil)
+	history := historyMemory.NewService()
+	for _, entry := range []notification.NotificationHistory{
+		{UserID: "user-1", Type: notification.NotificationTypeEmail, Body: "first"},
+		{UserID: "user-2", Type: notification.NotificationTypePush, Body: "other user"},
+		{UserID: "user-1", Type: notification.NotificationTypeInApp, Body: "second"},
+	} {
+		_, err := history.Record(ctx, entry)
+		require.NoError(t, err)
+	}
+	service := usecase.NewService(next, usecase.Dependencies{
+		NotificationService: notificationMock.NewService(),
+		EventPublisher:      eventsMemory.NewService(events.DefaultEventConfig()),
+		NotificationHistory: history,
+	})
+
+	// Act
+	export, exportErr := service.ExportUserData(ctx, "user-1")
+	eraseErr := service.EraseUser(ctx, "user-1")
+
+	// Assert
+	require.NoError(t, exportErr)
+	require.Len(t, export.Notifications, 2)
+	assert.Equal(t, "second", export.Notifications[0].Body)
+	assert.Equal(t, "first", export.Notifications[1].Body)
+
+	require.NoError(t, eraseErr)
+	remaining, err := history.List(ctx, notificationhistory.Filter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "user-2", remaining[0].UserID)
+}
+
+func TestDeactivateAndDelete_GivenIssuedTokens_WhenRemovingAccount_ThenRevokesThem(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(ctx context.Context, service user.Service, id string) error
+	}{
+		{
+			name: "Given issued tokens, When deactivating, Then revokes them",
+			remove: func(ctx context.Context, service user.Service, id string) error {
+				return service.Deactivate(ctx, id)
+			},
+		},
+		{
+			name: "Given issued tokens, When deleting, Then revokes them",
+			remove: func(ctx context.Context, service user.Service, id string) error {
+				return service.Delete(ctx, id)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _, tokens := newTestService(t, usecase.Config{})
+			ctx := context.Background()
+			registered, err := service.Register(ctx, testkit.NewUserBuilder().BuildRegisterData())
+			require.NoError(t, err)
+			accessToken, _, err := tokens.GenerateAuthToken(ctx, registered.ID.String(), registered.Email)
+			require.NoError(t, err)
+
+			// Act
+			err = tt.remove(ctx, service, registered.ID.String())
+
+			// Assert
+			require.NoError(t, err)
+			_, err = tokens.ValidateToken(ctx, accessToken)
+			assert.ErrorIs(t, err, token.ErrTokenRevoked)
+		})
+	}
+}
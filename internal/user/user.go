@@ -1,10 +1,20 @@
 package user
 
 import (
+	"archive/zip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/audit"
+	"github.com/gentra/decorator-arch-go/internal/notification"
 )
 
 // Service defines the user domain interface
@@ -12,28 +22,78 @@ type Service interface {
 	Register(ctx context.Context, data RegisterData) (*User, error)
 	Login(ctx context.Context, email, password string) (*AuthResult, error)
 	GetByID(ctx context.Context, id string) (*User, error)
+	GetByIDs(ctx context.Context, ids []string) (map[string]*User, error)
+	List(ctx context.Context, filters ListFilters) (*Page, error)
 	UpdateProfile(ctx context.Context, id string, data UpdateProfileData) (*User, error)
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) (*User, error)
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	RequestEmailChange(ctx context.Context, userID, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, userID, token string) (*User, error)
+	VerifyEmail(ctx context.Context, token string) (*User, error)
+	UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error
+	GetAvatarURL(ctx context.Context, userID string) (string, error)
 	GetPreferences(ctx context.Context, userID string) (*UserPreferences, error)
 	UpdatePreferences(ctx context.Context, userID string, prefs UserPreferences) error
+	UpdatePreferencesBulk(ctx context.Context, updates map[string]UserPreferences) error
+	Deactivate(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	ExportUserData(ctx context.Context, userID string) (*DataExport, error)
+	EraseUser(ctx context.Context, userID string) error
+	CleanupPreferences(ctx context.Context, opts PreferenceCleanupOptions) (*PreferenceCleanupReport, error)
+	GetFeatureFlags(ctx context.Context, userID string) (*FeatureFlags, error)
+	SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error
 }
 
 // User represents a user in the system
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	Email         string     `json:"email"`
+	PasswordHash  string     `json:"-"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+
+	// PendingEmail replaces Email once the user confirms it with the token
+	// sent by RequestEmailChange; empty when no change is in progress
+	PendingEmail           string     `json:"pending_email,omitempty"`
+	EmailChangeRequestedAt *time.Time `json:"email_change_requested_at,omitempty"`
+
+	// EmailVerifiedAt is when the user proved they control Email, with the
+	// token sent on registration or by confirming an email change; nil until then
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// PasswordResetRequestedAt is when the latest password reset was
+	// requested; only tokens issued since then reset the password, and it is
+	// cleared once one does
+	PasswordResetRequestedAt *time.Time `json:"password_reset_requested_at,omitempty"`
+
+	// AvatarKey locates the user's avatar in blob storage; links to it are
+	// issued by GetAvatarURL
+	AvatarKey string `json:"avatar_key,omitempty"`
+
+	// Version is incremented by every update of the user. UpdateProfile
+	// rejects changes based on an older version with ErrConflict.
+	Version int `json:"version"`
 }
 
 // RegisterData contains data for user registration
 type RegisterData struct {
+	// ID preassigns the new user's ID, e.g. the guest ID an account is
+	// upgraded from; the storage layer generates one when it is zero
+	ID uuid.UUID `json:"-"`
+
 	Email     string `json:"email" validate:"required,email"`
 	Password  string `json:"password" validate:"required,min=8"`
 	FirstName string `json:"first_name" validate:"required,min=2"`
 	LastName  string `json:"last_name" validate:"required,min=2"`
+
+	// EmailVerified registers the user with an address verified elsewhere,
+	// e.g. by an identity provider, so no verification email is sent
+	EmailVerified bool `json:"-"`
 }
 
 // UpdateProfileData contains data for profile updates
@@ -41,6 +101,10 @@ type UpdateProfileData struct {
 	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2"`
 	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=2"`
 	Email     *string `json:"email,omitempty" validate:"omitempty,email"`
+
+	// Version is the version of the user the update is based on; the update
+	// fails with ErrConflict if the user has changed since. Zero skips the check.
+	Version int `json:"version,omitempty"`
 }
 
 // AuthResult contains authentication result data
@@ -53,19 +117,170 @@ type AuthResult struct {
 
 // UserPreferences contains user notification and system preferences
 type UserPreferences struct {
-	ID                 uuid.UUID       `json:"id"`
-	UserID             uuid.UUID       `json:"user_id"`
-	EmailNotifications bool            `json:"email_notifications"`
-	PushNotifications  bool            `json:"push_notifications"`
-	SMSNotifications   bool            `json:"sms_notifications"`
-	Theme              string          `json:"theme"` // light, dark, auto
-	Language           string          `json:"language"`
-	Timezone           string          `json:"timezone"`
-	NotificationTypes  map[string]bool `json:"notification_types"` // task_assigned, project_updated, etc.
-	CreatedAt          time.Time       `json:"created_at"`
-	UpdatedAt          time.Time       `json:"updated_at"`
+	ID                 uuid.UUID                 `json:"id"`
+	UserID             uuid.UUID                 `json:"user_id"`
+	EmailNotifications bool                      `json:"email_notifications"`
+	PushNotifications  bool                      `json:"push_notifications"`
+	SMSNotifications   bool                      `json:"sms_notifications"`
+	Theme              string                    `json:"theme"` // light, dark, auto
+	Language           string                    `json:"language"`
+	Timezone           string                    `json:"timezone"`
+	NotificationTypes  map[NotificationType]bool `json:"notification_types"` // Only types in the PreferenceSchema
+	CreatedAt          time.Time                 `json:"created_at"`
+	UpdatedAt          time.Time                 `json:"updated_at"`
+
+	// Version is incremented by every update. UpdatePreferences and
+	// UpdatePreferencesBulk reject preferences carrying an older version with
+	// ErrConflict; zero skips the check.
+	Version int `json:"version"`
+}
+
+// FeatureFlags are the features switched on or off for a single user, e.g. to
+// open beta endpoints to selected users. Flags never set for the user are off.
+type FeatureFlags struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	Flags     map[string]bool `json:"flags"`
+	UpdatedAt time.Time       `json:"updated_at"` // Latest change to any flag; zero when none was set
+}
+
+// DataExport is a portable copy of the personal data held about a user. Each
+// layer of the decorator chain contributes the data it owns: storage the
+// profile and preferences, audit the audit trail and the usecase layer the
+// notification history.
+type DataExport struct {
+	UserID        string                             `json:"user_id"`
+	ExportedAt    time.Time                          `json:"exported_at"`
+	Profile       *User                              `json:"profile"`
+	Preferences   *UserPreferences                   `json:"preferences,omitempty"`
+	AuditEntries  []audit.AuditEntry                 `json:"audit_entries"`
+	Notifications []notification.NotificationHistory `json:"notifications"`
+}
+
+// ListFilters selects, sorts and pages the users returned by List
+type ListFilters struct {
+	EmailPrefix   string     `json:"email_prefix,omitempty" validate:"max=255"`
+	Name          string     `json:"name,omitempty" validate:"max=100"` // Case-insensitive match anywhere in the first or last name
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`           // Inclusive
+	CreatedBefore *time.Time `json:"created_before,omitempty"`          // Exclusive
+
+	SortBy    string `json:"sort_by,omitempty" validate:"omitempty,oneof=created_at email first_name last_name"` // One of the SortBy constants; defaults to created_at
+	SortOrder string `json:"sort_order,omitempty" validate:"omitempty,oneof=asc desc"`                           // SortAsc or SortDesc; defaults to descending
+
+	// Pages are selected either by offset or, for stable paging through
+	// changing data, by the NextCursor of the previous page. A cursor takes
+	// precedence over the offset.
+	Limit  int    `json:"limit,omitempty" validate:"gte=0,lte=100"` // Defaults to DefaultListLimit, at most MaxListLimit
+	Offset int    `json:"offset,omitempty" validate:"gte=0"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Sortable user fields and sort orders for ListFilters
+const (
+	SortByCreatedAt = "created_at"
+	SortByEmail     = "email"
+	SortByFirstName = "first_name"
+	SortByLastName  = "last_name"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// List page sizes
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// Page is one page of users returned by List
+type Page struct {
+	Users      []*User `json:"users"`
+	Total      int64   `json:"total"` // Users matching the filters across all pages
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset,omitempty"`
+	NextCursor string  `json:"next_cursor,omitempty"` // Set when more users follow
+}
+
+// HasMore reports whether another page follows
+func (p *Page) HasMore() bool {
+	return p.NextCursor != ""
+}
+
+// ListCursor is the position after the last user of a page. It records the
+// sort it was issued for so it cannot be replayed against another ordering.
+type ListCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"` // Sort field of the last user; RFC 3339 for created_at
+	ID        string `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor used in Page.NextCursor
+func (c ListCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
 }
 
+// DecodeListCursor parses a cursor issued for the given filters
+func DecodeListCursor(encoded string, filters ListFilters) (ListCursor, error) {
+	var cursor ListCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID == "" {
+		return ListCursor{}, ErrInvalidCursor
+	}
+
+	filters = filters.WithDefaults()
+	if cursor.SortBy != filters.SortBy || cursor.SortOrder != filters.SortOrder {
+		return ListCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// PreferenceCleanupOptions controls a stale preference cleanup run
+type PreferenceCleanupOptions struct {
+	// Strip removes unknown notification types; without it the run only reports them
+	Strip bool `json:"strip"`
+
+	// BatchSize is how many preference rows are loaded at a time; zero uses
+	// DefaultCleanupBatchSize
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// PreferenceCleanupReport summarizes the notification types found in stored
+// preferences that are no longer in the registry
+type PreferenceCleanupReport struct {
+	Scanned       int            `json:"scanned"`
+	UnknownKeys   map[string]int `json:"unknown_keys"` // Number of users holding each unknown key
+	AffectedUsers []string       `json:"affected_users"`
+	Stripped      bool           `json:"stripped"`
+}
+
+// MaxBatchSize is the most users a single GetByIDs or UpdatePreferencesBulk
+// call may address. GetByIDs omits users that do not exist, while
+// UpdatePreferencesBulk applies every update or none.
+const MaxBatchSize = 100
+
+// DefaultPasswordHistorySize is how many of a user's most recent passwords,
+// including the current one, ChangePassword refuses to reuse
+const DefaultPasswordHistorySize = 5
+
+// MaxAvatarSize is the largest avatar image UploadAvatar accepts, in bytes
+const MaxAvatarSize = 5 << 20
+
+// AvatarContentTypes maps the image types accepted as avatars to the file
+// extension they are stored with
+var AvatarContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// MaxFeatureFlagLength is the longest feature flag name SetFeatureFlag accepts
+const MaxFeatureFlagLength = 64
+
+// DefaultCleanupBatchSize is the number of preference rows loaded at a time by a cleanup run
+const DefaultCleanupBatchSize = 500
+
 // UserError represents domain-specific user errors
 type UserError struct {
 	Code    string `json:"code"`
@@ -77,6 +292,12 @@ func (e UserError) Error() string {
 	return e.Message
 }
 
+// Is matches errors by code so errors with a specific message or field still match the sentinels
+func (e UserError) Is(target error) bool {
+	t, ok := target.(UserError)
+	return ok && t.Code == e.Code
+}
+
 // Common user error codes
 var (
 	ErrUserNotFound        = UserError{Code: "USER_NOT_FOUND", Message: "User not found"}
@@ -87,6 +308,29 @@ var (
 	ErrEmptyFirstName      = UserError{Code: "EMPTY_FIRST_NAME", Message: "First name is required"}
 	ErrEmptyLastName       = UserError{Code: "EMPTY_LAST_NAME", Message: "Last name is required"}
 	ErrPreferencesNotFound = UserError{Code: "PREFERENCES_NOT_FOUND", Message: "User preferences not found"}
+	ErrRateLimited         = UserError{Code: "RATE_LIMITED", Message: "Too many requests, please try again later"}
+	ErrServiceUnavailable  = UserError{Code: "SERVICE_UNAVAILABLE", Message: "Service temporarily unavailable, please try again later"}
+	ErrForbidden           = UserError{Code: "FORBIDDEN", Message: "You are not allowed to perform this action"}
+	ErrAccountDeactivated  = UserError{Code: "ACCOUNT_DEACTIVATED", Message: "This account has been deactivated"}
+	ErrAccountLocked       = UserError{Code: "ACCOUNT_LOCKED", Message: "Too many failed sign-in attempts, please try again later"}
+	ErrInvalidCursor       = UserError{Code: "INVALID_CURSOR", Message: "Invalid or expired page cursor", Field: "cursor"}
+	ErrInvalidListFilter   = UserError{Code: "INVALID_LIST_FILTER", Message: "Invalid list filter"}
+	ErrIncorrectPassword   = UserError{Code: "INCORRECT_PASSWORD", Message: "Current password is incorrect", Field: "current_password"}
+	ErrPasswordReused      = UserError{Code: "PASSWORD_REUSED", Message: "New password must differ from your recent passwords", Field: "new_password"}
+	ErrEmailUnchanged      = UserError{Code: "EMAIL_UNCHANGED", Message: "New email is the same as the current email", Field: "email"}
+	ErrNoPendingEmail      = UserError{Code: "NO_PENDING_EMAIL_CHANGE", Message: "No email change is awaiting confirmation"}
+	ErrInvalidEmailToken   = UserError{Code: "INVALID_EMAIL_CHANGE_TOKEN", Message: "Invalid or expired email confirmation token", Field: "token"}
+	ErrInvalidVerifyToken  = UserError{Code: "INVALID_VERIFICATION_TOKEN", Message: "Invalid or expired email verification token", Field: "token"}
+	ErrInvalidResetToken   = UserError{Code: "INVALID_PASSWORD_RESET_TOKEN", Message: "Invalid or expired password reset token", Field: "token"}
+	ErrEmailNotVerified    = UserError{Code: "EMAIL_NOT_VERIFIED", Message: "Verify your email address to sign in; a new verification email has been sent"}
+	ErrAvatarNotFound      = UserError{Code: "AVATAR_NOT_FOUND", Message: "User has no avatar"}
+	ErrAvatarTooLarge      = UserError{Code: "AVATAR_TOO_LARGE", Message: "Avatar image must be at most 5 MB", Field: "avatar"}
+	ErrUnsupportedAvatar   = UserError{Code: "UNSUPPORTED_AVATAR_TYPE", Message: "Avatar must be a JPEG, PNG, GIF or WebP image", Field: "content_type"}
+	ErrConflict            = UserError{Code: "CONFLICT", Message: "The resource was changed by another request, reload it and try again"}
+	ErrIdempotencyKeyInUse = UserError{Code: "IDEMPOTENCY_KEY_IN_USE", Message: "A request with this idempotency key is still in progress"}
+	ErrIdempotencyKeyReuse = UserError{Code: "IDEMPOTENCY_KEY_REUSED", Message: "The idempotency key was already used for a different request"}
+	ErrUnknownNotification = UserError{Code: "UNKNOWN_NOTIFICATION_TYPE", Message: "Unknown notification type", Field: "notification_types"}
+	ErrInvalidFeatureFlag  = UserError{Code: "INVALID_FEATURE_FLAG", Message: "Feature flags are named with up to 64 lowercase letters, digits, '_', '-' or '.'", Field: "flag"}
 )
 
 // Helper methods for User
@@ -94,14 +338,74 @@ func (u *User) GetFullName() string {
 	return u.FirstName + " " + u.LastName
 }
 
+// IsActive reports whether the user can sign in
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil && u.DeletedAt == nil
+}
+
+// IsDeleted reports whether the user has been soft deleted
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsEmailVerified reports whether the user has proved they control their email
 func (u *User) IsEmailVerified() bool {
-	// This would typically check an email verification status
-	// For now, we'll assume all users are verified
-	return true
+	return u.EmailVerifiedAt != nil
+}
+
+// Helper methods for ListFilters
+
+// WithDefaults returns the filters with the sort and page size filled in
+func (f ListFilters) WithDefaults() ListFilters {
+	if f.SortBy == "" {
+		f.SortBy = SortByCreatedAt
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = SortDesc
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+	return f
+}
+
+// EncryptedFieldsError returns ErrInvalidListFilter when the filters search
+// or sort on the email or names. The encryption layer stores those as
+// ciphertext, so such lists cannot be served while it is enabled.
+func (f ListFilters) EncryptedFieldsError() error {
+	err := ErrInvalidListFilter
+	switch {
+	case f.EmailPrefix != "":
+		err.Message, err.Field = "email search is not available while user data is encrypted", "email_prefix"
+	case f.Name != "":
+		err.Message, err.Field = "name search is not available while user data is encrypted", "name"
+	case f.SortBy == SortByEmail || f.SortBy == SortByFirstName || f.SortBy == SortByLastName:
+		err.Message, err.Field = "sorting by encrypted fields is not supported", "sort_by"
+	default:
+		return nil
+	}
+	return err
+}
+
+// SortValue returns the field of u the filters sort by, as stored in a cursor
+func (f ListFilters) SortValue(u *User) string {
+	switch f.WithDefaults().SortBy {
+	case SortByEmail:
+		return u.Email
+	case SortByFirstName:
+		return u.FirstName
+	case SortByLastName:
+		return u.LastName
+	default:
+		return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
 }
 
 // Helper methods for UserPreferences
-func (p *UserPreferences) IsNotificationEnabled(notificationType string) bool {
+func (p *UserPreferences) IsNotificationEnabled(notificationType NotificationType) bool {
 	if p.NotificationTypes == nil {
 		return false
 	}
@@ -109,20 +413,104 @@ func (p *UserPreferences) IsNotificationEnabled(notificationType string) bool {
 	return exists && enabled
 }
 
-func (p *UserPreferences) EnableNotification(notificationType string) {
+func (p *UserPreferences) EnableNotification(notificationType NotificationType) {
 	if p.NotificationTypes == nil {
-		p.NotificationTypes = make(map[string]bool)
+		p.NotificationTypes = make(map[NotificationType]bool)
 	}
 	p.NotificationTypes[notificationType] = true
 }
 
-func (p *UserPreferences) DisableNotification(notificationType string) {
+func (p *UserPreferences) DisableNotification(notificationType NotificationType) {
 	if p.NotificationTypes == nil {
 		return
 	}
 	p.NotificationTypes[notificationType] = false
 }
 
+// Updated returns the preferences as stored after an update with p succeeded.
+// It reports false when p carried no version, as the stored version is then
+// unknown and the preferences have to be read again.
+func (p UserPreferences) Updated() (UserPreferences, bool) {
+	if p.Version <= 0 {
+		return p, false
+	}
+	p.Version++
+	return p, true
+}
+
+// Helper methods for FeatureFlags
+
+// IsEnabled reports whether the flag is switched on
+func (f *FeatureFlags) IsEnabled(flag string) bool {
+	return f != nil && f.Flags[flag]
+}
+
+// IsValidFeatureFlag reports whether flag can name a feature flag: 1 to
+// MaxFeatureFlagLength lowercase letters, digits, '_', '-' or '.'
+func IsValidFeatureFlag(flag string) bool {
+	if flag == "" || len(flag) > MaxFeatureFlagLength {
+		return false
+	}
+	for _, c := range flag {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFeatureEnabled reports whether the flag is switched on for the user. The
+// flags are read through service, so decorators can consult the layer below
+// them and the REST layer the whole chain, with its cache. Callers decide
+// whether an error fails open or closed.
+func IsFeatureEnabled(ctx context.Context, service Service, userID, flag string) (bool, error) {
+	flags, err := service.GetFeatureFlags(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return flags.IsEnabled(flag), nil
+}
+
+// Helper methods for DataExport
+
+// JSON renders the export as a single indented JSON document
+func (e *DataExport) JSON() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per section
+func (e *DataExport) WriteZip(w io.Writer) error {
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"export.json", map[string]interface{}{"user_id": e.UserID, "exported_at": e.ExportedAt}},
+		{"profile.json", e.Profile},
+		{"preferences.json", e.Preferences},
+		{"audit_entries.json", e.AuditEntries},
+		{"notifications.json", e.Notifications},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: e.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
 // DefaultUserPreferences returns default preferences for a new user
 func DefaultUserPreferences(userID uuid.UUID) *UserPreferences {
 	return &UserPreferences{
@@ -134,15 +522,502 @@ func DefaultUserPreferences(userID uuid.UUID) *UserPreferences {
 		Theme:              "light",
 		Language:           "en",
 		Timezone:           "UTC",
-		NotificationTypes: map[string]bool{
-			"task_assigned":   true,
-			"task_due_soon":   true,
-			"project_updated": true,
-			"project_invite":  true,
-			"system_updates":  false,
-			"marketing":       false,
+		NotificationTypes:  DefaultNotificationTypes(),
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+		Version:            1,
+	}
+}
+
+// NotificationType names a kind of notification users can switch on or off
+type NotificationType string
+
+// Notification types declared by the domains that send them
+const (
+	NotificationTaskAssigned   NotificationType = "task_assigned"
+	NotificationTaskDueSoon    NotificationType = "task_due_soon"
+	NotificationProjectUpdated NotificationType = "project_updated"
+	NotificationProjectInvite  NotificationType = "project_invite"
+	NotificationSystemUpdates  NotificationType = "system_updates"
+	NotificationMarketing      NotificationType = "marketing"
+)
+
+// NotificationTypeDefinition declares a notification type in a PreferenceSchema
+type NotificationTypeDefinition struct {
+	Type        NotificationType `json:"type"`
+	Domain      string           `json:"domain"`  // Domain sending the notifications, set by Register
+	Default     bool             `json:"default"` // Setting new users start with
+	Description string           `json:"description"`
+}
+
+// PreferenceSchema is the registry of notification types preferences may
+// hold. Each domain declares the types it sends with Register while the
+// application is wired; UpdatePreferences rejects every other type.
+type PreferenceSchema struct {
+	mu    sync.RWMutex
+	types map[NotificationType]NotificationTypeDefinition
+}
+
+// NewPreferenceSchema creates an empty preference schema
+func NewPreferenceSchema() *PreferenceSchema {
+	return &PreferenceSchema{types: make(map[NotificationType]NotificationTypeDefinition)}
+}
+
+// defaultPreferenceSchema holds the notification types of the built-in domains
+var defaultPreferenceSchema = newDefaultPreferenceSchema()
+
+// DefaultPreferenceSchema returns the schema used by the user service. Stored
+// keys missing from it are stale and are removed by CleanupPreferences.
+func DefaultPreferenceSchema() *PreferenceSchema {
+	return defaultPreferenceSchema
+}
+
+// Register declares the notification types sent by domain. A type already
+// declared by another domain is rejected so two features cannot share a switch.
+func (s *PreferenceSchema) Register(domain string, definitions ...NotificationTypeDefinition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, definition := range definitions {
+		if definition.Type == "" {
+			return fmt.Errorf("notification type of domain %s must not be empty", domain)
+		}
+		if existing, ok := s.types[definition.Type]; ok && existing.Domain != domain {
+			return fmt.Errorf("notification type %s is already declared by domain %s", definition.Type, existing.Domain)
+		}
+	}
+	for _, definition := range definitions {
+		definition.Domain = domain
+		s.types[definition.Type] = definition
+	}
+	return nil
+}
+
+// Definitions returns every declared notification type, sorted by type
+func (s *PreferenceSchema) Definitions() []NotificationTypeDefinition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	definitions := make([]NotificationTypeDefinition, 0, len(s.types))
+	for _, definition := range s.types {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Type < definitions[j].Type })
+	return definitions
+}
+
+// IsKnown reports whether the notification type is declared
+func (s *PreferenceSchema) IsKnown(notificationType NotificationType) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, known := s.types[notificationType]
+	return known
+}
+
+// Defaults returns every declared notification type with the setting new
+// users start with
+func (s *PreferenceSchema) Defaults() map[NotificationType]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defaults := make(map[NotificationType]bool, len(s.types))
+	for notificationType, definition := range s.types {
+		defaults[notificationType] = definition.Default
+	}
+	return defaults
+}
+
+// Unknown returns the notification types in prefs that are not declared, sorted
+func (s *PreferenceSchema) Unknown(prefs UserPreferences) []NotificationType {
+	var unknown []NotificationType
+	for notificationType := range prefs.NotificationTypes {
+		if !s.IsKnown(notificationType) {
+			unknown = append(unknown, notificationType)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+	return unknown
+}
+
+// Validate rejects preferences holding undeclared notification types with
+// ErrUnknownNotification naming the first of them
+func (s *PreferenceSchema) Validate(prefs UserPreferences) error {
+	unknown := s.Unknown(prefs)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	err := ErrUnknownNotification
+	err.Message = fmt.Sprintf("Unknown notification type %q", unknown[0])
+	return err
+}
+
+// Complete adds the declared notification types missing from prefs with
+// their defaults, e.g. for preferences stored before a type was declared
+func (s *PreferenceSchema) Complete(prefs *UserPreferences) {
+	if prefs.NotificationTypes == nil {
+		prefs.NotificationTypes = make(map[NotificationType]bool)
+	}
+	for notificationType, enabled := range s.Defaults() {
+		if _, exists := prefs.NotificationTypes[notificationType]; !exists {
+			prefs.NotificationTypes[notificationType] = enabled
+		}
+	}
+}
+
+// newDefaultPreferenceSchema declares the notification types of the built-in domains
+func newDefaultPreferenceSchema() *PreferenceSchema {
+	schema := NewPreferenceSchema()
+	declarations := map[string][]NotificationTypeDefinition{
+		"tasks": {
+			{Type: NotificationTaskAssigned, Default: true, Description: "A task was assigned to you"},
+			{Type: NotificationTaskDueSoon, Default: true, Description: "One of your tasks is due soon"},
+		},
+		"projects": {
+			{Type: NotificationProjectUpdated, Default: true, Description: "A project you belong to changed"},
+			{Type: NotificationProjectInvite, Default: true, Description: "You were invited to a project"},
+		},
+		"platform": {
+			{Type: NotificationSystemUpdates, Default: false, Description: "Product and maintenance announcements"},
+			{Type: NotificationMarketing, Default: false, Description: "Offers and newsletters"},
 		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	}
+	for domain, definitions := range declarations {
+		if err := schema.Register(domain, definitions...); err != nil {
+			panic(err)
+		}
+	}
+	return schema
+}
+
+// DefaultNotificationTypes returns the notification types of the default
+// schema with the setting new users start with
+func DefaultNotificationTypes() map[NotificationType]bool {
+	return DefaultPreferenceSchema().Defaults()
+}
+
+// IsKnownNotificationType reports whether the notification type is declared
+// in the default schema
+func IsKnownNotificationType(notificationType NotificationType) bool {
+	return DefaultPreferenceSchema().IsKnown(notificationType)
+}
+
+// UnknownNotificationTypes returns the stored notification types missing
+// from the default schema, sorted
+func (p *UserPreferences) UnknownNotificationTypes() []NotificationType {
+	return DefaultPreferenceSchema().Unknown(*p)
+}
+
+// cacheBypassKey is the context key used to request fresh reads
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a context that makes caching layers skip cached
+// reads and fetch fresh data, e.g. on consistency-critical paths right after
+// a payment
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// IsCacheBypassed reports whether the context requests a fresh read
+func IsCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// includeDeletedKey is the context key used to request soft-deleted users
+type includeDeletedKey struct{}
+
+// WithIncludeDeleted returns a context in which reads also return soft-deleted
+// users instead of ErrUserNotFound, e.g. for administrative lookups and exports
+func WithIncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IsDeletedIncluded reports whether the context requests soft-deleted users
+func IsDeletedIncluded(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
+// sessionIDKey is the context key carrying the client session that issued a request
+type sessionIDKey struct{}
+
+// WithSessionID returns a context tagged with the client session that
+// originated the request, so change events can exclude the originating session
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// SessionIDFromContext returns the originating client session, if any
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey{}).(string)
+	return sessionID
+}
+
+// clientIPKey is the context key carrying the IP address of the caller
+type clientIPKey struct{}
+
+// WithClientIP returns a context tagged with the caller's IP address, used by
+// per-IP protections such as rate limiting
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the caller's IP address, if any
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// userAgentKey is the context key carrying the caller's user agent
+type userAgentKey struct{}
+
+// WithUserAgent returns a context tagged with the caller's user agent, used
+// to recognize the devices users sign in from
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+// UserAgentFromContext returns the caller's user agent, if any
+func UserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(userAgentKey{}).(string)
+	return userAgent
+}
+
+// deviceFingerprintKey is the context key carrying the client's device fingerprint
+type deviceFingerprintKey struct{}
+
+// WithDeviceFingerprint returns a context tagged with a fingerprint the
+// client computed for its device, which identifies it more reliably than
+// the user agent
+func WithDeviceFingerprint(ctx context.Context, fingerprint string) context.Context {
+	return context.WithValue(ctx, deviceFingerprintKey{}, fingerprint)
+}
+
+// DeviceFingerprintFromContext returns the client's device fingerprint, if any
+func DeviceFingerprintFromContext(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(deviceFingerprintKey{}).(string)
+	return fingerprint
+}
+
+// captchaTokenKey is the context key carrying the client's captcha token
+type captchaTokenKey struct{}
+
+// WithCaptchaToken returns a context tagged with the token the client got
+// from solving a captcha, checked before registrations and suspicious logins
+func WithCaptchaToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, captchaTokenKey{}, token)
+}
+
+// CaptchaTokenFromContext returns the client's captcha token, if any
+func CaptchaTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(captchaTokenKey{}).(string)
+	return token
+}
+
+// captchaExemptKey is the context key marking requests that need no captcha
+type captchaExemptKey struct{}
+
+// WithCaptchaExemption returns a context whose requests skip captcha checks,
+// for users an identity provider has already signed in
+func WithCaptchaExemption(ctx context.Context) context.Context {
+	return context.WithValue(ctx, captchaExemptKey{}, true)
+}
+
+// IsCaptchaExempt reports whether the context was marked with WithCaptchaExemption
+func IsCaptchaExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(captchaExemptKey{}).(bool)
+	return exempt
+}
+
+// emailVerificationKey is the context key carrying the user a verification
+// token was issued to
+type emailVerificationKey struct{}
+
+// WithEmailVerification returns a context naming the user whose email a
+// VerifyEmail token proved. The usecase layer resolves the token; the storage
+// layer marks the named user verified.
+func WithEmailVerification(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, emailVerificationKey{}, userID)
+}
+
+// EmailVerificationFromContext returns the user stored by WithEmailVerification, if any
+func EmailVerificationFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(emailVerificationKey{}).(string)
+	return userID
+}
+
+// passwordResetKey is the context key carrying the user a password reset
+// token was issued to
+type passwordResetKey struct{}
+
+// WithPasswordReset returns a context naming the user whose password a
+// ResetPassword token may replace. The usecase layer resolves the token; the
+// storage layer replaces the password of the named user.
+func WithPasswordReset(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, passwordResetKey{}, userID)
+}
+
+// PasswordResetFromContext returns the user stored by WithPasswordReset, if any
+func PasswordResetFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(passwordResetKey{}).(string)
+	return userID
+}
+
+// idempotencyKeyKey is the context key carrying the client's idempotency key
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey returns a context tagged with a client-chosen key, so
+// retries of a mutating request carrying the same key return the original
+// result instead of repeating the operation
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the client's idempotency key, if any
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// Impersonation marks calls an admin makes as another user. The context's
+// subject is the impersonated user, so ownership checks see the effective
+// actor, while the audit trail also records the admin actually acting.
+type Impersonation struct {
+	ActorID      string   // The admin actually making the calls
+	OnBehalfOfID string   // The user the calls are made as
+	Scopes       []string // Authorization actions the admin was granted
+}
+
+// Allows reports whether the admin was granted the authorization action
+func (i Impersonation) Allows(action string) bool {
+	for _, scope := range i.Scopes {
+		if scope == action {
+			return true
+		}
+	}
+	return false
+}
+
+// impersonationKey is the context key carrying an admin's impersonation
+type impersonationKey struct{}
+
+// WithImpersonation returns a context marking that the admin in imp acts as
+// another user
+func WithImpersonation(ctx context.Context, imp Impersonation) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, imp)
+}
+
+// ImpersonationFromContext returns the impersonation the calls are made
+// under, if any
+func ImpersonationFromContext(ctx context.Context) (Impersonation, bool) {
+	imp, ok := ctx.Value(impersonationKey{}).(Impersonation)
+	return imp, ok && imp.ActorID != ""
+}
+
+// SideEffectTimeout bounds side effects that outlive the request, such as
+// audit writes, cache updates and event publishes
+const SideEffectTimeout = 5 * time.Second
+
+// DetachContext returns a context for side effects that must complete even if
+// the caller disconnects. It keeps the request values (audit, correlation and
+// session data) but not its cancellation, and is bounded by SideEffectTimeout.
+func DetachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), SideEffectTimeout)
+}
+
+// LayerTiming is the time spent inside one layer of the decorator chain,
+// excluding the time spent in the layers below it
+type LayerTiming struct {
+	Layer    string
+	Duration time.Duration
+	Calls    int
+}
+
+// Timings collects per-layer timings for a single request
+type Timings struct {
+	mu     sync.Mutex
+	layers []LayerTiming
+}
+
+// timingsKey is the context key carrying the request's Timings
+type timingsKey struct{}
+
+// timingFrameKey is the context key carrying the innermost running layer
+type timingFrameKey struct{}
+
+// timingFrame tracks time spent in the layers below a running layer
+type timingFrame struct {
+	mu    sync.Mutex
+	child time.Duration
+}
+
+// WithTimings returns a context in which decorator layers record their timings
+func WithTimings(ctx context.Context, timings *Timings) context.Context {
+	return context.WithValue(ctx, timingsKey{}, timings)
+}
+
+// StartLayerTiming starts timing a layer and returns the context to pass to
+// the next layer and a function that stops the timer. It is a no-op unless
+// the context carries Timings.
+func StartLayerTiming(ctx context.Context, layer string) (context.Context, func()) {
+	timings, _ := ctx.Value(timingsKey{}).(*Timings)
+	if timings == nil {
+		return ctx, func() {}
+	}
+
+	timings.enter(layer)
+	parent, _ := ctx.Value(timingFrameKey{}).(*timingFrame)
+	frame := &timingFrame{}
+	start := time.Now()
+
+	return context.WithValue(ctx, timingFrameKey{}, frame), func() {
+		elapsed := time.Since(start)
+
+		frame.mu.Lock()
+		self := elapsed - frame.child
+		frame.mu.Unlock()
+
+		if parent != nil {
+			parent.mu.Lock()
+			parent.child += elapsed
+			parent.mu.Unlock()
+		}
+
+		timings.record(layer, self)
+	}
+}
+
+// Layers returns the recorded timings in the order layers were first entered
+func (t *Timings) Layers() []LayerTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]LayerTiming(nil), t.layers...)
+}
+
+func (t *Timings) enter(layer string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, existing := range t.layers {
+		if existing.Layer == layer {
+			return
+		}
+	}
+	t.layers = append(t.layers, LayerTiming{Layer: layer})
+}
+
+func (t *Timings) record(layer string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.layers {
+		if t.layers[i].Layer == layer {
+			t.layers[i].Duration += duration
+			t.layers[i].Calls++
+			return
+		}
 	}
 }
@@ -1,6 +1,8 @@
 package user_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -62,32 +64,46 @@ func TestUser_GetFullName(t *testing.T) {
 }
 
 func TestUser_IsEmailVerified(t *testing.T) {
-	t.Run("Given any user, When IsEmailVerified is called, Then should return true", func(t *testing.T) {
-		// Arrange
-		testUser := user.User{
-			ID:    uuid.New(),
-			Email: "test@example.com",
-		}
+	verifiedAt := time.Now()
+	tests := []struct {
+		name     string
+		user     user.User
+		expected bool
+	}{
+		{
+			name:     "Given a user who has not verified their email, When IsEmailVerified is called, Then should return false",
+			user:     user.User{ID: uuid.New(), Email: "test@example.com"},
+			expected: false,
+		},
+		{
+			name:     "Given a user with a verification time, When IsEmailVerified is called, Then should return true",
+			user:     user.User{ID: uuid.New(), Email: "test@example.com", EmailVerifiedAt: &verifiedAt},
+			expected: true,
+		},
+	}
 
-		// Act
-		result := testUser.IsEmailVerified()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := tt.user.IsEmailVerified()
 
-		// Assert
-		assert.True(t, result)
-	})
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
 }
 
 func TestUserPreferences_IsNotificationEnabled(t *testing.T) {
 	tests := []struct {
 		name             string
 		preferences      user.UserPreferences
-		notificationType string
+		notificationType user.NotificationType
 		expected         bool
 	}{
 		{
 			name: "Given preferences with enabled notification type, When IsNotificationEnabled is called, Then should return true",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": true,
 					"task_updated":  false,
 				},
@@ -98,7 +114,7 @@ func TestUserPreferences_IsNotificationEnabled(t *testing.T) {
 		{
 			name: "Given preferences with disabled notification type, When IsNotificationEnabled is called, Then should return false",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": true,
 					"task_updated":  false,
 				},
@@ -109,7 +125,7 @@ func TestUserPreferences_IsNotificationEnabled(t *testing.T) {
 		{
 			name: "Given preferences with missing notification type, When IsNotificationEnabled is called, Then should return false",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": true,
 				},
 			},
@@ -141,13 +157,13 @@ func TestUserPreferences_EnableNotification(t *testing.T) {
 	tests := []struct {
 		name             string
 		preferences      user.UserPreferences
-		notificationType string
+		notificationType user.NotificationType
 		expectedValue    bool
 	}{
 		{
 			name: "Given preferences with existing notification types, When EnableNotification is called, Then should enable notification",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": false,
 				},
 			},
@@ -165,7 +181,7 @@ func TestUserPreferences_EnableNotification(t *testing.T) {
 		{
 			name: "Given preferences with new notification type, When EnableNotification is called, Then should add and enable notification",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_updated": true,
 				},
 			},
@@ -190,13 +206,13 @@ func TestUserPreferences_DisableNotification(t *testing.T) {
 	tests := []struct {
 		name             string
 		preferences      user.UserPreferences
-		notificationType string
+		notificationType user.NotificationType
 		expectedValue    bool
 	}{
 		{
 			name: "Given preferences with enabled notification, When DisableNotification is called, Then should disable notification",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": true,
 				},
 			},
@@ -206,7 +222,7 @@ func TestUserPreferences_DisableNotification(t *testing.T) {
 		{
 			name: "Given preferences with disabled notification, When DisableNotification is called, Then should keep notification disabled",
 			preferences: user.UserPreferences{
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned": false,
 				},
 			},
@@ -266,6 +282,122 @@ func TestDefaultUserPreferences(t *testing.T) {
 	})
 }
 
+func TestUserPreferences_UnknownNotificationTypes(t *testing.T) {
+	t.Run("Given preferences with keys missing from the registry, When UnknownNotificationTypes is called, Then should return them sorted", func(t *testing.T) {
+		// Arrange
+		preferences := user.DefaultUserPreferences(uuid.New())
+		preferences.NotificationTypes["weekly_digest"] = true
+		preferences.NotificationTypes["beta_invite"] = false
+
+		// Act
+		unknown := preferences.UnknownNotificationTypes()
+
+		// Assert
+		assert.Equal(t, []user.NotificationType{"beta_invite", "weekly_digest"}, unknown)
+		assert.True(t, user.IsKnownNotificationType("marketing"))
+		assert.False(t, user.IsKnownNotificationType("weekly_digest"))
+	})
+
+	t.Run("Given default preferences, When UnknownNotificationTypes is called, Then should return none", func(t *testing.T) {
+		// Act
+		unknown := user.DefaultUserPreferences(uuid.New()).UnknownNotificationTypes()
+
+		// Assert
+		assert.Empty(t, unknown)
+	})
+}
+
+func TestPreferenceSchema(t *testing.T) {
+	t.Run("Given a type declared by another domain, When Register is called, Then should reject it", func(t *testing.T) {
+		// Arrange
+		schema := user.NewPreferenceSchema()
+		assert.NoError(t, schema.Register("tasks", user.NotificationTypeDefinition{Type: user.NotificationTaskAssigned, Default: true}))
+
+		// Act
+		err := schema.Register("projects", user.NotificationTypeDefinition{Type: user.NotificationTaskAssigned})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, "tasks", schema.Definitions()[0].Domain)
+	})
+
+	t.Run("Given preferences with an undeclared type, When Validate is called, Then should return unknown notification error", func(t *testing.T) {
+		// Arrange
+		schema := user.NewPreferenceSchema()
+		assert.NoError(t, schema.Register("tasks", user.NotificationTypeDefinition{Type: user.NotificationTaskAssigned, Default: true}))
+		preferences := user.UserPreferences{NotificationTypes: map[user.NotificationType]bool{
+			user.NotificationTaskAssigned: true,
+			"weekly_digest":               true,
+		}}
+
+		// Act
+		err := schema.Validate(preferences)
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrUnknownNotification)
+		assert.Contains(t, err.Error(), "weekly_digest")
+	})
+
+	t.Run("Given preferences missing declared types, When Complete is called, Then should add them with their defaults", func(t *testing.T) {
+		// Arrange
+		schema := user.NewPreferenceSchema()
+		assert.NoError(t, schema.Register("tasks",
+			user.NotificationTypeDefinition{Type: user.NotificationTaskAssigned, Default: true},
+			user.NotificationTypeDefinition{Type: user.NotificationTaskDueSoon, Default: true},
+		))
+		preferences := user.UserPreferences{NotificationTypes: map[user.NotificationType]bool{
+			user.NotificationTaskAssigned: false,
+		}}
+
+		// Act
+		schema.Complete(&preferences)
+
+		// Assert
+		assert.Equal(t, map[user.NotificationType]bool{
+			user.NotificationTaskAssigned: false,
+			user.NotificationTaskDueSoon:  true,
+		}, preferences.NotificationTypes)
+	})
+}
+
+func TestUserPreferences_Updated(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         int
+		expectedVersion int
+		expectedKnown   bool
+	}{
+		{
+			name:            "Given versioned preferences, When Updated is called, Then should return the next version",
+			version:         3,
+			expectedVersion: 4,
+			expectedKnown:   true,
+		},
+		{
+			name:            "Given unversioned preferences, When Updated is called, Then should report the version as unknown",
+			version:         0,
+			expectedVersion: 0,
+			expectedKnown:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			preferences := user.UserPreferences{Theme: "dark", Version: tt.version}
+
+			// Act
+			updated, known := preferences.Updated()
+
+			// Assert
+			assert.Equal(t, tt.expectedKnown, known)
+			assert.Equal(t, tt.expectedVersion, updated.Version)
+			assert.Equal(t, "dark", updated.Theme)
+			assert.Equal(t, tt.version, preferences.Version)
+		})
+	}
+}
+
 func TestUserError_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -423,4 +555,216 @@ func TestAuthResult_Structure(t *testing.T) {
 		assert.Equal(t, "refresh-token", authResult.RefreshToken)
 		assert.Equal(t, now, authResult.ExpiresAt)
 	})
-}
\ No newline at end of file
+}
+func TestWithCacheBypass(t *testing.T) {
+	t.Run("Given plain context, When checking cache bypass, Then should not be bypassed", func(t *testing.T) {
+		assert.False(t, user.IsCacheBypassed(context.Background()))
+	})
+
+	t.Run("Given context with cache bypass, When checking cache bypass, Then should be bypassed", func(t *testing.T) {
+		ctx := user.WithCacheBypass(context.Background())
+		assert.True(t, user.IsCacheBypassed(ctx))
+	})
+}
+
+func TestUser_IsActive(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		user            *user.User
+		expectedActive  bool
+		expectedDeleted bool
+	}{
+		{
+			name:           "Given a regular user, When checking status, Then should be active",
+			user:           &user.User{},
+			expectedActive: true,
+		},
+		{
+			name:           "Given a deactivated user, When checking status, Then should be inactive but not deleted",
+			user:           &user.User{DeactivatedAt: &now},
+			expectedActive: false,
+		},
+		{
+			name:            "Given a soft-deleted user, When checking status, Then should be inactive and deleted",
+			user:            &user.User{DeletedAt: &now},
+			expectedActive:  false,
+			expectedDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedActive, tt.user.IsActive())
+			assert.Equal(t, tt.expectedDeleted, tt.user.IsDeleted())
+		})
+	}
+}
+
+func TestWithIncludeDeleted(t *testing.T) {
+	t.Run("Given plain context, When checking include deleted, Then should not include deleted users", func(t *testing.T) {
+		assert.False(t, user.IsDeletedIncluded(context.Background()))
+	})
+
+	t.Run("Given context with include deleted, When checking include deleted, Then should include deleted users", func(t *testing.T) {
+		ctx := user.WithIncludeDeleted(context.Background())
+		assert.True(t, user.IsDeletedIncluded(ctx))
+	})
+}
+
+func TestStartLayerTiming(t *testing.T) {
+	t.Run("Given nested layers, When timing, Then each layer reports only its own time", func(t *testing.T) {
+		timings := &user.Timings{}
+		ctx := user.WithTimings(context.Background(), timings)
+
+		outerCtx, stopOuter := user.StartLayerTiming(ctx, "validation")
+		_, stopInner := user.StartLayerTiming(outerCtx, "db")
+		time.Sleep(20 * time.Millisecond)
+		stopInner()
+		stopOuter()
+
+		layers := timings.Layers()
+		assert.Len(t, layers, 2)
+		assert.Equal(t, "validation", layers[0].Layer)
+		assert.Equal(t, "db", layers[1].Layer)
+		assert.GreaterOrEqual(t, layers[1].Duration, 20*time.Millisecond)
+		assert.Less(t, layers[0].Duration, 20*time.Millisecond)
+	})
+
+	t.Run("Given context without timings, When timing, Then nothing is recorded", func(t *testing.T) {
+		ctx := context.Background()
+
+		layerCtx, stop := user.StartLayerTiming(ctx, "db")
+		stop()
+
+		assert.Equal(t, ctx, layerCtx)
+	})
+}
+
+func TestDetachContext(t *testing.T) {
+	parent, cancel := context.WithCancel(user.WithSessionID(context.Background(), "session-1"))
+	cancel()
+
+	detached, stop := user.DetachContext(parent)
+	defer stop()
+
+	assert.NoError(t, detached.Err(), "detached context must survive the caller's cancellation")
+	assert.Equal(t, "session-1", user.SessionIDFromContext(detached))
+
+	deadline, ok := detached.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(user.SideEffectTimeout), deadline, time.Second)
+}
+
+func TestListFilters_WithDefaults(t *testing.T) {
+	t.Run("Given empty filters, When applying defaults, Then should sort newest first with the default limit", func(t *testing.T) {
+		filters := user.ListFilters{}.WithDefaults()
+
+		assert.Equal(t, user.SortByCreatedAt, filters.SortBy)
+		assert.Equal(t, user.SortDesc, filters.SortOrder)
+		assert.Equal(t, user.DefaultListLimit, filters.Limit)
+	})
+
+	t.Run("Given limit above maximum, When applying defaults, Then should clamp the limit", func(t *testing.T) {
+		filters := user.ListFilters{Limit: 1000, SortBy: user.SortByEmail, SortOrder: user.SortAsc}.WithDefaults()
+
+		assert.Equal(t, user.MaxListLimit, filters.Limit)
+		assert.Equal(t, user.SortByEmail, filters.SortBy)
+		assert.Equal(t, user.SortAsc, filters.SortOrder)
+	})
+}
+
+func TestListFilters_EncryptedFieldsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters user.ListFilters
+		field   string
+	}{
+		{name: "Given a created-at range and sort, When checking, Then should accept it", filters: user.ListFilters{SortBy: user.SortByCreatedAt}},
+		{name: "Given an email prefix, When checking, Then should reject the email", filters: user.ListFilters{EmailPrefix: "jane"}, field: "email_prefix"},
+		{name: "Given a name, When checking, Then should reject the name", filters: user.ListFilters{Name: "Jane"}, field: "name"},
+		{name: "Given a sort by last name, When checking, Then should reject the sort", filters: user.ListFilters{SortBy: user.SortByLastName}, field: "sort_by"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.EncryptedFieldsError()
+
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var userErr user.UserError
+			if assert.ErrorAs(t, err, &userErr) {
+				assert.ErrorIs(t, err, user.ErrInvalidListFilter)
+				assert.Equal(t, tt.field, userErr.Field)
+			}
+		})
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	t.Run("Given encoded cursor, When decoding with the same sort, Then should return the position", func(t *testing.T) {
+		cursor := user.ListCursor{SortBy: user.SortByEmail, SortOrder: user.SortAsc, Value: "jane@example.com", ID: uuid.NewString()}
+
+		decoded, err := user.DecodeListCursor(cursor.Encode(), user.ListFilters{SortBy: user.SortByEmail, SortOrder: user.SortAsc})
+
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("Given cursor issued for another sort, When decoding, Then should reject it", func(t *testing.T) {
+		cursor := user.ListCursor{SortBy: user.SortByEmail, SortOrder: user.SortAsc, Value: "jane@example.com", ID: uuid.NewString()}
+
+		_, err := user.DecodeListCursor(cursor.Encode(), user.ListFilters{})
+
+		assert.ErrorIs(t, err, user.ErrInvalidCursor)
+	})
+
+	t.Run("Given malformed cursor, When decoding, Then should reject it", func(t *testing.T) {
+		_, err := user.DecodeListCursor("not-a-cursor", user.ListFilters{})
+
+		assert.ErrorIs(t, err, user.ErrInvalidCursor)
+	})
+
+	t.Run("Given user sorted by creation time, When reading sort value, Then should use UTC RFC 3339", func(t *testing.T) {
+		created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+		value := user.ListFilters{}.SortValue(&user.User{CreatedAt: created})
+
+		assert.Equal(t, "2024-03-01T11:00:00Z", value)
+	})
+}
+
+func TestIsValidFeatureFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		expected bool
+	}{
+		{name: "Given snake case name, When validating, Then should accept it", flag: "beta_dashboard", expected: true},
+		{name: "Given dotted name with digits, When validating, Then should accept it", flag: "editor.v2-preview", expected: true},
+		{name: "Given empty name, When validating, Then should reject it", flag: "", expected: false},
+		{name: "Given upper case name, When validating, Then should reject it", flag: "BetaDashboard", expected: false},
+		{name: "Given name with spaces, When validating, Then should reject it", flag: "beta dashboard", expected: false},
+		{name: "Given overlong name, When validating, Then should reject it", flag: strings.Repeat("a", user.MaxFeatureFlagLength+1), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, user.IsValidFeatureFlag(tt.flag))
+		})
+	}
+}
+
+func TestFeatureFlags_IsEnabled(t *testing.T) {
+	flags := &user.FeatureFlags{Flags: map[string]bool{"beta_dashboard": true, "new_editor": false}}
+
+	assert.True(t, flags.IsEnabled("beta_dashboard"))
+	assert.False(t, flags.IsEnabled("new_editor"))
+	assert.False(t, flags.IsEnabled("unknown"))
+
+	var missing *user.FeatureFlags
+	assert.False(t, missing.IsEnabled("beta_dashboard"))
+}
@@ -0,0 +1,820 @@
+package usertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// concurrentWriters is how many goroutines race in the concurrency checks
+const concurrentWriters = 8
+
+// RunServiceConformance checks that a user.Service behaves like the storage
+// contract the rest of the chain relies on. newService is called once per
+// subtest; the services may share a database, as every subtest registers
+// users with fresh emails. Storage backends and decorators wrapping one run
+// the same suite so they stay interchangeable.
+func RunServiceConformance(t *testing.T, newService func() user.Service) {
+	t.Helper()
+
+	t.Run("Registration", func(t *testing.T) { testRegistration(t, newService) })
+	t.Run("Preferences", func(t *testing.T) { testPreferences(t, newService) })
+	t.Run("FeatureFlags", func(t *testing.T) { testFeatureFlags(t, newService) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newService) })
+	t.Run("Versions", func(t *testing.T) { testVersions(t, newService) })
+	t.Run("EmailVerification", func(t *testing.T) { testEmailVerification(t, newService) })
+	t.Run("PasswordReset", func(t *testing.T) { testPasswordReset(t, newService) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, newService) })
+}
+
+func testRegistration(t *testing.T, newService func() user.Service) {
+	t.Run("Given a new email, When Register is called, Then should store the user with default preferences", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		email := uniqueEmail()
+
+		// Act
+		registered, err := service.Register(ctx, registerData(email))
+		require.NoError(t, err)
+		found, findErr := service.GetByID(ctx, registered.ID.String())
+		prefs, prefsErr := service.GetPreferences(ctx, registered.ID.String())
+
+		// Assert
+		assert.NotEqual(t, uuid.Nil, registered.ID)
+		assert.NotEqual(t, "Password123!", registered.PasswordHash)
+		require.NoError(t, findErr)
+		assert.Equal(t, email, found.Email)
+		assert.Equal(t, "Jane", found.FirstName)
+		require.NoError(t, prefsErr)
+		assert.Equal(t, registered.ID, prefs.UserID)
+		assert.Equal(t, user.DefaultNotificationTypes(), prefs.NotificationTypes)
+	})
+
+	t.Run("Given a registered email, When Register is called again, Then should return ErrEmailAlreadyExists", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		email := uniqueEmail()
+		_, err := service.Register(ctx, registerData(email))
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.Register(ctx, registerData(email))
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrEmailAlreadyExists)
+	})
+
+	t.Run("Given a preassigned ID, When Register is called, Then should store the user under it", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		data := registerData(uniqueEmail())
+		data.ID = uuid.New()
+
+		// Act
+		registered, err := service.Register(ctx, data)
+		require.NoError(t, err)
+		found, findErr := service.GetByID(ctx, data.ID.String())
+
+		// Assert
+		assert.Equal(t, data.ID, registered.ID)
+		require.NoError(t, findErr)
+		assert.Equal(t, data.Email, found.Email)
+	})
+
+	t.Run("Given another user's email, When UpdateProfile changes to it, Then should return ErrEmailAlreadyExists", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		taken := uniqueEmail()
+		_, err := service.Register(ctx, registerData(taken))
+		require.NoError(t, err)
+		other, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.UpdateProfile(ctx, other.ID.String(), user.UpdateProfileData{Email: &taken})
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrEmailAlreadyExists)
+	})
+
+	t.Run("Given a deleted user, When the email is registered again, Then should create a new user", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		email := uniqueEmail()
+		registered, err := service.Register(ctx, registerData(email))
+		require.NoError(t, err)
+		require.NoError(t, service.Delete(ctx, registered.ID.String()))
+
+		// Act
+		reregistered, err := service.Register(ctx, registerData(email))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEqual(t, registered.ID, reregistered.ID)
+	})
+
+	t.Run("Given concurrent registrations of one email, When they finish, Then should create exactly one user", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		email := uniqueEmail()
+
+		// Act
+		errs := runConcurrently(func(int) error {
+			_, err := service.Register(ctx, registerData(email))
+			return err
+		})
+
+		// Assert
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, user.ErrEmailAlreadyExists)
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+}
+
+func testPreferences(t *testing.T, newService func() user.Service) {
+	t.Run("Given updated preferences, When GetPreferences is called, Then should return every stored field", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+
+		prefs, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		prefs.EmailNotifications = false
+		prefs.PushNotifications = false
+		prefs.SMSNotifications = true
+		prefs.Theme = "dark"
+		prefs.Language = "de"
+		prefs.Timezone = "Europe/Berlin"
+		prefs.DisableNotification("task_assigned")
+		prefs.EnableNotification("system_updates")
+
+		// Act
+		err = service.UpdatePreferences(ctx, userID, *prefs)
+		require.NoError(t, err)
+		stored, err := service.GetPreferences(ctx, userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, stored.UserID)
+		assert.False(t, stored.EmailNotifications)
+		assert.False(t, stored.PushNotifications)
+		assert.True(t, stored.SMSNotifications)
+		assert.Equal(t, "dark", stored.Theme)
+		assert.Equal(t, "de", stored.Language)
+		assert.Equal(t, "Europe/Berlin", stored.Timezone)
+		assert.Equal(t, prefs.NotificationTypes, stored.NotificationTypes)
+	})
+
+	t.Run("Given several users, When UpdatePreferencesBulk is called, Then should store every user's preferences", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		updates := make(map[string]user.UserPreferences)
+		for i := 0; i < 3; i++ {
+			registered, err := service.Register(ctx, registerData(uniqueEmail()))
+			require.NoError(t, err)
+			prefs, err := service.GetPreferences(ctx, registered.ID.String())
+			require.NoError(t, err)
+			prefs.Theme = "dark"
+			updates[registered.ID.String()] = *prefs
+		}
+
+		// Act
+		err := service.UpdatePreferencesBulk(ctx, updates)
+
+		// Assert
+		require.NoError(t, err)
+		for userID := range updates {
+			stored, err := service.GetPreferences(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, "dark", stored.Theme)
+		}
+	})
+
+	t.Run("Given a bulk update including an unknown user, When UpdatePreferencesBulk is called, Then should change nothing", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		prefs, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		prefs.Theme = "dark"
+
+		// Act
+		err = service.UpdatePreferencesBulk(ctx, map[string]user.UserPreferences{
+			userID:              *prefs,
+			uuid.New().String(): *prefs,
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrPreferencesNotFound)
+		stored, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "light", stored.Theme)
+	})
+}
+
+func testFeatureFlags(t *testing.T, newService func() user.Service) {
+	t.Run("Given a new user, When GetFeatureFlags is called, Then should return no flags", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+
+		// Act
+		flags, err := service.GetFeatureFlags(ctx, registered.ID.String())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, flags.UserID)
+		assert.Empty(t, flags.Flags)
+		assert.False(t, flags.IsEnabled("beta_dashboard"))
+	})
+
+	t.Run("Given a flag set twice, When GetFeatureFlags is called, Then should return the last value", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		require.NoError(t, service.SetFeatureFlag(ctx, userID, "beta_dashboard", true))
+		require.NoError(t, service.SetFeatureFlag(ctx, userID, "new_editor", true))
+
+		// Act
+		err = service.SetFeatureFlag(ctx, userID, "new_editor", false)
+		require.NoError(t, err)
+		flags, err := service.GetFeatureFlags(ctx, userID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"beta_dashboard": true, "new_editor": false}, flags.Flags)
+		assert.True(t, flags.IsEnabled("beta_dashboard"))
+		assert.False(t, flags.IsEnabled("new_editor"))
+	})
+}
+
+func testNotFound(t *testing.T, newService func() user.Service) {
+	unknownID := uuid.New().String()
+	firstName := "Nobody"
+
+	tests := []struct {
+		name        string
+		call        func(ctx context.Context, service user.Service) error
+		expectedErr error
+	}{
+		{
+			name: "Given an unknown ID, When GetByID is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				_, err := service.GetByID(ctx, unknownID)
+				return err
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given a malformed ID, When GetByID is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				_, err := service.GetByID(ctx, "not-a-uuid")
+				return err
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given an unknown ID, When UpdateProfile is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				_, err := service.UpdateProfile(ctx, unknownID, user.UpdateProfileData{FirstName: &firstName})
+				return err
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given an unknown ID, When GetPreferences is called, Then should return ErrPreferencesNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				_, err := service.GetPreferences(ctx, unknownID)
+				return err
+			},
+			expectedErr: user.ErrPreferencesNotFound,
+		},
+		{
+			name: "Given an unknown ID, When UpdatePreferences is called, Then should return ErrPreferencesNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				return service.UpdatePreferences(ctx, unknownID, *user.DefaultUserPreferences(uuid.MustParse(unknownID)))
+			},
+			expectedErr: user.ErrPreferencesNotFound,
+		},
+		{
+			name: "Given an unknown ID, When GetFeatureFlags is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				_, err := service.GetFeatureFlags(ctx, unknownID)
+				return err
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given an unknown ID, When SetFeatureFlag is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				return service.SetFeatureFlag(ctx, unknownID, "beta_dashboard", true)
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given an unknown ID, When Deactivate is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				return service.Deactivate(ctx, unknownID)
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+		{
+			name: "Given an unknown ID, When Delete is called, Then should return ErrUserNotFound",
+			call: func(ctx context.Context, service user.Service) error {
+				return service.Delete(ctx, unknownID)
+			},
+			expectedErr: user.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := newService()
+
+			// Act
+			err := tt.call(context.Background(), service)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("Given known and unknown IDs, When GetByIDs is called, Then should return only the known users", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		knownID := registered.ID.String()
+
+		// Act
+		found, err := service.GetByIDs(ctx, []string{knownID, unknownID, "not-a-uuid"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, found, 1)
+		assert.Contains(t, found, knownID)
+	})
+
+	t.Run("Given a deleted user, When GetByID is called, Then should only return the user when deleted users are included", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		require.NoError(t, service.Delete(ctx, userID))
+
+		// Act
+		_, hiddenErr := service.GetByID(ctx, userID)
+		deleted, includedErr := service.GetByID(user.WithIncludeDeleted(ctx), userID)
+
+		// Assert
+		assert.ErrorIs(t, hiddenErr, user.ErrUserNotFound)
+		require.NoError(t, includedErr)
+		assert.True(t, deleted.IsDeleted())
+	})
+}
+
+func testVersions(t *testing.T, newService func() user.Service) {
+	t.Run("Given a registered user, When the profile is updated, Then should increment the version", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		firstName := "Updated"
+
+		// Act
+		updated, err := service.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &firstName, Version: registered.Version})
+		require.NoError(t, err)
+		found, findErr := service.GetByID(ctx, userID)
+
+		// Assert
+		assert.Greater(t, registered.Version, 0)
+		assert.Equal(t, registered.Version+1, updated.Version)
+		require.NoError(t, findErr)
+		assert.Equal(t, updated.Version, found.Version)
+		assert.Equal(t, "Updated", found.FirstName)
+	})
+
+	t.Run("Given a stale version, When UpdateProfile is called, Then should return ErrConflict and keep the profile", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		first, second := "First", "Second"
+		_, err = service.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &first, Version: registered.Version})
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &second, Version: registered.Version})
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrConflict)
+		found, err := service.GetByID(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "First", found.FirstName)
+	})
+
+	t.Run("Given a stale version, When UpdatePreferences is called, Then should return ErrConflict and keep the preferences", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		read, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+
+		first := *read
+		first.Theme = "dark"
+		require.NoError(t, service.UpdatePreferences(ctx, userID, first))
+		stale := *read
+		stale.Theme = "auto"
+
+		// Act
+		err = service.UpdatePreferences(ctx, userID, stale)
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrConflict)
+		stored, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "dark", stored.Theme)
+		assert.Equal(t, read.Version+1, stored.Version)
+	})
+
+	t.Run("Given no version, When UpdatePreferences is called after another update, Then should overwrite the preferences", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		read, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		first := *read
+		first.Theme = "dark"
+		require.NoError(t, service.UpdatePreferences(ctx, userID, first))
+
+		unversioned := *read
+		unversioned.Theme = "auto"
+		unversioned.Version = 0
+
+		// Act
+		err = service.UpdatePreferences(ctx, userID, unversioned)
+
+		// Assert
+		require.NoError(t, err)
+		stored, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "auto", stored.Theme)
+	})
+
+	t.Run("Given a bulk update with a stale version, When UpdatePreferencesBulk is called, Then should return ErrConflict and change nothing", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		updates := make(map[string]user.UserPreferences)
+		var staleUserID string
+		for i := 0; i < 2; i++ {
+			registered, err := service.Register(ctx, registerData(uniqueEmail()))
+			require.NoError(t, err)
+			prefs, err := service.GetPreferences(ctx, registered.ID.String())
+			require.NoError(t, err)
+			prefs.Theme = "dark"
+			updates[registered.ID.String()] = *prefs
+			staleUserID = registered.ID.String()
+		}
+		require.NoError(t, service.UpdatePreferences(ctx, staleUserID, updates[staleUserID]))
+
+		// Act
+		err := service.UpdatePreferencesBulk(ctx, updates)
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrConflict)
+		for userID := range updates {
+			if userID == staleUserID {
+				continue
+			}
+			stored, err := service.GetPreferences(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, "light", stored.Theme)
+		}
+	})
+}
+
+func testConcurrentUpdates(t *testing.T, newService func() user.Service) {
+	t.Run("Given concurrent profile updates, When they finish, Then should store one of the written names", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+
+		// Act
+		errs := runConcurrently(func(i int) error {
+			firstName := fmt.Sprintf("Writer%d", i)
+			_, err := service.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &firstName})
+			return err
+		})
+
+		// Assert
+		for _, err := range errs {
+			assertUpdatedOrConflict(t, err)
+		}
+		stored, err := service.GetByID(ctx, userID)
+		require.NoError(t, err)
+		assert.Regexp(t, `^Writer\d+$`, stored.FirstName)
+	})
+
+	t.Run("Given concurrent profile updates from one read, When they finish, Then should apply exactly one", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+
+		// Act
+		errs := runConcurrently(func(i int) error {
+			firstName := fmt.Sprintf("Writer%d", i)
+			_, err := service.UpdateProfile(ctx, userID, user.UpdateProfileData{FirstName: &firstName, Version: registered.Version})
+			return err
+		})
+
+		// Assert
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, user.ErrConflict)
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("Given concurrent preference updates from one read, When they finish, Then should apply exactly one", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		userID := registered.ID.String()
+		read, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+
+		// Act
+		errs := runConcurrently(func(i int) error {
+			prefs := *read
+			prefs.Theme = fmt.Sprintf("theme-%d", i)
+			prefs.Language = fmt.Sprintf("l%d", i)
+			return service.UpdatePreferences(ctx, userID, prefs)
+		})
+
+		// Assert
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, user.ErrConflict)
+		}
+		assert.Equal(t, 1, succeeded)
+
+		// Fields of different writes are never mixed
+		stored, err := service.GetPreferences(ctx, userID)
+		require.NoError(t, err)
+		var writer int
+		_, err = fmt.Sscanf(stored.Theme, "theme-%d", &writer)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("l%d", writer), stored.Language)
+	})
+}
+
+func testEmailVerification(t *testing.T, newService func() user.Service) {
+	t.Run("Given a new user, When VerifyEmail is called for them, Then should mark the email verified and increment the version", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		require.False(t, registered.IsEmailVerified())
+
+		// Act
+		verified, err := service.VerifyEmail(user.WithEmailVerification(ctx, registered.ID.String()), "token")
+		require.NoError(t, err)
+		again, againErr := service.VerifyEmail(user.WithEmailVerification(ctx, registered.ID.String()), "token")
+		found, findErr := service.GetByID(ctx, registered.ID.String())
+
+		// Assert
+		assert.True(t, verified.IsEmailVerified())
+		assert.Equal(t, registered.Version+1, verified.Version)
+		require.NoError(t, againErr)
+		assert.Equal(t, verified.Version, again.Version, "verifying twice should change nothing")
+		require.NoError(t, findErr)
+		assert.True(t, found.IsEmailVerified())
+	})
+
+	t.Run("Given an address verified elsewhere, When Register is called, Then should store the user verified", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		data := registerData(uniqueEmail())
+		data.EmailVerified = true
+
+		// Act
+		registered, err := service.Register(context.Background(), data)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, registered.IsEmailVerified())
+	})
+
+	t.Run("Given a verified user, When UpdateProfile changes the email, Then should clear the verification", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+		_, err = service.VerifyEmail(user.WithEmailVerification(ctx, registered.ID.String()), "token")
+		require.NoError(t, err)
+		firstName, newEmail := "Janet", uniqueEmail()
+
+		// Act
+		renamed, renameErr := service.UpdateProfile(ctx, registered.ID.String(), user.UpdateProfileData{FirstName: &firstName})
+		moved, moveErr := service.UpdateProfile(ctx, registered.ID.String(), user.UpdateProfileData{Email: &newEmail})
+
+		// Assert
+		require.NoError(t, renameErr)
+		assert.True(t, renamed.IsEmailVerified())
+		require.NoError(t, moveErr)
+		assert.False(t, moved.IsEmailVerified())
+	})
+
+	t.Run("Given no resolved user, When VerifyEmail is called, Then should return ErrInvalidVerifyToken", func(t *testing.T) {
+		// Act
+		_, err := newService().VerifyEmail(context.Background(), "token")
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrInvalidVerifyToken)
+	})
+}
+
+func testPasswordReset(t *testing.T, newService func() user.Service) {
+	t.Run("Given a requested reset, When ResetPassword is called for the user, Then should replace the password once", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		data := registerData(uniqueEmail())
+		registered, err := service.Register(ctx, data)
+		require.NoError(t, err)
+		requested, err := service.RequestPasswordReset(ctx, data.Email)
+		require.NoError(t, err)
+		resetCtx := user.WithPasswordReset(ctx, registered.ID.String())
+
+		// Act
+		err = service.ResetPassword(resetCtx, "token", "NewPassword456!")
+		replayErr := service.ResetPassword(resetCtx, "token", "OtherPassword789!")
+
+		// Assert
+		assert.Equal(t, registered.ID, requested.ID)
+		assert.NotNil(t, requested.PasswordResetRequestedAt)
+		require.NoError(t, err)
+		assert.ErrorIs(t, replayErr, user.ErrInvalidResetToken)
+		_, err = service.Login(ctx, data.Email, "NewPassword456!")
+		assert.NoError(t, err)
+		_, err = service.Login(ctx, data.Email, data.Password)
+		assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+		found, err := service.GetByID(ctx, registered.ID.String())
+		require.NoError(t, err)
+		assert.Nil(t, found.PasswordResetRequestedAt)
+	})
+
+	t.Run("Given an unknown email, When RequestPasswordReset is called, Then should return ErrUserNotFound", func(t *testing.T) {
+		// Act
+		_, err := newService().RequestPasswordReset(context.Background(), uniqueEmail())
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Given no requested reset, When ResetPassword is called, Then should return ErrInvalidResetToken", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		registered, err := service.Register(ctx, registerData(uniqueEmail()))
+		require.NoError(t, err)
+
+		// Act
+		err = service.ResetPassword(user.WithPasswordReset(ctx, registered.ID.String()), "token", "NewPassword456!")
+		unresolvedErr := service.ResetPassword(ctx, "token", "NewPassword456!")
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrInvalidResetToken)
+		assert.ErrorIs(t, unresolvedErr, user.ErrInvalidResetToken)
+	})
+
+	t.Run("Given the current password, When ResetPassword is called, Then should return ErrPasswordReused and keep the request", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		ctx := context.Background()
+		data := registerData(uniqueEmail())
+		registered, err := service.Register(ctx, data)
+		require.NoError(t, err)
+		_, err = service.RequestPasswordReset(ctx, data.Email)
+		require.NoError(t, err)
+
+		// Act
+		err = service.ResetPassword(user.WithPasswordReset(ctx, registered.ID.String()), "token", data.Password)
+
+		// Assert
+		assert.ErrorIs(t, err, user.ErrPasswordReused)
+		found, findErr := service.GetByID(ctx, registered.ID.String())
+		require.NoError(t, findErr)
+		assert.NotNil(t, found.PasswordResetRequestedAt)
+	})
+}
+
+// Helper methods
+
+// uniqueEmail returns an address no other subtest registers
+func uniqueEmail() string {
+	return fmt.Sprintf("conformance-%s@example.com", uuid.NewString())
+}
+
+func registerData(email string) user.RegisterData {
+	return user.RegisterData{
+		Email:     email,
+		Password:  "Password123!",
+		FirstName: "Jane",
+		LastName:  "Doe",
+	}
+}
+
+// runConcurrently starts concurrentWriters calls of fn at once and returns
+// their errors by writer index
+func runConcurrently(fn func(writer int) error) []error {
+	errs := make([]error, concurrentWriters)
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	return errs
+}
+
+// assertUpdatedOrConflict accepts a successful write or one rejected as stale
+func assertUpdatedOrConflict(t *testing.T, err error) {
+	t.Helper()
+	if err != nil && !errors.Is(err, user.ErrConflict) {
+		t.Errorf("expected success or ErrConflict, got %v", err)
+	}
+}
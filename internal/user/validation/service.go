@@ -1,28 +1,54 @@
 package validation
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
 	"github.com/gentra/decorator-arch-go/internal/user"
 	"github.com/gentra/decorator-arch-go/internal/validation"
 )
 
-// service implements user.Service with validation capabilities
+// batchSizeRule bounds the IDs and updates of batch operations
+var batchSizeRule = fmt.Sprintf("max=%d", user.MaxBatchSize)
+
+// service implements user.Service with validation capabilities. With a
+// sanitizer, input is cleaned before its rules run, and the changes made are
+// carried in the context to the layers below, such as audit.
 type service struct {
 	next              user.Service
 	validationService validation.Service
+	sanitizer         sanitize.Service
+	schema            *user.PreferenceSchema
 }
 
 // NewService creates a new validation-enabled user service
 func NewService(next user.Service, validationService validation.Service) user.Service {
+	return NewServiceWithSanitizer(next, validationService, nil)
+}
+
+// NewServiceWithSanitizer creates a new validation-enabled user service
+// sanitizing input first; a nil sanitizer leaves input as it is
+func NewServiceWithSanitizer(next user.Service, validationService validation.Service, sanitizer sanitize.Service) user.Service {
 	return &service{
 		next:              next,
 		validationService: validationService,
+		sanitizer:         sanitizer,
+		schema:            user.DefaultPreferenceSchema(),
 	}
 }
 
 // Register validates registration data before creating a user
 func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.User, error) {
+	ctx, err := s.sanitizeData(ctx, &data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate registration data using the validation domain service
 	if err := s.validationService.ValidateUserRegistration(ctx, data); err != nil {
 		return nil, err
@@ -34,6 +60,8 @@ func (s *service) Register(ctx context.Context, data user.RegisterData) (*user.U
 
 // Login validates login credentials before authentication
 func (s *service) Login(ctx context.Context, email, password string) (*user.AuthResult, error) {
+	ctx, email = s.sanitizeField(ctx, "email", email)
+
 	// Validate email format
 	if err := s.validationService.ValidateEmail(ctx, email); err != nil {
 		return nil, err
@@ -59,6 +87,43 @@ func (s *service) GetByID(ctx context.Context, id string) (*user.User, error) {
 	return s.next.GetByID(ctx, id)
 }
 
+// GetByIDs validates the batch size and every user ID before retrieval
+func (s *service) GetByIDs(ctx context.Context, ids []string) (map[string]*user.User, error) {
+	if err := s.validationService.ValidateField(ctx, "ids", ids, batchSizeRule); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := s.validationService.ValidateUserID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Call next service if validation passes
+	return s.next.GetByIDs(ctx, ids)
+}
+
+// List sanity-checks the filters against their tags before retrieval
+func (s *service) List(ctx context.Context, filters user.ListFilters) (*user.Page, error) {
+	if err := s.validationService.ValidateStruct(ctx, filters); err != nil {
+		return nil, err
+	}
+
+	if filters.CreatedAfter != nil && filters.CreatedBefore != nil && !filters.CreatedAfter.Before(*filters.CreatedBefore) {
+		return nil, validation.ValidationError{
+			Field:   "created_before",
+			Message: "created_before must be after created_after",
+		}
+	}
+	if filters.Cursor != "" {
+		if _, err := user.DecodeListCursor(filters.Cursor, filters); err != nil {
+			return nil, err
+		}
+	}
+
+	// Call next service if validation passes
+	return s.next.List(ctx, filters)
+}
+
 // UpdateProfile validates profile update data before updating
 func (s *service) UpdateProfile(ctx context.Context, id string, data user.UpdateProfileData) (*user.User, error) {
 	// Validate user ID
@@ -66,6 +131,11 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 		return nil, err
 	}
 
+	ctx, err := s.sanitizeData(ctx, &data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate update data
 	if err := s.validationService.ValidateUserUpdate(ctx, data); err != nil {
 		return nil, err
@@ -75,6 +145,136 @@ func (s *service) UpdateProfile(ctx context.Context, id string, data user.Update
 	return s.next.UpdateProfile(ctx, id, data)
 }
 
+// ChangePassword validates the passwords before changing them; the new
+// password must meet the strength rules
+func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	// The current password is only verified, not held to the strength rules
+	if err := s.validationService.ValidateField(ctx, "current_password", currentPassword, "required"); err != nil {
+		return err
+	}
+
+	// Validate new password strength
+	if err := s.validationService.ValidatePassword(ctx, newPassword); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset validates the email before starting a password reset
+func (s *service) RequestPasswordReset(ctx context.Context, email string) (*user.User, error) {
+	ctx, email = s.sanitizeField(ctx, "email", email)
+	if err := s.validationService.ValidateEmail(ctx, email); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.RequestPasswordReset(ctx, email)
+}
+
+// ResetPassword validates the token and the new password's strength
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := s.validationService.ValidateField(ctx, "token", token, "required"); err != nil {
+		return err
+	}
+
+	if err := s.validationService.ValidatePassword(ctx, newPassword); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.ResetPassword(ctx, token, newPassword)
+}
+
+// RequestEmailChange validates the new email before recording it
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	ctx, newEmail = s.sanitizeField(ctx, "email", newEmail)
+
+	// Validate email format
+	if err := s.validationService.ValidateEmail(ctx, newEmail); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.RequestEmailChange(ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange validates the user ID and token before confirming
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, token string) (*user.User, error) {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validationService.ValidateField(ctx, "token", token, "required"); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.ConfirmEmailChange(ctx, userID, token)
+}
+
+// VerifyEmail validates the token before verifying the email
+func (s *service) VerifyEmail(ctx context.Context, token string) (*user.User, error) {
+	if err := s.validationService.ValidateField(ctx, "token", token, "required"); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.VerifyEmail(ctx, token)
+}
+
+// UploadAvatar validates the image type against its content and caps its size
+// at user.MaxAvatarSize before storing it
+func (s *service) UploadAvatar(ctx context.Context, userID string, content io.Reader, contentType string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	if _, ok := user.AvatarContentTypes[contentType]; !ok {
+		return user.ErrUnsupportedAvatar
+	}
+
+	// The declared type must match what the content actually is
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if n == 0 || http.DetectContentType(head[:n]) != contentType {
+		return user.ErrUnsupportedAvatar
+	}
+
+	// The size is only known once the image is read, so the limit is enforced while it is stored
+	content = &avatarSizeLimiter{r: io.MultiReader(bytes.NewReader(head[:n]), content), remaining: user.MaxAvatarSize}
+
+	// Call next service if validation passes
+	return s.next.UploadAvatar(ctx, userID, content, contentType)
+}
+
+// GetAvatarURL validates user ID before returning the avatar link
+func (s *service) GetAvatarURL(ctx context.Context, userID string) (string, error) {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return "", err
+	}
+
+	// Call next service if validation passes
+	return s.next.GetAvatarURL(ctx, userID)
+}
+
 // GetPreferences validates user ID before retrieving preferences
 func (s *service) GetPreferences(ctx context.Context, userID string) (*user.UserPreferences, error) {
 	// Validate user ID
@@ -93,11 +293,173 @@ func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs us
 		return err
 	}
 
+	ctx, err := s.sanitizeData(ctx, &prefs)
+	if err != nil {
+		return err
+	}
+
 	// Validate preferences data
 	if err := s.validationService.ValidateUserPreferences(ctx, prefs); err != nil {
 		return err
 	}
 
+	// Reject notification types no domain has registered
+	if err := s.schema.Validate(prefs); err != nil {
+		return err
+	}
+
 	// Call next service if validation passes
 	return s.next.UpdatePreferences(ctx, userID, prefs)
 }
+
+// UpdatePreferencesBulk validates the batch size and every update before any is applied
+func (s *service) UpdatePreferencesBulk(ctx context.Context, updates map[string]user.UserPreferences) error {
+	if err := s.validationService.ValidateField(ctx, "updates", updates, batchSizeRule); err != nil {
+		return err
+	}
+	sanitized := make(map[string]user.UserPreferences, len(updates))
+	for userID, prefs := range updates {
+		if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+			return err
+		}
+		var err error
+		if ctx, err = s.sanitizeData(ctx, &prefs); err != nil {
+			return err
+		}
+		sanitized[userID] = prefs
+		if err := s.validationService.ValidateUserPreferences(ctx, prefs); err != nil {
+			return err
+		}
+		if err := s.schema.Validate(prefs); err != nil {
+			return err
+		}
+	}
+
+	// Call next service if validation passes
+	return s.next.UpdatePreferencesBulk(ctx, sanitized)
+}
+
+// Deactivate validates the user ID before deactivating
+func (s *service) Deactivate(ctx context.Context, id string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, id); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.Deactivate(ctx, id)
+}
+
+// Delete validates the user ID before deleting
+func (s *service) Delete(ctx context.Context, id string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, id); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.Delete(ctx, id)
+}
+
+// ExportUserData validates the user ID before exporting
+func (s *service) ExportUserData(ctx context.Context, userID string) (*user.DataExport, error) {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.ExportUserData(ctx, userID)
+}
+
+// EraseUser validates the user ID before erasing
+func (s *service) EraseUser(ctx context.Context, userID string) error {
+	// Validate user ID
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	// Call next service if validation passes
+	return s.next.EraseUser(ctx, userID)
+}
+
+// CleanupPreferences validates the cleanup options before running
+func (s *service) CleanupPreferences(ctx context.Context, opts user.PreferenceCleanupOptions) (*user.PreferenceCleanupReport, error) {
+	if err := s.validationService.ValidateField(ctx, "batch_size", opts.BatchSize, "gte=0"); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.CleanupPreferences(ctx, opts)
+}
+
+// GetFeatureFlags validates the user ID before retrieving feature flags
+func (s *service) GetFeatureFlags(ctx context.Context, userID string) (*user.FeatureFlags, error) {
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Call next service if validation passes
+	return s.next.GetFeatureFlags(ctx, userID)
+}
+
+// SetFeatureFlag validates the user ID and flag name before switching the flag
+func (s *service) SetFeatureFlag(ctx context.Context, userID, flag string, enabled bool) error {
+	if err := s.validationService.ValidateUserID(ctx, userID); err != nil {
+		return err
+	}
+	if !user.IsValidFeatureFlag(flag) {
+		return user.ErrInvalidFeatureFlag
+	}
+
+	// Call next service if validation passes
+	return s.next.SetFeatureFlag(ctx, userID, flag, enabled)
+}
+
+// avatarSizeLimiter fails with user.ErrAvatarTooLarge once more than the
+// remaining bytes have been read
+type avatarSizeLimiter struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *avatarSizeLimiter) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, user.ErrAvatarTooLarge
+	}
+
+	// Read one byte past the limit so an image of exactly the limit passes
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, user.ErrAvatarTooLarge
+	}
+	return n, err
+}
+
+// sanitizeData cleans data, a pointer to a struct, in place, returning a
+// context carrying what changed
+func (s *service) sanitizeData(ctx context.Context, data interface{}) (context.Context, error) {
+	if s.sanitizer == nil {
+		return ctx, nil
+	}
+	report, err := s.sanitizer.Sanitize(ctx, data)
+	if err != nil {
+		return ctx, err
+	}
+	return sanitize.WithReport(ctx, report), nil
+}
+
+// sanitizeField cleans a value of the field, returning a context carrying
+// what changed
+func (s *service) sanitizeField(ctx context.Context, field, value string) (context.Context, string) {
+	if s.sanitizer == nil {
+		return ctx, value
+	}
+	value, report := s.sanitizer.SanitizeField(ctx, field, value)
+	return sanitize.WithReport(ctx, report), value
+}
@@ -1,13 +1,19 @@
 package validation_test
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/gentra/decorator-arch-go/internal/sanitize"
+	sanitizeStandard "github.com/gentra/decorator-arch-go/internal/sanitize/standard"
 	"github.com/gentra/decorator-arch-go/internal/user"
 	usermock "github.com/gentra/decorator-arch-go/internal/user/mock"
 	"github.com/gentra/decorator-arch-go/internal/user/validation"
@@ -219,6 +225,9 @@ func TestUserValidationService_Register(t *testing.T) {
 			// Assert
 			if tt.expectedError != nil {
 				assert.Error(t, err)
+				if _, ok := tt.expectedError.(user.UserError); ok {
+					assert.ErrorIs(t, err, tt.expectedError)
+				}
 
 				// Check if it's a validation error with expected fields
 				if len(tt.expectedFieldErrors) > 0 {
@@ -430,7 +439,7 @@ func TestUserValidationService_UpdatePreferences(t *testing.T) {
 				Theme:              "dark",
 				Language:           "en",
 				Timezone:           "UTC",
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"task_assigned":   true,
 					"project_updated": false,
 				},
@@ -509,7 +518,7 @@ func TestUserValidationService_UpdatePreferences(t *testing.T) {
 				Theme:    "light",
 				Language: "en",
 				Timezone: "UTC",
-				NotificationTypes: map[string]bool{
+				NotificationTypes: map[user.NotificationType]bool{
 					"invalid_notification_type": true, // Invalid notification type
 				},
 			},
@@ -517,6 +526,29 @@ func TestUserValidationService_UpdatePreferences(t *testing.T) {
 			expectNextCalled:    false,
 			expectedFieldErrors: []string{"notification_types"},
 		},
+		{
+			name: "Given notification type missing from the schema, When UpdatePreferences is called, Then should return unknown notification error and not call next service",
+			setupMocks: func(mockNext *usermock.MockUserService) {
+				// Next service should not be called
+			},
+			setupValidator: func(mockValidator *usermock.MockValidationService) {
+				validID := "550e8400-e29b-41d4-a716-446655440000"
+				mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+				mockValidator.On("ValidateUserPreferences", mock.Anything, mock.Anything).Return(nil)
+			},
+			userID: "550e8400-e29b-41d4-a716-446655440000",
+			preferences: user.UserPreferences{
+				Theme:    "light",
+				Language: "en",
+				Timezone: "UTC",
+				NotificationTypes: map[user.NotificationType]bool{
+					user.NotificationTaskAssigned: true,
+					"weekly_digest":               true, // Not declared by any domain
+				},
+			},
+			expectedError:    user.ErrUnknownNotification,
+			expectNextCalled: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -566,3 +598,308 @@ func TestUserValidationService_UpdatePreferences(t *testing.T) {
 		})
 	}
 }
+
+func TestUserValidationService_List(t *testing.T) {
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	emailCursor := user.ListCursor{SortBy: user.SortByEmail, SortOrder: user.SortAsc, Value: "a@example.com", ID: uuid.NewString()}.Encode()
+
+	tests := []struct {
+		name             string
+		filters          user.ListFilters
+		fieldError       error
+		expectedError    error
+		expectNextCalled bool
+	}{
+		{
+			name:             "Given valid filters, When List is called, Then should validate and pass to next service",
+			filters:          user.ListFilters{EmailPrefix: "jane", Limit: 10},
+			expectNextCalled: true,
+		},
+		{
+			name:          "Given field rule failure, When List is called, Then should return validation error and not call next service",
+			filters:       user.ListFilters{Limit: 1000},
+			fieldError:    validationDomain.ValidationError{Field: "limit", Message: "too large"},
+			expectedError: validationDomain.ValidationError{Field: "limit", Message: "too large"},
+		},
+		{
+			name:          "Given created range ending before it starts, When List is called, Then should return validation error and not call next service",
+			filters:       user.ListFilters{CreatedAfter: &after, CreatedBefore: &before},
+			expectedError: validationDomain.ValidationError{Field: "created_before", Message: "created_before must be after created_after"},
+		},
+		{
+			name:          "Given cursor issued for another sort, When List is called, Then should return invalid cursor and not call next service",
+			filters:       user.ListFilters{Cursor: emailCursor},
+			expectedError: user.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockNext := &usermock.MockUserService{}
+			mockValidator := &usermock.MockValidationService{}
+			mockValidator.On("ValidateStruct", mock.Anything, tt.filters).Return(tt.fieldError)
+			if tt.expectNextCalled {
+				mockNext.On("List", mock.Anything, tt.filters).Return(&user.Page{}, nil)
+			}
+
+			service := validation.NewService(mockNext, mockValidator)
+
+			_, err := service.List(context.Background(), tt.filters)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				mockNext.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				mockNext.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestUserValidationService_GetByIDs(t *testing.T) {
+	validID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("Given valid IDs, When GetByIDs is called, Then should validate and pass to next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateField", mock.Anything, "ids", []string{validID}, "max=100").Return(nil)
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockNext.On("GetByIDs", mock.Anything, []string{validID}).Return(map[string]*user.User{}, nil)
+
+		_, err := validation.NewService(mockNext, mockValidator).GetByIDs(context.Background(), []string{validID})
+
+		assert.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given one malformed ID, When GetByIDs is called, Then should return validation error and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		invalid := validationDomain.ValidationError{Field: "user_id", Message: "invalid"}
+		mockValidator.On("ValidateField", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateUserID", mock.Anything, "bad").Return(invalid)
+
+		_, err := validation.NewService(mockNext, mockValidator).GetByIDs(context.Background(), []string{validID, "bad"})
+
+		assert.ErrorIs(t, err, invalid)
+		mockNext.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserValidationService_UpdatePreferencesBulk(t *testing.T) {
+	t.Run("Given oversized batch, When UpdatePreferencesBulk is called, Then should return validation error and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		tooMany := validationDomain.ValidationError{Field: "updates", Message: "too many"}
+		mockValidator.On("ValidateField", mock.Anything, "updates", mock.Anything, "max=100").Return(tooMany)
+
+		err := validation.NewService(mockNext, mockValidator).UpdatePreferencesBulk(context.Background(), map[string]user.UserPreferences{})
+
+		assert.ErrorIs(t, err, tooMany)
+		mockNext.AssertNotCalled(t, "UpdatePreferencesBulk", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given update with unknown notification type, When UpdatePreferencesBulk is called, Then should return unknown notification error and not call next service", func(t *testing.T) {
+		validID := "550e8400-e29b-41d4-a716-446655440000"
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateField", mock.Anything, "updates", mock.Anything, "max=100").Return(nil)
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateUserPreferences", mock.Anything, mock.Anything).Return(nil)
+		updates := map[string]user.UserPreferences{
+			validID: {NotificationTypes: map[user.NotificationType]bool{"weekly_digest": true}},
+		}
+
+		err := validation.NewService(mockNext, mockValidator).UpdatePreferencesBulk(context.Background(), updates)
+
+		assert.ErrorIs(t, err, user.ErrUnknownNotification)
+		assert.Contains(t, err.Error(), "weekly_digest")
+		mockNext.AssertNotCalled(t, "UpdatePreferencesBulk", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserValidationService_ChangePassword(t *testing.T) {
+	validID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("Given strong new password, When ChangePassword is called, Then should validate and pass to next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateField", mock.Anything, "current_password", "old-password", "required").Return(nil)
+		mockValidator.On("ValidatePassword", mock.Anything, "N3w-Password!").Return(nil)
+		mockNext.On("ChangePassword", mock.Anything, validID, "old-password", "N3w-Password!").Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).ChangePassword(context.Background(), validID, "old-password", "N3w-Password!")
+
+		assert.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given weak new password, When ChangePassword is called, Then should return validation error and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		weak := validationDomain.ValidationError{Field: "password", Message: "too weak"}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateField", mock.Anything, "current_password", "old-password", "required").Return(nil)
+		mockValidator.On("ValidatePassword", mock.Anything, "short").Return(weak)
+
+		err := validation.NewService(mockNext, mockValidator).ChangePassword(context.Background(), validID, "old-password", "short")
+
+		assert.ErrorIs(t, err, weak)
+		mockNext.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserValidationService_RequestEmailChange(t *testing.T) {
+	validID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("Given malformed email, When RequestEmailChange is called, Then should return validation error and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		invalid := validationDomain.ValidationError{Field: "email", Message: "invalid email"}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateEmail", mock.Anything, "not-an-email").Return(invalid)
+
+		err := validation.NewService(mockNext, mockValidator).RequestEmailChange(context.Background(), validID, "not-an-email")
+
+		assert.ErrorIs(t, err, invalid)
+		mockNext.AssertNotCalled(t, "RequestEmailChange", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserValidationService_UploadAvatar(t *testing.T) {
+	validID := "550e8400-e29b-41d4-a716-446655440000"
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+	t.Run("Given PNG declared as PNG, When UploadAvatar is called, Then should pass the full image to next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		var stored []byte
+		mockNext.On("UploadAvatar", mock.Anything, validID, mock.Anything, "image/png").
+			Run(func(args mock.Arguments) { stored, _ = io.ReadAll(args.Get(2).(io.Reader)) }).
+			Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).UploadAvatar(context.Background(), validID, bytes.NewReader(png), "image/png")
+
+		assert.NoError(t, err)
+		assert.Equal(t, png, stored)
+	})
+
+	t.Run("Given PNG declared as JPEG, When UploadAvatar is called, Then should return unsupported avatar and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).UploadAvatar(context.Background(), validID, bytes.NewReader(png), "image/jpeg")
+
+		assert.ErrorIs(t, err, user.ErrUnsupportedAvatar)
+		mockNext.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given SVG, When UploadAvatar is called, Then should return unsupported avatar", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).UploadAvatar(context.Background(), validID, strings.NewReader("<svg/>"), "image/svg+xml")
+
+		assert.ErrorIs(t, err, user.ErrUnsupportedAvatar)
+	})
+
+	t.Run("Given image over the size limit, When next service reads it, Then should fail with avatar too large", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		var readErr error
+		mockNext.On("UploadAvatar", mock.Anything, validID, mock.Anything, "image/png").
+			Run(func(args mock.Arguments) { _, readErr = io.ReadAll(args.Get(2).(io.Reader)) }).
+			Return(nil)
+
+		oversized := append(png, make([]byte, user.MaxAvatarSize)...)
+		err := validation.NewService(mockNext, mockValidator).UploadAvatar(context.Background(), validID, bytes.NewReader(oversized), "image/png")
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, readErr, user.ErrAvatarTooLarge)
+	})
+}
+
+func TestUserValidationService_SetFeatureFlag(t *testing.T) {
+	validID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("Given a malformed flag name, When SetFeatureFlag is called, Then should return ErrInvalidFeatureFlag and not call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).SetFeatureFlag(context.Background(), validID, "Beta Dashboard", true)
+
+		assert.ErrorIs(t, err, user.ErrInvalidFeatureFlag)
+		mockNext.AssertNotCalled(t, "SetFeatureFlag", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a valid flag name, When SetFeatureFlag is called, Then should call next service", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockNext.On("SetFeatureFlag", mock.Anything, validID, "beta_dashboard", true).Return(nil)
+
+		err := validation.NewService(mockNext, mockValidator).SetFeatureFlag(context.Background(), validID, "beta_dashboard", true)
+
+		assert.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+}
+
+func TestUserValidationService_Sanitization(t *testing.T) {
+	t.Run("Given padded registration data, When Register is called, Then should validate and pass on the sanitized data with its report", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		clean := user.RegisterData{Email: "jane@example.com", Password: " Str0ng!Pass ", FirstName: "Jane", LastName: "Doe"}
+		mockValidator.On("ValidateUserRegistration", mock.Anything, clean).Return(nil)
+		mockNext.On("Register", mock.MatchedBy(func(ctx context.Context) bool {
+			report, ok := sanitize.ReportFromContext(ctx)
+			return ok && len(report.Changes) == 2
+		}), clean).Return(&user.User{Email: clean.Email}, nil)
+		service := validation.NewServiceWithSanitizer(mockNext, mockValidator, sanitizeStandard.NewService(sanitizeStandard.UserFields()))
+
+		_, err := service.Register(context.Background(), user.RegisterData{Email: " jane@example.com", Password: " Str0ng!Pass ", FirstName: " Jane", LastName: "Doe"})
+
+		assert.NoError(t, err)
+		mockValidator.AssertExpectations(t)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a padded email, When Login is called, Then should validate and log in with the sanitized email", func(t *testing.T) {
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		mockValidator.On("ValidateEmail", mock.Anything, "jane@example.com").Return(nil)
+		mockValidator.On("ValidatePassword", mock.Anything, "Str0ng!Pass").Return(nil)
+		mockNext.On("Login", mock.Anything, "jane@example.com", "Str0ng!Pass").Return(&user.AuthResult{}, nil)
+		service := validation.NewServiceWithSanitizer(mockNext, mockValidator, sanitizeStandard.NewService(sanitizeStandard.UserFields()))
+
+		_, err := service.Login(context.Background(), "jane@example.com\n", "Str0ng!Pass")
+
+		assert.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+
+	t.Run("Given a lower-cased time zone, When UpdatePreferences is called, Then should store it canonically", func(t *testing.T) {
+		validID := "550e8400-e29b-41d4-a716-446655440000"
+		mockNext := &usermock.MockUserService{}
+		mockValidator := &usermock.MockValidationService{}
+		clean := user.UserPreferences{Theme: "dark", Language: "en", Timezone: "Europe/Paris"}
+		mockValidator.On("ValidateUserID", mock.Anything, validID).Return(nil)
+		mockValidator.On("ValidateUserPreferences", mock.Anything, clean).Return(nil)
+		mockNext.On("UpdatePreferences", mock.Anything, validID, clean).Return(nil)
+		service := validation.NewServiceWithSanitizer(mockNext, mockValidator, sanitizeStandard.NewService(sanitizeStandard.UserFields()))
+
+		err := service.UpdatePreferences(context.Background(), validID, user.UserPreferences{Theme: "dark", Language: "en", Timezone: " europe/paris"})
+
+		assert.NoError(t, err)
+		mockNext.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,115 @@
+package standard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+)
+
+// OrganizationFunc returns the organization a user belongs to, or "" for none
+type OrganizationFunc func(ctx context.Context, userID string) (string, error)
+
+// Config controls how relationships are resolved
+type Config struct {
+	// OrganizationOf resolves the organization of rendered users; when nil no
+	// viewer is treated as an organization admin
+	OrganizationOf OrganizationFunc
+}
+
+// service implements userview.Service with the standard PII exposure rules:
+//   - public viewers see the ID and a display name of first name and last initial
+//   - organization admins also see full names and a masked email
+//   - the user and platform admins see every field
+type service struct {
+	organizationOf OrganizationFunc
+}
+
+// NewService creates a new user view service with the standard exposure rules
+func NewService(config Config) userview.Service {
+	return &service{
+		organizationOf: config.OrganizationOf,
+	}
+}
+
+// Relationship classifies the viewer, preferring the most privileged match
+func (s *service) Relationship(ctx context.Context, viewer authorization.Subject, target *user.User) (userview.Relationship, error) {
+	switch {
+	case viewer.HasRole(authorization.RoleAdmin):
+		return userview.RelationshipPlatformAdmin, nil
+	case viewer.IsAuthenticated() && viewer.ID == target.ID.String():
+		return userview.RelationshipSelf, nil
+	}
+
+	if s.organizationOf == nil || viewer.OrganizationID == "" || !viewer.HasRole(authorization.RoleOrgAdmin) {
+		return userview.RelationshipPublic, nil
+	}
+
+	organizationID, err := s.organizationOf(ctx, target.ID.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve organization: %w", err)
+	}
+	if organizationID == viewer.OrganizationID {
+		return userview.RelationshipOrgAdmin, nil
+	}
+	return userview.RelationshipPublic, nil
+}
+
+// Render shapes the user according to the viewer's relationship
+func (s *service) Render(ctx context.Context, viewer authorization.Subject, target *user.User) (*userview.View, error) {
+	if target == nil {
+		return nil, nil
+	}
+
+	relationship, err := s.Relationship(ctx, viewer, target)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &userview.View{
+		ID:          target.ID.String(),
+		DisplayName: displayName(target),
+	}
+
+	switch relationship {
+	case userview.RelationshipSelf, userview.RelationshipPlatformAdmin:
+		createdAt, updatedAt := target.CreatedAt, target.UpdatedAt
+		view.Email = target.Email
+		view.PendingEmail = target.PendingEmail
+		view.EmailVerifiedAt = target.EmailVerifiedAt
+		view.FirstName = target.FirstName
+		view.LastName = target.LastName
+		view.CreatedAt = &createdAt
+		view.UpdatedAt = &updatedAt
+	case userview.RelationshipOrgAdmin:
+		createdAt := target.CreatedAt
+		view.Email = maskEmail(target.Email)
+		view.FirstName = target.FirstName
+		view.LastName = target.LastName
+		view.CreatedAt = &createdAt
+	}
+
+	return view, nil
+}
+
+// Helper methods
+
+// displayName returns the first name and last initial, e.g. "Jane D."
+func displayName(u *user.User) string {
+	if u.LastName == "" {
+		return u.FirstName
+	}
+	return strings.TrimSpace(u.FirstName + " " + string([]rune(u.LastName)[0]) + ".")
+}
+
+// maskEmail keeps the first character of the local part and the domain
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return ""
+	}
+	return string([]rune(local)[0]) + "***@" + domain
+}
@@ -0,0 +1,93 @@
+package standard_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/userview"
+	"github.com/gentra/decorator-arch-go/internal/userview/standard"
+)
+
+func organizationOf(organizationID string) standard.OrganizationFunc {
+	return func(context.Context, string) (string, error) {
+		return organizationID, nil
+	}
+}
+
+func TestUserView_Render(t *testing.T) {
+	target := testkit.NewUserBuilder().Build()
+
+	tests := []struct {
+		name         string
+		viewer       authorization.Subject
+		relationship userview.Relationship
+	}{
+		{
+			name:         "Given anonymous viewer, When rendering, Then returns the public shape",
+			viewer:       authorization.Subject{},
+			relationship: userview.RelationshipPublic,
+		},
+		{
+			name:         "Given another user, When rendering, Then returns the public shape",
+			viewer:       authorization.Subject{ID: "user-2", Roles: []string{authorization.RoleUser}},
+			relationship: userview.RelationshipPublic,
+		},
+		{
+			name:         "Given the user, When rendering, Then returns the full shape",
+			viewer:       authorization.Subject{ID: target.ID.String(), Roles: []string{authorization.RoleUser}},
+			relationship: userview.RelationshipSelf,
+		},
+		{
+			name:         "Given admin of the user's organization, When rendering, Then masks the email",
+			viewer:       authorization.Subject{ID: "admin-2", Roles: []string{authorization.RoleOrgAdmin}, OrganizationID: "org-1"},
+			relationship: userview.RelationshipOrgAdmin,
+		},
+		{
+			name:         "Given platform admin, When rendering, Then returns the full shape",
+			viewer:       authorization.Subject{ID: "admin-1", Roles: []string{authorization.RoleAdmin}},
+			relationship: userview.RelationshipPlatformAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := standard.NewService(standard.Config{OrganizationOf: organizationOf("org-1")})
+
+			relationship, err := service.Relationship(context.Background(), tt.viewer, target)
+			require.NoError(t, err)
+			view, err := service.Render(context.Background(), tt.viewer, target)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.relationship, relationship)
+			testkit.AssertGolden(t, "view_"+string(tt.relationship), view)
+		})
+	}
+}
+
+func TestUserView_GivenOrgAdminOfAnotherOrganization_WhenRendering_ThenReturnsPublicShape(t *testing.T) {
+	service := standard.NewService(standard.Config{OrganizationOf: organizationOf("org-2")})
+	viewer := authorization.Subject{ID: "admin-2", Roles: []string{authorization.RoleOrgAdmin}, OrganizationID: "org-1"}
+
+	view, err := service.Render(context.Background(), viewer, testkit.NewUserBuilder().Build())
+
+	require.NoError(t, err)
+	assert.Empty(t, view.Email)
+	assert.Equal(t, "Jane D.", view.DisplayName)
+}
+
+func TestUserView_GivenOrganizationLookupFailure_WhenRendering_ThenReturnsError(t *testing.T) {
+	service := standard.NewService(standard.Config{OrganizationOf: func(context.Context, string) (string, error) {
+		return "", errors.New("directory unavailable")
+	}})
+	viewer := authorization.Subject{ID: "admin-2", Roles: []string{authorization.RoleOrgAdmin}, OrganizationID: "org-1"}
+
+	_, err := service.Render(context.Background(), viewer, testkit.NewUserBuilder().Build())
+
+	assert.ErrorContains(t, err, "directory unavailable")
+}
@@ -0,0 +1,55 @@
+package userview
+
+import (
+	"context"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/authorization"
+	"github.com/gentra/decorator-arch-go/internal/user"
+)
+
+// Service defines the user view domain interface - the ONLY interface in this domain
+// It centralizes which user fields each kind of viewer may see, so delivery
+// layers render users through it instead of deciding on PII exposure themselves
+type Service interface {
+	// Relationship classifies how the viewer relates to the user
+	Relationship(ctx context.Context, viewer authorization.Subject, target *user.User) (Relationship, error)
+
+	// Render returns the representation of the user the viewer is allowed to see
+	Render(ctx context.Context, viewer authorization.Subject, target *user.User) (*View, error)
+}
+
+// Domain types and models
+
+// Relationship is how a viewer relates to the user being rendered
+type Relationship string
+
+// Relationships from least to most privileged
+const (
+	RelationshipPublic        Relationship = "public"
+	RelationshipOrgAdmin      Relationship = "org_admin"
+	RelationshipSelf          Relationship = "self"
+	RelationshipPlatformAdmin Relationship = "platform_admin"
+)
+
+// View is the rendered user. Field names match user.User so every shape is a
+// subset of the full object; fields the viewer may not see are omitted.
+type View struct {
+	ID              string     `json:"id"`
+	DisplayName     string     `json:"display_name"`
+	Email           string     `json:"email,omitempty"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	FirstName       string     `json:"first_name,omitempty"`
+	LastName        string     `json:"last_name,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// AuthResultView is an authentication result with its user rendered
+type AuthResultView struct {
+	User         *View     `json:"user,omitempty"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
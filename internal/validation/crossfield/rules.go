@@ -0,0 +1,134 @@
+package crossfield
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// MaxFullNameLength bounds the first and last names of registrations combined
+const MaxFullNameLength = 100
+
+// minLocalPartLength is the shortest email local part ExcludesLocalPart
+// looks for; shorter ones would match too many passwords by chance
+const minLocalPartLength = 3
+
+// RegistrationRules returns the object-level rules of user registrations:
+// the password must not contain the email's local part, and the first and
+// last names together must fit MaxFullNameLength
+func RegistrationRules() validation.RuleSet {
+	return validation.RuleSet{
+		ExcludesLocalPart("password", "email"),
+		MaxCombinedLength(MaxFullNameLength, "first_name", "last_name"),
+	}
+}
+
+// New creates a rule over the fields at the JSON paths given. valid gets
+// their values in the same order; when it returns false the rule fails with
+// message against every one of the fields. The rule is skipped unless every
+// field is set.
+func New(name, message string, fields []string, valid func(values []interface{}) bool) validation.StructRule {
+	return validation.StructRule{
+		Name: name,
+		Check: func(ctx context.Context, data interface{}) []validation.ValidationError {
+			values, ok := fieldValues(data, fields)
+			if !ok || valid(values) {
+				return nil
+			}
+			failures := make([]validation.ValidationError, len(fields))
+			for i, field := range fields {
+				failures[i] = validation.ValidationError{Field: field, Message: message, Rule: name}
+			}
+			return failures
+		},
+	}
+}
+
+// ExcludesLocalPart creates a rule failing field when its value contains,
+// ignoring case, the local part of the email address in emailField. The
+// failure is reported against field alone, the one to change; its value is
+// left out of it.
+func ExcludesLocalPart(field, emailField string) validation.StructRule {
+	name := "excludes_" + emailField
+	return validation.StructRule{
+		Name: name,
+		Check: func(ctx context.Context, data interface{}) []validation.ValidationError {
+			values, ok := fieldValues(data, []string{field, emailField})
+			if !ok {
+				return nil
+			}
+			value, email := text(values[0]), text(values[1])
+			localPart, _, found := strings.Cut(email, "@")
+			if !found || utf8.RuneCountInString(localPart) < minLocalPartLength {
+				return nil
+			}
+			if !strings.Contains(strings.ToLower(value), strings.ToLower(localPart)) {
+				return nil
+			}
+			return []validation.ValidationError{{
+				Field:   field,
+				Message: fmt.Sprintf("must not contain the %s address", emailField),
+				Rule:    name,
+			}}
+		},
+	}
+}
+
+// MaxCombinedLength creates a rule failing every one of the string fields
+// when their lengths in characters add up to more than max. Fields left
+// unset do not count.
+func MaxCombinedLength(max int, fields ...string) validation.StructRule {
+	name := "max_combined_length"
+	return validation.StructRule{
+		Name: name,
+		Check: func(ctx context.Context, data interface{}) []validation.ValidationError {
+			var set []string
+			length := 0
+			for _, field := range fields {
+				value, ok := validation.FieldValue(data, field)
+				if !ok {
+					continue
+				}
+				length += utf8.RuneCountInString(text(value))
+				set = append(set, field)
+			}
+			if length <= max {
+				return nil
+			}
+
+			message := fmt.Sprintf("%s combined must be no more than %d characters long", strings.Join(fields, " and "), max)
+			failures := make([]validation.ValidationError, len(set))
+			for i, field := range set {
+				failures[i] = validation.ValidationError{Field: field, Message: message, Rule: name}
+			}
+			return failures
+		},
+	}
+}
+
+// fieldValues returns the values of the fields, or false when one is unset
+func fieldValues(data interface{}, fields []string) ([]interface{}, bool) {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value, ok := validation.FieldValue(data, field)
+		if !ok {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+// text returns the string a field holds directly or through a pointer
+func text(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case *string:
+		return *value
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package crossfield_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/crossfield"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
+)
+
+type dateRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func TestExcludesLocalPart_GivenPasswords_WhenChecking_ThenRejectsThoseContainingLocalPart(t *testing.T) {
+	rule := crossfield.ExcludesLocalPart("password", "email")
+
+	tests := []struct {
+		name     string
+		data     interface{}
+		expected []validation.ValidationError
+	}{
+		{
+			name:     "Given a password containing the local part in another case, When checking, Then fails the password",
+			data:     user.RegisterData{Email: "Jane.Doe@example.com", Password: "myjane.doe!1"},
+			expected: []validation.ValidationError{{Field: "password", Message: "must not contain the email address", Rule: "excludes_email"}},
+		},
+		{
+			name: "Given a password without the local part, When checking, Then passes",
+			data: user.RegisterData{Email: "jane@example.com", Password: "Str0ng!Pass"},
+		},
+		{
+			name: "Given a local part too short to matter, When checking, Then passes",
+			data: user.RegisterData{Email: "jd@example.com", Password: "jd-Str0ng!Pass"},
+		},
+		{
+			name: "Given data without the fields, When checking, Then passes",
+			data: dateRange{From: "2024-01-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			failures := rule.Check(context.Background(), tt.data)
+
+			// Assert
+			assert.Equal(t, tt.expected, failures)
+		})
+	}
+}
+
+func TestMaxCombinedLength_GivenUpdateSettingOneName_WhenTooLong_ThenFailsOnlyTheSetField(t *testing.T) {
+	// Arrange
+	rule := crossfield.MaxCombinedLength(10, "first_name", "last_name")
+	firstName := "Bartholomew"
+
+	// Act
+	failures := rule.Check(context.Background(), user.UpdateProfileData{FirstName: &firstName})
+
+	// Assert
+	assert.Equal(t, []validation.ValidationError{
+		{Field: "first_name", Message: "first_name and last_name combined must be no more than 10 characters long", Rule: "max_combined_length"},
+	}, failures)
+}
+
+func TestValidateStruct_GivenRuleSet_WhenFieldAlreadyFailed_ThenReportsOtherFieldsOfTheRule(t *testing.T) {
+	// Arrange
+	service := tagvalidator.NewService()
+	ordered := crossfield.New("date_order", "from must not be after to", []string{"from", "to"}, func(values []interface{}) bool {
+		return values[0].(string) <= values[1].(string)
+	})
+	type booking struct {
+		From string `json:"from" validate:"required,len=10"`
+		To   string `json:"to" validate:"required,len=10"`
+	}
+
+	// Act
+	err := service.ValidateStruct(context.Background(), booking{From: "2024-02-011", To: "2024-01-01"}, validation.RuleSet{ordered})
+
+	// Assert
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	require.Len(t, validationErrors.Errors, 2)
+	assert.Equal(t, "from", validationErrors.Errors[0].Field)
+	assert.Equal(t, "len", validationErrors.Errors[0].Rule)
+	assert.Equal(t, validation.ValidationError{Field: "to", Message: "from must not be after to", Rule: "date_order"}, validationErrors.Errors[1])
+}
+
+func TestValidateStruct_GivenPassingRuleSet_WhenValidating_ThenReturnsNil(t *testing.T) {
+	// Arrange
+	service := tagvalidator.NewService()
+
+	// Act
+	err := service.ValidateStruct(context.Background(), dateRange{From: "2024-01-01", To: "2024-02-01"}, validation.RuleSet{
+		crossfield.New("date_order", "from must not be after to", []string{"from", "to"}, func(values []interface{}) bool {
+			return values[0].(string) <= values[1].(string)
+		}),
+	})
+
+	// Assert
+	assert.NoError(t, err)
+}
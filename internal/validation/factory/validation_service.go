@@ -2,10 +2,17 @@ package factory
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
 	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/fieldrules"
+	"github.com/gentra/decorator-arch-go/internal/validation/memo"
 	"github.com/gentra/decorator-arch-go/internal/validation/standard"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
 	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/ruleset"
 )
 
 // Config contains all configuration for building the validation service
@@ -14,22 +21,30 @@ type Config struct {
 	Provider string // "standard", "custom", "external"
 
 	// Validation engine settings
-	Engine string // "go-playground", "ozzo", "custom"
+	Engine string // "go-playground", "tags", "ozzo", "custom"
 
 	// Validation behavior
 	StrictMode      bool
 	EnableI18n      bool
 	DefaultLanguage string
+	TranslationDir  string // Translations added to the embedded ones, one JSON file per language
 
-	// Custom rules configuration
+	// Custom rules configuration. Rules are usable as tags named after
+	// them. CustomRuleDir holds YAML or JSON rule sets declaring more, which
+	// also apply to the fields they name.
 	CustomRules   map[string]validationrule.Service
 	CustomRuleDir string
 
+	// FieldRules apply custom rules to the fields they name, e.g. remote
+	// checks of the email and password fields
+	FieldRules []fieldrules.Rule
+
 	// External provider settings (for future implementation)
 	ExternalURL    string
 	ExternalAPIKey string
 
-	// Performance settings
+	// Performance settings. CacheRules memoizes the verdicts of user ID and
+	// email checks for CacheTTL, a duration such as "1h".
 	CacheRules    bool
 	CacheTTL      string
 	ParallelMode  bool
@@ -45,6 +60,7 @@ type FeatureFlags struct {
 	EnableCustomProvider       bool
 	EnableExternalProvider     bool
 	EnableGoPlaygroundEngine   bool
+	EnableTagEngine            bool
 	EnableOzzoEngine           bool
 	EnableCustomRules          bool
 	EnableI18nSupport          bool
@@ -65,6 +81,7 @@ func DefaultFeatureFlags() FeatureFlags {
 		EnableCustomProvider:       false,
 		EnableExternalProvider:     false,
 		EnableGoPlaygroundEngine:   true,
+		EnableTagEngine:            false,
 		EnableOzzoEngine:           false,
 		EnableCustomRules:          true,
 		EnableI18nSupport:          false,
@@ -93,17 +110,84 @@ func NewFactory(config Config) *ValidationServiceFactory {
 
 // Build assembles and returns the complete validation service based on configuration
 func (f *ValidationServiceFactory) Build() (validation.Service, error) {
+	var service validation.Service
+	var err error
 	switch f.config.Provider {
 	case "standard":
-		return f.buildStandardService()
+		service, err = f.buildStandardService()
 	case "custom":
-		return f.buildCustomService()
+		service, err = f.buildCustomService()
 	case "external":
-		return f.buildExternalService()
+		service, err = f.buildExternalService()
 	default:
 		// Default to standard provider
-		return f.buildStandardService()
+		service, err = f.buildStandardService()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if service, err = f.applyCaching(service); err != nil {
+		return nil, err
+	}
+	return f.applyCustomRules(service)
+}
+
+// applyCaching memoizes the verdicts of user ID and email checks when rule
+// caching is enabled. Rules bound to fields are applied around the cache, so
+// their own checks are never memoized.
+func (f *ValidationServiceFactory) applyCaching(service validation.Service) (validation.Service, error) {
+	if !f.config.CacheRules || !f.config.Features.EnableRuleCaching {
+		return service, nil
+	}
+
+	var ttl time.Duration
+	if f.config.CacheTTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(f.config.CacheTTL); err != nil {
+			return nil, fmt.Errorf("invalid validation cache TTL %q: %w", f.config.CacheTTL, err)
+		}
+	}
+	return memo.NewService(service, memo.Config{TTL: ttl}), nil
+}
+
+// applyCustomRules registers the custom rules, including those declared in
+// rule sets, and applies declared rules to their fields
+func (f *ValidationServiceFactory) applyCustomRules(service validation.Service) (validation.Service, error) {
+	customRules := make(map[string]validationrule.Service, len(f.config.CustomRules))
+	for name, rule := range f.config.CustomRules {
+		customRules[name] = rule
+	}
+
+	fieldRules := append([]fieldrules.Rule(nil), f.config.FieldRules...)
+	if f.config.CustomRuleDir != "" {
+		configs, err := ruleset.LoadDir(f.config.CustomRuleDir)
+		if err != nil {
+			return nil, err
+		}
+		declared, err := ruleset.Build(configs)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range declared {
+			if _, ok := customRules[rule.Config.Name]; ok {
+				return nil, fmt.Errorf("validation rule %q is declared twice", rule.Config.Name)
+			}
+			customRules[rule.Config.Name] = rule.Rule
+			if rule.Config.Field != "" {
+				fieldRules = append(fieldRules, fieldrules.Rule{Field: rule.Config.Field, Rule: rule.Rule})
+			}
+		}
+	}
+
+	for name, rule := range customRules {
+		if err := service.AddCustomRule(name, rule); err != nil {
+			return nil, err
+		}
+	}
+	if len(fieldRules) == 0 {
+		return service, nil
 	}
+	return fieldrules.NewService(service, fieldRules), nil
 }
 
 // buildStandardService creates a standard validation service
@@ -111,6 +195,12 @@ func (f *ValidationServiceFactory) buildStandardService() (validation.Service, e
 	switch f.config.Engine {
 	case "go-playground":
 		return standard.NewService(), nil
+	case "tags":
+		translations, err := f.buildTranslator()
+		if err != nil {
+			return nil, err
+		}
+		return tagvalidator.NewServiceWithTranslator(translations), nil
 	case "ozzo":
 		return f.buildOzzoService()
 	default:
@@ -119,6 +209,25 @@ func (f *ValidationServiceFactory) buildStandardService() (validation.Service, e
 	}
 }
 
+// buildTranslator creates the translator of the tag engine's messages. With
+// i18n they render in the requester's language, otherwise always in the
+// default language.
+func (f *ValidationServiceFactory) buildTranslator() (translator.Service, error) {
+	config := catalog.Config{DefaultLanguage: f.config.DefaultLanguage}
+	if !f.config.EnableI18n {
+		config.Languages = []string{f.config.DefaultLanguage}
+	}
+	if f.config.TranslationDir != "" {
+		config.Loaders = append(config.Loaders, catalog.DirLoader(f.config.TranslationDir))
+	}
+
+	translations, err := catalog.NewService(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build validation translations: %w", err)
+	}
+	return translations, nil
+}
+
 // buildOzzoService creates an Ozzo validation service (placeholder)
 func (f *ValidationServiceFactory) buildOzzoService() (validation.Service, error) {
 	// TODO: Implement Ozzo validation service
@@ -192,6 +301,13 @@ func (b *ConfigBuilder) WithI18n(enable bool, defaultLanguage string) *ConfigBui
 	return b
 }
 
+// WithTranslations adds the translations in the JSON files of a directory,
+// one per language, to the embedded ones
+func (b *ConfigBuilder) WithTranslations(dir string) *ConfigBuilder {
+	b.config.TranslationDir = dir
+	return b
+}
+
 // WithCustomRule adds a custom validation rule
 func (b *ConfigBuilder) WithCustomRule(name string, rule validationrule.Service) *ConfigBuilder {
 	if b.config.CustomRules == nil {
@@ -202,6 +318,14 @@ func (b *ConfigBuilder) WithCustomRule(name string, rule validationrule.Service)
 	return b
 }
 
+// WithFieldRule adds a custom validation rule and applies it to a field,
+// named by its JSON path
+func (b *ConfigBuilder) WithFieldRule(field, name string, rule validationrule.Service) *ConfigBuilder {
+	b.WithCustomRule(name, rule)
+	b.config.FieldRules = append(b.config.FieldRules, fieldrules.Rule{Field: field, Rule: rule})
+	return b
+}
+
 // WithCustomRuleDir sets the directory for loading custom rules
 func (b *ConfigBuilder) WithCustomRuleDir(dir string) *ConfigBuilder {
 	b.config.CustomRuleDir = dir
@@ -240,6 +364,14 @@ func (b *ConfigBuilder) WithFeatures(features FeatureFlags) *ConfigBuilder {
 	return b
 }
 
+// EnableTagEngine switches to the engine deriving every rule from struct
+// tags, which names fields after their JSON names
+func (b *ConfigBuilder) EnableTagEngine() *ConfigBuilder {
+	b.config.Engine = "tags"
+	b.config.Features.EnableTagEngine = true
+	return b
+}
+
 // EnableOzzoEngine switches to Ozzo validation engine
 func (b *ConfigBuilder) EnableOzzoEngine() *ConfigBuilder {
 	b.config.Engine = "ozzo"
@@ -0,0 +1,136 @@
+package fieldrules
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// Rule binds a validation rule to the field of a JSON name, or a dotted
+// path of JSON names for nested fields
+type Rule struct {
+	Field string
+	Rule  validationrule.Service
+}
+
+// service implements validation.Service interface as a decorator applying
+// rules bound to fields on top of the wrapped service's own checks. A field
+// the wrapped service already rejected is not checked again, and a field is
+// reported with the first of its rules it fails.
+type service struct {
+	validation.Service
+	fields []string // Fields with rules, in the order their first rule was given
+	rules  map[string][]validationrule.Service
+}
+
+// NewService creates a decorator applying the rules to their fields, in the
+// order given
+func NewService(next validation.Service, rules []Rule) validation.Service {
+	s := &service{Service: next, rules: make(map[string][]validationrule.Service)}
+	for _, rule := range rules {
+		if _, ok := s.rules[rule.Field]; !ok {
+			s.fields = append(s.fields, rule.Field)
+		}
+		s.rules[rule.Field] = append(s.rules[rule.Field], rule.Rule)
+	}
+	return s
+}
+
+// ValidateStruct validates the struct and its rule sets, then applies the
+// rules of its fields
+func (s *service) ValidateStruct(ctx context.Context, data interface{}, ruleSets ...validation.RuleSet) error {
+	return s.validateStruct(ctx, data, s.Service.ValidateStruct(ctx, data, ruleSets...))
+}
+
+// ValidateUserRegistration validates registration data, then applies the
+// rules of its fields
+func (s *service) ValidateUserRegistration(ctx context.Context, data interface{}) error {
+	return s.validateStruct(ctx, data, s.Service.ValidateUserRegistration(ctx, data))
+}
+
+// ValidateUserUpdate validates profile updates, then applies the rules of
+// the fields they set
+func (s *service) ValidateUserUpdate(ctx context.Context, data interface{}) error {
+	return s.validateStruct(ctx, data, s.Service.ValidateUserUpdate(ctx, data))
+}
+
+// ValidateUserPreferences validates preferences, then applies the rules of
+// their fields
+func (s *service) ValidateUserPreferences(ctx context.Context, data interface{}) error {
+	return s.validateStruct(ctx, data, s.Service.ValidateUserPreferences(ctx, data))
+}
+
+// ValidateField validates the value, then applies the rules of the field
+func (s *service) ValidateField(ctx context.Context, field string, value interface{}, rules string) error {
+	if err := s.Service.ValidateField(ctx, field, value, rules); err != nil {
+		return err
+	}
+	return s.validateField(ctx, field, value)
+}
+
+// ValidateUserID validates the ID, then applies the rules of user_id
+func (s *service) ValidateUserID(ctx context.Context, id string) error {
+	if err := s.Service.ValidateUserID(ctx, id); err != nil {
+		return err
+	}
+	return s.validateField(ctx, "user_id", id)
+}
+
+// ValidateEmail validates the address, then applies the rules of email
+func (s *service) ValidateEmail(ctx context.Context, email string) error {
+	if err := s.Service.ValidateEmail(ctx, email); err != nil {
+		return err
+	}
+	return s.validateField(ctx, "email", email)
+}
+
+// ValidatePassword validates the password, then applies the rules of
+// password
+func (s *service) ValidatePassword(ctx context.Context, password string) error {
+	if err := s.Service.ValidatePassword(ctx, password); err != nil {
+		return err
+	}
+	return s.validateField(ctx, "password", password)
+}
+
+// validateStruct adds the failures of the rules of data's fields to the
+// wrapped service's result. Fields data does not have, or leaves unset
+// through a nil pointer, are skipped.
+func (s *service) validateStruct(ctx context.Context, data interface{}, err error) error {
+	var validationErrors validation.ValidationErrors
+	if err != nil && !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	for _, field := range s.fields {
+		if validationErrors.HasFieldError(field) {
+			continue
+		}
+		value, ok := validation.FieldValue(data, field)
+		if !ok {
+			continue
+		}
+		var fieldErr validation.ValidationError
+		if errors.As(s.validateField(ctx, field, value), &fieldErr) {
+			validationErrors.Add(fieldErr)
+		}
+	}
+
+	if !validationErrors.HasErrors() {
+		return nil
+	}
+	return validationErrors
+}
+
+// validateField applies the rules of a field to its value, reporting the
+// first it fails
+func (s *service) validateField(ctx context.Context, field string, value interface{}) error {
+	for _, rule := range s.rules[field] {
+		if err := rule.Validate(ctx, value); err != nil {
+			return validation.ValidationError{Field: field, Message: err.Error(), Rule: rule.Name()}
+		}
+	}
+	return nil
+}
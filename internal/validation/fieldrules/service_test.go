@@ -0,0 +1,71 @@
+package fieldrules_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/fieldrules"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/builtin"
+)
+
+func newService(t *testing.T) validation.Service {
+	t.Helper()
+	digitsOnly, err := builtin.NewPatternRule("digits_only", "", `^\d+$`)
+	require.NoError(t, err)
+	return fieldrules.NewService(tagvalidator.NewService(), []fieldrules.Rule{
+		{Field: "first_name", Rule: builtin.NewLengthRule("first_name_length", "", 2, 10)},
+		{Field: "password", Rule: builtin.NewLengthRule("password_length", "", 12, 0)},
+		{Field: "pin", Rule: digitsOnly},
+	})
+}
+
+func TestValidateStruct_GivenFieldRules_WhenValidating_ThenAddsTheirFailuresToTagFailures(t *testing.T) {
+	// Arrange
+	service := newService(t)
+	data := user.RegisterData{Email: "jane@", Password: "Str0ng!Pass", FirstName: "Bartholomew", LastName: "Doe"}
+
+	// Act
+	err := service.ValidateUserRegistration(context.Background(), data)
+
+	// Assert
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	assert.Equal(t, []validation.ValidationError{
+		{Field: "email", Message: "must be a valid email address", Value: "jane@", Rule: "email"},
+		{Field: "first_name", Message: "must be no more than 10 characters long", Rule: "first_name_length"},
+		{Field: "password", Message: "must be at least 12 characters long", Rule: "password_length"},
+	}, validationErrors.Errors)
+}
+
+func TestValidateStruct_GivenFieldsLeftUnset_WhenValidating_ThenSkipsTheirRules(t *testing.T) {
+	// Arrange
+	service := newService(t)
+
+	// Act
+	err := service.ValidateUserUpdate(context.Background(), user.UpdateProfileData{})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestValidateField_GivenFieldRules_WhenValidating_ThenAppliesRulesOfThatField(t *testing.T) {
+	// Arrange
+	service := newService(t)
+	ctx := context.Background()
+
+	// Act
+	pinErr := service.ValidateField(ctx, "pin", "12a4", "required")
+	passwordErr := service.ValidatePassword(ctx, "Str0ng!Pass")
+	otherErr := service.ValidateField(ctx, "nickname", "12a4", "required")
+
+	// Assert
+	assert.Equal(t, validation.ValidationError{Field: "pin", Message: `must match the pattern ^\d+$`, Rule: "digits_only"}, pinErr)
+	assert.Equal(t, validation.ValidationError{Field: "password", Message: "must be at least 12 characters long", Rule: "password_length"}, passwordErr)
+	assert.NoError(t, otherErr)
+}
@@ -0,0 +1,119 @@
+package jsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+)
+
+// ErrInvalidJSON reports a payload that is not a single JSON document
+var ErrInvalidJSON = errors.New("payload is not valid JSON")
+
+// Contracts validates API payloads against named JSON Schemas, one per
+// endpoint. Failures are reported as validation.ValidationErrors whose fields
+// are JSON pointers into the payload, e.g. "/email" or "/items/2/name", with
+// messages in the requester's language. It is safe for concurrent use.
+type Contracts struct {
+	translations translator.Service
+
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewContracts creates contracts without schemas whose messages render
+// through the translator
+func NewContracts(translations translator.Service) *Contracts {
+	return &Contracts{
+		translations: translations,
+		schemas:      make(map[string]*Schema),
+	}
+}
+
+// Register compiles a schema document under a name, replacing any schema of
+// that name
+func (c *Contracts) Register(name string, document []byte) error {
+	schema, err := Compile(document)
+	if err != nil {
+		return fmt.Errorf("schema %q: %w", name, err)
+	}
+	c.mu.Lock()
+	c.schemas[name] = schema
+	c.mu.Unlock()
+	return nil
+}
+
+// LoadFS registers every .json file of a directory, named after the file
+// without its extension
+func (c *Contracts) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read schemas: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		document, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read schema %s: %w", entry.Name(), err)
+		}
+		if err := c.Register(strings.TrimSuffix(entry.Name(), ".json"), document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Has reports whether a schema is registered under the name
+func (c *Contracts) Has(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.schemas[name]
+	return ok
+}
+
+// Validate checks a payload against the named schema. It returns nil for
+// valid payloads, ErrInvalidJSON for payloads that are not JSON and
+// validation.ValidationErrors for payloads breaking the contract.
+func (c *Contracts) Validate(ctx context.Context, name string, payload []byte) error {
+	c.mu.RLock()
+	schema, ok := c.schemas[name]
+	c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no schema registered as %q", name)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return ErrInvalidJSON
+	}
+
+	violations := schema.Validate(document)
+	if len(violations) == 0 {
+		return nil
+	}
+	errs := make([]validation.ValidationError, len(violations))
+	for i, violation := range violations {
+		errs[i] = validation.ValidationError{
+			Field:   violation.Pointer,
+			Message: c.translations.Translate(ctx, violation.Key, violation.Params),
+			Rule:    violation.Keyword,
+		}
+	}
+	return validation.ValidationErrors{Errors: errs}
+}
@@ -0,0 +1,102 @@
+package jsonschema_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/jsonschema"
+)
+
+const signupSchema = `{
+  "type": "object",
+  "required": ["email", "name"],
+  "properties": {
+    "email": {"type": "string", "format": "email"},
+    "name": {"type": "string", "minLength": 2}
+  }
+}`
+
+func newContracts(t *testing.T) *jsonschema.Contracts {
+	t.Helper()
+	contracts := jsonschema.NewContracts(catalog.MustNewService(catalog.Config{}))
+	require.NoError(t, contracts.LoadFS(fstest.MapFS{
+		"schemas/signup.json": {Data: []byte(signupSchema)},
+		"schemas/README.md":   {Data: []byte("not a schema")},
+	}, "schemas"))
+	return contracts
+}
+
+func TestContracts_GivenPayloads_WhenValidating_ThenReturnsTranslatedValidationErrors(t *testing.T) {
+	contracts := newContracts(t)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		payload  string
+		expected error
+	}{
+		{
+			name:    "Given a valid payload, When validating, Then returns nil",
+			ctx:     context.Background(),
+			payload: `{"email":"jane@example.com","name":"Jane"}`,
+		},
+		{
+			name:    "Given an invalid payload, When validating, Then returns errors with JSON pointer fields",
+			ctx:     context.Background(),
+			payload: `{"email":"jane@","name":"J"}`,
+			expected: validation.ValidationErrors{Errors: []validation.ValidationError{
+				{Field: "/email", Message: "must be a valid email address", Rule: "format"},
+				{Field: "/name", Message: "must be at least 2 characters long", Rule: "minLength"},
+			}},
+		},
+		{
+			name:    "Given a Spanish speaking requester, When validating, Then returns Spanish messages",
+			ctx:     translator.WithLanguages(context.Background(), []string{"es"}),
+			payload: `{"email":"jane@example.com"}`,
+			expected: validation.ValidationErrors{Errors: []validation.ValidationError{
+				{Field: "/name", Message: "el campo es obligatorio", Rule: "required"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := contracts.Validate(tt.ctx, "signup", []byte(tt.payload))
+
+			// Assert
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestContracts_GivenMalformedPayload_WhenValidating_ThenReturnsErrInvalidJSON(t *testing.T) {
+	contracts := newContracts(t)
+
+	for _, payload := range []string{`{"email":`, `{} {}`, ``} {
+		// Act
+		err := contracts.Validate(context.Background(), "signup", []byte(payload))
+
+		// Assert
+		assert.ErrorIs(t, err, jsonschema.ErrInvalidJSON, payload)
+	}
+}
+
+func TestContracts_GivenUnknownSchema_WhenValidating_ThenReturnsError(t *testing.T) {
+	contracts := newContracts(t)
+
+	// Act
+	err := contracts.Validate(context.Background(), "missing", []byte(`{}`))
+
+	// Assert
+	require.Error(t, err)
+	assert.False(t, contracts.Has("missing"))
+	assert.True(t, contracts.Has("signup"))
+}
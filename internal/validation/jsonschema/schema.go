@@ -0,0 +1,524 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Schema is a compiled JSON Schema document. It understands the keywords
+// request contracts are written with: type, enum, const, required,
+// properties, additionalProperties, items, minItems, maxItems, minLength,
+// maxLength, pattern, format (email, uuid, date-time, date and uri),
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf
+// and $ref to definitions within the document. Other keywords are ignored,
+// as the specification does for annotations.
+type Schema struct {
+	root *node
+}
+
+// Violation is a value failing a keyword of a schema, located by a JSON
+// pointer into the validated document. Key and Params describe the failure
+// as a translatable message.
+type Violation struct {
+	Pointer string
+	Keyword string
+	Key     string
+	Params  map[string]string
+}
+
+// node is a compiled schema or subschema
+type node struct {
+	boolean *bool // Set for the true and false schemas
+
+	types      []string
+	enum       []interface{}
+	hasConst   bool
+	constValue interface{}
+
+	required   []string
+	properties map[string]*node
+	additional *node // Schema of properties not listed; nil allows any
+
+	items    *node
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+
+	ref      string
+	resolved *node
+}
+
+// Compile parses a JSON Schema document
+func Compile(document []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(document, &raw); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+
+	c := &compiler{document: raw, nodes: make(map[string]*node)}
+	root, err := c.compile(raw, "#")
+	if err != nil {
+		return nil, err
+	}
+	for len(c.pending) > 0 {
+		ref := c.pending[0]
+		c.pending = c.pending[1:]
+		if ref.resolved, err = c.resolve(ref.ref); err != nil {
+			return nil, err
+		}
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics on invalid documents, for schemas
+// embedded in the binary
+func MustCompile(document []byte) *Schema {
+	schema, err := Compile(document)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks a decoded JSON document against the schema. Numbers must be
+// decoded as float64 or json.Number. Each location is reported with the first
+// keyword it fails, in document order.
+func (s *Schema) Validate(document interface{}) []Violation {
+	var violations []Violation
+	s.root.validate(document, "", &violations)
+	return violations
+}
+
+// compiler turns raw schemas into nodes, resolving references once the
+// whole document is compiled
+type compiler struct {
+	document interface{}
+	nodes    map[string]*node // Compiled nodes by their location
+	pending  []*node          // Nodes whose reference is unresolved
+}
+
+func (c *compiler) compile(raw interface{}, location string) (*node, error) {
+	if compiled, ok := c.nodes[location]; ok {
+		return compiled, nil
+	}
+	n := &node{}
+	c.nodes[location] = n
+
+	if boolean, ok := raw.(bool); ok {
+		n.boolean = &boolean
+		return n, nil
+	}
+	keywords, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema at %s must be an object or a boolean", location)
+	}
+
+	var err error
+	if ref, ok := keywords["$ref"].(string); ok {
+		n.ref = ref
+		c.pending = append(c.pending, n)
+	}
+	switch types := keywords["type"].(type) {
+	case string:
+		n.types = []string{types}
+	case []interface{}:
+		for _, t := range types {
+			name, ok := t.(string)
+			if !ok {
+				return nil, fmt.Errorf("type at %s must list type names", location)
+			}
+			n.types = append(n.types, name)
+		}
+	}
+	if enum, ok := keywords["enum"].([]interface{}); ok {
+		n.enum = enum
+	}
+	if constValue, ok := keywords["const"]; ok {
+		n.hasConst, n.constValue = true, constValue
+	}
+
+	if required, ok := keywords["required"].([]interface{}); ok {
+		for _, name := range required {
+			if field, ok := name.(string); ok {
+				n.required = append(n.required, field)
+			}
+		}
+	}
+	if properties, ok := keywords["properties"].(map[string]interface{}); ok {
+		n.properties = make(map[string]*node, len(properties))
+		for name, property := range properties {
+			if n.properties[name], err = c.compile(property, location+"/properties/"+escape(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if additional, ok := keywords["additionalProperties"]; ok {
+		if n.additional, err = c.compile(additional, location+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if items, ok := keywords["items"]; ok {
+		if n.items, err = c.compile(items, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	n.minItems, n.maxItems = count(keywords, "minItems"), count(keywords, "maxItems")
+	n.minLength, n.maxLength = count(keywords, "minLength"), count(keywords, "maxLength")
+	if pattern, ok := keywords["pattern"].(string); ok {
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("pattern at %s is invalid: %w", location, err)
+		}
+	}
+	n.format, _ = keywords["format"].(string)
+
+	n.minimum, n.maximum = number(keywords, "minimum"), number(keywords, "maximum")
+	n.exclusiveMinimum, n.exclusiveMaximum = number(keywords, "exclusiveMinimum"), number(keywords, "exclusiveMaximum")
+
+	for keyword, target := range map[string]*[]*node{"allOf": &n.allOf, "anyOf": &n.anyOf, "oneOf": &n.oneOf} {
+		branches, _ := keywords[keyword].([]interface{})
+		for i, branch := range branches {
+			compiled, err := c.compile(branch, location+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			*target = append(*target, compiled)
+		}
+	}
+	return n, nil
+}
+
+// resolve compiles the schema a reference within the document points to
+func (c *compiler) resolve(ref string) (*node, error) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("reference %q must point within the schema", ref)
+	}
+	raw := c.document
+	if ref != "#" {
+		for _, token := range strings.Split(ref[2:], "/") {
+			object, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("reference %q does not resolve", ref)
+			}
+			if raw, ok = object[unescape(token)]; !ok {
+				return nil, fmt.Errorf("reference %q does not resolve", ref)
+			}
+		}
+	}
+	return c.compile(raw, ref)
+}
+
+// validate adds the violations of value at pointer
+func (n *node) validate(value interface{}, pointer string, violations *[]Violation) {
+	if n.boolean != nil {
+		if !*n.boolean {
+			*violations = append(*violations, Violation{Pointer: pointer, Keyword: "false", Key: "validation.unknown_field"})
+		}
+		return
+	}
+	if n.resolved != nil {
+		before := len(*violations)
+		n.resolved.validate(value, pointer, violations)
+		if len(*violations) > before {
+			return
+		}
+	}
+
+	if violation, ok := n.validateLocal(value, pointer); !ok {
+		*violations = append(*violations, violation)
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		n.validateObject(typed, pointer, violations)
+	case []interface{}:
+		if n.items != nil {
+			for i, item := range typed {
+				n.items.validate(item, pointer+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	}
+
+	for _, branch := range n.allOf {
+		branch.validate(value, pointer, violations)
+	}
+	if len(n.anyOf) > 0 && matching(n.anyOf, value) == 0 {
+		*violations = append(*violations, Violation{Pointer: pointer, Keyword: "anyOf", Key: "validation.schema_mismatch"})
+	}
+	if len(n.oneOf) > 0 && matching(n.oneOf, value) != 1 {
+		*violations = append(*violations, Violation{Pointer: pointer, Keyword: "oneOf", Key: "validation.schema_mismatch"})
+	}
+}
+
+// validateLocal checks the keywords about the value itself, returning the
+// first it fails
+func (n *node) validateLocal(value interface{}, pointer string) (Violation, bool) {
+	fail := func(keyword, key string, params map[string]string) (Violation, bool) {
+		return Violation{Pointer: pointer, Keyword: keyword, Key: key, Params: params}, false
+	}
+
+	if len(n.types) > 0 && !hasType(value, n.types) {
+		return fail("type", "validation.type", map[string]string{"type": strings.Join(n.types, " or ")})
+	}
+	if len(n.enum) > 0 && !contains(n.enum, value) {
+		return fail("enum", "validation.oneof", map[string]string{"values": describe(n.enum)})
+	}
+	if n.hasConst && !equal(n.constValue, value) {
+		return fail("const", "validation.oneof", map[string]string{"values": describe([]interface{}{n.constValue})})
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if n.minLength != nil && length < *n.minLength {
+			return fail("minLength", "validation.min.string", param(*n.minLength))
+		}
+		if n.maxLength != nil && length > *n.maxLength {
+			return fail("maxLength", "validation.max.string", param(*n.maxLength))
+		}
+		if n.pattern != nil && !n.pattern.MatchString(typed) {
+			return fail("pattern", "validation.pattern", map[string]string{"pattern": n.pattern.String()})
+		}
+		if key, params, ok := checkFormat(n.format, typed); !ok {
+			return fail("format", key, params)
+		}
+	case []interface{}:
+		if n.minItems != nil && len(typed) < *n.minItems {
+			return fail("minItems", "validation.min.items", param(*n.minItems))
+		}
+		if n.maxItems != nil && len(typed) > *n.maxItems {
+			return fail("maxItems", "validation.max.items", param(*n.maxItems))
+		}
+	default:
+		f, ok := toFloat(value)
+		if !ok {
+			break
+		}
+		if n.minimum != nil && f < *n.minimum {
+			return fail("minimum", "validation.min.number", numberParam(*n.minimum))
+		}
+		if n.maximum != nil && f > *n.maximum {
+			return fail("maximum", "validation.max.number", numberParam(*n.maximum))
+		}
+		if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+			return fail("exclusiveMinimum", "validation.gt.number", numberParam(*n.exclusiveMinimum))
+		}
+		if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+			return fail("exclusiveMaximum", "validation.lt.number", numberParam(*n.exclusiveMaximum))
+		}
+	}
+	return Violation{}, true
+}
+
+// validateObject checks required, listed and additional properties, in
+// name order
+func (n *node) validateObject(object map[string]interface{}, pointer string, violations *[]Violation) {
+	for _, name := range n.required {
+		if _, ok := object[name]; !ok {
+			*violations = append(*violations, Violation{Pointer: pointer + "/" + escape(name), Keyword: "required", Key: "validation.required"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, listed := n.properties[name]
+		if !listed {
+			property = n.additional
+		}
+		if property != nil {
+			property.validate(object[name], pointer+"/"+escape(name), violations)
+		}
+	}
+}
+
+// matching counts the branches the value satisfies
+func matching(branches []*node, value interface{}) int {
+	matches := 0
+	for _, branch := range branches {
+		var violations []Violation
+		branch.validate(value, "", &violations)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// hasType reports whether a value is of one of the JSON types
+func hasType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(value); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := toFloat(value); ok && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFormat checks text against a format; unknown formats always pass
+func checkFormat(format, text string) (string, map[string]string, bool) {
+	valid := true
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(text)
+		if err != nil || address.Address != text {
+			return "validation.email", nil, false
+		}
+	case "uuid":
+		if _, err := uuid.Parse(text); err != nil || len(text) != 36 {
+			return "validation.uuid", nil, false
+		}
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, text)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, text)
+		valid = err == nil
+	case "uri":
+		parsed, err := url.Parse(text)
+		valid = err == nil && parsed.Scheme != ""
+	}
+	if !valid {
+		return "validation.format", map[string]string{"format": format}, false
+	}
+	return "", nil, true
+}
+
+// contains reports whether a value equals one of the values
+func contains(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equal(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares JSON values, numbers by value whatever their decoding
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// describe lists values for a message, strings unquoted
+func describe(values []interface{}) string {
+	described := make([]string, len(values))
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			described[i] = text
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		described[i] = string(encoded)
+	}
+	return strings.Join(described, ", ")
+}
+
+// toFloat returns the value of a decoded JSON number
+func toFloat(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// count reads a non-negative integer keyword
+func count(keywords map[string]interface{}, keyword string) *int {
+	f, ok := toFloat(keywords[keyword])
+	if !ok || f < 0 {
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+// number reads a numeric keyword
+func number(keywords map[string]interface{}, keyword string) *float64 {
+	f, ok := toFloat(keywords[keyword])
+	if !ok {
+		return nil
+	}
+	return &f
+}
+
+func param(n int) map[string]string {
+	return map[string]string{"param": strconv.Itoa(n)}
+}
+
+func numberParam(f float64) map[string]string {
+	return map[string]string{"param": strconv.FormatFloat(f, 'f', -1, 64)}
+}
+
+// escape encodes a property name as a JSON pointer token
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// unescape decodes a JSON pointer token
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,147 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validation/jsonschema"
+)
+
+const orderSchema = `{
+  "type": "object",
+  "required": ["id", "items"],
+  "additionalProperties": false,
+  "properties": {
+    "id": {"type": "string", "format": "uuid"},
+    "status": {"enum": ["draft", "placed"]},
+    "placed_at": {"type": "string", "format": "date-time"},
+    "items": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/item"}},
+    "a/b": {"type": "boolean"}
+  },
+  "$defs": {
+    "item": {
+      "type": "object",
+      "required": ["sku", "quantity"],
+      "properties": {
+        "sku": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]+$"},
+        "quantity": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100},
+        "note": {"anyOf": [{"type": "string", "maxLength": 5}, {"type": "null"}]}
+      }
+    }
+  }
+}`
+
+func TestValidate_GivenDocuments_WhenValidating_ThenReportsViolationsByPointer(t *testing.T) {
+	schema, err := jsonschema.Compile([]byte(orderSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		document string
+		expected []jsonschema.Violation
+	}{
+		{
+			name:     "Given a valid document, When validating, Then reports nothing",
+			document: `{"id":"8f14e45f-ceea-4e5a-9c4b-1c2d3e4f5a6b","status":"placed","placed_at":"2026-01-02T03:04:05Z","items":[{"sku":"ABC-1","quantity":2,"note":null}]}`,
+		},
+		{
+			name:     "Given missing required properties, When validating, Then reports each at its pointer",
+			document: `{}`,
+			expected: []jsonschema.Violation{
+				{Pointer: "/id", Keyword: "required", Key: "validation.required"},
+				{Pointer: "/items", Keyword: "required", Key: "validation.required"},
+			},
+		},
+		{
+			name:     "Given invalid nested items, When validating, Then points into the array",
+			document: `{"id":"8f14e45f-ceea-4e5a-9c4b-1c2d3e4f5a6b","items":[{"sku":"ABC-1","quantity":1},{"sku":"abc","quantity":1.5,"note":"too long"}]}`,
+			expected: []jsonschema.Violation{
+				{Pointer: "/items/1/note", Keyword: "anyOf", Key: "validation.schema_mismatch"},
+				{Pointer: "/items/1/quantity", Keyword: "type", Key: "validation.type", Params: map[string]string{"type": "integer"}},
+				{Pointer: "/items/1/sku", Keyword: "pattern", Key: "validation.pattern", Params: map[string]string{"pattern": "^[A-Z]{3}-[0-9]+$"}},
+			},
+		},
+		{
+			name:     "Given values out of bounds, When validating, Then reports the bound",
+			document: `{"id":"8f14e45f-ceea-4e5a-9c4b-1c2d3e4f5a6b","items":[{"sku":"ABC-1","quantity":0},{"sku":"ABC-2","quantity":100}]}`,
+			expected: []jsonschema.Violation{
+				{Pointer: "/items/0/quantity", Keyword: "minimum", Key: "validation.min.number", Params: map[string]string{"param": "1"}},
+				{Pointer: "/items/1/quantity", Keyword: "exclusiveMaximum", Key: "validation.lt.number", Params: map[string]string{"param": "100"}},
+			},
+		},
+		{
+			name:     "Given bad formats, enums and unknown properties, When validating, Then reports each",
+			document: `{"id":"not-a-uuid","status":"shipped","placed_at":"yesterday","items":[],"extra":1,"a/b":true}`,
+			expected: []jsonschema.Violation{
+				{Pointer: "/extra", Keyword: "false", Key: "validation.unknown_field"},
+				{Pointer: "/id", Keyword: "format", Key: "validation.uuid"},
+				{Pointer: "/items", Keyword: "minItems", Key: "validation.min.items", Params: map[string]string{"param": "1"}},
+				{Pointer: "/placed_at", Keyword: "format", Key: "validation.format", Params: map[string]string{"format": "date-time"}},
+				{Pointer: "/status", Keyword: "enum", Key: "validation.oneof", Params: map[string]string{"values": "draft, placed"}},
+			},
+		},
+		{
+			name:     "Given a document of the wrong type, When validating, Then reports the root",
+			document: `[]`,
+			expected: []jsonschema.Violation{{Pointer: "", Keyword: "type", Key: "validation.type", Params: map[string]string{"type": "object"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var document interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.document), &document))
+
+			// Act
+			violations := schema.Validate(document)
+
+			// Assert
+			assert.Equal(t, tt.expected, violations)
+		})
+	}
+}
+
+func TestCompile_GivenInvalidSchemas_WhenCompiling_ThenReturnsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "Given malformed JSON, When compiling, Then returns error", schema: `{"type":`},
+		{name: "Given a schema that is not an object, When compiling, Then returns error", schema: `"string"`},
+		{name: "Given an invalid pattern, When compiling, Then returns error", schema: `{"pattern":"("}`},
+		{name: "Given a dangling reference, When compiling, Then returns error", schema: `{"$ref":"#/$defs/missing"}`},
+		{name: "Given a remote reference, When compiling, Then returns error", schema: `{"$ref":"https://example.com/schema.json"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := jsonschema.Compile([]byte(tt.schema))
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompile_GivenRecursiveReference_WhenValidating_ThenFollowsIt(t *testing.T) {
+	// Arrange
+	schema, err := jsonschema.Compile([]byte(`{
+	  "$defs": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}, "name": {"type": "string"}}}},
+	  "$ref": "#/$defs/node"
+	}`))
+	require.NoError(t, err)
+	var document interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"root","children":[{"name":"leaf","children":[{"name":7}]}]}`), &document))
+
+	// Act
+	violations := schema.Validate(document)
+
+	// Assert
+	require.Len(t, violations, 1)
+	assert.Equal(t, "/children/0/children/0/name", violations[0].Pointer)
+}
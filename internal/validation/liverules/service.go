@@ -0,0 +1,117 @@
+package liverules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/fieldrules"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/ruleset"
+)
+
+// Service is a validation.Service decorator applying declared rules that
+// change at runtime, e.g. when administrators edit them. Like rules declared
+// in rule set files, each enabled rule is added to the wrapped service as a
+// custom rule under its name and, when it names a field, applied to that
+// field in priority order. Reload swaps the field rules at once, so a
+// request validates its fields with either the old or the new rules. It is
+// safe for concurrent use.
+type Service struct {
+	next validation.Service
+
+	mu      sync.RWMutex
+	current validation.Service // next with the field rules applied
+	names   []string           // Custom rules added to next
+}
+
+// NewService creates a decorator without rules
+func NewService(next validation.Service) *Service {
+	return &Service{next: next, current: next}
+}
+
+// Reload replaces the rules with the configurations given. Disabled rules
+// are left out. The rules in place are kept when any configuration is
+// invalid.
+func (s *Service) Reload(configs []validationrule.ValidationRuleConfig) error {
+	rules, err := ruleset.Build(configs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range s.names {
+		if err := s.next.RemoveCustomRule(name); err != nil {
+			return fmt.Errorf("failed to remove validation rule %q: %w", name, err)
+		}
+	}
+	s.names = s.names[:0]
+
+	var fieldRules []fieldrules.Rule
+	for _, rule := range rules {
+		if err := s.next.AddCustomRule(rule.Config.Name, rule.Rule); err != nil {
+			return err
+		}
+		s.names = append(s.names, rule.Config.Name)
+		if rule.Config.Field != "" {
+			fieldRules = append(fieldRules, fieldrules.Rule{Field: rule.Config.Field, Rule: rule.Rule})
+		}
+	}
+
+	s.current = s.next
+	if len(fieldRules) > 0 {
+		s.current = fieldrules.NewService(s.next, fieldRules)
+	}
+	return nil
+}
+
+// service returns the wrapped service with the current rules applied
+func (s *Service) service() validation.Service {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+func (s *Service) ValidateStruct(ctx context.Context, data interface{}, ruleSets ...validation.RuleSet) error {
+	return s.service().ValidateStruct(ctx, data, ruleSets...)
+}
+
+func (s *Service) ValidateField(ctx context.Context, field string, value interface{}, rules string) error {
+	return s.service().ValidateField(ctx, field, value, rules)
+}
+
+func (s *Service) ValidateUserRegistration(ctx context.Context, data interface{}) error {
+	return s.service().ValidateUserRegistration(ctx, data)
+}
+
+func (s *Service) ValidateUserUpdate(ctx context.Context, data interface{}) error {
+	return s.service().ValidateUserUpdate(ctx, data)
+}
+
+func (s *Service) ValidateUserPreferences(ctx context.Context, data interface{}) error {
+	return s.service().ValidateUserPreferences(ctx, data)
+}
+
+func (s *Service) ValidateUserID(ctx context.Context, id string) error {
+	return s.service().ValidateUserID(ctx, id)
+}
+
+func (s *Service) ValidateEmail(ctx context.Context, email string) error {
+	return s.service().ValidateEmail(ctx, email)
+}
+
+func (s *Service) ValidatePassword(ctx context.Context, password string) error {
+	return s.service().ValidatePassword(ctx, password)
+}
+
+// AddCustomRule passes through
+func (s *Service) AddCustomRule(name string, rule validationrule.Service) error {
+	return s.next.AddCustomRule(name, rule)
+}
+
+// RemoveCustomRule passes through
+func (s *Service) RemoveCustomRule(name string) error {
+	return s.next.RemoveCustomRule(name)
+}
@@ -0,0 +1,99 @@
+package liverules_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/liverules"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+func lengthRule(name, field string, max int, enabled bool) validationrule.ValidationRuleConfig {
+	config := validationrule.DefaultValidationRuleConfig()
+	config.RuleID, config.Name, config.Field, config.Enabled = name, name, field, enabled
+	config.Type = validationrule.ValidationRuleTypeLength
+	config.Parameters["max"] = max
+	return config
+}
+
+var registration = user.RegisterData{Email: "jane@example.com", Password: "Str0ng!Pass", FirstName: "Bartholomew", LastName: "Doe"}
+
+func TestReload_GivenRuleChanges_WhenValidating_ThenAppliesCurrentRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		configs  []validationrule.ValidationRuleConfig
+		expected []validation.ValidationError
+	}{
+		{
+			name:    "Given an enabled field rule, When validating, Then applies it",
+			configs: []validationrule.ValidationRuleConfig{lengthRule("first_name_length", "first_name", 10, true)},
+			expected: []validation.ValidationError{
+				{Field: "first_name", Message: "must be no more than 10 characters long", Rule: "first_name_length"},
+			},
+		},
+		{
+			name:    "Given the rule disabled, When validating, Then leaves it out",
+			configs: []validationrule.ValidationRuleConfig{lengthRule("first_name_length", "first_name", 10, false)},
+		},
+		{
+			name: "Given no rules, When validating, Then applies only the wrapped checks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := liverules.NewService(tagvalidator.NewService())
+			require.NoError(t, service.Reload([]validationrule.ValidationRuleConfig{lengthRule("first_name_length", "first_name", 5, true)}))
+
+			// Act
+			require.NoError(t, service.Reload(tt.configs))
+			err := service.ValidateUserRegistration(context.Background(), registration)
+
+			// Assert
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErrors validation.ValidationErrors
+			require.ErrorAs(t, err, &validationErrors)
+			assert.Equal(t, tt.expected, validationErrors.Errors)
+		})
+	}
+}
+
+func TestReload_GivenInvalidRules_WhenReloading_ThenKeepsRulesInPlace(t *testing.T) {
+	// Arrange
+	service := liverules.NewService(tagvalidator.NewService())
+	require.NoError(t, service.Reload([]validationrule.ValidationRuleConfig{lengthRule("first_name_length", "first_name", 5, true)}))
+	invalid := lengthRule("last_name_length", "last_name", 5, true)
+	invalid.Parameters["min"] = 10
+
+	// Act
+	err := service.Reload([]validationrule.ValidationRuleConfig{invalid})
+
+	// Assert
+	require.Error(t, err)
+	assert.Error(t, service.ValidateUserRegistration(context.Background(), registration))
+}
+
+func TestReload_GivenRuleWithoutField_WhenValidatingByTag_ThenRuleIsAddedAsCustomRule(t *testing.T) {
+	// Arrange
+	service := liverules.NewService(tagvalidator.NewService())
+	ctx := context.Background()
+	require.NoError(t, service.Reload([]validationrule.ValidationRuleConfig{lengthRule("short_code", "", 3, true)}))
+
+	// Act
+	tooLong := service.ValidateField(ctx, "code", "ABCDE", "short_code")
+	short := service.ValidateField(ctx, "code", "ABC", "short_code")
+
+	// Assert
+	assert.Error(t, tooLong)
+	assert.NoError(t, short)
+}
@@ -0,0 +1,135 @@
+package memo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// DefaultTTL is how long a result is reused when Config.TTL is not set
+const DefaultTTL = time.Hour
+
+// DefaultMaxEntries bounds the cache when Config.MaxEntries is not set
+const DefaultMaxEntries = 10000
+
+// Config holds the lifetime and size of memoized results
+type Config struct {
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// service implements validation.Service interface as a decorator memoizing
+// the results of checks whose only input is an immutable string: user IDs
+// and email addresses, validated on hot paths such as GetByID. Results are
+// kept per language, as messages are localized, and dropped whenever custom
+// rules change. Only verdicts are memoized: a pass or a ValidationError.
+// Passwords are never memoized, so none is kept in memory.
+type service struct {
+	validation.Service
+	config Config
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is a memoized verdict
+type entry struct {
+	err       error
+	expiresAt time.Time
+}
+
+// NewService creates a decorator memoizing the results of next
+func NewService(next validation.Service, config Config) validation.Service {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+	return &service{Service: next, config: config, entries: make(map[string]entry)}
+}
+
+// ValidateUserID validates the ID, reusing the verdict on an identical ID
+func (s *service) ValidateUserID(ctx context.Context, id string) error {
+	return s.memoize(ctx, "user_id", id, func() error {
+		return s.Service.ValidateUserID(ctx, id)
+	})
+}
+
+// ValidateEmail validates the address, reusing the verdict on an identical
+// address
+func (s *service) ValidateEmail(ctx context.Context, email string) error {
+	return s.memoize(ctx, "email", email, func() error {
+		return s.Service.ValidateEmail(ctx, email)
+	})
+}
+
+// AddCustomRule adds the rule and forgets every result, which it may change
+func (s *service) AddCustomRule(name string, rule validationrule.Service) error {
+	defer s.reset()
+	return s.Service.AddCustomRule(name, rule)
+}
+
+// RemoveCustomRule removes the rule and forgets every result, which it may
+// have decided
+func (s *service) RemoveCustomRule(name string) error {
+	defer s.reset()
+	return s.Service.RemoveCustomRule(name)
+}
+
+// memoize returns the verdict remembered for the check of input, or runs
+// validate and remembers its verdict
+func (s *service) memoize(ctx context.Context, check, input string, validate func() error) error {
+	key := check + "\x00" + strings.Join(translator.LanguagesFromContext(ctx), ",") + "\x00" + input
+	now := time.Now()
+
+	s.mu.Lock()
+	if cached, ok := s.entries[key]; ok && now.Before(cached.expiresAt) {
+		s.mu.Unlock()
+		return cached.err
+	}
+	s.mu.Unlock()
+
+	err := validate()
+	var validationErr validation.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.config.MaxEntries {
+		s.evict(now)
+	}
+	s.entries[key] = entry{err: err, expiresAt: now.Add(s.config.TTL)}
+	return err
+}
+
+// evict makes room by dropping expired results and, when none expired, an
+// arbitrary one; the caller holds the lock
+func (s *service) evict(now time.Time) {
+	for key, cached := range s.entries {
+		if !now.Before(cached.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	for key := range s.entries {
+		if len(s.entries) < s.config.MaxEntries {
+			return
+		}
+		delete(s.entries, key)
+	}
+}
+
+// reset forgets every result
+func (s *service) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]entry)
+}
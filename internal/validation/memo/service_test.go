@@ -0,0 +1,103 @@
+package memo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	userMock "github.com/gentra/decorator-arch-go/internal/user/mock"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/memo"
+)
+
+func TestValidateUserID_GivenRepeatedID_WhenValidating_ThenReusesVerdict(t *testing.T) {
+	// Arrange
+	next := &userMock.MockValidationService{}
+	invalid := validation.ValidationError{Field: "user_id", Message: "must be a valid UUID", Value: "nope", Rule: "uuid"}
+	next.On("ValidateUserID", mock.Anything, "nope").Return(invalid).Once()
+	service := memo.NewService(next, memo.Config{})
+	ctx := context.Background()
+
+	// Act
+	firstErr := service.ValidateUserID(ctx, "nope")
+	secondErr := service.ValidateUserID(ctx, "nope")
+
+	// Assert
+	assert.Equal(t, invalid, firstErr)
+	assert.Equal(t, invalid, secondErr)
+	next.AssertExpectations(t)
+}
+
+func TestValidateEmail_GivenAnotherLanguage_WhenValidating_ThenValidatesAgain(t *testing.T) {
+	// Arrange
+	next := &userMock.MockValidationService{}
+	next.On("ValidateEmail", mock.Anything, "jane@").Return(validation.ValidationError{Field: "email", Message: "must be a valid email address"}).Once()
+	next.On("ValidateEmail", mock.Anything, "jane@").Return(validation.ValidationError{Field: "email", Message: "debe ser un correo electrónico válido"}).Once()
+	service := memo.NewService(next, memo.Config{})
+
+	// Act
+	englishErr := service.ValidateEmail(context.Background(), "jane@")
+	spanishErr := service.ValidateEmail(translator.WithLanguages(context.Background(), []string{"es"}), "jane@")
+
+	// Assert
+	assert.EqualError(t, englishErr, "validation error for field 'email': must be a valid email address")
+	assert.EqualError(t, spanishErr, "validation error for field 'email': debe ser un correo electrónico válido")
+	next.AssertExpectations(t)
+}
+
+func TestValidateUserID_GivenFailureOtherThanVerdict_WhenValidating_ThenDoesNotReuseIt(t *testing.T) {
+	// Arrange
+	next := &userMock.MockValidationService{}
+	next.On("ValidateUserID", mock.Anything, "id").Return(errors.New("rule unavailable")).Once()
+	next.On("ValidateUserID", mock.Anything, "id").Return(nil).Once()
+	service := memo.NewService(next, memo.Config{})
+
+	// Act
+	firstErr := service.ValidateUserID(context.Background(), "id")
+	secondErr := service.ValidateUserID(context.Background(), "id")
+
+	// Assert
+	assert.EqualError(t, firstErr, "rule unavailable")
+	assert.NoError(t, secondErr)
+	next.AssertExpectations(t)
+}
+
+func TestAddCustomRule_GivenMemoizedVerdicts_WhenRulesChange_ThenValidatesAgain(t *testing.T) {
+	// Arrange
+	next := &userMock.MockValidationService{}
+	next.On("ValidateEmail", mock.Anything, "jane@example.com").Return(nil).Twice()
+	next.On("RemoveCustomRule", "example_domain").Return(nil)
+	service := memo.NewService(next, memo.Config{})
+	ctx := context.Background()
+
+	// Act
+	firstErr := service.ValidateEmail(ctx, "jane@example.com")
+	removeErr := service.RemoveCustomRule("example_domain")
+	secondErr := service.ValidateEmail(ctx, "jane@example.com")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, removeErr)
+	assert.NoError(t, secondErr)
+	next.AssertExpectations(t)
+}
+
+func TestValidateUserID_GivenFullCache_WhenValidatingNewID_ThenEvictsToStayBounded(t *testing.T) {
+	// Arrange
+	next := &userMock.MockValidationService{}
+	next.On("ValidateUserID", mock.Anything, mock.Anything).Return(nil)
+	service := memo.NewService(next, memo.Config{MaxEntries: 2})
+	ctx := context.Background()
+
+	// Act
+	for _, id := range []string{"a", "b", "c", "a", "b", "c"} {
+		assert.NoError(t, service.ValidateUserID(ctx, id))
+	}
+
+	// Assert
+	assert.Greater(t, len(next.Calls), 3)
+}
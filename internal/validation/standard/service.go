@@ -14,6 +14,12 @@ import (
 	"github.com/gentra/decorator-arch-go/internal/validationrule"
 )
 
+// Patterns are compiled once rather than on every check
+var (
+	emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	namePattern  = regexp.MustCompile(`^[a-zA-Z\s'-]+$`)
+)
+
 // service implements validation.Service interface using go-playground/validator
 type service struct {
 	validator   *validator.Validate
@@ -36,8 +42,13 @@ func NewService() validation.Service {
 	}
 }
 
-// ValidateStruct validates a struct using struct tags
-func (s *service) ValidateStruct(ctx context.Context, data interface{}) error {
+// ValidateStruct validates a struct using struct tags and the rule sets
+func (s *service) ValidateStruct(ctx context.Context, data interface{}, ruleSets ...validation.RuleSet) error {
+	return validation.ApplyRuleSets(ctx, data, s.validateTags(data), ruleSets...)
+}
+
+// validateTags validates a struct using struct tags
+func (s *service) validateTags(data interface{}) error {
 	if err := s.validator.Struct(data); err != nil {
 		// Convert validator errors to our validation errors
 		var validationErrors validation.ValidationErrors
@@ -54,13 +65,28 @@ func (s *service) ValidateStruct(ctx context.Context, data interface{}) error {
 	return nil
 }
 
-// ValidateField validates a single field
+// ValidateField validates a single field. Rules naming a registered custom
+// rule run that rule; the rest are validator tags.
 func (s *service) ValidateField(ctx context.Context, field string, value interface{}, rules string) error {
-	if err := s.validator.Var(value, rules); err != nil {
-		return validation.ValidationError{
-			Field:   field,
-			Message: getErrorMessage(err.(validator.ValidationErrors)[0]),
-			Value:   fmt.Sprintf("%v", value),
+	tags, custom := s.splitRules(rules)
+	if tags != "" {
+		if err := s.validator.Var(value, tags); err != nil {
+			return validation.ValidationError{
+				Field:   field,
+				Message: getErrorMessage(err.(validator.ValidationErrors)[0]),
+				Value:   fmt.Sprintf("%v", value),
+			}
+		}
+	}
+
+	for _, name := range custom {
+		if err := s.customRules[name].Validate(ctx, value); err != nil {
+			return validation.ValidationError{
+				Field:   field,
+				Message: err.Error(),
+				Value:   fmt.Sprintf("%v", value),
+				Rule:    name,
+			}
 		}
 	}
 	return nil
@@ -101,8 +127,7 @@ func (s *service) ValidateUserID(ctx context.Context, id string) error {
 // ValidateEmail validates email format and business rules
 func (s *service) ValidateEmail(ctx context.Context, email string) error {
 	// Basic format validation
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
+	if !emailPattern.MatchString(email) {
 		return validation.ValidationError{
 			Field:   "email",
 			Message: "must be a valid email address",
@@ -212,6 +237,23 @@ func (s *service) RemoveCustomRule(name string) error {
 	return nil
 }
 
+// splitRules separates registered custom rule names from validator tags
+func (s *service) splitRules(rules string) (string, []string) {
+	var tags, custom []string
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if _, ok := s.customRules[rule]; ok {
+			custom = append(custom, rule)
+			continue
+		}
+		tags = append(tags, rule)
+	}
+	return strings.Join(tags, ","), custom
+}
+
 // Custom validation functions for the validator package
 
 func validateStrongPassword(fl validator.FieldLevel) bool {
@@ -241,8 +283,7 @@ func validateStrongPassword(fl validator.FieldLevel) bool {
 
 func validateCleanName(fl validator.FieldLevel) bool {
 	name := fl.Field().String()
-	nameRegex := regexp.MustCompile(`^[a-zA-Z\s'-]+$`)
-	return nameRegex.MatchString(name)
+	return namePattern.MatchString(name)
 }
 
 func validateTheme(fl validator.FieldLevel) bool {
@@ -0,0 +1,323 @@
+package tagvalidator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/crossfield"
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// Password length bounds of the strong_password rule
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+// commonPasswords are rejected by the strong_password rule whatever their
+// character classes
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "abc123",
+	"password123", "admin", "letmein", "welcome",
+}
+
+// service implements validation.Service interface by deriving every rule
+// from struct tags, which go-playground/validator evaluates. Fields are named
+// after their JSON names, so errors point at the request fields clients sent,
+// and values of password, token and secret fields are left out of errors.
+// Messages render in the requester's language through the translator.
+type service struct {
+	validator    *validator.Validate
+	translations translator.Service
+
+	mu          sync.RWMutex
+	customRules map[string]validationrule.Service
+}
+
+// message is a translatable message with its placeholder params
+type message struct {
+	key    string
+	params map[string]string
+}
+
+// NewService creates a new struct tag validation service with English
+// messages. Besides the validator's own tags it understands strong_password
+// and the names of the custom rules added to it.
+func NewService() validation.Service {
+	return NewServiceWithTranslator(catalog.MustNewService(catalog.Config{Languages: []string{translator.DefaultLanguage}}))
+}
+
+// NewServiceWithTranslator creates a new struct tag validation service whose
+// messages render through the translator. Messages of custom rules are
+// reported as the rules return them.
+func NewServiceWithTranslator(translations translator.Service) validation.Service {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonName)
+	v.RegisterValidation("strong_password", func(fl validator.FieldLevel) bool {
+		return len(passwordProblems(fl.Field().String())) == 0
+	})
+
+	return &service{
+		validator:    v,
+		translations: translations,
+		customRules:  make(map[string]validationrule.Service),
+	}
+}
+
+// ValidateStruct validates a struct against its validate tags and the rule
+// sets, reporting every failing field
+func (s *service) ValidateStruct(ctx context.Context, data interface{}, ruleSets ...validation.RuleSet) error {
+	return validation.ApplyRuleSets(ctx, data, s.validateTags(ctx, data), ruleSets...)
+}
+
+// validateTags validates a struct against its validate tags
+func (s *service) validateTags(ctx context.Context, data interface{}) error {
+	err := s.validator.StructCtx(ctx, data)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return fmt.Errorf("failed to validate %T: %w", data, err)
+	}
+	validationErrors := validation.ValidationErrors{Errors: make([]validation.ValidationError, 0, len(fieldErrors))}
+	for _, fieldErr := range fieldErrors {
+		validationErrors.Add(s.toValidationError(ctx, fieldPath(fieldErr), fieldErr))
+	}
+	return validationErrors
+}
+
+// ValidateField validates a single value against comma-separated tags, which
+// may name custom rules, reporting the first rule it fails
+func (s *service) ValidateField(ctx context.Context, field string, value interface{}, rules string) error {
+	err := s.validator.VarCtx(ctx, value, rules)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) || len(fieldErrors) == 0 {
+		return fmt.Errorf("failed to validate %s: %w", field, err)
+	}
+	return s.toValidationError(ctx, field, fieldErrors[0])
+}
+
+// ValidateUserRegistration validates registration data against its tags and
+// the registration rules spanning several fields
+func (s *service) ValidateUserRegistration(ctx context.Context, data interface{}) error {
+	return s.ValidateStruct(ctx, data, crossfield.RegistrationRules())
+}
+
+// ValidateUserUpdate validates profile updates against their tags
+func (s *service) ValidateUserUpdate(ctx context.Context, data interface{}) error {
+	return s.ValidateStruct(ctx, data)
+}
+
+// ValidateUserPreferences validates preferences against their tags
+func (s *service) ValidateUserPreferences(ctx context.Context, data interface{}) error {
+	return s.ValidateStruct(ctx, data)
+}
+
+// ValidateUserID validates a user ID is a UUID
+func (s *service) ValidateUserID(ctx context.Context, id string) error {
+	return s.ValidateField(ctx, "user_id", id, "required,uuid")
+}
+
+// ValidateEmail validates an email address
+func (s *service) ValidateEmail(ctx context.Context, email string) error {
+	return s.ValidateField(ctx, "email", email, "required,email,max=254")
+}
+
+// ValidatePassword validates password strength
+func (s *service) ValidatePassword(ctx context.Context, password string) error {
+	return s.ValidateField(ctx, "password", password, "required,strong_password")
+}
+
+// AddCustomRule makes the rule usable as a tag named name. Rules are meant
+// to be added while the service is set up: tags cannot be unregistered, so
+// tags naming a removed rule fail validation.
+func (s *service) AddCustomRule(name string, rule validationrule.Service) error {
+	err := s.validator.RegisterValidationCtx(name, func(ctx context.Context, fl validator.FieldLevel) bool {
+		rule, ok := s.customRule(name)
+		return ok && rule.Validate(ctx, fl.Field().Interface()) == nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register validation rule %q: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.customRules[name] = rule
+	return nil
+}
+
+// RemoveCustomRule removes a custom validation rule
+func (s *service) RemoveCustomRule(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.customRules, name)
+	return nil
+}
+
+// customRule returns the custom rule named name, if it is still added
+func (s *service) customRule(name string) (validationrule.Service, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.customRules[name]
+	return rule, ok
+}
+
+// toValidationError maps a failed tag to the validation domain's error
+func (s *service) toValidationError(ctx context.Context, field string, fieldErr validator.FieldError) validation.ValidationError {
+	validationErr := validation.ValidationError{
+		Field:   field,
+		Message: s.message(ctx, fieldErr),
+		Rule:    fieldErr.Tag(),
+	}
+	if !isSensitive(field) {
+		validationErr.Value = fmt.Sprintf("%v", fieldErr.Value())
+	}
+	return validationErr
+}
+
+// message describes why a value failed a tag, in the requester's language
+func (s *service) message(ctx context.Context, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return s.translate(ctx, message{key: "validation.required"})
+	case "email":
+		return s.translate(ctx, message{key: "validation.email"})
+	case "uuid", "uuid4":
+		return s.translate(ctx, message{key: "validation.uuid"})
+	case "oneof":
+		values := strings.Join(strings.Fields(fieldErr.Param()), ", ")
+		return s.translate(ctx, message{key: "validation.oneof", params: map[string]string{"values": values}})
+	case "min", "gte":
+		return s.translate(ctx, bound("min", fieldErr))
+	case "max", "lte":
+		return s.translate(ctx, bound("max", fieldErr))
+	case "len", "gt", "lt":
+		return s.translate(ctx, bound(fieldErr.Tag(), fieldErr))
+	case "strong_password":
+		return s.translate(ctx, passwordProblems(fmt.Sprintf("%v", fieldErr.Value()))...)
+	}
+
+	// Custom rules explain their own failures
+	if rule, ok := s.customRule(fieldErr.Tag()); ok {
+		if err := rule.Validate(ctx, fieldErr.Value()); err != nil {
+			return err.Error()
+		}
+	}
+	return s.translate(ctx, message{key: "validation.rule_failed", params: map[string]string{"rule": fieldErr.Tag()}})
+}
+
+// translate renders messages in the requester's language, joined by
+// semicolons
+func (s *service) translate(ctx context.Context, messages ...message) string {
+	rendered := make([]string, 0, len(messages))
+	for _, m := range messages {
+		rendered = append(rendered, s.translations.Translate(ctx, m.key, m.params))
+	}
+	return strings.Join(rendered, "; ")
+}
+
+// bound describes a size bound in the units of the field's kind
+func bound(comparison string, fieldErr validator.FieldError) message {
+	unit := "number"
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = "string"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = "items"
+	}
+	return message{
+		key:    "validation." + comparison + "." + unit,
+		params: map[string]string{"param": fieldErr.Param()},
+	}
+}
+
+// passwordProblems lists what keeps a password from being strong
+func passwordProblems(password string) []message {
+	var problems []message
+	if len(password) < minPasswordLength {
+		problems = append(problems, message{key: "validation.password.too_short", params: map[string]string{"min": strconv.Itoa(minPasswordLength)}})
+	}
+	if len(password) > maxPasswordLength {
+		problems = append(problems, message{key: "validation.password.too_long", params: map[string]string{"max": strconv.Itoa(maxPasswordLength)}})
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+	if !hasLower {
+		problems = append(problems, message{key: "validation.password.lowercase"})
+	}
+	if !hasUpper {
+		problems = append(problems, message{key: "validation.password.uppercase"})
+	}
+	if !hasDigit {
+		problems = append(problems, message{key: "validation.password.digit"})
+	}
+	if !hasSpecial {
+		problems = append(problems, message{key: "validation.password.special"})
+	}
+
+	for _, common := range commonPasswords {
+		if strings.EqualFold(password, common) {
+			problems = append(problems, message{key: "validation.password.common"})
+			break
+		}
+	}
+	return problems
+}
+
+// jsonName names a struct field after its JSON name, or its Go name when it
+// has none
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// fieldPath is the dotted path of a failing field below the validated struct
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+// isSensitive reports whether a field's values must not appear in errors
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	return strings.Contains(field, "password") || strings.Contains(field, "token") || strings.Contains(field, "secret")
+}
@@ -0,0 +1,236 @@
+package tagvalidator_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/translator"
+	"github.com/gentra/decorator-arch-go/internal/translator/catalog"
+	"github.com/gentra/decorator-arch-go/internal/user"
+	"github.com/gentra/decorator-arch-go/internal/validation"
+	"github.com/gentra/decorator-arch-go/internal/validation/tagvalidator"
+)
+
+// domainRule accepts only emails of one domain
+type domainRule struct{}
+
+func (domainRule) Validate(ctx context.Context, value interface{}) error {
+	if email, _ := value.(string); !strings.HasSuffix(email, "@example.com") {
+		return errors.New("must be an example.com address")
+	}
+	return nil
+}
+
+func (domainRule) Name() string        { return "example_domain" }
+func (domainRule) Description() string { return "Accepts example.com addresses only" }
+
+type invitation struct {
+	Email string `json:"email" validate:"required,example_domain"`
+}
+
+func TestValidateStruct_GivenTaggedStruct_WhenFieldsFail_ThenReportsEveryFieldByJSONName(t *testing.T) {
+	// Arrange
+	service := tagvalidator.NewService()
+	data := user.RegisterData{Email: "not-an-email", Password: "short", FirstName: "J"}
+
+	// Act
+	err := service.ValidateStruct(context.Background(), data)
+
+	// Assert
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	assert.Equal(t, []validation.ValidationError{
+		{Field: "email", Message: "must be a valid email address", Value: "not-an-email", Rule: "email"},
+		{Field: "password", Message: "must be at least 8 characters long", Rule: "min"},
+		{Field: "first_name", Message: "must be at least 2 characters long", Value: "J", Rule: "min"},
+		{Field: "last_name", Message: "field is required", Value: "", Rule: "required"},
+	}, validationErrors.Errors)
+}
+
+func TestValidateStruct_GivenListFilters_WhenValidating_ThenAppliesTheirTags(t *testing.T) {
+	tests := []struct {
+		name          string
+		filters       user.ListFilters
+		expectedField string
+		expectedError string
+	}{
+		{
+			name:    "Given valid filters, When validating, Then returns nil",
+			filters: user.ListFilters{EmailPrefix: "jane", SortBy: user.SortByEmail, SortOrder: user.SortAsc, Limit: user.MaxListLimit},
+		},
+		{
+			name:          "Given a limit above the maximum, When validating, Then returns limit error",
+			filters:       user.ListFilters{Limit: user.MaxListLimit + 1},
+			expectedField: "limit",
+			expectedError: "must be no more than 100",
+		},
+		{
+			name:          "Given an unknown sort field, When validating, Then returns sort_by error",
+			filters:       user.ListFilters{SortBy: "password"},
+			expectedField: "sort_by",
+			expectedError: "must be one of: created_at, email, first_name, last_name",
+		},
+		{
+			name:          "Given a negative offset, When validating, Then returns offset error",
+			filters:       user.ListFilters{Offset: -1},
+			expectedField: "offset",
+			expectedError: "must be at least 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := tagvalidator.NewService()
+
+			// Act
+			err := service.ValidateStruct(context.Background(), tt.filters)
+
+			// Assert
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErrors validation.ValidationErrors
+			require.ErrorAs(t, err, &validationErrors)
+			require.Len(t, validationErrors.Errors, 1)
+			assert.Equal(t, tt.expectedField, validationErrors.Errors[0].Field)
+			assert.Equal(t, tt.expectedError, validationErrors.Errors[0].Message)
+		})
+	}
+}
+
+func TestValidateField_GivenRules_WhenValueFails_ThenReturnsFieldError(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(service validation.Service) error
+		expected error
+	}{
+		{
+			name: "Given a valid UUID, When validating user ID, Then returns nil",
+			validate: func(service validation.Service) error {
+				return service.ValidateUserID(context.Background(), "550e8400-e29b-41d4-a716-446655440000")
+			},
+		},
+		{
+			name:     "Given an invalid UUID, When validating user ID, Then returns uuid error",
+			validate: func(service validation.Service) error { return service.ValidateUserID(context.Background(), "user-1") },
+			expected: validation.ValidationError{Field: "user_id", Message: "must be a valid UUID", Value: "user-1", Rule: "uuid"},
+		},
+		{
+			name:     "Given an invalid email, When validating email, Then returns email error",
+			validate: func(service validation.Service) error { return service.ValidateEmail(context.Background(), "jane@") },
+			expected: validation.ValidationError{Field: "email", Message: "must be a valid email address", Value: "jane@", Rule: "email"},
+		},
+		{
+			name: "Given too many items, When validating a batch, Then returns item count error",
+			validate: func(service validation.Service) error {
+				return service.ValidateField(context.Background(), "ids", []string{"a", "b", "c"}, "max=2")
+			},
+			expected: validation.ValidationError{Field: "ids", Message: "must have no more than 2 items", Value: "[a b c]", Rule: "max"},
+		},
+		{
+			name: "Given a weak password, When validating password, Then lists its problems without its value",
+			validate: func(service validation.Service) error {
+				return service.ValidatePassword(context.Background(), "password")
+			},
+			expected: validation.ValidationError{
+				Field:   "password",
+				Message: "must contain at least one uppercase letter; must contain at least one digit; must contain at least one special character; password is too common",
+				Rule:    "strong_password",
+			},
+		},
+		{
+			name: "Given a strong password, When validating password, Then returns nil",
+			validate: func(service validation.Service) error {
+				return service.ValidatePassword(context.Background(), "Str0ng!Pass")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.validate(tagvalidator.NewService())
+
+			// Assert
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestCustomRule_GivenAddedRule_WhenUsedAsTag_ThenReportsItsMessageUntilRemoved(t *testing.T) {
+	// Arrange
+	service := tagvalidator.NewService()
+	require.NoError(t, service.AddCustomRule("example_domain", domainRule{}))
+	ctx := context.Background()
+
+	// Act
+	validErr := service.ValidateStruct(ctx, invitation{Email: "jane@example.com"})
+	invalidErr := service.ValidateStruct(ctx, invitation{Email: "jane@other.com"})
+	fieldErr := service.ValidateField(ctx, "email", "jane@other.com", "required,example_domain")
+	require.NoError(t, service.RemoveCustomRule("example_domain"))
+	removedErr := service.ValidateStruct(ctx, invitation{Email: "jane@example.com"})
+
+	// Assert
+	assert.NoError(t, validErr)
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, invalidErr, &validationErrors)
+	assert.Equal(t, "must be an example.com address", validationErrors.Errors[0].Message)
+	assert.Equal(t, "example_domain", validationErrors.Errors[0].Rule)
+	assert.Equal(t, validation.ValidationError{Field: "email", Message: "must be an example.com address", Value: "jane@other.com", Rule: "example_domain"}, fieldErr)
+	assert.Error(t, removedErr)
+}
+
+func TestValidateStruct_GivenRequesterLanguage_WhenFieldsFail_ThenRendersMessagesInIt(t *testing.T) {
+	// Arrange
+	translations, err := catalog.NewService(catalog.Config{})
+	require.NoError(t, err)
+	service := tagvalidator.NewServiceWithTranslator(translations)
+	ctx := translator.WithLanguages(context.Background(), []string{"es-AR", "en"})
+
+	// Act
+	structErr := service.ValidateStruct(ctx, user.RegisterData{Email: "jane@", Password: "Str0ng!Pass", FirstName: "Jane"})
+	passwordErr := service.ValidatePassword(ctx, "password")
+
+	// Assert
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, structErr, &validationErrors)
+	assert.Equal(t, []validation.ValidationError{
+		{Field: "email", Message: "debe ser una dirección de correo electrónico válida", Value: "jane@", Rule: "email"},
+		{Field: "last_name", Message: "el campo es obligatorio", Value: "", Rule: "required"},
+	}, validationErrors.Errors)
+	assert.Equal(t, validation.ValidationError{
+		Field:   "password",
+		Message: "debe contener al menos una letra mayúscula; debe contener al menos un dígito; debe contener al menos un carácter especial; la contraseña es demasiado común",
+		Rule:    "strong_password",
+	}, passwordErr)
+}
+
+func TestValidateUserRegistration_GivenFieldsFailingTogether_WhenValidating_ThenReportsEachField(t *testing.T) {
+	// Arrange
+	service := tagvalidator.NewService()
+	data := user.RegisterData{
+		Email:     "jane.doe@example.com",
+		Password:  "Jane.Doe-2024!",
+		FirstName: strings.Repeat("a", 60),
+		LastName:  strings.Repeat("b", 60),
+	}
+
+	// Act
+	err := service.ValidateUserRegistration(context.Background(), data)
+
+	// Assert
+	var validationErrors validation.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	assert.Equal(t, []validation.ValidationError{
+		{Field: "password", Message: "must not contain the email address", Rule: "excludes_email"},
+		{Field: "first_name", Message: "first_name and last_name combined must be no more than 100 characters long", Rule: "max_combined_length"},
+		{Field: "last_name", Message: "first_name and last_name combined must be no more than 100 characters long", Rule: "max_combined_length"},
+	}, validationErrors.Errors)
+}
@@ -2,7 +2,9 @@ package validation
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/gentra/decorator-arch-go/internal/validationrule"
@@ -10,8 +12,9 @@ import (
 
 // Service defines the validation domain interface - the ONLY interface in this domain
 type Service interface {
-	// General validation operations
-	ValidateStruct(ctx context.Context, data interface{}) error
+	// General validation operations. ValidateStruct also checks the struct
+	// against the object-level rules of the rule sets given.
+	ValidateStruct(ctx context.Context, data interface{}, ruleSets ...RuleSet) error
 	ValidateField(ctx context.Context, field string, value interface{}, rules string) error
 
 	// User domain specific validations
@@ -59,6 +62,18 @@ func (e ValidationErrors) Error() string {
 	return strings.Join(messages, "; ")
 }
 
+// StructRule is an object-level rule inspecting several fields of a struct
+// at once, e.g. that a password does not contain the email's local part.
+// Check returns its failures, each against one of the fields it concerns, so
+// a rule failing over two fields reports both.
+type StructRule struct {
+	Name  string
+	Check func(ctx context.Context, data interface{}) []ValidationError
+}
+
+// RuleSet is a group of object-level rules validated together
+type RuleSet []StructRule
+
 // ValidationResult contains the result of a validation operation
 type ValidationResult struct {
 	Valid  bool              `json:"valid"`
@@ -125,6 +140,104 @@ func (e *ValidationErrors) GetFieldErrors(field string) []ValidationError {
 	return fieldErrors
 }
 
+// ApplyRuleSets adds the failures of the rule sets' rules to err, the result
+// of validating data's fields, and returns the combined ValidationErrors or
+// nil. Failures of fields err already reports are left out. err is returned
+// as is when it is not a ValidationErrors.
+func ApplyRuleSets(ctx context.Context, data interface{}, err error, ruleSets ...RuleSet) error {
+	if len(ruleSets) == 0 {
+		return err
+	}
+
+	var validationErrors ValidationErrors
+	if err != nil && !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	failed := make(map[string]bool, len(validationErrors.Errors))
+	for _, fieldErr := range validationErrors.Errors {
+		failed[fieldErr.Field] = true
+	}
+	for _, ruleSet := range ruleSets {
+		for _, rule := range ruleSet {
+			for _, ruleErr := range rule.Check(ctx, data) {
+				if failed[ruleErr.Field] {
+					continue
+				}
+				if ruleErr.Rule == "" {
+					ruleErr.Rule = rule.Name
+				}
+				validationErrors.Add(ruleErr)
+			}
+		}
+	}
+
+	if !validationErrors.HasErrors() {
+		return nil
+	}
+	return validationErrors
+}
+
+// FieldValue finds the value of the field at a dotted path of JSON names in
+// structs and string-keyed maps. Fields that do not exist or are left unset
+// through a nil pointer are not found.
+func FieldValue(data interface{}, path string) (interface{}, bool) {
+	current := reflect.ValueOf(data)
+	for _, name := range strings.Split(path, ".") {
+		for current.Kind() == reflect.Pointer || current.Kind() == reflect.Interface {
+			if current.IsNil() {
+				return nil, false
+			}
+			current = current.Elem()
+		}
+
+		switch current.Kind() {
+		case reflect.Struct:
+			field, ok := structField(current, name)
+			if !ok {
+				return nil, false
+			}
+			current = field
+		case reflect.Map:
+			if current.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			current = current.MapIndex(reflect.ValueOf(name).Convert(current.Type().Key()))
+			if !current.IsValid() {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	if (current.Kind() == reflect.Pointer || current.Kind() == reflect.Interface) && current.IsNil() {
+		return nil, false
+	}
+	if !current.CanInterface() {
+		return nil, false
+	}
+	return current.Interface(), true
+}
+
+// structField returns the exported field of a struct named name in JSON
+func structField(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if jsonName == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
 // Helper methods for ValidationResult
 func (r *ValidationResult) IsValid() bool {
 	return r.Valid && len(r.Errors) == 0
@@ -0,0 +1,371 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// phonePattern matches E.164 phone numbers, with or without the leading plus
+var phonePattern = regexp.MustCompile(`^\+?[1-9]\d{6,14}$`)
+
+// rule holds what every built-in rule shares: its identity and the message
+// replacing its own when it fails
+type rule struct {
+	id          string
+	name        string
+	description string
+	message     string
+}
+
+func (r rule) Name() string        { return r.name }
+func (r rule) Description() string { return r.description }
+
+// fail reports a failed value, with the configured message if there is one
+func (r rule) fail(format string, args ...interface{}) error {
+	message := r.message
+	if message == "" {
+		message = fmt.Sprintf(format, args...)
+	}
+	return validationrule.ValidationRuleError{Code: validationrule.ErrInvalidValue.Code, Message: message, RuleID: r.id}
+}
+
+// NewRule builds the rule a configuration declares. Custom and conditional
+// rules need code and cannot be declared.
+func NewRule(config validationrule.ValidationRuleConfig) (validationrule.Service, error) {
+	if config.Name == "" {
+		return nil, invalidConfig(config, "rule has no name")
+	}
+	base := rule{id: config.RuleID, name: config.Name, description: config.Description, message: config.Message}
+	params := parameters(config.Parameters)
+
+	switch config.Type {
+	case validationrule.ValidationRuleTypeRequired:
+		return requiredRule{rule: base}, nil
+	case validationrule.ValidationRuleTypeLength:
+		min, err := params.whole("min")
+		if err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		max, err := params.whole("max")
+		if err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		if max > 0 && max < min {
+			return nil, invalidConfig(config, "max is below min")
+		}
+		return lengthRule{rule: base, min: min, max: max}, nil
+	case validationrule.ValidationRuleTypeRange:
+		min, hasMin, err := params.number("min")
+		if err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		max, hasMax, err := params.number("max")
+		if err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		if hasMin && hasMax && max < min {
+			return nil, invalidConfig(config, "max is below min")
+		}
+		return rangeRule{rule: base, min: min, max: max, hasMin: hasMin, hasMax: hasMax}, nil
+	case validationrule.ValidationRuleTypePattern:
+		pattern, _ := params.text("pattern")
+		compiled, err := regexp.Compile(pattern)
+		if err != nil || pattern == "" {
+			return nil, invalidConfig(config, "pattern must be a regular expression")
+		}
+		return patternRule{rule: base, pattern: compiled}, nil
+	case validationrule.ValidationRuleTypeFormat:
+		format, _ := params.text("format")
+		switch format {
+		case "email", "url", "uuid", "phone":
+			return formatRule{rule: base, format: format}, nil
+		}
+		return nil, invalidConfig(config, fmt.Sprintf("unknown format %q", format))
+	case validationrule.ValidationRuleTypePassword:
+		policy := passwordRule{rule: base}
+		var err error
+		if policy.minLength, err = params.whole("min_length"); err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		if policy.maxLength, err = params.whole("max_length"); err != nil {
+			return nil, invalidConfig(config, err.Error())
+		}
+		policy.requireUpper, _ = params.flag("require_uppercase")
+		policy.requireLower, _ = params.flag("require_lowercase")
+		policy.requireDigit, _ = params.flag("require_digit")
+		policy.requireSpecial, _ = params.flag("require_special")
+		return policy, nil
+	default:
+		return nil, invalidConfig(config, fmt.Sprintf("rule type %q cannot be declared", config.Type))
+	}
+}
+
+// NewLengthRule creates a rule bounding the length of text in characters;
+// a zero max leaves it unbounded
+func NewLengthRule(name, description string, min, max int) validationrule.Service {
+	return lengthRule{rule: rule{name: name, description: description}, min: min, max: max}
+}
+
+// NewRangeRule creates a rule bounding numbers
+func NewRangeRule(name, description string, min, max float64) validationrule.Service {
+	return rangeRule{rule: rule{name: name, description: description}, min: min, max: max, hasMin: true, hasMax: true}
+}
+
+// NewPatternRule creates a rule matching text against a regular expression
+func NewPatternRule(name, description, pattern string) (validationrule.Service, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for rule %q: %w", name, err)
+	}
+	return patternRule{rule: rule{name: name, description: description}, pattern: compiled}, nil
+}
+
+// NewFormatRule creates a rule checking text is an email, url, uuid or phone
+// number
+func NewFormatRule(name, description, format string) (validationrule.Service, error) {
+	return NewRule(validationrule.ValidationRuleConfig{
+		Name:        name,
+		Description: description,
+		Type:        validationrule.ValidationRuleTypeFormat,
+		Parameters:  map[string]interface{}{"format": format},
+	})
+}
+
+// requiredRule rejects missing and zero values
+type requiredRule struct {
+	rule
+}
+
+func (r requiredRule) Validate(ctx context.Context, value interface{}) error {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.IsZero() {
+		return r.fail("field is required")
+	}
+	return nil
+}
+
+// lengthRule bounds the number of characters of text
+type lengthRule struct {
+	rule
+	min, max int
+}
+
+func (r lengthRule) Validate(ctx context.Context, value interface{}) error {
+	text, ok := asString(value)
+	if !ok {
+		return r.fail("must be text")
+	}
+	length := utf8.RuneCountInString(text)
+	if length < r.min {
+		return r.fail("must be at least %d characters long", r.min)
+	}
+	if r.max > 0 && length > r.max {
+		return r.fail("must be no more than %d characters long", r.max)
+	}
+	return nil
+}
+
+// rangeRule bounds numbers
+type rangeRule struct {
+	rule
+	min, max       float64
+	hasMin, hasMax bool
+}
+
+func (r rangeRule) Validate(ctx context.Context, value interface{}) error {
+	number, ok := asFloat(value)
+	if !ok {
+		return r.fail("must be a number")
+	}
+	if r.hasMin && number < r.min {
+		return r.fail("must be at least %v", r.min)
+	}
+	if r.hasMax && number > r.max {
+		return r.fail("must be no more than %v", r.max)
+	}
+	return nil
+}
+
+// patternRule matches text against a regular expression
+type patternRule struct {
+	rule
+	pattern *regexp.Regexp
+}
+
+func (r patternRule) Validate(ctx context.Context, value interface{}) error {
+	text, ok := asString(value)
+	if !ok || !r.pattern.MatchString(text) {
+		return r.fail("must match the pattern %s", r.pattern)
+	}
+	return nil
+}
+
+// formatRule checks text is in a well-known format
+type formatRule struct {
+	rule
+	format string
+}
+
+func (r formatRule) Validate(ctx context.Context, value interface{}) error {
+	text, _ := asString(value)
+	switch r.format {
+	case "email":
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			return r.fail("must be a valid email address")
+		}
+	case "url":
+		if parsed, err := url.ParseRequestURI(text); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return r.fail("must be a valid URL")
+		}
+	case "uuid":
+		if _, err := uuid.Parse(text); err != nil {
+			return r.fail("must be a valid UUID")
+		}
+	case "phone":
+		if !phonePattern.MatchString(text) {
+			return r.fail("must be a valid phone number")
+		}
+	}
+	return nil
+}
+
+// passwordRule enforces a password policy, reporting every requirement a
+// password misses
+type passwordRule struct {
+	rule
+	minLength, maxLength                                     int
+	requireUpper, requireLower, requireDigit, requireSpecial bool
+}
+
+func (r passwordRule) Validate(ctx context.Context, value interface{}) error {
+	password, ok := asString(value)
+	if !ok {
+		return r.fail("must be text")
+	}
+
+	var problems []string
+	length := utf8.RuneCountInString(password)
+	if length < r.minLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters long", r.minLength))
+	}
+	if r.maxLength > 0 && length > r.maxLength {
+		problems = append(problems, fmt.Sprintf("must be no more than %d characters long", r.maxLength))
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+	if r.requireLower && !hasLower {
+		problems = append(problems, "must contain at least one lowercase letter")
+	}
+	if r.requireUpper && !hasUpper {
+		problems = append(problems, "must contain at least one uppercase letter")
+	}
+	if r.requireDigit && !hasDigit {
+		problems = append(problems, "must contain at least one digit")
+	}
+	if r.requireSpecial && !hasSpecial {
+		problems = append(problems, "must contain at least one special character")
+	}
+
+	if len(problems) > 0 {
+		return r.fail("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// invalidConfig reports a configuration that declares no usable rule
+func invalidConfig(config validationrule.ValidationRuleConfig, reason string) error {
+	return validationrule.ValidationRuleError{
+		Code:    validationrule.ErrInvalidConfig.Code,
+		Message: fmt.Sprintf("invalid rule %q: %s", config.Name, reason),
+		RuleID:  config.RuleID,
+		Field:   config.Field,
+	}
+}
+
+// asString returns text values, dereferencing pointers
+func asString(value interface{}) (string, bool) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// asFloat returns numeric values as floats, dereferencing pointers
+func asFloat(value interface{}) (float64, bool) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// parameters reads rule parameters decoded from YAML or JSON, whose numbers
+// arrive as ints or floats
+type parameters map[string]interface{}
+
+// whole returns a whole number parameter, zero when absent
+func (p parameters) whole(key string) (int, error) {
+	number, ok, err := p.number(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	if number != float64(int(number)) {
+		return 0, fmt.Errorf("%s must be a whole number", key)
+	}
+	return int(number), nil
+}
+
+// number returns a number parameter and whether it is set
+func (p parameters) number(key string) (float64, bool, error) {
+	value, ok := p[key]
+	if !ok {
+		return 0, false, nil
+	}
+	number, ok := asFloat(value)
+	if !ok {
+		return 0, false, fmt.Errorf("%s must be a number", key)
+	}
+	return number, true, nil
+}
+
+// text returns a text parameter and whether it is set
+func (p parameters) text(key string) (string, bool) {
+	text, ok := p[key].(string)
+	return text, ok
+}
+
+// flag returns a boolean parameter and whether it is set
+func (p parameters) flag(key string) (bool, bool) {
+	flag, ok := p[key].(bool)
+	return flag, ok
+}
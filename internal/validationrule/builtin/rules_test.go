@@ -0,0 +1,141 @@
+package builtin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/builtin"
+)
+
+func TestNewRule_GivenDeclaredRule_WhenValidating_ThenAppliesItsParameters(t *testing.T) {
+	tests := []struct {
+		name            string
+		ruleType        validationrule.ValidationRuleType
+		parameters      map[string]interface{}
+		message         string
+		value           interface{}
+		expectedMessage string
+	}{
+		{
+			name:       "Given a length rule, When the value fits, Then returns nil",
+			ruleType:   validationrule.ValidationRuleTypeLength,
+			parameters: map[string]interface{}{"min": 2, "max": 5},
+			value:      "Jane",
+		},
+		{
+			name:            "Given a length rule, When the value is too long in characters, Then returns max error",
+			ruleType:        validationrule.ValidationRuleTypeLength,
+			parameters:      map[string]interface{}{"min": 2, "max": 5.0},
+			value:           "Zoë-Ann",
+			expectedMessage: "must be no more than 5 characters long",
+		},
+		{
+			name:            "Given a range rule, When the number is below min, Then returns min error",
+			ruleType:        validationrule.ValidationRuleTypeRange,
+			parameters:      map[string]interface{}{"min": 1, "max": 10},
+			value:           0,
+			expectedMessage: "must be at least 1",
+		},
+		{
+			name:            "Given a pattern rule with a message, When the value does not match, Then returns the message",
+			ruleType:        validationrule.ValidationRuleTypePattern,
+			parameters:      map[string]interface{}{"pattern": `^[A-Z]{2}$`},
+			message:         "must be a two letter country code",
+			value:           "usa",
+			expectedMessage: "must be a two letter country code",
+		},
+		{
+			name:            "Given a phone format rule, When the value is not a phone number, Then returns format error",
+			ruleType:        validationrule.ValidationRuleTypeFormat,
+			parameters:      map[string]interface{}{"format": "phone"},
+			value:           "call me",
+			expectedMessage: "must be a valid phone number",
+		},
+		{
+			name:            "Given a password policy, When the password misses requirements, Then lists every one",
+			ruleType:        validationrule.ValidationRuleTypePassword,
+			parameters:      map[string]interface{}{"min_length": 12, "require_digit": true, "require_special": true},
+			value:           "longbutplain",
+			expectedMessage: "must contain at least one digit; must contain at least one special character",
+		},
+		{
+			name:            "Given a required rule, When the value is empty, Then returns required error",
+			ruleType:        validationrule.ValidationRuleTypeRequired,
+			value:           "",
+			expectedMessage: "field is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rule, err := builtin.NewRule(validationrule.ValidationRuleConfig{
+				RuleID:     "rule-1",
+				Name:       "declared",
+				Type:       tt.ruleType,
+				Message:    tt.message,
+				Parameters: tt.parameters,
+			})
+			require.NoError(t, err)
+
+			// Act
+			err = rule.Validate(context.Background(), tt.value)
+
+			// Assert
+			if tt.expectedMessage == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var ruleErr validationrule.ValidationRuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, validationrule.ErrInvalidValue.Code, ruleErr.Code)
+			assert.Equal(t, tt.expectedMessage, ruleErr.Message)
+			assert.Equal(t, "rule-1", ruleErr.RuleID)
+		})
+	}
+}
+
+func TestNewRule_GivenUnusableDeclaration_WhenBuilding_ThenReturnsInvalidConfigError(t *testing.T) {
+	tests := []struct {
+		name   string
+		config validationrule.ValidationRuleConfig
+	}{
+		{
+			name:   "Given a custom rule, When building, Then returns error",
+			config: validationrule.ValidationRuleConfig{Name: "custom", Type: validationrule.ValidationRuleTypeCustom},
+		},
+		{
+			name:   "Given an invalid pattern, When building, Then returns error",
+			config: validationrule.ValidationRuleConfig{Name: "pattern", Type: validationrule.ValidationRuleTypePattern, Parameters: map[string]interface{}{"pattern": "("}},
+		},
+		{
+			name:   "Given a length with max below min, When building, Then returns error",
+			config: validationrule.ValidationRuleConfig{Name: "length", Type: validationrule.ValidationRuleTypeLength, Parameters: map[string]interface{}{"min": 5, "max": 2}},
+		},
+		{
+			name:   "Given a fractional length, When building, Then returns error",
+			config: validationrule.ValidationRuleConfig{Name: "length", Type: validationrule.ValidationRuleTypeLength, Parameters: map[string]interface{}{"min": 1.5}},
+		},
+		{
+			name:   "Given an unknown format, When building, Then returns error",
+			config: validationrule.ValidationRuleConfig{Name: "format", Type: validationrule.ValidationRuleTypeFormat, Parameters: map[string]interface{}{"format": "iban"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rule, err := builtin.NewRule(tt.config)
+
+			// Assert
+			var ruleErr validationrule.ValidationRuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, validationrule.ErrInvalidConfig.Code, ruleErr.Code)
+			assert.Nil(t, rule)
+		})
+	}
+}
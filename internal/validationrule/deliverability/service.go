@@ -0,0 +1,108 @@
+package deliverability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+// RuleName is the name the rule is registered under
+const RuleName = "email_deliverable"
+
+// Config configures the email deliverability rule
+type Config struct {
+	Remote remote.Config
+
+	// LookupMX and LookupHost resolve a domain's mail exchangers and
+	// addresses; nil uses the default resolver
+	LookupMX   func(ctx context.Context, domain string) ([]*net.MX, error)
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// service implements validationrule.Service interface by checking in DNS
+// that the domain of an email address accepts mail: it publishes MX records
+// other than a null MX or, lacking any, has an address mail can be
+// delivered to directly. Verdicts are cached per domain.
+type service struct {
+	client     *remote.Client
+	lookupMX   func(ctx context.Context, domain string) ([]*net.MX, error)
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewService creates a new email deliverability rule
+func NewService(config Config) validationrule.Service {
+	s := &service{
+		client:     remote.NewClient(config.Remote),
+		lookupMX:   config.LookupMX,
+		lookupHost: config.LookupHost,
+	}
+	if s.lookupMX == nil {
+		s.lookupMX = net.DefaultResolver.LookupMX
+	}
+	if s.lookupHost == nil {
+		s.lookupHost = net.DefaultResolver.LookupHost
+	}
+	return s
+}
+
+// Validate checks the email address's domain accepts mail
+func (s *service) Validate(ctx context.Context, value interface{}) error {
+	email, _ := value.(string)
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return invalid("must be a valid email address")
+	}
+	domain := strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+
+	deliverable, err := s.client.Lookup(ctx, domain, func(ctx context.Context) (interface{}, error) {
+		return s.deliverable(ctx, domain)
+	})
+	if err != nil {
+		return s.client.Unavailable(RuleName)
+	}
+	if !deliverable.(bool) {
+		return invalid("email domain does not accept mail")
+	}
+	return nil
+}
+
+func (s *service) Name() string { return RuleName }
+
+func (s *service) Description() string {
+	return "Checks the domain of an email address accepts mail"
+}
+
+// deliverable resolves whether a domain accepts mail. Domains that do not
+// exist are a verdict; other resolver failures are errors.
+func (s *service) deliverable(ctx context.Context, domain string) (bool, error) {
+	records, err := s.lookupMX(ctx, domain)
+	if err != nil && !isNotFound(err) {
+		return false, err
+	}
+	if len(records) > 0 {
+		// A null MX (RFC 7505) declares the domain accepts no mail
+		return !(len(records) == 1 && strings.TrimSuffix(records[0].Host, ".") == ""), nil
+	}
+
+	// Without MX records mail goes to the domain's own address (RFC 5321)
+	addresses, err := s.lookupHost(ctx, domain)
+	if err != nil && !isNotFound(err) {
+		return false, err
+	}
+	return len(addresses) > 0, nil
+}
+
+// isNotFound reports whether the resolver found no such records
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// invalid reports an undeliverable address
+func invalid(message string) error {
+	return validationrule.ValidationRuleError{Code: validationrule.ErrInvalidValue.Code, Message: message, RuleID: RuleName}
+}
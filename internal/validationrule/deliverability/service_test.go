@@ -0,0 +1,112 @@
+package deliverability_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/deliverability"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+// resolver answers DNS lookups from fixed records
+type resolver struct {
+	mx      map[string][]*net.MX
+	hosts   map[string][]string
+	err     error
+	lookups int
+}
+
+func (r *resolver) lookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	if records, ok := r.mx[domain]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+}
+
+func (r *resolver) lookupHost(ctx context.Context, host string) ([]string, error) {
+	if addresses, ok := r.hosts[host]; ok {
+		return addresses, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestValidate_GivenEmailDomains_WhenValidating_ThenAcceptsDomainsThatTakeMail(t *testing.T) {
+	dns := &resolver{
+		mx: map[string][]*net.MX{
+			"example.com": {{Host: "mx.example.com.", Pref: 10}},
+			"nomail.com":  {{Host: ".", Pref: 0}},
+		},
+		hosts: map[string][]string{"direct.example.org": {"192.0.2.1"}},
+	}
+	rule := deliverability.NewService(deliverability.Config{LookupMX: dns.lookupMX, LookupHost: dns.lookupHost})
+
+	tests := []struct {
+		name            string
+		email           string
+		expectedMessage string
+	}{
+		{name: "Given a domain with MX records, When validating, Then returns nil", email: "jane@Example.com"},
+		{name: "Given a domain without MX records but an address, When validating, Then returns nil", email: "jane@direct.example.org"},
+		{name: "Given a null MX domain, When validating, Then returns undeliverable error", email: "jane@nomail.com", expectedMessage: "email domain does not accept mail"},
+		{name: "Given an unknown domain, When validating, Then returns undeliverable error", email: "jane@nowhere.invalid", expectedMessage: "email domain does not accept mail"},
+		{name: "Given no domain, When validating, Then returns invalid address error", email: "jane@", expectedMessage: "must be a valid email address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := rule.Validate(context.Background(), tt.email)
+
+			// Assert
+			if tt.expectedMessage == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, validationrule.ValidationRuleError{Code: "INVALID_VALUE", Message: tt.expectedMessage, RuleID: deliverability.RuleName}, err)
+		})
+	}
+}
+
+func TestValidate_GivenRepeatedDomain_WhenValidating_ThenResolvesItOnce(t *testing.T) {
+	// Arrange
+	dns := &resolver{mx: map[string][]*net.MX{"example.com": {{Host: "mx.example.com."}}}}
+	rule := deliverability.NewService(deliverability.Config{LookupMX: dns.lookupMX, LookupHost: dns.lookupHost})
+
+	// Act
+	firstErr := rule.Validate(context.Background(), "jane@example.com")
+	secondErr := rule.Validate(context.Background(), "john@example.com")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, 1, dns.lookups)
+}
+
+func TestValidate_GivenFailingResolver_WhenValidating_ThenAppliesFallback(t *testing.T) {
+	// Arrange
+	dns := &resolver{err: errors.New("i/o timeout")}
+	lenient := deliverability.NewService(deliverability.Config{LookupMX: dns.lookupMX, LookupHost: dns.lookupHost})
+	strict := deliverability.NewService(deliverability.Config{
+		Remote:   remote.Config{Fallback: remote.FallbackReject},
+		LookupMX: dns.lookupMX, LookupHost: dns.lookupHost,
+	})
+
+	// Act
+	lenientErr := lenient.Validate(context.Background(), "jane@example.com")
+	strictErr := strict.Validate(context.Background(), "jane@example.com")
+
+	// Assert
+	assert.NoError(t, lenientErr)
+	var ruleErr validationrule.ValidationRuleError
+	assert.ErrorAs(t, strictErr, &ruleErr)
+	assert.Equal(t, validationrule.ErrRuleExecution.Code, ruleErr.Code)
+}
@@ -0,0 +1,231 @@
+package validationrule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Condition decides whether a conditional rule applies to a value
+type Condition func(ctx context.Context, value interface{}) bool
+
+// EngineMode selects how an engine reacts to a failing rule
+type EngineMode string
+
+const (
+	// ModeCollectAll runs every applicable rule and reports every failure
+	ModeCollectAll EngineMode = "collect_all"
+	// ModeFailFast stops at the first failing rule
+	ModeFailFast EngineMode = "fail_fast"
+)
+
+// Metadata keys the engine sets on results and reports
+const (
+	MetadataRuleName     = "rule_name"
+	MetadataPriority     = "priority"
+	MetadataSkipped      = "skipped" // A conditional rule whose condition did not hold
+	MetadataRulesRun     = "rules_run"
+	MetadataRulesFailed  = "rules_failed"
+	MetadataRulesSkipped = "rules_skipped"
+	MetadataFailedRules  = "failed_rules"
+	MetadataShortCircuit = "short_circuited" // Rules left unrun after a fail-fast failure
+)
+
+// EngineReport is the outcome of running an engine's rules against a value
+type EngineReport struct {
+	Valid    bool                   `json:"valid"`
+	Results  []ValidationRuleResult `json:"results"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Engine runs registered rules against values in priority order, lower
+// priorities first and rules of equal priority in registration order.
+// Disabled rules are left out and conditional rules only run when their
+// condition holds. It is safe for concurrent use.
+type Engine struct {
+	mode EngineMode
+
+	mu    sync.RWMutex
+	rules []registeredRule
+	seq   int
+}
+
+// registeredRule is a rule with its configuration and, for conditional
+// rules, its condition
+type registeredRule struct {
+	config    ValidationRuleConfig
+	rule      Service
+	condition Condition
+	seq       int
+}
+
+// NewEngine creates an engine without rules. Unknown modes collect all
+// failures.
+func NewEngine(mode EngineMode) *Engine {
+	if mode != ModeFailFast {
+		mode = ModeCollectAll
+	}
+	return &Engine{mode: mode}
+}
+
+// Register adds a rule under its configuration's rule ID
+func (e *Engine) Register(config ValidationRuleConfig, rule Service) error {
+	return e.register(registeredRule{config: config, rule: rule})
+}
+
+// RegisterConditional adds a rule that only runs for values the condition
+// holds for; other values pass it and its result is marked skipped
+func (e *Engine) RegisterConditional(config ValidationRuleConfig, condition Condition, rule Service) error {
+	if condition == nil {
+		return ruleError(ErrInvalidConfig, config.RuleID, "conditional rule needs a condition")
+	}
+	config.Type = ValidationRuleTypeConditional
+	return e.register(registeredRule{config: config, rule: rule, condition: condition})
+}
+
+// register adds a rule keeping the rules in running order
+func (e *Engine) register(registered registeredRule) error {
+	if !registered.config.IsValid() || registered.rule == nil {
+		return ruleError(ErrInvalidConfig, registered.config.RuleID, "rule needs a rule ID, a name and an implementation")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.find(registered.config.RuleID) >= 0 {
+		return ruleError(ErrInvalidConfig, registered.config.RuleID, fmt.Sprintf("rule %q is already registered", registered.config.RuleID))
+	}
+	e.seq++
+	registered.seq = e.seq
+	e.rules = append(e.rules, registered)
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		if e.rules[i].config.Priority != e.rules[j].config.Priority {
+			return e.rules[i].config.Priority < e.rules[j].config.Priority
+		}
+		return e.rules[i].seq < e.rules[j].seq
+	})
+	return nil
+}
+
+// Unregister removes a rule
+func (e *Engine) Unregister(ruleID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.find(ruleID)
+	if i < 0 {
+		return ruleError(ErrRuleNotFound, ruleID, "")
+	}
+	e.rules = append(e.rules[:i], e.rules[i+1:]...)
+	return nil
+}
+
+// SetEnabled enables or disables a rule without unregistering it
+func (e *Engine) SetEnabled(ruleID string, enabled bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.find(ruleID)
+	if i < 0 {
+		return ruleError(ErrRuleNotFound, ruleID, "")
+	}
+	e.rules[i].config.Enabled = enabled
+	return nil
+}
+
+// Run validates the value against the enabled rules. Each rule run gets a
+// result carrying its configuration's metadata, its name and priority; the
+// report aggregates how many rules ran, failed and were skipped. The value
+// is valid when no rule failed. Run only returns an error when the context
+// ends before every rule ran.
+func (e *Engine) Run(ctx context.Context, value interface{}) (*EngineReport, error) {
+	e.mu.RLock()
+	rules := make([]registeredRule, 0, len(e.rules))
+	for _, registered := range e.rules {
+		if registered.config.IsEnabled() {
+			rules = append(rules, registered)
+		}
+	}
+	e.mu.RUnlock()
+
+	report := &EngineReport{Valid: true, Results: make([]ValidationRuleResult, 0, len(rules))}
+	var run, skipped int
+	failedRules := []string{}
+	for i, registered := range rules {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRuleExecution, err)
+		}
+
+		result := ValidationRuleResult{RuleID: registered.config.RuleID, Valid: true, Metadata: resultMetadata(registered.config)}
+		if registered.condition != nil && !registered.condition(ctx, value) {
+			result.Metadata[MetadataSkipped] = true
+			skipped++
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		run++
+		if err := registered.rule.Validate(ctx, value); err != nil {
+			result.Valid = false
+			result.Message = err.Error()
+			result.Value = value
+			report.Valid = false
+			failedRules = append(failedRules, registered.config.RuleID)
+		}
+		report.Results = append(report.Results, result)
+
+		if !result.Valid && e.mode == ModeFailFast {
+			report.Metadata = map[string]interface{}{MetadataShortCircuit: len(rules) - i - 1}
+			break
+		}
+	}
+
+	if report.Metadata == nil {
+		report.Metadata = make(map[string]interface{})
+	}
+	report.Metadata[MetadataRulesRun] = run
+	report.Metadata[MetadataRulesFailed] = len(failedRules)
+	report.Metadata[MetadataRulesSkipped] = skipped
+	report.Metadata[MetadataFailedRules] = failedRules
+	return report, nil
+}
+
+// Errors returns the failed results of the report
+func (r *EngineReport) Errors() []ValidationRuleResult {
+	var failed []ValidationRuleResult
+	for _, result := range r.Results {
+		if !result.Valid {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// find returns the index of a rule, or -1; the caller holds the lock
+func (e *Engine) find(ruleID string) int {
+	for i, registered := range e.rules {
+		if registered.config.RuleID == ruleID {
+			return i
+		}
+	}
+	return -1
+}
+
+// resultMetadata starts a result's metadata from its rule's configuration
+func resultMetadata(config ValidationRuleConfig) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(config.Metadata)+2)
+	for key, value := range config.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataRuleName] = config.Name
+	metadata[MetadataPriority] = config.Priority
+	return metadata
+}
+
+// ruleError is a copy of a rule error for a rule, with a more specific
+// message when one is given
+func ruleError(err ValidationRuleError, ruleID, message string) error {
+	err.RuleID = ruleID
+	if message != "" {
+		err.Message = message
+	}
+	return err
+}
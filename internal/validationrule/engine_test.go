@@ -0,0 +1,194 @@
+package validationrule_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// funcRule is a rule validating with a function and recording its runs
+type funcRule struct {
+	name     string
+	validate func(value interface{}) error
+	runs     *[]string
+}
+
+func (r funcRule) Validate(ctx context.Context, value interface{}) error {
+	*r.runs = append(*r.runs, r.name)
+	return r.validate(value)
+}
+
+func (r funcRule) Name() string        { return r.name }
+func (r funcRule) Description() string { return "" }
+
+func ruleConfig(id string, priority int) validationrule.ValidationRuleConfig {
+	config := validationrule.DefaultValidationRuleConfig()
+	config.RuleID = id
+	config.Name = id
+	config.Priority = priority
+	return config
+}
+
+func newEngine(t *testing.T, mode validationrule.EngineMode, runs *[]string) *validationrule.Engine {
+	t.Helper()
+	pass := func(value interface{}) error { return nil }
+	notEmpty := func(value interface{}) error {
+		if value == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	}
+	short := func(value interface{}) error {
+		if len(value.(string)) > 5 {
+			return errors.New("must be short")
+		}
+		return nil
+	}
+
+	engine := validationrule.NewEngine(mode)
+	lowConfig := ruleConfig("audit", validationrule.PriorityLow)
+	lowConfig.Metadata["owner"] = "security"
+	require.NoError(t, engine.Register(lowConfig, funcRule{name: "audit", validate: pass, runs: runs}))
+	require.NoError(t, engine.Register(ruleConfig("short", validationrule.PriorityNormal), funcRule{name: "short", validate: short, runs: runs}))
+	require.NoError(t, engine.Register(ruleConfig("not_empty", validationrule.PriorityHigh), funcRule{name: "not_empty", validate: notEmpty, runs: runs}))
+	require.NoError(t, engine.RegisterConditional(ruleConfig("admin_prefix", validationrule.PriorityNormal),
+		func(ctx context.Context, value interface{}) bool { return strings.HasPrefix(value.(string), "admin") },
+		funcRule{name: "admin_prefix", validate: func(value interface{}) error { return errors.New("admin names are reserved") }, runs: runs}))
+	return engine
+}
+
+func TestEngineRun_GivenRulesOfEveryPriority_WhenRunning_ThenRunsThemInPriorityOrder(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           validationrule.EngineMode
+		value          string
+		expectedRuns   []string
+		expectedFailed []string
+		expectedValid  bool
+	}{
+		{
+			name:          "Given a valid value, When collecting all, Then runs applicable rules and skips the conditional one",
+			mode:          validationrule.ModeCollectAll,
+			value:         "jane",
+			expectedRuns:  []string{"not_empty", "short", "audit"},
+			expectedValid: true,
+		},
+		{
+			name:           "Given a value failing several rules, When collecting all, Then reports every failure",
+			mode:           validationrule.ModeCollectAll,
+			value:          "administrator",
+			expectedRuns:   []string{"not_empty", "short", "admin_prefix", "audit"},
+			expectedFailed: []string{"short", "admin_prefix"},
+		},
+		{
+			name:           "Given a value failing several rules, When failing fast, Then stops at the first failure",
+			mode:           validationrule.ModeFailFast,
+			value:          "administrator",
+			expectedRuns:   []string{"not_empty", "short"},
+			expectedFailed: []string{"short"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var runs []string
+			engine := newEngine(t, tt.mode, &runs)
+
+			// Act
+			report, err := engine.Run(context.Background(), tt.value)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRuns, runs)
+			assert.Equal(t, tt.expectedValid, report.Valid)
+			var failed []string
+			for _, result := range report.Errors() {
+				failed = append(failed, result.RuleID)
+			}
+			assert.Equal(t, tt.expectedFailed, failed)
+		})
+	}
+}
+
+func TestEngineRun_GivenResults_WhenRunning_ThenAggregatesTheirMetadata(t *testing.T) {
+	// Arrange
+	var runs []string
+	engine := newEngine(t, validationrule.ModeCollectAll, &runs)
+
+	// Act
+	collected, err := engine.Run(context.Background(), "jane doe")
+	require.NoError(t, err)
+	failFast, err := newEngine(t, validationrule.ModeFailFast, &runs).Run(context.Background(), "")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, map[string]interface{}{
+		validationrule.MetadataRulesRun:     3,
+		validationrule.MetadataRulesFailed:  1,
+		validationrule.MetadataRulesSkipped: 1,
+		validationrule.MetadataFailedRules:  []string{"short"},
+	}, collected.Metadata)
+	require.Len(t, collected.Results, 4)
+	short := collected.Results[1]
+	assert.Equal(t, validationrule.ValidationRuleResult{
+		RuleID:   "short",
+		Message:  "must be short",
+		Value:    "jane doe",
+		Metadata: map[string]interface{}{validationrule.MetadataRuleName: "short", validationrule.MetadataPriority: validationrule.PriorityNormal},
+	}, short)
+	assert.Equal(t, true, collected.Results[2].Metadata[validationrule.MetadataSkipped])
+	assert.Equal(t, "security", collected.Results[3].Metadata["owner"])
+	assert.Equal(t, 3, failFast.Metadata[validationrule.MetadataShortCircuit])
+}
+
+func TestEngine_GivenRegisteredRules_WhenManagingThem_ThenAppliesChanges(t *testing.T) {
+	// Arrange
+	var runs []string
+	engine := newEngine(t, validationrule.ModeCollectAll, &runs)
+	ctx := context.Background()
+
+	// Act
+	disableErr := engine.SetEnabled("short", false)
+	unregisterErr := engine.Unregister("audit")
+	report, runErr := engine.Run(ctx, "jane doe")
+	duplicateErr := engine.Register(ruleConfig("not_empty", 1), funcRule{name: "not_empty", runs: &runs})
+	unknownErr := engine.Unregister("unknown")
+	invalidErr := engine.Register(validationrule.ValidationRuleConfig{Name: "no-id"}, funcRule{runs: &runs})
+
+	// Assert
+	require.NoError(t, disableErr)
+	require.NoError(t, unregisterErr)
+	require.NoError(t, runErr)
+	assert.True(t, report.Valid)
+	assert.Equal(t, []string{"not_empty"}, runs)
+	assert.ErrorIs(t, duplicateErr, validationrule.ValidationRuleError{Code: "INVALID_CONFIG", Message: `rule "not_empty" is already registered`, RuleID: "not_empty"})
+	var ruleErr validationrule.ValidationRuleError
+	require.ErrorAs(t, unknownErr, &ruleErr)
+	assert.Equal(t, validationrule.ErrRuleNotFound.Code, ruleErr.Code)
+	require.ErrorAs(t, invalidErr, &ruleErr)
+	assert.Equal(t, validationrule.ErrInvalidConfig.Code, ruleErr.Code)
+}
+
+func TestEngineRun_GivenCancelledContext_WhenRunning_ThenReturnsExecutionError(t *testing.T) {
+	// Arrange
+	var runs []string
+	engine := newEngine(t, validationrule.ModeCollectAll, &runs)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	report, err := engine.Run(ctx, "jane")
+
+	// Assert
+	assert.ErrorIs(t, err, validationrule.ErrRuleExecution)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, report)
+	assert.Empty(t, runs)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/builtin"
 )
 
 // Config contains all configuration for building the validation rule service
@@ -118,28 +119,50 @@ func (f *ValidationRuleServiceFactory) Build() (validationrule.Service, error) {
 	}
 }
 
-// buildFormatRule creates a format validation rule (placeholder)
+// buildFormatRule creates a format validation rule
 func (f *ValidationRuleServiceFactory) buildFormatRule() (validationrule.Service, error) {
-	// TODO: Implement format validation rule
-	return nil, fmt.Errorf("format validation rule not yet implemented")
+	return builtin.NewFormatRule(f.config.RuleName, f.config.Description, f.config.FormatType)
 }
 
-// buildLengthRule creates a length validation rule (placeholder)
+// buildLengthRule creates a length validation rule
 func (f *ValidationRuleServiceFactory) buildLengthRule() (validationrule.Service, error) {
-	// TODO: Implement length validation rule
-	return nil, fmt.Errorf("length validation rule not yet implemented")
+	if f.config.MaxLength > 0 && f.config.MaxLength < f.config.MinLength {
+		return nil, fmt.Errorf("length rule %q has max length below min length", f.config.RuleName)
+	}
+	return builtin.NewLengthRule(f.config.RuleName, f.config.Description, f.config.MinLength, f.config.MaxLength), nil
 }
 
-// buildRangeRule creates a range validation rule (placeholder)
+// buildRangeRule creates a range validation rule
 func (f *ValidationRuleServiceFactory) buildRangeRule() (validationrule.Service, error) {
-	// TODO: Implement range validation rule
-	return nil, fmt.Errorf("range validation rule not yet implemented")
+	min, minOK := toFloat(f.config.MinValue)
+	max, maxOK := toFloat(f.config.MaxValue)
+	if !minOK || !maxOK || max < min {
+		return nil, fmt.Errorf("range rule %q needs numeric bounds with min no more than max", f.config.RuleName)
+	}
+	return builtin.NewRangeRule(f.config.RuleName, f.config.Description, min, max), nil
 }
 
-// buildPatternRule creates a pattern validation rule (placeholder)
+// buildPatternRule creates a pattern validation rule
 func (f *ValidationRuleServiceFactory) buildPatternRule() (validationrule.Service, error) {
-	// TODO: Implement pattern validation rule
-	return nil, fmt.Errorf("pattern validation rule not yet implemented")
+	pattern := f.config.Regex
+	if f.config.RegexFlags != "" {
+		pattern = "(?" + f.config.RegexFlags + ")" + pattern
+	}
+	return builtin.NewPatternRule(f.config.RuleName, f.config.Description, pattern)
+}
+
+// toFloat converts a numeric bound to a float
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case float64:
+		return number, true
+	default:
+		return 0, false
+	}
 }
 
 // buildCustomRule creates a custom validation rule (placeholder)
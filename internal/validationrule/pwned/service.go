@@ -0,0 +1,140 @@
+package pwned
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+// RuleName is the name the rule is registered under
+const RuleName = "password_not_breached"
+
+// DefaultURL is the Pwned Passwords range API
+const DefaultURL = "https://api.pwnedpasswords.com/range/"
+
+// Config configures the breached password rule
+type Config struct {
+	Remote remote.Config
+
+	// URL is the range API the hash prefix is appended to; DefaultURL when
+	// empty
+	URL string
+
+	// MinOccurrences rejects passwords seen in at least this many breaches;
+	// zero rejects any breached password
+	MinOccurrences int
+
+	// HTTPClient makes the calls to the API; nil uses http.DefaultClient,
+	// bounded by the remote timeout
+	HTTPClient *http.Client
+}
+
+// service implements validationrule.Service interface with the k-anonymity
+// model of the Pwned Passwords API: only the first five characters of the
+// password's SHA-1 hash leave the process, and the API answers with the
+// suffixes of every breached hash sharing them. Answers are cached per
+// prefix, so no password or full hash is kept.
+type service struct {
+	client         *remote.Client
+	url            string
+	minOccurrences int
+	httpClient     *http.Client
+}
+
+// NewService creates a new breached password rule
+func NewService(config Config) validationrule.Service {
+	s := &service{
+		client:         remote.NewClient(config.Remote),
+		url:            config.URL,
+		minOccurrences: config.MinOccurrences,
+		httpClient:     config.HTTPClient,
+	}
+	if s.url == "" {
+		s.url = DefaultURL
+	}
+	if s.minOccurrences <= 0 {
+		s.minOccurrences = 1
+	}
+	if s.httpClient == nil {
+		s.httpClient = http.DefaultClient
+	}
+	return s
+}
+
+// Validate rejects passwords that appeared in known data breaches
+func (s *service) Validate(ctx context.Context, value interface{}) error {
+	password, _ := value.(string)
+	if password == "" {
+		return nil
+	}
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	breached, err := s.client.Lookup(ctx, prefix, func(ctx context.Context) (interface{}, error) {
+		return s.fetchRange(ctx, prefix)
+	})
+	if err != nil {
+		return s.client.Unavailable(RuleName)
+	}
+	if breached.(map[string]int)[suffix] >= s.minOccurrences {
+		return validationrule.ValidationRuleError{
+			Code:    validationrule.ErrInvalidValue.Code,
+			Message: "password has appeared in a data breach, please choose another",
+			RuleID:  RuleName,
+		}
+	}
+	return nil
+}
+
+func (s *service) Name() string { return RuleName }
+
+func (s *service) Description() string {
+	return "Rejects passwords found in known data breaches"
+}
+
+// fetchRange returns the breach counts of the hash suffixes sharing the
+// prefix. Responses are padded with zero-count suffixes so their size does
+// not give the prefix away.
+func (s *service) fetchRange(ctx context.Context, prefix string) (map[string]int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+prefix, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create range request: %w", err)
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query breached passwords: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("breached password API answered %d", resp.StatusCode)
+	}
+
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		suffix, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+		occurrences, err := strconv.Atoi(count)
+		if err != nil || occurrences == 0 {
+			continue
+		}
+		counts[strings.ToUpper(suffix)] = occurrences
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,91 @@
+package pwned_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/pwned"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+// newRangeAPI serves a range API knowing "password" as breached, and records
+// the paths requested
+func newRangeAPI(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n%s:9659365\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n", passwordSuffix)
+	}))
+	t.Cleanup(server.Close)
+	return server, &paths
+}
+
+func TestValidate_GivenPasswords_WhenValidating_ThenRejectsBreachedOnesSendingOnlyHashPrefix(t *testing.T) {
+	// Arrange
+	server, paths := newRangeAPI(t)
+	rule := pwned.NewService(pwned.Config{URL: server.URL + "/range/"})
+	ctx := context.Background()
+
+	// Act
+	breachedErr := rule.Validate(ctx, "password")
+	cachedErr := rule.Validate(ctx, "password")
+	safeErr := rule.Validate(ctx, "correct horse battery staple")
+
+	// Assert
+	assert.Equal(t, validationrule.ValidationRuleError{
+		Code:    validationrule.ErrInvalidValue.Code,
+		Message: "password has appeared in a data breach, please choose another",
+		RuleID:  pwned.RuleName,
+	}, breachedErr)
+	assert.Equal(t, breachedErr, cachedErr)
+	assert.NoError(t, safeErr)
+	require.Len(t, *paths, 2)
+	assert.Equal(t, "/range/5BAA6", (*paths)[0])
+}
+
+func TestValidate_GivenMinOccurrences_WhenPasswordIsRarelyBreached_ThenAcceptsIt(t *testing.T) {
+	// Arrange
+	server, _ := newRangeAPI(t)
+	rule := pwned.NewService(pwned.Config{URL: server.URL + "/range/", MinOccurrences: 10000000})
+
+	// Act
+	err := rule.Validate(context.Background(), "password")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestValidate_GivenUnavailableAPI_WhenValidating_ThenAppliesFallback(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	lenient := pwned.NewService(pwned.Config{URL: server.URL + "/"})
+	strict := pwned.NewService(pwned.Config{URL: server.URL + "/", Remote: remote.Config{Fallback: remote.FallbackReject}})
+	offline := pwned.NewService(pwned.Config{URL: server.URL + "/", Remote: remote.Config{Offline: true, Fallback: remote.FallbackReject}})
+
+	// Act
+	lenientErr := lenient.Validate(context.Background(), "password")
+	strictErr := strict.Validate(context.Background(), "password")
+	offlineErr := offline.Validate(context.Background(), "password")
+
+	// Assert
+	assert.NoError(t, lenientErr)
+	var ruleErr validationrule.ValidationRuleError
+	require.ErrorAs(t, strictErr, &ruleErr)
+	assert.Equal(t, validationrule.ErrRuleExecution.Code, ruleErr.Code)
+	assert.Equal(t, strictErr, offlineErr)
+}
@@ -0,0 +1,184 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+)
+
+// Fallback decides the verdict of a rule whose remote check is unavailable
+type Fallback string
+
+const (
+	// FallbackAccept lets values through unchecked, so an outage of the
+	// remote service never blocks users
+	FallbackAccept Fallback = "accept"
+	// FallbackReject fails values that could not be checked
+	FallbackReject Fallback = "reject"
+)
+
+// ErrUnavailable reports a remote check that could not complete: it timed
+// out, failed, the circuit breaker is open or the client is offline
+var ErrUnavailable = errors.New("remote check unavailable")
+
+// Config controls the calls of remote validation rules
+type Config struct {
+	Timeout  time.Duration // Bounds each remote call
+	CacheTTL time.Duration // How long results are reused; negative disables caching
+	MaxCache int           // Results kept at most
+
+	// FailureThreshold consecutive failures open the circuit breaker, which
+	// skips remote calls for OpenTimeout before trying again
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	// Fallback is the verdict for values that could not be checked;
+	// FallbackAccept by default
+	Fallback Fallback
+
+	// Offline never calls the remote service, checking every value with the
+	// fallback verdict, e.g. for air-gapped deployments and tests
+	Offline bool
+}
+
+// DefaultConfig returns the default remote rule configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          2 * time.Second,
+		CacheTTL:         time.Hour,
+		MaxCache:         10000,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		Fallback:         FallbackAccept,
+	}
+}
+
+// Fetch performs a remote lookup. Lookups that fail are not cached and
+// count towards opening the circuit breaker.
+type Fetch func(ctx context.Context) (interface{}, error)
+
+// Client guards the remote lookups of a rule with a timeout, a result cache
+// and a circuit breaker. It is safe for concurrent use.
+type Client struct {
+	config Config
+
+	mu        sync.Mutex
+	cache     map[string]cacheEntry
+	failures  int
+	openUntil time.Time
+}
+
+// cacheEntry is a cached lookup result
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewClient creates a client, filling in defaults for unset values
+func NewClient(config Config) *Client {
+	defaults := DefaultConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+	if config.MaxCache <= 0 {
+		config.MaxCache = defaults.MaxCache
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.Fallback != FallbackReject {
+		config.Fallback = FallbackAccept
+	}
+
+	return &Client{
+		config: config,
+		cache:  make(map[string]cacheEntry),
+	}
+}
+
+// Lookup returns the cached result for the key, or fetches it within the
+// timeout. It returns ErrUnavailable when the result cannot be had.
+func (c *Client) Lookup(ctx context.Context, key string, fetch Fetch) (interface{}, error) {
+	if c.config.Offline {
+		return nil, ErrUnavailable
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if entry, ok := c.cache[key]; ok && now.Before(entry.expiresAt) {
+		c.mu.Unlock()
+		return entry.value, nil
+	}
+	if now.Before(c.openUntil) {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: circuit breaker open", ErrUnavailable)
+	}
+	c.mu.Unlock()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	value, err := fetch(fetchCtx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		// Callers giving up say nothing about the remote service's health
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, ctx.Err())
+		}
+		c.failures++
+		if c.failures >= c.config.FailureThreshold {
+			c.openUntil = time.Now().Add(c.config.OpenTimeout)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	c.failures = 0
+	if c.config.CacheTTL > 0 {
+		c.store(key, value)
+	}
+	return value, nil
+}
+
+// Unavailable returns the verdict for a value the rule could not check:
+// nil when falling back to accepting it, an execution error otherwise
+func (c *Client) Unavailable(ruleName string) error {
+	if c.config.Fallback == FallbackAccept {
+		return nil
+	}
+	return validationrule.ValidationRuleError{
+		Code:    validationrule.ErrRuleExecution.Code,
+		Message: "could not be verified right now, please try again later",
+		RuleID:  ruleName,
+	}
+}
+
+// store caches a result, making room by dropping expired results and, when
+// none expired, an arbitrary one; the caller holds the lock
+func (c *Client) store(key string, value interface{}) {
+	now := time.Now()
+	if len(c.cache) >= c.config.MaxCache {
+		for cached, entry := range c.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(c.cache, cached)
+			}
+		}
+	}
+	if len(c.cache) >= c.config.MaxCache {
+		for cached := range c.cache {
+			delete(c.cache, cached)
+			break
+		}
+	}
+	c.cache[key] = cacheEntry{value: value, expiresAt: now.Add(c.config.CacheTTL)}
+}
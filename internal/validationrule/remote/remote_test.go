@@ -0,0 +1,124 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/remote"
+)
+
+func TestLookup_GivenRepeatedKey_WhenLookingUp_ThenFetchesOnceWhileCached(t *testing.T) {
+	// Arrange
+	client := remote.NewClient(remote.Config{})
+	calls := 0
+	fetch := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return "result", nil
+	}
+
+	// Act
+	first, firstErr := client.Lookup(context.Background(), "key", fetch)
+	second, secondErr := client.Lookup(context.Background(), "key", fetch)
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, "result", first)
+	assert.Equal(t, "result", second)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLookup_GivenFailingService_WhenFailuresReachThreshold_ThenOpensCircuitUntilTimeout(t *testing.T) {
+	// Arrange
+	client := remote.NewClient(remote.Config{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond, CacheTTL: -1})
+	calls := 0
+	healthy := false
+	fetch := func(ctx context.Context) (interface{}, error) {
+		calls++
+		if !healthy {
+			return nil, errors.New("connection refused")
+		}
+		return "result", nil
+	}
+	ctx := context.Background()
+
+	// Act
+	_, firstErr := client.Lookup(ctx, "key", fetch)
+	_, secondErr := client.Lookup(ctx, "key", fetch)
+	_, openErr := client.Lookup(ctx, "key", fetch)
+	callsWhileOpen := calls
+	healthy = true
+	time.Sleep(60 * time.Millisecond)
+	recovered, recoveredErr := client.Lookup(ctx, "key", fetch)
+
+	// Assert
+	assert.ErrorIs(t, firstErr, remote.ErrUnavailable)
+	assert.ErrorIs(t, secondErr, remote.ErrUnavailable)
+	assert.ErrorIs(t, openErr, remote.ErrUnavailable)
+	assert.Equal(t, 2, callsWhileOpen)
+	require.NoError(t, recoveredErr)
+	assert.Equal(t, "result", recovered)
+}
+
+func TestLookup_GivenSlowService_WhenLookingUp_ThenTimesOut(t *testing.T) {
+	// Arrange
+	client := remote.NewClient(remote.Config{Timeout: 10 * time.Millisecond})
+
+	// Act
+	_, err := client.Lookup(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, remote.ErrUnavailable)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUnavailable_GivenOfflineClient_WhenCheckingValue_ThenAppliesFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback remote.Fallback
+		expected error
+	}{
+		{
+			name:     "Given the accept fallback, When unavailable, Then accepts the value",
+			fallback: remote.FallbackAccept,
+		},
+		{
+			name:     "Given the strict reject fallback, When unavailable, Then returns execution error",
+			fallback: remote.FallbackReject,
+			expected: validationrule.ValidationRuleError{
+				Code:    validationrule.ErrRuleExecution.Code,
+				Message: "could not be verified right now, please try again later",
+				RuleID:  "rule",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			client := remote.NewClient(remote.Config{Offline: true, Fallback: tt.fallback})
+			fetched := false
+
+			// Act
+			_, lookupErr := client.Lookup(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+				fetched = true
+				return nil, nil
+			})
+			verdict := client.Unavailable("rule")
+
+			// Assert
+			assert.ErrorIs(t, lookupErr, remote.ErrUnavailable)
+			assert.False(t, fetched)
+			assert.Equal(t, tt.expected, verdict)
+		})
+	}
+}
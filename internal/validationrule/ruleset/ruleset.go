@@ -0,0 +1,147 @@
+package ruleset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/builtin"
+)
+
+// file is the layout of a rule set file
+type file struct {
+	Rules []declaration `json:"rules" yaml:"rules"`
+}
+
+// declaration is a rule as declared in a file. Rules are enabled and of
+// normal priority unless they say otherwise.
+type declaration struct {
+	RuleID      string                            `json:"rule_id" yaml:"rule_id"`
+	Name        string                            `json:"name" yaml:"name"`
+	Description string                            `json:"description" yaml:"description"`
+	Type        validationrule.ValidationRuleType `json:"type" yaml:"type"`
+	Field       string                            `json:"field" yaml:"field"`
+	Message     string                            `json:"message" yaml:"message"`
+	Enabled     *bool                             `json:"enabled" yaml:"enabled"`
+	Priority    *int                              `json:"priority" yaml:"priority"`
+	Metadata    map[string]string                 `json:"metadata" yaml:"metadata"`
+	Parameters  map[string]interface{}            `json:"parameters" yaml:"parameters"`
+}
+
+// Rule is a declared rule built into a validation rule
+type Rule struct {
+	Config validationrule.ValidationRuleConfig
+	Rule   validationrule.Service
+}
+
+// LoadFile reads the rules declared in a YAML (.yaml, .yml) or JSON (.json)
+// rule set file
+func LoadFile(path string) ([]validationrule.ValidationRuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule set: %w", err)
+	}
+
+	var declared file
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &declared)
+	case ".json":
+		err = json.Unmarshal(data, &declared)
+	default:
+		return nil, fmt.Errorf("unsupported rule set format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rule set %s: %w", path, err)
+	}
+
+	configs := make([]validationrule.ValidationRuleConfig, 0, len(declared.Rules))
+	for _, rule := range declared.Rules {
+		config := validationrule.DefaultValidationRuleConfig()
+		config.RuleID = rule.RuleID
+		if config.RuleID == "" {
+			config.RuleID = rule.Name
+		}
+		config.Name = rule.Name
+		config.Description = rule.Description
+		config.Type = rule.Type
+		config.Field = rule.Field
+		config.Message = rule.Message
+		if rule.Enabled != nil {
+			config.Enabled = *rule.Enabled
+		}
+		if rule.Priority != nil {
+			config.Priority = *rule.Priority
+		}
+		for key, value := range rule.Metadata {
+			config.Metadata[key] = value
+		}
+		for key, value := range rule.Parameters {
+			config.Parameters[key] = value
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// LoadDir reads the rules of every rule set file in a directory, in file
+// name order
+func LoadDir(dir string) ([]validationrule.ValidationRuleConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule set directory: %w", err)
+	}
+
+	var configs []validationrule.ValidationRuleConfig
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		loaded, err := LoadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, loaded...)
+	}
+	return configs, nil
+}
+
+// Build builds the enabled rules, ordered by priority, the most urgent
+// first. Every rule must be valid and named uniquely, as rules are
+// registered by name.
+func Build(configs []validationrule.ValidationRuleConfig) ([]Rule, error) {
+	seen := make(map[string]bool, len(configs))
+	var rules []Rule
+	for _, config := range configs {
+		if !config.IsValid() {
+			return nil, fmt.Errorf("%w: rule %q needs a rule ID and a name", validationrule.ErrInvalidConfig, config.Name)
+		}
+		if seen[config.Name] {
+			return nil, fmt.Errorf("%w: rule %q is declared twice", validationrule.ErrInvalidConfig, config.Name)
+		}
+		seen[config.Name] = true
+		if !config.IsEnabled() {
+			continue
+		}
+
+		rule, err := builtin.NewRule(config)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{Config: config, Rule: rule})
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Config.Priority < rules[j].Config.Priority })
+	return rules, nil
+}
@@ -0,0 +1,143 @@
+package ruleset_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/validationrule"
+	"github.com/gentra/decorator-arch-go/internal/validationrule/ruleset"
+)
+
+const passwordPolicyYAML = `
+rules:
+  - name: password_policy
+    description: Company password policy
+    type: password
+    field: password
+    priority: 10
+    parameters:
+      min_length: 12
+      require_digit: true
+  - name: first_name_length
+    type: length
+    field: first_name
+    parameters:
+      min: 2
+      max: 40
+  - name: legacy_rule
+    type: length
+    enabled: false
+`
+
+const nameLengthJSON = `{
+  "rules": [
+    {"rule_id": "last-name", "name": "last_name_length", "type": "length", "field": "last_name", "priority": 1, "parameters": {"max": 40}}
+  ]
+}`
+
+func writeRuleSet(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadFile_GivenYAMLRuleSet_WhenLoading_ThenDefaultsOmittedFields(t *testing.T) {
+	// Arrange
+	path := writeRuleSet(t, t.TempDir(), "rules.yaml", passwordPolicyYAML)
+
+	// Act
+	configs, err := ruleset.LoadFile(path)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, configs, 3)
+	assert.Equal(t, "password_policy", configs[0].RuleID)
+	assert.Equal(t, validationrule.ValidationRuleTypePassword, configs[0].Type)
+	assert.Equal(t, "password", configs[0].Field)
+	assert.Equal(t, validationrule.PriorityHigh, configs[0].Priority)
+	assert.Equal(t, 12, configs[0].Parameters["min_length"])
+	assert.True(t, configs[1].Enabled)
+	assert.Equal(t, validationrule.PriorityNormal, configs[1].Priority)
+	assert.False(t, configs[2].Enabled)
+}
+
+func TestLoadDir_GivenRuleSetFiles_WhenBuilding_ThenReturnsEnabledRulesByPriority(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeRuleSet(t, dir, "a-passwords.yml", passwordPolicyYAML)
+	writeRuleSet(t, dir, "b-names.json", nameLengthJSON)
+	writeRuleSet(t, dir, "README.md", "not a rule set")
+
+	// Act
+	configs, loadErr := ruleset.LoadDir(dir)
+	require.NoError(t, loadErr)
+	rules, err := ruleset.Build(configs)
+
+	// Assert
+	require.NoError(t, err)
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Rule.Name()
+	}
+	assert.Equal(t, []string{"last_name_length", "password_policy", "first_name_length"}, names)
+	assert.Error(t, rules[1].Rule.Validate(context.Background(), "Sh0rt"))
+	assert.NoError(t, rules[1].Rule.Validate(context.Background(), "a long passphrase 4 me"))
+}
+
+func TestBuild_GivenInvalidDeclarations_WhenBuilding_ThenReturnsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "Given a rule without a name, When building, Then returns error",
+			content: "rules:\n  - type: length\n",
+		},
+		{
+			name:    "Given two rules of the same name, When building, Then returns error",
+			content: "rules:\n  - {name: twice, type: required}\n  - {name: twice, type: required}\n",
+		},
+		{
+			name:    "Given a rule of a type that needs code, When building, Then returns error",
+			content: "rules:\n  - {name: conditional, type: conditional}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			configs, err := ruleset.LoadFile(writeRuleSet(t, t.TempDir(), "rules.yaml", tt.content))
+			require.NoError(t, err)
+
+			// Act
+			rules, err := ruleset.Build(configs)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, rules)
+		})
+	}
+}
+
+func TestLoadFile_GivenUnreadableRuleSet_WhenLoading_ThenReturnsError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	malformed := writeRuleSet(t, dir, "rules.json", "{")
+	unsupported := writeRuleSet(t, dir, "rules.toml", "rules = []")
+
+	// Act
+	_, malformedErr := ruleset.LoadFile(malformed)
+	_, unsupportedErr := ruleset.LoadFile(unsupported)
+	_, missingErr := ruleset.LoadFile(filepath.Join(dir, "missing.yaml"))
+
+	// Assert
+	assert.Error(t, malformedErr)
+	assert.Error(t, unsupportedErr)
+	assert.Error(t, missingErr)
+}
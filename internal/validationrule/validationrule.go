@@ -13,15 +13,21 @@ type Service interface {
 
 // Domain types and data structures
 
-// ValidationRuleConfig contains configuration for validation rules
+// ValidationRuleConfig contains configuration for validation rules. Rules
+// can be declared in YAML or JSON rule sets: Type selects the kind of rule,
+// Parameters configure it, and Field binds it to the field of that JSON name
+// in validated data.
 type ValidationRuleConfig struct {
-	RuleID      string                 `json:"rule_id"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	Enabled     bool                   `json:"enabled"`
-	Priority    int                    `json:"priority"`
-	Metadata    map[string]string      `json:"metadata,omitempty"`
-	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	RuleID      string                 `json:"rule_id" yaml:"rule_id"`
+	Name        string                 `json:"name" yaml:"name"`
+	Description string                 `json:"description" yaml:"description"`
+	Type        ValidationRuleType     `json:"type,omitempty" yaml:"type,omitempty"`
+	Field       string                 `json:"field,omitempty" yaml:"field,omitempty"`
+	Message     string                 `json:"message,omitempty" yaml:"message,omitempty"` // Replaces the rule's own failure message
+	Enabled     bool                   `json:"enabled" yaml:"enabled"`
+	Priority    int                    `json:"priority" yaml:"priority"`
+	Metadata    map[string]string      `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 }
 
 // ValidationRuleResult contains the result of a validation rule execution
@@ -65,6 +71,7 @@ const (
 	ValidationRuleTypeCustom      ValidationRuleType = "custom"
 	ValidationRuleTypeRequired    ValidationRuleType = "required"
 	ValidationRuleTypeConditional ValidationRuleType = "conditional"
+	ValidationRuleTypePassword    ValidationRuleType = "password"
 )
 
 // Helper methods for ValidationRuleConfig
@@ -0,0 +1,153 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+)
+
+// HandlerID names the handler in its EventHandlerConfig
+const HandlerID = "webhooks"
+
+// defaultClientTimeout bounds a single delivery when no client is given
+const defaultClientTimeout = 10 * time.Second
+
+// service implements eventhandler.Service by forwarding events to the
+// registered webhook endpoints subscribing to them
+type service struct {
+	registry webhook.Service
+	client   *http.Client
+}
+
+// NewService creates a handler delivering events to the endpoints in the
+// registry with the client, or with a client timing out after ten seconds
+// when it is nil
+func NewService(registry webhook.Service, client *http.Client) eventhandler.Service {
+	if client == nil {
+		client = &http.Client{Timeout: defaultClientTimeout}
+	}
+	return &service{registry: registry, client: client}
+}
+
+// DefaultConfig dispatches every event to the handler, a few at once, and
+// leaves it long enough to go through every endpoint
+func DefaultConfig() eventhandler.EventHandlerConfig {
+	config := eventhandler.DefaultEventHandlerConfig()
+	config.HandlerID = HandlerID
+	config.Concurrency = 4
+	config.Timeout = "2m"
+	return config
+}
+
+// Handle posts the event as JSON to every active endpoint subscribing to its
+// type, signed with the endpoint's secret, and records each attempt. A
+// retried event skips the endpoints it already reached, so failing ones are
+// retried with the dispatcher's backoff without the others hearing twice.
+func (s *service) Handle(ctx context.Context, event interface{}) error {
+	e, ok := event.(events.Event)
+	if !ok {
+		return eventhandler.ErrInvalidEventType
+	}
+
+	endpoints, err := s.registry.List(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%w: %v", eventhandler.ErrHandlingFailed, err)
+	}
+
+	var failed []string
+	for _, endpoint := range endpoints {
+		if !endpoint.Active || !endpoint.Subscribes(e.Type) {
+			continue
+		}
+		history, err := s.registry.ListDeliveries(ctx, webhook.DeliveryFilter{EndpointID: endpoint.ID, EventID: e.ID})
+		if err != nil {
+			return err
+		}
+		if delivered(history) {
+			continue
+		}
+
+		delivery := s.deliver(ctx, endpoint, e, body)
+		delivery.Attempt = len(history) + 1
+		if _, err := s.registry.RecordDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		if !delivery.Success {
+			failed = append(failed, fmt.Sprintf("%s: %s", endpoint.ID, delivery.Error))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", eventhandler.ErrHandlingFailed, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// GetHandledEventTypes returns no types, as endpoints may subscribe to any
+func (s *service) GetHandledEventTypes() []string {
+	return nil
+}
+
+// deliver posts the body to the endpoint once; any 2xx response is a success
+func (s *service) deliver(ctx context.Context, endpoint webhook.Endpoint, e events.Event, body []byte) (delivery webhook.Delivery) {
+	delivery = webhook.Delivery{
+		EndpointID:  endpoint.ID,
+		EventID:     e.ID,
+		EventType:   e.Type,
+		AttemptedAt: time.Now(),
+	}
+	defer func() {
+		delivery.DurationMS = time.Since(delivery.AttemptedAt).Milliseconds()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	timestamp := delivery.AttemptedAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(endpoint.Secret, timestamp, body))
+	req.Header.Set(webhook.EventTypeHeader, e.Type)
+	req.Header.Set(webhook.EventIDHeader, e.ID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("endpoint responded %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// delivered reports whether one of the attempts succeeded
+func delivered(history []webhook.Delivery) bool {
+	for _, d := range history {
+		if d.Success {
+			return true
+		}
+	}
+	return false
+}
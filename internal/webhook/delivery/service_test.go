@@ -0,0 +1,125 @@
+package delivery_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/eventhandler"
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/testkit"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	"github.com/gentra/decorator-arch-go/internal/webhook/delivery"
+	"github.com/gentra/decorator-arch-go/internal/webhook/memory"
+)
+
+// receiver is a webhook endpoint answering with the queued status codes,
+// then 200, and recording the signature checks of what it received
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []string
+	verified []bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(webhook.TimestampHeader), 10, 64)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, req.Header.Get(webhook.EventIDHeader))
+	r.verified = append(r.verified, webhook.Verify(r.secret, req.Header.Get(webhook.SignatureHeader), timestamp, body))
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) snapshot() ([]string, []bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.received...), append([]bool(nil), r.verified...)
+}
+
+func newEvent(eventType string) events.Event {
+	event := *testkit.NewEventBuilder().WithType(eventType).Build()
+	event.ID = uuid.New().String()
+	return event
+}
+
+// register creates an active endpoint for the receiver and serves it
+func register(t *testing.T, registry webhook.Service, r *receiver, eventTypes ...string) *webhook.Endpoint {
+	t.Helper()
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	endpoint, err := registry.Create(context.Background(), webhook.Endpoint{URL: server.URL, EventTypes: eventTypes, Active: true})
+	require.NoError(t, err)
+	r.secret = endpoint.Secret
+	return endpoint
+}
+
+func TestHandle_GivenSubscribedEndpoints_WhenHandling_ThenDeliversSignedEventsToSubscribers(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := memory.NewService()
+	everything, registrations := &receiver{}, &receiver{}
+	register(t, registry, everything, webhook.AllEvents)
+	register(t, registry, registrations, events.EventTypeUserRegistered)
+	handler := delivery.NewService(registry, nil)
+	registered, updated := newEvent(events.EventTypeUserRegistered), newEvent(events.EventTypeUserUpdated)
+
+	// Act
+	require.NoError(t, handler.Handle(ctx, registered))
+	require.NoError(t, handler.Handle(ctx, updated))
+
+	// Assert
+	received, verified := everything.snapshot()
+	assert.Equal(t, []string{registered.ID, updated.ID}, received)
+	assert.Equal(t, []bool{true, true}, verified)
+	received, verified = registrations.snapshot()
+	assert.Equal(t, []string{registered.ID}, received)
+	assert.Equal(t, []bool{true}, verified)
+}
+
+func TestHandle_GivenFailingEndpoint_WhenRetried_ThenOnlyRedeliversToTheFailedEndpoint(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := memory.NewService()
+	healthy, failing := &receiver{}, &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	register(t, registry, healthy, webhook.AllEvents)
+	failingEndpoint := register(t, registry, failing, webhook.AllEvents)
+	handler := delivery.NewService(registry, nil)
+	event := newEvent(events.EventTypeUserRegistered)
+
+	// Act
+	firstErr := handler.Handle(ctx, event)
+	retryErr := handler.Handle(ctx, event)
+
+	// Assert
+	assert.ErrorIs(t, firstErr, eventhandler.ErrHandlingFailed)
+	assert.NoError(t, retryErr)
+	received, _ := healthy.snapshot()
+	assert.Len(t, received, 1)
+	received, _ = failing.snapshot()
+	assert.Len(t, received, 2)
+	history, err := registry.ListDeliveries(ctx, webhook.DeliveryFilter{EndpointID: failingEndpoint.ID})
+	require.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, 2, history[0].Attempt)
+		assert.True(t, history[0].Success)
+		assert.Equal(t, 1, history[1].Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, history[1].StatusCode)
+		assert.False(t, history[1].Success)
+	}
+}
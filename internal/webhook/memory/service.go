@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+)
+
+// historyLimit bounds the deliveries kept; the oldest are dropped first
+const historyLimit = 1000
+
+// service implements webhook.Service interface in memory, for single
+// instances and tests
+type service struct {
+	mu         sync.RWMutex
+	endpoints  []webhook.Endpoint
+	deliveries []webhook.Delivery
+}
+
+// NewService creates an empty in-memory webhook registry
+func NewService() webhook.Service {
+	return &service{}
+}
+
+// Create registers an endpoint
+func (s *service) Create(ctx context.Context, endpoint webhook.Endpoint) (*webhook.Endpoint, error) {
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+	if endpoint.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = secret
+	}
+	endpoint.ID = uuid.New().String()
+	endpoint.EventTypes = append([]string(nil), endpoint.EventTypes...)
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = append(s.endpoints, endpoint)
+	return &endpoint, nil
+}
+
+// Get returns an endpoint
+func (s *service) Get(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.find(id); i >= 0 {
+		endpoint := s.endpoints[i]
+		return &endpoint, nil
+	}
+	return nil, webhook.ErrEndpointNotFound
+}
+
+// List returns every endpoint, oldest first
+func (s *service) List(ctx context.Context) ([]webhook.Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]webhook.Endpoint{}, s.endpoints...), nil
+}
+
+// Update replaces an endpoint's settings, keeping its secret
+func (s *service) Update(ctx context.Context, endpoint webhook.Endpoint) (*webhook.Endpoint, error) {
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(endpoint.ID)
+	if i < 0 {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	stored := &s.endpoints[i]
+	stored.URL = endpoint.URL
+	stored.EventTypes = append([]string(nil), endpoint.EventTypes...)
+	stored.Description = endpoint.Description
+	stored.Active = endpoint.Active
+	stored.UpdatedAt = time.Now()
+	updated := *stored
+	return &updated, nil
+}
+
+// Delete removes an endpoint and its deliveries
+func (s *service) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(id)
+	if i < 0 {
+		return webhook.ErrEndpointNotFound
+	}
+	s.endpoints = append(s.endpoints[:i], s.endpoints[i+1:]...)
+
+	kept := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if delivery.EndpointID != id {
+			kept = append(kept, delivery)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// RotateSecret gives an endpoint a new secret
+func (s *service) RotateSecret(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(id)
+	if i < 0 {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	s.endpoints[i].Secret = secret
+	s.endpoints[i].UpdatedAt = time.Now()
+	rotated := s.endpoints[i]
+	return &rotated, nil
+}
+
+// RecordDelivery adds a delivery attempt, dropping the oldest beyond the
+// history limit
+func (s *service) RecordDelivery(ctx context.Context, delivery webhook.Delivery) (*webhook.Delivery, error) {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if delivery.AttemptedAt.IsZero() {
+		delivery.AttemptedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	if len(s.deliveries) > historyLimit {
+		s.deliveries = s.deliveries[len(s.deliveries)-historyLimit:]
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries returns matching deliveries, newest first
+func (s *service) ListDeliveries(ctx context.Context, filter webhook.DeliveryFilter) ([]webhook.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []webhook.Delivery{}
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+		if filter.Matches(s.deliveries[i]) {
+			result = append(result, s.deliveries[i])
+		}
+	}
+	return result, nil
+}
+
+// find returns the index of an endpoint, or -1; callers hold mu
+func (s *service) find(id string) int {
+	for i, endpoint := range s.endpoints {
+		if endpoint.ID == id {
+			return i
+		}
+	}
+	return -1
+}
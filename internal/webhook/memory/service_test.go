@@ -0,0 +1,115 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/internal/events"
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+	"github.com/gentra/decorator-arch-go/internal/webhook/memory"
+)
+
+func TestCreate_GivenEndpoint_WhenCreating_ThenValidatesAndGeneratesSecret(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      webhook.Endpoint
+		expectedError error
+	}{
+		{
+			name:     "Given a valid endpoint, When creating, Then stores it with a generated secret",
+			endpoint: webhook.Endpoint{URL: "https://hooks.example.com/users", EventTypes: []string{events.EventTypeUserRegistered}, Active: true},
+		},
+		{
+			name:          "Given a relative URL, When creating, Then returns invalid endpoint",
+			endpoint:      webhook.Endpoint{URL: "/users", EventTypes: []string{webhook.AllEvents}},
+			expectedError: webhook.ErrInvalidEndpoint,
+		},
+		{
+			name:          "Given no event types, When creating, Then returns invalid endpoint",
+			endpoint:      webhook.Endpoint{URL: "https://hooks.example.com/users"},
+			expectedError: webhook.ErrInvalidEndpoint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			registry := memory.NewService()
+
+			// Act
+			created, err := registry.Create(context.Background(), tt.endpoint)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, created.ID)
+			assert.Contains(t, created.Secret, "whsec_")
+			stored, err := registry.Get(context.Background(), created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, *created, *stored)
+		})
+	}
+}
+
+func TestRotateSecret_GivenEndpoint_WhenRotating_ThenReplacesSecretAndKeepsItOnUpdate(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := memory.NewService()
+	created, err := registry.Create(ctx, webhook.Endpoint{URL: "https://hooks.example.com", EventTypes: []string{webhook.AllEvents}, Active: true})
+	require.NoError(t, err)
+
+	// Act
+	rotated, err := registry.RotateSecret(ctx, created.ID)
+	require.NoError(t, err)
+	update := *created
+	update.Secret = ""
+	update.Active = false
+	updated, err := registry.Update(ctx, update)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.Equal(t, rotated.Secret, updated.Secret)
+	assert.False(t, updated.Active)
+	_, err = registry.RotateSecret(ctx, "missing")
+	assert.ErrorIs(t, err, webhook.ErrEndpointNotFound)
+}
+
+func TestListDeliveries_GivenHistory_WhenFiltering_ThenReturnsMatchingNewestFirst(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := memory.NewService()
+	first, err := registry.Create(ctx, webhook.Endpoint{URL: "https://one.example.com", EventTypes: []string{webhook.AllEvents}})
+	require.NoError(t, err)
+	second, err := registry.Create(ctx, webhook.Endpoint{URL: "https://two.example.com", EventTypes: []string{webhook.AllEvents}})
+	require.NoError(t, err)
+	for _, d := range []webhook.Delivery{
+		{EndpointID: first.ID, EventID: "event-1", Attempt: 1},
+		{EndpointID: second.ID, EventID: "event-1", Attempt: 1},
+		{EndpointID: first.ID, EventID: "event-1", Attempt: 2, Success: true},
+	} {
+		_, err := registry.RecordDelivery(ctx, d)
+		require.NoError(t, err)
+	}
+
+	// Act
+	deliveries, err := registry.ListDeliveries(ctx, webhook.DeliveryFilter{EndpointID: first.ID, EventID: "event-1"})
+
+	// Assert
+	require.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, 2, deliveries[0].Attempt)
+		assert.Equal(t, 1, deliveries[1].Attempt)
+	}
+	require.NoError(t, registry.Delete(ctx, first.ID))
+	remaining, err := registry.ListDeliveries(ctx, webhook.DeliveryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	assert.ErrorIs(t, registry.Delete(ctx, first.ID), webhook.ErrEndpointNotFound)
+}
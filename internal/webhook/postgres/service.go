@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gentra/decorator-arch-go/internal/webhook"
+)
+
+// endpointColumns are the webhook_endpoints columns scanEndpoint reads, in order
+const endpointColumns = `id, url, event_types, secret, description, active, created_at, updated_at`
+
+// deliveryColumns are the webhook_deliveries columns scanDelivery reads, in order
+const deliveryColumns = `id, endpoint_id, event_id, event_type, attempt, status_code, error, success,
+	duration_ms, attempted_at`
+
+// service implements webhook.Service on the webhook_endpoints and
+// webhook_deliveries tables, so every instance delivers to the same endpoints
+type service struct {
+	pool *pgxpool.Pool
+}
+
+// NewService creates a Postgres-backed webhook registry
+func NewService(pool *pgxpool.Pool) webhook.Service {
+	return &service{pool: pool}
+}
+
+// Create inserts an endpoint
+func (s *service) Create(ctx context.Context, endpoint webhook.Endpoint) (*webhook.Endpoint, error) {
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+	if endpoint.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = secret
+	}
+
+	return scanEndpoint(s.pool.QueryRow(ctx, `
+		INSERT INTO webhook_endpoints (id, url, event_types, secret, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, now(), now())
+		RETURNING `+endpointColumns,
+		uuid.New().String(), endpoint.URL, endpoint.EventTypes, endpoint.Secret, endpoint.Description, endpoint.Active))
+}
+
+// Get returns an endpoint
+func (s *service) Get(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	endpoint, err := scanEndpoint(s.pool.QueryRow(ctx, `SELECT `+endpointColumns+` FROM webhook_endpoints WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	return endpoint, err
+}
+
+// List returns every endpoint, oldest first
+func (s *service) List(ctx context.Context) ([]webhook.Endpoint, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+endpointColumns+` FROM webhook_endpoints ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []webhook.Endpoint{}
+	for rows.Next() {
+		endpoint, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, *endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// Update replaces an endpoint's settings, keeping its secret
+func (s *service) Update(ctx context.Context, endpoint webhook.Endpoint) (*webhook.Endpoint, error) {
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := scanEndpoint(s.pool.QueryRow(ctx, `
+		UPDATE webhook_endpoints
+		SET url = $2, event_types = $3, description = $4, active = $5, updated_at = now()
+		WHERE id = $1
+		RETURNING `+endpointColumns,
+		endpoint.ID, endpoint.URL, endpoint.EventTypes, endpoint.Description, endpoint.Active))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	return updated, err
+}
+
+// Delete removes an endpoint; its deliveries go with it
+func (s *service) Delete(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+	return nil
+}
+
+// RotateSecret gives an endpoint a new secret
+func (s *service) RotateSecret(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := scanEndpoint(s.pool.QueryRow(ctx, `
+		UPDATE webhook_endpoints SET secret = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING `+endpointColumns, id, secret))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	return rotated, err
+}
+
+// RecordDelivery inserts a delivery attempt
+func (s *service) RecordDelivery(ctx context.Context, delivery webhook.Delivery) (*webhook.Delivery, error) {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if delivery.AttemptedAt.IsZero() {
+		delivery.AttemptedAt = time.Now()
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		delivery.ID, delivery.EndpointID, delivery.EventID, delivery.EventType, delivery.Attempt, delivery.StatusCode,
+		delivery.Error, delivery.Success, delivery.DurationMS, delivery.AttemptedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries returns matching deliveries, newest first
+func (s *service) ListDeliveries(ctx context.Context, filter webhook.DeliveryFilter) ([]webhook.Delivery, error) {
+	var conditions []string
+	var args []any
+	if filter.EndpointID != "" {
+		args = append(args, filter.EndpointID)
+		conditions = append(conditions, fmt.Sprintf("endpoint_id = $%d", len(args)))
+	}
+	if filter.EventID != "" {
+		args = append(args, filter.EventID)
+		conditions = append(conditions, fmt.Sprintf("event_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY attempted_at DESC, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	for rows.Next() {
+		var d webhook.Delivery
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Attempt, &d.StatusCode,
+			&d.Error, &d.Success, &d.DurationMS, &d.AttemptedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// scanEndpoint reads one endpoint in endpointColumns order
+func scanEndpoint(row pgx.Row) (*webhook.Endpoint, error) {
+	var endpoint webhook.Endpoint
+	if err := row.Scan(&endpoint.ID, &endpoint.URL, &endpoint.EventTypes, &endpoint.Secret, &endpoint.Description,
+		&endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
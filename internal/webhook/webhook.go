@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Service defines the webhook domain interface - the ONLY interface in this domain.
+// It keeps the external endpoints administrators register to receive domain
+// events, and the history of the deliveries made to them.
+type Service interface {
+	// Create registers an endpoint, generating its ID and, unless given, its
+	// secret; ErrInvalidEndpoint when the URL or event types are unusable
+	Create(ctx context.Context, endpoint Endpoint) (*Endpoint, error)
+
+	// Get returns an endpoint, or ErrEndpointNotFound
+	Get(ctx context.Context, id string) (*Endpoint, error)
+
+	// List returns every endpoint, oldest first
+	List(ctx context.Context) ([]Endpoint, error)
+
+	// Update replaces the URL, event types, description and active flag of
+	// an endpoint; its secret only changes through RotateSecret
+	Update(ctx context.Context, endpoint Endpoint) (*Endpoint, error)
+
+	// Delete removes an endpoint and its delivery history, or returns
+	// ErrEndpointNotFound
+	Delete(ctx context.Context, id string) error
+
+	// RotateSecret replaces the secret deliveries to the endpoint are signed
+	// with and returns the endpoint with the new secret
+	RotateSecret(ctx context.Context, id string) (*Endpoint, error)
+
+	// RecordDelivery adds an attempt to deliver an event to the history
+	RecordDelivery(ctx context.Context, delivery Delivery) (*Delivery, error)
+
+	// ListDeliveries returns delivery attempts, newest first
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error)
+}
+
+// Domain types and data structures
+
+// Endpoint is an external URL receiving the events of the types it
+// subscribes to, signed with its secret
+type Endpoint struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	// EventTypes are the event types delivered, "*" for every event
+	EventTypes []string `json:"event_types"`
+
+	// Secret signs every delivery; only shown when created or rotated
+	Secret string `json:"secret,omitempty"`
+
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether events of the type are delivered to the endpoint
+func (e Endpoint) Subscribes(eventType string) bool {
+	for _, t := range e.EventTypes {
+		if t == AllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the endpoint has an absolute http or https URL and at
+// least one event type
+func (e Endpoint) Validate() error {
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidEndpoint)
+	}
+	if len(e.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidEndpoint)
+	}
+	for _, t := range e.EventTypes {
+		if t == "" {
+			return fmt.Errorf("%w: event types cannot be empty", ErrInvalidEndpoint)
+		}
+	}
+	return nil
+}
+
+// Delivery is one attempt to deliver an event to an endpoint
+type Delivery struct {
+	ID          string    `json:"id"`
+	EndpointID  string    `json:"endpoint_id"`
+	EventID     string    `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Success     bool      `json:"success"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// DeliveryFilter narrows the deliveries returned by ListDeliveries
+type DeliveryFilter struct {
+	EndpointID string `json:"endpoint_id,omitempty"`
+	EventID    string `json:"event_id,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// Matches reports whether the delivery satisfies the filter
+func (f DeliveryFilter) Matches(delivery Delivery) bool {
+	if f.EndpointID != "" && f.EndpointID != delivery.EndpointID {
+		return false
+	}
+	return f.EventID == "" || f.EventID == delivery.EventID
+}
+
+// AllEvents subscribes an endpoint to every event type
+const AllEvents = "*"
+
+// Headers of a delivery. The signature is "v1=" followed by the hex HMAC-SHA256
+// of the timestamp, a dot and the body, keyed with the endpoint's secret.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventTypeHeader = "X-Webhook-Event"
+	EventIDHeader   = "X-Webhook-Event-ID"
+)
+
+// Sign returns the signature of a delivery body sent at the Unix timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the body and timestamp, for
+// receivers checking a delivery came from this service
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// NewSecret generates a random endpoint secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// WebhookError represents webhook domain errors
+type WebhookError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e WebhookError) Error() string {
+	return e.Message
+}
+
+// Common webhook errors
+var (
+	ErrEndpointNotFound = WebhookError{Code: "WEBHOOK_NOT_FOUND", Message: "Webhook endpoint not found"}
+	ErrInvalidEndpoint  = WebhookError{Code: "WEBHOOK_INVALID", Message: "Invalid webhook endpoint"}
+)
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS email_change_requested_at;
+ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
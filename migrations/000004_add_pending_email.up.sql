@@ -0,0 +1,3 @@
+-- Email changes are held here until the user confirms the new address
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_requested_at TIMESTAMPTZ;
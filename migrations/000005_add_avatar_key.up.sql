@@ -0,0 +1,2 @@
+-- Avatar images live in blob storage; the row only records where
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Dropped keys cannot be restored; the normalized rows remain valid.
+SELECT 1;
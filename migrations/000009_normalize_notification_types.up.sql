@@ -0,0 +1,31 @@
+-- Rewrites stored notification types to the declared schema: undeclared keys
+-- and non-boolean values are dropped, missing types get their defaults, and
+-- values that are not objects are replaced by the defaults.
+-- Keep the defaults in line with user.DefaultPreferenceSchema.
+WITH normalized AS (
+    SELECT
+        p.id,
+        '{
+            "task_assigned": true,
+            "task_due_soon": true,
+            "project_updated": true,
+            "project_invite": true,
+            "system_updates": false,
+            "marketing": false
+        }'::jsonb || COALESCE((
+            SELECT jsonb_object_agg(entry.key, entry.value)
+            FROM jsonb_each(CASE WHEN jsonb_typeof(p.notification_types) = 'object'
+                                 THEN p.notification_types END) AS entry
+            WHERE entry.key IN ('task_assigned', 'task_due_soon', 'project_updated',
+                                'project_invite', 'system_updates', 'marketing')
+              AND jsonb_typeof(entry.value) = 'boolean'
+        ), '{}'::jsonb) AS notification_types
+    FROM user_preferences p
+)
+UPDATE user_preferences p
+SET notification_types = normalized.notification_types,
+    version = p.version + 1,
+    updated_at = NOW()
+FROM normalized
+WHERE p.id = normalized.id
+  AND p.notification_types IS DISTINCT FROM normalized.notification_types;
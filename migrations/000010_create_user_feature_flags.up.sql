@@ -0,0 +1,8 @@
+-- Feature flags switched on or off per user; flags without a row are off
+CREATE TABLE IF NOT EXISTS user_feature_flags (
+    user_id UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    flag VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, flag)
+);
@@ -0,0 +1,4 @@
+-- Set once the user proves they control their email; users registered before
+-- verification existed are treated as verified so they can still sign in
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,2 @@
+-- Set while a password reset is outstanding; tokens issued before it are stale
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_requested_at TIMESTAMPTZ;
@@ -0,0 +1,11 @@
+-- Asymmetric keys tokens are signed with; retired keys still verify the
+-- tokens they signed until those expire and the key is pruned
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id TEXT PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_created_at ON signing_keys(created_at);
@@ -0,0 +1,12 @@
+-- Server-side claims of opaque tokens, keyed by the SHA-256 of the token
+CREATE TABLE IF NOT EXISTS opaque_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    claims JSONB NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_opaque_tokens_user_id ON opaque_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_opaque_tokens_expires_at ON opaque_tokens(expires_at);
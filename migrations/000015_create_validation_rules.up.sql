@@ -0,0 +1,17 @@
+-- Validation rule configurations managed at runtime through the admin API
+CREATE TABLE IF NOT EXISTS validation_rules (
+    rule_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(32) NOT NULL DEFAULT '',
+    field TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    priority INTEGER NOT NULL DEFAULT 100,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    parameters JSONB NOT NULL DEFAULT '{}',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,28 @@
+-- Events of event-sourced aggregates, one per aggregate version, in the
+-- order they were appended
+CREATE TABLE IF NOT EXISTS event_store (
+    position BIGSERIAL PRIMARY KEY,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL DEFAULT '',
+    aggregate_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT event_store_aggregate_version_key UNIQUE (aggregate_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_event_type ON event_store(event_type);
+CREATE INDEX IF NOT EXISTS idx_event_store_occurred_at ON event_store(occurred_at);
+
+-- Latest state of an aggregate as of a version, so loading it only replays
+-- the events after that version
+CREATE TABLE IF NOT EXISTS event_snapshots (
+    aggregate_id TEXT PRIMARY KEY,
+    aggregate_type TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
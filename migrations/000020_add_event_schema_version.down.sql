@@ -0,0 +1 @@
+ALTER TABLE event_store DROP COLUMN IF EXISTS schema_version;
@@ -0,0 +1,3 @@
+-- Version of the payload contract each stored event's data follows, so
+-- replays can upcast events written under older contracts
+ALTER TABLE event_store ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 1;
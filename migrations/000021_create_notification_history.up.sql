@@ -0,0 +1,19 @@
+-- Notifications sent to users, with the delivery status providers report
+CREATE TABLE IF NOT EXISTS notification_history (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT 'null',
+    status TEXT NOT NULL,
+    priority TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    read_at TIMESTAMPTZ,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_history_user ON notification_history(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_history_status ON notification_history(status);
@@ -0,0 +1,14 @@
+-- Saved versions of notification templates, overriding the shipped ones
+CREATE TABLE IF NOT EXISTS notification_templates (
+    name TEXT NOT NULL,
+    language TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    channel TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    html TEXT NOT NULL DEFAULT '',
+    variables JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (name, language, version)
+);
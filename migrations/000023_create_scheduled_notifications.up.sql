@@ -0,0 +1,19 @@
+-- Notifications queued for later delivery, leased to one worker at a time
+CREATE TABLE IF NOT EXISTS scheduled_notifications (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL DEFAULT '',
+    channel TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    leased_by TEXT NOT NULL DEFAULT '',
+    lease_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_notifications_due ON scheduled_notifications(status, send_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_notifications_user ON scheduled_notifications(user_id);
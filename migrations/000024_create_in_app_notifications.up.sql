@@ -0,0 +1,16 @@
+-- In-app notifications in the users' inboxes, unread until read_at is set
+CREATE TABLE IF NOT EXISTS in_app_notifications (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT 'null',
+    priority TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_in_app_notifications_user ON in_app_notifications(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_in_app_notifications_unread ON in_app_notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_digest_items;
+DROP TABLE IF EXISTS notification_digest_settings;
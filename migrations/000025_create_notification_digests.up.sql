@@ -0,0 +1,23 @@
+-- Users' digest settings: how often they get a digest and which notification types it collects
+CREATE TABLE IF NOT EXISTS notification_digest_settings (
+    user_id TEXT PRIMARY KEY,
+    frequency TEXT NOT NULL DEFAULT 'off',
+    deliver_at TEXT NOT NULL DEFAULT '',
+    types TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Notifications held for a user's next digest, which is due at the earliest due_at of the user's rows
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT 'null',
+    priority TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    due_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digest_items_user ON notification_digest_items(user_id, due_at);
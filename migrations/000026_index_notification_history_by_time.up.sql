@@ -0,0 +1,2 @@
+-- Delivery statistics and history pages over a time range across all users
+CREATE INDEX IF NOT EXISTS idx_notification_history_created ON notification_history(created_at, type);
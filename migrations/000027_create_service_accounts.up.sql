@@ -0,0 +1,17 @@
+-- Service accounts: non-human identities of an organization holding delegated scopes.
+-- Only the SHA-256 hash of each account's secret is stored.
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    secret_hash BYTEA NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    secret_rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    disabled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_accounts_organization ON service_accounts(organization_id, created_at);
@@ -0,0 +1,11 @@
+-- Progressive profiling: each user's response to a profile prompt.
+-- Prompts without a row are still pending.
+CREATE TABLE IF NOT EXISTS profile_fields (
+    user_id TEXT NOT NULL,
+    field TEXT NOT NULL,
+    status TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    remind_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, field)
+);
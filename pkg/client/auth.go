@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Register creates a new account
+func (c *Client) Register(ctx context.Context, data RegisterRequest) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/register",
+		body:   data,
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// VerifyEmail redeems the token emailed on registration and returns the
+// verified user; no sign-in is needed. Once the server's grace period is
+// over, Login fails with ErrEmailNotVerified until this is done.
+func (c *Client) VerifyEmail(ctx context.Context, token string) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/verify-email",
+		body:   VerifyEmailRequest{Token: token},
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RequestPasswordReset asks for a password reset token to be emailed to the
+// account with email; no sign-in is needed. The call succeeds whether or not
+// such an account exists.
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/password-reset/request",
+		body:   PasswordResetRequest{Email: email},
+	}, nil)
+}
+
+// ResetPassword redeems a token from RequestPasswordReset, replacing the
+// password and signing the user out everywhere. Each token works once.
+func (c *Client) ResetPassword(ctx context.Context, data ResetPasswordRequest) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/password-reset/confirm",
+		body:   data,
+	}, nil)
+}
+
+// Login authenticates with email and password and stores the issued tokens
+func (c *Client) Login(ctx context.Context, email, password string) (*AuthResult, error) {
+	var result AuthResult
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/login",
+		body:   LoginRequest{Email: email, Password: password},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storeTokens(&result)
+	return &result, nil
+}
+
+// Logout revokes the current session and clears the stored tokens
+func (c *Client) Logout(ctx context.Context) error {
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/auth/logout",
+		authenticated: true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	c.SetTokens("", "")
+	return nil
+}
+
+// LogoutAll revokes every session and token of the authenticated user,
+// including those on other devices, and clears the stored tokens
+func (c *Client) LogoutAll(ctx context.Context) error {
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/auth/logout-all",
+		authenticated: true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	c.SetTokens("", "")
+	return nil
+}
+
+// Introspect reports whether token is active and, when it is, whom it was
+// issued to. Tokens that are expired, revoked or malformed are reported
+// inactive rather than failing the call.
+func (c *Client) Introspect(ctx context.Context, token string) (*TokenIntrospection, error) {
+	var introspection TokenIntrospection
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/auth/introspect",
+		body:          IntrospectRequest{Token: token},
+		authenticated: true,
+	}, &introspection)
+	if err != nil {
+		return nil, err
+	}
+	return &introspection, nil
+}
+
+// Me returns the currently authenticated user
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/auth/me",
+		authenticated: true,
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RefreshToken exchanges the stored refresh token for a new token pair.
+// Calls made while another refresh is in flight share its result.
+func (c *Client) RefreshToken(ctx context.Context) error {
+	accessToken, _ := c.Tokens()
+	return c.refresh(ctx, accessToken)
+}
+
+// RequestMagicLink asks for a sign-in link to be emailed to the account with
+// email; no sign-in is needed. The call succeeds whether or not such an
+// account exists.
+func (c *Client) RequestMagicLink(ctx context.Context, email string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/magic-link",
+		body:   MagicLinkRequest{Email: email},
+	}, nil)
+}
+
+// VerifyMagicLink redeems the token from an emailed sign-in link and stores
+// the issued tokens, like Login. Each token works once.
+func (c *Client) VerifyMagicLink(ctx context.Context, token string) (*AuthResult, error) {
+	var result AuthResult
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/magic-link/verify",
+		body:   VerifyMagicLinkRequest{Token: token},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storeTokens(&result)
+	return &result, nil
+}
@@ -0,0 +1,286 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config contains all configuration for the API client
+type Config struct {
+	// BaseURL is the scheme and host of the API, e.g. "https://api.example.com"
+	BaseURL string
+
+	// HTTPClient is used for all requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+
+	// UserAgent is sent with every request
+	UserAgent string
+
+	// SessionID identifies this client session; realtime updates caused by
+	// its own requests are not echoed back to it
+	SessionID string
+
+	// Retry behaviour for transient failures
+	Retry RetryConfig
+
+	// AutoRefresh refreshes the access token once when the API reports it expired
+	AutoRefresh bool
+}
+
+// RetryConfig contains retry configuration for transient failures
+type RetryConfig struct {
+	MaxRetries    int
+	InitialDelay  time.Duration
+	BackoffFactor float64
+	MaxDelay      time.Duration
+}
+
+// DefaultConfig returns a sensible default client configuration
+func DefaultConfig(baseURL string) Config {
+	return Config{
+		BaseURL:     baseURL,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+		UserAgent:   "decorator-arch-go-client/1.0",
+		AutoRefresh: true,
+		Retry: RetryConfig{
+			MaxRetries:    3,
+			InitialDelay:  200 * time.Millisecond,
+			BackoffFactor: 2.0,
+			MaxDelay:      5 * time.Second,
+		},
+	}
+}
+
+// Client is a Go SDK for the public REST API
+type Client struct {
+	config Config
+
+	mu           sync.RWMutex
+	accessToken  string
+	refreshToken string
+
+	// refreshMu serializes refreshes: the server rotates refresh tokens and
+	// revokes the whole family when one is presented twice
+	refreshMu sync.Mutex
+}
+
+// NewClient creates a new API client with the given configuration
+func NewClient(config Config) (*Client, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("base URL is required")
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	return &Client{config: config}, nil
+}
+
+// SetTokens sets the access and refresh tokens used for authenticated requests
+func (c *Client) SetTokens(accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = accessToken
+	c.refreshToken = refreshToken
+}
+
+// Tokens returns the current access and refresh tokens
+func (c *Client) Tokens() (accessToken, refreshToken string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessToken, c.refreshToken
+}
+
+// request describes a single API call
+type request struct {
+	method         string
+	path           string
+	body           interface{}
+	authenticated  bool
+	idempotencyKey string
+
+	// contentType sends body, which must then be a []byte, as is instead of
+	// encoding it as JSON
+	contentType string
+}
+
+// do performs the request with retries and transparent token refresh
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	// Mutating requests always carry an idempotency key so retries are safe
+	if req.idempotencyKey == "" && req.method != http.MethodGet {
+		req.idempotencyKey = uuid.New().String()
+	}
+
+	accessToken, _ := c.Tokens()
+	err := c.doWithRetry(ctx, req, out)
+	if err == nil || !req.authenticated || !c.config.AutoRefresh || !IsTokenExpired(err) {
+		return err
+	}
+
+	if refreshErr := c.refresh(ctx, accessToken); refreshErr != nil {
+		return err
+	}
+
+	return c.doWithRetry(ctx, req, out)
+}
+
+// doWithRetry retries transient failures using exponential backoff
+func (c *Client) doWithRetry(ctx context.Context, req request, out interface{}) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.config.Retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff(attempt)):
+			}
+		}
+
+		lastErr = c.send(ctx, req, out)
+		if lastErr == nil || !isRetryable(lastErr) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// send performs a single HTTP round trip
+func (c *Client) send(ctx context.Context, req request, out interface{}) error {
+	var body io.Reader
+	contentType := "application/json"
+	switch {
+	case req.contentType != "":
+		payload, _ := req.body.([]byte)
+		body = bytes.NewReader(payload)
+		contentType = req.contentType
+	case req.body != nil:
+		payload, err := json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.config.BaseURL+req.path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	if req.body != nil {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	if c.config.SessionID != "" {
+		httpReq.Header.Set("X-Session-ID", c.config.SessionID)
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+	if req.authenticated {
+		accessToken, _ := c.Tokens()
+		if accessToken != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+		}
+	}
+
+	resp, err := c.config.HTTPClient.Do(httpReq)
+	if err != nil {
+		return &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// refresh exchanges the refresh token for a new token pair, replacing
+// staleAccessToken. Concurrent callers wait for the refresh in flight and
+// reuse its tokens. The call is never retried: when its response is lost
+// the server has already rotated the token, and resending it would be
+// taken as reuse and sign the user out.
+func (c *Client) refresh(ctx context.Context, staleAccessToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	accessToken, refreshToken := c.Tokens()
+	if accessToken != staleAccessToken {
+		return nil
+	}
+	if refreshToken == "" {
+		return ErrInvalidRefreshToken
+	}
+
+	var result AuthResult
+	err := c.send(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/refresh",
+		body:   map[string]string{"refresh_token": refreshToken},
+	}, &result)
+	if err != nil {
+		return err
+	}
+
+	c.storeTokens(&result)
+	return nil
+}
+
+// storeTokens keeps the tokens from an authentication result
+func (c *Client) storeTokens(result *AuthResult) {
+	refreshToken := result.RefreshToken
+	if refreshToken == "" {
+		_, refreshToken = c.Tokens()
+	}
+	c.SetTokens(result.Token, refreshToken)
+}
+
+// backoff returns the delay before the given retry attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	retry := c.config.Retry
+	factor := retry.BackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+
+	delay := time.Duration(float64(retry.InitialDelay) * math.Pow(factor, float64(attempt-1)))
+	if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+		delay = retry.MaxDelay
+	}
+	return delay
+}
+
+// transportError wraps network-level failures, which are always retryable
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
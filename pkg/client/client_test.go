@@ -0,0 +1,263 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/pkg/client"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *client.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := client.DefaultConfig(server.URL)
+	config.Retry.InitialDelay = time.Millisecond
+	config.Retry.MaxDelay = 5 * time.Millisecond
+
+	c, err := client.NewClient(config)
+	require.NoError(t, err)
+	return c
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
+
+func TestNewClient_GivenEmptyBaseURL_WhenCreating_ThenReturnsError(t *testing.T) {
+	c, err := client.NewClient(client.Config{})
+
+	assert.Error(t, err)
+	assert.Nil(t, c)
+}
+
+func TestLogin_GivenValidCredentials_WhenLoggingIn_ThenStoresTokens(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/auth/login", r.URL.Path)
+		assert.NotEmpty(t, r.Header.Get("Idempotency-Key"))
+
+		_ = json.NewEncoder(w).Encode(client.AuthResult{
+			User:         &client.User{ID: "user-1", Email: "test@example.com"},
+			Token:        "access-1",
+			RefreshToken: "refresh-1",
+		})
+	}))
+
+	result, err := c.Login(context.Background(), "test@example.com", "SecurePass123!")
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", result.User.ID)
+	accessToken, refreshToken := c.Tokens()
+	assert.Equal(t, "access-1", accessToken)
+	assert.Equal(t, "refresh-1", refreshToken)
+}
+
+func TestErrors_GivenErrorEnvelope_WhenCallingAPI_ThenReturnsTypedError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		expected error
+	}{
+		{
+			name:     "user not found",
+			status:   http.StatusNotFound,
+			code:     "USER_NOT_FOUND",
+			expected: client.ErrUserNotFound,
+		},
+		{
+			name:     "email already exists",
+			status:   http.StatusConflict,
+			code:     "EMAIL_EXISTS",
+			expected: client.ErrEmailAlreadyExists,
+		},
+		{
+			name:     "invalid credentials",
+			status:   http.StatusUnauthorized,
+			code:     "INVALID_CREDENTIALS",
+			expected: client.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, tt.status, tt.code, "failure")
+			}))
+			c.SetTokens("access", "")
+
+			_, err := c.GetProfile(context.Background())
+
+			assert.True(t, errors.Is(err, tt.expected))
+			var apiErr *client.APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+		})
+	}
+}
+
+func TestRetry_GivenTransientFailures_WhenUpdatingProfile_ThenRetriesWithSameIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		attempt := len(keys)
+		mu.Unlock()
+
+		if attempt < 3 {
+			writeError(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "try again")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(client.User{ID: "user-1", FirstName: "Jane"})
+	}))
+	c.SetTokens("access", "")
+
+	name := "Jane"
+	user, err := c.UpdateProfile(context.Background(), client.UpdateProfileRequest{FirstName: &name})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", user.FirstName)
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func TestRetry_GivenClientError_WhenCallingAPI_ThenDoesNotRetry(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeError(w, http.StatusBadRequest, "VALIDATION_FAILED", "invalid")
+	}))
+
+	_, err := c.Register(context.Background(), client.RegisterRequest{Email: "bad"})
+
+	assert.True(t, errors.Is(err, client.ErrValidation))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRefresh_GivenExpiredAccessToken_WhenCallingAPI_ThenRefreshesAndRetries(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "refresh-1", body["refresh_token"])
+
+		_ = json.NewEncoder(w).Encode(client.AuthResult{Token: "access-2", RefreshToken: "refresh-2"})
+	})
+	mux.HandleFunc("/api/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			writeError(w, http.StatusUnauthorized, "TOKEN_EXPIRED", "Token has expired")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(client.User{ID: "user-1"})
+	})
+
+	c := newTestClient(t, mux)
+	c.SetTokens("access-1", "refresh-1")
+
+	user, err := c.Me(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	accessToken, refreshToken := c.Tokens()
+	assert.Equal(t, "access-2", accessToken)
+	assert.Equal(t, "refresh-2", refreshToken)
+}
+
+func TestRefresh_GivenLostRefreshResponse_WhenCallingAPI_ThenDoesNotResendTheRefreshToken(t *testing.T) {
+	var refreshes int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		writeError(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "try again")
+	})
+	mux.HandleFunc("/api/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusUnauthorized, "TOKEN_EXPIRED", "Token has expired")
+	})
+
+	c := newTestClient(t, mux)
+	c.SetTokens("access-1", "refresh-1")
+
+	_, err := c.Me(context.Background())
+
+	assert.True(t, client.IsTokenExpired(err))
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestRefresh_GivenConcurrentExpiredRequests_WhenCallingAPI_ThenRefreshesOnce(t *testing.T) {
+	var mu sync.Mutex
+	var presented []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		presented = append(presented, body["refresh_token"])
+		mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(client.AuthResult{Token: "access-2", RefreshToken: "refresh-2"})
+	})
+	mux.HandleFunc("/api/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			writeError(w, http.StatusUnauthorized, "TOKEN_EXPIRED", "Token has expired")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(client.User{ID: "user-1"})
+	})
+
+	c := newTestClient(t, mux)
+	c.SetTokens("access-1", "refresh-1")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = c.Me(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"refresh-1"}, presented)
+}
+
+func TestUploadAvatar_GivenImage_WhenUploading_ThenSendsRawBodyWithContentType(t *testing.T) {
+	var contentType string
+	var body []byte
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	c.SetTokens("access", "")
+
+	err := c.UploadAvatar(context.Background(), []byte("\x89PNG"), "image/png")
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, []byte("\x89PNG"), body)
+}
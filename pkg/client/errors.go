@@ -0,0 +1,108 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// APIError represents an error returned by the API. Codes mirror the
+// domain error codes used by the server (user, auth and token domains).
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Field      string `json:"field,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Is reports whether the target error carries the same error code
+func (e *APIError) Is(target error) bool {
+	var t *APIError
+	if !errors.As(target, &t) {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Common API error codes
+var (
+	// User domain
+	ErrUserNotFound        = &APIError{Code: "USER_NOT_FOUND", Message: "User not found"}
+	ErrEmailAlreadyExists  = &APIError{Code: "EMAIL_EXISTS", Message: "Email already exists"}
+	ErrInvalidCredentials  = &APIError{Code: "INVALID_CREDENTIALS", Message: "Invalid email or password"}
+	ErrInvalidEmail        = &APIError{Code: "INVALID_EMAIL", Message: "Invalid email format"}
+	ErrWeakPassword        = &APIError{Code: "WEAK_PASSWORD", Message: "Password must be at least 8 characters"}
+	ErrEmptyFirstName      = &APIError{Code: "EMPTY_FIRST_NAME", Message: "First name is required"}
+	ErrEmptyLastName       = &APIError{Code: "EMPTY_LAST_NAME", Message: "Last name is required"}
+	ErrPreferencesNotFound = &APIError{Code: "PREFERENCES_NOT_FOUND", Message: "User preferences not found"}
+	ErrEmailNotVerified    = &APIError{Code: "EMAIL_NOT_VERIFIED", Message: "Email address not verified"}
+	ErrInvalidVerifyToken  = &APIError{Code: "INVALID_VERIFICATION_TOKEN", Message: "Invalid or expired email verification token"}
+	ErrInvalidResetToken   = &APIError{Code: "INVALID_PASSWORD_RESET_TOKEN", Message: "Invalid or expired password reset token"}
+
+	// Auth and token domains
+	ErrInvalidToken        = &APIError{Code: "INVALID_TOKEN", Message: "Invalid or expired token"}
+	ErrTokenExpired        = &APIError{Code: "TOKEN_EXPIRED", Message: "Token has expired"}
+	ErrTokenRevoked        = &APIError{Code: "TOKEN_REVOKED", Message: "Token has been revoked"}
+	ErrInvalidRefreshToken = &APIError{Code: "INVALID_REFRESH_TOKEN", Message: "Invalid refresh token"}
+
+	// Transport level
+	ErrValidation         = &APIError{Code: "VALIDATION_FAILED", Message: "Validation failed"}
+	ErrRateLimited        = &APIError{Code: "RATE_LIMITED", Message: "Too many requests"}
+	ErrServiceUnavailable = &APIError{Code: "SERVICE_UNAVAILABLE", Message: "Service temporarily unavailable"}
+	ErrInternal           = &APIError{Code: "INTERNAL_ERROR", Message: "Internal server error"}
+)
+
+// errorEnvelope is the error body returned by the API
+type errorEnvelope struct {
+	Error *APIError `json:"error"`
+}
+
+// decodeError converts an error response into an APIError
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Code != "" {
+		envelope.Error.StatusCode = resp.StatusCode
+		return envelope.Error
+	}
+
+	// Fall back to a code derived from the status when the body is not an envelope
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		apiErr.Code = ErrInvalidToken.Code
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Code = ErrRateLimited.Code
+	case resp.StatusCode >= 500:
+		apiErr.Code = ErrInternal.Code
+	default:
+		apiErr.Code = fmt.Sprintf("HTTP_%d", resp.StatusCode)
+	}
+	return apiErr
+}
+
+// IsTokenExpired reports whether the error indicates an expired access token
+func IsTokenExpired(err error) bool {
+	return errors.Is(err, ErrTokenExpired)
+}
+
+// isRetryable reports whether a request failing with err may be retried
+func isRetryable(err error) bool {
+	var transportErr *transportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListInbox returns a page of the authenticated user's in-app notifications,
+// newest first. Pass the page's NextCursor back in query for the next page.
+func (c *Client) ListInbox(ctx context.Context, query InboxQuery) (*InboxPage, error) {
+	values := url.Values{}
+	if query.Cursor != "" {
+		values.Set("cursor", query.Cursor)
+	}
+	if query.UnreadOnly {
+		values.Set("unread", "true")
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	var page InboxPage
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          withQuery("/api/users/me/notifications", values),
+		authenticated: true,
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// InboxUnreadCount returns how many in-app notifications the user has not read
+func (c *Client) InboxUnreadCount(ctx context.Context) (int, error) {
+	var count InboxCount
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/me/notifications/unread-count",
+		authenticated: true,
+	}, &count)
+	if err != nil {
+		return 0, err
+	}
+	return count.UnreadCount, nil
+}
+
+// MarkInboxRead marks an in-app notification as read and returns it
+func (c *Client) MarkInboxRead(ctx context.Context, notificationID string) (*InboxNotification, error) {
+	var notification InboxNotification
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/me/notifications/" + url.PathEscape(notificationID) + "/read",
+		authenticated: true,
+	}, &notification)
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkAllInboxRead marks every in-app notification as read and returns how
+// many were unread
+func (c *Client) MarkAllInboxRead(ctx context.Context) (int, error) {
+	var count InboxCount
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/me/notifications/read-all",
+		authenticated: true,
+	}, &count)
+	if err != nil {
+		return 0, err
+	}
+	return count.MarkedRead, nil
+}
+
+// withQuery appends the encoded values to path, when there are any
+func withQuery(path string, values url.Values) string {
+	if len(values) == 0 {
+		return path
+	}
+	return path + "?" + values.Encode()
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListNotifications returns the authenticated user's notification history
+func (c *Client) ListNotifications(ctx context.Context, limit int) ([]Notification, error) {
+	path := "/api/notifications"
+	if limit > 0 {
+		path += fmt.Sprintf("?limit=%d", limit)
+	}
+
+	var notifications []Notification
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          path,
+		authenticated: true,
+	}, &notifications)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification as read
+func (c *Client) MarkNotificationRead(ctx context.Context, notificationID string) error {
+	return c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/notifications/" + url.PathEscape(notificationID) + "/read",
+		authenticated: true,
+	}, nil)
+}
+
+// ListNotificationHistory returns a page of the notifications sent to the
+// authenticated user, newest first, with their delivery status. Pass the
+// page's NextCursor back in query for the next page.
+func (c *Client) ListNotificationHistory(ctx context.Context, query HistoryQuery) (*HistoryPage, error) {
+	values := url.Values{}
+	if query.Type != "" {
+		values.Set("type", query.Type)
+	}
+	if query.Status != "" {
+		values.Set("status", query.Status)
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.Cursor != "" {
+		values.Set("cursor", query.Cursor)
+	}
+
+	var page HistoryPage
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          withQuery("/api/users/me/notification-history", values),
+		authenticated: true,
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetDigestSettings returns how the authenticated user's notifications are
+// batched into digests
+func (c *Client) GetDigestSettings(ctx context.Context) (*DigestSettings, error) {
+	var settings DigestSettings
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/me/notification-digest",
+		authenticated: true,
+	}, &settings)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateDigestSettings replaces the authenticated user's digest settings and
+// returns them as stored
+func (c *Client) UpdateDigestSettings(ctx context.Context, settings DigestSettings) (*DigestSettings, error) {
+	var updated DigestSettings
+	err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/users/me/notification-digest",
+		body:          settings,
+		authenticated: true,
+	}, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// GetPendingDigest returns the notifications collected for the user's next
+// digest and when it is due
+func (c *Client) GetPendingDigest(ctx context.Context) (*Digest, error) {
+	var digest Digest
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/me/notification-digest/pending",
+		authenticated: true,
+	}, &digest)
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
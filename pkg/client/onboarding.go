@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GetOnboarding returns the authenticated user's onboarding progress and the
+// prompts due now
+func (c *Client) GetOnboarding(ctx context.Context) (*OnboardingProgress, error) {
+	return c.onboarding(ctx, http.MethodGet, "", nil)
+}
+
+// ListOnboardingPrompts returns every onboarding prompt in the order they are asked
+func (c *Client) ListOnboardingPrompts(ctx context.Context) ([]OnboardingPrompt, error) {
+	var prompts []OnboardingPrompt
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/profile/onboarding/prompts",
+		authenticated: true,
+	}, &prompts)
+	if err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// SubmitOnboardingField answers a prompt. Values failing the prompt's rules
+// are rejected with a validation error.
+func (c *Client) SubmitOnboardingField(ctx context.Context, field, value string) (*OnboardingProgress, error) {
+	return c.onboarding(ctx, http.MethodPut, "/"+url.PathEscape(field), SubmitOnboardingFieldRequest{Value: value})
+}
+
+// SkipOnboardingField dismisses an optional prompt for good
+func (c *Client) SkipOnboardingField(ctx context.Context, field string) (*OnboardingProgress, error) {
+	return c.onboarding(ctx, http.MethodPost, "/"+url.PathEscape(field)+"/skip", nil)
+}
+
+// RemindLaterOnboardingField hides an unanswered prompt until remindAt; a
+// zero remindAt uses the server's reminder delay
+func (c *Client) RemindLaterOnboardingField(ctx context.Context, field string, remindAt time.Time) (*OnboardingProgress, error) {
+	var body interface{}
+	if !remindAt.IsZero() {
+		body = RemindLaterRequest{RemindAt: remindAt}
+	}
+	return c.onboarding(ctx, http.MethodPost, "/"+url.PathEscape(field)+"/remind-later", body)
+}
+
+// onboarding sends a request below the onboarding path and returns the
+// resulting progress
+func (c *Client) onboarding(ctx context.Context, method, subpath string, body interface{}) (*OnboardingProgress, error) {
+	var progress OnboardingProgress
+	err := c.do(ctx, request{
+		method:        method,
+		path:          "/api/users/profile/onboarding" + subpath,
+		body:          body,
+		authenticated: true,
+	}, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
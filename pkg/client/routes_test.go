@@ -0,0 +1,293 @@
+package client_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gentra/decorator-arch-go/pkg/client"
+)
+
+// routesFile is the file the REST server registers its routes in
+const routesFile = "../../cmd/rest/routes.go"
+
+// adminPrefix marks the admin API, which operators reach with their own
+// tooling rather than this client
+const adminPrefix = "/api/admin/"
+
+// uncoveredRoutes are the routes the client deliberately has no method for,
+// with the reason. Patterns built by concatenation are keyed by their source.
+var uncoveredRoutes = map[string]string{
+	"GET /healthz":                       "load balancer probe",
+	"GET /.well-known/jwks.json":         "key discovery for services verifying tokens",
+	"DELETE /api/users/me/tokens":        "same handler as POST /api/auth/logout-all, covered by LogoutAll",
+	"POST /api/notifications/sms/status": "callback for the SMS provider",
+	"GET /api/realtime":                  "WebSocket stream, not a JSON endpoint",
+	"GET /api/auth/saml/metadata":        "SAML identity providers fetch it",
+	"GET /api/auth/saml/login":           "browser redirect to the identity provider",
+	"POST /api/auth/saml/acs":            "browser form post from the identity provider",
+	"POST /api/service-accounts/token":   "service accounts sign in with their client credentials",
+	"GET /oauth/authorize":               "browser consent page of the OAuth flow",
+	"POST /oauth/authorize":              "browser consent page of the OAuth flow",
+	"POST /oauth/token":                  "OAuth clients use their OAuth library",
+	`"GET "+mediaPath+"/{key...}"`:       "media is fetched from the links the API returns, such as GetAvatarURL",
+}
+
+// clientCalls exercise every client method against a server that answers
+// each request with an empty JSON body
+var clientCalls = map[string]func(ctx context.Context, c *client.Client) error{
+	"Register": func(ctx context.Context, c *client.Client) error {
+		_, err := c.Register(ctx, client.RegisterRequest{})
+		return err
+	},
+	"VerifyEmail": func(ctx context.Context, c *client.Client) error {
+		_, err := c.VerifyEmail(ctx, "token")
+		return err
+	},
+	"RequestPasswordReset": func(ctx context.Context, c *client.Client) error {
+		return c.RequestPasswordReset(ctx, "user@example.com")
+	},
+	"ResetPassword": func(ctx context.Context, c *client.Client) error {
+		return c.ResetPassword(ctx, client.ResetPasswordRequest{})
+	},
+	"Login": func(ctx context.Context, c *client.Client) error {
+		_, err := c.Login(ctx, "user@example.com", "password")
+		return err
+	},
+	"Logout": func(ctx context.Context, c *client.Client) error {
+		return c.Logout(ctx)
+	},
+	"LogoutAll": func(ctx context.Context, c *client.Client) error {
+		return c.LogoutAll(ctx)
+	},
+	"Introspect": func(ctx context.Context, c *client.Client) error {
+		_, err := c.Introspect(ctx, "token")
+		return err
+	},
+	"Me": func(ctx context.Context, c *client.Client) error {
+		_, err := c.Me(ctx)
+		return err
+	},
+	"RefreshToken": func(ctx context.Context, c *client.Client) error {
+		return c.RefreshToken(ctx)
+	},
+	"RequestMagicLink": func(ctx context.Context, c *client.Client) error {
+		return c.RequestMagicLink(ctx, "user@example.com")
+	},
+	"VerifyMagicLink": func(ctx context.Context, c *client.Client) error {
+		_, err := c.VerifyMagicLink(ctx, "token")
+		return err
+	},
+	"GetProfile": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetProfile(ctx)
+		return err
+	},
+	"UpdateProfile": func(ctx context.Context, c *client.Client) error {
+		_, err := c.UpdateProfile(ctx, client.UpdateProfileRequest{})
+		return err
+	},
+	"ChangePassword": func(ctx context.Context, c *client.Client) error {
+		return c.ChangePassword(ctx, client.ChangePasswordRequest{})
+	},
+	"RequestEmailChange": func(ctx context.Context, c *client.Client) error {
+		return c.RequestEmailChange(ctx, "new@example.com")
+	},
+	"ConfirmEmailChange": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ConfirmEmailChange(ctx, "token")
+		return err
+	},
+	"UploadAvatar": func(ctx context.Context, c *client.Client) error {
+		return c.UploadAvatar(ctx, []byte("image"), "image/png")
+	},
+	"GetAvatarURL": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetAvatarURL(ctx)
+		return err
+	},
+	"GetPreferences": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetPreferences(ctx)
+		return err
+	},
+	"UpdatePreferences": func(ctx context.Context, c *client.Client) error {
+		return c.UpdatePreferences(ctx, client.Preferences{})
+	},
+	"GetPreferenceSchema": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetPreferenceSchema(ctx)
+		return err
+	},
+	"GetFeatureFlags": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetFeatureFlags(ctx)
+		return err
+	},
+	"ExportData": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ExportData(ctx)
+		return err
+	},
+	"EraseAccount": func(ctx context.Context, c *client.Client) error {
+		return c.EraseAccount(ctx)
+	},
+	"ListTokens": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ListTokens(ctx)
+		return err
+	},
+	"CreateAPIKey": func(ctx context.Context, c *client.Client) error {
+		_, err := c.CreateAPIKey(ctx, "user-1", []string{"read"})
+		return err
+	},
+	"RevokeAPIKey": func(ctx context.Context, c *client.Client) error {
+		return c.RevokeAPIKey(ctx, "user-1", "key")
+	},
+	"GetOnboarding": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetOnboarding(ctx)
+		return err
+	},
+	"ListOnboardingPrompts": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ListOnboardingPrompts(ctx)
+		return err
+	},
+	"SubmitOnboardingField": func(ctx context.Context, c *client.Client) error {
+		_, err := c.SubmitOnboardingField(ctx, "company", "Acme")
+		return err
+	},
+	"SkipOnboardingField": func(ctx context.Context, c *client.Client) error {
+		_, err := c.SkipOnboardingField(ctx, "company")
+		return err
+	},
+	"RemindLaterOnboardingField": func(ctx context.Context, c *client.Client) error {
+		_, err := c.RemindLaterOnboardingField(ctx, "company", time.Time{})
+		return err
+	},
+	"ListNotifications": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ListNotifications(ctx, 10)
+		return err
+	},
+	"MarkNotificationRead": func(ctx context.Context, c *client.Client) error {
+		return c.MarkNotificationRead(ctx, "notification-1")
+	},
+	"ListNotificationHistory": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ListNotificationHistory(ctx, client.HistoryQuery{Limit: 10})
+		return err
+	},
+	"GetDigestSettings": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetDigestSettings(ctx)
+		return err
+	},
+	"UpdateDigestSettings": func(ctx context.Context, c *client.Client) error {
+		_, err := c.UpdateDigestSettings(ctx, client.DigestSettings{Frequency: "daily"})
+		return err
+	},
+	"GetPendingDigest": func(ctx context.Context, c *client.Client) error {
+		_, err := c.GetPendingDigest(ctx)
+		return err
+	},
+	"ListInbox": func(ctx context.Context, c *client.Client) error {
+		_, err := c.ListInbox(ctx, client.InboxQuery{UnreadOnly: true})
+		return err
+	},
+	"InboxUnreadCount": func(ctx context.Context, c *client.Client) error {
+		_, err := c.InboxUnreadCount(ctx)
+		return err
+	},
+	"MarkInboxRead": func(ctx context.Context, c *client.Client) error {
+		_, err := c.MarkInboxRead(ctx, "notification-1")
+		return err
+	},
+	"MarkAllInboxRead": func(ctx context.Context, c *client.Client) error {
+		_, err := c.MarkAllInboxRead(ctx)
+		return err
+	},
+}
+
+// registeredRoutes returns the patterns passed to mux.Handle and
+// mux.HandleFunc in routesFile. Patterns that are not string literals are
+// returned as their source.
+func registeredRoutes(t *testing.T) (literal []string, computed []string) {
+	t.Helper()
+
+	source, err := os.ReadFile(routesFile)
+	require.NoError(t, err)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, routesFile, source, 0)
+	require.NoError(t, err)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (selector.Sel.Name != "Handle" && selector.Sel.Name != "HandleFunc") {
+			return true
+		}
+
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			pattern, err := strconv.Unquote(lit.Value)
+			require.NoError(t, err)
+			literal = append(literal, pattern)
+			return true
+		}
+		arg := call.Args[0]
+		computed = append(computed, string(source[fset.Position(arg.Pos()).Offset:fset.Position(arg.End()).Offset]))
+		return true
+	})
+	return literal, computed
+}
+
+func TestClient_GivenServerRoutes_WhenCallingEveryMethod_ThenEachRouteIsCoveredOrExcluded(t *testing.T) {
+	// Arrange
+	literal, computed := registeredRoutes(t)
+	require.NotEmpty(t, literal, "no routes found in %s", routesFile)
+
+	var mu sync.Mutex
+	hit := make(map[string]bool)
+	mux := http.NewServeMux()
+	for _, pattern := range literal {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hit[r.Pattern] = true
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("null"))
+		})
+	}
+	c := newTestClient(t, mux)
+
+	// Act
+	ctx := context.Background()
+	for name, call := range clientCalls {
+		c.SetTokens("access-token", "refresh-token")
+		assert.NoError(t, call(ctx, c), name)
+	}
+
+	// Assert
+	var missing []string
+	for _, pattern := range append(literal, computed...) {
+		_, excluded := uncoveredRoutes[pattern]
+		admin := strings.Contains(pattern, " "+adminPrefix)
+		if !hit[pattern] && !excluded && !admin {
+			missing = append(missing, pattern)
+		}
+	}
+	sort.Strings(missing)
+	assert.Empty(t, missing, "routes without a client method; add one or list the route in uncoveredRoutes")
+
+	registered := make(map[string]bool)
+	for _, pattern := range append(literal, computed...) {
+		registered[pattern] = true
+	}
+	for pattern := range uncoveredRoutes {
+		assert.True(t, registered[pattern], "uncoveredRoutes lists %q, which %s no longer registers", pattern, routesFile)
+		assert.False(t, hit[pattern], "uncoveredRoutes lists %q, which the client now calls", pattern)
+	}
+}
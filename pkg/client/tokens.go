@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListTokens returns the authenticated user's active sessions and API keys
+func (c *Client) ListTokens(ctx context.Context) ([]TokenInfo, error) {
+	var list TokenList
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/me/tokens",
+		authenticated: true,
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return list.Tokens, nil
+}
+
+// CreateAPIKey issues an API key for the user with the given scopes. The key
+// is only returned here, so store it before discarding the result.
+func (c *Client) CreateAPIKey(ctx context.Context, userID string, scopes []string) (*APIKey, error) {
+	var key APIKey
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/" + url.PathEscape(userID) + "/api-keys",
+		body:          CreateAPIKeyRequest{Scopes: scopes},
+		authenticated: true,
+	}, &key)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey revokes one of the user's API keys
+func (c *Client) RevokeAPIKey(ctx context.Context, userID, key string) error {
+	return c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/" + url.PathEscape(userID) + "/api-keys/revoke",
+		body:          RevokeAPIKeyRequest{Key: key},
+		authenticated: true,
+	}, nil)
+}
@@ -0,0 +1,359 @@
+package client
+
+import "time"
+
+// User represents a user as returned by the API
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// PendingEmail is set while a requested email change awaits confirmation
+	PendingEmail string `json:"pending_email,omitempty"`
+
+	// EmailVerifiedAt is set once the user has verified their email
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+}
+
+// RegisterRequest contains data for user registration
+type RegisterRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// LoginRequest contains login credentials
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UpdateProfileRequest contains data for profile updates; nil fields are left unchanged
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Email     *string `json:"email,omitempty"`
+}
+
+// EmailChangeRequest asks for the email to be changed to Email once confirmed
+type EmailChangeRequest struct {
+	Email string `json:"email"`
+}
+
+// ConfirmEmailChangeRequest contains the token sent to the new email
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmailRequest contains the token sent to the user's email on registration
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// PasswordResetRequest names the account a reset token is emailed to
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest contains an emailed reset token and the new password
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePasswordRequest contains the current password and its replacement
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// Avatar is a link to a user's avatar image
+type Avatar struct {
+	URL string `json:"url"`
+}
+
+// AuthResult contains authentication result data
+type AuthResult struct {
+	User         *User     `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Preferences contains user notification and system preferences
+type Preferences struct {
+	ID                 string          `json:"id,omitempty"`
+	UserID             string          `json:"user_id,omitempty"`
+	EmailNotifications bool            `json:"email_notifications"`
+	PushNotifications  bool            `json:"push_notifications"`
+	SMSNotifications   bool            `json:"sms_notifications"`
+	Theme              string          `json:"theme"`
+	Language           string          `json:"language"`
+	Timezone           string          `json:"timezone"`
+	NotificationTypes  map[string]bool `json:"notification_types"`
+	CreatedAt          time.Time       `json:"created_at,omitempty"`
+	UpdatedAt          time.Time       `json:"updated_at,omitempty"`
+}
+
+// DataExport is a copy of all personal data the API holds about the user
+type DataExport struct {
+	UserID        string                   `json:"user_id"`
+	ExportedAt    time.Time                `json:"exported_at"`
+	Profile       *User                    `json:"profile"`
+	Preferences   *Preferences             `json:"preferences,omitempty"`
+	AuditEntries  []map[string]interface{} `json:"audit_entries"`
+	Notifications []Notification           `json:"notifications"`
+}
+
+// Notification represents a notification in the user's history
+type Notification struct {
+	ID        string                 `json:"id"`
+	UserID    string                 `json:"user_id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Status    string                 `json:"status"`
+	Priority  string                 `json:"priority"`
+	CreatedAt time.Time              `json:"created_at"`
+	SentAt    *time.Time             `json:"sent_at,omitempty"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+}
+
+// IsRead reports whether the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// IntrospectRequest contains the token to introspect
+type IntrospectRequest struct {
+	Token string `json:"token"`
+}
+
+// TokenIntrospection describes a token, following RFC 7662
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`    // Space-separated scopes
+	Username  string `json:"username,omitempty"` // Email of the token's user
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"` // Unix seconds
+	IssuedAt  int64  `json:"iat,omitempty"` // Unix seconds
+	Subject   string `json:"sub,omitempty"` // ID of the token's user
+	Strategy  string `json:"strategy,omitempty"`
+}
+
+// MagicLinkRequest names the account a sign-in link is emailed to
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyMagicLinkRequest contains the token from an emailed sign-in link
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token"`
+}
+
+// TokenList is the user's active tokens as returned by the API
+type TokenList struct {
+	Tokens []TokenInfo `json:"tokens"`
+}
+
+// TokenInfo describes an active session or API key
+type TokenInfo struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenType string     `json:"token_type"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+	IsRevoked bool       `json:"is_revoked"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	IPAddress string     `json:"ip_address,omitempty"`
+}
+
+// CreateAPIKeyRequest contains the scopes granted to a new API key
+type CreateAPIKeyRequest struct {
+	Scopes []string `json:"scopes"`
+}
+
+// RevokeAPIKeyRequest contains the API key to revoke
+type RevokeAPIKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// APIKey is an issued API key; Token is only set when the key is created
+type APIKey struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token"`
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+}
+
+// PreferenceSchema lists the notification types preferences may hold
+type PreferenceSchema struct {
+	NotificationTypes []NotificationTypeDefinition `json:"notification_types"`
+}
+
+// NotificationTypeDefinition describes a notification type users can opt out of
+type NotificationTypeDefinition struct {
+	Type        string `json:"type"`
+	Domain      string `json:"domain"`  // Domain sending the notifications
+	Default     bool   `json:"default"` // Setting new users start with
+	Description string `json:"description"`
+}
+
+// FeatureFlags are the feature flags set for a user
+type FeatureFlags struct {
+	UserID    string          `json:"user_id"`
+	Flags     map[string]bool `json:"flags"`
+	UpdatedAt time.Time       `json:"updated_at"` // Latest change to any flag; zero when none was set
+}
+
+// IsEnabled reports whether the named flag is on
+func (f *FeatureFlags) IsEnabled(flag string) bool {
+	return f.Flags[flag]
+}
+
+// OnboardingPrompt is a profile field the user is asked to fill in
+type OnboardingPrompt struct {
+	Field       string `json:"field"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	State       string `json:"state"`
+	Required    bool   `json:"required"`
+	Rules       string `json:"rules,omitempty"`
+}
+
+// OnboardingField is how the user has responded to a prompt
+type OnboardingField struct {
+	Status    string     `json:"status"`
+	Value     string     `json:"value,omitempty"`
+	RemindAt  *time.Time `json:"remind_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// OnboardingProgress is the user's onboarding state and the prompts due now
+type OnboardingProgress struct {
+	UserID     string                     `json:"user_id"`
+	State      string                     `json:"state"`
+	Fields     map[string]OnboardingField `json:"fields"`
+	Due        []OnboardingPrompt         `json:"due"`
+	Completion int                        `json:"completion"` // Percentage of prompts filled in
+}
+
+// SubmitOnboardingFieldRequest contains the answer to a prompt
+type SubmitOnboardingFieldRequest struct {
+	Value string `json:"value"`
+}
+
+// RemindLaterRequest contains when a prompt should be shown again
+type RemindLaterRequest struct {
+	RemindAt time.Time `json:"remind_at"`
+}
+
+// InboxQuery selects a page of in-app notifications
+type InboxQuery struct {
+	Cursor     string
+	UnreadOnly bool
+	Limit      int
+}
+
+// InboxNotification is an in-app notification
+type InboxNotification struct {
+	ID        string                 `json:"id"`
+	UserID    string                 `json:"user_id"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body,omitempty"`
+	Category  string                 `json:"category,omitempty"`
+	Link      string                 `json:"link,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Priority  string                 `json:"priority,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+}
+
+// IsRead reports whether the notification has been read
+func (n *InboxNotification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// InboxPage is a page of in-app notifications
+type InboxPage struct {
+	Notifications []InboxNotification `json:"notifications"`
+	UnreadCount   int                 `json:"unread_count"`
+	Limit         int                 `json:"limit"`
+	NextCursor    string              `json:"next_cursor,omitempty"` // Set when more notifications follow
+}
+
+// InboxCount contains the counts returned by the inbox endpoints
+type InboxCount struct {
+	MarkedRead  int `json:"marked_read,omitempty"`
+	UnreadCount int `json:"unread_count"`
+}
+
+// HistoryQuery selects a page of the notification history; zero fields are
+// not filtered on
+type HistoryQuery struct {
+	Type   string
+	Status string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Cursor string
+}
+
+// HistoryNotification is a sent notification with its delivery status
+type HistoryNotification struct {
+	Notification
+	FailureCount int    `json:"failure_count"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+// HistoryPage is a page of the notification history
+type HistoryPage struct {
+	Notifications []HistoryNotification `json:"notifications"`
+	Limit         int                   `json:"limit"`
+	NextCursor    string                `json:"next_cursor,omitempty"` // Set when more notifications follow
+}
+
+// DigestSettings controls how a user's notifications are batched into digests
+type DigestSettings struct {
+	UserID    string `json:"user_id,omitempty"`
+	Frequency string `json:"frequency"` // off, hourly or daily
+
+	// DeliverAt is the local time, "HH:MM" in the user's timezone, daily
+	// digests are sent at
+	DeliverAt string `json:"deliver_at,omitempty"`
+
+	// Types are the notification types collected in the digest
+	Types []string `json:"types"`
+
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// DigestItem is a notification waiting for the next digest
+type DigestItem struct {
+	ID        string                 `json:"id"`
+	UserID    string                 `json:"user_id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Priority  string                 `json:"priority,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// Digest is the notifications collected for a user's next digest
+type Digest struct {
+	UserID string       `json:"user_id"`
+	Items  []DigestItem `json:"items"`
+	DueAt  time.Time    `json:"due_at"`
+}
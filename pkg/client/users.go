@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetProfile returns the authenticated user's profile
+func (c *Client) GetProfile(ctx context.Context) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/profile",
+		authenticated: true,
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile updates the authenticated user's profile
+func (c *Client) UpdateProfile(ctx context.Context, data UpdateProfileRequest) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/users/profile",
+		body:          data,
+		authenticated: true,
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChangePassword replaces the authenticated user's password. Recently used
+// passwords are rejected with the PASSWORD_REUSED error code.
+func (c *Client) ChangePassword(ctx context.Context, data ChangePasswordRequest) error {
+	return c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/users/password",
+		body:          data,
+		authenticated: true,
+	}, nil)
+}
+
+// RequestEmailChange sends a confirmation token to the new email; the
+// authenticated user's email changes once ConfirmEmailChange is called with it
+func (c *Client) RequestEmailChange(ctx context.Context, email string) error {
+	return c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/email",
+		body:          EmailChangeRequest{Email: email},
+		authenticated: true,
+	}, nil)
+}
+
+// ConfirmEmailChange applies the pending email change and returns the updated user
+func (c *Client) ConfirmEmailChange(ctx context.Context, token string) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/email/confirm",
+		body:          ConfirmEmailChangeRequest{Token: token},
+		authenticated: true,
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UploadAvatar replaces the authenticated user's avatar. JPEG, PNG, GIF and
+// WebP images of up to 5 MB are accepted; contentType must match the image.
+func (c *Client) UploadAvatar(ctx context.Context, image []byte, contentType string) error {
+	return c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/users/avatar",
+		body:          image,
+		contentType:   contentType,
+		authenticated: true,
+	}, nil)
+}
+
+// GetAvatarURL returns a link to the authenticated user's avatar. Links to
+// avatars in cloud storage expire, so fetch a new one rather than storing it.
+func (c *Client) GetAvatarURL(ctx context.Context) (string, error) {
+	var avatar Avatar
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/avatar",
+		authenticated: true,
+	}, &avatar)
+	if err != nil {
+		return "", err
+	}
+	return avatar.URL, nil
+}
+
+// GetPreferences returns the authenticated user's preferences
+func (c *Client) GetPreferences(ctx context.Context) (*Preferences, error) {
+	var prefs Preferences
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/preferences",
+		authenticated: true,
+	}, &prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences replaces the authenticated user's preferences
+func (c *Client) UpdatePreferences(ctx context.Context, prefs Preferences) error {
+	return c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/users/preferences",
+		body:          prefs,
+		authenticated: true,
+	}, nil)
+}
+
+// GetPreferenceSchema returns the notification types preferences may hold,
+// with the setting new users start with
+func (c *Client) GetPreferenceSchema(ctx context.Context) ([]NotificationTypeDefinition, error) {
+	var schema PreferenceSchema
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/preferences/schema",
+		authenticated: true,
+	}, &schema)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NotificationTypes, nil
+}
+
+// GetFeatureFlags returns the feature flags set for the authenticated user
+func (c *Client) GetFeatureFlags(ctx context.Context) (*FeatureFlags, error) {
+	var flags FeatureFlags
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/feature-flags",
+		authenticated: true,
+	}, &flags)
+	if err != nil {
+		return nil, err
+	}
+	return &flags, nil
+}
+
+// ExportData returns a copy of all personal data held about the authenticated user
+func (c *Client) ExportData(ctx context.Context) (*DataExport, error) {
+	var export DataExport
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/users/profile/export",
+		authenticated: true,
+	}, &export)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// EraseAccount permanently erases the authenticated user's personal data; the
+// client's tokens are revoked and must not be used afterwards
+func (c *Client) EraseAccount(ctx context.Context) error {
+	return c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/users/profile/erase",
+		authenticated: true,
+	}, nil)
+}